@@ -1,6 +1,8 @@
 package agent
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 )
 
@@ -9,7 +11,11 @@ func NewAgentCommand() *cobra.Command {
 		message    string
 		sessionKey string
 		model      string
+		files      []string
 		debug      bool
+		noTools    bool
+		resume     bool
+		loop       stdinLoopOptions
 	)
 
 	cmd := &cobra.Command{
@@ -17,7 +23,17 @@ func NewAgentCommand() *cobra.Command {
 		Short: "Interact with the agent directly",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return agentCmd(message, sessionKey, model, debug)
+			if loop.enabled {
+				if message != "" || len(files) > 0 {
+					return fmt.Errorf("--stdin-loop cannot be combined with --message or --file")
+				}
+			} else if loop.json || loop.separateSessions {
+				return fmt.Errorf("--json and --separate-sessions require --stdin-loop")
+			}
+			if resume && (message != "" || loop.enabled) {
+				return fmt.Errorf("--resume is for interactive mode; --message and --stdin-loop always continue --session")
+			}
+			return agentCmd(message, files, sessionKey, model, debug, noTools, resume, loop)
 		},
 	}
 
@@ -25,6 +41,17 @@ func NewAgentCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&message, "message", "m", "", "Send a single message (non-interactive mode)")
 	cmd.Flags().StringVarP(&sessionKey, "session", "s", "cli:default", "Session key")
 	cmd.Flags().StringVarP(&model, "model", "", "", "Model to use")
+	cmd.Flags().BoolVar(&resume, "resume", false,
+		"Continue the stored conversation of --session instead of starting a new one (interactive mode)")
+	cmd.Flags().BoolVar(&noTools, "no-tools", false,
+		"Answer without running any tools (type :notools in interactive mode to toggle per session)")
+	cmd.Flags().StringArrayVarP(&files, "file", "f", nil,
+		"Include a file's contents with --message (repeatable, - reads stdin)")
+	cmd.Flags().BoolVar(&loop.enabled, "stdin-loop", false,
+		"Answer each line of stdin in order and exit at end of input")
+	cmd.Flags().BoolVar(&loop.json, "json", false, "With --stdin-loop, print one JSON object per line")
+	cmd.Flags().BoolVar(&loop.separateSessions, "separate-sessions", false,
+		"With --stdin-loop, give each line its own session instead of sharing --session")
 
 	return cmd
 }
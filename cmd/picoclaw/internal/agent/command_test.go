@@ -30,4 +30,26 @@ func TestNewAgentCommand(t *testing.T) {
 	assert.NotNil(t, cmd.Flags().Lookup("message"))
 	assert.NotNil(t, cmd.Flags().Lookup("session"))
 	assert.NotNil(t, cmd.Flags().Lookup("model"))
+	assert.NotNil(t, cmd.Flags().Lookup("file"))
+	assert.NotNil(t, cmd.Flags().Lookup("stdin-loop"))
+	assert.NotNil(t, cmd.Flags().Lookup("json"))
+	assert.NotNil(t, cmd.Flags().Lookup("separate-sessions"))
+	assert.NotNil(t, cmd.Flags().Lookup("no-tools"))
+	assert.NotNil(t, cmd.Flags().Lookup("resume"))
+}
+
+func TestNewAgentCommand_StdinLoopFlagConflicts(t *testing.T) {
+	for _, args := range [][]string{
+		{"--stdin-loop", "-m", "hi"},
+		{"--json"},
+		{"--separate-sessions"},
+		{"--resume", "-m", "hi"},
+		{"--resume", "--stdin-loop"},
+	} {
+		cmd := NewAgentCommand()
+		cmd.SetArgs(args)
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		assert.Error(t, cmd.Execute(), "args %v", args)
+	}
 }
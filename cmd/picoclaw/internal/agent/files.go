@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxAttachedFileBytes caps the combined size of files passed with --file.
+const maxAttachedFileBytes = 256 * 1024
+
+const stdinFileName = "-"
+
+// attachFiles prepends the contents of paths to message, each under a
+// filename header. The combined contents are capped at limit bytes; the
+// names of files that had to be cut short are returned so the caller can
+// warn about them.
+func attachFiles(message string, paths []string, stdin io.Reader, limit int) (string, []string, error) {
+	var (
+		sb        strings.Builder
+		truncated []string
+		usedStdin bool
+	)
+	remaining := limit
+
+	for _, path := range paths {
+		name := path
+		var r io.Reader
+		if path == stdinFileName {
+			if usedStdin {
+				return "", nil, fmt.Errorf("--file - can only be given once")
+			}
+			usedStdin = true
+			name = "stdin"
+			r = stdin
+		} else {
+			f, err := os.Open(path)
+			if err != nil {
+				return "", nil, fmt.Errorf("error reading --file: %w", err)
+			}
+			defer f.Close()
+			r = f
+		}
+
+		// Read one byte past the budget to tell a file that fits exactly
+		// from one that was cut.
+		data, err := io.ReadAll(io.LimitReader(r, int64(remaining)+1))
+		if err != nil {
+			return "", nil, fmt.Errorf("error reading --file %s: %w", name, err)
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			return "", nil, fmt.Errorf("--file %s looks like a binary file", name)
+		}
+
+		cut := len(data) > remaining
+		if cut {
+			data = data[:remaining]
+			truncated = append(truncated, name)
+		}
+		remaining -= len(data)
+
+		fmt.Fprintf(&sb, "--- file: %s ---\n", name)
+		content := strings.ToValidUTF8(string(data), "")
+		sb.WriteString(content)
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			sb.WriteByte('\n')
+		}
+		if cut {
+			sb.WriteString("[truncated]\n")
+		}
+		fmt.Fprintf(&sb, "--- end of file: %s ---\n\n", name)
+	}
+
+	sb.WriteString(message)
+	return sb.String(), truncated, nil
+}
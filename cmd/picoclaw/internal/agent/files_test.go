@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachFiles_PrependsContentsWithHeaders(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "main.go")
+	second := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(first, []byte("package main\n"), 0o644))
+	require.NoError(t, os.WriteFile(second, []byte("no trailing newline"), 0o644))
+
+	got, truncated, err := attachFiles("review these", []string{first, second}, nil, 1024)
+	require.NoError(t, err)
+	assert.Empty(t, truncated)
+
+	want := "--- file: " + first + " ---\npackage main\n--- end of file: " + first + " ---\n\n" +
+		"--- file: " + second + " ---\nno trailing newline\n--- end of file: " + second + " ---\n\n" +
+		"review these"
+	assert.Equal(t, want, got)
+}
+
+func TestAttachFiles_ReadsStdin(t *testing.T) {
+	got, _, err := attachFiles("summarize", []string{"-"}, strings.NewReader("log line\n"), 1024)
+	require.NoError(t, err)
+	assert.Equal(t, "--- file: stdin ---\nlog line\n--- end of file: stdin ---\n\nsummarize", got)
+
+	_, _, err = attachFiles("summarize", []string{"-", "-"}, strings.NewReader(""), 1024)
+	assert.Error(t, err)
+}
+
+func TestAttachFiles_TruncatesAtTotalLimit(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a.txt")
+	second := filepath.Join(dir, "b.txt")
+	require.NoError(t, os.WriteFile(first, []byte("123456"), 0o644))
+	require.NoError(t, os.WriteFile(second, []byte("abcdef"), 0o644))
+
+	got, truncated, err := attachFiles("go", []string{first, second}, nil, 8)
+	require.NoError(t, err)
+	assert.Equal(t, []string{second}, truncated)
+	assert.Contains(t, got, "123456\n")
+	assert.Contains(t, got, "ab\n[truncated]\n")
+	assert.NotContains(t, got, "abc")
+}
+
+func TestAttachFiles_ExactFitIsNotTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	require.NoError(t, os.WriteFile(path, []byte("1234"), 0o644))
+
+	_, truncated, err := attachFiles("go", []string{path}, nil, 4)
+	require.NoError(t, err)
+	assert.Empty(t, truncated)
+}
+
+func TestAttachFiles_Errors(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "blob.bin")
+	require.NoError(t, os.WriteFile(binary, []byte{0x7f, 'E', 'L', 'F', 0}, 0o644))
+
+	_, _, err := attachFiles("go", []string{filepath.Join(dir, "missing.txt")}, nil, 1024)
+	assert.Error(t, err)
+
+	_, _, err = attachFiles("go", []string{binary}, nil, 1024)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "binary")
+}
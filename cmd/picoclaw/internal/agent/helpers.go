@@ -14,14 +14,39 @@ import (
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
 	"github.com/sipeed/picoclaw/pkg/agent"
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
-func agentCmd(message, sessionKey, model string, debug bool) error {
+func agentCmd(
+	message string,
+	files []string,
+	sessionKey, model string,
+	debug, noTools, resume bool,
+	loop stdinLoopOptions,
+) error {
 	if sessionKey == "" {
 		sessionKey = "cli:default"
 	}
+	sessionName := sessionKey
+	sessionKey = cliSessionKey(sessionKey)
+
+	if len(files) > 0 {
+		if message == "" {
+			return fmt.Errorf("--file requires --message")
+		}
+		var truncated []string
+		var err error
+		message, truncated, err = attachFiles(message, files, os.Stdin, maxAttachedFileBytes)
+		if err != nil {
+			return err
+		}
+		for _, name := range truncated {
+			fmt.Fprintf(os.Stderr, "Warning: %s was truncated to fit the %d KB file limit\n",
+				name, maxAttachedFileBytes/1024)
+		}
+	}
 
 	cfg, err := internal.LoadConfig()
 	if err != nil {
@@ -32,12 +57,22 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 
 	if debug {
 		logger.SetLevel(logger.DEBUG)
-		fmt.Println("🔍 Debug mode enabled")
+		fmt.Fprintln(os.Stderr, "🔍 Debug mode enabled")
+	}
+	if loop.enabled {
+		// Console logs go to stdout and would mix with the responses.
+		logger.DisableConsole()
 	}
 
 	if model != "" {
 		cfg.Agents.Defaults.ModelName = model
 	}
+	if noTools {
+		// Sending no tool definitions forces a direct answer. The rest of a
+		// configured turn profile still applies.
+		cfg.Agents.Defaults.TurnProfile.Enabled = true
+		cfg.Agents.Defaults.TurnProfile.Tools = config.TurnProfileBlock{Mode: config.TurnProfileModeOff}
+	}
 
 	provider, modelID, err := providers.CreateProvider(cfg)
 	if err != nil {
@@ -74,28 +109,48 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 	}
 	logger.InfoCF("agent", "Agent initialized", logFields)
 
+	if loop.enabled {
+		loop.sessionKey = sessionKey
+		return runStdinLoop(context.Background(), agentLoop, os.Stdin, os.Stdout, os.Stderr, loop)
+	}
+
 	if message != "" {
 		ctx := context.Background()
 		response, err := agentLoop.ProcessDirect(ctx, message, sessionKey)
 		if err != nil {
 			return fmt.Errorf("error processing message: %w", err)
 		}
-		fmt.Printf("\n%s %s\n", internal.Logo, response)
+		fmt.Printf("\n%s %s\n", responseLabel(cfg.Agents.Defaults.Name), response)
 		return nil
 	}
 
-	fmt.Printf("%s Interactive mode (Ctrl+C to exit)\n\n", internal.Logo)
-	interactiveMode(agentLoop, sessionKey)
+	note, err := startInteractiveSession(context.Background(), agentLoop, sessionName, sessionKey, resume)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Interactive mode (Ctrl+C to exit)\n%s\n\n", internal.Logo, note)
+	historyFile := filepath.Join(cfg.WorkspacePath(), historyFileName)
+	interactiveMode(agentLoop, sessionKey, responseLabel(cfg.Agents.Defaults.Name), historyFile)
 
 	return nil
 }
 
-func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
+// responseLabel prefixes the agent's replies: the logo, followed by the
+// configured agent name when there is one.
+func responseLabel(name string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return internal.Logo + " " + name + ":"
+	}
+	return internal.Logo
+}
+
+func interactiveMode(agentLoop *agent.AgentLoop, sessionKey, label, historyFile string) {
 	prompt := fmt.Sprintf("%s You: ", internal.Logo)
 
+	_ = os.MkdirAll(filepath.Dir(historyFile), 0o755)
 	rl, err := readline.NewEx(&readline.Config{
 		Prompt:          prompt,
-		HistoryFile:     filepath.Join(os.TempDir(), ".picoclaw_history"),
+		HistoryFile:     historyFile,
 		HistoryLimit:    100,
 		InterruptPrompt: "^C",
 		EOFPrompt:       "exit",
@@ -103,7 +158,7 @@ func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 	if err != nil {
 		fmt.Printf("Error initializing readline: %v\n", err)
 		fmt.Println("Falling back to simple input mode...")
-		simpleInteractiveMode(agentLoop, sessionKey)
+		simpleInteractiveMode(agentLoop, sessionKey, label)
 		return
 	}
 	defer rl.Close()
@@ -128,6 +183,10 @@ func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 			fmt.Println("Goodbye!")
 			return
 		}
+		if input == noToolsCommand {
+			fmt.Printf("%s\n\n", toggleNoTools(agentLoop, sessionKey))
+			continue
+		}
 
 		ctx := context.Background()
 		response, err := agentLoop.ProcessDirect(ctx, input, sessionKey)
@@ -136,11 +195,11 @@ func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 			continue
 		}
 
-		fmt.Printf("\n%s %s\n\n", internal.Logo, response)
+		fmt.Printf("\n%s %s\n\n", label, response)
 	}
 }
 
-func simpleInteractiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
+func simpleInteractiveMode(agentLoop *agent.AgentLoop, sessionKey, label string) {
 	reader := bufio.NewReader(os.Stdin)
 	for {
 		fmt.Print(fmt.Sprintf("%s You: ", internal.Logo))
@@ -163,6 +222,10 @@ func simpleInteractiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 			fmt.Println("Goodbye!")
 			return
 		}
+		if input == noToolsCommand {
+			fmt.Printf("%s\n\n", toggleNoTools(agentLoop, sessionKey))
+			continue
+		}
 
 		ctx := context.Background()
 		response, err := agentLoop.ProcessDirect(ctx, input, sessionKey)
@@ -171,6 +234,6 @@ func simpleInteractiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 			continue
 		}
 
-		fmt.Printf("\n%s %s\n\n", internal.Logo, response)
+		fmt.Printf("\n%s %s\n\n", label, response)
 	}
 }
@@ -0,0 +1,29 @@
+package agent
+
+import "fmt"
+
+// noToolsCommand switches pure-chat mode for the current session in
+// interactive mode.
+const noToolsCommand = ":notools"
+
+// noToolsSwitch is the part of the agent loop that :notools needs.
+type noToolsSwitch interface {
+	NoTools(sessionKey string) (on, enforced bool)
+	SetNoTools(sessionKey string, on bool) error
+}
+
+// toggleNoTools flips pure-chat mode for sessionKey and returns the line to
+// show the user.
+func toggleNoTools(s noToolsSwitch, sessionKey string) string {
+	on, enforced := s.NoTools(sessionKey)
+	if enforced {
+		return "Tools are off for this whole run (--no-tools or turn_profile config)."
+	}
+	if err := s.SetNoTools(sessionKey, !on); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if on {
+		return "Tools on: the agent may use tools again."
+	}
+	return "Tools off: the agent answers directly without running tools. Type :notools again to turn them back on."
+}
@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNoToolsSwitch struct {
+	sessions map[string]bool
+	enforced bool
+}
+
+func (s *fakeNoToolsSwitch) NoTools(sessionKey string) (bool, bool) {
+	return s.enforced || s.sessions[sessionKey], s.enforced
+}
+
+func (s *fakeNoToolsSwitch) SetNoTools(sessionKey string, on bool) error {
+	s.sessions[sessionKey] = on
+	return nil
+}
+
+func TestToggleNoTools(t *testing.T) {
+	s := &fakeNoToolsSwitch{sessions: map[string]bool{}}
+
+	assert.Contains(t, toggleNoTools(s, "cli:default"), "Tools off")
+	assert.True(t, s.sessions["cli:default"])
+	assert.False(t, s.sessions["cli:other"])
+
+	assert.Contains(t, toggleNoTools(s, "cli:default"), "Tools on")
+	assert.False(t, s.sessions["cli:default"])
+
+	s.enforced = true
+	assert.Contains(t, toggleNoTools(s, "cli:default"), "whole run")
+	assert.False(t, s.sessions["cli:default"])
+}
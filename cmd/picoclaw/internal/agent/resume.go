@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+// historyFileName is the file under the workspace that keeps the lines typed
+// in interactive mode, so arrow-up recall works across runs.
+const historyFileName = ".picoclaw_history"
+
+// sessionResetter is the part of the agent loop that --resume needs.
+type sessionResetter interface {
+	SessionHistory(key string) (agent.SessionInfo, []providers.Message, string, error)
+	ClearSession(ctx context.Context, key string) error
+}
+
+// cliSessionKey is the key a --session name is stored under. Session keys
+// are used as given; any other name gets an opaque key of its own, so each
+// name keeps a separate conversation instead of all of them sharing the one
+// the CLI channel routes to.
+func cliSessionKey(name string) string {
+	if session.IsExplicitSessionKey(name) {
+		return name
+	}
+	return session.BuildOpaqueSessionKey(name)
+}
+
+// startInteractiveSession readies the session key for an interactive run
+// and returns the line to show. With resume the stored conversation is
+// continued; otherwise it is cleared, so the run starts fresh.
+func startInteractiveSession(
+	ctx context.Context,
+	s sessionResetter,
+	name, key string,
+	resume bool,
+) (string, error) {
+	info, _, _, err := s.SessionHistory(key)
+	if err != nil && !errors.Is(err, agent.ErrSessionNotFound) {
+		return "", fmt.Errorf("error reading session %s: %w", name, err)
+	}
+	stored := err == nil && (info.MessageCount > 0 || info.HasSummary)
+
+	switch {
+	case resume && stored:
+		return fmt.Sprintf("Resuming %s (%d earlier messages).", name, info.MessageCount), nil
+	case resume:
+		return fmt.Sprintf("No earlier conversation in %s; starting fresh.", name), nil
+	case stored:
+		if err := s.ClearSession(ctx, key); err != nil {
+			return "", fmt.Errorf("error starting a new conversation in %s: %w", name, err)
+		}
+	}
+	return fmt.Sprintf("New conversation in %s (run with --resume to continue it next time).", name), nil
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type fakeSessionResetter struct {
+	counts  map[string]int
+	cleared []string
+}
+
+func (s *fakeSessionResetter) SessionHistory(key string) (agent.SessionInfo, []providers.Message, string, error) {
+	n, ok := s.counts[key]
+	if !ok {
+		return agent.SessionInfo{}, nil, "", agent.ErrSessionNotFound
+	}
+	return agent.SessionInfo{Key: key, MessageCount: n}, make([]providers.Message, n), "", nil
+}
+
+func (s *fakeSessionResetter) ClearSession(_ context.Context, key string) error {
+	s.cleared = append(s.cleared, key)
+	s.counts[key] = 0
+	return nil
+}
+
+func TestCLISessionKey(t *testing.T) {
+	assert.NotEqual(t, cliSessionKey("cli:default"), cliSessionKey("cli:work"))
+	assert.Equal(t, cliSessionKey("cli:default"), cliSessionKey("cli:default"))
+	assert.Equal(t, "agent:main:cli:default", cliSessionKey("agent:main:cli:default"))
+	assert.Equal(t, "sk_v1_abc", cliSessionKey("sk_v1_abc"))
+}
+
+func TestStartInteractiveSession_Resume(t *testing.T) {
+	key := cliSessionKey("cli:default")
+	s := &fakeSessionResetter{counts: map[string]int{key: 6}}
+
+	note, err := startInteractiveSession(context.Background(), s, "cli:default", key, true)
+	require.NoError(t, err)
+	assert.Equal(t, "Resuming cli:default (6 earlier messages).", note)
+	assert.Empty(t, s.cleared)
+}
+
+func TestStartInteractiveSession_ResumeWithoutHistory(t *testing.T) {
+	s := &fakeSessionResetter{counts: map[string]int{}}
+
+	note, err := startInteractiveSession(context.Background(), s, "cli:work", cliSessionKey("cli:work"), true)
+	require.NoError(t, err)
+	assert.Equal(t, "No earlier conversation in cli:work; starting fresh.", note)
+	assert.Empty(t, s.cleared)
+}
+
+func TestStartInteractiveSession_NewConversationClearsHistory(t *testing.T) {
+	key := cliSessionKey("cli:default")
+	s := &fakeSessionResetter{counts: map[string]int{key: 4}}
+
+	note, err := startInteractiveSession(context.Background(), s, "cli:default", key, false)
+	require.NoError(t, err)
+	assert.Contains(t, note, "New conversation in cli:default")
+	assert.Equal(t, []string{key}, s.cleared)
+
+	s = &fakeSessionResetter{counts: map[string]int{}}
+	_, err = startInteractiveSession(context.Background(), s, "cli:default", key, false)
+	require.NoError(t, err)
+	assert.Empty(t, s.cleared, "a session without history needs no clearing")
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// directProcessor is the part of the agent loop that --stdin-loop needs.
+type directProcessor interface {
+	ProcessDirect(ctx context.Context, content, sessionKey string) (string, error)
+}
+
+// stdinLoopOptions configures --stdin-loop.
+type stdinLoopOptions struct {
+	enabled bool
+	json    bool
+	// separateSessions gives every line its own session instead of sharing
+	// sessionKey, so answers do not depend on earlier lines.
+	separateSessions bool
+	sessionKey       string
+}
+
+// stdinLoopResult is one line of --json output.
+type stdinLoopResult struct {
+	Line     int    `json:"line"`
+	Input    string `json:"input"`
+	Response string `json:"response,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// runStdinLoop sends every non-empty line of in to the agent, in order, and
+// writes one result per line to out as soon as it is ready. A failed line is
+// reported and the loop moves on; the returned error counts the failures once
+// the input is exhausted. Plain-text errors go to errOut so out only carries
+// responses.
+func runStdinLoop(
+	ctx context.Context,
+	p directProcessor,
+	in io.Reader,
+	out, errOut io.Writer,
+	opts stdinLoopOptions,
+) error {
+	reader := bufio.NewReader(in)
+	lineNo, processed, failed := 0, 0, 0
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return fmt.Errorf("error reading stdin: %w", readErr)
+		}
+		if line != "" {
+			lineNo++
+			if input := strings.TrimSpace(line); input != "" {
+				processed++
+				if !processStdinLine(ctx, p, out, errOut, opts, lineNo, input) {
+					failed++
+				}
+			}
+		}
+		if readErr != nil || ctx.Err() != nil {
+			break
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d lines failed", failed, processed)
+	}
+	return nil
+}
+
+func processStdinLine(
+	ctx context.Context,
+	p directProcessor,
+	out, errOut io.Writer,
+	opts stdinLoopOptions,
+	lineNo int,
+	input string,
+) bool {
+	sessionKey := opts.sessionKey
+	if opts.separateSessions {
+		sessionKey = fmt.Sprintf("%s:line-%d", opts.sessionKey, lineNo)
+	}
+	response, err := p.ProcessDirect(ctx, input, sessionKey)
+
+	if opts.json {
+		result := stdinLoopResult{Line: lineNo, Input: input, Response: response}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		data, _ := json.Marshal(result)
+		_, _ = out.Write(append(data, '\n'))
+		return err == nil
+	}
+	if err != nil {
+		fmt.Fprintf(errOut, "Error on line %d: %v\n", lineNo, err)
+		return false
+	}
+	_, _ = io.WriteString(out, strings.TrimRight(response, "\n")+"\n")
+	return true
+}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDirectProcessor struct {
+	sessions []string
+}
+
+func (p *fakeDirectProcessor) ProcessDirect(_ context.Context, content, sessionKey string) (string, error) {
+	p.sessions = append(p.sessions, sessionKey)
+	if content == "fail" {
+		return "", errors.New("provider unavailable")
+	}
+	return "answer: " + content, nil
+}
+
+func TestRunStdinLoop_JSON(t *testing.T) {
+	p := &fakeDirectProcessor{}
+	var out, errOut bytes.Buffer
+	in := strings.NewReader("first\n\n  \nfail\nlast")
+
+	err := runStdinLoop(context.Background(), p, in, &out, &errOut, stdinLoopOptions{
+		enabled:    true,
+		json:       true,
+		sessionKey: "cli:batch",
+	})
+	require.EqualError(t, err, "1 of 3 lines failed")
+
+	assert.Equal(t,
+		`{"line":1,"input":"first","response":"answer: first"}`+"\n"+
+			`{"line":4,"input":"fail","error":"provider unavailable"}`+"\n"+
+			`{"line":5,"input":"last","response":"answer: last"}`+"\n",
+		out.String())
+	assert.Empty(t, errOut.String())
+	assert.Equal(t, []string{"cli:batch", "cli:batch", "cli:batch"}, p.sessions)
+}
+
+func TestRunStdinLoop_PlainTextAndSeparateSessions(t *testing.T) {
+	p := &fakeDirectProcessor{}
+	var out, errOut bytes.Buffer
+	in := strings.NewReader("one\nfail\ntwo\n")
+
+	err := runStdinLoop(context.Background(), p, in, &out, &errOut, stdinLoopOptions{
+		enabled:          true,
+		separateSessions: true,
+		sessionKey:       "cli:batch",
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, "answer: one\nanswer: two\n", out.String())
+	assert.Equal(t, "Error on line 2: provider unavailable\n", errOut.String())
+	assert.Equal(t, []string{"cli:batch:line-1", "cli:batch:line-2", "cli:batch:line-3"}, p.sessions)
+}
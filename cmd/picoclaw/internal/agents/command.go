@@ -0,0 +1,21 @@
+package agents
+
+import "github.com/spf13/cobra"
+
+func NewAgentsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agents",
+		Short: "Inspect configured agents and message routing",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		newListCommand(),
+		newTestCommand(),
+	)
+
+	return cmd
+}
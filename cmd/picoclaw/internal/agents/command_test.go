@@ -0,0 +1,93 @@
+package agents
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestNewAgentsCommand(t *testing.T) {
+	cmd := NewAgentsCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "agents", cmd.Use)
+	assert.True(t, cmd.HasSubCommands())
+
+	for _, name := range []string{"list", "test"} {
+		sub, _, err := cmd.Find([]string{name})
+		require.NoError(t, err)
+		assert.Equal(t, name, sub.Use)
+		assert.NotNil(t, sub.RunE)
+	}
+
+	test, _, err := cmd.Find([]string{"test"})
+	require.NoError(t, err)
+	for _, flag := range []string{"channel", "peer", "chat", "chat-type", "account", "space", "topic", "mentioned"} {
+		assert.NotNil(t, test.Flags().Lookup(flag), "missing --%s", flag)
+	}
+}
+
+func TestRouteTestOptions_InboundContext(t *testing.T) {
+	inbound := routeTestOptions{Channel: "telegram", Peer: "42", ChatType: "direct"}.inboundContext()
+	assert.Equal(t, "42", inbound.ChatID, "direct chats default the chat to the peer")
+	assert.Equal(t, "42", inbound.SenderID)
+
+	inbound = routeTestOptions{Channel: "telegram", Peer: "42", ChatType: "group"}.inboundContext()
+	assert.Empty(t, inbound.ChatID)
+}
+
+func TestPrintRouteResult(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+	cfg.Agents.List = []config.AgentConfig{{ID: "main", Default: true}, {ID: "support"}}
+	cfg.Agents.Dispatch = &config.DispatchConfig{
+		Rules: []config.DispatchRule{
+			{Name: "vip", Agent: "support", When: config.DispatchSelector{Channel: "telegram", Sender: "42"}},
+		},
+	}
+	agentLoop, closeLoop := internal.NewOfflineAgentLoop(cfg)
+	defer closeLoop()
+	resolver := agentLoop.GetRegistry()
+
+	var buf bytes.Buffer
+	route, rule := resolver.ExplainRoute(routeTestOptions{Channel: "telegram", Peer: "42"}.inboundContext())
+	printRouteResult(&buf, route, rule)
+	assert.Contains(t, buf.String(), "Agent:      support")
+	assert.Contains(t, buf.String(), "Matched by: dispatch.rule:vip")
+	assert.Contains(t, buf.String(), "Rule:       channel=telegram sender=42")
+
+	buf.Reset()
+	route, rule = resolver.ExplainRoute(routeTestOptions{Channel: "discord", Peer: "42"}.inboundContext())
+	printRouteResult(&buf, route, rule)
+	assert.Contains(t, buf.String(), "Agent:      main")
+	assert.Contains(t, buf.String(), "Matched by: default")
+	assert.NotContains(t, buf.String(), "Rule:")
+}
+
+func TestPrintAgentList(t *testing.T) {
+	rules := []config.DispatchRule{
+		{Name: "vip", Agent: "support", When: config.DispatchSelector{Channel: "telegram"}},
+		{Agent: "support"},
+	}
+	agents := []agentSummary{
+		{ID: "main", Default: true, Model: "gpt-4", Workspace: "/ws/main", Tools: []string{"exec", "read_file"}},
+		{ID: "support", Name: "Support", Model: "claude", Fallbacks: []string{"gpt-4"}, Rules: []string{"vip"}},
+	}
+
+	var buf bytes.Buffer
+	printAgentList(&buf, agents, rules)
+	out := buf.String()
+
+	assert.Contains(t, out, "main (default)")
+	assert.Contains(t, out, "  Tools:     exec, read_file")
+	assert.Contains(t, out, "  Rules:     - (plus unmatched messages)")
+	assert.Contains(t, out, "  Model:     claude (fallbacks: gpt-4)")
+	assert.Contains(t, out, "  1. vip -> support  when channel=telegram")
+	assert.Contains(t, out, "  2. (unnamed) -> support  when (no constraints, never matches)")
+}
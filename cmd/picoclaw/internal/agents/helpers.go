@@ -0,0 +1,165 @@
+package agents
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+// agentSummary is one entry of `picoclaw agents list`.
+type agentSummary struct {
+	ID        string
+	Name      string
+	Default   bool
+	Model     string
+	Fallbacks []string
+	Workspace string
+	Tools     []string
+	Rules     []string
+}
+
+// routeTestOptions describes the synthetic inbound message of
+// `picoclaw agents test`.
+type routeTestOptions struct {
+	Channel   string
+	Peer      string
+	Account   string
+	Chat      string
+	ChatType  string
+	Space     string
+	SpaceType string
+	Topic     string
+	Mentioned bool
+}
+
+func (o routeTestOptions) inboundContext() bus.InboundContext {
+	chatType := strings.ToLower(strings.TrimSpace(o.ChatType))
+	chatID := o.Chat
+	if chatID == "" && (chatType == "" || chatType == "direct") {
+		chatID = o.Peer
+	}
+	return bus.InboundContext{
+		Channel:   o.Channel,
+		Account:   o.Account,
+		ChatID:    chatID,
+		ChatType:  chatType,
+		TopicID:   o.Topic,
+		SpaceID:   o.Space,
+		SpaceType: o.SpaceType,
+		SenderID:  o.Peer,
+		Mentioned: o.Mentioned,
+	}
+}
+
+// summarizeAgents collects the registered agents together with the dispatch
+// rules that target each of them.
+func summarizeAgents(registry *agent.AgentRegistry, rules []config.DispatchRule) []agentSummary {
+	defaultID := ""
+	if defaultAgent := registry.GetDefaultAgent(); defaultAgent != nil {
+		defaultID = defaultAgent.ID
+	}
+
+	instances := registry.Agents()
+	summaries := make([]agentSummary, 0, len(instances))
+	for _, instance := range instances {
+		summary := agentSummary{
+			ID:        instance.ID,
+			Name:      instance.Name,
+			Default:   instance.ID == defaultID,
+			Model:     instance.Model,
+			Fallbacks: instance.Fallbacks,
+			Workspace: instance.Workspace,
+		}
+		if instance.Tools != nil {
+			summary.Tools = instance.Tools.List()
+		}
+		for _, rule := range rules {
+			if routing.NormalizeAgentID(rule.Agent) == instance.ID {
+				summary.Rules = append(summary.Rules, ruleLabel(rule))
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// ruleLabel names a dispatch rule the way route results report it.
+func ruleLabel(rule config.DispatchRule) string {
+	name := strings.TrimSpace(rule.Name)
+	if name == "" {
+		name = "(unnamed)"
+	}
+	return name
+}
+
+func printAgentList(w io.Writer, agents []agentSummary, rules []config.DispatchRule) {
+	if len(agents) == 0 {
+		fmt.Fprintln(w, "No agents configured.")
+		return
+	}
+
+	for i, a := range agents {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		header := a.ID
+		if a.Default {
+			header += " (default)"
+		}
+		fmt.Fprintln(w, header)
+		if a.Name != "" && a.Name != a.ID {
+			fmt.Fprintf(w, "  Name:      %s\n", a.Name)
+		}
+		model := a.Model
+		if model == "" {
+			model = "-"
+		}
+		if len(a.Fallbacks) > 0 {
+			model += " (fallbacks: " + strings.Join(a.Fallbacks, ", ") + ")"
+		}
+		fmt.Fprintf(w, "  Model:     %s\n", model)
+		fmt.Fprintf(w, "  Workspace: %s\n", a.Workspace)
+		fmt.Fprintf(w, "  Tools:     %s\n", joinOrDash(a.Tools))
+		routes := joinOrDash(a.Rules)
+		if a.Default {
+			routes += " (plus unmatched messages)"
+		}
+		fmt.Fprintf(w, "  Rules:     %s\n", routes)
+	}
+
+	if len(rules) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nDispatch rules (first match wins):")
+	for i, rule := range rules {
+		when := routing.DescribeSelector(rule.When)
+		if when == "" {
+			when = "(no constraints, never matches)"
+		}
+		fmt.Fprintf(w, "  %d. %s -> %s  when %s\n", i+1, ruleLabel(rule), routing.NormalizeAgentID(rule.Agent), when)
+	}
+}
+
+func printRouteResult(w io.Writer, route routing.ResolvedRoute, rule *config.DispatchRule) {
+	fmt.Fprintf(w, "Agent:      %s\n", route.AgentID)
+	fmt.Fprintf(w, "Matched by: %s\n", route.MatchedBy)
+	if rule != nil {
+		fmt.Fprintf(w, "Rule:       %s\n", routing.DescribeSelector(rule.When))
+		if target := routing.NormalizeAgentID(rule.Agent); target != route.AgentID {
+			fmt.Fprintf(w, "Note:       rule targets unknown agent %q, using the default agent\n", target)
+		}
+	}
+	fmt.Fprintf(w, "Session:    %s\n", joinOrDash(route.SessionPolicy.Dimensions))
+}
+
+func joinOrDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,34 @@
+package agents
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+)
+
+func newListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List agents with their model, tools, and dispatch rules",
+		Example: `picoclaw agents list`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+
+			agentLoop, closeLoop := internal.NewOfflineAgentLoop(cfg)
+			defer closeLoop()
+
+			registry := agentLoop.GetRegistry()
+			rules := registry.DispatchRules()
+			printAgentList(cmd.OutOrStdout(), summarizeAgents(registry, rules), rules)
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,47 @@
+package agents
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+)
+
+func newTestCommand() *cobra.Command {
+	var opts routeTestOptions
+
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Show which agent an inbound message would be routed to",
+		Example: `picoclaw agents test --channel telegram --peer 123456
+picoclaw agents test --channel slack --chat C0123 --chat-type channel --space T001 --mentioned`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+
+			agentLoop, closeLoop := internal.NewOfflineAgentLoop(cfg)
+			defer closeLoop()
+
+			route, rule := agentLoop.GetRegistry().ExplainRoute(opts.inboundContext())
+			printRouteResult(cmd.OutOrStdout(), route, rule)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Channel, "channel", "", "Channel the message arrives on (e.g. telegram)")
+	cmd.Flags().StringVar(&opts.Peer, "peer", "", "Sender ID of the message")
+	cmd.Flags().StringVar(&opts.Account, "account", "", "Bot account on the channel")
+	cmd.Flags().StringVar(&opts.Chat, "chat", "", "Chat ID (defaults to the peer for direct chats)")
+	cmd.Flags().StringVar(&opts.ChatType, "chat-type", "direct", "Chat type: direct, group, or channel")
+	cmd.Flags().StringVar(&opts.Space, "space", "", "Space ID (guild, team, workspace)")
+	cmd.Flags().StringVar(&opts.SpaceType, "space-type", "", "Space type (defaults to \"space\")")
+	cmd.Flags().StringVar(&opts.Topic, "topic", "", "Topic or thread ID")
+	cmd.Flags().BoolVar(&opts.Mentioned, "mentioned", false, "Treat the bot as mentioned")
+	_ = cmd.MarkFlagRequired("channel")
+
+	return cmd
+}
@@ -17,16 +17,35 @@ import (
 )
 
 const (
-	supportedProvidersMsg = "supported providers: openai, anthropic, google-antigravity, antigravity"
+	supportedProvidersMsg = "supported providers: openai, anthropic, google, gemini, google-antigravity, antigravity"
 	defaultAnthropicModel = "claude-sonnet-4.6"
+	defaultGeminiModel    = "gemini-3-flash-preview"
 )
 
+// Login runs the default interactive login flow for provider, as
+// `picoclaw auth login --provider <name>` would.
+func Login(provider string) error {
+	return authLoginCmd(provider, false, false, false)
+}
+
+// SupportsLogin reports whether provider has a login flow.
+func SupportsLogin(provider string) bool {
+	switch provider {
+	case "openai", "anthropic", "google", "gemini", "google-antigravity", "antigravity":
+		return true
+	default:
+		return false
+	}
+}
+
 func authLoginCmd(provider string, useDeviceCode bool, useOauth bool, noBrowser bool) error {
 	switch provider {
 	case "openai":
 		return authLoginOpenAI(useDeviceCode, noBrowser)
 	case "anthropic":
 		return authLoginAnthropic(useOauth)
+	case "google", "gemini":
+		return authLoginGoogle(useDeviceCode, noBrowser)
 	case "google-antigravity", "antigravity":
 		return authLoginGoogleAntigravity(noBrowser)
 	default:
@@ -92,6 +111,75 @@ func authLoginOpenAI(useDeviceCode bool, noBrowser bool) error {
 	return nil
 }
 
+func authLoginGoogle(useDeviceCode bool, noBrowser bool) error {
+	cfg := auth.GoogleOAuthConfig()
+
+	var cred *auth.AuthCredential
+	var err error
+
+	if useDeviceCode {
+		cred, err = auth.LoginDeviceCode(cfg)
+	} else {
+		cred, err = auth.LoginBrowserWithOptions(cfg, auth.LoginBrowserOptions{NoBrowser: noBrowser})
+	}
+
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+
+	// Requests made with user credentials are billed to a quota project.
+	cred.ProjectID = strings.TrimSpace(os.Getenv("GOOGLE_CLOUD_PROJECT"))
+
+	email, err := fetchGoogleUserEmail(cred.AccessToken)
+	if err != nil {
+		fmt.Printf("Warning: could not fetch email: %v\n", err)
+	} else {
+		cred.Email = email
+		fmt.Printf("Email: %s\n", email)
+	}
+
+	if err = auth.SetCredential("google", cred); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+
+	appCfg, err := internal.LoadConfig()
+	if err == nil {
+		// Update or add gemini in ModelList
+		foundGemini := false
+		for i := range appCfg.ModelList {
+			if isGeminiModel(appCfg.ModelList[i]) {
+				appCfg.ModelList[i].AuthMethod = "oauth"
+				foundGemini = true
+				break
+			}
+		}
+
+		// If no gemini in ModelList, add it
+		if !foundGemini {
+			appCfg.ModelList = append(appCfg.ModelList, &config.ModelConfig{
+				ModelName:  defaultGeminiModel,
+				Model:      "gemini/" + defaultGeminiModel,
+				AuthMethod: "oauth",
+			})
+		}
+
+		// Update default model to use Gemini
+		appCfg.Agents.Defaults.ModelName = defaultGeminiModel
+
+		if err = config.SaveConfig(internal.GetConfigPath(), appCfg); err != nil {
+			return fmt.Errorf("could not update config: %w", err)
+		}
+	}
+
+	fmt.Println("Login successful!")
+	if cred.ProjectID != "" {
+		fmt.Printf("Quota project: %s\n", cred.ProjectID)
+	}
+	fmt.Printf("Default model set to: %s\n", defaultGeminiModel)
+
+	return nil
+}
+
 func authLoginGoogleAntigravity(noBrowser bool) error {
 	cfg := auth.GoogleAntigravityOAuthConfig()
 
@@ -349,6 +437,10 @@ func authLogoutCmd(provider string) error {
 					if isAnthropicModel(appCfg.ModelList[i]) {
 						appCfg.ModelList[i].AuthMethod = ""
 					}
+				case "google", "gemini":
+					if isGeminiModel(appCfg.ModelList[i]) {
+						appCfg.ModelList[i].AuthMethod = ""
+					}
 				case "google-antigravity", "antigravity":
 					if isAntigravityModel(appCfg.ModelList[i]) {
 						appCfg.ModelList[i].AuthMethod = ""
@@ -490,6 +582,12 @@ func isAntigravityModel(modelCfg *config.ModelConfig) bool {
 	return protocol == "antigravity" || protocol == "google-antigravity"
 }
 
+// isGeminiModel checks if a model config belongs to the Gemini provider.
+func isGeminiModel(modelCfg *config.ModelConfig) bool {
+	protocol, _ := providers.ExtractProtocol(modelCfg)
+	return protocol == "gemini"
+}
+
 // isOpenAIModel checks if a model config belongs to the OpenAI provider.
 func isOpenAIModel(modelCfg *config.ModelConfig) bool {
 	protocol, _ := providers.ExtractProtocol(modelCfg)
@@ -0,0 +1,58 @@
+package backup
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewExportCommand() *cobra.Command {
+	var opts exportOptions
+
+	cmd := &cobra.Command{
+		Use:   "export <archive.tar.gz>",
+		Short: "Export config, skills, cron jobs and workspace templates to an archive",
+		Args:  cobra.ExactArgs(1),
+		Example: `  picoclaw export picoclaw-backup.tar.gz
+  picoclaw export --no-secrets shared.tar.gz`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := exportArchive(args[0], opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✓ Exported %d files to %s\n", len(m.Files), args[0])
+			if m.Secrets {
+				fmt.Fprintln(cmd.OutOrStdout(),
+					"  The archive contains API keys and auth tokens; store it securely or use --no-secrets.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.noSecrets, "no-secrets", false,
+		"Leave out API keys, channel tokens and the auth store")
+
+	return cmd
+}
+
+func NewImportCommand() *cobra.Command {
+	var opts importOptions
+
+	cmd := &cobra.Command{
+		Use:   "import <archive.tar.gz>",
+		Short: "Restore an archive created by picoclaw export",
+		Args:  cobra.ExactArgs(1),
+		Example: `  picoclaw import picoclaw-backup.tar.gz
+  picoclaw import --force picoclaw-backup.tar.gz`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.in = cmd.InOrStdin()
+			opts.out = cmd.OutOrStdout()
+			return importArchive(args[0], opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.force, "force", false,
+		"Overwrite existing files without prompting")
+
+	return cmd
+}
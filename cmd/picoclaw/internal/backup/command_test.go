@@ -0,0 +1,38 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExportCommand(t *testing.T) {
+	cmd := NewExportCommand()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "export <archive.tar.gz>", cmd.Use)
+	assert.NotNil(t, cmd.RunE)
+	assert.False(t, cmd.HasSubCommands())
+
+	flag := cmd.Flags().Lookup("no-secrets")
+	require.NotNil(t, flag)
+	assert.Equal(t, "false", flag.DefValue)
+
+	assert.Error(t, cmd.Args(cmd, nil))
+}
+
+func TestNewImportCommand(t *testing.T) {
+	cmd := NewImportCommand()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "import <archive.tar.gz>", cmd.Use)
+	assert.NotNil(t, cmd.RunE)
+	assert.False(t, cmd.HasSubCommands())
+
+	flag := cmd.Flags().Lookup("force")
+	require.NotNil(t, flag)
+	assert.Equal(t, "false", flag.DefValue)
+
+	assert.Error(t, cmd.Args(cmd, nil))
+}
@@ -0,0 +1,513 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+const (
+	archiveFormatVersion = 1
+	manifestName         = "manifest.json"
+
+	// maxArchiveFileSize rejects oversized entries before they are staged.
+	maxArchiveFileSize = 256 << 20
+
+	// restorePermMask caps the permissions of restored workspace files like a
+	// 022 umask; setuid, setgid and sticky bits are never restored.
+	restorePermMask os.FileMode = 0o755
+
+	archiveConfig    = "config/config.json"
+	archiveSecurity  = "config/" + config.SecurityConfigFile
+	archiveAuth      = "auth/auth.json"
+	archiveWorkspace = "workspace/"
+)
+
+// workspaceTemplateFiles are the workspace files that define an agent's
+// persona and instructions; they are exported alongside skills and cron jobs.
+var workspaceTemplateFiles = []string{
+	"AGENT.md",
+	"AGENTS.md",
+	"SOUL.md",
+	"USER.md",
+	"IDENTITY.md",
+	"TOOLS.md",
+	"HEARTBEAT.md",
+	"memory/MEMORY.md",
+	"cron/jobs.json",
+}
+
+// manifest is written as the last archive entry. An archive without it, or
+// whose files do not match it, is treated as truncated or corrupt.
+type manifest struct {
+	FormatVersion   int            `json:"format_version"`
+	PicoclawVersion string         `json:"picoclaw_version"`
+	CreatedAt       time.Time      `json:"created_at"`
+	Secrets         bool           `json:"secrets"`
+	Files           []manifestFile `json:"files"`
+}
+
+type manifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Mode holds the file's permission bits. Archives written before it was
+	// recorded leave it zero.
+	Mode os.FileMode `json:"mode,omitempty"`
+}
+
+type exportOptions struct {
+	noSecrets bool
+}
+
+type importOptions struct {
+	force bool
+	in    io.Reader
+	out   io.Writer
+}
+
+// archiveEntry is one file to export, read either from disk or from data.
+// Entries read from disk keep the file's permission bits.
+type archiveEntry struct {
+	name string
+	src  string
+	data []byte
+}
+
+func exportArchive(archivePath string, opts exportOptions) (*manifest, error) {
+	entries, err := collectExportEntries(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &manifest{
+		FormatVersion:   archiveFormatVersion,
+		PicoclawVersion: config.GetVersion(),
+		CreatedAt:       time.Now().UTC(),
+		Secrets:         !opts.noSecrets,
+	}
+
+	// Write to a temp file first so a failed export never leaves a partial
+	// archive at the requested path.
+	if dir := filepath.Dir(archivePath); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(archivePath), ".picoclaw-export-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		data, mode := e.data, os.FileMode(0o600)
+		if data == nil {
+			info, err := os.Stat(e.src)
+			if err == nil {
+				mode = info.Mode().Perm()
+				data, err = os.ReadFile(e.src)
+			}
+			if err != nil {
+				tmp.Close()
+				return nil, fmt.Errorf("read %s: %w", e.src, err)
+			}
+		}
+		if err := writeTarFile(tw, e.name, data); err != nil {
+			tmp.Close()
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		m.Files = append(m.Files, manifestFile{
+			Path:   e.name,
+			Size:   int64(len(data)),
+			SHA256: hex.EncodeToString(sum[:]),
+			Mode:   mode,
+		})
+	}
+
+	manifestData, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := writeTarFile(tw, manifestName, manifestData); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), archivePath); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+func collectExportEntries(opts exportOptions) ([]archiveEntry, error) {
+	configPath := internal.GetConfigPath()
+	if _, err := os.Stat(configPath); err != nil {
+		return nil, fmt.Errorf("config not found at %s: run 'picoclaw onboard' first", configPath)
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	var entries []archiveEntry
+	if opts.noSecrets {
+		// Re-serialize through SaveConfig: secure fields are written to
+		// .security.yml, so config.json comes out without any credentials,
+		// including ones that were inlined by hand.
+		stripped, err := configWithoutSecrets(cfg)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, archiveEntry{name: archiveConfig, data: stripped})
+	} else {
+		entries = append(entries, archiveEntry{name: archiveConfig, src: configPath})
+		securityPath := filepath.Join(filepath.Dir(configPath), config.SecurityConfigFile)
+		if fileExists(securityPath) {
+			entries = append(entries, archiveEntry{name: archiveSecurity, src: securityPath})
+		}
+		authPath := filepath.Join(internal.GetPicoclawHome(), "auth.json")
+		if fileExists(authPath) {
+			entries = append(entries, archiveEntry{name: archiveAuth, src: authPath})
+		}
+	}
+
+	workspace := cfg.WorkspacePath()
+	for _, rel := range workspaceTemplateFiles {
+		src := filepath.Join(workspace, filepath.FromSlash(rel))
+		if fileExists(src) {
+			entries = append(entries, archiveEntry{name: archiveWorkspace + rel, src: src})
+		}
+	}
+
+	skillsDir := filepath.Join(workspace, "skills")
+	err = filepath.WalkDir(skillsDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		// Symlinks and special files are skipped: they may point outside
+		// the workspace and cannot be restored portably.
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(workspace, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, archiveEntry{name: archiveWorkspace + filepath.ToSlash(rel), src: p})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collect skills: %w", err)
+	}
+	return entries, nil
+}
+
+func configWithoutSecrets(cfg *config.Config) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "picoclaw-export-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	if err := config.SaveConfig(path, cfg); err != nil {
+		return nil, fmt.Errorf("serialize config: %w", err)
+	}
+	return os.ReadFile(path)
+}
+
+// readArchive extracts the archive into stageDir and verifies it against
+// its manifest. Nothing outside stageDir is touched.
+func readArchive(archivePath, stageDir string) (*manifest, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, corruptArchiveError(err)
+	}
+	defer gz.Close()
+
+	var manifestData []byte
+	staged := map[string]manifestFile{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, corruptArchiveError(err)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("archive entry %q is not a regular file", hdr.Name)
+		}
+		if hdr.Size > maxArchiveFileSize {
+			return nil, fmt.Errorf("archive entry %q is too large (%d bytes)", hdr.Name, hdr.Size)
+		}
+
+		if hdr.Name == manifestName {
+			if manifestData, err = io.ReadAll(tr); err != nil {
+				return nil, corruptArchiveError(err)
+			}
+			continue
+		}
+		if err := validateArchivePath(hdr.Name); err != nil {
+			return nil, err
+		}
+		if _, dup := staged[hdr.Name]; dup {
+			return nil, fmt.Errorf("archive contains %q more than once", hdr.Name)
+		}
+
+		dst := filepath.Join(stageDir, filepath.FromSlash(hdr.Name))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+			return nil, err
+		}
+		out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		n, err := io.Copy(io.MultiWriter(out, h), tr)
+		out.Close()
+		if err != nil {
+			return nil, corruptArchiveError(err)
+		}
+		staged[hdr.Name] = manifestFile{Path: hdr.Name, Size: n, SHA256: hex.EncodeToString(h.Sum(nil))}
+	}
+
+	if manifestData == nil {
+		return nil, fmt.Errorf("archive has no %s: it is incomplete or was not created by 'picoclaw export'", manifestName)
+	}
+	var m manifest
+	if err := json.Unmarshal(manifestData, &m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", manifestName, err)
+	}
+	if m.FormatVersion != archiveFormatVersion {
+		return nil, fmt.Errorf("unsupported archive format version %d (expected %d)", m.FormatVersion, archiveFormatVersion)
+	}
+	if len(m.Files) != len(staged) {
+		return nil, fmt.Errorf("archive lists %d files but contains %d: it is incomplete or corrupt", len(m.Files), len(staged))
+	}
+	for _, want := range m.Files {
+		got, ok := staged[want.Path]
+		if !ok {
+			return nil, fmt.Errorf("archive is missing %s", want.Path)
+		}
+		if got.Size != want.Size || got.SHA256 != want.SHA256 {
+			return nil, fmt.Errorf("checksum mismatch for %s: archive is corrupt", want.Path)
+		}
+	}
+	return &m, nil
+}
+
+func corruptArchiveError(err error) error {
+	return fmt.Errorf("archive is incomplete or corrupt: %w", err)
+}
+
+// validateArchivePath accepts only clean relative paths under the known
+// top-level directories, so a crafted archive cannot write elsewhere.
+func validateArchivePath(name string) error {
+	clean := path.Clean(name)
+	if clean != name || path.IsAbs(name) || strings.HasPrefix(clean, "../") || strings.Contains(name, `\`) {
+		return fmt.Errorf("archive entry %q has an unsafe path", name)
+	}
+	switch {
+	case name == archiveConfig, name == archiveSecurity, name == archiveAuth:
+		return nil
+	case strings.HasPrefix(name, archiveWorkspace) && len(name) > len(archiveWorkspace):
+		return nil
+	}
+	return fmt.Errorf("archive entry %q is not part of a picoclaw export", name)
+}
+
+type restorePlan struct {
+	src, dst string
+	perm     os.FileMode
+}
+
+func importArchive(archivePath string, opts importOptions) error {
+	stageDir, err := os.MkdirTemp("", "picoclaw-import-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stageDir)
+
+	m, err := readArchive(archivePath, stageDir)
+	if err != nil {
+		return err
+	}
+
+	plans, err := planRestore(m, stageDir)
+	if err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(opts.in)
+	var restored, skipped, unchanged int
+	for _, p := range plans {
+		data, err := os.ReadFile(p.src)
+		if err != nil {
+			return err
+		}
+		if existing, err := os.ReadFile(p.dst); err == nil {
+			if bytes.Equal(existing, data) {
+				unchanged++
+				continue
+			}
+			if !opts.force {
+				ok, err := confirmOverwrite(in, opts.out, p.dst)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					skipped++
+					continue
+				}
+			}
+		}
+		if err := fileutil.WriteFileAtomic(p.dst, data, p.perm); err != nil {
+			return fmt.Errorf("restore %s: %w", p.dst, err)
+		}
+		restored++
+	}
+
+	fmt.Fprintf(opts.out, "Imported %s (exported %s by picoclaw %s)\n",
+		archivePath, m.CreatedAt.Local().Format("2006-01-02 15:04"), m.PicoclawVersion)
+	fmt.Fprintf(opts.out, "  %d restored, %d unchanged, %d skipped\n", restored, unchanged, skipped)
+	if !m.Secrets {
+		fmt.Fprintln(opts.out, "  Archive was exported with --no-secrets: re-enter API keys and run 'picoclaw auth login' as needed.")
+	}
+	return nil
+}
+
+// planRestore maps staged archive files to their destinations. Workspace
+// files go to the workspace named by the imported config when it has one,
+// otherwise to the current config's workspace.
+func planRestore(m *manifest, stageDir string) ([]restorePlan, error) {
+	configPath := internal.GetConfigPath()
+	home := internal.GetPicoclawHome()
+
+	var cfg *config.Config
+	var err error
+	if stagedConfig := filepath.Join(stageDir, filepath.FromSlash(archiveConfig)); fileExists(stagedConfig) {
+		cfg, err = config.LoadConfig(stagedConfig)
+		if err != nil {
+			return nil, fmt.Errorf("archive config is invalid: %w", err)
+		}
+	} else if cfg, err = config.LoadConfig(configPath); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	workspace := cfg.WorkspacePath()
+
+	files := slices.Clone(m.Files)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	plans := make([]restorePlan, 0, len(files))
+	for _, f := range files {
+		name := f.Path
+		p := restorePlan{src: filepath.Join(stageDir, filepath.FromSlash(name)), perm: 0o600}
+		switch {
+		case name == archiveConfig:
+			p.dst = configPath
+		case name == archiveSecurity:
+			p.dst = filepath.Join(filepath.Dir(configPath), config.SecurityConfigFile)
+		case name == archiveAuth:
+			p.dst = filepath.Join(home, "auth.json")
+		default:
+			p.dst = filepath.Join(workspace, filepath.FromSlash(strings.TrimPrefix(name, archiveWorkspace)))
+			p.perm = workspaceFilePerm(f.Mode)
+		}
+		plans = append(plans, p)
+	}
+	return plans, nil
+}
+
+// workspaceFilePerm returns the permissions to restore a workspace file
+// with: the recorded mode capped by restorePermMask and always readable and
+// writable by the owner. Archives without recorded modes restore as 0644.
+func workspaceFilePerm(mode os.FileMode) os.FileMode {
+	if mode == 0 {
+		return 0o644
+	}
+	return mode&restorePermMask | 0o600
+}
+
+func confirmOverwrite(r *bufio.Reader, w io.Writer, path string) (bool, error) {
+	if _, err := fmt.Fprintf(w, "%s already exists and differs. Overwrite? [y/N]: ", path); err != nil {
+		return false, err
+	}
+	answer, err := r.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
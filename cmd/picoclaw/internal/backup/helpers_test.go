@@ -0,0 +1,191 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const testAPIKey = "sk-test-secret-123"
+
+// setupHome creates a picoclaw home with a config holding an API key, an
+// auth store, a skill, a cron store and a template file.
+func setupHome(t *testing.T) (home, workspace string) {
+	t.Helper()
+	home = t.TempDir()
+	workspace = filepath.Join(home, "workspace")
+	configPath := filepath.Join(home, "config.json")
+	t.Setenv(config.EnvHome, home)
+	t.Setenv(config.EnvConfig, configPath)
+
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = workspace
+	cfg.ModelList[0].SetAPIKey(testAPIKey)
+	require.NoError(t, config.SaveConfig(configPath, cfg))
+
+	writeFile(t, filepath.Join(home, "auth.json"), `{"credentials":{}}`)
+	writeFile(t, filepath.Join(workspace, "SOUL.md"), "# Soul\nBe kind.")
+	writeFile(t, filepath.Join(workspace, "cron", "jobs.json"), `{"version":1,"jobs":[]}`)
+	writeFile(t, filepath.Join(workspace, "skills", "weather", "SKILL.md"), "---\nname: weather\n---\n")
+	writeFile(t, filepath.Join(workspace, "sessions", "chat.json"), "not exported")
+	return home, workspace
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+// switchHome points the CLI at a fresh, empty home for import.
+func switchHome(t *testing.T) (home, workspace string) {
+	t.Helper()
+	home = t.TempDir()
+	t.Setenv(config.EnvHome, home)
+	t.Setenv(config.EnvConfig, filepath.Join(home, "config.json"))
+	return home, filepath.Join(home, "workspace")
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	_, srcWorkspace := setupHome(t)
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+
+	m, err := exportArchive(archive, exportOptions{})
+	require.NoError(t, err)
+	assert.True(t, m.Secrets)
+
+	names := make([]string, 0, len(m.Files))
+	for _, f := range m.Files {
+		names = append(names, f.Path)
+	}
+	assert.Contains(t, names, archiveSecurity)
+	assert.Contains(t, names, archiveAuth)
+	assert.Contains(t, names, "workspace/skills/weather/SKILL.md")
+	assert.NotContains(t, names, "workspace/sessions/chat.json")
+
+	home, _ := switchHome(t)
+	var out bytes.Buffer
+	require.NoError(t, importArchive(archive, importOptions{in: strings.NewReader(""), out: &out}))
+
+	// The exported config names the source workspace, so files land there.
+	data, err := os.ReadFile(filepath.Join(srcWorkspace, "skills", "weather", "SKILL.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "name: weather")
+	assert.FileExists(t, filepath.Join(home, "auth.json"))
+
+	cfg, err := config.LoadConfig(filepath.Join(home, "config.json"))
+	require.NoError(t, err)
+	assert.Equal(t, testAPIKey, cfg.ModelList[0].APIKey())
+}
+
+func TestExportImport_RestoresFileModes(t *testing.T) {
+	_, srcWorkspace := setupHome(t)
+	script := filepath.Join(srcWorkspace, "skills", "weather", "fetch.sh")
+	private := filepath.Join(srcWorkspace, "skills", "weather", "notes.md")
+	writeFile(t, script, "#!/bin/sh\necho sunny\n")
+	writeFile(t, private, "private notes")
+	require.NoError(t, os.Chmod(script, 0o775|os.ModeSetuid))
+	require.NoError(t, os.Chmod(private, 0o600))
+
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	_, err := exportArchive(archive, exportOptions{})
+	require.NoError(t, err)
+	require.NoError(t, os.RemoveAll(srcWorkspace))
+
+	switchHome(t)
+	var out bytes.Buffer
+	require.NoError(t, importArchive(archive, importOptions{in: strings.NewReader(""), out: &out}))
+
+	soul := filepath.Join(srcWorkspace, "SOUL.md")
+	for path, want := range map[string]os.FileMode{script: 0o755, private: 0o600, soul: 0o644} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, info.Mode(), path)
+	}
+}
+
+func TestExport_NoSecretsStripsCredentials(t *testing.T) {
+	setupHome(t)
+	archive := filepath.Join(t.TempDir(), "shared.tar.gz")
+
+	m, err := exportArchive(archive, exportOptions{noSecrets: true})
+	require.NoError(t, err)
+	assert.False(t, m.Secrets)
+
+	stage := t.TempDir()
+	_, err = readArchive(archive, stage)
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, filepath.Join(stage, "config", config.SecurityConfigFile))
+	assert.NoFileExists(t, filepath.Join(stage, "auth", "auth.json"))
+	cfgData, err := os.ReadFile(filepath.Join(stage, "config", "config.json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(cfgData), testAPIKey)
+}
+
+func TestImport_RejectsTruncatedArchive(t *testing.T) {
+	setupHome(t)
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	_, err := exportArchive(archive, exportOptions{})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(archive)
+	require.NoError(t, err)
+	truncated := filepath.Join(t.TempDir(), "truncated.tar.gz")
+	require.NoError(t, os.WriteFile(truncated, data[:len(data)/2], 0o600))
+
+	home, _ := switchHome(t)
+	err = importArchive(truncated, importOptions{force: true, in: strings.NewReader(""), out: &bytes.Buffer{}})
+	require.Error(t, err)
+	assert.NoFileExists(t, filepath.Join(home, "config.json"), "nothing may be restored from a partial archive")
+}
+
+func TestReadArchive_RejectsUnsafeAndUnlistedEntries(t *testing.T) {
+	for name, entry := range map[string]string{
+		"traversal":  "workspace/../../etc/passwd",
+		"absolute":   "/etc/passwd",
+		"unexpected": "sessions/chat.json",
+	} {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		require.NoError(t, writeTarFile(tw, entry, []byte("x")))
+		require.NoError(t, writeTarFile(tw, manifestName, []byte(`{"format_version":1}`)))
+		require.NoError(t, tw.Close())
+		require.NoError(t, gz.Close())
+
+		archive := filepath.Join(t.TempDir(), "bad.tar.gz")
+		require.NoError(t, os.WriteFile(archive, buf.Bytes(), 0o600))
+		_, err := readArchive(archive, t.TempDir())
+		assert.Error(t, err, name)
+	}
+}
+
+func TestImport_PromptsBeforeOverwriting(t *testing.T) {
+	_, workspace := setupHome(t)
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	_, err := exportArchive(archive, exportOptions{})
+	require.NoError(t, err)
+
+	soul := filepath.Join(workspace, "SOUL.md")
+	writeFile(t, soul, "# Soul\nLocal edits.")
+
+	var out bytes.Buffer
+	require.NoError(t, importArchive(archive, importOptions{in: strings.NewReader("n\n"), out: &out}))
+	assert.Contains(t, out.String(), "Overwrite? [y/N]")
+	data, _ := os.ReadFile(soul)
+	assert.Equal(t, "# Soul\nLocal edits.", string(data))
+
+	require.NoError(t, importArchive(archive, importOptions{force: true, in: strings.NewReader(""), out: &out}))
+	data, _ = os.ReadFile(soul)
+	assert.Equal(t, "# Soul\nBe kind.", string(data))
+}
@@ -0,0 +1,113 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func NewBenchCommand() *cobra.Command {
+	var (
+		opts        benchOptions
+		models      string
+		promptsFile string
+		prices      []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Compare models on a fixed set of prompts",
+		Long: `Run every prompt against every model and report latency, token usage and
+cost side by side. Answers are not scored; this measures how fast and how
+expensive each model is on your workload.
+
+The prompts file holds one prompt per line. Blank lines and lines starting
+with # are skipped. Cost is shown for models given a --price.`,
+		Example: `  picoclaw bench --models gpt-5.2,claude-sonnet-4.6 --prompts prompts.txt
+  picoclaw bench --models fast,smart --prompts prompts.txt --concurrency 4 --json
+  picoclaw bench --models fast --prompts prompts.txt --price fast=0.15/0.60`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts.models = splitModels(models)
+			if len(opts.models) == 0 {
+				return fmt.Errorf("--models needs at least one model name")
+			}
+			if opts.concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1")
+			}
+			parsed, err := parsePrices(prices)
+			if err != nil {
+				return err
+			}
+			opts.prices = parsed
+			opts.prompts, err = loadPrompts(promptsFile)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			opts.maxTokens = cfg.Agents.Defaults.MaxTokens
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			reports := runBench(ctx, opts, func(name string) (providers.LLMProvider, string, error) {
+				mc, err := cfg.GetModelConfig(name)
+				if err != nil {
+					return nil, "", err
+				}
+				return providers.CreateProviderFromConfig(mc)
+			})
+			if opts.json {
+				return writeBenchJSON(cmd.OutOrStdout(), reports, len(opts.prompts))
+			}
+			printBenchTable(cmd.OutOrStdout(), reports, len(opts.prompts))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&models, "models", "m", "",
+		"Comma-separated model names from model_list to compare")
+	cmd.Flags().StringVarP(&promptsFile, "prompts", "p", "",
+		"File with one prompt per line")
+	cmd.Flags().IntVarP(&opts.concurrency, "concurrency", "c", 1,
+		"Number of requests to run at the same time")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 2*time.Minute,
+		"Time limit for each request")
+	cmd.Flags().StringArrayVar(&prices, "price", nil,
+		"USD per million input/output tokens for a model, as name=IN/OUT (repeatable)")
+	cmd.Flags().BoolVar(&opts.json, "json", false,
+		"Print results as JSON")
+	_ = cmd.MarkFlagRequired("models")
+	_ = cmd.MarkFlagRequired("prompts")
+
+	return cmd
+}
+
+func splitModels(s string) []string {
+	var models []string
+	seen := make(map[string]struct{})
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, dup := seen[name]; dup {
+			continue
+		}
+		seen[name] = struct{}{}
+		models = append(models, name)
+	}
+	return models
+}
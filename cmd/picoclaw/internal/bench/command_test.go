@@ -0,0 +1,53 @@
+package bench
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBenchCommand(t *testing.T) {
+	cmd := NewBenchCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "bench", cmd.Use)
+	assert.Equal(t, "Compare models on a fixed set of prompts", cmd.Short)
+	assert.False(t, cmd.HasSubCommands())
+	assert.NotNil(t, cmd.RunE)
+
+	for name, def := range map[string]string{
+		"models":      "",
+		"prompts":     "",
+		"concurrency": "1",
+		"timeout":     "2m0s",
+		"price":       "[]",
+		"json":        "false",
+	} {
+		flag := cmd.Flags().Lookup(name)
+		require.NotNil(t, flag, "expected --%s flag to be registered", name)
+		assert.Equal(t, def, flag.DefValue, "--%s default", name)
+	}
+}
+
+func TestBenchCommand_RejectsBadFlags(t *testing.T) {
+	prompts := filepath.Join(t.TempDir(), "prompts.txt")
+	require.NoError(t, os.WriteFile(prompts, []byte("hello\n"), 0o644))
+
+	for _, args := range [][]string{
+		{"--prompts", prompts},
+		{"--models", " , ", "--prompts", prompts},
+		{"--models", "a", "--prompts", prompts, "--concurrency", "0"},
+		{"--models", "a", "--prompts", prompts, "--price", "a=cheap"},
+		{"--models", "a", "--prompts", filepath.Join(t.TempDir(), "missing.txt")},
+	} {
+		cmd := NewBenchCommand()
+		cmd.SetArgs(args)
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		assert.Error(t, cmd.Execute(), "args %v", args)
+	}
+}
@@ -0,0 +1,265 @@
+package bench
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// maxReportedErrors caps the distinct errors kept per model, so a model that
+// fails every prompt the same way does not flood the report.
+const maxReportedErrors = 3
+
+type benchOptions struct {
+	models      []string
+	prompts     []string
+	concurrency int
+	timeout     time.Duration
+	maxTokens   int
+	prices      map[string]modelPrice
+	json        bool
+}
+
+// modelPrice is what a model costs in USD per million tokens.
+type modelPrice struct {
+	Input  float64
+	Output float64
+}
+
+type providerFactory func(name string) (providers.LLMProvider, string, error)
+
+// modelReport sums up one model's runs. Latency covers successful runs only.
+type modelReport struct {
+	Model            string   `json:"model"`
+	Runs             int      `json:"runs"`
+	Failures         int      `json:"failures"`
+	AvgLatencyMs     int64    `json:"avg_latency_ms"`
+	MaxLatencyMs     int64    `json:"max_latency_ms"`
+	PromptTokens     int      `json:"prompt_tokens"`
+	CompletionTokens int      `json:"completion_tokens"`
+	TotalTokens      int      `json:"total_tokens"`
+	CostUSD          *float64 `json:"cost_usd,omitempty"`
+	Errors           []string `json:"errors,omitempty"`
+}
+
+type benchRun struct {
+	latency time.Duration
+	usage   *providers.UsageInfo
+	err     error
+}
+
+// loadPrompts reads one prompt per line, skipping blank lines and # comments.
+func loadPrompts(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open prompts file: %w", err)
+	}
+	defer f.Close()
+
+	var prompts []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		prompts = append(prompts, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read prompts file: %w", err)
+	}
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("no prompts in %s", path)
+	}
+	return prompts, nil
+}
+
+// parsePrices parses --price values of the form name=IN/OUT.
+func parsePrices(values []string) (map[string]modelPrice, error) {
+	prices := make(map[string]modelPrice, len(values))
+	for _, v := range values {
+		name, rates, ok := strings.Cut(v, "=")
+		in, out, ok2 := strings.Cut(rates, "/")
+		name = strings.TrimSpace(name)
+		if !ok || !ok2 || name == "" {
+			return nil, fmt.Errorf("invalid --price %q (use name=IN/OUT, e.g. gpt-5.2=1.25/10)", v)
+		}
+		inRate, err1 := strconv.ParseFloat(strings.TrimSpace(in), 64)
+		outRate, err2 := strconv.ParseFloat(strings.TrimSpace(out), 64)
+		if err1 != nil || err2 != nil || inRate < 0 || outRate < 0 {
+			return nil, fmt.Errorf("invalid --price %q: rates must be non-negative numbers", v)
+		}
+		prices[name] = modelPrice{Input: inRate, Output: outRate}
+	}
+	return prices, nil
+}
+
+// runBench sends every prompt to every model, at most opts.concurrency
+// requests at a time. A model that cannot be created or whose requests fail
+// is reported as such; the other models still run.
+func runBench(ctx context.Context, opts benchOptions, newProvider providerFactory) []modelReport {
+	type job struct {
+		model    int
+		prompt   int
+		provider providers.LLMProvider
+		modelID  string
+	}
+
+	runs := make([][]benchRun, len(opts.models))
+	var jobs []job
+	for i, name := range opts.models {
+		runs[i] = make([]benchRun, len(opts.prompts))
+		provider, modelID, err := newProvider(name)
+		if err != nil {
+			for p := range runs[i] {
+				runs[i][p].err = fmt.Errorf("create provider: %w", err)
+			}
+			continue
+		}
+		if closer, ok := provider.(providers.StatefulProvider); ok {
+			defer closer.Close()
+		}
+		for p := range opts.prompts {
+			jobs = append(jobs, job{model: i, prompt: p, provider: provider, modelID: modelID})
+		}
+	}
+
+	options := map[string]any{}
+	if opts.maxTokens > 0 {
+		options["max_tokens"] = opts.maxTokens
+	}
+
+	queue := make(chan job)
+	var wg sync.WaitGroup
+	for range max(opts.concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				runs[j.model][j.prompt] = runOne(ctx, j.provider, j.modelID, opts.prompts[j.prompt], options, opts.timeout)
+			}
+		}()
+	}
+	for _, j := range jobs {
+		queue <- j
+	}
+	close(queue)
+	wg.Wait()
+
+	reports := make([]modelReport, len(opts.models))
+	for i, name := range opts.models {
+		price, hasPrice := opts.prices[name]
+		reports[i] = summarizeRuns(name, runs[i], price, hasPrice)
+	}
+	return reports
+}
+
+func runOne(
+	ctx context.Context,
+	provider providers.LLMProvider,
+	modelID, prompt string,
+	options map[string]any,
+	timeout time.Duration,
+) benchRun {
+	if err := ctx.Err(); err != nil {
+		return benchRun{err: err}
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	messages := []providers.Message{{Role: "user", Content: prompt}}
+	start := time.Now()
+	resp, err := provider.Chat(ctx, messages, nil, modelID, options)
+	run := benchRun{latency: time.Since(start), err: err}
+	if err == nil && resp != nil {
+		run.usage = resp.Usage
+	}
+	return run
+}
+
+func summarizeRuns(name string, runs []benchRun, price modelPrice, hasPrice bool) modelReport {
+	report := modelReport{Model: name, Runs: len(runs)}
+	var total time.Duration
+	seenErrors := make(map[string]struct{})
+	for _, run := range runs {
+		if run.err != nil {
+			report.Failures++
+			msg := run.err.Error()
+			if _, dup := seenErrors[msg]; !dup && len(report.Errors) < maxReportedErrors {
+				seenErrors[msg] = struct{}{}
+				report.Errors = append(report.Errors, msg)
+			}
+			continue
+		}
+		total += run.latency
+		report.MaxLatencyMs = max(report.MaxLatencyMs, run.latency.Milliseconds())
+		if run.usage != nil {
+			report.PromptTokens += run.usage.PromptTokens
+			report.CompletionTokens += run.usage.CompletionTokens
+			report.TotalTokens += run.usage.TotalTokens
+		}
+	}
+	if ok := report.Runs - report.Failures; ok > 0 {
+		report.AvgLatencyMs = (total / time.Duration(ok)).Milliseconds()
+	}
+	if hasPrice {
+		cost := (float64(report.PromptTokens)*price.Input + float64(report.CompletionTokens)*price.Output) / 1e6
+		report.CostUSD = &cost
+	}
+	return report
+}
+
+func printBenchTable(w io.Writer, reports []modelReport, prompts int) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODEL\tOK\tAVG LATENCY\tMAX LATENCY\tINPUT TOKENS\tOUTPUT TOKENS\tCOST (USD)")
+	for _, r := range reports {
+		avg, maxLatency, cost := "-", "-", "-"
+		if r.Failures < r.Runs {
+			avg = formatLatency(r.AvgLatencyMs)
+			maxLatency = formatLatency(r.MaxLatencyMs)
+		}
+		if r.CostUSD != nil {
+			cost = fmt.Sprintf("%.4f", *r.CostUSD)
+		}
+		fmt.Fprintf(tw, "%s\t%d/%d\t%s\t%s\t%d\t%d\t%s\n",
+			r.Model, r.Runs-r.Failures, r.Runs, avg, maxLatency, r.PromptTokens, r.CompletionTokens, cost)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n%d prompts per model.\n", prompts)
+
+	for _, r := range reports {
+		if r.Failures == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s: %d of %d requests failed\n", r.Model, r.Failures, r.Runs)
+		for _, msg := range r.Errors {
+			fmt.Fprintf(w, "  %s\n", msg)
+		}
+	}
+}
+
+func formatLatency(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+func writeBenchJSON(w io.Writer, reports []modelReport, prompts int) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Prompts int           `json:"prompts"`
+		Models  []modelReport `json:"models"`
+	}{Prompts: prompts, Models: reports})
+}
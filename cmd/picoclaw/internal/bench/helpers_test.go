@@ -0,0 +1,171 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type benchProvider struct {
+	delay    time.Duration
+	fail     bool
+	calls    atomic.Int32
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (p *benchProvider) Chat(
+	ctx context.Context,
+	_ []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	p.calls.Add(1)
+	n := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if p.fail {
+		return nil, errors.New("rate limited")
+	}
+	return &providers.LLMResponse{
+		Content: "ok",
+		Usage:   &providers.UsageInfo{PromptTokens: 100, CompletionTokens: 50, TotalTokens: 150},
+	}, nil
+}
+
+func (p *benchProvider) GetDefaultModel() string { return "" }
+
+func TestLoadPrompts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompts.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# warmup\nfirst\n\n  second  \n"), 0o644))
+
+	prompts, err := loadPrompts(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, prompts)
+
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	require.NoError(t, os.WriteFile(empty, []byte("# nothing\n"), 0o644))
+	_, err = loadPrompts(empty)
+	assert.Error(t, err)
+}
+
+func TestParsePrices(t *testing.T) {
+	prices, err := parsePrices([]string{"fast=0.15/0.6", " smart = 3 / 15 "})
+	require.NoError(t, err)
+	assert.Equal(t, modelPrice{Input: 0.15, Output: 0.6}, prices["fast"])
+	assert.Equal(t, modelPrice{Input: 3, Output: 15}, prices["smart"])
+
+	for _, bad := range []string{"fast", "fast=1", "=1/2", "fast=a/b", "fast=-1/2"} {
+		_, err := parsePrices([]string{bad})
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRunBench_ReportsEachModelAndSurvivesFailures(t *testing.T) {
+	fast := &benchProvider{delay: 5 * time.Millisecond}
+	flaky := &benchProvider{fail: true}
+	opts := benchOptions{
+		models:      []string{"fast", "flaky", "missing"},
+		prompts:     []string{"a", "b", "c", "d"},
+		concurrency: 2,
+		prices:      map[string]modelPrice{"fast": {Input: 1, Output: 2}},
+	}
+
+	reports := runBench(context.Background(), opts, func(name string) (providers.LLMProvider, string, error) {
+		switch name {
+		case "fast":
+			return fast, "fast-1", nil
+		case "flaky":
+			return flaky, "flaky-1", nil
+		}
+		return nil, "", errors.New("model not found")
+	})
+	require.Len(t, reports, 3)
+
+	ok := reports[0]
+	assert.Equal(t, "fast", ok.Model)
+	assert.Equal(t, 4, ok.Runs)
+	assert.Zero(t, ok.Failures)
+	assert.Equal(t, 400, ok.PromptTokens)
+	assert.Equal(t, 200, ok.CompletionTokens)
+	assert.Positive(t, ok.AvgLatencyMs)
+	require.NotNil(t, ok.CostUSD)
+	assert.InDelta(t, (400*1.0+200*2.0)/1e6, *ok.CostUSD, 1e-12)
+
+	assert.Equal(t, 4, reports[1].Failures)
+	assert.Equal(t, []string{"rate limited"}, reports[1].Errors)
+	assert.Nil(t, reports[1].CostUSD)
+
+	assert.Equal(t, 4, reports[2].Failures)
+	require.Len(t, reports[2].Errors, 1)
+	assert.Contains(t, reports[2].Errors[0], "model not found")
+
+	assert.EqualValues(t, 4, fast.calls.Load())
+	assert.LessOrEqual(t, fast.peak.Load(), int32(2))
+}
+
+func TestRunBench_TimeoutCountsAsFailure(t *testing.T) {
+	slow := &benchProvider{delay: time.Second}
+	opts := benchOptions{
+		models:      []string{"slow"},
+		prompts:     []string{"a"},
+		concurrency: 1,
+		timeout:     10 * time.Millisecond,
+	}
+	reports := runBench(context.Background(), opts, func(string) (providers.LLMProvider, string, error) {
+		return slow, "slow", nil
+	})
+	require.Len(t, reports, 1)
+	assert.Equal(t, 1, reports[0].Failures)
+}
+
+func TestPrintBenchTableAndJSON(t *testing.T) {
+	cost := 0.0123
+	reports := []modelReport{
+		{Model: "fast", Runs: 2, AvgLatencyMs: 1200, MaxLatencyMs: 1500, PromptTokens: 20, CompletionTokens: 10, CostUSD: &cost},
+		{Model: "broken", Runs: 2, Failures: 2, Errors: []string{"create provider: no key"}},
+	}
+
+	var table bytes.Buffer
+	printBenchTable(&table, reports, 2)
+	out := table.String()
+	assert.Contains(t, out, "MODEL")
+	assert.Contains(t, out, "1.2s")
+	assert.Contains(t, out, "0.0123")
+	assert.Contains(t, out, "broken: 2 of 2 requests failed")
+	assert.Contains(t, out, "create provider: no key")
+
+	var buf bytes.Buffer
+	require.NoError(t, writeBenchJSON(&buf, reports, 2))
+	var decoded struct {
+		Prompts int           `json:"prompts"`
+		Models  []modelReport `json:"models"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, 2, decoded.Prompts)
+	require.Len(t, decoded.Models, 2)
+	assert.Equal(t, "fast", decoded.Models[0].Model)
+	assert.Nil(t, decoded.Models[1].CostUSD)
+}
@@ -2,6 +2,8 @@ package cliui
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -25,51 +27,59 @@ type StatusReport struct {
 	Model         string
 	Providers     []ProviderRow
 	OAuthLines    []string // each full line "provider (method): state"
+	RuntimeLines  []string // gateway, channel and cron summary lines
 }
 
-// PrintStatus renders picoclaw status (plain or fancy).
-func PrintStatus(r StatusReport) {
-	if !UseFancyLayout() {
-		printStatusPlain(r)
+// PrintStatus renders picoclaw status to w. The fancy layout is used only
+// when w is a wide enough terminal on stdout; anything else gets plain text.
+func PrintStatus(w io.Writer, r StatusReport) {
+	if f, ok := w.(*os.File); !ok || f != os.Stdout || !UseFancyLayout() {
+		printStatusPlain(w, r)
 		return
 	}
-	printStatusFancy(r)
+	printStatusFancy(w, r)
 }
 
-func printStatusPlain(r StatusReport) {
-	fmt.Printf("%s picoclaw Status\n", r.Logo)
-	fmt.Printf("Version: %s\n", r.Version)
+func printStatusPlain(w io.Writer, r StatusReport) {
+	fmt.Fprintf(w, "%s picoclaw Status\n", r.Logo)
+	fmt.Fprintf(w, "Version: %s\n", r.Version)
 	if r.Build != "" {
-		fmt.Printf("Build: %s\n", r.Build)
+		fmt.Fprintf(w, "Build: %s\n", r.Build)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 
-	printPathLine("Config", r.ConfigPath, r.ConfigOK)
-	printPathLine("Workspace", r.WorkspacePath, r.WorkspaceOK)
+	printPathLine(w, "Config", r.ConfigPath, r.ConfigOK)
+	printPathLine(w, "Workspace", r.WorkspacePath, r.WorkspaceOK)
 
 	if r.ConfigOK {
-		fmt.Printf("Model: %s\n", r.Model)
+		fmt.Fprintf(w, "Model: %s\n", r.Model)
 		for _, p := range r.Providers {
-			fmt.Printf("%s: %s\n", p.Name, p.Val)
+			fmt.Fprintf(w, "%s: %s\n", p.Name, p.Val)
 		}
 		if len(r.OAuthLines) > 0 {
-			fmt.Println("\nOAuth/Token Auth:")
+			fmt.Fprintln(w, "\nOAuth/Token Auth:")
 			for _, line := range r.OAuthLines {
-				fmt.Printf("  %s\n", line)
+				fmt.Fprintf(w, "  %s\n", line)
 			}
 		}
 	}
+	if len(r.RuntimeLines) > 0 {
+		fmt.Fprintln(w)
+		for _, line := range r.RuntimeLines {
+			fmt.Fprintln(w, line)
+		}
+	}
 }
 
-func printPathLine(label, path string, ok bool) {
+func printPathLine(w io.Writer, label, path string, ok bool) {
 	mark := "✗"
 	if ok {
 		mark = "✓"
 	}
-	fmt.Println(label+":", path, mark)
+	fmt.Fprintln(w, label+":", path, mark)
 }
 
-func printStatusFancy(r StatusReport) {
+func printStatusFancy(w io.Writer, r StatusReport) {
 	inner := InnerWidth()
 	topBox := borderStyle().Width(inner)
 
@@ -81,8 +91,8 @@ func printStatusFancy(r StatusReport) {
 		head.WriteString("\n")
 		head.WriteString(kvKeyStyle().Render("Build") + "     " + kvValStyle().Render(r.Build))
 	}
-	fmt.Println(topBox.Render(head.String()))
-	fmt.Println()
+	fmt.Fprintln(w, topBox.Render(head.String()))
+	fmt.Fprintln(w)
 
 	if UseColumnLayout() && len(r.Providers) > 0 && r.ConfigOK {
 		leftW := (inner - 2) / 2
@@ -90,11 +100,11 @@ func printStatusFancy(r StatusReport) {
 		pathsNarrow := pathStatusPanel(r, leftW)
 		prov := providerTablePanel(r, rightW)
 		gap := strings.Repeat(" ", 2)
-		fmt.Println(lipgloss.JoinHorizontal(lipgloss.Top, pathsNarrow, gap, prov))
+		fmt.Fprintln(w, lipgloss.JoinHorizontal(lipgloss.Top, pathsNarrow, gap, prov))
 	} else {
-		fmt.Println(pathStatusPanel(r, inner))
+		fmt.Fprintln(w, pathStatusPanel(r, inner))
 		if len(r.Providers) > 0 && r.ConfigOK {
-			fmt.Println(providerTablePanel(r, inner))
+			fmt.Fprintln(w, providerTablePanel(r, inner))
 		}
 	}
 
@@ -104,8 +114,18 @@ func printStatusFancy(r StatusReport) {
 		for _, line := range r.OAuthLines {
 			ob.WriteString("  • " + line + "\n")
 		}
-		fmt.Println()
-		fmt.Println(borderStyle().Width(inner).Render(ob.String()))
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, borderStyle().Width(inner).Render(ob.String()))
+	}
+
+	if len(r.RuntimeLines) > 0 {
+		var rb strings.Builder
+		rb.WriteString(titleBarStyle().Render("Runtime") + "\n\n")
+		for _, line := range r.RuntimeLines {
+			rb.WriteString("  " + line + "\n")
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, borderStyle().Width(inner).Render(strings.TrimRight(rb.String(), "\n")))
 	}
 }
 
@@ -16,6 +16,7 @@ func newAddCommand(storePath func() string) *cobra.Command {
 		cronExp string
 		channel string
 		to      string
+		deliver bool
 	)
 
 	cmd := &cobra.Command{
@@ -35,11 +36,27 @@ func newAddCommand(storePath func() string) *cobra.Command {
 				schedule = cron.CronSchedule{Kind: "cron", Expr: cronExp}
 			}
 
+			recipients, err := cron.ParseRecipients(channel, to)
+			if err != nil {
+				return err
+			}
+			var first cron.CronRecipient
+			if len(recipients) > 0 {
+				first = recipients[0]
+			}
+
 			cs := cron.NewCronService(storePath(), nil)
-			job, err := cs.AddJob(name, schedule, message, channel, to)
+			job, err := cs.AddJob(name, schedule, message, first.Channel, first.To)
 			if err != nil {
 				return fmt.Errorf("error adding job: %w", err)
 			}
+			if deliver || len(recipients) > 1 {
+				job.Payload.Deliver = deliver
+				job.Payload.SetRecipients(recipients)
+				if err := cs.UpdateJob(job); err != nil {
+					return fmt.Errorf("error adding job: %w", err)
+				}
+			}
 
 			fmt.Printf("✓ Added job '%s' (%s)\n", job.Name, job.ID)
 
@@ -51,8 +68,10 @@ func newAddCommand(storePath func() string) *cobra.Command {
 	cmd.Flags().StringVarP(&message, "message", "m", "", "Message for agent")
 	cmd.Flags().Int64VarP(&every, "every", "e", 0, "Run every N seconds")
 	cmd.Flags().StringVarP(&cronExp, "cron", "c", "", "Cron expression (e.g. '0 9 * * *')")
-	cmd.Flags().StringVar(&to, "to", "", "Recipient for delivery")
+	cmd.Flags().StringVar(&to, "to", "",
+		"Recipient for delivery; separate several with commas (each channel:chat_id when --channel is not set)")
 	cmd.Flags().StringVar(&channel, "channel", "", "Channel for delivery")
+	cmd.Flags().BoolVar(&deliver, "deliver", false, "Send the message as-is instead of running an agent turn")
 
 	_ = cmd.MarkFlagRequired("name")
 	_ = cmd.MarkFlagRequired("message")
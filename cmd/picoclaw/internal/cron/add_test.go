@@ -23,6 +23,7 @@ func TestNewAddSubcommand(t *testing.T) {
 	assert.NotNil(t, cmd.Flags().Lookup("cron"))
 	assert.NotNil(t, cmd.Flags().Lookup("to"))
 	assert.NotNil(t, cmd.Flags().Lookup("channel"))
+	assert.NotNil(t, cmd.Flags().Lookup("deliver"))
 
 	nameFlag := cmd.Flags().Lookup("name")
 	require.NotNil(t, nameFlag)
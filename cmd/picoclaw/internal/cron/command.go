@@ -38,6 +38,7 @@ func NewCronCommand() *cobra.Command {
 		newRemoveCommand(func() string { return storePath }),
 		newEnableCommand(func() string { return storePath }),
 		newDisableCommand(func() string { return storePath }),
+		newTestCommand(func() string { return storePath }),
 	)
 
 	return cmd
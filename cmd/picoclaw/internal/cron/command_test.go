@@ -35,6 +35,7 @@ func TestNewCronCommand(t *testing.T) {
 		"remove",
 		"enable",
 		"disable",
+		"test",
 	}
 
 	subcommands := cmd.Commands()
@@ -1,10 +1,19 @@
 package cron
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/gateway"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
 func cronListCmd(storePath string) {
@@ -43,7 +52,22 @@ func cronListCmd(storePath string) {
 		fmt.Printf("    Schedule: %s\n", schedule)
 		fmt.Printf("    Status: %s\n", status)
 		fmt.Printf("    Next run: %s\n", nextRun)
+		if recipients := formatRecipients(job.Payload.Targets()); recipients != "" {
+			fmt.Printf("    Recipients: %s\n", recipients)
+		}
+	}
+}
+
+// formatRecipients lists a job's recipients for display, or "" when the job
+// has no target of its own.
+func formatRecipients(recipients []cron.CronRecipient) string {
+	parts := make([]string, 0, len(recipients))
+	for _, r := range recipients {
+		if r.Channel != "" || r.To != "" {
+			parts = append(parts, r.String())
+		}
 	}
+	return strings.Join(parts, ", ")
 }
 
 func cronRemoveCmd(storePath, jobID string) {
@@ -64,3 +88,102 @@ func cronSetJobEnabled(storePath, jobID string, enabled bool) {
 		fmt.Printf("✗ Job %s not found\n", jobID)
 	}
 }
+
+// cronTestCmd runs one job through the gateway's job handler and prints what
+// it sends. With deliver set, the configured channels are started and each
+// message is also sent for real.
+func cronTestCmd(storePath, jobID string, deliver bool) error {
+	job, ok := cron.NewCronService(storePath, nil).GetJob(jobID)
+	if !ok {
+		return fmt.Errorf("job %s not found", jobID)
+	}
+
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	logger.ConfigureFromEnv()
+
+	provider, modelID, err := providers.CreateProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("error creating provider: %w", err)
+	}
+	if modelID != "" {
+		cfg.Agents.Defaults.ModelName = modelID
+	}
+
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
+	defer agentLoop.Close()
+
+	cronService, err := gateway.SetupCronService(agentLoop, msgBus, cfg)
+	if err != nil {
+		return fmt.Errorf("error setting up cron service: %w", err)
+	}
+
+	ctx := context.Background()
+	var channelManager *channels.Manager
+	if deliver {
+		// The manager gets its own bus: the job's messages are read here and
+		// sent synchronously, so each delivery result can be reported.
+		channelBus := bus.NewMessageBus()
+		defer channelBus.Close()
+		channelManager, err = channels.NewManager(cfg, channelBus, nil)
+		if err != nil {
+			return fmt.Errorf("error creating channel manager: %w", err)
+		}
+		if err = channelManager.StartAll(ctx); err != nil {
+			return fmt.Errorf("error starting channels: %w", err)
+		}
+		defer channelManager.StopAll(ctx)
+	}
+
+	fmt.Printf("▶ Running job '%s' (%s)\n", job.Name, job.ID)
+
+	handle := func(msg bus.OutboundMessage) {
+		msg = bus.NormalizeOutboundMessage(msg)
+		if msg.Operation != "" && msg.Operation != bus.OutboundSend {
+			return
+		}
+		fmt.Printf("\n→ %s:%s\n%s\n", msg.Channel, msg.ChatID, msg.Content)
+		if channelManager == nil {
+			return
+		}
+		if err := channelManager.SendMessage(ctx, msg); err != nil {
+			fmt.Printf("✗ Not delivered: %v\n", err)
+		} else {
+			fmt.Println("✓ Delivered")
+		}
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case msg := <-msgBus.OutboundChan():
+				handle(msg)
+			case <-stop:
+				for {
+					select {
+					case msg := <-msgBus.OutboundChan():
+						handle(msg)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	result, runErr := cronService.RunJob(jobID)
+	close(stop)
+	<-done
+
+	if runErr != nil {
+		return fmt.Errorf("job %s failed: %w", jobID, runErr)
+	}
+	fmt.Printf("\n✓ Job finished: %s\n", result)
+	return nil
+}
@@ -0,0 +1,25 @@
+package cron
+
+import "github.com/spf13/cobra"
+
+func newTestCommand(storePath func() string) *cobra.Command {
+	var noDeliver bool
+
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Run a job once now",
+		Long: `Run a job's action once, right now, through the same path the scheduler
+uses. Everything the job sends is printed and, unless --no-deliver is given,
+also delivered to its channels. The job's schedule is left untouched.`,
+		Args: cobra.ExactArgs(1),
+		Example: `picoclaw cron test 1a2b3c4d
+picoclaw cron test 1a2b3c4d --no-deliver`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cronTestCmd(storePath(), args[0], !noDeliver)
+		},
+	}
+
+	cmd.Flags().BoolVar(&noDeliver, "no-deliver", false, "Print the job's output without sending it to channels")
+
+	return cmd
+}
@@ -0,0 +1,21 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestSubcommand(t *testing.T) {
+	fn := func() string { return "" }
+	cmd := newTestCommand(fn)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "test", cmd.Use)
+	assert.Equal(t, "Run a job once now", cmd.Short)
+
+	assert.True(t, cmd.HasExample())
+	assert.NotNil(t, cmd.Flags().Lookup("no-deliver"))
+}
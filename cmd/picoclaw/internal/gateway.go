@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/health"
+	"github.com/sipeed/picoclaw/pkg/pid"
+)
+
+const gatewayProbeTimeout = 2 * time.Second
+
+// GatewayProbe describes a gateway found through its pid file.
+type GatewayProbe struct {
+	PID     int
+	Address string
+	Health  *health.StatusResponse
+}
+
+// ProbeGateway finds a running gateway through its pid file and asks its
+// health endpoint for liveness, uptime and live checks. It returns nil when
+// no gateway is running; a non-nil probe with an error means the gateway is
+// running but its health endpoint could not be read.
+func ProbeGateway(homePath string) (*GatewayProbe, error) {
+	data := pid.ReadPidFileWithCheck(homePath)
+	if data == nil {
+		return nil, nil
+	}
+	probe := &GatewayProbe{
+		PID:     data.PID,
+		Address: net.JoinHostPort(data.Host, strconv.Itoa(data.Port)),
+	}
+
+	client := &http.Client{Timeout: gatewayProbeTimeout}
+	resp, err := client.Get("http://" + probe.Address + "/health")
+	if err != nil {
+		return probe, err
+	}
+	defer resp.Body.Close()
+
+	var body health.StatusResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return probe, fmt.Errorf("invalid health response: %w", err)
+	}
+	probe.Health = &body
+	return probe, nil
+}
@@ -87,5 +87,7 @@ func NewGatewayCommand() *cobra.Command {
 		"Host address for gateway binding (overrides gateway.host for this run)",
 	)
 
+	cmd.AddCommand(newRotateKeyCommand())
+
 	return cmd
 }
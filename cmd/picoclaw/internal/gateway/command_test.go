@@ -24,7 +24,12 @@ func TestNewGatewayCommand(t *testing.T) {
 	assert.Nil(t, cmd.PersistentPreRun)
 	assert.Nil(t, cmd.PersistentPostRun)
 
-	assert.False(t, cmd.HasSubCommands())
+	assert.True(t, cmd.HasSubCommands())
+	rotate, _, err := cmd.Find([]string{"rotate-key"})
+	require.NoError(t, err)
+	assert.Equal(t, "rotate-key", rotate.Name())
+	assert.NotNil(t, rotate.Flags().Lookup("print"))
+	assert.NotNil(t, rotate.Flags().Lookup("grace"))
 
 	assert.True(t, cmd.HasFlags())
 	assert.NotNil(t, cmd.Flags().Lookup("debug"))
@@ -0,0 +1,114 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/gateway"
+	"github.com/sipeed/picoclaw/pkg/pid"
+)
+
+const rotateKeyTimeout = 5 * time.Second
+
+func newRotateKeyCommand() *cobra.Command {
+	var printOnly bool
+	var grace time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Replace the running gateway's API key",
+		Long: `Replace the API key of the running gateway without restarting it.
+
+The new key is written to the gateway's pid file and printed once. The old key
+keeps working for the --grace period so clients can switch over.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			data := pid.ReadPidFileWithCheck(internal.GetPicoclawHome())
+			if data == nil {
+				return fmt.Errorf("gateway is not running; a new key is generated each time it starts")
+			}
+			if printOnly {
+				fmt.Fprintln(cmd.OutOrStdout(), data.Token)
+				return nil
+			}
+			if grace < 0 || grace > time.Hour {
+				return fmt.Errorf("--grace must be between 0s and 1h")
+			}
+
+			resp, err := rotateGatewayKey(data, grace)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintln(out, resp.Token)
+			if grace > 0 {
+				fmt.Fprintf(out, "The previous key stops working at %s.\n",
+					resp.PreviousValidUntil.Local().Format(time.RFC3339))
+			} else {
+				fmt.Fprintln(out, "The previous key no longer works.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the current key without rotating it")
+	cmd.Flags().DurationVar(
+		&grace,
+		"grace",
+		gateway.DefaultKeyRotationGrace,
+		"How long the previous key keeps working (0 retires it at once, at most 1h)",
+	)
+
+	return cmd
+}
+
+// rotateGatewayKey asks the gateway described by data to rotate its key,
+// authenticating with the current one.
+func rotateGatewayKey(data *pid.PidFileData, grace time.Duration) (*gateway.RotateKeyResponse, error) {
+	body, err := json.Marshal(map[string]int{"grace_seconds": int(grace / time.Second)})
+	if err != nil {
+		return nil, err
+	}
+	url := "http://" + net.JoinHostPort(data.Host, strconv.Itoa(data.Port)) + gateway.RotateKeyAPIPath
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+data.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: rotateKeyTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach gateway: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(raw, &apiErr) == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("gateway refused to rotate the key: %s", apiErr.Error)
+		}
+		return nil, fmt.Errorf("gateway refused to rotate the key: %s", resp.Status)
+	}
+	var out gateway.RotateKeyResponse
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("invalid gateway response: %w", err)
+	}
+	if out.Token == "" {
+		return nil, fmt.Errorf("gateway returned an empty key")
+	}
+	return &out, nil
+}
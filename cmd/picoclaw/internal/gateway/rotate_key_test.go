@@ -0,0 +1,50 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/gateway"
+	"github.com/sipeed/picoclaw/pkg/pid"
+)
+
+func testPidData(t *testing.T, srv *httptest.Server, token string) *pid.PidFileData {
+	t.Helper()
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return &pid.PidFileData{Host: host, Port: p, Token: token}
+}
+
+func TestRotateGatewayKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, gateway.RotateKeyAPIPath, r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer old" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+			return
+		}
+		var body map[string]int
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, 30, body["grace_seconds"])
+		_ = json.NewEncoder(w).Encode(gateway.RotateKeyResponse{Token: "new", PreviousValidUntil: time.Now()})
+	}))
+	defer srv.Close()
+
+	resp, err := rotateGatewayKey(testPidData(t, srv, "old"), 30*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "new", resp.Token)
+
+	_, err = rotateGatewayKey(testPidData(t, srv, "wrong"), 30*time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unauthorized")
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
 const Logo = pkg.Logo
@@ -17,6 +18,17 @@ func GetPicoclawHome() string {
 	return config.GetHome()
 }
 
+// SetConfigPath points every command at an explicit config file (the global
+// --config flag). The path is exported as PICOCLAW_CONFIG so child processes,
+// such as a gateway started by the launcher, resolve the same file.
+func SetConfigPath(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	return os.Setenv(config.EnvConfig, absPath)
+}
+
 func GetConfigPath() string {
 	if configPath := os.Getenv(config.EnvConfig); configPath != "" {
 		return configPath
@@ -30,6 +42,8 @@ func LoadConfig() (*config.Config, error) {
 		return nil, err
 	}
 	logger.SetLevelFromString(cfg.Gateway.LogLevel)
+	logger.SetRedactor(config.EffectiveLogRedactor(cfg))
+	providers.Configure(cfg)
 	return cfg, nil
 }
 
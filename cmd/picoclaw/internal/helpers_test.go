@@ -42,6 +42,15 @@ func TestGetConfigPath_WithPICOCLAW_CONFIG(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func TestSetConfigPath_OverridesEnvironment(t *testing.T) {
+	t.Setenv(config.EnvConfig, "/custom/config.json")
+	dir := t.TempDir()
+
+	require.NoError(t, SetConfigPath(filepath.Join(dir, "instance-a.json")))
+
+	assert.Equal(t, filepath.Join(dir, "instance-a.json"), GetConfigPath())
+}
+
 func TestGetConfigPath_Windows(t *testing.T) {
 	if runtime.GOOS != "windows" {
 		t.Skip("windows-specific HOME behavior varies; run on windows")
@@ -0,0 +1,56 @@
+package logs
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/gateway"
+)
+
+func NewLogsCommand() *cobra.Command {
+	opts := logsOptions{pollInterval: defaultPollInterval}
+	var since string
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show gateway logs",
+		Example: `  picoclaw logs
+  picoclaw logs --follow --level warn
+  picoclaw logs --since 10m --component agent,channels`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			filter, err := newLogFilter(opts.level, opts.components, since, time.Now())
+			if err != nil {
+				return err
+			}
+			opts.filter = filter
+			if opts.file == "" {
+				opts.file = gateway.LogFilePath(internal.GetPicoclawHome())
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			return runLogs(ctx, cmd.OutOrStdout(), opts)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.follow, "follow", "f", false,
+		"Keep printing new entries as they are written")
+	cmd.Flags().StringVar(&since, "since", "",
+		"Only show entries newer than a duration (10m, 2h, 1d) or an RFC 3339 time")
+	cmd.Flags().StringVarP(&opts.components, "component", "c", "",
+		"Only show these components (comma-separated, e.g. agent,cron)")
+	cmd.Flags().StringVarP(&opts.level, "level", "l", "",
+		"Minimum level to show: debug, info, warn, error or fatal")
+	cmd.Flags().IntVarP(&opts.lines, "lines", "n", defaultLines,
+		"Number of matching entries to show before following (0 shows all)")
+	cmd.Flags().StringVar(&opts.file, "file", "",
+		"Log file to read (defaults to the gateway log under the picoclaw home)")
+
+	return cmd
+}
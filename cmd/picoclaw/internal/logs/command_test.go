@@ -0,0 +1,45 @@
+package logs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogsCommand(t *testing.T) {
+	cmd := NewLogsCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "logs", cmd.Use)
+	assert.Equal(t, "Show gateway logs", cmd.Short)
+	assert.False(t, cmd.HasSubCommands())
+	assert.NotNil(t, cmd.RunE)
+
+	for name, def := range map[string]string{
+		"follow":    "false",
+		"since":     "",
+		"component": "",
+		"level":     "",
+		"lines":     "100",
+		"file":      "",
+	} {
+		flag := cmd.Flags().Lookup(name)
+		require.NotNil(t, flag, "expected --%s flag to be registered", name)
+		assert.Equal(t, def, flag.DefValue, "--%s default", name)
+	}
+}
+
+func TestLogsCommand_RejectsBadFilters(t *testing.T) {
+	for _, args := range [][]string{
+		{"--level", "loud"},
+		{"--since", "yesterday"},
+	} {
+		cmd := NewLogsCommand()
+		cmd.SetArgs(args)
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		assert.Error(t, cmd.Execute(), "args %v", args)
+	}
+}
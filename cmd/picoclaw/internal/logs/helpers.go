@@ -0,0 +1,406 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rs/zerolog"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	defaultLines        = 100
+	defaultPollInterval = 500 * time.Millisecond
+)
+
+type logsOptions struct {
+	follow     bool
+	components string
+	level      string
+	lines      int
+	file       string
+
+	filter       *logFilter
+	pollInterval time.Duration
+}
+
+// logFilter decides which entries are printed. Lines that are not JSON log
+// entries (panic traces, partial writes) carry no level, component or time,
+// so they are only shown when no filter is active.
+type logFilter struct {
+	minLevel   zerolog.Level
+	hasLevel   bool
+	components map[string]struct{}
+	since      time.Time
+}
+
+func newLogFilter(level, components, since string, now time.Time) (*logFilter, error) {
+	f := &logFilter{}
+	if strings.TrimSpace(level) != "" {
+		lvl, ok := logger.ParseLevel(level)
+		if !ok {
+			return nil, fmt.Errorf("invalid --level %q (expected debug, info, warn, error or fatal)", level)
+		}
+		f.minLevel, f.hasLevel = lvl, true
+	}
+	for _, c := range strings.Split(components, ",") {
+		if c = strings.ToLower(strings.TrimSpace(c)); c != "" {
+			if f.components == nil {
+				f.components = make(map[string]struct{})
+			}
+			f.components[c] = struct{}{}
+		}
+	}
+	if strings.TrimSpace(since) != "" {
+		t, err := parseSince(since, now)
+		if err != nil {
+			return nil, err
+		}
+		f.since = t
+	}
+	return f, nil
+}
+
+// parseSince accepts a Go duration, a whole number of days or weeks ("1d",
+// "2w"), an RFC 3339 timestamp, or a date.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if n := len(s); n > 1 && (s[n-1] == 'd' || s[n-1] == 'w') {
+		if count, err := strconv.Atoi(s[:n-1]); err == nil && count >= 0 {
+			days := count
+			if s[n-1] == 'w' {
+				days *= 7
+			}
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (use a duration like 10m or 1d, or a time like 2026-03-04T15:00)", s)
+}
+
+func (f *logFilter) active() bool {
+	return f != nil && (f.hasLevel || f.components != nil || !f.since.IsZero())
+}
+
+func (f *logFilter) match(e logEntry) bool {
+	if f == nil {
+		return true
+	}
+	if !e.parsed {
+		return !f.active()
+	}
+	if f.hasLevel {
+		lvl, err := zerolog.ParseLevel(e.level)
+		if err != nil || lvl < f.minLevel {
+			return false
+		}
+	}
+	if f.components != nil {
+		if _, ok := f.components[strings.ToLower(e.component)]; !ok {
+			return false
+		}
+	}
+	if !f.since.IsZero() && (e.time.IsZero() || e.time.Before(f.since)) {
+		return false
+	}
+	return true
+}
+
+// logEntry is one line of the gateway log, which is written by zerolog as a
+// JSON object per line.
+type logEntry struct {
+	raw       string
+	parsed    bool
+	time      time.Time
+	level     string
+	component string
+	message   string
+	fields    map[string]any
+}
+
+func parseLogLine(line string) logEntry {
+	e := logEntry{raw: line}
+	var obj map[string]any
+	if !strings.HasPrefix(strings.TrimSpace(line), "{") || json.Unmarshal([]byte(line), &obj) != nil {
+		return e
+	}
+	e.parsed = true
+	if v, ok := obj[zerolog.TimestampFieldName].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			e.time = t
+		}
+	}
+	e.level, _ = obj[zerolog.LevelFieldName].(string)
+	e.component, _ = obj[logger.Component].(string)
+	e.message, _ = obj[zerolog.MessageFieldName].(string)
+	for _, k := range []string{
+		zerolog.TimestampFieldName, zerolog.LevelFieldName, logger.Component,
+		zerolog.MessageFieldName, zerolog.CallerFieldName,
+	} {
+		delete(obj, k)
+	}
+	e.fields = obj
+	return e
+}
+
+var (
+	logLevelLabels = map[string]string{
+		"trace": "TRC",
+		"debug": "DBG",
+		"info":  "INF",
+		"warn":  "WRN",
+		"error": "ERR",
+		"fatal": "FTL",
+		"panic": "PNC",
+	}
+	logLevelColors = map[string]lipgloss.Color{
+		"trace": lipgloss.Color("8"),
+		"debug": lipgloss.Color("8"),
+		"info":  lipgloss.Color("2"),
+		"warn":  lipgloss.Color("3"),
+		"error": lipgloss.Color("1"),
+		"fatal": lipgloss.Color("9"),
+		"panic": lipgloss.Color("9"),
+	}
+)
+
+// formatEntry renders e in the same shape as the gateway's console output.
+// Colors come from lipgloss, so --no-color, NO_COLOR and non-terminal output
+// all print plain text.
+func formatEntry(e logEntry) string {
+	if !e.parsed {
+		return e.raw
+	}
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	var b strings.Builder
+	if e.time.IsZero() {
+		b.WriteString(muted.Render("-------------------"))
+	} else {
+		b.WriteString(muted.Render(e.time.Local().Format("2006-01-02 15:04:05")))
+	}
+	b.WriteByte(' ')
+
+	label, ok := logLevelLabels[e.level]
+	if !ok {
+		label = "???"
+	}
+	b.WriteString(lipgloss.NewStyle().Foreground(logLevelColors[e.level]).Bold(true).Render(label))
+	if e.component != "" {
+		b.WriteByte(' ')
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Render(e.component))
+	}
+	b.WriteString(" ")
+	b.WriteString(e.message)
+
+	keys := make([]string, 0, len(e.fields))
+	for k := range e.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteByte(' ')
+		b.WriteString(muted.Render(k + "="))
+		b.WriteString(formatFieldValue(e.fields[k]))
+	}
+	return b.String()
+}
+
+func formatFieldValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		if val == "" || strings.ContainsAny(val, " \t\n\"=") {
+			return strconv.Quote(val)
+		}
+		return val
+	case nil:
+		return "null"
+	case float64, bool:
+		return fmt.Sprint(val)
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	}
+}
+
+func runLogs(ctx context.Context, out io.Writer, opts logsOptions) error {
+	follower := &logFollower{path: opts.file}
+	if err := follower.open(); err != nil {
+		if !opts.follow || !errors.Is(err, fs.ErrNotExist) {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("no log file at %s (start the gateway first, or pass --file)", opts.file)
+			}
+			return err
+		}
+	}
+	defer follower.close()
+
+	// Print the last opts.lines matching entries of what is already there.
+	var backlog []string
+	err := follower.readLines(!opts.follow, func(line string) {
+		e := parseLogLine(line)
+		if !opts.filter.match(e) {
+			return
+		}
+		backlog = append(backlog, formatEntry(e))
+		if opts.lines > 0 && len(backlog) > opts.lines {
+			backlog = backlog[1:]
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, line := range backlog {
+		fmt.Fprintln(out, line)
+	}
+	if !opts.follow {
+		return nil
+	}
+
+	interval := opts.pollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	emit := func(line string) {
+		if e := parseLogLine(line); opts.filter.match(e) {
+			fmt.Fprintln(out, formatEntry(e))
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := follower.readLines(false, emit); err != nil {
+			return err
+		}
+		if err := follower.checkRotation(emit); err != nil {
+			return err
+		}
+	}
+}
+
+// logFollower reads a log file incrementally and survives the file being
+// truncated in place or replaced by a new file (rename-style rotation).
+type logFollower struct {
+	path    string
+	file    *os.File
+	reader  *bufio.Reader
+	offset  int64
+	partial string
+}
+
+func (f *logFollower) open() error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	f.file = file
+	f.reader = bufio.NewReader(file)
+	f.offset = 0
+	f.partial = ""
+	return nil
+}
+
+func (f *logFollower) close() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+}
+
+// readLines passes every complete line available to fn. A trailing line
+// without a newline is held back until it is finished, unless flush is set.
+func (f *logFollower) readLines(flush bool, fn func(string)) error {
+	if f.file == nil {
+		return nil
+	}
+	for {
+		chunk, err := f.reader.ReadString('\n')
+		f.offset += int64(len(chunk))
+		if err == nil {
+			line := strings.TrimRight(f.partial+chunk, "\r\n")
+			f.partial = ""
+			fn(line)
+			continue
+		}
+		f.partial += chunk
+		if errors.Is(err, io.EOF) {
+			if flush && f.partial != "" {
+				fn(f.partial)
+				f.partial = ""
+			}
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", f.path, err)
+	}
+}
+
+// checkRotation reopens the log when the path now names a different file,
+// after draining what was left in the old one, and rewinds when the file
+// was truncated. A missing file is waited for.
+func (f *logFollower) checkRotation(fn func(string)) error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return f.readLines(false, fn)
+	}
+
+	current, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(current, info) {
+		if err := f.readLines(true, fn); err != nil {
+			return err
+		}
+		f.close()
+		if err := f.open(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return f.readLines(false, fn)
+	}
+	if info.Size() < f.offset {
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		f.reader.Reset(f.file)
+		f.offset = 0
+		f.partial = ""
+		return f.readLines(false, fn)
+	}
+	return nil
+}
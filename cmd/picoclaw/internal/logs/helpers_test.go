@@ -0,0 +1,186 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var logsTestNow = time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+
+const (
+	agentDebugLine = `{"level":"debug","component":"agent","iteration":2,"time":"2026-03-04T11:50:00Z","message":"calling llm"}`
+	cronInfoLine   = `{"level":"info","component":"cron","job_id":"abc","time":"2026-03-04T11:55:00Z","message":"job ran"}`
+	agentWarnLine  = `{"level":"warn","component":"agent","error":"rate limited","time":"2026-03-04T11:58:00Z","message":"retrying"}`
+)
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"10m", logsTestNow.Add(-10 * time.Minute)},
+		{"1h30m", logsTestNow.Add(-90 * time.Minute)},
+		{"2d", logsTestNow.AddDate(0, 0, -2)},
+		{"1w", logsTestNow.AddDate(0, 0, -7)},
+		{"2026-03-01T08:00:00Z", time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.in, logsTestNow)
+		require.NoError(t, err, tt.in)
+		assert.True(t, got.Equal(tt.want), "parseSince(%q) = %v, want %v", tt.in, got, tt.want)
+	}
+
+	for _, in := range []string{"soon", "-5m", "d"} {
+		_, err := parseSince(in, logsTestNow)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestLogFilter_Match(t *testing.T) {
+	lines := []string{agentDebugLine, cronInfoLine, agentWarnLine, "panic: boom"}
+	matching := func(level, components, since string) []string {
+		f, err := newLogFilter(level, components, since, logsTestNow)
+		require.NoError(t, err)
+		var got []string
+		for _, line := range lines {
+			e := parseLogLine(line)
+			switch {
+			case !f.match(e):
+			case e.parsed:
+				got = append(got, e.message)
+			default:
+				got = append(got, e.raw)
+			}
+		}
+		return got
+	}
+
+	assert.Equal(t, []string{"calling llm", "job ran", "retrying", "panic: boom"}, matching("", "", ""))
+	assert.Equal(t, []string{"job ran", "retrying"}, matching("info", "", ""))
+	assert.Equal(t, []string{"calling llm", "retrying"}, matching("", "Agent", ""))
+	assert.Equal(t, []string{"job ran", "retrying"}, matching("", "", "6m"))
+	assert.Equal(t, []string{"retrying"}, matching("warn", "agent,cron", "1h"))
+
+	_, err := newLogFilter("verbose", "", "", logsTestNow)
+	assert.Error(t, err)
+}
+
+func TestFormatEntry(t *testing.T) {
+	line := formatEntry(parseLogLine(
+		`{"level":"warn","component":"agent","caller":"loop.go:10","error":"rate limited","attempt":2,` +
+			`"time":"2026-03-04T11:58:00Z","message":"retrying"}`))
+	ts := time.Date(2026, 3, 4, 11, 58, 0, 0, time.UTC).Local().Format("2006-01-02 15:04:05")
+	assert.Equal(t, ts+` WRN agent retrying attempt=2 error="rate limited"`, line)
+
+	assert.Equal(t, "not json", formatEntry(parseLogLine("not json")))
+}
+
+func writeLogFile(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644))
+}
+
+func appendLogFile(t *testing.T, path string, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestRunLogs_TailsLastMatchingEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gateway.log")
+	writeLogFile(t, path, agentDebugLine, cronInfoLine, agentWarnLine)
+
+	filter, err := newLogFilter("info", "", "", logsTestNow)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	require.NoError(t, runLogs(context.Background(), &out, logsOptions{file: path, lines: 1, filter: filter}))
+	assert.Contains(t, out.String(), "retrying")
+	assert.NotContains(t, out.String(), "job ran")
+
+	err = runLogs(context.Background(), &out, logsOptions{file: filepath.Join(t.TempDir(), "missing.log")})
+	assert.ErrorContains(t, err, "no log file")
+}
+
+// syncBuffer lets the test read what the follower has printed so far.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRunLogs_FollowSurvivesRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gateway.log")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- runLogs(ctx, out, logsOptions{
+			file:         path,
+			follow:       true,
+			lines:        defaultLines,
+			pollInterval: 5 * time.Millisecond,
+		})
+	}()
+
+	waitFor := func(want string) {
+		t.Helper()
+		require.Eventually(t, func() bool { return strings.Contains(out.String(), want) },
+			2*time.Second, 5*time.Millisecond, "waiting for %q in:\n%s", want, out.String())
+	}
+
+	// The file does not exist yet; the follower waits for it.
+	writeLogFile(t, path, `{"level":"info","message":"first"}`)
+	waitFor("first")
+
+	// A line written in two parts is printed once, whole.
+	appendLogFile(t, path, `{"level":"info","message":"sec`)
+	time.Sleep(20 * time.Millisecond)
+	appendLogFile(t, path, `ond"}`+"\n")
+	waitFor("second")
+
+	// Rename-style rotation: the old file gets one more line before a new
+	// file takes its place.
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendLogFile(t, path+".1", `{"level":"info","message":"third"}`+"\n")
+	writeLogFile(t, path, `{"level":"info","message":"fourth"}`)
+	waitFor("fourth")
+	assert.Contains(t, out.String(), "third")
+
+	// Copy-truncate rotation: the file shrinks in place.
+	require.NoError(t, os.Truncate(path, 0))
+	time.Sleep(20 * time.Millisecond)
+	appendLogFile(t, path, `{"level":"info","message":"fifth"}`+"\n")
+	waitFor("fifth")
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, 1, strings.Count(out.String(), "second"))
+	assert.Equal(t, 1, strings.Count(out.String(), "fourth"))
+}
@@ -17,6 +17,7 @@ func NewMigrateCommand() *cobra.Command {
   picoclaw migrate --from openclaw
   picoclaw migrate --dry-run
   picoclaw migrate --refresh
+  picoclaw migrate --merge
   picoclaw migrate --force`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			m := migrate.NewMigrateInstance(opts)
@@ -37,6 +38,8 @@ func NewMigrateCommand() *cobra.Command {
 		"Source to migrate from (e.g., openclaw)")
 	cmd.Flags().BoolVar(&opts.Refresh, "refresh", false,
 		"Re-sync workspace files from OpenClaw (repeatable)")
+	cmd.Flags().BoolVar(&opts.Merge, "merge", false,
+		"Only add what PicoClaw is missing; keep existing files and customized config")
 	cmd.Flags().BoolVar(&opts.ConfigOnly, "config-only", false,
 		"Only migrate config, skip workspace files")
 	cmd.Flags().BoolVar(&opts.WorkspaceOnly, "workspace-only", false,
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// offlineProvider satisfies the agent loop's provider dependency without
+// touching the network, for commands that only inspect the agent setup.
+type offlineProvider struct{}
+
+func (offlineProvider) Chat(
+	context.Context, []providers.Message, []providers.ToolDefinition, string, map[string]any,
+) (*providers.LLMResponse, error) {
+	return nil, fmt.Errorf("model calls are not available in inspection commands")
+}
+
+func (offlineProvider) GetDefaultModel() string { return "" }
+
+// NewOfflineAgentLoop builds the agents the same way the gateway does, but
+// never calls a model. The returned function releases the loop and its bus.
+func NewOfflineAgentLoop(cfg *config.Config) (*agent.AgentLoop, func()) {
+	logger.SetLevel(logger.WARN)
+
+	msgBus := bus.NewMessageBus()
+	agentLoop := agent.NewAgentLoop(cfg, msgBus, offlineProvider{})
+	return agentLoop, func() {
+		agentLoop.Close()
+		msgBus.Close()
+	}
+}
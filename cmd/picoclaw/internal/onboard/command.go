@@ -9,16 +9,22 @@ import (
 var embeddedFiles = picoclaw.OnboardWorkspace
 
 func NewOnboardCommand() *cobra.Command {
-	var encrypt bool
+	var (
+		encrypt bool
+		opts    providerOptions
+	)
 
 	cmd := &cobra.Command{
 		Use:     "onboard",
 		Aliases: []string{"o"},
 		Short:   "Initialize picoclaw configuration and workspace",
+		Example: `  picoclaw onboard
+  picoclaw onboard --provider openrouter --model anthropic/claude-sonnet-4.6 --api-key sk-or-...
+  picoclaw onboard --provider antigravity`,
 		// Run without subcommands → original onboard flow
 		Run: func(cmd *cobra.Command, args []string) {
 			if len(args) == 0 {
-				onboard(encrypt)
+				onboard(encrypt, opts)
 			} else {
 				_ = cmd.Help()
 			}
@@ -28,5 +34,14 @@ func NewOnboardCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&encrypt, "enc", false,
 		"Enable credential encryption (generates SSH key and prompts for passphrase)")
 
+	cmd.Flags().StringVar(&opts.provider, "provider", "",
+		"Provider to set up as the default model (e.g. openrouter, openai, anthropic, ollama)")
+	cmd.Flags().StringVar(&opts.model, "model", "",
+		"Model id for --provider; defaults to the provider's built-in model")
+	cmd.Flags().StringVar(&opts.apiKey, "api-key", "",
+		"API key for --provider; prompted for when omitted on a terminal")
+	cmd.Flags().BoolVar(&opts.noCheck, "no-check", false,
+		"Skip the provider health check after saving the config")
+
 	return cmd
 }
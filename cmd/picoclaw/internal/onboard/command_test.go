@@ -28,5 +28,13 @@ func TestNewOnboardCommand(t *testing.T) {
 	encFlag := cmd.Flags().Lookup("enc")
 	require.NotNil(t, encFlag, "expected --enc flag to be registered")
 	assert.Equal(t, "false", encFlag.DefValue, "--enc should default to false")
+	for _, name := range []string{"provider", "model", "api-key"} {
+		flag := cmd.Flags().Lookup(name)
+		require.NotNil(t, flag, "expected --%s flag to be registered", name)
+		assert.Empty(t, flag.DefValue)
+	}
+	noCheck := cmd.Flags().Lookup("no-check")
+	require.NotNil(t, noCheck, "expected --no-check flag to be registered")
+	assert.Equal(t, "false", noCheck.DefValue)
 	assert.False(t, cmd.HasSubCommands())
 }
@@ -14,7 +14,12 @@ import (
 	"github.com/sipeed/picoclaw/pkg/credential"
 )
 
-func onboard(encrypt bool) {
+func onboard(encrypt bool, opts providerOptions) {
+	if opts.provider == "" && (opts.model != "" || opts.apiKey != "") {
+		fmt.Println("Error: --model and --api-key require --provider")
+		os.Exit(1)
+	}
+
 	configPath := internal.GetConfigPath()
 
 	configExists := false
@@ -72,6 +77,15 @@ func onboard(encrypt bool) {
 	} else {
 		cfg = config.DefaultConfig()
 	}
+
+	var entry *config.ModelConfig
+	if opts.provider != "" {
+		entry, err = configureProvider(cfg, opts, os.Stdout)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	if err := config.SaveConfig(configPath, cfg); err != nil {
 		fmt.Printf("Error saving config: %v\n", err)
 		os.Exit(1)
@@ -80,6 +94,10 @@ func onboard(encrypt bool) {
 	workspace := cfg.WorkspacePath()
 	createWorkspaceTemplates(workspace)
 
+	if entry != nil {
+		finishProviderSetup(entry, opts, os.Stdout)
+	}
+
 	cliui.PrintOnboardComplete(internal.Logo, encrypt, configPath)
 }
 
@@ -0,0 +1,185 @@
+package onboard
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/auth"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const providerCheckTimeout = 30 * time.Second
+
+type providerOptions struct {
+	provider string
+	model    string
+	apiKey   string
+	noCheck  bool
+}
+
+// promptAPIKey asks for an API key without echoing it. It returns "" when
+// stdin is not a terminal, leaving the key to be filled in later.
+var promptAPIKey = func(provider string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", nil
+	}
+	fmt.Printf("Enter %s API key (leave empty to add it later): ", provider)
+	key, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("reading API key: %w", err)
+	}
+	return strings.TrimSpace(string(key)), nil
+}
+
+// configureProvider makes a model from provider the default, creating its
+// model_list entry from the built-in template when needed. The returned
+// entry is the one now referenced by agents.defaults.model_name.
+func configureProvider(cfg *config.Config, opts providerOptions, out io.Writer) (*config.ModelConfig, error) {
+	provider := strings.ToLower(strings.TrimSpace(opts.provider))
+	template := providerTemplate(provider)
+	if template == nil {
+		return nil, fmt.Errorf("unknown provider %q (known providers: %s)",
+			opts.provider, strings.Join(knownProviders(), ", "))
+	}
+
+	modelID := strings.TrimSpace(opts.model)
+	alias := template.ModelName
+	if modelID == "" {
+		modelID = template.Model
+	} else if modelID != template.Model {
+		alias = strings.ReplaceAll(modelID, "/", "-")
+	}
+
+	var entry *config.ModelConfig
+	for _, m := range cfg.ModelList {
+		if m != nil && m.ModelName == alias {
+			entry = m
+			break
+		}
+	}
+	if entry == nil {
+		clone := *template
+		entry = &clone
+		cfg.ModelList = append(cfg.ModelList, entry)
+	}
+	entry.ModelName = alias
+	entry.Provider = template.Provider
+	entry.Model = modelID
+	if entry.APIBase == "" {
+		entry.APIBase = template.APIBase
+	}
+
+	if needsAPIKey(entry) {
+		key := strings.TrimSpace(opts.apiKey)
+		if key == "" && entry.APIKey() == "" {
+			var err error
+			if key, err = promptAPIKey(provider); err != nil {
+				return nil, err
+			}
+		}
+		if key != "" {
+			entry.APIKeys = config.SimpleSecureStrings(key)
+		}
+		if entry.APIKey() == "" {
+			fmt.Fprintf(out, "⚠ No API key set for %s; add one to model '%s' before chatting.\n", provider, alias)
+		}
+	}
+
+	entry.Enabled = true
+	cfg.Agents.Defaults.ModelName = alias
+	fmt.Fprintf(out, "✓ Default model set to '%s' (%s/%s).\n", alias, template.Provider, modelID)
+	return entry, nil
+}
+
+// providerTemplate returns the first built-in model entry for provider.
+func providerTemplate(provider string) *config.ModelConfig {
+	for _, m := range config.DefaultConfig().ModelList {
+		if m != nil && m.Provider == provider {
+			return m
+		}
+	}
+	return nil
+}
+
+func knownProviders() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, m := range config.DefaultConfig().ModelList {
+		if m == nil || m.Provider == "" || seen[m.Provider] {
+			continue
+		}
+		seen[m.Provider] = true
+		names = append(names, m.Provider)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// needsAPIKey reports whether entry authenticates with an API key. OAuth
+// providers log in separately and local servers usually need no key.
+func needsAPIKey(entry *config.ModelConfig) bool {
+	if entry.AuthMethod == "oauth" {
+		return false
+	}
+	if u, err := url.Parse(entry.APIBase); err == nil {
+		switch u.Hostname() {
+		case "localhost", "127.0.0.1", "::1":
+			return false
+		}
+	}
+	return true
+}
+
+// finishProviderSetup runs the steps that need the saved config: an OAuth
+// login when the provider requires one, then a connectivity check. Failures
+// are reported as warnings since the config itself is already usable.
+func finishProviderSetup(entry *config.ModelConfig, opts providerOptions, out io.Writer) {
+	if entry.AuthMethod == "oauth" {
+		if !auth.SupportsLogin(entry.Provider) {
+			fmt.Fprintf(out, "ℹ %s uses OAuth; sign in with its own tooling before chatting.\n", entry.Provider)
+			return
+		}
+		fmt.Fprintf(out, "Starting %s login...\n", entry.Provider)
+		if err := auth.Login(entry.Provider); err != nil {
+			fmt.Fprintf(out, "⚠ Login failed: %v\n", err)
+			fmt.Fprintf(out, "  Retry with: picoclaw auth login --provider %s\n", entry.Provider)
+			return
+		}
+	}
+
+	if opts.noCheck || (needsAPIKey(entry) && entry.APIKey() == "") {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), providerCheckTimeout)
+	defer cancel()
+	if err := checkProvider(ctx, entry); err != nil {
+		fmt.Fprintf(out, "⚠ Health check failed for '%s': %v\n", entry.ModelName, err)
+		fmt.Fprintln(out, "  The config was saved; check the API key and model name, then try again.")
+		return
+	}
+	fmt.Fprintf(out, "✓ %s responded.\n", entry.ModelName)
+}
+
+// checkProvider sends a minimal chat request to confirm the credentials and
+// model are accepted.
+func checkProvider(ctx context.Context, entry *config.ModelConfig) error {
+	provider, modelID, err := providers.CreateProviderFromConfig(entry)
+	if err != nil {
+		return err
+	}
+	_, err = provider.Chat(ctx, []providers.Message{
+		{Role: "user", Content: "Reply with OK."},
+	}, nil, modelID, map[string]any{"max_tokens": 16})
+	return err
+}
@@ -0,0 +1,92 @@
+package onboard
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func stubPromptAPIKey(t *testing.T, key string) *int {
+	t.Helper()
+	calls := 0
+	orig := promptAPIKey
+	promptAPIKey = func(string) (string, error) {
+		calls++
+		return key, nil
+	}
+	t.Cleanup(func() { promptAPIKey = orig })
+	return &calls
+}
+
+func TestConfigureProvider_CustomModel(t *testing.T) {
+	calls := stubPromptAPIKey(t, "")
+	cfg := config.DefaultConfig()
+	var out bytes.Buffer
+
+	entry, err := configureProvider(cfg, providerOptions{
+		provider: "OpenRouter",
+		model:    "anthropic/claude-sonnet-4.6",
+		apiKey:   "sk-or-test",
+	}, &out)
+	require.NoError(t, err)
+
+	assert.Equal(t, "anthropic-claude-sonnet-4.6", entry.ModelName)
+	assert.Equal(t, "openrouter", entry.Provider)
+	assert.Equal(t, "anthropic/claude-sonnet-4.6", entry.Model)
+	assert.Equal(t, "https://openrouter.ai/api/v1", entry.APIBase)
+	assert.Equal(t, "sk-or-test", entry.APIKey())
+	assert.True(t, entry.Enabled)
+	assert.Equal(t, "anthropic-claude-sonnet-4.6", cfg.Agents.Defaults.ModelName)
+	assert.Same(t, entry, cfg.ModelList[len(cfg.ModelList)-1])
+	assert.Zero(t, *calls, "--api-key should skip the prompt")
+}
+
+func TestConfigureProvider_ReusesTemplateEntry(t *testing.T) {
+	stubPromptAPIKey(t, "sk-prompted")
+	cfg := config.DefaultConfig()
+	before := len(cfg.ModelList)
+
+	entry, err := configureProvider(cfg, providerOptions{provider: "deepseek"}, &bytes.Buffer{})
+	require.NoError(t, err)
+
+	assert.Len(t, cfg.ModelList, before)
+	assert.Equal(t, "deepseek-chat", entry.ModelName)
+	assert.Equal(t, "sk-prompted", entry.APIKey())
+	assert.Equal(t, "deepseek-chat", cfg.Agents.Defaults.ModelName)
+}
+
+func TestConfigureProvider_NoKeyNeeded(t *testing.T) {
+	calls := stubPromptAPIKey(t, "unused")
+
+	for _, provider := range []string{"ollama", "antigravity"} {
+		cfg := config.DefaultConfig()
+		var out bytes.Buffer
+		entry, err := configureProvider(cfg, providerOptions{provider: provider}, &out)
+		require.NoError(t, err, provider)
+		assert.Empty(t, entry.APIKey(), provider)
+		assert.NotContains(t, out.String(), "No API key", provider)
+	}
+	assert.Zero(t, *calls)
+}
+
+func TestConfigureProvider_MissingKeyWarns(t *testing.T) {
+	stubPromptAPIKey(t, "")
+	var out bytes.Buffer
+
+	entry, err := configureProvider(config.DefaultConfig(), providerOptions{provider: "openai"}, &out)
+	require.NoError(t, err)
+
+	assert.True(t, entry.Enabled)
+	assert.Contains(t, out.String(), "No API key set for openai")
+}
+
+func TestConfigureProvider_UnknownProvider(t *testing.T) {
+	_, err := configureProvider(config.DefaultConfig(), providerOptions{provider: "nope"}, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown provider")
+	assert.Contains(t, err.Error(), "openrouter")
+}
@@ -0,0 +1,24 @@
+package sessions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSessionsCommand(t *testing.T) {
+	cmd := NewSessionsCommand()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "sessions", cmd.Use)
+	assert.True(t, cmd.HasSubCommands())
+
+	export, _, err := cmd.Find([]string{"export"})
+	require.NoError(t, err)
+	assert.Equal(t, "export <file.jsonl>", export.Use)
+	assert.NotNil(t, export.RunE)
+	for _, flag := range []string{"format", "session", "since", "until", "tools", "system", "include-sensitive"} {
+		assert.NotNil(t, export.Flags().Lookup(flag), "missing --%s", flag)
+	}
+}
@@ -0,0 +1,51 @@
+package sessions
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+)
+
+func newExportCommand() *cobra.Command {
+	var opts exportOptions
+
+	cmd := &cobra.Command{
+		Use:   "export <file.jsonl>",
+		Short: "Export session history as a fine-tuning dataset",
+		Args:  cobra.ExactArgs(1),
+		Example: `  picoclaw sessions export --format openai-ft train.jsonl
+  picoclaw sessions export --format openai-ft --tools strip --since 2026-01-01 train.jsonl
+  picoclaw sessions export --format openai-ft --session agent:main:telegram:direct:42 train.jsonl`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			report, err := exportSessions(cfg.WorkspacePath(), cfg.Agents.Defaults.SessionStore, args[0], opts)
+			if err != nil {
+				return err
+			}
+			printExportReport(cmd.OutOrStdout(), args[0], report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", formatOpenAIFineTune,
+		"Output format; only openai-ft (OpenAI chat fine-tuning JSONL) is supported")
+	cmd.Flags().StringSliceVar(&opts.sessions, "session", nil,
+		"Export only these session keys (repeatable)")
+	cmd.Flags().StringVar(&opts.since, "since", "",
+		"Only turns on or after this date (YYYY-MM-DD or RFC 3339)")
+	cmd.Flags().StringVar(&opts.until, "until", "",
+		"Only turns before the end of this date (YYYY-MM-DD or RFC 3339)")
+	cmd.Flags().StringVar(&opts.tools, "tools", toolsInclude,
+		"include keeps tool calls and results, strip leaves only the conversation text")
+	cmd.Flags().StringVar(&opts.system, "system", "",
+		"System message to put at the start of every example")
+	cmd.Flags().BoolVar(&opts.includeSensitive, "include-sensitive", false,
+		"Also export sessions marked sensitive with /sensitive")
+
+	return cmd
+}
@@ -0,0 +1,395 @@
+package sessions
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/memory"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+const (
+	formatOpenAIFineTune = "openai-ft"
+
+	toolsInclude = "include"
+	toolsStrip   = "strip"
+)
+
+type exportOptions struct {
+	format           string
+	sessions         []string
+	since            string
+	until            string
+	tools            string
+	system           string
+	includeSensitive bool
+}
+
+type exportReport struct {
+	written   int
+	sensitive int
+	empty     int
+	skipped   []skippedSession
+}
+
+type skippedSession struct {
+	key    string
+	reason string
+}
+
+// ftMessage is one message of an OpenAI chat fine-tuning example.
+type ftMessage struct {
+	Role       string       `json:"role"`
+	Content    string       `json:"content,omitempty"`
+	ToolCalls  []ftToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string       `json:"tool_call_id,omitempty"`
+}
+
+type ftToolCall struct {
+	ID       string     `json:"id"`
+	Type     string     `json:"type"`
+	Function ftFunction `json:"function"`
+}
+
+type ftFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type ftExample struct {
+	Messages []ftMessage `json:"messages"`
+}
+
+// exportSessions writes the sessions stored in workspace as a fine-tuning
+// dataset at outPath, one example per session.
+func exportSessions(workspace, backend, outPath string, opts exportOptions) (*exportReport, error) {
+	if opts.format != formatOpenAIFineTune {
+		return nil, fmt.Errorf("unsupported format %q (supported: %s)", opts.format, formatOpenAIFineTune)
+	}
+	if opts.tools != toolsInclude && opts.tools != toolsStrip {
+		return nil, fmt.Errorf("--tools must be %q or %q, got %q", toolsInclude, toolsStrip, opts.tools)
+	}
+	since, err := parseExportTime(opts.since, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --since: %w", err)
+	}
+	until, err := parseExportTime(opts.until, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --until: %w", err)
+	}
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		return nil, fmt.Errorf("--since must be before --until")
+	}
+
+	store, err := openSessionStore(workspace, backend)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	keys := store.ListSessions()
+	slices.Sort(keys)
+	if len(opts.sessions) > 0 {
+		keys = slices.DeleteFunc(keys, func(key string) bool {
+			return !slices.Contains(opts.sessions, key)
+		})
+		for _, want := range opts.sessions {
+			if !slices.Contains(keys, want) {
+				return nil, fmt.Errorf("session %q not found", want)
+			}
+		}
+	}
+	var sensitive *state.Manager
+	if !opts.includeSensitive {
+		sensitive = state.NewManager(workspace)
+	}
+
+	if dir := filepath.Dir(outPath); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	// Write to a temp file first so a failed export never leaves a partial
+	// dataset at the requested path.
+	tmp, err := os.CreateTemp(filepath.Dir(outPath), ".picoclaw-sessions-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	report := &exportReport{}
+	w := bufio.NewWriter(tmp)
+	for _, key := range keys {
+		if sensitive != nil && sensitive.IsSensitive(key) {
+			report.sensitive++
+			continue
+		}
+		history, err := store.GetHistory(context.Background(), key)
+		if err != nil {
+			report.skipped = append(report.skipped, skippedSession{key: key, reason: err.Error()})
+			continue
+		}
+		messages := buildFineTuneMessages(history, since, until, opts)
+		if len(messages) == 0 {
+			report.empty++
+			continue
+		}
+		if err := validateFineTuneExample(messages); err != nil {
+			report.skipped = append(report.skipped, skippedSession{key: key, reason: err.Error()})
+			continue
+		}
+		line, err := json.Marshal(ftExample{Messages: messages})
+		if err != nil {
+			tmp.Close()
+			return nil, err
+		}
+		w.Write(line)
+		w.WriteByte('\n')
+		report.written++
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), outPath); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// openSessionStore opens the session store the agent writes to in
+// workspace/sessions.
+func openSessionStore(workspace, backend string) (memory.Store, error) {
+	dir := filepath.Join(workspace, "sessions")
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("no sessions found in %s", dir)
+	}
+	if strings.EqualFold(strings.TrimSpace(backend), "sqlite") {
+		return memory.NewSQLiteStore(filepath.Join(dir, "sessions.db"))
+	}
+	return memory.NewJSONLStore(dir)
+}
+
+// parseExportTime parses a date or RFC 3339 time. A bare date used as the
+// end of a range means the end of that day.
+func parseExportTime(value string, endOfDay bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date like 2026-01-31 or an RFC 3339 time", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// buildFineTuneMessages turns a session history into the messages of one
+// fine-tuning example. Only turns whose user message falls in [since, until)
+// are kept. Tool calls without results, results without calls and a
+// trailing unanswered user message are dropped, so the example ends with an
+// assistant reply.
+func buildFineTuneMessages(history []providers.Message, since, until time.Time, opts exportOptions) []ftMessage {
+	var out []ftMessage
+	keepTurn := false
+	pending := map[string]bool{} // tool call IDs waiting for a result
+	for _, msg := range history {
+		switch msg.Role {
+		case "user":
+			keepTurn = inRange(msg.CreatedAt, since, until)
+			if keepTurn && strings.TrimSpace(msg.Content) != "" {
+				out = dropUnansweredToolCalls(out, pending)
+				out = append(out, ftMessage{Role: "user", Content: msg.Content})
+			}
+		case "assistant":
+			if !keepTurn || len(out) == 0 {
+				continue
+			}
+			m := ftMessage{Role: "assistant", Content: msg.Content}
+			if opts.tools == toolsInclude {
+				out = dropUnansweredToolCalls(out, pending)
+				for _, tc := range msg.ToolCalls {
+					if call, ok := fineTuneToolCall(tc); ok {
+						m.ToolCalls = append(m.ToolCalls, call)
+						pending[call.ID] = true
+					}
+				}
+			}
+			if strings.TrimSpace(m.Content) == "" && len(m.ToolCalls) == 0 {
+				continue
+			}
+			// With tools stripped, the text around tool calls would show up
+			// as several assistant messages in a row; join them.
+			if last := len(out) - 1; opts.tools == toolsStrip && out[last].Role == "assistant" {
+				out[last].Content += "\n\n" + m.Content
+				continue
+			}
+			out = append(out, m)
+		case "tool":
+			if !keepTurn || opts.tools != toolsInclude || !pending[msg.ToolCallID] {
+				continue
+			}
+			delete(pending, msg.ToolCallID)
+			content := msg.Content
+			if strings.TrimSpace(content) == "" {
+				content = "(no output)"
+			}
+			out = append(out, ftMessage{Role: "tool", Content: content, ToolCallID: msg.ToolCallID})
+		}
+	}
+	out = dropUnansweredToolCalls(out, pending)
+
+	// An example has to end with an assistant reply to learn from.
+	for len(out) > 0 {
+		last := out[len(out)-1]
+		if last.Role == "assistant" && len(last.ToolCalls) == 0 {
+			break
+		}
+		out = out[:len(out)-1]
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	if system := strings.TrimSpace(opts.system); system != "" {
+		out = append([]ftMessage{{Role: "system", Content: system}}, out...)
+	}
+	return out
+}
+
+// dropUnansweredToolCalls removes the calls still in pending from the
+// latest assistant message, since their results never arrived, and clears
+// pending. The message goes too when nothing is left of it.
+func dropUnansweredToolCalls(out []ftMessage, pending map[string]bool) []ftMessage {
+	if len(pending) == 0 {
+		return out
+	}
+	for i := len(out) - 1; i >= 0; i-- {
+		if out[i].Role != "assistant" || len(out[i].ToolCalls) == 0 {
+			continue
+		}
+		out[i].ToolCalls = slices.DeleteFunc(out[i].ToolCalls, func(tc ftToolCall) bool {
+			return pending[tc.ID]
+		})
+		if len(out[i].ToolCalls) == 0 && strings.TrimSpace(out[i].Content) == "" {
+			out = slices.Delete(out, i, i+1)
+		}
+		break
+	}
+	clear(pending)
+	return out
+}
+
+func fineTuneToolCall(tc providers.ToolCall) (ftToolCall, bool) {
+	name, args := tc.Name, ""
+	if tc.Function != nil {
+		name, args = tc.Function.Name, tc.Function.Arguments
+	} else if tc.Arguments != nil {
+		data, err := json.Marshal(tc.Arguments)
+		if err != nil {
+			return ftToolCall{}, false
+		}
+		args = string(data)
+	}
+	if tc.ID == "" || name == "" {
+		return ftToolCall{}, false
+	}
+	if strings.TrimSpace(args) == "" {
+		args = "{}"
+	}
+	return ftToolCall{ID: tc.ID, Type: "function", Function: ftFunction{Name: name, Arguments: args}}, true
+}
+
+func inRange(createdAt *time.Time, since, until time.Time) bool {
+	if since.IsZero() && until.IsZero() {
+		return true
+	}
+	if createdAt == nil {
+		return false
+	}
+	return (since.IsZero() || !createdAt.Before(since)) && (until.IsZero() || createdAt.Before(until))
+}
+
+// validateFineTuneExample checks an example against the chat fine-tuning
+// format: an optional leading system message, then a user message, tool
+// results right after the assistant message that called them, and an
+// assistant reply at the end.
+func validateFineTuneExample(messages []ftMessage) error {
+	start := 0
+	if len(messages) > 0 && messages[0].Role == "system" {
+		start = 1
+	}
+	if len(messages) <= start || messages[start].Role != "user" {
+		return errors.New("example must start with a user message")
+	}
+	last := messages[len(messages)-1]
+	if last.Role != "assistant" || len(last.ToolCalls) > 0 {
+		return errors.New("example must end with an assistant reply")
+	}
+
+	var open map[string]bool // calls of the latest assistant message without a result yet
+	for i, m := range messages[start:] {
+		if m.Role != "tool" && len(open) > 0 {
+			return fmt.Errorf("message %d: tool calls of the previous assistant message have no result", i+start+1)
+		}
+		switch m.Role {
+		case "user":
+			if strings.TrimSpace(m.Content) == "" {
+				return fmt.Errorf("message %d: user message is empty", i+start+1)
+			}
+		case "assistant":
+			if strings.TrimSpace(m.Content) == "" && len(m.ToolCalls) == 0 {
+				return fmt.Errorf("message %d: assistant message has neither content nor tool calls", i+start+1)
+			}
+			open = make(map[string]bool, len(m.ToolCalls))
+			for _, tc := range m.ToolCalls {
+				if tc.ID == "" || tc.Type != "function" || tc.Function.Name == "" || !json.Valid([]byte(tc.Function.Arguments)) {
+					return fmt.Errorf("message %d: malformed tool call %q", i+start+1, tc.ID)
+				}
+				open[tc.ID] = true
+			}
+		case "tool":
+			if !open[m.ToolCallID] {
+				return fmt.Errorf("message %d: tool result %q does not answer a preceding tool call", i+start+1, m.ToolCallID)
+			}
+			delete(open, m.ToolCallID)
+		default:
+			return fmt.Errorf("message %d: unexpected role %q", i+start+1, m.Role)
+		}
+	}
+	return nil
+}
+
+func printExportReport(w io.Writer, outPath string, report *exportReport) {
+	fmt.Fprintf(w, "✓ Exported %d sessions to %s\n", report.written, outPath)
+	if report.sensitive > 0 {
+		fmt.Fprintf(w, "  Left out %d sessions marked sensitive (use --include-sensitive to export them)\n", report.sensitive)
+	}
+	if report.empty > 0 {
+		fmt.Fprintf(w, "  Left out %d sessions with no complete exchange in range\n", report.empty)
+	}
+	for _, s := range report.skipped {
+		fmt.Fprintf(w, "  Skipped %s: %s\n", s.key, s.reason)
+	}
+}
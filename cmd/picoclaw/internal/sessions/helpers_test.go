@@ -0,0 +1,181 @@
+package sessions
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/memory"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func at(day int) *time.Time {
+	t := time.Date(2026, 3, day, 12, 0, 0, 0, time.Local)
+	return &t
+}
+
+// toolSession is a turn with a tool call, followed by a turn from a later day.
+func toolSession() []providers.Message {
+	return []providers.Message{
+		{Role: "user", Content: "what's in notes.txt?", CreatedAt: at(1)},
+		{Role: "assistant", Content: "Let me look.", ToolCalls: []providers.ToolCall{{
+			ID: "call_1", Type: "function",
+			Function: &providers.FunctionCall{Name: "read_file", Arguments: `{"path":"notes.txt"}`},
+		}}, CreatedAt: at(1)},
+		{Role: "tool", Content: "buy milk", ToolCallID: "call_1", CreatedAt: at(1)},
+		{Role: "assistant", Content: "It says to buy milk.", CreatedAt: at(1)},
+		{Role: "user", Content: "thanks", CreatedAt: at(5)},
+		{Role: "assistant", Content: "You're welcome!", CreatedAt: at(5)},
+	}
+}
+
+func TestBuildFineTuneMessages_IncludesToolCalls(t *testing.T) {
+	got := buildFineTuneMessages(toolSession(), time.Time{}, time.Time{}, exportOptions{tools: toolsInclude, system: "You are helpful."})
+	require.NoError(t, validateFineTuneExample(got))
+
+	roles := make([]string, len(got))
+	for i, m := range got {
+		roles[i] = m.Role
+	}
+	assert.Equal(t, []string{"system", "user", "assistant", "tool", "assistant", "user", "assistant"}, roles)
+	assert.Equal(t, []ftToolCall{{
+		ID: "call_1", Type: "function", Function: ftFunction{Name: "read_file", Arguments: `{"path":"notes.txt"}`},
+	}}, got[2].ToolCalls)
+	assert.Equal(t, "call_1", got[3].ToolCallID)
+}
+
+func TestBuildFineTuneMessages_StripsToolsAndJoinsReplies(t *testing.T) {
+	got := buildFineTuneMessages(toolSession(), time.Time{}, time.Time{}, exportOptions{tools: toolsStrip})
+	require.NoError(t, validateFineTuneExample(got))
+	assert.Equal(t, []ftMessage{
+		{Role: "user", Content: "what's in notes.txt?"},
+		{Role: "assistant", Content: "Let me look.\n\nIt says to buy milk."},
+		{Role: "user", Content: "thanks"},
+		{Role: "assistant", Content: "You're welcome!"},
+	}, got)
+}
+
+func TestBuildFineTuneMessages_FiltersTurnsByDate(t *testing.T) {
+	since, err := parseExportTime("2026-03-02", false)
+	require.NoError(t, err)
+	got := buildFineTuneMessages(toolSession(), since, time.Time{}, exportOptions{tools: toolsInclude})
+	assert.Equal(t, []ftMessage{
+		{Role: "user", Content: "thanks"},
+		{Role: "assistant", Content: "You're welcome!"},
+	}, got)
+
+	until, err := parseExportTime("2026-03-01", true)
+	require.NoError(t, err)
+	got = buildFineTuneMessages(toolSession(), time.Time{}, until, exportOptions{tools: toolsInclude})
+	require.NoError(t, validateFineTuneExample(got))
+	assert.Equal(t, "It says to buy milk.", got[len(got)-1].Content)
+}
+
+func TestBuildFineTuneMessages_RepairsInterruptedTurns(t *testing.T) {
+	history := []providers.Message{
+		{Role: "tool", Content: "orphan", ToolCallID: "call_0"},
+		{Role: "user", Content: "run the tests"},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{
+			ID: "call_1", Type: "function", Function: &providers.FunctionCall{Name: "exec", Arguments: `{}`},
+		}}},
+		{Role: "user", Content: "never mind, hi"},
+		{Role: "assistant", Content: "Hi!"},
+		{Role: "user", Content: "unanswered"},
+	}
+	got := buildFineTuneMessages(history, time.Time{}, time.Time{}, exportOptions{tools: toolsInclude})
+	require.NoError(t, validateFineTuneExample(got))
+	assert.Equal(t, []ftMessage{
+		{Role: "user", Content: "run the tests"},
+		{Role: "user", Content: "never mind, hi"},
+		{Role: "assistant", Content: "Hi!"},
+	}, got)
+}
+
+func TestValidateFineTuneExample(t *testing.T) {
+	call := ftToolCall{ID: "c1", Type: "function", Function: ftFunction{Name: "exec", Arguments: "{}"}}
+	tests := []struct {
+		name     string
+		messages []ftMessage
+		wantErr  bool
+	}{
+		{"valid", []ftMessage{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}}, false},
+		{"starts with assistant", []ftMessage{{Role: "assistant", Content: "hello"}}, true},
+		{"ends with user", []ftMessage{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "a"}, {Role: "user", Content: "b"}}, true},
+		{"unanswered call", []ftMessage{
+			{Role: "user", Content: "hi"}, {Role: "assistant", ToolCalls: []ftToolCall{call}}, {Role: "assistant", Content: "done"},
+		}, true},
+		{"orphan result", []ftMessage{
+			{Role: "user", Content: "hi"}, {Role: "tool", Content: "x", ToolCallID: "c1"}, {Role: "assistant", Content: "done"},
+		}, true},
+		{"bad arguments", []ftMessage{
+			{Role: "user", Content: "hi"},
+			{Role: "assistant", ToolCalls: []ftToolCall{{ID: "c1", Type: "function", Function: ftFunction{Name: "exec", Arguments: "{"}}}},
+			{Role: "tool", Content: "x", ToolCallID: "c1"},
+			{Role: "assistant", Content: "done"},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFineTuneExample(tt.messages)
+			assert.Equal(t, tt.wantErr, err != nil, "err = %v", err)
+		})
+	}
+}
+
+func TestExportSessions_WritesJSONLAndSkipsSensitive(t *testing.T) {
+	workspace := t.TempDir()
+	store, err := memory.NewJSONLStore(filepath.Join(workspace, "sessions"))
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, store.SetHistory(ctx, "agent:main:telegram:direct:1", toolSession()))
+	require.NoError(t, store.SetHistory(ctx, "agent:main:telegram:direct:2", []providers.Message{
+		{Role: "user", Content: "my password is hunter2"},
+		{Role: "assistant", Content: "Noted."},
+	}))
+	require.NoError(t, store.SetHistory(ctx, "agent:main:telegram:direct:3", []providers.Message{
+		{Role: "user", Content: "no reply yet"},
+	}))
+	require.NoError(t, state.NewManager(workspace).SetSensitive("agent:main:telegram:direct:2", true))
+
+	out := filepath.Join(t.TempDir(), "train.jsonl")
+	report, err := exportSessions(workspace, "", out, exportOptions{format: formatOpenAIFineTune, tools: toolsInclude})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.written)
+	assert.Equal(t, 1, report.sensitive)
+	assert.Equal(t, 1, report.empty)
+
+	f, err := os.Open(out)
+	require.NoError(t, err)
+	defer f.Close()
+	var lines []ftExample
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ex ftExample
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ex))
+		lines = append(lines, ex)
+	}
+	require.Len(t, lines, 1)
+	assert.Equal(t, "what's in notes.txt?", lines[0].Messages[0].Content)
+
+	report, err = exportSessions(workspace, "", out, exportOptions{
+		format: formatOpenAIFineTune, tools: toolsInclude, includeSensitive: true,
+		sessions: []string{"agent:main:telegram:direct:2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.written)
+
+	_, err = exportSessions(workspace, "", out, exportOptions{format: "csv", tools: toolsInclude})
+	assert.ErrorContains(t, err, "unsupported format")
+	_, err = exportSessions(workspace, "", out, exportOptions{
+		format: formatOpenAIFineTune, tools: toolsInclude, sessions: []string{"missing"},
+	})
+	assert.ErrorContains(t, err, `session "missing" not found`)
+}
@@ -7,11 +7,13 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/skills"
 )
 
 type deps struct {
 	workspace    string
+	cfg          *config.Config
 	skillsLoader *skills.SkillsLoader
 }
 
@@ -27,6 +29,7 @@ func NewSkillsCommand() *cobra.Command {
 				return fmt.Errorf("error loading config: %w", err)
 			}
 
+			d.cfg = cfg
 			d.workspace = cfg.WorkspacePath()
 
 			// get global config directory and builtin skills directory
@@ -56,6 +59,13 @@ func NewSkillsCommand() *cobra.Command {
 		return d.workspace, nil
 	}
 
+	configFn := func() (*config.Config, error) {
+		if d.cfg == nil {
+			return nil, fmt.Errorf("config is not initialized")
+		}
+		return d.cfg, nil
+	}
+
 	cmd.AddCommand(
 		newListCommand(loaderFn),
 		newInstallCommand(),
@@ -64,6 +74,7 @@ func NewSkillsCommand() *cobra.Command {
 		newRemoveCommand(),
 		newSearchCommand(),
 		newShowCommand(loaderFn),
+		newDoctorCommand(loaderFn, configFn),
 	)
 
 	return cmd
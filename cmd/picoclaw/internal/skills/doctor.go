@@ -0,0 +1,33 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newDoctorCommand(
+	loaderFn func() (*skills.SkillsLoader, error),
+	configFn func() (*config.Config, error),
+) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "doctor",
+		Short:   "Diagnose broken skills",
+		Args:    cobra.NoArgs,
+		Example: `picoclaw skills doctor`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			loader, err := loaderFn()
+			if err != nil {
+				return err
+			}
+			cfg, err := configFn()
+			if err != nil {
+				return err
+			}
+			return skillsDoctorCmd(loader, cfg)
+		},
+	}
+
+	return cmd
+}
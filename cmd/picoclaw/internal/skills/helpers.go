@@ -3,6 +3,7 @@ package skills
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -88,6 +89,9 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, target string)
 		if rmErr != nil {
 			fmt.Printf("\u2717 Failed to remove partial install: %v\n", rmErr)
 		}
+		if errors.Is(err, skills.ErrInvalidSkill) {
+			return fmt.Errorf("✗ failed to install skill: registry archive for %q is not a valid skill: %w", target, err)
+		}
 		return fmt.Errorf("✗ failed to install skill: %w", err)
 	}
 
@@ -277,14 +281,23 @@ func skillsSearchCmd(query string) {
 	}
 
 	registryMgr := skills.NewRegistryManagerFromToolsConfig(cfg.Tools.Skills)
+	cache := skills.NewSearchCacheFromToolsConfig(cfg.Tools.Skills)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	results, err := registryMgr.SearchAll(ctx, query, skillsSearchMaxResults)
 	if err != nil {
-		fmt.Printf("✗ Failed to fetch skills list: %v\n", err)
-		return
+		stale, fetchedAt, ok := cache.GetStale(query)
+		if !ok {
+			fmt.Printf("✗ Failed to fetch skills list: %v\n", err)
+			return
+		}
+		fmt.Printf("⚠ Skill registries are unreachable: %v\n", err)
+		fmt.Printf("  Showing cached results from %s.\n", fetchedAt.Local().Format("2006-01-02 15:04"))
+		results = stale
+	} else if len(results) > 0 {
+		cache.Put(query, results)
 	}
 
 	if len(results) == 0 {
@@ -318,6 +331,44 @@ func skillsShowCmd(loader *skills.SkillsLoader, skillName string) {
 	fmt.Println(content)
 }
 
+// skillsDoctorCmd prints a pass/warn/fail line per installed skill with the
+// problems found and how to fix them. It fails when any skill is broken.
+func skillsDoctorCmd(loader *skills.SkillsLoader, cfg *config.Config) error {
+	results := loader.Diagnose(cfg)
+	if len(results) == 0 {
+		fmt.Println("No skills installed.")
+		return nil
+	}
+
+	fmt.Println("\nSkill Doctor:")
+	fmt.Println("-------------")
+	counts := make(map[skills.DiagnosisStatus]int)
+	for _, d := range results {
+		counts[d.Status]++
+		mark := "✓"
+		switch d.Status {
+		case skills.DiagnosisWarn:
+			mark = "!"
+		case skills.DiagnosisFail:
+			mark = "✗"
+		}
+		fmt.Printf("  %s %s (%s)\n", mark, d.Name, d.Source)
+		for _, p := range d.Problems {
+			fmt.Printf("    %s: %s\n", p.Status, p.Message)
+			if p.Hint != "" {
+				fmt.Printf("      → %s\n", p.Hint)
+			}
+		}
+	}
+	fmt.Printf("\n%d passed, %d with warnings, %d failed\n",
+		counts[skills.DiagnosisPass], counts[skills.DiagnosisWarn], counts[skills.DiagnosisFail])
+
+	if n := counts[skills.DiagnosisFail]; n > 0 {
+		return fmt.Errorf("%d skill(s) failed the check", n)
+	}
+	return nil
+}
+
 func copyDirectory(src, dst string) error {
 	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -1,18 +1,32 @@
 package status
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 )
 
 func NewStatusCommand() *cobra.Command {
+	var opts statusOptions
+
 	cmd := &cobra.Command{
 		Use:     "status",
 		Aliases: []string{"s"},
 		Short:   "Show picoclaw status",
+		Example: `  picoclaw status
+  picoclaw status --json
+  picoclaw status --watch --interval 10s`,
 		Run: func(cmd *cobra.Command, args []string) {
-			statusCmd()
+			runStatus(cmd.OutOrStdout(), opts)
 		},
 	}
 
+	cmd.Flags().BoolVar(&opts.json, "json", false,
+		"Print status as JSON (credentials are reported as presence only)")
+	cmd.Flags().BoolVarP(&opts.watch, "watch", "w", false,
+		"Refresh the status until interrupted")
+	cmd.Flags().DurationVar(&opts.interval, "interval", 5*time.Second,
+		"Refresh interval for --watch")
+
 	return cmd
 }
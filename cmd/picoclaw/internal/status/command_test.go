@@ -26,4 +26,13 @@ func TestNewStatusCommand(t *testing.T) {
 
 	assert.Nil(t, cmd.PersistentPreRun)
 	assert.Nil(t, cmd.PersistentPostRun)
+
+	for _, name := range []string{"json", "watch"} {
+		flag := cmd.Flags().Lookup(name)
+		require.NotNil(t, flag, "expected --%s flag to be registered", name)
+		assert.Equal(t, "false", flag.DefValue)
+	}
+	interval := cmd.Flags().Lookup("interval")
+	require.NotNil(t, interval, "expected --interval flag to be registered")
+	assert.Equal(t, "5s", interval.DefValue)
 }
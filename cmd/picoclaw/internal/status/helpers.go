@@ -1,145 +1,340 @@
 package status
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/cliui"
 	"github.com/sipeed/picoclaw/pkg/auth"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/health"
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
-func statusCmd() {
-	cfg, err := internal.LoadConfig()
+type statusOptions struct {
+	json     bool
+	watch    bool
+	interval time.Duration
+}
+
+// statusSnapshot is the serializable form of `picoclaw status`. It never
+// carries secrets: credentials are reported as presence booleans only.
+type statusSnapshot struct {
+	Version       string           `json:"version"`
+	Build         string           `json:"build,omitempty"`
+	ConfigPath    string           `json:"config_path"`
+	ConfigExists  bool             `json:"config_exists"`
+	WorkspacePath string           `json:"workspace_path"`
+	WorkspaceOK   bool             `json:"workspace_exists"`
+	Model         string           `json:"model"`
+	Providers     []providerStatus `json:"providers,omitempty"`
+	Auth          []authStatus     `json:"auth,omitempty"`
+	Channels      []string         `json:"channels"`
+	SafeMode      bool             `json:"safe_mode"`
+	Cron          cronStatus       `json:"cron"`
+	Gateway       gatewayStatus    `json:"gateway"`
+	CollectedAt   time.Time        `json:"collected_at"`
+}
+
+type providerStatus struct {
+	Name       string `json:"name"`
+	Protocol   string `json:"protocol"`
+	Configured bool   `json:"configured"`
+	APIBase    string `json:"api_base,omitempty"`
+}
+
+type authStatus struct {
+	Provider string `json:"provider"`
+	Method   string `json:"method"`
+	State    string `json:"state"`
+}
+
+type cronStatus struct {
+	Jobs        int `json:"jobs"`
+	EnabledJobs int `json:"enabled_jobs"`
+}
+
+type gatewayStatus struct {
+	Running bool   `json:"running"`
+	PID     int    `json:"pid,omitempty"`
+	Address string `json:"address,omitempty"`
+	Health  string `json:"health,omitempty"`
+	Uptime  string `json:"uptime,omitempty"`
+	Error   string `json:"error,omitempty"`
+
+	Checks map[string]health.Check `json:"checks,omitempty"`
+}
+
+// statusProviders lists the providers shown by `picoclaw status`, keyed by the
+// protocol their model_list entries resolve to.
+var statusProviders = []struct {
+	name     string
+	protocol string
+	local    bool
+}{
+	{"OpenRouter API", "openrouter", false},
+	{"Anthropic API", "anthropic", false},
+	{"OpenAI API", "openai", false},
+	{"Gemini API", "gemini", false},
+	{"Cohere API", "cohere", false},
+	{"Zhipu API", "zhipu", false},
+	{"Qwen API", "qwen", false},
+	{"Groq API", "groq", false},
+	{"Moonshot API", "moonshot", false},
+	{"DeepSeek API", "deepseek", false},
+	{"VolcEngine API", "volcengine", false},
+	{"Nvidia API", "nvidia", false},
+	{"Fireworks API", "fireworks", false},
+	{"vLLM / local", "vllm", true},
+	{"Ollama", "ollama", true},
+}
+
+func runStatus(out io.Writer, opts statusOptions) {
+	if !opts.watch {
+		printSnapshot(out, opts)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	interval := opts.interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if !opts.json {
+			// Clear the screen so the snapshot redraws in place.
+			fmt.Fprint(out, "\033[H\033[2J")
+		}
+		printSnapshot(out, opts)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func printSnapshot(out io.Writer, opts statusOptions) {
+	snapshot, err := collectStatus()
 	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
+		if opts.json {
+			_ = json.NewEncoder(out).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		fmt.Fprintf(out, "Error loading config: %v\n", err)
 		return
 	}
 
+	if opts.json {
+		enc := json.NewEncoder(out)
+		// Watch mode emits one compact object per line so the stream stays
+		// parseable; a single snapshot is indented for reading.
+		if !opts.watch {
+			enc.SetIndent("", "  ")
+		}
+		_ = enc.Encode(snapshot)
+		return
+	}
+	cliui.PrintStatus(out, snapshot.report())
+}
+
+func collectStatus() (*statusSnapshot, error) {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	configPath := internal.GetConfigPath()
 	build, _ := config.FormatBuildInfo()
 
 	_, configStatErr := os.Stat(configPath)
-	configOK := configStatErr == nil
-
 	workspace := cfg.WorkspacePath()
 	_, wsErr := os.Stat(workspace)
-	wsOK := wsErr == nil
 
-	report := cliui.StatusReport{
-		Logo:          internal.Logo,
+	s := &statusSnapshot{
 		Version:       config.FormatVersion(),
 		Build:         build,
 		ConfigPath:    configPath,
-		ConfigOK:      configOK,
+		ConfigExists:  configStatErr == nil,
 		WorkspacePath: workspace,
-		WorkspaceOK:   wsOK,
+		WorkspaceOK:   wsErr == nil,
 		Model:         cfg.Agents.Defaults.GetModelName(),
+		Channels:      enabledChannels(cfg),
+		SafeMode:      cfg.Agents.Defaults.SafeMode,
+		Cron:          collectCron(workspace),
+		Gateway:       probeGateway(internal.GetPicoclawHome()),
+		CollectedAt:   time.Now().UTC(),
 	}
 
-	if configOK {
-		// PicoClaw moved to a model-centric configuration (model_list). Status should
-		// not depend on a legacy cfg.Providers field (which may not exist under some
-		// build tags). We infer provider availability from model_list entries.
-		hasProtocolKey := func(protocol string) bool {
-			want := providers.NormalizeProvider(protocol)
-			for _, m := range cfg.ModelList {
-				if m == nil {
-					continue
-				}
-				got, _ := providers.ExtractProtocol(m)
-				if got == want && m.APIKey() != "" {
-					return true
-				}
+	if s.ConfigExists {
+		s.Providers = collectProviders(cfg)
+		s.Auth = collectAuth()
+	}
+	return s, nil
+}
+
+// collectProviders infers provider availability from model_list entries.
+// PicoClaw moved to a model-centric configuration, so status must not depend
+// on a legacy cfg.Providers field (which may not exist under some build tags).
+// The reserved "local-model" entry also counts as the vLLM endpoint.
+func collectProviders(cfg *config.Config) []providerStatus {
+	rows := make([]providerStatus, 0, len(statusProviders))
+	for _, p := range statusProviders {
+		row := providerStatus{Name: p.name, Protocol: p.protocol}
+		want := providers.NormalizeProvider(p.protocol)
+		for _, m := range cfg.ModelList {
+			if m == nil {
+				continue
 			}
-			return false
-		}
-		findLocalModelBase := func(modelName string) (string, bool) {
-			for _, m := range cfg.ModelList {
-				if m == nil {
-					continue
-				}
-				if m.ModelName == modelName && m.APIBase != "" {
-					return m.APIBase, true
-				}
+			got, _ := providers.ExtractProtocol(m)
+			isLocalModel := p.protocol == "vllm" && m.ModelName == "local-model"
+			if got != want && !isLocalModel {
+				continue
 			}
-			return "", false
-		}
-		findProtocolBase := func(protocol string) (string, bool) {
-			want := providers.NormalizeProvider(protocol)
-			for _, m := range cfg.ModelList {
-				if m == nil {
-					continue
+			if p.local {
+				if m.APIBase != "" && (isLocalModel || !row.Configured) {
+					row.Configured, row.APIBase = true, m.APIBase
 				}
-				got, _ := providers.ExtractProtocol(m)
-				if got == want && m.APIBase != "" {
-					return m.APIBase, true
+				if isLocalModel && row.Configured {
+					break
 				}
+				continue
+			}
+			if m.APIKey() != "" {
+				row.Configured = true
+				break
 			}
-			return "", false
 		}
+		rows = append(rows, row)
+	}
+	return rows
+}
 
-		hasOpenRouter := hasProtocolKey("openrouter")
-		hasAnthropic := hasProtocolKey("anthropic")
-		hasOpenAI := hasProtocolKey("openai")
-		hasGemini := hasProtocolKey("gemini")
-		hasZhipu := hasProtocolKey("zhipu")
-		hasQwen := hasProtocolKey("qwen")
-		hasGroq := hasProtocolKey("groq")
-		hasMoonshot := hasProtocolKey("moonshot")
-		hasDeepSeek := hasProtocolKey("deepseek")
-		hasVolcEngine := hasProtocolKey("volcengine")
-		hasNvidia := hasProtocolKey("nvidia")
-
-		// Local endpoints: allow both the special reserved name and protocol-based entries.
-		vllmBase, hasVLLM := findLocalModelBase("local-model")
-		if !hasVLLM {
-			vllmBase, hasVLLM = findProtocolBase("vllm")
+func collectAuth() []authStatus {
+	store, _ := auth.LoadStore()
+	if store == nil {
+		return nil
+	}
+	var rows []authStatus
+	for provider, cred := range store.Credentials {
+		state := "authenticated"
+		if cred.IsExpired() {
+			state = "expired"
+		} else if cred.NeedsRefresh() {
+			state = "needs refresh"
 		}
-		ollamaBase, hasOllama := findProtocolBase("ollama")
+		rows = append(rows, authStatus{Provider: provider, Method: cred.AuthMethod, State: state})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Provider < rows[j].Provider })
+	return rows
+}
 
-		val := func(enabled bool, extra ...string) string {
-			if enabled {
-				if len(extra) > 0 && extra[0] != "" {
-					return "✓ " + extra[0]
-				}
-				return "✓"
-			}
-			return "not set"
+func enabledChannels(cfg *config.Config) []string {
+	names := []string{}
+	for name, bc := range cfg.Channels {
+		if bc != nil && bc.Enabled {
+			names = append(names, name)
 		}
+	}
+	sort.Strings(names)
+	return names
+}
 
-		report.Providers = []cliui.ProviderRow{
-			{Name: "OpenRouter API", Val: val(hasOpenRouter)},
-			{Name: "Anthropic API", Val: val(hasAnthropic)},
-			{Name: "OpenAI API", Val: val(hasOpenAI)},
-			{Name: "Gemini API", Val: val(hasGemini)},
-			{Name: "Zhipu API", Val: val(hasZhipu)},
-			{Name: "Qwen API", Val: val(hasQwen)},
-			{Name: "Groq API", Val: val(hasGroq)},
-			{Name: "Moonshot API", Val: val(hasMoonshot)},
-			{Name: "DeepSeek API", Val: val(hasDeepSeek)},
-			{Name: "VolcEngine API", Val: val(hasVolcEngine)},
-			{Name: "Nvidia API", Val: val(hasNvidia)},
-			{Name: "vLLM / local", Val: val(hasVLLM, vllmBase)},
-			{Name: "Ollama", Val: val(hasOllama, ollamaBase)},
-		}
+func collectCron(workspace string) cronStatus {
+	storePath := filepath.Join(workspace, "cron", "jobs.json")
+	if _, err := os.Stat(storePath); err != nil {
+		return cronStatus{}
+	}
+	cs := cron.NewCronService(storePath, nil)
+	return cronStatus{
+		Jobs:        len(cs.ListJobs(true)),
+		EnabledJobs: len(cs.ListJobs(false)),
+	}
+}
 
-		store, _ := auth.LoadStore()
-		if store != nil && len(store.Credentials) > 0 {
-			for provider, cred := range store.Credentials {
-				st := "authenticated"
-				if cred.IsExpired() {
-					st = "expired"
-				} else if cred.NeedsRefresh() {
-					st = "needs refresh"
-				}
-				report.OAuthLines = append(report.OAuthLines,
-					fmt.Sprintf("%s (%s): %s", provider, cred.AuthMethod, st))
+// probeGateway reports the running gateway, if any, along with the live
+// checks (such as MCP server health) from its health endpoint.
+func probeGateway(homePath string) gatewayStatus {
+	probe, err := internal.ProbeGateway(homePath)
+	if probe == nil {
+		return gatewayStatus{}
+	}
+	st := gatewayStatus{
+		Running: true,
+		PID:     probe.PID,
+		Address: probe.Address,
+	}
+	if err != nil {
+		st.Error = err.Error()
+		return st
+	}
+	st.Health = probe.Health.Status
+	st.Uptime = probe.Health.Uptime
+	st.Checks = probe.Health.Checks
+	return st
+}
+
+// report converts the snapshot into the human-readable status layout.
+func (s *statusSnapshot) report() cliui.StatusReport {
+	r := cliui.StatusReport{
+		Logo:          internal.Logo,
+		Version:       s.Version,
+		Build:         s.Build,
+		ConfigPath:    s.ConfigPath,
+		ConfigOK:      s.ConfigExists,
+		WorkspacePath: s.WorkspacePath,
+		WorkspaceOK:   s.WorkspaceOK,
+		Model:         s.Model,
+	}
+	for _, p := range s.Providers {
+		val := "not set"
+		if p.Configured {
+			val = "✓"
+			if p.APIBase != "" {
+				val += " " + p.APIBase
 			}
 		}
+		r.Providers = append(r.Providers, cliui.ProviderRow{Name: p.Name, Val: val})
+	}
+	for _, a := range s.Auth {
+		r.OAuthLines = append(r.OAuthLines, fmt.Sprintf("%s (%s): %s", a.Provider, a.Method, a.State))
 	}
 
-	cliui.PrintStatus(report)
+	gateway := "Gateway: not running"
+	switch g := s.Gateway; {
+	case g.Running && g.Error != "":
+		gateway = fmt.Sprintf("Gateway: pid %d at %s, health check failed: %s", g.PID, g.Address, g.Error)
+	case g.Running:
+		gateway = fmt.Sprintf("Gateway: %s (pid %d at %s, up %s)", g.Health, g.PID, g.Address, g.Uptime)
+	}
+	channels := "none"
+	if len(s.Channels) > 0 {
+		channels = strings.Join(s.Channels, ", ")
+	}
+	r.RuntimeLines = []string{
+		gateway,
+		"Channels: " + channels,
+		fmt.Sprintf("Cron: %d job(s), %d enabled", s.Cron.Jobs, s.Cron.EnabledJobs),
+	}
+	if s.SafeMode {
+		r.RuntimeLines = append(r.RuntimeLines, "Safe mode: on (only read-only tools can run)")
+	}
+	if check, ok := s.Gateway.Checks["mcp"]; ok {
+		r.RuntimeLines = append(r.RuntimeLines, "MCP: "+check.Message)
+	}
+	return r
 }
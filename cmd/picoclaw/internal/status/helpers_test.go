@@ -2,7 +2,7 @@ package status
 
 import (
 	"bytes"
-	"io"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,30 +11,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/config"
 )
 
-func captureStdout(t *testing.T, fn func()) string {
-	t.Helper()
-
-	oldStdout := os.Stdout
-	r, w, err := os.Pipe()
-	if err != nil {
-		t.Fatalf("os.Pipe() error = %v", err)
-	}
-	os.Stdout = w
-
-	fn()
-
-	_ = w.Close()
-	os.Stdout = oldStdout
-	defer r.Close()
-
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, r); err != nil {
-		t.Fatalf("io.Copy() error = %v", err)
-	}
-	return buf.String()
-}
-
-func TestStatusCmd_RecognizesProviderFieldWithoutModelPrefix(t *testing.T) {
+func TestRunStatus_RecognizesProviderFieldWithoutModelPrefix(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "config.json")
 	workspace := filepath.Join(tmpDir, "workspace")
@@ -78,8 +55,13 @@ func TestStatusCmd_RecognizesProviderFieldWithoutModelPrefix(t *testing.T) {
 		t.Fatalf("config.SaveConfig() error = %v", err)
 	}
 
-	output := captureStdout(t, statusCmd)
+	var out bytes.Buffer
+	runStatus(&out, statusOptions{})
+	output := out.String()
 
+	if !strings.Contains(output, "Model: gpt-5.4") {
+		t.Fatalf("status output missing the model: %s", output)
+	}
 	if !strings.Contains(output, "OpenAI API: \u2713") {
 		t.Fatalf("status output missing OpenAI provider: %s", output)
 	}
@@ -87,3 +69,75 @@ func TestStatusCmd_RecognizesProviderFieldWithoutModelPrefix(t *testing.T) {
 		t.Fatalf("status output missing Qwen provider: %s", output)
 	}
 }
+
+func TestRunStatus_JSONReportsPresenceWithoutSecrets(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	workspace := filepath.Join(tmpDir, "workspace")
+	if err := os.MkdirAll(workspace, 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() error = %v", err)
+	}
+
+	t.Setenv(config.EnvConfig, configPath)
+	t.Setenv(config.EnvHome, tmpDir)
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{ModelName: "gpt-5.4", Workspace: workspace},
+		},
+		ModelList: []*config.ModelConfig{
+			{
+				ModelName: "gpt-5.4",
+				Provider:  "openai",
+				Model:     "gpt-5.4",
+				APIKeys:   config.SimpleSecureStrings("sk-super-secret"),
+				Enabled:   true,
+			},
+			{
+				ModelName: "llama3",
+				Provider:  "ollama",
+				Model:     "llama3",
+				APIBase:   "http://localhost:11434/v1",
+				Enabled:   true,
+			},
+		},
+		Channels: config.ChannelsConfig{
+			"telegram": &config.Channel{Enabled: true, Type: config.ChannelTelegram},
+			"discord":  &config.Channel{Enabled: false, Type: config.ChannelDiscord},
+		},
+	}
+	if err := config.SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("config.SaveConfig() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	runStatus(&out, statusOptions{json: true})
+
+	if strings.Contains(out.String(), "sk-super-secret") {
+		t.Fatalf("status JSON leaked an API key: %s", out.String())
+	}
+
+	var snapshot statusSnapshot
+	if err := json.Unmarshal(out.Bytes(), &snapshot); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v\n%s", err, out.String())
+	}
+	if snapshot.Model != "gpt-5.4" || !snapshot.ConfigExists || !snapshot.WorkspaceOK {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+	configured := map[string]providerStatus{}
+	for _, p := range snapshot.Providers {
+		configured[p.Protocol] = p
+	}
+	if !configured["openai"].Configured || configured["anthropic"].Configured {
+		t.Fatalf("providers = %+v", snapshot.Providers)
+	}
+	if got := configured["ollama"]; !got.Configured || got.APIBase != "http://localhost:11434/v1" {
+		t.Fatalf("ollama = %+v", got)
+	}
+	if len(snapshot.Channels) != 1 || snapshot.Channels[0] != "telegram" {
+		t.Fatalf("channels = %v", snapshot.Channels)
+	}
+	if snapshot.Gateway.Running {
+		t.Fatalf("gateway should not be running: %+v", snapshot.Gateway)
+	}
+}
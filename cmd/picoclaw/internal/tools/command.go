@@ -0,0 +1,20 @@
+package tools
+
+import "github.com/spf13/cobra"
+
+func NewToolsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tools",
+		Short: "Inspect the tools available to the agent",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		newListCommand(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestNewToolsCommand(t *testing.T) {
+	cmd := NewToolsCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "tools", cmd.Use)
+	assert.True(t, cmd.HasSubCommands())
+
+	list, _, err := cmd.Find([]string{"list"})
+	require.NoError(t, err)
+	assert.Equal(t, "list", list.Use)
+	assert.NotNil(t, list.RunE)
+}
+
+func findRow(rows []toolRow, name string) (toolRow, bool) {
+	for _, row := range rows {
+		if row.Name == name {
+			return row, true
+		}
+	}
+	return toolRow{}, false
+}
+
+func TestBuildToolRows_StatusAndPrerequisites(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Tools.Web.Enabled = true
+	cfg.Tools.Web.Provider = ""
+	cfg.Tools.Web.DuckDuckGo.Enabled = false
+	cfg.Tools.Web.Sogou.Enabled = false
+	cfg.Tools.Web.Brave.Enabled = true
+	cfg.Tools.WriteFile.Enabled = false
+	cfg.Tools.I2C.Enabled = true
+	cfg.Tools.Spawn.Enabled = true
+	cfg.Tools.Subagent.Enabled = false
+
+	registered := map[string]string{
+		"read_file": "Read the contents of a file.\nMore detail.",
+		"i2c":       "I2C tool",
+		"reaction":  "React to a message",
+	}
+	rows := buildToolRows(cfg, registered)
+
+	readFile, ok := findRow(rows, "read_file")
+	require.True(t, ok)
+	assert.Equal(t, statusEnabled, readFile.Status)
+	assert.Equal(t, "Read the contents of a file.", readFile.Description)
+
+	writeFile, _ := findRow(rows, "write_file")
+	assert.Equal(t, statusDisabled, writeFile.Status)
+
+	// Brave is enabled but has no API key, so nothing can serve searches.
+	webSearch, _ := findRow(rows, "web_search")
+	assert.Equal(t, statusBlocked, webSearch.Status)
+	assert.Equal(t, "no search backend configured", webSearch.Note)
+
+	spawn, _ := findRow(rows, "spawn")
+	assert.Equal(t, statusBlocked, spawn.Status)
+	assert.Equal(t, "requires tools.subagent", spawn.Note)
+
+	i2c, _ := findRow(rows, "i2c")
+	if runtime.GOOS == "linux" {
+		assert.Equal(t, statusEnabled, i2c.Status)
+	} else {
+		assert.Equal(t, statusBlocked, i2c.Status)
+	}
+
+	reaction, ok := findRow(rows, "reaction")
+	require.True(t, ok, "registered tools outside the catalog should be listed")
+	assert.Equal(t, statusEnabled, reaction.Status)
+}
+
+func TestPrintToolList_ShowsActiveWebSearchBackend(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Tools.Web.Enabled = true
+	cfg.Tools.Web.Provider = ""
+	cfg.Tools.Web.Brave.Enabled = true
+	cfg.Tools.Web.Brave.SetAPIKeys([]string{"brave-key"})
+
+	var out bytes.Buffer
+	printToolList(&out, buildToolRows(cfg, map[string]string{"web_search": "Search"}), webSearchBackends(cfg))
+
+	assert.Contains(t, out.String(), "backend: brave")
+	assert.Regexp(t, `brave\s+ready \(active\)`, out.String())
+	assert.Regexp(t, `tavily\s+not configured`, out.String())
+}
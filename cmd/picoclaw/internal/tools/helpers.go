@@ -0,0 +1,273 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+	picotools "github.com/sipeed/picoclaw/pkg/tools"
+)
+
+const (
+	statusEnabled  = "enabled"
+	statusDisabled = "disabled"
+	statusBlocked  = "blocked"
+
+	maxDescriptionWidth = 60
+)
+
+// toolRow is one line of `picoclaw tools list`.
+type toolRow struct {
+	Name        string
+	Status      string
+	Note        string
+	Description string
+}
+
+// catalogEntry describes a config-gated tool so it can be listed even when
+// it is disabled and therefore absent from the agent's registry. Gateway
+// tools are registered by the gateway after the agent is built, so their
+// absence from a standalone agent is expected.
+type catalogEntry struct {
+	Name        string
+	ConfigKey   string
+	Description string
+	Gateway     bool
+}
+
+var toolCatalog = []catalogEntry{
+	{"read_file", "read_file", "Read file content from the workspace", false},
+	{"write_file", "write_file", "Create or overwrite files in the workspace", false},
+	{"list_dir", "list_dir", "List directory contents", false},
+	{"edit_file", "edit_file", "Apply targeted edits to existing files", false},
+	{"append_file", "append_file", "Append content to a file", false},
+	{"exec", "exec", "Run shell commands in the workspace", false},
+	{"git", "git", "Run git operations on workspace repositories", false},
+	{"python", "python", "Run Python scripts in a sandboxed scratch directory", false},
+	{"memory", "memory", "Remember and recall named facts across sessions", false},
+	{"cron", "cron", "Schedule reminders and recurring jobs", true},
+	{"plan", "plan", "Keep a per-session checklist for multi-step tasks", false},
+	{"reminder", "reminder", "Set, list and cancel one-time chat reminders", true},
+	{"timer", "timer", "Run short in-session countdowns and stopwatches", true},
+	{"web_search", "web", "Search the web using the configured backends", false},
+	{"web_fetch", "web_fetch", "Fetch the contents of a web page", false},
+	{"http_request", "http", "Call HTTP APIs with any method, headers and body", false},
+	{"message", "message", "Send a message to the active chat", false},
+	{"send_file", "send_file", "Send a file to the active chat", false},
+	{"send_tts", "send_tts", "Send a synthesized voice message", false},
+	{"image_gen", "image_gen", "Generate an image and send it to the active chat", false},
+	{"load_image", "load_image", "Load an image for the model to inspect", false},
+	{"ocr", "ocr", "Extract text from an image file or URL", false},
+	{"weather", "weather", "Get current weather and forecasts from Open-Meteo", false},
+	{"market", "market", "Get stock and crypto quotes and price history", false},
+	{"send_email", "send_email", "Send an email over SMTP, with optional attachments", false},
+	{"translate", "translate", "Translate text with a cheap model or LibreTranslate", false},
+	{"find_skills", "find_skills", "Search skill registries", false},
+	{"install_skill", "install_skill", "Install a skill into the workspace", false},
+	{"spawn", "spawn", "Launch a background subagent", false},
+	{"spawn_status", "spawn_status", "Query spawned subagents", false},
+	{"i2c", "i2c", "Interact with I2C devices", false},
+	{"spi", "spi", "Interact with SPI devices", false},
+	{"serial", "serial", "Interact with serial ports", false},
+	{"clipboard", "clipboard", "Read and write the desktop clipboard", false},
+	{"screenshot", "screenshot", "Capture the desktop screen for the model to see", false},
+}
+
+// webSearchProviders lists the web search backends in display order.
+var webSearchProviders = []string{
+	"brave", "tavily", "duckduckgo", "perplexity", "kagi",
+	"searxng", "gemini", "sogou", "glm_search", "baidu_search",
+}
+
+// registeredTools builds the agent the same way the gateway does and returns
+// the description of every tool registered on the default agent, keyed by
+// tool name.
+func registeredTools(cfg *config.Config) map[string]string {
+	agentLoop, closeLoop := internal.NewOfflineAgentLoop(cfg)
+	defer closeLoop()
+
+	registered := make(map[string]string)
+	toolsInfo, _ := agentLoop.GetStartupInfo()["tools"].(map[string]any)
+	names, _ := toolsInfo["names"].([]string)
+	defaultAgent := agentLoop.GetRegistry().GetDefaultAgent()
+	for _, name := range names {
+		description := ""
+		if defaultAgent != nil {
+			if tool, ok := defaultAgent.Tools.Get(name); ok {
+				description = tool.Description()
+			}
+		}
+		registered[name] = description
+	}
+	return registered
+}
+
+// buildToolRows merges the tools actually registered on the agent with the
+// config-gated catalog, explaining why configured tools are unavailable.
+func buildToolRows(cfg *config.Config, registered map[string]string) []toolRow {
+	rows := make([]toolRow, 0, len(toolCatalog)+len(registered))
+	seen := make(map[string]bool, len(toolCatalog))
+
+	for _, entry := range toolCatalog {
+		seen[entry.Name] = true
+		description, isRegistered := registered[entry.Name]
+		if description == "" {
+			description = entry.Description
+		}
+		row := toolRow{Name: entry.Name, Status: statusDisabled, Description: summarizeDescription(description)}
+
+		if cfg.Tools.IsToolEnabled(entry.ConfigKey) {
+			row.Status, row.Note = toolPrerequisites(cfg, entry.Name)
+			if row.Status == statusEnabled && !isRegistered && !entry.Gateway {
+				row.Status = statusBlocked
+				row.Note = "failed to initialize, check the gateway log"
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	extra := make([]string, 0, len(registered))
+	for name := range registered {
+		if !seen[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		rows = append(rows, toolRow{
+			Name:        name,
+			Status:      statusEnabled,
+			Description: summarizeDescription(registered[name]),
+		})
+	}
+
+	return rows
+}
+
+// toolPrerequisites checks the runtime requirements of a tool that is
+// enabled in config.
+func toolPrerequisites(cfg *config.Config, name string) (string, string) {
+	switch name {
+	case "i2c", "spi":
+		if runtime.GOOS != "linux" {
+			return statusBlocked, "requires Linux"
+		}
+	case "serial":
+		switch runtime.GOOS {
+		case "linux", "darwin", "windows":
+		default:
+			return statusBlocked, "unsupported platform"
+		}
+	case "find_skills", "install_skill":
+		if !cfg.Tools.IsToolEnabled("skills") {
+			return statusBlocked, "requires tools.skills"
+		}
+	case "spawn", "spawn_status":
+		if !cfg.Tools.IsToolEnabled("subagent") {
+			return statusBlocked, "requires tools.subagent"
+		}
+	case "web_search":
+		active, _ := picotools.ResolveWebSearchProviderName(picotools.WebSearchToolOptionsFromConfig(cfg), "")
+		if active == "" {
+			return statusBlocked, "no search backend configured"
+		}
+		return statusEnabled, "backend: " + active
+	case "cron":
+		if cfg.Tools.Cron.AllowCommand && !cfg.Tools.IsToolEnabled("exec") {
+			return statusEnabled, "shell jobs need tools.exec"
+		}
+	case "exec":
+		if cfg.Tools.Exec.AllowRemote {
+			return statusEnabled, "remote channels allowed"
+		}
+		return statusEnabled, "local channels only"
+	}
+	return statusEnabled, ""
+}
+
+// webSearchBackend is the readiness of one web search backend.
+type webSearchBackend struct {
+	Name   string
+	Ready  bool
+	Active bool
+}
+
+func webSearchBackends(cfg *config.Config) []webSearchBackend {
+	if !cfg.Tools.IsToolEnabled("web") {
+		return nil
+	}
+	opts := picotools.WebSearchToolOptionsFromConfig(cfg)
+	active, _ := picotools.ResolveWebSearchProviderName(opts, "")
+	backends := make([]webSearchBackend, 0, len(webSearchProviders))
+	for _, name := range webSearchProviders {
+		backends = append(backends, webSearchBackend{
+			Name:   name,
+			Ready:  picotools.WebSearchProviderReady(opts, name),
+			Active: name == active,
+		})
+	}
+	return backends
+}
+
+// summarizeDescription keeps the first line of a tool description and caps
+// its width so the table stays readable.
+func summarizeDescription(description string) string {
+	description, _, _ = strings.Cut(strings.TrimSpace(description), "\n")
+	if len(description) > maxDescriptionWidth {
+		description = strings.TrimSpace(description[:maxDescriptionWidth-3]) + "..."
+	}
+	return description
+}
+
+// mcpHealthLine returns the MCP server health reported by a running gateway,
+// or "" when no gateway is running or it reports no MCP check.
+func mcpHealthLine(probe *internal.GatewayProbe) string {
+	if probe == nil || probe.Health == nil {
+		return ""
+	}
+	check, ok := probe.Health.Checks["mcp"]
+	if !ok {
+		return ""
+	}
+	return check.Message
+}
+
+func printToolList(w io.Writer, rows []toolRow, backends []webSearchBackend) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATUS\tNOTES\tDESCRIPTION")
+	enabled := 0
+	for _, row := range rows {
+		if row.Status == statusEnabled {
+			enabled++
+		}
+		note := row.Note
+		if note == "" {
+			note = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", row.Name, row.Status, note, row.Description)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n%d of %d tools enabled.\n", enabled, len(rows))
+
+	if len(backends) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nWeb search backends:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, backend := range backends {
+		state := "not configured"
+		if backend.Ready {
+			state = "ready"
+		}
+		if backend.Active {
+			state += " (active)"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", backend.Name, state)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+)
+
+func newListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List tools with their enabled status and prerequisites",
+		Example: `picoclaw tools list`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			printToolList(out, buildToolRows(cfg, registeredTools(cfg)), webSearchBackends(cfg))
+
+			// MCP tools only exist inside a running gateway, so their health
+			// comes from its health endpoint rather than from config.
+			probe, _ := internal.ProbeGateway(internal.GetPicoclawHome())
+			if line := mcpHealthLine(probe); line != "" {
+				fmt.Fprintf(out, "\nMCP servers (running gateway): %s\n", line)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,59 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func NewWatchCommand() *cobra.Command {
+	var opts watchOptions
+
+	cmd := &cobra.Command{
+		Use:   "watch <path>",
+		Short: "Run the agent whenever a file or directory changes",
+		Long: `Watch a file, or every file under a directory, and run the agent each time
+one changes. The prompt template is filled in with the changed file's path
+({{file}}) and a unified diff of the change ({{diff}}). Changes are batched
+until the files have been quiet for --debounce.
+
+The reply is printed and, with --deliver, also sent to a channel.`,
+		Example: `picoclaw watch ./logs -m "Summarize new errors in {{file}}: {{diff}}"
+picoclaw watch notes.md -m "Review this edit: {{diff}}" --deliver telegram:123456789`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if strings.TrimSpace(opts.template) == "" {
+				return fmt.Errorf("--message is required")
+			}
+			if opts.debounce <= 0 {
+				return fmt.Errorf("--debounce must be positive")
+			}
+			if opts.deliver != "" {
+				if _, _, err := parseDeliverTarget(opts.deliver); err != nil {
+					return err
+				}
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			return watchCmd(ctx, args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.template, "message", "m", "",
+		"Prompt template; {{file}} and {{diff}} are replaced for each change")
+	cmd.Flags().StringVar(&opts.deliver, "deliver", "", "Also send each reply to channel:chat_id")
+	cmd.Flags().DurationVar(&opts.debounce, "debounce", 2*time.Second,
+		"Wait this long after the last change before running the agent")
+	cmd.Flags().StringVarP(&opts.sessionKey, "session", "s", "watch:default", "Session key")
+	cmd.Flags().StringVar(&opts.model, "model", "", "Model to use")
+	cmd.Flags().BoolVarP(&opts.debug, "debug", "d", false, "Enable debug logging")
+
+	_ = cmd.MarkFlagRequired("message")
+
+	return cmd
+}
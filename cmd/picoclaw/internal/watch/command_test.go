@@ -0,0 +1,31 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWatchCommand(t *testing.T) {
+	cmd := NewWatchCommand()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "watch <path>", cmd.Use)
+	assert.NotNil(t, cmd.RunE)
+	for _, flag := range []string{"message", "deliver", "debounce", "session", "model", "debug"} {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), "missing --%s", flag)
+	}
+	assert.Equal(t, "m", cmd.Flags().Lookup("message").Shorthand)
+}
+
+func TestWatchCommand_RejectsBadDeliverTarget(t *testing.T) {
+	cmd := NewWatchCommand()
+	cmd.SetArgs([]string{t.TempDir(), "-m", "{{diff}}", "--deliver", "telegram"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "channel:chat_id")
+}
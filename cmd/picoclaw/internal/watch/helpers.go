@@ -0,0 +1,410 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	// maxSnapshotBytes bounds the files whose contents are kept to diff
+	// against. Larger files are reported as changed without a diff.
+	maxSnapshotBytes = 256 * 1024
+	// maxDiffBytes bounds the diff put into the prompt.
+	maxDiffBytes = 16 * 1024
+
+	deletedDiff     = "(file deleted)"
+	noDiffAvailable = "(binary or large file changed; no diff available)"
+	truncatedNote   = "\n[diff truncated]"
+)
+
+type watchOptions struct {
+	template   string
+	deliver    string
+	debounce   time.Duration
+	sessionKey string
+	model      string
+	debug      bool
+}
+
+// change is a file that changed since the agent last ran for it.
+type change struct {
+	file string // absolute path
+	diff string
+}
+
+func watchCmd(ctx context.Context, path string, opts watchOptions) error {
+	w, err := newFileWatcher(path, opts.debounce)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	logger.ConfigureFromEnv()
+	if opts.debug {
+		logger.SetLevel(logger.DEBUG)
+		fmt.Fprintln(os.Stderr, "🔍 Debug mode enabled")
+	}
+	if opts.model != "" {
+		cfg.Agents.Defaults.ModelName = opts.model
+	}
+
+	provider, modelID, err := providers.CreateProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("error creating provider: %w", err)
+	}
+	if modelID != "" {
+		cfg.Agents.Defaults.ModelName = modelID
+	}
+
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
+	defer agentLoop.Close()
+
+	var (
+		channelManager  *channels.Manager
+		channel, chatID string
+	)
+	if opts.deliver != "" {
+		channel, chatID, _ = parseDeliverTarget(opts.deliver)
+		// The manager gets its own bus: replies are sent synchronously, so
+		// each delivery result can be reported.
+		channelBus := bus.NewMessageBus()
+		defer channelBus.Close()
+		channelManager, err = channels.NewManager(cfg, channelBus, nil)
+		if err != nil {
+			return fmt.Errorf("error creating channel manager: %w", err)
+		}
+		if _, ok := channelManager.GetChannel(channel); !ok {
+			return fmt.Errorf("channel %q is not enabled", channel)
+		}
+		if err = channelManager.StartAll(ctx); err != nil {
+			return fmt.Errorf("error starting channels: %w", err)
+		}
+		defer channelManager.StopAll(context.Background())
+	}
+
+	fmt.Printf("%s Watching %s (Ctrl+C to stop)\n", internal.Logo, w.root)
+	return w.run(ctx, func(ctx context.Context, c change) {
+		fmt.Printf("\n→ %s changed\n", c.file)
+		response, err := agentLoop.ProcessDirect(ctx, renderPrompt(opts.template, c), opts.sessionKey)
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Printf("✗ Error: %v\n", err)
+			}
+			return
+		}
+		fmt.Printf("%s %s\n", internal.Logo, response)
+		if channelManager == nil || strings.TrimSpace(response) == "" {
+			return
+		}
+		err = channelManager.SendMessage(ctx, bus.OutboundMessage{
+			Channel:   channel,
+			ChatID:    chatID,
+			Content:   response,
+			Proactive: true,
+		})
+		if err != nil {
+			fmt.Printf("✗ Not delivered: %v\n", err)
+		} else {
+			fmt.Printf("✓ Delivered to %s:%s\n", channel, chatID)
+		}
+	})
+}
+
+// parseDeliverTarget splits a --deliver value of the form channel:chat_id.
+func parseDeliverTarget(target string) (channel, chatID string, err error) {
+	channel, chatID, ok := strings.Cut(strings.TrimSpace(target), ":")
+	channel, chatID = strings.TrimSpace(channel), strings.TrimSpace(chatID)
+	if !ok || channel == "" || chatID == "" {
+		return "", "", fmt.Errorf("--deliver must be channel:chat_id, got %q", target)
+	}
+	return channel, chatID, nil
+}
+
+// renderPrompt fills in the {{file}} and {{diff}} placeholders of template.
+func renderPrompt(template string, c change) string {
+	return strings.NewReplacer("{{file}}", c.file, "{{diff}}", c.diff).Replace(template)
+}
+
+// fileWatcher reports the files that changed under root, once they have
+// been quiet for the debounce interval.
+type fileWatcher struct {
+	root     string
+	dir      bool
+	debounce time.Duration
+	fsw      *fsnotify.Watcher
+	// snapshots holds the last seen content of each file, nil when the file
+	// is too large or not text.
+	snapshots map[string][]byte
+}
+
+func newFileWatcher(path string, debounce time.Duration) (*fileWatcher, error) {
+	root, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("cannot watch %s: %w", path, err)
+	}
+	if !info.IsDir() && !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("cannot watch %s: not a regular file or directory", path)
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error creating file watcher: %w", err)
+	}
+	w := &fileWatcher{
+		root:      root,
+		dir:       info.IsDir(),
+		debounce:  debounce,
+		fsw:       fsw,
+		snapshots: make(map[string][]byte),
+	}
+	if w.dir {
+		_, err = w.addTree(root)
+	} else {
+		// Editors often save by writing a new file and renaming it over the
+		// old one, which drops a watch on the file itself, so the parent
+		// directory is watched instead.
+		err = fsw.Add(filepath.Dir(root))
+		w.snapshot(root)
+	}
+	if err != nil {
+		fsw.Close()
+		return nil, fmt.Errorf("cannot watch %s: %w", path, err)
+	}
+	return w, nil
+}
+
+func (w *fileWatcher) Close() error {
+	return w.fsw.Close()
+}
+
+// addTree watches dir and the directories below it, skipping hidden ones,
+// and snapshots the files it finds. It returns those files.
+func (w *fileWatcher) addTree(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && ignoredName(d.Name()) {
+				return filepath.SkipDir
+			}
+			return w.fsw.Add(path)
+		}
+		if d.Type().IsRegular() && !ignoredName(d.Name()) {
+			w.snapshot(path)
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// ignoredName reports whether a file or directory under a watched directory
+// is left alone: hidden entries and editor swap and backup files.
+func ignoredName(name string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+		return true
+	}
+	switch filepath.Ext(name) {
+	case ".swp", ".swx", ".tmp":
+		return true
+	}
+	return false
+}
+
+// relevant reports whether an event on path concerns a watched file.
+func (w *fileWatcher) relevant(path string) bool {
+	if !w.dir {
+		return path == w.root
+	}
+	return !ignoredName(filepath.Base(path))
+}
+
+func (w *fileWatcher) snapshot(path string) {
+	content, diffable, exists := readDiffable(path)
+	switch {
+	case !exists:
+		delete(w.snapshots, path)
+	case diffable:
+		w.snapshots[path] = content
+	default:
+		w.snapshots[path] = nil
+	}
+}
+
+// readDiffable returns the content of path when it is a text file small
+// enough to diff. exists reports whether path is a regular file at all.
+func readDiffable(path string) (content []byte, diffable, exists bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, false, false
+	}
+	if info.Size() > maxSnapshotBytes {
+		return nil, false, true
+	}
+	content, err = os.ReadFile(path)
+	if err != nil {
+		return nil, false, false
+	}
+	if bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content) {
+		return nil, false, true
+	}
+	return content, true, true
+}
+
+// change compares path with its snapshot and updates the snapshot. It
+// reports false when the content did not change, such as after a touch.
+func (w *fileWatcher) change(path string) (change, bool) {
+	before, had := w.snapshots[path]
+	after, diffable, exists := readDiffable(path)
+	switch {
+	case !exists:
+		if !had {
+			return change{}, false
+		}
+		delete(w.snapshots, path)
+		return change{file: path, diff: deletedDiff}, true
+	case !diffable:
+		w.snapshots[path] = nil
+		return change{file: path, diff: noDiffAvailable}, true
+	}
+	w.snapshots[path] = after
+	if had && before != nil && bytes.Equal(before, after) {
+		return change{}, false
+	}
+	return change{file: path, diff: unifiedDiff(w.displayName(path), before, after, had)}, true
+}
+
+// displayName is the name a file goes by in its diff: its path below the
+// watched directory, or its base name when a single file is watched.
+func (w *fileWatcher) displayName(path string) string {
+	if w.dir {
+		if rel, err := filepath.Rel(w.root, path); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.Base(path)
+}
+
+func unifiedDiff(name string, before, after []byte, existed bool) string {
+	from := "a/" + name
+	if !existed {
+		from = "/dev/null"
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(before)),
+		B:        difflib.SplitLines(string(after)),
+		FromFile: from,
+		ToFile:   "b/" + name,
+		Context:  3,
+	})
+	if err != nil {
+		return noDiffAvailable
+	}
+	diff = strings.TrimRight(diff, "\n")
+	if len(diff) > maxDiffBytes {
+		cut := maxDiffBytes
+		for cut > 0 && !utf8.RuneStart(diff[cut]) {
+			cut--
+		}
+		diff = diff[:cut] + truncatedNote
+	}
+	return diff
+}
+
+// run waits for changes and calls handle for each changed file, in path
+// order, once no event has arrived for the debounce interval. It returns
+// when ctx is done.
+func (w *fileWatcher) run(ctx context.Context, handle func(context.Context, change)) error {
+	pending := make(map[string]struct{})
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod || !w.relevant(event.Name) {
+				continue
+			}
+			if w.dir && event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					files, err := w.addTree(event.Name)
+					if err != nil {
+						logger.WarnCF("watch", "Failed to watch new directory", map[string]any{
+							"path":  event.Name,
+							"error": err.Error(),
+						})
+					}
+					for _, file := range files {
+						// Files that arrived with the directory are new, so
+						// they are diffed against nothing.
+						delete(w.snapshots, file)
+						pending[file] = struct{}{}
+					}
+					timer.Reset(w.debounce)
+					continue
+				}
+			}
+			pending[event.Name] = struct{}{}
+			timer.Reset(w.debounce)
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return nil
+			}
+			logger.WarnCF("watch", "File watcher error", map[string]any{"error": err.Error()})
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			clear(pending)
+			slices.Sort(paths)
+			for _, path := range paths {
+				if ctx.Err() != nil {
+					return nil
+				}
+				if c, ok := w.change(path); ok {
+					handle(ctx, c)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDeliverTarget(t *testing.T) {
+	channel, chatID, err := parseDeliverTarget("telegram:-100123:45")
+	require.NoError(t, err)
+	assert.Equal(t, "telegram", channel)
+	assert.Equal(t, "-100123:45", chatID)
+
+	for _, bad := range []string{"", "telegram", ":123", "telegram: "} {
+		_, _, err := parseDeliverTarget(bad)
+		assert.Error(t, err, "target %q", bad)
+	}
+}
+
+func TestRenderPrompt(t *testing.T) {
+	got := renderPrompt("Review {{file}}:\n{{diff}}\n({{file}})", change{file: "/srv/notes.md", diff: "+hello"})
+	assert.Equal(t, "Review /srv/notes.md:\n+hello\n(/srv/notes.md)", got)
+}
+
+func TestIgnoredName(t *testing.T) {
+	for _, name := range []string{".git", ".notes.md.swp", "notes.md~", "upload.tmp", "x.swx"} {
+		assert.True(t, ignoredName(name), name)
+	}
+	for _, name := range []string{"notes.md", "app.log", "data"} {
+		assert.False(t, ignoredName(name), name)
+	}
+}
+
+func TestFileWatcher_ChangeDiffsAgainstSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(path, []byte("one\ntwo\n"), 0o644))
+
+	w, err := newFileWatcher(dir, time.Second)
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, ok := w.change(path)
+	assert.False(t, ok, "an untouched file is not a change")
+
+	require.NoError(t, os.WriteFile(path, []byte("one\nthree\n"), 0o644))
+	c, ok := w.change(path)
+	require.True(t, ok)
+	assert.Equal(t, path, c.file)
+	assert.Contains(t, c.diff, "--- a/notes.md\n+++ b/notes.md")
+	assert.Contains(t, c.diff, "-two\n+three")
+
+	created := filepath.Join(dir, "new.txt")
+	require.NoError(t, os.WriteFile(created, []byte("hi\n"), 0o644))
+	c, ok = w.change(created)
+	require.True(t, ok)
+	assert.Contains(t, c.diff, "--- /dev/null\n+++ b/new.txt")
+
+	require.NoError(t, os.WriteFile(created, []byte{0, 1, 2}, 0o644))
+	c, ok = w.change(created)
+	require.True(t, ok)
+	assert.Equal(t, noDiffAvailable, c.diff)
+
+	require.NoError(t, os.Remove(path))
+	c, ok = w.change(path)
+	require.True(t, ok)
+	assert.Equal(t, deletedDiff, c.diff)
+	_, ok = w.change(path)
+	assert.False(t, ok, "a deletion is reported once")
+}
+
+func TestUnifiedDiff_Truncates(t *testing.T) {
+	after := strings.Repeat("a line of text\n", maxDiffBytes/10)
+	diff := unifiedDiff("big.txt", nil, []byte(after), false)
+	assert.LessOrEqual(t, len(diff), maxDiffBytes+len(truncatedNote))
+	assert.True(t, strings.HasSuffix(diff, truncatedNote))
+}
+
+func TestFileWatcher_RunDebouncesChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(path, []byte("start\n"), 0o644))
+
+	w, err := newFileWatcher(path, 100*time.Millisecond)
+	require.NoError(t, err)
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan change, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- w.run(ctx, func(_ context.Context, c change) { changes <- c })
+	}()
+
+	// Writes to other files in the directory are not reported.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.log"), []byte("x\n"), 0o644))
+	for _, content := range []string{"start\nfirst\n", "start\nfirst\nsecond\n"} {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	select {
+	case c := <-changes:
+		assert.Equal(t, w.root, c.file)
+		assert.Contains(t, c.diff, "+first\n+second")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+	select {
+	case c := <-changes:
+		t.Fatalf("unexpected second change: %+v", c)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestFileWatcher_RunWatchesNewDirectories(t *testing.T) {
+	dir := t.TempDir()
+	w, err := newFileWatcher(dir, 50*time.Millisecond)
+	require.NoError(t, err)
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan change, 10)
+	go w.run(ctx, func(_ context.Context, c change) { changes <- c })
+
+	sub := filepath.Join(dir, "reports")
+	require.NoError(t, os.Mkdir(sub, 0o755))
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "q3.csv"), []byte("a,b\n"), 0o644))
+
+	select {
+	case c := <-changes:
+		assert.Equal(t, filepath.Join(sub, "q3.csv"), c.file)
+		assert.Contains(t, c.diff, "+++ b/reports/q3.csv")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported for a file in a new directory")
+	}
+}
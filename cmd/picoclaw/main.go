@@ -17,18 +17,25 @@ import (
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/agent"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/agents"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/auth"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/backup"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/bench"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/cliui"
 	configcmd "github.com/sipeed/picoclaw/cmd/picoclaw/internal/config"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/cron"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/gateway"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/logs"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/mcp"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/migrate"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/model"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/onboard"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/sessions"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/skills"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/status"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/tools"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/version"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/watch"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/updater"
 )
@@ -78,6 +85,21 @@ func initTermuxSSL() {
 	}
 }
 
+// configPathFlag applies --config as soon as it is parsed, so it takes effect
+// for every subcommand regardless of which persistent hooks they define.
+type configPathFlag struct{}
+
+func (configPathFlag) String() string { return "" }
+
+func (configPathFlag) Set(path string) error {
+	if path == "" {
+		return fmt.Errorf("config path must not be empty")
+	}
+	return internal.SetConfigPath(path)
+}
+
+func (configPathFlag) Type() string { return "path" }
+
 func syncCliUIColor(root *cobra.Command) {
 	no, _ := root.PersistentFlags().GetBool("no-color")
 	cliui.Init(no || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb")
@@ -121,6 +143,8 @@ picoclaw --no-color status`,
 
 	cmd.PersistentFlags().BoolVar(&rootNoColor, "no-color", false,
 		"Disable colors (boxed layout unchanged)")
+	cmd.PersistentFlags().Var(configPathFlag{}, "config",
+		"Path to config.json (overrides "+config.EnvConfig+")")
 
 	cmd.SetHelpFunc(func(c *cobra.Command, _ []string) {
 		syncCliUIColor(c.Root())
@@ -131,14 +155,22 @@ picoclaw --no-color status`,
 		configcmd.NewConfigCommand(),
 		onboard.NewOnboardCommand(),
 		agent.NewAgentCommand(),
+		agents.NewAgentsCommand(),
 		auth.NewAuthCommand(),
 		gateway.NewGatewayCommand(),
 		status.NewStatusCommand(),
+		logs.NewLogsCommand(),
 		cron.NewCronCommand(),
 		mcp.NewMCPCommand(),
 		migrate.NewMigrateCommand(),
+		backup.NewExportCommand(),
+		backup.NewImportCommand(),
+		sessions.NewSessionsCommand(),
 		skills.NewSkillsCommand(),
+		tools.NewToolsCommand(),
 		model.NewModelCommand(),
+		bench.NewBenchCommand(),
+		watch.NewWatchCommand(),
 		updater.NewUpdateCommand("picoclaw"),
 		version.NewVersionCommand(),
 	)
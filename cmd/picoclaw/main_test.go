@@ -29,6 +29,7 @@ func TestNewPicoclawCommand(t *testing.T) {
 	assert.True(t, cmd.HasAvailableSubCommands())
 
 	assert.True(t, cmd.PersistentFlags().Lookup("no-color") != nil)
+	assert.True(t, cmd.PersistentFlags().Lookup("config") != nil)
 
 	assert.Nil(t, cmd.Run)
 	assert.Nil(t, cmd.RunE)
@@ -38,18 +39,26 @@ func TestNewPicoclawCommand(t *testing.T) {
 
 	allowedCommands := []string{
 		"agent",
+		"agents",
 		"auth",
+		"bench",
 		"config",
 		"cron",
+		"export",
 		"gateway",
+		"import",
+		"logs",
 		"mcp",
 		"migrate",
 		"model",
 		"onboard",
+		"sessions",
 		"skills",
 		"status",
+		"tools",
 		"update",
 		"version",
+		"watch",
 	}
 
 	subcommands := cmd.Commands()
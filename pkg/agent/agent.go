@@ -8,6 +8,7 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -28,6 +29,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/session"
 	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/tools"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
@@ -44,12 +46,16 @@ type AgentLoop struct {
 	runtimeEventLogMu  sync.RWMutex
 	runtimeEventLogger *runtimeEventLogger
 	runtimeEventLogSub runtimeevents.Subscription
-	hooks              *HookManager
+	// Event webhooks share runtimeEventLogMu with the logger.
+	runtimeEventWebhooks   *runtimeEventWebhooks
+	runtimeEventWebhookSub runtimeevents.Subscription
+	hooks                  *HookManager
 
 	// Runtime state
 	running        atomic.Bool
 	contextManager ContextManager
 	fallback       *providers.FallbackChain
+	cooldown       *providers.CooldownTracker // shared by every fallback chain, survives reloads
 	channelManager interfaces.ChannelManager
 	mediaStore     media.MediaStore
 	transcriber    asr.Transcriber
@@ -60,11 +66,41 @@ type AgentLoop struct {
 	steering       *steeringQueue
 	pendingSkills  sync.Map
 	pendingStops   sync.Map
+	partialTurns   sync.Map    // session key -> *partialTurn saved after an LLM failure
+	safeMode       atomic.Bool // runtime safe mode; config can also enforce it
+	providerHealth providerHealthTracker
 	mu             sync.RWMutex
 
 	// workerSem limits concurrent turn processing workers.
 	workerSem chan struct{}
 
+	// noToolsSessions holds the session keys switched to pure-chat mode.
+	noToolsSessions sync.Map
+
+	// pendingApprovals holds, per session key, the *pendingToolApproval
+	// waiting for /approve or /deny.
+	pendingApprovals sync.Map
+
+	// pendingContinuations holds, per session key, the sender ID of a turn
+	// cut off at the tool-iteration limit that /continue may resume.
+	pendingContinuations sync.Map
+
+	// sessionLanguages caches the reply language detected per session key.
+	sessionLanguages sync.Map
+
+	// timers backs the timer tool. It outlives config reloads so running
+	// countdowns keep going.
+	timers *tools.TimerTool
+
+	// asyncTools tracks background tool calls until their result arrives.
+	asyncTools asyncToolRegistry
+
+	// Inbound queue accounting and the per-session coalesce buffers.
+	queueWaiting   atomic.Int64
+	queueDropped   atomic.Uint64
+	queueCoalesced atomic.Uint64
+	coalescing     sync.Map
+
 	// activeTurnStates tracks active turns per session to prevent duplicates.
 	activeTurnStates sync.Map
 	subTurnCounter   atomic.Int64
@@ -88,6 +124,10 @@ type processOptions struct {
 	ReplyToMessageID        string          // Current inbound reply target message ID
 	SenderID                string          // Current sender ID for dynamic context
 	SenderDisplayName       string          // Current sender display name for dynamic context
+	SenderLocale            string          // Current sender language tag reported by the channel
+	Verbosity               string          // Answer length the sender chose with :set verbosity
+	PreferredModel          string          // Model the sender chose with :set model
+	ReplyLanguage           string          // Language tag detected from the user's messages
 	UserMessage             string          // User message content (may include prefix)
 	ForcedSkills            []string        // Skills explicitly requested for this message
 	TurnProfile             config.EffectiveTurnProfile
@@ -101,6 +141,7 @@ type processOptions struct {
 	SuppressToolFeedback    bool                   // Whether to suppress inline tool feedback messages
 	NoHistory               bool                   // If true, don't load session history (for heartbeat)
 	SkipInitialSteeringPoll bool                   // If true, skip the steering poll at loop start (used by Continue)
+	Continued               bool                   // Turn resumes a task cut off at the iteration limit (/continue)
 	InboundContext          *bus.InboundContext    // Normalized inbound facts for events/hooks
 	RouteResult             *routing.ResolvedRoute // Route decision snapshot for events/hooks
 	SessionScope            *session.SessionScope  // Session scope snapshot for events/hooks
@@ -114,7 +155,10 @@ type continuationTarget struct {
 
 const (
 	defaultResponse            = "The model returned an empty response. This may indicate a provider error or token limit."
-	toolLimitResponse          = "I've reached `max_tool_iterations` without a final response. Increase `max_tool_iterations` in config.json if this task needs more tool steps."
+	contentPolicyReply         = "The model declined to answer this request (content policy)."
+	toolLimitResponse          = "I reached the tool-iteration limit of %d before finishing, so the task may be incomplete. Increase `max_tool_iterations` in config.json if this task needs more tool steps."
+	toolLimitAskResponse       = "I reached the tool-iteration limit of %d before finishing, so the task may be incomplete. Reply \"continue\" if you want me to keep going."
+	toolLimitContinueResponse  = "I reached the tool-iteration limit of %d before finishing, so the task may be incomplete. Reply /continue to let me keep going for up to %d more tool steps."
 	handledToolResponseSummary = "Requested output delivered via tool attachment."
 	sessionKeyAgentPrefix      = "agent:"
 	pendingTurnPrefix          = "pending-"
@@ -186,8 +230,22 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 					continue
 				}
 
+				// The sender is still typing out a thought; fold it into the
+				// turn that has not started yet instead of steering it.
+				if al.tryCoalesce(sessionKey, msg) {
+					continue
+				}
+
 				msg = al.prepareInboundMessageForAgent(ctx, msg)
 
+				// Steering messages never reach processMessage, so screen them here.
+				if agent, ok := al.GetRegistry().GetAgent(agentID); ok {
+					if refusal, blocked := al.screenInbound(ctx, msg, agent); blocked {
+						go al.publishQueueNotice(ctx, msg, sessionKey, refusal)
+						continue
+					}
+				}
+
 				// Another turn is already active (or reserved) for this session — enqueue
 				if err := al.enqueueSteeringMessage(sessionKey, agentID, providers.Message{
 					Role:    "user",
@@ -201,19 +259,27 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 							"chat_id":     msg.ChatID,
 							"session_key": sessionKey,
 						})
+					if errors.Is(err, errSteeringQueueFull) {
+						al.notifyQueueFull(ctx, msg, sessionKey)
+					}
+					continue
 				}
+				al.notifyQueuedBehindTurn(ctx, msg, sessionKey)
 				continue
 			}
 
+			coalesce := al.openCoalesceBuffer(sessionKey, msg)
+
 			// Session claimed — spawn a worker goroutine that acquires a semaphore
 			// slot. The goroutine is spawned immediately so the main loop keeps
 			// draining the inbound channel. The goroutine blocks on the semaphore.
 			go func(m bus.InboundMessage) {
+				if coalesce != nil {
+					m = al.awaitCoalesced(ctx, sessionKey, coalesce, m)
+				}
+
 				// Acquire semaphore slot (blocks if at capacity)
-				select {
-				case al.workerSem <- struct{}{}:
-					// Got slot, start worker
-				case <-ctx.Done():
+				if !al.acquireWorkerSlot(ctx, m, sessionKey) {
 					// Context canceled while waiting for a slot — clean up the
 					// placeholder to prevent session-level deadlock.
 					al.activeTurnStates.Delete(sessionKey)
@@ -325,10 +391,14 @@ func (al *AgentLoop) Close() {
 	}
 
 	al.GetRegistry().Close()
+	if al.timers != nil {
+		al.timers.Close()
+	}
 	if al.hooks != nil {
 		al.hooks.Close()
 	}
 	al.closeRuntimeEventLogger()
+	al.closeRuntimeEventWebhooks()
 	if al.runtimeEvents != nil && al.ownsRuntimeEvents {
 		if err := al.runtimeEvents.Close(); err != nil {
 			logger.ErrorCF("agent", "Failed to close runtime event bus",
@@ -439,24 +509,23 @@ func (al *AgentLoop) ReloadProviderAndConfig(
 			newRL.RegisterCandidates(agent.LightCandidates)
 		}
 	}
-	al.fallback = providers.NewFallbackChain(providers.NewCooldownTracker(), newRL)
+	if al.cooldown == nil {
+		al.cooldown = providers.NewCooldownTracker()
+	}
+	al.fallback = providers.NewFallbackChain(al.cooldown, newRL)
+	al.fallback.SetRefusalFallback(cfg.Agents.Defaults.FallbackOnRefusal)
 
 	al.mu.Unlock()
 	al.refreshRuntimeEventLogger(cfg)
+	al.refreshRuntimeEventWebhooks(cfg)
 
-	oldMCPManager := al.mcp.reset()
+	al.mcp.retainForReload()
 	al.hookRuntime.reset(al)
 	configureHookManagerFromConfig(al.hooks, cfg)
 	if err := al.ensureHooksInitialized(ctx); err != nil {
 		logger.WarnCF("agent", "Configured hooks failed to reinitialize after reload",
 			map[string]any{"error": err.Error()})
 	}
-	if oldMCPManager != nil {
-		if err := oldMCPManager.Close(); err != nil {
-			logger.WarnCF("agent", "Failed to close previous MCP manager during reload",
-				map[string]any{"error": err.Error()})
-		}
-	}
 	if oldEvolution != nil {
 		if err := oldEvolution.Close(); err != nil {
 			logger.WarnCF("agent", "Failed to close previous evolution bridge during reload",
@@ -540,6 +609,7 @@ func (al *AgentLoop) runAgentLoop(
 	if err != nil {
 		return "", err
 	}
+	opts = al.applyNoTools(opts)
 
 	// Record last channel for heartbeat notifications (skip internal channels and cli)
 	if opts.Dispatch.Channel() != "" &&
@@ -562,6 +632,11 @@ func (al *AgentLoop) runAgentLoop(
 		opts.Dispatch.SessionAliases,
 	)
 
+	// A new request supersedes a cut-off task still waiting for /continue.
+	if !opts.Continued {
+		al.pendingContinuations.Delete(opts.Dispatch.SessionKey)
+	}
+
 	turnScope := al.newTurnEventScope(
 		agent.ID,
 		opts.Dispatch.SessionKey,
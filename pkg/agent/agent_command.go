@@ -5,15 +5,19 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/commands"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
 func (al *AgentLoop) handleCommand(
@@ -31,12 +35,19 @@ func (al *AgentLoop) handleCommand(
 	if matched, handled, reply := al.applyExplicitSkillCommand(msg.Content, agent, opts); matched {
 		return reply, handled
 	}
+	if matched, handled, reply := al.applyToolApprovalCommand(msg, opts); matched {
+		return reply, handled
+	}
+	if matched, handled, reply := al.applyContinueCommand(msg, opts); matched {
+		return reply, handled
+	}
 
 	if al.cmdRegistry == nil {
 		return "", false
 	}
 
 	rt := al.buildCommandsRuntime(ctx, agent, opts)
+	rt.SetSafeMode = func(on bool) error { return al.setSafeModeFrom(msg, on) }
 	executor := commands.NewExecutor(al.cmdRegistry, rt)
 
 	var commandReply string
@@ -65,6 +76,17 @@ func (al *AgentLoop) handleCommand(
 	}
 }
 
+// isCommandTrigger reports whether an alias trigger names a registered
+// command ("/help", "!help"); commands always win over aliases.
+func (al *AgentLoop) isCommandTrigger(trigger string) bool {
+	name, ok := commands.CommandName(trigger)
+	if !ok || al.cmdRegistry == nil {
+		return false
+	}
+	_, registered := al.cmdRegistry.Lookup(name)
+	return registered
+}
+
 func (al *AgentLoop) applyExplicitSkillCommand(
 	raw string,
 	agent *AgentInstance,
@@ -257,6 +279,20 @@ func (al *AgentLoop) buildCommandsRuntime(
 			}
 			return al.channelManager.GetEnabledChannels()
 		},
+		GetChannelHealth: func() map[string]string {
+			hp, ok := al.channelManager.(interface {
+				ChannelHealth() map[string]channels.ChannelHealth
+			})
+			if !ok {
+				return nil
+			}
+			health := hp.ChannelHealth()
+			states := make(map[string]string, len(health))
+			for name, h := range health {
+				states[name] = string(h.State)
+			}
+			return states
+		},
 		GetActiveTurn: func() any {
 			info := al.GetActiveTurn()
 			if info == nil {
@@ -274,6 +310,7 @@ func (al *AgentLoop) buildCommandsRuntime(
 			return nil
 		},
 	}
+	rt.GetSafeMode = al.SafeMode
 	rt.StopActiveTurn = func() (commands.StopResult, error) {
 		if opts == nil {
 			return commands.StopResult{}, fmt.Errorf("process options not available")
@@ -333,9 +370,82 @@ func (al *AgentLoop) buildCommandsRuntime(
 			if opts == nil {
 				return fmt.Errorf("process options not available")
 			}
+			if al.timers != nil {
+				al.timers.CancelSession(opts.SessionKey)
+			}
+			if al.state != nil {
+				if err := al.state.SetPlan(opts.SessionKey, nil); err != nil {
+					return err
+				}
+			}
+			if agent != nil {
+				if err := removeStagedAttachments(agent.Workspace, opts.SessionKey); err != nil {
+					return err
+				}
+			}
 			return al.contextManager.Clear(ctx, opts.SessionKey)
 		}
 
+		rt.SummarizeHistory = func(ctx context.Context) (bool, error) {
+			if opts == nil {
+				return false, fmt.Errorf("process options not available")
+			}
+			al.publishCommandNotice(ctx, opts, "Summarizing chat history, this may take a moment...")
+			err := al.contextManager.Compact(ctx, &CompactRequest{
+				SessionKey: opts.SessionKey,
+				Reason:     ContextCompressReasonManual,
+				Budget:     agent.ContextWindow,
+			})
+			if errors.Is(err, ErrNothingToSummarize) {
+				return false, nil
+			}
+			return err == nil, err
+		}
+
+		if agent.Sessions != nil && opts != nil && strings.TrimSpace(opts.SessionKey) != "" {
+			sessionKey := opts.SessionKey
+			rt.GetSummary = func() string {
+				return agent.Sessions.GetSummary(sessionKey)
+			}
+			rt.SetSummary = func(summary string) error {
+				agent.Sessions.SetSummary(sessionKey, summary)
+				return agent.Sessions.Save(sessionKey)
+			}
+		}
+
+		if al.state != nil && opts != nil && strings.TrimSpace(opts.SessionKey) != "" {
+			sessionKey := opts.SessionKey
+			rt.PinFile = func(path string) (string, bool, error) {
+				rel, err := resolvePinPath(agent.Workspace, path)
+				if err != nil {
+					return "", false, err
+				}
+				if len(al.state.GetPinnedFiles(sessionKey)) >= maxPinnedFiles {
+					return "", false, fmt.Errorf("at most %d files can be pinned per session", maxPinnedFiles)
+				}
+				added, err := al.state.PinFile(sessionKey, rel)
+				return rel, added, err
+			}
+			rt.UnpinFile = func(path string) (bool, error) {
+				return al.state.UnpinFile(sessionKey, normalizePinPath(agent.Workspace, path))
+			}
+			rt.ListPinnedFiles = func() []string {
+				return al.state.GetPinnedFiles(sessionKey)
+			}
+			rt.GetPlan = func() string {
+				return tools.FormatPlan(al.state.GetPlan(sessionKey))
+			}
+			rt.ClearPlan = func() error {
+				return al.state.SetPlan(sessionKey, nil)
+			}
+			rt.GetSensitive = func() bool {
+				return al.state.IsSensitive(sessionKey)
+			}
+			rt.SetSensitive = func(sensitive bool) error {
+				return al.state.SetSensitive(sessionKey, sensitive)
+			}
+		}
+
 		rt.AskSideQuestion = func(ctx context.Context, question string) (string, error) {
 			return al.askSideQuestion(ctx, agent, opts, question)
 		}
@@ -363,6 +473,36 @@ func (al *AgentLoop) buildCommandsRuntime(
 	return rt
 }
 
+// publishCommandNotice sends an interim status line to the chat a command was
+// issued from, for commands that block long enough to need one. The command's
+// own reply still arrives separately once it finishes.
+func (al *AgentLoop) publishCommandNotice(ctx context.Context, opts *processOptions, content string) {
+	if al.bus == nil || opts == nil || opts.Dispatch.ChatID() == "" || ctx.Err() != nil {
+		return
+	}
+	pubCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	err := al.bus.PublishOutbound(pubCtx, bus.OutboundMessage{
+		Context: outboundContextFromInbound(
+			opts.Dispatch.InboundContext,
+			opts.Dispatch.Channel(),
+			opts.Dispatch.ChatID(),
+			opts.Dispatch.ReplyToMessageID(),
+		),
+		SessionKey: opts.Dispatch.SessionKey,
+		Content:    content,
+	})
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) &&
+		!errors.Is(err, context.Canceled) &&
+		!errors.Is(err, bus.ErrBusClosed) {
+		logger.WarnCF("agent", "Failed to publish command notice", map[string]any{
+			"channel": opts.Dispatch.Channel(),
+			"chat_id": opts.Dispatch.ChatID(),
+			"error":   err.Error(),
+		})
+	}
+}
+
 func summarizeMCPToolParameters(schema any) []commands.MCPToolParameterInfo {
 	schemaMap := normalizeMCPSchema(schema)
 	properties, ok := schemaMap["properties"].(map[string]any)
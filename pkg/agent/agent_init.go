@@ -5,6 +5,7 @@ package agent
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/agent/interfaces"
@@ -18,6 +19,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/tokenizer"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
@@ -41,6 +43,10 @@ func NewAgentLoop(
 		}
 	}
 	fallbackChain := providers.NewFallbackChain(cooldown, rl)
+	fallbackChain.SetRefusalFallback(cfg.Agents.Defaults.FallbackOnRefusal)
+	if strings.EqualFold(strings.TrimSpace(cfg.Agents.Defaults.Tokenizer), "bpe") {
+		tokenizer.EnableBPE()
+	}
 
 	// Create state manager using default agent's workspace for channel recording
 	defaultAgent := registry.GetDefaultAgent()
@@ -68,6 +74,7 @@ func NewAgentLoop(
 		registry:          registry,
 		state:             stateManager,
 		fallback:          fallbackChain,
+		cooldown:          cooldown,
 		cmdRegistry:       commands.NewRegistry(commands.BuiltinDefinitions()),
 		evolution:         bridge,
 		steering:          newSteeringQueue(parseSteeringMode(cfg.Agents.Defaults.SteeringMode)),
@@ -92,9 +99,18 @@ func NewAgentLoop(
 		}
 	}
 	al.refreshRuntimeEventLogger(cfg)
+	al.refreshRuntimeEventWebhooks(cfg)
 	al.providerFactory = providers.CreateProviderFromConfig
 	al.hooks = NewHookManager(al.runtimeEvents.Channel())
 	configureHookManagerFromConfig(al.hooks, cfg)
+	if err := al.MountHook(HookRegistration{
+		Name:     toolApprovalHookName,
+		Priority: toolApprovalHookPriority,
+		Source:   HookSourceInProcess,
+		Hook:     toolApprovalHook{al: al},
+	}); err != nil {
+		logger.WarnCF("agent", "Failed to mount tool approval hook", map[string]any{"error": err.Error()})
+	}
 	al.contextManager = al.resolveContextManager()
 
 	// Register shared tools to all agents (now that al is created)
@@ -119,12 +135,38 @@ func registerSharedTools(
 		}
 	}
 
+	// Truncating tools keep their full output here for get_full_result and
+	// :expand; with the tool off they truncate as before.
+	var fullResults *tools.FullResults
+	if cfg.Tools.IsToolEnabled("get_full_result") {
+		fullResults = tools.NewFullResults(
+			time.Duration(cfg.Tools.FullResults.TTLSeconds)*time.Second,
+			cfg.Tools.FullResults.MaxBytes,
+		)
+	}
+	tools.SetDefaultFullResults(fullResults)
+
+	if cfg.Tools.IsToolEnabled("timer") {
+		if al.timers == nil {
+			al.timers = tools.NewTimerTool(msgBus.PublishOutbound)
+		}
+	} else if al.timers != nil {
+		al.timers.Close()
+		al.timers = nil
+	}
+
 	for _, agentID := range registry.ListAgentIDs() {
 		agent, ok := registry.GetAgent(agentID)
 		if !ok {
 			continue
 		}
 
+		if fullResults != nil {
+			agent.Tools.Register(tools.NewGetFullResultTool(fullResults))
+		}
+		if al.timers != nil {
+			agent.Tools.Register(al.timers)
+		}
 		if cfg.Tools.IsToolEnabled("web") {
 			searchTool, err := tools.NewWebSearchTool(tools.WebSearchToolOptionsFromConfig(cfg))
 			if err != nil {
@@ -143,9 +185,24 @@ func registerSharedTools(
 			if err != nil {
 				logger.ErrorCF("agent", "Failed to create web fetch tool", map[string]any{"error": err.Error()})
 			} else {
+				fetchTool.SetDomainPolicy(cfg.Tools.Web.FetchAllowlist, cfg.Tools.Web.FetchDenylist)
+				fetchTool.SetMaxRedirects(cfg.Tools.Web.FetchMaxRedirects)
+				fetchTool.SetContentTypes(cfg.Tools.Web.FetchContentTypes)
 				agent.Tools.Register(fetchTool)
 			}
 		}
+		if cfg.Tools.IsToolEnabled("http") {
+			httpTool, err := tools.NewHTTPRequestTool(
+				cfg.Tools.Web.Proxy,
+				cfg.Tools.HTTP.AllowedHosts,
+				cfg.Tools.Web.PrivateHostWhitelist,
+				cfg.Tools.HTTP.MaxResponseBytes)
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create http request tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(httpTool)
+			}
+		}
 
 		// Hardware tools (I2C, SPI) - Linux only, returns error on other platforms
 		if cfg.Tools.IsToolEnabled("i2c") {
@@ -158,6 +215,23 @@ func registerSharedTools(
 			agent.Tools.Register(tools.NewSerialTool())
 		}
 
+		if cfg.Tools.IsToolEnabled("image_gen") {
+			imageTool, err := tools.NewImageGenTool(tools.ImageGenToolOptionsFromConfig(cfg))
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create image generation tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(imageTool)
+			}
+		}
+
+		// Clipboard tool - desktop only, returns error on headless hosts
+		if cfg.Tools.IsToolEnabled("clipboard") {
+			agent.Tools.Register(tools.NewClipboardTool())
+		}
+		if cfg.Tools.IsToolEnabled("screenshot") {
+			agent.Tools.Register(tools.NewScreenshotTool())
+		}
+
 		// Message tool
 		if cfg.Tools.IsToolEnabled("message") {
 			messageTool := tools.NewMessageTool()
@@ -214,6 +288,32 @@ func registerSharedTools(
 				defer pubCancel()
 				return msgBus.PublishOutbound(pubCtx, outboundMessage)
 			})
+			messageTool.SetMessageOpCallback(func(
+				ctx context.Context,
+				channel, chatID string,
+				op bus.OutboundOperation,
+				messageID, content string,
+			) error {
+				if al.channelManager == nil {
+					return fmt.Errorf("channel manager not configured")
+				}
+				outboundAgentID, outboundSessionKey, outboundScope := outboundTurnMetadata(
+					tools.ToolAgentID(ctx),
+					tools.ToolSessionKey(ctx),
+					tools.ToolSessionScope(ctx),
+				)
+				return al.channelManager.SendMessage(ctx, bus.OutboundMessage{
+					Channel:         channel,
+					ChatID:          chatID,
+					Context:         bus.NewOutboundContext(channel, chatID, ""),
+					AgentID:         outboundAgentID,
+					SessionKey:      outboundSessionKey,
+					Scope:           outboundScope,
+					Content:         content,
+					Operation:       op,
+					TargetMessageID: messageID,
+				})
+			})
 			agent.Tools.Register(messageTool)
 		}
 		if cfg.Tools.IsToolEnabled("reaction") {
@@ -263,6 +363,112 @@ func registerSharedTools(
 			agent.Tools.Register(loadImageTool)
 		}
 
+		if cfg.Tools.IsToolEnabled("ocr") {
+			ocrTool, err := tools.NewOCRTool(tools.OCRToolOptions{
+				Engine:               cfg.Tools.OCR.Engine,
+				Languages:            cfg.Tools.OCR.Languages,
+				TesseractPath:        cfg.Tools.OCR.TesseractPath,
+				APIKey:               cfg.Tools.OCR.APIKey.String(),
+				BaseURL:              cfg.Tools.OCR.BaseURL,
+				Workspace:            agent.Workspace,
+				Restrict:             cfg.Agents.Defaults.RestrictToWorkspace,
+				AllowPaths:           allowReadPaths,
+				MaxFileSize:          cfg.Agents.Defaults.GetMaxMediaSize(),
+				Proxy:                cfg.Tools.Web.Proxy,
+				PrivateHostWhitelist: cfg.Tools.Web.PrivateHostWhitelist,
+			})
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create OCR tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(ocrTool)
+			}
+		}
+
+		if cfg.Tools.IsToolEnabled("weather") {
+			weatherTool, err := tools.NewWeatherTool(tools.WeatherToolOptionsFromConfig(cfg))
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create weather tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(weatherTool)
+			}
+		}
+
+		if cfg.Tools.IsToolEnabled("market") {
+			marketTool, err := tools.NewMarketTool(tools.MarketToolOptionsFromConfig(cfg))
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create market tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(marketTool)
+			}
+		}
+
+		if cfg.Tools.IsToolEnabled("send_email") {
+			emailTool, err := tools.NewEmailSendTool(tools.EmailSendToolOptions{
+				SMTPHost:       cfg.Tools.Email.SMTPHost,
+				SMTPPort:       cfg.Tools.Email.SMTPPort,
+				Username:       cfg.Tools.Email.Username,
+				Password:       cfg.Tools.Email.Password.String(),
+				From:           cfg.Tools.Email.From,
+				AllowedDomains: cfg.Tools.Email.AllowedDomains,
+				RequireConfirm: cfg.Tools.Email.RequireConfirm,
+				Workspace:      agent.Workspace,
+				Restrict:       cfg.Agents.Defaults.RestrictToWorkspace,
+				AllowPaths:     allowReadPaths,
+				MaxFileSize:    cfg.Agents.Defaults.GetMaxMediaSize(),
+			})
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create send_email tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(emailTool)
+			}
+		}
+
+		if cfg.Tools.IsToolEnabled("translate") {
+			opts := tools.TranslateToolOptionsFromConfig(cfg)
+			opts.Provider = agent.Provider
+			opts.Model = resolvedCandidateModel(agent.Candidates, agent.Model)
+			if name := strings.TrimSpace(cfg.Tools.Translate.Model); name != "" {
+				modelCfg, err := resolvedModelConfig(cfg, name, agent.Workspace)
+				if err == nil {
+					opts.Provider, opts.Model, err = providers.CreateProviderFromConfig(modelCfg)
+				}
+				if err != nil {
+					logger.WarnCF("agent", "Translate model unavailable; using the agent's model",
+						map[string]any{"model": name, "agent_id": agent.ID, "error": err.Error()})
+					opts.Provider = agent.Provider
+					opts.Model = resolvedCandidateModel(agent.Candidates, agent.Model)
+				}
+			}
+			translateTool, err := tools.NewTranslateTool(opts)
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create translate tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(translateTool)
+			}
+		}
+
+		if cfg.Tools.IsToolEnabled("python") {
+			workspace := agent.Workspace
+			pythonTool, err := tools.NewPythonTool(tools.PythonToolOptions{
+				InterpreterPath: cfg.Tools.Python.InterpreterPath,
+				AllowNetwork:    cfg.Tools.Python.AllowNetwork,
+				Timeout:         time.Duration(cfg.Tools.Python.TimeoutSeconds) * time.Second,
+				MemoryLimitMB:   cfg.Tools.Python.MemoryLimitMB,
+				Workspace:       workspace,
+				Restrict:        cfg.Agents.Defaults.RestrictToWorkspace,
+				AllowPaths:      allowReadPaths,
+				MaxFileSize:     cfg.Agents.Defaults.GetMaxMediaSize(),
+				AttachmentsDir: func(sessionKey string) string {
+					return attachmentStagingDir(workspace, sessionKey)
+				},
+			})
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create python tool", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(pythonTool)
+			}
+		}
+
 		// Skill discovery and installation tools
 		skills_enabled := cfg.Tools.IsToolEnabled("skills")
 		find_skills_enable := cfg.Tools.IsToolEnabled("find_skills")
@@ -271,10 +477,7 @@ func registerSharedTools(
 			registryMgr := skills.NewRegistryManagerFromToolsConfig(cfg.Tools.Skills)
 
 			if find_skills_enable {
-				searchCache := skills.NewSearchCache(
-					cfg.Tools.Skills.SearchCache.MaxSize,
-					time.Duration(cfg.Tools.Skills.SearchCache.TTLSeconds)*time.Second,
-				)
+				searchCache := skills.NewSearchCacheFromToolsConfig(cfg.Tools.Skills)
 				agent.Tools.Register(tools.NewFindSkillsTool(registryMgr, searchCache))
 			}
 
@@ -290,6 +493,11 @@ func registerSharedTools(
 		if (spawnEnabled || spawnStatusEnabled) && cfg.Tools.IsToolEnabled("subagent") {
 			subagentManager := tools.NewSubagentManager(provider, agent.Model, agent.Workspace)
 			subagentManager.SetLLMOptions(agent.MaxTokens, agent.Temperature)
+			subagentManager.SetConcurrencyLimit(
+				cfg.Agents.Defaults.MaxConcurrentSubagents,
+				cfg.Agents.Defaults.RejectExcessSubagents,
+			)
+			agent.SubagentManager = subagentManager
 
 			// Inject a media resolver so the legacy RunToolLoop fallback path can
 			// resolve media:// refs in the same way the main AgentLoop does.
@@ -401,6 +609,59 @@ func registerSharedTools(
 			agent.Tools.Register(delegateTool)
 		}
 
+		// Script-backed tools from tools.external. They never replace a
+		// built-in tool of the same name.
+		for _, extCfg := range cfg.Tools.External {
+			if agent.Tools.HasRegistered(strings.TrimSpace(extCfg.Name)) {
+				logger.WarnCF("agent", "Skipping external tool that shadows a built-in tool",
+					map[string]any{"name": extCfg.Name})
+				continue
+			}
+			extTool, err := tools.NewExternalTool(extCfg, agent.Workspace)
+			if err != nil {
+				logger.ErrorCF("agent", "Failed to create external tool", map[string]any{"error": err.Error()})
+				continue
+			}
+			agent.Tools.Register(extTool)
+		}
+
+		if al.state != nil && cfg.Tools.IsToolEnabled("plan") {
+			agent.Tools.Register(tools.NewPlanTool(al.state))
+			if agent.ContextBuilder != nil {
+				if err := agent.ContextBuilder.RegisterPromptContributor(planPromptContributor{
+					plan: al.state.GetPlan,
+				}); err != nil {
+					logger.WarnCF("agent", "Failed to register plan prompt contributor", map[string]any{
+						"agent_id": agentID,
+						"error":    err.Error(),
+					})
+				}
+			}
+		}
+
+		if cfg.Agents.Defaults.Attachments.Enabled && agent.ContextBuilder != nil {
+			if err := agent.ContextBuilder.RegisterPromptContributor(attachmentsPromptContributor{
+				workspace: agent.Workspace,
+			}); err != nil {
+				logger.WarnCF("agent", "Failed to register attachments prompt contributor", map[string]any{
+					"agent_id": agentID,
+					"error":    err.Error(),
+				})
+			}
+		}
+
+		if al.state != nil && agent.ContextBuilder != nil {
+			if err := agent.ContextBuilder.RegisterPromptContributor(pinnedFilesPromptContributor{
+				workspace: agent.Workspace,
+				pinned:    al.state.GetPinnedFiles,
+			}); err != nil {
+				logger.WarnCF("agent", "Failed to register pinned files prompt contributor", map[string]any{
+					"agent_id": agentID,
+					"error":    err.Error(),
+				})
+			}
+		}
+
 		warnOnUnknownAgentToolDeclarations(agentID, agent.Workspace, agent.Definition, agent.Tools)
 	}
 }
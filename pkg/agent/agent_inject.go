@@ -3,9 +3,12 @@
 package agent
 
 import (
+	"strings"
+
 	"github.com/sipeed/picoclaw/pkg/audio/asr"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
@@ -56,6 +59,11 @@ func (al *AgentLoop) SetTranscriber(t asr.Transcriber) {
 	al.transcriber = t
 }
 
+// HasTranscriber reports whether voice messages can be transcribed.
+func (al *AgentLoop) HasTranscriber() bool {
+	return al.transcriber != nil
+}
+
 func (al *AgentLoop) SetReloadFunc(fn func() error) {
 	al.reloadFunc = fn
 }
@@ -67,6 +75,19 @@ func (al *AgentLoop) RecordLastChannel(channel string) error {
 	return al.state.SetLastChannel(channel)
 }
 
+// LastChannel returns the channel and chat ID of the most recent user
+// conversation, or empty strings when none has been recorded yet.
+func (al *AgentLoop) LastChannel() (channel, chatID string) {
+	if al.state == nil {
+		return "", ""
+	}
+	channel, chatID, ok := strings.Cut(al.state.GetLastChannel(), ":")
+	if !ok || channel == "" || chatID == "" || constants.IsInternalChannel(channel) {
+		return "", ""
+	}
+	return channel, chatID
+}
+
 func (al *AgentLoop) RecordLastChatID(chatID string) error {
 	if al.state == nil {
 		return nil
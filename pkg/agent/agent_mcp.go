@@ -22,6 +22,9 @@ type mcpRuntime struct {
 	mu       sync.Mutex
 	manager  *mcp.Manager
 	initErr  error
+	// retained is the manager kept across a config reload so the next
+	// initialization can reconcile it instead of reconnecting every server.
+	retained *mcp.Manager
 }
 
 func (r *mcpRuntime) reset() *mcp.Manager {
@@ -34,6 +37,40 @@ func (r *mcpRuntime) reset() *mcp.Manager {
 	return manager
 }
 
+// retainForReload resets the runtime like reset, but holds on to the current
+// manager for the next initialization to reuse.
+func (r *mcpRuntime) retainForReload() {
+	manager := r.reset()
+	if manager == nil {
+		return
+	}
+	r.mu.Lock()
+	previous := r.retained
+	r.retained = manager
+	r.mu.Unlock()
+	closeMCPManager(previous, "Failed to close previous MCP manager during reload")
+}
+
+func (r *mcpRuntime) takeRetained() *mcp.Manager {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	manager := r.retained
+	r.retained = nil
+	return manager
+}
+
+func closeMCPManager(manager *mcp.Manager, message string) {
+	if manager == nil {
+		return
+	}
+	if err := manager.Close(); err != nil {
+		logger.ErrorCF("agent", message,
+			map[string]any{
+				"error": err.Error(),
+			})
+	}
+}
+
 func (r *mcpRuntime) setManager(manager *mcp.Manager) {
 	r.mu.Lock()
 	r.manager = manager
@@ -73,9 +110,25 @@ func (r *mcpRuntime) getManager() *mcp.Manager {
 	return r.manager
 }
 
+// MCPServerHealth reports the supervision state of connected MCP servers.
+// It returns nil until MCP has been initialized.
+func (al *AgentLoop) MCPServerHealth() []mcp.ServerHealth {
+	manager := al.mcp.getManager()
+	if manager == nil {
+		return nil
+	}
+	return manager.Health()
+}
+
 // ensureMCPInitialized loads MCP servers/tools once so both Run() and direct
 // agent mode share the same initialization path.
 func (al *AgentLoop) ensureMCPInitialized(ctx context.Context) error {
+	// A manager retained across a reload that the new config no longer
+	// needs is shut down on the way out.
+	defer func() {
+		closeMCPManager(al.mcp.takeRetained(), "Failed to close retained MCP manager")
+	}()
+
 	if !al.cfg.Tools.IsToolEnabled("mcp") {
 		return nil
 	}
@@ -107,26 +160,30 @@ func (al *AgentLoop) ensureMCPInitialized(ctx context.Context) error {
 	}
 
 	al.mcp.initOnce.Do(func() {
-		mcpManager := mcp.NewManager(mcp.WithRuntimeEvents(al.runtimeEvents))
-
 		defaultAgent := al.registry.GetDefaultAgent()
 		workspacePath := al.cfg.WorkspacePath()
 		if defaultAgent != nil && defaultAgent.Workspace != "" {
 			workspacePath = defaultAgent.Workspace
 		}
 
-		if err := mcpManager.LoadFromMCPConfig(ctx, mcpCfg, workspacePath); err != nil {
-			al.mcp.setInitErr(fmt.Errorf("failed to load MCP servers: %w", err))
+		var loadErr error
+		mcpManager := al.mcp.takeRetained()
+		if mcpManager != nil {
+			// Config reload: only start, stop or reconnect servers whose
+			// config changed; the tools are re-registered below because the
+			// agent registry was rebuilt.
+			_, loadErr = mcpManager.Reconcile(ctx, mcpCfg, workspacePath)
+		} else {
+			mcpManager = mcp.NewManager(mcp.WithRuntimeEvents(al.runtimeEvents))
+			loadErr = mcpManager.LoadFromMCPConfig(ctx, mcpCfg, workspacePath)
+		}
+		if loadErr != nil {
+			al.mcp.setInitErr(fmt.Errorf("failed to load MCP servers: %w", loadErr))
 			logger.WarnCF("agent", "Failed to load MCP servers, MCP tools will not be available",
 				map[string]any{
-					"error": err.Error(),
+					"error": loadErr.Error(),
 				})
-			if closeErr := mcpManager.Close(); closeErr != nil {
-				logger.ErrorCF("agent", "Failed to close MCP manager",
-					map[string]any{
-						"error": closeErr.Error(),
-					})
-			}
+			closeMCPManager(mcpManager, "Failed to close MCP manager")
 			return
 		}
 
@@ -44,6 +44,31 @@ func TestMCPRuntimeResetClearsState(t *testing.T) {
 	}
 }
 
+func TestMCPRuntimeRetainForReloadKeepsManager(t *testing.T) {
+	var rt mcpRuntime
+	manager := mcp.NewManager()
+	defer manager.Close()
+	rt.setManager(manager)
+	rt.initOnce.Do(func() {})
+
+	rt.retainForReload()
+	if rt.hasManager() {
+		t.Fatal("expected active manager to be cleared after retainForReload")
+	}
+	if got := rt.takeRetained(); got != manager {
+		t.Fatalf("takeRetained() = %p, want %p", got, manager)
+	}
+	if got := rt.takeRetained(); got != nil {
+		t.Fatalf("second takeRetained() = %p, want nil", got)
+	}
+
+	reran := false
+	rt.initOnce.Do(func() { reran = true })
+	if !reran {
+		t.Fatal("expected initOnce to be reset")
+	}
+}
+
 func TestReloadProviderAndConfig_ResetsMCPRuntime(t *testing.T) {
 	al, cfg, _, _, cleanup := newTestAgentLoop(t)
 	defer cleanup()
@@ -65,6 +90,12 @@ func TestReloadProviderAndConfig_ResetsMCPRuntime(t *testing.T) {
 	if al.mcp.hasManager() {
 		t.Fatal("expected MCP manager to be cleared when reloaded config has MCP disabled")
 	}
+	if al.mcp.takeRetained() != nil {
+		t.Fatal("expected retained MCP manager to be closed when reloaded config has MCP disabled")
+	}
+	if _, err := manager.Reconcile(context.Background(), config.MCPConfig{}, ""); err == nil {
+		t.Fatal("expected retained MCP manager to be closed")
+	}
 	if err := al.mcp.getInitErr(); err != nil {
 		t.Fatalf("getInitErr() = %v, want nil", err)
 	}
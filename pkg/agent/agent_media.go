@@ -120,7 +120,7 @@ func resolveMediaRefs(
 			pathTags = append(pathTags, buildPathTag(mime, localPath))
 
 			if m.Role == "tool" && idx >= currentTurnStart && strings.HasPrefix(mime, "image/") {
-				dataURL := encodeImageToDataURL(localPath, mime, info, maxSize)
+				dataURL := encodeMediaToDataURL(localPath, mime, info, maxSize)
 				if dataURL != "" {
 					pendingToolImages = append(pendingToolImages, dataURL)
 				}
@@ -143,9 +143,58 @@ func resolveMediaRefs(
 	return result
 }
 
-// encodeImageToDataURL base64-encodes an image file into a data URL.
+// inlineCurrentTurnAudio replaces the audio media refs of the current turn's
+// user messages with data URLs, for models that take audio input. Other refs
+// are left for resolveMediaRefs.
+func inlineCurrentTurnAudio(
+	messages []providers.Message,
+	store media.MediaStore,
+	maxSize int,
+	currentTurnStart int,
+) []providers.Message {
+	if store == nil {
+		return messages
+	}
+	currentTurnStart = normalizeCurrentTurnStart(messages, currentTurnStart)
+
+	result := make([]providers.Message, len(messages))
+	copy(result, messages)
+	for idx := currentTurnStart; idx < len(result); idx++ {
+		m := result[idx]
+		if m.Role != "user" || len(m.Media) == 0 {
+			continue
+		}
+		refs := make([]string, 0, len(m.Media))
+		for _, ref := range m.Media {
+			if !strings.HasPrefix(ref, "media://") {
+				refs = append(refs, ref)
+				continue
+			}
+			localPath, meta, err := store.ResolveWithMeta(ref)
+			if err != nil {
+				refs = append(refs, ref)
+				continue
+			}
+			mime := detectMIME(localPath, meta)
+			info, err := os.Stat(localPath)
+			if err != nil || !strings.HasPrefix(mime, "audio/") {
+				refs = append(refs, ref)
+				continue
+			}
+			if dataURL := encodeMediaToDataURL(localPath, mime, info, maxSize); dataURL != "" {
+				refs = append(refs, dataURL)
+			} else {
+				refs = append(refs, ref)
+			}
+		}
+		result[idx].Media = refs
+	}
+	return result
+}
+
+// encodeMediaToDataURL base64-encodes a media file into a data URL.
 // Returns empty string if the file exceeds maxSize or encoding fails.
-func encodeImageToDataURL(localPath, mime string, info os.FileInfo, maxSize int) string {
+func encodeMediaToDataURL(localPath, mime string, info os.FileInfo, maxSize int) string {
 	if info.Size() > int64(maxSize) {
 		logger.WarnCF("agent", "Media file too large, skipping", map[string]any{
 			"path":     localPath,
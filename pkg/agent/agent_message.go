@@ -146,11 +146,40 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		return al.processSystemMessage(ctx, msg)
 	}
 
+	expanded, applied, aliasErr := al.GetConfig().Agents.Defaults.Aliases.Expand(msg.Content, al.isCommandTrigger)
+	if aliasErr != nil {
+		return aliasErr.Error(), nil
+	}
+	if len(applied) > 0 {
+		logger.InfoCF("agent", "Expanded message alias",
+			map[string]any{
+				"aliases":     strings.Join(applied, " -> "),
+				"channel":     msg.Channel,
+				"chat_id":     msg.ChatID,
+				"session_key": msg.SessionKey,
+			})
+		msg.Content = expanded
+	}
+
 	route, agent, routeErr := al.resolveMessageRoute(msg)
 	if routeErr != nil {
 		return "", routeErr
 	}
 
+	// Without a transcriber a voice note reaches a text-only model as a bare
+	// annotation; tell the user how to enable transcription instead.
+	if !al.HasTranscriber() && !agent.AudioInput && isVoiceOnlyContent(msg.Content) {
+		logger.InfoCF("voice", "Voice message received but no transcriber is configured", map[string]any{
+			"channel": msg.Channel,
+			"chat_id": msg.ChatID,
+		})
+		return voiceTranscriptionUnavailableMsg, nil
+	}
+
+	if refusal, blocked := al.screenInbound(ctx, msg, agent); blocked {
+		return refusal, nil
+	}
+
 	allocation := al.allocateRouteSession(route, msg)
 
 	// Resolve session key from the route allocation, while preserving explicit
@@ -158,6 +187,21 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 	scopeKey := resolveScopeKey(allocation.SessionKey, msg.SessionKey)
 	sessionKey := scopeKey
 
+	al.greetNewPeer(ctx, msg, sessionKey)
+
+	if response, handled := al.handleCompactionKeep(sessionKey, msg.Content); handled {
+		return response, nil
+	}
+	if response, handled := al.handleExpandResult(msg.Content); handled {
+		return response, nil
+	}
+	if response, handled := al.handlePeerPreferences(msg); handled {
+		return response, nil
+	}
+	if response, handled := al.handleForward(ctx, msg, agent, sessionKey); handled {
+		return response, nil
+	}
+
 	// Reset message-tool state for this round so we don't skip publishing due to a previous round.
 	if tool, ok := agent.Tools.Get("message"); ok {
 		if resetter, ok := tool.(interface{ ResetSentInRound(sessionKey string) }); ok {
@@ -188,22 +232,27 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		},
 		SenderID:                msg.SenderID,
 		SenderDisplayName:       msg.Sender.DisplayName,
+		SenderLocale:            msg.Sender.Locale,
 		DefaultResponse:         defaultResponse,
 		EnableSummary:           true,
 		SendResponse:            false,
 		AllowInterimPicoPublish: true,
 	}
+	opts = al.applyReplyLanguage(msg, opts)
+	opts = al.applyPeerPreferences(msg, opts)
 	var err error
 	opts, err = resolveTurnProfileOptions(al.GetConfig(), opts)
 	if err != nil {
 		return "", err
 	}
+	opts = al.applyNoTools(opts)
 
 	// context-dependent commands check their own Runtime fields and report
 	// "unavailable" when the required capability is nil.
 	if response, handled := al.handleCommand(ctx, msg, agent, &opts); handled {
 		return response, nil
 	}
+	opts.Dispatch = al.stageAttachments(agent, opts.Dispatch)
 
 	if pending := al.takePendingSkills(opts.Dispatch.SessionKey); len(pending) > 0 {
 		opts.ForcedSkills = append(opts.ForcedSkills, pending...)
@@ -258,6 +307,9 @@ func (al *AgentLoop) processSystemMessage(
 			"chat_id":   msg.ChatID,
 		})
 
+	// Results of tracked async tools go back to the session that started them.
+	task, tracked := al.asyncTools.finish(msg.Context.MessageID)
+
 	// Parse origin channel from chat_id (format: "channel:chat_id")
 	var originChannel, originChatID string
 	if idx := strings.Index(msg.ChatID, ":"); idx > 0 {
@@ -294,9 +346,28 @@ func (al *AgentLoop) processSystemMessage(
 
 	// Use the origin session for context
 	sessionKey := session.BuildMainSessionKey(agent.ID)
+	if tracked {
+		if origin, ok := al.GetRegistry().GetAgent(task.AgentID); ok && task.SessionKey != "" {
+			agent, sessionKey = origin, task.SessionKey
+		}
+	}
+	userMessage := fmt.Sprintf("[System: %s] %s", msg.SenderID, msg.Content)
+
+	// Without a follow-up turn the result only joins the session history,
+	// where the model sees it on the user's next message.
+	if tracked && !al.GetConfig().Agents.Defaults.IsAsyncToolFollowUpEnabled() {
+		agent.Sessions.AddMessage(sessionKey, "user", userMessage)
+		logger.InfoCF("agent", "Async tool result added to session",
+			map[string]any{
+				"tool":        task.Tool,
+				"session_key": sessionKey,
+			})
+		return "", nil
+	}
+
 	dispatch := DispatchRequest{
 		SessionKey:  sessionKey,
-		UserMessage: fmt.Sprintf("[System: %s] %s", msg.SenderID, msg.Content),
+		UserMessage: userMessage,
 	}
 	if originChannel != "" || originChatID != "" {
 		dispatch.InboundContext = &bus.InboundContext{
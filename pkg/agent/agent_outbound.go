@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/tools"
@@ -21,6 +22,11 @@ func (al *AgentLoop) maybePublishError(ctx context.Context, channel, chatID, ses
 	if errors.Is(err, context.Canceled) {
 		return false
 	}
+	var llmErr *llmCallError
+	if errors.As(err, &llmErr) {
+		al.PublishResponseIfNeeded(ctx, channel, chatID, sessionKey, al.failureReply(llmErr, channel, chatID))
+		return true
+	}
 	al.PublishResponseIfNeeded(ctx, channel, chatID, sessionKey, fmt.Sprintf("Error processing message: %v", err))
 	return true
 }
@@ -102,6 +108,105 @@ func (al *AgentLoop) targetReasoningChannelID(channelName string) (chatID string
 	return ""
 }
 
+// notifyContextCompressed tells the user that older messages were dropped to
+// fit the context window, so a reply that has forgotten something is not a
+// surprise. Internal channels and no-op compactions stay silent.
+func (al *AgentLoop) notifyContextCompressed(ctx context.Context, ts *turnState, dropped int) {
+	if dropped <= 0 || constants.IsInternalChannel(ts.channel) {
+		return
+	}
+	al.bus.PublishOutbound(ctx, outboundMessageForTurn(ts, fmt.Sprintf(
+		"I compressed our earlier conversation to fit the model's context window "+
+			"(%d older messages dropped). Some older details may now only be available as a summary.",
+		dropped,
+	)))
+}
+
+// greetNewPeer sends the channel's greeting the first time a sender writes
+// to it, before the agent answers. Peers are remembered in the workspace
+// state so restarts do not greet them again. Internal channels and
+// cron/heartbeat turns are skipped.
+func (al *AgentLoop) greetNewPeer(ctx context.Context, msg bus.InboundMessage, sessionKey string) {
+	if al.state == nil || constants.IsInternalChannel(msg.Channel) {
+		return
+	}
+	switch msg.SenderID {
+	case "", "cron", "heartbeat":
+		return
+	}
+	cfg := al.GetConfig()
+	if cfg == nil {
+		return
+	}
+	ch := cfg.Channels.Get(msg.Channel)
+	if ch == nil || strings.TrimSpace(ch.Greeting) == "" {
+		return
+	}
+
+	peer := msg.Channel + ":" + msg.SenderID
+	added, err := al.state.MarkPeerGreeted(peer)
+	if err != nil {
+		logger.WarnCF("agent", "Failed to record greeted peer", map[string]any{
+			"peer":  peer,
+			"error": err.Error(),
+		})
+	}
+	if !added {
+		return
+	}
+
+	al.bus.PublishOutbound(ctx, bus.OutboundMessage{
+		Context:    bus.NewOutboundContext(msg.Channel, msg.ChatID, ""),
+		SessionKey: sessionKey,
+		Content:    strings.TrimSpace(ch.Greeting),
+	})
+	logger.InfoCF("agent", "Greeted new peer", map[string]any{
+		"channel":   msg.Channel,
+		"chat_id":   msg.ChatID,
+		"sender_id": msg.SenderID,
+	})
+}
+
+// maxShownReasoningChars caps reasoning posted into the chat by
+// show_reasoning; thinking models can produce far more than anyone reads.
+const maxShownReasoningChars = 3000
+
+// showsReasoningInChat reports whether the channel's show_reasoning option
+// asks for the model's reasoning to be posted alongside the answer.
+func (al *AgentLoop) showsReasoningInChat(channelName string) bool {
+	cfg := al.GetConfig()
+	if cfg == nil {
+		return false
+	}
+	ch := cfg.Channels.Get(channelName)
+	return ch != nil && ch.ShowReasoning
+}
+
+// publishReasoningToChat posts reasoning into the conversation as a separate
+// thought message. It is published synchronously so it arrives before the
+// final answer, and the thought kind keeps it from replacing the placeholder.
+func (al *AgentLoop) publishReasoningToChat(ctx context.Context, ts *turnState, reasoningContent, modelName string) {
+	reasoningContent = strings.TrimSpace(reasoningContent)
+	if reasoningContent == "" || ctx.Err() != nil {
+		return
+	}
+
+	pubCtx, pubCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer pubCancel()
+
+	msg := outboundMessageForTurnWithOptions(
+		ts,
+		"💭 Thinking\n\n"+utils.Truncate(reasoningContent, maxShownReasoningChars),
+		outboundTurnMessageOptions{kind: messageKindThought, modelName: modelName},
+	)
+	if err := al.bus.PublishOutbound(pubCtx, msg); err != nil {
+		logger.DebugCF("agent", "Reasoning publish to chat skipped", map[string]any{
+			"channel": ts.channel,
+			"error":   err.Error(),
+		})
+	}
+}
+
 func (al *AgentLoop) publishPicoReasoning(
 	ctx context.Context,
 	reasoningContent, chatID, sessionKey, modelName string,
@@ -0,0 +1,232 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// QueueStats is a point-in-time view of inbound work held by the agent loop.
+type QueueStats struct {
+	// ActiveTurns is the number of turns currently holding a worker slot.
+	ActiveTurns int `json:"active_turns"`
+	// WorkerSlots is the configured number of concurrent turns.
+	WorkerSlots int `json:"worker_slots"`
+	// WaitingSessions counts sessions whose first message waits for a slot.
+	WaitingSessions int `json:"waiting_sessions"`
+	// QueuedMessages counts messages queued behind an active turn.
+	QueuedMessages int `json:"queued_messages"`
+	// Dropped counts messages rejected because their session queue was full.
+	Dropped uint64 `json:"dropped"`
+	// Coalesced counts messages merged into the turn of an earlier message.
+	Coalesced uint64 `json:"coalesced"`
+	// ActiveSubagents counts spawned subagents running across all agents.
+	ActiveSubagents int `json:"active_subagents"`
+	// QueuedSubagents counts spawns waiting for a free subagent slot.
+	QueuedSubagents int `json:"queued_subagents"`
+}
+
+// QueueStats reports how much inbound work is waiting, for health and
+// metrics endpoints.
+func (al *AgentLoop) QueueStats() QueueStats {
+	stats := QueueStats{
+		ActiveTurns:     len(al.workerSem),
+		WorkerSlots:     cap(al.workerSem),
+		WaitingSessions: int(al.queueWaiting.Load()),
+		Dropped:         al.queueDropped.Load(),
+		Coalesced:       al.queueCoalesced.Load(),
+	}
+	if al.steering != nil {
+		stats.QueuedMessages = al.steering.len()
+	}
+	if registry := al.GetRegistry(); registry != nil {
+		for _, agentID := range registry.ListAgentIDs() {
+			agent, ok := registry.GetAgent(agentID)
+			if !ok || agent == nil || agent.SubagentManager == nil {
+				continue
+			}
+			running, queued := agent.SubagentManager.Counts()
+			stats.ActiveSubagents += running
+			stats.QueuedSubagents += queued
+		}
+	}
+	return stats
+}
+
+// coalesceBuffer collects messages a sender fires off right after the one
+// that claimed their session, so they can be answered in a single turn.
+type coalesceBuffer struct {
+	mu       sync.Mutex
+	senderID string
+	msgs     []bus.InboundMessage
+	closed   bool
+}
+
+func (al *AgentLoop) coalesceWindow() time.Duration {
+	if al.cfg == nil || al.cfg.Agents.Defaults.Queue.CoalesceWindowMS <= 0 {
+		return 0
+	}
+	return time.Duration(al.cfg.Agents.Defaults.Queue.CoalesceWindowMS) * time.Millisecond
+}
+
+func (al *AgentLoop) queueNotifyEnabled() bool {
+	return al.cfg != nil && al.cfg.Agents.Defaults.Queue.Notify
+}
+
+// isCoalescable reports whether msg may be merged with its neighbours.
+// Commands keep their own turn so they are never swallowed into prose.
+func isCoalescable(msg bus.InboundMessage) bool {
+	return msg.SenderID != "" && !strings.HasPrefix(strings.TrimSpace(msg.Content), "/")
+}
+
+// openCoalesceBuffer starts collecting follow-ups for the message that just
+// claimed sessionKey. It returns nil when coalescing does not apply.
+func (al *AgentLoop) openCoalesceBuffer(sessionKey string, msg bus.InboundMessage) *coalesceBuffer {
+	if al.coalesceWindow() <= 0 || !isCoalescable(msg) {
+		return nil
+	}
+	buf := &coalesceBuffer{senderID: msg.SenderID}
+	al.coalescing.Store(sessionKey, buf)
+	return buf
+}
+
+// tryCoalesce adds msg to the open buffer of its session when it comes from
+// the sender that opened it. It returns false once the window has closed.
+func (al *AgentLoop) tryCoalesce(sessionKey string, msg bus.InboundMessage) bool {
+	v, ok := al.coalescing.Load(sessionKey)
+	if !ok || !isCoalescable(msg) {
+		return false
+	}
+	buf := v.(*coalesceBuffer)
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	if buf.closed || buf.senderID != msg.SenderID || len(buf.msgs) >= MaxQueueSize {
+		return false
+	}
+	buf.msgs = append(buf.msgs, msg)
+	return true
+}
+
+// awaitCoalesced waits out the coalesce window and folds every message the
+// buffer collected into first, in arrival order.
+func (al *AgentLoop) awaitCoalesced(
+	ctx context.Context,
+	sessionKey string,
+	buf *coalesceBuffer,
+	first bus.InboundMessage,
+) bus.InboundMessage {
+	timer := time.NewTimer(al.coalesceWindow())
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+	}
+
+	buf.mu.Lock()
+	buf.closed = true
+	msgs := buf.msgs
+	buf.msgs = nil
+	buf.mu.Unlock()
+	al.coalescing.CompareAndDelete(sessionKey, buf)
+
+	if len(msgs) == 0 {
+		return first
+	}
+	merged := first
+	parts := []string{first.Content}
+	merged.Media = append([]string(nil), first.Media...)
+	for _, m := range msgs {
+		if content := strings.TrimSpace(m.Content); content != "" {
+			parts = append(parts, m.Content)
+		}
+		merged.Media = append(merged.Media, m.Media...)
+	}
+	merged.Content = strings.Join(parts, "\n")
+	al.queueCoalesced.Add(uint64(len(msgs)))
+	logger.DebugCF("agent", "Coalesced rapid messages into one turn", map[string]any{
+		"session_key": sessionKey,
+		"merged":      len(msgs) + 1,
+	})
+	return merged
+}
+
+// acquireWorkerSlot blocks until a turn may run, telling the sender where
+// they stand when every slot is taken. It returns false if ctx ends first.
+func (al *AgentLoop) acquireWorkerSlot(ctx context.Context, msg bus.InboundMessage, sessionKey string) bool {
+	select {
+	case al.workerSem <- struct{}{}:
+		return true
+	default:
+	}
+
+	position := al.queueWaiting.Add(1)
+	defer al.queueWaiting.Add(-1)
+	if al.queueNotifyEnabled() {
+		al.publishQueueNotice(ctx, msg, sessionKey, fmt.Sprintf(
+			"You're #%d in the queue. I'll reply as soon as I finish the conversations ahead of you.",
+			position,
+		))
+	}
+
+	select {
+	case al.workerSem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// notifyQueuedBehindTurn reports the position of a message that was queued
+// behind the active turn of its own session.
+func (al *AgentLoop) notifyQueuedBehindTurn(ctx context.Context, msg bus.InboundMessage, sessionKey string) {
+	if !al.queueNotifyEnabled() || al.steering == nil {
+		return
+	}
+	position := al.steering.lenScope(sessionKey)
+	if position <= 0 {
+		return
+	}
+	go al.publishQueueNotice(ctx, msg, sessionKey, fmt.Sprintf(
+		"You're #%d in the queue. I'll get to this once I'm done with your earlier message.",
+		position,
+	))
+}
+
+// notifyQueueFull tells the sender their message was not accepted, so a
+// burst that overflows the session queue is not lost without a trace.
+func (al *AgentLoop) notifyQueueFull(ctx context.Context, msg bus.InboundMessage, sessionKey string) {
+	al.queueDropped.Add(1)
+	go al.publishQueueNotice(ctx, msg, sessionKey, fmt.Sprintf(
+		"I already have %d messages from this chat waiting, so I couldn't queue this one. "+
+			"Please send it again after my next reply.",
+		MaxQueueSize,
+	))
+}
+
+func (al *AgentLoop) publishQueueNotice(ctx context.Context, msg bus.InboundMessage, sessionKey, content string) {
+	if al.bus == nil || msg.ChatID == "" || ctx.Err() != nil {
+		return
+	}
+	pubCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	err := al.bus.PublishOutbound(pubCtx, bus.OutboundMessage{
+		Context:    outboundContextFromInbound(&msg.Context, msg.Channel, msg.ChatID, msg.MessageID),
+		SessionKey: sessionKey,
+		Content:    content,
+	})
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) &&
+		!errors.Is(err, context.Canceled) &&
+		!errors.Is(err, bus.ErrBusClosed) {
+		logger.WarnCF("agent", "Failed to publish queue notice", map[string]any{
+			"channel": msg.Channel,
+			"chat_id": msg.ChatID,
+			"error":   err.Error(),
+		})
+	}
+}
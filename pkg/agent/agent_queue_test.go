@@ -0,0 +1,169 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+func startQueueTestLoop(
+	t *testing.T,
+	queue config.InboundQueueConfig,
+	provider providers.LLMProvider,
+) (*AgentLoop, *bus.MessageBus) {
+	t.Helper()
+	// A released turn may still be saving its session when Run returns, so
+	// the workspace is removed best-effort rather than through t.TempDir.
+	workspace, err := os.MkdirTemp("", "agent-queue-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(workspace) })
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         workspace,
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				MaxParallelTurns:  1,
+				Queue:             queue,
+			},
+		},
+	}
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, provider)
+
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	runErrCh := make(chan error, 1)
+	go func() {
+		runErrCh <- al.Run(runCtx)
+	}()
+	t.Cleanup(func() {
+		if p, ok := provider.(*lateSteeringProvider); ok {
+			select {
+			case <-p.releaseFirstCall:
+			default:
+				close(p.releaseFirstCall)
+			}
+		}
+		cancelRun()
+		select {
+		case <-runErrCh:
+		case <-time.After(2 * time.Second):
+			t.Error("timeout waiting for Run to stop")
+		}
+	})
+	return al, msgBus
+}
+
+func publishQueueTestMessage(t *testing.T, msgBus *bus.MessageBus, chat, sender, content string) {
+	t.Helper()
+	if err := msgBus.PublishInbound(context.Background(), bus.InboundMessage{
+		Context: bus.InboundContext{
+			Channel:  "test",
+			ChatID:   chat,
+			ChatType: "direct",
+			SenderID: sender,
+		},
+		Content:    content,
+		SessionKey: session.BuildOpaqueSessionKey("agent:main:test:" + chat),
+	}); err != nil {
+		t.Fatalf("PublishInbound(%q) error = %v", content, err)
+	}
+}
+
+// waitForOutbound returns the first outbound message whose content contains
+// want, skipping any others.
+func waitForOutbound(t *testing.T, msgBus *bus.MessageBus, want string) bus.OutboundMessage {
+	t.Helper()
+	deadline := time.After(3 * time.Second)
+	for {
+		select {
+		case out := <-msgBus.OutboundChan():
+			if strings.Contains(out.Content, want) {
+				return out
+			}
+		case <-deadline:
+			t.Fatalf("timed out waiting for outbound containing %q", want)
+		}
+	}
+}
+
+func TestAgentLoop_Run_CoalescesRapidMessagesFromSameSender(t *testing.T) {
+	provider := &recordingProvider{}
+	al, msgBus := startQueueTestLoop(t, config.InboundQueueConfig{CoalesceWindowMS: 300}, provider)
+
+	publishQueueTestMessage(t, msgBus, "chat-1", "user-1", "hey")
+	publishQueueTestMessage(t, msgBus, "chat-1", "user-1", "can you check the build")
+	publishQueueTestMessage(t, msgBus, "chat-1", "user-1", "on main please")
+
+	waitForOutbound(t, msgBus, "Mock response")
+
+	var lastUser string
+	for _, m := range provider.lastMessages {
+		if m.Role == "user" {
+			lastUser = m.Content
+		}
+	}
+	if !strings.Contains(lastUser, "hey\ncan you check the build\non main please") {
+		t.Fatalf("last user message = %q, want all three messages in order", lastUser)
+	}
+	if got := al.QueueStats().Coalesced; got != 2 {
+		t.Fatalf("Coalesced = %d, want 2", got)
+	}
+
+	select {
+	case out := <-msgBus.OutboundChan():
+		t.Fatalf("unexpected second outbound %q; messages should share one turn", out.Content)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestAgentLoop_Run_QueueNoticesAndOverflow(t *testing.T) {
+	provider := &lateSteeringProvider{
+		firstCallStarted: make(chan struct{}),
+		releaseFirstCall: make(chan struct{}),
+	}
+	al, msgBus := startQueueTestLoop(t, config.InboundQueueConfig{Notify: true}, provider)
+
+	publishQueueTestMessage(t, msgBus, "busy-chat", "user-1", "long task")
+	select {
+	case <-provider.firstCallStarted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for first turn to start")
+	}
+
+	publishQueueTestMessage(t, msgBus, "busy-chat", "user-1", "follow-up 1")
+	out := waitForOutbound(t, msgBus, "You're #1 in the queue")
+	if out.Context.ChatID != "busy-chat" {
+		t.Fatalf("queue notice sent to %q, want busy-chat", out.Context.ChatID)
+	}
+
+	// A different session has to wait for the only worker slot.
+	publishQueueTestMessage(t, msgBus, "other-chat", "user-2", "hello")
+	out = waitForOutbound(t, msgBus, "conversations ahead of you")
+	if out.Context.ChatID != "other-chat" || !strings.Contains(out.Content, "#1") {
+		t.Fatalf("worker-slot notice = %+v", out)
+	}
+
+	for i := 2; i <= MaxQueueSize+1; i++ {
+		publishQueueTestMessage(t, msgBus, "busy-chat", "user-1", "follow-up")
+	}
+	waitForOutbound(t, msgBus, "couldn't queue this one")
+
+	stats := al.QueueStats()
+	if stats.QueuedMessages != MaxQueueSize || stats.Dropped != 1 || stats.WaitingSessions != 1 {
+		t.Fatalf("QueueStats() = %+v, want full session queue, one drop, one waiting session", stats)
+	}
+	if stats.ActiveTurns != 1 || stats.WorkerSlots != 1 {
+		t.Fatalf("QueueStats() = %+v, want the single worker slot in use", stats)
+	}
+}
@@ -0,0 +1,136 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+var (
+	// ErrSessionNotFound is returned when no agent has stored history under
+	// the requested session key.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionBusy is returned when a session cannot be changed because a
+	// turn is running in it.
+	ErrSessionBusy = errors.New("session has an active turn")
+)
+
+// SessionInfo describes one stored conversation.
+type SessionInfo struct {
+	Key          string `json:"key"`
+	AgentID      string `json:"agent_id"`
+	MessageCount int    `json:"message_count"`
+	HasSummary   bool   `json:"has_summary"`
+	Active       bool   `json:"active"`
+}
+
+// ListSessions returns every stored session across all agents, sorted by
+// key. A key stored by more than one agent is reported for the first agent
+// in ID order.
+func (al *AgentLoop) ListSessions() []SessionInfo {
+	registry := al.GetRegistry()
+	if registry == nil {
+		return nil
+	}
+	agentIDs := registry.ListAgentIDs()
+	sort.Strings(agentIDs)
+
+	seen := make(map[string]struct{})
+	var sessions []SessionInfo
+	for _, agentID := range agentIDs {
+		agent, ok := registry.GetAgent(agentID)
+		if !ok || agent == nil || agent.Sessions == nil {
+			continue
+		}
+		for _, key := range agent.Sessions.ListSessions() {
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			sessions = append(sessions, SessionInfo{
+				Key:          key,
+				AgentID:      agent.ID,
+				MessageCount: len(agent.Sessions.GetHistory(key)),
+				HasSummary:   agent.Sessions.GetSummary(key) != "",
+				Active:       al.GetActiveTurnBySession(key) != nil,
+			})
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Key < sessions[j].Key })
+	return sessions
+}
+
+// SessionHistory returns the stored messages and summary of a session.
+func (al *AgentLoop) SessionHistory(key string) (SessionInfo, []providers.Message, string, error) {
+	agent := al.sessionOwner(key)
+	if agent == nil {
+		return SessionInfo{}, nil, "", ErrSessionNotFound
+	}
+	history := agent.Sessions.GetHistory(key)
+	summary := agent.Sessions.GetSummary(key)
+	info := SessionInfo{
+		Key:          key,
+		AgentID:      agent.ID,
+		MessageCount: len(history),
+		HasSummary:   summary != "",
+		Active:       al.GetActiveTurnBySession(key) != nil,
+	}
+	return info, history, summary, nil
+}
+
+// ClearSession removes the history and summary of a session, as /clear does
+// from inside the conversation. Sessions with a running turn are refused.
+func (al *AgentLoop) ClearSession(ctx context.Context, key string) error {
+	agent := al.sessionOwner(key)
+	if agent == nil {
+		return ErrSessionNotFound
+	}
+	if al.GetActiveTurnBySession(key) != nil {
+		return ErrSessionBusy
+	}
+	if al.contextManager != nil {
+		if err := al.contextManager.Clear(ctx, key); err != nil {
+			return err
+		}
+	}
+	if al.timers != nil {
+		al.timers.CancelSession(key)
+	}
+	if err := removeStagedAttachments(agent.Workspace, key); err != nil {
+		return err
+	}
+	// The context manager clears the default agent's store; a session owned
+	// by another agent lives in that agent's store.
+	if len(agent.Sessions.GetHistory(key)) == 0 && agent.Sessions.GetSummary(key) == "" {
+		return nil
+	}
+	agent.Sessions.SetHistory(key, []providers.Message{})
+	agent.Sessions.SetSummary(key, "")
+	return agent.Sessions.Save(key)
+}
+
+// sessionOwner returns the first agent, in ID order, whose store lists key.
+func (al *AgentLoop) sessionOwner(key string) *AgentInstance {
+	registry := al.GetRegistry()
+	if registry == nil || key == "" {
+		return nil
+	}
+	agentIDs := registry.ListAgentIDs()
+	sort.Strings(agentIDs)
+	for _, agentID := range agentIDs {
+		agent, ok := registry.GetAgent(agentID)
+		if !ok || agent == nil || agent.Sessions == nil {
+			continue
+		}
+		for _, stored := range agent.Sessions.ListSessions() {
+			if stored == key {
+				return agent
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestAgentLoop_ListAndClearSessions(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	agent := al.GetRegistry().GetDefaultAgent()
+	agent.Sessions.SetHistory("s:b", []providers.Message{{Role: "user", Content: "hi"}})
+	agent.Sessions.SetHistory("s:a", []providers.Message{
+		{Role: "user", Content: "hello"},
+		{Role: "assistant", Content: "hi there"},
+	})
+	agent.Sessions.SetSummary("s:a", "greetings")
+
+	sessions := al.ListSessions()
+	var keys []string
+	for _, s := range sessions {
+		if s.Key == "s:a" || s.Key == "s:b" {
+			keys = append(keys, s.Key)
+		}
+	}
+	if len(keys) != 2 || keys[0] != "s:a" || keys[1] != "s:b" {
+		t.Fatalf("ListSessions keys = %v, want [s:a s:b]", keys)
+	}
+
+	info, history, summary, err := al.SessionHistory("s:a")
+	if err != nil {
+		t.Fatalf("SessionHistory() error = %v", err)
+	}
+	if info.MessageCount != 2 || !info.HasSummary || len(history) != 2 || summary != "greetings" {
+		t.Fatalf("SessionHistory() = %+v, %d messages, %q", info, len(history), summary)
+	}
+
+	if err := al.ClearSession(context.Background(), "s:a"); err != nil {
+		t.Fatalf("ClearSession() error = %v", err)
+	}
+	if got := agent.Sessions.GetHistory("s:a"); len(got) != 0 {
+		t.Fatalf("history after clear = %+v", got)
+	}
+	if got := agent.Sessions.GetSummary("s:a"); got != "" {
+		t.Fatalf("summary after clear = %q", got)
+	}
+
+	if _, _, _, err := al.SessionHistory("missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("SessionHistory(missing) error = %v, want ErrSessionNotFound", err)
+	}
+	if err := al.ClearSession(context.Background(), "missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("ClearSession(missing) error = %v, want ErrSessionNotFound", err)
+	}
+}
@@ -506,6 +506,97 @@ func TestProcessMessage_IncludesCurrentSenderInDynamicContext(t *testing.T) {
 	}
 }
 
+func TestProcessMessage_VoiceWithoutTranscriberRepliesWithSetupHint(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+	response, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel: "telegram",
+		ChatID:  "1",
+		Content: "[voice]",
+	}))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if response != voiceTranscriptionUnavailableMsg {
+		t.Fatalf("processMessage() response = %q, want setup hint", response)
+	}
+	if provider.lastMessages != nil {
+		t.Fatalf("provider was called for untranscribable voice message: %+v", provider.lastMessages)
+	}
+
+	response, err = al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel: "telegram",
+		ChatID:  "1",
+		Content: "what does this say? [voice]",
+	}))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if response != "Mock response" {
+		t.Fatalf("processMessage() response = %q, want captioned voice to reach the model", response)
+	}
+}
+
+func TestProcessMessage_VoiceWithoutTranscriberReachesAudioModel(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				ModelName:         "audio-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		ModelList: []*config.ModelConfig{
+			{ModelName: "audio-model", Model: "openai/gpt-4o-audio-preview", AudioInput: true},
+		},
+	}
+
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	store := media.NewFileMediaStore()
+	al.SetMediaStore(store)
+
+	voicePath := filepath.Join(tmpDir, "voice.ogg")
+	if err := os.WriteFile(voicePath, []byte("OggS fake voice"), 0o644); err != nil {
+		t.Fatalf("WriteFile(voicePath) error = %v", err)
+	}
+	ref, err := store.Store(voicePath, media.MediaMeta{Filename: "voice.ogg", ContentType: "audio/ogg"}, "test")
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	response, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel: "telegram",
+		ChatID:  "1",
+		Content: "[voice]",
+		Media:   []string{ref},
+	}))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if response != "Mock response" {
+		t.Fatalf("processMessage() response = %q, want the voice note to reach the audio model", response)
+	}
+	last := provider.lastMessages[len(provider.lastMessages)-1]
+	if len(last.Media) != 1 || !strings.HasPrefix(last.Media[0], "data:audio/ogg;base64,") {
+		t.Fatalf("voice note not sent as audio: %+v", last.Media)
+	}
+}
+
 func TestProcessMessage_DoesNotPassImplicitThinkingOffToCapableProvider(t *testing.T) {
 	cfg := &config.Config{
 		Agents: config.AgentsConfig{
@@ -646,6 +737,118 @@ func TestProcessMessage_PassesDeepSeekThinkingLevelToThinkingCapableProvider(t *
 	}
 }
 
+func TestProcessMessage_AppliesModelProfileOverDefaults(t *testing.T) {
+	defaultTemp, profileTemp := 0.3, 1.0
+	tests := []struct {
+		name          string
+		entryThinking string
+		wantThinking  string
+	}{
+		{"profile fills thinking level", "", "high"},
+		{"model_list entry wins", "low", "low"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Agents: config.AgentsConfig{
+					Defaults: config.AgentDefaults{
+						Workspace:         t.TempDir(),
+						ModelName:         "reasoner",
+						MaxTokens:         4096,
+						Temperature:       &defaultTemp,
+						MaxToolIterations: 10,
+						ModelProfiles: config.ModelProfiles{
+							"*":        {MaxTokens: 2048},
+							"o3*":      {MaxTokens: 16000, ThinkingLevel: "high", StopSequences: []string{"END"}},
+							"reasoner": {Temperature: &profileTemp},
+							"fast-*":   {MaxTokens: 512},
+						},
+					},
+				},
+				ModelList: []*config.ModelConfig{{
+					ModelName:     "reasoner",
+					Provider:      "deepseek",
+					Model:         "o3-mini",
+					ThinkingLevel: tt.entryThinking,
+				}},
+			}
+
+			provider := &thinkingRecordingProvider{}
+			al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+			if _, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+				Channel: "pico",
+				ChatID:  "chat-1",
+				Content: "hello",
+			})); err != nil {
+				t.Fatalf("processMessage() error = %v", err)
+			}
+
+			opts := provider.lastOptions
+			if got := opts["max_tokens"]; got != 16000 {
+				t.Errorf("max_tokens = %#v, want 16000 from the o3* profile", got)
+			}
+			if got := opts["temperature"]; got != profileTemp {
+				t.Errorf("temperature = %#v, want %g from the exact profile", got, profileTemp)
+			}
+			if got, _ := opts["stop"].([]string); len(got) != 1 || got[0] != "END" {
+				t.Errorf("stop = %#v, want [END]", opts["stop"])
+			}
+			if got := opts["thinking_level"]; got != tt.wantThinking {
+				t.Errorf("thinking_level = %#v, want %q", got, tt.wantThinking)
+			}
+		})
+	}
+}
+
+func TestProcessMessage_ExpandsAliases(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				Aliases: config.MessageAliases{
+					"!standup": "Write my standup notes. Focus: {{arg}}",
+					"!help":    "should never replace the built-in /help command",
+					"!loop":    "!loop again",
+				},
+			},
+		},
+	}
+
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	process := func(content string) string {
+		t.Helper()
+		response, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+			Channel: "telegram",
+			ChatID:  "1",
+			Content: content,
+		}))
+		if err != nil {
+			t.Fatalf("processMessage(%q) error = %v", content, err)
+		}
+		return response
+	}
+
+	process("!Standup release blockers")
+	last := provider.lastMessages[len(provider.lastMessages)-1]
+	if want := "Write my standup notes. Focus: release blockers"; last.Content != want {
+		t.Fatalf("user message = %q, want %q", last.Content, want)
+	}
+
+	provider.lastMessages = nil
+	if response := process("!help"); !strings.Contains(response, "/help") || provider.lastMessages != nil {
+		t.Fatalf("!help response = %q, provider called = %v; want the built-in command", response,
+			provider.lastMessages != nil)
+	}
+
+	if response := process("!loop"); !strings.Contains(response, "levels deep") || provider.lastMessages != nil {
+		t.Fatalf("!loop response = %q, want loop error without calling the provider", response)
+	}
+}
+
 func TestProcessMessage_SuppressesReasoningWhenThinkingOff(t *testing.T) {
 	cfg := &config.Config{
 		Agents: config.AgentsConfig{
@@ -1582,6 +1785,30 @@ func TestRecordLastChannel(t *testing.T) {
 	}
 }
 
+func TestLastChannel(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	for _, tt := range []struct {
+		recorded            string
+		wantChannel, wantID string
+	}{
+		{"", "", ""},
+		{"telegram:123456", "telegram", "123456"},
+		{"slack:C1:thread", "slack", "C1:thread"},
+		{"cli:direct", "", ""},
+		{"malformed", "", ""},
+	} {
+		if err := al.RecordLastChannel(tt.recorded); err != nil {
+			t.Fatalf("RecordLastChannel(%q) failed: %v", tt.recorded, err)
+		}
+		if channel, chatID := al.LastChannel(); channel != tt.wantChannel || chatID != tt.wantID {
+			t.Errorf("LastChannel() after %q = %q, %q; want %q, %q",
+				tt.recorded, channel, chatID, tt.wantChannel, tt.wantID)
+		}
+	}
+}
+
 func TestRecordLastChatID(t *testing.T) {
 	al, cfg, msgBus, provider, cleanup := newTestAgentLoop(t)
 	defer cleanup()
@@ -4356,11 +4583,28 @@ func TestAgentLoop_ContextExhaustionRetry(t *testing.T) {
 	if len(finalHistory) >= 7 {
 		t.Errorf("Expected history to be compressed (len < 7), got %d", len(finalHistory))
 	}
+
+	// The user is told that older messages were dropped.
+	var notice string
+	for notice == "" {
+		select {
+		case msg := <-msgBus.OutboundChan():
+			if strings.Contains(msg.Content, "compressed our earlier conversation") {
+				notice = msg.Content
+			}
+		default:
+			t.Fatal("expected a compression notice on the outbound bus")
+		}
+	}
+	if !strings.Contains(notice, "older messages dropped") {
+		t.Errorf("notice = %q, want it to say how many messages were dropped", notice)
+	}
 }
 
 type visionUnsupportedMediaProvider struct {
-	calls     int
-	mediaSeen []bool
+	calls           int
+	mediaSeen       []bool
+	lastUserContent string
 }
 
 func (p *visionUnsupportedMediaProvider) Chat(
@@ -4374,6 +4618,9 @@ func (p *visionUnsupportedMediaProvider) Chat(
 
 	hasMedia := false
 	for _, msg := range messages {
+		if msg.Role == "user" {
+			p.lastUserContent = msg.Content
+		}
 		for _, ref := range msg.Media {
 			if strings.TrimSpace(ref) != "" {
 				hasMedia = true
@@ -4644,7 +4891,7 @@ func (p *loadImageThenTextFollowUpProvider) GetDefaultModel() string {
 	return "load-image-then-text-follow-up-model"
 }
 
-func TestAgentLoop_VisionUnsupportedErrorReturnsClearFailure(t *testing.T) {
+func TestAgentLoop_VisionUnsupportedSkipsAttachmentsWithNote(t *testing.T) {
 	workspace := t.TempDir()
 
 	cfg := &config.Config{
@@ -4679,20 +4926,20 @@ func TestAgentLoop_VisionUnsupportedErrorReturnsClearFailure(t *testing.T) {
 		Media:      []string{"data:image/png;base64,abc123"},
 		SessionKey: sessionKey,
 	}))
-	if err == nil {
-		t.Fatal("processMessage() error = nil, want vision unsupported failure")
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
 	}
-	if resp != "" {
-		t.Fatalf("response = %q, want empty response on error", resp)
+	if resp != "ok" {
+		t.Fatalf("response = %q, want %q", resp, "ok")
 	}
-	if !strings.Contains(err.Error(), `active model "test-model" does not support image input`) {
-		t.Fatalf("error = %q, want clear vision unsupported guidance", err.Error())
+	if provider.calls != 2 {
+		t.Fatalf("calls = %d, want %d (one retry without media)", provider.calls, 2)
 	}
-	if provider.calls != 1 {
-		t.Fatalf("calls = %d, want %d (no retry without media)", provider.calls, 1)
+	if !slices.Equal(provider.mediaSeen, []bool{true, false}) {
+		t.Fatalf("mediaSeen = %v, want %v", provider.mediaSeen, []bool{true, false})
 	}
-	if !slices.Equal(provider.mediaSeen, []bool{true}) {
-		t.Fatalf("mediaSeen = %v, want %v", provider.mediaSeen, []bool{true})
+	if !strings.Contains(provider.lastUserContent, `1 attachment(s) skipped: the active model "test-model"`) {
+		t.Fatalf("last user content = %q, want skipped attachment note", provider.lastUserContent)
 	}
 
 	agent := al.registry.GetDefaultAgent()
@@ -5270,8 +5517,9 @@ func TestAgentLoop_ToolLimitUsesDedicatedFallback(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
 	}
-	if response != toolLimitResponse {
-		t.Fatalf("response = %q, want %q", response, toolLimitResponse)
+	wantResponse := fmt.Sprintf(toolLimitResponse, 1)
+	if response != wantResponse {
+		t.Fatalf("response = %q, want %q", response, wantResponse)
 	}
 
 	defaultAgent := al.registry.GetDefaultAgent()
@@ -5292,9 +5540,174 @@ func TestAgentLoop_ToolLimitUsesDedicatedFallback(t *testing.T) {
 		t.Fatalf("history len = %d, want 4", len(history))
 	}
 	assertRoles(t, history, "user", "assistant", "tool", "assistant")
-	if history[3].Content != toolLimitResponse {
-		t.Fatalf("final assistant content = %q, want %q", history[3].Content, toolLimitResponse)
+	if history[3].Content != wantResponse {
+		t.Fatalf("final assistant content = %q, want %q", history[3].Content, wantResponse)
+	}
+}
+
+func TestAgentLoop_OnIterationLimitBehaviors(t *testing.T) {
+	tests := []struct {
+		behavior  string
+		wantCalls int
+		wantNote  string
+	}{
+		{behavior: "", wantCalls: 1, wantNote: "tool-iteration limit of 1 before finishing"},
+		{behavior: "ask", wantCalls: 1, wantNote: "Reply \"continue\""},
+		{behavior: "continue", wantCalls: 1, wantNote: "Reply /continue"},
+	}
+
+	for _, tt := range tests {
+		t.Run("behavior="+tt.behavior, func(t *testing.T) {
+			cfg := &config.Config{
+				Agents: config.AgentsConfig{
+					Defaults: config.AgentDefaults{
+						Workspace:         t.TempDir(),
+						ModelName:         "test-model",
+						MaxTokens:         4096,
+						MaxToolIterations: 1,
+						OnIterationLimit:  tt.behavior,
+					},
+				},
+			}
+
+			provider := &partialToolLimitProvider{}
+			al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+			al.RegisterTool(&toolLimitTestTool{})
+
+			response, err := al.ProcessDirectWithChannel(context.Background(), "hello", "tool-limit", "test", "chat1")
+			if err != nil {
+				t.Fatalf("ProcessDirectWithChannel failed: %v", err)
+			}
+			if provider.calls != tt.wantCalls {
+				t.Fatalf("provider calls = %d, want %d", provider.calls, tt.wantCalls)
+			}
+			if !strings.Contains(response, tt.wantNote) {
+				t.Fatalf("response = %q, want note containing %q", response, tt.wantNote)
+			}
+		})
+	}
+}
+
+func TestAgentLoop_ContinueNeedsUserAndIsBounded(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 2,
+				OnIterationLimit:  "continue",
+			},
+		},
 	}
+	provider := &partialToolLimitProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	al.RegisterTool(&toolLimitTestTool{})
+
+	send := func(content string) string {
+		t.Helper()
+		response, err := al.ProcessDirectWithChannel(context.Background(), content, "tool-limit", "test", "chat1")
+		if err != nil {
+			t.Fatalf("ProcessDirectWithChannel(%q) failed: %v", content, err)
+		}
+		return response
+	}
+
+	// The turn stops at the limit and waits for the user instead of
+	// extending itself.
+	if response := send("hello"); !strings.Contains(response, "Reply /continue") {
+		t.Fatalf("first response = %q, want a /continue offer", response)
+	}
+	if provider.calls != 2 {
+		t.Fatalf("provider calls after the first turn = %d, want 2", provider.calls)
+	}
+
+	// /continue grants one more budget; running out again stops for good.
+	response := send("/continue")
+	if provider.calls != 4 {
+		t.Fatalf("provider calls after /continue = %d, want 4", provider.calls)
+	}
+	if strings.Contains(response, "/continue") || !strings.Contains(response, "max_tool_iterations") {
+		t.Fatalf("continued response = %q, want the final stop note", response)
+	}
+	if response := send("/continue"); !strings.Contains(response, "no cut-off task") {
+		t.Fatalf("second /continue = %q, want it refused", response)
+	}
+	if provider.calls != 4 {
+		t.Fatalf("provider calls after a refused /continue = %d, want 4", provider.calls)
+	}
+}
+
+func TestAgentLoop_NewMessageDropsContinueOffer(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 2,
+				OnIterationLimit:  "continue",
+			},
+		},
+	}
+	provider := &partialToolLimitProvider{finishOn: "never mind, what time is it?"}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	al.RegisterTool(&toolLimitTestTool{})
+
+	send := func(content string) string {
+		t.Helper()
+		response, err := al.ProcessDirectWithChannel(context.Background(), content, "tool-limit", "test", "chat1")
+		if err != nil {
+			t.Fatalf("ProcessDirectWithChannel(%q) failed: %v", content, err)
+		}
+		return response
+	}
+
+	if response := send("hello"); !strings.Contains(response, "Reply /continue") {
+		t.Fatalf("first response = %q, want a /continue offer", response)
+	}
+	send("never mind, what time is it?")
+	calls := provider.calls
+	if response := send("/continue"); response != "There is no cut-off task to continue." {
+		t.Fatalf("/continue after a new message = %q, want it refused", response)
+	}
+	if provider.calls != calls {
+		t.Fatalf("provider calls after a refused /continue = %d, want %d", provider.calls, calls)
+	}
+}
+
+// partialToolLimitProvider always asks for another tool call, so the turn
+// only ends at the iteration cap, except that a user message equal to
+// finishOn is answered directly.
+type partialToolLimitProvider struct {
+	calls    int
+	finishOn string
+}
+
+func (m *partialToolLimitProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.calls++
+	if last := messages[len(messages)-1]; m.finishOn != "" && last.Role == "user" && last.Content == m.finishOn {
+		return &providers.LLMResponse{Content: "Done"}, nil
+	}
+	return &providers.LLMResponse{
+		Content: "Still working",
+		ToolCalls: []providers.ToolCall{{
+			ID:        fmt.Sprintf("call_partial_%d", m.calls),
+			Type:      "function",
+			Name:      "tool_limit_test_tool",
+			Arguments: map[string]any{"value": "x"},
+		}},
+	}, nil
+}
+
+func (m *partialToolLimitProvider) GetDefaultModel() string {
+	return "partial-tool-limit-model"
 }
 
 // TestProcessDirectWithChannel_TriggersMCPInitialization verifies that
@@ -5651,6 +6064,148 @@ func TestProcessMessage_PublishesReasoningContentToReasoningChannel(t *testing.T
 	}
 }
 
+func TestProcessMessage_ShowReasoningPostsThoughtToChat(t *testing.T) {
+	for _, show := range []bool{false, true} {
+		t.Run(fmt.Sprintf("show_reasoning=%v", show), func(t *testing.T) {
+			tmpDir, err := os.MkdirTemp("", "agent-test-*")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(tmpDir)
+			cfg := &config.Config{
+				Agents: config.AgentsConfig{
+					Defaults: config.AgentDefaults{
+						Workspace:         tmpDir,
+						ModelName:         "test-model",
+						MaxTokens:         4096,
+						MaxToolIterations: 10,
+					},
+				},
+				Channels: config.ChannelsConfig{
+					"telegram": &config.Channel{Type: config.ChannelTelegram, ShowReasoning: show},
+				},
+			}
+
+			msgBus := bus.NewMessageBus()
+			provider := &reasoningContentProvider{
+				response:         "final answer",
+				reasoningContent: "thinking trace",
+			}
+			al := NewAgentLoop(cfg, msgBus, provider)
+
+			response, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+				Channel:  "telegram",
+				SenderID: "user1",
+				ChatID:   "chat1",
+				Content:  "hello",
+			}))
+			if err != nil {
+				t.Fatalf("processMessage() error = %v", err)
+			}
+			if response != "final answer" {
+				t.Fatalf("processMessage() response = %q, want %q", response, "final answer")
+			}
+
+			select {
+			case outbound := <-msgBus.OutboundChan():
+				if !show {
+					t.Fatalf("unexpected outbound with show_reasoning off: %+v", outbound)
+				}
+				if outbound.Channel != "telegram" || outbound.ChatID != "chat1" {
+					t.Fatalf("reasoning route = %s/%s, want telegram/chat1", outbound.Channel, outbound.ChatID)
+				}
+				if outbound.Context.Raw[metadataKeyMessageKind] != messageKindThought {
+					t.Fatalf("reasoning kind = %q, want %q",
+						outbound.Context.Raw[metadataKeyMessageKind], messageKindThought)
+				}
+				if !strings.Contains(outbound.Content, "thinking trace") {
+					t.Fatalf("reasoning content = %q, want it to contain the trace", outbound.Content)
+				}
+			default:
+				if show {
+					t.Fatal("expected reasoning to be published to the chat before processMessage returned")
+				}
+			}
+		})
+	}
+}
+
+func TestProcessMessage_GreetsNewPeerOnce(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "agent-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	const greeting = "Hi! I'm the team assistant. Send :help to see what I can do."
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		Channels: config.ChannelsConfig{
+			"telegram": &config.Channel{Type: config.ChannelTelegram, Greeting: greeting},
+			"discord":  &config.Channel{Type: config.ChannelDiscord},
+		},
+	}
+
+	send := func(al *AgentLoop, msgBus *bus.MessageBus, channel, sender, chatID string) int {
+		t.Helper()
+		if _, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+			Channel:  channel,
+			SenderID: sender,
+			ChatID:   chatID,
+			Content:  "hello",
+		})); err != nil {
+			t.Fatalf("processMessage() error = %v", err)
+		}
+		greetings := 0
+		for {
+			select {
+			case outbound := <-msgBus.OutboundChan():
+				if outbound.Content == greeting {
+					if outbound.Channel != channel || outbound.ChatID != chatID {
+						t.Fatalf("greeting route = %s/%s, want %s/%s", outbound.Channel, outbound.ChatID, channel, chatID)
+					}
+					greetings++
+				}
+			default:
+				return greetings
+			}
+		}
+	}
+
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, &reasoningContentProvider{response: "ok"})
+	if n := send(al, msgBus, "telegram", "user1", "chat1"); n != 1 {
+		t.Fatalf("first message: %d greetings, want 1", n)
+	}
+	if n := send(al, msgBus, "telegram", "user1", "chat1"); n != 0 {
+		t.Fatalf("second message: %d greetings, want 0", n)
+	}
+	if n := send(al, msgBus, "telegram", "cron", "chat1"); n != 0 {
+		t.Fatalf("cron sender: %d greetings, want 0", n)
+	}
+	if n := send(al, msgBus, "discord", "user1", "chat1"); n != 0 {
+		t.Fatalf("channel without greeting: %d greetings, want 0", n)
+	}
+	al.Close()
+
+	// A restarted loop on the same workspace remembers who was greeted.
+	msgBus = bus.NewMessageBus()
+	al = NewAgentLoop(cfg, msgBus, &reasoningContentProvider{response: "ok"})
+	defer al.Close()
+	if n := send(al, msgBus, "telegram", "user1", "chat1"); n != 0 {
+		t.Fatalf("after restart: %d greetings, want 0", n)
+	}
+	if n := send(al, msgBus, "telegram", "user2", "chat2"); n != 1 {
+		t.Fatalf("new peer after restart: %d greetings, want 1", n)
+	}
+}
+
 func TestProcessMessage_PicoPublishesReasoningAsThoughtMessage(t *testing.T) {
 	tmpDir := t.TempDir()
 	cfg := &config.Config{
@@ -0,0 +1,31 @@
+package agent
+
+import (
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// ToolStats returns tool execution counts summed across all agents, keyed by
+// tool name. Registered tools that have never run are included with zero
+// counts, so unused tools show up too.
+func (al *AgentLoop) ToolStats() map[string]tools.ToolStats {
+	stats := make(map[string]tools.ToolStats)
+	registry := al.GetRegistry()
+	if registry == nil {
+		return stats
+	}
+	for _, agentID := range registry.ListAgentIDs() {
+		agent, ok := registry.GetAgent(agentID)
+		if !ok || agent == nil || agent.Tools == nil {
+			continue
+		}
+		for _, name := range agent.Tools.List() {
+			if _, ok := stats[name]; !ok {
+				stats[name] = tools.ToolStats{}
+			}
+		}
+		for name, s := range agent.Tools.Stats() {
+			stats[name] = stats[name].Add(s)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,27 @@
+package agent
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAgentLoop_ToolStatsIncludesUnusedTools(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	al.RegisterTool(&allowlistTestTool{name: "used_tool"})
+	al.RegisterTool(&allowlistTestTool{name: "unused_tool"})
+
+	agent := al.GetRegistry().GetDefaultAgent()
+	agent.Tools.Execute(context.Background(), "used_tool", nil)
+	agent.Tools.Execute(context.Background(), "used_tool", nil)
+
+	stats := al.ToolStats()
+	if got := stats["used_tool"]; got.Invocations != 2 || got.Successes != 2 {
+		t.Fatalf("used_tool stats = %+v, want 2 successful invocations", got)
+	}
+	got, ok := stats["unused_tool"]
+	if !ok || got.Invocations != 0 {
+		t.Fatalf("unused_tool stats = %+v (present %v), want listed with zero counts", got, ok)
+	}
+}
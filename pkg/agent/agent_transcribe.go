@@ -11,6 +11,20 @@ import (
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
+const voiceTranscriptionUnavailableMsg = "Voice transcription isn't enabled, so I can't listen to voice messages yet. " +
+	"Set voice.model_name to a transcription-capable model (for example a Whisper model in model_list), " +
+	"or send your message as text."
+
+// isVoiceOnlyContent reports whether content consists solely of voice/audio
+// annotations, i.e. there is nothing for the model to act on without a
+// transcript.
+func isVoiceOnlyContent(content string) bool {
+	if !audioAnnotationRe.MatchString(content) {
+		return false
+	}
+	return strings.TrimSpace(audioAnnotationRe.ReplaceAllString(content, "")) == ""
+}
+
 func (al *AgentLoop) transcribeAudioInMessage(ctx context.Context, msg bus.InboundMessage) (bus.InboundMessage, bool) {
 	if al.transcriber == nil || al.mediaStore == nil || len(msg.Media) == 0 {
 		return msg, false
@@ -11,10 +11,12 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/commands"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/session"
+	"github.com/sipeed/picoclaw/pkg/tools"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
@@ -222,6 +224,38 @@ func shouldPublishToolFeedback(cfg *config.Config, ts *turnState) bool {
 	return cfg != nil && cfg.Agents.Defaults.IsToolFeedbackEnabled()
 }
 
+// toolProgressRelay returns a callback that shows a running tool's latest
+// output below its feedback message. It returns nil when there is no
+// feedback message to update or when the channel would post every update as
+// a new message rather than editing the tracked one.
+func (al *AgentLoop) toolProgressRelay(
+	ctx context.Context,
+	ts *turnState,
+	feedbackMsg string,
+) tools.ProgressCallback {
+	if feedbackMsg == "" || al.channelManager == nil ||
+		al.cfg.Agents.Defaults.IsToolFeedbackSeparateMessagesEnabled() {
+		return nil
+	}
+	ch, ok := al.channelManager.GetChannel(ts.channel)
+	if !ok || !channels.SupportsToolFeedbackUpdates(ch) {
+		return nil
+	}
+	return func(output string) {
+		if ctx.Err() != nil {
+			return
+		}
+		content := feedbackMsg + "\n```\n" + al.cfg.FilterSensitiveData(output) + "\n```"
+		pubCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		defer cancel()
+		_ = al.bus.PublishOutbound(pubCtx, outboundMessageForTurnWithOptions(
+			ts,
+			content,
+			outboundTurnMessageOptions{kind: messageKindToolFeedback},
+		))
+	}
+}
+
 func cloneEventArguments(args map[string]any) map[string]any {
 	if len(args) == 0 {
 		return nil
@@ -1,6 +1,14 @@
 package agent
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
 
 func TestInferMediaType(t *testing.T) {
 	tests := []struct {
@@ -74,3 +82,61 @@ func TestInferMediaType(t *testing.T) {
 		})
 	}
 }
+
+type trackingFeedbackChannel struct{ fakeChannel }
+
+func (c *trackingFeedbackChannel) RecordToolFeedbackMessage(chatID, messageID, content string) {}
+func (c *trackingFeedbackChannel) ClearToolFeedbackMessage(chatID string)                      {}
+
+type singleChannelManager struct {
+	recordingChannelManager
+	ch channels.Channel
+}
+
+func (m *singleChannelManager) GetChannel(name string) (channels.Channel, bool) {
+	return m.ch, name == "telegram"
+}
+
+func TestToolProgressRelay(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	cfg := config.DefaultConfig()
+	al := &AgentLoop{cfg: cfg, bus: msgBus}
+	ts := &turnState{agent: &AgentInstance{ID: "main"}, channel: "telegram", chatID: "42"}
+	ctx := context.Background()
+
+	if al.toolProgressRelay(ctx, ts, "🔧 exec") != nil {
+		t.Fatal("expected no relay without a channel manager")
+	}
+	al.channelManager = &singleChannelManager{ch: &fakeChannel{}}
+	if al.toolProgressRelay(ctx, ts, "🔧 exec") != nil {
+		t.Fatal("expected no relay for a channel that cannot edit tool feedback")
+	}
+
+	al.channelManager = &singleChannelManager{ch: &trackingFeedbackChannel{}}
+	if al.toolProgressRelay(ctx, ts, "") != nil {
+		t.Fatal("expected no relay without a feedback message")
+	}
+	relay := al.toolProgressRelay(ctx, ts, "🔧 exec")
+	if relay == nil {
+		t.Fatal("expected a relay for a channel that edits tool feedback")
+	}
+	relay("building\ndone")
+
+	select {
+	case msg := <-msgBus.OutboundChan():
+		if msg.ChatID != "42" || msg.Context.Raw[metadataKeyMessageKind] != messageKindToolFeedback {
+			t.Fatalf("outbound = %+v, want tool feedback for chat 42", msg)
+		}
+		if want := "🔧 exec\n```\nbuilding\ndone\n```"; msg.Content != want {
+			t.Fatalf("content = %q, want %q", msg.Content, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no progress message published")
+	}
+
+	cfg.Agents.Defaults.ToolFeedback.SeparateMessages = true
+	if al.toolProgressRelay(ctx, ts, "🔧 exec") != nil {
+		t.Fatal("expected no relay when tool feedback uses separate messages")
+	}
+}
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AsyncToolTask is a tool call that keeps running in the background after
+// the turn that started it has moved on. Its result is routed back to the
+// same agent and session when it completes.
+type AsyncToolTask struct {
+	ID         string
+	Tool       string
+	ToolCallID string
+	AgentID    string
+	SessionKey string
+	Channel    string
+	ChatID     string
+	StartedAt  time.Time
+}
+
+// asyncToolRegistry tracks in-flight async tool calls. The task ID travels
+// with the completion message so the result can be matched to its session.
+// The zero value is ready to use.
+type asyncToolRegistry struct {
+	mu    sync.Mutex
+	next  uint64
+	tasks map[string]AsyncToolTask
+}
+
+func (r *asyncToolRegistry) start(task AsyncToolTask) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tasks == nil {
+		r.tasks = make(map[string]AsyncToolTask)
+	}
+	r.next++
+	task.ID = fmt.Sprintf("async-%d", r.next)
+	if task.StartedAt.IsZero() {
+		task.StartedAt = time.Now()
+	}
+	r.tasks[task.ID] = task
+	return task.ID
+}
+
+// finish removes the task and returns it, or false when id is unknown.
+func (r *asyncToolRegistry) finish(id string) (AsyncToolTask, bool) {
+	if id == "" {
+		return AsyncToolTask{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, ok := r.tasks[id]
+	delete(r.tasks, id)
+	return task, ok
+}
+
+func (r *asyncToolRegistry) list(sessionKey string) []AsyncToolTask {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tasks []AsyncToolTask
+	for _, task := range r.tasks {
+		if sessionKey == "" || task.SessionKey == sessionKey {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].StartedAt.Before(tasks[j].StartedAt) })
+	return tasks
+}
+
+// AsyncToolTasks returns the async tool calls still running for sessionKey,
+// oldest first. An empty key returns the tasks of every session.
+func (al *AgentLoop) AsyncToolTasks(sessionKey string) []AsyncToolTask {
+	return al.asyncTools.list(sessionKey)
+}
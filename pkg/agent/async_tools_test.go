@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// gatedAsyncTool returns at once and reports its result when release is
+// closed, like a long build running in the background.
+type gatedAsyncTool struct {
+	release chan struct{}
+}
+
+func (t *gatedAsyncTool) Name() string        { return "slow_build" }
+func (t *gatedAsyncTool) Description() string { return "slow background build" }
+func (t *gatedAsyncTool) Parameters() map[string]any {
+	return map[string]any{"type": "object", "properties": map[string]any{}}
+}
+
+func (t *gatedAsyncTool) Execute(ctx context.Context, args map[string]any) *tools.ToolResult {
+	return tools.AsyncResult("build started")
+}
+
+func (t *gatedAsyncTool) ExecuteAsync(
+	ctx context.Context,
+	args map[string]any,
+	cb tools.AsyncCallback,
+) *tools.ToolResult {
+	go func() {
+		<-t.release
+		cb(context.Background(), &tools.ToolResult{ForLLM: "build finished: 0 errors"})
+	}()
+	return tools.AsyncResult("build started")
+}
+
+func runAsyncToolTurn(t *testing.T, followUp bool) (*AgentLoop, *AgentInstance, bus.InboundMessage) {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				AsyncToolFollowUp: &followUp,
+			},
+		},
+	}
+	provider := &toolCallProvider{
+		toolCalls: []providers.ToolCall{{
+			ID:        "call_build",
+			Type:      "function",
+			Name:      "slow_build",
+			Function:  &providers.FunctionCall{Name: "slow_build", Arguments: "{}"},
+			Arguments: map[string]any{},
+		}},
+		finalResp: "build is running",
+	}
+	msgBus := bus.NewMessageBus()
+	t.Cleanup(msgBus.Close)
+	al := NewAgentLoop(cfg, msgBus, provider)
+	tool := &gatedAsyncTool{release: make(chan struct{})}
+	al.RegisterTool(tool)
+	agent := al.registry.GetDefaultAgent()
+
+	resp, err := al.runAgentLoop(context.Background(), agent, processOptions{
+		SessionKey:      "session-build",
+		Channel:         "telegram",
+		ChatID:          "42",
+		UserMessage:     "build the project",
+		DefaultResponse: defaultResponse,
+	})
+	if err != nil || resp != "build is running" {
+		t.Fatalf("runAgentLoop() = %q, %v; want the turn to finish while the build runs", resp, err)
+	}
+
+	inFlight := al.AsyncToolTasks("session-build")
+	if len(inFlight) != 1 || inFlight[0].Tool != "slow_build" || inFlight[0].ToolCallID != "call_build" {
+		t.Fatalf("AsyncToolTasks() = %+v, want the running build", inFlight)
+	}
+
+	close(tool.release)
+	select {
+	case msg := <-msgBus.InboundChan():
+		if msg.Context.MessageID != inFlight[0].ID {
+			t.Fatalf("completion MessageID = %q, want task %q", msg.Context.MessageID, inFlight[0].ID)
+		}
+		return al, agent, msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("async result was not published")
+	}
+	return nil, nil, bus.InboundMessage{}
+}
+
+func sessionHasMessage(agent *AgentInstance, sessionKey, text string) bool {
+	for _, m := range agent.Sessions.GetHistory(sessionKey) {
+		if strings.Contains(m.Content, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAsyncTool_ResultRunsFollowUpTurnInOriginSession(t *testing.T) {
+	al, agent, msg := runAsyncToolTurn(t, true)
+
+	if _, err := al.processSystemMessage(context.Background(), msg); err != nil {
+		t.Fatalf("processSystemMessage() error = %v", err)
+	}
+	if !sessionHasMessage(agent, "session-build", "build finished: 0 errors") {
+		t.Fatal("follow-up turn did not run in the session that started the tool")
+	}
+	if tasks := al.AsyncToolTasks(""); len(tasks) != 0 {
+		t.Fatalf("AsyncToolTasks() = %+v, want none after completion", tasks)
+	}
+}
+
+func TestAsyncTool_ResultOnlyInjectedWhenFollowUpDisabled(t *testing.T) {
+	al, agent, msg := runAsyncToolTurn(t, false)
+	before := len(agent.Sessions.GetHistory("session-build"))
+
+	resp, err := al.processSystemMessage(context.Background(), msg)
+	if err != nil || resp != "" {
+		t.Fatalf("processSystemMessage() = %q, %v; want no turn", resp, err)
+	}
+	history := agent.Sessions.GetHistory("session-build")
+	if len(history) != before+1 || !strings.Contains(history[len(history)-1].Content, "build finished") {
+		t.Fatalf("history = %+v, want only the injected result appended", history)
+	}
+}
@@ -0,0 +1,266 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	// attachmentsDir is the workspace directory attachments are staged in,
+	// one subdirectory per session.
+	attachmentsDir = "attachments"
+	// stagedAttachmentsRawKey lists the staged paths, one per line, in the
+	// raw inbound context of the turn.
+	stagedAttachmentsRawKey = "staged_attachments"
+	// maxListedAttachments bounds the files named in the prompt.
+	maxListedAttachments = 20
+)
+
+// stageAttachments copies the files attached to an inbound message into the
+// agent workspace when agents.defaults.attachments is enabled. The user
+// message gets a note with the workspace paths, or why a file was not
+// staged, and the paths are recorded in the raw inbound context.
+func (al *AgentLoop) stageAttachments(agent *AgentInstance, dispatch DispatchRequest) DispatchRequest {
+	cfg := al.GetConfig()
+	if cfg == nil || !cfg.Agents.Defaults.Attachments.Enabled || al.mediaStore == nil ||
+		len(dispatch.Media) == 0 || agent == nil || agent.Workspace == "" || dispatch.SessionKey == "" {
+		return dispatch
+	}
+	staged, skipped := stageAttachmentFiles(
+		al.mediaStore,
+		dispatch.Media,
+		agent.Workspace,
+		dispatch.SessionKey,
+		cfg.Agents.Defaults.Attachments,
+		cfg.Agents.Defaults.GetAttachmentMaxSize(),
+	)
+	if len(staged) == 0 && len(skipped) == 0 {
+		return dispatch
+	}
+
+	var notes []string
+	if len(staged) > 0 {
+		notes = append(notes, "[attachments saved in the workspace: "+strings.Join(staged, ", ")+"]")
+		if dispatch.InboundContext != nil {
+			raw := cloneStringMap(dispatch.InboundContext.Raw)
+			if raw == nil {
+				raw = make(map[string]string, 1)
+			}
+			raw[stagedAttachmentsRawKey] = strings.Join(staged, "\n")
+			dispatch.InboundContext.Raw = raw
+		}
+	}
+	for _, reason := range skipped {
+		notes = append(notes, "[attachment not saved: "+reason+"]")
+	}
+	note := strings.Join(notes, "\n")
+	switch {
+	case strings.TrimSpace(dispatch.UserMessage) == "":
+		dispatch.UserMessage = note
+	case looksLikeJSON(dispatch.UserMessage):
+		dispatch.UserMessage = note + "\n" + dispatch.UserMessage
+	default:
+		dispatch.UserMessage += "\n" + note
+	}
+	return dispatch
+}
+
+// stageAttachmentFiles copies the media refs into the session's staging
+// directory under workspace. It returns the workspace-relative paths of the
+// staged files and, for files it left out, a short reason each.
+func stageAttachmentFiles(
+	store media.MediaStore,
+	refs []string,
+	workspace, sessionKey string,
+	cfg config.AttachmentsConfig,
+	maxSize int,
+) (staged, skipped []string) {
+	dir := attachmentStagingDir(workspace, sessionKey)
+	for _, ref := range refs {
+		if !strings.HasPrefix(ref, "media://") {
+			continue
+		}
+		localPath, meta, err := store.ResolveWithMeta(ref)
+		if err != nil {
+			continue
+		}
+		name := utils.SanitizeFilename(meta.Filename)
+		if name == "" || name == "." {
+			name = utils.SanitizeFilename(filepath.Base(localPath))
+		}
+		info, err := os.Stat(localPath)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if info.Size() > int64(maxSize) {
+			skipped = append(skipped, fmt.Sprintf("%s is larger than the %d byte limit", name, maxSize))
+			continue
+		}
+		if !cfg.Allows(name, detectMIME(localPath, meta)) {
+			skipped = append(skipped, name+" is not an allowed file type")
+			continue
+		}
+		dst, err := copyAttachment(localPath, dir, name)
+		if err != nil {
+			logger.WarnCF("agent", "Failed to stage attachment", map[string]any{
+				"file":  name,
+				"error": err.Error(),
+			})
+			skipped = append(skipped, name+" could not be saved")
+			continue
+		}
+		rel, err := filepath.Rel(workspace, dst)
+		if err != nil {
+			rel = dst
+		}
+		staged = append(staged, filepath.ToSlash(rel))
+	}
+	return staged, skipped
+}
+
+// copyAttachment copies src into dir as name, adding -2, -3, ... before the
+// extension when a file of that name is already staged.
+func copyAttachment(src, dir, name string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; i <= 100; i++ {
+		candidate := name
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+		dst := filepath.Join(dir, candidate)
+		out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err = io.Copy(out, in); err == nil {
+			err = out.Close()
+		} else {
+			out.Close()
+		}
+		if err != nil {
+			os.Remove(dst)
+			return "", err
+		}
+		return dst, nil
+	}
+	return "", fmt.Errorf("too many attachments named %s", name)
+}
+
+// attachmentStagingDir is the directory the attachments of sessionKey are
+// staged in. Session keys are reduced to safe characters, with a short hash
+// so distinct keys never share a directory.
+func attachmentStagingDir(workspace, sessionKey string) string {
+	safe := []rune(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, sessionKey))
+	if len(safe) > 64 {
+		safe = safe[:64]
+	}
+	sum := sha256.Sum256([]byte(sessionKey))
+	return filepath.Join(workspace, attachmentsDir, fmt.Sprintf("%s-%x", string(safe), sum[:4]))
+}
+
+// removeStagedAttachments deletes the staged attachments of sessionKey.
+func removeStagedAttachments(workspace, sessionKey string) error {
+	if workspace == "" || sessionKey == "" {
+		return nil
+	}
+	return os.RemoveAll(attachmentStagingDir(workspace, sessionKey))
+}
+
+// attachmentsPromptContributor tells the agent where the files the user sent
+// in the current session are staged, so later turns can still find them.
+type attachmentsPromptContributor struct {
+	workspace string
+}
+
+func (c attachmentsPromptContributor) PromptSource() PromptSourceDescriptor {
+	return PromptSourceDescriptor{
+		ID:              PromptSourceAttachments,
+		Owner:           "agent",
+		Description:     "Attachments staged in the workspace for the current session",
+		Allowed:         []PromptPlacement{{Layer: PromptLayerContext, Slot: PromptSlotRuntime}},
+		StableByDefault: false,
+	}
+}
+
+func (c attachmentsPromptContributor) ContributePrompt(
+	_ context.Context,
+	req PromptBuildRequest,
+) ([]PromptPart, error) {
+	if c.workspace == "" || strings.TrimSpace(req.SessionKey) == "" {
+		return nil, nil
+	}
+	dir := attachmentStagingDir(c.workspace, req.SessionKey)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	slices.Sort(names)
+
+	rel := path.Join(attachmentsDir, filepath.Base(dir))
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Attachments\nFiles the user sent in this conversation are saved in the workspace "+
+		"under %s/. Open them with the file and exec tools by these paths:", rel)
+	for i, name := range names {
+		if i == maxListedAttachments {
+			fmt.Fprintf(&sb, "\n- ... and %d more", len(names)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "\n- %s/%s", rel, name)
+	}
+
+	return []PromptPart{
+		{
+			ID:      "context.attachments",
+			Layer:   PromptLayerContext,
+			Slot:    PromptSlotRuntime,
+			Source:  PromptSource{ID: PromptSourceAttachments, Name: "session:attachments"},
+			Title:   "attachments",
+			Content: sb.String(),
+			Stable:  false,
+			Cache:   PromptCacheNone,
+		},
+	}, nil
+}
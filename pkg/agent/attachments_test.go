@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/media"
+)
+
+func storeTestAttachment(t *testing.T, store media.MediaStore, name, content string) string {
+	t.Helper()
+	localPath := filepath.Join(t.TempDir(), "download.bin")
+	if err := os.WriteFile(localPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := store.Store(localPath, media.MediaMeta{Filename: name, Source: "telegram"}, "telegram:chat1:1")
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	return ref
+}
+
+func TestStageAttachments_CopiesAllowedFilesIntoWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	store := media.NewFileMediaStore()
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Attachments = config.AttachmentsConfig{
+		Enabled:      true,
+		MaxSize:      64,
+		AllowedTypes: []string{".csv", ".txt"},
+	}
+	al := &AgentLoop{cfg: cfg, mediaStore: store}
+	agent := &AgentInstance{Workspace: workspace}
+
+	refs := []string{
+		storeTestAttachment(t, store, "sales.csv", "region,total\nnorth,10\n"),
+		storeTestAttachment(t, store, "sales.csv", "region,total\nsouth,20\n"),
+		storeTestAttachment(t, store, "setup.exe", "MZ"),
+		storeTestAttachment(t, store, "big.txt", strings.Repeat("a", 100)),
+	}
+	dispatch := al.stageAttachments(agent, DispatchRequest{
+		SessionKey:     "agent:main:telegram:direct:user1",
+		UserMessage:    "analyze this spreadsheet",
+		Media:          refs,
+		InboundContext: &bus.InboundContext{Channel: "telegram", ChatID: "chat1"},
+	})
+
+	dir := attachmentStagingDir(workspace, "agent:main:telegram:direct:user1")
+	rel := "attachments/" + filepath.Base(dir)
+	for _, name := range []string{"sales.csv", "sales-2.csv"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("%s was not staged: %v", name, err)
+		}
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "sales-2.csv")); !strings.Contains(string(got), "south") {
+		t.Fatalf("sales-2.csv = %q, want the second upload", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "setup.exe")); !os.IsNotExist(err) {
+		t.Fatalf("setup.exe should not be staged, stat error = %v", err)
+	}
+
+	for _, want := range []string{
+		"analyze this spreadsheet\n",
+		"[attachments saved in the workspace: " + rel + "/sales.csv, " + rel + "/sales-2.csv]",
+		"[attachment not saved: setup.exe is not an allowed file type]",
+		"[attachment not saved: big.txt is larger than the 64 byte limit]",
+	} {
+		if !strings.Contains(dispatch.UserMessage, want) {
+			t.Errorf("user message missing %q:\n%s", want, dispatch.UserMessage)
+		}
+	}
+	if got := dispatch.InboundContext.Raw[stagedAttachmentsRawKey]; got != rel+"/sales.csv\n"+rel+"/sales-2.csv" {
+		t.Errorf("raw %s = %q", stagedAttachmentsRawKey, got)
+	}
+	if len(dispatch.Media) != len(refs) {
+		t.Errorf("media refs should be kept for the model, got %v", dispatch.Media)
+	}
+
+	if err := removeStagedAttachments(workspace, "agent:main:telegram:direct:user1"); err != nil {
+		t.Fatalf("removeStagedAttachments() error = %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("staging dir should be removed, stat error = %v", err)
+	}
+}
+
+func TestStageAttachments_DisabledLeavesMessageUnchanged(t *testing.T) {
+	workspace := t.TempDir()
+	store := media.NewFileMediaStore()
+	al := &AgentLoop{cfg: config.DefaultConfig(), mediaStore: store}
+	ref := storeTestAttachment(t, store, "sales.csv", "a,b\n")
+
+	dispatch := al.stageAttachments(&AgentInstance{Workspace: workspace}, DispatchRequest{
+		SessionKey:  "session-1",
+		UserMessage: "hi",
+		Media:       []string{ref},
+	})
+	if dispatch.UserMessage != "hi" {
+		t.Fatalf("UserMessage = %q, want unchanged", dispatch.UserMessage)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, attachmentsDir)); !os.IsNotExist(err) {
+		t.Fatalf("nothing should be staged when disabled, stat error = %v", err)
+	}
+}
+
+func TestAttachmentStagingDir_IsSafeAndDistinct(t *testing.T) {
+	workspace := t.TempDir()
+	a := attachmentStagingDir(workspace, "agent:main:../../etc")
+	b := attachmentStagingDir(workspace, "agent_main_.._.._etc")
+	if a == b {
+		t.Fatalf("distinct session keys share %s", a)
+	}
+	if filepath.Dir(a) != filepath.Join(workspace, attachmentsDir) {
+		t.Fatalf("staging dir %s escapes %s", a, filepath.Join(workspace, attachmentsDir))
+	}
+}
+
+func TestContextBuilder_ListsStagedAttachments(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+	workspace := t.TempDir()
+	dir := attachmentStagingDir(workspace, "session-1")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sales.csv"), []byte("a,b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cb := NewContextBuilder(workspace)
+	if err := cb.RegisterPromptContributor(attachmentsPromptContributor{workspace: workspace}); err != nil {
+		t.Fatalf("RegisterPromptContributor() error = %v", err)
+	}
+
+	system := cb.BuildMessagesFromPrompt(PromptBuildRequest{SessionKey: "session-1", CurrentMessage: "hi"})[0]
+	want := "- attachments/" + filepath.Base(dir) + "/sales.csv"
+	if !strings.Contains(system.Content, want) {
+		t.Fatalf("system prompt missing %q: %q", want, system.Content)
+	}
+
+	system = cb.BuildMessagesFromPrompt(PromptBuildRequest{SessionKey: "session-2", CurrentMessage: "hi"})[0]
+	if strings.Contains(system.Content, "## Attachments") {
+		t.Fatalf("attachments leaked into another session: %q", system.Content)
+	}
+}
@@ -0,0 +1,204 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	// compactionKeepTrigger starts the reply that protects previewed
+	// messages from summarization, e.g. ":keep 2 5".
+	compactionKeepTrigger = ":keep"
+	// compactionPreviewSnippet is how much of each message the preview shows.
+	compactionPreviewSnippet = 80
+)
+
+// compactionPreview is a summarization announced to the user but not yet run.
+// candidates are the messages listed in the notice, numbered from 1; kept
+// holds the numbers the user protected.
+type compactionPreview struct {
+	mu         sync.Mutex
+	candidates []providers.Message
+	kept       []int
+}
+
+// compactAfterTurn runs the post-turn summarization check. With
+// compaction_preview on, an interactive session is first shown what would be
+// summarized and the returned notice must be delivered; the summarization then
+// runs after the session's next turn. Emergency compression never waits.
+func (al *AgentLoop) compactAfterTurn(ctx context.Context, ts *turnState) (notice string) {
+	if ts.opts.NoHistory || !ts.opts.EnableSummary {
+		return ""
+	}
+	if lcm, ok := al.contextManager.(*legacyContextManager); ok && al.compactionPreviewApplies(ts) {
+		if notice = lcm.previewSummary(ts.sessionKey); notice != "" {
+			return notice
+		}
+	}
+	al.contextManager.Compact(ctx, &CompactRequest{
+		SessionKey: ts.sessionKey,
+		Reason:     ContextCompressReasonSummarize,
+		Budget:     ts.agent.ContextWindow,
+	})
+	return ""
+}
+
+// compactionPreviewApplies reports whether the turn came from someone who can
+// answer a preview: cron, heartbeat and internal channels cannot.
+func (al *AgentLoop) compactionPreviewApplies(ts *turnState) bool {
+	cfg := al.GetConfig()
+	if cfg == nil || !cfg.Agents.Defaults.CompactionPreview {
+		return false
+	}
+	if ts.channel == "" || constants.IsInternalChannel(ts.channel) {
+		return false
+	}
+	switch ts.opts.SenderID {
+	case "", "cron", "heartbeat":
+		return false
+	}
+	return true
+}
+
+// previewSummary records a due summarization as pending and returns the notice
+// describing it. It returns "" when the summarization should go ahead now:
+// nothing is due, or the user has already seen the preview.
+func (m *legacyContextManager) previewSummary(sessionKey string) string {
+	agent := m.al.registry.GetDefaultAgent()
+	if agent == nil {
+		return ""
+	}
+	key := agent.ID + ":" + sessionKey
+	history := agent.Sessions.GetHistory(sessionKey)
+	keep, due := summaryPlan(agent, history)
+	if !due {
+		m.previews.Delete(key)
+		return ""
+	}
+	if _, shown := m.previews.Load(key); shown {
+		return ""
+	}
+
+	var candidates []providers.Message
+	for _, msg := range history[:summaryCut(agent, history, keep)] {
+		if (msg.Role == "user" || msg.Role == "assistant") && strings.TrimSpace(msg.Content) != "" {
+			candidates = append(candidates, providers.Message{Role: msg.Role, Content: msg.Content})
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	m.previews.Store(key, &compactionPreview{candidates: candidates})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "I'm about to summarize messages 1–%d to free context. "+
+		"Reply %s <n> to protect specific ones (e.g. %s 2 5); "+
+		"the summary is written after your next message.\n",
+		len(candidates), compactionKeepTrigger, compactionKeepTrigger)
+	for i, msg := range candidates {
+		snippet := strings.Join(strings.Fields(msg.Content), " ")
+		fmt.Fprintf(&sb, "\n%d. %s: %s", i+1, msg.Role, utils.Truncate(snippet, compactionPreviewSnippet))
+	}
+	return sb.String()
+}
+
+// keepMessages handles a ":keep <n>..." reply to a preview.
+func (m *legacyContextManager) keepMessages(sessionKey, args string) string {
+	agent := m.al.registry.GetDefaultAgent()
+	if agent == nil {
+		return "No summarization is pending."
+	}
+	value, ok := m.previews.Load(agent.ID + ":" + sessionKey)
+	if !ok {
+		return "No summarization is pending."
+	}
+	preview := value.(*compactionPreview)
+	preview.mu.Lock()
+	defer preview.mu.Unlock()
+
+	fields := strings.FieldsFunc(args, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	if len(fields) == 0 {
+		return fmt.Sprintf("Usage: %s <n> [n...], with n between 1 and %d.",
+			compactionKeepTrigger, len(preview.candidates))
+	}
+	var added []int
+	for _, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > len(preview.candidates) {
+			return fmt.Sprintf("%q is not a message number; use 1 to %d.", field, len(preview.candidates))
+		}
+		added = append(added, n)
+	}
+	preview.kept = append(preview.kept, added...)
+	slices.Sort(preview.kept)
+	preview.kept = slices.Compact(preview.kept)
+
+	numbers := make([]string, len(preview.kept))
+	for i, n := range preview.kept {
+		numbers[i] = strconv.Itoa(n)
+	}
+	noun := "message"
+	if len(numbers) > 1 {
+		noun = "messages"
+	}
+	return fmt.Sprintf("Keeping %s %s word for word when the conversation is summarized.",
+		noun, strings.Join(numbers, ", "))
+}
+
+// protectedMessages returns the messages the user kept from a preview that
+// are still among toSummarize, oldest first.
+func (m *legacyContextManager) protectedMessages(
+	agent *AgentInstance,
+	sessionKey string,
+	toSummarize []providers.Message,
+) []providers.Message {
+	value, ok := m.previews.Load(agent.ID + ":" + sessionKey)
+	if !ok {
+		return nil
+	}
+	preview := value.(*compactionPreview)
+	preview.mu.Lock()
+	defer preview.mu.Unlock()
+
+	var protected []providers.Message
+	for _, n := range preview.kept {
+		candidate := preview.candidates[n-1]
+		if slices.ContainsFunc(toSummarize, func(msg providers.Message) bool {
+			return msg.Role == candidate.Role && msg.Content == candidate.Content
+		}) {
+			protected = append(protected, candidate)
+		}
+	}
+	return protected
+}
+
+func isProtectedMessage(protected []providers.Message, msg providers.Message) bool {
+	return slices.ContainsFunc(protected, func(p providers.Message) bool {
+		return p.Role == msg.Role && p.Content == msg.Content
+	})
+}
+
+// handleCompactionKeep answers ":keep" replies without starting a turn. It
+// only applies while the legacy context manager is in use.
+func (al *AgentLoop) handleCompactionKeep(sessionKey, content string) (string, bool) {
+	lcm, ok := al.contextManager.(*legacyContextManager)
+	if !ok {
+		return "", false
+	}
+	content = strings.TrimSpace(content)
+	trigger, args, _ := strings.Cut(content, " ")
+	if !strings.EqualFold(trigger, compactionKeepTrigger) {
+		return "", false
+	}
+	return lcm.keepMessages(sessionKey, args), true
+}
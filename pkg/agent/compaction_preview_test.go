@@ -0,0 +1,154 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestCompactionPreview_KeptMessagesSurviveSummary(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:                 t.TempDir(),
+				ModelName:                 "test-model",
+				MaxTokens:                 4096,
+				ContextWindow:             8000,
+				SummarizeMessageThreshold: 4,
+				SummarizeTokenPercent:     75,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &simpleMockProvider{response: "summary text"})
+	defaultAgent := al.registry.GetDefaultAgent()
+	defaultAgent.Sessions.SetHistory("session-1", []providers.Message{
+		{Role: "user", Content: "My deploy key lives in vault path secret/deploy"},
+		{Role: "assistant", Content: "Noted."},
+		{Role: "user", Content: "Question two"},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "tc1", Name: "read_file"}}},
+		{Role: "tool", Content: "file", ToolCallID: "tc1"},
+		{Role: "assistant", Content: "Answer two"},
+		{Role: "user", Content: "Question three"},
+		{Role: "assistant", Content: "Answer three"},
+	})
+	lcm := &legacyContextManager{al: al}
+
+	if got := lcm.keepMessages("session-1", "1"); got != "No summarization is pending." {
+		t.Fatalf("keep without preview = %q", got)
+	}
+
+	notice := lcm.previewSummary("session-1")
+	for _, want := range []string{"messages 1–2", "1. user: My deploy key", "2. assistant: Noted.", ":keep <n>"} {
+		if !strings.Contains(notice, want) {
+			t.Fatalf("notice = %q, want it to contain %q", notice, want)
+		}
+	}
+	if again := lcm.previewSummary("session-1"); again != "" {
+		t.Fatalf("second preview = %q, want the summarization to go ahead", again)
+	}
+
+	if got := lcm.keepMessages("session-1", "3"); !strings.Contains(got, "use 1 to 2") {
+		t.Fatalf("keep 3 = %q, want a range error", got)
+	}
+	want := "Keeping message 1 word for word when the conversation is summarized."
+	if got := lcm.keepMessages("session-1", "1"); got != want {
+		t.Fatalf("keep 1 = %q, want %q", got, want)
+	}
+
+	if !lcm.summarizeSession(defaultAgent, "session-1", summaryKeepMessages) {
+		t.Fatal("expected the history to be summarized")
+	}
+	kept := defaultAgent.Sessions.GetHistory("session-1")
+	if len(kept) != 7 || kept[0].Content != "My deploy key lives in vault path secret/deploy" ||
+		kept[1].Content != "Question two" {
+		t.Fatalf("kept history = %+v, want the protected message ahead of the kept turns", kept)
+	}
+	if _, pending := lcm.previews.Load(defaultAgent.ID + ":session-1"); pending {
+		t.Error("preview should be cleared once the summary is written")
+	}
+}
+
+func TestProcessMessage_PreviewsCompactionBeforeSummarizing(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:                 t.TempDir(),
+				ModelName:                 "test-model",
+				MaxTokens:                 4096,
+				MaxToolIterations:         10,
+				ContextWindow:             8000,
+				SummarizeMessageThreshold: 2,
+				SummarizeTokenPercent:     75,
+				CompactionPreview:         true,
+			},
+		},
+	}
+	msgBus := bus.NewMessageBus()
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, msgBus, provider)
+	process := func(content string) string {
+		t.Helper()
+		response, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+			Channel:  "telegram",
+			ChatID:   "1",
+			SenderID: "alice",
+			Content:  content,
+		}))
+		if err != nil {
+			t.Fatalf("processMessage(%q) error = %v", content, err)
+		}
+		return response
+	}
+
+	process("remember the launch date is May 3")
+	process("what is on the agenda")
+	process("who is presenting")
+
+	var notice string
+	for notice == "" {
+		select {
+		case outbound := <-msgBus.OutboundChan():
+			if strings.Contains(outbound.Content, ":keep") {
+				notice = outbound.Content
+			}
+		case <-time.After(responseTimeout):
+			t.Fatal("expected a compaction preview after the history passed the threshold")
+		}
+	}
+	if !strings.Contains(notice, "1. user: remember the launch date is May 3") {
+		t.Fatalf("notice = %q, want the first message listed", notice)
+	}
+
+	sessionKey := ""
+	defaultAgent := al.registry.GetDefaultAgent()
+	for _, key := range defaultAgent.Sessions.ListSessions() {
+		sessionKey = key
+	}
+	if len(defaultAgent.Sessions.GetHistory(sessionKey)) != 6 || defaultAgent.Sessions.GetSummary(sessionKey) != "" {
+		t.Fatal("history must not be summarized before the user has answered the preview")
+	}
+
+	provider.lastMessages = nil
+	response := process(":keep 1")
+	if !strings.HasPrefix(response, "Keeping message 1") || provider.lastMessages != nil {
+		t.Fatalf(":keep response = %q, provider called = %v", response, provider.lastMessages != nil)
+	}
+
+	process("thanks")
+	deadline := time.Now().Add(responseTimeout)
+	for defaultAgent.Sessions.GetSummary(sessionKey) == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the summary to be written after the next turn")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	history := defaultAgent.Sessions.GetHistory(sessionKey)
+	if len(history) == 0 || history[0].Content != "remember the launch date is May 3" {
+		t.Fatalf("history = %+v, want the kept message first", history)
+	}
+}
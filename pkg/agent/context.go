@@ -29,6 +29,17 @@ type ContextBuilder struct {
 	agentDiscovery func(agentID string) []AgentDescriptor
 	promptRegistry *PromptRegistry
 
+	contextProviders *contextProvidersPromptContributor
+
+	// name and persona replace the default "picoclaw" identity; see
+	// WithIdentity.
+	name    string
+	persona string
+
+	// location is the configured zone for the current time; nil uses the
+	// host zone.
+	location *time.Location
+
 	// Cache for system prompt to avoid rebuilding on every call.
 	// This fixes issue #607: repeated reprocessing of the entire context.
 	// The cache auto-invalidates when workspace source files change (mtime check).
@@ -71,6 +82,20 @@ func (cb *ContextBuilder) WithSplitOnMarker(enabled bool) *ContextBuilder {
 	return cb
 }
 
+// WithMemoryKeys lists the keys saved with the memory tool in each turn's
+// prompt when enabled.
+func (cb *ContextBuilder) WithMemoryKeys(enabled bool) *ContextBuilder {
+	if !enabled {
+		return cb
+	}
+	if err := cb.RegisterPromptContributor(memoryKeysPromptContributor{workspace: cb.workspace}); err != nil {
+		logger.WarnCF("agent", "Failed to register memory keys prompt contributor", map[string]any{
+			"error": err.Error(),
+		})
+	}
+	return cb
+}
+
 func (cb *ContextBuilder) WithAgentDiscovery(
 	agentID string,
 	discover func(agentID string) []AgentDescriptor,
@@ -93,6 +118,57 @@ func (cb *ContextBuilder) WithAgentDiscovery(
 	return cb
 }
 
+// WithChannelPersonas registers the persona prompts configured on channels.
+// Prompts longer than config.MaxPersonaPromptChars are truncated.
+func (cb *ContextBuilder) WithChannelPersonas(channels config.ChannelsConfig) *ContextBuilder {
+	personas := make(map[string]string)
+	for name, ch := range channels {
+		if ch == nil {
+			continue
+		}
+		persona := strings.TrimSpace(ch.PersonaPrompt)
+		if persona == "" {
+			continue
+		}
+		if runes := []rune(persona); len(runes) > config.MaxPersonaPromptChars {
+			logger.WarnCF("agent", "Truncating channel persona prompt", map[string]any{
+				"channel": name,
+				"chars":   len(runes),
+				"max":     config.MaxPersonaPromptChars,
+			})
+			persona = string(runes[:config.MaxPersonaPromptChars])
+		}
+		personas[name] = persona
+	}
+	if len(personas) == 0 {
+		return cb
+	}
+	if err := cb.RegisterPromptContributor(channelPersonaPromptContributor{personas: personas}); err != nil {
+		logger.WarnCF("agent", "Failed to register channel persona prompt contributor", map[string]any{
+			"error": err.Error(),
+		})
+	}
+	return cb
+}
+
+// WithIdentity sets the assistant name and persona used in the identity
+// section of the system prompt. Empty values keep the default picoclaw
+// identity. Personas longer than config.MaxPersonaPromptChars are truncated.
+func (cb *ContextBuilder) WithIdentity(name, persona string) *ContextBuilder {
+	cb.name = strings.TrimSpace(name)
+	persona = strings.TrimSpace(persona)
+	if runes := []rune(persona); len(runes) > config.MaxPersonaPromptChars {
+		logger.WarnCF("agent", "Truncating agent persona", map[string]any{
+			"chars": len(runes),
+			"max":   config.MaxPersonaPromptChars,
+		})
+		persona = string(runes[:config.MaxPersonaPromptChars])
+	}
+	cb.persona = persona
+	cb.InvalidateCache()
+	return cb
+}
+
 func getGlobalConfigDir() string {
 	return config.GetHome()
 }
@@ -173,10 +249,18 @@ func (cb *ContextBuilder) getIdentity(includeToolUseRule bool) string {
 		rules[i] = fmt.Sprintf("%d. %s", i+1, rule)
 	}
 
+	title, intro := "picoclaw 🦞", "You are picoclaw, a helpful AI assistant."
+	if cb.name != "" {
+		title, intro = cb.name, fmt.Sprintf("You are %s, a helpful AI assistant.", cb.name)
+	}
+	if cb.persona != "" {
+		intro += "\n\n" + cb.persona
+	}
+
 	return fmt.Sprintf(
-		`# picoclaw 🦞 (%s)
+		`# %s (%s)
 
-You are picoclaw, a helpful AI assistant.
+%s
 
 ## Workspace
 Your workspace is at: %s
@@ -188,7 +272,9 @@ Your workspace is at: %s
 
 %s
 `,
+		title,
 		version,
+		intro,
 		workspacePath,
 		workspacePath,
 		workspacePath,
@@ -803,6 +889,9 @@ func (cb *ContextBuilder) buildDynamicContext(
 	channel, chatID, senderID, senderDisplayName string,
 ) string {
 	now := time.Now().Format("2006-01-02 15:04 (Monday)")
+	if cb.location != nil {
+		now = time.Now().In(cb.location).Format("2006-01-02 15:04 (Monday) ") + cb.location.String()
+	}
 	rt := fmt.Sprintf("%s %s, Go %s", runtime.GOOS, runtime.GOARCH, runtime.Version())
 
 	var sb strings.Builder
@@ -853,6 +942,8 @@ func (cb *ContextBuilder) BuildMessagesFromPrompt(req PromptBuildRequest) []prov
 	//   contiguous system block makes this extraction straightforward.
 	// - Codex maps only the first system message to its instructions field.
 	// - OpenAI-compat passes messages through as-is.
+	// Providers that accept a developer role get the per-turn blocks split
+	// back out at call time; see messagesForProvider.
 	staticPrompt, contentBlocks := cb.buildSystemPromptForRequest(req)
 
 	// Compose a single system message: static (cached) + dynamic + optional summary.
@@ -965,7 +1056,7 @@ func (cb *ContextBuilder) BuildMessagesFromPrompt(req PromptBuildRequest) []prov
 		contentBlocks = append(contentBlocks, promptContentBlock(fallbackPart, nil))
 	}
 
-	fullSystemPrompt := strings.Join(stringParts, "\n\n---\n\n")
+	fullSystemPrompt := strings.Join(stringParts, systemPartSeparator)
 
 	// Log system prompt summary for debugging (debug mode only).
 	// Read cachedSystemPrompt under lock to avoid a data race with
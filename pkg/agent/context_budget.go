@@ -37,6 +37,22 @@ func isSafeBoundary(history []providers.Message, index int) bool {
 	return history[index].Role == "user"
 }
 
+// historyWindowCut returns the index of the first Turn boundary that keeps at
+// most limit messages. When the newest Turn alone is longer than limit it
+// falls back to findSafeBoundary, keeping that Turn whole.
+func historyWindowCut(history []providers.Message, limit int) int {
+	target := len(history) - limit
+	if target <= 0 {
+		return 0
+	}
+	for _, t := range parseTurnBoundaries(history) {
+		if t >= target {
+			return t
+		}
+	}
+	return findSafeBoundary(history, target)
+}
+
 // findSafeBoundary locates the nearest Turn boundary to targetIndex.
 // It prefers the boundary at or before targetIndex (preserving more recent
 // context). Falls back to the nearest boundary after targetIndex, and
@@ -99,7 +115,9 @@ func EstimateToolDefsTokens(defs []providers.ToolDefinition) int {
 // isOverContextBudget checks whether the assembled messages plus tool definitions
 // and output reserve would exceed the model's context window. This enables
 // proactive compression before calling the LLM, rather than reacting to 400 errors.
+// Counting uses tok, or the character heuristic when tok is nil.
 func isOverContextBudget(
+	tok tokenizer.Tokenizer,
 	contextWindow int,
 	messages []providers.Message,
 	toolDefs []providers.ToolDefinition,
@@ -107,10 +125,10 @@ func isOverContextBudget(
 ) bool {
 	msgTokens := 0
 	for _, m := range messages {
-		msgTokens += EstimateMessageTokens(m)
+		msgTokens += tokenizer.CountMessageTokens(tok, m)
 	}
 
-	toolTokens := EstimateToolDefsTokens(toolDefs)
+	toolTokens := tokenizer.CountToolDefsTokens(tok, toolDefs)
 	total := msgTokens + toolTokens + maxTokens
 
 	return total > contextWindow
@@ -120,6 +138,7 @@ func isOverContextBudget(
 // history slices until it fits within the context window. Oldest complete turns
 // are dropped first so tool-call sequences remain intact.
 func trimHistoryToFitContextWindow(
+	tok tokenizer.Tokenizer,
 	history []providers.Message,
 	build func([]providers.Message) []providers.Message,
 	contextWindow int,
@@ -127,7 +146,7 @@ func trimHistoryToFitContextWindow(
 	maxTokens int,
 ) ([]providers.Message, []providers.Message, bool) {
 	messages := build(history)
-	if !isOverContextBudget(contextWindow, messages, toolDefs, maxTokens) {
+	if !isOverContextBudget(tok, contextWindow, messages, toolDefs, maxTokens) {
 		return history, messages, true
 	}
 
@@ -141,7 +160,7 @@ func trimHistoryToFitContextWindow(
 		}
 
 		messages = build(trimmedHistory)
-		if !isOverContextBudget(contextWindow, messages, toolDefs, maxTokens) {
+		if !isOverContextBudget(tok, contextWindow, messages, toolDefs, maxTokens) {
 			return trimmedHistory, messages, true
 		}
 	}
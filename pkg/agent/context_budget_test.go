@@ -363,6 +363,34 @@ func TestFindSafeBoundary_SingleTurnReturnsZero(t *testing.T) {
 	}
 }
 
+func TestHistoryWindowCut(t *testing.T) {
+	history := []providers.Message{
+		msgUser("q1"),            // 0
+		msgAssistant("a1"),       // 1
+		msgUser("q2"),            // 2
+		msgAssistantTC("tc1"),    // 3
+		msgTool("tc1", "result"), // 4
+		msgAssistant("a2"),       // 5
+		msgUser("q3"),            // 6
+		msgAssistant("a3"),       // 7
+	}
+
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{limit: 10, want: 0}, // everything fits
+		{limit: 6, want: 2},  // exactly at a Turn boundary
+		{limit: 5, want: 6},  // cutting at 3 would split a tool sequence; keep fewer
+		{limit: 1, want: 6},  // the newest Turn is kept whole
+	}
+	for _, tt := range tests {
+		if got := historyWindowCut(history, tt.limit); got != tt.want {
+			t.Errorf("historyWindowCut(limit=%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
+
 func TestFindSafeBoundary_BackwardScanSkipsToolSequence(t *testing.T) {
 	// A long tool-call chain: user → assistant+TC → tool → tool → ... → assistant → user
 	// Target is inside the chain; boundary should skip the entire chain backward.
@@ -696,7 +724,7 @@ func TestIsOverContextBudget(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isOverContextBudget(tt.contextWindow, tt.messages, tt.toolDefs, tt.maxTokens)
+			got := isOverContextBudget(nil, tt.contextWindow, tt.messages, tt.toolDefs, tt.maxTokens)
 			if got != tt.want {
 				t.Errorf("isOverContextBudget() = %v, want %v", got, tt.want)
 			}
@@ -835,12 +863,12 @@ func TestIsOverContextBudget_RealisticSession(t *testing.T) {
 	}
 
 	// With a large context window, should be within budget.
-	if isOverContextBudget(131072, messages, tools, 32768) {
+	if isOverContextBudget(nil, 131072, messages, tools, 32768) {
 		t.Error("realistic session should be within 131072 context window")
 	}
 
 	// With a tiny context window, should exceed budget.
-	if !isOverContextBudget(500, messages, tools, 32768) {
+	if !isOverContextBudget(nil, 500, messages, tools, 32768) {
 		t.Error("realistic session should exceed 500 context window")
 	}
 }
@@ -860,6 +888,7 @@ func TestTrimHistoryToFitContextWindow_DropsOldestTurns(t *testing.T) {
 	}
 
 	trimmedHistory, messages, fit := trimHistoryToFitContextWindow(
+		nil,
 		history,
 		build,
 		700,
@@ -875,7 +904,7 @@ func TestTrimHistoryToFitContextWindow_DropsOldestTurns(t *testing.T) {
 	if trimmedHistory[0].Content != history[2].Content {
 		t.Fatalf("first kept message = %q, want second turn start", trimmedHistory[0].Content)
 	}
-	if isOverContextBudget(700, messages, nil, 0) {
+	if isOverContextBudget(nil, 700, messages, nil, 0) {
 		t.Fatal("trimmed messages should be within budget")
 	}
 }
@@ -887,6 +916,7 @@ func TestTrimHistoryToFitContextWindow_ClearsSingleOversizedTurn(t *testing.T) {
 	}
 
 	trimmedHistory, messages, fit := trimHistoryToFitContextWindow(
+		nil,
 		history,
 		func(history []providers.Message) []providers.Message {
 			return append([]providers.Message(nil), history...)
@@ -18,6 +18,7 @@ import (
 type legacyContextManager struct {
 	al          *AgentLoop
 	summarizing sync.Map // dedup for async Compact (post-turn)
+	previews    sync.Map // agentID:sessionKey -> *compactionPreview
 }
 
 func (m *legacyContextManager) Assemble(_ context.Context, req *AssembleRequest) (*AssembleResponse, error) {
@@ -53,6 +54,8 @@ func (m *legacyContextManager) Compact(_ context.Context, req *CompactRequest) e
 		}
 	case ContextCompressReasonSummarize:
 		m.maybeSummarize(req.SessionKey)
+	case ContextCompressReasonManual:
+		return m.summarizeNow(req.SessionKey)
 	}
 	return nil
 }
@@ -67,11 +70,16 @@ func (m *legacyContextManager) Clear(_ context.Context, sessionKey string) error
 	if agent == nil || agent.Sessions == nil {
 		return fmt.Errorf("sessions not initialized")
 	}
+	m.previews.Delete(agent.ID + ":" + sessionKey)
 	agent.Sessions.SetHistory(sessionKey, []providers.Message{})
 	agent.Sessions.SetSummary(sessionKey, "")
 	return agent.Sessions.Save(sessionKey)
 }
 
+// summaryKeepMessages is how many recent messages summarization keeps
+// verbatim when a threshold is exceeded.
+const summaryKeepMessages = 4
+
 // maybeSummarize triggers summarization if the session history exceeds thresholds.
 // It runs asynchronously in a goroutine.
 func (m *legacyContextManager) maybeSummarize(sessionKey string) {
@@ -80,11 +88,7 @@ func (m *legacyContextManager) maybeSummarize(sessionKey string) {
 		return
 	}
 
-	newHistory := agent.Sessions.GetHistory(sessionKey)
-	tokenEstimate := m.estimateTokens(newHistory)
-	threshold := agent.ContextWindow * agent.SummarizeTokenPercent / 100
-
-	if len(newHistory) > agent.SummarizeMessageThreshold || tokenEstimate > threshold {
+	if keep, ok := summaryPlan(agent, agent.Sessions.GetHistory(sessionKey)); ok {
 		summarizeKey := agent.ID + ":" + sessionKey
 		if _, loading := m.summarizing.LoadOrStore(summarizeKey, true); !loading {
 			go func() {
@@ -98,12 +102,67 @@ func (m *legacyContextManager) maybeSummarize(sessionKey string) {
 					}
 				}()
 				logger.Debug("Memory threshold reached. Optimizing conversation history...")
-				m.summarizeSession(agent, sessionKey)
+				m.summarizeSession(agent, sessionKey, keep)
 			}()
 		}
 	}
 }
 
+// summaryPlan reports whether history is due for summarization and how many
+// recent messages to keep verbatim.
+func summaryPlan(agent *AgentInstance, history []providers.Message) (keep int, ok bool) {
+	tokenEstimate := agent.CountTokens(history)
+	threshold := agent.ContextWindow * agent.SummarizeTokenPercent / 100
+
+	overThreshold := len(history) > agent.SummarizeMessageThreshold || tokenEstimate > threshold
+	// Past the history window only the oldest turns are folded. Keeping half
+	// the window leaves room for a few turns before the next summarization.
+	overWindow := agent.MaxHistoryMessages > 0 && len(history) > agent.MaxHistoryMessages
+	switch {
+	case overThreshold:
+		return summaryKeepMessages, true
+	case overWindow:
+		return max(agent.MaxHistoryMessages/2, summaryKeepMessages), true
+	default:
+		return 0, false
+	}
+}
+
+// summaryCut returns the index before which history is folded into the
+// summary when about keepMessages recent messages are kept. The kept history
+// never exceeds the agent's history window unless that would split a turn.
+func summaryCut(agent *AgentInstance, history []providers.Message, keepMessages int) int {
+	if len(history) <= keepMessages {
+		return 0
+	}
+	safeCut := findSafeBoundary(history, len(history)-keepMessages)
+	if limit := agent.MaxHistoryMessages; limit > 0 && len(history)-safeCut > limit {
+		safeCut = historyWindowCut(history, limit)
+	}
+	return safeCut
+}
+
+// summarizeNow runs summarization synchronously regardless of thresholds.
+// It shares the dedup key with maybeSummarize so a manual request never
+// races a background run on the same session.
+func (m *legacyContextManager) summarizeNow(sessionKey string) error {
+	agent := m.al.registry.GetDefaultAgent()
+	if agent == nil {
+		return fmt.Errorf("no default agent")
+	}
+
+	summarizeKey := agent.ID + ":" + sessionKey
+	if _, loading := m.summarizing.LoadOrStore(summarizeKey, true); loading {
+		return fmt.Errorf("summarization is already in progress")
+	}
+	defer m.summarizing.Delete(summarizeKey)
+
+	if !m.summarizeSession(agent, sessionKey, summaryKeepMessages) {
+		return ErrNothingToSummarize
+	}
+	return nil
+}
+
 type compressionResult struct {
 	DroppedMessages   int
 	RemainingMessages int
@@ -122,6 +181,9 @@ func (m *legacyContextManager) forceCompression(sessionKey string) (compressionR
 	if len(history) <= 2 {
 		return compressionResult{}, false
 	}
+	// Emergency compression does not wait for the user; a pending preview no
+	// longer matches the history it described.
+	m.previews.Delete(agent.ID + ":" + sessionKey)
 
 	turns := parseTurnBoundaries(history)
 	var mid int
@@ -132,12 +194,7 @@ func (m *legacyContextManager) forceCompression(sessionKey string) (compressionR
 	}
 	var keptHistory []providers.Message
 	if mid <= 0 {
-		for i := len(history) - 1; i >= 0; i-- {
-			if history[i].Role == "user" {
-				keptHistory = []providers.Message{history[i]}
-				break
-			}
-		}
+		keptHistory = compressSingleTurn(history)
 	} else {
 		keptHistory = history[mid:]
 	}
@@ -169,23 +226,48 @@ func (m *legacyContextManager) forceCompression(sessionKey string) (compressionR
 	}, true
 }
 
-func (m *legacyContextManager) summarizeSession(agent *AgentInstance, sessionKey string) {
+// compressSingleTurn shrinks a history that is one long Turn. It keeps the
+// turn's user message and the most recent complete tool groups (an assistant
+// message plus the tool results that follow it) from the newer half, so the
+// model still sees what it was just working on.
+func compressSingleTurn(history []providers.Message) []providers.Message {
+	userIdx := -1
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			userIdx = i
+			break
+		}
+	}
+	if userIdx < 0 {
+		return nil
+	}
+	kept := []providers.Message{history[userIdx]}
+	for i := max(userIdx+1, len(history)/2); i < len(history); i++ {
+		if history[i].Role == "assistant" {
+			return append(kept, history[i:]...)
+		}
+	}
+	return kept
+}
+
+// summarizeSession folds older history into the session summary, keeping
+// about keepMessages recent messages, and reports whether anything was
+// summarized. Messages the user protected with :keep stay in the history
+// verbatim, ahead of the kept messages.
+func (m *legacyContextManager) summarizeSession(agent *AgentInstance, sessionKey string, keepMessages int) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
 	history := agent.Sessions.GetHistory(sessionKey)
 	summary := agent.Sessions.GetSummary(sessionKey)
 
-	if len(history) <= 4 {
-		return
-	}
-
-	safeCut := findSafeBoundary(history, len(history)-4)
+	safeCut := summaryCut(agent, history, keepMessages)
 	if safeCut <= 0 {
-		return
+		return false
 	}
 	keepCount := len(history) - safeCut
 	toSummarize := history[:safeCut]
+	protected := m.protectedMessages(agent, sessionKey, toSummarize)
 
 	maxMessageTokens := agent.ContextWindow / 2
 	validMessages := make([]providers.Message, 0)
@@ -195,6 +277,9 @@ func (m *legacyContextManager) summarizeSession(agent *AgentInstance, sessionKey
 		if msg.Role != "user" && msg.Role != "assistant" {
 			continue
 		}
+		if isProtectedMessage(protected, msg) {
+			continue
+		}
 		msgTokens := len(msg.Content) / 2
 		if msgTokens > maxMessageTokens {
 			omitted = true
@@ -204,7 +289,7 @@ func (m *legacyContextManager) summarizeSession(agent *AgentInstance, sessionKey
 	}
 
 	if len(validMessages) == 0 {
-		return
+		return false
 	}
 
 	const (
@@ -242,21 +327,32 @@ func (m *legacyContextManager) summarizeSession(agent *AgentInstance, sessionKey
 		finalSummary += "\n[Note: Some oversized messages were omitted from this summary for efficiency.]"
 	}
 
-	if finalSummary != "" {
-		agent.Sessions.SetSummary(sessionKey, finalSummary)
+	if finalSummary == "" {
+		return false
+	}
+
+	agent.Sessions.SetSummary(sessionKey, finalSummary)
+	if len(protected) > 0 {
+		current := agent.Sessions.GetHistory(sessionKey)
+		kept := append(protected, current[max(len(current)-keepCount, 0):]...)
+		agent.Sessions.SetHistory(sessionKey, kept)
+	} else {
 		agent.Sessions.TruncateHistory(sessionKey, keepCount)
-		agent.Sessions.Save(sessionKey)
-		m.al.emitEvent(
-			runtimeevents.KindAgentSessionSummarize,
-			m.al.newTurnEventScope(agent.ID, sessionKey, nil).meta(0, "summarizeSession", "turn.session.summarize"),
-			SessionSummarizePayload{
-				SummarizedMessages: len(validMessages),
-				KeptMessages:       keepCount,
-				SummaryLen:         len(finalSummary),
-				OmittedOversized:   omitted,
-			},
-		)
 	}
+	agent.Sessions.Save(sessionKey)
+	m.previews.Delete(agent.ID + ":" + sessionKey)
+	m.al.emitEvent(
+		runtimeevents.KindAgentSessionSummarize,
+		m.al.newTurnEventScope(agent.ID, sessionKey, nil).meta(0, "summarizeSession", "turn.session.summarize"),
+		SessionSummarizePayload{
+			SummarizedMessages: len(validMessages),
+			KeptMessages:       keepCount,
+			SummaryLen:         len(finalSummary),
+			OmittedOversized:   omitted,
+			Model:              summaryModelName(agent),
+		},
+	)
+	return true
 }
 
 func (m *legacyContextManager) findNearestUserMessage(messages []providers.Message, mid int) int {
@@ -297,17 +393,11 @@ func (m *legacyContextManager) retryLLMCall(
 		m.al.activeRequests.Add(1)
 		resp, err = func() (*providers.LLMResponse, error) {
 			defer m.al.activeRequests.Done()
-			return agent.Provider.Chat(
-				ctx,
-				[]providers.Message{{Role: "user", Content: prompt}},
-				nil,
-				agent.Model,
-				map[string]any{
-					"max_tokens":       agent.MaxTokens,
-					"temperature":      llmTemperature,
-					"prompt_cache_key": agent.ID,
-				},
-			)
+			return m.al.summaryChat(ctx, agent, prompt, map[string]any{
+				"max_tokens":       agent.MaxTokens,
+				"temperature":      llmTemperature,
+				"prompt_cache_key": agent.ID,
+			})
 		}()
 
 		if err == nil && resp != nil && resp.Content != "" {
@@ -380,11 +470,3 @@ func (m *legacyContextManager) summarizeBatch(
 	}
 	return fallback.String(), nil
 }
-
-func (m *legacyContextManager) estimateTokens(messages []providers.Message) int {
-	total := 0
-	for _, msg := range messages {
-		total += EstimateMessageTokens(msg)
-	}
-	return total
-}
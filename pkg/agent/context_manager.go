@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -30,6 +31,10 @@ type ContextManager interface {
 	Clear(ctx context.Context, sessionKey string) error
 }
 
+// ErrNothingToSummarize is returned by a manual Compact when the session
+// history is too short to be worth summarizing.
+var ErrNothingToSummarize = errors.New("not enough history to summarize")
+
 // AssembleRequest is the input to Assemble.
 type AssembleRequest struct {
 	SessionKey string // session identifier
@@ -46,7 +51,7 @@ type AssembleResponse struct {
 // CompactRequest is the input to Compact.
 type CompactRequest struct {
 	SessionKey string                // session identifier
-	Reason     ContextCompressReason // proactive_budget | llm_retry | summarize
+	Reason     ContextCompressReason // proactive_budget | llm_retry | summarize | manual
 	Budget     int                   // context window budget (used for retry aggressive compaction)
 }
 
@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"strings"
 	"sync"
@@ -520,6 +521,64 @@ func TestLegacyCompact_PostTurn_ExceedsMessageThreshold(t *testing.T) {
 	}
 }
 
+func TestLegacyCompact_Manual_SummarizesBelowThreshold(t *testing.T) {
+	cfg := testConfig(t)
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &simpleMockProvider{response: "summary"})
+
+	defaultAgent := al.registry.GetDefaultAgent()
+	if defaultAgent == nil {
+		t.Fatal("expected default agent")
+	}
+
+	// 6 messages is below the default threshold, but a manual request
+	// summarizes anyway and does so before returning.
+	history := []providers.Message{
+		{Role: "user", Content: "q1"},
+		{Role: "assistant", Content: "a1"},
+		{Role: "user", Content: "q2"},
+		{Role: "assistant", Content: "a2"},
+		{Role: "user", Content: "q3"},
+		{Role: "assistant", Content: "a3"},
+	}
+	defaultAgent.Sessions.SetHistory("session-manual", history)
+
+	err := al.contextManager.Compact(context.Background(), &CompactRequest{
+		SessionKey: "session-manual",
+		Reason:     ContextCompressReasonManual,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(defaultAgent.Sessions.GetHistory("session-manual")); got >= len(history) {
+		t.Fatalf("expected manual summarization to reduce history from %d messages, got %d", len(history), got)
+	}
+	if summary := defaultAgent.Sessions.GetSummary("session-manual"); summary == "" {
+		t.Fatal("expected a session summary after manual summarization")
+	}
+}
+
+func TestLegacyCompact_Manual_TooLittleHistory(t *testing.T) {
+	cfg := testConfig(t)
+	al := newCMTestAgentLoop(cfg)
+
+	defaultAgent := al.registry.GetDefaultAgent()
+	if defaultAgent == nil {
+		t.Fatal("expected default agent")
+	}
+	defaultAgent.Sessions.SetHistory("session-short", []providers.Message{
+		{Role: "user", Content: "hi"},
+		{Role: "assistant", Content: "hello"},
+	})
+
+	err := al.contextManager.Compact(context.Background(), &CompactRequest{
+		SessionKey: "session-short",
+		Reason:     ContextCompressReasonManual,
+	})
+	if !errors.Is(err, ErrNothingToSummarize) {
+		t.Fatalf("Compact() error = %v, want ErrNothingToSummarize", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Legacy Ingest tests
 // ---------------------------------------------------------------------------
@@ -693,6 +752,59 @@ func TestLegacyCompact_Overflow_SingleTurnKeepsLastUserMessage(t *testing.T) {
 	}
 }
 
+func TestLegacyCompact_Overflow_SingleLongTurnKeepsRecentToolGroups(t *testing.T) {
+	cfg := testConfig(t)
+	al := newCMTestAgentLoop(cfg)
+
+	defaultAgent := al.registry.GetDefaultAgent()
+	if defaultAgent == nil {
+		t.Fatal("expected default agent")
+	}
+
+	toolGroup := func(id string) []providers.Message {
+		return []providers.Message{
+			{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: id, Name: "exec"}}},
+			{Role: "tool", ToolCallID: id, Content: "output " + id},
+		}
+	}
+	history := []providers.Message{{Role: "user", Content: "build the project"}}
+	for _, id := range []string{"t1", "t2", "t3", "t4"} {
+		history = append(history, toolGroup(id)...)
+	}
+	defaultAgent.Sessions.SetHistory("session-long-turn", history)
+
+	if err := al.contextManager.Compact(context.Background(), &CompactRequest{
+		SessionKey: "session-long-turn",
+		Reason:     ContextCompressReasonRetry,
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := defaultAgent.Sessions.GetHistory("session-long-turn")
+	if len(got) == 0 || got[0].Role != "user" {
+		t.Fatalf("expected the turn's user message first, got %+v", got)
+	}
+	if len(got) >= len(history) {
+		t.Fatalf("expected history to shrink, got %d of %d messages", len(got), len(history))
+	}
+	if got[1].Role != "assistant" {
+		t.Fatalf("expected kept tool groups to start at an assistant message, got %q", got[1].Role)
+	}
+	last := got[len(got)-1]
+	if last.Role != "tool" || last.ToolCallID != "t4" {
+		t.Fatalf("expected the latest tool result to be kept, got %+v", last)
+	}
+	called := make(map[string]bool)
+	for _, msg := range got {
+		for _, tc := range msg.ToolCalls {
+			called[tc.ID] = true
+		}
+		if msg.Role == "tool" && !called[msg.ToolCallID] {
+			t.Fatalf("tool result %q kept without its tool call", msg.ToolCallID)
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Test helpers
 // ---------------------------------------------------------------------------
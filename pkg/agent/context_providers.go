@@ -0,0 +1,272 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// contextProviderTimeout bounds a single provider so a slow lookup (weather,
+// device state) cannot stall the turn.
+const contextProviderTimeout = 2 * time.Second
+
+// Priorities of the built-in providers. Lower values are rendered first.
+const (
+	ContextPriorityDatetime = 100
+	ContextPriorityLocale   = 150
+	ContextPriorityHostInfo = 200
+)
+
+// ContextProvider contributes dynamic text to the system prompt. Contribute is
+// called on every turn; returning an empty string contributes nothing.
+// Implementations should honour ctx cancellation.
+type ContextProvider interface {
+	Contribute(ctx context.Context) (string, error)
+}
+
+// ContextProviderFunc adapts a plain function to ContextProvider.
+type ContextProviderFunc func(ctx context.Context) (string, error)
+
+func (f ContextProviderFunc) Contribute(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+type registeredContextProvider struct {
+	name     string
+	priority int
+	provider ContextProvider
+}
+
+// contextProvidersPromptContributor renders all registered context providers
+// as a single runtime part, ordered by priority and then name.
+type contextProvidersPromptContributor struct {
+	mu        sync.RWMutex
+	providers []registeredContextProvider
+}
+
+func (c *contextProvidersPromptContributor) PromptSource() PromptSourceDescriptor {
+	return PromptSourceDescriptor{
+		ID:              PromptSourceContextProviders,
+		Owner:           "agent",
+		Description:     "Dynamic context from registered context providers",
+		Allowed:         []PromptPlacement{{Layer: PromptLayerContext, Slot: PromptSlotRuntime}},
+		StableByDefault: false,
+	}
+}
+
+func (c *contextProvidersPromptContributor) register(name string, priority int, provider ContextProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.providers = slices.DeleteFunc(c.providers, func(p registeredContextProvider) bool {
+		return p.name == name
+	})
+	c.providers = append(c.providers, registeredContextProvider{name: name, priority: priority, provider: provider})
+	slices.SortStableFunc(c.providers, func(a, b registeredContextProvider) int {
+		if a.priority != b.priority {
+			return a.priority - b.priority
+		}
+		return strings.Compare(a.name, b.name)
+	})
+}
+
+func (c *contextProvidersPromptContributor) ContributePrompt(
+	ctx context.Context,
+	req PromptBuildRequest,
+) ([]PromptPart, error) {
+	c.mu.RLock()
+	providers := append([]registeredContextProvider(nil), c.providers...)
+	c.mu.RUnlock()
+
+	ctx = context.WithValue(ctx, senderLocaleKey{}, req.SenderLocale)
+
+	var sections []string
+	for _, p := range providers {
+		text, err := contributeWithTimeout(ctx, p.provider)
+		if err != nil {
+			// One failing provider must not drop the rest of the prompt.
+			logger.WarnCF("agent", "Context provider failed", map[string]any{
+				"provider": p.name,
+				"error":    err.Error(),
+			})
+			continue
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			sections = append(sections, text)
+		}
+	}
+	if len(sections) == 0 {
+		return nil, nil
+	}
+
+	return []PromptPart{
+		{
+			ID:      "context.providers",
+			Layer:   PromptLayerContext,
+			Slot:    PromptSlotRuntime,
+			Source:  PromptSource{ID: PromptSourceContextProviders, Name: "runtime:providers"},
+			Title:   "context providers",
+			Content: strings.Join(sections, "\n\n"),
+			Stable:  false,
+			Cache:   PromptCacheNone,
+		},
+	}, nil
+}
+
+type senderLocaleKey struct{}
+
+// SenderLocale returns the language tag the current sender's platform
+// reported, or "" if unknown. It is set for ContextProvider.Contribute calls.
+func SenderLocale(ctx context.Context) string {
+	locale, _ := ctx.Value(senderLocaleKey{}).(string)
+	return locale
+}
+
+func contributeWithTimeout(ctx context.Context, provider ContextProvider) (text string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, contextProviderTimeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return provider.Contribute(ctx)
+}
+
+// RegisterContextProvider adds a provider whose output is appended to the
+// system prompt on every turn. Providers with a lower priority are rendered
+// first; registering a name again replaces the earlier provider.
+func (cb *ContextBuilder) RegisterContextProvider(name string, priority int, provider ContextProvider) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("context provider name is required")
+	}
+	if provider == nil {
+		return fmt.Errorf("context provider %q is nil", name)
+	}
+	if cb.contextProviders == nil {
+		contributor := &contextProvidersPromptContributor{}
+		if err := cb.RegisterPromptContributor(contributor); err != nil {
+			return err
+		}
+		cb.contextProviders = contributor
+	}
+	cb.contextProviders.register(name, priority, provider)
+	return nil
+}
+
+// WithLocale sets the agent's timezone and default locale. The timezone is
+// used for the current time in every prompt and is the default zone of the
+// datetime provider, so call it before WithContextProviders. The locale is
+// reported by the "locale" provider, which prefers the sender's own locale.
+func (cb *ContextBuilder) WithLocale(timezone, locale string) *ContextBuilder {
+	if tz := strings.TrimSpace(timezone); tz != "" {
+		if loc, err := time.LoadLocation(tz); err != nil {
+			logger.WarnCF("agent", "Invalid agent timezone, using host zone", map[string]any{
+				"timezone": tz,
+				"error":    err.Error(),
+			})
+		} else {
+			cb.location = loc
+		}
+	}
+	cb.registerBuiltinContextProvider("locale", ContextPriorityLocale, localeContextProvider{
+		fallback: strings.TrimSpace(locale),
+	})
+	return cb
+}
+
+// WithContextProviders registers the built-in providers enabled in cfg.
+func (cb *ContextBuilder) WithContextProviders(cfg config.ContextProvidersConfig) *ContextBuilder {
+	if cfg.Datetime.Enabled {
+		timezone := cfg.Datetime.Timezone
+		if strings.TrimSpace(timezone) == "" && cb.location != nil {
+			timezone = cb.location.String()
+		}
+		if provider, err := newDatetimeContextProvider(timezone); err != nil {
+			logger.WarnCF("agent", "Invalid datetime context provider timezone", map[string]any{
+				"timezone": timezone,
+				"error":    err.Error(),
+			})
+		} else {
+			cb.registerBuiltinContextProvider("datetime", ContextPriorityDatetime, provider)
+		}
+	}
+	if cfg.HostInfo.Enabled {
+		cb.registerBuiltinContextProvider("host_info", ContextPriorityHostInfo, ContextProviderFunc(hostInfoContext))
+	}
+	return cb
+}
+
+func (cb *ContextBuilder) registerBuiltinContextProvider(name string, priority int, provider ContextProvider) {
+	if err := cb.RegisterContextProvider(name, priority, provider); err != nil {
+		logger.WarnCF("agent", "Failed to register context provider", map[string]any{
+			"provider": name,
+			"error":    err.Error(),
+		})
+	}
+}
+
+// newDatetimeContextProvider reports the current date and time in the
+// configured zone, which may differ from the host zone used by the runtime
+// section (e.g. a UTC server talking to a user in Asia/Shanghai).
+func newDatetimeContextProvider(timezone string) (ContextProvider, error) {
+	loc := time.Local
+	if tz := strings.TrimSpace(timezone); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return nil, err
+		}
+	}
+	return datetimeContextProvider{loc: loc, now: time.Now}, nil
+}
+
+type datetimeContextProvider struct {
+	loc *time.Location
+	now func() time.Time
+}
+
+func (p datetimeContextProvider) Contribute(context.Context) (string, error) {
+	now := p.now().In(p.loc)
+	return fmt.Sprintf("## Date and Time\nLocal: %s\nTimezone: %s (UTC%s)\nUTC: %s",
+		now.Format("2006-01-02 15:04:05 (Monday)"),
+		p.loc.String(),
+		now.Format("-07:00"),
+		now.UTC().Format(time.RFC3339),
+	), nil
+}
+
+// localeContextProvider reports the language to answer in: the sender's
+// locale when their platform reports one, otherwise the configured default.
+type localeContextProvider struct {
+	fallback string
+}
+
+func (p localeContextProvider) Contribute(ctx context.Context) (string, error) {
+	locale, source := strings.TrimSpace(SenderLocale(ctx)), "reported by the sender's app"
+	if locale == "" {
+		locale, source = p.fallback, "configured default"
+	}
+	if locale == "" {
+		return "", nil
+	}
+	return fmt.Sprintf("## Locale\nLanguage: %s (%s)\n"+
+		"Use this language and its date and number conventions unless the user writes in another language.",
+		locale, source), nil
+}
+
+func hostInfoContext(context.Context) (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("## Host\nHostname: %s\nPlatform: %s/%s\nCPUs: %d",
+		hostname, runtime.GOOS, runtime.GOARCH, runtime.NumCPU()), nil
+}
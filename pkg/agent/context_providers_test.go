@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func staticContextProvider(text string) ContextProvider {
+	return ContextProviderFunc(func(context.Context) (string, error) { return text, nil })
+}
+
+func TestContextBuilder_ContextProvidersRenderInPriorityOrder(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+	cb := NewContextBuilder(t.TempDir())
+
+	for _, p := range []struct {
+		name     string
+		priority int
+		text     string
+	}{
+		{"weather", 300, "## Weather\nSunny"},
+		{"locale", 10, "## Locale\nzh-CN"},
+		{"device", 300, "## Device\nLamp on"},
+	} {
+		if err := cb.RegisterContextProvider(p.name, p.priority, staticContextProvider(p.text)); err != nil {
+			t.Fatalf("RegisterContextProvider(%q) error = %v", p.name, err)
+		}
+	}
+
+	system := cb.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi"})[0].Content
+	locale := strings.Index(system, "## Locale")
+	device := strings.Index(system, "## Device")
+	weather := strings.Index(system, "## Weather")
+	if locale < 0 || device < 0 || weather < 0 {
+		t.Fatalf("system prompt missing provider content: %q", system)
+	}
+	if !(locale < device && device < weather) {
+		t.Fatalf("providers out of order: locale=%d device=%d weather=%d", locale, device, weather)
+	}
+}
+
+func TestContextBuilder_ContextProviderFailureDoesNotDropOthers(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+	cb := NewContextBuilder(t.TempDir())
+
+	failing := ContextProviderFunc(func(context.Context) (string, error) {
+		return "", errors.New("sensor offline")
+	})
+	panicking := ContextProviderFunc(func(context.Context) (string, error) { panic("boom") })
+	_ = cb.RegisterContextProvider("failing", 1, failing)
+	_ = cb.RegisterContextProvider("panicking", 2, panicking)
+	_ = cb.RegisterContextProvider("ok", 3, staticContextProvider("## Still Here"))
+
+	system := cb.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi"})[0].Content
+	if !strings.Contains(system, "## Still Here") {
+		t.Fatalf("healthy provider content missing: %q", system)
+	}
+}
+
+func TestContextBuilder_RegisterContextProviderReplacesByName(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+	cb := NewContextBuilder(t.TempDir())
+
+	_ = cb.RegisterContextProvider("status", 1, staticContextProvider("old status"))
+	_ = cb.RegisterContextProvider("status", 1, staticContextProvider("new status"))
+
+	system := cb.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi"})[0].Content
+	if strings.Contains(system, "old status") || !strings.Contains(system, "new status") {
+		t.Fatalf("provider was not replaced: %q", system)
+	}
+	if err := cb.RegisterContextProvider(" ", 1, staticContextProvider("x")); err == nil {
+		t.Fatal("expected error for empty provider name")
+	}
+}
+
+func TestDatetimeContextProvider_UsesConfiguredTimezone(t *testing.T) {
+	provider, err := newDatetimeContextProvider("Asia/Shanghai")
+	if err != nil {
+		t.Fatalf("newDatetimeContextProvider() error = %v", err)
+	}
+	dp := provider.(datetimeContextProvider)
+	dp.now = func() time.Time { return time.Date(2026, 3, 1, 16, 30, 0, 0, time.UTC) }
+
+	got, err := dp.Contribute(context.Background())
+	if err != nil {
+		t.Fatalf("Contribute() error = %v", err)
+	}
+	for _, want := range []string{
+		"Local: 2026-03-02 00:30:00 (Monday)",
+		"Timezone: Asia/Shanghai (UTC+08:00)",
+		"UTC: 2026-03-01T16:30:00Z",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Contribute() = %q, missing %q", got, want)
+		}
+	}
+
+	if _, err := newDatetimeContextProvider("Mars/Olympus"); err == nil {
+		t.Fatal("expected error for unknown timezone")
+	}
+}
+
+func TestContextBuilder_WithContextProvidersHonoursConfig(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+
+	disabled := NewContextBuilder(t.TempDir()).WithContextProviders(config.ContextProvidersConfig{})
+	system := disabled.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi"})[0].Content
+	if strings.Contains(system, "## Date and Time") || strings.Contains(system, "## Host") {
+		t.Fatalf("disabled providers contributed content: %q", system)
+	}
+
+	enabled := NewContextBuilder(t.TempDir()).WithContextProviders(config.ContextProvidersConfig{
+		Datetime: config.DatetimeContextConfig{Enabled: true, Timezone: "UTC"},
+		HostInfo: config.HostInfoContextConfig{Enabled: true},
+	})
+	system = enabled.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi"})[0].Content
+	dt := strings.Index(system, "## Date and Time")
+	host := strings.Index(system, "## Host")
+	if dt < 0 || host < 0 || dt > host {
+		t.Fatalf("expected datetime before host info: %q", system)
+	}
+}
+
+func TestContextBuilder_WithLocalePrefersSenderLocale(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+	cb := NewContextBuilder(t.TempDir()).WithLocale("Asia/Tokyo", "en-US")
+
+	system := cb.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi"})[0].Content
+	if !strings.Contains(system, "Language: en-US (configured default)") {
+		t.Fatalf("expected configured locale: %q", system)
+	}
+	if !strings.Contains(system, "Asia/Tokyo") {
+		t.Fatalf("expected current time in the configured zone: %q", system)
+	}
+
+	system = cb.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "oi", SenderLocale: "pt-BR"})[0].Content
+	if !strings.Contains(system, "Language: pt-BR (reported by the sender's app)") {
+		t.Fatalf("expected sender locale to win: %q", system)
+	}
+
+	bare := NewContextBuilder(t.TempDir()).WithLocale("", "")
+	system = bare.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi"})[0].Content
+	if strings.Contains(system, "## Locale") {
+		t.Fatalf("locale section without any locale: %q", system)
+	}
+}
+
+func TestContextBuilder_AgentTimezoneIsDatetimeDefault(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+	cb := NewContextBuilder(t.TempDir()).
+		WithLocale("Asia/Kolkata", "").
+		WithContextProviders(config.ContextProvidersConfig{
+			Datetime: config.DatetimeContextConfig{Enabled: true},
+		})
+
+	system := cb.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi"})[0].Content
+	if !strings.Contains(system, "Timezone: Asia/Kolkata (UTC+05:30)") {
+		t.Fatalf("expected datetime provider to use the agent timezone: %q", system)
+	}
+}
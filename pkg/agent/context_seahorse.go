@@ -34,7 +34,7 @@ func newSeahorseContextManager(_ json.RawMessage, al *AgentLoop) (ContextManager
 	dbPath := agent.Workspace + "/sessions/seahorse.db"
 
 	// Create CompleteFn from provider
-	completeFn := providerToCompleteFn(agent.Provider, agent.Model)
+	completeFn := summaryCompleteFn(al, agent)
 
 	// Create engine
 	engine, err := seahorse.NewEngine(seahorse.Config{
@@ -65,6 +65,25 @@ func newSeahorseContextManager(_ json.RawMessage, al *AgentLoop) (ContextManager
 	return mgr, nil
 }
 
+// summaryCompleteFn wraps the agent's summary model, or its primary model
+// when none is configured, as a seahorse.CompleteFn.
+func summaryCompleteFn(al *AgentLoop, agent *AgentInstance) seahorse.CompleteFn {
+	if len(agent.SummaryCandidates) == 0 {
+		return providerToCompleteFn(agent.Provider, agent.Model)
+	}
+	return func(ctx context.Context, prompt string, opts seahorse.CompleteOptions) (string, error) {
+		resp, err := al.summaryChat(ctx, agent, prompt, map[string]any{
+			"max_tokens":       opts.MaxTokens,
+			"temperature":      opts.Temperature,
+			"prompt_cache_key": "seahorse",
+		})
+		if err != nil {
+			return "", err
+		}
+		return resp.Content, nil
+	}
+}
+
 // providerToCompleteFn wraps providers.LLMProvider as a seahorse.CompleteFn.
 func providerToCompleteFn(provider providers.LLMProvider, model string) seahorse.CompleteFn {
 	return func(ctx context.Context, prompt string, opts seahorse.CompleteOptions) (string, error) {
@@ -136,11 +155,17 @@ func (m *seahorseContextManager) Compact(ctx context.Context, req *CompactReques
 		return err
 	}
 
-	_, err := m.engine.Compact(ctx, req.SessionKey, seahorse.CompactInput{
-		Force:  req.Reason == ContextCompressReasonRetry,
+	result, err := m.engine.Compact(ctx, req.SessionKey, seahorse.CompactInput{
+		Force:  req.Reason == ContextCompressReasonRetry || req.Reason == ContextCompressReasonManual,
 		Budget: &req.Budget,
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	if req.Reason == ContextCompressReasonManual && (result == nil || len(result.SummariesCreated) == 0) {
+		return ErrNothingToSummarize
+	}
+	return nil
 }
 
 // Ingest records a message into seahorse SQLite.
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/tokenizer"
 )
 
 // computeContextUsage estimates current context window consumption for the
@@ -25,10 +26,7 @@ func computeContextUsage(agent *AgentInstance, sessionKey string) *bus.ContextUs
 
 	// History tokens
 	history := agent.Sessions.GetHistory(sessionKey)
-	historyTokens := 0
-	for _, m := range history {
-		historyTokens += EstimateMessageTokens(m)
-	}
+	historyTokens := agent.CountTokens(history)
 
 	// System message tokens: uses EstimateSystemTokens which mirrors
 	// the full system message composition in BuildMessages (static prompt,
@@ -45,7 +43,7 @@ func computeContextUsage(agent *AgentInstance, sessionKey string) *bus.ContextUs
 	// Tool definition tokens
 	toolTokens := 0
 	if agent.Tools != nil {
-		toolTokens = EstimateToolDefsTokens(agent.Tools.ToProviderDefs())
+		toolTokens = tokenizer.CountToolDefsTokens(agent.Tokenizer(), agent.Tools.ToProviderDefs())
 	}
 
 	// Used = history + system (includes summary) + tools
@@ -0,0 +1,87 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+
+package agent
+
+import (
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const continueResponsePrompt = "Your reply was cut off by the output token limit. " +
+	"Continue exactly where it stopped, without repeating anything and without any preamble."
+
+// stoppedAtMaxTokens reports whether finishReason says the model ran out of
+// output tokens. Providers report "length", or "truncated" once normalized.
+func stoppedAtMaxTokens(finishReason string) bool {
+	switch finishReason {
+	case "length", "truncated", "max_tokens":
+		return true
+	}
+	return false
+}
+
+// continueTruncatedResponse extends a text reply that stopped at max_tokens.
+// The reply so far is sent back as an assistant message with a request to
+// go on, and each continuation is appended to it, up to maxContinuations
+// follow-up calls. The returned response has the stitched content, the
+// finish_reason of the last call and the usage of all of them.
+//
+// Replies with tool calls are left alone, and so is a continuation's tool
+// call: only text is stitched. A failed continuation ends the loop and the
+// reply gathered so far is kept.
+func continueTruncatedResponse(
+	resp *providers.LLMResponse,
+	messages []providers.Message,
+	toolDefs []providers.ToolDefinition,
+	maxContinuations int,
+	call func([]providers.Message, []providers.ToolDefinition) (*providers.LLMResponse, error),
+	logFields map[string]any,
+) *providers.LLMResponse {
+	if resp == nil || len(resp.ToolCalls) > 0 || resp.Content == "" || !stoppedAtMaxTokens(resp.FinishReason) {
+		return resp
+	}
+
+	stitched := *resp
+	if resp.Usage != nil {
+		usage := *resp.Usage
+		stitched.Usage = &usage
+	}
+	for n := 1; n <= maxContinuations && stoppedAtMaxTokens(stitched.FinishReason); n++ {
+		callMessages := append(append([]providers.Message(nil), messages...),
+			providers.Message{Role: "assistant", Content: stitched.Content},
+			providers.Message{Role: "user", Content: continueResponsePrompt},
+		)
+		next, err := call(callMessages, toolDefs)
+		fields := map[string]any{"continuation": n}
+		for k, v := range logFields {
+			fields[k] = v
+		}
+		if err != nil || next == nil {
+			if err != nil {
+				fields["error"] = err.Error()
+			}
+			logger.WarnCF("agent", "Continuing a reply cut off at max_tokens failed; keeping the partial reply", fields)
+			break
+		}
+		stitched.Content += next.Content
+		stitched.FinishReason = next.FinishReason
+		if next.Usage != nil {
+			if stitched.Usage == nil {
+				stitched.Usage = &providers.UsageInfo{}
+			}
+			stitched.Usage.PromptTokens += next.Usage.PromptTokens
+			stitched.Usage.CompletionTokens += next.Usage.CompletionTokens
+			stitched.Usage.TotalTokens += next.Usage.TotalTokens
+			stitched.Usage.CacheCreationTokens += next.Usage.CacheCreationTokens
+			stitched.Usage.CacheReadTokens += next.Usage.CacheReadTokens
+			stitched.Usage.ReasoningTokens += next.Usage.ReasoningTokens
+		}
+		fields["content_chars"] = len(stitched.Content)
+		fields["finish_reason"] = next.FinishReason
+		logger.InfoCF("agent", "Continued a reply cut off at max_tokens", fields)
+		if next.Content == "" {
+			break
+		}
+	}
+	return &stitched
+}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// continuationProvider answers with responses in order and records the
+// messages of each call.
+type continuationProvider struct {
+	mu        sync.Mutex
+	responses []*providers.LLMResponse
+	errs      []error
+	calls     [][]providers.Message
+}
+
+func (p *continuationProvider) Chat(
+	_ context.Context,
+	messages []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	idx := len(p.calls)
+	p.calls = append(p.calls, append([]providers.Message(nil), messages...))
+	if idx < len(p.errs) && p.errs[idx] != nil {
+		return nil, p.errs[idx]
+	}
+	if idx < len(p.responses) {
+		return p.responses[idx], nil
+	}
+	return &providers.LLMResponse{Content: "ok", FinishReason: "stop"}, nil
+}
+
+func (p *continuationProvider) GetDefaultModel() string {
+	return "test-model"
+}
+
+func TestContinueTruncatedResponse_StitchesContinuations(t *testing.T) {
+	provider := &continuationProvider{responses: []*providers.LLMResponse{
+		{Content: "Step one, step tw", FinishReason: "truncated", Usage: &providers.UsageInfo{CompletionTokens: 5}},
+		{Content: "o, step three.", FinishReason: "stop", Usage: &providers.UsageInfo{CompletionTokens: 4}},
+	}}
+	al := newPeerPreferencesLoop(t, provider)
+
+	if got := sendAs(t, al, "alice", "list the steps"); got != "Step one, step two, step three." {
+		t.Fatalf("reply = %q, want the stitched reply", got)
+	}
+	if len(provider.calls) != 2 {
+		t.Fatalf("LLM calls = %d, want 2", len(provider.calls))
+	}
+	continuation := provider.calls[1]
+	n := len(continuation)
+	if n < 2 ||
+		continuation[n-2].Role != "assistant" || continuation[n-2].Content != "Step one, step tw" ||
+		continuation[n-1].Role != "user" || continuation[n-1].Content != continueResponsePrompt {
+		t.Fatalf("continuation request should end with the partial reply and the continue prompt, got %+v",
+			continuation[max(n-2, 0):])
+	}
+}
+
+func TestContinueTruncatedResponse_StopsAtCap(t *testing.T) {
+	resp := &providers.LLMResponse{Content: "a", FinishReason: "length", Usage: &providers.UsageInfo{TotalTokens: 1}}
+	calls := 0
+	call := func([]providers.Message, []providers.ToolDefinition) (*providers.LLMResponse, error) {
+		calls++
+		return &providers.LLMResponse{
+			Content:      "b",
+			FinishReason: "length",
+			Usage:        &providers.UsageInfo{TotalTokens: 2},
+		}, nil
+	}
+
+	got := continueTruncatedResponse(resp, nil, nil, 2, call, nil)
+	if calls != 2 || got.Content != "abb" || got.FinishReason != "length" {
+		t.Fatalf("calls = %d, response = %+v; want 2 continuations ending in length", calls, got)
+	}
+	if got.Usage.TotalTokens != 5 {
+		t.Fatalf("usage total = %d, want the sum over all calls", got.Usage.TotalTokens)
+	}
+	if resp.Content != "a" || resp.Usage.TotalTokens != 1 {
+		t.Fatal("the original response was modified")
+	}
+}
+
+func TestContinueTruncatedResponse_LeavesOtherResponsesAlone(t *testing.T) {
+	call := func([]providers.Message, []providers.ToolDefinition) (*providers.LLMResponse, error) {
+		t.Fatal("no continuation expected")
+		return nil, nil
+	}
+	for _, resp := range []*providers.LLMResponse{
+		{Content: "done", FinishReason: "stop"},
+		{Content: "", FinishReason: "length"},
+		{Content: "calling", FinishReason: "length", ToolCalls: []providers.ToolCall{{Name: "read_file"}}},
+	} {
+		if got := continueTruncatedResponse(resp, nil, nil, 2, call, nil); got != resp {
+			t.Fatalf("response %+v was changed to %+v", resp, got)
+		}
+	}
+	resp := &providers.LLMResponse{Content: "cut", FinishReason: "length"}
+	if got := continueTruncatedResponse(resp, nil, nil, 0, call, nil); got.Content != "cut" {
+		t.Fatalf("max_continuations 0 should disable continuation, got %q", got.Content)
+	}
+}
+
+func TestContinueTruncatedResponse_KeepsPartialOnError(t *testing.T) {
+	provider := &continuationProvider{
+		responses: []*providers.LLMResponse{{Content: "The answer is", FinishReason: "length"}},
+		errs:      []error{nil, errors.New("bad request")},
+	}
+	al := newPeerPreferencesLoop(t, provider)
+
+	if got := sendAs(t, al, "alice", "what is it?"); got != "The answer is" {
+		t.Fatalf("reply = %q, want the partial reply", got)
+	}
+}
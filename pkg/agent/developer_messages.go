@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// systemPartSeparator joins system prompt blocks into the flat Content that
+// adapters without SystemParts support read.
+const systemPartSeparator = "\n\n---\n\n"
+
+// isTurnInstructionPart reports whether a system block changes from turn to
+// turn (runtime context, the compression summary, turn-layer overlays) rather
+// than describing the persistent persona.
+func isTurnInstructionPart(part providers.ContentBlock) bool {
+	switch PromptSlot(part.PromptSlot) {
+	case PromptSlotRuntime, PromptSlotSummary:
+		return true
+	}
+	return PromptLayer(part.PromptLayer) == PromptLayerTurn
+}
+
+// supportsDeveloperRole reports whether provider accepts a separate
+// "developer" message next to the system prompt.
+func supportsDeveloperRole(provider providers.LLMProvider) bool {
+	dc, ok := provider.(providers.DeveloperRoleCapable)
+	return ok && dc.SupportsDeveloperRole()
+}
+
+// messagesForProvider shapes the built messages for one provider call.
+// BuildMessages always produces a single system message, which every adapter
+// understands. Providers that support the developer role get the per-turn
+// blocks split out of it into a developer message placed just before the
+// current user message, so the system prompt and history stay a stable,
+// cacheable prefix. Everything else receives the messages unchanged.
+func messagesForProvider(provider providers.LLMProvider, messages []providers.Message) []providers.Message {
+	if !supportsDeveloperRole(provider) {
+		return messages
+	}
+	sysIdx := -1
+	for i, msg := range messages {
+		if msg.Role == "system" {
+			sysIdx = i
+			break
+		}
+	}
+	if sysIdx < 0 {
+		return messages
+	}
+	system := messages[sysIdx]
+	if len(system.SystemParts) == 0 || system.Content != joinSystemParts(system.SystemParts) {
+		// A hook rewrote the prompt text; the blocks no longer describe it.
+		return messages
+	}
+
+	var persona, turn []providers.ContentBlock
+	for _, part := range system.SystemParts {
+		if isTurnInstructionPart(part) {
+			turn = append(turn, part)
+		} else {
+			persona = append(persona, part)
+		}
+	}
+	if len(turn) == 0 || len(persona) == 0 {
+		return messages
+	}
+
+	system.SystemParts = persona
+	system.Content = joinSystemParts(persona)
+	developer := providers.Message{
+		Role:    "developer",
+		Content: joinSystemParts(turn),
+	}
+
+	insertAt := sysIdx + 1
+	for i := len(messages) - 1; i > sysIdx; i-- {
+		if messages[i].Role == "user" && messages[i].ToolCallID == "" {
+			insertAt = i
+			break
+		}
+	}
+
+	shaped := make([]providers.Message, 0, len(messages)+1)
+	shaped = append(shaped, messages[:sysIdx]...)
+	shaped = append(shaped, system)
+	shaped = append(shaped, messages[sysIdx+1:insertAt]...)
+	shaped = append(shaped, developer)
+	return append(shaped, messages[insertAt:]...)
+}
+
+func joinSystemParts(parts []providers.ContentBlock) string {
+	texts := make([]string, len(parts))
+	for i, part := range parts {
+		texts[i] = part.Text
+	}
+	return strings.Join(texts, systemPartSeparator)
+}
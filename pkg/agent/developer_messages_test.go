@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type developerRoleProvider struct {
+	mockProvider
+	supported bool
+}
+
+func (p *developerRoleProvider) SupportsDeveloperRole() bool { return p.supported }
+
+func TestMessagesForProvider_SplitsTurnInstructions(t *testing.T) {
+	cb := NewContextBuilder(t.TempDir())
+	history := []providers.Message{
+		{Role: "user", Content: "earlier question"},
+		{Role: "assistant", Content: "earlier answer"},
+	}
+	built := cb.BuildMessages(history, "user prefers short answers", "hello", nil, "telegram", "1", "alice", "")
+
+	collapsed := messagesForProvider(&developerRoleProvider{}, built)
+	if len(collapsed) != len(built) || collapsed[0].Content != built[0].Content {
+		t.Fatalf("provider without developer role should get the messages unchanged")
+	}
+
+	shaped := messagesForProvider(&developerRoleProvider{supported: true}, built)
+	if len(shaped) != len(built)+1 {
+		t.Fatalf("len(shaped) = %d, want %d", len(shaped), len(built)+1)
+	}
+	system, developer := shaped[0], shaped[len(shaped)-2]
+	if system.Role != "system" || developer.Role != "developer" {
+		t.Fatalf("roles = %q ... %q, want system ... developer", system.Role, developer.Role)
+	}
+	if shaped[len(shaped)-1].Content != "hello" || shaped[1].Content != "earlier question" {
+		t.Errorf("history order changed: %+v", shaped)
+	}
+	if !strings.Contains(developer.Content, "user prefers short answers") {
+		t.Errorf("developer message should carry the summary, got %q", developer.Content)
+	}
+	if strings.Contains(system.Content, "CONTEXT_SUMMARY") {
+		t.Error("system message should no longer carry the summary")
+	}
+	for _, part := range system.SystemParts {
+		if isTurnInstructionPart(part) {
+			t.Errorf("system message kept turn block %s/%s", part.PromptLayer, part.PromptSlot)
+		}
+	}
+	if got := system.Content + systemPartSeparator + developer.Content; got != built[0].Content {
+		t.Error("split messages should carry the same text as the collapsed prompt")
+	}
+	if built[0].Content == system.Content {
+		t.Error("messagesForProvider must not modify its input")
+	}
+}
+
+func TestMessagesForProvider_LeavesRewrittenPromptAlone(t *testing.T) {
+	cb := NewContextBuilder(t.TempDir())
+	built := cb.BuildMessages(nil, "", "hello", nil, "telegram", "1", "alice", "")
+	built[0].Content += "\n\nextra instructions from a hook"
+
+	shaped := messagesForProvider(&developerRoleProvider{supported: true}, built)
+	if len(shaped) != len(built) || shaped[0].Content != built[0].Content {
+		t.Error("a system prompt that no longer matches its blocks should be sent as-is")
+	}
+}
@@ -1,6 +1,10 @@
 package agent
 
-import "time"
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
 
 // TurnEndStatus describes the terminal state of a turn.
 type TurnEndStatus string
@@ -53,6 +57,8 @@ type TurnEndPayload struct {
 	SkillContextSnapshots []SkillContextSnapshot
 	ToolKinds             []string
 	ToolExecutions        []ToolExecutionRecord
+	Usage                 providers.UsageInfo // summed over the turn's LLM calls
+	Error                 string              // set when Status is TurnEndStatusError
 }
 
 // LLMRequestPayload describes an outbound LLM request.
@@ -96,6 +102,8 @@ const (
 	ContextCompressReasonRetry ContextCompressReason = "llm_retry"
 	// ContextCompressReasonSummarize indicates post-turn async summarization.
 	ContextCompressReasonSummarize ContextCompressReason = "summarize"
+	// ContextCompressReasonManual indicates a user-requested summarization (/summarize).
+	ContextCompressReasonManual ContextCompressReason = "manual"
 )
 
 // ContextCompressPayload describes a forced history compression.
@@ -111,6 +119,7 @@ type SessionSummarizePayload struct {
 	KeptMessages       int
 	SummaryLen         int
 	OmittedOversized   bool
+	Model              string // model configured for summarization
 }
 
 // ToolExecStartPayload describes a tool execution request.
@@ -560,7 +560,7 @@ func TestAgentLoop_EmitsSessionSummarizeEvent(t *testing.T) {
 	defer closeRuntimeEvents()
 
 	lcm := &legacyContextManager{al: al}
-	lcm.summarizeSession(defaultAgent, "session-1")
+	lcm.summarizeSession(defaultAgent, "session-1", summaryKeepMessages)
 
 	events := collectRuntimeEventStream(runtimeCh)
 	summaryEvt, ok := findRuntimeEvent(events, runtimeevents.KindAgentSessionSummarize)
@@ -576,6 +576,47 @@ func TestAgentLoop_EmitsSessionSummarizeEvent(t *testing.T) {
 	}
 }
 
+func TestSummarizeSession_KeepsHistoryWindow(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:          t.TempDir(),
+				ModelName:          "test-model",
+				MaxTokens:          4096,
+				ContextWindow:      8000,
+				MaxHistoryMessages: 4,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &simpleMockProvider{response: "summary text"})
+	defaultAgent := al.registry.GetDefaultAgent()
+
+	history := []providers.Message{
+		{Role: "user", Content: "Question one"},
+		{Role: "assistant", Content: "Answer one"},
+		{Role: "user", Content: "Question two"},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "tc1", Name: "read_file"}}},
+		{Role: "tool", Content: "file", ToolCallID: "tc1"},
+		{Role: "assistant", Content: "Answer two"},
+		{Role: "user", Content: "Question three"},
+		{Role: "assistant", Content: "Answer three"},
+	}
+	defaultAgent.Sessions.SetHistory("session-1", history)
+
+	lcm := &legacyContextManager{al: al}
+	if !lcm.summarizeSession(defaultAgent, "session-1", defaultAgent.MaxHistoryMessages/2) {
+		t.Fatal("expected history beyond the window to be summarized")
+	}
+
+	kept := defaultAgent.Sessions.GetHistory("session-1")
+	if len(kept) != 2 || kept[0].Content != "Question three" {
+		t.Fatalf("kept history = %+v, want only the last turn", kept)
+	}
+	if got := defaultAgent.Sessions.GetSummary("session-1"); got != "summary text" {
+		t.Fatalf("summary = %q, want %q", got, "summary text")
+	}
+}
+
 func TestAgentLoop_EmitsFollowUpQueuedEvent(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "agent-eventbus-followup-*")
 	if err != nil {
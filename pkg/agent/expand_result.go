@@ -0,0 +1,57 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+
+package agent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+const (
+	// expandResultTrigger starts a reply that shows truncated tool output,
+	// e.g. ":expand r1a2b3c4" or ":expand r1a2b3c4 16000" for the next page.
+	expandResultTrigger = ":expand"
+	// expandResultPage is how many bytes one :expand reply shows.
+	expandResultPage = 16000
+)
+
+// handleExpandResult answers ":expand <handle> [offset]" with the full output
+// kept for a truncated tool result, without starting a turn.
+func (al *AgentLoop) handleExpandResult(content string) (string, bool) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], expandResultTrigger) {
+		return "", false
+	}
+	results := tools.DefaultFullResults()
+	if results == nil {
+		return "Full tool results are not kept; enable tools.full_results to use " + expandResultTrigger + ".", true
+	}
+	if len(fields) < 2 || len(fields) > 3 {
+		return fmt.Sprintf("Usage: %s <handle> [offset]", expandResultTrigger), true
+	}
+	offset := 0
+	if len(fields) == 3 {
+		n, err := strconv.Atoi(fields[2])
+		if err != nil || n < 0 {
+			return fmt.Sprintf("%q is not a byte offset.", fields[2]), true
+		}
+		offset = n
+	}
+
+	text, ok := results.Get(fields[1])
+	if !ok {
+		return fmt.Sprintf("No result with handle %s; it may have expired.", fields[1]), true
+	}
+	page, start, end := tools.FullResultPage(text, offset, expandResultPage)
+	if start == 0 && end == len(text) {
+		return page, true
+	}
+	note := fmt.Sprintf("[bytes %d-%d of %d", start, end, len(text))
+	if end < len(text) {
+		note += fmt.Sprintf("; %s %s %d for more", expandResultTrigger, fields[1], end)
+	}
+	return page + "\n\n" + note + "]", true
+}
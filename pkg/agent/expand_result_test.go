@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+func TestProcessMessage_ExpandShowsKeptResult(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+	cfg.Agents.Defaults.ModelName = "test-model"
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	t.Cleanup(func() { tools.SetDefaultFullResults(nil) })
+
+	if _, ok := al.registry.GetDefaultAgent().Tools.Get("get_full_result"); !ok {
+		t.Fatal("get_full_result should be registered by default")
+	}
+	results := tools.DefaultFullResults()
+	full := strings.Repeat("x", expandResultPage) + "tail"
+	handle := results.Put(full)
+
+	process := func(content string) string {
+		t.Helper()
+		response, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+			Channel:  "telegram",
+			ChatID:   "1",
+			SenderID: "alice",
+			Content:  content,
+		}))
+		if err != nil {
+			t.Fatalf("processMessage(%q) error = %v", content, err)
+		}
+		return response
+	}
+
+	first := process(":expand " + handle)
+	want := ":expand " + handle + " 16000 for more"
+	if !strings.HasPrefix(first, strings.Repeat("x", expandResultPage)) || !strings.Contains(first, want) {
+		t.Fatalf(":expand response tail = %q, want %q", first[expandResultPage:], want)
+	}
+	if got := process(":expand " + handle + " 16000"); !strings.HasPrefix(got, "tail") {
+		t.Errorf("second page = %q, want the tail", got)
+	}
+	if got := process(":expand rmissing"); !strings.Contains(got, "expired") {
+		t.Errorf("unknown handle response = %q", got)
+	}
+	if provider.lastMessages != nil {
+		t.Error(":expand must not start a turn")
+	}
+}
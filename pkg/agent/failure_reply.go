@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// defaultFailureMessage is the reply when every model candidate fails and
+// agents.defaults.failure_message is unset.
+const defaultFailureMessage = "Sorry, I couldn't answer that: {reason}. {hint}"
+
+// llmCallError is returned by a turn whose LLM call failed after retries and
+// every fallback candidate. Chat users get the failure message instead of the
+// raw provider error.
+type llmCallError struct {
+	err error
+}
+
+func (e *llmCallError) Error() string {
+	return "LLM call failed after retries: " + e.err.Error()
+}
+
+func (e *llmCallError) Unwrap() error {
+	return e.err
+}
+
+// llmFailureReason classifies a failed LLM call. Transient failures (rate
+// limits, outages, timeouts) are worth retrying; the others need an operator
+// to fix the provider setup, or the user to start over.
+func llmFailureReason(err error) (reason providers.FailoverReason, transient bool) {
+	var exhausted *providers.FallbackExhaustedError
+	if errors.As(err, &exhausted) && len(exhausted.Attempts) > 0 {
+		// One candidate that failed transiently, or sat out a cooldown, is
+		// enough to make trying again worthwhile.
+		reason = providers.FailoverUnknown
+		for _, attempt := range exhausted.Attempts {
+			if attempt.Skipped {
+				return providers.FailoverRateLimit, true
+			}
+			if isTransientFailover(attempt.Reason) {
+				return attempt.Reason, true
+			}
+			if reason == providers.FailoverUnknown && attempt.Reason != "" {
+				reason = attempt.Reason
+			}
+		}
+		return reason, isTransientFailover(reason)
+	}
+
+	var failover *providers.FailoverError
+	if errors.As(err, &failover) {
+		return failover.Reason, isTransientFailover(failover.Reason)
+	}
+	if classified := providers.ClassifyError(err, "", ""); classified != nil {
+		return classified.Reason, isTransientFailover(classified.Reason)
+	}
+	switch kind, _ := transientLLMRetryReason(err); kind {
+	case "network":
+		return providers.FailoverNetwork, true
+	case "timeout":
+		return providers.FailoverTimeout, true
+	default:
+		return providers.FailoverUnknown, true
+	}
+}
+
+func isTransientFailover(reason providers.FailoverReason) bool {
+	switch reason {
+	case providers.FailoverAuth, providers.FailoverBilling, providers.FailoverFormat,
+		providers.FailoverContextOverflow, providers.FailoverContentPolicy:
+		return false
+	default:
+		return true
+	}
+}
+
+// failureReplyParts returns the {reason} and {hint} of the failure message.
+func failureReplyParts(reason providers.FailoverReason, transient bool) (string, string) {
+	var text string
+	switch reason {
+	case providers.FailoverRateLimit:
+		text = "the model provider is rate limiting requests"
+	case providers.FailoverOverloaded:
+		text = "the model provider is overloaded"
+	case providers.FailoverTimeout:
+		text = "the model provider took too long to respond"
+	case providers.FailoverNetwork:
+		text = "I couldn't reach the model provider"
+	case providers.FailoverAuth:
+		text = "the model provider rejected my credentials"
+	case providers.FailoverBilling:
+		text = "the model provider account is out of credit"
+	case providers.FailoverFormat:
+		text = "the model provider rejected the request"
+	case providers.FailoverContextOverflow:
+		return "this conversation is too long for the model", "Send /clear to start a new one."
+	default:
+		text = "the model provider returned an error"
+	}
+	if transient {
+		return text, "Please try again shortly."
+	}
+	return text, "The operator needs to check the model provider settings."
+}
+
+// failureReply builds the reply for a turn whose LLM call failed from the
+// configured failure message, and logs the underlying error for operators.
+func (al *AgentLoop) failureReply(err error, channel, chatID string) string {
+	reason, transient := llmFailureReason(err)
+	fields := map[string]any{
+		"channel":   channel,
+		"chat_id":   chatID,
+		"reason":    string(reason),
+		"transient": transient,
+		"error":     err.Error(),
+	}
+	if transient {
+		logger.WarnCF("agent", "All models failed, replying with failure message", fields)
+	} else {
+		logger.ErrorCF("agent", "All models failed and the provider needs operator attention", fields)
+	}
+
+	template := defaultFailureMessage
+	if cfg := al.GetConfig(); cfg != nil && strings.TrimSpace(cfg.Agents.Defaults.FailureMessage) != "" {
+		template = cfg.Agents.Defaults.FailureMessage
+	}
+	text, hint := failureReplyParts(reason, transient)
+	return strings.NewReplacer("{reason}", text, "{hint}", hint).Replace(template)
+}
@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestLLMFailureReason(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantReason    providers.FailoverReason
+		wantTransient bool
+	}{
+		{
+			name:          "rate limit",
+			err:           &providers.FailoverError{Reason: providers.FailoverRateLimit, Wrapped: errors.New("429")},
+			wantReason:    providers.FailoverRateLimit,
+			wantTransient: true,
+		},
+		{
+			name:       "auth",
+			err:        fmt.Errorf("wrapped: %w", &providers.FailoverError{Reason: providers.FailoverAuth}),
+			wantReason: providers.FailoverAuth,
+		},
+		{
+			name: "exhausted with one transient candidate",
+			err: &providers.FallbackExhaustedError{Attempts: []providers.FallbackAttempt{
+				{Provider: "openai", Reason: providers.FailoverAuth},
+				{Provider: "anthropic", Reason: providers.FailoverOverloaded},
+			}},
+			wantReason:    providers.FailoverOverloaded,
+			wantTransient: true,
+		},
+		{
+			name: "exhausted with only permanent candidates",
+			err: &providers.FallbackExhaustedError{Attempts: []providers.FallbackAttempt{
+				{Provider: "openai", Reason: providers.FailoverBilling},
+				{Provider: "anthropic", Reason: providers.FailoverAuth},
+			}},
+			wantReason: providers.FailoverBilling,
+		},
+		{
+			name: "exhausted with a candidate in cooldown",
+			err: &providers.FallbackExhaustedError{Attempts: []providers.FallbackAttempt{
+				{Provider: "openai", Reason: providers.FailoverAuth},
+				{Provider: "anthropic", Skipped: true},
+			}},
+			wantReason:    providers.FailoverRateLimit,
+			wantTransient: true,
+		},
+		{
+			name:          "unclassified",
+			err:           errors.New("something odd"),
+			wantReason:    providers.FailoverUnknown,
+			wantTransient: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, transient := llmFailureReason(tt.err)
+			if reason != tt.wantReason || transient != tt.wantTransient {
+				t.Fatalf("llmFailureReason() = (%s, %v), want (%s, %v)",
+					reason, transient, tt.wantReason, tt.wantTransient)
+			}
+		})
+	}
+}
+
+func TestFailureReply_UsesTemplateAndHidesProviderError(t *testing.T) {
+	cfg := config.DefaultConfig()
+	al := &AgentLoop{cfg: cfg}
+
+	transient := &llmCallError{err: &providers.FailoverError{
+		Reason:  providers.FailoverRateLimit,
+		Wrapped: errors.New("429 Too Many Requests: org-abc123"),
+	}}
+	want := "Sorry, I couldn't answer that: the model provider is rate limiting requests. Please try again shortly."
+	if got := al.failureReply(transient, "telegram", "chat1"); got != want {
+		t.Fatalf("failureReply() = %q, want %q", got, want)
+	}
+
+	permanent := &llmCallError{err: &providers.FailoverError{Reason: providers.FailoverAuth}}
+	want = "Sorry, I couldn't answer that: the model provider rejected my credentials. " +
+		"The operator needs to check the model provider settings."
+	if got := al.failureReply(permanent, "telegram", "chat1"); got != want {
+		t.Fatalf("failureReply() = %q, want %q", got, want)
+	}
+
+	cfg.Agents.Defaults.FailureMessage = "The assistant is offline ({reason}). {hint}"
+	want = "The assistant is offline (the model provider rejected my credentials). " +
+		"The operator needs to check the model provider settings."
+	if got := al.failureReply(permanent, "telegram", "chat1"); got != want {
+		t.Fatalf("failureReply() = %q, want %q", got, want)
+	}
+
+	cfg.Agents.Defaults.FailureMessage = "Back soon."
+	if got := al.failureReply(transient, "telegram", "chat1"); got != "Back soon." {
+		t.Fatalf("failureReply() = %q, want the fixed message", got)
+	}
+}
@@ -0,0 +1,91 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// forwardTrigger forwards the conversation's last message to another chat,
+// e.g. ":forward slack C0123456".
+const forwardTrigger = ":forward"
+
+// messageForwarder is implemented by the channel manager.
+type messageForwarder interface {
+	ForwardMessage(ctx context.Context, channelName, chatID, content string) error
+}
+
+// handleForward answers ":forward <channel> <chatID>" by sending the latest
+// response (or message) of the current session to that chat, without
+// starting a turn.
+func (al *AgentLoop) handleForward(
+	ctx context.Context,
+	msg bus.InboundMessage,
+	agent *AgentInstance,
+	sessionKey string,
+) (string, bool) {
+	fields := strings.Fields(msg.Content)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], forwardTrigger) {
+		return "", false
+	}
+	if len(fields) != 3 {
+		return fmt.Sprintf("Usage: %s <channel> <chat_id>", forwardTrigger), true
+	}
+	forwarder, ok := al.channelManager.(messageForwarder)
+	if !ok {
+		return "Forwarding is not available here.", true
+	}
+	channelName, chatID := strings.ToLower(fields[1]), fields[2]
+
+	last := lastForwardableMessage(agent.Sessions.GetHistory(sessionKey))
+	if last == "" {
+		return "There is nothing to forward yet.", true
+	}
+	content := fmt.Sprintf("Forwarded from %s:\n\n%s", msg.Channel, last)
+
+	if err := forwarder.ForwardMessage(ctx, channelName, chatID, content); err != nil {
+		logger.WarnCF("agent", "Forward failed", map[string]any{
+			"from":    msg.Channel + ":" + msg.ChatID,
+			"to":      channelName + ":" + chatID,
+			"error":   err.Error(),
+			"session": sessionKey,
+		})
+		if errors.Is(err, channels.ErrForwardNotAllowed) {
+			return fmt.Sprintf("Chat %s is not on the %s allow list.", chatID, channelName), true
+		}
+		return "Could not forward: " + err.Error(), true
+	}
+	return fmt.Sprintf("Forwarded to %s:%s.", channelName, chatID), true
+}
+
+// lastForwardableMessage returns the newest assistant response in history,
+// or the newest user message when the agent has not answered yet.
+func lastForwardableMessage(history []providers.Message) string {
+	user := ""
+	for i := len(history) - 1; i >= 0; i-- {
+		m := history[i]
+		content := strings.TrimSpace(m.Content)
+		if content == "" {
+			continue
+		}
+		switch m.Role {
+		case "assistant":
+			if len(m.ToolCalls) == 0 {
+				return content
+			}
+		case "user":
+			if user == "" {
+				user = content
+			}
+		}
+	}
+	return user
+}
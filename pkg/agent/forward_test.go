@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type forwardingChannelManager struct {
+	recordingChannelManager
+	forwarded []string
+}
+
+func (m *forwardingChannelManager) ForwardMessage(ctx context.Context, channelName, chatID, content string) error {
+	if chatID == "blocked" {
+		return fmt.Errorf("%w: %s:%s", channels.ErrForwardNotAllowed, channelName, chatID)
+	}
+	m.forwarded = append(m.forwarded, channelName+":"+chatID+"|"+content)
+	return nil
+}
+
+func TestHandleForward_SendsLastResponseToTargetChat(t *testing.T) {
+	al := newPeerPreferencesLoop(t, &recordingProvider{})
+	cm := &forwardingChannelManager{}
+	al.channelManager = cm
+
+	if got := sendAs(t, al, "alice", ":forward slack C-ops"); got != "There is nothing to forward yet." {
+		t.Fatalf("forward on empty session = %q", got)
+	}
+
+	sendAs(t, al, "alice", "summarize the incident")
+	if got := sendAs(t, al, "alice", ":forward Slack C-ops"); got != "Forwarded to slack:C-ops." {
+		t.Fatalf("forward reply = %q", got)
+	}
+	if len(cm.forwarded) != 1 {
+		t.Fatalf("forwarded %d messages, want 1", len(cm.forwarded))
+	}
+	if want := "slack:C-ops|Forwarded from telegram:\n\nMock response"; cm.forwarded[0] != want {
+		t.Fatalf("forwarded %q, want %q", cm.forwarded[0], want)
+	}
+
+	if got := sendAs(t, al, "alice", ":forward slack blocked"); !strings.Contains(got, "allow list") {
+		t.Fatalf("blocked forward reply = %q", got)
+	}
+	if got := sendAs(t, al, "alice", ":forward slack"); !strings.HasPrefix(got, "Usage:") {
+		t.Fatalf("usage reply = %q", got)
+	}
+}
+
+func TestLastForwardableMessage_PrefersFinalResponse(t *testing.T) {
+	history := []providers.Message{
+		{Role: "user", Content: "question"},
+		{Role: "assistant", Content: "answer"},
+		{Role: "user", Content: "follow-up"},
+		{Role: "assistant", Content: "checking", ToolCalls: []providers.ToolCall{{ID: "1"}}},
+		{Role: "tool", Content: "tool output"},
+	}
+	if got := lastForwardableMessage(history); got != "answer" {
+		t.Fatalf("lastForwardableMessage() = %q, want answer", got)
+	}
+	if got := lastForwardableMessage(history[:1]); got != "question" {
+		t.Fatalf("lastForwardableMessage() without a response = %q, want question", got)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/session"
+	"github.com/sipeed/picoclaw/pkg/tokenizer"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
@@ -31,19 +32,29 @@ type AgentInstance struct {
 	Temperature               float64
 	ThinkingLevel             ThinkingLevel
 	ThinkingLevelConfigured   bool
+	ModelProfiles             config.ModelProfiles
 	ContextWindow             int
 	SummarizeMessageThreshold int
 	SummarizeTokenPercent     int
+	MaxHistoryMessages        int
 	Provider                  providers.LLMProvider
 	Sessions                  session.SessionStore
 	ContextBuilder            *ContextBuilder
 	Tools                     *tools.ToolRegistry
 	Definition                AgentContextDefinition
 	Subagents                 *config.SubagentsConfig
+	SubagentManager           *tools.SubagentManager // nil unless spawn or spawn_status is enabled
 	SkillsFilter              []string
 	MCPServerAllowlist        map[string]struct{}
 	Candidates                []providers.FallbackCandidate
 	ImageCandidates           []providers.FallbackCandidate
+	// SummaryCandidates holds the resolved candidates for summary_model, used
+	// for history summarization instead of the primary model. Empty when unset.
+	SummaryCandidates []providers.FallbackCandidate
+	// AudioInput is set when the model that takes media turns (image_model,
+	// or the agent's model) accepts audio, so voice notes the transcriber did
+	// not turn into text are sent to it as audio.
+	AudioInput bool
 
 	// Router is non-nil when model routing is configured and the light model
 	// was successfully resolved. It scores each incoming message and decides
@@ -92,6 +103,10 @@ func NewAgentInstance(
 	agentMCPServerAllowlist := resolveAgentMCPServerAllowlist(definition)
 
 	toolsRegistry := tools.NewToolRegistry()
+	if agentCfg != nil && agentCfg.Tools != nil {
+		agentToolAllowlist = intersectToolAllowlists(agentToolAllowlist, agentCfg.Tools.Allow)
+		toolsRegistry.SetDenylist(agentCfg.Tools.Deny)
+	}
 	toolsRegistry.SetAllowlist(agentToolAllowlist)
 
 	if cfg.Tools.IsToolEnabled("read_file") {
@@ -125,9 +140,15 @@ func NewAgentInstance(
 	if cfg.Tools.IsToolEnabled("append_file") {
 		toolsRegistry.Register(tools.NewAppendFileTool(workspace, restrict, allowWritePaths))
 	}
+	if cfg.Tools.IsToolEnabled("git") {
+		toolsRegistry.Register(tools.NewGitTool(workspace, restrict, cfg.Tools.Git.AllowPush, cfg.Tools.Git.AllowReset))
+	}
+	if cfg.Tools.IsToolEnabled("memory") {
+		toolsRegistry.Register(tools.NewMemoryTool(workspace, cfg.Tools.Memory.MaxBytes))
+	}
 
 	sessionsDir := filepath.Join(workspace, "sessions")
-	sessions := initSessionStore(sessionsDir)
+	sessions := initSessionStore(sessionsDir, cfg.Agents.Defaults.SessionStore)
 
 	mcpDiscoveryActive := agentHasDiscoverableMCPServers(cfg, agentMCPServerAllowlist)
 	contextBuilder := NewContextBuilder(workspace).
@@ -135,7 +156,12 @@ func NewAgentInstance(
 			mcpDiscoveryActive && cfg.Tools.MCP.Discovery.UseBM25,
 			mcpDiscoveryActive && cfg.Tools.MCP.Discovery.UseRegex,
 		).
-		WithSplitOnMarker(cfg.Agents.Defaults.SplitOnMarker)
+		WithSplitOnMarker(cfg.Agents.Defaults.SplitOnMarker).
+		WithMemoryKeys(cfg.Tools.IsToolEnabled("memory") && cfg.Tools.Memory.InjectKeys).
+		WithLocale(cfg.Agents.Defaults.Timezone, cfg.Agents.Defaults.Locale).
+		WithContextProviders(cfg.Agents.Defaults.ContextProviders).
+		WithChannelPersonas(cfg.Channels).
+		WithIdentity(cfg.Agents.Defaults.Name, cfg.Agents.Defaults.Persona)
 
 	agentID := routing.DefaultAgentID
 	agentName := ""
@@ -212,6 +238,18 @@ func NewAgentInstance(
 		imageNames := append([]string{defaults.ImageModel}, defaults.ImageModelFallbacks...)
 		populateCandidateProvidersFromNames(cfg, workspace, imageNames, candidateProviders)
 	}
+	mediaModel := model
+	if strings.TrimSpace(defaults.ImageModel) != "" {
+		mediaModel = defaults.ImageModel
+	}
+	var audioInput bool
+	if mc, err := cfg.GetModelConfig(mediaModel); err == nil {
+		audioInput = mc.AudioInput
+	}
+	summaryCandidates := resolveModelCandidates(cfg, defaults.Provider, defaults.SummaryModel, nil)
+	if len(summaryCandidates) > 0 {
+		populateCandidateProvidersFromNames(cfg, workspace, []string{defaults.SummaryModel}, candidateProviders)
+	}
 
 	// Model routing setup: pre-resolve light model candidates at creation time
 	// to avoid repeated model_list lookups on every incoming message.
@@ -264,9 +302,11 @@ func NewAgentInstance(
 		Temperature:               temperature,
 		ThinkingLevel:             thinkingLevel,
 		ThinkingLevelConfigured:   thinkingLevelConfigured,
+		ModelProfiles:             defaults.ModelProfiles,
 		ContextWindow:             contextWindow,
 		SummarizeMessageThreshold: summarizeMessageThreshold,
 		SummarizeTokenPercent:     summarizeTokenPercent,
+		MaxHistoryMessages:        max(defaults.MaxHistoryMessages, 0),
 		Provider:                  provider,
 		Sessions:                  sessions,
 		ContextBuilder:            contextBuilder,
@@ -277,6 +317,8 @@ func NewAgentInstance(
 		MCPServerAllowlist:        agentMCPServerAllowlist,
 		Candidates:                candidates,
 		ImageCandidates:           imageCandidates,
+		SummaryCandidates:         summaryCandidates,
+		AudioInput:                audioInput,
 		Router:                    router,
 		LightCandidates:           lightCandidates,
 		LightProvider:             lightProvider,
@@ -420,6 +462,28 @@ func (a *AgentInstance) AllowsMCPServer(serverName string) bool {
 	return ok
 }
 
+// Tokenizer returns the token counter for the agent's active model. It is
+// resolved on each call so a /switch model takes effect immediately.
+func (a *AgentInstance) Tokenizer() tokenizer.Tokenizer {
+	if a == nil {
+		return tokenizer.Heuristic
+	}
+	if len(a.Candidates) > 0 && a.Candidates[0].Model != "" {
+		return tokenizer.ForModel(a.Candidates[0].Model)
+	}
+	return tokenizer.ForModel(a.Model)
+}
+
+// CountTokens counts the tokens messages occupy for the agent's active model.
+func (a *AgentInstance) CountTokens(messages []providers.Message) int {
+	tok := a.Tokenizer()
+	total := 0
+	for _, msg := range messages {
+		total += tokenizer.CountMessageTokens(tok, msg)
+	}
+	return total
+}
+
 func compilePatterns(patterns []string) []*regexp.Regexp {
 	compiled := make([]*regexp.Regexp, 0, len(patterns))
 	for _, p := range patterns {
@@ -470,7 +534,15 @@ func (a *AgentInstance) Close() error {
 // It uses the JSONL store by default and auto-migrates legacy JSON sessions.
 // Falls back to SessionManager if the JSONL store cannot be initialized or
 // if migration fails (which indicates the store cannot write reliably).
-func initSessionStore(dir string) session.SessionStore {
+// With backend "sqlite" sessions live in dir/sessions.db instead; existing
+// JSON and JSONL sessions are copied into it on startup.
+func initSessionStore(dir, backend string) session.SessionStore {
+	if strings.EqualFold(strings.TrimSpace(backend), "sqlite") {
+		if store := initSQLiteSessionStore(dir); store != nil {
+			return store
+		}
+	}
+
 	store, err := memory.NewJSONLStore(dir)
 	if err != nil {
 		logger.WarnCF("agent", "Memory JSONL store init failed; falling back to json sessions",
@@ -493,6 +565,48 @@ func initSessionStore(dir string) session.SessionStore {
 	return session.NewJSONLBackend(store)
 }
 
+// initSQLiteSessionStore opens the SQLite session store and imports
+// sessions left in the file-based formats. It returns nil when the store
+// cannot be used, so the caller falls back to the JSONL store.
+func initSQLiteSessionStore(dir string) session.SessionStore {
+	store, err := memory.NewSQLiteStore(filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		logger.WarnCF("agent", "SQLite session store init failed; falling back to jsonl sessions",
+			map[string]any{"error": err.Error()})
+		return nil
+	}
+
+	ctx := context.Background()
+	migrated, err := memory.MigrateFromJSON(ctx, dir, store)
+	if err == nil {
+		var n int
+		n, err = migrateJSONLSessions(ctx, dir, store)
+		migrated += n
+	}
+	if err != nil {
+		logger.WarnCF("agent", "Session migration to SQLite failed; falling back to jsonl sessions",
+			map[string]any{"error": err.Error()})
+		store.Close()
+		return nil
+	}
+	if migrated > 0 {
+		logger.InfoCF("agent", "Sessions migrated to SQLite", map[string]any{"sessions_migrated": migrated})
+	}
+
+	return session.NewJSONLBackend(store)
+}
+
+// migrateJSONLSessions copies the jsonl sessions in dir into store. The
+// jsonl store is only opened for the copy and closed again afterwards.
+func migrateJSONLSessions(ctx context.Context, dir string, store memory.Store) (int, error) {
+	jsonl, err := memory.NewJSONLStore(dir)
+	if err != nil {
+		return 0, err
+	}
+	defer jsonl.Close()
+	return memory.MigrateStore(ctx, jsonl, store)
+}
+
 func expandHome(path string) string {
 	if path == "" {
 		return path
@@ -506,3 +620,15 @@ func expandHome(path string) string {
 	}
 	return path
 }
+
+// modelParams returns the request settings for the active model: the agent's
+// max_tokens and temperature with any matching model profile applied on top.
+// names are the model_list alias and the provider model ID.
+func (a *AgentInstance) modelParams(names ...string) config.ModelParams {
+	temperature := a.Temperature
+	params := config.ModelParams{MaxTokens: a.MaxTokens, Temperature: &temperature}
+	if profile, ok := a.ModelProfiles.Resolve(names...); ok {
+		params = params.Merge(profile)
+	}
+	return params
+}
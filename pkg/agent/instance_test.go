@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -350,6 +351,54 @@ func TestNewAgentInstance_AllowsMediaTempDirForReadListAndExec(t *testing.T) {
 	}
 }
 
+// TestNewAgentInstance_RestrictToWorkspaceConfinesTools verifies that the
+// restrict_to_workspace default reaches the exec and file tools, so a command
+// cannot read /etc/passwd.
+func TestNewAgentInstance_RestrictToWorkspaceConfinesTools(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("/etc/passwd is Unix-only")
+	}
+	workspace := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:           workspace,
+				ModelName:           "test-model",
+				RestrictToWorkspace: true,
+			},
+		},
+		Tools: config.ToolsConfig{
+			ReadFile: config.ReadFileToolConfig{Enabled: true},
+			Exec: config.ExecConfig{
+				ToolConfig:         config.ToolConfig{Enabled: true},
+				EnableDenyPatterns: true,
+				AllowRemote:        true,
+			},
+		},
+	}
+
+	agent := NewAgentInstance(nil, &cfg.Agents.Defaults, cfg, &mockProvider{})
+
+	execTool, ok := agent.Tools.Get("exec")
+	if !ok {
+		t.Fatal("exec tool not registered")
+	}
+	for _, command := range []string{"cat /etc/passwd", "cd / && cat etc/passwd", "cd .. && ls"} {
+		result := execTool.Execute(context.Background(), map[string]any{"action": "run", "command": command})
+		if !result.IsError || strings.Contains(result.ForLLM, "root:") {
+			t.Errorf("exec %q should be blocked, got: %s", command, result.ForLLM)
+		}
+	}
+
+	readTool, ok := agent.Tools.Get("read_file")
+	if !ok {
+		t.Fatal("read_file tool not registered")
+	}
+	if result := readTool.Execute(context.Background(), map[string]any{"path": "/etc/passwd"}); !result.IsError {
+		t.Errorf("read_file /etc/passwd should be blocked, got: %s", result.ForLLM)
+	}
+}
+
 // TestPopulateCandidateProviders_NilCfgIsNoop verifies that passing a nil
 // config does not panic and leaves the output map empty.
 func TestPopulateCandidateProviders_NilCfgIsNoop(t *testing.T) {
@@ -543,6 +592,50 @@ func TestNewAgentInstance_CandidateProvidersPopulatedForCrossProviderFallbacks(t
 	}
 }
 
+func TestNewAgentInstance_ResolvesSummaryModel(t *testing.T) {
+	workspace := t.TempDir()
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:    workspace,
+				ModelName:    "mistral-small-3.1",
+				SummaryModel: "gemma-3-27b",
+			},
+		},
+		ModelList: []*config.ModelConfig{
+			{
+				ModelName: "mistral-small-3.1",
+				Model:     "openrouter/mistralai/mistral-small-3.1-24b-instruct:free",
+				APIBase:   "https://openrouter.ai/api/v1",
+				APIKeys:   config.SimpleSecureStrings("sk-or-test"),
+				Workspace: workspace,
+			},
+			{
+				ModelName: "gemma-3-27b",
+				Model:     "gemini/gemma-3-27b-it",
+				APIKeys:   config.SimpleSecureStrings("AIzaSy-test"),
+				Workspace: workspace,
+			},
+		},
+	}
+
+	primaryProvider := &mockProvider{}
+	agent := NewAgentInstance(nil, &cfg.Agents.Defaults, cfg, primaryProvider)
+
+	if len(agent.SummaryCandidates) != 1 || agent.SummaryCandidates[0].Model != "gemma-3-27b-it" {
+		t.Fatalf("SummaryCandidates = %+v, want gemma-3-27b-it", agent.SummaryCandidates)
+	}
+	p := agent.CandidateProviders[providers.ModelKey("gemini", "gemma-3-27b-it")]
+	if p == nil || p == primaryProvider {
+		t.Fatalf("summary model provider = %v, want a dedicated provider", p)
+	}
+	candidates := summaryCandidates(agent)
+	if len(candidates) != 2 || candidates[1].Model != agent.Candidates[0].Model {
+		t.Fatalf("summaryCandidates() = %+v, want summary model then primary", candidates)
+	}
+}
+
 func TestNewAgentInstance_ReadFileModeSelectsSchema(t *testing.T) {
 	workspace := t.TempDir()
 
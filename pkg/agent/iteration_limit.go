@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/commands"
+)
+
+// continueTaskMessage replaces /continue as the user message of the turn
+// that resumes a task cut off at the iteration limit.
+const continueTaskMessage = "Continue the task you were working on from where you stopped."
+
+// IterationLimitBehavior controls what a turn does when it exhausts
+// max_tool_iterations before the model produces a final answer.
+type IterationLimitBehavior string
+
+const (
+	// IterationLimitStop ends the turn and tells the user it was cut off.
+	IterationLimitStop IterationLimitBehavior = "stop"
+	// IterationLimitAsk ends the turn and asks the user whether to continue;
+	// replying starts a new turn with a fresh iteration budget.
+	IterationLimitAsk IterationLimitBehavior = "ask"
+	// IterationLimitContinue ends the turn and offers /continue, which the
+	// user must send to grant one more budget of max_tool_iterations for the
+	// same task. A continued turn that runs out again stops.
+	IterationLimitContinue IterationLimitBehavior = "continue"
+)
+
+// parseIterationLimitBehavior normalizes a config string into an
+// IterationLimitBehavior.
+func parseIterationLimitBehavior(s string) IterationLimitBehavior {
+	switch IterationLimitBehavior(strings.ToLower(strings.TrimSpace(s))) {
+	case IterationLimitAsk:
+		return IterationLimitAsk
+	case IterationLimitContinue:
+		return IterationLimitContinue
+	default:
+		return IterationLimitStop
+	}
+}
+
+// iterationLimitResponse builds the reply for a turn that ran out of tool
+// iterations, keeping any partial answer the model already produced.
+func iterationLimitResponse(content string, limit int, behavior IterationLimitBehavior) string {
+	var note string
+	switch behavior {
+	case IterationLimitAsk:
+		note = fmt.Sprintf(toolLimitAskResponse, limit)
+	case IterationLimitContinue:
+		note = fmt.Sprintf(toolLimitContinueResponse, limit, limit)
+	default:
+		note = fmt.Sprintf(toolLimitResponse, limit)
+	}
+	if content == "" {
+		return note
+	}
+	return content + "\n\n" + note
+}
+
+// offerContinuation decides how a turn that ran out of tool iterations ends.
+// In "continue" mode the first cut-off of a task records an offer for the
+// session, answered by /continue; a turn that was itself continued is not
+// offered another extension, so a task never gets more than two budgets.
+// SubTurns have no user to ask and simply stop.
+func (al *AgentLoop) offerContinuation(ts *turnState, behavior IterationLimitBehavior) IterationLimitBehavior {
+	if behavior != IterationLimitContinue {
+		return behavior
+	}
+	if ts.opts.Continued || ts.sessionKey == "" || ts.depth > 0 {
+		return IterationLimitStop
+	}
+	al.pendingContinuations.Store(ts.sessionKey, ts.opts.SenderID)
+	return IterationLimitContinue
+}
+
+// applyContinueCommand handles /continue. When the session has an open
+// offer from a turn cut off at the iteration limit, it rewrites the message
+// so the model resumes the task and passes it on with a fresh budget.
+func (al *AgentLoop) applyContinueCommand(
+	msg bus.InboundMessage,
+	opts *processOptions,
+) (matched bool, handled bool, reply string) {
+	cmdName, ok := commands.CommandName(msg.Content)
+	if !ok || cmdName != "continue" {
+		return false, false, ""
+	}
+	if opts == nil || opts.Dispatch.SessionKey == "" {
+		return true, true, "There is no cut-off task to continue."
+	}
+	sessionKey := opts.Dispatch.SessionKey
+	value, ok := al.pendingContinuations.Load(sessionKey)
+	if !ok {
+		return true, true, "There is no cut-off task to continue."
+	}
+	if senderID, _ := value.(string); senderID != "" && msg.SenderID != senderID {
+		return true, true, "Only the user whose request was cut off can continue it."
+	}
+	if !al.pendingContinuations.CompareAndDelete(sessionKey, value) {
+		return true, true, "There is no cut-off task to continue."
+	}
+	opts.Dispatch.UserMessage = continueTaskMessage
+	opts.UserMessage = continueTaskMessage
+	opts.Continued = true
+	return true, false, ""
+}
@@ -95,6 +95,53 @@ func visionUnsupportedModelError(modelName string, imageModelConfigured bool) er
 	)
 }
 
+// messagesContainToolLoadedMedia reports whether any media in messages was
+// loaded by a tool (e.g. load_image) rather than attached by the user.
+func messagesContainToolLoadedMedia(messages []providers.Message) bool {
+	for _, msg := range messages {
+		if len(msg.Media) > 0 && msg.PromptSlot == string(PromptSlotToolResult) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripAttachedMediaWithNote removes media from messages and leaves a short
+// text note in their place, so a text-only model still knows the user sent
+// something it could not look at.
+func stripAttachedMediaWithNote(messages []providers.Message, modelName string) []providers.Message {
+	if !messagesContainMedia(messages) {
+		return messages
+	}
+	modelName = strings.TrimSpace(modelName)
+	reason := "the active model does not support image input"
+	if modelName != "" {
+		reason = fmt.Sprintf("the active model %q does not support image input", modelName)
+	}
+
+	stripped := make([]providers.Message, len(messages))
+	for i, msg := range messages {
+		stripped[i] = msg
+		count := 0
+		for _, ref := range msg.Media {
+			if strings.TrimSpace(ref) != "" {
+				count++
+			}
+		}
+		stripped[i].Media = nil
+		if count == 0 {
+			continue
+		}
+		note := fmt.Sprintf("[%d attachment(s) skipped: %s]", count, reason)
+		if strings.TrimSpace(msg.Content) == "" {
+			stripped[i].Content = note
+		} else {
+			stripped[i].Content = msg.Content + "\n" + note
+		}
+	}
+	return stripped
+}
+
 func sameCandidateSet(a, b []providers.FallbackCandidate) bool {
 	if len(a) != len(b) {
 		return false
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// NoTools reports whether turns in sessionKey run without tools, and whether
+// the config turns tools off for every session so that it cannot be switched
+// back on at runtime.
+func (al *AgentLoop) NoTools(sessionKey string) (on, enforced bool) {
+	if cfg := al.GetConfig(); cfg != nil {
+		if profile, ok, err := cfg.Agents.Defaults.ResolveTurnProfile(); err == nil && ok {
+			enforced = profile.ToolsMode == config.TurnProfileModeOff
+		}
+	}
+	_, on = al.noToolsSessions.Load(strings.TrimSpace(sessionKey))
+	return enforced || on, enforced
+}
+
+// SetNoTools switches pure-chat mode for one session. While it is on, turns
+// are sent without tool definitions and the model has to answer directly.
+// sessionKey may be the key the caller passed or the routed session key.
+func (al *AgentLoop) SetNoTools(sessionKey string, on bool) error {
+	if _, enforced := al.NoTools(sessionKey); enforced && !on {
+		return fmt.Errorf("tools are turned off by agents.defaults.turn_profile")
+	}
+	key := strings.TrimSpace(sessionKey)
+	if on {
+		al.noToolsSessions.Store(key, struct{}{})
+	} else {
+		al.noToolsSessions.Delete(key)
+	}
+	return nil
+}
+
+// applyNoTools turns the tools block of the turn profile off when the turn's
+// session is in pure-chat mode, leaving the rest of the profile as resolved.
+func (al *AgentLoop) applyNoTools(opts processOptions) processOptions {
+	keys := append([]string{opts.Dispatch.SessionKey}, opts.Dispatch.SessionAliases...)
+	for _, key := range keys {
+		if _, ok := al.noToolsSessions.Load(strings.TrimSpace(key)); !ok {
+			continue
+		}
+		if !opts.TurnProfile.Enabled {
+			opts.TurnProfile = config.EffectiveTurnProfile{
+				Enabled:          true,
+				HistoryMode:      config.TurnProfileModeDefault,
+				SystemPromptMode: config.TurnProfileModeDefault,
+				SkillsMode:       config.TurnProfileModeDefault,
+			}
+		}
+		opts.TurnProfile.ToolsMode = config.TurnProfileModeOff
+		opts.TurnProfile.AllowedTools = nil
+		return opts
+	}
+	return opts
+}
@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestSetNoTools_SendsNoToolDefinitionsForThatSession(t *testing.T) {
+	provider := &turnProfileCaptureProvider{}
+	al := newTurnProfileAgentLoop(t, &config.Config{}, provider)
+	agent := al.GetRegistry().GetDefaultAgent()
+	agent.Tools.Register(&mockCustomTool{})
+
+	run := func(sessionKey string) {
+		t.Helper()
+		_, err := al.runAgentLoop(context.Background(), agent, processOptions{
+			SessionKey:      sessionKey,
+			UserMessage:     "hello",
+			DefaultResponse: defaultResponse,
+		})
+		if err != nil {
+			t.Fatalf("runAgentLoop() error = %v", err)
+		}
+	}
+
+	if err := al.SetNoTools("agent:default:chat", true); err != nil {
+		t.Fatalf("SetNoTools() error = %v", err)
+	}
+	run("agent:default:chat")
+	if len(provider.tools) != 0 {
+		t.Fatalf("tools sent in no-tools session: %d", len(provider.tools))
+	}
+
+	run("agent:default:other")
+	if len(provider.tools) == 0 {
+		t.Fatal("no tools sent in a session without no-tools")
+	}
+
+	if err := al.SetNoTools("agent:default:chat", false); err != nil {
+		t.Fatalf("SetNoTools(false) error = %v", err)
+	}
+	run("agent:default:chat")
+	if len(provider.tools) == 0 {
+		t.Fatal("tools still withheld after switching no-tools off")
+	}
+}
+
+func TestSetNoTools_ConfigEnforced(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Agents.Defaults.TurnProfile = config.TurnProfileConfig{
+		Enabled: true,
+		Tools:   config.TurnProfileBlock{Mode: config.TurnProfileModeOff},
+	}
+	al := newTurnProfileAgentLoop(t, cfg, &turnProfileCaptureProvider{})
+
+	if on, enforced := al.NoTools("cli:default"); !on || !enforced {
+		t.Fatalf("NoTools() = %v, %v; want on and enforced", on, enforced)
+	}
+	if err := al.SetNoTools("cli:default", false); err == nil {
+		t.Fatal("SetNoTools(false) succeeded despite config turning tools off")
+	}
+}
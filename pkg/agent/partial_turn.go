@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	// interruptedReplySuffix marks assistant text that was cut off when the
+	// provider failed mid-stream.
+	interruptedReplySuffix = "\n\n[Reply interrupted by a provider error.]"
+
+	// resumeTurnPrompt asks the model to pick up a reply that was cut off.
+	resumeTurnPrompt = "[The previous reply was interrupted by a provider error. " +
+		"Continue from where it stopped without repeating completed work.]"
+)
+
+// partialTurn is the saved progress of a turn whose LLM call failed after
+// tools had already run or text had streamed.
+type partialTurn struct {
+	userMessage string
+	messages    []providers.Message // the turn's messages as saved in the session
+}
+
+// continuation returns the prompt that resumes the turn: nothing when it
+// stopped after tool results, which the model can act on directly, or a
+// short note when it stopped in the middle of a reply.
+func (p *partialTurn) continuation() string {
+	if n := len(p.messages); n > 0 && p.messages[n-1].Role == "tool" {
+		return ""
+	}
+	return resumeTurnPrompt
+}
+
+// persistPartialTurn saves what a failed turn completed so the work is not
+// lost. Completed tool calls and their results stay in the session,
+// incomplete tool calls are dropped, and any text streamed before the failure
+// is kept as an interrupted reply. If the same message is sent again, the next
+// turn resumes from this point instead of starting over.
+func (al *AgentLoop) persistPartialTurn(ts *turnState, exec *turnExecution) {
+	if ts.opts.NoHistory || ts.hardAbortRequested() {
+		return
+	}
+	persisted := ts.persistedMessagesSnapshot()
+	partial := strings.TrimSpace(exec.partialContent)
+	if !messagesContainToolResult(persisted) && partial == "" {
+		return
+	}
+
+	history := ts.agent.Sessions.GetHistory(ts.sessionKey)
+	matched := matchingTurnMessageTail(history, persisted)
+	if matched == 0 {
+		return
+	}
+	stable := history[:len(history)-matched]
+	kept := sanitizeHistoryForProvider(append([]providers.Message(nil), history[len(history)-matched:]...))
+	if partial != "" {
+		if cfg := al.GetConfig(); cfg != nil && cfg.Tools.IsFilterSensitiveDataEnabled() {
+			partial = cfg.FilterSensitiveData(partial)
+		}
+		kept = append(kept, providers.Message{Role: "assistant", Content: partial + interruptedReplySuffix})
+	}
+
+	ts.agent.Sessions.SetHistory(ts.sessionKey, append(append([]providers.Message(nil), stable...), kept...))
+	if err := ts.agent.Sessions.Save(ts.sessionKey); err != nil {
+		logger.WarnCF("agent", "Failed to save partial turn", map[string]any{
+			"session_key": ts.sessionKey,
+			"error":       err.Error(),
+		})
+		return
+	}
+	al.partialTurns.Store(ts.sessionKey, &partialTurn{userMessage: ts.userMessage, messages: kept})
+	logger.InfoCF("agent", "Saved partial turn after LLM failure", map[string]any{
+		"agent_id":    ts.agent.ID,
+		"session_key": ts.sessionKey,
+		"messages":    len(kept),
+		"partial_len": len(partial),
+	})
+}
+
+// takeResumableTurn returns the saved progress of the session's last failed
+// turn when ts retries the same message and nothing was added to the session
+// since. Any new turn discards the saved progress.
+func (al *AgentLoop) takeResumableTurn(ts *turnState) *partialTurn {
+	v, ok := al.partialTurns.LoadAndDelete(ts.sessionKey)
+	if !ok || ts.opts.NoHistory || len(ts.media) > 0 {
+		return nil
+	}
+	p := v.(*partialTurn)
+	if strings.TrimSpace(p.userMessage) != strings.TrimSpace(ts.userMessage) {
+		return nil
+	}
+	history := ts.agent.Sessions.GetHistory(ts.sessionKey)
+	if matchingTurnMessageTail(history, p.messages) != len(p.messages) {
+		return nil
+	}
+	return p
+}
+
+func messagesContainToolResult(messages []providers.Message) bool {
+	for _, msg := range messages {
+		if msg.Role == "tool" {
+			return true
+		}
+	}
+	return false
+}
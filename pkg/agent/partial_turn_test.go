@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// failAfterToolProvider requests one tool call, fails the next call, and
+// answers every call after that, recording the messages of each call.
+type failAfterToolProvider struct {
+	mu    sync.Mutex
+	calls [][]providers.Message
+}
+
+func (p *failAfterToolProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, append([]providers.Message(nil), messages...))
+
+	switch len(p.calls) {
+	case 1:
+		return &providers.LLMResponse{
+			ToolCalls: []providers.ToolCall{
+				{ID: "call_1", Name: "counting_tool", Arguments: map[string]any{}},
+			},
+			FinishReason: "tool_calls",
+		}, nil
+	case 2:
+		return nil, errors.New("stream closed unexpectedly")
+	default:
+		return &providers.LLMResponse{Content: "done", FinishReason: "stop"}, nil
+	}
+}
+
+func (p *failAfterToolProvider) GetDefaultModel() string {
+	return "test-model"
+}
+
+type countingTool struct {
+	calls atomic.Int32
+}
+
+func (t *countingTool) Name() string        { return "counting_tool" }
+func (t *countingTool) Description() string { return "Counts its executions" }
+func (t *countingTool) Parameters() map[string]any {
+	return map[string]any{"type": "object", "properties": map[string]any{}}
+}
+
+func (t *countingTool) Execute(context.Context, map[string]any) *tools.ToolResult {
+	t.calls.Add(1)
+	return tools.SilentResult("tool output")
+}
+
+func partialTurnTestOpts(sessionKey, message string) processOptions {
+	opts := makeTestProcessOpts(sessionKey)
+	opts.Dispatch = DispatchRequest{
+		SessionKey:     sessionKey,
+		InboundContext: &bus.InboundContext{Channel: "cli", ChatID: "test-chat"},
+		UserMessage:    message,
+	}
+	return opts
+}
+
+func TestRunTurn_ResumesAfterFailureFollowingToolCall(t *testing.T) {
+	provider := &failAfterToolProvider{}
+	al, agent, cleanup := newTurnCoordTestLoop(t, provider)
+	defer cleanup()
+	tool := &countingTool{}
+	agent.Tools.Register(tool)
+
+	pipeline := NewPipeline(al)
+	opts := partialTurnTestOpts("test-session-partial", "look it up")
+	newTS := func(id string) *turnState {
+		return newTurnState(agent, opts, turnEventScope{turnID: id, context: newTurnContext(nil, nil, nil)})
+	}
+
+	if _, err := al.runTurn(context.Background(), newTS("turn-1"), pipeline); err == nil {
+		t.Fatal("expected the first turn to fail")
+	}
+	history := agent.Sessions.GetHistory(opts.Dispatch.SessionKey)
+	if n := len(history); n == 0 || history[n-1].Role != "tool" || history[n-1].Content != "tool output" {
+		t.Fatalf("completed tool result was not kept after the failure: %+v", history)
+	}
+
+	result, err := al.runTurn(context.Background(), newTS("turn-2"), pipeline)
+	if err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if result.finalContent != "done" {
+		t.Fatalf("finalContent = %q, want %q", result.finalContent, "done")
+	}
+	if got := tool.calls.Load(); got != 1 {
+		t.Fatalf("tool executed %d times, want 1", got)
+	}
+
+	resumed := provider.calls[2]
+	if last := resumed[len(resumed)-1]; last.Role != "tool" {
+		t.Fatalf("resumed call should continue from the tool result, last message = %+v", last)
+	}
+	userMessages := 0
+	for _, msg := range agent.Sessions.GetHistory(opts.Dispatch.SessionKey) {
+		if msg.Role == "user" && strings.Contains(msg.Content, opts.Dispatch.UserMessage) {
+			userMessages++
+		}
+	}
+	if userMessages != 1 {
+		t.Fatalf("user message saved %d times, want 1", userMessages)
+	}
+}
+
+func TestRunTurn_NewMessageDiscardsPartialTurn(t *testing.T) {
+	provider := &failAfterToolProvider{}
+	al, agent, cleanup := newTurnCoordTestLoop(t, provider)
+	defer cleanup()
+	tool := &countingTool{}
+	agent.Tools.Register(tool)
+
+	pipeline := NewPipeline(al)
+	opts := partialTurnTestOpts("test-session-partial-new", "look it up")
+	if _, err := al.runTurn(context.Background(), newTurnState(agent, opts, turnEventScope{
+		turnID:  "turn-1",
+		context: newTurnContext(nil, nil, nil),
+	}), pipeline); err == nil {
+		t.Fatal("expected the first turn to fail")
+	}
+
+	opts.Dispatch.UserMessage = "something else"
+	if _, err := al.runTurn(context.Background(), newTurnState(agent, opts, turnEventScope{
+		turnID:  "turn-2",
+		context: newTurnContext(nil, nil, nil),
+	}), pipeline); err != nil {
+		t.Fatalf("second turn failed: %v", err)
+	}
+	last := provider.calls[2]
+	if msg := last[len(last)-1]; msg.Role != "user" || msg.Content != "something else" {
+		t.Fatalf("new message should start a fresh turn, last message = %+v", msg)
+	}
+	if _, ok := al.partialTurns.Load(opts.Dispatch.SessionKey); ok {
+		t.Fatal("partial turn should be discarded")
+	}
+}
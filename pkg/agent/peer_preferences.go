@@ -0,0 +1,246 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	// setPreferenceTrigger stores a per-user preference, e.g. ":set lang es".
+	setPreferenceTrigger = ":set"
+	// listPreferencesTrigger shows the sender's stored preferences.
+	listPreferencesTrigger = ":prefs"
+
+	preferenceLang      = "lang"
+	preferenceVerbosity = "verbosity"
+	preferenceModel     = "model"
+)
+
+// preferenceResetValues clear a preference back to the configured default.
+var preferenceResetValues = []string{"default", "reset", "off"}
+
+// languageTagPattern accepts BCP 47 style tags such as "es" or "pt-BR".
+var languageTagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// verbosityInstructions is the system instruction for each verbosity level
+// a user can pick. "normal" is the default and adds nothing.
+var verbosityInstructions = map[string]string{
+	"brief": "Keep answers brief: a few sentences or a short list, no preamble or recap. " +
+		"Go into detail only when the user asks for it.",
+	"normal": "",
+	"detailed": "Give thorough answers: explain the reasoning, cover relevant edge cases " +
+		"and include examples where they help.",
+}
+
+// peerPreferenceKey identifies the sender of msg across sessions, the same
+// way greeted peers are tracked. It is empty for senders that cannot hold
+// preferences (internal channels, cron, heartbeat).
+func peerPreferenceKey(msg bus.InboundMessage) string {
+	if constants.IsInternalChannel(msg.Channel) {
+		return ""
+	}
+	switch msg.SenderID {
+	case "", "cron", "heartbeat":
+		return ""
+	}
+	return msg.Channel + ":" + msg.SenderID
+}
+
+// handlePeerPreferences answers ":set <key> <value>" and ":prefs" without
+// starting a turn. Preferences belong to the sender, not the chat, so users
+// sharing a group each keep their own.
+func (al *AgentLoop) handlePeerPreferences(msg bus.InboundMessage) (string, bool) {
+	fields := strings.Fields(msg.Content)
+	if len(fields) == 0 {
+		return "", false
+	}
+	trigger := strings.ToLower(fields[0])
+	if trigger != setPreferenceTrigger && trigger != listPreferencesTrigger {
+		return "", false
+	}
+	peer := peerPreferenceKey(msg)
+	if al.state == nil || peer == "" {
+		return "Preferences are not available here.", true
+	}
+
+	if trigger == listPreferencesTrigger {
+		return formatPeerPreferences(al.state.GetPeerPreferences(peer)), true
+	}
+
+	if len(fields) != 3 {
+		return fmt.Sprintf("Usage: %s <lang|verbosity|model> <value>, or %s <key> default to reset.",
+			setPreferenceTrigger, setPreferenceTrigger), true
+	}
+	key, value := strings.ToLower(fields[1]), fields[2]
+	if isPreferenceReset(value) {
+		value = ""
+	} else {
+		var err error
+		if value, err = al.validatePeerPreference(key, value); err != nil {
+			return err.Error(), true
+		}
+	}
+	if err := al.state.SetPeerPreference(peer, key, value); err != nil {
+		logger.WarnCF("agent", "Failed to save peer preference", map[string]any{
+			"peer":  peer,
+			"key":   key,
+			"error": err.Error(),
+		})
+		return "Could not save the preference: " + err.Error(), true
+	}
+	if value == "" {
+		return fmt.Sprintf("Reset %s to the default.", key), true
+	}
+	return fmt.Sprintf("Set %s to %s.", key, value), true
+}
+
+func isPreferenceReset(value string) bool {
+	for _, reset := range preferenceResetValues {
+		if strings.EqualFold(value, reset) {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePeerPreference checks value for key and returns it normalized.
+func (al *AgentLoop) validatePeerPreference(key, value string) (string, error) {
+	switch key {
+	case preferenceLang:
+		if !languageTagPattern.MatchString(value) {
+			return "", fmt.Errorf("%q is not a language tag; use something like es, de or pt-BR.", value)
+		}
+		return value, nil
+	case preferenceVerbosity:
+		value = strings.ToLower(value)
+		if _, ok := verbosityInstructions[value]; !ok {
+			return "", fmt.Errorf("verbosity must be brief, normal or detailed.")
+		}
+		if value == "normal" {
+			return "", nil
+		}
+		return value, nil
+	case preferenceModel:
+		if _, err := resolvedModelConfig(al.GetConfig(), value, ""); err != nil {
+			return "", fmt.Errorf("unknown model %q; use a model_name from model_list.", value)
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown preference %q; use lang, verbosity or model.", key)
+	}
+}
+
+func formatPeerPreferences(prefs map[string]string) string {
+	if len(prefs) == 0 {
+		return fmt.Sprintf("No preferences set. Use %s <lang|verbosity|model> <value>.", setPreferenceTrigger)
+	}
+	keys := make([]string, 0, len(prefs))
+	for key := range prefs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := []string{"Your preferences:"}
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("- %s: %s", key, prefs[key]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// applyPeerPreferences folds the sender's stored preferences into the turn
+// options: the language replaces the locale their app reports and any
+// detected reply language, verbosity becomes an instruction and the model
+// overrides the agent's for this turn.
+func (al *AgentLoop) applyPeerPreferences(msg bus.InboundMessage, opts processOptions) processOptions {
+	peer := peerPreferenceKey(msg)
+	if al.state == nil || peer == "" {
+		return opts
+	}
+	prefs := al.state.GetPeerPreferences(peer)
+	if lang := prefs[preferenceLang]; lang != "" {
+		opts.SenderLocale = lang
+		opts.ReplyLanguage = ""
+	}
+	opts.Verbosity = prefs[preferenceVerbosity]
+	opts.PreferredModel = prefs[preferenceModel]
+	return opts
+}
+
+// verbosityPromptParts returns the instruction for the sender's chosen
+// verbosity, if any.
+func verbosityPromptParts(verbosity string) []PromptPart {
+	instruction := verbosityInstructions[verbosity]
+	if instruction == "" {
+		return nil
+	}
+	return []PromptPart{
+		{
+			ID:      "instruction.peer_verbosity",
+			Layer:   PromptLayerInstruction,
+			Slot:    PromptSlotWorkspace,
+			Source:  PromptSource{ID: PromptSourcePeerPreferences, Name: "peer:verbosity"},
+			Title:   "user verbosity preference",
+			Content: "## Answer Length\n\n" + instruction,
+			Stable:  false,
+			Cache:   PromptCacheNone,
+		},
+	}
+}
+
+// usePreferredModel switches the turn to the model the sender picked with
+// ":set model". It returns a function that releases the provider created for
+// it, or nil when the agent's own model is used.
+func (p *Pipeline) usePreferredModel(ts *turnState, exec *turnExecution) func() {
+	name := strings.TrimSpace(ts.opts.PreferredModel)
+	if name == "" || name == strings.TrimSpace(ts.agent.Model) {
+		return nil
+	}
+	warn := func(err error) {
+		logger.WarnCF("agent", "Ignoring preferred model", map[string]any{
+			"agent_id": ts.agent.ID,
+			"model":    name,
+			"error":    err.Error(),
+		})
+	}
+	modelCfg, err := resolvedModelConfig(p.Cfg, name, ts.agent.Workspace)
+	if err != nil {
+		warn(err)
+		return nil
+	}
+	candidates := resolveModelCandidates(p.Cfg, p.Cfg.Agents.Defaults.Provider, name, ts.agent.Fallbacks)
+	if len(candidates) == 0 {
+		warn(fmt.Errorf("model did not resolve to any provider candidates"))
+		return nil
+	}
+	factory := p.al.providerFactory
+	if factory == nil {
+		factory = providers.CreateProviderFromConfig
+	}
+	provider, _, err := factory(modelCfg)
+	if err != nil {
+		warn(err)
+		return nil
+	}
+
+	exec.activeCandidates = candidates
+	exec.activeModel = resolvedCandidateModel(candidates, name)
+	exec.activeProvider = provider
+	exec.activeModelConfig = resolveActiveModelConfig(
+		p.Cfg,
+		ts.agent.Workspace,
+		candidates,
+		exec.activeModel,
+		p.Cfg.Agents.Defaults.Provider,
+	)
+	exec.llmModelName = resolvedCandidateModelName(candidates, name)
+	exec.usedLight = false
+	return func() { closeProviderIfStateful(provider) }
+}
@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func newPeerPreferencesLoop(t *testing.T, provider providers.LLMProvider) *AgentLoop {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		ModelList: []*config.ModelConfig{
+			{ModelName: "test-model", Model: "openai/test-model"},
+			{ModelName: "fast-model", Model: "openai/fast-model"},
+		},
+	}
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	t.Cleanup(al.Close)
+	return al
+}
+
+func sendAs(t *testing.T, al *AgentLoop, sender, content string) string {
+	t.Helper()
+	response, err := al.processMessage(context.Background(), testInboundMessage(bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: sender,
+		ChatID:   "group-1",
+		Content:  content,
+	}))
+	if err != nil {
+		t.Fatalf("processMessage(%q) error = %v", content, err)
+	}
+	return response
+}
+
+func TestPeerPreferences_SetListAndScopePerPeer(t *testing.T) {
+	provider := &recordingProvider{}
+	al := newPeerPreferencesLoop(t, provider)
+
+	if got := sendAs(t, al, "alice", ":set lang es"); got != "Set lang to es." {
+		t.Fatalf(":set lang reply = %q", got)
+	}
+	if got := sendAs(t, al, "alice", ":set verbosity brief"); got != "Set verbosity to brief." {
+		t.Fatalf(":set verbosity reply = %q", got)
+	}
+	for _, bad := range []string{":set verbosity chatty", ":set lang español!", ":set model nope", ":set color blue"} {
+		if got := sendAs(t, al, "alice", bad); strings.HasPrefix(got, "Set ") {
+			t.Errorf("%q was accepted: %q", bad, got)
+		}
+	}
+	if provider.lastMessages != nil {
+		t.Fatal("preference commands must not start a turn")
+	}
+
+	prefs := sendAs(t, al, "alice", ":prefs")
+	if !strings.Contains(prefs, "- lang: es") || !strings.Contains(prefs, "- verbosity: brief") {
+		t.Fatalf(":prefs = %q", prefs)
+	}
+	if other := sendAs(t, al, "bob", ":prefs"); !strings.HasPrefix(other, "No preferences set") {
+		t.Fatalf("bob sees %q; preferences leaked across peers", other)
+	}
+
+	sendAs(t, al, "alice", "hello")
+	system := provider.lastMessages[0].Content
+	if !strings.Contains(system, "Language: es") || !strings.Contains(system, "## Answer Length") {
+		t.Fatalf("alice's system prompt lacks her preferences:\n%s", system)
+	}
+
+	sendAs(t, al, "bob", "hello")
+	system = provider.lastMessages[0].Content
+	if strings.Contains(system, "Language: es") || strings.Contains(system, "## Answer Length") {
+		t.Fatalf("bob's system prompt carries alice's preferences:\n%s", system)
+	}
+
+	if got := sendAs(t, al, "alice", ":set verbosity default"); got != "Reset verbosity to the default." {
+		t.Fatalf("reset reply = %q", got)
+	}
+	if prefs := sendAs(t, al, "alice", ":prefs"); strings.Contains(prefs, "verbosity") {
+		t.Fatalf(":prefs after reset = %q", prefs)
+	}
+}
+
+func TestPeerPreferences_ModelOverridesTurnModel(t *testing.T) {
+	defaultProvider := &recordingProvider{}
+	al := newPeerPreferencesLoop(t, defaultProvider)
+	preferred := &recordingProvider{}
+	al.providerFactory = func(mc *config.ModelConfig) (providers.LLMProvider, string, error) {
+		return preferred, "fast-model", nil
+	}
+
+	if got := sendAs(t, al, "alice", ":set model fast-model"); got != "Set model to fast-model." {
+		t.Fatalf(":set model reply = %q", got)
+	}
+	sendAs(t, al, "alice", "hello")
+	if preferred.lastModel != "fast-model" {
+		t.Fatalf("preferred provider model = %q, want fast-model", preferred.lastModel)
+	}
+	if defaultProvider.lastMessages != nil {
+		t.Fatal("alice's turn should not use the agent's default provider")
+	}
+
+	sendAs(t, al, "bob", "hello")
+	if defaultProvider.lastMessages == nil {
+		t.Fatal("bob's turn should use the agent's default provider")
+	}
+}
@@ -0,0 +1,160 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+const (
+	// maxPinnedFiles bounds how many files a single session can pin.
+	maxPinnedFiles = 10
+	// maxPinnedFileBytes caps the content injected per pinned file; longer
+	// files are truncated with a marker.
+	maxPinnedFileBytes = 16 * 1024
+	// maxPinnedTotalBytes caps the combined pinned content per turn.
+	maxPinnedTotalBytes = 48 * 1024
+)
+
+// pinnedFilesPromptContributor injects the contents of files pinned to the
+// current session. Files are re-read on every turn so edits show up on the
+// next message without re-pinning.
+type pinnedFilesPromptContributor struct {
+	workspace string
+	pinned    func(sessionKey string) []string
+}
+
+func (c pinnedFilesPromptContributor) PromptSource() PromptSourceDescriptor {
+	return PromptSourceDescriptor{
+		ID:              PromptSourcePinnedFiles,
+		Owner:           "workspace",
+		Description:     "Files pinned to the current session",
+		Allowed:         []PromptPlacement{{Layer: PromptLayerContext, Slot: PromptSlotPinnedFiles}},
+		StableByDefault: false,
+	}
+}
+
+func (c pinnedFilesPromptContributor) ContributePrompt(
+	_ context.Context,
+	req PromptBuildRequest,
+) ([]PromptPart, error) {
+	if c.pinned == nil || strings.TrimSpace(req.SessionKey) == "" {
+		return nil, nil
+	}
+	paths := c.pinned(req.SessionKey)
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Pinned Files\n\nThe user pinned these workspace files to this conversation. " +
+		"Their current contents are shown below and refreshed every turn.")
+	remaining := maxPinnedTotalBytes
+	for _, rel := range paths {
+		fmt.Fprintf(&sb, "\n\n## %s\n\n", rel)
+		if remaining <= 0 {
+			sb.WriteString("[omitted: pinned content limit reached]")
+			continue
+		}
+		content, truncated, err := readPinnedFile(c.workspace, rel, min(maxPinnedFileBytes, remaining))
+		if err != nil {
+			fmt.Fprintf(&sb, "[unavailable: %v]", err)
+			continue
+		}
+		remaining -= len(content)
+		sb.WriteString(content)
+		if truncated {
+			sb.WriteString("\n[... truncated]")
+		}
+	}
+
+	return []PromptPart{
+		{
+			ID:      "context.pinned_files",
+			Layer:   PromptLayerContext,
+			Slot:    PromptSlotPinnedFiles,
+			Source:  PromptSource{ID: PromptSourcePinnedFiles, Name: "workspace:pinned"},
+			Title:   "pinned files",
+			Content: sb.String(),
+			Stable:  false,
+			Cache:   PromptCacheNone,
+		},
+	}, nil
+}
+
+// readPinnedFile reads at most limit bytes of a workspace-relative file.
+func readPinnedFile(workspace, rel string, limit int) (string, bool, error) {
+	absPath, err := tools.ValidatePathWithAllowPaths(rel, workspace, true, nil)
+	if err != nil {
+		return "", false, err
+	}
+	f, err := os.Open(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, fmt.Errorf("file no longer exists")
+		}
+		return "", false, err
+	}
+	defer f.Close()
+
+	buf, err := io.ReadAll(io.LimitReader(f, int64(limit)+1))
+	if err != nil {
+		return "", false, err
+	}
+	if len(buf) > limit {
+		return strings.ToValidUTF8(string(buf[:limit]), ""), true, nil
+	}
+	return string(buf), false, nil
+}
+
+// resolvePinPath validates a user-supplied path and returns it relative to
+// the workspace, which is the form stored in session state.
+func resolvePinPath(workspace, path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("file path is required")
+	}
+	absPath, err := tools.ValidatePathWithAllowPaths(path, workspace, true, nil)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("file not found: %s", path)
+		}
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("not a regular file: %s", path)
+	}
+	absWorkspace, err := filepath.Abs(workspace)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absWorkspace, absPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// normalizePinPath maps a user-supplied path to the stored workspace-relative
+// form without requiring the file to still exist, so deleted files can be
+// unpinned.
+func normalizePinPath(workspace, path string) string {
+	path = strings.TrimSpace(path)
+	if filepath.IsAbs(path) {
+		if absWorkspace, err := filepath.Abs(workspace); err == nil {
+			if rel, err := filepath.Rel(absWorkspace, path); err == nil {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestContextBuilder_IncludesPinnedFilesForSession(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+	workspace := t.TempDir()
+	specPath := filepath.Join(workspace, "spec.md")
+	if err := os.WriteFile(specPath, []byte("Build the widget."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cb := NewContextBuilder(workspace)
+	pins := map[string][]string{"session-1": {"spec.md", "gone.md"}}
+	if err := cb.RegisterPromptContributor(pinnedFilesPromptContributor{
+		workspace: workspace,
+		pinned:    func(sessionKey string) []string { return pins[sessionKey] },
+	}); err != nil {
+		t.Fatalf("RegisterPromptContributor() error = %v", err)
+	}
+
+	system := cb.BuildMessagesFromPrompt(PromptBuildRequest{SessionKey: "session-1", CurrentMessage: "hi"})[0]
+	if !strings.Contains(system.Content, "## spec.md\n\nBuild the widget.") {
+		t.Fatalf("system prompt missing pinned file content: %q", system.Content)
+	}
+	if !strings.Contains(system.Content, "## gone.md\n\n[unavailable: file no longer exists]") {
+		t.Fatalf("system prompt missing unavailable marker: %q", system.Content)
+	}
+
+	// Edits are picked up on the next turn.
+	if err := os.WriteFile(specPath, []byte("Build the gadget."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	system = cb.BuildMessagesFromPrompt(PromptBuildRequest{SessionKey: "session-1", CurrentMessage: "hi"})[0]
+	if !strings.Contains(system.Content, "Build the gadget.") {
+		t.Fatalf("system prompt did not reflect edited pinned file: %q", system.Content)
+	}
+
+	system = cb.BuildMessagesFromPrompt(PromptBuildRequest{SessionKey: "session-2", CurrentMessage: "hi"})[0]
+	if strings.Contains(system.Content, "Pinned Files") {
+		t.Fatalf("pinned files leaked into another session: %q", system.Content)
+	}
+}
+
+func TestReadPinnedFile_TruncatesLargeFiles(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "big.txt"), []byte(strings.Repeat("a", 100)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	content, truncated, err := readPinnedFile(workspace, "big.txt", 10)
+	if err != nil {
+		t.Fatalf("readPinnedFile() error = %v", err)
+	}
+	if !truncated || len(content) != 10 {
+		t.Fatalf("readPinnedFile() = %d bytes, truncated=%v; want 10, true", len(content), truncated)
+	}
+}
+
+func TestResolvePinPath(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspace, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "docs", "spec.md"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rel, err := resolvePinPath(workspace, filepath.Join(workspace, "docs", "spec.md"))
+	if err != nil || rel != "docs/spec.md" {
+		t.Fatalf("resolvePinPath(abs) = %q, %v; want docs/spec.md", rel, err)
+	}
+	if _, err := resolvePinPath(workspace, "docs"); err == nil {
+		t.Fatal("resolvePinPath(dir) expected error")
+	}
+	if _, err := resolvePinPath(workspace, "missing.md"); err == nil {
+		t.Fatal("resolvePinPath(missing) expected error")
+	}
+	if _, err := resolvePinPath(workspace, "../outside.md"); err == nil {
+		t.Fatal("resolvePinPath(outside workspace) expected error")
+	}
+}
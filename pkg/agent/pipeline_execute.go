@@ -121,6 +121,7 @@ func (p *Pipeline) ExecuteTools(
 	ts.setPhase(TurnPhaseTools)
 	messages := exec.messages
 	handledAttachments := make([]providers.Attachment, 0)
+	correctionLimit := al.cfg.Agents.Defaults.GetToolCorrectionRetries()
 
 toolLoop:
 	for i, tc := range normalizedToolCalls {
@@ -131,12 +132,18 @@ toolLoop:
 
 		toolName := tc.Name
 		toolArgs := cloneStringAnyMap(tc.Arguments)
-		denyByTurnProfile := func() bool {
-			if turnProfileToolAllowed(ts.profile, toolName) {
+		denyByPolicy := func() bool {
+			denyContent := al.safeModeDenial(toolName, toolArgs, ts.channel, ts.chatID)
+			if denyContent == "" && !turnProfileToolAllowed(ts.profile, toolName) {
+				denyContent = fmt.Sprintf("Tool %q is not allowed by the active turn profile.", toolName)
+			}
+			if denyContent == "" && ts.toolCorrectionsExhausted(toolName, correctionLimit) {
+				denyContent = toolCorrectionDenial(toolName)
+			}
+			if denyContent == "" {
 				return false
 			}
 			exec.allResponsesHandled = false
-			denyContent := fmt.Sprintf("Tool %q is not allowed by the active turn profile.", toolName)
 			al.emitEvent(
 				runtimeevents.KindAgentToolExecSkipped,
 				ts.eventMeta("runTurn", "turn.tool.skipped"),
@@ -158,7 +165,7 @@ toolLoop:
 			return true
 		}
 
-		if denyByTurnProfile() {
+		if denyByPolicy() {
 			continue
 		}
 
@@ -468,7 +475,7 @@ toolLoop:
 			}
 		}
 
-		if denyByTurnProfile() {
+		if denyByPolicy() {
 			continue
 		}
 
@@ -489,6 +496,7 @@ toolLoop:
 			},
 		)
 
+		var feedbackMsg string
 		if shouldPublishToolFeedback(al.cfg, ts) && ts.channel != "pico" {
 			toolFeedbackMaxLen := al.cfg.Agents.Defaults.GetToolFeedbackMaxArgsLength()
 			toolFeedbackExplanation := toolFeedbackExplanationForToolCall(
@@ -496,7 +504,7 @@ toolLoop:
 				tc,
 				messages,
 			)
-			feedbackMsg := utils.FormatToolFeedbackMessage(
+			feedbackMsg = utils.FormatToolFeedbackMessage(
 				toolName,
 				toolFeedbackExplanation,
 				toolFeedbackArgsPreview(toolArgs, toolFeedbackMaxLen),
@@ -512,6 +520,21 @@ toolLoop:
 
 		toolCallID := tc.ID
 		asyncToolName := toolName
+		// Register async-capable calls before they start, so a result that
+		// arrives quickly still finds its task.
+		var asyncTaskID string
+		if tool, ok := ts.agent.Tools.Get(toolName); ok {
+			if _, isAsync := tool.(tools.AsyncExecutor); isAsync {
+				asyncTaskID = al.asyncTools.start(AsyncToolTask{
+					Tool:       toolName,
+					ToolCallID: toolCallID,
+					AgentID:    ts.agent.ID,
+					SessionKey: ts.sessionKey,
+					Channel:    ts.channel,
+					ChatID:     ts.chatID,
+				})
+			}
+		}
 		asyncCallback := func(_ context.Context, result *tools.ToolResult) {
 			if !result.Silent && result.ForUser != "" {
 				outCtx, outCancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -521,6 +544,7 @@ toolLoop:
 
 			content := result.ContentForLLM()
 			if content == "" {
+				al.asyncTools.finish(asyncTaskID)
 				return
 			}
 
@@ -544,10 +568,11 @@ toolLoop:
 			defer pubCancel()
 			_ = al.bus.PublishInbound(pubCtx, bus.InboundMessage{
 				Context: bus.InboundContext{
-					Channel:  "system",
-					ChatID:   fmt.Sprintf("%s:%s", ts.channel, ts.chatID),
-					ChatType: "direct",
-					SenderID: fmt.Sprintf("async:%s", asyncToolName),
+					Channel:   "system",
+					ChatID:    fmt.Sprintf("%s:%s", ts.channel, ts.chatID),
+					ChatType:  "direct",
+					SenderID:  fmt.Sprintf("async:%s", asyncToolName),
+					MessageID: asyncTaskID,
 				},
 				Content: content,
 			})
@@ -567,6 +592,9 @@ toolLoop:
 			ts.sessionKey,
 			ts.opts.Dispatch.SessionScope,
 		)
+		if progress := al.toolProgressRelay(turnCtx, ts, feedbackMsg); progress != nil {
+			execCtx = tools.WithToolProgress(execCtx, progress)
+		}
 		toolResult := ts.agent.Tools.ExecuteWithContext(
 			execCtx,
 			toolName,
@@ -576,6 +604,9 @@ toolLoop:
 			asyncCallback,
 		)
 		toolDuration := time.Since(toolStart)
+		if asyncTaskID != "" && (toolResult == nil || !toolResult.Async) {
+			al.asyncTools.finish(asyncTaskID)
+		}
 
 		if ts.hardAbortRequested() {
 			exec.abortedByHardAbort = true
@@ -689,6 +720,13 @@ toolLoop:
 		if al.cfg.Tools.IsFilterSensitiveDataEnabled() {
 			contentForLLM = al.cfg.FilterSensitiveData(contentForLLM)
 		}
+		if correctionLimit > 0 {
+			failures := ts.recordToolCorrection(toolName, toolResult)
+			if toolResult.IsError && toolResult.ErrorCode.Correctable() {
+				tool, _ := ts.agent.Tools.Get(toolName)
+				contentForLLM += "\n" + toolCorrectionNote(tool, toolName, failures, correctionLimit)
+			}
+		}
 
 		var toolResultMedia []string
 		if len(toolResult.Media) > 0 && !toolResult.ResponseHandled {
@@ -832,12 +870,8 @@ toolLoop:
 					})
 			}
 		}
-		if !ts.opts.NoHistory && ts.opts.EnableSummary {
-			al.contextManager.Compact(turnCtx, &CompactRequest{
-				SessionKey: ts.sessionKey,
-				Reason:     ContextCompressReasonSummarize,
-				Budget:     ts.agent.ContextWindow,
-			})
+		if preview := al.compactAfterTurn(turnCtx, ts); preview != "" {
+			_ = al.bus.PublishOutbound(turnCtx, outboundMessageForTurn(ts, preview))
 		}
 		ts.setPhase(TurnPhaseCompleted)
 		ts.setFinalContent("")
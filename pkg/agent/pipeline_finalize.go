@@ -40,6 +40,10 @@ func (p *Pipeline) Finalize(
 	}
 
 	ts.setPhase(TurnPhaseFinalizing)
+	// A reply the user already watched stream in cannot be shortened.
+	if exec.streamingPublisher == nil || !exec.streamingPublisher.Published() {
+		finalContent = al.limitResponseLength(turnCtx, ts, finalContent)
+	}
 	ts.setFinalContent(finalContent)
 	if !ts.opts.NoHistory {
 		finalMsg := providers.Message{
@@ -65,16 +69,7 @@ func (p *Pipeline) Finalize(
 		}
 	}
 
-	if !ts.opts.NoHistory && ts.opts.EnableSummary {
-		al.contextManager.Compact(
-			turnCtx,
-			&CompactRequest{
-				SessionKey: ts.sessionKey,
-				Reason:     ContextCompressReasonSummarize,
-				Budget:     ts.agent.ContextWindow,
-			},
-		)
-	}
+	compactionPreview := al.compactAfterTurn(turnCtx, ts)
 
 	contextUsage := computeContextUsage(ts.agent, ts.sessionKey)
 	streamErr := finalizeConfiguredStreamingLLM(turnCtx, ts, exec, finalContent, contextUsage)
@@ -89,6 +84,9 @@ func (p *Pipeline) Finalize(
 		markFinalOutbound(&msg)
 		_ = al.bus.PublishOutbound(turnCtx, msg)
 	}
+	if compactionPreview != "" {
+		_ = al.bus.PublishOutbound(turnCtx, outboundMessageForTurn(ts, compactionPreview))
+	}
 	if streamErr != nil && isConfiguredStreamingVisibleError(streamErr) {
 		ts.setPhase(TurnPhaseCompleted)
 		return turnResult{
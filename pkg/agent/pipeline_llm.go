@@ -10,7 +10,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/sipeed/picoclaw/pkg/constants"
 	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
@@ -36,26 +35,15 @@ func (p *Pipeline) CallLLM(
 
 	// PreLLM: graceful terminal handling
 	exec.gracefulTerminal, _ = ts.gracefulInterruptRequested()
-	exec.providerToolDefs = ts.agent.Tools.ToProviderDefs()
-	exec.providerToolDefs = filterToolsByTurnProfile(exec.providerToolDefs, ts.profile)
-
-	// Native web search support
-	webSearchEnabled := al.cfg.Tools.IsToolEnabled("web") && turnProfileToolAllowed(ts.profile, "web_search")
-	exec.useNativeSearch = webSearchEnabled && al.cfg.Tools.Web.PreferNative &&
-		func() bool {
-			if ns, ok := ts.agent.Provider.(providers.NativeSearchCapable); ok {
-				return ns.SupportsNativeSearch()
-			}
-			return false
-		}()
-	if exec.useNativeSearch {
-		filtered := make([]providers.ToolDefinition, 0, len(exec.providerToolDefs))
-		for _, td := range exec.providerToolDefs {
-			if td.Function.Name != "web_search" {
-				filtered = append(filtered, td)
-			}
-		}
-		exec.providerToolDefs = filtered
+	var droppedToolDefs []string
+	exec.providerToolDefs, exec.useNativeSearch, droppedToolDefs = al.turnToolDefs(ts, exec.messages)
+	if len(droppedToolDefs) > 0 {
+		logger.InfoCF("agent", "Left out tool definitions over max_tool_defs", map[string]any{
+			"agent_id":    ts.agent.ID,
+			"session_key": ts.sessionKey,
+			"sent":        len(exec.providerToolDefs),
+			"dropped":     droppedToolDefs,
+		})
 	}
 
 	exec.callMessages = exec.messages
@@ -68,11 +56,15 @@ func (p *Pipeline) CallLLM(
 		return ControlBreak, err
 	}
 
+	exec.modelParams = ts.agent.modelParams(exec.llmModelName, exec.activeModel)
 	exec.llmOpts = map[string]any{
-		"max_tokens":       ts.agent.MaxTokens,
-		"temperature":      ts.agent.Temperature,
+		"max_tokens":       exec.modelParams.MaxTokens,
+		"temperature":      *exec.modelParams.Temperature,
 		"prompt_cache_key": ts.agent.ID,
 	}
+	if len(exec.modelParams.StopSequences) > 0 {
+		exec.llmOpts["stop"] = exec.modelParams.StopSequences
+	}
 	if exec.useNativeSearch {
 		exec.llmOpts["native_search"] = true
 	}
@@ -128,8 +120,8 @@ func (p *Pipeline) CallLLM(
 			Model:         exec.llmModel,
 			MessagesCount: len(exec.callMessages),
 			ToolsCount:    len(exec.providerToolDefs),
-			MaxTokens:     ts.agent.MaxTokens,
-			Temperature:   ts.agent.Temperature,
+			MaxTokens:     exec.modelParams.MaxTokens,
+			Temperature:   *exec.modelParams.Temperature,
 		},
 	)
 
@@ -140,8 +132,8 @@ func (p *Pipeline) CallLLM(
 			"model":             exec.llmModel,
 			"messages_count":    len(exec.callMessages),
 			"tools_count":       len(exec.providerToolDefs),
-			"max_tokens":        ts.agent.MaxTokens,
-			"temperature":       ts.agent.Temperature,
+			"max_tokens":        exec.modelParams.MaxTokens,
+			"temperature":       *exec.modelParams.Temperature,
 			"system_prompt_len": len(exec.callMessages[0].Content),
 		})
 	logger.DebugCF("agent", "Full LLM request",
@@ -163,13 +155,17 @@ func (p *Pipeline) CallLLM(
 		al.activeRequests.Add(1)
 		defer al.activeRequests.Done()
 
+		activeMessages := messagesForProvider(exec.activeProvider, messagesForCall)
 		if response, handled, streamErr := p.tryConfiguredStreamingLLM(
 			providerCtx,
 			ts,
 			exec,
-			messagesForCall,
+			activeMessages,
 			toolDefsForCall,
 		); handled {
+			if streamErr == nil {
+				streamErr = providers.CheckContentPolicy(response)
+			}
 			return response, streamErr
 		}
 
@@ -199,7 +195,17 @@ func (p *Pipeline) CallLLM(
 			candidateThinking := thinkingSettingsFromModelConfig(candidateCfg)
 			applyThinkingOption(callOpts, candidateProvider, candidateThinking, true, ts.agent.ID)
 			exec.suppressReasoning = shouldSuppressReasoningFor(candidateThinking)
-			return candidateProvider.Chat(ctx, messagesForCall, toolDefsForCall, candidate.Model, callOpts)
+			resp, err := candidateProvider.Chat(
+				ctx,
+				messagesForProvider(candidateProvider, messagesForCall),
+				toolDefsForCall,
+				candidate.Model,
+				callOpts,
+			)
+			if err == nil {
+				err = providers.CheckContentPolicy(resp)
+			}
+			return resp, err
 		}
 
 		if len(exec.activeCandidates) > 1 && p.Fallback != nil {
@@ -252,7 +258,11 @@ func (p *Pipeline) CallLLM(
 			}
 			return fbResult.Response, nil
 		}
-		return exec.activeProvider.Chat(providerCtx, messagesForCall, toolDefsForCall, exec.llmModel, exec.llmOpts)
+		resp, err := exec.activeProvider.Chat(providerCtx, activeMessages, toolDefsForCall, exec.llmModel, exec.llmOpts)
+		if err == nil {
+			err = providers.CheckContentPolicy(resp)
+		}
+		return resp, err
 	}
 
 	// Retry loop
@@ -266,6 +276,7 @@ func (p *Pipeline) CallLLM(
 		backoffSecs = 2
 	}
 	for retry := 0; retry <= maxRetries; retry++ {
+		exec.partialContent = ""
 		exec.response, err = callLLM(exec.callMessages, exec.providerToolDefs)
 		if err == nil {
 			break
@@ -278,12 +289,39 @@ func (p *Pipeline) CallLLM(
 		if isConfiguredStreamingVisibleError(err) {
 			break
 		}
+		if _, refused := providers.AsContentPolicyError(err); refused {
+			break
+		}
 
 		if hasMediaRefs(exec.callMessages) && isVisionUnsupportedError(err) {
-			return ControlBreak, visionUnsupportedModelError(
-				exec.llmModelName,
-				len(ts.agent.ImageCandidates) > 0,
+			// Images the model explicitly asked for (load_image) or a configured
+			// image model that still rejects images are configuration problems.
+			// Plain user attachments are skipped with a note instead.
+			if len(ts.agent.ImageCandidates) > 0 || messagesContainToolLoadedMedia(exec.callMessages) ||
+				retry == maxRetries {
+				return ControlBreak, visionUnsupportedModelError(
+					exec.llmModelName,
+					len(ts.agent.ImageCandidates) > 0,
+				)
+			}
+			al.emitEvent(
+				runtimeevents.KindAgentLLMRetry,
+				ts.eventMeta("runTurn", "turn.llm.retry"),
+				LLMRetryPayload{
+					Attempt:    retry + 1,
+					MaxRetries: maxRetries,
+					Reason:     "vision_unsupported",
+					Error:      err.Error(),
+				},
 			)
+			logger.WarnCF("agent", "Model does not support image input, retrying without attachments",
+				map[string]any{
+					"agent_id": ts.agent.ID,
+					"model":    exec.llmModelName,
+				})
+			exec.messages = stripAttachedMediaWithNote(exec.messages, exec.llmModelName)
+			exec.callMessages = stripAttachedMediaWithNote(exec.callMessages, exec.llmModelName)
+			continue
 		}
 
 		errMsg := strings.ToLower(err.Error())
@@ -349,13 +387,7 @@ func (p *Pipeline) CallLLM(
 				},
 			)
 
-			if retry == 0 && !constants.IsInternalChannel(ts.channel) {
-				al.bus.PublishOutbound(ctx, outboundMessageForTurn(
-					ts,
-					"Context window exceeded. Compressing history and retrying...",
-				))
-			}
-
+			historyBeforeCompact := len(exec.history)
 			if compactErr := p.ContextManager.Compact(ctx, &CompactRequest{
 				SessionKey: ts.sessionKey,
 				Reason:     ContextCompressReasonRetry,
@@ -375,6 +407,9 @@ func (p *Pipeline) CallLLM(
 				exec.history = asmResp.History
 				exec.summary = asmResp.Summary
 			}
+			if retry == 0 {
+				al.notifyContextCompressed(ctx, ts, historyBeforeCompact-len(exec.history))
+			}
 			contextualSkills := ts.activeSkills
 			if ts.agent.ContextBuilder != nil {
 				contextualSkills = ts.agent.ContextBuilder.ResolveActiveSkillsForContext(ts.activeSkills)
@@ -400,6 +435,7 @@ func (p *Pipeline) CallLLM(
 			var fit bool
 			var trimmedStableHistory []providers.Message
 			trimmedStableHistory, exec.callMessages, fit = trimHistoryToFitContextWindow(
+				ts.agent.Tokenizer(),
 				stableHistory,
 				func(trimmedHistory []providers.Message) []providers.Message {
 					rebuilt := buildMessages(trimmedHistory)
@@ -451,7 +487,25 @@ func (p *Pipeline) CallLLM(
 		break
 	}
 
+	if refusal, refused := providers.AsContentPolicyError(err); refused {
+		// A refusal is the model's answer, not a malfunction: reply with it
+		// instead of failing the turn with a provider error.
+		logger.WarnCF("agent", "Model declined the request",
+			map[string]any{
+				"agent_id":  ts.agent.ID,
+				"iteration": iteration,
+				"model":     exec.llmModel,
+				"error":     err.Error(),
+			})
+		exec.response = &providers.LLMResponse{
+			Content:      contentPolicyResponse(refusal),
+			FinishReason: "content_filter",
+		}
+		err = nil
+	}
+
 	if err != nil {
+		al.providerHealth.recordFailure(err)
 		al.emitEvent(
 			runtimeevents.KindAgentError,
 			ts.eventMeta("runTurn", "turn.error"),
@@ -467,7 +521,21 @@ func (p *Pipeline) CallLLM(
 				"model":     exec.llmModel,
 				"error":     err.Error(),
 			})
-		return ControlBreak, fmt.Errorf("LLM call failed after retries: %w", err)
+		return ControlBreak, &llmCallError{err: err}
+	}
+	al.providerHealth.recordSuccess()
+
+	// A reply cut off by max_tokens is continued with follow-up calls. A
+	// streamed reply has already been shown as it was, so it is left alone.
+	if exec.streamingPublisher == nil {
+		exec.response = continueTruncatedResponse(
+			exec.response,
+			exec.callMessages,
+			exec.providerToolDefs,
+			p.Cfg.Agents.Defaults.GetMaxContinuations(),
+			callLLM,
+			map[string]any{"agent_id": ts.agent.ID, "iteration": iteration, "model": exec.llmModel},
+		)
 	}
 
 	// AfterLLM hook
@@ -528,13 +596,10 @@ func (p *Pipeline) CallLLM(
 			// thought message in CI even though the LLM produced reasoning content.
 			al.publishPicoReasoning(turnCtx, reasoningContent, ts.chatID, ts.sessionKey, exec.llmModelName)
 		}
-	} else {
-		go al.handleReasoning(
-			turnCtx,
-			reasoningContent,
-			ts.channel,
-			al.targetReasoningChannelID(ts.channel),
-		)
+	} else if reasoningChannelID := al.targetReasoningChannelID(ts.channel); reasoningChannelID != "" {
+		go al.handleReasoning(turnCtx, reasoningContent, ts.channel, reasoningChannelID)
+	} else if al.showsReasoningInChat(ts.channel) {
+		al.publishReasoningToChat(turnCtx, ts, reasoningContent, exec.llmModelName)
 	}
 	al.emitEvent(
 		runtimeevents.KindAgentLLMResponse,
@@ -559,6 +624,13 @@ func (p *Pipeline) CallLLM(
 		llmResponseFields["prompt_tokens"] = exec.response.Usage.PromptTokens
 		llmResponseFields["completion_tokens"] = exec.response.Usage.CompletionTokens
 		llmResponseFields["total_tokens"] = exec.response.Usage.TotalTokens
+		if exec.response.Usage.CacheReadTokens > 0 || exec.response.Usage.CacheCreationTokens > 0 {
+			llmResponseFields["cache_read_tokens"] = exec.response.Usage.CacheReadTokens
+			llmResponseFields["cache_creation_tokens"] = exec.response.Usage.CacheCreationTokens
+		}
+		if exec.response.Usage.ReasoningTokens > 0 {
+			llmResponseFields["reasoning_tokens"] = exec.response.Usage.ReasoningTokens
+		}
 	}
 	logger.DebugCF("agent", "LLM response", llmResponseFields)
 
@@ -593,8 +665,13 @@ func (p *Pipeline) CallLLM(
 
 	// Tool-call path: normalize and prepare for tool execution
 	exec.normalizedToolCalls = make([]providers.ToolCall, 0, len(exec.response.ToolCalls))
+	repairArguments := al.cfg.Agents.Defaults.IsToolCallRepairEnabled()
 	for _, tc := range exec.response.ToolCalls {
-		exec.normalizedToolCalls = append(exec.normalizedToolCalls, providers.NormalizeToolCall(tc))
+		tc = providers.NormalizeToolCall(tc)
+		if repairArguments {
+			tc = repairToolCallArguments(ts.agent.Tools, tc)
+		}
+		exec.normalizedToolCalls = append(exec.normalizedToolCalls, tc)
 	}
 
 	toolNames := make([]string, 0, len(exec.normalizedToolCalls))
@@ -688,6 +765,16 @@ func (p *Pipeline) applyBeforeLLMModelRewrite(ts *turnState, exec *turnExecution
 	exec.activeModel = resolvedCandidateModel(candidates, rawModel)
 	exec.llmModel = exec.activeModel
 	exec.activeModelConfig = resolveActiveModelConfig(p.Cfg, ts.agent.Workspace, candidates, rawModel, defaultProvider)
+	exec.modelParams = ts.agent.modelParams(rawModel, exec.activeModel)
+}
+
+// contentPolicyResponse is the reply for a refused request. The provider's
+// own refusal text, when it sent one, follows the notice.
+func contentPolicyResponse(refusal *providers.ContentPolicyError) string {
+	if refusal == nil || refusal.Reason == "" {
+		return contentPolicyReply
+	}
+	return contentPolicyReply + "\n\n" + refusal.Reason
 }
 
 func providerForFallbackCandidate(
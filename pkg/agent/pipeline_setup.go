@@ -31,26 +31,52 @@ func (p *Pipeline) SetupTurn(ctx context.Context, ts *turnState) (*turnExecution
 	}
 	ts.captureRestorePoint(history, summary)
 
+	// Retrying the message of a turn that failed mid-way resumes from the
+	// saved tool results or partial reply instead of starting over.
+	promptMessage, promptMedia := ts.userMessage, ts.media
+	resumedMessages := 0
+	if resume := p.al.takeResumableTurn(ts); resume != nil {
+		promptMessage, promptMedia = resume.continuation(), nil
+		for _, msg := range resume.messages {
+			ts.recordPersistedMessage(msg)
+		}
+		resumedMessages = len(resume.messages)
+		ts.refreshRestorePointFromSession(ts.agent)
+		logger.InfoCF("agent", "Resuming partial turn after LLM failure", map[string]any{
+			"agent_id":    ts.agent.ID,
+			"session_key": ts.sessionKey,
+			"messages":    resumedMessages,
+		})
+	}
+	turnStart := func(built []providers.Message) int {
+		start := len(built) - resumedMessages
+		if strings.TrimSpace(promptMessage) != "" || len(promptMedia) > 0 {
+			start--
+		}
+		return max(start, 0)
+	}
+
 	contextualSkills := ts.activeSkills
 	if ts.agent.ContextBuilder != nil {
 		contextualSkills = ts.agent.ContextBuilder.ResolveActiveSkillsForContext(ts.activeSkills)
 	}
 	ts.recordSkillContextSnapshot(skillContextTriggerInitialBuild, contextualSkills)
-	initialPromptReq := promptBuildRequestForTurn(ts, history, summary, ts.userMessage, ts.media, cfg)
+	initialPromptReq := promptBuildRequestForTurn(ts, history, summary, promptMessage, promptMedia, cfg)
 	initialPromptReq.ActiveSkills = append([]string(nil), contextualSkills...)
 	messages := ts.agent.ContextBuilder.BuildMessagesFromPrompt(initialPromptReq)
-	currentTurnStart := len(messages)
-	if strings.TrimSpace(ts.userMessage) != "" || len(ts.media) > 0 {
-		currentTurnStart = len(messages) - 1
-	}
+	currentTurnStart := turnStart(messages)
 
+	if ts.agent.AudioInput {
+		messages = inlineCurrentTurnAudio(messages, p.MediaStore, maxMediaSize, currentTurnStart)
+	}
 	messages = resolveMediaRefs(messages, p.MediaStore, maxMediaSize, currentTurnStart)
 
 	if !ts.opts.NoHistory {
-		toolDefs := filterToolsByTurnProfile(ts.agent.Tools.ToProviderDefs(), ts.profile)
-		if isOverContextBudget(ts.agent.ContextWindow, messages, toolDefs, ts.agent.MaxTokens) {
+		toolDefs, _, _ := p.al.turnToolDefs(ts, messages)
+		if isOverContextBudget(ts.agent.Tokenizer(), ts.agent.ContextWindow, messages, toolDefs, ts.agent.MaxTokens) {
 			logger.WarnCF("agent", "Proactive compression: context budget exceeded before LLM call",
 				map[string]any{"session_key": ts.sessionKey})
+			historyBeforeCompact := len(history)
 			if err := p.ContextManager.Compact(ctx, &CompactRequest{
 				SessionKey: ts.sessionKey,
 				Reason:     ContextCompressReasonProactive,
@@ -70,26 +96,27 @@ func (p *Pipeline) SetupTurn(ctx context.Context, ts *turnState) (*turnExecution
 				history = resp.History
 				summary = resp.Summary
 			}
+			p.al.notifyContextCompressed(ctx, ts, historyBeforeCompact-len(history))
 			originalHistoryCount := len(history)
 			var fit bool
 			history, messages, fit = trimHistoryToFitContextWindow(
+				ts.agent.Tokenizer(),
 				history,
 				func(trimmedHistory []providers.Message) []providers.Message {
 					rebuildPromptReq := promptBuildRequestForTurn(
 						ts,
 						trimmedHistory,
 						summary,
-						ts.userMessage,
-						ts.media,
+						promptMessage,
+						promptMedia,
 						cfg,
 					)
 					rebuildPromptReq.ActiveSkills = append([]string(nil), contextualSkills...)
 					rebuilt := ts.agent.ContextBuilder.BuildMessagesFromPrompt(rebuildPromptReq)
-					rebuiltCurrentTurnStart := len(rebuilt)
-					if strings.TrimSpace(ts.userMessage) != "" || len(ts.media) > 0 {
-						rebuiltCurrentTurnStart = len(rebuilt) - 1
+					if ts.agent.AudioInput {
+						rebuilt = inlineCurrentTurnAudio(rebuilt, p.MediaStore, maxMediaSize, turnStart(rebuilt))
 					}
-					return resolveMediaRefs(rebuilt, p.MediaStore, maxMediaSize, rebuiltCurrentTurnStart)
+					return resolveMediaRefs(rebuilt, p.MediaStore, maxMediaSize, turnStart(rebuilt))
 				},
 				ts.agent.ContextWindow,
 				toolDefs,
@@ -116,8 +143,8 @@ func (p *Pipeline) SetupTurn(ctx context.Context, ts *turnState) (*turnExecution
 		}
 	}
 
-	if !ts.opts.NoHistory && (strings.TrimSpace(ts.userMessage) != "" || len(ts.media) > 0) {
-		rootMsg := userPromptMessage(ts.userMessage, ts.media)
+	if !ts.opts.NoHistory && (strings.TrimSpace(promptMessage) != "" || len(promptMedia) > 0) {
+		rootMsg := userPromptMessage(promptMessage, promptMedia)
 		if len(rootMsg.Media) > 0 {
 			ts.agent.Sessions.AddFullMessage(ts.sessionKey, rootMsg)
 		} else {
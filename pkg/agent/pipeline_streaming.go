@@ -89,6 +89,7 @@ func (p *Pipeline) tryConfiguredStreamingLLM(
 					publisher.UpdateReasoning(ctx, chunk.ReasoningContent)
 				}
 				if strings.TrimSpace(chunk.Content) != "" {
+					exec.partialContent = chunk.Content
 					publisher.Update(ctx, chunk.Content)
 				}
 			},
@@ -102,6 +103,7 @@ func (p *Pipeline) tryConfiguredStreamingLLM(
 			exec.llmOpts,
 			func(accumulated string) {
 				recordChunk()
+				exec.partialContent = accumulated
 				publisher.Update(ctx, accumulated)
 			},
 		)
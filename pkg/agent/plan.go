@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// planPromptContributor shows the task plan the agent keeps with the plan
+// tool, so it stays on track after compaction and across restarts.
+type planPromptContributor struct {
+	plan func(sessionKey string) []state.PlanStep
+}
+
+func (c planPromptContributor) PromptSource() PromptSourceDescriptor {
+	return PromptSourceDescriptor{
+		ID:              PromptSourcePlan,
+		Owner:           "tools",
+		Description:     "Task plan kept with the plan tool",
+		Allowed:         []PromptPlacement{{Layer: PromptLayerContext, Slot: PromptSlotPlan}},
+		StableByDefault: false,
+	}
+}
+
+func (c planPromptContributor) ContributePrompt(
+	_ context.Context,
+	req PromptBuildRequest,
+) ([]PromptPart, error) {
+	if c.plan == nil || strings.TrimSpace(req.SessionKey) == "" {
+		return nil, nil
+	}
+	steps := c.plan(req.SessionKey)
+	if len(steps) == 0 {
+		return nil, nil
+	}
+
+	content := "# Current Plan\n\nYou are working through this plan with the plan tool. " +
+		"Mark steps in_progress and done as you go, and clear the plan when the task is finished.\n\n" +
+		tools.FormatPlan(steps)
+
+	return []PromptPart{
+		{
+			ID:      "context.plan",
+			Layer:   PromptLayerContext,
+			Slot:    PromptSlotPlan,
+			Source:  PromptSource{ID: PromptSourcePlan, Name: "session:plan"},
+			Title:   "task plan",
+			Content: content,
+			Stable:  false,
+			Cache:   PromptCacheNone,
+		},
+	}, nil
+}
@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func TestContextBuilder_IncludesPlanForSession(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+	cb := NewContextBuilder(t.TempDir())
+	plans := map[string][]state.PlanStep{
+		"session-1": {{Text: "collect logs", Status: "done"}, {Text: "find the bug", Status: "in_progress"}},
+	}
+	if err := cb.RegisterPromptContributor(planPromptContributor{
+		plan: func(sessionKey string) []state.PlanStep { return plans[sessionKey] },
+	}); err != nil {
+		t.Fatalf("RegisterPromptContributor() error = %v", err)
+	}
+
+	system := cb.BuildMessagesFromPrompt(PromptBuildRequest{SessionKey: "session-1", CurrentMessage: "go on"})[0]
+	if !strings.Contains(system.Content, "1. [x] collect logs\n2. [~] find the bug") {
+		t.Fatalf("system prompt missing plan: %q", system.Content)
+	}
+
+	// Updates show up on the next turn.
+	plans["session-1"][1].Status = "done"
+	system = cb.BuildMessagesFromPrompt(PromptBuildRequest{SessionKey: "session-1", CurrentMessage: "go on"})[0]
+	if !strings.Contains(system.Content, "(2 of 2 steps finished)") {
+		t.Fatalf("system prompt did not reflect plan update: %q", system.Content)
+	}
+
+	system = cb.BuildMessagesFromPrompt(PromptBuildRequest{SessionKey: "session-2", CurrentMessage: "hi"})[0]
+	if strings.Contains(system.Content, "Current Plan") {
+		t.Fatalf("plan leaked into another session: %q", system.Content)
+	}
+}
@@ -32,6 +32,8 @@ const (
 	PromptSlotSkillCatalog PromptSlot = "skill_catalog"
 	PromptSlotActiveSkill  PromptSlot = "active_skill"
 	PromptSlotMemory       PromptSlot = "memory"
+	PromptSlotPinnedFiles  PromptSlot = "pinned_files"
+	PromptSlotPlan         PromptSlot = "plan"
 	PromptSlotRuntime      PromptSlot = "runtime"
 	PromptSlotSummary      PromptSlot = "summary"
 	PromptSlotMessage      PromptSlot = "message"
@@ -45,24 +47,32 @@ const (
 type PromptSourceID string
 
 const (
-	PromptSourceKernel         PromptSourceID = "runtime.kernel"
-	PromptSourceHierarchy      PromptSourceID = "runtime.hierarchy"
-	PromptSourceWorkspace      PromptSourceID = "workspace.definition"
-	PromptSourceRuntime        PromptSourceID = "runtime.context"
-	PromptSourceSummary        PromptSourceID = "context.summary"
-	PromptSourceMemory         PromptSourceID = "memory:workspace"
-	PromptSourceSkillCatalog   PromptSourceID = "skill:index"
-	PromptSourceActiveSkills   PromptSourceID = "skill:active"
-	PromptSourceAgentDiscovery PromptSourceID = "agent:discovery"
-	PromptSourceToolRegistry   PromptSourceID = "tool_registry:native"
-	PromptSourceToolDiscovery  PromptSourceID = "tool_registry:discovery"
-	PromptSourceOutputPolicy   PromptSourceID = "runtime.output"
-	PromptSourceSubTurnProfile PromptSourceID = "subturn.profile"
-	PromptSourceUserMessage    PromptSourceID = "turn:user_message"
-	PromptSourceSteering       PromptSourceID = "turn:steering"
-	PromptSourceSubTurnResult  PromptSourceID = "turn:subturn_result"
-	PromptSourceToolResult     PromptSourceID = "turn:tool_result"
-	PromptSourceInterrupt      PromptSourceID = "turn:interrupt"
+	PromptSourceKernel           PromptSourceID = "runtime.kernel"
+	PromptSourceHierarchy        PromptSourceID = "runtime.hierarchy"
+	PromptSourceWorkspace        PromptSourceID = "workspace.definition"
+	PromptSourceRuntime          PromptSourceID = "runtime.context"
+	PromptSourceContextProviders PromptSourceID = "runtime.providers"
+	PromptSourceSummary          PromptSourceID = "context.summary"
+	PromptSourceMemory           PromptSourceID = "memory:workspace"
+	PromptSourceMemoryKeys       PromptSourceID = "memory:keys"
+	PromptSourcePinnedFiles      PromptSourceID = "workspace:pinned"
+	PromptSourcePlan             PromptSourceID = "session:plan"
+	PromptSourceAttachments      PromptSourceID = "session:attachments"
+	PromptSourceSkillCatalog     PromptSourceID = "skill:index"
+	PromptSourceActiveSkills     PromptSourceID = "skill:active"
+	PromptSourceAgentDiscovery   PromptSourceID = "agent:discovery"
+	PromptSourceToolRegistry     PromptSourceID = "tool_registry:native"
+	PromptSourceToolDiscovery    PromptSourceID = "tool_registry:discovery"
+	PromptSourceOutputPolicy     PromptSourceID = "runtime.output"
+	PromptSourceSubTurnProfile   PromptSourceID = "subturn.profile"
+	PromptSourceChannelPersona   PromptSourceID = "channel:persona"
+	PromptSourcePeerPreferences  PromptSourceID = "peer:preferences"
+	PromptSourcePeerLanguage     PromptSourceID = "peer:language"
+	PromptSourceUserMessage      PromptSourceID = "turn:user_message"
+	PromptSourceSteering         PromptSourceID = "turn:steering"
+	PromptSourceSubTurnResult    PromptSourceID = "turn:subturn_result"
+	PromptSourceToolResult       PromptSourceID = "turn:tool_result"
+	PromptSourceInterrupt        PromptSourceID = "turn:interrupt"
 )
 
 type PromptCachePolicy string
@@ -110,10 +120,12 @@ type PromptBuildRequest struct {
 	CurrentMessage string
 	Media          []string
 
+	SessionKey        string
 	Channel           string
 	ChatID            string
 	SenderID          string
 	SenderDisplayName string
+	SenderLocale      string
 
 	ActiveSkills []string
 	Overlays     []PromptPart
@@ -219,6 +231,27 @@ func builtinPromptSources() []PromptSourceDescriptor {
 			Allowed:         []PromptPlacement{{Layer: PromptLayerContext, Slot: PromptSlotMemory}},
 			StableByDefault: true,
 		},
+		{
+			ID:              PromptSourceMemoryKeys,
+			Owner:           "memory",
+			Description:     "Keys saved with the memory tool",
+			Allowed:         []PromptPlacement{{Layer: PromptLayerContext, Slot: PromptSlotMemory}},
+			StableByDefault: false,
+		},
+		{
+			ID:              PromptSourcePinnedFiles,
+			Owner:           "workspace",
+			Description:     "Files pinned to the current session",
+			Allowed:         []PromptPlacement{{Layer: PromptLayerContext, Slot: PromptSlotPinnedFiles}},
+			StableByDefault: false,
+		},
+		{
+			ID:              PromptSourcePlan,
+			Owner:           "tools",
+			Description:     "Task plan kept with the plan tool",
+			Allowed:         []PromptPlacement{{Layer: PromptLayerContext, Slot: PromptSlotPlan}},
+			StableByDefault: false,
+		},
 		{
 			ID:              PromptSourceRuntime,
 			Owner:           "agent",
@@ -226,6 +259,20 @@ func builtinPromptSources() []PromptSourceDescriptor {
 			Allowed:         []PromptPlacement{{Layer: PromptLayerContext, Slot: PromptSlotRuntime}},
 			StableByDefault: false,
 		},
+		{
+			ID:              PromptSourceContextProviders,
+			Owner:           "agent",
+			Description:     "Dynamic context from registered context providers",
+			Allowed:         []PromptPlacement{{Layer: PromptLayerContext, Slot: PromptSlotRuntime}},
+			StableByDefault: false,
+		},
+		{
+			ID:              PromptSourceAttachments,
+			Owner:           "agent",
+			Description:     "Attachments staged in the workspace for the current session",
+			Allowed:         []PromptPlacement{{Layer: PromptLayerContext, Slot: PromptSlotRuntime}},
+			StableByDefault: false,
+		},
 		{
 			ID:              PromptSourceSummary,
 			Owner:           "context_manager",
@@ -247,6 +294,27 @@ func builtinPromptSources() []PromptSourceDescriptor {
 			Allowed:         []PromptPlacement{{Layer: PromptLayerInstruction, Slot: PromptSlotWorkspace}},
 			StableByDefault: false,
 		},
+		{
+			ID:              PromptSourceChannelPersona,
+			Owner:           "channels",
+			Description:     "Persona prompt configured for the originating channel",
+			Allowed:         []PromptPlacement{{Layer: PromptLayerInstruction, Slot: PromptSlotWorkspace}},
+			StableByDefault: true,
+		},
+		{
+			ID:              PromptSourcePeerPreferences,
+			Owner:           "agent",
+			Description:     "Answer preferences the sender set with :set",
+			Allowed:         []PromptPlacement{{Layer: PromptLayerInstruction, Slot: PromptSlotWorkspace}},
+			StableByDefault: false,
+		},
+		{
+			ID:              PromptSourcePeerLanguage,
+			Owner:           "agent",
+			Description:     "Reply language detected from the user's messages",
+			Allowed:         []PromptPlacement{{Layer: PromptLayerInstruction, Slot: PromptSlotWorkspace}},
+			StableByDefault: false,
+		},
 		{
 			ID:              PromptSourceUserMessage,
 			Owner:           "turn",
@@ -438,7 +506,7 @@ func renderPromptPartsLegacy(parts []PromptPart) string {
 		}
 		textParts = append(textParts, part.Content)
 	}
-	return strings.Join(textParts, "\n\n---\n\n")
+	return strings.Join(textParts, systemPartSeparator)
 }
 
 func sortPromptParts(parts []PromptPart) []PromptPart {
@@ -493,6 +561,10 @@ func slotPriority(slot PromptSlot) int {
 		return 770
 	case PromptSlotMemory:
 		return 700
+	case PromptSlotPinnedFiles:
+		return 698
+	case PromptSlotPlan:
+		return 697
 	case PromptSlotOutput:
 		return 695
 	case PromptSlotRuntime:
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
@@ -157,6 +158,113 @@ func (c agentDiscoveryPromptContributor) ContributePrompt(
 	}, nil
 }
 
+// channelPersonaPromptContributor injects the persona prompt configured for
+// the channel a turn originated from, keyed by channel name.
+type channelPersonaPromptContributor struct {
+	personas map[string]string
+}
+
+func (c channelPersonaPromptContributor) PromptSource() PromptSourceDescriptor {
+	return PromptSourceDescriptor{
+		ID:              PromptSourceChannelPersona,
+		Owner:           "channels",
+		Description:     "Persona prompt configured for the originating channel",
+		Allowed:         []PromptPlacement{{Layer: PromptLayerInstruction, Slot: PromptSlotWorkspace}},
+		StableByDefault: true,
+	}
+}
+
+func (c channelPersonaPromptContributor) ContributePrompt(
+	_ context.Context,
+	req PromptBuildRequest,
+) ([]PromptPart, error) {
+	channel := strings.TrimSpace(req.Channel)
+	persona := c.personas[channel]
+	if channel == "" || persona == "" {
+		return nil, nil
+	}
+
+	return []PromptPart{
+		{
+			ID:     "instruction.channel_persona",
+			Layer:  PromptLayerInstruction,
+			Slot:   PromptSlotWorkspace,
+			Source: PromptSource{ID: PromptSourceChannelPersona, Name: "channel:" + channel},
+			Title:  "channel persona",
+			Content: fmt.Sprintf(
+				"## Channel Persona\n\nThis conversation is on the %s channel. "+
+					"Follow these channel-specific instructions:\n\n%s",
+				channel,
+				persona,
+			),
+			Stable: true,
+			Cache:  PromptCacheEphemeral,
+		},
+	}, nil
+}
+
+// maxPromptMemoryKeys bounds how many memory keys are listed in the prompt;
+// the model can call the memory tool's list action for the rest.
+const maxPromptMemoryKeys = 50
+
+// memoryKeysPromptContributor lists the keys saved with the memory tool on
+// every turn, so the model knows what it can recall without a tool call.
+type memoryKeysPromptContributor struct {
+	workspace string
+}
+
+func (c memoryKeysPromptContributor) PromptSource() PromptSourceDescriptor {
+	return PromptSourceDescriptor{
+		ID:              PromptSourceMemoryKeys,
+		Owner:           "memory",
+		Description:     "Keys saved with the memory tool",
+		Allowed:         []PromptPlacement{{Layer: PromptLayerContext, Slot: PromptSlotMemory}},
+		StableByDefault: false,
+	}
+}
+
+func (c memoryKeysPromptContributor) ContributePrompt(
+	_ context.Context,
+	req PromptBuildRequest,
+) ([]PromptPart, error) {
+	if !promptAllowsTool(req, "memory") {
+		return nil, nil
+	}
+	keys, err := tools.MemoryKeys(c.workspace)
+	if err != nil {
+		// A damaged memory file must not take the other contributors down
+		// with it; the tool reports the error when the model uses it.
+		logger.WarnCF("agent", "Failed to read memory keys", map[string]any{"error": err.Error()})
+		return nil, nil
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	listed := keys
+	if len(listed) > maxPromptMemoryKeys {
+		listed = listed[:maxPromptMemoryKeys]
+	}
+	content := "# Saved Memories\n\nYou have saved values under these keys; use the memory tool's " +
+		"recall action to read one: " + strings.Join(listed, ", ")
+	if extra := len(keys) - len(listed); extra > 0 {
+		content += fmt.Sprintf(" (and %d more; use list to see all)", extra)
+	}
+
+	return []PromptPart{
+		{
+			ID:      "context.memory_keys",
+			Layer:   PromptLayerContext,
+			Slot:    PromptSlotMemory,
+			Source:  PromptSource{ID: PromptSourceMemoryKeys, Name: "memory:keys"},
+			Title:   "memory keys",
+			Content: content,
+			Stable:  false,
+			Cache:   PromptCacheNone,
+		},
+	}, nil
+}
+
 func mcpPromptSourceID(serverName string) PromptSourceID {
 	return PromptSourceID("mcp:" + promptSourceComponent(serverName))
 }
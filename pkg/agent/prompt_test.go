@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
 func TestPromptRegistry_RejectsRegisteredSourceWrongPlacement(t *testing.T) {
@@ -447,3 +450,87 @@ func TestContextBuilder_CollectsRegisteredPromptContributors(t *testing.T) {
 		t.Fatalf("system prompt missing contributor content: %q", messages[0].Content)
 	}
 }
+
+func TestContextBuilder_ChannelPersonaAppliesToOriginatingChannelOnly(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+	cb := NewContextBuilder(t.TempDir()).WithChannelPersonas(config.ChannelsConfig{
+		"sms":      &config.Channel{PersonaPrompt: "Reply in one short sentence."},
+		"email":    &config.Channel{PersonaPrompt: "  "},
+		"telegram": &config.Channel{},
+	})
+
+	sms := cb.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi", Channel: "sms"})[0].Content
+	if !strings.Contains(sms, "Reply in one short sentence.") {
+		t.Fatalf("sms prompt missing persona: %q", sms)
+	}
+
+	for _, channel := range []string{"email", "telegram", ""} {
+		system := cb.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi", Channel: channel})[0].Content
+		if strings.Contains(system, "Channel Persona") {
+			t.Fatalf("channel %q received a persona section: %q", channel, system)
+		}
+	}
+}
+
+func TestContextBuilder_ChannelPersonaIsTruncated(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+	long := strings.Repeat("a", config.MaxPersonaPromptChars) + "TAIL"
+	cb := NewContextBuilder(t.TempDir()).WithChannelPersonas(config.ChannelsConfig{
+		"sms": &config.Channel{PersonaPrompt: long},
+	})
+
+	system := cb.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi", Channel: "sms"})[0].Content
+	if strings.Contains(system, "TAIL") {
+		t.Fatal("persona prompt was not truncated")
+	}
+}
+
+func TestContextBuilder_IdentityUsesConfiguredName(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+
+	system := NewContextBuilder(t.TempDir()).BuildSystemPrompt()
+	if !strings.Contains(system, "You are picoclaw, a helpful AI assistant.") {
+		t.Fatalf("default prompt lost the picoclaw identity: %q", system)
+	}
+
+	system = NewContextBuilder(t.TempDir()).
+		WithIdentity(" Jarvis ", "You speak like a British butler.").
+		BuildSystemPrompt()
+	if !strings.Contains(system, "# Jarvis (") ||
+		!strings.Contains(system, "You are Jarvis, a helpful AI assistant.\n\nYou speak like a British butler.") {
+		t.Fatalf("prompt missing configured identity: %q", system)
+	}
+	if strings.Contains(system, "You are picoclaw") {
+		t.Fatalf("prompt still names picoclaw: %q", system)
+	}
+}
+
+func TestContextBuilder_MemoryKeysListedEachTurn(t *testing.T) {
+	t.Setenv("PICOCLAW_BUILTIN_SKILLS", t.TempDir())
+	workspace := t.TempDir()
+	cb := NewContextBuilder(workspace).WithMemoryKeys(true)
+
+	system := cb.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi"})[0].Content
+	if strings.Contains(system, "Saved Memories") {
+		t.Fatalf("empty memory should add no section: %q", system)
+	}
+
+	memory := tools.NewMemoryTool(workspace, 0)
+	memory.Execute(context.Background(), map[string]any{"action": "remember", "key": "timezone", "value": "UTC+8"})
+
+	system = cb.BuildMessagesFromPrompt(PromptBuildRequest{CurrentMessage: "hi"})[0].Content
+	if !strings.Contains(system, "Saved Memories") || !strings.Contains(system, "timezone") {
+		t.Fatalf("system prompt missing memory keys: %q", system)
+	}
+	if strings.Contains(system, "UTC+8") {
+		t.Fatal("memory values must not be injected, only keys")
+	}
+
+	system = cb.BuildMessagesFromPrompt(PromptBuildRequest{
+		CurrentMessage: "hi",
+		AllowedTools:   []string{"read_file"},
+	})[0].Content
+	if strings.Contains(system, "Saved Memories") {
+		t.Fatal("memory keys should be hidden when the memory tool is not allowed")
+	}
+}
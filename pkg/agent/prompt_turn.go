@@ -21,10 +21,12 @@ func promptBuildRequestForTurn(
 		Summary:           summary,
 		CurrentMessage:    currentMessage,
 		Media:             append([]string(nil), media...),
+		SessionKey:        ts.sessionKey,
 		Channel:           ts.channel,
 		ChatID:            ts.chatID,
 		SenderID:          ts.opts.Dispatch.SenderID(),
 		SenderDisplayName: ts.opts.SenderDisplayName,
+		SenderLocale:      ts.opts.SenderLocale,
 		ActiveSkills:      activeSkillNames(ts.agent, ts.opts),
 		Overlays:          promptOverlaysForOptions(ts.opts),
 	}
@@ -84,10 +86,12 @@ func promptBuildRequestForProcessOptions(
 		Summary:           summary,
 		CurrentMessage:    currentMessage,
 		Media:             append([]string(nil), media...),
+		SessionKey:        opts.SessionKey,
 		Channel:           opts.Channel,
 		ChatID:            opts.ChatID,
 		SenderID:          opts.SenderID,
 		SenderDisplayName: opts.SenderDisplayName,
+		SenderLocale:      opts.SenderLocale,
 		ActiveSkills:      activeSkillNames(agent, opts),
 		Overlays:          promptOverlaysForOptions(opts),
 	}
@@ -117,12 +121,13 @@ func promptBuildRequestForProcessOptions(
 }
 
 func promptOverlaysForOptions(opts processOptions) []PromptPart {
+	overlays := append(verbosityPromptParts(opts.Verbosity), replyLanguagePromptParts(opts.ReplyLanguage)...)
 	systemPrompt := strings.TrimSpace(opts.SystemPromptOverride)
 	if systemPrompt == "" {
-		return nil
+		return overlays
 	}
 
-	return []PromptPart{
+	return append(overlays, []PromptPart{
 		{
 			ID:      "instruction.subturn_profile",
 			Layer:   PromptLayerInstruction,
@@ -133,7 +138,7 @@ func promptOverlaysForOptions(opts processOptions) []PromptPart {
 			Stable:  false,
 			Cache:   PromptCacheNone,
 		},
-	}
+	}...)
 }
 
 func promptContentBlock(part PromptPart, cache *providers.CacheControl) providers.ContentBlock {
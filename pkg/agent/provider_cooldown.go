@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// ProviderCooldown is the cooldown state of one configured model candidate.
+type ProviderCooldown struct {
+	// Name is the model_name from model_list, or provider/model when the
+	// candidate has no configured name.
+	Name                     string    `json:"name"`
+	Provider                 string    `json:"provider"`
+	Model                    string    `json:"model"`
+	Available                bool      `json:"available"`
+	CooldownRemainingSeconds int64     `json:"cooldown_remaining_seconds,omitempty"`
+	ErrorCount               int       `json:"error_count,omitempty"`
+	DisabledReason           string    `json:"disabled_reason,omitempty"`
+	LastFailure              time.Time `json:"last_failure,omitzero"`
+}
+
+// ProvidersStatus describes the default agent's model, its fallback chain
+// and the cooldown state of every model the agents can call.
+type ProvidersStatus struct {
+	ActiveModel   string             `json:"active_model"`
+	FallbackChain []string           `json:"fallback_chain"`
+	Providers     []ProviderCooldown `json:"providers"`
+}
+
+func candidateName(c providers.FallbackCandidate) string {
+	if c.DisplayName != "" {
+		return c.DisplayName
+	}
+	return providers.ModelKey(c.Provider, c.Model)
+}
+
+// configuredCandidates returns every candidate of every agent once, in
+// registry order: primary chain first, then light, image and summary models.
+func (al *AgentLoop) configuredCandidates() []providers.FallbackCandidate {
+	registry := al.GetRegistry()
+	if registry == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var out []providers.FallbackCandidate
+	for _, agentID := range registry.ListAgentIDs() {
+		agent, ok := registry.GetAgent(agentID)
+		if !ok || agent == nil {
+			continue
+		}
+		for _, list := range [][]providers.FallbackCandidate{
+			agent.Candidates, agent.LightCandidates, agent.ImageCandidates, agent.SummaryCandidates,
+		} {
+			for _, c := range list {
+				if key := c.StableKey(); !seen[key] {
+					seen[key] = true
+					out = append(out, c)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func (al *AgentLoop) cooldownTracker() *providers.CooldownTracker {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+	return al.cooldown
+}
+
+func (al *AgentLoop) candidateCooldown(ct *providers.CooldownTracker, c providers.FallbackCandidate) ProviderCooldown {
+	entry := ProviderCooldown{
+		Name:      candidateName(c),
+		Provider:  c.Provider,
+		Model:     c.Model,
+		Available: true,
+	}
+	if ct == nil {
+		return entry
+	}
+	s := ct.Status(c.StableKey())
+	entry.Available = s.Available
+	entry.CooldownRemainingSeconds = int64((s.Remaining + time.Second - 1) / time.Second)
+	entry.ErrorCount = s.ErrorCount
+	entry.DisabledReason = string(s.DisabledReason)
+	entry.LastFailure = s.LastFailure
+	return entry
+}
+
+// ProvidersStatus reports the active model, its fallback chain and which
+// configured models are cooling down after failures.
+func (al *AgentLoop) ProvidersStatus() ProvidersStatus {
+	status := ProvidersStatus{FallbackChain: []string{}, Providers: []ProviderCooldown{}}
+	if registry := al.GetRegistry(); registry != nil {
+		if agent := registry.GetDefaultAgent(); agent != nil {
+			status.ActiveModel = agent.Model
+			for _, c := range agent.Candidates {
+				status.FallbackChain = append(status.FallbackChain, candidateName(c))
+			}
+		}
+	}
+	ct := al.cooldownTracker()
+	for _, c := range al.configuredCandidates() {
+		status.Providers = append(status.Providers, al.candidateCooldown(ct, c))
+	}
+	return status
+}
+
+// ResetProviderCooldown clears the failures recorded for the model called
+// name (its model_name or provider/model), so the fallback chain tries it
+// again right away. It reports false when no configured model matches.
+func (al *AgentLoop) ResetProviderCooldown(name string) (ProviderCooldown, bool) {
+	ct := al.cooldownTracker()
+	for _, c := range al.configuredCandidates() {
+		if name != candidateName(c) && name != c.StableKey() {
+			continue
+		}
+		if ct != nil {
+			ct.Reset(c.StableKey())
+		}
+		return al.candidateCooldown(ct, c), true
+	}
+	return ProviderCooldown{}, false
+}
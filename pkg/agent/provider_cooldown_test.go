@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestProvidersStatus_ReportsAndResetsCooldown(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:      t.TempDir(),
+				ModelName:      "main",
+				ModelFallbacks: []string{"backup"},
+			},
+		},
+		ModelList: []*config.ModelConfig{
+			{ModelName: "main", Model: "openai/gpt-4o"},
+			{ModelName: "backup", Model: "deepseek/deepseek-chat"},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+
+	status := al.ProvidersStatus()
+	if status.ActiveModel != "main" || len(status.FallbackChain) != 2 ||
+		status.FallbackChain[0] != "main" || status.FallbackChain[1] != "backup" {
+		t.Fatalf("status = %+v", status)
+	}
+
+	al.cooldown.MarkFailure("model_name:backup", providers.FailoverRateLimit)
+	if err := al.ReloadProviderAndConfig(t.Context(), &mockProvider{}, cfg); err != nil {
+		t.Fatalf("ReloadProviderAndConfig() error = %v", err)
+	}
+
+	var backup ProviderCooldown
+	for _, p := range al.ProvidersStatus().Providers {
+		if p.Name == "backup" {
+			backup = p
+		}
+	}
+	if backup.Available || backup.CooldownRemainingSeconds != 60 || backup.ErrorCount != 1 {
+		t.Fatalf("backup after failure and reload = %+v", backup)
+	}
+	if h := al.ProviderHealth(); h.InCooldown != 1 {
+		t.Fatalf("ProviderHealth().InCooldown = %d, want 1", h.InCooldown)
+	}
+
+	if _, ok := al.ResetProviderCooldown("missing"); ok {
+		t.Fatal("ResetProviderCooldown() found an unconfigured model")
+	}
+	reset, ok := al.ResetProviderCooldown("backup")
+	if !ok || !reset.Available || reset.ErrorCount != 0 {
+		t.Fatalf("ResetProviderCooldown() = %+v, %v", reset, ok)
+	}
+}
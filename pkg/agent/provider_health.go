@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// providerUnhealthyAfter is how many LLM calls in a row must fail with a
+// transient error (network, timeout, 5xx) before the provider is reported
+// unhealthy. Errors caused by the request itself do not count.
+const providerUnhealthyAfter = 3
+
+// ProviderHealth is a snapshot of recent LLM call outcomes.
+type ProviderHealth struct {
+	Healthy             bool      `json:"healthy"`
+	ConsecutiveFailures int       `json:"consecutive_failures,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastSuccess         time.Time `json:"last_success,omitzero"`
+	LastFailure         time.Time `json:"last_failure,omitzero"`
+	// InCooldown counts configured models the fallback chain is skipping
+	// after recent failures.
+	InCooldown int `json:"in_cooldown,omitempty"`
+}
+
+type providerHealthTracker struct {
+	mu          sync.Mutex
+	failures    int
+	lastErr     string
+	lastSuccess time.Time
+	lastFailure time.Time
+}
+
+func (t *providerHealthTracker) recordSuccess() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failures = 0
+	t.lastErr = ""
+	t.lastSuccess = time.Now()
+}
+
+// recordFailure counts err against the provider if it suggests the provider
+// is unreachable: a transient error, or a fallback chain with no candidate left.
+func (t *providerHealthTracker) recordFailure(err error) {
+	var exhausted *providers.FallbackExhaustedError
+	if _, transient := transientLLMRetryReason(err); !transient && !errors.As(err, &exhausted) {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failures++
+	t.lastErr = err.Error()
+	t.lastFailure = time.Now()
+}
+
+func (t *providerHealthTracker) snapshot() ProviderHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return ProviderHealth{
+		Healthy:             t.failures < providerUnhealthyAfter,
+		ConsecutiveFailures: t.failures,
+		LastError:           t.lastErr,
+		LastSuccess:         t.lastSuccess,
+		LastFailure:         t.lastFailure,
+	}
+}
+
+// ProviderHealth reports whether recent LLM calls reached the provider, for
+// readiness probes. A provider that has not been called yet is healthy.
+func (al *AgentLoop) ProviderHealth() ProviderHealth {
+	h := al.providerHealth.snapshot()
+	if ct := al.cooldownTracker(); ct != nil {
+		for _, c := range al.configuredCandidates() {
+			if !ct.IsAvailable(c.StableKey()) {
+				h.InCooldown++
+			}
+		}
+	}
+	return h
+}
@@ -0,0 +1,28 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProviderHealthTracker(t *testing.T) {
+	var tracker providerHealthTracker
+
+	tracker.recordFailure(errors.New("invalid request: unknown parameter"))
+	if h := tracker.snapshot(); !h.Healthy || h.ConsecutiveFailures != 0 {
+		t.Fatalf("request errors should not count against the provider: %+v", h)
+	}
+
+	for range providerUnhealthyAfter {
+		tracker.recordFailure(errors.New("dial tcp: connection refused"))
+	}
+	h := tracker.snapshot()
+	if h.Healthy || h.ConsecutiveFailures != providerUnhealthyAfter || h.LastError != "dial tcp: connection refused" {
+		t.Fatalf("snapshot after network errors = %+v", h)
+	}
+
+	tracker.recordSuccess()
+	if h := tracker.snapshot(); !h.Healthy || h.ConsecutiveFailures != 0 || h.LastSuccess.IsZero() {
+		t.Fatalf("snapshot after success = %+v", h)
+	}
+}
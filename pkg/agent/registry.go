@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
@@ -89,6 +90,29 @@ func (r *AgentRegistry) ListAgentIDs() []string {
 	return ids
 }
 
+// Agents returns all registered agent instances sorted by ID.
+func (r *AgentRegistry) Agents() []*AgentInstance {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	agents := make([]*AgentInstance, 0, len(r.agents))
+	for _, agent := range r.agents {
+		agents = append(agents, agent)
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
+	return agents
+}
+
+// ExplainRoute resolves the inbound context and returns the dispatch rule
+// that matched it, or nil when the default agent was selected.
+func (r *AgentRegistry) ExplainRoute(inbound bus.InboundContext) (routing.ResolvedRoute, *config.DispatchRule) {
+	return r.resolver.ExplainRoute(inbound)
+}
+
+// DispatchRules returns the configured dispatch rules in evaluation order.
+func (r *AgentRegistry) DispatchRules() []config.DispatchRule {
+	return r.resolver.DispatchRules()
+}
+
 func (r *AgentRegistry) allowedMCPServers() map[string]struct{} {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
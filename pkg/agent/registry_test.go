@@ -268,6 +268,92 @@ Research agent.
 	}
 }
 
+func TestNewAgentLoop_AgentToolsConfigAllowAndDeny(t *testing.T) {
+	mainWorkspace := setupWorkspace(t, map[string]string{
+		"AGENT.md": "# Agent\nMain agent.\n",
+	})
+	defer cleanupWorkspace(t, mainWorkspace)
+	researchWorkspace := setupWorkspace(t, map[string]string{
+		"AGENT.md": `---
+tools: [read_file, write_file, web_fetch, exec]
+---
+# Agent
+
+Research agent.
+`,
+	})
+	defer cleanupWorkspace(t, researchWorkspace)
+	opsWorkspace := setupWorkspace(t, map[string]string{
+		"AGENT.md": "# Agent\nOps agent.\n",
+	})
+	defer cleanupWorkspace(t, opsWorkspace)
+
+	cfg := testCfg([]config.AgentConfig{
+		{ID: "main", Default: true, Workspace: mainWorkspace},
+		{
+			ID:        "research",
+			Workspace: researchWorkspace,
+			Tools: &config.AgentToolsConfig{
+				Allow: []string{"read_file", "web_fetch", "exec", "list_dir"},
+				Deny:  []string{"exec"},
+			},
+		},
+		{
+			ID:        "ops",
+			Workspace: opsWorkspace,
+			Tools:     &config.AgentToolsConfig{Deny: []string{"exec", "write_file"}},
+		},
+	})
+	cfg.Agents.Defaults.Workspace = mainWorkspace
+	cfg.Tools.ReadFile.Enabled = true
+	cfg.Tools.WriteFile.Enabled = true
+	cfg.Tools.ListDir.Enabled = true
+	cfg.Tools.Exec.Enabled = true
+	cfg.Tools.WebFetch.Enabled = true
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockRegistryProvider{})
+	defer al.Close()
+
+	research, ok := al.GetRegistry().GetAgent("research")
+	if !ok || research == nil {
+		t.Fatal("expected research agent")
+	}
+	if got, want := research.Tools.List(), []string{"read_file", "web_fetch"}; !slices.Equal(got, want) {
+		t.Fatalf("research tools = %v, want %v", got, want)
+	}
+
+	ops, ok := al.GetRegistry().GetAgent("ops")
+	if !ok || ops == nil {
+		t.Fatal("expected ops agent")
+	}
+	for _, denied := range []string{"exec", "write_file"} {
+		if _, ok := ops.Tools.Get(denied); ok {
+			t.Fatalf("ops should not have denied tool %q", denied)
+		}
+	}
+	if _, ok := ops.Tools.Get("read_file"); !ok {
+		t.Fatal("ops should keep tools it does not deny")
+	}
+
+	mainAgent, ok := al.GetRegistry().GetAgent("main")
+	if !ok || mainAgent == nil {
+		t.Fatal("expected main agent")
+	}
+	for _, name := range []string{"exec", "list_dir", "read_file", "web_fetch", "write_file"} {
+		if _, ok := mainAgent.Tools.Get(name); !ok {
+			t.Fatalf("main agent without tools config should keep %q", name)
+		}
+	}
+
+	al.RegisterTool(&allowlistTestTool{name: "exec_extra"})
+	if _, ok := research.Tools.Get("exec_extra"); ok {
+		t.Fatal("RegisterTool should respect the research allowlist")
+	}
+	if _, ok := mainAgent.Tools.Get("exec_extra"); !ok {
+		t.Fatal("RegisterTool should still reach the main agent")
+	}
+}
+
 func TestNewAgentLoop_AgentToolAllowlistRequiresExactRuntimeToolNames(t *testing.T) {
 	mainWorkspace := setupWorkspace(t, map[string]string{
 		"AGENT.md": "# Agent\nMain agent.\n",
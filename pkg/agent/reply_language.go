@@ -0,0 +1,63 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+
+package agent
+
+import (
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// replyLanguageMinConfidence is the detection confidence below which the
+// reply language is left unspecified rather than risk a wrong label.
+const replyLanguageMinConfidence = 0.65
+
+// applyReplyLanguage sets the language to reply in from what the user wrote
+// when agents.defaults.match_user_language is on. The first confident
+// detection is kept for the session, so later turns skip detection; a
+// language the sender picked with ":set lang" still wins.
+func (al *AgentLoop) applyReplyLanguage(msg bus.InboundMessage, opts processOptions) processOptions {
+	cfg := al.GetConfig()
+	if cfg == nil || !cfg.Agents.Defaults.MatchUserLanguage || opts.Dispatch.SessionKey == "" {
+		return opts
+	}
+	if cached, ok := al.sessionLanguages.Load(opts.Dispatch.SessionKey); ok {
+		opts.ReplyLanguage = cached.(string)
+		return opts
+	}
+	tag, confidence := utils.DetectLanguage(msg.Content)
+	if tag == "" || confidence < replyLanguageMinConfidence {
+		return opts
+	}
+	al.sessionLanguages.Store(opts.Dispatch.SessionKey, tag)
+	logger.DebugCF("agent", "Detected reply language", map[string]any{
+		"session_key": opts.Dispatch.SessionKey,
+		"language":    tag,
+		"confidence":  confidence,
+	})
+	opts.ReplyLanguage = tag
+	return opts
+}
+
+// replyLanguagePromptParts asks the model to answer in the detected language.
+func replyLanguagePromptParts(tag string) []PromptPart {
+	if tag == "" {
+		return nil
+	}
+	name := utils.LanguageName(tag)
+	return []PromptPart{
+		{
+			ID:     "instruction.reply_language",
+			Layer:  PromptLayerInstruction,
+			Slot:   PromptSlotWorkspace,
+			Source: PromptSource{ID: PromptSourcePeerLanguage, Name: "peer:language"},
+			Title:  "detected reply language",
+			Content: fmt.Sprintf("## Reply Language\n\nThe user writes in %s (%s). "+
+				"Respond in %s unless they ask for another language.", name, tag, name),
+			Stable: false,
+			Cache:  PromptCacheNone,
+		},
+	}
+}
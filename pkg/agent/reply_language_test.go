@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplyLanguage_DetectedAndCachedPerSession(t *testing.T) {
+	provider := &recordingProvider{}
+	al := newPeerPreferencesLoop(t, provider)
+	al.GetConfig().Agents.Defaults.MatchUserLanguage = true
+
+	sendAs(t, al, "alice", "Hola, ¿cómo estás? Necesito ayuda con mi cuenta")
+	if system := provider.lastMessages[0].Content; !strings.Contains(system, "Respond in Spanish") {
+		t.Fatalf("system prompt lacks the detected language:\n%s", system)
+	}
+
+	// Too short to detect on its own; the session keeps Spanish.
+	sendAs(t, al, "alice", "ok")
+	if system := provider.lastMessages[0].Content; !strings.Contains(system, "Respond in Spanish") {
+		t.Fatalf("detected language was not kept for the session:\n%s", system)
+	}
+
+	// A language the sender picked wins over detection.
+	sendAs(t, al, "alice", ":set lang de")
+	sendAs(t, al, "alice", "gracias")
+	system := provider.lastMessages[0].Content
+	if strings.Contains(system, "## Reply Language") || !strings.Contains(system, "Language: de") {
+		t.Fatalf("peer preference did not override detection:\n%s", system)
+	}
+}
+
+func TestReplyLanguage_LowConfidenceOrDisabledLeavesLanguageUnset(t *testing.T) {
+	provider := &recordingProvider{}
+	al := newPeerPreferencesLoop(t, provider)
+
+	sendAs(t, al, "alice", "Hola, ¿cómo estás? Necesito ayuda con mi cuenta")
+	if strings.Contains(provider.lastMessages[0].Content, "## Reply Language") {
+		t.Fatal("reply language was set with match_user_language off")
+	}
+
+	al.GetConfig().Agents.Defaults.MatchUserLanguage = true
+	sendAs(t, al, "bob", "ok 42")
+	if strings.Contains(provider.lastMessages[0].Content, "## Reply Language") {
+		t.Fatal("reply language was set without a confident detection")
+	}
+}
@@ -0,0 +1,131 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// responseTruncatedMarker ends a reply cut to the channel's length cap.
+const responseTruncatedMarker = "\n\n(truncated, ask me to continue)"
+
+const condenseResponsePrompt = `Condense the reply below to at most %d characters. Keep the direct answer, ` +
+	`key facts, numbers and names. Copy code blocks exactly as written or leave them out; never edit code. ` +
+	`Answer with the condensed reply only, without any preamble.
+
+---
+%s`
+
+// limitResponseLength enforces the max_response_chars cap of the turn's
+// channel on its final reply. With response_overflow "condense" (the
+// default) the summary model rewrites the reply to fit; if that fails, comes
+// back too long or changes a code block, the reply is truncated instead.
+func (al *AgentLoop) limitResponseLength(ctx context.Context, ts *turnState, content string) string {
+	cfg := al.GetConfig()
+	if cfg == nil {
+		return content
+	}
+	ch := cfg.Channels.Get(ts.channel)
+	if ch == nil || ch.MaxResponseChars <= 0 || utf8.RuneCountInString(content) <= ch.MaxResponseChars {
+		return content
+	}
+	limit := ch.MaxResponseChars
+	fields := map[string]any{
+		"agent_id": ts.agent.ID,
+		"channel":  ts.channel,
+		"chars":    utf8.RuneCountInString(content),
+		"limit":    limit,
+	}
+
+	if ch.ResponseOverflow != config.ResponseOverflowTruncate {
+		condensed, err := al.condenseResponse(ctx, ts.agent, content, limit)
+		if err == nil {
+			logger.InfoCF("agent", "Condensed reply to fit the channel limit", fields)
+			return condensed
+		}
+		fields["error"] = err.Error()
+		logger.WarnCF("agent", "Could not condense reply; truncating", fields)
+	}
+	return truncateResponse(content, limit)
+}
+
+// condenseResponse asks the summary model for a version of content within
+// limit characters whose code blocks are all copied unchanged from content.
+func (al *AgentLoop) condenseResponse(
+	ctx context.Context,
+	agent *AgentInstance,
+	content string,
+	limit int,
+) (string, error) {
+	al.activeRequests.Add(1)
+	defer al.activeRequests.Done()
+	resp, err := al.summaryChat(ctx, agent, fmt.Sprintf(condenseResponsePrompt, limit, content), map[string]any{
+		"max_tokens":       agent.MaxTokens,
+		"temperature":      0.3,
+		"prompt_cache_key": agent.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp == nil {
+		return "", fmt.Errorf("empty response")
+	}
+	condensed := strings.TrimSpace(resp.Content)
+	switch {
+	case condensed == "":
+		return "", fmt.Errorf("empty response")
+	case utf8.RuneCountInString(condensed) > limit:
+		return "", fmt.Errorf("condensed reply is %d characters", utf8.RuneCountInString(condensed))
+	}
+	originalBlocks := fencedCodeBlocks(content)
+	for _, block := range fencedCodeBlocks(condensed) {
+		if !slices.Contains(originalBlocks, block) {
+			return "", fmt.Errorf("condensed reply changed a code block")
+		}
+	}
+	return condensed, nil
+}
+
+// truncateResponse cuts content to at most limit characters including the
+// truncation marker, preferring a line break, and closes a code fence left
+// open by the cut so the rest of the message still renders.
+func truncateResponse(content string, limit int) string {
+	const fence = "\n```"
+	budget := limit - utf8.RuneCountInString(responseTruncatedMarker) - utf8.RuneCountInString(fence)
+	if budget <= 0 {
+		return string([]rune(content)[:limit])
+	}
+	cut := string([]rune(content)[:budget])
+	if i := strings.LastIndex(cut, "\n"); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	cut = strings.TrimRight(cut, " \t\n")
+	if strings.Count(cut, "```")%2 == 1 {
+		cut += fence
+	}
+	return cut + responseTruncatedMarker
+}
+
+// fencedCodeBlocks returns the bodies of the ``` fenced blocks in s.
+func fencedCodeBlocks(s string) []string {
+	parts := strings.Split(s, "```")
+	var blocks []string
+	for i := 1; i < len(parts); i += 2 {
+		body := parts[i]
+		// Drop the info string ("go", "bash") on the opening line.
+		if nl := strings.Index(body, "\n"); nl >= 0 {
+			body = body[nl+1:]
+		}
+		if body = strings.TrimSpace(body); body != "" {
+			blocks = append(blocks, body)
+		}
+	}
+	return blocks
+}
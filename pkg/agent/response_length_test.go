@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const longReply = "Here is a long explanation of the deploy. It covers the build, the rollout, " +
+	"the canary checks and what to do when the health probe fails on the second region."
+
+func newResponseLengthLoop(t *testing.T, overflow string, responses ...string) (*AgentLoop, *sequenceProvider) {
+	t.Helper()
+	provider := &sequenceProvider{}
+	for _, r := range responses {
+		provider.responses = append(provider.responses, &providers.LLMResponse{Content: r, FinishReason: "stop"})
+	}
+	al := newPeerPreferencesLoop(t, provider)
+	al.GetConfig().Channels = config.ChannelsConfig{
+		"telegram": {MaxResponseChars: 80, ResponseOverflow: overflow},
+	}
+	return al, provider
+}
+
+func TestLimitResponseLength_CondensesLongReply(t *testing.T) {
+	al, provider := newResponseLengthLoop(t, "", longReply, "Deploy: build, roll out, watch canaries.")
+
+	got := sendAs(t, al, "alice", "how do deploys work?")
+	if got != "Deploy: build, roll out, watch canaries." {
+		t.Fatalf("reply = %q, want the condensed version", got)
+	}
+	if provider.callCount != 2 {
+		t.Fatalf("LLM calls = %d, want 2 (answer + condense)", provider.callCount)
+	}
+}
+
+func TestLimitResponseLength_TruncatesWhenCondensingFails(t *testing.T) {
+	// The condensed reply is still over the limit, so the original is cut.
+	al, _ := newResponseLengthLoop(t, "", longReply, longReply)
+
+	got := sendAs(t, al, "alice", "how do deploys work?")
+	if !strings.HasSuffix(got, responseTruncatedMarker) {
+		t.Fatalf("reply = %q, want truncation marker", got)
+	}
+	if n := utf8.RuneCountInString(got); n > 80 {
+		t.Fatalf("reply is %d characters, limit is 80", n)
+	}
+}
+
+func TestLimitResponseLength_TruncateModeSkipsCondensing(t *testing.T) {
+	al, provider := newResponseLengthLoop(t, config.ResponseOverflowTruncate, longReply)
+
+	got := sendAs(t, al, "alice", "how do deploys work?")
+	if !strings.HasSuffix(got, responseTruncatedMarker) {
+		t.Fatalf("reply = %q, want truncation marker", got)
+	}
+	if provider.callCount != 1 {
+		t.Fatalf("LLM calls = %d, want 1", provider.callCount)
+	}
+}
+
+func TestLimitResponseLength_ShortReplyAndOtherChannelsUntouched(t *testing.T) {
+	al, provider := newResponseLengthLoop(t, "", "Short answer.")
+	if got := sendAs(t, al, "alice", "hi"); got != "Short answer." {
+		t.Fatalf("reply = %q", got)
+	}
+	if provider.callCount != 1 {
+		t.Fatalf("LLM calls = %d, want 1", provider.callCount)
+	}
+}
+
+func TestCondenseResponse_RejectsEditedCode(t *testing.T) {
+	original := "Run this:\n```bash\nmake deploy ENV=prod\n```\nThen wait for the canary checks to pass."
+	al, _ := newResponseLengthLoop(t, "", "Run:\n```bash\nmake deploy\n```")
+	agent := al.GetRegistry().GetDefaultAgent()
+
+	if _, err := al.condenseResponse(t.Context(), agent, original, 80); err == nil {
+		t.Fatal("a condensed reply with edited code was accepted")
+	}
+}
+
+func TestTruncateResponse_ClosesOpenCodeFence(t *testing.T) {
+	content := "Setup:\n```go\n" + strings.Repeat("fmt.Println(\"hello\")\n", 20) + "```\nDone."
+	got := truncateResponse(content, 120)
+	if n := utf8.RuneCountInString(got); n > 120 {
+		t.Fatalf("truncated reply is %d characters, limit is 120", n)
+	}
+	if strings.Count(got, "```")%2 != 0 {
+		t.Fatalf("truncated reply leaves a code fence open:\n%s", got)
+	}
+	if !strings.HasSuffix(got, responseTruncatedMarker) {
+		t.Fatalf("truncated reply lacks the marker:\n%s", got)
+	}
+}
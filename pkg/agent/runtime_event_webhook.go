@@ -0,0 +1,324 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	runtimeEventWebhookBuffer   = 256
+	runtimeEventWebhookTimeout  = 5 * time.Second
+	runtimeEventWebhookAttempts = 3
+	runtimeEventWebhookBackoff  = time.Second
+
+	// The signing headers match the Pico channel's HMAC handshake; the
+	// signature covers "<timestamp>.<body>" so the body cannot be swapped.
+	webhookTimestampHeader = "X-Pico-Timestamp"
+	webhookSignatureHeader = "X-Pico-Signature"
+	webhookEventHeader     = "X-Pico-Event"
+)
+
+// Event names delivered to gateway.event_webhooks.
+const (
+	webhookEventTurnStarted       = "turn.started"
+	webhookEventTurnCompleted     = "turn.completed"
+	webhookEventTurnFailed        = "turn.failed"
+	webhookEventToolCalled        = "tool.called"
+	webhookEventSessionSummarized = "session.summarized"
+)
+
+// runtimeEventWebhooks posts agent lifecycle events to the configured
+// endpoints. Each delivery runs in its own goroutine so a slow or failing
+// endpoint never holds up the agent or the other endpoints.
+type runtimeEventWebhooks struct {
+	mu      sync.RWMutex
+	hooks   []config.WebhookConfig
+	client  *http.Client
+	backoff time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// webhookEventBody is the JSON document POSTed for each event.
+type webhookEventBody struct {
+	ID         string         `json:"id"`
+	Event      string         `json:"event"`
+	Kind       string         `json:"kind"`
+	Time       time.Time      `json:"time"`
+	AgentID    string         `json:"agent_id,omitempty"`
+	SessionKey string         `json:"session_key,omitempty"`
+	TurnID     string         `json:"turn_id,omitempty"`
+	Channel    string         `json:"channel,omitempty"`
+	ChatID     string         `json:"chat_id,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
+func (al *AgentLoop) refreshRuntimeEventWebhooks(cfg *config.Config) {
+	if al == nil {
+		return
+	}
+	var hooks []config.WebhookConfig
+	if cfg != nil {
+		hooks = cfg.Gateway.EventWebhooks
+	}
+
+	al.runtimeEventLogMu.Lock()
+	if len(hooks) == 0 {
+		oldWebhooks, oldSub := al.runtimeEventWebhooks, al.runtimeEventWebhookSub
+		al.runtimeEventWebhooks = nil
+		al.runtimeEventWebhookSub = nil
+		al.runtimeEventLogMu.Unlock()
+		oldWebhooks.close(oldSub)
+		return
+	}
+
+	if al.runtimeEventWebhooks != nil && al.runtimeEventWebhookSub != nil {
+		al.runtimeEventWebhooks.updateHooks(hooks)
+		al.runtimeEventLogMu.Unlock()
+		return
+	}
+	al.runtimeEventLogMu.Unlock()
+
+	webhooks := newRuntimeEventWebhooks(hooks)
+	sub, err := webhooks.subscribe(al.runtimeEvents)
+	if err != nil {
+		logger.WarnCF("events", "Failed to subscribe runtime event webhooks", map[string]any{"error": err.Error()})
+		webhooks.close(nil)
+		return
+	}
+
+	al.runtimeEventLogMu.Lock()
+	oldWebhooks, oldSub := al.runtimeEventWebhooks, al.runtimeEventWebhookSub
+	al.runtimeEventWebhooks = webhooks
+	al.runtimeEventWebhookSub = sub
+	al.runtimeEventLogMu.Unlock()
+	oldWebhooks.close(oldSub)
+}
+
+func (al *AgentLoop) closeRuntimeEventWebhooks() {
+	if al == nil {
+		return
+	}
+	al.runtimeEventLogMu.Lock()
+	oldWebhooks, oldSub := al.runtimeEventWebhooks, al.runtimeEventWebhookSub
+	al.runtimeEventWebhooks = nil
+	al.runtimeEventWebhookSub = nil
+	al.runtimeEventLogMu.Unlock()
+	oldWebhooks.close(oldSub)
+}
+
+func newRuntimeEventWebhooks(hooks []config.WebhookConfig) *runtimeEventWebhooks {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &runtimeEventWebhooks{
+		hooks:   slices.Clone(hooks),
+		client:  &http.Client{Timeout: runtimeEventWebhookTimeout},
+		backoff: runtimeEventWebhookBackoff,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+func (w *runtimeEventWebhooks) updateHooks(hooks []config.WebhookConfig) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	w.hooks = slices.Clone(hooks)
+	w.mu.Unlock()
+}
+
+func (w *runtimeEventWebhooks) hooksSnapshot() []config.WebhookConfig {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.hooks
+}
+
+// close stops new deliveries and abandons pending retries.
+func (w *runtimeEventWebhooks) close(sub runtimeevents.Subscription) {
+	if sub != nil {
+		if err := sub.Close(); err != nil {
+			logger.WarnCF("events", "Failed to close runtime event webhook subscription", map[string]any{
+				"error": err.Error(),
+			})
+		}
+	}
+	if w != nil {
+		w.cancel()
+	}
+}
+
+func (w *runtimeEventWebhooks) subscribe(eventBus runtimeevents.Bus) (runtimeevents.Subscription, error) {
+	if w == nil || eventBus == nil {
+		return nil, nil
+	}
+	return eventBus.Channel().OfKind(
+		runtimeevents.KindAgentTurnStart,
+		runtimeevents.KindAgentTurnEnd,
+		runtimeevents.KindAgentToolExecStart,
+		runtimeevents.KindAgentSessionSummarize,
+	).Subscribe(w.ctx, runtimeevents.SubscribeOptions{
+		Name:         "runtime-event-webhooks",
+		Buffer:       runtimeEventWebhookBuffer,
+		Concurrency:  runtimeevents.Locked,
+		Backpressure: runtimeevents.DropNewest,
+		PanicPolicy:  runtimeevents.RecoverAndLog,
+	}, w.handle)
+}
+
+func (w *runtimeEventWebhooks) handle(_ context.Context, evt runtimeevents.Event) error {
+	if w == nil {
+		return nil
+	}
+	body, ok := webhookBodyForEvent(evt)
+	if !ok {
+		return nil
+	}
+	var encoded []byte
+	for _, hook := range w.hooksSnapshot() {
+		if hook.URL == "" || (len(hook.Events) > 0 && !slices.Contains(hook.Events, body.Event)) {
+			continue
+		}
+		if encoded == nil {
+			var err error
+			if encoded, err = json.Marshal(body); err != nil {
+				return fmt.Errorf("encode webhook event: %w", err)
+			}
+		}
+		go w.deliver(hook, body.Event, encoded)
+	}
+	return nil
+}
+
+// deliver POSTs payload to hook, retrying network errors, 429 and 5xx
+// responses with a linear backoff.
+func (w *runtimeEventWebhooks) deliver(hook config.WebhookConfig, event string, payload []byte) {
+	var lastErr error
+	for attempt := 1; attempt <= runtimeEventWebhookAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-time.After(time.Duration(attempt-1) * w.backoff):
+			}
+		}
+		retry, err := w.post(hook, event, payload)
+		if err == nil {
+			return
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	if w.ctx.Err() != nil {
+		return
+	}
+	logger.WarnCF("events", "Failed to deliver runtime event webhook", map[string]any{
+		"url":   hook.URL,
+		"event": event,
+		"error": lastErr.Error(),
+	})
+}
+
+func (w *runtimeEventWebhooks) post(hook config.WebhookConfig, event string, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, event)
+	if secret := hook.Secret.String(); secret != "" {
+		timestamp := time.Now().Unix()
+		req.Header.Set(webhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(webhookSignatureHeader, signWebhookPayload(secret, timestamp, payload))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned %s", resp.Status)
+}
+
+// signWebhookPayload returns the hex-encoded HMAC-SHA256 of
+// "<timestamp>.<payload>" under secret.
+func signWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookBodyForEvent(evt runtimeevents.Event) (webhookEventBody, bool) {
+	body := webhookEventBody{
+		ID:         evt.ID,
+		Kind:       evt.Kind.String(),
+		Time:       evt.Time,
+		AgentID:    evt.Scope.AgentID,
+		SessionKey: evt.Scope.SessionKey,
+		TurnID:     evt.Scope.TurnID,
+		Channel:    evt.Scope.Channel,
+		ChatID:     evt.Scope.ChatID,
+	}
+	switch payload := evt.Payload.(type) {
+	case TurnStartPayload:
+		body.Event = webhookEventTurnStarted
+		body.Data = map[string]any{
+			"user_message": payload.UserMessage,
+			"media_count":  payload.MediaCount,
+		}
+	case TurnEndPayload:
+		body.Event = webhookEventTurnCompleted
+		if payload.Status != TurnEndStatusCompleted {
+			body.Event = webhookEventTurnFailed
+		}
+		body.Data = map[string]any{
+			"status":            payload.Status,
+			"iterations":        payload.Iterations,
+			"duration_ms":       payload.Duration.Milliseconds(),
+			"final_content_len": payload.FinalContentLen,
+			"usage":             payload.Usage,
+		}
+		if payload.Error != "" {
+			body.Data["error"] = payload.Error
+		}
+	case ToolExecStartPayload:
+		body.Event = webhookEventToolCalled
+		body.Data = map[string]any{
+			"tool":      payload.Tool,
+			"arguments": payload.Arguments,
+		}
+	case SessionSummarizePayload:
+		body.Event = webhookEventSessionSummarized
+		body.Data = map[string]any{
+			"summarized_messages": payload.SummarizedMessages,
+			"kept_messages":       payload.KeptMessages,
+			"summary_len":         payload.SummaryLen,
+			"model":               payload.Model,
+		}
+	default:
+		return webhookEventBody{}, false
+	}
+	return body, true
+}
@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookTestServer(t *testing.T, failures int) (*httptest.Server, <-chan webhookRequest, *atomic.Int32) {
+	t.Helper()
+	requests := make(chan webhookRequest, 8)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if int(calls.Add(1)) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		requests <- webhookRequest{header: r.Header.Clone(), body: body}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests, &calls
+}
+
+func waitWebhookRequest(t *testing.T, requests <-chan webhookRequest) webhookRequest {
+	t.Helper()
+	select {
+	case req := <-requests:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for webhook request")
+		return webhookRequest{}
+	}
+}
+
+func TestRuntimeEventWebhooks_PostsSignedTurnCompleted(t *testing.T) {
+	srv, requests, _ := newWebhookTestServer(t, 0)
+	webhooks := newRuntimeEventWebhooks([]config.WebhookConfig{{URL: srv.URL, Secret: *config.NewSecureString("s3cret")}})
+	defer webhooks.close(nil)
+
+	err := webhooks.handle(t.Context(), runtimeevents.Event{
+		ID:    "evt-1",
+		Kind:  runtimeevents.KindAgentTurnEnd,
+		Scope: runtimeevents.Scope{AgentID: "main", SessionKey: "s1", TurnID: "t1"},
+		Payload: TurnEndPayload{
+			Status:     TurnEndStatusCompleted,
+			Iterations: 2,
+			Usage:      providers.UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		},
+	})
+	if err != nil {
+		t.Fatalf("handle() error = %v", err)
+	}
+
+	req := waitWebhookRequest(t, requests)
+	if got := req.header.Get(webhookEventHeader); got != webhookEventTurnCompleted {
+		t.Fatalf("event header = %q, want %q", got, webhookEventTurnCompleted)
+	}
+	timestamp, err := strconv.ParseInt(req.header.Get(webhookTimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header: %v", err)
+	}
+	if got, want := req.header.Get(webhookSignatureHeader), signWebhookPayload("s3cret", timestamp, req.body); got != want {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+
+	var body struct {
+		ID      string `json:"id"`
+		Event   string `json:"event"`
+		AgentID string `json:"agent_id"`
+		TurnID  string `json:"turn_id"`
+		Data    struct {
+			Status string              `json:"status"`
+			Usage  providers.UsageInfo `json:"usage"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(req.body, &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.ID != "evt-1" || body.Event != webhookEventTurnCompleted || body.AgentID != "main" || body.TurnID != "t1" {
+		t.Fatalf("unexpected body: %s", req.body)
+	}
+	if body.Data.Status != "completed" || body.Data.Usage.TotalTokens != 15 {
+		t.Fatalf("unexpected data: %s", req.body)
+	}
+}
+
+func TestRuntimeEventWebhooks_FiltersEventsAndMapsFailures(t *testing.T) {
+	srv, requests, calls := newWebhookTestServer(t, 0)
+	webhooks := newRuntimeEventWebhooks([]config.WebhookConfig{
+		{URL: srv.URL, Events: []string{webhookEventTurnFailed}},
+	})
+	defer webhooks.close(nil)
+
+	_ = webhooks.handle(t.Context(), runtimeevents.Event{
+		Kind:    runtimeevents.KindAgentToolExecStart,
+		Payload: ToolExecStartPayload{Tool: "exec"},
+	})
+	_ = webhooks.handle(t.Context(), runtimeevents.Event{
+		Kind:    runtimeevents.KindAgentTurnEnd,
+		Payload: TurnEndPayload{Status: TurnEndStatusError, Error: "boom"},
+	})
+
+	req := waitWebhookRequest(t, requests)
+	if req.header.Get(webhookSignatureHeader) != "" {
+		t.Fatal("unsigned webhook should not carry a signature")
+	}
+	var body struct {
+		Event string `json:"event"`
+		Data  struct {
+			Error string `json:"error"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(req.body, &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Event != webhookEventTurnFailed || body.Data.Error != "boom" {
+		t.Fatalf("unexpected body: %s", req.body)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("webhook calls = %d, want 1 (tool.called filtered out)", got)
+	}
+}
+
+func TestRuntimeEventWebhooks_RetriesServerErrors(t *testing.T) {
+	srv, requests, calls := newWebhookTestServer(t, 2)
+	webhooks := newRuntimeEventWebhooks([]config.WebhookConfig{{URL: srv.URL}})
+	webhooks.backoff = time.Millisecond
+	defer webhooks.close(nil)
+
+	_ = webhooks.handle(t.Context(), runtimeevents.Event{
+		Kind:    runtimeevents.KindAgentSessionSummarize,
+		Payload: SessionSummarizePayload{SummarizedMessages: 4},
+	})
+
+	waitWebhookRequest(t, requests)
+	if got := calls.Load(); got != runtimeEventWebhookAttempts {
+		t.Fatalf("webhook calls = %d, want %d", got, runtimeEventWebhookAttempts)
+	}
+}
@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// safeModeTools are the tools a turn may call while safe mode is on. They
+// look things up but change nothing outside the conversation.
+var safeModeTools = map[string]bool{
+	"read_file":               true,
+	"list_dir":                true,
+	"web_search":              true,
+	"web_fetch":               true,
+	"weather":                 true,
+	"ocr":                     true,
+	"load_image":              true,
+	"find_skills":             true,
+	"spawn_status":            true,
+	tools.RegexSearchToolName: true,
+	tools.BM25SearchToolName:  true,
+}
+
+// SafeMode reports whether safe mode is on, and whether the config enforces
+// it so that it cannot be switched off at runtime.
+func (al *AgentLoop) SafeMode() (on, enforced bool) {
+	if cfg := al.GetConfig(); cfg != nil {
+		enforced = cfg.Agents.Defaults.SafeMode
+	}
+	return enforced || al.safeMode.Load(), enforced
+}
+
+// SetSafeMode switches safe mode at runtime. It cannot switch off safe mode
+// that agents.defaults.safe_mode enforces.
+func (al *AgentLoop) SetSafeMode(on bool) error {
+	if _, enforced := al.SafeMode(); enforced && !on {
+		return fmt.Errorf("safe mode is enforced by agents.defaults.safe_mode")
+	}
+	al.safeMode.Store(on)
+	return nil
+}
+
+// setSafeModeFrom switches safe mode for a /safe command sent in msg.
+// Anyone who can talk to the bot may see the state, but only a sender
+// allowed to switch it (see safeModeSwitchAllowed) may change it.
+func (al *AgentLoop) setSafeModeFrom(msg bus.InboundMessage, on bool) error {
+	if !al.safeModeSwitchAllowed(msg) {
+		return fmt.Errorf("only senders listed in this channel's allow_from can switch safe mode")
+	}
+	return al.SetSafeMode(on)
+}
+
+// safeModeSwitchAllowed reports whether the sender of msg may switch safe
+// mode. Local CLI users always may. On a chat channel the sender must match
+// an entry of that channel's allow_from other than "*", since an empty list
+// or "*" lets anyone talk to the bot.
+func (al *AgentLoop) safeModeSwitchAllowed(msg bus.InboundMessage) bool {
+	channel := msg.Context.Channel
+	if channel == "" {
+		channel = msg.Channel
+	}
+	if channel == "" || channel == "cli" {
+		return true
+	}
+	cfg := al.GetConfig()
+	if cfg == nil {
+		return false
+	}
+	ch := cfg.Channels.Get(channel)
+	if ch == nil {
+		return false
+	}
+
+	sender := msg.Sender
+	if sender.CanonicalID == "" && sender.PlatformID == "" {
+		// Channels that report only a sender ID use "id" or "id|username".
+		senderID := msg.Context.SenderID
+		if senderID == "" {
+			senderID = msg.SenderID
+		}
+		sender.PlatformID, sender.Username, _ = strings.Cut(senderID, "|")
+	}
+	for _, allowed := range ch.AllowFrom {
+		if strings.TrimSpace(allowed) != "*" && identity.MatchAllowed(sender, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// safeModeDenial returns why a tool call is refused under safe mode, or ""
+// if it may run. The message tool may still reply in the current chat.
+func (al *AgentLoop) safeModeDenial(toolName string, args map[string]any, channel, chatID string) string {
+	if on, _ := al.SafeMode(); !on || safeModeTools[toolName] {
+		return ""
+	}
+	if toolName == "message" {
+		targetChannel, _ := args["channel"].(string)
+		targetChatID, _ := args["chat_id"].(string)
+		if (targetChannel == "" || targetChannel == channel) && (targetChatID == "" || targetChatID == chatID) {
+			return ""
+		}
+		return "Safe mode is on: messages can only be sent to the current chat."
+	}
+	return fmt.Sprintf("Safe mode is on: tool %q may change state and is disabled. Only read-only tools can run.",
+		strings.TrimSpace(toolName))
+}
@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestSafeModeDenial(t *testing.T) {
+	al := &AgentLoop{cfg: config.DefaultConfig()}
+
+	if got := al.safeModeDenial("exec", nil, "telegram", "1"); got != "" {
+		t.Fatalf("safe mode off should allow exec, got %q", got)
+	}
+	if err := al.SetSafeMode(true); err != nil {
+		t.Fatalf("SetSafeMode(true): %v", err)
+	}
+
+	for _, name := range []string{"exec", "write_file", "mcp_github_create_issue", "i2c", "spawn"} {
+		if got := al.safeModeDenial(name, nil, "telegram", "1"); !strings.Contains(got, "Safe mode is on") {
+			t.Fatalf("%s should be denied, got %q", name, got)
+		}
+	}
+	for _, name := range []string{"read_file", "web_search", "web_fetch"} {
+		if got := al.safeModeDenial(name, nil, "telegram", "1"); got != "" {
+			t.Fatalf("%s should be allowed, got %q", name, got)
+		}
+	}
+
+	reply := map[string]any{"content": "hi"}
+	if got := al.safeModeDenial("message", reply, "telegram", "1"); got != "" {
+		t.Fatalf("reply to the current chat should be allowed, got %q", got)
+	}
+	elsewhere := map[string]any{"content": "hi", "channel": "slack", "chat_id": "C1"}
+	if got := al.safeModeDenial("message", elsewhere, "telegram", "1"); got == "" {
+		t.Fatal("message to another chat should be denied")
+	}
+}
+
+func TestSetSafeMode_ConfigEnforced(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.SafeMode = true
+	al := &AgentLoop{cfg: cfg}
+
+	if on, enforced := al.SafeMode(); !on || !enforced {
+		t.Fatalf("SafeMode() = %v, %v; want on and enforced", on, enforced)
+	}
+	if err := al.SetSafeMode(false); err == nil {
+		t.Fatal("expected error turning off config-enforced safe mode")
+	}
+	if on, _ := al.SafeMode(); !on {
+		t.Fatal("safe mode should still be on")
+	}
+}
+
+func TestSetSafeModeFrom_RequiresAllowListedSender(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Channels = config.ChannelsConfig{
+		"telegram": {Enabled: true, Type: "telegram", AllowFrom: config.FlexibleStringSlice{"123", "@alice"}},
+		"discord":  {Enabled: true, Type: "discord", AllowFrom: config.FlexibleStringSlice{"*"}},
+		"slack":    {Enabled: true, Type: "slack"},
+	}
+	al := &AgentLoop{cfg: cfg}
+	from := func(channel string, sender bus.SenderInfo) bus.InboundMessage {
+		return bus.InboundMessage{Context: bus.InboundContext{Channel: channel, SenderID: sender.PlatformID}, Sender: sender}
+	}
+
+	for _, tt := range []struct {
+		name string
+		msg  bus.InboundMessage
+		want bool
+	}{
+		{name: "cli", msg: bus.InboundMessage{Channel: "cli", SenderID: "cron"}, want: true},
+		{name: "listed id", msg: from("telegram", bus.SenderInfo{Platform: "telegram", PlatformID: "123"}), want: true},
+		{name: "listed username", msg: from("telegram", bus.SenderInfo{PlatformID: "9", Username: "alice"}), want: true},
+		{name: "unlisted", msg: from("telegram", bus.SenderInfo{Platform: "telegram", PlatformID: "456"}), want: false},
+		{name: "wildcard", msg: from("discord", bus.SenderInfo{Platform: "discord", PlatformID: "123"}), want: false},
+		{name: "open channel", msg: from("slack", bus.SenderInfo{Platform: "slack", PlatformID: "123"}), want: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := al.safeModeSwitchAllowed(tt.msg); got != tt.want {
+				t.Fatalf("safeModeSwitchAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := al.setSafeModeFrom(from("slack", bus.SenderInfo{PlatformID: "123"}), true); err == nil {
+		t.Fatal("an open channel's participant switched safe mode")
+	}
+	if on, _ := al.SafeMode(); on {
+		t.Fatal("a refused /safe on changed safe mode")
+	}
+	if err := al.setSafeModeFrom(from("telegram", bus.SenderInfo{PlatformID: "123"}), true); err != nil {
+		t.Fatalf("setSafeModeFrom(listed sender): %v", err)
+	}
+	if on, _ := al.SafeMode(); !on {
+		t.Fatal("safe mode should be on")
+	}
+}
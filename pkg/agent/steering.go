@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -30,6 +31,10 @@ const (
 	manualSteeringScope = "__manual__"
 )
 
+// errSteeringQueueFull is returned when a scope already holds MaxQueueSize
+// messages.
+var errSteeringQueueFull = errors.New("steering queue is full")
+
 // parseSteeringMode normalizes a config string into a SteeringMode.
 func parseSteeringMode(s string) SteeringMode {
 	switch s {
@@ -76,7 +81,7 @@ func (sq *steeringQueue) pushScope(scope string, msg providers.Message) error {
 	scope = normalizeSteeringScope(scope)
 	queue := sq.queues[scope]
 	if len(queue) >= MaxQueueSize {
-		return fmt.Errorf("steering queue is full")
+		return errSteeringQueueFull
 	}
 	sq.queues[scope] = append(queue, msg)
 	return nil
@@ -0,0 +1,66 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+
+package agent
+
+import (
+	"context"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// summaryCandidates returns the candidates used for summarization: the
+// configured summary_model first, then the agent's primary chain so a failing
+// summary model degrades to the main model instead of losing the summary.
+// It returns nil when no summary model is configured.
+func summaryCandidates(agent *AgentInstance) []providers.FallbackCandidate {
+	if agent == nil || len(agent.SummaryCandidates) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(agent.SummaryCandidates)+len(agent.Candidates))
+	candidates := make([]providers.FallbackCandidate, 0, len(agent.SummaryCandidates)+len(agent.Candidates))
+	for _, group := range [][]providers.FallbackCandidate{agent.SummaryCandidates, agent.Candidates} {
+		for _, candidate := range group {
+			if key := candidate.StableKey(); !seen[key] {
+				seen[key] = true
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+	return candidates
+}
+
+// summaryModelName returns the model summarization calls first.
+func summaryModelName(agent *AgentInstance) string {
+	return resolvedCandidateModel(agent.SummaryCandidates, agent.Model)
+}
+
+// summaryChat sends a summarization prompt. Without a summary model it calls the agent's primary model
+// directly, as summarization always has.
+func (al *AgentLoop) summaryChat(
+	ctx context.Context,
+	agent *AgentInstance,
+	prompt string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	messages := []providers.Message{{Role: "user", Content: prompt}}
+	candidates := summaryCandidates(agent)
+	if len(candidates) == 0 {
+		return agent.Provider.Chat(ctx, messages, nil, agent.Model, opts)
+	}
+
+	run := func(ctx context.Context, candidate providers.FallbackCandidate) (*providers.LLMResponse, error) {
+		provider, err := providerForFallbackCandidate(agent, agent.Provider, candidates, candidate.Provider, candidate.Model)
+		if err != nil {
+			return nil, err
+		}
+		return provider.Chat(ctx, messages, nil, candidate.Model, opts)
+	}
+	if al.fallback == nil {
+		return run(ctx, candidates[0])
+	}
+	result, err := al.fallback.ExecuteCandidate(ctx, candidates, run)
+	if err != nil {
+		return nil, err
+	}
+	return result.Response, nil
+}
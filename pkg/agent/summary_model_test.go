@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type summaryRecordingProvider struct {
+	response string
+	err      error
+	models   []string
+}
+
+func (p *summaryRecordingProvider) Chat(
+	_ context.Context,
+	_ []providers.Message,
+	_ []providers.ToolDefinition,
+	model string,
+	_ map[string]any,
+) (*providers.LLMResponse, error) {
+	p.models = append(p.models, model)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &providers.LLMResponse{Content: p.response}, nil
+}
+
+func (p *summaryRecordingProvider) GetDefaultModel() string { return "" }
+
+func newSummaryModelTestLoop(t *testing.T, summary *summaryRecordingProvider) (*AgentLoop, *AgentInstance, *summaryRecordingProvider) {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:     t.TempDir(),
+				ModelName:     "test-model",
+				MaxTokens:     4096,
+				ContextWindow: 8000,
+			},
+		},
+	}
+	main := &summaryRecordingProvider{response: "main summary"}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), main)
+	agent := al.registry.GetDefaultAgent()
+	if summary != nil {
+		candidate := providers.FallbackCandidate{Provider: "cheap", Model: "mini"}
+		agent.SummaryCandidates = []providers.FallbackCandidate{candidate}
+		agent.CandidateProviders[providers.ModelKey(candidate.Provider, candidate.Model)] = summary
+	}
+	return al, agent, main
+}
+
+func TestSummaryChat_UsesSummaryModel(t *testing.T) {
+	summary := &summaryRecordingProvider{response: "cheap summary"}
+	al, agent, main := newSummaryModelTestLoop(t, summary)
+
+	resp, err := al.summaryChat(context.Background(), agent, "summarize", nil)
+	if err != nil {
+		t.Fatalf("summaryChat() error = %v", err)
+	}
+	if resp.Content != "cheap summary" {
+		t.Fatalf("summary = %q, want the summary model's answer", resp.Content)
+	}
+	if len(summary.models) != 1 || summary.models[0] != "mini" || len(main.models) != 0 {
+		t.Fatalf("summary model calls = %v, main model calls = %v", summary.models, main.models)
+	}
+	if got := summaryModelName(agent); got != "mini" {
+		t.Fatalf("summaryModelName() = %q, want mini", got)
+	}
+}
+
+func TestSummaryChat_FallsBackToMainModel(t *testing.T) {
+	summary := &summaryRecordingProvider{err: errors.New("429 rate limit exceeded")}
+	al, agent, main := newSummaryModelTestLoop(t, summary)
+
+	resp, err := al.summaryChat(context.Background(), agent, "summarize", nil)
+	if err != nil {
+		t.Fatalf("summaryChat() error = %v", err)
+	}
+	if resp.Content != "main summary" || len(summary.models) != 1 || len(main.models) != 1 {
+		t.Fatalf("resp = %q, summary calls = %v, main calls = %v", resp.Content, summary.models, main.models)
+	}
+}
+
+func TestSummaryChat_WithoutSummaryModelUsesMainModel(t *testing.T) {
+	al, agent, main := newSummaryModelTestLoop(t, nil)
+
+	if _, err := al.summaryChat(context.Background(), agent, "summarize", nil); err != nil {
+		t.Fatalf("summaryChat() error = %v", err)
+	}
+	if len(main.models) != 1 || main.models[0] != agent.Model {
+		t.Fatalf("main calls = %v, want one call to %q", main.models, agent.Model)
+	}
+	if got := summaryModelName(agent); got != agent.Model {
+		t.Fatalf("summaryModelName() = %q, want %q", got, agent.Model)
+	}
+}
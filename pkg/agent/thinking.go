@@ -59,11 +59,18 @@ type thinkingSettings struct {
 }
 
 func thinkingSettingsFromModelConfig(mc *config.ModelConfig) thinkingSettings {
-	if mc == nil || !isConfiguredThinkingLevel(mc.ThinkingLevel) {
+	if mc == nil {
+		return thinkingSettings{}
+	}
+	return thinkingSettingsFromLevel(mc.ThinkingLevel)
+}
+
+func thinkingSettingsFromLevel(level string) thinkingSettings {
+	if !isConfiguredThinkingLevel(level) {
 		return thinkingSettings{}
 	}
 	return thinkingSettings{
-		level:      parseThinkingLevel(mc.ThinkingLevel),
+		level:      parseThinkingLevel(level),
 		configured: true,
 	}
 }
@@ -115,7 +122,14 @@ func applyTurnThinkingOptions(
 		return
 	}
 	delete(exec.llmOpts, "thinking_level")
-	settings := activeThinkingSettings(agent, exec.activeModelConfig)
+	settings := thinkingSettingsFromModelConfig(exec.activeModelConfig)
+	if !settings.configured {
+		// A model profile fills in when the model_list entry leaves it unset.
+		settings = thinkingSettingsFromLevel(exec.modelParams.ThinkingLevel)
+	}
+	if !settings.configured {
+		settings = activeThinkingSettings(agent, exec.activeModelConfig)
+	}
 	agentID := ""
 	if agent != nil {
 		agentID = agent.ID
@@ -164,6 +164,35 @@ func resolveAgentToolAllowlist(definition AgentContextDefinition) []string {
 	return sortedKeys(allowlist)
 }
 
+// intersectToolAllowlists combines the AGENT.md tools list with the
+// agents.list[].tools.allow list from config. Nil means "no restriction", so
+// a nil side defers to the other one; when both are set a tool must be in
+// both.
+func intersectToolAllowlists(declared, configured []string) []string {
+	if configured == nil {
+		return declared
+	}
+	allowed := make(map[string]struct{}, len(configured))
+	for _, raw := range configured {
+		if name := strings.ToLower(strings.TrimSpace(raw)); name != "" {
+			allowed[name] = struct{}{}
+		}
+	}
+	if declared != nil {
+		both := make(map[string]struct{}, len(declared))
+		for _, name := range declared {
+			if _, ok := allowed[name]; ok {
+				both[name] = struct{}{}
+			}
+		}
+		allowed = both
+	}
+	if len(allowed) == 0 {
+		return []string{}
+	}
+	return sortedKeys(allowed)
+}
+
 func resolveAgentMCPServerAllowlist(definition AgentContextDefinition) map[string]struct{} {
 	if frontmatterParseFailed(definition) {
 		return map[string]struct{}{}
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -182,3 +183,27 @@ mcpServers: [github, FileSystem, slak]
 		t.Fatalf("unknownAgentMCPServerNames() = %v, want [slak]", unknown)
 	}
 }
+
+func TestIntersectToolAllowlists(t *testing.T) {
+	tests := []struct {
+		name       string
+		declared   []string
+		configured []string
+		want       []string
+	}{
+		{name: "neither set", want: nil},
+		{name: "only declared", declared: []string{"read_file"}, want: []string{"read_file"}},
+		{name: "only configured", configured: []string{" Web_Fetch ", "read_file"}, want: []string{"read_file", "web_fetch"}},
+		{name: "both set", declared: []string{"exec", "read_file"}, configured: []string{"read_file", "web_fetch"}, want: []string{"read_file"}},
+		{name: "configured empty", declared: []string{"read_file"}, configured: []string{}, want: []string{}},
+		{name: "no overlap", declared: []string{"exec"}, configured: []string{"read_file"}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := intersectToolAllowlists(tt.declared, tt.configured)
+			if (got == nil) != (tt.want == nil) || !slices.Equal(got, tt.want) {
+				t.Fatalf("intersectToolAllowlists() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	toolApprovalHookName = "builtin:tool_approval"
+	// toolApprovalHookPriority orders the hook after configured approvers, so
+	// the user is only asked about calls nothing else has refused.
+	toolApprovalHookPriority = 1 << 20
+)
+
+// pendingToolApproval is a tool call that waits for the user's answer.
+// Values are replaced, never modified, so they can be swapped atomically in
+// AgentLoop.pendingApprovals.
+type pendingToolApproval struct {
+	tool      string
+	arguments string // canonical JSON, compared with the call made after approval
+	senderID  string
+	approved  bool
+}
+
+// toolApprovalHook holds calls to tools for which tools.<name>.require_approval
+// is set until the user approves them in the chat. The first call is denied
+// and shown to the user; /approve marks it approved and asks the model to
+// repeat it, and the repeated call with the same arguments is let through
+// once. The model cannot approve its own calls, since only a user message
+// can.
+type toolApprovalHook struct {
+	al *AgentLoop
+}
+
+func (h toolApprovalHook) ApproveTool(ctx context.Context, req *ToolApprovalRequest) (ApprovalDecision, error) {
+	cfg := h.al.GetConfig()
+	if cfg == nil || !cfg.Tools.RequiresApproval(req.Tool) {
+		return ApprovalDecision{Approved: true}, nil
+	}
+
+	var inbound *bus.InboundContext
+	if req.Context != nil {
+		inbound = req.Context.Inbound
+	}
+	sessionKey := req.Meta.SessionKey
+	if sessionKey == "" || inbound == nil || inbound.ChatID == "" {
+		return ApprovalDecision{
+			Reason: fmt.Sprintf("%s calls need the user's approval, and this turn has no chat to ask in", req.Tool),
+		}, nil
+	}
+
+	args := canonicalToolArguments(req.Arguments)
+	if value, ok := h.al.pendingApprovals.Load(sessionKey); ok {
+		pending := value.(*pendingToolApproval)
+		if pending.approved && pending.tool == req.Tool && pending.arguments == args &&
+			h.al.pendingApprovals.CompareAndDelete(sessionKey, value) {
+			return ApprovalDecision{Approved: true}, nil
+		}
+	}
+
+	h.al.pendingApprovals.Store(sessionKey, &pendingToolApproval{
+		tool:      req.Tool,
+		arguments: args,
+		senderID:  inbound.SenderID,
+	})
+	h.al.publishApprovalRequest(ctx, sessionKey, inbound, formatToolApprovalRequest(req.Tool, req.Arguments))
+	return ApprovalDecision{
+		Reason: "the user has been shown this call and asked to reply /approve or /deny. " +
+			"End your turn now without retrying it; if they approve, you will be asked to make the same call again.",
+	}, nil
+}
+
+// applyToolApprovalCommand handles /approve and /deny. Approving rewrites the
+// message so the model repeats the held call, and passes it on to the model.
+func (al *AgentLoop) applyToolApprovalCommand(
+	msg bus.InboundMessage,
+	opts *processOptions,
+) (matched bool, handled bool, reply string) {
+	cmdName, ok := commands.CommandName(msg.Content)
+	if !ok || (cmdName != "approve" && cmdName != "deny") {
+		return false, false, ""
+	}
+	if opts == nil || opts.Dispatch.SessionKey == "" {
+		return true, true, "Nothing is waiting for approval."
+	}
+
+	sessionKey := opts.Dispatch.SessionKey
+	value, ok := al.pendingApprovals.Load(sessionKey)
+	if !ok {
+		return true, true, "Nothing is waiting for approval."
+	}
+	pending := value.(*pendingToolApproval)
+	if pending.senderID != "" && msg.SenderID != pending.senderID {
+		return true, true, fmt.Sprintf("Only the user whose request led to this %s call can answer it.", pending.tool)
+	}
+
+	if cmdName == "deny" {
+		al.pendingApprovals.CompareAndDelete(sessionKey, value)
+		return true, true, fmt.Sprintf("Denied. The %s call will not run.", pending.tool)
+	}
+
+	approved := *pending
+	approved.approved = true
+	al.pendingApprovals.Store(sessionKey, &approved)
+	message := fmt.Sprintf("I approve the %s call you asked about. Make exactly the same call again now.", pending.tool)
+	opts.Dispatch.UserMessage = message
+	opts.UserMessage = message
+	return true, false, ""
+}
+
+func (al *AgentLoop) publishApprovalRequest(
+	ctx context.Context,
+	sessionKey string,
+	inbound *bus.InboundContext,
+	content string,
+) {
+	if al.bus == nil {
+		return
+	}
+	pubCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 3*time.Second)
+	defer cancel()
+	err := al.bus.PublishOutbound(pubCtx, bus.OutboundMessage{
+		Context:    outboundContextFromInbound(inbound, inbound.Channel, inbound.ChatID, inbound.MessageID),
+		SessionKey: sessionKey,
+		Content:    content,
+	})
+	if err != nil && !errors.Is(err, bus.ErrBusClosed) {
+		logger.WarnCF("agent", "Failed to publish tool approval request", map[string]any{
+			"channel": inbound.Channel,
+			"chat_id": inbound.ChatID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// formatToolApprovalRequest shows the user what the call would do: a code
+// argument as a fenced block, and the other arguments as JSON.
+func formatToolApprovalRequest(tool string, args map[string]any) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "The assistant wants to run the %s tool", tool)
+	rest := make(map[string]any, len(args))
+	for k, v := range args {
+		rest[k] = v
+	}
+	if code, ok := rest["code"].(string); ok {
+		delete(rest, "code")
+		fmt.Fprintf(&sb, " with this code:\n\n```%s\n%s\n```", tool, strings.TrimRight(code, "\n"))
+	} else {
+		sb.WriteString(".")
+	}
+	if len(rest) > 0 {
+		fmt.Fprintf(&sb, "\n\nArguments:\n```json\n%s\n```", canonicalToolArguments(rest))
+	}
+	sb.WriteString("\n\nReply /approve to run it or /deny to refuse.")
+	return sb.String()
+}
+
+// canonicalToolArguments encodes arguments with sorted keys, so two calls
+// with the same arguments compare equal.
+func canonicalToolArguments(args map[string]any) string {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprintf("%v", args)
+	}
+	return string(data)
+}
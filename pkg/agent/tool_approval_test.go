@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func approvalRequestForTest(code string) *ToolApprovalRequest {
+	return &ToolApprovalRequest{
+		Meta: HookMeta{SessionKey: "session-1"},
+		Context: &TurnContext{Inbound: &bus.InboundContext{
+			Channel:  "telegram",
+			ChatID:   "chat-1",
+			SenderID: "alice",
+		}},
+		Tool:      "python",
+		Arguments: map[string]any{"code": code},
+	}
+}
+
+func approvalCommandForTest(
+	t *testing.T,
+	al *AgentLoop,
+	agent *AgentInstance,
+	sender, text string,
+) (string, bool, *processOptions) {
+	t.Helper()
+	opts := &processOptions{Dispatch: DispatchRequest{SessionKey: "session-1"}, UserMessage: text}
+	reply, handled := al.handleCommand(context.Background(), bus.InboundMessage{
+		Context:  bus.InboundContext{Channel: "telegram", ChatID: "chat-1", SenderID: sender},
+		SenderID: sender,
+		Content:  text,
+	}, agent, opts)
+	return reply, handled, opts
+}
+
+func TestToolApproval_UserMustApprove(t *testing.T) {
+	al, agent, cleanup := newHookTestLoop(t, &toolHookProvider{})
+	defer cleanup()
+	al.GetConfig().Tools.Python.RequireApproval = true
+	msgBus := al.bus.(*bus.MessageBus)
+	ctx := context.Background()
+
+	decision := al.hooks.ApproveTool(ctx, approvalRequestForTest("print(1)"))
+	if decision.Approved || !strings.Contains(decision.Reason, "/approve") {
+		t.Fatalf("first call should be held for the user: %+v", decision)
+	}
+	select {
+	case out := <-msgBus.OutboundChan():
+		if out.Context.ChatID != "chat-1" || !strings.Contains(out.Content, "```python\nprint(1)\n```") {
+			t.Fatalf("unexpected approval request: %+v", out)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the user was not asked")
+	}
+	if decision := al.hooks.ApproveTool(ctx, approvalRequestForTest("print(1)")); decision.Approved {
+		t.Fatal("repeating the call must not approve it")
+	}
+
+	if reply, handled, _ := approvalCommandForTest(t, al, agent, "mallory", "/approve"); !handled ||
+		!strings.Contains(reply, "Only the user") {
+		t.Fatalf("another sender approved the call: %q, %v", reply, handled)
+	}
+	_, handled, opts := approvalCommandForTest(t, al, agent, "alice", "/approve")
+	if handled || !strings.Contains(opts.UserMessage, "same call again") {
+		t.Fatalf("/approve should ask the model to repeat the call: %v, %q", handled, opts.UserMessage)
+	}
+
+	if decision := al.hooks.ApproveTool(ctx, approvalRequestForTest("print(2)")); decision.Approved {
+		t.Fatal("approval must not carry over to different code")
+	}
+	<-msgBus.OutboundChan()
+	if _, _, opts := approvalCommandForTest(t, al, agent, "alice", "/approve"); opts.UserMessage == "/approve" {
+		t.Fatal("/approve was not applied")
+	}
+	if decision := al.hooks.ApproveTool(ctx, approvalRequestForTest("print(2)")); !decision.Approved {
+		t.Fatalf("approved call was refused: %+v", decision)
+	}
+	if decision := al.hooks.ApproveTool(ctx, approvalRequestForTest("print(2)")); decision.Approved {
+		t.Fatal("an approval must only let one call through")
+	}
+	<-msgBus.OutboundChan()
+
+	reply, handled, _ := approvalCommandForTest(t, al, agent, "alice", "/deny")
+	if !handled || !strings.Contains(reply, "will not run") {
+		t.Fatalf("unexpected /deny reply: %q", reply)
+	}
+	if reply, _, _ := approvalCommandForTest(t, al, agent, "alice", "/approve"); !strings.Contains(reply, "Nothing") {
+		t.Fatalf("denied call is still pending: %q", reply)
+	}
+}
+
+func TestToolApproval_OnlyCoversConfiguredTools(t *testing.T) {
+	al, _, cleanup := newHookTestLoop(t, &toolHookProvider{})
+	defer cleanup()
+	ctx := context.Background()
+
+	req := approvalRequestForTest("print(1)")
+	if decision := al.hooks.ApproveTool(ctx, req); !decision.Approved {
+		t.Fatalf("python without require_approval was held: %+v", decision)
+	}
+
+	al.GetConfig().Tools.Python.RequireApproval = true
+	req.Tool = "echo_text"
+	if decision := al.hooks.ApproveTool(ctx, req); !decision.Approved {
+		t.Fatalf("a tool without require_approval was held: %+v", decision)
+	}
+	req = approvalRequestForTest("print(1)")
+	req.Context = nil
+	if decision := al.hooks.ApproveTool(ctx, req); decision.Approved {
+		t.Fatal("a call without a chat to ask in must be refused")
+	}
+}
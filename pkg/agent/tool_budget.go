@@ -0,0 +1,209 @@
+package agent
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// toolBudgetRecentUserMessages is how many of the latest user messages feed
+// the keyword match used to rank tools.
+const toolBudgetRecentUserMessages = 3
+
+// toolBudgetStopwords are common words that would otherwise match almost
+// every tool description.
+var toolBudgetStopwords = map[string]struct{}{
+	"the": {}, "and": {}, "for": {}, "with": {}, "you": {}, "your": {}, "this": {},
+	"that": {}, "from": {}, "are": {}, "can": {}, "use": {}, "not": {}, "all": {},
+	"any": {}, "into": {}, "its": {}, "has": {}, "have": {}, "will": {}, "please": {},
+}
+
+// turnToolDefs builds the tool definitions sent with one LLM call: the
+// agent's tools, narrowed by the turn profile, without web_search when the
+// provider's native search replaces it, and finally capped by
+// agents.defaults.max_tool_defs. The cap runs last so no slot goes to a tool
+// that is filtered out afterwards. It also reports whether native search is
+// used and which tools the cap dropped.
+func (al *AgentLoop) turnToolDefs(
+	ts *turnState,
+	messages []providers.Message,
+) (defs []providers.ToolDefinition, useNativeSearch bool, dropped []string) {
+	defs = filterToolsByTurnProfile(ts.agent.Tools.ToProviderDefs(), ts.profile)
+
+	cfg := al.GetConfig()
+	if cfg == nil {
+		return defs, false, nil
+	}
+	webSearchEnabled := cfg.Tools.IsToolEnabled("web") && turnProfileToolAllowed(ts.profile, "web_search")
+	if webSearchEnabled && cfg.Tools.Web.PreferNative {
+		if ns, ok := ts.agent.Provider.(providers.NativeSearchCapable); ok {
+			useNativeSearch = ns.SupportsNativeSearch()
+		}
+	}
+	if useNativeSearch {
+		filtered := make([]providers.ToolDefinition, 0, len(defs))
+		for _, td := range defs {
+			if td.Function.Name != "web_search" {
+				filtered = append(filtered, td)
+			}
+		}
+		defs = filtered
+	}
+
+	defs, dropped = limitToolDefs(defs, cfg.Agents.Defaults.MaxToolDefs, cfg.Agents.Defaults.CoreTools, messages)
+	return defs, useNativeSearch, dropped
+}
+
+// limitToolDefs keeps at most maxDefs tool definitions. When there are more,
+// tools are picked in this order until the cap is reached:
+//
+//  1. tools named in core, in the order given
+//  2. tools already called in messages, most recent first
+//  3. tools whose name or description shares words with the latest user
+//     messages, best match first
+//  4. the remaining tools by name
+//
+// The result keeps the input order, so the definitions sent to the model stay
+// stable between iterations. maxDefs <= 0 means no limit. It also returns the
+// names of the dropped tools.
+func limitToolDefs(
+	defs []providers.ToolDefinition,
+	maxDefs int,
+	core []string,
+	messages []providers.Message,
+) ([]providers.ToolDefinition, []string) {
+	if maxDefs <= 0 || len(defs) <= maxDefs {
+		return defs, nil
+	}
+
+	available := make(map[string]struct{}, len(defs))
+	for _, def := range defs {
+		available[def.Function.Name] = struct{}{}
+	}
+	keep := make(map[string]struct{}, maxDefs)
+	pick := func(name string) {
+		if len(keep) >= maxDefs {
+			return
+		}
+		if _, ok := available[name]; ok {
+			keep[name] = struct{}{}
+		}
+	}
+
+	for _, name := range core {
+		pick(strings.TrimSpace(name))
+	}
+	for _, name := range recentlyCalledTools(messages) {
+		pick(name)
+	}
+	for _, name := range toolsMatchingKeywords(defs, recentUserKeywords(messages)) {
+		pick(name)
+	}
+	for _, def := range defs {
+		pick(def.Function.Name)
+	}
+
+	kept := make([]providers.ToolDefinition, 0, len(keep))
+	var dropped []string
+	for _, def := range defs {
+		if _, ok := keep[def.Function.Name]; ok {
+			kept = append(kept, def)
+		} else {
+			dropped = append(dropped, def.Function.Name)
+		}
+	}
+	return kept, dropped
+}
+
+// recentlyCalledTools returns the names of tools called by the assistant,
+// most recent first, without duplicates.
+func recentlyCalledTools(messages []providers.Message) []string {
+	var names []string
+	seen := make(map[string]struct{})
+	for i := len(messages) - 1; i >= 0; i-- {
+		calls := messages[i].ToolCalls
+		for j := len(calls) - 1; j >= 0; j-- {
+			name := calls[j].Name
+			if name == "" && calls[j].Function != nil {
+				name = calls[j].Function.Name
+			}
+			if _, dup := seen[name]; name == "" || dup {
+				continue
+			}
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// recentUserKeywords returns the distinct words of the latest user messages,
+// as split by splitWords.
+func recentUserKeywords(messages []providers.Message) map[string]struct{} {
+	keywords := make(map[string]struct{})
+	found := 0
+	for i := len(messages) - 1; i >= 0 && found < toolBudgetRecentUserMessages; i-- {
+		if messages[i].Role != "user" {
+			continue
+		}
+		found++
+		for _, word := range splitWords(messages[i].Content) {
+			keywords[word] = struct{}{}
+		}
+	}
+	return keywords
+}
+
+// toolsMatchingKeywords ranks tools by how many keywords appear among the
+// words of their name and description. Tools with no match are left out.
+func toolsMatchingKeywords(defs []providers.ToolDefinition, keywords map[string]struct{}) []string {
+	if len(keywords) == 0 {
+		return nil
+	}
+	type scored struct {
+		name  string
+		score int
+	}
+	var matches []scored
+	for _, def := range defs {
+		score := 0
+		seen := make(map[string]struct{})
+		for _, word := range splitWords(def.Function.Name + " " + def.Function.Description) {
+			if _, dup := seen[word]; dup {
+				continue
+			}
+			seen[word] = struct{}{}
+			if _, ok := keywords[word]; ok {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{name: def.Function.Name, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.name
+	}
+	return names
+}
+
+// splitWords lowercases s and splits it on anything that is not a letter or
+// digit, so "web_search" yields "web" and "search". Words shorter than three
+// characters and stopwords are dropped.
+func splitWords(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := fields[:0]
+	for _, f := range fields {
+		if _, stop := toolBudgetStopwords[f]; stop || len([]rune(f)) < 3 {
+			continue
+		}
+		words = append(words, f)
+	}
+	return words
+}
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func budgetTestDefs(nameDescs ...string) []providers.ToolDefinition {
+	var defs []providers.ToolDefinition
+	for i := 0; i+1 < len(nameDescs); i += 2 {
+		defs = append(defs, providers.ToolDefinition{
+			Type: "function",
+			Function: providers.ToolFunctionDefinition{
+				Name:        nameDescs[i],
+				Description: nameDescs[i+1],
+			},
+		})
+	}
+	return defs
+}
+
+func defNames(defs []providers.ToolDefinition) []string {
+	names := make([]string, len(defs))
+	for i, def := range defs {
+		names[i] = def.Function.Name
+	}
+	return names
+}
+
+func TestLimitToolDefs(t *testing.T) {
+	defs := budgetTestDefs(
+		"exec", "Run a shell command",
+		"github_issue", "Create an issue in a GitHub repository",
+		"message", "Send a message to the user",
+		"read_file", "Read the contents of a file",
+		"weather", "Get the weather forecast for a city",
+		"web_search", "Search the web",
+	)
+	messages := []providers.Message{
+		{Role: "user", Content: "what's in notes.md?"},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "1", Name: "read_file"}}},
+		{Role: "tool", ToolCallID: "1", Content: "..."},
+		{Role: "user", Content: "Will it rain in Paris? Check the forecast"},
+	}
+
+	t.Run("under the cap keeps everything", func(t *testing.T) {
+		kept, dropped := limitToolDefs(defs, 10, nil, messages)
+		if len(kept) != len(defs) || dropped != nil {
+			t.Fatalf("kept %v, dropped %v", defNames(kept), dropped)
+		}
+	})
+
+	t.Run("zero means no limit", func(t *testing.T) {
+		if kept, _ := limitToolDefs(defs, 0, nil, messages); len(kept) != len(defs) {
+			t.Fatalf("kept %v", defNames(kept))
+		}
+	})
+
+	t.Run("core, recent and matching tools win", func(t *testing.T) {
+		kept, dropped := limitToolDefs(defs, 3, []string{"message", "not_registered"}, messages)
+		want := []string{"message", "read_file", "weather"}
+		if got := defNames(kept); !slices.Equal(got, want) {
+			t.Fatalf("kept %v, want %v", got, want)
+		}
+		if wantDropped := []string{"exec", "github_issue", "web_search"}; !slices.Equal(dropped, wantDropped) {
+			t.Fatalf("dropped %v, want %v", dropped, wantDropped)
+		}
+	})
+
+	t.Run("fills remaining slots in input order", func(t *testing.T) {
+		kept, _ := limitToolDefs(defs, 2, nil, []providers.Message{{Role: "user", Content: "hi"}})
+		if got, want := defNames(kept), []string{"exec", "github_issue"}; !slices.Equal(got, want) {
+			t.Fatalf("kept %v, want %v", got, want)
+		}
+	})
+}
+
+func TestPipeline_CallLLM_ToolDefBudgetAppliesAfterNativeSearchFilter(t *testing.T) {
+	provider := &nativeSearchCaptureProvider{}
+	al, agent, cleanup := newTurnCoordTestLoop(t, provider)
+	defer cleanup()
+
+	agent.Tools.Register(&allowlistTestTool{name: "web_search"})
+	agent.Tools.Register(&allowlistTestTool{name: "alpha"})
+	agent.Tools.Register(&allowlistTestTool{name: "beta"})
+	total := len(agent.Tools.ToProviderDefs())
+
+	al.cfg.Tools.Web.Enabled = true
+	al.cfg.Tools.Web.PreferNative = true
+	// web_search is core, so a cap applied before the native search filter
+	// would spend a slot on it and send one tool fewer than allowed.
+	al.cfg.Agents.Defaults.MaxToolDefs = total - 1
+	al.cfg.Agents.Defaults.CoreTools = []string{"web_search"}
+
+	pipeline := NewPipeline(al)
+	ts := newTurnState(agent, makeTestProcessOpts("test-session"), turnEventScope{
+		turnID:  "turn-1",
+		context: newTurnContext(nil, nil, nil),
+	})
+	exec, err := pipeline.SetupTurn(context.Background(), ts)
+	if err != nil {
+		t.Fatalf("SetupTurn failed: %v", err)
+	}
+	if _, err := pipeline.CallLLM(context.Background(), context.Background(), ts, exec, 1); err != nil {
+		t.Fatalf("CallLLM failed: %v", err)
+	}
+
+	names := defNames(provider.tools)
+	if slices.Contains(names, "web_search") {
+		t.Fatalf("tools = %v, want web_search replaced by native search", names)
+	}
+	if len(names) != total-1 {
+		t.Fatalf("sent %d tools (%v), want %d", len(names), names, total-1)
+	}
+}
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"encoding/json"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// repairToolCallArguments gives a tool call whose arguments the provider
+// could not decode a second chance: common almost-JSON mistakes are fixed in
+// place. Calls that stay malformed are left for the registry to reject with an
+// error asking the model to reissue them.
+func repairToolCallArguments(registry *tools.ToolRegistry, tc providers.ToolCall) providers.ToolCall {
+	if registry == nil {
+		return tc
+	}
+	tool, ok := registry.Get(tc.Name)
+	if !ok {
+		return tc
+	}
+	raw, malformed := tools.MalformedArguments(tool.Parameters(), tc.Arguments)
+	if !malformed {
+		return tc
+	}
+	args, fixes, err := tools.RepairArguments(raw)
+	if err != nil {
+		logger.DebugCF("agent", "Could not repair tool call arguments", map[string]any{
+			"tool":  tc.Name,
+			"error": err.Error(),
+		})
+		return tc
+	}
+	logger.DebugCF("agent", "Repaired tool call arguments", map[string]any{
+		"tool":  tc.Name,
+		"fixes": fixes,
+	})
+	argumentsJSON, err := json.Marshal(args)
+	if err != nil {
+		return tc
+	}
+	tc.Arguments = args
+	if tc.Function != nil {
+		fn := *tc.Function
+		fn.Arguments = string(argumentsJSON)
+		tc.Function = &fn
+	}
+	return tc
+}
@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToolCallRepair_FixesMalformedArguments(t *testing.T) {
+	provider := &correctionProvider{args: []map[string]any{
+		{"raw": "```json\n{'name': 'Ada',}\n```"},
+	}}
+	tool := runCorrectionTurn(t, provider)
+
+	if len(tool.greeted) != 1 || tool.greeted[0] != "Ada" {
+		t.Fatalf("greeted = %v, want [Ada]", tool.greeted)
+	}
+}
+
+func TestToolCallRepair_AsksToReissueUnrepairableCall(t *testing.T) {
+	provider := &correctionProvider{args: []map[string]any{
+		{"raw": "name=Ada"},
+		{"name": "Ada"},
+	}}
+	tool := runCorrectionTurn(t, provider)
+
+	if len(tool.greeted) != 1 || tool.greeted[0] != "Ada" {
+		t.Fatalf("greeted = %v, want only the reissued call", tool.greeted)
+	}
+	if len(provider.toolResults) == 0 || !strings.Contains(provider.toolResults[0], "not valid JSON") {
+		t.Fatalf("first tool result = %v, want a reissue request", provider.toolResults)
+	}
+}
@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// recordToolCorrection updates the per-turn count of correctable failures for
+// tool and returns it. A successful call clears the count; failures the model
+// cannot fix by changing arguments leave it as is.
+func (ts *turnState) recordToolCorrection(tool string, result *tools.ToolResult) int {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if result == nil || !result.IsError {
+		delete(ts.toolCorrections, tool)
+		return 0
+	}
+	if !result.ErrorCode.Correctable() {
+		return ts.toolCorrections[tool]
+	}
+	if ts.toolCorrections == nil {
+		ts.toolCorrections = make(map[string]int)
+	}
+	ts.toolCorrections[tool]++
+	return ts.toolCorrections[tool]
+}
+
+// toolCorrectionsExhausted reports whether tool already failed more often
+// than the retry budget allows in this turn.
+func (ts *turnState) toolCorrectionsExhausted(tool string, limit int) bool {
+	if limit <= 0 {
+		return false
+	}
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.toolCorrections[tool] > limit
+}
+
+// toolCorrectionNote is appended to a correctable tool error. While retries
+// remain it shows the tool's parameter schema so the model can fix its
+// arguments; once they run out it tells the model to move on.
+func toolCorrectionNote(tool tools.Tool, name string, failures, limit int) string {
+	if failures > limit {
+		return fmt.Sprintf(
+			"[%s failed %d times with arguments that could not be corrected. It is disabled for the rest "+
+				"of this turn; continue without it or tell the user what went wrong.]",
+			name, failures,
+		)
+	}
+	note := fmt.Sprintf(
+		"[Correction %d of %d for %s: check the arguments against the parameter schema and retry once "+
+			"with corrected values.",
+		failures, limit, name,
+	)
+	if tool != nil {
+		if schema, err := json.Marshal(tool.Parameters()); err == nil {
+			note += "\nParameters: " + string(schema)
+		}
+	}
+	return note + "]"
+}
+
+// toolCorrectionDenial explains why a tool whose corrections are used up is
+// not run again in this turn.
+func toolCorrectionDenial(name string) string {
+	return fmt.Sprintf(
+		"Tool %q is disabled for the rest of this turn after repeated invalid calls. "+
+			"Continue without it or tell the user what went wrong.",
+		name,
+	)
+}
@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+type greetTool struct {
+	greeted []string
+}
+
+func (t *greetTool) Name() string        { return "greet" }
+func (t *greetTool) Description() string { return "greet someone by name" }
+
+func (t *greetTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string"},
+		},
+		"required": []string{"name"},
+	}
+}
+
+func (t *greetTool) Execute(ctx context.Context, args map[string]any) *tools.ToolResult {
+	name, _ := args["name"].(string)
+	t.greeted = append(t.greeted, name)
+	return tools.SilentResult("greeted " + name)
+}
+
+// correctionProvider calls greet with the scripted argument sets, one per
+// LLM call, and records the tool results it is shown.
+type correctionProvider struct {
+	args        []map[string]any
+	calls       int
+	toolResults []string
+}
+
+func (p *correctionProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	if last := messages[len(messages)-1]; last.Role == "tool" {
+		p.toolResults = append(p.toolResults, last.Content)
+	}
+	p.calls++
+	if p.calls > len(p.args) {
+		return &providers.LLMResponse{Content: "done"}, nil
+	}
+	return &providers.LLMResponse{
+		ToolCalls: []providers.ToolCall{{
+			ID:        "call_" + string(rune('0'+p.calls)),
+			Type:      "function",
+			Name:      "greet",
+			Arguments: p.args[p.calls-1],
+		}},
+	}, nil
+}
+
+func (p *correctionProvider) GetDefaultModel() string { return "correction-model" }
+
+func runCorrectionTurn(t *testing.T, provider *correctionProvider) *greetTool {
+	t.Helper()
+	al, agent, cleanup := newHookTestLoop(t, provider)
+	t.Cleanup(cleanup)
+	tool := &greetTool{}
+	al.RegisterTool(tool)
+
+	resp, err := al.runAgentLoop(context.Background(), agent, processOptions{
+		SessionKey:      "session-1",
+		Channel:         "cli",
+		ChatID:          "direct",
+		UserMessage:     "greet alice",
+		DefaultResponse: defaultResponse,
+	})
+	if err != nil {
+		t.Fatalf("runAgentLoop failed: %v", err)
+	}
+	if resp != "done" {
+		t.Fatalf("response = %q, want done", resp)
+	}
+	return tool
+}
+
+func TestToolCorrection_BadArgumentsThenSuccess(t *testing.T) {
+	provider := &correctionProvider{args: []map[string]any{
+		{"nme": "alice"},
+		{"name": "alice"},
+	}}
+	tool := runCorrectionTurn(t, provider)
+
+	if len(tool.greeted) != 1 || tool.greeted[0] != "alice" {
+		t.Fatalf("greeted = %v, want only the corrected call to run", tool.greeted)
+	}
+	if len(provider.toolResults) != 2 {
+		t.Fatalf("tool results = %q", provider.toolResults)
+	}
+	first := provider.toolResults[0]
+	for _, want := range []string{"invalid arguments", "Correction 1 of 2 for greet", `"required":["name"]`} {
+		if !strings.Contains(first, want) {
+			t.Errorf("first tool result missing %q:\n%s", want, first)
+		}
+	}
+	if provider.toolResults[1] != "greeted alice" {
+		t.Errorf("second tool result = %q", provider.toolResults[1])
+	}
+}
+
+func TestToolCorrection_DisablesToolWhenRetriesRunOut(t *testing.T) {
+	bad := map[string]any{"nme": "alice"}
+	provider := &correctionProvider{args: []map[string]any{bad, bad, bad, {"name": "alice"}}}
+	tool := runCorrectionTurn(t, provider)
+
+	if len(tool.greeted) != 0 {
+		t.Fatalf("greeted = %v, want the tool blocked after the retries ran out", tool.greeted)
+	}
+	if len(provider.toolResults) != 4 {
+		t.Fatalf("tool results = %q", provider.toolResults)
+	}
+	if !strings.Contains(provider.toolResults[1], "Correction 2 of 2") {
+		t.Errorf("second failure = %q", provider.toolResults[1])
+	}
+	if !strings.Contains(provider.toolResults[2], "disabled for the rest of this turn") {
+		t.Errorf("third failure should give up, got %q", provider.toolResults[2])
+	}
+	if !strings.Contains(provider.toolResults[3], `Tool "greet" is disabled`) {
+		t.Errorf("call after giving up = %q, want a denial", provider.toolResults[3])
+	}
+}
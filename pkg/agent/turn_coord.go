@@ -14,7 +14,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
-func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState, pipeline *Pipeline) (turnResult, error) {
+func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState, pipeline *Pipeline) (_ turnResult, turnErr error) {
 	turnCtx, turnCancel := context.WithCancel(ctx)
 	defer turnCancel()
 	ts.setTurnCancel(turnCancel)
@@ -42,6 +42,10 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState, pipeline *Pipel
 				finalSuccessfulPath = append([]string(nil), attemptedSkills...)
 			}
 		}
+		turnError := ""
+		if turnStatus == TurnEndStatusError && turnErr != nil {
+			turnError = turnErr.Error()
+		}
 		al.emitEvent(
 			runtimeevents.KindAgentTurnEnd,
 			ts.eventMeta("runTurn", "turn.end"),
@@ -59,6 +63,8 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState, pipeline *Pipel
 				SkillContextSnapshots: skillContextSnapshots,
 				ToolKinds:             ts.toolKindsSnapshot(),
 				ToolExecutions:        ts.toolExecutionsSnapshot(),
+				Usage:                 ts.usageSnapshot(),
+				Error:                 turnError,
 			},
 		)
 	}()
@@ -82,6 +88,9 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState, pipeline *Pipel
 	if err != nil {
 		return turnResult{}, err
 	}
+	if release := pipeline.usePreferredModel(ts, exec); release != nil {
+		defer release()
+	}
 
 	// Convenience references to exec fields used throughout the turn loop.
 	messages := exec.messages
@@ -89,7 +98,10 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState, pipeline *Pipel
 	maxMediaSize := pipeline.Cfg.Agents.Defaults.GetMaxMediaSize()
 	finalContent := exec.finalContent
 
-	for ts.currentIteration() < ts.agent.MaxIterations || len(exec.pendingMessages) > 0 || func() bool {
+	maxIterations := ts.agent.MaxIterations
+	limitBehavior := parseIterationLimitBehavior(pipeline.Cfg.Agents.Defaults.OnIterationLimit)
+
+	for ts.currentIteration() < maxIterations || len(exec.pendingMessages) > 0 || func() bool {
 		graceful, _ := ts.gracefulInterruptRequested()
 		return graceful
 	}() {
@@ -187,7 +199,7 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState, pipeline *Pipel
 			map[string]any{
 				"agent_id":  ts.agent.ID,
 				"iteration": iteration,
-				"max":       ts.agent.MaxIterations,
+				"max":       maxIterations,
 			})
 
 		// Execute LLM call via Pipeline
@@ -195,6 +207,7 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState, pipeline *Pipel
 		ctrl, callErr := pipeline.CallLLM(ctx, turnCtx, ts, exec, iteration)
 		if callErr != nil {
 			turnStatus = TurnEndStatusError
+			al.persistPartialTurn(ts, exec)
 			return turnResult{}, callErr
 		}
 		messages = exec.messages
@@ -264,12 +277,19 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState, pipeline *Pipel
 		return al.abortTurn(ts)
 	}
 
-	if finalContent == "" {
-		if ts.currentIteration() >= ts.agent.MaxIterations && ts.agent.MaxIterations > 0 {
-			finalContent = toolLimitResponse
-		} else {
-			finalContent = ts.opts.DefaultResponse
-		}
+	if maxIterations > 0 && ts.currentIteration() >= maxIterations {
+		limitBehavior = al.offerContinuation(ts, limitBehavior)
+		logger.WarnCF("agent", "Tool iteration limit reached without a final response", map[string]any{
+			"agent_id":           ts.agent.ID,
+			"turn_id":            ts.turnID,
+			"max":                maxIterations,
+			"on_iteration_limit": string(limitBehavior),
+			"continued":          ts.opts.Continued,
+			"has_partial":        finalContent != "",
+		})
+		finalContent = iterationLimitResponse(finalContent, maxIterations, limitBehavior)
+	} else if finalContent == "" {
+		finalContent = ts.opts.DefaultResponse
 	}
 
 	// Check hard abort before finalizing (may have been set during tool execution)
@@ -377,7 +397,7 @@ func (al *AgentLoop) askSideQuestion(
 		if err != nil {
 			return "", err
 		}
-		*opts = resolved
+		*opts = al.applyNoTools(resolved)
 	}
 
 	var media []string
@@ -480,7 +500,7 @@ func (al *AgentLoop) askSideQuestion(
 				applyThinkingOption(callOpts, provider, settings, false, agent.ID)
 			}
 		}
-		return provider.Chat(ctx, callMessages, nil, model, callOpts)
+		return provider.Chat(ctx, messagesForProvider(provider, callMessages), nil, model, callOpts)
 	}
 
 	turnCtx := newTurnContext(nil, nil, nil)
@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -1124,3 +1125,30 @@ func TestTurnState_SkillContextSnapshotsTrackLatestSuccessfulPath(t *testing.T)
 		t.Fatalf("snapshots[1] = %+v, want sequence=2 trigger=%q", snapshots[1], skillContextTriggerContextRetryRebuild)
 	}
 }
+
+type refusingProvider struct{}
+
+func (refusingProvider) Chat(
+	context.Context,
+	[]providers.Message,
+	[]providers.ToolDefinition,
+	string,
+	map[string]any,
+) (*providers.LLMResponse, error) {
+	return &providers.LLMResponse{Content: "I can't help with that.", FinishReason: "content_filter"}, nil
+}
+
+func (refusingProvider) GetDefaultModel() string { return "refusing-model" }
+
+func TestRunTurn_ContentPolicyRefusalRepliesWithNotice(t *testing.T) {
+	al, _, cleanup := newTurnCoordTestLoop(t, refusingProvider{})
+	defer cleanup()
+
+	reply, err := al.ProcessDirect(context.Background(), "hello", "session-refusal")
+	if err != nil {
+		t.Fatalf("ProcessDirect() error = %v, want a reply", err)
+	}
+	if !strings.HasPrefix(reply, contentPolicyReply) || !strings.Contains(reply, "I can't help with that.") {
+		t.Fatalf("reply = %q, want the content policy notice with the refusal text", reply)
+	}
+}
@@ -145,8 +145,10 @@ type turnExecution struct {
 	llmModel            string
 	llmModelName        string
 	llmOpts             map[string]any
+	modelParams         config.ModelParams // profile for the active model
 	gracefulTerminal    bool
 	useNativeSearch     bool
+	partialContent      string // text streamed by the current LLM call so far
 
 	// Phase tracking
 	phase LLMPhase
@@ -195,6 +197,7 @@ type turnState struct {
 	skillContextTrace []SkillContextSnapshot
 	toolKinds         []string
 	toolExecutions    []ToolExecutionRecord
+	toolCorrections   map[string]int // correctable failures per tool, reset by a success
 	turnCtx           *TurnContext
 
 	channel     string
@@ -244,6 +247,7 @@ type turnState struct {
 	tokenBudget      *atomic.Int64        // Shared token budget counter
 	lastFinishReason string               // Last LLM finish_reason
 	lastUsage        *providers.UsageInfo // Last LLM usage info
+	usage            providers.UsageInfo  // Usage summed over the turn's LLM calls
 
 	// Back-reference to the owning AgentLoop (set for SubTurns only, used for hard abort cascade)
 	al *AgentLoop
@@ -736,6 +740,8 @@ func normalizeMessageForComparison(msg providers.Message) providers.Message {
 	msg.PromptLayer = ""
 	msg.PromptSlot = ""
 	msg.PromptSource = ""
+	// The session stamps CreatedAt when it stores a message.
+	msg.CreatedAt = nil
 
 	if len(msg.Media) == 0 {
 		msg.Media = nil
@@ -872,6 +878,21 @@ func (ts *turnState) SetLastUsage(usage *providers.UsageInfo) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 	ts.lastUsage = usage
+	if usage != nil {
+		ts.usage.PromptTokens += usage.PromptTokens
+		ts.usage.CompletionTokens += usage.CompletionTokens
+		ts.usage.TotalTokens += usage.TotalTokens
+		ts.usage.CacheCreationTokens += usage.CacheCreationTokens
+		ts.usage.CacheReadTokens += usage.CacheReadTokens
+		ts.usage.ReasoningTokens += usage.ReasoningTokens
+	}
+}
+
+// usageSnapshot returns the usage summed over the turn's LLM calls.
+func (ts *turnState) usageSnapshot() providers.UsageInfo {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.usage
 }
 
 // =============================================================================
@@ -88,6 +88,7 @@ func TestTrimHistoryToFitContextWindow_WithProtectedTurnTailKeepsActiveTurn(t *t
 		userPromptMessage(current, nil),
 	})
 	trimmedStable, messages, fit := trimHistoryToFitContextWindow(
+		nil,
 		stable,
 		func(trimmedHistory []providers.Message) []providers.Message {
 			return append(append([]providers.Message(nil), trimmedHistory...), protected...)
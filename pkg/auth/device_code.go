@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// errDevicePending reports that the user has not finished signing in yet.
+var errDevicePending = errors.New("authorization pending")
+
+// errDeviceSlowDown asks the client to poll less often.
+var errDeviceSlowDown = errors.New("slow down")
+
+// standardDeviceCode is a device authorization response as defined by
+// RFC 8628. Google names the verification field verification_url.
+type standardDeviceCode struct {
+	DeviceCode      string          `json:"device_code"`
+	UserCode        string          `json:"user_code"`
+	VerificationURI string          `json:"verification_uri"`
+	VerificationURL string          `json:"verification_url"`
+	ExpiresIn       json.RawMessage `json:"expires_in"`
+	Interval        json.RawMessage `json:"interval"`
+}
+
+// standardDeviceAuth is a started device sign-in: the code to poll with,
+// the code the user types in, the page to type it on, and how often and how
+// long to poll.
+type standardDeviceAuth struct {
+	DeviceCode string
+	UserCode   string
+	VerifyURL  string
+	Interval   time.Duration
+	ExpiresIn  time.Duration
+}
+
+// requestStandardDeviceCode starts an RFC 8628 device authorization at
+// cfg.DeviceAuthURL.
+func requestStandardDeviceCode(cfg OAuthProviderConfig) (*standardDeviceAuth, error) {
+	resp, err := http.PostForm(cfg.DeviceAuthURL, url.Values{
+		"client_id": {cfg.ClientID},
+		"scope":     {cfg.Scopes},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("requesting device code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading device code response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("device code request failed: %s", string(body))
+	}
+
+	var dc standardDeviceCode
+	if err = json.Unmarshal(body, &dc); err != nil {
+		return nil, fmt.Errorf("parsing device code response: %w", err)
+	}
+	if dc.DeviceCode == "" || dc.UserCode == "" {
+		return nil, fmt.Errorf("device code response is missing device_code or user_code")
+	}
+	interval, err := parseFlexibleInt(dc.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("parsing device code interval: %w", err)
+	}
+	if interval < 1 {
+		interval = 5
+	}
+	expiresIn, err := parseFlexibleInt(dc.ExpiresIn)
+	if err != nil {
+		return nil, fmt.Errorf("parsing device code lifetime: %w", err)
+	}
+	if expiresIn < 1 {
+		expiresIn = 15 * 60
+	}
+
+	verifyURL := dc.VerificationURI
+	if verifyURL == "" {
+		verifyURL = dc.VerificationURL
+	}
+	return &standardDeviceAuth{
+		DeviceCode: dc.DeviceCode,
+		UserCode:   dc.UserCode,
+		VerifyURL:  verifyURL,
+		Interval:   time.Duration(interval) * time.Second,
+		ExpiresIn:  time.Duration(expiresIn) * time.Second,
+	}, nil
+}
+
+// pollStandardDeviceToken asks cfg.TokenURL once whether the user has
+// approved deviceCode. It returns errDevicePending or errDeviceSlowDown
+// while sign-in is still open.
+func pollStandardDeviceToken(cfg OAuthProviderConfig, deviceCode string) (*AuthCredential, error) {
+	data := url.Values{
+		"client_id":   {cfg.ClientID},
+		"device_code": {deviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+	}
+	if cfg.ClientSecret != "" {
+		data.Set("client_secret", cfg.ClientSecret)
+	}
+
+	resp, err := http.PostForm(cfg.TokenURL, data)
+	if err != nil {
+		return nil, fmt.Errorf("polling device token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading device token response: %w", err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return parseTokenResponse(body, credentialProvider(cfg))
+	}
+
+	var tokenErr struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	_ = json.Unmarshal(body, &tokenErr)
+	switch tokenErr.Error {
+	case "authorization_pending":
+		return nil, errDevicePending
+	case "slow_down":
+		return nil, errDeviceSlowDown
+	case "access_denied":
+		return nil, fmt.Errorf("sign-in was denied")
+	case "expired_token":
+		return nil, fmt.Errorf("device code expired; run the login again")
+	case "":
+		return nil, fmt.Errorf("device token request failed: %s", string(body))
+	default:
+		if tokenErr.ErrorDescription != "" {
+			return nil, fmt.Errorf("device token request failed: %s: %s", tokenErr.Error, tokenErr.ErrorDescription)
+		}
+		return nil, fmt.Errorf("device token request failed: %s", tokenErr.Error)
+	}
+}
+
+// loginStandardDeviceCode runs the RFC 8628 device flow used by Google: the
+// user approves the code on another device while this one polls for tokens.
+func loginStandardDeviceCode(cfg OAuthProviderConfig) (*AuthCredential, error) {
+	da, err := requestStandardDeviceCode(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf(
+		"\nTo authenticate, open this URL in your browser:\n\n  %s\n\nThen enter this code: %s\n\nWaiting for authentication...\n",
+		da.VerifyURL,
+		da.UserCode,
+	)
+
+	interval := da.Interval
+	deadline := time.Now().Add(da.ExpiresIn)
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+		cred, err := pollStandardDeviceToken(cfg, da.DeviceCode)
+		switch {
+		case err == nil:
+			return cred, nil
+		case errors.Is(err, errDeviceSlowDown):
+			interval += 5 * time.Second
+		case !errors.Is(err, errDevicePending):
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("device code authentication timed out after %s", da.ExpiresIn)
+}
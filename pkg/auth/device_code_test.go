@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestStandardDeviceCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.FormValue("client_id") != "test-client" || r.FormValue("scope") != "scope-a scope-b" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"device_code":      "dev-123",
+			"user_code":        "ABCD-EFGH",
+			"verification_url": "https://www.google.com/device",
+			"expires_in":       1800,
+			"interval":         "7",
+		})
+	}))
+	defer server.Close()
+
+	da, err := requestStandardDeviceCode(OAuthProviderConfig{
+		ClientID:      "test-client",
+		DeviceAuthURL: server.URL,
+		Scopes:        "scope-a scope-b",
+	})
+	if err != nil {
+		t.Fatalf("requestStandardDeviceCode() error: %v", err)
+	}
+	if da.DeviceCode != "dev-123" || da.UserCode != "ABCD-EFGH" {
+		t.Errorf("codes = %q/%q, want dev-123/ABCD-EFGH", da.DeviceCode, da.UserCode)
+	}
+	if da.VerifyURL != "https://www.google.com/device" {
+		t.Errorf("VerifyURL = %q, want Google's verification_url", da.VerifyURL)
+	}
+	if da.Interval != 7*time.Second || da.ExpiresIn != 30*time.Minute {
+		t.Errorf("Interval/ExpiresIn = %s/%s, want 7s/30m", da.Interval, da.ExpiresIn)
+	}
+}
+
+func TestRequestStandardDeviceCodeMissingCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"verification_uri": "https://example.com/device"})
+	}))
+	defer server.Close()
+
+	if _, err := requestStandardDeviceCode(OAuthProviderConfig{DeviceAuthURL: server.URL}); err == nil {
+		t.Fatal("expected error for response without device_code")
+	}
+}
+
+func TestPollStandardDeviceToken(t *testing.T) {
+	responses := []struct {
+		status int
+		body   map[string]any
+	}{
+		{http.StatusPreconditionRequired, map[string]any{"error": "authorization_pending"}},
+		{http.StatusForbidden, map[string]any{"error": "slow_down"}},
+		{http.StatusOK, map[string]any{
+			"access_token":  "google-access",
+			"refresh_token": "google-refresh",
+			"expires_in":    3599,
+		}},
+	}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:device_code" ||
+			r.FormValue("device_code") != "dev-123" || r.FormValue("client_secret") != "secret" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		resp := responses[calls]
+		calls++
+		w.WriteHeader(resp.status)
+		json.NewEncoder(w).Encode(resp.body)
+	}))
+	defer server.Close()
+
+	cfg := OAuthProviderConfig{
+		ClientID:     "test-client",
+		ClientSecret: "secret",
+		TokenURL:     server.URL,
+		Provider:     "google",
+	}
+
+	if _, err := pollStandardDeviceToken(cfg, "dev-123"); !errors.Is(err, errDevicePending) {
+		t.Fatalf("first poll error = %v, want errDevicePending", err)
+	}
+	if _, err := pollStandardDeviceToken(cfg, "dev-123"); !errors.Is(err, errDeviceSlowDown) {
+		t.Fatalf("second poll error = %v, want errDeviceSlowDown", err)
+	}
+	cred, err := pollStandardDeviceToken(cfg, "dev-123")
+	if err != nil {
+		t.Fatalf("third poll error: %v", err)
+	}
+	if cred.AccessToken != "google-access" || cred.RefreshToken != "google-refresh" {
+		t.Errorf("tokens = %q/%q, want google-access/google-refresh", cred.AccessToken, cred.RefreshToken)
+	}
+	if cred.Provider != "google" || cred.AuthMethod != "oauth" {
+		t.Errorf("Provider/AuthMethod = %q/%q, want google/oauth", cred.Provider, cred.AuthMethod)
+	}
+}
+
+func TestPollStandardDeviceTokenDenied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]any{"error": "access_denied"})
+	}))
+	defer server.Close()
+
+	_, err := pollStandardDeviceToken(OAuthProviderConfig{TokenURL: server.URL}, "dev-123")
+	if err == nil || errors.Is(err, errDevicePending) {
+		t.Fatalf("error = %v, want a terminal error", err)
+	}
+}
@@ -21,13 +21,15 @@ import (
 )
 
 type OAuthProviderConfig struct {
-	Issuer       string
-	ClientID     string
-	ClientSecret string // Required for Google OAuth (confidential client)
-	TokenURL     string // Override token endpoint (Google uses a different URL than issuer)
-	Scopes       string
-	Originator   string
-	Port         int
+	Issuer        string
+	ClientID      string
+	ClientSecret  string // Required for Google OAuth (confidential client)
+	TokenURL      string // Override token endpoint (Google uses a different URL than issuer)
+	DeviceAuthURL string // RFC 8628 device authorization endpoint; empty uses OpenAI's device flow
+	Provider      string // Provider name stored on issued credentials; inferred from TokenURL when empty
+	Scopes        string
+	Originator    string
+	Port          int
 }
 
 type LoginBrowserOptions struct {
@@ -67,6 +69,30 @@ func GoogleAntigravityOAuthConfig() OAuthProviderConfig {
 	}
 }
 
+// GoogleOAuthConfig returns the OAuth configuration for the Gemini API
+// (provider "google"). It uses the same installed-app client as Antigravity
+// unless PICOCLAW_GOOGLE_CLIENT_ID and PICOCLAW_GOOGLE_CLIENT_SECRET name
+// another one. Google only allows the device-code flow for clients of type
+// "TVs and Limited Input devices", so --device-code needs such a client.
+func GoogleOAuthConfig() OAuthProviderConfig {
+	base := GoogleAntigravityOAuthConfig()
+	clientID, clientSecret := base.ClientID, base.ClientSecret
+	if id := strings.TrimSpace(os.Getenv("PICOCLAW_GOOGLE_CLIENT_ID")); id != "" {
+		clientID = id
+		clientSecret = strings.TrimSpace(os.Getenv("PICOCLAW_GOOGLE_CLIENT_SECRET"))
+	}
+	return OAuthProviderConfig{
+		Issuer:        base.Issuer,
+		TokenURL:      base.TokenURL,
+		DeviceAuthURL: "https://oauth2.googleapis.com/device/code",
+		Provider:      "google",
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		Scopes:        "https://www.googleapis.com/auth/cloud-platform https://www.googleapis.com/auth/generative-language.retriever https://www.googleapis.com/auth/userinfo.email",
+		Port:          base.Port,
+	}
+}
+
 func decodeBase64(s string) string {
 	data, err := base64.StdEncoding.DecodeString(s)
 	if err != nil {
@@ -340,6 +366,10 @@ func parseFlexibleInt(raw json.RawMessage) (int, error) {
 }
 
 func LoginDeviceCode(cfg OAuthProviderConfig) (*AuthCredential, error) {
+	if cfg.DeviceAuthURL != "" {
+		return loginStandardDeviceCode(cfg)
+	}
+
 	reqBody, _ := json.Marshal(map[string]string{
 		"client_id": cfg.ClientID,
 	})
@@ -444,7 +474,11 @@ func RefreshAccessToken(cred *AuthCredential, cfg OAuthProviderConfig) (*AuthCre
 		"client_id":     {cfg.ClientID},
 		"grant_type":    {"refresh_token"},
 		"refresh_token": {cred.RefreshToken},
-		"scope":         {"openid profile email"},
+	}
+	// Google narrows the refreshed token to the requested scopes, so leave
+	// scope out there to keep everything granted at login.
+	if !isGoogleIssuer(cfg) {
+		data.Set("scope", "openid profile email")
 	}
 	if cfg.ClientSecret != "" {
 		data.Set("client_secret", cfg.ClientSecret)
@@ -503,7 +537,7 @@ func buildAuthorizeURL(cfg OAuthProviderConfig, pkce PKCECodes, state, redirectU
 		"state":                 {state},
 	}
 
-	isGoogle := strings.Contains(strings.ToLower(cfg.Issuer), "accounts.google.com")
+	isGoogle := isGoogleIssuer(cfg)
 	if isGoogle {
 		// Google OAuth requires these for refresh token support
 		params.Set("access_type", "offline")
@@ -545,12 +579,6 @@ func ExchangeCodeForTokens(cfg OAuthProviderConfig, code, codeVerifier, redirect
 		tokenURL = cfg.TokenURL
 	}
 
-	// Determine provider name from config
-	provider := "openai"
-	if cfg.TokenURL != "" && strings.Contains(cfg.TokenURL, "googleapis.com") {
-		provider = "google-antigravity"
-	}
-
 	resp, err := http.PostForm(tokenURL, data)
 	if err != nil {
 		return nil, fmt.Errorf("exchanging code for tokens: %w", err)
@@ -565,7 +593,22 @@ func ExchangeCodeForTokens(cfg OAuthProviderConfig, code, codeVerifier, redirect
 		return nil, fmt.Errorf("token exchange failed: %s", string(body))
 	}
 
-	return parseTokenResponse(body, provider)
+	return parseTokenResponse(body, credentialProvider(cfg))
+}
+
+// credentialProvider returns the provider name for credentials issued under cfg.
+func credentialProvider(cfg OAuthProviderConfig) string {
+	if cfg.Provider != "" {
+		return cfg.Provider
+	}
+	if cfg.TokenURL != "" && strings.Contains(cfg.TokenURL, "googleapis.com") {
+		return "google-antigravity"
+	}
+	return "openai"
+}
+
+func isGoogleIssuer(cfg OAuthProviderConfig) bool {
+	return strings.Contains(strings.ToLower(cfg.Issuer), "accounts.google.com")
 }
 
 func parseTokenResponse(body []byte, provider string) (*AuthCredential, error) {
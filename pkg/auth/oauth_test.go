@@ -335,6 +335,79 @@ func TestOpenAIOAuthConfig(t *testing.T) {
 	}
 }
 
+func TestGoogleOAuthConfig(t *testing.T) {
+	t.Setenv("PICOCLAW_GOOGLE_CLIENT_ID", "")
+	cfg := GoogleOAuthConfig()
+	if cfg.Provider != "google" {
+		t.Errorf("Provider = %q, want google", cfg.Provider)
+	}
+	if cfg.DeviceAuthURL == "" || cfg.TokenURL == "" {
+		t.Error("DeviceAuthURL and TokenURL must be set")
+	}
+	if cfg.ClientID != GoogleAntigravityOAuthConfig().ClientID {
+		t.Error("ClientID should default to the installed-app client")
+	}
+	if !strings.Contains(cfg.Scopes, "https://www.googleapis.com/auth/cloud-platform") {
+		t.Errorf("Scopes = %q, want cloud-platform", cfg.Scopes)
+	}
+
+	t.Setenv("PICOCLAW_GOOGLE_CLIENT_ID", "tv-client")
+	t.Setenv("PICOCLAW_GOOGLE_CLIENT_SECRET", "tv-secret")
+	cfg = GoogleOAuthConfig()
+	if cfg.ClientID != "tv-client" || cfg.ClientSecret != "tv-secret" {
+		t.Errorf("client = %q/%q, want tv-client/tv-secret", cfg.ClientID, cfg.ClientSecret)
+	}
+}
+
+func TestExchangeCodeForTokensUsesConfiguredProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "google-access"})
+	}))
+	defer server.Close()
+
+	cfg := OAuthProviderConfig{TokenURL: server.URL + "/googleapis.com/token", Provider: "google"}
+	cred, err := ExchangeCodeForTokens(cfg, "code", "verifier", "http://localhost/cb")
+	if err != nil {
+		t.Fatalf("ExchangeCodeForTokens() error: %v", err)
+	}
+	if cred.Provider != "google" {
+		t.Errorf("Provider = %q, want google", cred.Provider)
+	}
+}
+
+func TestRefreshAccessTokenGoogleKeepsGrantedScopes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if _, ok := r.Form["scope"]; ok {
+			http.Error(w, "scope must not be sent", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "refreshed", "expires_in": 3599})
+	}))
+	defer server.Close()
+
+	cfg := GoogleOAuthConfig()
+	cfg.TokenURL = server.URL
+	cred := &AuthCredential{
+		AccessToken:  "old",
+		RefreshToken: "refresh",
+		Provider:     "google",
+		AuthMethod:   "oauth",
+		ProjectID:    "quota-project",
+	}
+
+	refreshed, err := RefreshAccessToken(cred, cfg)
+	if err != nil {
+		t.Fatalf("RefreshAccessToken() error: %v", err)
+	}
+	if refreshed.AccessToken != "refreshed" || refreshed.RefreshToken != "refresh" {
+		t.Errorf("tokens = %q/%q, want refreshed/refresh", refreshed.AccessToken, refreshed.RefreshToken)
+	}
+	if refreshed.Provider != "google" || refreshed.ProjectID != "quota-project" {
+		t.Errorf("Provider/ProjectID = %q/%q, want google/quota-project", refreshed.Provider, refreshed.ProjectID)
+	}
+}
+
 func TestParseDeviceCodeResponseIntervalAsNumber(t *testing.T) {
 	body := []byte(`{"device_auth_id":"abc","user_code":"DEF-1234","interval":5}`)
 
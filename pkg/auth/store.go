@@ -29,6 +29,8 @@ type AuthStore struct {
 const (
 	providerGoogleAntigravity = "google-antigravity"
 	providerAntigravityAlias  = "antigravity"
+	providerGoogle            = "google"
+	providerGeminiAlias       = "gemini"
 )
 
 func (c *AuthCredential) IsExpired() bool {
@@ -54,6 +56,8 @@ func canonicalProvider(provider string) string {
 	switch normalized {
 	case providerAntigravityAlias:
 		return providerGoogleAntigravity
+	case providerGeminiAlias:
+		return providerGoogle
 	default:
 		return normalized
 	}
@@ -173,6 +173,33 @@ func TestDeleteCredential(t *testing.T) {
 	}
 }
 
+func TestGeminiAliasUsesGoogleCredential(t *testing.T) {
+	setTestAuthHome(t)
+
+	cred := &AuthCredential{AccessToken: "google-token", Provider: "gemini", AuthMethod: "oauth"}
+	if err := SetCredential("gemini", cred); err != nil {
+		t.Fatalf("SetCredential() error: %v", err)
+	}
+
+	loaded, err := GetCredential("google")
+	if err != nil {
+		t.Fatalf("GetCredential() error: %v", err)
+	}
+	if loaded == nil || loaded.AccessToken != "google-token" {
+		t.Fatalf("GetCredential(google) = %+v, want google-token", loaded)
+	}
+	if loaded.Provider != "google" {
+		t.Errorf("Provider = %q, want %q", loaded.Provider, "google")
+	}
+
+	if err := DeleteCredential("gemini"); err != nil {
+		t.Fatalf("DeleteCredential() error: %v", err)
+	}
+	if loaded, _ = GetCredential("google"); loaded != nil {
+		t.Error("expected nil after deleting the gemini alias")
+	}
+}
+
 func TestLoadStoreEmpty(t *testing.T) {
 	setTestAuthHome(t)
 
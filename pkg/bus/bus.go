@@ -130,6 +130,9 @@ func (mb *MessageBus) InboundChan() <-chan InboundMessage {
 
 func (mb *MessageBus) PublishOutbound(ctx context.Context, msg OutboundMessage) error {
 	msg = NormalizeOutboundMessage(msg)
+	if IsProactive(ctx) {
+		msg.Proactive = true
+	}
 	if msg.Context.isZero() {
 		mb.publishFailure("outbound", runtimeScopeFromInboundContext(msg.Context), ErrMissingOutboundContext)
 		return ErrMissingOutboundContext
@@ -197,7 +200,12 @@ func (mb *MessageBus) SetEventPublisher(p EventPublisher) {
 }
 
 // GetStreamer returns a Streamer for the given channel+chatID+session via the delegate.
+// Proactive work never streams, so its output goes through PublishOutbound and
+// can be held back like any other proactive message.
 func (mb *MessageBus) GetStreamer(ctx context.Context, channel, chatID, sessionKey string) (Streamer, bool) {
+	if IsProactive(ctx) {
+		return nil, false
+	}
 	if d, ok := mb.streamDelegate.Load().(StreamDelegate); ok && d != nil {
 		return d.GetStreamer(ctx, channel, chatID, sessionKey)
 	}
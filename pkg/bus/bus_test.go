@@ -750,3 +750,23 @@ func TestCloseIdempotent(t *testing.T) {
 		t.Fatalf("expected ErrBusClosed after multiple closes, got %v", err)
 	}
 }
+
+func TestPublishOutbound_MarksProactiveContext(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+
+	ctx := WithProactive(context.Background())
+	msg := OutboundMessage{
+		Context: InboundContext{Channel: "telegram", ChatID: "123"},
+		Content: "reminder",
+	}
+	if err := mb.PublishOutbound(ctx, msg); err != nil {
+		t.Fatalf("PublishOutbound failed: %v", err)
+	}
+	if got := <-mb.OutboundChan(); !got.Proactive {
+		t.Fatalf("expected proactive message, got %+v", got)
+	}
+	if _, ok := mb.GetStreamer(ctx, "telegram", "123", "session"); ok {
+		t.Fatal("GetStreamer should not stream proactive work")
+	}
+}
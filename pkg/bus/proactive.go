@@ -0,0 +1,18 @@
+package bus
+
+import "context"
+
+type proactiveKey struct{}
+
+// WithProactive marks ctx as belonging to work the agent started on its own,
+// such as a heartbeat or cron job. Outbound messages published with it are
+// flagged Proactive.
+func WithProactive(ctx context.Context) context.Context {
+	return context.WithValue(ctx, proactiveKey{}, true)
+}
+
+// IsProactive reports whether ctx was marked with WithProactive.
+func IsProactive(ctx context.Context) bool {
+	proactive, _ := ctx.Value(proactiveKey{}).(bool)
+	return proactive
+}
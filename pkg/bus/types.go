@@ -7,6 +7,7 @@ type SenderInfo struct {
 	CanonicalID string `json:"canonical_id,omitempty"` // "platform:id" format
 	Username    string `json:"username,omitempty"`     // username (e.g. @alice)
 	DisplayName string `json:"display_name,omitempty"` // display name
+	Locale      string `json:"locale,omitempty"`       // sender's language tag (e.g. "en", "pt-BR"), if the platform reports it
 }
 
 // InboundContext captures the normalized, platform-agnostic facts about an
@@ -72,6 +73,22 @@ type ContextUsage struct {
 	UsedPercent       int `json:"used_percent"`        // 0-100, relative to compressAt
 }
 
+// OutboundOperation selects what an OutboundMessage does to the chat.
+type OutboundOperation string
+
+const (
+	// OutboundSend posts a new message. It is the default when empty.
+	OutboundSend OutboundOperation = "send"
+	// OutboundEdit replaces the content of TargetMessageID.
+	OutboundEdit OutboundOperation = "edit"
+	// OutboundDelete removes TargetMessageID.
+	OutboundDelete OutboundOperation = "delete"
+)
+
+// TargetLastMessage as TargetMessageID refers to the latest message the
+// agent sent to the chat.
+const TargetLastMessage = "last"
+
 type OutboundMessage struct {
 	Channel          string         `json:"channel"`
 	ChatID           string         `json:"chat_id"`
@@ -82,6 +99,14 @@ type OutboundMessage struct {
 	Content          string         `json:"content"`
 	ReplyToMessageID string         `json:"reply_to_message_id,omitempty"`
 	ContextUsage     *ContextUsage  `json:"context_usage,omitempty"`
+	// Operation and TargetMessageID edit or delete a message the agent sent
+	// earlier instead of posting a new one.
+	Operation       OutboundOperation `json:"operation,omitempty"`
+	TargetMessageID string            `json:"target_message_id,omitempty"`
+	// Proactive marks a message the agent sent on its own (heartbeat, cron,
+	// device events) rather than in reply to a user. Channels may hold such
+	// messages back during quiet hours.
+	Proactive bool `json:"proactive,omitempty"`
 }
 
 // MediaPart describes a single media attachment to send.
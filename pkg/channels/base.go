@@ -94,6 +94,8 @@ type BaseChannel struct {
 	placeholderRecorder PlaceholderRecorder
 	owner               Channel // the concrete channel that embeds this BaseChannel
 	reasoningChannelID  string
+	outputFormat        string
+	outputFormatters    map[string]OutputFormatter // per-format overrides of DefaultOutputFormatter
 }
 
 func NewBaseChannel(
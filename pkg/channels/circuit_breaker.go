@@ -0,0 +1,215 @@
+package channels
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	// breakerFailureThreshold is the number of consecutive failed sends
+	// (after per-message retries) that marks a channel as degraded.
+	breakerFailureThreshold = 5
+	breakerBaseCooldown     = 30 * time.Second
+	breakerMaxCooldown      = 10 * time.Minute
+)
+
+// ChannelHealthState is the circuit breaker state of a channel.
+type ChannelHealthState string
+
+const (
+	// ChannelHealthy means sends are flowing normally.
+	ChannelHealthy ChannelHealthState = "healthy"
+	// ChannelDegraded means sends to the channel kept failing and outbound
+	// messages are dropped until the next recovery probe succeeds. Receive
+	// and poll errors do not count; a channel that implements
+	// ConnectionStatusReporter shows them as ChannelDisconnected instead.
+	ChannelDegraded ChannelHealthState = "degraded"
+	// ChannelDisconnected means the channel lost its upstream connection and
+	// is reconnecting (see ConnectionStatusReporter).
+	ChannelDisconnected ChannelHealthState = "disconnected"
+)
+
+// ChannelHealth is a snapshot of a channel's circuit breaker, which only
+// sees outbound sends.
+type ChannelHealth struct {
+	State               ChannelHealthState `json:"state"`
+	ConsecutiveFailures int                `json:"consecutive_failures,omitempty"`
+	LastError           string             `json:"last_error,omitempty"`
+	DegradedSince       time.Time          `json:"degraded_since,omitzero"`
+	NextProbeAt         time.Time          `json:"next_probe_at,omitzero"`
+}
+
+// channelBreaker tracks consecutive send failures for one channel. Once the
+// threshold is reached the breaker opens: messages are dropped until the
+// cooldown elapses, then a single message is let through as a recovery probe.
+// Each failed probe doubles the cooldown up to breakerMaxCooldown.
+type channelBreaker struct {
+	mu            sync.Mutex
+	failures      int
+	lastErr       string
+	degradedSince time.Time
+	cooldown      time.Duration
+	nextProbe     time.Time
+	probing       bool
+}
+
+func (b *channelBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.degradedSince.IsZero() {
+		return true
+	}
+	if b.probing || now.Before(b.nextProbe) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// endProbe releases an in-flight recovery probe that ended without a
+// recordSuccess or recordFailure, e.g. because the send was cancelled, so the
+// next message can probe again. It is a no-op when no probe is in flight.
+func (b *channelBreaker) endProbe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// recordSuccess closes the breaker and reports whether it was degraded.
+func (b *channelBreaker) recordSuccess() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	recovered := !b.degradedSince.IsZero()
+	b.failures = 0
+	b.lastErr = ""
+	b.degradedSince = time.Time{}
+	b.cooldown = 0
+	b.nextProbe = time.Time{}
+	b.probing = false
+	return recovered
+}
+
+// recordFailure counts a failed send and reports whether the breaker just
+// transitioned into the degraded state.
+func (b *channelBreaker) recordFailure(now time.Time, err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if err != nil {
+		b.lastErr = err.Error()
+	}
+	if !b.degradedSince.IsZero() {
+		b.probing = false
+		b.cooldown = min(b.cooldown*2, breakerMaxCooldown)
+		b.nextProbe = now.Add(b.cooldown)
+		return false
+	}
+	if b.failures < breakerFailureThreshold {
+		return false
+	}
+	b.degradedSince = now
+	b.cooldown = breakerBaseCooldown
+	b.nextProbe = now.Add(b.cooldown)
+	return true
+}
+
+func (b *channelBreaker) snapshot() ChannelHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	h := ChannelHealth{
+		State:               ChannelHealthy,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastErr,
+	}
+	if !b.degradedSince.IsZero() {
+		h.State = ChannelDegraded
+		h.DegradedSince = b.degradedSince
+		h.NextProbeAt = b.nextProbe
+	}
+	return h
+}
+
+func (m *Manager) breakerFor(name string) *channelBreaker {
+	v, _ := m.breakers.LoadOrStore(name, &channelBreaker{})
+	return v.(*channelBreaker)
+}
+
+// breakerAllowSend reports whether an outbound send to the channel should be
+// attempted. Dropped sends are logged at debug level to keep degraded
+// channels from flooding the log.
+func (m *Manager) breakerAllowSend(name, chatID string) bool {
+	if m.breakerFor(name).allow(time.Now()) {
+		return true
+	}
+	logger.DebugCF("channels", "Channel degraded, dropping outbound message", map[string]any{
+		"channel": name,
+		"chat_id": chatID,
+	})
+	return false
+}
+
+func (m *Manager) breakerRecordSuccess(name string) {
+	if m.breakerFor(name).recordSuccess() {
+		logger.InfoCF("channels", "Channel recovered", map[string]any{"channel": name})
+	}
+}
+
+// breakerCountsFailure reports whether a failed send says something about
+// the channel as a whole. Permanent errors such as ErrSendFailed usually
+// concern one chat (an invalid or blocked chat ID) and must not degrade the
+// channel for everyone else.
+func breakerCountsFailure(err error) bool {
+	return !errors.Is(err, ErrSendFailed) && !errors.Is(err, ErrNotRunning)
+}
+
+func (m *Manager) breakerRecordFailure(name string, err error) {
+	if !breakerCountsFailure(err) {
+		return
+	}
+	b := m.breakerFor(name)
+	if b.recordFailure(time.Now(), err) {
+		h := b.snapshot()
+		logger.WarnCF("channels", "Channel marked degraded after consecutive failures", map[string]any{
+			"channel":       name,
+			"failures":      h.ConsecutiveFailures,
+			"next_probe_at": h.NextProbeAt.Format(time.RFC3339),
+		})
+	}
+}
+
+// ChannelHealth returns the send circuit breaker state of every registered channel.
+// A channel that reports a lost upstream connection is shown as disconnected,
+// which takes precedence over the breaker state.
+func (m *Manager) ChannelHealth() map[string]ChannelHealth {
+	m.mu.RLock()
+	chans := make(map[string]Channel, len(m.channels))
+	for name, ch := range m.channels {
+		chans[name] = ch
+	}
+	m.mu.RUnlock()
+
+	health := make(map[string]ChannelHealth, len(chans))
+	for name, ch := range chans {
+		health[name] = m.channelHealth(name, ch)
+	}
+	return health
+}
+
+func (m *Manager) channelHealth(name string, ch Channel) ChannelHealth {
+	h := ChannelHealth{State: ChannelHealthy}
+	if v, ok := m.breakers.Load(name); ok {
+		h = v.(*channelBreaker).snapshot()
+	}
+	if reporter, ok := ch.(ConnectionStatusReporter); ok && ch.IsRunning() {
+		if connected, detail := reporter.ConnectionStatus(); !connected {
+			h.State = ChannelDisconnected
+			if detail != "" {
+				h.LastError = detail
+			}
+		}
+	}
+	return h
+}
@@ -0,0 +1,185 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func TestChannelBreaker_OpensAfterThresholdAndProbes(t *testing.T) {
+	b := &channelBreaker{}
+	now := time.Now()
+	errBoom := errors.New("boom")
+
+	for i := 0; i < breakerFailureThreshold-1; i++ {
+		if b.recordFailure(now, errBoom) {
+			t.Fatalf("breaker opened after %d failures, want %d", i+1, breakerFailureThreshold)
+		}
+	}
+	if !b.recordFailure(now, errBoom) {
+		t.Fatal("breaker did not open at threshold")
+	}
+	if got := b.snapshot(); got.State != ChannelDegraded || got.LastError != "boom" {
+		t.Fatalf("snapshot = %+v, want degraded with last error", got)
+	}
+
+	if b.allow(now.Add(breakerBaseCooldown / 2)) {
+		t.Fatal("allow() = true during cooldown")
+	}
+	probeAt := now.Add(breakerBaseCooldown)
+	if !b.allow(probeAt) {
+		t.Fatal("allow() = false after cooldown, want recovery probe")
+	}
+	if b.allow(probeAt) {
+		t.Fatal("allow() = true while probe is in flight")
+	}
+
+	// A failed probe doubles the cooldown.
+	b.recordFailure(probeAt, errBoom)
+	if got := b.snapshot().NextProbeAt; !got.Equal(probeAt.Add(2 * breakerBaseCooldown)) {
+		t.Fatalf("NextProbeAt = %v, want %v", got, probeAt.Add(2*breakerBaseCooldown))
+	}
+
+	if !b.recordSuccess() {
+		t.Fatal("recordSuccess() = false, want recovered")
+	}
+	if got := b.snapshot(); got.State != ChannelHealthy || got.ConsecutiveFailures != 0 {
+		t.Fatalf("snapshot after recovery = %+v, want healthy", got)
+	}
+}
+
+func TestSendWithRetry_DegradedChannelDropsWithoutSending(t *testing.T) {
+	m := newTestManager()
+	var callCount int
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			callCount++
+			return nil
+		},
+	}
+	m.channels["test"] = ch
+	w := &channelWorker{
+		ch:      ch,
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+	ctx := context.Background()
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "hello"})
+
+	for i := 0; i < breakerFailureThreshold; i++ {
+		m.breakerRecordFailure("test", fmt.Errorf("connection reset: %w", ErrTemporary))
+	}
+	if got := m.ChannelHealth()["test"].State; got != ChannelDegraded {
+		t.Fatalf("health = %q, want %q", got, ChannelDegraded)
+	}
+
+	if _, ok := m.sendWithRetry(ctx, "test", w, msg); ok {
+		t.Fatal("sendWithRetry() succeeded on degraded channel")
+	}
+	if callCount != 0 {
+		t.Fatalf("Send calls = %d, want no call while degraded", callCount)
+	}
+
+	status := m.GetStatus()["test"].(map[string]any)
+	if status["health"] != string(ChannelDegraded) {
+		t.Fatalf("GetStatus health = %v, want %q", status["health"], ChannelDegraded)
+	}
+}
+
+func TestChannelHealth_DefaultsToHealthy(t *testing.T) {
+	m := newTestManager()
+	m.channels["telegram"] = &mockChannel{}
+
+	health := m.ChannelHealth()
+	if got := health["telegram"].State; got != ChannelHealthy {
+		t.Fatalf("health = %q, want %q", got, ChannelHealthy)
+	}
+}
+
+func TestSendWithRetry_PermanentErrorsDoNotDegradeChannel(t *testing.T) {
+	m := newTestManager()
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			return fmt.Errorf("chat not found: %w", ErrSendFailed)
+		},
+	}
+	m.channels["test"] = ch
+	w := &channelWorker{
+		ch:      ch,
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "blocked", Content: "hello"})
+
+	for i := 0; i < breakerFailureThreshold*2; i++ {
+		m.sendWithRetry(context.Background(), "test", w, msg)
+	}
+	if got := m.ChannelHealth()["test"]; got.State != ChannelHealthy || got.ConsecutiveFailures != 0 {
+		t.Fatalf("health = %+v, want healthy with no counted failures", got)
+	}
+}
+
+func TestSendWithRetry_CancelledProbeReleasesBreaker(t *testing.T) {
+	m := newTestManager()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			cancel()
+			return fmt.Errorf("timeout: %w", ErrTemporary)
+		},
+	}
+	m.channels["test"] = ch
+	w := &channelWorker{
+		ch:      ch,
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+
+	past := time.Now().Add(-2 * breakerBaseCooldown)
+	b := m.breakerFor("test")
+	for i := 0; i < breakerFailureThreshold; i++ {
+		b.recordFailure(past, errors.New("boom"))
+	}
+
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "hello"})
+	if _, ok := m.sendWithRetry(ctx, "test", w, msg); ok {
+		t.Fatal("sendWithRetry() succeeded, want cancelled probe")
+	}
+	if !b.allow(time.Now()) {
+		t.Fatal("allow() = false after cancelled probe, want next message to probe again")
+	}
+}
+
+type disconnectedChannel struct {
+	mockChannel
+	connected bool
+}
+
+func (c *disconnectedChannel) ConnectionStatus() (bool, string) {
+	return c.connected, "bridge unreachable: connection refused"
+}
+
+func TestChannelHealth_ReportsLostConnection(t *testing.T) {
+	m := newTestManager()
+	ch := &disconnectedChannel{}
+	ch.SetRunning(true)
+	m.channels["whatsapp"] = ch
+
+	got := m.ChannelHealth()["whatsapp"]
+	if got.State != ChannelDisconnected || !strings.Contains(got.LastError, "bridge unreachable") {
+		t.Fatalf("health = %+v, want disconnected with detail", got)
+	}
+	if status := m.GetStatus()["whatsapp"].(map[string]any); status["health"] != string(ChannelDisconnected) {
+		t.Fatalf("GetStatus health = %v, want %q", status["health"], ChannelDisconnected)
+	}
+
+	ch.connected = true
+	if got = m.ChannelHealth()["whatsapp"]; got.State != ChannelHealthy {
+		t.Fatalf("health after reconnect = %+v, want healthy", got)
+	}
+}
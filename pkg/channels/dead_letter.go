@@ -0,0 +1,149 @@
+package channels
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// deadLetterDir is where undelivered messages are kept, relative to the
+// workspace: one JSON Lines file per channel.
+const deadLetterDir = "channels/dead_letter"
+
+// deadLetterEntry is one line of a dead-letter file.
+type deadLetterEntry struct {
+	FailedAt time.Time           `json:"failed_at"`
+	Error    string              `json:"error"`
+	Message  bus.OutboundMessage `json:"message"`
+}
+
+var deadLetterMu sync.Mutex
+
+func (m *Manager) deadLetterPath(name string) string {
+	if m.config == nil {
+		return ""
+	}
+	return filepath.Join(m.config.WorkspacePath(), deadLetterDir, name+".jsonl")
+}
+
+// storeDeadLetter appends a message that could not be delivered to the
+// channel's dead-letter file, when the channel has retry.dead_letter set.
+func (m *Manager) storeDeadLetter(name string, msg bus.OutboundMessage, sendErr error) {
+	if !m.retryPolicy(name).deadLetter {
+		return
+	}
+	path := m.deadLetterPath(name)
+	if path == "" {
+		return
+	}
+	entry := deadLetterEntry{FailedAt: time.Now().UTC(), Message: msg}
+	if sendErr != nil {
+		entry.Error = sendErr.Error()
+	}
+	if err := appendDeadLetter(path, entry); err != nil {
+		logger.ErrorCF("channels", "Failed to store undelivered message", map[string]any{
+			"channel": name,
+			"error":   err.Error(),
+		})
+		return
+	}
+	logger.WarnCF("channels", "Stored undelivered message for retry", map[string]any{
+		"channel": name,
+		"chat_id": outboundMessageChatID(msg),
+		"path":    path,
+	})
+}
+
+func appendDeadLetter(path string, entry deadLetterEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	deadLetterMu.Lock()
+	defer deadLetterMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// takeDeadLetters reads and removes the channel's dead-letter file. Lines
+// that cannot be parsed are skipped.
+func takeDeadLetters(path string) ([]deadLetterEntry, error) {
+	deadLetterMu.Lock()
+	defer deadLetterMu.Unlock()
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []deadLetterEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry deadLetterEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	scanErr := scanner.Err()
+	f.Close()
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	return entries, os.Remove(path)
+}
+
+// replayDeadLetters queues the channel's undelivered messages again. It runs
+// once the channel's worker has started; messages that fail again go back to
+// the dead-letter file through the normal send path.
+func (m *Manager) replayDeadLetters(ctx context.Context, name string, w *channelWorker) {
+	path := m.deadLetterPath(name)
+	if path == "" {
+		return
+	}
+	entries, err := takeDeadLetters(path)
+	if err != nil {
+		logger.ErrorCF("channels", "Failed to read undelivered messages", map[string]any{
+			"channel": name,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if len(entries) == 0 {
+		return
+	}
+	logger.InfoCF("channels", "Resending undelivered messages", map[string]any{
+		"channel": name,
+		"count":   len(entries),
+	})
+	for i, entry := range entries {
+		select {
+		case w.queue <- entry.Message:
+		case <-ctx.Done():
+			// Shutting down: keep what was not queued for the next start.
+			for _, rest := range entries[i:] {
+				_ = appendDeadLetter(path, rest)
+			}
+			return
+		}
+	}
+}
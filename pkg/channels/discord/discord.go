@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -21,7 +22,6 @@ import (
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
-	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/utils"
@@ -55,10 +55,18 @@ type DiscordChannel struct {
 	voiceMu    sync.RWMutex
 	voiceSSRC  map[string]map[uint32]string // guildID -> ssrc -> userID
 
+	// Deferred /ask interactions awaiting the agent's reply, by interaction ID.
+	interactionMu       sync.Mutex
+	pendingInteractions map[string]pendingInteraction
+
 	// TTS interruption: cancel active playback when user speaks
 	ttsMu     sync.Mutex
 	cancelTTS context.CancelFunc
 	ttsPlayID uint64
+
+	// disconnectedAt is when the gateway connection dropped, in Unix
+	// nanoseconds, or zero while connected.
+	disconnectedAt atomic.Int64
 }
 
 func NewDiscordChannel(
@@ -79,7 +87,7 @@ func NewDiscordChannel(
 		return nil, fmt.Errorf("failed to create discord session: %w", err)
 	}
 
-	if err := applyDiscordProxy(session, cfg.Proxy); err != nil {
+	if err := applyDiscordProxy(session, channels.ValidateProxyURL(bc.Name(), cfg.Proxy)); err != nil {
 		return nil, err
 	}
 	base := channels.NewBaseChannel("discord", cfg, bus, bc.AllowFrom,
@@ -97,6 +105,8 @@ func NewDiscordChannel(
 		typingStop:  make(map[string]chan struct{}),
 		bus:         bus,
 		voiceSSRC:   make(map[string]map[uint32]string),
+
+		pendingInteractions: make(map[string]pendingInteraction),
 	}
 	ch.playTTSFn = ch.playTTS
 	ch.ttsVoiceFn = ch.voiceConnectionForTTS
@@ -117,6 +127,12 @@ func (c *DiscordChannel) Start(ctx context.Context) error {
 	c.botUserID = botUser.ID
 
 	c.session.AddHandler(c.handleMessage)
+	c.session.AddHandler(c.handleGatewayDisconnect)
+	c.session.AddHandler(c.handleGatewayResumed)
+	c.session.AddHandler(c.handleGatewayReady)
+	if c.config.SlashCommands {
+		c.session.AddHandler(c.handleInteraction)
+	}
 
 	go c.listenVoiceControl(c.ctx)
 
@@ -124,6 +140,10 @@ func (c *DiscordChannel) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to open discord session: %w", err)
 	}
 
+	if c.config.SlashCommands {
+		c.registerSlashCommands(botUser.ID)
+	}
+
 	c.SetRunning(true)
 
 	logger.InfoCF("discord", "Discord bot connected", map[string]any{
@@ -134,6 +154,45 @@ func (c *DiscordChannel) Start(ctx context.Context) error {
 	return nil
 }
 
+// discordgo reconnects dropped gateway connections itself with backoff and
+// sends RESUME when it still has a session, so missed events are replayed.
+// These handlers only surface those transitions in the logs.
+
+func (c *DiscordChannel) handleGatewayDisconnect(_ *discordgo.Session, _ *discordgo.Disconnect) {
+	if !c.IsRunning() {
+		return
+	}
+	if c.disconnectedAt.CompareAndSwap(0, time.Now().UnixNano()) {
+		logger.WarnC("discord", "Discord gateway connection lost, reconnecting")
+	}
+}
+
+func (c *DiscordChannel) handleGatewayResumed(_ *discordgo.Session, _ *discordgo.Resumed) {
+	if downtime, ok := c.takeDowntime(); ok {
+		logger.InfoCF("discord", "Discord gateway session resumed", map[string]any{
+			"downtime": downtime.Round(time.Second).String(),
+		})
+	}
+}
+
+func (c *DiscordChannel) handleGatewayReady(_ *discordgo.Session, _ *discordgo.Ready) {
+	// READY after a drop means the old session could not be resumed and the
+	// bot identified again; events sent while it was offline are not replayed.
+	if downtime, ok := c.takeDowntime(); ok {
+		logger.WarnCF("discord", "Discord gateway reconnected with a new session", map[string]any{
+			"downtime": downtime.Round(time.Second).String(),
+		})
+	}
+}
+
+func (c *DiscordChannel) takeDowntime() (time.Duration, bool) {
+	since := c.disconnectedAt.Swap(0)
+	if since == 0 {
+		return 0, false
+	}
+	return time.Since(time.Unix(0, since)), true
+}
+
 func (c *DiscordChannel) Stop(ctx context.Context) error {
 	logger.InfoC("discord", "Stopping Discord bot")
 	c.SetRunning(false)
@@ -195,6 +254,19 @@ func (c *DiscordChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]s
 	content := msg.Content
 	if isToolFeedback {
 		content = channels.InitialAnimatedToolFeedbackContent(msg.Content)
+	} else if interaction, ok := c.takePendingInteraction(msg); ok {
+		msgID, err := c.completeInteraction(ctx, interaction, content)
+		if err == nil {
+			if hasTrackedMsg {
+				c.dismissTrackedToolFeedbackMessage(ctx, channelID, trackedMsgID)
+			}
+			return []string{msgID}, nil
+		}
+		// The interaction token may have expired (15 minutes); post normally.
+		logger.WarnCF("discord", "Failed to complete interaction, sending as message", map[string]any{
+			"channel_id": channelID,
+			"error":      err.Error(),
+		})
 	}
 	msgID, err := c.sendChunk(ctx, channelID, content, msg.ReplyToMessageID)
 	if err != nil {
@@ -382,6 +454,11 @@ func (c *DiscordChannel) SendPlaceholder(ctx context.Context, chatID string) (st
 	if !c.bc.Placeholder.Enabled {
 		return "", nil
 	}
+	// A deferred /ask interaction already shows "thinking..." and must be
+	// completed by the reply itself, so don't post a separate placeholder.
+	if c.hasPendingInteraction(chatID) {
+		return "", nil
+	}
 
 	text := c.bc.Placeholder.GetRandomText()
 
@@ -531,18 +608,7 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 	}
 
 	// Check allowlist first to avoid downloading attachments for rejected users
-	sender := bus.SenderInfo{
-		Platform:    "discord",
-		PlatformID:  m.Author.ID,
-		CanonicalID: identity.BuildCanonicalID("discord", m.Author.ID),
-		Username:    m.Author.Username,
-	}
-	// Build display name
-	displayName := m.Author.Username
-	if m.Author.Discriminator != "" && m.Author.Discriminator != "0" {
-		displayName += "#" + m.Author.Discriminator
-	}
-	sender.DisplayName = displayName
+	sender := discordSender(m.Author)
 
 	if !c.IsAllowedSender(sender) {
 		logger.DebugCF("discord", "Message rejected by allowlist", map[string]any{
@@ -651,32 +717,8 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 		"preview":     utils.Truncate(content, 50),
 	})
 
-	peerKind := "channel"
-	if m.GuildID == "" {
-		peerKind = "direct"
-	}
-
-	metadata := map[string]string{
-		"user_id":      senderID,
-		"username":     m.Author.Username,
-		"display_name": sender.DisplayName,
-		"guild_id":     m.GuildID,
-		"channel_id":   m.ChannelID,
-		"is_dm":        fmt.Sprintf("%t", m.GuildID == ""),
-	}
-	inboundCtx := bus.InboundContext{
-		Channel:   c.Name(),
-		ChatID:    m.ChannelID,
-		ChatType:  peerKind,
-		SenderID:  senderID,
-		MessageID: m.ID,
-		Mentioned: isMentioned,
-		Raw:       metadata,
-	}
-	if m.GuildID != "" {
-		inboundCtx.SpaceID = m.GuildID
-		inboundCtx.SpaceType = "guild"
-	}
+	inboundCtx := c.buildInboundContext(s, m.GuildID, m.ChannelID, m.Author, m.ID)
+	inboundCtx.Mentioned = isMentioned
 	if m.MessageReference != nil {
 		inboundCtx.ReplyToMessageID = m.MessageReference.MessageID
 	}
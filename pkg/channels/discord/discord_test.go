@@ -334,3 +334,40 @@ func TestSend_NonToolFeedbackFinalizerStillStartsTTS(t *testing.T) {
 		t.Fatal("expected TTS to start for finalized tracked tool feedback reply")
 	}
 }
+
+func TestGatewayTransitions_TrackDowntime(t *testing.T) {
+	ch := &DiscordChannel{
+		BaseChannel: channels.NewBaseChannel("discord", nil, nil, nil),
+	}
+
+	// Disconnects while stopped are part of shutting down, not outages.
+	ch.handleGatewayDisconnect(nil, &discordgo.Disconnect{})
+	if ch.disconnectedAt.Load() != 0 {
+		t.Fatal("disconnect while stopped should not be tracked")
+	}
+
+	ch.SetRunning(true)
+	ch.handleGatewayDisconnect(nil, &discordgo.Disconnect{})
+	first := ch.disconnectedAt.Load()
+	if first == 0 {
+		t.Fatal("disconnect while running should be tracked")
+	}
+	ch.handleGatewayDisconnect(nil, &discordgo.Disconnect{})
+	if got := ch.disconnectedAt.Load(); got != first {
+		t.Fatal("repeated disconnects should keep the original outage start")
+	}
+
+	ch.handleGatewayResumed(nil, &discordgo.Resumed{})
+	if ch.disconnectedAt.Load() != 0 {
+		t.Fatal("resume should clear the outage")
+	}
+
+	ch.handleGatewayDisconnect(nil, &discordgo.Disconnect{})
+	ch.handleGatewayReady(nil, &discordgo.Ready{})
+	if ch.disconnectedAt.Load() != 0 {
+		t.Fatal("a fresh session should clear the outage")
+	}
+	if _, ok := ch.takeDowntime(); ok {
+		t.Fatal("no outage should be pending")
+	}
+}
@@ -0,0 +1,297 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	askCommandName   = "ask"
+	askPromptOption  = "prompt"
+	askDeniedMessage = "You are not allowed to use this bot."
+	// interactionTokenTTL is how long Discord accepts edits to a deferred
+	// interaction's response.
+	interactionTokenTTL = 15 * time.Minute
+)
+
+// pendingInteraction is a deferred /ask waiting for the agent's reply.
+type pendingInteraction struct {
+	interaction *discordgo.Interaction
+	channelID   string
+	deferredAt  time.Time
+}
+
+// askCommand is the /ask application command. It lets users reach the agent
+// in busy channels without mentioning the bot.
+func askCommand() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        askCommandName,
+		Description: "Ask the assistant a question",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        askPromptOption,
+				Description: "What you want to ask",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// registerSlashCommands creates (or updates) the global /ask command.
+// Failure is logged rather than returned so the bot still runs for plain
+// messages when the application lacks the applications.commands scope.
+func (c *DiscordChannel) registerSlashCommands(appID string) {
+	if _, err := c.session.ApplicationCommandCreate(appID, "", askCommand()); err != nil {
+		logger.WarnCF("discord", "Failed to register slash commands", map[string]any{
+			"error": err.Error(),
+		})
+		return
+	}
+	logger.InfoCF("discord", "Registered slash commands", map[string]any{
+		"commands": "/" + askCommandName,
+	})
+}
+
+// handleInteraction answers /ask. Interactions arrive over the authenticated
+// gateway connection, so unlike HTTP interaction endpoints there is no request
+// signature to verify. Discord requires an acknowledgement within 3 seconds,
+// so the interaction is deferred first and the agent's reply later replaces
+// the "thinking" placeholder (see takePendingInteraction in Send). The
+// interaction ID is the inbound message ID, so the reply to this /ask, and
+// not any other message in the channel, completes it.
+func (c *DiscordChannel) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i == nil || i.Interaction == nil || i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+	data := i.ApplicationCommandData()
+	if data.Name != askCommandName {
+		return
+	}
+
+	user := i.User
+	if i.Member != nil && i.Member.User != nil {
+		user = i.Member.User
+	}
+	if user == nil {
+		return
+	}
+	sender := discordSender(user)
+	if !c.IsAllowedSender(sender) {
+		logger.DebugCF("discord", "Interaction rejected by allowlist", map[string]any{
+			"user_id": user.ID,
+		})
+		c.respondEphemeral(s, i.Interaction, askDeniedMessage)
+		return
+	}
+
+	prompt := ""
+	for _, opt := range data.Options {
+		if opt.Name == askPromptOption && opt.Type == discordgo.ApplicationCommandOptionString {
+			prompt = strings.TrimSpace(opt.StringValue())
+		}
+	}
+	if prompt == "" {
+		c.respondEphemeral(s, i.Interaction, "Usage: /ask <prompt>")
+		return
+	}
+
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		logger.WarnCF("discord", "Failed to acknowledge interaction", map[string]any{
+			"interaction_id": i.ID,
+			"error":          err.Error(),
+		})
+		return
+	}
+	c.setPendingInteraction(i.ChannelID, i.Interaction)
+
+	logger.DebugCF("discord", "Received slash command", map[string]any{
+		"sender_id": user.ID,
+		"preview":   utils.Truncate(prompt, 50),
+	})
+
+	inboundCtx := c.buildInboundContext(s, i.GuildID, i.ChannelID, user, i.ID)
+	// Invoking the command is an explicit request to the bot.
+	inboundCtx.Mentioned = true
+	inboundCtx.Raw["slash_command"] = askCommandName
+
+	c.HandleInboundContext(c.ctx, i.ChannelID, prompt, nil, inboundCtx, sender)
+}
+
+func (c *DiscordChannel) respondEphemeral(s *discordgo.Session, interaction *discordgo.Interaction, content string) {
+	err := s.InteractionRespond(interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		logger.WarnCF("discord", "Failed to respond to interaction", map[string]any{
+			"interaction_id": interaction.ID,
+			"error":          err.Error(),
+		})
+	}
+}
+
+func (c *DiscordChannel) setPendingInteraction(channelID string, interaction *discordgo.Interaction) {
+	c.interactionMu.Lock()
+	defer c.interactionMu.Unlock()
+	now := time.Now()
+	for id, pending := range c.pendingInteractions {
+		if now.Sub(pending.deferredAt) > interactionTokenTTL {
+			delete(c.pendingInteractions, id)
+		}
+	}
+	c.pendingInteractions[interaction.ID] = pendingInteraction{
+		interaction: interaction,
+		channelID:   channelID,
+		deferredAt:  now,
+	}
+}
+
+// hasPendingInteraction reports whether a deferred /ask in channelID still
+// waits for its reply.
+func (c *DiscordChannel) hasPendingInteraction(channelID string) bool {
+	c.interactionMu.Lock()
+	defer c.interactionMu.Unlock()
+	for _, pending := range c.pendingInteractions {
+		if pending.channelID == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// takePendingInteraction returns the deferred /ask that msg answers: the one
+// whose ID is the message ID of the turn's inbound context, or the message
+// msg replies to.
+func (c *DiscordChannel) takePendingInteraction(msg bus.OutboundMessage) (*discordgo.Interaction, bool) {
+	c.interactionMu.Lock()
+	defer c.interactionMu.Unlock()
+	for _, id := range []string{msg.Context.MessageID, msg.ReplyToMessageID, msg.Context.ReplyToMessageID} {
+		if id == "" {
+			continue
+		}
+		if pending, ok := c.pendingInteractions[id]; ok && pending.channelID == msg.ChatID {
+			delete(c.pendingInteractions, id)
+			return pending.interaction, true
+		}
+	}
+	return nil, false
+}
+
+// completeInteraction replaces a deferred interaction's placeholder with
+// content and returns the resulting message ID.
+func (c *DiscordChannel) completeInteraction(
+	ctx context.Context,
+	interaction *discordgo.Interaction,
+	content string,
+) (string, error) {
+	msg, err := c.session.InteractionResponseEdit(interaction, &discordgo.WebhookEdit{
+		Content: &content,
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("discord interaction edit: %w", err)
+	}
+	return msg.ID, nil
+}
+
+// discordSender builds the sender identity for a Discord user.
+func discordSender(user *discordgo.User) bus.SenderInfo {
+	displayName := user.Username
+	if user.Discriminator != "" && user.Discriminator != "0" {
+		displayName += "#" + user.Discriminator
+	}
+	return bus.SenderInfo{
+		Platform:    "discord",
+		PlatformID:  user.ID,
+		CanonicalID: identity.BuildCanonicalID("discord", user.ID),
+		Username:    user.Username,
+		DisplayName: displayName,
+	}
+}
+
+// buildInboundContext describes where a message or interaction came from.
+// Threads are Discord channels of their own, so ChatID stays the thread ID
+// (replies land in the thread and it keeps its own session) while TopicID
+// marks it as a thread and raw metadata records the parent channel.
+func (c *DiscordChannel) buildInboundContext(
+	s *discordgo.Session,
+	guildID, channelID string,
+	user *discordgo.User,
+	messageID string,
+) bus.InboundContext {
+	peerKind := "channel"
+	if guildID == "" {
+		peerKind = "direct"
+	}
+	displayName := discordSender(user).DisplayName
+
+	inboundCtx := bus.InboundContext{
+		Channel:   c.Name(),
+		ChatID:    channelID,
+		ChatType:  peerKind,
+		SenderID:  user.ID,
+		MessageID: messageID,
+		Raw: map[string]string{
+			"user_id":      user.ID,
+			"username":     user.Username,
+			"display_name": displayName,
+			"guild_id":     guildID,
+			"channel_id":   channelID,
+			"is_dm":        fmt.Sprintf("%t", guildID == ""),
+		},
+	}
+	if guildID != "" {
+		inboundCtx.SpaceID = guildID
+		inboundCtx.SpaceType = "guild"
+		if parentID, ok := c.threadParent(s, channelID); ok {
+			inboundCtx.TopicID = channelID
+			inboundCtx.Raw["thread_id"] = channelID
+			inboundCtx.Raw["parent_channel_id"] = parentID
+		}
+	}
+	return inboundCtx
+}
+
+// threadParent reports whether channelID is a thread and returns its parent
+// channel. The state cache is consulted first; a REST lookup fills it.
+func (c *DiscordChannel) threadParent(s *discordgo.Session, channelID string) (string, bool) {
+	if s == nil || channelID == "" {
+		return "", false
+	}
+	var ch *discordgo.Channel
+	if s.State != nil {
+		ch, _ = s.State.Channel(channelID)
+	}
+	if ch == nil {
+		fetched, err := s.Channel(channelID)
+		if err != nil {
+			logger.DebugCF("discord", "Failed to look up channel", map[string]any{
+				"channel_id": channelID,
+				"error":      err.Error(),
+			})
+			return "", false
+		}
+		ch = fetched
+		if s.State != nil {
+			_ = s.State.ChannelAdd(ch)
+		}
+	}
+	if !ch.IsThread() || ch.ParentID == "" {
+		return "", false
+	}
+	return ch.ParentID, true
+}
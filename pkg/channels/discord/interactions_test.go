@@ -0,0 +1,165 @@
+package discord
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+)
+
+func TestBuildInboundContext_ThreadKeepsOwnChatAndRecordsParent(t *testing.T) {
+	session, err := discordgo.New("Bot test-token")
+	if err != nil {
+		t.Fatalf("discordgo.New() error: %v", err)
+	}
+	if err := session.State.GuildAdd(&discordgo.Guild{ID: "guild-1"}); err != nil {
+		t.Fatalf("GuildAdd() error: %v", err)
+	}
+	for _, ch := range []*discordgo.Channel{
+		{ID: "chan-1", GuildID: "guild-1", Type: discordgo.ChannelTypeGuildText},
+		{ID: "thread-1", GuildID: "guild-1", ParentID: "chan-1", Type: discordgo.ChannelTypeGuildPublicThread},
+	} {
+		if err := session.State.ChannelAdd(ch); err != nil {
+			t.Fatalf("ChannelAdd(%s) error: %v", ch.ID, err)
+		}
+	}
+
+	ch := &DiscordChannel{BaseChannel: channels.NewBaseChannel("discord", nil, bus.NewMessageBus(), nil)}
+	user := &discordgo.User{ID: "user-1", Username: "alice"}
+
+	threadCtx := ch.buildInboundContext(session, "guild-1", "thread-1", user, "msg-1")
+	if threadCtx.ChatID != "thread-1" || threadCtx.TopicID != "thread-1" {
+		t.Fatalf("thread ChatID/TopicID = %q/%q, want thread-1/thread-1", threadCtx.ChatID, threadCtx.TopicID)
+	}
+	if got := threadCtx.Raw["parent_channel_id"]; got != "chan-1" {
+		t.Fatalf("parent_channel_id = %q, want chan-1", got)
+	}
+	if threadCtx.SpaceID != "guild-1" || threadCtx.SpaceType != "guild" {
+		t.Fatalf("space = %q/%q, want guild-1/guild", threadCtx.SpaceID, threadCtx.SpaceType)
+	}
+
+	channelCtx := ch.buildInboundContext(session, "guild-1", "chan-1", user, "msg-2")
+	if channelCtx.TopicID != "" || channelCtx.Raw["parent_channel_id"] != "" {
+		t.Fatalf("plain channel should not be marked as thread: %+v", channelCtx)
+	}
+
+	dmCtx := ch.buildInboundContext(session, "", "dm-1", user, "msg-3")
+	if dmCtx.ChatType != "direct" || dmCtx.SpaceID != "" {
+		t.Fatalf("DM context = %+v, want direct without space", dmCtx)
+	}
+}
+
+func TestSend_CompletesTheInteractionItAnswers(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPatch && r.URL.Path == "/webhooks/app-1/token-1/messages/@original":
+			_, _ = io.WriteString(w, `{"id":"reply-1"}`)
+		case r.Method == http.MethodPatch && r.URL.Path == "/webhooks/app-1/token-2/messages/@original":
+			_, _ = io.WriteString(w, `{"id":"reply-2"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/channels/chat-1/messages":
+			_, _ = io.WriteString(w, `{"id":"msg-2"}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	origChannels, origWebhooks := discordgo.EndpointChannels, discordgo.EndpointWebhooks
+	discordgo.EndpointChannels = server.URL + "/channels/"
+	discordgo.EndpointWebhooks = server.URL + "/webhooks/"
+	defer func() {
+		discordgo.EndpointChannels, discordgo.EndpointWebhooks = origChannels, origWebhooks
+	}()
+
+	session, err := discordgo.New("Bot test-token")
+	if err != nil {
+		t.Fatalf("discordgo.New() error: %v", err)
+	}
+	session.Client = server.Client()
+
+	ch := &DiscordChannel{
+		BaseChannel:         channels.NewBaseChannel("discord", nil, bus.NewMessageBus(), nil),
+		session:             session,
+		ctx:                 context.Background(),
+		typingStop:          make(map[string]chan struct{}),
+		voiceSSRC:           make(map[string]map[uint32]string),
+		pendingInteractions: make(map[string]pendingInteraction),
+	}
+	ch.progress = channels.NewToolFeedbackAnimator(ch.EditMessage)
+	ch.SetRunning(true)
+	// Two /ask commands are in flight in the same channel.
+	ch.setPendingInteraction("chat-1", &discordgo.Interaction{ID: "int-1", AppID: "app-1", Token: "token-1"})
+	ch.setPendingInteraction("chat-1", &discordgo.Interaction{ID: "int-2", AppID: "app-1", Token: "token-2"})
+	if !ch.hasPendingInteraction("chat-1") {
+		t.Fatal("hasPendingInteraction(chat-1) = false")
+	}
+
+	send := func(inboundID, content string) []string {
+		t.Helper()
+		ids, err := ch.Send(context.Background(), bus.OutboundMessage{
+			ChatID:  "chat-1",
+			Content: content,
+			Context: bus.InboundContext{Channel: "discord", ChatID: "chat-1", MessageID: inboundID},
+		})
+		if err != nil {
+			t.Fatalf("Send(%q) error = %v", content, err)
+		}
+		return ids
+	}
+
+	if got, want := send("plain-msg", "reply to a plain message"), []string{"msg-2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("plain reply ids = %v, want %v", got, want)
+	}
+	if got, want := send("int-2", "second answer"), []string{"reply-2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("second interaction ids = %v, want %v", got, want)
+	}
+	if got, want := send("int-1", "first answer"), []string{"reply-1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("first interaction ids = %v, want %v", got, want)
+	}
+	if got, want := send("int-1", "more of the first answer"), []string{"msg-2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("follow-up chunk ids = %v, want %v", got, want)
+	}
+	if ch.hasPendingInteraction("chat-1") {
+		t.Fatal("completed interactions are still pending")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	wantRequests := []string{
+		"POST /channels/chat-1/messages",
+		"PATCH /webhooks/app-1/token-2/messages/@original",
+		"PATCH /webhooks/app-1/token-1/messages/@original",
+		"POST /channels/chat-1/messages",
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Fatalf("requests = %v, want %v", requests, wantRequests)
+	}
+}
+
+func TestAskCommand_RequiresPrompt(t *testing.T) {
+	cmd := askCommand()
+	if cmd.Name != "ask" || len(cmd.Options) != 1 {
+		t.Fatalf("askCommand() = %+v", cmd)
+	}
+	opt := cmd.Options[0]
+	if opt.Name != askPromptOption || !opt.Required || opt.Type != discordgo.ApplicationCommandOptionString {
+		t.Fatalf("prompt option = %+v", opt)
+	}
+}
@@ -18,4 +18,8 @@ var (
 	// ErrSendFailed indicates a permanent failure (e.g. invalid chat ID, 4xx non-429).
 	// Manager will not retry.
 	ErrSendFailed = errors.New("send failed")
+
+	// ErrChannelDegraded indicates the channel's circuit breaker is open after
+	// repeated send failures. Manager drops the message without calling the channel.
+	ErrChannelDegraded = errors.New("channel degraded")
 )
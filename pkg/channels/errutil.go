@@ -1,8 +1,12 @@
 package channels
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // ClassifySendError wraps a raw error with the appropriate sentinel based on
@@ -28,3 +32,59 @@ func ClassifyNetError(err error) error {
 	}
 	return fmt.Errorf("%w: %w", ErrTemporary, err)
 }
+
+// retryAfterError carries the delay a platform asked for before the next
+// attempt, e.g. from a Retry-After header or a retry_after response field.
+type retryAfterError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryAfterError) Error() string { return e.err.Error() }
+
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+// WithRetryAfter attaches a platform-requested retry delay to err. Manager
+// waits at least that long before retrying instead of its own backoff.
+// A nil err or a non-positive delay returns err unchanged.
+func WithRetryAfter(err error, after time.Duration) error {
+	if err == nil || after <= 0 {
+		return err
+	}
+	return &retryAfterError{err: err, after: after}
+}
+
+// RetryAfter returns the retry delay attached to err with WithRetryAfter.
+func RetryAfter(err error) (time.Duration, bool) {
+	var ra *retryAfterError
+	if errors.As(err, &ra) {
+		return ra.after, true
+	}
+	return 0, false
+}
+
+// ClassifySendResponse is ClassifySendError for an HTTP response: it also
+// honors the response's Retry-After header.
+func ClassifySendResponse(resp *http.Response, rawErr error) error {
+	if resp == nil {
+		return ClassifySendError(0, rawErr)
+	}
+	err := ClassifySendError(resp.StatusCode, rawErr)
+	return WithRetryAfter(err, ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()))
+}
+
+// ParseRetryAfter parses a Retry-After header value, given either as seconds
+// or as an HTTP date. It returns 0 when the value is empty or invalid.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
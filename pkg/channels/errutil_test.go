@@ -3,7 +3,9 @@ package channels
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func TestClassifySendError(t *testing.T) {
@@ -95,3 +97,38 @@ func TestClassifyNetError(t *testing.T) {
 		}
 	})
 }
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"7", 7 * time.Second},
+		{" 2 ", 2 * time.Second},
+		{"-3", 0},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestClassifySendResponseRetryAfter(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "4")
+
+	err := ClassifySendResponse(resp, fmt.Errorf("slow down"))
+	if !errors.Is(err, ErrRateLimit) {
+		t.Fatalf("expected ErrRateLimit, got %v", err)
+	}
+	after, ok := RetryAfter(err)
+	if !ok || after != 4*time.Second {
+		t.Fatalf("RetryAfter = %v, %v; want 4s, true", after, ok)
+	}
+}
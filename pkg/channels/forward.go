@@ -0,0 +1,42 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+// ErrForwardNotAllowed is returned when the target chat is not on the target
+// channel's allow list.
+var ErrForwardNotAllowed = errors.New("target chat is not allowed on that channel")
+
+// ForwardMessage delivers content to chatID on any enabled channel, not just
+// the one the current conversation runs on. The target chat must pass the
+// target channel's allow list, the same check its inbound messages get, so a
+// forward cannot reach a chat that could not talk to the agent itself.
+func (m *Manager) ForwardMessage(ctx context.Context, channelName, chatID, content string) error {
+	channelName = strings.TrimSpace(channelName)
+	chatID = strings.TrimSpace(chatID)
+	if channelName == "" || chatID == "" {
+		return fmt.Errorf("forward needs a channel and a chat ID")
+	}
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("nothing to forward")
+	}
+
+	ch, ok := m.GetChannel(channelName)
+	if !ok {
+		return fmt.Errorf("channel %s not found", channelName)
+	}
+	if !ch.IsAllowed(chatID) {
+		return fmt.Errorf("%w: %s:%s", ErrForwardNotAllowed, channelName, chatID)
+	}
+
+	return m.SendMessage(ctx, bus.OutboundMessage{
+		Context: bus.NewOutboundContext(channelName, chatID, ""),
+		Content: content,
+	})
+}
@@ -0,0 +1,57 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func newForwardManager(allowList []string) (*Manager, *mockChannel) {
+	m := newTestManager()
+	ch := &mockChannel{BaseChannel: *NewBaseChannel("slack", nil, nil, allowList)}
+	m.channels["slack"] = ch
+	m.workers["slack"] = &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+	return m, ch
+}
+
+func TestForwardMessage_DeliversToAllowedChat(t *testing.T) {
+	m, ch := newForwardManager([]string{"C-ops"})
+
+	if err := m.ForwardMessage(context.Background(), "slack", "C-ops", "summary"); err != nil {
+		t.Fatalf("ForwardMessage() error = %v", err)
+	}
+	if len(ch.sentMessages) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(ch.sentMessages))
+	}
+	sent := bus.NormalizeOutboundMessage(ch.sentMessages[0])
+	if sent.ChatID != "C-ops" || sent.Content != "summary" {
+		t.Fatalf("sent %+v, want summary to C-ops", sent)
+	}
+}
+
+func TestForwardMessage_RejectsChatOutsideAllowList(t *testing.T) {
+	m, ch := newForwardManager([]string{"C-ops"})
+
+	err := m.ForwardMessage(context.Background(), "slack", "C-random", "summary")
+	if !errors.Is(err, ErrForwardNotAllowed) {
+		t.Fatalf("ForwardMessage() error = %v, want ErrForwardNotAllowed", err)
+	}
+	if len(ch.sentMessages) != 0 {
+		t.Fatal("a rejected forward must not be sent")
+	}
+}
+
+func TestForwardMessage_UnknownChannelAndEmptyContent(t *testing.T) {
+	m, _ := newForwardManager(nil)
+
+	if err := m.ForwardMessage(context.Background(), "irc", "1", "summary"); err == nil {
+		t.Fatal("expected error for unknown channel")
+	}
+	if err := m.ForwardMessage(context.Background(), "slack", "1", "  "); err == nil {
+		t.Fatal("expected error for empty content")
+	}
+}
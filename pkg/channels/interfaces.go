@@ -79,3 +79,11 @@ type PlaceholderRecorder interface {
 type CommandRegistrarCapable interface {
 	RegisterCommands(ctx context.Context, defs []commands.Definition) error
 }
+
+// ConnectionStatusReporter is implemented by channels that keep a long-lived
+// connection to an external service (e.g. a bridge process) and reconnect on
+// their own. While it reports disconnected, ChannelHealth shows the channel
+// as ChannelDisconnected with detail as the last error.
+type ConnectionStatusReporter interface {
+	ConnectionStatus() (connected bool, detail string)
+}
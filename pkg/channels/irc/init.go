@@ -22,6 +22,11 @@ func init() {
 			if !ok {
 				return nil, channels.ErrSendFailed
 			}
+			if c.Nick == "" {
+				// Fall back to the configured agent name so the bot is
+				// branded the same here as in prompts.
+				c.Nick = nickFromName(cfg.Agents.Defaults.Name)
+			}
 			ch, err := NewIRCChannel(bc, c, b)
 			if err != nil {
 				return nil, err
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/ergochat/irc-go/ircevent"
 	"github.com/ergochat/irc-go/ircmsg"
@@ -146,19 +147,26 @@ func (c *IRCChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]strin
 		return nil, nil
 	}
 
-	// Send each line separately (IRC is line-oriented)
-	lines := strings.Split(msg.Content, "\n")
-	for _, line := range lines {
+	// Send each line separately (IRC is line-oriented), splitting lines that
+	// would exceed the protocol's 512-byte limit.
+	budget := lineBudget(target, c.conn.CurrentNick(), c.config.User)
+	sent := 0
+	for _, line := range strings.Split(msg.Content, "\n") {
 		line = strings.TrimRight(line, "\r")
 		if line == "" {
 			continue
 		}
-		c.conn.Privmsg(target, line)
+		for _, part := range splitLine(line, budget) {
+			if err := c.conn.Privmsg(target, part); err != nil {
+				return nil, fmt.Errorf("irc privmsg: %v: %w", err, channels.ErrTemporary)
+			}
+			sent++
+		}
 	}
 
 	logger.DebugCF("irc", "Message sent", map[string]any{
 		"target": target,
-		"lines":  len(lines),
+		"lines":  sent,
 	})
 	return nil, nil
 }
@@ -186,6 +194,50 @@ func (c *IRCChannel) StartTyping(ctx context.Context, chatID string) (func(), er
 	}, nil
 }
 
+const (
+	// maxLineBytes is the IRC line limit, including the trailing CRLF.
+	maxLineBytes = 512
+	// maxHostBytes is reserved for the host in the ":nick!user@host" prefix
+	// the server adds when relaying our message.
+	maxHostBytes = 63
+)
+
+// lineBudget returns how many bytes of text fit in one PRIVMSG to target once
+// the server has prefixed it with our nick!user@host.
+func lineBudget(target, nick, user string) int {
+	if user == "" {
+		user = nick
+	}
+	prefix := len(":"+nick+"!"+user+"@ ") + maxHostBytes
+	command := len("PRIVMSG " + target + " :\r\n")
+	return max(maxLineBytes-prefix-command, 1)
+}
+
+// splitLine splits line into parts of at most maxBytes bytes, preferring to
+// break at spaces and never splitting a UTF-8 character.
+func splitLine(line string, maxBytes int) []string {
+	var parts []string
+	for len(line) > maxBytes {
+		cut := maxBytes
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if space := strings.LastIndexByte(line[:cut], ' '); space > 0 {
+			cut = space
+		}
+		if cut == 0 {
+			// A single character wider than the budget; send it whole.
+			_, cut = utf8.DecodeRuneInString(line)
+		}
+		parts = append(parts, line[:cut])
+		line = strings.TrimLeft(line[cut:], " ")
+	}
+	if line != "" {
+		parts = append(parts, line)
+	}
+	return parts
+}
+
 // extractHost returns the hostname portion of a host:port string.
 func extractHost(server string) string {
 	host, _, found := strings.Cut(server, ":")
@@ -194,3 +246,22 @@ func extractHost(server string) string {
 	}
 	return server
 }
+
+// nickFromName turns an agent name into an IRC nick: characters IRC does not
+// allow in nicks are dropped and a leading digit or dash is skipped.
+func nickFromName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', strings.ContainsRune("[]\\`_^{|}", r):
+		case (r >= '0' && r <= '9') || r == '-':
+			if b.Len() == 0 {
+				continue
+			}
+		default:
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -1,7 +1,9 @@
 package irc
 
 import (
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -48,6 +50,24 @@ func TestNewIRCChannel(t *testing.T) {
 	})
 }
 
+func TestNickFromName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Jarvis", "Jarvis"},
+		{"Mr. Robot", "MrRobot"},
+		{"7-of-9", "of-9"},
+		{"bot_[x]", "bot_[x]"},
+		{"小龙虾", ""},
+	}
+	for _, tt := range tests {
+		if got := nickFromName(tt.name); got != tt.want {
+			t.Errorf("nickFromName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestExtractHost(t *testing.T) {
 	tests := []struct {
 		server string
@@ -146,3 +166,34 @@ func TestStripBotMention(t *testing.T) {
 		})
 	}
 }
+
+func TestLineBudget(t *testing.T) {
+	budget := lineBudget("#test", "bot", "")
+	prefix := ":bot!bot@" + strings.Repeat("h", maxHostBytes) + " "
+	if got := len(prefix + "PRIVMSG #test :" + strings.Repeat("x", budget) + "\r\n"); got != maxLineBytes {
+		t.Errorf("full relayed line = %d bytes, want %d", got, maxLineBytes)
+	}
+}
+
+func TestSplitLine(t *testing.T) {
+	if got := splitLine("short line", 100); len(got) != 1 || got[0] != "short line" {
+		t.Errorf("splitLine(short) = %q", got)
+	}
+
+	got := splitLine("alpha beta gamma delta", 11)
+	want := []string{"alpha beta", "gamma delta"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("splitLine(words) = %q, want %q", got, want)
+	}
+
+	long := strings.Repeat("你好", 300)
+	parts := splitLine(long, 100)
+	if strings.Join(parts, "") != long {
+		t.Error("splitLine lost content")
+	}
+	for _, part := range parts {
+		if len(part) > 100 || !utf8.ValidString(part) {
+			t.Errorf("part %q is %d bytes or invalid UTF-8", part, len(part))
+		}
+	}
+}
@@ -63,7 +63,9 @@ func NewLINEChannel(
 
 	client, err := messaging_api.NewMessagingApiAPI(
 		cfg.ChannelAccessToken.String(),
-		messaging_api.WithHTTPClient(&http.Client{Timeout: 30 * time.Second}),
+		messaging_api.WithHTTPClient(
+			channels.NewProxyHTTPClient(channels.ValidateProxyURL(bc.Name(), cfg.Proxy), 30*time.Second),
+		),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create LINE messaging client: %w", err)
@@ -594,7 +596,7 @@ func classifySDKError(resp *http.Response, err error) error {
 		return nil
 	}
 	if resp != nil {
-		return channels.ClassifySendError(resp.StatusCode, err)
+		return channels.ClassifySendResponse(resp, err)
 	}
 	return channels.ClassifyNetError(err)
 }
@@ -100,7 +100,10 @@ type Manager struct {
 	reactionUndos             sync.Map          // "channel:chatID" → reactionEntry
 	streamActive              sync.Map          // streamSuppressionKey → true (set when streamer.Finalize sent the message)
 	streamAuxiliaryTombstones sync.Map          // streamSuppressionKey → time.Time (drops late auxiliary messages after stream final)
+	breakers                  sync.Map          // channel name → *channelBreaker
+	sentMessages              sync.Map          // "channel:chatID" → *sentMessageLog
 	channelHashes             map[string]string // channel name → config hash
+	quiet                     quietHold         // proactive messages held during quiet hours
 }
 
 type mediaStoreSetter interface {
@@ -269,6 +272,14 @@ func clearTrackedToolFeedbackMessage(
 	}
 }
 
+// SupportsToolFeedbackUpdates reports whether ch edits its tracked tool
+// feedback message in place when another tool feedback message arrives for
+// the same chat, instead of posting a new one.
+func SupportsToolFeedbackUpdates(ch Channel) bool {
+	_, ok := ch.(toolFeedbackMessageTracker)
+	return ok
+}
+
 // DismissToolFeedback clears any tracked tool feedback animation for the
 // given channel/chat. This is called when a turn ends without a final
 // response (e.g., ResponseHandled tools) to stop orphaned animation goroutines.
@@ -977,6 +988,12 @@ func (m *Manager) initChannel(typeName, channelName string) {
 		if setter, ok := ch.(interface{ SetOwner(ch Channel) }); ok {
 			setter.SetOwner(ch)
 		}
+		// Inject the output format the agent's markdown is converted to
+		if bc := m.config.Channels[channelName]; bc != nil && bc.OutputFormat != "" {
+			if setter, ok := ch.(interface{ SetOutputFormat(format string) }); ok {
+				setter.SetOutputFormat(bc.OutputFormat)
+			}
+		}
 		m.channels[channelName] = ch
 		m.publishChannelEvent(
 			runtimeevents.KindChannelLifecycleInitialized,
@@ -1059,6 +1076,8 @@ func (m *Manager) getChannelConfigAndEnabled(channelName string) (*config.Channe
 		return bc, settings.GroupID != 0 && settings.Token.String() != ""
 	case *config.MQTTSettings:
 		return bc, settings.Broker != "" && settings.AgentID != ""
+	case *config.SMSSettings:
+		return bc, settings.AccountSID != "" && settings.AuthToken.String() != "" && settings.FromNumber != ""
 	}
 
 	return bc, bc.Enabled
@@ -1120,6 +1139,16 @@ func (m *Manager) SetupHTTPServerListeners(listeners []net.Listener, addr string
 	m.httpListeners = append([]net.Listener(nil), listeners...)
 }
 
+// HandleHTTP registers an additional handler on the shared HTTP server, such
+// as a gateway API. A pattern ending in "/" matches the whole subtree. It is
+// a no-op before SetupHTTPServer.
+func (m *Manager) HandleHTTP(pattern string, handler http.Handler) {
+	if m.mux == nil {
+		return
+	}
+	m.mux.Handle(pattern, handler)
+}
+
 // registerHTTPHandlersLocked registers webhook and health-check handlers for
 // all channels currently in m.channels. Caller must hold m.mu (or ensure
 // exclusive access).
@@ -1227,6 +1256,7 @@ func (m *Manager) StartAll(ctx context.Context) error {
 		m.workers[name] = w
 		go m.runWorker(dispatchCtx, name, w)
 		go m.runMediaWorker(dispatchCtx, name, w)
+		go m.replayDeadLetters(dispatchCtx, name, w)
 		m.publishChannelEvent(
 			runtimeevents.KindChannelLifecycleStarted,
 			name,
@@ -1273,6 +1303,9 @@ func (m *Manager) StartAll(ctx context.Context) error {
 	// Start the TTL janitor that cleans up stale typing/placeholder entries
 	go m.runTTLJanitor(dispatchCtx)
 
+	// Release proactive messages held back during quiet hours
+	go m.runQuietHoursFlush(dispatchCtx)
+
 	// Start shared HTTP server if configured
 	if m.httpServer != nil {
 		if len(m.httpListeners) > 0 {
@@ -1407,7 +1440,10 @@ func newChannelWorker(name string, ch Channel, channelType string) *channelWorke
 // runWorker processes outbound messages for a single channel.
 // Message processing follows this order:
 //  1. SplitByMarker (if enabled in config) - LLM semantic marker-based splitting
-//  2. SplitMessage - channel-specific length-based splitting (MaxMessageLength)
+//  2. SplitMessage - channel-specific length-based splitting (MaxMessageLength),
+//     leaving room for the channel's message_prefix and message_suffix
+//  3. FormatOutput - conversion of each chunk to the channel's output format,
+//     after the prefix is added to the first chunk and the suffix to the last
 func (m *Manager) runWorker(ctx context.Context, name string, w *channelWorker) {
 	defer close(w.done)
 	for {
@@ -1416,6 +1452,17 @@ func (m *Manager) runWorker(ctx context.Context, name string, w *channelWorker)
 			if !ok {
 				return
 			}
+			if outboundOperation(msg) != bus.OutboundSend {
+				if err := m.applyMessageOperation(ctx, name, w, msg); err != nil {
+					logger.WarnCF("channels", "Message operation failed", map[string]any{
+						"channel":   name,
+						"chat_id":   outboundMessageChatID(msg),
+						"operation": string(msg.Operation),
+						"error":     err.Error(),
+					})
+				}
+				continue
+			}
 			maxLen := 0
 			if mlp, ok := w.ch.(MessageLengthProvider); ok {
 				maxLen = mlp.MaxMessageLength()
@@ -1423,12 +1470,26 @@ func (m *Manager) runWorker(ctx context.Context, name string, w *channelWorker)
 
 			// Collect all message chunks to send
 			var chunks []string
+			streamFinal := m.finalizedStreamActiveForMessage(name, msg)
+
+			// The prefix and suffix count against the channel's length limit,
+			// so chunks are split to leave room for them.
+			var prefix, suffix string
+			if !streamFinal {
+				prefix, suffix = m.messageTemplate(name, msg)
+				if prefix != "" || suffix != "" {
+					var fits bool
+					if maxLen, fits = messageTemplateBudget(name, maxLen, prefix, suffix); !fits {
+						prefix, suffix = "", ""
+					}
+				}
+			}
 
 			// Step 1: Try marker-based splitting if enabled.
 			// Tool feedback must stay a single message, so it skips marker splitting.
 			// Stream-final duplicate responses must also stay intact so preSend can
 			// consume the whole final message before any marker chunk leaks.
-			if m.finalizedStreamActiveForMessage(name, msg) {
+			if streamFinal {
 				chunks = []string{msg.Content}
 			} else if m.config != nil && m.config.Agents.Defaults.SplitOnMarker && !outboundMessageIsToolFeedback(msg) {
 				if markerChunks := SplitByMarker(msg.Content); len(markerChunks) > 1 {
@@ -1445,10 +1506,12 @@ func (m *Manager) runWorker(ctx context.Context, name string, w *channelWorker)
 				chunks = splitOutboundMessageContent(msg, maxLen)
 			}
 
-			// Step 3: Send all chunks
+			// Step 3: Add the channel's prefix and suffix once per reply and send
+			// all chunks in the channel's output format
+			chunks = applyMessageTemplate(chunks, prefix, suffix)
 			for _, chunk := range chunks {
 				chunkMsg := msg
-				chunkMsg.Content = chunk
+				chunkMsg.Content = formatOutboundContent(w.ch, chunk)
 				m.sendWithRetry(ctx, name, w, chunkMsg)
 			}
 		case <-ctx.Done():
@@ -1518,17 +1581,30 @@ func (m *Manager) sendWithRetry(
 		return nil, false
 	}
 
+	if !m.breakerAllowSend(name, outboundMessageChatID(msg)) {
+		m.publishOutboundFailed(name, msg, ErrChannelDegraded, false)
+		return nil, false
+	}
+	// Release a recovery probe on exits that record neither outcome, such as
+	// cancellation during backoff.
+	defer m.breakerFor(name).endProbe()
+
 	// Pre-send: stop typing and try to edit placeholder
 	if msgIDs, handled := m.preSend(ctx, name, msg, w.ch); handled {
+		m.breakerRecordSuccess(name)
+		m.recordSentMessages(name, msg, msgIDs)
 		m.publishOutboundSent(name, msg, msgIDs)
 		return msgIDs, true
 	}
 
+	policy := m.retryPolicy(name)
 	var lastErr error
 	var msgIDs []string
-	for attempt := 0; attempt <= maxRetries; attempt++ {
+	for attempt := 0; attempt <= policy.maxRetries; attempt++ {
 		msgIDs, lastErr = w.ch.Send(ctx, msg)
 		if lastErr == nil {
+			m.breakerRecordSuccess(name)
+			m.recordSentMessages(name, msg, msgIDs)
 			m.publishOutboundSent(name, msg, msgIDs)
 			return msgIDs, true
 		}
@@ -1539,24 +1615,13 @@ func (m *Manager) sendWithRetry(
 		}
 
 		// Last attempt exhausted — don't sleep
-		if attempt == maxRetries {
+		if attempt == policy.maxRetries {
 			break
 		}
 
-		// Rate limit error — fixed delay
-		if errors.Is(lastErr, ErrRateLimit) {
-			select {
-			case <-time.After(rateLimitDelay):
-				continue
-			case <-ctx.Done():
-				return nil, false
-			}
-		}
-
-		// ErrTemporary or unknown error — exponential backoff
-		backoff := min(time.Duration(float64(baseBackoff)*math.Pow(2, float64(attempt))), maxBackoff)
+		// Retry-After, rate-limit delay or exponential backoff
 		select {
-		case <-time.After(backoff):
+		case <-time.After(policy.retryDelay(lastErr, attempt)):
 		case <-ctx.Done():
 			return nil, false
 		}
@@ -1567,9 +1632,14 @@ func (m *Manager) sendWithRetry(
 		"channel": name,
 		"chat_id": outboundMessageChatID(msg),
 		"error":   lastErr.Error(),
-		"retries": maxRetries,
+		"retries": policy.maxRetries,
 	})
+	m.breakerRecordFailure(name, lastErr)
 	m.publishOutboundFailed(name, msg, lastErr, false)
+	if !errors.Is(lastErr, ErrSendFailed) {
+		// Permanent errors would fail the same way on replay.
+		m.storeDeadLetter(name, msg, lastErr)
+	}
 
 	return nil, false
 }
@@ -1630,6 +1700,9 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 		m.bus.OutboundChan(),
 		func(msg bus.OutboundMessage) string { return outboundMessageChannel(msg) },
 		func(ctx context.Context, w *channelWorker, msg bus.OutboundMessage) bool {
+			if m.holdDuringQuietHours(msg) {
+				return true
+			}
 			select {
 			case w.queue <- msg:
 				m.publishOutboundQueued(outboundMessageChannel(msg), msg)
@@ -1716,14 +1789,22 @@ func (m *Manager) sendMediaWithRetry(
 		return nil, err
 	}
 
+	if !m.breakerAllowSend(name, outboundMediaChatID(msg)) {
+		m.publishOutboundMediaFailed(name, msg, ErrChannelDegraded)
+		return nil, ErrChannelDegraded
+	}
+	defer m.breakerFor(name).endProbe()
+
 	// Pre-send: stop typing and clean up any placeholder before sending media.
 	m.preSendMedia(ctx, name, msg, w.ch)
 
+	policy := m.retryPolicy(name)
 	var lastErr error
 	var msgIDs []string
-	for attempt := 0; attempt <= maxRetries; attempt++ {
+	for attempt := 0; attempt <= policy.maxRetries; attempt++ {
 		msgIDs, lastErr = ms.SendMedia(ctx, msg)
 		if lastErr == nil {
+			m.breakerRecordSuccess(name)
 			m.publishOutboundMediaSent(name, msg, msgIDs)
 			return msgIDs, nil
 		}
@@ -1734,24 +1815,13 @@ func (m *Manager) sendMediaWithRetry(
 		}
 
 		// Last attempt exhausted — don't sleep
-		if attempt == maxRetries {
+		if attempt == policy.maxRetries {
 			break
 		}
 
-		// Rate limit error — fixed delay
-		if errors.Is(lastErr, ErrRateLimit) {
-			select {
-			case <-time.After(rateLimitDelay):
-				continue
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			}
-		}
-
-		// ErrTemporary or unknown error — exponential backoff
-		backoff := min(time.Duration(float64(baseBackoff)*math.Pow(2, float64(attempt))), maxBackoff)
+		// Retry-After, rate-limit delay or exponential backoff
 		select {
-		case <-time.After(backoff):
+		case <-time.After(policy.retryDelay(lastErr, attempt)):
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		}
@@ -1762,8 +1832,9 @@ func (m *Manager) sendMediaWithRetry(
 		"channel": name,
 		"chat_id": outboundMediaChatID(msg),
 		"error":   lastErr.Error(),
-		"retries": maxRetries,
+		"retries": policy.maxRetries,
 	})
+	m.breakerRecordFailure(name, lastErr)
 	m.publishOutboundMediaFailed(name, msg, lastErr)
 	return nil, lastErr
 }
@@ -1831,9 +1902,11 @@ func (m *Manager) GetStatus() map[string]any {
 
 	status := make(map[string]any)
 	for name, channel := range m.channels {
+		health := m.channelHealth(name, channel)
 		status[name] = map[string]any{
 			"enabled": true,
 			"running": channel.IsRunning(),
+			"health":  string(health.State),
 		}
 	}
 	return status
@@ -1928,6 +2001,7 @@ func (m *Manager) Reload(ctx context.Context, cfg *config.Config) error {
 		m.workers[name] = w
 		go m.runWorker(dispatchCtx, name, w)
 		go m.runMediaWorker(dispatchCtx, name, w)
+		go m.replayDeadLetters(dispatchCtx, name, w)
 		m.publishChannelEvent(
 			runtimeevents.KindChannelLifecycleStarted,
 			name,
@@ -1973,6 +2047,7 @@ func (m *Manager) UnregisterChannel(name string) {
 	}
 	delete(m.workers, name)
 	delete(m.channels, name)
+	m.breakers.Delete(name)
 }
 
 // SendMessage sends an outbound message synchronously through the channel
@@ -1994,21 +2069,33 @@ func (m *Manager) SendMessage(ctx context.Context, msg bus.OutboundMessage) erro
 	if !wExists || w == nil {
 		return fmt.Errorf("channel %s has no active worker", channelName)
 	}
+	if outboundOperation(msg) != bus.OutboundSend {
+		return m.applyMessageOperation(ctx, channelName, w, msg)
+	}
 
 	maxLen := 0
 	if mlp, ok := w.ch.(MessageLengthProvider); ok {
 		maxLen = mlp.MaxMessageLength()
 	}
-	if chunks := splitOutboundMessageContent(msg, maxLen); len(chunks) > 1 {
+	prefix, suffix := m.messageTemplate(channelName, msg)
+	if prefix != "" || suffix != "" {
+		var fits bool
+		if maxLen, fits = messageTemplateBudget(channelName, maxLen, prefix, suffix); !fits {
+			prefix, suffix = "", ""
+		}
+	}
+	chunks := applyMessageTemplate(splitOutboundMessageContent(msg, maxLen), prefix, suffix)
+	if len(chunks) > 1 {
 		for _, chunk := range chunks {
 			chunkMsg := msg
-			chunkMsg.Content = chunk
+			chunkMsg.Content = formatOutboundContent(w.ch, chunk)
 			m.sendWithRetry(ctx, channelName, w, chunkMsg)
 		}
 	} else {
 		if len(chunks) == 1 {
 			msg.Content = chunks[0]
 		}
+		msg.Content = formatOutboundContent(w.ch, msg.Content)
 		m.sendWithRetry(ctx, channelName, w, msg)
 	}
 	return nil
@@ -121,6 +121,10 @@ func hiddenValues(key string, value map[string]any, ch *config.Channel) {
 			}
 		}
 		value["webhooks"] = webhooks
+	case "sms":
+		if settings, ok := v.(*config.SMSSettings); ok {
+			value["auth_token"] = settings.AuthToken.String()
+		}
 	case "mqtt":
 		if settings, ok := v.(*config.MQTTSettings); ok {
 			value["username"] = settings.Username.String()
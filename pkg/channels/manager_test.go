@@ -632,6 +632,105 @@ func TestSendWithRetry_MaxRetriesExhausted(t *testing.T) {
 	}
 }
 
+func TestSendWithRetry_ConfiguredRetryPolicy(t *testing.T) {
+	m := newTestManager()
+	retries := 1
+	m.config = &config.Config{Channels: config.ChannelsConfig{
+		"test": {Retry: config.ChannelRetryConfig{MaxRetries: &retries, BaseBackoffMs: 1}},
+	}}
+	var callCount int
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			callCount++
+			return fmt.Errorf("timeout: %w", ErrTemporary)
+		},
+	}
+	w := &channelWorker{
+		ch:      ch,
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "hello"})
+	m.sendWithRetry(context.Background(), "test", w, msg)
+
+	if callCount != 2 {
+		t.Fatalf("expected 2 Send calls with max_retries=1, got %d", callCount)
+	}
+}
+
+func TestSendWithRetry_HonorsRetryAfter(t *testing.T) {
+	m := newTestManager()
+	var callCount int
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			callCount++
+			if callCount == 1 {
+				// A short Retry-After must win over the 1s rate-limit delay.
+				return WithRetryAfter(fmt.Errorf("429: %w", ErrRateLimit), 10*time.Millisecond)
+			}
+			return nil
+		},
+	}
+	w := &channelWorker{
+		ch:      ch,
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "hello"})
+	start := time.Now()
+	m.sendWithRetry(context.Background(), "test", w, msg)
+	elapsed := time.Since(start)
+
+	if callCount != 2 {
+		t.Fatalf("expected 2 Send calls, got %d", callCount)
+	}
+	if elapsed >= 500*time.Millisecond {
+		t.Fatalf("expected Retry-After delay instead of rate-limit delay, got %v", elapsed)
+	}
+}
+
+func TestSendWithRetry_DeadLetterReplayedOnStart(t *testing.T) {
+	m := newTestManager()
+	retries := 0
+	m.config = &config.Config{
+		Agents: config.AgentsConfig{Defaults: config.AgentDefaults{Workspace: t.TempDir()}},
+		Channels: config.ChannelsConfig{
+			"test": {Retry: config.ChannelRetryConfig{MaxRetries: &retries, DeadLetter: true}},
+		},
+	}
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			return fmt.Errorf("upstream 502: %w", ErrTemporary)
+		},
+	}
+	w := &channelWorker{
+		ch:      ch,
+		queue:   make(chan bus.OutboundMessage, 1),
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "hello"})
+	m.sendWithRetry(context.Background(), "test", w, msg)
+
+	// On the next start the stored message is queued again.
+	m.replayDeadLetters(context.Background(), "test", w)
+
+	select {
+	case got := <-w.queue:
+		if got.Content != "hello" || got.ChatID != "1" {
+			t.Fatalf("replayed message = %+v, want the undelivered one", got)
+		}
+	default:
+		t.Fatal("expected undelivered message to be queued for replay")
+	}
+
+	// The dead-letter file is consumed by the replay.
+	m.replayDeadLetters(context.Background(), "test", w)
+	if len(w.queue) != 0 {
+		t.Fatal("expected dead-letter file to be emptied after replay")
+	}
+}
+
 func TestSendMedia_Success(t *testing.T) {
 	m := newTestManager()
 	var callCount int
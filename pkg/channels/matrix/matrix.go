@@ -242,6 +242,8 @@ func NewMatrixChannel(
 		channels.WithMaxMessageLength(65536),
 		channels.WithGroupTrigger(bc.GroupTrigger),
 		channels.WithReasoningChannelID(bc.ReasoningChannelID),
+		// Send already renders markdown into formatted_body.
+		channels.WithOutputFormatter(config.OutputFormatHTML, nil),
 	)
 
 	ch := &MatrixChannel{
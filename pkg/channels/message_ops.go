@@ -0,0 +1,152 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// maxTrackedSentMessages bounds how many sent message IDs are remembered per
+// chat for later edits and deletes.
+const maxTrackedSentMessages = 20
+
+// sentMessageLog is the list of message IDs the agent sent to one chat,
+// oldest first.
+type sentMessageLog struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (l *sentMessageLog) add(ids []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ids = append(l.ids, ids...)
+	if n := len(l.ids) - maxTrackedSentMessages; n > 0 {
+		l.ids = append([]string(nil), l.ids[n:]...)
+	}
+}
+
+func (l *sentMessageLog) last() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.ids) == 0 {
+		return ""
+	}
+	return l.ids[len(l.ids)-1]
+}
+
+func (l *sentMessageLog) remove(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, existing := range l.ids {
+		if existing == id {
+			l.ids = append(l.ids[:i:i], l.ids[i+1:]...)
+			return
+		}
+	}
+}
+
+func outboundOperation(msg bus.OutboundMessage) bus.OutboundOperation {
+	switch op := bus.OutboundOperation(strings.ToLower(strings.TrimSpace(string(msg.Operation)))); op {
+	case bus.OutboundEdit, bus.OutboundDelete:
+		return op
+	default:
+		return bus.OutboundSend
+	}
+}
+
+// recordSentMessages remembers message IDs delivered to a chat so later edit
+// and delete operations can refer to them. Tool feedback is transient and is
+// not recorded.
+func (m *Manager) recordSentMessages(channelName string, msg bus.OutboundMessage, ids []string) {
+	if len(ids) == 0 || outboundMessageIsToolFeedback(msg) {
+		return
+	}
+	key := channelName + ":" + outboundMessageChatID(msg)
+	v, _ := m.sentMessages.LoadOrStore(key, &sentMessageLog{})
+	v.(*sentMessageLog).add(ids)
+}
+
+// LastSentMessageID returns the ID of the latest message the agent sent to
+// the chat through this manager, or "" if none is known.
+func (m *Manager) LastSentMessageID(channelName, chatID string) string {
+	v, ok := m.sentMessages.Load(channelName + ":" + chatID)
+	if !ok {
+		return ""
+	}
+	return v.(*sentMessageLog).last()
+}
+
+// resolveTargetMessageID turns a TargetMessageID into a platform message ID;
+// empty and TargetLastMessage mean the latest message sent to the chat.
+func (m *Manager) resolveTargetMessageID(channelName string, msg bus.OutboundMessage) string {
+	target := strings.TrimSpace(msg.TargetMessageID)
+	if target == "" || strings.EqualFold(target, bus.TargetLastMessage) {
+		return m.LastSentMessageID(channelName, outboundMessageChatID(msg))
+	}
+	return target
+}
+
+// applyMessageOperation performs an edit or delete operation. Channels that
+// cannot edit get the new content as a fresh message instead; channels that
+// cannot delete leave the message in place.
+func (m *Manager) applyMessageOperation(
+	ctx context.Context,
+	name string,
+	w *channelWorker,
+	msg bus.OutboundMessage,
+) error {
+	op := outboundOperation(msg)
+	chatID := outboundMessageChatID(msg)
+	target := m.resolveTargetMessageID(name, msg)
+
+	switch op {
+	case bus.OutboundEdit:
+		msg.Content = formatOutboundContent(w.ch, msg.Content)
+		editor, ok := w.ch.(MessageEditor)
+		if !ok || target == "" {
+			logger.DebugCF("channels", "Edit not possible, sending correction as new message", map[string]any{
+				"channel": name,
+				"chat_id": chatID,
+			})
+			msg.Operation = bus.OutboundSend
+			msg.TargetMessageID = ""
+			if _, ok := m.sendWithRetry(ctx, name, w, msg); !ok {
+				return fmt.Errorf("sending correction to %s:%s failed", name, chatID)
+			}
+			return nil
+		}
+		if err := w.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		if err := editor.EditMessage(ctx, chatID, target, msg.Content); err != nil {
+			return fmt.Errorf("editing message %s: %w", target, err)
+		}
+		m.publishOutboundSent(name, msg, []string{target})
+		return nil
+
+	case bus.OutboundDelete:
+		if target == "" {
+			return fmt.Errorf("no message to delete in %s:%s", name, chatID)
+		}
+		deleter, ok := w.ch.(MessageDeleter)
+		if !ok {
+			return fmt.Errorf("channel %s does not support deleting messages", name)
+		}
+		if err := w.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		if err := deleter.DeleteMessage(ctx, chatID, target); err != nil {
+			return fmt.Errorf("deleting message %s: %w", target, err)
+		}
+		if v, ok := m.sentMessages.Load(name + ":" + chatID); ok {
+			v.(*sentMessageLog).remove(target)
+		}
+		return nil
+	}
+	return nil
+}
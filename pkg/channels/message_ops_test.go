@@ -0,0 +1,130 @@
+package channels
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+// sendOnlyChannel can neither edit nor delete messages.
+type sendOnlyChannel struct {
+	BaseChannel
+	sentMessages []bus.OutboundMessage
+}
+
+func (c *sendOnlyChannel) Start(context.Context) error { return nil }
+
+func (c *sendOnlyChannel) Stop(context.Context) error { return nil }
+
+func (c *sendOnlyChannel) Send(_ context.Context, msg bus.OutboundMessage) ([]string, error) {
+	c.sentMessages = append(c.sentMessages, msg)
+	return []string{"new"}, nil
+}
+
+func TestSendMessage_EditLastSentMessage(t *testing.T) {
+	m := newTestManager()
+	var editedID, editedContent string
+	ch := &mockMessageEditor{
+		editFn: func(_ context.Context, _, messageID, content string) error {
+			editedID, editedContent = messageID, content
+			return nil
+		},
+	}
+	m.channels["test"] = ch
+	m.workers["test"] = &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	sent := bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "draft"}
+	m.recordSentMessages("test", sent, []string{"10", "11"})
+
+	err := m.SendMessage(context.Background(), bus.OutboundMessage{
+		Channel:   "test",
+		ChatID:    "1",
+		Content:   "corrected",
+		Operation: bus.OutboundEdit,
+	})
+	if err != nil {
+		t.Fatalf("SendMessage(edit) error = %v", err)
+	}
+	if editedID != "11" || editedContent != "corrected" {
+		t.Fatalf("edited %q with %q, want last message 11 with corrected", editedID, editedContent)
+	}
+	if len(ch.sentMessages) != 0 {
+		t.Fatalf("edit should not send a new message, sent %d", len(ch.sentMessages))
+	}
+}
+
+func TestSendMessage_EditFallsBackToSend(t *testing.T) {
+	m := newTestManager()
+	ch := &sendOnlyChannel{}
+	m.channels["test"] = ch
+	m.workers["test"] = &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	err := m.SendMessage(context.Background(), bus.OutboundMessage{
+		Channel:         "test",
+		ChatID:          "1",
+		Content:         "corrected",
+		Operation:       bus.OutboundEdit,
+		TargetMessageID: "42",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage(edit) error = %v", err)
+	}
+	if len(ch.sentMessages) != 1 || ch.sentMessages[0].Content != "corrected" {
+		t.Fatalf("sent = %+v, want correction sent as a new message", ch.sentMessages)
+	}
+	if ch.sentMessages[0].Operation != bus.OutboundSend {
+		t.Fatalf("fallback operation = %q, want send", ch.sentMessages[0].Operation)
+	}
+	if got := m.LastSentMessageID("test", "1"); got != "new" {
+		t.Fatalf("LastSentMessageID = %q, want the correction", got)
+	}
+}
+
+func TestSendMessage_DeleteForgetsMessage(t *testing.T) {
+	m := newTestManager()
+	ch := &mockDeletingMessageEditor{}
+	m.channels["test"] = ch
+	m.workers["test"] = &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	sent := bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "oops"}
+	m.recordSentMessages("test", sent, []string{"10", "11"})
+
+	err := m.SendMessage(context.Background(), bus.OutboundMessage{
+		Channel:         "test",
+		ChatID:          "1",
+		Operation:       bus.OutboundDelete,
+		TargetMessageID: bus.TargetLastMessage,
+	})
+	if err != nil {
+		t.Fatalf("SendMessage(delete) error = %v", err)
+	}
+	if ch.deletedChatID != "1" || ch.deletedMessageID != "11" {
+		t.Fatalf("deleted %s/%s, want 1/11", ch.deletedChatID, ch.deletedMessageID)
+	}
+	if got := m.LastSentMessageID("test", "1"); got != "10" {
+		t.Fatalf("LastSentMessageID after delete = %q, want 10", got)
+	}
+}
+
+func TestSendMessage_DeleteUnsupported(t *testing.T) {
+	m := newTestManager()
+	ch := &sendOnlyChannel{}
+	m.channels["test"] = ch
+	m.workers["test"] = &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	err := m.SendMessage(context.Background(), bus.OutboundMessage{
+		Channel:         "test",
+		ChatID:          "1",
+		Operation:       bus.OutboundDelete,
+		TargetMessageID: "42",
+	})
+	if err == nil {
+		t.Fatal("expected error for channel without delete support")
+	}
+	if len(ch.sentMessages) != 0 {
+		t.Fatalf("delete should not send anything, sent %d", len(ch.sentMessages))
+	}
+}
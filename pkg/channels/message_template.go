@@ -0,0 +1,77 @@
+package channels
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// messageTemplateTimeFormat renders the {timestamp} placeholder.
+const messageTemplateTimeFormat = "2006-01-02 15:04 MST"
+
+// messageTemplate returns the rendered message_prefix and message_suffix of
+// the channel for msg. Only the agent's final replies are wrapped; tool
+// feedback, thoughts and messages sent from tools go out unchanged.
+//
+// Placeholders: {model} is the model that produced the reply, {agent} the
+// configured agent name and {timestamp} the send time in the agent timezone.
+func (m *Manager) messageTemplate(name string, msg bus.OutboundMessage) (prefix, suffix string) {
+	if m.config == nil || !outboundMessageIsFinal(msg) {
+		return "", ""
+	}
+	bc := m.config.Channels.Get(name)
+	if bc == nil || (bc.MessagePrefix == "" && bc.MessageSuffix == "") {
+		return "", ""
+	}
+
+	agentName := strings.TrimSpace(m.config.Agents.Defaults.Name)
+	if agentName == "" {
+		agentName = "PicoClaw"
+	}
+	now := time.Now()
+	if tz := strings.TrimSpace(m.config.Agents.Defaults.Timezone); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			now = now.In(loc)
+		}
+	}
+	r := strings.NewReplacer(
+		"{model}", strings.TrimSpace(msg.Context.Raw["model_name"]),
+		"{agent}", agentName,
+		"{timestamp}", now.Format(messageTemplateTimeFormat),
+	)
+	return r.Replace(bc.MessagePrefix), r.Replace(bc.MessageSuffix)
+}
+
+// messageTemplateBudget returns the length each chunk may use so the prefix
+// and suffix still fit within maxLen, which is unchanged when there is no
+// limit. It reports false when the template alone does not fit, in which
+// case the reply is sent without it.
+func messageTemplateBudget(name string, maxLen int, prefix, suffix string) (int, bool) {
+	if maxLen <= 0 {
+		return maxLen, true
+	}
+	budget := maxLen - utf8.RuneCountInString(prefix) - utf8.RuneCountInString(suffix)
+	if budget <= 0 {
+		logger.WarnCF("channels", "Message prefix and suffix exceed the channel's length limit; sending without them",
+			map[string]any{
+				"channel": name,
+				"max_len": maxLen,
+			})
+		return maxLen, false
+	}
+	return budget, true
+}
+
+// applyMessageTemplate adds prefix to the first chunk and suffix to the last,
+// so a reply split into several messages carries them once.
+func applyMessageTemplate(chunks []string, prefix, suffix string) []string {
+	if len(chunks) == 0 {
+		return chunks
+	}
+	chunks[0] = prefix + chunks[0]
+	chunks[len(chunks)-1] += suffix
+	return chunks
+}
@@ -0,0 +1,101 @@
+package channels
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newTemplateTestManager(bc *config.Channel, maxLen int) (*Manager, *[]string) {
+	m := newTestManager()
+	m.config = &config.Config{Channels: config.ChannelsConfig{"sms": bc}}
+	m.config.Agents.Defaults.Name = "Jarvis"
+
+	var received []string
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, msg bus.OutboundMessage) error {
+			received = append(received, msg.Content)
+			return nil
+		},
+	}
+	ch.maxMessageLength = maxLen
+	m.channels["sms"] = ch
+	m.workers["sms"] = &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+	return m, &received
+}
+
+func finalReply(content string) bus.OutboundMessage {
+	return testOutboundMessage(bus.OutboundMessage{
+		Channel: "sms",
+		ChatID:  "+15550100",
+		Content: content,
+		Context: bus.InboundContext{
+			Channel: "sms",
+			ChatID:  "+15550100",
+			Raw:     map[string]string{"outbound_kind": "final", "model_name": "glm-4.7"},
+		},
+	})
+}
+
+func TestSendMessage_AddsPrefixAndSuffixOncePerReply(t *testing.T) {
+	m, received := newTemplateTestManager(&config.Channel{
+		MessagePrefix: "[{agent}] ",
+		MessageSuffix: "\n-- {model}",
+	}, 40)
+
+	content := strings.Repeat("word ", 20)
+	if err := m.SendMessage(context.Background(), finalReply(content)); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	chunks := *received
+	if len(chunks) < 2 {
+		t.Fatalf("sent %d chunks, want the reply split: %q", len(chunks), chunks)
+	}
+	for i, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > 40 {
+			t.Errorf("chunk %d has %d runes, over the 40 limit: %q", i, n, chunk)
+		}
+	}
+	if !strings.HasPrefix(chunks[0], "[Jarvis] ") || !strings.HasSuffix(chunks[len(chunks)-1], "\n-- glm-4.7") {
+		t.Fatalf("template not applied to first and last chunk: %q", chunks)
+	}
+	joined := strings.Join(chunks, "")
+	if strings.Count(joined, "[Jarvis]") != 1 || strings.Count(joined, "glm-4.7") != 1 {
+		t.Fatalf("template repeated across chunks: %q", chunks)
+	}
+}
+
+func TestSendMessage_TemplateSkipsNonFinalMessages(t *testing.T) {
+	m, received := newTemplateTestManager(&config.Channel{MessageSuffix: " (bot)"}, 0)
+
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "sms", ChatID: "+15550100", Content: "working on it"})
+	if err := m.SendMessage(context.Background(), msg); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if err := m.SendMessage(context.Background(), finalReply("done")); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	want := []string{"working on it", "done (bot)"}
+	if strings.Join(*received, "|") != strings.Join(want, "|") {
+		t.Fatalf("sent %q, want %q", *received, want)
+	}
+}
+
+func TestSendMessage_TemplateLongerThanLimitIsDropped(t *testing.T) {
+	m, received := newTemplateTestManager(&config.Channel{MessageSuffix: strings.Repeat("x", 30)}, 20)
+
+	if err := m.SendMessage(context.Background(), finalReply("short reply")); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if len(*received) != 1 || (*received)[0] != "short reply" {
+		t.Fatalf("sent %q, want the reply without the oversized suffix", *received)
+	}
+}
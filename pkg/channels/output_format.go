@@ -0,0 +1,233 @@
+package channels
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// OutputFormatter converts the agent's markdown into the text a channel sends.
+type OutputFormatter func(markdown string) string
+
+// OutputFormatProvider is implemented by channels that render outbound
+// content in a format other than markdown. The Manager applies it to every
+// chunk it sends or edits, after splitting, so code fences are still intact
+// when the formatter runs. BaseChannel implements it for every channel.
+type OutputFormatProvider interface {
+	FormatOutput(content string) string
+}
+
+// WithOutputFormatter overrides the formatter BaseChannel uses for format,
+// for platforms whose flavor of plain text or HTML differs from the default.
+// A nil formatter sends content unchanged, for channels that already render
+// markdown in that format themselves.
+func WithOutputFormatter(format string, f OutputFormatter) BaseChannelOption {
+	return func(c *BaseChannel) {
+		if c.outputFormatters == nil {
+			c.outputFormatters = make(map[string]OutputFormatter)
+		}
+		c.outputFormatters[format] = f
+	}
+}
+
+// SetOutputFormat selects the channel's output format, one of the
+// config.OutputFormat* values. The Manager sets it from the channel's
+// output_format config.
+func (c *BaseChannel) SetOutputFormat(format string) {
+	c.outputFormat = format
+}
+
+// OutputFormat returns the channel's output format; empty means markdown.
+func (c *BaseChannel) OutputFormat() string {
+	return c.outputFormat
+}
+
+// FormatOutput converts markdown content to the channel's output format.
+func (c *BaseChannel) FormatOutput(content string) string {
+	if c.outputFormat == "" || c.outputFormat == config.OutputFormatMarkdown {
+		return content
+	}
+	f, overridden := c.outputFormatters[c.outputFormat]
+	if !overridden {
+		f = DefaultOutputFormatter(c.outputFormat)
+	}
+	if f == nil {
+		return content
+	}
+	return f(content)
+}
+
+// formatOutboundContent renders content in ch's output format.
+func formatOutboundContent(ch Channel, content string) string {
+	if f, ok := ch.(OutputFormatProvider); ok {
+		return f.FormatOutput(content)
+	}
+	return content
+}
+
+// DefaultOutputFormatter returns the built-in formatter for format, or nil
+// when content is sent as markdown.
+func DefaultOutputFormatter(format string) OutputFormatter {
+	switch format {
+	case config.OutputFormatPlain:
+		return MarkdownToPlain
+	case config.OutputFormatHTML:
+		return MarkdownToHTML
+	default:
+		return nil
+	}
+}
+
+var (
+	reFmtCodeBlock  = regexp.MustCompile("```[\\w+-]*\\n?([\\s\\S]*?)```")
+	reFmtInlineCode = regexp.MustCompile("`([^`\\n]+)`")
+	reFmtImage      = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	reFmtLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	reFmtHeading    = regexp.MustCompile(`(?m)^#{1,6}[ \t]+(.+?)[ \t#]*$`)
+	reFmtListItem   = regexp.MustCompile(`(?m)^([ \t]*)[-*+][ \t]+`)
+	reFmtRule       = regexp.MustCompile(`(?m)^[ \t]*([-*_])[ \t]*(?:[-*_][ \t]*){2,}$`)
+	reFmtBoldStar   = regexp.MustCompile(`\*\*([^*\n]+?)\*\*`)
+	reFmtBoldUnder  = regexp.MustCompile(`\b__([^_\n]+?)__\b`)
+	reFmtItalicStar = regexp.MustCompile(`(^|[^*\w])\*([^*\s][^*\n]*?)\*([^*\w]|$)`)
+	reFmtItalicUndr = regexp.MustCompile(`(^|[^_\w])_([^_\s][^_\n]*?)_([^_\w]|$)`)
+	reFmtStrike     = regexp.MustCompile(`~~([^~\n]+?)~~`)
+)
+
+// protectedSpans swaps code and links for placeholders so inline markup
+// inside them is left alone.
+type protectedSpans struct {
+	spans []string
+}
+
+func (p *protectedSpans) add(rendered string) string {
+	p.spans = append(p.spans, rendered)
+	return fmt.Sprintf("\x00%d\x00", len(p.spans)-1)
+}
+
+func (p *protectedSpans) restore(text string) string {
+	for i := len(p.spans) - 1; i >= 0; i-- {
+		text = strings.ReplaceAll(text, fmt.Sprintf("\x00%d\x00", i), p.spans[i])
+	}
+	return text
+}
+
+// htmlTextEscaper escapes text outside tags. Quotes stay readable since they
+// only need escaping inside attributes.
+var htmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// replaceEmphasis swaps bold, italic and strikethrough markers for the given
+// open and close strings, which are empty to strip them.
+func replaceEmphasis(text, boldOpen, boldClose, italicOpen, italicClose, strikeOpen, strikeClose string) string {
+	text = reFmtBoldStar.ReplaceAllString(text, boldOpen+"$1"+boldClose)
+	text = reFmtBoldUnder.ReplaceAllString(text, boldOpen+"$1"+boldClose)
+	text = reFmtItalicStar.ReplaceAllString(text, "$1"+italicOpen+"$2"+italicClose+"$3")
+	text = reFmtItalicUndr.ReplaceAllString(text, "$1"+italicOpen+"$2"+italicClose+"$3")
+	return reFmtStrike.ReplaceAllString(text, strikeOpen+"$1"+strikeClose)
+}
+
+// MarkdownToPlain renders markdown as plain text for channels that show
+// markup literally, such as SMS or IRC. Code fences and emphasis markers are
+// dropped, links become "text (url)", headings become plain lines and list
+// bullets become "- ".
+func MarkdownToPlain(markdown string) string {
+	if markdown == "" {
+		return ""
+	}
+	var p protectedSpans
+	text := reFmtCodeBlock.ReplaceAllStringFunc(markdown, func(m string) string {
+		return p.add(strings.TrimRight(reFmtCodeBlock.FindStringSubmatch(m)[1], "\n"))
+	})
+	text = reFmtInlineCode.ReplaceAllStringFunc(text, func(m string) string {
+		return p.add(reFmtInlineCode.FindStringSubmatch(m)[1])
+	})
+	text = reFmtImage.ReplaceAllStringFunc(text, func(m string) string {
+		sub := reFmtImage.FindStringSubmatch(m)
+		return p.add(plainLink(sub[1], sub[2]))
+	})
+	text = reFmtLink.ReplaceAllStringFunc(text, func(m string) string {
+		sub := reFmtLink.FindStringSubmatch(m)
+		return p.add(plainLink(sub[1], sub[2]))
+	})
+
+	text = reFmtRule.ReplaceAllString(text, "")
+	text = reFmtHeading.ReplaceAllString(text, "$1")
+	text = reFmtListItem.ReplaceAllString(text, "$1- ")
+	text = replaceEmphasis(text, "", "", "", "", "", "")
+	return p.restore(text)
+}
+
+func plainLink(label, url string) string {
+	if label == "" || label == url {
+		return url
+	}
+	return label + " (" + url + ")"
+}
+
+// MarkdownToHTML renders markdown in the HTML subset chat platforms such as
+// Telegram accept: b, i, s, code, pre, a and blockquote. Line breaks stay
+// newlines rather than <br> tags, headings become bold lines and list
+// bullets become "• ".
+func MarkdownToHTML(markdown string) string {
+	if markdown == "" {
+		return ""
+	}
+	var p protectedSpans
+	text := reFmtCodeBlock.ReplaceAllStringFunc(markdown, func(m string) string {
+		body := strings.TrimRight(reFmtCodeBlock.FindStringSubmatch(m)[1], "\n")
+		return p.add("<pre><code>" + htmlTextEscaper.Replace(body) + "</code></pre>")
+	})
+	text = reFmtInlineCode.ReplaceAllStringFunc(text, func(m string) string {
+		return p.add("<code>" + htmlTextEscaper.Replace(reFmtInlineCode.FindStringSubmatch(m)[1]) + "</code>")
+	})
+	text = reFmtImage.ReplaceAllStringFunc(text, func(m string) string {
+		sub := reFmtImage.FindStringSubmatch(m)
+		label := sub[1]
+		if label == "" {
+			label = sub[2]
+		}
+		return p.add(htmlLink(label, sub[2]))
+	})
+	text = reFmtLink.ReplaceAllStringFunc(text, func(m string) string {
+		sub := reFmtLink.FindStringSubmatch(m)
+		return p.add(htmlLink(sub[1], sub[2]))
+	})
+
+	text = htmlTextEscaper.Replace(text)
+	text = reFmtRule.ReplaceAllString(text, "")
+	text = reFmtHeading.ReplaceAllString(text, "<b>$1</b>")
+	text = reFmtListItem.ReplaceAllString(text, "$1• ")
+	text = replaceEmphasis(text, "<b>", "</b>", "<i>", "</i>", "<s>", "</s>")
+	text = wrapBlockquotes(text)
+	return p.restore(text)
+}
+
+func htmlLink(label, url string) string {
+	return `<a href="` + html.EscapeString(url) + `">` + htmlTextEscaper.Replace(label) + "</a>"
+}
+
+// wrapBlockquotes turns runs of "> " lines, already HTML-escaped to
+// "&gt; ", into one blockquote element.
+func wrapBlockquotes(text string) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	var quote []string
+	flush := func() {
+		if len(quote) > 0 {
+			out = append(out, "<blockquote>"+strings.Join(quote, "\n")+"</blockquote>")
+			quote = nil
+		}
+	}
+	for _, line := range lines {
+		if rest, ok := strings.CutPrefix(line, "&gt;"); ok {
+			quote = append(quote, strings.TrimPrefix(rest, " "))
+			continue
+		}
+		flush()
+		out = append(out, line)
+	}
+	flush()
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,114 @@
+package channels
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestMarkdownToPlain(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"emphasis", "This is **bold**, *italic*, __strong__, _soft_ and ~~gone~~.",
+			"This is bold, italic, strong, soft and gone."},
+		{"heading and list", "## Steps\n- first\n* second\n  + nested", "Steps\n- first\n- second\n  - nested"},
+		{"link", "See [the docs](https://example.com/docs).", "See the docs (https://example.com/docs)."},
+		{"bare link label", "[https://example.com](https://example.com)", "https://example.com"},
+		{"inline code keeps markup", "Run `rm -rf **tmp**` now", "Run rm -rf **tmp** now"},
+		{"code block", "Try:\n```go\nx := a * b * c\n```\ndone", "Try:\nx := a * b * c\ndone"},
+		{"snake_case untouched", "set max_response_chars and 2 * 3 * 4", "set max_response_chars and 2 * 3 * 4"},
+		{"rule", "above\n---\nbelow", "above\n\nbelow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MarkdownToPlain(tt.in); got != tt.want {
+				t.Errorf("MarkdownToPlain(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"emphasis", "**bold** *italic* ~~gone~~", "<b>bold</b> <i>italic</i> <s>gone</s>"},
+		{"escapes text", "a < b && c > d", "a &lt; b &amp;&amp; c &gt; d"},
+		{"heading and list", "# Title\n- one", "<b>Title</b>\n• one"},
+		{"link", `[a "b" <c>](https://example.com/?q=1&r=2)`,
+			`<a href="https://example.com/?q=1&amp;r=2">a "b" &lt;c&gt;</a>`},
+		{"inline code", "use `<div>` and **not** `**this**`", "use <code>&lt;div&gt;</code> and <b>not</b> <code>**this**</code>"},
+		{"code block", "```python\nif a < b:\n    pass\n```", "<pre><code>if a &lt; b:\n    pass</code></pre>"},
+		{"blockquote", "> quoted\n> lines\nafter", "<blockquote>quoted\nlines</blockquote>\nafter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MarkdownToHTML(tt.in); got != tt.want {
+				t.Errorf("MarkdownToHTML(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseChannelFormatOutput(t *testing.T) {
+	bc := NewBaseChannel("test", nil, nil, []string{"*"})
+	if got := bc.FormatOutput("**hi**"); got != "**hi**" {
+		t.Errorf("default format changed content: %q", got)
+	}
+
+	bc.SetOutputFormat(config.OutputFormatPlain)
+	if got := bc.FormatOutput("**hi**"); got != "hi" {
+		t.Errorf("plain format = %q, want %q", got, "hi")
+	}
+
+	custom := NewBaseChannel("test", nil, nil, []string{"*"},
+		WithOutputFormatter(config.OutputFormatPlain, strings.ToUpper),
+		WithOutputFormatter(config.OutputFormatHTML, nil),
+	)
+	custom.SetOutputFormat(config.OutputFormatPlain)
+	if got := custom.FormatOutput("**hi**"); got != "**HI**" {
+		t.Errorf("overridden plain format = %q, want %q", got, "**HI**")
+	}
+	custom.SetOutputFormat(config.OutputFormatHTML)
+	if got := custom.FormatOutput("**hi**"); got != "**hi**" {
+		t.Errorf("pass-through html format = %q, want %q", got, "**hi**")
+	}
+}
+
+func TestSendMessage_AppliesOutputFormatPerChunk(t *testing.T) {
+	m := newTestManager()
+
+	var received []string
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, msg bus.OutboundMessage) error {
+			received = append(received, msg.Content)
+			return nil
+		},
+	}
+	ch.SetOutputFormat(config.OutputFormatPlain)
+	m.channels["sms"] = ch
+	m.workers["sms"] = &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	err := m.SendMessage(context.Background(), testOutboundMessage(bus.OutboundMessage{
+		Channel: "sms",
+		ChatID:  "+15550100",
+		Content: "**Done.** See [log](https://example.com/log) and run `make`.",
+	}))
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	want := "Done. See log (https://example.com/log) and run make."
+	if len(received) != 1 || received[0] != want {
+		t.Fatalf("sent %q, want [%q]", received, want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -85,6 +86,11 @@ func (c *PicoClientChannel) dial() error {
 	if c.config.Token.String() != "" {
 		header.Set("Authorization", "Bearer "+c.config.Token.String())
 	}
+	if secret := c.config.Secret.String(); secret != "" {
+		ts := time.Now().Unix()
+		header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+		header.Set(SignatureHeader, SignHandshake(secret, ts))
+	}
 
 	ws, resp, err := websocket.DefaultDialer.DialContext(c.ctx, c.config.URL, header)
 	if resp != nil && resp.Body != nil {
@@ -0,0 +1,88 @@
+package pico
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// TimestampHeader carries the Unix timestamp (seconds) of an HMAC handshake.
+	TimestampHeader = "X-Pico-Timestamp"
+	// SignatureHeader carries the hex HMAC-SHA256 of the timestamp.
+	SignatureHeader = "X-Pico-Signature"
+
+	// hmacSubprotocolPrefix lets browsers, which cannot set headers on a
+	// WebSocket upgrade, send the handshake as "hmac.<timestamp>.<signature>".
+	hmacSubprotocolPrefix = "hmac."
+
+	// hmacMaxClockSkew bounds how far a handshake timestamp may drift from the
+	// server clock. It is also the window in which a captured handshake could
+	// be replayed.
+	hmacMaxClockSkew = 60 * time.Second
+)
+
+var (
+	errHandshakeMissing   = errors.New("missing handshake timestamp or signature")
+	errHandshakeMalformed = errors.New("malformed handshake timestamp")
+	errHandshakeExpired   = errors.New("handshake timestamp outside allowed clock skew")
+	errHandshakeSignature = errors.New("handshake signature mismatch")
+)
+
+// SignHandshake returns the hex-encoded HMAC-SHA256 of the decimal Unix
+// timestamp under secret. Clients send the timestamp and this signature in
+// TimestampHeader and SignatureHeader.
+func SignHandshake(secret string, timestamp int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyHandshake checks a timestamp/signature pair against secret.
+func verifyHandshake(secret, timestamp, signature string, now time.Time) error {
+	if timestamp == "" || signature == "" {
+		return errHandshakeMissing
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errHandshakeMalformed
+	}
+	skew := now.Sub(time.Unix(ts, 0))
+	if skew < -hmacMaxClockSkew || skew > hmacMaxClockSkew {
+		return errHandshakeExpired
+	}
+	expected := SignHandshake(secret, ts)
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected)) {
+		return errHandshakeSignature
+	}
+	return nil
+}
+
+// verifyHMACRequest validates the handshake carried in the request headers or,
+// failing that, in an "hmac.<timestamp>.<signature>" subprotocol. It returns
+// the matched subprotocol (empty when headers were used) so it can be echoed
+// on upgrade.
+func verifyHMACRequest(r *http.Request, secret string, now time.Time) (string, error) {
+	if ts, sig := r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader); ts != "" || sig != "" {
+		return "", verifyHandshake(secret, ts, sig, now)
+	}
+	err := errHandshakeMissing
+	for _, proto := range websocket.Subprotocols(r) {
+		rest, ok := strings.CutPrefix(proto, hmacSubprotocolPrefix)
+		if !ok {
+			continue
+		}
+		ts, sig, _ := strings.Cut(rest, ".")
+		if err = verifyHandshake(secret, ts, sig, now); err == nil {
+			return proto, nil
+		}
+	}
+	return "", err
+}
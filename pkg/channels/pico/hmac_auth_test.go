@@ -0,0 +1,99 @@
+package pico
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newHMACTestPicoChannel(t *testing.T, legacyTokenAuth bool) *PicoChannel {
+	t.Helper()
+
+	cfg := &config.PicoSettings{LegacyTokenAuth: legacyTokenAuth}
+	cfg.SetToken("test-token")
+	cfg.Secret = *config.NewSecureString("shared-secret")
+	ch, err := NewPicoChannel(&config.Channel{Type: config.ChannelPico, Enabled: true}, cfg, bus.NewMessageBus())
+	if err != nil {
+		t.Fatalf("NewPicoChannel: %v", err)
+	}
+	return ch
+}
+
+func TestVerifyHandshake(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	validTS := strconv.FormatInt(now.Unix(), 10)
+	validSig := SignHandshake("shared-secret", now.Unix())
+	staleTS := now.Add(-2 * hmacMaxClockSkew).Unix()
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		wantErr   error
+	}{
+		{name: "valid", timestamp: validTS, signature: validSig},
+		{name: "within skew", timestamp: strconv.FormatInt(now.Unix()-30, 10),
+			signature: SignHandshake("shared-secret", now.Unix()-30)},
+		{name: "expired", timestamp: strconv.FormatInt(staleTS, 10),
+			signature: SignHandshake("shared-secret", staleTS), wantErr: errHandshakeExpired},
+		{name: "tampered timestamp", timestamp: strconv.FormatInt(now.Unix()+1, 10),
+			signature: validSig, wantErr: errHandshakeSignature},
+		{name: "wrong secret", timestamp: validTS,
+			signature: SignHandshake("other-secret", now.Unix()), wantErr: errHandshakeSignature},
+		{name: "malformed", timestamp: "soon", signature: validSig, wantErr: errHandshakeMalformed},
+		{name: "missing", timestamp: validTS, wantErr: errHandshakeMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifyHandshake("shared-secret", tt.timestamp, tt.signature, now); err != tt.wantErr {
+				t.Fatalf("verifyHandshake() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_HMACHandshake(t *testing.T) {
+	ch := newHMACTestPicoChannel(t, false)
+	ts := time.Now().Unix()
+
+	req := httptest.NewRequest("GET", "/pico/ws", nil)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(SignatureHeader, SignHandshake("shared-secret", ts))
+	if !ch.authenticate(req) {
+		t.Fatal("authenticate() rejected a valid header handshake")
+	}
+
+	proto := "hmac." + strconv.FormatInt(ts, 10) + "." + SignHandshake("shared-secret", ts)
+	req = httptest.NewRequest("GET", "/pico/ws", nil)
+	req.Header.Set("Sec-WebSocket-Protocol", proto)
+	if !ch.authenticate(req) {
+		t.Fatal("authenticate() rejected a valid subprotocol handshake")
+	}
+	if got := ch.matchedSubprotocol(req); got != proto {
+		t.Fatalf("matchedSubprotocol() = %q, want %q", got, proto)
+	}
+
+	req = httptest.NewRequest("GET", "/pico/ws", nil)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(SignatureHeader, SignHandshake("shared-secret", ts+1))
+	if ch.authenticate(req) {
+		t.Fatal("authenticate() accepted a tampered handshake")
+	}
+}
+
+func TestAuthenticate_LegacyTokenRequiresFlagWhenSecretSet(t *testing.T) {
+	req := httptest.NewRequest("GET", "/pico/ws", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	if newHMACTestPicoChannel(t, false).authenticate(req) {
+		t.Fatal("authenticate() accepted a bearer token without legacy_token_auth")
+	}
+	if !newHMACTestPicoChannel(t, true).authenticate(req) {
+		t.Fatal("authenticate() rejected a bearer token with legacy_token_auth")
+	}
+}
@@ -113,8 +113,8 @@ func NewPicoChannel(
 	cfg *config.PicoSettings,
 	messageBus *bus.MessageBus,
 ) (*PicoChannel, error) {
-	if cfg.Token.String() == "" {
-		return nil, fmt.Errorf("pico token is required")
+	if cfg.Token.String() == "" && cfg.Secret.String() == "" {
+		return nil, fmt.Errorf("pico token or secret is required")
 	}
 
 	base := channels.NewBaseChannel("pico", cfg, messageBus, bc.AllowFrom)
@@ -1018,11 +1018,24 @@ func (c *PicoChannel) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	go c.readLoop(pc)
 }
 
-// authenticate checks the request for a valid token:
+// authenticate checks the request credentials. With a secret configured the
+// request must carry a valid HMAC handshake (see verifyHMACRequest); plain
+// tokens are only accepted when no secret is set or LegacyTokenAuth is on:
 //  1. Authorization: Bearer <token> header
 //  2. Sec-WebSocket-Protocol "token.<value>" (for browsers that can't set headers)
 //  3. Query parameter "token" (only when AllowTokenQuery is on)
 func (c *PicoChannel) authenticate(r *http.Request) bool {
+	if secret := c.config.Secret.String(); secret != "" {
+		_, err := verifyHMACRequest(r, secret, time.Now())
+		if err == nil {
+			return true
+		}
+		if !c.config.LegacyTokenAuth {
+			logger.DebugCF("pico", "HMAC handshake rejected", map[string]any{"error": err.Error()})
+			return false
+		}
+	}
+
 	token := c.config.Token.String()
 	if token == "" {
 		return false
@@ -1037,7 +1050,7 @@ func (c *PicoChannel) authenticate(r *http.Request) bool {
 	}
 
 	// Check Sec-WebSocket-Protocol subprotocol ("token.<value>")
-	if c.matchedSubprotocol(r) != "" {
+	if c.matchedTokenSubprotocol(r) != "" {
 		return true
 	}
 
@@ -1051,10 +1064,27 @@ func (c *PicoChannel) authenticate(r *http.Request) bool {
 	return false
 }
 
-// matchedSubprotocol returns the "token.<value>" subprotocol that matches
-// the configured token, or "" if none do.
+// matchedSubprotocol returns the credential-bearing subprotocol ("hmac.…" or
+// "token.<value>") that authenticated the request, or "" if none did.
 func (c *PicoChannel) matchedSubprotocol(r *http.Request) string {
+	if secret := c.config.Secret.String(); secret != "" {
+		if proto, err := verifyHMACRequest(r, secret, time.Now()); err == nil {
+			return proto
+		}
+		if !c.config.LegacyTokenAuth {
+			return ""
+		}
+	}
+	return c.matchedTokenSubprotocol(r)
+}
+
+// matchedTokenSubprotocol returns the "token.<value>" subprotocol that matches
+// the configured token, or "" if none do.
+func (c *PicoChannel) matchedTokenSubprotocol(r *http.Request) string {
 	token := c.config.Token.String()
+	if token == "" {
+		return ""
+	}
 	for _, proto := range websocket.Subprotocols(r) {
 		if after, ok := strings.CutPrefix(proto, "token."); ok && after == token {
 			return proto
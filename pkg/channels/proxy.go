@@ -0,0 +1,68 @@
+package channels
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// ValidateProxyURL checks a channel's configured proxy. It returns the
+// trimmed URL when it is a usable http, https, socks5 or socks5h proxy.
+// Otherwise it logs a warning and returns "", so the channel starts without
+// the configured proxy (HTTP_PROXY/HTTPS_PROXY still apply) instead of
+// failing.
+func ValidateProxyURL(channel, proxy string) string {
+	proxy = strings.TrimSpace(proxy)
+	if proxy == "" {
+		return ""
+	}
+	u, err := url.Parse(proxy)
+	if err == nil && u.Host != "" {
+		switch strings.ToLower(u.Scheme) {
+		case "http", "https", "socks5", "socks5h":
+			return proxy
+		}
+	}
+	logger.WarnCF("channels", "Ignoring invalid proxy URL; expected http(s):// or socks5(h)://", map[string]any{
+		"channel": channel,
+		"proxy":   redactProxyURL(proxy),
+	})
+	return ""
+}
+
+// ProxyFunc returns the proxy selector for a proxy accepted by
+// ValidateProxyURL: the fixed URL when set, otherwise the environment proxy.
+func ProxyFunc(proxy string) func(*http.Request) (*url.URL, error) {
+	if proxy == "" {
+		return http.ProxyFromEnvironment
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return http.ProxyFromEnvironment
+	}
+	return http.ProxyURL(u)
+}
+
+// NewProxyHTTPClient returns an HTTP client whose transport routes through
+// proxy, or through the environment proxy when proxy is empty.
+func NewProxyHTTPClient(proxy string, timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = ProxyFunc(proxy)
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// redactProxyURL hides proxy credentials before they reach the logs.
+func redactProxyURL(proxy string) string {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		// The raw string may still carry credentials.
+		return "<invalid proxy URL>"
+	}
+	if u.User == nil {
+		return proxy
+	}
+	return u.Redacted()
+}
@@ -104,8 +104,20 @@ func (c *QQChannel) Start(ctx context.Context) error {
 		return fmt.Errorf("QQ app_id and app_secret not configured")
 	}
 
+	switch c.config.Mode {
+	case "", modeWebSocket, modeWebhook:
+	default:
+		return fmt.Errorf("QQ mode %q is invalid (expected %q or %q)", c.config.Mode, modeWebSocket, modeWebhook)
+	}
+
 	botgo.SetLogger(newBotGoLogger("botgo"))
-	logger.InfoC("qq", "Starting QQ bot (WebSocket mode)")
+	if c.webhookMode() {
+		logger.InfoCF("qq", "Starting QQ bot (webhook mode)", map[string]any{
+			"path": c.WebhookPath(),
+		})
+	} else {
+		logger.InfoC("qq", "Starting QQ bot (WebSocket mode)")
+	}
 
 	// Reinitialize shutdown signal for clean restart.
 	c.done = make(chan struct{})
@@ -135,6 +147,30 @@ func (c *QQChannel) Start(ctx context.Context) error {
 		c.handleGroupATMessage(),
 	)
 
+	// In webhook mode events are pushed to ServeHTTP, which dispatches them to
+	// the handlers registered above; no gateway connection is needed.
+	if !c.webhookMode() {
+		if err := c.startWebSocket(intent); err != nil {
+			return err
+		}
+	}
+
+	// start dedup janitor goroutine
+	go c.dedupJanitor()
+
+	// Pre-register reasoning_channel_id as group chat if configured,
+	// so outbound-only destinations are routed correctly.
+	if c.bc.ReasoningChannelID != "" {
+		c.chatType.Store(c.bc.ReasoningChannelID, "group")
+	}
+
+	c.SetRunning(true)
+	logger.InfoC("qq", "QQ bot started successfully")
+
+	return nil
+}
+
+func (c *QQChannel) startWebSocket(intent dto.Intent) error {
 	// get WebSocket endpoint
 	wsInfo, err := c.api.WS(c.ctx, nil, "")
 	if err != nil {
@@ -157,19 +193,6 @@ func (c *QQChannel) Start(ctx context.Context) error {
 			c.SetRunning(false)
 		}
 	}()
-
-	// start dedup janitor goroutine
-	go c.dedupJanitor()
-
-	// Pre-register reasoning_channel_id as group chat if configured,
-	// so outbound-only destinations are routed correctly.
-	if c.bc.ReasoningChannelID != "" {
-		c.chatType.Store(c.bc.ReasoningChannelID, "group")
-	}
-
-	c.SetRunning(true)
-	logger.InfoC("qq", "QQ bot started successfully")
-
 	return nil
 }
 
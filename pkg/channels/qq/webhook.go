@@ -0,0 +1,112 @@
+package qq
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/tencent-connect/botgo/dto"
+	"github.com/tencent-connect/botgo/event"
+	"github.com/tencent-connect/botgo/interaction/signature"
+	"github.com/tencent-connect/botgo/interaction/webhook"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	modeWebSocket = "websocket"
+	modeWebhook   = "webhook"
+
+	defaultWebhookPath = "/webhook/qq"
+	maxWebhookBodySize = 1 << 20
+)
+
+// webhookMode reports whether events arrive as HTTP callbacks instead of over
+// the WebSocket gateway.
+func (c *QQChannel) webhookMode() bool {
+	return c.config.Mode == modeWebhook
+}
+
+// WebhookPath returns the path for registering on the shared HTTP server.
+func (c *QQChannel) WebhookPath() string {
+	if c.config.WebhookPath != "" {
+		return c.config.WebhookPath
+	}
+	return defaultWebhookPath
+}
+
+// ServeHTTP handles QQ HTTP callbacks. Every request is signed with an
+// ed25519 key derived from the app secret; unsigned or tampered requests are
+// rejected before the payload is parsed. Dispatch events go through the same
+// handlers as the WebSocket gateway.
+func (c *QQChannel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !c.webhookMode() {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize+1))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxWebhookBodySize {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	secret := c.config.AppSecret.String()
+	if ok, err := signature.Verify(secret, r.Header, body); err != nil || !ok {
+		logger.WarnCF("qq", "Rejected webhook request with invalid signature", map[string]any{
+			"remote": r.RemoteAddr,
+		})
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	payload := &dto.WSPayload{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	payload.RawMessage = body
+	payload.Session = &dto.Session{AppID: c.config.AppID}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch payload.OPCode {
+	case dto.HTTPCallbackValidation:
+		// Callback URL verification: sign plain_token with the event
+		// timestamp to prove ownership of the app secret.
+		var req struct {
+			Data dto.WHValidationReq `json:"d"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || req.Data.PlainToken == "" {
+			http.Error(w, "invalid validation request", http.StatusBadRequest)
+			return
+		}
+		rsp := webhook.GenValidationACK(&req.Data, r.Header, secret)
+		if rsp == nil {
+			http.Error(w, "failed to sign validation response", http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(rsp)
+	case dto.WSDispatchEvent:
+		err := event.ParseAndHandle(payload)
+		if err != nil {
+			logger.WarnCF("qq", "Failed to handle webhook event", map[string]any{
+				"type":  string(payload.Type),
+				"error": err.Error(),
+			})
+		}
+		_, _ = io.WriteString(w, webhook.GenDispatchACK(err == nil))
+	case dto.WSHeartbeat:
+		seq, _ := payload.Data.(float64)
+		_, _ = io.WriteString(w, webhook.GenHeartbeatACK(uint32(seq)))
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,125 @@
+package qq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tencent-connect/botgo/event"
+	"github.com/tencent-connect/botgo/interaction/signature"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const testWebhookSecret = "naOC0ocQE3shWLAfffVLB1rhYPG7"
+
+func newWebhookTestChannel(t *testing.T, mode string) (*QQChannel, *bus.MessageBus) {
+	t.Helper()
+	messageBus := bus.NewMessageBus()
+	ch := &QQChannel{
+		BaseChannel: channels.NewBaseChannel("qq", nil, messageBus, nil),
+		config: &config.QQSettings{
+			AppID:     "11111111",
+			AppSecret: *config.NewSecureString(testWebhookSecret),
+			Mode:      mode,
+		},
+		dedup: make(map[string]time.Time),
+		done:  make(chan struct{}),
+		ctx:   context.Background(),
+	}
+	return ch, messageBus
+}
+
+func signedWebhookRequest(t *testing.T, secret, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, defaultWebhookPath, strings.NewReader(body))
+	req.Header.Set(signature.HeaderTimestamp, "1725442341")
+	sig, err := signature.Generate(secret, req.Header, []byte(body))
+	if err != nil {
+		t.Fatalf("signature.Generate() error = %v", err)
+	}
+	req.Header.Set(signature.HeaderSig, sig)
+	return req
+}
+
+func TestServeHTTP_RejectsInvalidSignature(t *testing.T) {
+	ch, _ := newWebhookTestChannel(t, modeWebhook)
+	body := `{"op":0,"t":"C2C_MESSAGE_CREATE","d":{"id":"m1","content":"hi","author":{"id":"u1"}}}`
+
+	for name, req := range map[string]*http.Request{
+		"unsigned":     httptest.NewRequest(http.MethodPost, defaultWebhookPath, strings.NewReader(body)),
+		"wrong secret": signedWebhookRequest(t, "some-other-secret-value-here", body),
+	} {
+		rec := httptest.NewRecorder()
+		ch.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want %d", name, rec.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestServeHTTP_AnswersCallbackValidation(t *testing.T) {
+	ch, _ := newWebhookTestChannel(t, modeWebhook)
+	body := `{"op":13,"d":{"plain_token":"Arq0D5A61EgUu4OxUvOp","event_ts":"1725442341"}}`
+
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, signedWebhookRequest(t, testWebhookSecret, body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	var rsp struct {
+		PlainToken string `json:"plain_token"`
+		Signature  string `json:"signature"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &rsp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if rsp.PlainToken != "Arq0D5A61EgUu4OxUvOp" {
+		t.Fatalf("plain_token = %q", rsp.PlainToken)
+	}
+	header := http.Header{}
+	header.Set(signature.HeaderTimestamp, "1725442341")
+	header.Set(signature.HeaderSig, rsp.Signature)
+	if ok, err := signature.Verify(testWebhookSecret, header, []byte(rsp.PlainToken)); err != nil || !ok {
+		t.Fatalf("validation signature does not verify: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestServeHTTP_DispatchesDirectMessage(t *testing.T) {
+	ch, messageBus := newWebhookTestChannel(t, modeWebhook)
+	event.RegisterHandlers(ch.handleC2CMessage(), ch.handleGroupATMessage())
+
+	body := `{"op":0,"id":"ev1","t":"C2C_MESSAGE_CREATE","d":{"id":"m1","content":"hello","author":{"id":"u1"}}}`
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, signedWebhookRequest(t, testWebhookSecret, body))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"d":0`) {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+		t.Fatal("timeout waiting for inbound message")
+	case inbound := <-messageBus.InboundChan():
+		if inbound.Content != "hello" || inbound.ChatID != "u1" {
+			t.Fatalf("inbound = %+v", inbound)
+		}
+	}
+}
+
+func TestServeHTTP_NotFoundInWebSocketMode(t *testing.T) {
+	ch, _ := newWebhookTestChannel(t, "")
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, signedWebhookRequest(t, testWebhookSecret, `{"op":0}`))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
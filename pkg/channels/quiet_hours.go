@@ -0,0 +1,133 @@
+package channels
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// quietHoursInterval is how often held proactive messages are checked for
+// release once quiet hours end.
+const quietHoursInterval = time.Minute
+
+// quietHold keeps proactive messages back during quiet hours, one merged
+// message per chat, in the order the chats were first held.
+type quietHold struct {
+	mu    sync.Mutex
+	held  map[string]bus.OutboundMessage // "channel:chatID" → merged message
+	order []string
+}
+
+// quietHours returns the configured window and the clock to test it against,
+// in the agent timezone.
+func (m *Manager) quietHours(now time.Time) (config.QuietHoursConfig, time.Time) {
+	m.mu.RLock()
+	cfg := m.config
+	m.mu.RUnlock()
+	if cfg == nil {
+		return config.QuietHoursConfig{}, now
+	}
+	defaults := cfg.Agents.Defaults
+	if tz := strings.TrimSpace(defaults.Timezone); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			now = now.In(loc)
+		}
+	}
+	return defaults.QuietHours, now
+}
+
+// holdDuringQuietHours reports whether msg was taken out of the outbound
+// flow because quiet hours are active. Only new proactive messages are held;
+// replies, edits and deletes always go through.
+func (m *Manager) holdDuringQuietHours(msg bus.OutboundMessage) bool {
+	if !msg.Proactive || outboundOperation(msg) != bus.OutboundSend {
+		return false
+	}
+	quiet, now := m.quietHours(time.Now())
+	if !quiet.Active(now) {
+		return false
+	}
+	channel, chatID := outboundMessageChannel(msg), outboundMessageChatID(msg)
+	if quiet.Drop() {
+		logger.InfoCF("channels", "Dropped proactive message during quiet hours", map[string]any{
+			"channel": channel,
+			"chat_id": chatID,
+		})
+		return true
+	}
+
+	key := channel + ":" + chatID
+	m.quiet.mu.Lock()
+	defer m.quiet.mu.Unlock()
+	if m.quiet.held == nil {
+		m.quiet.held = make(map[string]bus.OutboundMessage)
+	}
+	if prev, ok := m.quiet.held[key]; ok {
+		prev.Content = strings.TrimSpace(prev.Content + "\n\n" + msg.Content)
+		m.quiet.held[key] = prev
+	} else {
+		m.quiet.held[key] = msg
+		m.quiet.order = append(m.quiet.order, key)
+	}
+	logger.DebugCF("channels", "Holding proactive message until quiet hours end", map[string]any{
+		"channel": channel,
+		"chat_id": chatID,
+	})
+	return true
+}
+
+// flushQuietHours hands held messages to their channel workers once quiet
+// hours are over. It returns false if ctx ended before all were queued.
+func (m *Manager) flushQuietHours(ctx context.Context) bool {
+	quiet, now := m.quietHours(time.Now())
+	if quiet.Active(now) {
+		return true
+	}
+	m.quiet.mu.Lock()
+	order, held := m.quiet.order, m.quiet.held
+	m.quiet.order, m.quiet.held = nil, nil
+	m.quiet.mu.Unlock()
+
+	for _, key := range order {
+		msg := held[key]
+		channel := outboundMessageChannel(msg)
+		m.mu.RLock()
+		w := m.workers[channel]
+		m.mu.RUnlock()
+		if w == nil {
+			logger.WarnCF("channels", "Channel has no active worker, dropping held message", map[string]any{
+				"channel": channel,
+			})
+			continue
+		}
+		select {
+		case w.queue <- msg:
+			m.publishOutboundQueued(channel, msg)
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// runQuietHoursFlush releases held proactive messages after quiet hours end.
+func (m *Manager) runQuietHoursFlush(ctx context.Context) {
+	ticker := time.NewTicker(quietHoursInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !m.flushQuietHours(ctx) {
+				return
+			}
+		}
+	}
+}
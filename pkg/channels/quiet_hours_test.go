@@ -0,0 +1,86 @@
+package channels
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// quietWindow returns a window that is active at now when active is true and
+// inactive otherwise.
+func quietWindow(now time.Time, active bool, mode string) config.QuietHoursConfig {
+	if active {
+		return config.QuietHoursConfig{
+			Start: now.Add(-time.Hour).Format("15:04"),
+			End:   now.Add(time.Hour).Format("15:04"),
+			Mode:  mode,
+		}
+	}
+	return config.QuietHoursConfig{
+		Start: now.Add(2 * time.Hour).Format("15:04"),
+		End:   now.Add(3 * time.Hour).Format("15:04"),
+		Mode:  mode,
+	}
+}
+
+func newQuietHoursManager(quiet config.QuietHoursConfig) (*Manager, *channelWorker) {
+	m := newTestManager()
+	m.config = config.DefaultConfig()
+	m.config.Agents.Defaults.Timezone = "UTC"
+	m.config.Agents.Defaults.QuietHours = quiet
+	w := &channelWorker{queue: make(chan bus.OutboundMessage, 10)}
+	m.workers["test"] = w
+	return m, w
+}
+
+func TestQuietHours_HoldsAndMergesProactiveMessages(t *testing.T) {
+	now := time.Now().UTC()
+	m, w := newQuietHoursManager(quietWindow(now, true, ""))
+
+	reply := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "reply"})
+	if m.holdDuringQuietHours(reply) {
+		t.Fatal("replies to the user must not be held")
+	}
+	for _, content := range []string{"first", "second"} {
+		msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: content})
+		msg.Proactive = true
+		if !m.holdDuringQuietHours(msg) {
+			t.Fatalf("proactive message %q was not held", content)
+		}
+	}
+
+	// Still quiet: nothing is released.
+	m.flushQuietHours(context.Background())
+	if len(w.queue) != 0 {
+		t.Fatalf("queue len = %d during quiet hours, want 0", len(w.queue))
+	}
+
+	m.config.Agents.Defaults.QuietHours = quietWindow(now, false, "")
+	m.flushQuietHours(context.Background())
+	if len(w.queue) != 1 {
+		t.Fatalf("queue len = %d after quiet hours, want 1 merged message", len(w.queue))
+	}
+	got := <-w.queue
+	if got.Content != "first\n\nsecond" || got.ChatID != "1" {
+		t.Fatalf("released message = %+v", got)
+	}
+}
+
+func TestQuietHours_DropModeDiscards(t *testing.T) {
+	now := time.Now().UTC()
+	m, w := newQuietHoursManager(quietWindow(now, true, "drop"))
+
+	msg := testOutboundMessage(bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "ping"})
+	msg.Proactive = true
+	if !m.holdDuringQuietHours(msg) {
+		t.Fatal("proactive message was not dropped")
+	}
+	m.config.Agents.Defaults.QuietHours = quietWindow(now, false, "drop")
+	m.flushQuietHours(context.Background())
+	if len(w.queue) != 0 {
+		t.Fatalf("queue len = %d, dropped messages must not be released", len(w.queue))
+	}
+}
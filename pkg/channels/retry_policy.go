@@ -0,0 +1,61 @@
+package channels
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// maxRetryAfter caps a platform-requested Retry-After so one bad header
+// cannot stall a channel worker for hours.
+const maxRetryAfter = 5 * time.Minute
+
+// sendRetryPolicy is the resolved retry configuration for one channel.
+type sendRetryPolicy struct {
+	maxRetries  int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	deadLetter  bool
+}
+
+// retryPolicy returns the channel's retry settings from its "retry" config,
+// falling back to the package defaults for unset values.
+func (m *Manager) retryPolicy(name string) sendRetryPolicy {
+	p := sendRetryPolicy{
+		maxRetries:  maxRetries,
+		baseBackoff: baseBackoff,
+		maxBackoff:  maxBackoff,
+	}
+	if m.config == nil {
+		return p
+	}
+	bc := m.config.Channels.Get(name)
+	if bc == nil {
+		return p
+	}
+	if r := bc.Retry.MaxRetries; r != nil && *r >= 0 {
+		p.maxRetries = *r
+	}
+	if bc.Retry.BaseBackoffMs > 0 {
+		p.baseBackoff = time.Duration(bc.Retry.BaseBackoffMs) * time.Millisecond
+	}
+	if bc.Retry.MaxBackoffMs > 0 {
+		p.maxBackoff = time.Duration(bc.Retry.MaxBackoffMs) * time.Millisecond
+	}
+	p.maxBackoff = max(p.maxBackoff, p.baseBackoff)
+	p.deadLetter = bc.Retry.DeadLetter
+	return p
+}
+
+// retryDelay returns how long to wait before retrying after err on the given
+// attempt. A Retry-After attached by the channel wins; otherwise rate limits
+// wait a fixed delay and other errors back off exponentially.
+func (p sendRetryPolicy) retryDelay(err error, attempt int) time.Duration {
+	if after, ok := RetryAfter(err); ok {
+		return min(after, maxRetryAfter)
+	}
+	if errors.Is(err, ErrRateLimit) {
+		return rateLimitDelay
+	}
+	return min(time.Duration(float64(p.baseBackoff)*math.Pow(2, float64(attempt))), p.maxBackoff)
+}
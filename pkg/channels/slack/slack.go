@@ -6,6 +6,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/gorilla/websocket"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
@@ -47,12 +48,17 @@ func NewSlackChannel(
 		return nil, fmt.Errorf("slack bot_token and app_token are required")
 	}
 
+	proxy := channels.ValidateProxyURL(bc.Name(), cfg.Proxy)
 	api := slack.New(
 		cfg.BotToken.String(),
 		slack.OptionAppLevelToken(cfg.AppToken.String()),
+		slack.OptionHTTPClient(channels.NewProxyHTTPClient(proxy, 0)),
 	)
 
-	socketClient := socketmode.New(api)
+	socketClient := socketmode.New(api, socketmode.OptionDialer(&websocket.Dialer{
+		Proxy:            channels.ProxyFunc(proxy),
+		HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout,
+	}))
 
 	base := channels.NewBaseChannel("slack", cfg, messageBus, bc.AllowFrom,
 		channels.WithMaxMessageLength(40000),
@@ -247,6 +253,31 @@ func slackFirstMediaCaption(parts []bus.MediaPart) string {
 	return ""
 }
 
+// EditMessage implements channels.MessageEditor.
+func (c *SlackChannel) EditMessage(ctx context.Context, chatID, messageID, content string) error {
+	channelID, _ := parseSlackChatID(chatID)
+	if channelID == "" {
+		return fmt.Errorf("invalid slack chat ID: %s", chatID)
+	}
+	_, _, _, err := c.api.UpdateMessageContext(ctx, channelID, messageID, slack.MsgOptionText(content, false))
+	if err != nil {
+		return fmt.Errorf("slack edit: %w", channels.ErrTemporary)
+	}
+	return nil
+}
+
+// DeleteMessage implements channels.MessageDeleter.
+func (c *SlackChannel) DeleteMessage(ctx context.Context, chatID, messageID string) error {
+	channelID, _ := parseSlackChatID(chatID)
+	if channelID == "" {
+		return fmt.Errorf("invalid slack chat ID: %s", chatID)
+	}
+	if _, _, err := c.api.DeleteMessageContext(ctx, channelID, messageID); err != nil {
+		return fmt.Errorf("slack delete: %w", channels.ErrTemporary)
+	}
+	return nil
+}
+
 // ReactToMessage implements channels.ReactionCapable.
 // It adds an "eyes" (👀) reaction to the inbound message and returns an undo function
 // that removes the reaction.
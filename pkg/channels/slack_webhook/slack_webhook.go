@@ -161,7 +161,7 @@ func (c *SlackWebhookChannel) Send(ctx context.Context, msg bus.OutboundMessage)
 			"response": respText,
 		})
 		sendErr := fmt.Errorf("status %d: %s", resp.StatusCode, respText)
-		return nil, fmt.Errorf("slack_webhook: %w", channels.ClassifySendError(resp.StatusCode, sendErr))
+		return nil, fmt.Errorf("slack_webhook: %w", channels.ClassifySendResponse(resp, sendErr))
 	}
 
 	logger.DebugCF("slack_webhook", "Message sent successfully", map[string]any{
@@ -0,0 +1,334 @@
+package sms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	twilioAPIBase      = "https://api.twilio.com/2010-04-01"
+	defaultWebhookPath = "/webhook/sms"
+
+	// Twilio concatenates long messages up to 1600 characters; longer
+	// replies are split by the channel manager.
+	smsMaxMessageLength = 1600
+
+	// Twilio form payloads are a few KB even with MMS media URLs.
+	maxWebhookBodySize = 64 << 10
+
+	// emptyTwiML acknowledges a webhook without sending an immediate reply;
+	// the agent's answer is sent later through the REST API.
+	emptyTwiML = `<?xml version="1.0" encoding="UTF-8"?><Response></Response>`
+)
+
+// SMSChannel implements the Channel interface for SMS and MMS via Twilio.
+// Inbound messages arrive on a webhook mounted on the shared HTTP server;
+// replies are sent through the Twilio Messages REST API.
+type SMSChannel struct {
+	*channels.BaseChannel
+	config  *config.SMSSettings
+	client  *http.Client
+	proxy   string
+	apiBase string
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewSMSChannel creates a new Twilio SMS channel instance.
+func NewSMSChannel(bc *config.Channel, cfg *config.SMSSettings, messageBus *bus.MessageBus) (*SMSChannel, error) {
+	if cfg.AccountSID == "" || cfg.AuthToken.String() == "" || cfg.FromNumber == "" {
+		return nil, fmt.Errorf("sms account_sid, auth_token and from_number are required")
+	}
+
+	base := channels.NewBaseChannel("sms", cfg, messageBus, bc.AllowFrom,
+		channels.WithMaxMessageLength(smsMaxMessageLength),
+		channels.WithReasoningChannelID(bc.ReasoningChannelID),
+	)
+	proxy := channels.ValidateProxyURL(bc.Name(), cfg.Proxy)
+
+	return &SMSChannel{
+		BaseChannel: base,
+		config:      cfg,
+		client:      channels.NewProxyHTTPClient(proxy, 30*time.Second),
+		proxy:       proxy,
+		apiBase:     twilioAPIBase,
+	}, nil
+}
+
+// Start initializes the SMS channel.
+func (c *SMSChannel) Start(ctx context.Context) error {
+	logger.InfoCF("sms", "Starting SMS channel (Twilio webhook)", map[string]any{
+		"path": c.WebhookPath(),
+	})
+	c.ctx, c.cancel = context.WithCancel(ctx)
+	c.SetRunning(true)
+	return nil
+}
+
+// Stop gracefully stops the SMS channel.
+func (c *SMSChannel) Stop(ctx context.Context) error {
+	logger.InfoC("sms", "Stopping SMS channel")
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.SetRunning(false)
+	return nil
+}
+
+// WebhookPath returns the path for registering on the shared HTTP server.
+func (c *SMSChannel) WebhookPath() string {
+	if c.config.WebhookPath != "" {
+		return c.config.WebhookPath
+	}
+	return defaultWebhookPath
+}
+
+// ServeHTTP handles Twilio's inbound message webhook. Requests must carry a
+// valid X-Twilio-Signature for the configured auth token.
+func (c *SMSChannel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBodySize)
+	if err := r.ParseForm(); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Request entity too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	expected := twilioSignature(c.config.AuthToken.String(), c.webhookURL(r), r.PostForm)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get("X-Twilio-Signature"))) {
+		logger.WarnC("sms", "Invalid Twilio webhook signature")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	_, _ = io.WriteString(w, emptyTwiML)
+
+	go c.processMessage(r.PostForm)
+}
+
+// webhookURL returns the URL Twilio signed. Behind a reverse proxy the
+// request URL differs from the public one, so webhook_url takes precedence.
+func (c *SMSChannel) webhookURL(r *http.Request) string {
+	if c.config.WebhookURL != "" {
+		return c.config.WebhookURL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	host := r.Host
+	if fwd := r.Header.Get("X-Forwarded-Host"); fwd != "" {
+		host = fwd
+	}
+	return scheme + "://" + host + r.URL.RequestURI()
+}
+
+// twilioSignature computes Twilio's request signature: HMAC-SHA1 over the
+// full URL followed by each POST parameter name and value, sorted by name.
+func twilioSignature(authToken, requestURL string, params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(requestURL)
+	for _, k := range keys {
+		for _, v := range params[k] {
+			b.WriteString(k)
+			b.WriteString(v)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(b.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (c *SMSChannel) processMessage(form url.Values) {
+	from := form.Get("From")
+	if from == "" {
+		return
+	}
+	messageSID := form.Get("MessageSid")
+
+	sender := bus.SenderInfo{
+		Platform:    "sms",
+		PlatformID:  from,
+		CanonicalID: identity.BuildCanonicalID("sms", from),
+	}
+	if !c.IsAllowedSender(sender) {
+		logger.DebugCF("sms", "Message rejected by allowlist", map[string]any{
+			"from": from,
+		})
+		return
+	}
+
+	content := strings.TrimSpace(form.Get("Body"))
+	var mediaPaths []string
+	numMedia, _ := strconv.Atoi(form.Get("NumMedia"))
+	for i := 0; i < numMedia; i++ {
+		mediaURL := form.Get(fmt.Sprintf("MediaUrl%d", i))
+		if mediaURL == "" {
+			continue
+		}
+		kind := mediaKind(form.Get(fmt.Sprintf("MediaContentType%d", i)))
+		filename := fmt.Sprintf("%s-%d", kind, i)
+		if localPath := c.downloadMedia(mediaURL, filename); localPath != "" {
+			scope := channels.BuildMediaScope("sms", from, messageSID)
+			mediaPaths = append(mediaPaths, c.storeMedia(localPath, filename, scope))
+			content = strings.TrimSpace(content + "\n[" + kind + "]")
+		}
+	}
+
+	if content == "" {
+		return
+	}
+
+	logger.DebugCF("sms", "Received message", map[string]any{
+		"from":    from,
+		"media":   len(mediaPaths),
+		"preview": utils.Truncate(content, 50),
+	})
+
+	inboundCtx := bus.InboundContext{
+		Channel:   c.Name(),
+		ChatID:    from,
+		ChatType:  "direct",
+		SenderID:  from,
+		MessageID: messageSID,
+		Raw: map[string]string{
+			"platform": "sms",
+			"to":       form.Get("To"),
+		},
+	}
+
+	c.HandleInboundContext(c.ctx, from, content, mediaPaths, inboundCtx, sender)
+}
+
+func mediaKind(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return "image"
+	case strings.HasPrefix(contentType, "audio/"):
+		return "audio"
+	case strings.HasPrefix(contentType, "video/"):
+		return "video"
+	default:
+		return "file"
+	}
+}
+
+// downloadMedia fetches an MMS attachment. Twilio media URLs require the
+// account credentials unless the account allows public media access.
+func (c *SMSChannel) downloadMedia(mediaURL, filename string) string {
+	credentials := base64.StdEncoding.EncodeToString(
+		[]byte(c.config.AccountSID + ":" + c.config.AuthToken.String()),
+	)
+	return utils.DownloadFile(mediaURL, filename, utils.DownloadOptions{
+		LoggerPrefix: "sms",
+		ProxyURL:     c.proxy,
+		ExtraHeaders: map[string]string{"Authorization": "Basic " + credentials},
+	})
+}
+
+func (c *SMSChannel) storeMedia(localPath, filename, scope string) string {
+	if store := c.GetMediaStore(); store != nil {
+		ref, err := store.Store(localPath, media.MediaMeta{
+			Filename: filename,
+			Source:   "sms",
+		}, scope)
+		if err == nil {
+			return ref
+		}
+	}
+	return localPath
+}
+
+// Send delivers a text message to a phone number through the Twilio API.
+func (c *SMSChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
+	if !c.IsRunning() {
+		return nil, channels.ErrNotRunning
+	}
+	if strings.TrimSpace(msg.Content) == "" {
+		return nil, nil
+	}
+
+	form := url.Values{}
+	form.Set("To", msg.ChatID)
+	form.Set("From", c.config.FromNumber)
+	form.Set("Body", msg.Content)
+
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", c.apiBase, url.PathEscape(c.config.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", channels.ErrSendFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.config.AccountSID, c.config.AuthToken.String())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, channels.ClassifyNetError(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var result struct {
+		SID     string `json:"sid"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(body, &result)
+
+	if resp.StatusCode >= 300 {
+		detail := result.Message
+		if detail == "" {
+			detail = strings.TrimSpace(string(body))
+		}
+		return nil, channels.ClassifySendResponse(resp,
+			fmt.Errorf("twilio API error %d (code %d): %s", resp.StatusCode, result.Code, detail))
+	}
+
+	logger.DebugCF("sms", "Message sent", map[string]any{
+		"to":  msg.ChatID,
+		"sid": result.SID,
+	})
+	if result.SID == "" {
+		return nil, nil
+	}
+	return []string{result.SID}, nil
+}
@@ -0,0 +1,183 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newTestChannel(t *testing.T, allowFrom ...string) (*SMSChannel, *bus.MessageBus) {
+	t.Helper()
+	messageBus := bus.NewMessageBus()
+	bc := &config.Channel{Type: config.ChannelSMS, AllowFrom: allowFrom}
+	ch, err := NewSMSChannel(bc, &config.SMSSettings{
+		AccountSID: "AC123",
+		AuthToken:  *config.NewSecureString("12345"),
+		FromNumber: "+15005550006",
+		WebhookURL: "https://example.com/webhook/sms",
+	}, messageBus)
+	if err != nil {
+		t.Fatalf("NewSMSChannel() error = %v", err)
+	}
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	return ch, messageBus
+}
+
+func signedRequest(t *testing.T, token, signedURL string, form url.Values) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/webhook/sms", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Twilio-Signature", twilioSignature(token, signedURL, form))
+	return req
+}
+
+func TestTwilioSignature_MatchesDocumentedExample(t *testing.T) {
+	params := url.Values{
+		"CallSid": {"CA1234567890ABCDE"},
+		"Caller":  {"+12349013030"},
+		"Digits":  {"1234"},
+		"From":    {"+12349013030"},
+		"To":      {"+18005551212"},
+	}
+	got := twilioSignature("12345", "https://mycompany.com/myapp.php?foo=1&bar=2", params)
+	if got != "0/KCTR6DLpKmkAf8muzZqo1nDgQ=" {
+		t.Fatalf("twilioSignature() = %q", got)
+	}
+}
+
+func TestServeHTTP_PublishesInboundMessage(t *testing.T) {
+	ch, messageBus := newTestChannel(t)
+	form := url.Values{
+		"From":       {"+14155550100"},
+		"To":         {"+15005550006"},
+		"Body":       {"  what's on my calendar?  "},
+		"MessageSid": {"SM1"},
+		"NumMedia":   {"0"},
+	}
+
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, signedRequest(t, "12345", "https://example.com/webhook/sms", form))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<Response>") {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+		t.Fatal("timeout waiting for inbound message")
+	case inbound := <-messageBus.InboundChan():
+		if inbound.ChatID != "+14155550100" || inbound.Content != "what's on my calendar?" {
+			t.Fatalf("inbound = %+v", inbound)
+		}
+		if inbound.Context.ChatType != "direct" || inbound.Context.MessageID != "SM1" {
+			t.Fatalf("inbound context = %+v", inbound.Context)
+		}
+	}
+}
+
+func TestServeHTTP_RejectsBadSignature(t *testing.T) {
+	ch, _ := newTestChannel(t)
+	form := url.Values{"From": {"+14155550100"}, "Body": {"hi"}}
+
+	for name, req := range map[string]*http.Request{
+		"wrong token": signedRequest(t, "other-token", "https://example.com/webhook/sms", form),
+		"wrong url":   signedRequest(t, "12345", "https://attacker.example/webhook/sms", form),
+	} {
+		rec := httptest.NewRecorder()
+		ch.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: status = %d, want %d", name, rec.Code, http.StatusForbidden)
+		}
+	}
+}
+
+func TestServeHTTP_DropsSendersOutsideAllowlist(t *testing.T) {
+	ch, messageBus := newTestChannel(t, "+14155550199")
+	form := url.Values{"From": {"+14155550100"}, "Body": {"hi"}, "MessageSid": {"SM2"}}
+
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, signedRequest(t, "12345", "https://example.com/webhook/sms", form))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+
+	select {
+	case inbound := <-messageBus.InboundChan():
+		t.Fatalf("unexpected inbound message: %+v", inbound)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSend_PostsToTwilioAPI(t *testing.T) {
+	var gotForm url.Values
+	var gotUser, gotPass, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotUser, gotPass, _ = r.BasicAuth()
+		_ = r.ParseForm()
+		gotForm = r.PostForm
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"SM42","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	ch, _ := newTestChannel(t)
+	ch.apiBase = server.URL
+
+	ids, err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "+14155550100", Content: "hello"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "SM42" {
+		t.Fatalf("ids = %v", ids)
+	}
+	if gotPath != "/Accounts/AC123/Messages.json" || gotUser != "AC123" || gotPass != "12345" {
+		t.Fatalf("path = %q, auth = %q:%q", gotPath, gotUser, gotPass)
+	}
+	if gotForm.Get("To") != "+14155550100" || gotForm.Get("From") != "+15005550006" || gotForm.Get("Body") != "hello" {
+		t.Fatalf("form = %v", gotForm)
+	}
+}
+
+func TestSend_ClassifiesAPIErrors(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		want   error
+	}{
+		{http.StatusBadRequest, channels.ErrSendFailed},
+		{http.StatusTooManyRequests, channels.ErrRateLimit},
+		{http.StatusServiceUnavailable, channels.ErrTemporary},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			_, _ = w.Write([]byte(`{"code":21211,"message":"Invalid 'To' Phone Number"}`))
+		}))
+		ch, _ := newTestChannel(t)
+		ch.apiBase = server.URL
+
+		_, err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "+1", Content: "hello"})
+		server.Close()
+		if !errors.Is(err, tc.want) {
+			t.Errorf("status %d: err = %v, want %v", tc.status, err, tc.want)
+		}
+	}
+}
+
+func TestNewSMSChannel_RequiresCredentials(t *testing.T) {
+	_, err := NewSMSChannel(&config.Channel{Type: config.ChannelSMS}, &config.SMSSettings{AccountSID: "AC123"}, bus.NewMessageBus())
+	if err == nil {
+		t.Fatal("expected error for missing auth_token and from_number")
+	}
+}
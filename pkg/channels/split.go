@@ -42,10 +42,7 @@ func SplitMessage(content string, maxLen int) []string {
 		end := start + effectiveLimit
 
 		// Find natural split point within the effective limit
-		msgEnd := findLastNewlineInRange(runes, start, end, 200)
-		if msgEnd <= start {
-			msgEnd = findLastSpaceInRange(runes, start, end, 100)
-		}
+		msgEnd := findNaturalSplitPoint(runes, start, end)
 		if msgEnd <= start {
 			msgEnd = end
 		}
@@ -139,6 +136,22 @@ func SplitMessage(content string, maxLen int) []string {
 	return messages
 }
 
+// findNaturalSplitPoint picks where a chunk covering runes[start:end] should end,
+// preferring a paragraph break, then a line break, then the end of a sentence,
+// then a space. Returns start-1 if none is found, leaving a hard cut to the caller.
+func findNaturalSplitPoint(runes []rune, start, end int) int {
+	if idx := findLastParagraphBreakInRange(runes, start, end, (end-start)/2); idx > start {
+		return idx
+	}
+	if idx := findLastNewlineInRange(runes, start, end, 200); idx > start {
+		return idx
+	}
+	if idx := findLastSentenceEndInRange(runes, start, end, 200); idx > start {
+		return idx
+	}
+	return findLastSpaceInRange(runes, start, end, 100)
+}
+
 // findLastUnclosedCodeBlockInRange finds the last opening ``` that doesn't have a closing ```
 // within runes[start:end]. Returns the absolute rune index or -1.
 func findLastUnclosedCodeBlockInRange(runes []rune, start, end int) int {
@@ -206,3 +219,35 @@ func findLastSpaceInRange(runes []rune, start, end, searchWindow int) int {
 	}
 	return start - 1
 }
+
+// findLastParagraphBreakInRange finds the last blank line ("\n\n") within the last
+// searchWindow runes of runes[start:end]. Returns the index of its first newline
+// or start-1 (indicating not found).
+func findLastParagraphBreakInRange(runes []rune, start, end, searchWindow int) int {
+	searchStart := max(end-searchWindow, start)
+	for i := end - 2; i >= searchStart; i-- {
+		if runes[i] == '\n' && runes[i+1] == '\n' {
+			return i
+		}
+	}
+	return start - 1
+}
+
+// findLastSentenceEndInRange finds the last sentence-ending punctuation within the
+// last searchWindow runes of runes[start:end]. Latin punctuation must be followed
+// by whitespace so that "3.14" or "example.com" are not split. Returns the index
+// just after the punctuation or start-1 (indicating not found).
+func findLastSentenceEndInRange(runes []rune, start, end, searchWindow int) int {
+	searchStart := max(end-searchWindow, start)
+	for i := end - 1; i >= searchStart; i-- {
+		switch runes[i] {
+		case '。', '！', '？':
+			return i + 1
+		case '.', '!', '?':
+			if i+1 < len(runes) && (runes[i+1] == ' ' || runes[i+1] == '\t') {
+				return i + 1
+			}
+		}
+	}
+	return start - 1
+}
@@ -360,3 +360,44 @@ func TestSplitMessage_CodeBlockIntegrity(t *testing.T) {
 		t.Errorf("First chunk exceeded maxLen: length %d runes", len([]rune(chunks[0])))
 	}
 }
+
+func TestSplitMessage_PrefersParagraphThenSentenceBoundaries(t *testing.T) {
+	first := strings.Repeat("word ", 30) + "end.\nNext line " + strings.Repeat("x", 10)
+	content := first + "\n\n" + strings.Repeat("second paragraph ", 10)
+
+	chunks := SplitMessage(content, 300)
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 chunks, got %d: %q", len(chunks), chunks)
+	}
+	if chunks[0] != first {
+		t.Errorf("First chunk should end at the paragraph break.\nGot:  %q\nWant: %q", chunks[0], first)
+	}
+
+	sentences := strings.Repeat("This is a sentence. ", 10) + strings.Repeat("tail ", 20)
+	chunks = SplitMessage(sentences, 150)
+	if !strings.HasSuffix(chunks[0], ".") {
+		t.Errorf("First chunk should end at a sentence boundary. Got: %q", chunks[0])
+	}
+}
+
+func TestFindLastSentenceEndInRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"latin sentence", "Hi there. More", 9},
+		{"decimal is not a sentence end", "pi is 3.14 ok", -1},
+		{"cjk sentence", "你好。世界", 3},
+		{"question", "Why? Because", 4},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runes := []rune(tc.content)
+			if got := findLastSentenceEndInRange(runes, 0, len(runes), 200); got != tc.want {
+				t.Errorf("findLastSentenceEndInRange(%q) = %d, want %d", tc.content, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package telegram
+
+import (
+	"context"
+	"time"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const longPollTimeoutSeconds = 30
+
+var (
+	pollRetryInitial    = time.Second
+	pollRetryMax        = time.Minute
+	pollRetryMultiplier = 2.0
+)
+
+// pollUpdates long-polls getUpdates and forwards updates to out until ctx is
+// done, then closes out. It replaces telego's built-in poller, which retries
+// on a fixed interval and forgets its offset when restarted: here failed
+// polls back off exponentially, and polling always resumes after the last
+// update handed to the bot handler, so a network blip or channel restart
+// neither replays nor skips updates.
+func (c *TelegramChannel) pollUpdates(ctx context.Context, out chan<- telego.Update) {
+	defer close(out)
+
+	backoff := pollRetryInitial
+	failures := 0
+	var disconnectedAt time.Time
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		updates, err := c.bot.GetUpdates(ctx, &telego.GetUpdatesParams{
+			Offset:  int(c.nextUpdateID.Load()),
+			Timeout: longPollTimeoutSeconds,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if failures == 0 {
+				disconnectedAt = time.Now()
+				logger.WarnCF("telegram", "Telegram long polling lost connection, reconnecting", map[string]any{
+					"error": err.Error(),
+				})
+			} else {
+				logger.DebugCF("telegram", "Telegram long polling retry failed", map[string]any{
+					"attempt": failures,
+					"error":   err.Error(),
+				})
+			}
+			failures++
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(time.Duration(float64(backoff)*pollRetryMultiplier), pollRetryMax)
+			continue
+		}
+
+		if failures > 0 {
+			logger.InfoCF("telegram", "Telegram long polling reconnected", map[string]any{
+				"attempts":      failures,
+				"downtime":      time.Since(disconnectedAt).Round(time.Second).String(),
+				"resume_offset": c.nextUpdateID.Load(),
+			})
+			failures = 0
+			backoff = pollRetryInitial
+		}
+
+		for _, update := range updates {
+			if !c.markUpdateDelivered(update.UpdateID) {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- update.WithContext(ctx):
+			}
+		}
+	}
+}
+
+// markUpdateDelivered advances the polling offset past updateID. It reports
+// false for updates at or before the current offset, which Telegram can
+// resend after a reconnect.
+func (c *TelegramChannel) markUpdateDelivered(updateID int) bool {
+	next := int64(updateID) + 1
+	for {
+		current := c.nextUpdateID.Load()
+		if next <= current {
+			return false
+		}
+		if c.nextUpdateID.CompareAndSwap(current, next) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func updatesResponse(t *testing.T, ids ...int) *ta.Response {
+	t.Helper()
+	updates := make([]telego.Update, 0, len(ids))
+	for _, id := range ids {
+		updates = append(updates, telego.Update{UpdateID: id})
+	}
+	b, err := json.Marshal(updates)
+	require.NoError(t, err)
+	return &ta.Response{Ok: true, Result: b}
+}
+
+func TestPollUpdates_ResumesFromLastOffsetAfterDisconnect(t *testing.T) {
+	origInitial := pollRetryInitial
+	pollRetryInitial = time.Millisecond
+	t.Cleanup(func() { pollRetryInitial = origInitial })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var offsets []int
+	caller := &stubCaller{
+		callFn: func(ctx context.Context, url string, data *ta.RequestData) (*ta.Response, error) {
+			if !strings.Contains(url, "getUpdates") {
+				t.Errorf("unexpected API call: %s", url)
+			}
+			var params telego.GetUpdatesParams
+			if err := json.Unmarshal(data.BodyRaw, &params); err != nil {
+				return nil, err
+			}
+			offsets = append(offsets, params.Offset)
+
+			switch len(offsets) {
+			case 1:
+				return updatesResponse(t, 1, 2), nil
+			case 2, 3:
+				return nil, errors.New("connection reset by peer")
+			case 4:
+				// Telegram resends an update the bot already received.
+				return updatesResponse(t, 2, 3), nil
+			default:
+				cancel()
+				return nil, ctx.Err()
+			}
+		},
+	}
+	ch := newTestChannel(t, caller)
+
+	out := make(chan telego.Update, 10)
+	done := make(chan struct{})
+	go func() {
+		ch.pollUpdates(ctx, out)
+		close(done)
+	}()
+
+	var got []int
+	for update := range out {
+		got = append(got, update.UpdateID)
+	}
+	<-done
+
+	assert.Equal(t, []int{1, 2, 3}, got, "each update must be delivered exactly once")
+	assert.Equal(t, []int{0, 3, 3, 3, 4}, offsets, "polling must resume after the last delivered update")
+}
+
+func TestPollUpdates_OffsetSurvivesRestart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var offsets []int
+	caller := &stubCaller{
+		callFn: func(ctx context.Context, url string, data *ta.RequestData) (*ta.Response, error) {
+			var params telego.GetUpdatesParams
+			if err := json.Unmarshal(data.BodyRaw, &params); err != nil {
+				return nil, err
+			}
+			offsets = append(offsets, params.Offset)
+			cancel()
+			return nil, ctx.Err()
+		},
+	}
+	ch := newTestChannel(t, caller)
+	require.True(t, ch.markUpdateDelivered(41))
+	require.False(t, ch.markUpdateDelivered(41))
+	require.False(t, ch.markUpdateDelivered(7))
+
+	out := make(chan telego.Update)
+	ch.pollUpdates(ctx, out)
+
+	assert.Equal(t, []int{42}, offsets)
+	_, open := <-out
+	assert.False(t, open, "pollUpdates must close its output channel")
+}
@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mymmrac/telego"
@@ -68,6 +69,10 @@ type TelegramChannel struct {
 	mediaGroupMu    sync.Mutex
 	mediaGroups     map[string]*telegramMediaGroup
 	mediaGroupDelay time.Duration
+
+	// nextUpdateID is the getUpdates offset: one past the last update handed
+	// to the bot handler. It survives reconnects and Stop/Start.
+	nextUpdateID atomic.Int64
 }
 
 type telegramMediaGroup struct {
@@ -89,10 +94,10 @@ func NewTelegramChannel(
 	channelName := bc.Name()
 	var opts []telego.BotOption
 
-	if telegramCfg.Proxy != "" {
-		proxyURL, parseErr := url.Parse(telegramCfg.Proxy)
+	if proxy := channels.ValidateProxyURL(channelName, telegramCfg.Proxy); proxy != "" {
+		proxyURL, parseErr := url.Parse(proxy)
 		if parseErr != nil {
-			return nil, fmt.Errorf("invalid proxy URL %q: %w", telegramCfg.Proxy, parseErr)
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", proxy, parseErr)
 		}
 		opts = append(opts, telego.WithHTTPClient(&http.Client{
 			Transport: &http.Transport{
@@ -126,6 +131,8 @@ func NewTelegramChannel(
 		channels.WithMaxMessageLength(4000),
 		channels.WithGroupTrigger(bc.GroupTrigger),
 		channels.WithReasoningChannelID(bc.ReasoningChannelID),
+		// Send already renders markdown as Telegram HTML.
+		channels.WithOutputFormatter(config.OutputFormatHTML, nil),
 	)
 
 	ch := &TelegramChannel{
@@ -154,13 +161,8 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 
 	c.ctx, c.cancel = context.WithCancel(ctx)
 
-	updates, err := c.bot.UpdatesViaLongPolling(c.ctx, &telego.GetUpdatesParams{
-		Timeout: 30,
-	})
-	if err != nil {
-		c.cancel()
-		return fmt.Errorf("failed to start long polling: %w", err)
-	}
+	updates := make(chan telego.Update, 100)
+	go c.pollUpdates(c.ctx, updates)
 
 	bh, err := th.NewBotHandler(c.bot, updates)
 	if err != nil {
@@ -1063,6 +1065,7 @@ func (c *TelegramChannel) handleMessages(ctx context.Context, messages []*telego
 		CanonicalID: identity.BuildCanonicalID("telegram", platformID),
 		Username:    user.Username,
 		DisplayName: user.FirstName,
+		Locale:      user.LanguageCode,
 	}
 
 	// check allowlist to avoid downloading attachments for rejected users
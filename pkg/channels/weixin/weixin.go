@@ -66,7 +66,7 @@ func NewWeixinChannel(
 	cfg *config.WeixinSettings,
 	messageBus *bus.MessageBus,
 ) (*WeixinChannel, error) {
-	api, err := NewApiClient(cfg.BaseURL, cfg.Token.String(), cfg.Proxy)
+	api, err := NewApiClient(cfg.BaseURL, cfg.Token.String(), channels.ValidateProxyURL(bc.Name(), cfg.Proxy))
 	if err != nil {
 		return nil, fmt.Errorf("weixin: failed to create API client: %w", err)
 	}
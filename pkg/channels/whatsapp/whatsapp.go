@@ -17,6 +17,24 @@ import (
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
+const (
+	bridgeHandshakeTimeout = 10 * time.Second
+	bridgeWriteTimeout     = 10 * time.Second
+	bridgePingInterval     = 30 * time.Second
+	// bridgeReadTimeout drops a connection that has answered neither a ping
+	// nor sent anything for two ping intervals.
+	bridgeReadTimeout = 2*bridgePingInterval + 15*time.Second
+	bridgeMinBackoff  = 1 * time.Second
+	bridgeMaxBackoff  = 60 * time.Second
+
+	// maxQueuedOutbound caps the messages held while the bridge is down.
+	maxQueuedOutbound = 100
+)
+
+// WhatsAppChannel talks to an external WhatsApp bridge over a WebSocket. The
+// bridge runs as its own process and may restart at any time, so the channel
+// keeps reconnecting with backoff and queues outbound messages while it is
+// disconnected.
 type WhatsAppChannel struct {
 	*channels.BaseChannel
 	conn      *websocket.Conn
@@ -24,8 +42,13 @@ type WhatsAppChannel struct {
 	url       string
 	ctx       context.Context
 	cancel    context.CancelFunc
-	mu        sync.Mutex
+	mu        sync.Mutex // guards conn, connected, lastErr, queue and writes to conn
 	connected bool
+	lastErr   string
+	queue     [][]byte
+
+	// retryBackoff overrides bridgeMinBackoff in tests.
+	retryBackoff time.Duration
 }
 
 func NewWhatsAppChannel(
@@ -50,6 +73,8 @@ func NewWhatsAppChannel(
 	}, nil
 }
 
+// Start connects to the bridge. An unreachable bridge does not fail the
+// channel: it logs a warning and keeps retrying in the background.
 func (c *WhatsAppChannel) Start(ctx context.Context) error {
 	logger.InfoCF("whatsapp", "Starting WhatsApp channel", map[string]any{
 		"bridge_url": c.url,
@@ -57,27 +82,18 @@ func (c *WhatsAppChannel) Start(ctx context.Context) error {
 
 	c.ctx, c.cancel = context.WithCancel(ctx)
 
-	dialer := websocket.DefaultDialer
-	dialer.HandshakeTimeout = 10 * time.Second
-
-	conn, resp, err := dialer.Dial(c.url, nil)
-	if resp != nil {
-		resp.Body.Close()
-	}
-	if err != nil {
-		c.cancel()
-		return fmt.Errorf("failed to connect to WhatsApp bridge: %w", err)
+	if err := c.connect(); err != nil {
+		logger.WarnCF("whatsapp", "WhatsApp bridge unreachable, retrying in background; "+
+			"outbound messages are queued until it connects", map[string]any{
+			"bridge_url": c.url,
+			"error":      err.Error(),
+		})
+	} else {
+		logger.InfoC("whatsapp", "WhatsApp channel connected")
 	}
 
-	c.mu.Lock()
-	c.conn = conn
-	c.connected = true
-	c.mu.Unlock()
-
 	c.SetRunning(true)
-	logger.InfoC("whatsapp", "WhatsApp channel connected")
-
-	go c.listen()
+	go c.run()
 
 	return nil
 }
@@ -85,7 +101,7 @@ func (c *WhatsAppChannel) Start(ctx context.Context) error {
 func (c *WhatsAppChannel) Stop(ctx context.Context) error {
 	logger.InfoC("whatsapp", "Stopping WhatsApp channel...")
 
-	// Cancel context first to signal listen goroutine to exit
+	// Cancel context first to signal the connection loop to exit
 	if c.cancel != nil {
 		c.cancel()
 	}
@@ -103,11 +119,36 @@ func (c *WhatsAppChannel) Stop(ctx context.Context) error {
 	}
 
 	c.connected = false
+	if len(c.queue) > 0 {
+		logger.WarnCF("whatsapp", "Dropping queued WhatsApp messages on stop", map[string]any{
+			"queued": len(c.queue),
+		})
+		c.queue = nil
+	}
 	c.SetRunning(false)
 
 	return nil
 }
 
+// ConnectionStatus implements channels.ConnectionStatusReporter.
+func (c *WhatsAppChannel) ConnectionStatus() (bool, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.connected {
+		return true, ""
+	}
+	detail := "bridge unreachable"
+	if c.lastErr != "" {
+		detail += ": " + c.lastErr
+	}
+	if len(c.queue) > 0 {
+		detail += fmt.Sprintf(" (%d messages queued)", len(c.queue))
+	}
+	return false, detail
+}
+
+// Send writes the message to the bridge, or queues it while the bridge is
+// disconnected. Queued messages go out in order once it reconnects.
 func (c *WhatsAppChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]string, error) {
 	if !c.IsRunning() {
 		return nil, channels.ErrNotRunning
@@ -120,13 +161,6 @@ func (c *WhatsAppChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]
 	default:
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if c.conn == nil {
-		return nil, fmt.Errorf("whatsapp connection not established: %w", channels.ErrTemporary)
-	}
-
 	payload := map[string]any{
 		"type":    "message",
 		"to":      msg.ChatID,
@@ -138,56 +172,198 @@ func (c *WhatsAppChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]
 		return nil, fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-	if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		_ = c.conn.SetWriteDeadline(time.Time{})
-		return nil, fmt.Errorf("whatsapp send: %w", channels.ErrTemporary)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		err = c.writeLocked(data)
+		if err == nil {
+			return nil, nil
+		}
+		// The connection is broken; drop it so the loop reconnects, and keep
+		// the message for the next connection.
+		c.dropConnLocked(c.conn, err)
 	}
-	_ = c.conn.SetWriteDeadline(time.Time{})
 
+	if len(c.queue) >= maxQueuedOutbound {
+		return nil, fmt.Errorf("whatsapp bridge disconnected and %d messages already queued: %w",
+			len(c.queue), channels.ErrTemporary)
+	}
+	c.queue = append(c.queue, data)
+	logger.DebugCF("whatsapp", "WhatsApp bridge disconnected, message queued", map[string]any{
+		"chat_id": msg.ChatID,
+		"queued":  len(c.queue),
+	})
 	return nil, nil
 }
 
-func (c *WhatsAppChannel) listen() {
+func (c *WhatsAppChannel) writeLocked(data []byte) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(bridgeWriteTimeout))
+	err := c.conn.WriteMessage(websocket.TextMessage, data)
+	_ = c.conn.SetWriteDeadline(time.Time{})
+	return err
+}
+
+// connect dials the bridge and, once connected, sends the queued messages.
+func (c *WhatsAppChannel) connect() error {
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = bridgeHandshakeTimeout
+
+	conn, resp, err := dialer.DialContext(c.ctx, c.url, nil)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		c.mu.Lock()
+		c.lastErr = err.Error()
+		c.mu.Unlock()
+		return err
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(bridgeReadTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(bridgeReadTimeout))
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn = conn
+	c.connected = true
+	c.lastErr = ""
+
+	if n := len(c.queue); n > 0 {
+		for len(c.queue) > 0 {
+			if err = c.writeLocked(c.queue[0]); err != nil {
+				c.dropConnLocked(conn, err)
+				return fmt.Errorf("flush queued messages: %w", err)
+			}
+			c.queue = c.queue[1:]
+		}
+		logger.InfoCF("whatsapp", "Sent messages queued while the bridge was down", map[string]any{
+			"count": n,
+		})
+	}
+
+	go c.pinger(conn)
+	return nil
+}
+
+// dropConnLocked closes conn if it is still the current connection and marks
+// the channel disconnected.
+func (c *WhatsAppChannel) dropConnLocked(conn *websocket.Conn, err error) {
+	if c.conn != conn {
+		return
+	}
+	_ = conn.Close()
+	c.conn = nil
+	c.connected = false
+	if err != nil {
+		c.lastErr = err.Error()
+	}
+	if c.ctx.Err() == nil {
+		logger.WarnCF("whatsapp", "WhatsApp bridge connection lost, reconnecting", map[string]any{
+			"bridge_url": c.url,
+			"error":      c.lastErr,
+		})
+	}
+}
+
+// run keeps the bridge connection alive: it reads from the current
+// connection and redials with exponential backoff when it drops.
+func (c *WhatsAppChannel) run() {
+	minBackoff := bridgeMinBackoff
+	if c.retryBackoff > 0 {
+		minBackoff = c.retryBackoff
+	}
+	backoff := minBackoff
+
 	for {
+		c.mu.Lock()
+		conn := c.conn
+		c.mu.Unlock()
+
+		if conn != nil {
+			err := c.listen(conn)
+			c.mu.Lock()
+			c.dropConnLocked(conn, err)
+			c.mu.Unlock()
+			backoff = minBackoff
+		}
+
 		select {
 		case <-c.ctx.Done():
 			return
-		default:
-			c.mu.Lock()
-			conn := c.conn
-			c.mu.Unlock()
+		case <-time.After(backoff):
+		}
 
-			if conn == nil {
-				time.Sleep(1 * time.Second)
-				continue
-			}
+		c.mu.Lock()
+		reconnected := c.conn != nil
+		c.mu.Unlock()
+		if reconnected {
+			continue
+		}
+		if err := c.connect(); err != nil {
+			backoff = min(backoff*2, bridgeMaxBackoff)
+			logger.WarnCF("whatsapp", "WhatsApp bridge reconnect failed", map[string]any{
+				"error":    err.Error(),
+				"retry_in": backoff.String(),
+			})
+			continue
+		}
+		logger.InfoC("whatsapp", "WhatsApp bridge reconnected")
+	}
+}
+
+func (c *WhatsAppChannel) pinger(conn *websocket.Conn) {
+	ticker := time.NewTicker(bridgePingInterval)
+	defer ticker.Stop()
 
-			_, message, err := conn.ReadMessage()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			if c.conn != conn {
+				c.mu.Unlock()
+				return
+			}
+			err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(bridgeWriteTimeout))
+			c.mu.Unlock()
 			if err != nil {
-				logger.ErrorCF("whatsapp", "WhatsApp read error", map[string]any{
+				logger.DebugCF("whatsapp", "Bridge ping failed", map[string]any{
 					"error": err.Error(),
 				})
-				time.Sleep(2 * time.Second)
-				continue
+				return
 			}
+		}
+	}
+}
 
-			var msg map[string]any
-			if err := json.Unmarshal(message, &msg); err != nil {
-				logger.ErrorCF("whatsapp", "Failed to unmarshal WhatsApp message", map[string]any{
-					"error": err.Error(),
-				})
-				continue
-			}
+// listen reads bridge messages until the connection fails.
+func (c *WhatsAppChannel) listen(conn *websocket.Conn) error {
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(bridgeReadTimeout))
 
-			msgType, ok := msg["type"].(string)
-			if !ok {
-				continue
-			}
+		var msg map[string]any
+		if err := json.Unmarshal(message, &msg); err != nil {
+			logger.ErrorCF("whatsapp", "Failed to unmarshal WhatsApp message", map[string]any{
+				"error": err.Error(),
+			})
+			continue
+		}
 
-			if msgType == "message" {
-				c.handleIncomingMessage(msg)
-			}
+		msgType, ok := msg["type"].(string)
+		if !ok {
+			continue
+		}
+
+		if msgType == "message" {
+			c.handleIncomingMessage(msg)
 		}
 	}
 }
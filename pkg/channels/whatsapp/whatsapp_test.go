@@ -0,0 +1,182 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// fakeBridge is a WhatsApp bridge that can be taken down and brought back.
+type fakeBridge struct {
+	mu       sync.Mutex
+	up       bool
+	conns    []*websocket.Conn
+	received chan string
+}
+
+func newFakeBridge(t *testing.T, up bool) (*fakeBridge, string) {
+	t.Helper()
+	b := &fakeBridge{up: up, received: make(chan string, 256)}
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		up := b.up
+		b.mu.Unlock()
+		if !up {
+			http.Error(w, "bridge restarting", http.StatusServiceUnavailable)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		b.conns = append(b.conns, conn)
+		b.mu.Unlock()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg map[string]any
+			if json.Unmarshal(data, &msg) == nil {
+				content, _ := msg["content"].(string)
+				b.received <- content
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return b, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func (b *fakeBridge) setUp(up bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.up = up
+	if !up {
+		for _, conn := range b.conns {
+			conn.Close()
+		}
+		b.conns = nil
+	}
+}
+
+func (b *fakeBridge) expect(t *testing.T, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-b.received:
+			if got != w {
+				t.Fatalf("bridge received %q, want %q", got, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("bridge did not receive %q", w)
+		}
+	}
+}
+
+func newTestChannel(t *testing.T, url string) *WhatsAppChannel {
+	t.Helper()
+	bc := &config.Channel{Enabled: true, Type: config.ChannelWhatsApp}
+	ch, err := NewWhatsAppChannel(bc, &config.WhatsAppSettings{BridgeURL: url}, bus.NewMessageBus())
+	if err != nil {
+		t.Fatalf("NewWhatsAppChannel() error = %v", err)
+	}
+	ch.retryBackoff = 10 * time.Millisecond
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { ch.Stop(context.Background()) })
+	return ch
+}
+
+func waitConnected(t *testing.T, ch *WhatsAppChannel, want bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if connected, _ := ch.ConnectionStatus(); connected == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("connected never became %v", want)
+}
+
+func send(t *testing.T, ch *WhatsAppChannel, content string) error {
+	t.Helper()
+	_, err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "chat1", Content: content})
+	return err
+}
+
+func TestWhatsAppChannel_QueuesWhileBridgeDownAndFlushesOnConnect(t *testing.T) {
+	bridge, url := newFakeBridge(t, false)
+	ch := newTestChannel(t, url)
+
+	if !ch.IsRunning() {
+		t.Fatal("channel should run while the bridge is unreachable")
+	}
+	connected, detail := ch.ConnectionStatus()
+	if connected || !strings.Contains(detail, "bridge unreachable") {
+		t.Fatalf("ConnectionStatus() = %v, %q; want disconnected with detail", connected, detail)
+	}
+
+	for _, content := range []string{"one", "two"} {
+		if err := send(t, ch, content); err != nil {
+			t.Fatalf("Send(%q) while disconnected error = %v, want queued", content, err)
+		}
+	}
+	if _, detail = ch.ConnectionStatus(); !strings.Contains(detail, "2 messages queued") {
+		t.Errorf("detail = %q, want queued count", detail)
+	}
+
+	bridge.setUp(true)
+	bridge.expect(t, "one", "two")
+	waitConnected(t, ch, true)
+
+	if err := send(t, ch, "three"); err != nil {
+		t.Fatalf("Send() after reconnect error = %v", err)
+	}
+	bridge.expect(t, "three")
+}
+
+func TestWhatsAppChannel_ReconnectsAfterBridgeRestart(t *testing.T) {
+	bridge, url := newFakeBridge(t, true)
+	ch := newTestChannel(t, url)
+	waitConnected(t, ch, true)
+
+	bridge.setUp(false)
+	waitConnected(t, ch, false)
+	if err := send(t, ch, "while down"); err != nil {
+		t.Fatalf("Send() while down error = %v", err)
+	}
+
+	bridge.setUp(true)
+	bridge.expect(t, "while down")
+	waitConnected(t, ch, true)
+}
+
+func TestWhatsAppChannel_QueueIsCapped(t *testing.T) {
+	_, url := newFakeBridge(t, false)
+	ch := newTestChannel(t, url)
+
+	for i := 0; i < maxQueuedOutbound; i++ {
+		if err := send(t, ch, "queued"); err != nil {
+			t.Fatalf("Send() #%d error = %v", i, err)
+		}
+	}
+	if err := send(t, ch, "overflow"); !errors.Is(err, channels.ErrTemporary) {
+		t.Fatalf("Send() with a full queue error = %v, want ErrTemporary", err)
+	}
+}
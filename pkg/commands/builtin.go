@@ -12,12 +12,24 @@ func BuiltinDefinitions() []Definition {
 		showCommand(),
 		listCommand(),
 		useCommand(),
+		approveCommand(),
+		denyCommand(),
+		continueCommand(),
 		btwCommand(),
 		switchCommand(),
 		checkCommand(),
 		clearCommand(),
+		summarizeCommand(),
+		summaryCommand(),
 		contextCommand(),
+		pinCommand(),
+		unpinCommand(),
+		pinsCommand(),
+		planCommand(),
+		sensitiveCommand(),
 		subagentsCommand(),
 		reloadCommand(),
+		safeCommand(),
+		aliasesCommand(),
 	}
 }
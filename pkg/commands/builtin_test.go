@@ -2,8 +2,11 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
 )
 
 func findDefinitionByName(t *testing.T, defs []Definition, name string) Definition {
@@ -406,3 +409,259 @@ func TestBuiltinBtwCommand_PreservesQuestionWhitespace(t *testing.T) {
 		t.Fatalf("/btw outcome=%v, want=%v", res.Outcome, OutcomeHandled)
 	}
 }
+
+func TestBuiltinPinCommands_UseRuntimeCallbacks(t *testing.T) {
+	var pinned []string
+	rt := &Runtime{
+		PinFile: func(path string) (string, bool, error) {
+			for _, p := range pinned {
+				if p == path {
+					return path, false, nil
+				}
+			}
+			pinned = append(pinned, path)
+			return path, true, nil
+		},
+		UnpinFile: func(path string) (bool, error) {
+			for i, p := range pinned {
+				if p == path {
+					pinned = append(pinned[:i], pinned[i+1:]...)
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+		ListPinnedFiles: func() []string { return pinned },
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+	run := func(text string) string {
+		t.Helper()
+		var reply string
+		res := ex.Execute(context.Background(), Request{
+			Text: text,
+			Reply: func(s string) error {
+				reply = s
+				return nil
+			},
+		})
+		if res.Outcome != OutcomeHandled {
+			t.Fatalf("%s outcome = %v, want handled", text, res.Outcome)
+		}
+		return reply
+	}
+
+	if got := run("/pins"); !strings.Contains(got, "No pinned files") {
+		t.Fatalf("/pins empty reply = %q", got)
+	}
+	if got := run("/pin docs/task spec.md"); !strings.Contains(got, "Pinned docs/task spec.md") {
+		t.Fatalf("/pin reply = %q", got)
+	}
+	if got := run("/pin docs/task spec.md"); !strings.Contains(got, "already pinned") {
+		t.Fatalf("/pin duplicate reply = %q", got)
+	}
+	if got := run("/pins"); !strings.Contains(got, "- docs/task spec.md") {
+		t.Fatalf("/pins reply = %q", got)
+	}
+	if got := run("/unpin docs/task spec.md"); !strings.Contains(got, "Unpinned") {
+		t.Fatalf("/unpin reply = %q", got)
+	}
+	if got := run("/unpin docs/task spec.md"); !strings.Contains(got, "is not pinned") {
+		t.Fatalf("/unpin missing reply = %q", got)
+	}
+	if got := run("/pin"); got != "Usage: /pin <file>" {
+		t.Fatalf("/pin without args reply = %q", got)
+	}
+}
+
+func TestBuiltinSummarizeCommand_ReportsOutcome(t *testing.T) {
+	summarized := true
+	rt := &Runtime{
+		SummarizeHistory: func(context.Context) (bool, error) {
+			return summarized, nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+	run := func() string {
+		t.Helper()
+		var reply string
+		res := ex.Execute(context.Background(), Request{
+			Text: "/summarize",
+			Reply: func(s string) error {
+				reply = s
+				return nil
+			},
+		})
+		if res.Outcome != OutcomeHandled {
+			t.Fatalf("/summarize outcome = %v, want handled", res.Outcome)
+		}
+		return reply
+	}
+
+	if got := run(); !strings.Contains(got, "Chat history summarized") {
+		t.Fatalf("/summarize reply = %q", got)
+	}
+	summarized = false
+	if got := run(); !strings.Contains(got, "Not enough chat history") {
+		t.Fatalf("/summarize no-op reply = %q", got)
+	}
+}
+
+func TestBuiltinSafeCommand_TogglesRuntimeState(t *testing.T) {
+	var on, enforced bool
+	rt := &Runtime{
+		GetSafeMode: func() (bool, bool) { return on || enforced, enforced },
+		SetSafeMode: func(v bool) error {
+			if enforced && !v {
+				return errors.New("enforced by config")
+			}
+			on = v
+			return nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+	run := func(text string) string {
+		t.Helper()
+		var reply string
+		res := ex.Execute(context.Background(), Request{
+			Text: text,
+			Reply: func(s string) error {
+				reply = s
+				return nil
+			},
+		})
+		if res.Outcome != OutcomeHandled {
+			t.Fatalf("%s outcome = %v, want handled", text, res.Outcome)
+		}
+		return reply
+	}
+
+	if got := run("/safe on"); !on || !strings.Contains(got, "Safe mode: on") {
+		t.Fatalf("/safe on reply = %q, on = %v", got, on)
+	}
+	if got := run("/safe"); !strings.Contains(got, "Safe mode: on") {
+		t.Fatalf("/safe reply = %q", got)
+	}
+	if got := run("/safe off"); on || got != "Safe mode: off." {
+		t.Fatalf("/safe off reply = %q, on = %v", got, on)
+	}
+	enforced = true
+	if got := run("/safe off"); !strings.Contains(got, "Failed to change safe mode") {
+		t.Fatalf("/safe off under enforcement reply = %q", got)
+	}
+}
+
+func TestBuiltinSummaryCommand_ShowsEditsAndClears(t *testing.T) {
+	summary := ""
+	rt := &Runtime{
+		GetSummary: func() string { return summary },
+		SetSummary: func(s string) error {
+			summary = s
+			return nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+	run := func(text string) string {
+		t.Helper()
+		var reply string
+		res := ex.Execute(context.Background(), Request{
+			Text: text,
+			Reply: func(s string) error {
+				reply = s
+				return nil
+			},
+		})
+		if res.Outcome != OutcomeHandled {
+			t.Fatalf("%s outcome = %v, want handled", text, res.Outcome)
+		}
+		return reply
+	}
+
+	if got := run("/summary"); !strings.Contains(got, "no summary") {
+		t.Fatalf("/summary empty reply = %q", got)
+	}
+	run("/summary edit \"\"\"\nUser prefers Go.\n  - deploys on Fridays\n\"\"\"")
+	if want := "User prefers Go.\n  - deploys on Fridays"; summary != want {
+		t.Fatalf("summary after edit = %q, want %q", summary, want)
+	}
+	if got := run("/summary"); !strings.Contains(got, "deploys on Fridays") {
+		t.Fatalf("/summary reply = %q", got)
+	}
+	run("/summary edit plain text")
+	if summary != "plain text" {
+		t.Fatalf("summary after plain edit = %q", summary)
+	}
+	if got := run("/summary edit"); !strings.HasPrefix(got, "Usage:") || summary != "plain text" {
+		t.Fatalf("/summary edit without text reply = %q, summary = %q", got, summary)
+	}
+	if got := run("/summary clear"); got != "Summary cleared." || summary != "" {
+		t.Fatalf("/summary clear reply = %q, summary = %q", got, summary)
+	}
+}
+
+func TestBuiltinAliases_ListsConfiguredAliases(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rt := &Runtime{Config: cfg}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+
+	var reply string
+	req := Request{
+		Text: "/aliases",
+		Reply: func(text string) error {
+			reply = text
+			return nil
+		},
+	}
+	if res := ex.Execute(context.Background(), req); res.Outcome != OutcomeHandled {
+		t.Fatalf("/aliases: outcome=%v, want=%v", res.Outcome, OutcomeHandled)
+	}
+	if !strings.Contains(reply, "No aliases configured") {
+		t.Fatalf("/aliases reply without aliases = %q", reply)
+	}
+
+	cfg.Agents.Defaults.Aliases = config.MessageAliases{
+		"!tldr":    "Summarize this in three bullets:",
+		"!standup": "Write my standup notes.\nFocus: {{arg}}",
+	}
+	ex.Execute(context.Background(), req)
+	want := "- !standup → Write my standup notes. Focus: {{arg}}\n- !tldr → Summarize this in three bullets:"
+	if !strings.Contains(reply, want) {
+		t.Fatalf("/aliases reply = %q, want sorted entries %q", reply, want)
+	}
+}
+
+func TestBuiltinSensitiveCommand_TogglesMark(t *testing.T) {
+	sensitive := false
+	rt := &Runtime{
+		GetSensitive: func() bool { return sensitive },
+		SetSensitive: func(v bool) error {
+			sensitive = v
+			return nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+	run := func(text string) string {
+		t.Helper()
+		var reply string
+		res := ex.Execute(context.Background(), Request{
+			Text: text,
+			Reply: func(s string) error {
+				reply = s
+				return nil
+			},
+		})
+		if res.Outcome != OutcomeHandled {
+			t.Fatalf("%s outcome = %v, want handled", text, res.Outcome)
+		}
+		return reply
+	}
+
+	if got := run("/sensitive on"); !sensitive || !strings.Contains(got, "marked sensitive") {
+		t.Fatalf("/sensitive on reply = %q, sensitive = %v", got, sensitive)
+	}
+	if got := run("/sensitive off"); sensitive || !strings.Contains(got, "not marked sensitive") {
+		t.Fatalf("/sensitive off reply = %q, sensitive = %v", got, sensitive)
+	}
+	if got := run("/sensitive maybe"); got != "Usage: /sensitive [on|off]" {
+		t.Fatalf("/sensitive maybe reply = %q", got)
+	}
+}
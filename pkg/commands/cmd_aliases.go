@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const aliasPreviewLength = 60
+
+func aliasesCommand() Definition {
+	return Definition{
+		Name:        "aliases",
+		Description: "List configured message aliases",
+		Usage:       "/aliases",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.Config == nil {
+				return req.Reply(unavailableMsg)
+			}
+			aliases := rt.Config.Agents.Defaults.Aliases
+			if len(aliases) == 0 {
+				return req.Reply("No aliases configured. Add them under agents.defaults.aliases.")
+			}
+			triggers := make([]string, 0, len(aliases))
+			for trigger := range aliases {
+				triggers = append(triggers, trigger)
+			}
+			sort.Strings(triggers)
+
+			var b strings.Builder
+			b.WriteString("Aliases:")
+			for _, trigger := range triggers {
+				preview := strings.Join(strings.Fields(aliases[trigger]), " ")
+				fmt.Fprintf(&b, "\n- %s → %s", trigger, utils.Truncate(preview, aliasPreviewLength))
+			}
+			b.WriteString("\n\nStart a message with an alias to send its prompt; the rest of the message fills {{arg}}.")
+			return req.Reply(b.String())
+		},
+	}
+}
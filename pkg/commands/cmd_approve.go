@@ -0,0 +1,20 @@
+package commands
+
+// The agent loop handles these itself, since they answer a tool call held
+// for the user's approval in the current session.
+
+func approveCommand() Definition {
+	return Definition{
+		Name:        "approve",
+		Description: "Run the tool call that is waiting for your approval",
+		Usage:       "/approve",
+	}
+}
+
+func denyCommand() Definition {
+	return Definition{
+		Name:        "deny",
+		Description: "Refuse the tool call that is waiting for your approval",
+		Usage:       "/deny",
+	}
+}
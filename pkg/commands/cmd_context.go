@@ -18,7 +18,13 @@ func contextCommand() Definition {
 			if stats == nil {
 				return req.Reply("No active session context.")
 			}
-			return req.Reply(formatContextStats(stats))
+			reply := formatContextStats(stats)
+			if rt.GetSafeMode != nil {
+				if on, _ := rt.GetSafeMode(); on {
+					reply += "  \nSafe mode: on"
+				}
+			}
+			return req.Reply(reply)
 		},
 	}
 }
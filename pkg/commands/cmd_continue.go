@@ -0,0 +1,12 @@
+package commands
+
+// The agent loop handles /continue itself, since it resumes a task that the
+// current session's last turn left at the tool-iteration limit.
+
+func continueCommand() Definition {
+	return Definition{
+		Name:        "continue",
+		Description: "Let a task cut off at the tool-iteration limit keep going",
+		Usage:       "/continue",
+	}
+}
@@ -39,6 +39,14 @@ func listCommand() Definition {
 					if len(enabled) == 0 {
 						return req.Reply("No channels enabled")
 					}
+					if rt.GetChannelHealth != nil {
+						health := rt.GetChannelHealth()
+						for i, name := range enabled {
+							if state := health[name]; state != "" && state != "healthy" {
+								enabled[i] = fmt.Sprintf("%s (%s)", name, state)
+							}
+						}
+					}
 					return req.Reply(fmt.Sprintf("Enabled Channels:\n- %s", strings.Join(enabled, "\n- ")))
 				},
 			},
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func pinCommand() Definition {
+	return Definition{
+		Name:        "pin",
+		Description: "Pin a workspace file into this session's context",
+		Usage:       "/pin <file>",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.PinFile == nil {
+				return req.Reply(unavailableMsg)
+			}
+			path := commandArgText(req.Text)
+			if path == "" {
+				return req.Reply("Usage: /pin <file>")
+			}
+			pinned, added, err := rt.PinFile(path)
+			if err != nil {
+				return req.Reply("Failed to pin file: " + err.Error())
+			}
+			if !added {
+				return req.Reply(fmt.Sprintf("%s is already pinned.", pinned))
+			}
+			return req.Reply(fmt.Sprintf("Pinned %s. Its contents will be included on every turn.", pinned))
+		},
+	}
+}
+
+func unpinCommand() Definition {
+	return Definition{
+		Name:        "unpin",
+		Description: "Remove a pinned file from this session's context",
+		Usage:       "/unpin <file>",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.UnpinFile == nil {
+				return req.Reply(unavailableMsg)
+			}
+			path := commandArgText(req.Text)
+			if path == "" {
+				return req.Reply("Usage: /unpin <file>")
+			}
+			removed, err := rt.UnpinFile(path)
+			if err != nil {
+				return req.Reply("Failed to unpin file: " + err.Error())
+			}
+			if !removed {
+				return req.Reply(fmt.Sprintf("%s is not pinned.", path))
+			}
+			return req.Reply(fmt.Sprintf("Unpinned %s.", path))
+		},
+	}
+}
+
+func pinsCommand() Definition {
+	return Definition{
+		Name:        "pins",
+		Description: "List files pinned to this session",
+		Usage:       "/pins",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.ListPinnedFiles == nil {
+				return req.Reply(unavailableMsg)
+			}
+			pins := rt.ListPinnedFiles()
+			if len(pins) == 0 {
+				return req.Reply("No pinned files. Use /pin <file> to add one.")
+			}
+			return req.Reply("Pinned files:\n- " + strings.Join(pins, "\n- "))
+		},
+	}
+}
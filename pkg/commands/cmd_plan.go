@@ -0,0 +1,35 @@
+package commands
+
+import "context"
+
+func planCommand() Definition {
+	return Definition{
+		Name:        "plan",
+		Description: "Show or clear the agent's task plan for this session",
+		SubCommands: []SubCommand{
+			{
+				Name:        "show",
+				Description: "Current plan and progress",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.GetPlan == nil {
+						return req.Reply(unavailableMsg)
+					}
+					return req.Reply(rt.GetPlan())
+				},
+			},
+			{
+				Name:        "clear",
+				Description: "Discard the current plan",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.ClearPlan == nil {
+						return req.Reply(unavailableMsg)
+					}
+					if err := rt.ClearPlan(); err != nil {
+						return req.Reply("Failed to clear plan: " + err.Error())
+					}
+					return req.Reply("Plan cleared.")
+				},
+			},
+		},
+	}
+}
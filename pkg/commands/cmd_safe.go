@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"context"
+	"strings"
+)
+
+func safeCommand() Definition {
+	return Definition{
+		Name:        "safe",
+		Description: "Show or toggle safe mode (read-only tools only)",
+		Usage:       "/safe [on|off]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.GetSafeMode == nil || rt.SetSafeMode == nil {
+				return req.Reply(unavailableMsg)
+			}
+			switch arg := strings.ToLower(commandArgText(req.Text)); arg {
+			case "":
+				return req.Reply(formatSafeMode(rt.GetSafeMode()))
+			case "on", "off":
+				if err := rt.SetSafeMode(arg == "on"); err != nil {
+					return req.Reply("Failed to change safe mode: " + err.Error())
+				}
+				return req.Reply(formatSafeMode(rt.GetSafeMode()))
+			default:
+				return req.Reply("Usage: /safe [on|off]")
+			}
+		},
+	}
+}
+
+func formatSafeMode(on, enforced bool) string {
+	switch {
+	case enforced:
+		return "Safe mode: on (set by agents.defaults.safe_mode). Only read-only tools can run."
+	case on:
+		return "Safe mode: on. Only read-only tools can run."
+	default:
+		return "Safe mode: off."
+	}
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"context"
+	"strings"
+)
+
+func sensitiveCommand() Definition {
+	return Definition{
+		Name:        "sensitive",
+		Description: "Show or set whether this conversation is left out of session exports",
+		Usage:       "/sensitive [on|off]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.GetSensitive == nil || rt.SetSensitive == nil {
+				return req.Reply(unavailableMsg)
+			}
+			switch arg := strings.ToLower(commandArgText(req.Text)); arg {
+			case "":
+				return req.Reply(formatSensitive(rt.GetSensitive()))
+			case "on", "off":
+				if err := rt.SetSensitive(arg == "on"); err != nil {
+					return req.Reply("Failed to change the sensitive mark: " + err.Error())
+				}
+				return req.Reply(formatSensitive(rt.GetSensitive()))
+			default:
+				return req.Reply("Usage: /sensitive [on|off]")
+			}
+		},
+	}
+}
+
+func formatSensitive(on bool) string {
+	if on {
+		return "This conversation is marked sensitive and is left out of session exports."
+	}
+	return "This conversation is not marked sensitive."
+}
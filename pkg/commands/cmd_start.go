@@ -7,7 +7,10 @@ func startCommand() Definition {
 		Name:        "start",
 		Description: "Start the bot",
 		Usage:       "/start",
-		Handler: func(_ context.Context, req Request, _ *Runtime) error {
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt != nil && rt.Config != nil && rt.Config.Agents.Defaults.Name != "" {
+				return req.Reply("Hello! I am " + rt.Config.Agents.Defaults.Name)
+			}
 			return req.Reply("Hello! I am PicoClaw 🦞")
 		},
 	}
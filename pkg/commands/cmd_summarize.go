@@ -0,0 +1,24 @@
+package commands
+
+import "context"
+
+func summarizeCommand() Definition {
+	return Definition{
+		Name:        "summarize",
+		Description: "Summarize older chat history now to free up context",
+		Usage:       "/summarize",
+		Handler: func(ctx context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.SummarizeHistory == nil {
+				return req.Reply(unavailableMsg)
+			}
+			summarized, err := rt.SummarizeHistory(ctx)
+			if err != nil {
+				return req.Reply("Failed to summarize chat history: " + err.Error())
+			}
+			if !summarized {
+				return req.Reply("Not enough chat history to summarize yet.")
+			}
+			return req.Reply("Chat history summarized. Use /context to see current usage.")
+		},
+	}
+}
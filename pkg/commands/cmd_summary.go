@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"strings"
+)
+
+const summaryUsage = `/summary [edit <text>|clear]`
+
+func summaryCommand() Definition {
+	return Definition{
+		Name:        "summary",
+		Description: "Show, replace, or clear this session's conversation summary",
+		Usage:       summaryUsage,
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.GetSummary == nil || rt.SetSummary == nil {
+				return req.Reply(unavailableMsg)
+			}
+			args := commandArgText(req.Text)
+			switch action := strings.ToLower(nthToken(args, 0)); action {
+			case "":
+				summary := rt.GetSummary()
+				if strings.TrimSpace(summary) == "" {
+					return req.Reply("This session has no summary yet.")
+				}
+				return req.Reply("Conversation summary:\n" + summary)
+			case "clear":
+				if err := rt.SetSummary(""); err != nil {
+					return req.Reply("Failed to clear summary: " + err.Error())
+				}
+				return req.Reply("Summary cleared.")
+			case "edit":
+				text := summaryEditText(args)
+				if text == "" {
+					return req.Reply(`Usage: /summary edit <text> (wrap multiple lines in """...""")`)
+				}
+				if err := rt.SetSummary(text); err != nil {
+					return req.Reply("Failed to update summary: " + err.Error())
+				}
+				return req.Reply("Summary updated. It will be used from the next message on.")
+			default:
+				return req.Reply("Usage: " + summaryUsage)
+			}
+		},
+	}
+}
+
+// summaryEditText returns the text after "edit", with surrounding
+// whitespace and an optional """...""" wrapper removed. Line breaks and
+// indentation inside the text are kept.
+func summaryEditText(args string) string {
+	text := strings.TrimSpace(strings.TrimPrefix(args, nthToken(args, 0)))
+	if len(text) >= 6 && strings.HasPrefix(text, `"""`) && strings.HasSuffix(text, `"""`) {
+		text = strings.TrimSpace(text[3 : len(text)-3])
+	}
+	return text
+}
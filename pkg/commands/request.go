@@ -78,3 +78,10 @@ func nthToken(input string, n int) string {
 func normalizeCommandName(name string) string {
 	return strings.ToLower(strings.TrimSpace(name))
 }
+
+// commandArgText returns the raw text following the command token, so
+// arguments containing spaces (such as file paths) survive intact.
+func commandArgText(input string) string {
+	input = strings.TrimSpace(input)
+	return strings.TrimSpace(strings.TrimPrefix(input, nthToken(input, 0)))
+}
@@ -57,11 +57,25 @@ type Runtime struct {
 	ListMCPServers     func(ctx context.Context) []MCPServerInfo
 	ListMCPTools       func(ctx context.Context, serverName string) ([]MCPToolInfo, error)
 	GetEnabledChannels func() []string
-	GetActiveTurn      func() any // Returning any to avoid circular dependency with agent package
+	GetChannelHealth   func() map[string]string // channel name → health state
+	GetActiveTurn      func() any               // Returning any to avoid circular dependency with agent package
 	GetContextStats    func() *ContextStats
 	SwitchModel        func(value string) (oldModel string, err error)
 	SwitchChannel      func(value string) error
 	ClearHistory       func() error
+	SummarizeHistory   func(ctx context.Context) (summarized bool, err error)
+	GetSummary         func() string
+	SetSummary         func(summary string) error
+	PinFile            func(path string) (pinned string, added bool, err error)
+	UnpinFile          func(path string) (bool, error)
+	ListPinnedFiles    func() []string
+	GetPlan            func() string
+	ClearPlan          func() error
+	GetSensitive       func() bool
+	SetSensitive       func(sensitive bool) error
 	ReloadConfig       func() error
 	StopActiveTurn     func() (StopResult, error)
+	// GetSafeMode reports whether safe mode is on and whether config enforces it.
+	GetSafeMode func() (on, enforced bool)
+	SetSafeMode func(on bool) error
 }
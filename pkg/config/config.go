@@ -35,13 +35,15 @@ func init() {
 type Config struct {
 	// Config schema version for migration.
 	Version   int             `json:"version"             yaml:"-"`
+	Include   []string        `json:"include,omitempty"   yaml:"-"` // more config files merged on top of this one, in order
 	Isolation IsolationConfig `json:"isolation,omitempty" yaml:"-"`
 	Agents    AgentsConfig    `json:"agents"              yaml:"-"`
 	Session   SessionConfig   `json:"session,omitempty"   yaml:"-"`
 	Evolution EvolutionConfig `json:"evolution,omitempty" yaml:"-"`
 	Channels  ChannelsConfig  `json:"channel_list"        yaml:"channel_list"`
 	ModelList SecureModelList `json:"model_list"          yaml:"model_list"` // New model-centric provider configuration
-	Gateway   GatewayConfig   `json:"gateway"             yaml:"-"`
+	Providers ProvidersConfig `json:"providers,omitzero"  yaml:"-"`
+	Gateway   GatewayConfig   `json:"gateway"             yaml:"gateway"`
 	Events    EventsConfig    `json:"events,omitempty"    yaml:"-"`
 	Hooks     HooksConfig     `json:"hooks,omitempty"     yaml:"-"`
 	Tools     ToolsConfig     `json:"tools"               yaml:",inline"`
@@ -319,6 +321,16 @@ type AgentConfig struct {
 	Model     *AgentModelConfig `json:"model,omitempty"`
 	Skills    []string          `json:"skills,omitempty"`
 	Subagents *SubagentsConfig  `json:"subagents,omitempty"`
+	Tools     *AgentToolsConfig `json:"tools,omitempty"`
+}
+
+// AgentToolsConfig limits the runtime tools registered for one agent. Allow,
+// when set, is the only set of tools the agent gets (an empty list allows
+// none); it is combined with any tools list in the agent's AGENT.md, and a
+// tool must appear in both. Deny removes tools from whatever is allowed.
+type AgentToolsConfig struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
 }
 
 type SubagentsConfig struct {
@@ -420,33 +432,211 @@ type ToolFeedbackConfig struct {
 	SeparateMessages bool `json:"separate_messages" env:"PICOCLAW_AGENTS_DEFAULTS_TOOL_FEEDBACK_SEPARATE_MESSAGES"`
 }
 
+// InboundQueueConfig tunes how messages that arrive while their session is
+// busy, or while every worker is taken, are queued and reported to the sender.
+type InboundQueueConfig struct {
+	// Notify replies to a queued message with its position in the queue.
+	Notify bool `json:"notify"             env:"PICOCLAW_AGENTS_DEFAULTS_QUEUE_NOTIFY"`
+	// CoalesceWindowMS merges messages the same sender sends within this many
+	// milliseconds of the one that started a turn into that turn. 0 disables it.
+	CoalesceWindowMS int `json:"coalesce_window_ms" env:"PICOCLAW_AGENTS_DEFAULTS_QUEUE_COALESCE_WINDOW_MS"`
+}
+
+// ContextProvidersConfig toggles the built-in context providers that append
+// runtime facts to the system prompt on every turn.
+type ContextProvidersConfig struct {
+	Datetime DatetimeContextConfig `json:"datetime"`
+	HostInfo HostInfoContextConfig `json:"host_info"`
+}
+
+type DatetimeContextConfig struct {
+	Enabled bool `json:"enabled" env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_PROVIDERS_DATETIME_ENABLED"`
+	// Timezone is an IANA name such as "Asia/Shanghai"; empty uses the host zone.
+	Timezone string `json:"timezone,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_PROVIDERS_DATETIME_TIMEZONE"`
+}
+
+type HostInfoContextConfig struct {
+	Enabled bool `json:"enabled" env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_PROVIDERS_HOST_INFO_ENABLED"`
+}
+
 type AgentDefaults struct {
-	Workspace                 string             `json:"workspace"                        env:"PICOCLAW_AGENTS_DEFAULTS_WORKSPACE"`
-	RestrictToWorkspace       bool               `json:"restrict_to_workspace"            env:"PICOCLAW_AGENTS_DEFAULTS_RESTRICT_TO_WORKSPACE"`
-	AllowReadOutsideWorkspace bool               `json:"allow_read_outside_workspace"     env:"PICOCLAW_AGENTS_DEFAULTS_ALLOW_READ_OUTSIDE_WORKSPACE"`
-	Provider                  string             `json:"provider"                         env:"PICOCLAW_AGENTS_DEFAULTS_PROVIDER"`
-	ModelName                 string             `json:"model_name"                       env:"PICOCLAW_AGENTS_DEFAULTS_MODEL_NAME"`
-	ModelFallbacks            []string           `json:"model_fallbacks,omitempty"`
-	ImageModel                string             `json:"image_model,omitempty"            env:"PICOCLAW_AGENTS_DEFAULTS_IMAGE_MODEL"`
-	ImageModelFallbacks       []string           `json:"image_model_fallbacks,omitempty"`
-	MaxTokens                 int                `json:"max_tokens"                       env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOKENS"`
-	ContextWindow             int                `json:"context_window,omitempty"         env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_WINDOW"`
-	Temperature               *float64           `json:"temperature,omitempty"            env:"PICOCLAW_AGENTS_DEFAULTS_TEMPERATURE"`
-	MaxToolIterations         int                `json:"max_tool_iterations"              env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOOL_ITERATIONS"`
-	SummarizeMessageThreshold int                `json:"summarize_message_threshold"      env:"PICOCLAW_AGENTS_DEFAULTS_SUMMARIZE_MESSAGE_THRESHOLD"`
-	SummarizeTokenPercent     int                `json:"summarize_token_percent"          env:"PICOCLAW_AGENTS_DEFAULTS_SUMMARIZE_TOKEN_PERCENT"`
-	MaxMediaSize              int                `json:"max_media_size,omitempty"         env:"PICOCLAW_AGENTS_DEFAULTS_MAX_MEDIA_SIZE"`
-	Routing                   *RoutingConfig     `json:"routing,omitempty"`
-	SteeringMode              string             `json:"steering_mode,omitempty"          env:"PICOCLAW_AGENTS_DEFAULTS_STEERING_MODE"`      // "one-at-a-time" (default) or "all"
-	MaxParallelTurns          int                `json:"max_parallel_turns,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_MAX_PARALLEL_TURNS"` // Max concurrent turns (0 or 1 = sequential)
-	SubTurn                   SubTurnConfig      `json:"subturn"                                                                                      envPrefix:"PICOCLAW_AGENTS_DEFAULTS_SUBTURN_"`
-	ToolFeedback              ToolFeedbackConfig `json:"tool_feedback,omitempty"`
-	SplitOnMarker             bool               `json:"split_on_marker"                  env:"PICOCLAW_AGENTS_DEFAULTS_SPLIT_ON_MARKER"` // split messages on <|[SPLIT]|> marker
-	ContextManager            string             `json:"context_manager,omitempty"        env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_MANAGER"`
-	ContextManagerConfig      json.RawMessage    `json:"context_manager_config,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_MANAGER_CONFIG"`
-	TurnProfile               TurnProfileConfig  `json:"turn_profile,omitempty"`
-	MaxLLMRetries             int                `json:"max_llm_retries,omitempty"        env:"PICOCLAW_AGENTS_DEFAULTS_MAX_LLM_RETRIES"`
-	LLMRetryBackoffSecs       int                `json:"llm_retry_backoff_secs,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_LLM_RETRY_BACKOFF_SECS"`
+	Workspace                 string                 `json:"workspace"                        env:"PICOCLAW_AGENTS_DEFAULTS_WORKSPACE"`
+	RestrictToWorkspace       bool                   `json:"restrict_to_workspace"            env:"PICOCLAW_AGENTS_DEFAULTS_RESTRICT_TO_WORKSPACE"`
+	AllowReadOutsideWorkspace bool                   `json:"allow_read_outside_workspace"     env:"PICOCLAW_AGENTS_DEFAULTS_ALLOW_READ_OUTSIDE_WORKSPACE"`
+	Provider                  string                 `json:"provider"                         env:"PICOCLAW_AGENTS_DEFAULTS_PROVIDER"`
+	ModelName                 string                 `json:"model_name"                       env:"PICOCLAW_AGENTS_DEFAULTS_MODEL_NAME"`
+	ModelFallbacks            []string               `json:"model_fallbacks,omitempty"`
+	ImageModel                string                 `json:"image_model,omitempty"            env:"PICOCLAW_AGENTS_DEFAULTS_IMAGE_MODEL"`
+	ImageModelFallbacks       []string               `json:"image_model_fallbacks,omitempty"`
+	SummaryModel              string                 `json:"summary_model,omitempty"          env:"PICOCLAW_AGENTS_DEFAULTS_SUMMARY_MODEL"`
+	MaxTokens                 int                    `json:"max_tokens"                       env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOKENS"`
+	ContextWindow             int                    `json:"context_window,omitempty"         env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_WINDOW"`
+	Tokenizer                 string                 `json:"tokenizer,omitempty"              env:"PICOCLAW_AGENTS_DEFAULTS_TOKENIZER"` // "estimate" (default) or "bpe" for exact OpenAI counts
+	Temperature               *float64               `json:"temperature,omitempty"            env:"PICOCLAW_AGENTS_DEFAULTS_TEMPERATURE"`
+	MaxToolIterations         int                    `json:"max_tool_iterations"              env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOOL_ITERATIONS"`
+	MaxToolDefs               int                    `json:"max_tool_defs,omitempty"          env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOOL_DEFS"`
+	CoreTools                 []string               `json:"core_tools,omitempty"`
+	OnIterationLimit          string                 `json:"on_iteration_limit,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_ON_ITERATION_LIMIT"` // "stop" (default), "ask" or "continue"
+	SummarizeMessageThreshold int                    `json:"summarize_message_threshold"      env:"PICOCLAW_AGENTS_DEFAULTS_SUMMARIZE_MESSAGE_THRESHOLD"`
+	SummarizeTokenPercent     int                    `json:"summarize_token_percent"          env:"PICOCLAW_AGENTS_DEFAULTS_SUMMARIZE_TOKEN_PERCENT"`
+	MaxHistoryMessages        int                    `json:"max_history_messages,omitempty"    env:"PICOCLAW_AGENTS_DEFAULTS_MAX_HISTORY_MESSAGES"` // 0 = unbounded
+	CompactionPreview         bool                   `json:"compaction_preview,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_COMPACTION_PREVIEW"`    // ask before summarizing interactive sessions
+	MaxMediaSize              int                    `json:"max_media_size,omitempty"         env:"PICOCLAW_AGENTS_DEFAULTS_MAX_MEDIA_SIZE"`
+	Routing                   *RoutingConfig         `json:"routing,omitempty"`
+	SteeringMode              string                 `json:"steering_mode,omitempty"          env:"PICOCLAW_AGENTS_DEFAULTS_STEERING_MODE"`      // "one-at-a-time" (default) or "all"
+	MaxParallelTurns          int                    `json:"max_parallel_turns,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_MAX_PARALLEL_TURNS"` // Max concurrent turns (0 or 1 = sequential)
+	SubTurn                   SubTurnConfig          `json:"subturn"                                                                                      envPrefix:"PICOCLAW_AGENTS_DEFAULTS_SUBTURN_"`
+	ToolFeedback              ToolFeedbackConfig     `json:"tool_feedback,omitempty"`
+	Queue                     InboundQueueConfig     `json:"queue,omitempty"`
+	ContextProviders          ContextProvidersConfig `json:"context_providers,omitempty"`
+	Timezone                  string                 `json:"timezone,omitempty"               env:"PICOCLAW_AGENTS_DEFAULTS_TIMEZONE"`        // IANA zone such as "Asia/Shanghai"; empty uses the host zone
+	Locale                    string                 `json:"locale,omitempty"                 env:"PICOCLAW_AGENTS_DEFAULTS_LOCALE"`          // language tag such as "en-US"; a sender's own locale wins
+	Name                      string                 `json:"name,omitempty"                   env:"PICOCLAW_AGENTS_DEFAULTS_NAME"`            // assistant name for prompts, the CLI and channels; empty keeps "picoclaw"
+	Persona                   string                 `json:"persona,omitempty"                env:"PICOCLAW_AGENTS_DEFAULTS_PERSONA"`         // short self-description added after "You are {name}"
+	SafeMode                  bool                   `json:"safe_mode,omitempty"              env:"PICOCLAW_AGENTS_DEFAULTS_SAFE_MODE"`       // only read-only tools may run
+	FailureMessage            string                 `json:"failure_message,omitempty"        env:"PICOCLAW_AGENTS_DEFAULTS_FAILURE_MESSAGE"` // reply when every model fails; {reason} and {hint} are filled in
+	SplitOnMarker             bool                   `json:"split_on_marker"                  env:"PICOCLAW_AGENTS_DEFAULTS_SPLIT_ON_MARKER"` // split messages on <|[SPLIT]|> marker
+	ContextManager            string                 `json:"context_manager,omitempty"        env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_MANAGER"`
+	ContextManagerConfig      json.RawMessage        `json:"context_manager_config,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_MANAGER_CONFIG"`
+	SessionStore              string                 `json:"session_store,omitempty"          env:"PICOCLAW_AGENTS_DEFAULTS_SESSION_STORE"` // "jsonl" (default) or "sqlite"
+	TurnProfile               TurnProfileConfig      `json:"turn_profile,omitempty"`
+	MatchUserLanguage         bool                   `json:"match_user_language,omitempty"    env:"PICOCLAW_AGENTS_DEFAULTS_MATCH_USER_LANGUAGE"`
+	MaxLLMRetries             int                    `json:"max_llm_retries,omitempty"        env:"PICOCLAW_AGENTS_DEFAULTS_MAX_LLM_RETRIES"`
+	LLMRetryBackoffSecs       int                    `json:"llm_retry_backoff_secs,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_LLM_RETRY_BACKOFF_SECS"`
+	FallbackOnRefusal         bool                   `json:"fallback_on_refusal,omitempty"    env:"PICOCLAW_AGENTS_DEFAULTS_FALLBACK_ON_REFUSAL"`
+	MaxContinuations          *int                   `json:"max_continuations,omitempty"      env:"PICOCLAW_AGENTS_DEFAULTS_MAX_CONTINUATIONS"`          // follow-up calls when a reply stops at max_tokens; 0 disables
+	MaxConcurrentSubagents    int                    `json:"max_concurrent_subagents,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_MAX_CONCURRENT_SUBAGENTS"` // per agent; 0 = unlimited
+	RejectExcessSubagents     bool                   `json:"reject_excess_subagents,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_REJECT_EXCESS_SUBAGENTS"`   // fail spawns at the limit instead of queueing them
+	AsyncToolFollowUp         *bool                  `json:"async_tool_follow_up,omitempty"   env:"PICOCLAW_AGENTS_DEFAULTS_ASYNC_TOOL_FOLLOW_UP"`
+	ToolCorrectionRetries     *int                   `json:"tool_correction_retries,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_TOOL_CORRECTION_RETRIES"` // per tool and turn; 0 disables
+	ToolCallRepair            *bool                  `json:"tool_call_repair,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_TOOL_CALL_REPAIR"`               // fix almost-JSON tool arguments; default true
+	LogRedaction              LogRedactionConfig     `json:"log_redaction,omitzero"`
+	QuietHours                QuietHoursConfig       `json:"quiet_hours,omitzero"`
+	Guardrails                GuardrailsConfig       `json:"guardrails,omitzero"`
+	Attachments               AttachmentsConfig      `json:"attachments,omitzero"`
+
+	// ModelProfiles overrides request settings per model name or glob.
+	ModelProfiles ModelProfiles `json:"model_profiles,omitempty"`
+
+	// Aliases expands a leading trigger word into a canned prompt.
+	Aliases MessageAliases `json:"aliases,omitempty"`
+}
+
+// LogRedactionConfig controls what is scrubbed from log output before it is
+// written. Mode is "moderate" (default: credential-like strings and the
+// configured secrets), "strict" (also email addresses and phone numbers) or
+// "off". Patterns are extra regular expressions to scrub. MaxContentLength
+// caps each logged string; 0 means no cap.
+type LogRedactionConfig struct {
+	Mode             string   `json:"mode,omitempty"               env:"PICOCLAW_AGENTS_DEFAULTS_LOG_REDACTION_MODE"`
+	Patterns         []string `json:"patterns,omitempty"`
+	MaxContentLength int      `json:"max_content_length,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_LOG_REDACTION_MAX_CONTENT_LENGTH"`
+}
+
+// QuietHoursConfig is a daily window, in the agent timezone, during which
+// messages the agent sends on its own (heartbeat, cron, device events) are
+// held back. Start and End are "HH:MM"; a window may wrap past midnight.
+// Mode "queue" (default) delivers held messages, merged per chat, when the
+// window ends; "drop" discards them. Replies to users are never held.
+type QuietHoursConfig struct {
+	Start string `json:"start,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_QUIET_HOURS_START"`
+	End   string `json:"end,omitempty"   env:"PICOCLAW_AGENTS_DEFAULTS_QUIET_HOURS_END"`
+	Mode  string `json:"mode,omitempty"  env:"PICOCLAW_AGENTS_DEFAULTS_QUIET_HOURS_MODE"`
+}
+
+// Guardrail modes for GuardrailsConfig.Mode.
+const (
+	GuardrailModeRules = "rules"
+	GuardrailModeModel = "model"
+)
+
+// GuardrailsConfig screens inbound user messages for prompt injection and
+// policy violations before the agent processes them. Mode "rules" (default)
+// matches built-in injection phrases plus Patterns; "model" asks the summary
+// model, or the agent's model, to classify each message. A tripped message
+// gets Refusal as its reply. Channels with skip_guardrails are not screened.
+type GuardrailsConfig struct {
+	Enabled  bool     `json:"enabled,omitempty"  env:"PICOCLAW_AGENTS_DEFAULTS_GUARDRAILS_ENABLED"`
+	Mode     string   `json:"mode,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_GUARDRAILS_MODE"`
+	Patterns []string `json:"patterns,omitempty"`
+	Refusal  string   `json:"refusal,omitempty"  env:"PICOCLAW_AGENTS_DEFAULTS_GUARDRAILS_REFUSAL"`
+}
+
+// Active reports whether t's wall-clock time falls inside the window. An
+// unset, malformed or empty window is never active.
+func (q QuietHoursConfig) Active(t time.Time) bool {
+	start, okStart := parseClock(q.Start)
+	end, okEnd := parseClock(q.End)
+	if !okStart || !okEnd || start == end {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// Drop reports whether held messages are discarded instead of queued.
+func (q QuietHoursConfig) Drop() bool {
+	return strings.EqualFold(strings.TrimSpace(q.Mode), "drop")
+}
+
+// parseClock turns "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// AttachmentsConfig stages files users send on channels in the workspace,
+// under attachments/<session>/, so file, exec and OCR tools can work on them.
+// Files larger than MaxSize bytes (default max_media_size) are not staged, nor
+// are files whose extension or MIME type is missing from AllowedTypes; an
+// empty list allows every type. Entries are extensions (".csv") or MIME types,
+// which may end in "/*" ("image/*"). A session's staged files are deleted when
+// the session is cleared.
+type AttachmentsConfig struct {
+	Enabled      bool     `json:"enabled,omitempty"  env:"PICOCLAW_AGENTS_DEFAULTS_ATTACHMENTS_ENABLED"`
+	MaxSize      int      `json:"max_size,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_ATTACHMENTS_MAX_SIZE"`
+	AllowedTypes []string `json:"allowed_types,omitempty"`
+}
+
+// Allows reports whether a file with this name and MIME type may be staged.
+func (a AttachmentsConfig) Allows(filename, mimeType string) bool {
+	if len(a.AllowedTypes) == 0 {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = strings.TrimSpace(mimeType[:i])
+	}
+	for _, allowed := range a.AllowedTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		switch {
+		case allowed == "":
+		case strings.HasPrefix(allowed, "."):
+			if ext == allowed {
+				return true
+			}
+		case strings.HasSuffix(allowed, "/*"):
+			if mimeType != "" && strings.HasPrefix(mimeType, strings.TrimSuffix(allowed, "*")) {
+				return true
+			}
+		case strings.Contains(allowed, "/"):
+			if mimeType == allowed {
+				return true
+			}
+		default:
+			if ext == "."+allowed {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 const DefaultMaxMediaSize = 20 * 1024 * 1024 // 20 MB
@@ -458,6 +648,42 @@ func (d *AgentDefaults) GetMaxMediaSize() int {
 	return DefaultMaxMediaSize
 }
 
+// GetAttachmentMaxSize returns the largest attachment, in bytes, that is
+// staged in the workspace.
+func (d *AgentDefaults) GetAttachmentMaxSize() int {
+	if d.Attachments.MaxSize > 0 {
+		return d.Attachments.MaxSize
+	}
+	return d.GetMaxMediaSize()
+}
+
+// DefaultToolCorrectionRetries is how many corrective retries a tool gets
+// per turn when ToolCorrectionRetries is unset.
+const DefaultToolCorrectionRetries = 2
+
+// GetToolCorrectionRetries returns how many times a tool call that failed
+// with correctable arguments may be retried in one turn. 0 disables the
+// correction hints and the per-turn cut-off.
+func (d *AgentDefaults) GetToolCorrectionRetries() int {
+	if d.ToolCorrectionRetries != nil {
+		return max(*d.ToolCorrectionRetries, 0)
+	}
+	return DefaultToolCorrectionRetries
+}
+
+// DefaultMaxContinuations is how many follow-up calls may extend a reply cut
+// off by max_tokens when MaxContinuations is unset.
+const DefaultMaxContinuations = 2
+
+// GetMaxContinuations returns how many times a reply that stopped at
+// max_tokens is continued with a follow-up call. 0 disables continuation.
+func (d *AgentDefaults) GetMaxContinuations() int {
+	if d.MaxContinuations != nil {
+		return max(*d.MaxContinuations, 0)
+	}
+	return DefaultMaxContinuations
+}
+
 // GetToolFeedbackMaxArgsLength returns the max visible text length for tool argument previews.
 func (d *AgentDefaults) GetToolFeedbackMaxArgsLength() int {
 	if d.ToolFeedback.MaxArgsLength > 0 {
@@ -478,6 +704,19 @@ func (d *AgentDefaults) IsToolFeedbackSeparateMessagesEnabled() bool {
 	return d.ToolFeedback.SeparateMessages
 }
 
+// IsAsyncToolFollowUpEnabled reports whether the result of a background tool
+// call starts a follow-up turn. When false, the result is only added to the
+// session history. Default: true.
+func (d *AgentDefaults) IsAsyncToolFollowUpEnabled() bool {
+	return d.AsyncToolFollowUp == nil || *d.AsyncToolFollowUp
+}
+
+// IsToolCallRepairEnabled reports whether tool call arguments that are not
+// valid JSON get a repair pass before the call is rejected. Default: true.
+func (d *AgentDefaults) IsToolCallRepairEnabled() bool {
+	return d.ToolCallRepair == nil || *d.ToolCallRepair
+}
+
 // GetModelName returns the effective model name for the agent defaults.
 // It prefers the new "model_name" field but falls back to "model" for backward compatibility.
 func (d *AgentDefaults) GetModelName() string {
@@ -495,6 +734,20 @@ type TypingConfig struct {
 	Enabled bool `json:"enabled,omitempty"`
 }
 
+// ChannelRetryConfig tunes how failed outbound sends to a channel are
+// retried. Zero values keep the built-in defaults (3 retries, 500ms backoff
+// doubling up to 8s).
+type ChannelRetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt; 0 disables
+	// retrying.
+	MaxRetries    *int `json:"max_retries,omitempty"`
+	BaseBackoffMs int  `json:"base_backoff_ms,omitempty"`
+	MaxBackoffMs  int  `json:"max_backoff_ms,omitempty"`
+	// DeadLetter keeps messages that still failed after all retries on disk
+	// and sends them again when the channel next starts.
+	DeadLetter bool `json:"dead_letter,omitempty"`
+}
+
 // PlaceholderConfig controls placeholder message behavior (Phase 10).
 type PlaceholderConfig struct {
 	Enabled bool                `json:"enabled"`
@@ -560,9 +813,10 @@ type FeishuSettings struct {
 }
 
 type DiscordSettings struct {
-	Token       SecureString `json:"token,omitzero" yaml:"token,omitempty" env:"PICOCLAW_CHANNELS_DISCORD_TOKEN"`
-	Proxy       string       `json:"proxy"          yaml:"-"               env:"PICOCLAW_CHANNELS_DISCORD_PROXY"`
-	MentionOnly bool         `json:"mention_only"   yaml:"-"               env:"PICOCLAW_CHANNELS_DISCORD_MENTION_ONLY"`
+	Token         SecureString `json:"token,omitzero"           yaml:"token,omitempty" env:"PICOCLAW_CHANNELS_DISCORD_TOKEN"`
+	Proxy         string       `json:"proxy"                    yaml:"-"               env:"PICOCLAW_CHANNELS_DISCORD_PROXY"`
+	MentionOnly   bool         `json:"mention_only"             yaml:"-"               env:"PICOCLAW_CHANNELS_DISCORD_MENTION_ONLY"`
+	SlashCommands bool         `json:"slash_commands,omitempty" yaml:"-"               env:"PICOCLAW_CHANNELS_DISCORD_SLASH_COMMANDS"`
 }
 
 type MaixCamSettings struct {
@@ -576,6 +830,8 @@ type QQSettings struct {
 	MaxMessageLength     int          `json:"max_message_length"       yaml:"-"                    env:"PICOCLAW_CHANNELS_QQ_MAX_MESSAGE_LENGTH"`
 	MaxBase64FileSizeMiB int64        `json:"max_base64_file_size_mib" yaml:"-"                    env:"PICOCLAW_CHANNELS_QQ_MAX_BASE64_FILE_SIZE_MIB"`
 	SendMarkdown         bool         `json:"send_markdown"            yaml:"-"                    env:"PICOCLAW_CHANNELS_QQ_SEND_MARKDOWN"`
+	Mode                 string       `json:"mode,omitempty"           yaml:"-"                    env:"PICOCLAW_CHANNELS_QQ_MODE"`
+	WebhookPath          string       `json:"webhook_path,omitempty"   yaml:"-"                    env:"PICOCLAW_CHANNELS_QQ_WEBHOOK_PATH"`
 }
 
 type DingTalkSettings struct {
@@ -586,6 +842,7 @@ type DingTalkSettings struct {
 type SlackSettings struct {
 	BotToken SecureString `json:"bot_token,omitzero" yaml:"bot_token,omitempty" env:"PICOCLAW_CHANNELS_SLACK_BOT_TOKEN"`
 	AppToken SecureString `json:"app_token,omitzero" yaml:"app_token,omitempty" env:"PICOCLAW_CHANNELS_SLACK_APP_TOKEN"`
+	Proxy    string       `json:"proxy,omitempty"   yaml:"-"                   env:"PICOCLAW_CHANNELS_SLACK_PROXY"`
 }
 
 type MatrixSettings struct {
@@ -605,6 +862,17 @@ type LINESettings struct {
 	WebhookHost        string       `json:"webhook_host"                  yaml:"-"                              env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_HOST"`
 	WebhookPort        int          `json:"webhook_port"                  yaml:"-"                              env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_PORT"`
 	WebhookPath        string       `json:"webhook_path"                  yaml:"-"                              env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_PATH"`
+	Proxy              string       `json:"proxy,omitempty"               yaml:"-"                              env:"PICOCLAW_CHANNELS_LINE_PROXY"`
+}
+
+// SMSSettings configures the Twilio SMS channel.
+type SMSSettings struct {
+	AccountSID  string       `json:"account_sid"            yaml:"-"                    env:"PICOCLAW_CHANNELS_SMS_ACCOUNT_SID"`
+	AuthToken   SecureString `json:"auth_token,omitzero"    yaml:"auth_token,omitempty" env:"PICOCLAW_CHANNELS_SMS_AUTH_TOKEN"`
+	FromNumber  string       `json:"from_number"            yaml:"-"                    env:"PICOCLAW_CHANNELS_SMS_FROM_NUMBER"`
+	WebhookPath string       `json:"webhook_path,omitempty" yaml:"-"                    env:"PICOCLAW_CHANNELS_SMS_WEBHOOK_PATH"`
+	WebhookURL  string       `json:"webhook_url,omitempty"  yaml:"-"                    env:"PICOCLAW_CHANNELS_SMS_WEBHOOK_URL"`
+	Proxy       string       `json:"proxy,omitempty"        yaml:"-"                    env:"PICOCLAW_CHANNELS_SMS_PROXY"`
 }
 
 type OneBotSettings struct {
@@ -643,8 +911,14 @@ func (c *WeixinSettings) SetToken(token string) {
 	c.Token = *NewSecureString(token)
 }
 
+// PicoSettings configures the Pico WebSocket server channel. Secret enables
+// HMAC-signed handshakes (a Unix timestamp plus its HMAC-SHA256 under the
+// secret); once it is set, plain Token auth is rejected unless
+// LegacyTokenAuth is on.
 type PicoSettings struct {
 	Token           SecureString    `json:"token,omitzero"              yaml:"token,omitempty" env:"PICOCLAW_CHANNELS_PICO_TOKEN"`
+	Secret          SecureString    `json:"secret,omitzero"             yaml:"secret,omitempty" env:"PICOCLAW_CHANNELS_PICO_SECRET"`
+	LegacyTokenAuth bool            `json:"legacy_token_auth,omitempty" yaml:"-"`
 	AllowTokenQuery bool            `json:"allow_token_query,omitempty" yaml:"-"`
 	AllowOrigins    []string        `json:"allow_origins,omitempty"     yaml:"-"`
 	Streaming       StreamingConfig `json:"streaming,omitzero"          yaml:"-"`
@@ -662,6 +936,7 @@ func (c *PicoSettings) SetToken(token string) {
 type PicoClientSettings struct {
 	URL          string       `json:"url"                     yaml:"-"               env:"PICOCLAW_CHANNELS_PICO_CLIENT_URL"`
 	Token        SecureString `json:"token,omitzero"          yaml:"token,omitempty" env:"PICOCLAW_CHANNELS_PICO_CLIENT_TOKEN"`
+	Secret       SecureString `json:"secret,omitzero"         yaml:"secret,omitempty" env:"PICOCLAW_CHANNELS_PICO_CLIENT_SECRET"`
 	SessionID    string       `json:"session_id,omitempty"    yaml:"-"`
 	PingInterval int          `json:"ping_interval,omitempty" yaml:"-"`
 	ReadTimeout  int          `json:"read_timeout,omitempty"  yaml:"-"`
@@ -725,9 +1000,69 @@ type SlackWebhookTarget struct {
 	IconEmoji  string       `json:"icon_emoji,omitempty" yaml:"-"`
 }
 
+// HeartbeatConfig controls the periodic heartbeat. DefaultChannel and
+// DefaultChatID name the chat that receives its output; when unset, the last
+// chat a user wrote from is used. Recipients, as "channel:chat_id" entries,
+// send the output to several chats instead.
 type HeartbeatConfig struct {
-	Enabled  bool `json:"enabled"  env:"PICOCLAW_HEARTBEAT_ENABLED"`
-	Interval int  `json:"interval" env:"PICOCLAW_HEARTBEAT_INTERVAL"` // minutes, min 5
+	Enabled        bool     `json:"enabled"                   env:"PICOCLAW_HEARTBEAT_ENABLED"`
+	Interval       int      `json:"interval"                  env:"PICOCLAW_HEARTBEAT_INTERVAL"` // minutes, min 5
+	DefaultChannel string   `json:"default_channel,omitempty" env:"PICOCLAW_HEARTBEAT_DEFAULT_CHANNEL"`
+	DefaultChatID  string   `json:"default_chat_id,omitempty" env:"PICOCLAW_HEARTBEAT_DEFAULT_CHAT_ID"`
+	Recipients     []string `json:"recipients,omitempty"      env:"PICOCLAW_HEARTBEAT_RECIPIENTS"`
+}
+
+// ProvidersConfig holds settings shared by every model provider.
+type ProvidersConfig struct {
+	HTTP ProviderHTTPConfig `json:"http,omitzero"`
+	// OpenRouter applies to every model_list entry using the openrouter
+	// provider.
+	OpenRouter OpenRouterConfig `json:"openrouter,omitzero"`
+}
+
+// OpenRouterConfig holds OpenRouter's provider routing preferences, sent as
+// the request's "provider" object. ProviderOrder lists upstream providers to
+// try first (e.g. "anthropic", "together"), AllowFallbacks set to false stops
+// OpenRouter from using any other upstream, and DataCollection "deny" limits
+// routing to upstreams that do not store or train on prompts. A model's own
+// extra_body "provider" object replaces these settings.
+type OpenRouterConfig struct {
+	ProviderOrder  []string `json:"provider_order,omitempty"`
+	AllowFallbacks *bool    `json:"allow_fallbacks,omitempty"`
+	DataCollection string   `json:"data_collection,omitempty"`
+}
+
+// RoutingPreferences returns the "provider" request object, or nil when no
+// preference is set.
+func (c OpenRouterConfig) RoutingPreferences() map[string]any {
+	prefs := make(map[string]any, 3)
+	var order []string
+	for _, name := range c.ProviderOrder {
+		if name = strings.TrimSpace(name); name != "" {
+			order = append(order, name)
+		}
+	}
+	if len(order) > 0 {
+		prefs["order"] = order
+	}
+	if c.AllowFallbacks != nil {
+		prefs["allow_fallbacks"] = *c.AllowFallbacks
+	}
+	if dc := strings.ToLower(strings.TrimSpace(c.DataCollection)); dc != "" {
+		prefs["data_collection"] = dc
+	}
+	if len(prefs) == 0 {
+		return nil
+	}
+	return prefs
+}
+
+// ProviderHTTPConfig tunes the connection pool shared by provider HTTP
+// clients. IdleTimeout is in seconds; zero values keep the built-in defaults.
+type ProviderHTTPConfig struct {
+	MaxIdleConns        int `json:"max_idle_conns,omitempty"          env:"PICOCLAW_PROVIDERS_HTTP_MAX_IDLE_CONNS"`
+	MaxIdleConnsPerHost int `json:"max_idle_conns_per_host,omitempty" env:"PICOCLAW_PROVIDERS_HTTP_MAX_IDLE_CONNS_PER_HOST"`
+	IdleTimeout         int `json:"idle_timeout,omitempty"            env:"PICOCLAW_PROVIDERS_HTTP_IDLE_TIMEOUT"`
 }
 
 type DevicesConfig struct {
@@ -779,6 +1114,11 @@ type ModelConfig struct {
 	RequestTimeout      int                  `json:"request_timeout,omitempty"`
 	ThinkingLevel       string               `json:"thinking_level,omitempty"`        // Extended thinking: off|low|medium|high|xhigh|adaptive
 	ToolSchemaTransform string               `json:"tool_schema_transform,omitempty"` // Optional tool schema compatibility transform (e.g. "simple")
+	PromptCaching       bool                 `json:"prompt_caching,omitempty"`        // Mark the static system prompt with cache_control (anthropic-messages)
+	ThinkingBudget      *int                 `json:"thinking_budget,omitempty"`       // Gemini thinking token budget (-1 dynamic, 0 off); overrides thinking_level
+	EnableWebSearch     bool                 `json:"enable_web_search,omitempty"`     // Zhipu/Z.ai built-in web_search tool, used when tools.web.prefer_native is set
+	AudioInput          bool                 `json:"audio_input,omitempty"`           // Model accepts audio; voice messages are sent to it as audio when there is no transcriber
+	StopSequences       []string             `json:"stop_sequences,omitempty"`        // Sent as "stop" to OpenAI-compatible APIs
 	Streaming           ModelStreamingConfig `json:"streaming,omitzero"`              // Opt-in for provider streaming on this model entry
 	ExtraBody           map[string]any       `json:"extra_body,omitempty"`            // Additional fields to inject into request body
 	CustomHeaders       map[string]string    `json:"custom_headers,omitempty"`        // Additional headers to inject into every HTTP request
@@ -1025,12 +1365,165 @@ type WebToolsConfig struct {
 	FetchLimitBytes      int64               `yaml:"-" json:"fetch_limit_bytes,omitempty"      env:"PICOCLAW_TOOLS_WEB_FETCH_LIMIT_BYTES"`
 	Format               string              `yaml:"-" json:"format,omitempty"                 env:"PICOCLAW_TOOLS_WEB_FORMAT"`
 	PrivateHostWhitelist FlexibleStringSlice `yaml:"-" json:"private_host_whitelist,omitempty" env:"PICOCLAW_TOOLS_WEB_PRIVATE_HOST_WHITELIST"`
+	// FetchAllowlist and FetchDenylist restrict the domains web_fetch may
+	// request ("example.com", or "*.example.com" for the domain and its
+	// subdomains). A non-empty allowlist permits only listed domains.
+	FetchAllowlist FlexibleStringSlice `yaml:"-" json:"fetch_allowlist,omitempty" env:"PICOCLAW_TOOLS_WEB_FETCH_ALLOWLIST"`
+	FetchDenylist  FlexibleStringSlice `yaml:"-" json:"fetch_denylist,omitempty"  env:"PICOCLAW_TOOLS_WEB_FETCH_DENYLIST"`
+	// FetchMaxRedirects caps the redirects web_fetch follows (0 keeps the
+	// default of 5). FetchContentTypes lists the media types it downloads,
+	// e.g. "text/*" or "application/json".
+	FetchMaxRedirects int                 `yaml:"-" json:"fetch_max_redirects,omitempty" env:"PICOCLAW_TOOLS_WEB_FETCH_MAX_REDIRECTS"`
+	FetchContentTypes FlexibleStringSlice `yaml:"-" json:"fetch_content_types,omitempty" env:"PICOCLAW_TOOLS_WEB_FETCH_CONTENT_TYPES"`
+	// SearchTimeout bounds each search backend, in seconds (0 keeps the
+	// built-in timeouts). Ready backends are queried concurrently; with
+	// MergeResults their results are combined and deduplicated, otherwise the
+	// highest-priority backend that answers wins.
+	SearchTimeout int  `yaml:"-" json:"search_timeout,omitempty" env:"PICOCLAW_TOOLS_WEB_SEARCH_TIMEOUT"`
+	MergeResults  bool `yaml:"-" json:"merge_results,omitempty"  env:"PICOCLAW_TOOLS_WEB_MERGE_RESULTS"`
+}
+
+// HTTPToolConfig configures the http_request tool. It is off by default
+// because, unlike web_fetch, it can send data to arbitrary endpoints.
+type HTTPToolConfig struct {
+	ToolConfig       `                    envPrefix:"PICOCLAW_TOOLS_HTTP_"`
+	AllowedHosts     FlexibleStringSlice `                                 json:"allowed_hosts,omitempty"      env:"PICOCLAW_TOOLS_HTTP_ALLOWED_HOSTS"`
+	MaxResponseBytes int64               `                                 json:"max_response_bytes,omitempty" env:"PICOCLAW_TOOLS_HTTP_MAX_RESPONSE_BYTES"`
+}
+
+// ImageGenToolConfig configures the image_gen tool. Provider is "openai"
+// (default) or "stable_diffusion"; BaseURL points at a self-hosted
+// AUTOMATIC1111-compatible server or an OpenAI-compatible gateway.
+type ImageGenToolConfig struct {
+	ToolConfig `yaml:"-" envPrefix:"PICOCLAW_TOOLS_IMAGE_GEN_"`
+	Provider   string       `json:"provider,omitempty" yaml:"-"                 env:"PICOCLAW_TOOLS_IMAGE_GEN_PROVIDER"`
+	APIKey     SecureString `json:"api_key,omitzero"   yaml:"api_key,omitempty" env:"PICOCLAW_TOOLS_IMAGE_GEN_API_KEY"`
+	Model      string       `json:"model,omitempty"    yaml:"-"                 env:"PICOCLAW_TOOLS_IMAGE_GEN_MODEL"`
+	BaseURL    string       `json:"base_url,omitempty" yaml:"-"                 env:"PICOCLAW_TOOLS_IMAGE_GEN_BASE_URL"`
+}
+
+// MemoryToolConfig configures the memory tool's key-value store. MaxBytes
+// caps the combined size of keys and values; InjectKeys lists the stored keys
+// in the system prompt so the model knows what it can recall.
+type MemoryToolConfig struct {
+	ToolConfig `envPrefix:"PICOCLAW_TOOLS_MEMORY_"`
+	MaxBytes   int  `json:"max_bytes,omitempty" env:"PICOCLAW_TOOLS_MEMORY_MAX_BYTES"`
+	InjectKeys bool `json:"inject_keys"         env:"PICOCLAW_TOOLS_MEMORY_INJECT_KEYS"`
+}
+
+// OCRToolConfig configures the ocr tool. Engine "tesseract" (default) runs
+// the local tesseract binary; "ocr_space" sends images to the OCR.space API.
+type OCRToolConfig struct {
+	ToolConfig    `yaml:"-" envPrefix:"PICOCLAW_TOOLS_OCR_"`
+	Engine        string       `json:"engine,omitempty"         yaml:"-"                 env:"PICOCLAW_TOOLS_OCR_ENGINE"`
+	Languages     string       `json:"languages,omitempty"      yaml:"-"                 env:"PICOCLAW_TOOLS_OCR_LANGUAGES"`
+	TesseractPath string       `json:"tesseract_path,omitempty" yaml:"-"                 env:"PICOCLAW_TOOLS_OCR_TESSERACT_PATH"`
+	APIKey        SecureString `json:"api_key,omitzero"         yaml:"api_key,omitempty" env:"PICOCLAW_TOOLS_OCR_API_KEY"`
+	BaseURL       string       `json:"base_url,omitempty"       yaml:"-"                 env:"PICOCLAW_TOOLS_OCR_BASE_URL"`
+}
+
+// WeatherToolConfig configures the weather tool. Open-Meteo is the only
+// provider and needs no key; APIKey is for its commercial plan.
+type WeatherToolConfig struct {
+	ToolConfig   `yaml:"-" envPrefix:"PICOCLAW_TOOLS_WEATHER_"`
+	Provider     string       `json:"provider,omitempty"      yaml:"-"                 env:"PICOCLAW_TOOLS_WEATHER_PROVIDER"`
+	APIKey       SecureString `json:"api_key,omitzero"        yaml:"api_key,omitempty" env:"PICOCLAW_TOOLS_WEATHER_API_KEY"`
+	BaseURL      string       `json:"base_url,omitempty"      yaml:"-"                 env:"PICOCLAW_TOOLS_WEATHER_BASE_URL"`
+	GeocodingURL string       `json:"geocoding_url,omitempty" yaml:"-"                 env:"PICOCLAW_TOOLS_WEATHER_GEOCODING_URL"`
+	Units        string       `json:"units,omitempty"         yaml:"-"                 env:"PICOCLAW_TOOLS_WEATHER_UNITS"`
+}
+
+// TranslateToolConfig configures the translate tool. The "llm" engine sends
+// one request to Model (a model_list name; empty uses the agent's model), and
+// "libretranslate" calls the LibreTranslate server at BaseURL instead.
+type TranslateToolConfig struct {
+	ToolConfig `yaml:"-" envPrefix:"PICOCLAW_TOOLS_TRANSLATE_"`
+	Engine     string       `json:"engine,omitempty"   yaml:"-"                 env:"PICOCLAW_TOOLS_TRANSLATE_ENGINE"`
+	Model      string       `json:"model,omitempty"    yaml:"-"                 env:"PICOCLAW_TOOLS_TRANSLATE_MODEL"`
+	BaseURL    string       `json:"base_url,omitempty" yaml:"-"                 env:"PICOCLAW_TOOLS_TRANSLATE_BASE_URL"`
+	APIKey     SecureString `json:"api_key,omitzero"   yaml:"api_key,omitempty" env:"PICOCLAW_TOOLS_TRANSLATE_API_KEY"`
+}
+
+// FullResultsToolConfig keeps the complete text of tool output that exec,
+// git and web_fetch truncated, so the get_full_result tool and the :expand
+// chat command can read it back by the handle in the truncation marker.
+// Results expire after TTLSeconds and the oldest are evicted once MaxBytes is
+// held in total.
+type FullResultsToolConfig struct {
+	ToolConfig `yaml:"-" envPrefix:"PICOCLAW_TOOLS_FULL_RESULTS_"`
+	TTLSeconds int `json:"ttl_seconds,omitempty" env:"PICOCLAW_TOOLS_FULL_RESULTS_TTL_SECONDS"`
+	MaxBytes   int `json:"max_bytes,omitempty"   env:"PICOCLAW_TOOLS_FULL_RESULTS_MAX_BYTES"`
+}
+
+// EmailToolConfig configures the send_email tool, which sends mail over SMTP.
+// Port 465 uses implicit TLS; other ports upgrade with STARTTLS when the
+// server offers it. AllowedDomains, when set, limits recipients to those
+// domains and their subdomains. RequireConfirm makes the model pass
+// confirm=true, so it asks the user first and approval hooks can hold the call.
+type EmailToolConfig struct {
+	ToolConfig     `yaml:"-" envPrefix:"PICOCLAW_TOOLS_EMAIL_"`
+	SMTPHost       string       `json:"smtp_host,omitempty"       yaml:"-"                  env:"PICOCLAW_TOOLS_EMAIL_SMTP_HOST"`
+	SMTPPort       int          `json:"smtp_port,omitempty"       yaml:"-"                  env:"PICOCLAW_TOOLS_EMAIL_SMTP_PORT"`
+	Username       string       `json:"username,omitempty"        yaml:"-"                  env:"PICOCLAW_TOOLS_EMAIL_USERNAME"`
+	Password       SecureString `json:"password,omitzero"         yaml:"password,omitempty" env:"PICOCLAW_TOOLS_EMAIL_PASSWORD"`
+	From           string       `json:"from,omitempty"            yaml:"-"                  env:"PICOCLAW_TOOLS_EMAIL_FROM"`
+	AllowedDomains []string     `json:"allowed_domains,omitempty" yaml:"-"                  env:"PICOCLAW_TOOLS_EMAIL_ALLOWED_DOMAINS"`
+	RequireConfirm bool         `json:"require_confirm"           yaml:"-"                  env:"PICOCLAW_TOOLS_EMAIL_REQUIRE_CONFIRM"`
+}
+
+// PythonToolConfig configures the python tool, which runs a script in a
+// scratch directory with a timeout and, where the platform allows it, a
+// memory cap, no network and no view of other files. It runs arbitrary code,
+// so RequireApproval is on by default: each script is shown in the chat and
+// runs only after the user replies /approve.
+type PythonToolConfig struct {
+	ToolConfig      `yaml:"-" envPrefix:"PICOCLAW_TOOLS_PYTHON_"`
+	InterpreterPath string `json:"interpreter_path,omitempty" env:"PICOCLAW_TOOLS_PYTHON_INTERPRETER_PATH"`
+	AllowNetwork    bool   `json:"allow_network"              env:"PICOCLAW_TOOLS_PYTHON_ALLOW_NETWORK"`
+	TimeoutSeconds  int    `json:"timeout_seconds,omitempty"  env:"PICOCLAW_TOOLS_PYTHON_TIMEOUT_SECONDS"`
+	MemoryLimitMB   int    `json:"memory_limit_mb,omitempty"  env:"PICOCLAW_TOOLS_PYTHON_MEMORY_LIMIT_MB"`
+	RequireApproval bool   `json:"require_approval"           env:"PICOCLAW_TOOLS_PYTHON_REQUIRE_APPROVAL"`
+}
+
+// MarketToolConfig configures the market tool. Stocks use Stooq unless
+// StockProvider is "alpha_vantage"; crypto always uses CoinGecko.
+type MarketToolConfig struct {
+	ToolConfig    `yaml:"-" envPrefix:"PICOCLAW_TOOLS_MARKET_"`
+	StockProvider string       `json:"stock_provider,omitempty" yaml:"-"                        env:"PICOCLAW_TOOLS_MARKET_STOCK_PROVIDER"`
+	StockAPIKey   SecureString `json:"stock_api_key,omitzero"   yaml:"stock_api_key,omitempty"  env:"PICOCLAW_TOOLS_MARKET_STOCK_API_KEY"`
+	CryptoAPIKey  SecureString `json:"crypto_api_key,omitzero"  yaml:"crypto_api_key,omitempty" env:"PICOCLAW_TOOLS_MARKET_CRYPTO_API_KEY"`
+	Currency      string       `json:"currency,omitempty"       yaml:"-"                        env:"PICOCLAW_TOOLS_MARKET_CURRENCY"`
+	CacheSeconds  int          `json:"cache_seconds,omitempty"  yaml:"-"                        env:"PICOCLAW_TOOLS_MARKET_CACHE_SECONDS"`
+}
+
+// ExternalToolConfig registers a tool that runs Command for each call. The
+// call arguments are written to its stdin as a JSON object, and it prints a
+// tool result such as {"for_llm": "...", "is_error": false} on stdout.
+// Schema is the JSON Schema of the arguments shown to the model.
+type ExternalToolConfig struct {
+	Name           string            `json:"name"`
+	Description    string            `json:"description,omitempty"`
+	Command        string            `json:"command"`
+	Args           []string          `json:"args,omitempty"`
+	Env            map[string]string `json:"env,omitempty"`
+	Schema         map[string]any    `json:"schema,omitempty"`
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"`
+}
+
+// GitToolConfig configures the git tool. Push and hard reset can publish or
+// discard work, so each needs its own opt-in.
+type GitToolConfig struct {
+	ToolConfig `     envPrefix:"PICOCLAW_TOOLS_GIT_"`
+	AllowPush  bool `                                json:"allow_push"  env:"PICOCLAW_TOOLS_GIT_ALLOW_PUSH"`
+	AllowReset bool `                                json:"allow_reset" env:"PICOCLAW_TOOLS_GIT_ALLOW_RESET"`
 }
 
 type CronToolsConfig struct {
-	ToolConfig         `     envPrefix:"PICOCLAW_TOOLS_CRON_"`
-	ExecTimeoutMinutes int  `                                 json:"exec_timeout_minutes" env:"PICOCLAW_TOOLS_CRON_EXEC_TIMEOUT_MINUTES"` // 0 means no timeout
-	AllowCommand       bool `                                 json:"allow_command"        env:"PICOCLAW_TOOLS_CRON_ALLOW_COMMAND"`
+	ToolConfig         `       envPrefix:"PICOCLAW_TOOLS_CRON_"`
+	ExecTimeoutMinutes int    `                                 json:"exec_timeout_minutes"      env:"PICOCLAW_TOOLS_CRON_EXEC_TIMEOUT_MINUTES"` // 0 means no timeout
+	AllowCommand       bool   `                                 json:"allow_command"             env:"PICOCLAW_TOOLS_CRON_ALLOW_COMMAND"`
+	DefaultChannel     string `                                 json:"default_channel,omitempty" env:"PICOCLAW_TOOLS_CRON_DEFAULT_CHANNEL"`
+	DefaultChatID      string `                                 json:"default_chat_id,omitempty" env:"PICOCLAW_TOOLS_CRON_DEFAULT_CHAT_ID"`
 }
 
 type ExecConfig struct {
@@ -1091,12 +1584,20 @@ type ToolsConfig struct {
 	// Default: 8
 	FilterMinLength int                `json:"filter_min_length" yaml:"-"                env:"PICOCLAW_TOOLS_FILTER_MIN_LENGTH"`
 	Web             WebToolsConfig     `json:"web"               yaml:"web,omitempty"`
+	HTTP            HTTPToolConfig     `json:"http"              yaml:"-"`
+	Git             GitToolConfig      `json:"git"               yaml:"-"`
+	ImageGen        ImageGenToolConfig `json:"image_gen"         yaml:"image_gen,omitempty"`
+	OCR             OCRToolConfig      `json:"ocr"               yaml:"ocr,omitempty"`
+	Weather         WeatherToolConfig  `json:"weather"           yaml:"weather,omitempty"`
+	Market          MarketToolConfig   `json:"market"            yaml:"market,omitempty"`
 	Cron            CronToolsConfig    `json:"cron"              yaml:"-"`
 	Exec            ExecConfig         `json:"exec"              yaml:"-"`
 	Skills          SkillsToolsConfig  `json:"skills"            yaml:"skills,omitempty"`
 	MediaCleanup    MediaCleanupConfig `json:"media_cleanup"     yaml:"-"`
 	MCP             MCPConfig          `json:"mcp"               yaml:"-"`
+	Memory          MemoryToolConfig   `json:"memory"            yaml:"-"`
 	AppendFile      ToolConfig         `json:"append_file"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_APPEND_FILE_"`
+	Clipboard       ToolConfig         `json:"clipboard"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_CLIPBOARD_"`
 	EditFile        ToolConfig         `json:"edit_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_EDIT_FILE_"`
 	FindSkills      ToolConfig         `json:"find_skills"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_FIND_SKILLS_"`
 	I2C             ToolConfig         `json:"i2c"               yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_I2C_"`
@@ -1104,7 +1605,10 @@ type ToolsConfig struct {
 	ListDir         ToolConfig         `json:"list_dir"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_LIST_DIR_"`
 	LoadImage       ToolConfig         `json:"load_image"        yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_LOAD_IMAGE_"`
 	Message         MessageToolsConfig `json:"message"           yaml:"-"`
+	Plan            ToolConfig         `json:"plan"              yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_PLAN_"`
 	ReadFile        ReadFileToolConfig `json:"read_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_READ_FILE_"`
+	Reminder        ToolConfig         `json:"reminder"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_REMINDER_"`
+	Screenshot      ToolConfig         `json:"screenshot"        yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SCREENSHOT_"`
 	Serial          ToolConfig         `json:"serial"            yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SERIAL_"`
 	SendFile        ToolConfig         `json:"send_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SEND_FILE_"`
 	SendTTS         ToolConfig         `json:"send_tts"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SEND_TTS_"`
@@ -1112,8 +1616,24 @@ type ToolsConfig struct {
 	SpawnStatus     ToolConfig         `json:"spawn_status"      yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPAWN_STATUS_"`
 	SPI             ToolConfig         `json:"spi"               yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPI_"`
 	Subagent        ToolConfig         `json:"subagent"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SUBAGENT_"`
+	Timer           ToolConfig         `json:"timer"             yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_TIMER_"`
 	WebFetch        ToolConfig         `json:"web_fetch"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_WEB_FETCH_"`
 	WriteFile       ToolConfig         `json:"write_file"        yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_WRITE_FILE_"`
+
+	// External registers script-backed tools next to the built-in ones.
+	External []ExternalToolConfig `json:"external,omitempty" yaml:"-"`
+
+	// Email configures the send_email tool.
+	Email EmailToolConfig `json:"email" yaml:"email,omitempty"`
+
+	// Translate configures the translate tool.
+	Translate TranslateToolConfig `json:"translate" yaml:"translate,omitempty"`
+
+	// Python configures the python tool.
+	Python PythonToolConfig `json:"python" yaml:"-"`
+
+	// FullResults keeps truncated tool output for get_full_result and :expand.
+	FullResults FullResultsToolConfig `json:"full_results" yaml:"-"`
 }
 
 // IsFilterSensitiveDataEnabled returns true if sensitive data filtering is enabled
@@ -1132,6 +1652,9 @@ func (c *ToolsConfig) GetFilterMinLength() int {
 type SearchCacheConfig struct {
 	MaxSize    int `json:"max_size"    env:"PICOCLAW_SKILLS_SEARCH_CACHE_MAX_SIZE"`
 	TTLSeconds int `json:"ttl_seconds" env:"PICOCLAW_SKILLS_SEARCH_CACHE_TTL_SECONDS"`
+	// StaleTTLSeconds is how long results stay usable as a fallback while
+	// every registry is unreachable.
+	StaleTTLSeconds int `json:"stale_ttl_seconds,omitempty" env:"PICOCLAW_SKILLS_SEARCH_CACHE_STALE_TTL_SECONDS"`
 }
 
 type SkillsRegistriesConfig []*SkillRegistryConfig
@@ -1250,6 +1773,9 @@ type MCPConfig struct {
 	Discovery  ToolDiscoveryConfig `                                json:"discovery"`
 	// MaxInlineTextChars controls how much MCP text stays inline before it is saved as an artifact.
 	MaxInlineTextChars int `json:"max_inline_text_chars,omitempty" env:"PICOCLAW_TOOLS_MCP_MAX_INLINE_TEXT_CHARS"`
+	// MaxRestarts caps consecutive automatic restarts of a crashed server.
+	// Zero uses the default; a negative value disables automatic restarts.
+	MaxRestarts int `json:"max_restarts,omitempty" env:"PICOCLAW_TOOLS_MCP_MAX_RESTARTS"`
 	// Servers is a map of server name to server configuration
 	Servers map[string]MCPServerConfig `json:"servers,omitempty"`
 }
@@ -1263,7 +1789,23 @@ func (c *MCPConfig) GetMaxInlineTextChars() int {
 	return DefaultMCPMaxInlineTextChars
 }
 
+const DefaultMCPMaxRestarts = 5
+
+// GetMaxRestarts returns the restart budget for a crashed MCP server.
+// A negative result means crashed servers are not restarted.
+func (c *MCPConfig) GetMaxRestarts() int {
+	if c.MaxRestarts == 0 {
+		return DefaultMCPMaxRestarts
+	}
+	return c.MaxRestarts
+}
+
 func LoadConfig(path string) (*Config, error) {
+	return loadConfigFile(path, true)
+}
+
+// loadConfigFile is LoadConfig, optionally without applying .security.yml.
+func loadConfigFile(path string, withSecurity bool) (*Config, error) {
 	updateResolver(filepath.Dir(path))
 
 	data, err := os.ReadFile(path)
@@ -1292,83 +1834,17 @@ func LoadConfig(path string) (*Config, error) {
 		return DefaultConfig(), nil
 	}
 
+	data, err = resolveIncludes(path, data)
+	if err != nil {
+		logger.ErrorCF("config", formatDiagnosticLogMessage("Failed to load included config", err), map[string]any{"path": path})
+		return nil, err
+	}
+
 	// Load config based on detected version
 	var cfg *Config
-	switch versionInfo.Version {
-	case 0:
-		logger.InfoF(
-			"config migrate start",
-			map[string]any{"from": versionInfo.Version, "to": CurrentVersion},
-		)
-		if err = validateLegacyConfigDiagnostics(data); err != nil {
-			logger.ErrorCF(
-				"config",
-				formatDiagnosticLogMessage("Failed to load config", err),
-				map[string]any{"path": path},
-			)
-			return nil, err
-		}
-
-		var m map[string]any
-		m, err = loadConfigMap(path)
-		if err != nil {
-			logger.ErrorCF(
-				"config",
-				formatDiagnosticLogMessage("Failed to load config", err),
-				map[string]any{"path": path},
-			)
-			return nil, err
-		}
-
-		migrateErr := migrateV0ToV1(m)
-		if migrateErr != nil {
-			return nil, fmt.Errorf("V0→V1 migration failed: %w", migrateErr)
-		}
-		migrateErr = migrateV1ToV2(m)
-		if migrateErr != nil {
-			return nil, fmt.Errorf("V1→V2 migration failed: %w", migrateErr)
-		}
-		migrateErr = migrateV2ToV3(m)
-		if migrateErr != nil {
-			return nil, fmt.Errorf("V2→V3 migration failed: %w", migrateErr)
-		}
-
-		var migrated []byte
-		migrated, err = json.Marshal(m)
-		if err != nil {
-			return nil, err
-		}
-
-		cfg, err = loadConfig(migrated)
-		if err != nil {
-			return nil, err
-		}
-
-		err = MakeBackup(path)
-		if err != nil {
-			return nil, err
-		}
-
-		defer func(cfg *Config) {
-			_ = SaveConfig(path, cfg)
-		}(cfg)
-	case 1:
-		// V1→V3 migration: rename channels→channel_list, infer Enabled, migrate channel configs
-		logger.InfoF(
-			"config migrate start",
-			map[string]any{"from": versionInfo.Version, "to": CurrentVersion},
-		)
-		if err = validateLegacyConfigDiagnostics(data); err != nil {
-			logger.ErrorCF(
-				"config",
-				formatDiagnosticLogMessage("Failed to load config", err),
-				map[string]any{"path": path},
-			)
-			return nil, err
-		}
-
-		var m map[string]any
-		m, err = loadConfigMap(path)
+	switch {
+	case versionInfo.Version == CurrentVersion:
+		cfg, err = loadConfig(data)
 		if err != nil {
 			logger.ErrorCF(
 				"config",
@@ -1377,108 +1853,28 @@ func LoadConfig(path string) (*Config, error) {
 			)
 			return nil, err
 		}
-
-		migrateErr := migrateV1ToV2(m)
-		if migrateErr != nil {
-			return nil, fmt.Errorf("V1→V2 migration failed: %w", migrateErr)
-		}
-		migrateErr = migrateV2ToV3(m)
-		if migrateErr != nil {
-			return nil, fmt.Errorf("V2→V3 migration failed: %w", migrateErr)
-		}
-
-		var migrated []byte
-		migrated, err = json.Marshal(m)
-		if err != nil {
-			return nil, err
-		}
-
-		cfg, err = loadConfig(migrated)
-		if err != nil {
-			return nil, err
+		// Load security configuration
+		if withSecurity {
+			err = loadSecurityConfig(cfg, securityPath(path))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("failed to load security config: %w", err)
+			}
 		}
 
-		err = MakeBackup(path)
+	case versionInfo.Version >= 0 && versionInfo.Version < CurrentVersion:
+		cfg, err = loadAndMigrateConfig(path, data, versionInfo.Version)
 		if err != nil {
 			return nil, err
 		}
-
 		defer func(cfg *Config) {
 			_ = SaveConfig(path, cfg)
 		}(cfg)
-		logger.InfoF(
-			"config migrate success",
-			map[string]any{"from": versionInfo.Version, "to": CurrentVersion},
-		)
-	case 2:
-		// V2→V3 migration: rename channels→channel_list, convert flat→nested
-		logger.InfoF(
-			"config migrate start",
-			map[string]any{"from": versionInfo.Version, "to": CurrentVersion},
-		)
-		if err = validateLegacyConfigDiagnostics(data); err != nil {
-			logger.ErrorCF(
-				"config",
-				formatDiagnosticLogMessage("Failed to load config", err),
-				map[string]any{"path": path},
-			)
-			return nil, err
-		}
-		var m map[string]any
-		m, err = loadConfigMap(path)
-		if err != nil {
-			logger.ErrorCF(
-				"config",
-				formatDiagnosticLogMessage("Failed to load config", err),
-				map[string]any{"path": path},
-			)
-			return nil, err
-		}
-		migrateErr := migrateV2ToV3(m)
-		if migrateErr != nil {
-			return nil, fmt.Errorf("V2→V3 migration failed: %w", migrateErr)
-		}
 
-		var migrated []byte
-		migrated, err = json.Marshal(m)
-		if err != nil {
-			return nil, err
-		}
-
-		cfg, err = loadConfig(migrated)
-		if err != nil {
-			return nil, err
-		}
-
-		err = MakeBackup(path)
-		if err != nil {
-			return nil, err
-		}
-
-		defer func(cfg *Config) {
-			_ = SaveConfig(path, cfg)
-		}(cfg)
-		logger.InfoF(
-			"config migrate success",
-			map[string]any{"from": versionInfo.Version, "to": CurrentVersion},
+	case versionInfo.Version > CurrentVersion:
+		return nil, fmt.Errorf(
+			"unsupported config version: %d (this build reads up to version %d; upgrade PicoClaw or restore a backup)",
+			versionInfo.Version, CurrentVersion,
 		)
-	case CurrentVersion:
-		// Current version
-		cfg, err = loadConfig(data)
-		if err != nil {
-			logger.ErrorCF(
-				"config",
-				formatDiagnosticLogMessage("Failed to load config", err),
-				map[string]any{"path": path},
-			)
-			return nil, err
-		}
-		// Load security configuration
-		secPath := securityPath(path)
-		err = loadSecurityConfig(cfg, secPath)
-		if err != nil && !errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("failed to load security config: %w", err)
-		}
 
 	default:
 		return nil, fmt.Errorf("unsupported config version: %d", versionInfo.Version)
@@ -1518,6 +1914,14 @@ func LoadConfig(path string) (*Config, error) {
 		cfg.Agents.Defaults.Workspace = filepath.Join(homePath, pkg.WorkspaceName)
 	}
 
+	hardErrs, warnings := SplitValidationErrors(cfg.Validate())
+	for _, w := range warnings {
+		logger.WarnCF("config", w.Error(), map[string]any{"path": path})
+	}
+	if len(hardErrs) > 0 {
+		return nil, fmt.Errorf("invalid config: %w", errors.Join(hardErrs...))
+	}
+
 	cfg.Session.ApplyDmScope()
 	cfg.Session.DeriveDmScope()
 
@@ -1661,15 +2065,25 @@ func SaveConfig(path string, cfg *Config) error {
 	}()
 	cfg.ModelList = nonVirtualModels
 
-	if err := saveSecurityConfig(securityPath(path), cfg); err != nil {
-		logger.ErrorCF("config", "cannot save .security.yml", map[string]any{"error": err})
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
 		return err
 	}
-
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	secData, err := marshalSecurityConfig(cfg)
 	if err != nil {
 		return err
 	}
+	// With an include list, only the base file's own settings are written
+	// back; values from the included files stay in those files.
+	data, secData, err = withoutIncludedSettings(path, data, secData)
+	if err != nil {
+		return err
+	}
+
+	if err := fileutil.WriteFileAtomic(securityPath(path), secData, 0o600); err != nil {
+		logger.ErrorCF("config", "cannot save .security.yml", map[string]any{"error": err})
+		return err
+	}
 	return fileutil.WriteFileAtomic(path, data, 0o600)
 }
 
@@ -1787,6 +2201,7 @@ func expandMultiKeyModels(models []*ModelConfig) []*ModelConfig {
 				RequestTimeout:      m.RequestTimeout,
 				ThinkingLevel:       m.ThinkingLevel,
 				ToolSchemaTransform: m.ToolSchemaTransform,
+				StopSequences:       m.StopSequences,
 				Streaming:           m.Streaming,
 				ExtraBody:           m.ExtraBody,
 				CustomHeaders:       m.CustomHeaders,
@@ -1812,6 +2227,7 @@ func expandMultiKeyModels(models []*ModelConfig) []*ModelConfig {
 			RequestTimeout:      m.RequestTimeout,
 			ThinkingLevel:       m.ThinkingLevel,
 			ToolSchemaTransform: m.ToolSchemaTransform,
+			StopSequences:       m.StopSequences,
 			Streaming:           m.Streaming,
 			ExtraBody:           m.ExtraBody,
 			CustomHeaders:       m.CustomHeaders,
@@ -1846,6 +2262,26 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.MediaCleanup.Enabled
 	case "append_file":
 		return t.AppendFile.Enabled
+	case "clipboard":
+		return t.Clipboard.Enabled
+	case "image_gen":
+		return t.ImageGen.Enabled
+	case "memory":
+		return t.Memory.Enabled
+	case "ocr":
+		return t.OCR.Enabled
+	case "weather":
+		return t.Weather.Enabled
+	case "market":
+		return t.Market.Enabled
+	case "send_email":
+		return t.Email.Enabled
+	case "translate":
+		return t.Translate.Enabled
+	case "python":
+		return t.Python.Enabled
+	case "get_full_result":
+		return t.FullResults.Enabled
 	case "edit_file":
 		return t.EditFile.Enabled
 	case "find_skills":
@@ -1862,6 +2298,12 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.Message.Enabled
 	case "read_file":
 		return t.ReadFile.Enabled
+	case "plan":
+		return t.Plan.Enabled
+	case "reminder":
+		return t.Reminder.Enabled
+	case "screenshot":
+		return t.Screenshot.Enabled
 	case "serial":
 		return t.Serial.Enabled
 	case "spawn":
@@ -1872,8 +2314,14 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.SPI.Enabled
 	case "subagent":
 		return t.Subagent.Enabled
+	case "timer":
+		return t.Timer.Enabled
 	case "web_fetch":
 		return t.WebFetch.Enabled
+	case "http":
+		return t.HTTP.Enabled
+	case "git":
+		return t.Git.Enabled
 	case "send_file":
 		return t.SendFile.Enabled
 	case "send_tts":
@@ -1886,3 +2334,14 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return true
 	}
 }
+
+// RequiresApproval reports whether calls to the named tool must be approved
+// by the user in the chat before they run.
+func (t *ToolsConfig) RequiresApproval(name string) bool {
+	switch name {
+	case "python":
+		return t.Python.RequireApproval
+	default:
+		return false
+	}
+}
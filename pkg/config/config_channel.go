@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/caarlos0/env/v11"
 	"gopkg.in/yaml.v3"
@@ -37,6 +38,7 @@ const (
 	ChannelTeamsWebHook   = "teams_webhook"
 	ChannelMQTT           = "mqtt"
 	ChannelSlackWebHook   = "slack_webhook"
+	ChannelSMS            = "sms"
 )
 
 func initChannel() {
@@ -220,14 +222,24 @@ func (r *RawNode) IsEmpty() bool {
 //nolint:recvcheck
 type Channel struct {
 	name               string
-	Enabled            bool                `json:"enabled"                 yaml:"-"`
-	Type               string              `json:"type"                    yaml:"-"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from,omitempty"    yaml:"-"`
-	ReasoningChannelID string              `json:"reasoning_channel_id"    yaml:"-"`
-	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty" yaml:"-"`
-	Typing             TypingConfig        `json:"typing,omitempty"        yaml:"-"`
-	Placeholder        PlaceholderConfig   `json:"placeholder,omitempty"   yaml:"-"`
-	Settings           RawNode             `json:"settings,omitzero"       yaml:"settings,omitempty"`
+	Enabled            bool                `json:"enabled"                      yaml:"-"`
+	Type               string              `json:"type"                         yaml:"-"`
+	AllowFrom          FlexibleStringSlice `json:"allow_from,omitempty"         yaml:"-"`
+	ReasoningChannelID string              `json:"reasoning_channel_id"         yaml:"-"`
+	ShowReasoning      bool                `json:"show_reasoning,omitempty"     yaml:"-"`
+	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty"      yaml:"-"`
+	Typing             TypingConfig        `json:"typing,omitempty"             yaml:"-"`
+	Placeholder        PlaceholderConfig   `json:"placeholder,omitempty"        yaml:"-"`
+	PersonaPrompt      string              `json:"persona_prompt,omitempty"     yaml:"-"`
+	Greeting           string              `json:"greeting,omitempty"           yaml:"-"`
+	Retry              ChannelRetryConfig  `json:"retry,omitzero"               yaml:"-"`
+	MaxResponseChars   int                 `json:"max_response_chars,omitempty" yaml:"-"` // 0 = no cap
+	ResponseOverflow   string              `json:"response_overflow,omitempty"  yaml:"-"` // "condense" (default) or "truncate"
+	OutputFormat       string              `json:"output_format,omitempty"      yaml:"-"` // "markdown" (default), "plain" or "html"
+	MessagePrefix      string              `json:"message_prefix,omitempty"     yaml:"-"` // added before each reply; {model}, {agent}, {timestamp}
+	MessageSuffix      string              `json:"message_suffix,omitempty"     yaml:"-"` // added after each reply, e.g. a disclaimer footer
+	SkipGuardrails     bool                `json:"skip_guardrails,omitempty"    yaml:"-"` // trusted channel; agents.defaults.guardrails does not screen it
+	Settings           RawNode             `json:"settings,omitzero"            yaml:"settings,omitempty"`
 	extend             any
 }
 
@@ -535,15 +547,49 @@ func validateSingletonChannels(channels ChannelsConfig) error {
 	return nil
 }
 
+// Response overflow modes for Channel.ResponseOverflow.
+const (
+	ResponseOverflowCondense = "condense"
+	ResponseOverflowTruncate = "truncate"
+)
+
+// Output formats for Channel.OutputFormat.
+const (
+	OutputFormatMarkdown = "markdown"
+	OutputFormatPlain    = "plain"
+	OutputFormatHTML     = "html"
+)
+
+// MaxPersonaPromptChars caps a channel persona prompt. The persona is added
+// to every system prompt for that channel, so a runaway value would eat into
+// the context window of each turn.
+const MaxPersonaPromptChars = 4000
+
+func validateChannelPersonaPrompts(channels ChannelsConfig) error {
+	for name, bc := range channels {
+		if n := utf8.RuneCountInString(strings.TrimSpace(bc.PersonaPrompt)); n > MaxPersonaPromptChars {
+			return fmt.Errorf(
+				"channel %q persona_prompt is %d characters, maximum is %d",
+				name, n, MaxPersonaPromptChars,
+			)
+		}
+	}
+	return nil
+}
+
 // BaseFieldNames are JSON keys that belong to Channel, not to channel-specific settings.
 var BaseFieldNames = map[string]struct{}{
 	"enabled":              {},
 	"type":                 {},
 	"allow_from":           {},
 	"reasoning_channel_id": {},
+	"show_reasoning":       {},
 	"group_trigger":        {},
 	"typing":               {},
 	"placeholder":          {},
+	"persona_prompt":       {},
+	"greeting":             {},
+	"retry":                {},
 }
 
 // ─── Internal helpers ───
@@ -677,6 +723,7 @@ var channelSettingsFactory = map[string]any{
 	ChannelTeamsWebHook:   (TeamsWebhookSettings{}),
 	ChannelMQTT:           (MQTTSettings{}),
 	ChannelSlackWebHook:   (SlackWebhookSettings{}),
+	ChannelSMS:            (SMSSettings{}),
 }
 
 // newChannelSettings creates a fresh zero-value pointer for the given channel type.
@@ -740,6 +787,9 @@ func InitChannelList(channels ChannelsConfig) error {
 	if err := validateSingletonChannels(channels); err != nil {
 		return err
 	}
+	if err := validateChannelPersonaPrompts(channels); err != nil {
+		return err
+	}
 
 	return nil
 }
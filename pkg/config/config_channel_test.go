@@ -106,6 +106,8 @@ func TestChannel_JSON_Unmarshal(t *testing.T) {
 		"type": "telegram",
 		"allow_from": ["user1", "user2"],
 		"reasoning_channel_id": "-100xxx",
+		"show_reasoning": true,
+		"greeting": "Hi there",
 		"settings": {
 			"base_url": "https://custom-api.example.com",
 			"use_markdown_v2": true,
@@ -121,6 +123,8 @@ func TestChannel_JSON_Unmarshal(t *testing.T) {
 	assert.Equal(t, "telegram", ch.Type)
 	assert.Equal(t, FlexibleStringSlice{"user1", "user2"}, ch.AllowFrom)
 	assert.Equal(t, "-100xxx", ch.ReasoningChannelID)
+	assert.True(t, ch.ShowReasoning)
+	assert.Equal(t, "Hi there", ch.Greeting)
 	assert.False(t, ch.SettingsIsEmpty())
 
 	// Decode into combined struct
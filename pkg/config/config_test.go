@@ -7,11 +7,13 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
 
 	"github.com/sipeed/picoclaw/pkg/credential"
+	"github.com/sipeed/picoclaw/pkg/logger"
 )
 
 // mustSetupSSHKey generates a temporary Ed25519 SSH key in t.TempDir() and sets
@@ -1065,6 +1067,24 @@ func TestValidateSingletonChannels_AllowsMultiInstanceTypes(t *testing.T) {
 	}
 }
 
+func TestInitChannelList_RejectsOversizedPersonaPrompt(t *testing.T) {
+	channels := ChannelsConfig{
+		"telegram": &Channel{Type: ChannelTelegram, PersonaPrompt: strings.Repeat("x", MaxPersonaPromptChars+1)},
+	}
+	err := InitChannelList(channels)
+	if err == nil {
+		t.Fatal("expected error for oversized persona_prompt, got nil")
+	}
+	if !strings.Contains(err.Error(), "persona_prompt") {
+		t.Fatalf("expected persona_prompt error, got: %v", err)
+	}
+
+	channels["telegram"].PersonaPrompt = "Be terse."
+	if err := InitChannelList(channels); err != nil {
+		t.Fatalf("expected persona within limit to be accepted, got: %v", err)
+	}
+}
+
 // TestDefaultConfig_WebTools verifies web tools config
 func TestDefaultConfig_WebTools(t *testing.T) {
 	cfg := DefaultConfig()
@@ -2612,6 +2632,47 @@ func TestResolveGatewayLogLevel_UsesEnvOverrideAndNormalizesInvalid(t *testing.T
 	}
 }
 
+func TestResolveGatewayLogFormat(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.json")
+	data := `{"version":1,"gateway":{"log_format":"json"}}`
+	if err := os.WriteFile(cfgPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	if got := ResolveGatewayLogFormat(cfgPath); got != logger.FormatJSON {
+		t.Fatalf("ResolveGatewayLogFormat() = %q, want %q", got, logger.FormatJSON)
+	}
+
+	t.Setenv("PICOCLAW_LOG_FORMAT", "text")
+	if got := ResolveGatewayLogFormat(cfgPath); got != logger.FormatText {
+		t.Fatalf("ResolveGatewayLogFormat() with env override = %q, want %q", got, logger.FormatText)
+	}
+
+	t.Setenv("PICOCLAW_LOG_FORMAT", "garbage")
+	if got := ResolveGatewayLogFormat(cfgPath); got != logger.FormatText {
+		t.Fatalf("ResolveGatewayLogFormat() with invalid env override = %q, want %q", got, logger.FormatText)
+	}
+}
+
+func TestEffectiveGatewayComponentLogLevels(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Gateway.LogLevels = map[string]string{
+		"agent": "debug",
+		"mcp":   "info",
+		"bad":   "loud",
+	}
+	t.Setenv("PICOCLAW_LOG_LEVELS", "mcp=error,telegram=warn")
+
+	got := EffectiveGatewayComponentLogLevels(cfg)
+	want := map[string]logger.LogLevel{
+		"agent":    logger.DEBUG,
+		"mcp":      logger.ERROR,
+		"telegram": logger.WARN,
+	}
+	assert.Equal(t, want, got)
+}
+
 func TestLoadConfig_AppliesLegacyClawHubRegistryEnvOverrides(t *testing.T) {
 	dir := t.TempDir()
 	cfgPath := filepath.Join(dir, "config.json")
@@ -3202,3 +3263,71 @@ func testChannelsConfigWithTokens() ChannelsConfig {
 	}
 	return channels
 }
+
+func TestQuietHoursConfig_Active(t *testing.T) {
+	at := func(clock string) time.Time {
+		t.Helper()
+		ts, err := time.Parse("15:04", clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	tests := []struct {
+		name  string
+		quiet QuietHoursConfig
+		clock string
+		want  bool
+	}{
+		{"unset", QuietHoursConfig{}, "23:00", false},
+		{"same day inside", QuietHoursConfig{Start: "13:00", End: "15:00"}, "14:30", true},
+		{"same day end is exclusive", QuietHoursConfig{Start: "13:00", End: "15:00"}, "15:00", false},
+		{"overnight before midnight", QuietHoursConfig{Start: "22:00", End: "07:00"}, "23:15", true},
+		{"overnight after midnight", QuietHoursConfig{Start: "22:00", End: "07:00"}, "06:59", true},
+		{"overnight outside", QuietHoursConfig{Start: "22:00", End: "07:00"}, "12:00", false},
+		{"malformed", QuietHoursConfig{Start: "late", End: "07:00"}, "23:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quiet.Active(at(tt.clock)); got != tt.want {
+				t.Errorf("Active(%s) = %v, want %v", tt.clock, got, tt.want)
+			}
+		})
+	}
+	if !(QuietHoursConfig{Mode: "Drop"}).Drop() || (QuietHoursConfig{}).Drop() {
+		t.Error("Drop() should only be true for mode drop")
+	}
+}
+
+func TestAttachmentsConfig_Allows(t *testing.T) {
+	cfg := AttachmentsConfig{AllowedTypes: []string{".csv", "pdf", "image/*", "text/plain"}}
+	tests := []struct {
+		filename string
+		mime     string
+		want     bool
+	}{
+		{"sales.CSV", "", true},
+		{"report.pdf", "application/pdf", true},
+		{"photo.heic", "image/heic", true},
+		{"notes", "text/plain; charset=utf-8", true},
+		{"setup.exe", "application/octet-stream", false},
+		{"archive.zip", "", false},
+	}
+	for _, tt := range tests {
+		if got := cfg.Allows(tt.filename, tt.mime); got != tt.want {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tt.filename, tt.mime, got, tt.want)
+		}
+	}
+	if !(AttachmentsConfig{}).Allows("setup.exe", "") {
+		t.Error("an empty allow list should allow every type")
+	}
+
+	d := AgentDefaults{}
+	if got := d.GetAttachmentMaxSize(); got != DefaultMaxMediaSize {
+		t.Errorf("GetAttachmentMaxSize() = %d, want %d", got, DefaultMaxMediaSize)
+	}
+	d.Attachments.MaxSize = 1024
+	if got := d.GetAttachmentMaxSize(); got != 1024 {
+		t.Errorf("GetAttachmentMaxSize() = %d, want 1024", got)
+	}
+}
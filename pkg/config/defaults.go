@@ -39,9 +39,10 @@ func DefaultConfig() *Config {
 					MaxArgsLength:    300,
 					SeparateMessages: false,
 				},
-				SplitOnMarker:       false,
-				MaxLLMRetries:       2,
-				LLMRetryBackoffSecs: 2,
+				SplitOnMarker:          false,
+				MaxLLMRetries:          2,
+				LLMRetryBackoffSecs:    2,
+				MaxConcurrentSubagents: 5,
 			},
 		},
 		Session: SessionConfig{
@@ -316,6 +317,10 @@ func DefaultConfig() *Config {
 				MaxAge:   30,
 				Interval: 5,
 			},
+			Memory: MemoryToolConfig{
+				MaxBytes:   32 * 1024,
+				InjectKeys: true,
+			},
 			Web: WebToolsConfig{
 				ToolConfig: ToolConfig{
 					Enabled: true,
@@ -379,6 +384,46 @@ func DefaultConfig() *Config {
 				ExecTimeoutMinutes: 5,
 				AllowCommand:       true,
 			},
+			Plan: ToolConfig{
+				Enabled: true,
+			},
+			Reminder: ToolConfig{
+				Enabled: true,
+			},
+			Timer: ToolConfig{
+				Enabled: true,
+			},
+			Weather: WeatherToolConfig{
+				ToolConfig: ToolConfig{
+					Enabled: true,
+				},
+				Provider: "open_meteo",
+				Units:    "metric",
+			},
+			Market: MarketToolConfig{
+				StockProvider: "stooq",
+				Currency:      "usd",
+				CacheSeconds:  60,
+			},
+			Email: EmailToolConfig{
+				SMTPPort:       587,
+				RequireConfirm: true,
+			},
+			Translate: TranslateToolConfig{
+				Engine: "llm",
+			},
+			Python: PythonToolConfig{
+				TimeoutSeconds:  30,
+				MemoryLimitMB:   1024,
+				RequireApproval: true,
+			},
+			FullResults: FullResultsToolConfig{
+				ToolConfig: ToolConfig{
+					Enabled: true,
+				},
+				TTLSeconds: 600,
+				MaxBytes:   2 << 20,
+			},
 			Exec: ExecConfig{
 				ToolConfig: ToolConfig{
 					Enabled: true,
@@ -407,8 +452,9 @@ func DefaultConfig() *Config {
 				},
 				MaxConcurrentSearches: 2,
 				SearchCache: SearchCacheConfig{
-					MaxSize:    50,
-					TTLSeconds: 300,
+					MaxSize:         50,
+					TTLSeconds:      300,
+					StaleTTLSeconds: 7 * 24 * 3600,
 				},
 			},
 			SendFile: ToolConfig{
@@ -434,6 +480,9 @@ func DefaultConfig() *Config {
 			AppendFile: ToolConfig{
 				Enabled: true,
 			},
+			Clipboard: ToolConfig{
+				Enabled: false, // Desktop tool - needs a graphical session
+			},
 			EditFile: ToolConfig{
 				Enabled: true,
 			},
@@ -463,6 +512,9 @@ func DefaultConfig() *Config {
 				Mode:            ReadFileModeBytes,
 				MaxReadFileSize: 64 * 1024, // 64KB
 			},
+			Screenshot: ToolConfig{
+				Enabled: false, // Desktop tool - needs a graphical session
+			},
 			Serial: ToolConfig{
 				Enabled: false, // Hardware tool - requires host serial ports
 			},
@@ -2,8 +2,12 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/netbind"
@@ -12,10 +16,138 @@ import (
 const DefaultGatewayLogLevel = "warn"
 
 type GatewayConfig struct {
-	Host      string `json:"host"                env:"PICOCLAW_GATEWAY_HOST"`
-	Port      int    `json:"port"                env:"PICOCLAW_GATEWAY_PORT"`
-	HotReload bool   `json:"hot_reload"          env:"PICOCLAW_GATEWAY_HOT_RELOAD"`
-	LogLevel  string `json:"log_level,omitempty" env:"PICOCLAW_LOG_LEVEL"`
+	Host      string `json:"host"                 yaml:"-" env:"PICOCLAW_GATEWAY_HOST"`
+	Port      int    `json:"port"                 yaml:"-" env:"PICOCLAW_GATEWAY_PORT"`
+	HotReload bool   `json:"hot_reload"           yaml:"-" env:"PICOCLAW_GATEWAY_HOT_RELOAD"`
+	LogLevel  string `json:"log_level,omitempty"  yaml:"-" env:"PICOCLAW_LOG_LEVEL"`
+	LogFormat string `json:"log_format,omitempty" yaml:"-" env:"PICOCLAW_LOG_FORMAT"`
+	// LogLevels overrides log_level for individual log components.
+	LogLevels map[string]string `json:"log_levels,omitempty" yaml:"-"`
+	// Security throttles clients that keep failing gateway authentication.
+	Security GatewaySecurityConfig `json:"security,omitzero" yaml:"-"`
+	// EventWebhooks receive a signed POST for each agent lifecycle event.
+	// Their secrets are kept in .security.yml.
+	EventWebhooks WebhookConfigs `json:"event_webhooks,omitempty" yaml:"event_webhooks,omitempty"`
+	// UI serves the built-in web UI and its config API on the gateway port.
+	UI GatewayUIConfig `json:"ui,omitzero" yaml:"-"`
+	// ControlAPI serves the config and chat APIs on a port of their own.
+	ControlAPI GatewayControlAPIConfig `json:"control_api,omitzero" yaml:"-"`
+}
+
+// DefaultGatewayControlAPIPort is used when gateway.control_api.port is 0.
+const DefaultGatewayControlAPIPort = 18791
+
+// GatewayControlAPIConfig toggles the control API: config get/set and a
+// streaming chat endpoint for orchestration clients, served over HTTP/1.1 and
+// cleartext HTTP/2 on gateway.host. Like the other gateway APIs it needs the
+// gateway token.
+type GatewayControlAPIConfig struct {
+	Enabled bool `json:"enabled"        env:"PICOCLAW_GATEWAY_CONTROL_API_ENABLED"`
+	Port    int  `json:"port,omitempty" env:"PICOCLAW_GATEWAY_CONTROL_API_PORT"`
+}
+
+// EffectivePort returns the configured port, or the default when unset.
+func (c GatewayControlAPIConfig) EffectivePort() int {
+	if c.Port == 0 {
+		return DefaultGatewayControlAPIPort
+	}
+	return c.Port
+}
+
+// GatewayUIConfig toggles the built-in web UI: a chat page using the Pico
+// WebSocket channel and a raw JSON config editor. The page itself is static;
+// every API it calls needs the gateway token.
+type GatewayUIConfig struct {
+	Enabled bool `json:"enabled" env:"PICOCLAW_GATEWAY_UI_ENABLED"`
+}
+
+// WebhookConfig is an outbound endpoint for agent lifecycle events. Events
+// limits delivery to the listed event names (turn.started, turn.completed,
+// turn.failed, tool.called, session.summarized); empty means all of them.
+// With a Secret, each request carries an HMAC-SHA256 signature of the
+// timestamp and body.
+type WebhookConfig struct {
+	URL    string       `json:"url"`
+	Secret SecureString `json:"secret,omitzero"  yaml:"secret,omitempty"`
+	Events []string     `json:"events,omitempty"`
+}
+
+// WebhookConfigs is gateway.event_webhooks. In .security.yml it is a map
+// from "<url>:<n>", n counting earlier webhooks with the same URL, to the
+// webhook's secret, which is merged into the webhooks loaded from the config.
+type WebhookConfigs []WebhookConfig
+
+func (v *WebhookConfigs) UnmarshalYAML(value *yaml.Node) error {
+	secrets := make(map[string]WebhookConfig)
+	if err := value.Decode(&secrets); err != nil {
+		return err
+	}
+	for i, key := range webhookSecurityKeys(*v) {
+		if sec, ok := secrets[key]; ok {
+			(*v)[i].Secret = sec.Secret
+		}
+	}
+	return nil
+}
+
+func (v WebhookConfigs) MarshalYAML() (any, error) {
+	type onlySecureData struct {
+		Secret SecureString `yaml:"secret,omitempty"`
+	}
+	secrets := make(map[string]onlySecureData, len(v))
+	for i, key := range webhookSecurityKeys(v) {
+		secrets[key] = onlySecureData{Secret: v[i].Secret}
+	}
+	return secrets, nil
+}
+
+func webhookSecurityKeys(list WebhookConfigs) []string {
+	keys := make([]string, 0, len(list))
+	counts := make(map[string]int)
+	for _, hook := range list {
+		keys = append(keys, fmt.Sprintf("%s:%d", hook.URL, counts[hook.URL]))
+		counts[hook.URL]++
+	}
+	return keys
+}
+
+// Defaults for gateway.security. A client that fails authentication
+// DefaultGatewayAuthMaxFailures times within the window is locked out.
+const (
+	DefaultGatewayAuthMaxFailures    = 10
+	DefaultGatewayAuthWindowSeconds  = 300
+	DefaultGatewayAuthLockoutSeconds = 900
+)
+
+// GatewaySecurityConfig controls the lockout of clients, keyed by remote IP,
+// that repeatedly send a wrong token to the gateway's protected endpoints.
+// Zero values use the defaults; a negative auth_max_failures disables the
+// lockout.
+type GatewaySecurityConfig struct {
+	AuthMaxFailures    int `json:"auth_max_failures,omitempty"    env:"PICOCLAW_GATEWAY_SECURITY_AUTH_MAX_FAILURES"`
+	AuthWindowSeconds  int `json:"auth_window_seconds,omitempty"  env:"PICOCLAW_GATEWAY_SECURITY_AUTH_WINDOW_SECONDS"`
+	AuthLockoutSeconds int `json:"auth_lockout_seconds,omitempty" env:"PICOCLAW_GATEWAY_SECURITY_AUTH_LOCKOUT_SECONDS"`
+}
+
+// AuthLockoutPolicy returns the effective lockout settings. maxFailures is 0
+// when the lockout is disabled.
+func (c GatewaySecurityConfig) AuthLockoutPolicy() (maxFailures int, window, lockout time.Duration) {
+	maxFailures = c.AuthMaxFailures
+	switch {
+	case maxFailures < 0:
+		return 0, 0, 0
+	case maxFailures == 0:
+		maxFailures = DefaultGatewayAuthMaxFailures
+	}
+	windowSeconds := c.AuthWindowSeconds
+	if windowSeconds <= 0 {
+		windowSeconds = DefaultGatewayAuthWindowSeconds
+	}
+	lockoutSeconds := c.AuthLockoutSeconds
+	if lockoutSeconds <= 0 {
+		lockoutSeconds = DefaultGatewayAuthLockoutSeconds
+	}
+	return maxFailures, time.Duration(windowSeconds) * time.Second, time.Duration(lockoutSeconds) * time.Second
 }
 
 func canonicalGatewayLogLevel(level logger.LogLevel) string {
@@ -51,6 +183,78 @@ func EffectiveGatewayLogLevel(cfg *Config) string {
 	return normalizeGatewayLogLevel(cfg.Gateway.LogLevel)
 }
 
+// EffectiveGatewayLogFormat returns the console log format from a loaded
+// config. Invalid or empty values fall back to text.
+func EffectiveGatewayLogFormat(cfg *Config) logger.LogFormat {
+	if cfg == nil {
+		return logger.FormatText
+	}
+	format, _ := logger.ParseFormat(cfg.Gateway.LogFormat)
+	return format
+}
+
+// EffectiveLogRedactor builds the redactor for log output from
+// agents.defaults.log_redaction. Unless redaction is off, the secrets stored
+// in the config are scrubbed too. An invalid setting falls back to moderate
+// redaction with a warning.
+func EffectiveLogRedactor(cfg *Config) *logger.Redactor {
+	if cfg == nil {
+		r, _ := logger.NewRedactor(logger.RedactionOptions{})
+		return r
+	}
+	rc := cfg.Agents.Defaults.LogRedaction
+	opts := logger.RedactionOptions{
+		Mode:      rc.Mode,
+		Patterns:  rc.Patterns,
+		MaxLength: rc.MaxContentLength,
+	}
+	if !strings.EqualFold(strings.TrimSpace(rc.Mode), logger.RedactionOff) {
+		opts.Secrets = cfg.collectSensitiveValues()
+	}
+	r, err := logger.NewRedactor(opts)
+	if err != nil {
+		logger.WarnCF("config", "invalid log_redaction, using moderate redaction", map[string]any{
+			"error": err.Error(),
+		})
+		r, _ = logger.NewRedactor(logger.RedactionOptions{Secrets: opts.Secrets})
+	}
+	return r
+}
+
+// EffectiveGatewayComponentLogLevels returns the per-component log level
+// overrides from gateway.log_levels, with entries from the
+// PICOCLAW_LOG_LEVELS environment variable (component=level,...) taking
+// precedence. Invalid entries are skipped with a warning.
+func EffectiveGatewayComponentLogLevels(cfg *Config) map[string]logger.LogLevel {
+	levels := make(map[string]logger.LogLevel)
+	if cfg != nil {
+		for component, name := range cfg.Gateway.LogLevels {
+			level, ok := logger.ParseLevel(name)
+			if !ok {
+				logger.WarnCF("config", "ignoring invalid component log level", map[string]any{
+					"component": component,
+					"level":     name,
+				})
+				continue
+			}
+			levels[component] = level
+		}
+	}
+
+	if spec := os.Getenv("PICOCLAW_LOG_LEVELS"); spec != "" {
+		overrides, err := logger.ParseComponentLevels(spec)
+		if err != nil {
+			logger.WarnCF("config", "ignoring invalid PICOCLAW_LOG_LEVELS", map[string]any{
+				"error": err.Error(),
+			})
+		}
+		for component, level := range overrides {
+			levels[component] = level
+		}
+	}
+	return levels
+}
+
 func resolveGatewayHostFromEnv(baseHost string) (string, error) {
 	envHost, ok := os.LookupEnv(EnvGatewayHost)
 	if !ok {
@@ -80,6 +284,28 @@ func normalizeGatewayHostInput(host string) (string, error) {
 // the full config loader, so startup code can apply logging before config load logs run.
 // The PICOCLAW_LOG_LEVEL environment variable overrides the file value.
 func ResolveGatewayLogLevel(path string) string {
+	gateway := readGatewayConfig(path)
+	if envLevel := os.Getenv("PICOCLAW_LOG_LEVEL"); envLevel != "" {
+		gateway.LogLevel = envLevel
+	}
+
+	return normalizeGatewayLogLevel(gateway.LogLevel)
+}
+
+// ResolveGatewayLogFormat is the log format counterpart of
+// ResolveGatewayLogLevel. The PICOCLAW_LOG_FORMAT environment variable
+// overrides the file value.
+func ResolveGatewayLogFormat(path string) logger.LogFormat {
+	gateway := readGatewayConfig(path)
+	if envFormat := os.Getenv("PICOCLAW_LOG_FORMAT"); envFormat != "" {
+		gateway.LogFormat = envFormat
+	}
+
+	format, _ := logger.ParseFormat(gateway.LogFormat)
+	return format
+}
+
+func readGatewayConfig(path string) GatewayConfig {
 	cfg := struct {
 		Gateway GatewayConfig `json:"gateway"`
 	}{
@@ -95,10 +321,5 @@ func ResolveGatewayLogLevel(path string) string {
 			})
 		}
 	}
-
-	if envLevel := os.Getenv("PICOCLAW_LOG_LEVEL"); envLevel != "" {
-		cfg.Gateway.LogLevel = envLevel
-	}
-
-	return normalizeGatewayLogLevel(cfg.Gateway.LogLevel)
+	return cfg.Gateway
 }
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGatewaySecurityConfig_AuthLockoutPolicy(t *testing.T) {
+	maxFailures, window, lockout := GatewaySecurityConfig{}.AuthLockoutPolicy()
+	if maxFailures != DefaultGatewayAuthMaxFailures ||
+		window != DefaultGatewayAuthWindowSeconds*time.Second ||
+		lockout != DefaultGatewayAuthLockoutSeconds*time.Second {
+		t.Fatalf("defaults = %d, %v, %v", maxFailures, window, lockout)
+	}
+
+	maxFailures, window, lockout = GatewaySecurityConfig{
+		AuthMaxFailures:    3,
+		AuthWindowSeconds:  30,
+		AuthLockoutSeconds: 60,
+	}.AuthLockoutPolicy()
+	if maxFailures != 3 || window != 30*time.Second || lockout != time.Minute {
+		t.Fatalf("custom = %d, %v, %v", maxFailures, window, lockout)
+	}
+
+	if maxFailures, _, _ = (GatewaySecurityConfig{AuthMaxFailures: -1}).AuthLockoutPolicy(); maxFailures != 0 {
+		t.Fatalf("disabled maxFailures = %d, want 0", maxFailures)
+	}
+}
+
+func TestWebhookSecretsAreStoredInSecurityFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	cfg := DefaultConfig()
+	cfg.Gateway.EventWebhooks = WebhookConfigs{
+		{URL: "https://a.example.com/hook", Secret: *NewSecureString("first-secret")},
+		{URL: "https://b.example.com/hook"},
+		{URL: "https://a.example.com/hook", Secret: *NewSecureString("second-secret")},
+	}
+	if err := SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "first-secret") || strings.Contains(string(data), "second-secret") {
+		t.Fatalf("config.json holds a webhook secret:\n%s", data)
+	}
+	sec, err := os.ReadFile(filepath.Join(dir, SecurityConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(sec), "second-secret") {
+		t.Fatalf(".security.yml misses the webhook secret:\n%s", sec)
+	}
+
+	loaded, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	hooks := loaded.Gateway.EventWebhooks
+	if len(hooks) != 3 || hooks[0].Secret.String() != "first-secret" ||
+		hooks[1].Secret.String() != "" || hooks[2].Secret.String() != "second-secret" {
+		t.Fatalf("loaded webhooks = %+v", hooks)
+	}
+	if got := loaded.SensitiveDataReplacer().Replace("sig first-secret"); got != "sig [FILTERED]" {
+		t.Errorf("log redaction = %q", got)
+	}
+}
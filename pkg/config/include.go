@@ -0,0 +1,338 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxIncludeDepth bounds how deeply included files may include others.
+const maxIncludeDepth = 8
+
+// MergePatch merges src into dst with JSON Merge Patch (RFC 7386) semantics:
+// a null value deletes the key, objects present on both sides are merged
+// recursively, and anything else, arrays included, replaces the value in dst.
+func MergePatch(dst, src map[string]any) {
+	for key, srcVal := range src {
+		if srcVal == nil {
+			delete(dst, key)
+			continue
+		}
+		srcMap, srcIsMap := srcVal.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			MergePatch(dstMap, srcMap)
+		} else {
+			dst[key] = srcVal
+		}
+	}
+}
+
+// resolveIncludes merges the files named in the top-level "include" list of
+// the config at path on top of data, in order, so later files override
+// earlier ones. Paths are relative to the including file and may be globs,
+// which expand in lexical order. Included files may be JSON or YAML (by
+// extension) and may include further files. data is returned unchanged when
+// it has no include list.
+func resolveIncludes(path string, data []byte) ([]byte, error) {
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if _, ok := root["include"]; !ok {
+		return data, nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := mergeIncludes(abs, root, []string{abs})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merged)
+}
+
+// mergeIncludes returns doc, read from path, with its includes merged on
+// top. chain holds the files currently being resolved, to reject cycles.
+func mergeIncludes(path string, doc map[string]any, chain []string) (map[string]any, error) {
+	files, err := includeFiles(path, doc["include"])
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if slices.Contains(chain, file) {
+			return nil, fmt.Errorf("config include cycle: %s", strings.Join(append(chain, file), " -> "))
+		}
+		if len(chain) > maxIncludeDepth {
+			return nil, fmt.Errorf("config includes nest deeper than %d files at %s", maxIncludeDepth, file)
+		}
+		included, err := readConfigDocument(file)
+		if err != nil {
+			return nil, err
+		}
+		included, err = mergeIncludes(file, included, append(chain, file))
+		if err != nil {
+			return nil, err
+		}
+		// Only the top-level file's include list is kept in the result.
+		delete(included, "include")
+		MergePatch(doc, included)
+	}
+	return doc, nil
+}
+
+// includeFiles expands the include list of the file at path into absolute
+// file paths.
+func includeFiles(path string, raw any) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: include must be a list of file paths", path)
+	}
+	var files []string
+	for _, item := range list {
+		pattern, ok := item.(string)
+		if !ok || strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("%s: include entries must be non-empty file paths", path)
+		}
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		pattern = filepath.Clean(pattern)
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid include pattern %q: %w", path, pattern, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("%s: included config file %s not found", path, pattern)
+		}
+		slices.Sort(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// readConfigDocument reads a JSON or YAML config file as a generic object.
+func readConfigDocument(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read included config: %w", err)
+	}
+	var doc map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err = yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		if err = json.Unmarshal(data, &doc); err != nil {
+			return nil, wrapJSONError(data, err, path)
+		}
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	return doc, nil
+}
+
+// IncludedFiles returns every file the config at path includes, directly or
+// through other includes, in merge order. It is best effort: files that
+// cannot be read or parsed end the walk at that point, and LoadConfig reports
+// the error.
+func IncludedFiles(path string) []string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil
+	}
+	var root map[string]any
+	if json.Unmarshal(data, &root) != nil {
+		return nil
+	}
+	var out []string
+	walkIncludes(abs, root, []string{abs}, &out)
+	return out
+}
+
+func walkIncludes(path string, doc map[string]any, chain []string, out *[]string) {
+	files, err := includeFiles(path, doc["include"])
+	if err != nil || len(chain) > maxIncludeDepth {
+		return
+	}
+	for _, file := range files {
+		if slices.Contains(chain, file) {
+			return
+		}
+		*out = append(*out, file)
+		included, err := readConfigDocument(file)
+		if err != nil {
+			return
+		}
+		walkIncludes(file, included, append(chain, file), out)
+	}
+}
+
+// withoutIncludedSettings rewrites the config and security documents that
+// SaveConfig is about to write to path so that they hold only the base
+// file's own settings when it has an include list. Settings changed since
+// the config was loaded are applied to the base file as written. Changing a
+// setting that an included file provides is an error, since the include
+// would override it again on the next load. Without an include list the
+// documents are returned unchanged.
+func withoutIncludedSettings(path string, cfgJSON, secYAML []byte) ([]byte, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfgJSON, secYAML, nil
+		}
+		return nil, nil, err
+	}
+	var base map[string]any
+	if json.Unmarshal(data, &base) != nil || base["include"] == nil {
+		return cfgJSON, secYAML, nil
+	}
+	if version, _ := base["version"].(float64); int(version) != CurrentVersion {
+		return nil, nil, fmt.Errorf("%s has includes and config version %v; update it to version %d by hand before saving",
+			path, base["version"], CurrentVersion)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	included, err := mergeIncludes(abs, map[string]any{"include": base["include"]}, []string{abs})
+	if err != nil {
+		return nil, nil, err
+	}
+	delete(included, "include")
+
+	// The config as currently loaded from disk, without .security.yml, is
+	// what the pending changes are measured against.
+	loaded, err := loadConfigFile(path, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	loaded.ModelList = slices.DeleteFunc(loaded.ModelList, func(m *ModelConfig) bool { return m.isVirtual })
+	loadedJSON, err := json.Marshal(loaded)
+	if err != nil {
+		return nil, nil, err
+	}
+	var before, after map[string]any
+	if err = json.Unmarshal(loadedJSON, &before); err != nil {
+		return nil, nil, err
+	}
+	if err = json.Unmarshal(cfgJSON, &after); err != nil {
+		return nil, nil, err
+	}
+	if err = applyConfigChanges(base, included, before, after, ""); err != nil {
+		return nil, nil, err
+	}
+	baseJSON, err := json.MarshalIndent(base, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Secrets that come from included files are left out of .security.yml,
+	// so that it cannot shadow them once they change there.
+	loadedYAML, err := marshalSecurityConfig(loaded)
+	if err != nil {
+		return nil, nil, err
+	}
+	var secBefore, secAfter map[string]any
+	if err = yaml.Unmarshal(loadedYAML, &secBefore); err != nil {
+		return nil, nil, err
+	}
+	if err = yaml.Unmarshal(secYAML, &secAfter); err != nil {
+		return nil, nil, err
+	}
+	pruneUnchanged(secAfter, secBefore)
+	secYAML, err = marshalSecurityConfig(secAfter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return baseJSON, secYAML, nil
+}
+
+// applyConfigChanges applies the differences between the before and after
+// config documents to base. included holds the settings provided by included
+// files at the same level; a change to one of them is reported as an error.
+func applyConfigChanges(base, included, before, after map[string]any, prefix string) error {
+	keys := slices.Sorted(maps.Keys(after))
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		old, inBefore := before[key]
+		val, inAfter := after[key]
+		if inBefore == inAfter && reflect.DeepEqual(old, val) {
+			continue
+		}
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		inc, isIncluded := included[key]
+		oldMap, oldIsMap := old.(map[string]any)
+		valMap, valIsMap := val.(map[string]any)
+		incMap, incIsMap := inc.(map[string]any)
+		if oldIsMap && valIsMap && (!isIncluded || incIsMap) {
+			sub, ok := base[key].(map[string]any)
+			if !ok {
+				sub = map[string]any{}
+			}
+			if err := applyConfigChanges(sub, incMap, oldMap, valMap, name); err != nil {
+				return err
+			}
+			if len(sub) > 0 {
+				base[key] = sub
+			}
+			continue
+		}
+		if isIncluded {
+			return fmt.Errorf("%s is set in an included config file; change it there", name)
+		}
+		if inAfter {
+			base[key] = val
+		} else {
+			delete(base, key)
+		}
+	}
+	return nil
+}
+
+// pruneUnchanged removes the entries of doc that are equal in before, and
+// objects left empty by that.
+func pruneUnchanged(doc, before map[string]any) {
+	for key, val := range doc {
+		old, ok := before[key]
+		if !ok {
+			continue
+		}
+		valMap, valIsMap := val.(map[string]any)
+		oldMap, oldIsMap := old.(map[string]any)
+		switch {
+		case valIsMap && oldIsMap:
+			pruneUnchanged(valMap, oldMap)
+			if len(valMap) == 0 {
+				delete(doc, key)
+			}
+		case reflect.DeepEqual(val, old):
+			delete(doc, key)
+		}
+	}
+}
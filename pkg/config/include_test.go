@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile(%s): %v", path, err)
+	}
+}
+
+func TestLoadConfig_MergesIncludesInOrder(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	writeConfigFile(t, configPath, fmt.Sprintf(`{
+		"version": %d,
+		"include": ["env/*.json", "secrets.yaml"],
+		"agents": {"defaults": {"workspace": "/srv/picoclaw", "max_tokens": 1000, "timezone": "UTC", "locale": "en-US"}},
+		"model_list": [{"model_name": "base", "model": "openai/gpt-4o-mini"}]
+	}`, CurrentVersion))
+	writeConfigFile(t, filepath.Join(dir, "env", "10-prod.json"), `{
+		"agents": {"defaults": {"max_tokens": 2000, "locale": null}}
+	}`)
+	writeConfigFile(t, filepath.Join(dir, "env", "20-region.json"), `{
+		"agents": {"defaults": {"timezone": "Europe/Berlin"}}
+	}`)
+	writeConfigFile(t, filepath.Join(dir, "secrets.yaml"), `
+model_list:
+  - model_name: prod
+    model: openai/gpt-4o
+    api_keys: ["sk-prod"]
+`)
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	d := cfg.Agents.Defaults
+	if d.Workspace != "/srv/picoclaw" || d.MaxTokens != 2000 || d.Timezone != "Europe/Berlin" || d.Locale != "" {
+		t.Fatalf("agents.defaults = workspace %q, max_tokens %d, timezone %q, locale %q",
+			d.Workspace, d.MaxTokens, d.Timezone, d.Locale)
+	}
+	if len(cfg.ModelList) != 1 || cfg.ModelList[0].ModelName != "prod" || cfg.ModelList[0].APIKey() != "sk-prod" {
+		t.Fatalf("model_list = %+v, want the secrets file's list to replace the base one", cfg.ModelList)
+	}
+	if len(cfg.Include) != 2 {
+		t.Fatalf("Include = %v, want the main file's list kept", cfg.Include)
+	}
+
+	files := IncludedFiles(configPath)
+	want := []string{
+		filepath.Join(dir, "env", "10-prod.json"),
+		filepath.Join(dir, "env", "20-region.json"),
+		filepath.Join(dir, "secrets.yaml"),
+	}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Fatalf("IncludedFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestLoadConfig_IncludeErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "missing file",
+			files:   map[string]string{"config.json": `{"version": %d, "include": ["prod.json"]}`},
+			wantErr: "not found",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"config.json": `{"version": %d, "include": ["a.json"]}`,
+				"a.json":      `{"include": ["b.json"]}`,
+				"b.json":      `{"include": ["a.json"]}`,
+			},
+			wantErr: "include cycle",
+		},
+		{
+			name:    "not a list",
+			files:   map[string]string{"config.json": `{"version": %d, "include": "prod.json"}`},
+			wantErr: "list of file paths",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if strings.Contains(content, "%d") {
+					content = fmt.Sprintf(content, CurrentVersion)
+				}
+				writeConfigFile(t, filepath.Join(dir, name), content)
+			}
+			_, err := LoadConfig(filepath.Join(dir, "config.json"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadConfig() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSaveConfig_KeepsIncludedSettingsOutOfBaseFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	writeConfigFile(t, configPath, fmt.Sprintf(`{
+		"version": %d,
+		"include": ["prod.json", "secrets.yaml"],
+		"agents": {"defaults": {"workspace": "/srv/picoclaw", "max_tokens": 1000}}
+	}`, CurrentVersion))
+	writeConfigFile(t, filepath.Join(dir, "prod.json"), `{
+		"agents": {"defaults": {"max_tokens": 2000, "timezone": "Europe/Berlin"}}
+	}`)
+	secretsPath := filepath.Join(dir, "secrets.yaml")
+	writeConfigFile(t, secretsPath, `
+model_list:
+  - model_name: prod
+    model: openai/gpt-4o
+    api_keys: ["sk-prod"]
+`)
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	cfg.Agents.Defaults.Locale = "de-DE"
+	if err = SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error: %v", err)
+	}
+
+	saved, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"2000", "Europe/Berlin", "gpt-4o", "model_list"} {
+		if strings.Contains(string(saved), leaked) {
+			t.Errorf("config.json contains %q from an included file:\n%s", leaked, saved)
+		}
+	}
+	for _, kept := range []string{`"de-DE"`, `"/srv/picoclaw"`, `"secrets.yaml"`} {
+		if !strings.Contains(string(saved), kept) {
+			t.Errorf("config.json lost %s:\n%s", kept, saved)
+		}
+	}
+	if sec, _ := os.ReadFile(filepath.Join(dir, ".security.yml")); strings.Contains(string(sec), "sk-prod") {
+		t.Errorf(".security.yml copies the included secret:\n%s", sec)
+	}
+
+	// A secret rotated in its own file takes effect on the next load.
+	writeConfigFile(t, secretsPath, `
+model_list:
+  - model_name: prod
+    model: openai/gpt-4o
+    api_keys: ["sk-rotated"]
+`)
+	cfg, err = LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() after save error: %v", err)
+	}
+	d := cfg.Agents.Defaults
+	if d.Locale != "de-DE" || d.MaxTokens != 2000 || d.Timezone != "Europe/Berlin" {
+		t.Fatalf("reloaded defaults = locale %q, max_tokens %d, timezone %q", d.Locale, d.MaxTokens, d.Timezone)
+	}
+	if len(cfg.ModelList) != 1 || cfg.ModelList[0].APIKey() != "sk-rotated" {
+		t.Fatalf("model_list = %+v, want the rotated key from secrets.yaml", cfg.ModelList)
+	}
+
+	// Settings an included file provides cannot be changed in the base file.
+	cfg.Agents.Defaults.Timezone = "UTC"
+	err = SaveConfig(configPath, cfg)
+	if err == nil || !strings.Contains(err.Error(), "agents.defaults.timezone") {
+		t.Fatalf("SaveConfig() error = %v, want one naming agents.defaults.timezone", err)
+	}
+	if after, _ := os.ReadFile(configPath); string(after) != string(saved) {
+		t.Fatalf("a refused save rewrote config.json:\n%s", after)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	dst := map[string]any{
+		"a":    map[string]any{"keep": 1, "drop": 2, "over": 3},
+		"list": []any{1, 2},
+	}
+	MergePatch(dst, map[string]any{
+		"a":    map[string]any{"drop": nil, "over": 4, "new": 5},
+		"list": []any{3},
+	})
+	a := dst["a"].(map[string]any)
+	if a["keep"] != 1 || a["over"] != 4 || a["new"] != 5 {
+		t.Fatalf("a = %v", a)
+	}
+	if _, ok := a["drop"]; ok {
+		t.Fatal("null should delete the key")
+	}
+	if list := dst["list"].([]any); len(list) != 1 || list[0] != 3 {
+		t.Fatalf("list = %v, want it replaced", list)
+	}
+}
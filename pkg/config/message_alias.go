@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxAliasExpansionDepth caps how many aliases one message may chain through,
+// so an alias that expands to itself (directly or via others) cannot loop.
+const MaxAliasExpansionDepth = 5
+
+// aliasArgPlaceholder is replaced with the text following the alias trigger.
+const aliasArgPlaceholder = "{{arg}}"
+
+// MessageAliases maps a trigger word such as "!standup" to the prompt it
+// expands to. The trigger must be the first word of a message and is matched
+// case-insensitively.
+type MessageAliases map[string]string
+
+// Lookup returns the template for trigger.
+func (ma MessageAliases) Lookup(trigger string) (string, bool) {
+	trigger = strings.ToLower(strings.TrimSpace(trigger))
+	if trigger == "" {
+		return "", false
+	}
+	for key, template := range ma {
+		if strings.ToLower(strings.TrimSpace(key)) == trigger {
+			return template, true
+		}
+	}
+	return "", false
+}
+
+// Expand rewrites content while its first word is an alias, replacing
+// {{arg}} in the template with the rest of the message (or appending the rest
+// when the template has no placeholder). skip, if set, vetoes triggers that
+// belong to someone else, such as command names. It returns the triggers
+// applied in order; none means content is unchanged. An error reports a chain
+// longer than MaxAliasExpansionDepth.
+func (ma MessageAliases) Expand(content string, skip func(trigger string) bool) (string, []string, error) {
+	if len(ma) == 0 {
+		return content, nil, nil
+	}
+	var applied []string
+	for {
+		trimmed := strings.TrimSpace(content)
+		fields := strings.Fields(trimmed)
+		if len(fields) == 0 {
+			return content, applied, nil
+		}
+		trigger := fields[0]
+		template, ok := ma.Lookup(trigger)
+		if !ok || (skip != nil && skip(trigger)) {
+			return content, applied, nil
+		}
+		if len(applied) == MaxAliasExpansionDepth {
+			return content, applied, fmt.Errorf(
+				"alias %q expands more than %d levels deep; check aliases for a loop",
+				applied[0], MaxAliasExpansionDepth)
+		}
+		applied = append(applied, trigger)
+
+		arg := strings.TrimSpace(strings.TrimPrefix(trimmed, trigger))
+		switch {
+		case strings.Contains(template, aliasArgPlaceholder):
+			content = strings.ReplaceAll(template, aliasArgPlaceholder, arg)
+		case arg != "":
+			content = strings.TrimRight(template, " \t\n") + "\n\n" + arg
+		default:
+			content = template
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMessageAliases_Expand(t *testing.T) {
+	aliases := MessageAliases{
+		"!standup": "Write my standup notes. Focus: {{arg}}",
+		"!tldr":    "Summarize this in three bullets:",
+		"!daily":   "!standup {{arg}} and open PRs",
+		"!loop":    "!loop",
+		"/help":    "shadowed by the command",
+	}
+
+	tests := []struct {
+		name        string
+		content     string
+		want        string
+		wantApplied []string
+	}{
+		{
+			"placeholder", "!standup  release blockers ",
+			"Write my standup notes. Focus: release blockers", []string{"!standup"},
+		},
+		{"case-insensitive trigger", "!STANDUP x", "Write my standup notes. Focus: x", []string{"!STANDUP"}},
+		{
+			"appends rest without placeholder", "!tldr some\nlong text",
+			"Summarize this in three bullets:\n\nsome\nlong text", []string{"!tldr"},
+		},
+		{"empty placeholder", "!standup", "Write my standup notes. Focus: ", []string{"!standup"}},
+		{"recursive", "!daily infra", "Write my standup notes. Focus: infra and open PRs", []string{"!daily", "!standup"}},
+		{"not first word", "please !standup", "please !standup", nil},
+		{"prefix only", "!standups", "!standups", nil},
+		{"skipped", "/help", "/help", nil},
+	}
+	skip := func(trigger string) bool { return trigger == "/help" }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, applied, err := aliases.Expand(tt.content, skip)
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+			if strings.Join(applied, ",") != strings.Join(tt.wantApplied, ",") {
+				t.Errorf("applied = %v, want %v", applied, tt.wantApplied)
+			}
+		})
+	}
+
+	_, applied, err := aliases.Expand("!loop", nil)
+	if err == nil || !strings.Contains(err.Error(), "loop") {
+		t.Fatalf("Expand(!loop) error = %v, want loop error", err)
+	}
+	if len(applied) != MaxAliasExpansionDepth {
+		t.Errorf("applied %d aliases, want the depth cap %d", len(applied), MaxAliasExpansionDepth)
+	}
+}
@@ -396,11 +396,83 @@ func migrateV2ToV3(m map[string]any) error {
 		m["channel_list"] = channels
 	}
 
-	m["version"] = CurrentVersion
+	m["version"] = 3
 
 	return nil
 }
 
+// configMigrations upgrades a raw config one schema version at a time:
+// configMigrations[v] turns a version v config into version v+1. A schema
+// change bumps CurrentVersion and appends its step here; every older config
+// then runs through the new step on its next load.
+var configMigrations = []func(map[string]any) error{
+	migrateV0ToV1,
+	migrateV1ToV2,
+	migrateV2ToV3,
+}
+
+// migrateConfigMap runs every migration step from version from up to
+// CurrentVersion on m.
+func migrateConfigMap(m map[string]any, from int) error {
+	if len(configMigrations) != CurrentVersion {
+		return fmt.Errorf("config migrations cover %d versions, want %d", len(configMigrations), CurrentVersion)
+	}
+	for v := from; v < CurrentVersion; v++ {
+		if err := configMigrations[v](m); err != nil {
+			return fmt.Errorf("V%d→V%d migration failed: %w", v, v+1, err)
+		}
+	}
+	return nil
+}
+
+// loadAndMigrateConfig upgrades the config at path from version from to
+// CurrentVersion and backs up the original file. The caller is expected to
+// save the returned config so the upgrade happens only once.
+func loadAndMigrateConfig(path string, data []byte, from int) (*Config, error) {
+	logger.InfoF(
+		"config migrate start",
+		map[string]any{"from": from, "to": CurrentVersion},
+	)
+	if err := validateLegacyConfigDiagnostics(data); err != nil {
+		logger.ErrorCF(
+			"config",
+			formatDiagnosticLogMessage("Failed to load config", err),
+			map[string]any{"path": path},
+		)
+		return nil, err
+	}
+
+	m, err := loadConfigMap(path)
+	if err != nil {
+		logger.ErrorCF(
+			"config",
+			formatDiagnosticLogMessage("Failed to load config", err),
+			map[string]any{"path": path},
+		)
+		return nil, err
+	}
+	if err = migrateConfigMap(m, from); err != nil {
+		return nil, err
+	}
+
+	migrated, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfig(migrated)
+	if err != nil {
+		return nil, err
+	}
+	if err = MakeBackup(path); err != nil {
+		return nil, err
+	}
+	logger.InfoF(
+		"config migrate success",
+		map[string]any{"from": from, "to": CurrentVersion},
+	)
+	return cfg, nil
+}
+
 func loadConfigMap(path string) (map[string]any, error) {
 	var m1, m2 map[string]any
 	data, err := os.ReadFile(path)
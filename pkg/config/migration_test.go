@@ -6,8 +6,11 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -395,3 +398,72 @@ func TestMigrateV1ToV3_AlreadyNestedFormat(t *testing.T) {
 	// Should NOT have nested settings inside settings
 	require.NotContains(t, settings, "settings")
 }
+
+func TestConfigMigrations_CoverEveryVersion(t *testing.T) {
+	if len(configMigrations) != CurrentVersion {
+		t.Fatalf("configMigrations has %d steps, want one per version below %d", len(configMigrations), CurrentVersion)
+	}
+}
+
+func TestMigrateConfigMap_StampsEachStep(t *testing.T) {
+	m := map[string]any{
+		"version": float64(2),
+		"channels": map[string]any{
+			"telegram": map[string]any{"enabled": true, "token": "tok"},
+		},
+	}
+	if err := migrateConfigMap(m, 2); err != nil {
+		t.Fatalf("migrateConfigMap: %v", err)
+	}
+	if !compareInt(m["version"], CurrentVersion) {
+		t.Fatalf("version = %v, want %d", m["version"], CurrentVersion)
+	}
+
+	// A step refuses input stamped with the wrong version, so a skipped or
+	// misordered step fails loudly instead of corrupting the config.
+	err := migrateConfigMap(map[string]any{"version": float64(1)}, 2)
+	if err == nil || !strings.Contains(err.Error(), "V2→V3") {
+		t.Fatalf("err = %v, want V2→V3 step failure", err)
+	}
+}
+
+func TestLoadConfig_MigrationRewritesFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	v2Config := `{"version": 2, "gateway": {"host": "127.0.0.1", "port": 18790}}`
+	if err := os.WriteFile(configPath, []byte(v2Config), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if _, err := LoadConfig(configPath); err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var onDisk struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &onDisk); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if onDisk.Version != CurrentVersion {
+		t.Fatalf("version on disk = %d, want %d after migration", onDisk.Version, CurrentVersion)
+	}
+}
+
+func TestLoadConfig_NewerVersionSuggestsUpgrade(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	newer := fmt.Sprintf(`{"version": %d, "gateway": {"host": "127.0.0.1", "port": 18790}}`, CurrentVersion+1)
+	if err := os.WriteFile(configPath, []byte(newer), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	_, err := LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "upgrade PicoClaw") {
+		t.Fatalf("err = %v, want upgrade hint", err)
+	}
+}
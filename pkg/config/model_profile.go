@@ -0,0 +1,106 @@
+package config
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// ModelParams holds per-model request settings layered over the agent
+// defaults while that model is active. Zero values leave the default alone.
+type ModelParams struct {
+	MaxTokens     int      `json:"max_tokens,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	StopSequences []string `json:"stop_sequences,omitempty"`
+	ThinkingLevel string   `json:"thinking_level,omitempty"`
+}
+
+// ModelProfiles maps a model name or glob to the settings used for it.
+type ModelProfiles map[string]ModelParams
+
+// Resolve merges the profiles whose key matches any of names (typically the
+// model_list alias and the provider model ID). Keys are matched
+// case-insensitively and may be globs such as "gpt-5*". Matching globs apply
+// from least to most specific, and an exact key is applied last so it wins.
+// The second result reports whether any profile matched.
+func (mp ModelProfiles) Resolve(names ...string) (ModelParams, bool) {
+	if len(mp) == 0 {
+		return ModelParams{}, false
+	}
+
+	var globs, exact []string
+	for key := range mp {
+		pattern := strings.ToLower(strings.TrimSpace(key))
+		if pattern == "" {
+			continue
+		}
+		isGlob := strings.ContainsAny(pattern, "*?[")
+		for _, name := range names {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if !isGlob {
+				if pattern == name {
+					exact = append(exact, key)
+					break
+				}
+				continue
+			}
+			if ok, err := path.Match(pattern, name); err == nil && ok {
+				globs = append(globs, key)
+				break
+			}
+		}
+	}
+	if len(globs) == 0 && len(exact) == 0 {
+		return ModelParams{}, false
+	}
+
+	sort.Slice(globs, func(i, j int) bool {
+		si, sj := globSpecificity(globs[i]), globSpecificity(globs[j])
+		if si != sj {
+			return si < sj
+		}
+		return globs[i] < globs[j]
+	})
+	sort.Strings(exact)
+
+	var merged ModelParams
+	for _, key := range append(globs, exact...) {
+		merged = merged.Merge(mp[key])
+	}
+	return merged, true
+}
+
+// Merge returns p with the non-zero fields of over applied on top.
+func (p ModelParams) Merge(over ModelParams) ModelParams {
+	if over.MaxTokens > 0 {
+		p.MaxTokens = over.MaxTokens
+	}
+	if over.Temperature != nil {
+		t := *over.Temperature
+		p.Temperature = &t
+	}
+	if len(over.StopSequences) > 0 {
+		p.StopSequences = append([]string(nil), over.StopSequences...)
+	}
+	if strings.TrimSpace(over.ThinkingLevel) != "" {
+		p.ThinkingLevel = over.ThinkingLevel
+	}
+	return p
+}
+
+// globSpecificity counts the literal characters of a glob, so "gpt-5*" ranks
+// above "gpt-*" and both above "*".
+func globSpecificity(pattern string) int {
+	n := 0
+	for _, r := range pattern {
+		switch r {
+		case '*', '?', '[', ']':
+		default:
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestModelProfiles_ResolvePrecedence(t *testing.T) {
+	low, mid, high := 0.2, 0.5, 1.0
+	profiles := ModelProfiles{
+		"*":           {MaxTokens: 1000, Temperature: &mid},
+		"gpt-*":       {MaxTokens: 2000, StopSequences: []string{"END"}},
+		"gpt-5*":      {MaxTokens: 4000, ThinkingLevel: "medium"},
+		"GPT-5-Mini":  {Temperature: &low},
+		"claude-opus": {Temperature: &high, ThinkingLevel: "high"},
+	}
+
+	tests := []struct {
+		name      string
+		names     []string
+		maxTokens int
+		temp      float64
+		stop      []string
+		thinking  string
+	}{
+		{"catch-all only", []string{"llama3"}, 1000, mid, nil, ""},
+		{"more specific glob wins", []string{"gpt-4o"}, 2000, mid, []string{"END"}, ""},
+		{"most specific glob wins", []string{"gpt-5"}, 4000, mid, []string{"END"}, "medium"},
+		{"exact key applied last", []string{"gpt-5-mini"}, 4000, low, []string{"END"}, "medium"},
+		{"alias or model id", []string{"my-alias", "claude-opus"}, 1000, high, nil, "high"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := profiles.Resolve(tt.names...)
+			if !ok {
+				t.Fatalf("Resolve(%v) matched nothing", tt.names)
+			}
+			if got.MaxTokens != tt.maxTokens {
+				t.Errorf("MaxTokens = %d, want %d", got.MaxTokens, tt.maxTokens)
+			}
+			if got.Temperature == nil || *got.Temperature != tt.temp {
+				t.Errorf("Temperature = %v, want %g", got.Temperature, tt.temp)
+			}
+			if !slices.Equal(got.StopSequences, tt.stop) {
+				t.Errorf("StopSequences = %v, want %v", got.StopSequences, tt.stop)
+			}
+			if got.ThinkingLevel != tt.thinking {
+				t.Errorf("ThinkingLevel = %q, want %q", got.ThinkingLevel, tt.thinking)
+			}
+		})
+	}
+}
+
+func TestModelProfiles_ResolveNoMatch(t *testing.T) {
+	profiles := ModelProfiles{"gpt-*": {MaxTokens: 2000}}
+	if got, ok := profiles.Resolve("claude-sonnet", ""); ok {
+		t.Fatalf("Resolve() = %+v, true; want no match", got)
+	}
+	if _, ok := ModelProfiles(nil).Resolve("gpt-4o"); ok {
+		t.Fatal("nil profiles should not match")
+	}
+}
+
+func TestModelParams_MergeKeepsDefaultsForZeroFields(t *testing.T) {
+	temp := 0.7
+	base := ModelParams{MaxTokens: 8192, Temperature: &temp}
+	got := base.Merge(ModelParams{ThinkingLevel: "low"})
+	if got.MaxTokens != 8192 || got.Temperature == nil || *got.Temperature != 0.7 || got.ThinkingLevel != "low" {
+		t.Fatalf("Merge() = %+v", got)
+	}
+}
@@ -192,14 +192,23 @@ func applyLegacySkillsSecurityNode(cfg *Config, skillsNode *yaml.Node) error {
 
 // saveSecurityConfig saves the security configuration to security.yml
 func saveSecurityConfig(securityPath string, sec *Config) error {
+	data, err := marshalSecurityConfig(sec)
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(securityPath, data, 0o600)
+}
+
+// marshalSecurityConfig encodes sec, a *Config or an already extracted
+// document, as security.yml.
+func marshalSecurityConfig(sec any) ([]byte, error) {
 	var buf bytes.Buffer
 	enc := yaml.NewEncoder(&buf)
 	enc.SetIndent(2)
-	err := enc.Encode(sec)
-	if err != nil {
-		return fmt.Errorf("failed to marshal security config: %w", err)
+	if err := enc.Encode(sec); err != nil {
+		return nil, fmt.Errorf("failed to marshal security config: %w", err)
 	}
-	return fileutil.WriteFileAtomic(securityPath, buf.Bytes(), 0o600)
+	return buf.Bytes(), nil
 }
 
 // SensitiveDataCache caches the strings.Replacer for filtering sensitive data.
@@ -0,0 +1,369 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// ValidationError is one problem found by Config.Validate. Warnings describe
+// settings that work but are probably not what the user meant; everything
+// else makes the config unusable.
+type ValidationError struct {
+	Field   string
+	Message string
+	Warning bool
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// IsValidationWarning reports whether err is a ValidationError marked as a
+// warning.
+func IsValidationWarning(err error) bool {
+	var ve *ValidationError
+	return errors.As(err, &ve) && ve.Warning
+}
+
+// SplitValidationErrors separates the result of Validate into hard errors and
+// warnings.
+func SplitValidationErrors(errs []error) (hard, warnings []error) {
+	for _, err := range errs {
+		if IsValidationWarning(err) {
+			warnings = append(warnings, err)
+		} else {
+			hard = append(hard, err)
+		}
+	}
+	return hard, warnings
+}
+
+// Validate checks ranges and cross-field constraints and returns every
+// problem found. LoadConfig fails on hard errors and logs warnings; the CLI
+// and the web config API use the same checks.
+func (c *Config) Validate() []error {
+	if c == nil {
+		return nil
+	}
+	v := &configValidator{}
+
+	for i := range c.ModelList {
+		if err := c.ModelList[i].Validate(); err != nil {
+			v.fail(fmt.Sprintf("model_list[%d]", i), err.Error())
+		}
+	}
+	if err := c.ValidateTurnProfile(); err != nil {
+		v.fail("", err.Error())
+	}
+
+	c.validateAgentDefaults(v)
+	v.nonNegative("providers.http.max_idle_conns", c.Providers.HTTP.MaxIdleConns)
+	v.nonNegative("providers.http.max_idle_conns_per_host", c.Providers.HTTP.MaxIdleConnsPerHost)
+	v.nonNegative("providers.http.idle_timeout", c.Providers.HTTP.IdleTimeout)
+	switch dc := strings.ToLower(strings.TrimSpace(c.Providers.OpenRouter.DataCollection)); dc {
+	case "", "allow", "deny":
+	default:
+		v.fail("providers.openrouter.data_collection", fmt.Sprintf("must be \"allow\" or \"deny\", got %q", dc))
+	}
+	c.validateGateway(v)
+	c.validateChannels(v)
+	c.validateTools(v)
+
+	if c.Heartbeat.Enabled && c.Heartbeat.Interval > 0 && c.Heartbeat.Interval < 5 {
+		v.warn("heartbeat.interval", fmt.Sprintf("%d minutes is below the minimum and is raised to 5", c.Heartbeat.Interval))
+	}
+	return v.errs
+}
+
+type configValidator struct {
+	errs []error
+}
+
+func (v *configValidator) fail(field, message string) {
+	v.errs = append(v.errs, &ValidationError{Field: field, Message: message})
+}
+
+func (v *configValidator) warn(field, message string) {
+	v.errs = append(v.errs, &ValidationError{Field: field, Message: message, Warning: true})
+}
+
+func (v *configValidator) nonNegative(field string, value int) {
+	if value < 0 {
+		v.fail(field, fmt.Sprintf("must be >= 0, got %d", value))
+	}
+}
+
+func (c *Config) validateAgentDefaults(v *configValidator) {
+	d := &c.Agents.Defaults
+	if strings.TrimSpace(d.Workspace) == "" {
+		v.warn("agents.defaults.workspace", "is empty; the default workspace under the PicoClaw home is used")
+	}
+	if d.Temperature != nil && (*d.Temperature < 0 || *d.Temperature > 2) {
+		v.fail("agents.defaults.temperature", fmt.Sprintf("must be between 0 and 2, got %g", *d.Temperature))
+	}
+	v.nonNegative("agents.defaults.max_tokens", d.MaxTokens)
+	v.nonNegative("agents.defaults.context_window", d.ContextWindow)
+	v.nonNegative("agents.defaults.max_tool_iterations", d.MaxToolIterations)
+	if d.ToolCorrectionRetries != nil {
+		v.nonNegative("agents.defaults.tool_correction_retries", *d.ToolCorrectionRetries)
+	}
+	if d.MaxContinuations != nil {
+		v.nonNegative("agents.defaults.max_continuations", *d.MaxContinuations)
+	}
+	v.nonNegative("agents.defaults.max_concurrent_subagents", d.MaxConcurrentSubagents)
+	if d.SummarizeTokenPercent < 0 || d.SummarizeTokenPercent > 100 {
+		v.fail("agents.defaults.summarize_token_percent",
+			fmt.Sprintf("must be between 0 and 100, got %d", d.SummarizeTokenPercent))
+	}
+	if n := utf8.RuneCountInString(strings.TrimSpace(d.Persona)); n > MaxPersonaPromptChars {
+		v.fail("agents.defaults.persona", fmt.Sprintf("is %d characters; the limit is %d", n, MaxPersonaPromptChars))
+	}
+	if q := d.QuietHours; q.Start != "" || q.End != "" {
+		for field, value := range map[string]string{"start": q.Start, "end": q.End} {
+			if _, ok := parseClock(value); !ok {
+				v.fail("agents.defaults.quiet_hours."+field, fmt.Sprintf("must be a time like 22:00, got %q", value))
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(q.Mode)) {
+		case "", "queue", "drop":
+		default:
+			v.fail("agents.defaults.quiet_hours.mode", fmt.Sprintf("must be \"queue\" or \"drop\", got %q", q.Mode))
+		}
+	}
+	if g := d.Guardrails; g.Enabled {
+		switch g.Mode {
+		case "", GuardrailModeRules, GuardrailModeModel:
+		default:
+			v.fail("agents.defaults.guardrails.mode",
+				fmt.Sprintf("must be %q or %q, got %q", GuardrailModeRules, GuardrailModeModel, g.Mode))
+		}
+		validateRegexList(v, "agents.defaults.guardrails.patterns", g.Patterns)
+	}
+	v.nonNegative("agents.defaults.attachments.max_size", d.Attachments.MaxSize)
+	rc := d.LogRedaction
+	if _, err := logger.NewRedactor(logger.RedactionOptions{Mode: rc.Mode, Patterns: rc.Patterns}); err != nil {
+		v.fail("agents.defaults.log_redaction", err.Error())
+	}
+	v.nonNegative("agents.defaults.log_redaction.max_content_length", rc.MaxContentLength)
+	for key, params := range d.ModelProfiles {
+		field := "agents.defaults.model_profiles." + key
+		if _, err := path.Match(strings.ToLower(key), ""); err != nil {
+			v.fail(field, fmt.Sprintf("invalid pattern: %v", err))
+		}
+		if params.Temperature != nil && (*params.Temperature < 0 || *params.Temperature > 2) {
+			v.fail(field+".temperature", fmt.Sprintf("must be between 0 and 2, got %g", *params.Temperature))
+		}
+		v.nonNegative(field+".max_tokens", params.MaxTokens)
+		switch strings.ToLower(strings.TrimSpace(params.ThinkingLevel)) {
+		case "", "off", "low", "medium", "high", "xhigh", "adaptive":
+		default:
+			v.fail(field+".thinking_level", fmt.Sprintf("unknown level %q", params.ThinkingLevel))
+		}
+	}
+
+	seenAliases := make(map[string]string, len(d.Aliases))
+	for trigger, template := range d.Aliases {
+		field := "agents.defaults.aliases." + trigger
+		normalized := strings.ToLower(strings.TrimSpace(trigger))
+		switch {
+		case normalized == "" || strings.ContainsAny(normalized, " \t\r\n"):
+			v.fail(field, "trigger must be a single word")
+		case strings.HasPrefix(normalized, "/"):
+			v.fail(field, "triggers starting with \"/\" are reserved for commands")
+		}
+		if other, dup := seenAliases[normalized]; dup {
+			v.fail(field, fmt.Sprintf("duplicates alias %q (triggers are case-insensitive)", other))
+		}
+		seenAliases[normalized] = trigger
+		if strings.TrimSpace(template) == "" {
+			v.fail(field, "template is empty")
+		}
+	}
+
+	modelName := d.GetModelName()
+	if modelName == "" || len(c.ModelList) == 0 {
+		return
+	}
+	model := c.findMatches(modelName)
+	if len(model) == 0 {
+		v.warn("agents.defaults.model_name", fmt.Sprintf("%q is not in model_list", modelName))
+		return
+	}
+	if m := model[0]; m.APIKey() == "" && modelNeedsAPIKey(m) {
+		v.warn("agents.defaults.model_name", fmt.Sprintf("model %q has no API key", modelName))
+	}
+}
+
+// modelNeedsAPIKey reports whether m authenticates with an API key. OAuth
+// and CLI providers log in separately and local servers usually need none.
+func modelNeedsAPIKey(m *ModelConfig) bool {
+	if m.AuthMethod != "" || m.ConnectMode != "" {
+		return false
+	}
+	if u, err := url.Parse(m.APIBase); err == nil {
+		switch u.Hostname() {
+		case "localhost", "127.0.0.1", "::1":
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Config) validateGateway(v *configValidator) {
+	if c.Gateway.Port < 0 || c.Gateway.Port > 65535 {
+		v.fail("gateway.port", fmt.Sprintf("%d is out of valid range (1-65535)", c.Gateway.Port))
+	}
+	if bc := c.Channels.GetByType(ChannelMaixCam); bc != nil && bc.Enabled && c.Gateway.Port != 0 {
+		if decoded, err := bc.GetDecoded(); err == nil {
+			if s, ok := decoded.(*MaixCamSettings); ok && s.Port == c.Gateway.Port {
+				v.fail("channels.maixcam.port", fmt.Sprintf("%d is already used by gateway.port", s.Port))
+			}
+		}
+	}
+	if api := c.Gateway.ControlAPI; api.Enabled {
+		if api.Port < 0 || api.Port > 65535 {
+			v.fail("gateway.control_api.port", fmt.Sprintf("%d is out of valid range (1-65535)", api.Port))
+		} else if api.EffectivePort() == c.Gateway.Port {
+			v.fail("gateway.control_api.port", fmt.Sprintf("%d is already used by gateway.port", api.EffectivePort()))
+		}
+	}
+}
+
+func (c *Config) validateChannels(v *configValidator) {
+	if err := validateSingletonChannels(c.Channels); err != nil {
+		v.fail("channels", err.Error())
+	}
+	for name, bc := range c.Channels {
+		if bc == nil {
+			continue
+		}
+		decoded, err := bc.GetDecoded()
+		if err != nil {
+			v.fail("channels."+name, err.Error())
+			continue
+		}
+		if err := validateChannelStreamingConfig(name, decoded); err != nil {
+			v.fail("channels."+name, err.Error())
+		}
+		v.nonNegative("channels."+name+".max_response_chars", bc.MaxResponseChars)
+		switch bc.ResponseOverflow {
+		case "", ResponseOverflowCondense, ResponseOverflowTruncate:
+		default:
+			v.fail("channels."+name+".response_overflow",
+				fmt.Sprintf("must be %q or %q, got %q", ResponseOverflowCondense, ResponseOverflowTruncate, bc.ResponseOverflow))
+		}
+		switch bc.OutputFormat {
+		case "", OutputFormatMarkdown, OutputFormatPlain, OutputFormatHTML:
+		default:
+			v.fail("channels."+name+".output_format",
+				fmt.Sprintf("must be %q, %q or %q, got %q",
+					OutputFormatMarkdown, OutputFormatPlain, OutputFormatHTML, bc.OutputFormat))
+		}
+		if !bc.Enabled {
+			continue
+		}
+		// A channel without credentials fails on its own at startup, so
+		// the rest of the gateway keeps working.
+		for _, field := range missingChannelCredentials(decoded) {
+			v.warn("channels."+name+"."+field, fmt.Sprintf("is required when the %s channel is enabled", name))
+		}
+	}
+}
+
+func missingChannelCredentials(decoded any) []string {
+	var missing []string
+	switch s := decoded.(type) {
+	case *PicoSettings:
+		if s.Token.String() == "" {
+			missing = append(missing, "token")
+		}
+	case *TelegramSettings:
+		if s.Token.String() == "" {
+			missing = append(missing, "token")
+		}
+	case *DiscordSettings:
+		if s.Token.String() == "" {
+			missing = append(missing, "token")
+		}
+	case *WeComSettings:
+		if s.BotID == "" {
+			missing = append(missing, "bot_id")
+		}
+		if s.Secret.String() == "" {
+			missing = append(missing, "secret")
+		}
+	}
+	return missing
+}
+
+func (c *Config) validateTools(v *configValidator) {
+	if c.Tools.Exec.Enabled {
+		if c.Tools.Exec.EnableDenyPatterns {
+			validateRegexList(v, "tools.exec.custom_deny_patterns", c.Tools.Exec.CustomDenyPatterns)
+		}
+		validateRegexList(v, "tools.exec.custom_allow_patterns", c.Tools.Exec.CustomAllowPatterns)
+	}
+
+	if email := c.Tools.Email; email.Enabled {
+		// The tool is skipped at startup without these; the rest keeps working.
+		if strings.TrimSpace(email.SMTPHost) == "" {
+			v.warn("tools.email.smtp_host", "is required when the send_email tool is enabled")
+		}
+		if strings.TrimSpace(email.From) == "" {
+			v.warn("tools.email.from", "is required when the send_email tool is enabled")
+		}
+	}
+
+	if tr := c.Tools.Translate; tr.Enabled {
+		switch strings.ToLower(strings.TrimSpace(tr.Engine)) {
+		case "", "llm":
+			if m := strings.TrimSpace(tr.Model); m != "" && len(c.findMatches(m)) == 0 {
+				v.warn("tools.translate.model", fmt.Sprintf("%q is not in model_list; the agent's model is used", m))
+			}
+		case "libretranslate":
+		default:
+			v.fail("tools.translate.engine", fmt.Sprintf("unknown engine %q (expected llm or libretranslate)", tr.Engine))
+		}
+	}
+
+	v.nonNegative("tools.python.timeout_seconds", c.Tools.Python.TimeoutSeconds)
+	v.nonNegative("tools.python.memory_limit_mb", c.Tools.Python.MemoryLimitMB)
+	v.nonNegative("tools.full_results.ttl_seconds", c.Tools.FullResults.TTLSeconds)
+	v.nonNegative("tools.full_results.max_bytes", c.Tools.FullResults.MaxBytes)
+	v.nonNegative("tools.web.search_timeout", c.Tools.Web.SearchTimeout)
+
+	seen := make(map[string]bool, len(c.Tools.External))
+	for i, ext := range c.Tools.External {
+		field := fmt.Sprintf("tools.external[%d]", i)
+		name := strings.TrimSpace(ext.Name)
+		switch {
+		case name == "":
+			v.fail(field+".name", "is required")
+		case seen[name]:
+			v.fail(field+".name", fmt.Sprintf("%q is used by another external tool", name))
+		}
+		seen[name] = true
+		if strings.TrimSpace(ext.Command) == "" {
+			v.fail(field+".command", "is required")
+		}
+		v.nonNegative(field+".timeout_seconds", ext.TimeoutSeconds)
+	}
+}
+
+func validateRegexList(v *configValidator, field string, patterns []string) {
+	for i, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			v.fail(fmt.Sprintf("%s[%d]", field, i), fmt.Sprintf("is not a valid regular expression: %v", err))
+		}
+	}
+}
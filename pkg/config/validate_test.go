@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func validationFields(errs []error) map[string]bool {
+	fields := make(map[string]bool, len(errs))
+	for _, err := range errs {
+		fields[err.(*ValidationError).Field] = true
+	}
+	return fields
+}
+
+func TestValidate_DefaultConfigHasNoHardErrors(t *testing.T) {
+	hard, _ := SplitValidationErrors(DefaultConfig().Validate())
+	if len(hard) > 0 {
+		t.Fatalf("DefaultConfig().Validate() hard errors = %v", hard)
+	}
+}
+
+func TestValidate_HardErrorsAndWarnings(t *testing.T) {
+	cfg := DefaultConfig()
+	temperature := 3.0
+	cfg.Agents.Defaults.Temperature = &temperature
+	cfg.Agents.Defaults.MaxTokens = -1
+	cfg.Agents.Defaults.Workspace = ""
+	cfg.Gateway.Port = 70000
+	cfg.Heartbeat.Enabled = true
+	cfg.Heartbeat.Interval = 2
+	cfg.Tools.Exec.Enabled = true
+	cfg.Tools.Exec.CustomAllowPatterns = []string{"("}
+	cfg.Tools.External = []ExternalToolConfig{{Name: "dup", Command: "a"}, {Name: "dup"}}
+	cfg.Tools.Email.Enabled = true
+	cfg.Tools.Translate.Enabled = true
+	cfg.Providers.HTTP.IdleTimeout = -5
+	cfg.Providers.OpenRouter.DataCollection = "sometimes"
+	cfg.Tools.FullResults.MaxBytes = -1
+	cfg.Tools.Python.TimeoutSeconds = -1
+	cfg.Tools.Web.SearchTimeout = -1
+	correctionRetries := -1
+	cfg.Agents.Defaults.ToolCorrectionRetries = &correctionRetries
+	cfg.Agents.Defaults.MaxContinuations = &correctionRetries
+	cfg.Agents.Defaults.QuietHours = QuietHoursConfig{Start: "22:00", End: "7am", Mode: "later"}
+	cfg.Tools.Translate.Engine = "babelfish"
+	cfg.Agents.Defaults.ModelProfiles = ModelProfiles{
+		"gpt-[": {},
+		"o3*":   {Temperature: &temperature, ThinkingLevel: "max"},
+	}
+	cfg.Agents.Defaults.Aliases = MessageAliases{"/standup": "Write my standup notes", "!empty": " "}
+	cfg.Channels.Get("telegram").MaxResponseChars = -1
+	cfg.Channels.Get("telegram").ResponseOverflow = "summarize"
+	cfg.Channels.Get("telegram").OutputFormat = "rtf"
+	cfg.Agents.Defaults.Persona = strings.Repeat("p", MaxPersonaPromptChars+1)
+	cfg.Agents.Defaults.Guardrails = GuardrailsConfig{Enabled: true, Mode: "strict", Patterns: []string{"[a-"}}
+	cfg.Gateway.ControlAPI = GatewayControlAPIConfig{Enabled: true, Port: 70000}
+	cfg.Agents.Defaults.Attachments.MaxSize = -1
+
+	hard, warnings := SplitValidationErrors(cfg.Validate())
+	hardFields := validationFields(hard)
+	for _, field := range []string{
+		"agents.defaults.temperature",
+		"agents.defaults.max_tokens",
+		"gateway.port",
+		"gateway.control_api.port",
+		"tools.exec.custom_allow_patterns[0]",
+		"tools.external[1].name",
+		"tools.external[1].command",
+		"agents.defaults.model_profiles.gpt-[",
+		"agents.defaults.model_profiles.o3*.temperature",
+		"agents.defaults.model_profiles.o3*.thinking_level",
+		"tools.translate.engine",
+		"providers.http.idle_timeout",
+		"providers.openrouter.data_collection",
+		"tools.full_results.max_bytes",
+		"tools.python.timeout_seconds",
+		"tools.web.search_timeout",
+		"agents.defaults.tool_correction_retries",
+		"agents.defaults.max_continuations",
+		"agents.defaults.quiet_hours.end",
+		"agents.defaults.quiet_hours.mode",
+		"agents.defaults.persona",
+		"agents.defaults.guardrails.mode",
+		"agents.defaults.guardrails.patterns[0]",
+		"agents.defaults.attachments.max_size",
+		"agents.defaults.aliases./standup",
+		"agents.defaults.aliases.!empty",
+		"channels.telegram.max_response_chars",
+		"channels.telegram.response_overflow",
+		"channels.telegram.output_format",
+	} {
+		if !hardFields[field] {
+			t.Errorf("missing hard error for %s in %v", field, hard)
+		}
+	}
+	warnFields := validationFields(warnings)
+	for _, field := range []string{"agents.defaults.workspace", "heartbeat.interval", "tools.email.smtp_host"} {
+		if !warnFields[field] {
+			t.Errorf("missing warning for %s in %v", field, warnings)
+		}
+	}
+}
+
+func TestValidate_EnabledChannelWithoutTokenIsWarning(t *testing.T) {
+	cfg := DefaultConfig()
+	bc := cfg.Channels.GetByType(ChannelTelegram)
+	if bc == nil {
+		t.Fatal("default config has no telegram channel")
+	}
+	bc.Enabled = true
+
+	hard, warnings := SplitValidationErrors(cfg.Validate())
+	if len(hard) > 0 {
+		t.Fatalf("hard errors = %v, want none", hard)
+	}
+	found := false
+	for _, w := range warnings {
+		if strings.HasSuffix(w.(*ValidationError).Field, ".token") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("warnings = %v, want missing telegram token", warnings)
+	}
+}
+
+func TestLoadConfig_FailsOnValidationError(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	raw := `{
+		"version": 3,
+		"agents": {"defaults": {"max_tool_iterations": -5}}
+	}`
+	if err := os.WriteFile(configPath, []byte(raw), 0o644); err != nil {
+		t.Fatalf("WriteFile(configPath): %v", err)
+	}
+
+	_, err := LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "agents.defaults.max_tool_iterations") {
+		t.Fatalf("LoadConfig() error = %v, want max_tool_iterations validation error", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,6 +30,86 @@ type CronPayload struct {
 	Command string `json:"command,omitempty"`
 	Channel string `json:"channel,omitempty"`
 	To      string `json:"to,omitempty"`
+	// Deliver sends Message to the target chat as-is instead of running it
+	// as an agent turn.
+	Deliver bool `json:"deliver,omitempty"`
+	// Recipients lists every chat the job reports to when there is more than
+	// one. Channel and To then hold the first entry, so code that knows only
+	// a single target keeps working.
+	Recipients []CronRecipient `json:"recipients,omitempty"`
+}
+
+// CronRecipient is one chat that receives a job's output.
+type CronRecipient struct {
+	Channel string `json:"channel"`
+	To      string `json:"to"`
+}
+
+func (r CronRecipient) String() string {
+	if r.Channel == "" {
+		return r.To
+	}
+	return r.Channel + ":" + r.To
+}
+
+// Targets returns the chats the job reports to: Recipients when set,
+// otherwise the single Channel/To pair, whose parts may be empty.
+func (p CronPayload) Targets() []CronRecipient {
+	if len(p.Recipients) > 0 {
+		return append([]CronRecipient(nil), p.Recipients...)
+	}
+	return []CronRecipient{{Channel: p.Channel, To: p.To}}
+}
+
+// SetRecipients replaces the chats the job reports to, keeping Channel and
+// To in step with the first one. A single recipient is stored the way jobs
+// always were, without a Recipients list.
+func (p *CronPayload) SetRecipients(recipients []CronRecipient) {
+	p.Channel, p.To, p.Recipients = "", "", nil
+	if len(recipients) == 0 {
+		return
+	}
+	p.Channel, p.To = recipients[0].Channel, recipients[0].To
+	if len(recipients) > 1 {
+		p.Recipients = append([]CronRecipient(nil), recipients...)
+	}
+}
+
+// ParseRecipients turns the comma-separated to list of the CLI into
+// recipients. With a channel, every entry is a chat ID on that channel.
+// Without one, a lone entry is kept as-is for the fallback channel, and
+// several entries must each read "channel:chat_id".
+func ParseRecipients(channel, to string) ([]CronRecipient, error) {
+	channel = strings.TrimSpace(channel)
+	var entries []string
+	for _, entry := range strings.Split(to, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		if channel == "" {
+			return nil, nil
+		}
+		return []CronRecipient{{Channel: channel}}, nil
+	}
+	if channel != "" || len(entries) == 1 {
+		recipients := make([]CronRecipient, 0, len(entries))
+		for _, entry := range entries {
+			recipients = append(recipients, CronRecipient{Channel: channel, To: entry})
+		}
+		return recipients, nil
+	}
+	recipients := make([]CronRecipient, 0, len(entries))
+	for _, entry := range entries {
+		ch, chatID, ok := strings.Cut(entry, ":")
+		ch, chatID = strings.TrimSpace(ch), strings.TrimSpace(chatID)
+		if !ok || ch == "" || chatID == "" {
+			return nil, fmt.Errorf("recipient %q needs a channel: pass --channel or write channel:chat_id", entry)
+		}
+		recipients = append(recipients, CronRecipient{Channel: ch, To: chatID})
+	}
+	return recipients, nil
 }
 
 type CronJobState struct {
@@ -48,6 +129,9 @@ type CronJob struct {
 	CreatedAtMS    int64        `json:"createdAtMs"`
 	UpdatedAtMS    int64        `json:"updatedAtMs"`
 	DeleteAfterRun bool         `json:"deleteAfterRun"`
+	// CreatedBy names who scheduled the job, such as "agent:main" for jobs the
+	// agent created through the cron tool. Empty for CLI and web UI jobs.
+	CreatedBy string `json:"createdBy,omitempty"`
 }
 
 type CronStore struct {
@@ -460,6 +544,23 @@ func (cs *CronService) GetJob(jobID string) (*CronJob, bool) {
 	return nil, false
 }
 
+// RunJob runs a job's handler once, right now, and returns its result. It
+// leaves the job's schedule and run state untouched, so a one-time job can be
+// tried out without being used up.
+func (cs *CronService) RunJob(jobID string) (string, error) {
+	job, ok := cs.GetJob(jobID)
+	if !ok {
+		return "", fmt.Errorf("job %s not found", jobID)
+	}
+	cs.mu.RLock()
+	handler := cs.onJob
+	cs.mu.RUnlock()
+	if handler == nil {
+		return "", fmt.Errorf("no job handler configured")
+	}
+	return handler(job)
+}
+
 func (cs *CronService) UpdateJob(job *CronJob) error {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
@@ -505,6 +606,9 @@ func cloneCronJob(job CronJob) CronJob {
 		lastRunAtMS := *job.State.LastRunAtMS
 		clone.State.LastRunAtMS = &lastRunAtMS
 	}
+	if job.Payload.Recipients != nil {
+		clone.Payload.Recipients = append([]CronRecipient(nil), job.Payload.Recipients...)
+	}
 	return clone
 }
 
@@ -365,3 +365,77 @@ func TestCronService_ConcurrentAccess(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestParseRecipients(t *testing.T) {
+	tests := []struct {
+		channel, to string
+		want        []CronRecipient
+		wantErr     bool
+	}{
+		{channel: "telegram", to: "a, b", want: []CronRecipient{{"telegram", "a"}, {"telegram", "b"}}},
+		{channel: "", to: "chat-1", want: []CronRecipient{{"", "chat-1"}}},
+		{channel: "", to: "telegram:a,slack:C1", want: []CronRecipient{{"telegram", "a"}, {"slack", "C1"}}},
+		{channel: "", to: "a,b", wantErr: true},
+		{channel: "", to: "", want: nil},
+	}
+	for _, tt := range tests {
+		got, err := ParseRecipients(tt.channel, tt.to)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseRecipients(%q, %q) error = %v, wantErr %v", tt.channel, tt.to, err, tt.wantErr)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Fatalf("ParseRecipients(%q, %q) = %v, want %v", tt.channel, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestCronPayload_SetRecipients(t *testing.T) {
+	var p CronPayload
+	p.SetRecipients([]CronRecipient{{"telegram", "a"}, {"slack", "C1"}})
+	if p.Channel != "telegram" || p.To != "a" || len(p.Targets()) != 2 {
+		t.Fatalf("payload = %+v, want first recipient mirrored and two targets", p)
+	}
+
+	p.SetRecipients([]CronRecipient{{"discord", "x"}})
+	if p.Recipients != nil || p.Channel != "discord" || p.To != "x" {
+		t.Fatalf("payload = %+v, want single recipient without list", p)
+	}
+	if targets := p.Targets(); len(targets) != 1 || targets[0].String() != "discord:x" {
+		t.Fatalf("Targets() = %v", targets)
+	}
+}
+
+func TestCronService_RunJobLeavesRunStateAlone(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+	var ran []string
+	cs := NewCronService(storePath, func(job *CronJob) (string, error) {
+		ran = append(ran, job.ID)
+		return "ok", nil
+	})
+
+	atMS := time.Now().Add(time.Hour).UnixMilli()
+	job, err := cs.AddJob("once", CronSchedule{Kind: "at", AtMS: &atMS}, "hello", "telegram", "1")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+
+	result, err := cs.RunJob(job.ID)
+	if err != nil || result != "ok" {
+		t.Fatalf("RunJob = %q, %v", result, err)
+	}
+	if len(ran) != 1 || ran[0] != job.ID {
+		t.Fatalf("handler ran for %v, want [%s]", ran, job.ID)
+	}
+
+	got, ok := cs.GetJob(job.ID)
+	if !ok {
+		t.Fatal("one-time job should survive a test run")
+	}
+	if !got.Enabled || got.State.LastRunAtMS != nil || got.State.NextRunAtMS == nil {
+		t.Errorf("run state changed: enabled=%v state=%+v", got.Enabled, got.State)
+	}
+
+	if _, err := cs.RunJob("missing"); err == nil {
+		t.Error("RunJob should fail for an unknown job")
+	}
+}
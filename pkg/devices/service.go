@@ -131,8 +131,9 @@ func (s *Service) sendNotification(ev *events.DeviceEvent) {
 	pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer pubCancel()
 	msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
-		Context: bus.NewOutboundContext(platform, userID, ""),
-		Content: msg,
+		Context:   bus.NewOutboundContext(platform, userID, ""),
+		Content:   msg,
+		Proactive: true,
 	})
 
 	logger.InfoCF("devices", "Device notification sent", map[string]any{
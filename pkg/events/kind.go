@@ -94,6 +94,10 @@ const (
 	KindMCPServerConnecting Kind = "mcp.server.connecting"
 	// KindMCPServerFailed is emitted when an MCP server fails.
 	KindMCPServerFailed Kind = "mcp.server.failed"
+	// KindMCPServerRestarted is emitted when a crashed MCP server is restarted.
+	KindMCPServerRestarted Kind = "mcp.server.restarted"
+	// KindMCPServerRemoved is emitted when a config reload drops an MCP server.
+	KindMCPServerRemoved Kind = "mcp.server.removed"
 	// KindMCPToolDiscovered is emitted when an MCP tool is discovered.
 	KindMCPToolDiscovered Kind = "mcp.tool.discovered"
 	// KindMCPToolCallStart is emitted when an MCP tool call starts.
@@ -145,6 +149,8 @@ var knownKinds = []Kind{
 	KindMCPServerConnected,
 	KindMCPServerConnecting,
 	KindMCPServerFailed,
+	KindMCPServerRestarted,
+	KindMCPServerRemoved,
 	KindMCPToolDiscovered,
 	KindMCPToolCallStart,
 	KindMCPToolCallEnd,
@@ -0,0 +1,273 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/config"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/health"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	controlConfigPath = "/v1/config"
+	controlChatPath   = "/v1/chat"
+
+	// controlAPIChannel is the internal channel chat requests arrive on; the
+	// request's chat_id picks the conversation.
+	controlAPIChannel     = "api"
+	controlDefaultChatID  = "default"
+	maxControlChatBytes   = 64 << 10
+	controlEventBuffer    = 64
+	controlShutdownWindow = 5 * time.Second
+)
+
+// chatBackend is the part of the agent loop the chat endpoint needs.
+type chatBackend interface {
+	ProcessDirectWithChannel(ctx context.Context, content, sessionKey, channel, chatID string) (string, error)
+	RuntimeEventBus() runtimeevents.Bus
+}
+
+// controlAPI is the opt-in control API on its own port (gateway.control_api):
+//
+//	GET  /v1/config  current config (secrets masked)
+//	PUT  /v1/config  validate and save a full config
+//	POST /v1/chat    send a message; the reply is JSON, or a stream of
+//	                 server-sent events with Accept: text/event-stream
+//
+// The server speaks HTTP/1.1 and cleartext HTTP/2, so clients can multiplex
+// several streams over one connection. Every request needs the gateway token.
+type controlAPI struct {
+	servers []*http.Server
+}
+
+type controlChatRequest struct {
+	Message string `json:"message"`
+	ChatID  string `json:"chat_id,omitempty"`
+}
+
+type controlChatResponse struct {
+	ChatID  string `json:"chat_id"`
+	Content string `json:"content"`
+}
+
+// controlChatEvent is the data of one server-sent event on /v1/chat.
+type controlChatEvent struct {
+	TurnID     string `json:"turn_id,omitempty"`
+	Tool       string `json:"tool,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	IsError    bool   `json:"is_error,omitempty"`
+	Content    string `json:"content,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// startControlAPI listens on gateway.host at the control API port and serves
+// the control API until stop is called.
+func startControlAPI(
+	cfg *config.Config,
+	backend chatBackend,
+	configPath, token string,
+	guard *health.AuthGuard,
+) (*controlAPI, string, error) {
+	port := cfg.Gateway.ControlAPI.EffectivePort()
+	_, result, err := openGatewayListeners(cfg.Gateway.Host, port)
+	if err != nil {
+		return nil, "", fmt.Errorf("control API listen on port %d: %w", port, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(controlConfigPath, &configAPI{configPath: configPath, token: token, guard: guard})
+	mux.Handle(controlChatPath, &controlChatAPI{backend: backend, token: token, guard: guard})
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	api := &controlAPI{}
+	for _, ln := range result.Listeners {
+		srv := &http.Server{
+			Handler:           mux,
+			Protocols:         &protocols,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		api.servers = append(api.servers, srv)
+		go func(ln net.Listener) {
+			if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.ErrorCF("gateway", "Control API server stopped", map[string]any{"error": err.Error()})
+			}
+		}(ln)
+	}
+	return api, net.JoinHostPort(result.ProbeHost, strconv.Itoa(port)), nil
+}
+
+// stop closes the listeners and waits briefly for open requests.
+func (a *controlAPI) stop() {
+	if a == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), controlShutdownWindow)
+	defer cancel()
+	for _, srv := range a.servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			srv.Close()
+		}
+	}
+}
+
+type controlChatAPI struct {
+	backend chatBackend
+	token   string
+	guard   *health.AuthGuard
+}
+
+func (a *controlChatAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !a.guard.Authorize(w, r, a.token) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use POST")
+		return
+	}
+	var req controlChatRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxControlChatBytes)).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" {
+		writeAPIError(w, http.StatusBadRequest, "message is required")
+		return
+	}
+	req.ChatID = strings.TrimSpace(req.ChatID)
+	if req.ChatID == "" {
+		req.ChatID = controlDefaultChatID
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		content, err := a.backend.ProcessDirectWithChannel(r.Context(), req.Message, "", controlAPIChannel, req.ChatID)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeAPIJSON(w, http.StatusOK, controlChatResponse{ChatID: req.ChatID, Content: content})
+		return
+	}
+	a.stream(w, r, req)
+}
+
+// stream runs the turn and forwards its progress as server-sent events:
+// turn.started, tool.started and tool.finished while it runs, then message
+// with the reply, or error, and finally done.
+func (a *controlChatAPI) stream(w http.ResponseWriter, r *http.Request, req controlChatRequest) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, "streaming is not supported by this connection")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	var events <-chan runtimeevents.Event
+	if eventBus := a.backend.RuntimeEventBus(); eventBus != nil {
+		sub, ch, err := eventBus.Channel().
+			Scope(runtimeevents.ScopeFilter{Channel: controlAPIChannel, ChatID: req.ChatID}).
+			OfKind(
+				runtimeevents.KindAgentTurnStart,
+				runtimeevents.KindAgentToolExecStart,
+				runtimeevents.KindAgentToolExecEnd,
+			).
+			SubscribeChan(ctx, runtimeevents.SubscribeOptions{
+				Name:         "control-api-chat",
+				Buffer:       controlEventBuffer,
+				Backpressure: runtimeevents.DropNewest,
+			})
+		if err == nil {
+			defer sub.Close()
+			events = ch
+		}
+	}
+
+	type turnResult struct {
+		content string
+		err     error
+	}
+	done := make(chan turnResult, 1)
+	go func() {
+		content, err := a.backend.ProcessDirectWithChannel(ctx, req.Message, "", controlAPIChannel, req.ChatID)
+		done <- turnResult{content: content, err: err}
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(name string, data controlChatEvent) {
+		payload, _ := json.Marshal(data)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+		flusher.Flush()
+	}
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if name, data, ok := controlEventFor(evt); ok {
+				send(name, data)
+			}
+		case res := <-done:
+			for drained := false; !drained; {
+				select {
+				case evt, ok := <-events:
+					if !ok {
+						drained = true
+					} else if name, data, ok := controlEventFor(evt); ok {
+						send(name, data)
+					}
+				default:
+					drained = true
+				}
+			}
+			if res.err != nil {
+				send("error", controlChatEvent{Error: res.err.Error()})
+			} else {
+				send("message", controlChatEvent{Content: res.content})
+			}
+			send("done", controlChatEvent{})
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func controlEventFor(evt runtimeevents.Event) (string, controlChatEvent, bool) {
+	data := controlChatEvent{TurnID: evt.Scope.TurnID}
+	switch payload := evt.Payload.(type) {
+	case agent.TurnStartPayload:
+		return "turn.started", data, true
+	case agent.ToolExecStartPayload:
+		data.Tool = payload.Tool
+		return "tool.started", data, true
+	case agent.ToolExecEndPayload:
+		data.Tool = payload.Tool
+		data.DurationMS = payload.Duration.Milliseconds()
+		data.IsError = payload.IsError
+		return "tool.finished", data, true
+	default:
+		return "", data, false
+	}
+}
@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/health"
+)
+
+type fakeChatBackend struct {
+	bus      *runtimeevents.EventBus
+	chatID   string
+	response string
+	err      error
+}
+
+func (b *fakeChatBackend) ProcessDirectWithChannel(
+	ctx context.Context,
+	content, _, channel, chatID string,
+) (string, error) {
+	b.chatID = chatID
+	scope := runtimeevents.Scope{Channel: channel, ChatID: chatID, TurnID: "turn-1"}
+	b.bus.Publish(ctx, runtimeevents.Event{
+		Kind:    runtimeevents.KindAgentTurnStart,
+		Scope:   scope,
+		Payload: agent.TurnStartPayload{UserMessage: content},
+	})
+	b.bus.Publish(ctx, runtimeevents.Event{
+		Kind:    runtimeevents.KindAgentToolExecEnd,
+		Scope:   scope,
+		Payload: agent.ToolExecEndPayload{Tool: "web_search", Duration: 1500 * time.Millisecond},
+	})
+	// Another conversation's events must not leak into this stream.
+	b.bus.Publish(ctx, runtimeevents.Event{
+		Kind:    runtimeevents.KindAgentTurnStart,
+		Scope:   runtimeevents.Scope{Channel: channel, ChatID: "other"},
+		Payload: agent.TurnStartPayload{},
+	})
+	return b.response, b.err
+}
+
+func (b *fakeChatBackend) RuntimeEventBus() runtimeevents.Bus {
+	return b.bus
+}
+
+func newTestControlChatAPI(backend *fakeChatBackend) *controlChatAPI {
+	return &controlChatAPI{
+		backend: backend,
+		token:   "secret",
+		guard:   health.NewAuthGuard(0, time.Minute, time.Minute),
+	}
+}
+
+func serveControlChat(api *controlChatAPI, method, body, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, controlChatPath, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestControlChatAPI_JSON(t *testing.T) {
+	backend := &fakeChatBackend{bus: runtimeevents.NewBus(), response: "Hello!"}
+	defer backend.bus.Close()
+	api := newTestControlChatAPI(backend)
+
+	rec := serveControlChat(api, http.MethodPost, `{"message":"hi"}`, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var got controlChatResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Content != "Hello!" || got.ChatID != controlDefaultChatID || backend.chatID != controlDefaultChatID {
+		t.Fatalf("response = %+v, backend chat = %q", got, backend.chatID)
+	}
+
+	if rec := serveControlChat(api, http.MethodPost, `{"message":"  "}`, ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty message: status = %d, want 400", rec.Code)
+	}
+	if rec := serveControlChat(api, http.MethodGet, "", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET: status = %d, want 405", rec.Code)
+	}
+	req := httptest.NewRequest(http.MethodPost, controlChatPath, strings.NewReader(`{"message":"hi"}`))
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("no token: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestControlChatAPI_StreamsEvents(t *testing.T) {
+	backend := &fakeChatBackend{bus: runtimeevents.NewBus(), response: "Found it."}
+	defer backend.bus.Close()
+	api := newTestControlChatAPI(backend)
+
+	rec := serveControlChat(api, http.MethodPost, `{"message":"search","chat_id":"ops"}`, "text/event-stream")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, content type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	var names []string
+	for _, line := range strings.Split(body, "\n") {
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			names = append(names, name)
+		}
+	}
+	want := []string{"turn.started", "tool.finished", "message", "done"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("events = %v, want %v\n%s", names, want, body)
+	}
+	if !strings.Contains(body, `"tool":"web_search","duration_ms":1500`) ||
+		!strings.Contains(body, `"content":"Found it."`) {
+		t.Fatalf("unexpected stream:\n%s", body)
+	}
+
+	backend.err = errors.New("provider down")
+	body = serveControlChat(api, http.MethodPost, `{"message":"search"}`, "text/event-stream").Body.String()
+	if !strings.Contains(body, "event: error\ndata: {\"error\":\"provider down\"}") {
+		t.Fatalf("missing error event:\n%s", body)
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
@@ -31,6 +33,7 @@ import (
 	_ "github.com/sipeed/picoclaw/pkg/channels/qq"
 	_ "github.com/sipeed/picoclaw/pkg/channels/slack"
 	_ "github.com/sipeed/picoclaw/pkg/channels/slack_webhook"
+	_ "github.com/sipeed/picoclaw/pkg/channels/sms"
 	_ "github.com/sipeed/picoclaw/pkg/channels/teams_webhook"
 	_ "github.com/sipeed/picoclaw/pkg/channels/telegram"
 	_ "github.com/sipeed/picoclaw/pkg/channels/vk"
@@ -45,10 +48,12 @@ import (
 	"github.com/sipeed/picoclaw/pkg/health"
 	"github.com/sipeed/picoclaw/pkg/heartbeat"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/mcp"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/netbind"
 	"github.com/sipeed/picoclaw/pkg/pid"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/state"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
@@ -70,6 +75,7 @@ type services struct {
 	ChannelManager   *channels.Manager
 	DeviceService    *devices.Service
 	HealthServer     *health.Server
+	ControlAPI       *controlAPI
 	VoiceAgentCancel context.CancelFunc
 	manualReloadChan chan struct{}
 	reloading        atomic.Bool
@@ -115,6 +121,11 @@ func (p *startupBlockedProvider) GetDefaultModel() string {
 	return ""
 }
 
+// LogFilePath returns where the gateway writes its JSON log for homePath.
+func LogFilePath(homePath string) string {
+	return filepath.Join(homePath, logPath, logFile)
+}
+
 // Run starts the gateway runtime using the configuration loaded from configPath.
 func Run(debug bool, homePath, configPath string, allowEmptyStartup bool) (runErr error) {
 	startedAt := time.Now()
@@ -125,7 +136,7 @@ func Run(debug bool, homePath, configPath string, allowEmptyStartup bool) (runEr
 	}
 	defer panicFunc()
 
-	if err = logger.EnableFileLogging(filepath.Join(homePath, logPath, logFile)); err != nil {
+	if err = logger.EnableFileLogging(LogFilePath(homePath)); err != nil {
 		logger.Fatal(fmt.Sprintf("error enabling file logging: %v", err))
 	}
 	defer logger.DisableFileLogging()
@@ -135,6 +146,7 @@ func Run(debug bool, homePath, configPath string, allowEmptyStartup bool) (runEr
 	} else {
 		logger.SetLevelFromString(config.ResolveGatewayLogLevel(configPath))
 	}
+	logger.SetFormat(config.ResolveGatewayLogFormat(configPath))
 	defer func() {
 		if runErr != nil {
 			logger.ErrorCF("gateway", "Gateway startup failed", map[string]any{
@@ -156,6 +168,9 @@ func Run(debug bool, homePath, configPath string, allowEmptyStartup bool) (runEr
 		return fmt.Errorf("config pre-check failed: %w", err)
 	}
 
+	logger.SetComponentLevels(config.EffectiveGatewayComponentLogLevels(cfg))
+	logger.SetRedactor(config.EffectiveLogRedactor(cfg))
+
 	// Debug mode permanently overrides the config log level to DEBUG.
 	if debug {
 		fmt.Println("🔍 Debug mode enabled")
@@ -190,6 +205,14 @@ func Run(debug bool, homePath, configPath string, allowEmptyStartup bool) (runEr
 		}
 	}()
 
+	// Installs interrupted by a crash leave staging directories in the
+	// skills folder; the hour of slack spares installs the CLI or web
+	// backend may be running right now.
+	if n := skills.CleanupStaleInstalls(filepath.Join(cfg.WorkspacePath(), "skills"), time.Hour); n > 0 {
+		logger.InfoCF("gateway", "Removed stale skill install directories", map[string]any{"count": n})
+	}
+
+	providers.Configure(cfg)
 	provider, modelID, err := createStartupProvider(cfg, allowEmptyStartup)
 	if err != nil {
 		return fmt.Errorf("error creating provider: %w", err)
@@ -218,7 +241,7 @@ func Run(debug bool, homePath, configPath string, allowEmptyStartup bool) (runEr
 			"skills_available": skillsInfo["available"],
 		})
 
-	runningServices, err := setupAndStartServices(cfg, agentLoop, msgBus, pidData.Token, listenResult)
+	runningServices, err := setupAndStartServices(cfg, homePath, configPath, agentLoop, msgBus, pidData.Token, listenResult)
 	if err != nil {
 		return err
 	}
@@ -363,6 +386,7 @@ func createStartupProvider(
 
 func setupAndStartServices(
 	cfg *config.Config,
+	homePath, configPath string,
 	agentLoop *agent.AgentLoop,
 	msgBus *bus.MessageBus,
 	authToken string,
@@ -395,6 +419,10 @@ func setupAndStartServices(
 	)
 	runningServices.HeartbeatService.SetBus(msgBus)
 	runningServices.HeartbeatService.SetHandler(createHeartbeatHandler(agentLoop))
+	runningServices.HeartbeatService.SetTargetFunc(
+		proactiveTarget(cfg.Heartbeat.DefaultChannel, cfg.Heartbeat.DefaultChatID, agentLoop),
+	)
+	runningServices.HeartbeatService.SetRecipients(heartbeatRecipients(cfg.Heartbeat.Recipients))
 	if err = runningServices.HeartbeatService.Start(); err != nil {
 		return nil, fmt.Errorf("error starting heartbeat service: %w", err)
 	}
@@ -429,6 +457,9 @@ func setupAndStartServices(
 	if transcriber != nil {
 		agentLoop.SetTranscriber(transcriber)
 		logger.InfoCF("voice", "Transcription enabled (agent-level)", map[string]any{"provider": transcriber.Name()})
+	} else {
+		logger.InfoCF("voice", "Transcription disabled; voice messages will get a setup hint reply",
+			map[string]any{"hint": "set voice.model_name to a transcription-capable model"})
 	}
 
 	ttsAvailable := tts.DetectTTS(cfg) != nil
@@ -442,6 +473,20 @@ func setupAndStartServices(
 
 	runningServices.authToken = authToken
 	runningServices.HealthServer = health.NewServer(listenResult.ProbeHost, cfg.Gateway.Port, authToken)
+	authGuard := health.NewAuthGuard(cfg.Gateway.Security.AuthLockoutPolicy())
+	runningServices.HealthServer.SetAuthGuard(authGuard)
+	runningServices.HealthServer.RegisterLiveCheck("mcp", func() (bool, string) {
+		return mcpHealthCheck(agentLoop.MCPServerHealth())
+	})
+	runningServices.HealthServer.RegisterLiveCheck("agent_queue", func() (bool, string) {
+		return agentQueueCheck(agentLoop.QueueStats())
+	})
+	runningServices.HealthServer.RegisterLiveCheck("provider", func() (bool, string) {
+		return providerHealthCheck(agentLoop.ProviderHealth())
+	})
+	runningServices.HealthServer.RegisterLiveCheck("channels", func() (bool, string) {
+		return channelsHealthCheck(runningServices.ChannelManager)
+	})
 
 	var listenAddr string
 	if len(listenResult.Listeners) > 0 {
@@ -454,6 +499,26 @@ func setupAndStartServices(
 		listenAddr,
 		runningServices.HealthServer,
 	)
+	(&sessionsAPI{backend: agentLoop, token: authToken, guard: authGuard}).register(runningServices.ChannelManager)
+	(&toolStatsAPI{backend: agentLoop, token: authToken, guard: authGuard}).register(runningServices.ChannelManager)
+	(&providersAPI{backend: agentLoop, token: authToken, guard: authGuard}).register(runningServices.ChannelManager)
+	(&rotateKeyAPI{
+		rotate: func() (string, error) { return pid.RotateToken(homePath) },
+		token:  authToken,
+		guard:  authGuard,
+	}).register(runningServices.ChannelManager)
+	if cfg.Gateway.UI.Enabled {
+		registerUI(runningServices.ChannelManager, configPath, authToken, authGuard)
+	}
+	if cfg.Gateway.ControlAPI.Enabled {
+		api, addr, apiErr := startControlAPI(cfg, agentLoop, configPath, authToken, authGuard)
+		if apiErr != nil {
+			logger.ErrorCF("gateway", "Control API unavailable", map[string]any{"error": apiErr.Error()})
+		} else {
+			runningServices.ControlAPI = api
+			fmt.Printf("✓ Control API available at http://%s/v1/ (HTTP/1.1 and h2c)\n", addr)
+		}
+	}
 
 	if err = runningServices.ChannelManager.StartAll(context.Background()); err != nil {
 		return nil, fmt.Errorf("error starting channels: %w", err)
@@ -471,9 +536,16 @@ func setupAndStartServices(
 
 	healthAddr := net.JoinHostPort(listenResult.ProbeHost, strconv.Itoa(cfg.Gateway.Port))
 	fmt.Printf(
-		"✓ Health endpoints available at http://%s/health, /ready and /reload (POST)\n",
+		"✓ Health endpoints available at http://%s/health, /ready, /livez, /readyz and /reload (POST)\n",
 		healthAddr,
 	)
+	fmt.Printf("✓ Sessions API available at http://%s%s (bearer token from the gateway PID file)\n",
+		healthAddr, sessionsAPIPath)
+	fmt.Printf("✓ Tool stats available at http://%s%s\n", healthAddr, toolStatsAPIPath)
+	fmt.Printf("✓ Provider cooldowns available at http://%s%s\n", healthAddr, providersAPIPath)
+	if cfg.Gateway.UI.Enabled {
+		fmt.Printf("✓ Web UI available at http://%s/\n", healthAddr)
+	}
 
 	stateManager := state.NewManager(cfg.WorkspacePath())
 	runningServices.DeviceService = devices.NewService(devices.Config{
@@ -490,6 +562,108 @@ func setupAndStartServices(
 	return runningServices, nil
 }
 
+// mcpHealthCheck summarizes MCP supervision for the health endpoint. Servers
+// that are restarting keep the check passing; one that exhausted its restart
+// budget fails it.
+func mcpHealthCheck(servers []mcp.ServerHealth) (bool, string) {
+	if len(servers) == 0 {
+		return true, "no MCP servers connected"
+	}
+	ok := true
+	parts := make([]string, 0, len(servers))
+	for _, s := range servers {
+		part := fmt.Sprintf("%s: %s", s.Name, s.State)
+		if s.Restarts > 0 {
+			part += fmt.Sprintf(" (%d restarts)", s.Restarts)
+		}
+		if s.State != mcp.ServerStateHealthy && s.LastError != "" {
+			part += " - " + s.LastError
+		}
+		if s.State == mcp.ServerStateFailed {
+			ok = false
+		}
+		parts = append(parts, part)
+	}
+	return ok, strings.Join(parts, "; ")
+}
+
+// agentQueueCheck reports inbound queue depth on the health endpoint. It
+// never fails readiness: a backlog means the gateway is busy, not broken.
+func agentQueueCheck(stats agent.QueueStats) (bool, string) {
+	return true, fmt.Sprintf(
+		"%d/%d turns active, %d sessions waiting, %d messages queued, %d dropped, %d coalesced, "+
+			"%d subagents active, %d subagents queued",
+		stats.ActiveTurns, stats.WorkerSlots, stats.WaitingSessions,
+		stats.QueuedMessages, stats.Dropped, stats.Coalesced,
+		stats.ActiveSubagents, stats.QueuedSubagents,
+	)
+}
+
+// providerHealthCheck fails readiness after several LLM calls in a row could
+// not reach the provider. Models in cooldown are mentioned but do not fail
+// the check, since the fallback chain can still answer.
+func providerHealthCheck(h agent.ProviderHealth) (bool, string) {
+	var ok bool
+	var msg string
+	switch {
+	case h.ConsecutiveFailures > 0:
+		ok, msg = h.Healthy, fmt.Sprintf("%d calls failed in a row - %s", h.ConsecutiveFailures, h.LastError)
+	case h.LastSuccess.IsZero():
+		ok, msg = true, "no LLM calls yet"
+	default:
+		ok, msg = true, "last call succeeded "+h.LastSuccess.Format(time.RFC3339)
+	}
+	if h.InCooldown > 0 {
+		msg += fmt.Sprintf(" (%d model(s) in cooldown)", h.InCooldown)
+	}
+	return ok, msg
+}
+
+// channelsHealthCheck fails readiness while an enabled channel is not
+// connected or its circuit breaker has marked it degraded.
+func channelsHealthCheck(cm *channels.Manager) (bool, string) {
+	if cm == nil {
+		return false, "channel manager not started"
+	}
+	names := cm.GetEnabledChannels()
+	running := make(map[string]bool, len(names))
+	for _, name := range names {
+		if ch, ok := cm.GetChannel(name); ok {
+			running[name] = ch.IsRunning()
+		}
+	}
+	return channelsReadiness(names, running, cm.ChannelHealth())
+}
+
+func channelsReadiness(
+	names []string,
+	running map[string]bool,
+	health map[string]channels.ChannelHealth,
+) (bool, string) {
+	if len(names) == 0 {
+		return true, "no channels enabled"
+	}
+	names = slices.Sorted(slices.Values(names))
+	ok := true
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		state := string(health[name].State)
+		if !running[name] {
+			state = "not connected"
+		} else if state == "" {
+			state = string(channels.ChannelHealthy)
+		}
+		if state != string(channels.ChannelHealthy) {
+			ok = false
+			if lastErr := health[name].LastError; lastErr != "" {
+				state += " - " + lastErr
+			}
+		}
+		parts = append(parts, name+": "+state)
+	}
+	return ok, strings.Join(parts, "; ")
+}
+
 func stopAndCleanupServices(runningServices *services, shutdownTimeout time.Duration, isReload bool) {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer shutdownCancel()
@@ -498,6 +672,9 @@ func stopAndCleanupServices(runningServices *services, shutdownTimeout time.Dura
 	if !isReload && runningServices.ChannelManager != nil {
 		runningServices.ChannelManager.StopAll(shutdownCtx)
 	}
+	if !isReload {
+		runningServices.ControlAPI.stop()
+	}
 	if runningServices.VoiceAgentCancel != nil {
 		runningServices.VoiceAgentCancel()
 	}
@@ -561,6 +738,7 @@ func handleConfigReload(
 	logger.Info("  Stopping all services...")
 	stopAndCleanupServices(runningServices, serviceShutdownTimeout, true)
 
+	providers.Configure(newCfg)
 	newProvider, newModelID, err := createStartupProvider(newCfg, allowEmptyStartup)
 	if err != nil {
 		logger.Errorf("  ⚠ Error creating new provider: %v", err)
@@ -600,6 +778,10 @@ func handleConfigReload(
 
 	logger.Info("  ✓ Provider, configuration, and services reloaded successfully (thread-safe)")
 
+	logger.SetFormat(config.EffectiveGatewayLogFormat(newCfg))
+	logger.SetComponentLevels(config.EffectiveGatewayComponentLogLevels(newCfg))
+	logger.SetRedactor(config.EffectiveLogRedactor(newCfg))
+
 	// Debug mode permanently overrides the config log level to DEBUG.
 	if !debug {
 		// Update log level last so that reload-related info/warn logs above are not suppressed.
@@ -643,6 +825,10 @@ func restartServices(
 	)
 	runningServices.HeartbeatService.SetBus(msgBus)
 	runningServices.HeartbeatService.SetHandler(createHeartbeatHandler(al))
+	runningServices.HeartbeatService.SetTargetFunc(
+		proactiveTarget(cfg.Heartbeat.DefaultChannel, cfg.Heartbeat.DefaultChatID, al),
+	)
+	runningServices.HeartbeatService.SetRecipients(heartbeatRecipients(cfg.Heartbeat.Recipients))
 	if err = runningServices.HeartbeatService.Start(); err != nil {
 		return fmt.Errorf("error restarting heartbeat service: %w", err)
 	}
@@ -718,8 +904,7 @@ func setupConfigWatcherPolling(configPath string, debug bool) (chan *config.Conf
 	go func() {
 		defer wg.Done()
 
-		lastModTime := getFileModTime(configPath)
-		lastSize := getFileSize(configPath)
+		lastState := configFilesState(configPath)
 
 		ticker := time.NewTicker(2 * time.Second)
 		defer ticker.Stop()
@@ -727,18 +912,16 @@ func setupConfigWatcherPolling(configPath string, debug bool) (chan *config.Conf
 		for {
 			select {
 			case <-ticker.C:
-				currentModTime := getFileModTime(configPath)
-				currentSize := getFileSize(configPath)
+				currentState := configFilesState(configPath)
 
-				if currentModTime.After(lastModTime) || currentSize != lastSize {
+				if currentState != lastState {
 					if debug {
 						logger.Debugf("🔍 Config file change detected")
 					}
 
 					time.Sleep(500 * time.Millisecond)
 
-					lastModTime = currentModTime
-					lastSize = currentSize
+					lastState = currentState
 
 					newCfg, err := config.LoadConfig(configPath)
 					if err != nil {
@@ -775,6 +958,16 @@ func setupConfigWatcherPolling(configPath string, debug bool) (chan *config.Conf
 	return configChan, stopFunc
 }
 
+// configFilesState fingerprints the config file and every file it includes,
+// so that editing an overlay or secrets file also triggers a reload.
+func configFilesState(configPath string) string {
+	var b strings.Builder
+	for _, path := range append([]string{configPath}, config.IncludedFiles(configPath)...) {
+		fmt.Fprintf(&b, "%s:%d:%d\n", path, getFileModTime(path).UnixNano(), getFileSize(path))
+	}
+	return b.String()
+}
+
 func getFileModTime(path string) time.Time {
 	info, err := os.Stat(path)
 	if err != nil {
@@ -791,6 +984,25 @@ func getFileSize(path string) int64 {
 	return info.Size()
 }
 
+// SetupCronService builds the cron service the gateway runs, with the same
+// job handler, so callers outside the gateway can execute jobs exactly as the
+// scheduler would. The service is not started.
+func SetupCronService(
+	agentLoop *agent.AgentLoop,
+	msgBus *bus.MessageBus,
+	cfg *config.Config,
+) (*cron.CronService, error) {
+	execTimeout := time.Duration(cfg.Tools.Cron.ExecTimeoutMinutes) * time.Minute
+	return setupCronTool(
+		agentLoop,
+		msgBus,
+		cfg.WorkspacePath(),
+		cfg.Agents.Defaults.RestrictToWorkspace,
+		execTimeout,
+		cfg,
+	)
+}
+
 func setupCronTool(
 	agentLoop *agent.AgentLoop,
 	msgBus *bus.MessageBus,
@@ -802,6 +1014,9 @@ func setupCronTool(
 	cronStorePath := filepath.Join(workspace, "cron", "jobs.json")
 
 	cronService := cron.NewCronService(cronStorePath, nil)
+	fallbackTarget := tools.CronTargetFunc(
+		proactiveTarget(cfg.Tools.Cron.DefaultChannel, cfg.Tools.Cron.DefaultChatID, agentLoop),
+	)
 
 	var cronTool *tools.CronTool
 	if cfg.Tools.IsToolEnabled("cron") {
@@ -810,27 +1025,75 @@ func setupCronTool(
 		if err != nil {
 			return nil, fmt.Errorf("critical error during CronTool initialization: %w", err)
 		}
+		cronTool.SetFallbackTarget(fallbackTarget)
 
 		agentLoop.RegisterTool(cronTool)
 	}
 
+	if cfg.Tools.IsToolEnabled("reminder") {
+		agentLoop.RegisterTool(tools.NewReminderTool(cronService))
+	}
+
 	if cronTool != nil {
 		cronService.SetOnJob(func(job *cron.CronJob) (string, error) {
 			result := cronTool.ExecuteJob(context.Background(), job)
 			return result, nil
 		})
+	} else {
+		// Without the cron tool only plain delivered messages, such as
+		// reminders, can run; agent-turn and command jobs stay dormant.
+		cronService.SetOnJob(func(job *cron.CronJob) (string, error) {
+			if !job.Payload.Deliver || job.Payload.Command != "" {
+				return "", fmt.Errorf("cron tool is disabled; cannot run job %s", job.ID)
+			}
+			if err := tools.DeliverCronMessage(context.Background(), msgBus, job, fallbackTarget); err != nil {
+				return "", err
+			}
+			return "ok", nil
+		})
 	}
 
 	return cronService, nil
 }
 
+// proactiveTarget returns where heartbeat and cron output goes when nothing
+// ties it to a chat: the configured default if both parts are set, otherwise
+// the last chat a user wrote from. Empty results leave callers on cli:direct.
+func proactiveTarget(channel, chatID string, agentLoop *agent.AgentLoop) func() (string, string) {
+	channel, chatID = strings.TrimSpace(channel), strings.TrimSpace(chatID)
+	return func() (string, string) {
+		if channel != "" && chatID != "" {
+			return channel, chatID
+		}
+		return agentLoop.LastChannel()
+	}
+}
+
+// heartbeatRecipients parses "channel:chat_id" entries. Malformed entries are
+// logged and skipped so one typo does not silence the others.
+func heartbeatRecipients(entries []string) []heartbeat.Recipient {
+	var recipients []heartbeat.Recipient
+	for _, entry := range entries {
+		channel, chatID, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		channel, chatID = strings.TrimSpace(channel), strings.TrimSpace(chatID)
+		if !ok || channel == "" || chatID == "" {
+			logger.WarnCF("heartbeat", "Ignoring heartbeat recipient, want channel:chat_id", map[string]any{
+				"recipient": entry,
+			})
+			continue
+		}
+		recipients = append(recipients, heartbeat.Recipient{Channel: channel, ChatID: chatID})
+	}
+	return recipients
+}
+
 func createHeartbeatHandler(agentLoop *agent.AgentLoop) func(prompt, channel, chatID string) *tools.ToolResult {
 	return func(prompt, channel, chatID string) *tools.ToolResult {
 		if channel == "" || chatID == "" {
 			channel, chatID = "cli", "direct"
 		}
 
-		response, err := agentLoop.ProcessHeartbeat(context.Background(), prompt, channel, chatID)
+		response, err := agentLoop.ProcessHeartbeat(bus.WithProactive(context.Background()), prompt, channel, chatID)
 		if err != nil {
 			return tools.ErrorResult(fmt.Sprintf("Heartbeat error: %v", err))
 		}
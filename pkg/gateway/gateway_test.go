@@ -13,8 +13,10 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/agent"
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
 	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/mcp"
 )
 
 func TestRun_StartupFailuresReturnErrorAndEmitStructuredLog(t *testing.T) {
@@ -209,3 +211,69 @@ func receiveGatewayRuntimeEvent(t *testing.T, ch <-chan runtimeevents.Event) run
 		return runtimeevents.Event{}
 	}
 }
+
+func TestMCPHealthCheck(t *testing.T) {
+	ok, msg := mcpHealthCheck(nil)
+	if !ok || msg != "no MCP servers connected" {
+		t.Fatalf("mcpHealthCheck(nil) = %v, %q", ok, msg)
+	}
+
+	ok, msg = mcpHealthCheck([]mcp.ServerHealth{
+		{Name: "fs", State: mcp.ServerStateHealthy, Restarts: 1},
+		{Name: "github", State: mcp.ServerStateRestarting, LastError: "exit status 1"},
+	})
+	if !ok {
+		t.Fatal("restarting servers should not fail the check")
+	}
+	if msg != "fs: healthy (1 restarts); github: restarting - exit status 1" {
+		t.Fatalf("message = %q", msg)
+	}
+
+	ok, _ = mcpHealthCheck([]mcp.ServerHealth{{Name: "github", State: mcp.ServerStateFailed}})
+	if ok {
+		t.Fatal("a server that exhausted its restarts should fail the check")
+	}
+}
+
+func TestProviderHealthCheck(t *testing.T) {
+	if ok, msg := providerHealthCheck(agent.ProviderHealth{Healthy: true}); !ok || msg != "no LLM calls yet" {
+		t.Fatalf("fresh provider = %v, %q", ok, msg)
+	}
+	ok, msg := providerHealthCheck(agent.ProviderHealth{
+		Healthy:             false,
+		ConsecutiveFailures: 3,
+		LastError:           "connection refused",
+	})
+	if ok || msg != "3 calls failed in a row - connection refused" {
+		t.Fatalf("failing provider = %v, %q", ok, msg)
+	}
+	ok, msg = providerHealthCheck(agent.ProviderHealth{Healthy: true, InCooldown: 2})
+	if !ok || msg != "no LLM calls yet (2 model(s) in cooldown)" {
+		t.Fatalf("models in cooldown = %v, %q", ok, msg)
+	}
+}
+
+func TestChannelsReadiness(t *testing.T) {
+	if ok, msg := channelsReadiness(nil, nil, nil); !ok || msg != "no channels enabled" {
+		t.Fatalf("no channels = %v, %q", ok, msg)
+	}
+
+	names := []string{"telegram", "discord"}
+	running := map[string]bool{"telegram": true, "discord": true}
+	health := map[string]channels.ChannelHealth{"telegram": {State: channels.ChannelHealthy}}
+	ok, msg := channelsReadiness(names, running, health)
+	if !ok || msg != "discord: healthy; telegram: healthy" {
+		t.Fatalf("connected channels = %v, %q", ok, msg)
+	}
+
+	running["discord"] = false
+	if ok, msg = channelsReadiness(names, running, health); ok || msg != "discord: not connected; telegram: healthy" {
+		t.Fatalf("disconnected channel = %v, %q", ok, msg)
+	}
+
+	running["discord"] = true
+	health["telegram"] = channels.ChannelHealth{State: channels.ChannelDegraded, LastError: "502"}
+	if ok, msg = channelsReadiness(names, running, health); ok || msg != "discord: healthy; telegram: degraded - 502" {
+		t.Fatalf("degraded channel = %v, %q", ok, msg)
+	}
+}
@@ -0,0 +1,81 @@
+package gateway
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/health"
+)
+
+const (
+	providersAPIPath       = "/api/providers"
+	providersResetCooldown = "/reset-cooldown"
+)
+
+// providersBackend is the part of the agent loop the providers API needs.
+type providersBackend interface {
+	ProvidersStatus() agent.ProvidersStatus
+	ResetProviderCooldown(name string) (agent.ProviderCooldown, bool)
+}
+
+// providersAPI serves the fallback chain's cooldown state:
+//
+//	GET  /api/providers                       active model, fallback chain and cooldowns
+//	POST /api/providers/{name}/reset-cooldown make a model available again
+//
+// {name} is the model_name from model_list, path escaped. Like the sessions
+// API, every request needs the gateway token as a bearer token.
+type providersAPI struct {
+	backend providersBackend
+	token   string
+	guard   *health.AuthGuard
+}
+
+type providerResetResponse struct {
+	Provider agent.ProviderCooldown `json:"provider"`
+}
+
+func (a *providersAPI) register(cm *channels.Manager) {
+	cm.HandleHTTP(providersAPIPath, a)
+	cm.HandleHTTP(providersAPIPath+"/", a)
+}
+
+func (a *providersAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !a.guard.Authorize(w, r, a.token) {
+		return
+	}
+
+	rest := strings.TrimPrefix(strings.TrimPrefix(r.URL.EscapedPath(), providersAPIPath), "/")
+	if rest == "" {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use GET")
+			return
+		}
+		writeAPIJSON(w, http.StatusOK, a.backend.ProvidersStatus())
+		return
+	}
+
+	rawName, ok := strings.CutSuffix(rest, providersResetCooldown)
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "not found")
+		return
+	}
+	name, err := url.PathUnescape(rawName)
+	if err != nil || strings.TrimSpace(name) == "" {
+		writeAPIError(w, http.StatusBadRequest, "invalid provider name")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use POST")
+		return
+	}
+	provider, found := a.backend.ResetProviderCooldown(name)
+	if !found {
+		writeAPIError(w, http.StatusNotFound, "provider not found")
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, providerResetResponse{Provider: provider})
+}
@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+)
+
+type fakeProvidersBackend struct {
+	status agent.ProvidersStatus
+	reset  []string
+}
+
+func (f *fakeProvidersBackend) ProvidersStatus() agent.ProvidersStatus { return f.status }
+
+func (f *fakeProvidersBackend) ResetProviderCooldown(name string) (agent.ProviderCooldown, bool) {
+	for _, p := range f.status.Providers {
+		if p.Name == name {
+			f.reset = append(f.reset, name)
+			return agent.ProviderCooldown{Name: p.Name, Provider: p.Provider, Model: p.Model, Available: true}, true
+		}
+	}
+	return agent.ProviderCooldown{}, false
+}
+
+func TestProvidersAPI(t *testing.T) {
+	backend := &fakeProvidersBackend{status: agent.ProvidersStatus{
+		ActiveModel:   "qwen-main",
+		FallbackChain: []string{"qwen-main", "deepseek/deepseek-chat"},
+		Providers: []agent.ProviderCooldown{
+			{Name: "qwen-main", Provider: "openai", Model: "qwen3.5", Available: true},
+			{
+				Name: "deepseek/deepseek-chat", Provider: "deepseek", Model: "deepseek-chat",
+				CooldownRemainingSeconds: 300, ErrorCount: 2,
+			},
+		},
+	}}
+	api := &providersAPI{backend: backend, token: "secret"}
+
+	serve := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(http.MethodGet, providersAPIPath, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("no token: status = %d, want 401", rec.Code)
+	}
+	if rec := serve(http.MethodPost, providersAPIPath, "secret"); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST list: status = %d, want 405", rec.Code)
+	}
+
+	rec := serve(http.MethodGet, providersAPIPath, "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var got agent.ProvidersStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.ActiveModel != "qwen-main" || len(got.FallbackChain) != 2 || len(got.Providers) != 2 {
+		t.Fatalf("status = %+v", got)
+	}
+	if p := got.Providers[1]; p.Available || p.CooldownRemainingSeconds != 300 || p.ErrorCount != 2 {
+		t.Fatalf("cooling provider = %+v", p)
+	}
+
+	resetPath := providersAPIPath + "/deepseek%2Fdeepseek-chat/reset-cooldown"
+	if rec := serve(http.MethodPost, resetPath, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("reset without token: status = %d, want 401", rec.Code)
+	}
+	if rec := serve(http.MethodGet, resetPath, "secret"); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET reset: status = %d, want 405", rec.Code)
+	}
+	rec = serve(http.MethodPost, resetPath, "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reset status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var reset providerResetResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &reset); err != nil {
+		t.Fatalf("decode reset: %v", err)
+	}
+	if !reset.Provider.Available || len(backend.reset) != 1 || backend.reset[0] != "deepseek/deepseek-chat" {
+		t.Fatalf("reset = %+v, backend saw %v", reset, backend.reset)
+	}
+
+	if rec := serve(http.MethodPost, providersAPIPath+"/unknown/reset-cooldown", "secret"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown provider: status = %d, want 404", rec.Code)
+	}
+	if rec := serve(http.MethodGet, providersAPIPath+"/qwen-main", "secret"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown route: status = %d, want 404", rec.Code)
+	}
+}
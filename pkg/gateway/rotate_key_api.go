@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/health"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	// RotateKeyAPIPath rotates the gateway token; see rotateKeyAPI.
+	RotateKeyAPIPath = "/api/gateway/rotate-key"
+
+	// DefaultKeyRotationGrace is how long the replaced token keeps working.
+	DefaultKeyRotationGrace = time.Minute
+	maxKeyRotationGrace     = time.Hour
+)
+
+// rotateKeyAPI serves POST /api/gateway/rotate-key: it replaces the gateway
+// token in the PID file and on every protected endpoint, without a restart.
+// The old token keeps working for grace_seconds (default 60, at most 3600)
+// so clients can switch over; 0 retires it at once. The request itself needs
+// the current token.
+type rotateKeyAPI struct {
+	rotate func() (string, error) // writes a new token to the PID file
+	token  string
+	guard  *health.AuthGuard
+}
+
+type rotateKeyRequest struct {
+	GraceSeconds *int `json:"grace_seconds,omitempty"`
+}
+
+// RotateKeyResponse is the reply to a successful key rotation.
+type RotateKeyResponse struct {
+	Token              string    `json:"token"`
+	PreviousValidUntil time.Time `json:"previous_valid_until"`
+}
+
+func (a *rotateKeyAPI) register(cm *channels.Manager) {
+	cm.HandleHTTP(RotateKeyAPIPath, a)
+}
+
+func (a *rotateKeyAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !a.guard.Authorize(w, r, a.token) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use POST")
+		return
+	}
+
+	grace := DefaultKeyRotationGrace
+	var req rotateKeyRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<10)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.GraceSeconds != nil {
+		grace = time.Duration(*req.GraceSeconds) * time.Second
+		if grace < 0 || grace > maxKeyRotationGrace {
+			writeAPIError(w, http.StatusBadRequest, "grace_seconds must be between 0 and 3600")
+			return
+		}
+	}
+
+	next, err := a.rotate()
+	if err != nil {
+		logger.ErrorCF("gateway", "Gateway key rotation failed", map[string]any{"error": err.Error()})
+		writeAPIError(w, http.StatusInternalServerError, "could not rotate the gateway key")
+		return
+	}
+	a.guard.RotateToken(a.token, next, grace)
+	logger.InfoCF("gateway", "Rotated gateway key", map[string]any{
+		"remote_ip": health.ClientIP(r),
+		"grace":     grace.String(),
+	})
+	writeAPIJSON(w, http.StatusOK, RotateKeyResponse{
+		Token:              next,
+		PreviousValidUntil: time.Now().Add(grace).UTC(),
+	})
+}
@@ -0,0 +1,74 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/health"
+)
+
+func TestRotateKeyAPI(t *testing.T) {
+	guard := health.NewAuthGuard(0, time.Minute, time.Minute)
+	tokens := []string{"second", "third"}
+	api := &rotateKeyAPI{
+		rotate: func() (string, error) {
+			if len(tokens) == 0 {
+				return "", errors.New("pid file gone")
+			}
+			next := tokens[0]
+			tokens = tokens[1:]
+			return next, nil
+		},
+		token: "first",
+		guard: guard,
+	}
+
+	serve := func(method, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, RotateKeyAPIPath, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(http.MethodPost, "", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("no token: status = %d, want 401", rec.Code)
+	}
+	if rec := serve(http.MethodGet, "first", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET: status = %d, want 405", rec.Code)
+	}
+	if rec := serve(http.MethodPost, "first", `{"grace_seconds": 7200}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("long grace: status = %d, want 400", rec.Code)
+	}
+
+	rec := serve(http.MethodPost, "first", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rotate: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var got RotateKeyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Token != "second" || time.Until(got.PreviousValidUntil) <= 0 {
+		t.Fatalf("response = %+v", got)
+	}
+
+	// The old token still works during the grace period; rotating again
+	// with no grace retires the second token at once.
+	if rec := serve(http.MethodPost, "first", `{"grace_seconds": 0}`); rec.Code != http.StatusOK {
+		t.Fatalf("old token during grace: status = %d", rec.Code)
+	}
+	if rec := serve(http.MethodPost, "second", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("token retired without grace: status = %d, want 401", rec.Code)
+	}
+	if rec := serve(http.MethodPost, "third", ""); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("failed rotation: status = %d, want 500", rec.Code)
+	}
+}
@@ -0,0 +1,201 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/health"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	sessionsAPIPath         = "/api/sessions"
+	sessionsDefaultPageSize = 100
+	sessionsMaxPageSize     = 1000
+)
+
+// sessionBackend is the part of the agent loop the sessions API needs.
+type sessionBackend interface {
+	ListSessions() []agent.SessionInfo
+	SessionHistory(key string) (agent.SessionInfo, []providers.Message, string, error)
+	ClearSession(ctx context.Context, key string) error
+}
+
+// sessionsAPI serves /api/sessions on the gateway's shared HTTP server:
+//
+//	GET    /api/sessions        list sessions (paginated)
+//	GET    /api/sessions/{key}  one session with its history (paginated)
+//	DELETE /api/sessions/{key}  clear a session
+//
+// Every request needs the gateway token as a bearer token; without a token
+// nothing is served. Keys are path escaped, since they usually contain ":".
+type sessionsAPI struct {
+	backend sessionBackend
+	token   string
+	guard   *health.AuthGuard
+}
+
+type sessionListResponse struct {
+	Sessions   []agent.SessionInfo `json:"sessions"`
+	Total      int                 `json:"total"`
+	Offset     int                 `json:"offset"`
+	Limit      int                 `json:"limit"`
+	NextOffset *int                `json:"next_offset,omitempty"`
+}
+
+type sessionDetailResponse struct {
+	agent.SessionInfo
+	Summary    string              `json:"summary,omitempty"`
+	Messages   []providers.Message `json:"messages"`
+	Total      int                 `json:"total"`
+	Offset     int                 `json:"offset"`
+	Limit      int                 `json:"limit"`
+	NextOffset *int                `json:"next_offset,omitempty"`
+}
+
+func (a *sessionsAPI) register(cm *channels.Manager) {
+	cm.HandleHTTP(sessionsAPIPath, a)
+	cm.HandleHTTP(sessionsAPIPath+"/", a)
+}
+
+func (a *sessionsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !a.guard.Authorize(w, r, a.token) {
+		return
+	}
+
+	rawKey := strings.TrimPrefix(strings.TrimPrefix(r.URL.EscapedPath(), sessionsAPIPath), "/")
+	if rawKey == "" {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use GET")
+			return
+		}
+		a.list(w, r)
+		return
+	}
+	key, err := url.PathUnescape(rawKey)
+	if err != nil || strings.TrimSpace(key) == "" {
+		writeAPIError(w, http.StatusBadRequest, "invalid session key")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		a.get(w, r, key)
+	case http.MethodDelete:
+		a.clear(w, r, key)
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use GET or DELETE")
+	}
+}
+
+func (a *sessionsAPI) list(w http.ResponseWriter, r *http.Request) {
+	offset, limit, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+	sessions := a.backend.ListSessions()
+	page, next := paginate(sessions, offset, limit)
+	writeAPIJSON(w, http.StatusOK, sessionListResponse{
+		Sessions:   page,
+		Total:      len(sessions),
+		Offset:     offset,
+		Limit:      limit,
+		NextOffset: next,
+	})
+}
+
+func (a *sessionsAPI) get(w http.ResponseWriter, r *http.Request, key string) {
+	offset, limit, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+	info, history, summary, err := a.backend.SessionHistory(key)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	page, next := paginate(history, offset, limit)
+	writeAPIJSON(w, http.StatusOK, sessionDetailResponse{
+		SessionInfo: info,
+		Summary:     summary,
+		Messages:    page,
+		Total:       len(history),
+		Offset:      offset,
+		Limit:       limit,
+		NextOffset:  next,
+	})
+}
+
+func (a *sessionsAPI) clear(w http.ResponseWriter, r *http.Request, key string) {
+	if err := a.backend.ClearSession(r.Context(), key); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]string{"status": "cleared", "key": key})
+}
+
+// parsePage reads the offset and limit query parameters, writing a 400 and
+// returning false when either is malformed.
+func parsePage(w http.ResponseWriter, r *http.Request) (offset, limit int, ok bool) {
+	limit = sessionsDefaultPageSize
+	query := r.URL.Query()
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeAPIError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return 0, 0, false
+		}
+		offset = n
+	}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > sessionsMaxPageSize {
+			writeAPIError(w, http.StatusBadRequest,
+				"limit must be between 1 and "+strconv.Itoa(sessionsMaxPageSize))
+			return 0, 0, false
+		}
+		limit = n
+	}
+	return offset, limit, true
+}
+
+// paginate returns items[offset:offset+limit] and the offset of the next
+// page, or nil when this is the last one.
+func paginate[T any](items []T, offset, limit int) ([]T, *int) {
+	if offset >= len(items) {
+		return []T{}, nil
+	}
+	end := min(offset+limit, len(items))
+	if end == len(items) {
+		return items[offset:end], nil
+	}
+	return items[offset:end], &end
+}
+
+func writeSessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, agent.ErrSessionNotFound):
+		writeAPIError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, agent.ErrSessionBusy):
+		writeAPIError(w, http.StatusConflict, err.Error())
+	default:
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, msg string) {
+	writeAPIJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,164 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type fakeSessionBackend struct {
+	sessions []agent.SessionInfo
+	history  map[string][]providers.Message
+	busy     map[string]bool
+	cleared  []string
+}
+
+func (f *fakeSessionBackend) ListSessions() []agent.SessionInfo { return f.sessions }
+
+func (f *fakeSessionBackend) SessionHistory(key string) (agent.SessionInfo, []providers.Message, string, error) {
+	for _, s := range f.sessions {
+		if s.Key == key {
+			return s, f.history[key], "earlier talk", nil
+		}
+	}
+	return agent.SessionInfo{}, nil, "", agent.ErrSessionNotFound
+}
+
+func (f *fakeSessionBackend) ClearSession(_ context.Context, key string) error {
+	if _, _, _, err := f.SessionHistory(key); err != nil {
+		return err
+	}
+	if f.busy[key] {
+		return agent.ErrSessionBusy
+	}
+	f.cleared = append(f.cleared, key)
+	return nil
+}
+
+func newTestSessionsAPI() (*sessionsAPI, *fakeSessionBackend) {
+	backend := &fakeSessionBackend{
+		sessions: []agent.SessionInfo{
+			{Key: "agent:main:telegram:1", AgentID: "main", MessageCount: 3},
+			{Key: "agent:main:telegram:2", AgentID: "main", MessageCount: 0},
+			{Key: "agent:main:telegram:3", AgentID: "main", MessageCount: 1, Active: true},
+		},
+		history: map[string][]providers.Message{
+			"agent:main:telegram:1": {
+				{Role: "user", Content: "hi"},
+				{Role: "assistant", Content: "hello"},
+				{Role: "user", Content: "bye"},
+			},
+		},
+		busy: map[string]bool{"agent:main:telegram:3": true},
+	}
+	return &sessionsAPI{backend: backend, token: "secret"}, backend
+}
+
+func doSessionsRequest(t *testing.T, api *sessionsAPI, method, target, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSessionsAPI_RequiresToken(t *testing.T) {
+	api, _ := newTestSessionsAPI()
+	for _, token := range []string{"", "wrong"} {
+		if rec := doSessionsRequest(t, api, http.MethodGet, "/api/sessions", token); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("token %q: status = %d, want 401", token, rec.Code)
+		}
+	}
+
+	api.token = ""
+	if rec := doSessionsRequest(t, api, http.MethodGet, "/api/sessions", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("empty configured token: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestSessionsAPI_ListPaginates(t *testing.T) {
+	api, _ := newTestSessionsAPI()
+
+	rec := doSessionsRequest(t, api, http.MethodGet, "/api/sessions?limit=2", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var first sessionListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &first); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if first.Total != 3 || len(first.Sessions) != 2 || first.NextOffset == nil || *first.NextOffset != 2 {
+		t.Fatalf("first page = %+v", first)
+	}
+
+	rec = doSessionsRequest(t, api, http.MethodGet, "/api/sessions?limit=2&offset=2", "secret")
+	var second sessionListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &second); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(second.Sessions) != 1 || second.Sessions[0].Key != "agent:main:telegram:3" || second.NextOffset != nil {
+		t.Fatalf("second page = %+v", second)
+	}
+
+	for _, target := range []string{"/api/sessions?limit=0", "/api/sessions?limit=5000", "/api/sessions?offset=-1"} {
+		if rec := doSessionsRequest(t, api, http.MethodGet, target, "secret"); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", target, rec.Code)
+		}
+	}
+	if rec := doSessionsRequest(t, api, http.MethodPost, "/api/sessions", "secret"); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST list: status = %d, want 405", rec.Code)
+	}
+}
+
+func TestSessionsAPI_GetSession(t *testing.T) {
+	api, _ := newTestSessionsAPI()
+
+	rec := doSessionsRequest(t, api, http.MethodGet, "/api/sessions/agent%3Amain%3Atelegram%3A1?offset=1", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var got sessionDetailResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Key != "agent:main:telegram:1" || got.Summary != "earlier talk" || got.Total != 3 {
+		t.Fatalf("detail = %+v", got)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Content != "hello" {
+		t.Fatalf("messages = %+v, want the last two", got.Messages)
+	}
+
+	if rec := doSessionsRequest(t, api, http.MethodGet, "/api/sessions/missing", "secret"); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestSessionsAPI_DeleteSession(t *testing.T) {
+	api, backend := newTestSessionsAPI()
+
+	rec := doSessionsRequest(t, api, http.MethodDelete, "/api/sessions/agent:main:telegram:1", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if len(backend.cleared) != 1 || backend.cleared[0] != "agent:main:telegram:1" {
+		t.Fatalf("cleared = %v", backend.cleared)
+	}
+
+	if rec := doSessionsRequest(t, api, http.MethodDelete, "/api/sessions/agent:main:telegram:3", "secret"); rec.Code != http.StatusConflict {
+		t.Fatalf("busy: status = %d, want 409", rec.Code)
+	}
+	if rec := doSessionsRequest(t, api, http.MethodDelete, "/api/sessions/missing", "secret"); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing: status = %d, want 404", rec.Code)
+	}
+	if rec := doSessionsRequest(t, api, http.MethodPut, "/api/sessions/agent:main:telegram:1", "secret"); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("PUT: status = %d, want 405", rec.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/health"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+const toolStatsAPIPath = "/api/tools/stats"
+
+// toolStatsBackend is the part of the agent loop the tool stats API needs.
+type toolStatsBackend interface {
+	ToolStats() map[string]tools.ToolStats
+}
+
+// toolStatsAPI serves GET /api/tools/stats: how often each tool has run
+// since the gateway started and how often it failed. Like the sessions API,
+// it needs the gateway token as a bearer token.
+type toolStatsAPI struct {
+	backend toolStatsBackend
+	token   string
+	guard   *health.AuthGuard
+}
+
+type toolStatsEntry struct {
+	Name string `json:"name"`
+	tools.ToolStats
+	AvgDurationMs int64   `json:"avg_duration_ms"`
+	FailureRate   float64 `json:"failure_rate"`
+}
+
+type toolStatsResponse struct {
+	Tools []toolStatsEntry `json:"tools"`
+}
+
+func (a *toolStatsAPI) register(cm *channels.Manager) {
+	cm.HandleHTTP(toolStatsAPIPath, a)
+}
+
+func (a *toolStatsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !a.guard.Authorize(w, r, a.token) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use GET")
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, toolStatsResponse{Tools: sortedToolStats(a.backend.ToolStats())})
+}
+
+// sortedToolStats orders tools by invocation count, most used first, with
+// ties broken by name.
+func sortedToolStats(stats map[string]tools.ToolStats) []toolStatsEntry {
+	entries := make([]toolStatsEntry, 0, len(stats))
+	for name, s := range stats {
+		entry := toolStatsEntry{Name: name, ToolStats: s}
+		if s.Invocations > 0 {
+			entry.AvgDurationMs = s.TotalDurationMs / s.Invocations
+			entry.FailureRate = float64(s.Failures) / float64(s.Invocations)
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Invocations != entries[j].Invocations {
+			return entries[i].Invocations > entries[j].Invocations
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+type fakeToolStatsBackend map[string]tools.ToolStats
+
+func (f fakeToolStatsBackend) ToolStats() map[string]tools.ToolStats { return f }
+
+func TestToolStatsAPI(t *testing.T) {
+	api := &toolStatsAPI{
+		backend: fakeToolStatsBackend{
+			"exec":       {},
+			"web_search": {Invocations: 10, Successes: 6, Failures: 4, TotalDurationMs: 5000},
+			"read_file":  {Invocations: 10, Successes: 10, TotalDurationMs: 100},
+		},
+		token: "secret",
+	}
+
+	serve := func(method, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, toolStatsAPIPath, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(http.MethodGet, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("no token: status = %d, want 401", rec.Code)
+	}
+	if rec := serve(http.MethodPost, "secret"); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST: status = %d, want 405", rec.Code)
+	}
+
+	rec := serve(http.MethodGet, "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var got toolStatsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got.Tools) != 3 {
+		t.Fatalf("tools = %+v", got.Tools)
+	}
+	names := []string{got.Tools[0].Name, got.Tools[1].Name, got.Tools[2].Name}
+	if names[0] != "read_file" || names[1] != "web_search" || names[2] != "exec" {
+		t.Fatalf("order = %v, want most used first, then by name", names)
+	}
+	search := got.Tools[1]
+	if search.FailureRate != 0.4 || search.AvgDurationMs != 500 || search.Failures != 4 {
+		t.Fatalf("web_search = %+v", search)
+	}
+	if unused := got.Tools[2]; unused.Invocations != 0 || unused.FailureRate != 0 {
+		t.Fatalf("exec = %+v", unused)
+	}
+}
@@ -0,0 +1,205 @@
+package gateway
+
+import (
+	"embed"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/health"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	configAPIPath = "/api/config"
+	uiChatAPIPath = "/api/ui/chat"
+	picoChatPath  = "/pico/ws"
+
+	maxConfigBodyBytes = 1 << 20
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+// uiCSP keeps the page to its own scripts and lets it open the Pico
+// WebSocket on the same host.
+const uiCSP = "default-src 'self'; connect-src 'self' ws: wss:; img-src 'self' data:; " +
+	"frame-ancestors 'none'; base-uri 'none'; form-action 'none'"
+
+// registerUI mounts the built-in web UI at "/" together with the endpoints
+// only it uses:
+//
+//	GET /api/config   current config (secrets masked)
+//	PUT /api/config   validate and save a full config
+//	GET /api/ui/chat  how to reach the Pico chat WebSocket
+//
+// The page is static and carries no data; the endpoints need the gateway
+// token as a bearer token, which the page asks for.
+func registerUI(cm *channels.Manager, configPath, token string, guard *health.AuthGuard) {
+	static, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		logger.ErrorCF("gateway", "Built-in web UI unavailable", map[string]any{"error": err.Error()})
+		return
+	}
+	cm.HandleHTTP("/", uiStaticHandler(http.FileServer(http.FS(static))))
+	cm.HandleHTTP(configAPIPath, &configAPI{configPath: configPath, token: token, guard: guard})
+	cm.HandleHTTP(uiChatAPIPath, &uiChatAPI{configPath: configPath, token: token, guard: guard})
+}
+
+func uiStaticHandler(files http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Security-Policy", uiCSP)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		w.Header().Set("Cache-Control", "no-cache")
+		files.ServeHTTP(w, r)
+	})
+}
+
+// configAPI reads and replaces the gateway's config file. Secrets stay in
+// .security.yml: they are masked on GET and kept as they are on PUT. A saved
+// config takes effect through hot reload or POST /reload.
+type configAPI struct {
+	configPath string
+	token      string
+	guard      *health.AuthGuard
+}
+
+type configSaveResponse struct {
+	Status   string   `json:"status"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type configInvalidResponse struct {
+	Error  string   `json:"error"`
+	Errors []string `json:"errors"`
+}
+
+func (a *configAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !a.guard.Authorize(w, r, a.token) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		cfg, err := config.LoadConfig(a.configPath)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "failed to load config: "+err.Error())
+			return
+		}
+		writeAPIJSON(w, http.StatusOK, cfg)
+	case http.MethodPut:
+		a.put(w, r)
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use GET or PUT")
+	}
+}
+
+func (a *configAPI) put(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxConfigBodyBytes+1))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+	if len(body) > maxConfigBodyBytes {
+		writeAPIError(w, http.StatusRequestEntityTooLarge, "config is too large")
+		return
+	}
+
+	var cfg config.Config
+	if err = json.Unmarshal(body, &cfg); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	cfg.Session.ApplyDmScope()
+	cfg.Session.DeriveDmScope()
+	if err = cfg.SecurityCopyFrom(a.configPath); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to apply security config: "+err.Error())
+		return
+	}
+
+	hard, warnings := config.SplitValidationErrors(cfg.Validate())
+	if err = cfg.ValidateModelList(); err != nil {
+		hard = append(hard, err)
+	}
+	if len(hard) > 0 {
+		writeAPIJSON(w, http.StatusBadRequest, configInvalidResponse{
+			Error:  "invalid config",
+			Errors: errorStrings(hard),
+		})
+		return
+	}
+
+	if err = config.SaveConfig(a.configPath, &cfg); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to save config: "+err.Error())
+		return
+	}
+	logger.InfoCF("gateway", "Config saved from web UI", map[string]any{"remote_ip": health.ClientIP(r)})
+	writeAPIJSON(w, http.StatusOK, configSaveResponse{Status: "saved", Warnings: errorStrings(warnings)})
+}
+
+func errorStrings(errs []error) []string {
+	out := make([]string, 0, len(errs))
+	for _, err := range errs {
+		out = append(out, err.Error())
+	}
+	return out
+}
+
+// uiChatAPI tells the UI whether it can chat through the Pico channel and
+// hands it the channel token. Anyone holding the gateway token can already
+// read and change that token through the config API.
+type uiChatAPI struct {
+	configPath string
+	token      string
+	guard      *health.AuthGuard
+}
+
+type uiChatResponse struct {
+	Available bool   `json:"available"`
+	Path      string `json:"path,omitempty"`
+	Token     string `json:"token,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+func (a *uiChatAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !a.guard.Authorize(w, r, a.token) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use GET")
+		return
+	}
+	cfg, err := config.LoadConfig(a.configPath)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to load config: "+err.Error())
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, picoChatInfo(cfg))
+}
+
+func picoChatInfo(cfg *config.Config) uiChatResponse {
+	bc := cfg.Channels.GetByType(config.ChannelPico)
+	if bc == nil || !bc.Enabled {
+		return uiChatResponse{Reason: "enable the pico channel to chat from the web UI"}
+	}
+	decoded, err := bc.GetDecoded()
+	pico, ok := decoded.(*config.PicoSettings)
+	if err != nil || !ok {
+		return uiChatResponse{Reason: "invalid pico channel settings"}
+	}
+	if pico.Secret.String() != "" && !pico.LegacyTokenAuth {
+		return uiChatResponse{Reason: "the pico channel only accepts HMAC handshakes; " +
+			"set legacy_token_auth to chat from the web UI"}
+	}
+	if pico.Token.String() == "" {
+		return uiChatResponse{Reason: "set a pico channel token to chat from the web UI"}
+	}
+	return uiChatResponse{Available: true, Path: picoChatPath, Token: pico.Token.String()}
+}
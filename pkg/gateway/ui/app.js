@@ -0,0 +1,227 @@
+// Built-in PicoClaw web UI: chat over the Pico WebSocket channel and a raw
+// JSON config editor. No build step and no dependencies.
+(function () {
+  "use strict";
+
+  var TOKEN_KEY = "picoclaw.gatewayToken";
+  var SESSION_KEY = "picoclaw.chatSession";
+
+  var $ = function (id) { return document.getElementById(id); };
+  var token = sessionStorage.getItem(TOKEN_KEY) || "";
+  var socket = null;
+  var bubbles = {};
+
+  function api(method, path, body) {
+    var opts = { method: method, headers: { Authorization: "Bearer " + token } };
+    if (body !== undefined) {
+      opts.headers["Content-Type"] = "application/json";
+      opts.body = body;
+    }
+    return fetch(path, opts).then(function (resp) {
+      return resp.json().catch(function () { return {}; }).then(function (data) {
+        if (resp.status === 401) {
+          signOut("The gateway token was rejected.");
+        }
+        if (!resp.ok) {
+          var err = new Error(data.error || resp.statusText);
+          err.details = data.errors || [];
+          throw err;
+        }
+        return data;
+      });
+    });
+  }
+
+  // --- sign in ---
+
+  function showPanel(name) {
+    ["login", "chat", "config"].forEach(function (id) { $(id).hidden = id !== name; });
+    $("tab-chat").classList.toggle("active", name === "chat");
+    $("tab-config").classList.toggle("active", name === "config");
+    $("logout").hidden = name === "login";
+  }
+
+  function signIn(value) {
+    token = value;
+    return api("GET", "/api/ui/chat").then(function (info) {
+      sessionStorage.setItem(TOKEN_KEY, token);
+      $("login-error").textContent = "";
+      showPanel("chat");
+      connectChat(info);
+    });
+  }
+
+  function signOut(message) {
+    token = "";
+    sessionStorage.removeItem(TOKEN_KEY);
+    if (socket) {
+      socket.close();
+      socket = null;
+    }
+    $("login-error").textContent = message || "";
+    showPanel("login");
+  }
+
+  $("login-form").addEventListener("submit", function (ev) {
+    ev.preventDefault();
+    signIn($("token").value.trim()).catch(function (err) {
+      $("login-error").textContent = err.message;
+    });
+  });
+  $("logout").addEventListener("click", function () { signOut(); });
+  $("tab-chat").addEventListener("click", function () { showPanel("chat"); });
+  $("tab-config").addEventListener("click", function () {
+    showPanel("config");
+    if (!$("config-editor").value) {
+      loadConfig();
+    }
+  });
+
+  // --- chat ---
+
+  function chatStatus(text, isError) {
+    $("chat-status").textContent = text;
+    $("chat-status").classList.toggle("error", !!isError);
+  }
+
+  function sessionID() {
+    var id = sessionStorage.getItem(SESSION_KEY);
+    if (!id) {
+      id = "webui-" + Date.now().toString(36) + Math.random().toString(36).slice(2, 8);
+      sessionStorage.setItem(SESSION_KEY, id);
+    }
+    return id;
+  }
+
+  function addBubble(kind, text, id) {
+    var li = id && bubbles[id];
+    if (!li) {
+      li = document.createElement("li");
+      $("messages").appendChild(li);
+      if (id) {
+        bubbles[id] = li;
+      }
+    }
+    li.className = kind;
+    li.textContent = text;
+    $("messages").scrollTop = $("messages").scrollHeight;
+  }
+
+  function connectChat(info) {
+    if (!info.available) {
+      chatStatus(info.reason, true);
+      $("chat-form").querySelector("button").disabled = true;
+      return;
+    }
+    var scheme = location.protocol === "https:" ? "wss:" : "ws:";
+    var url = scheme + "//" + location.host + info.path + "?session_id=" + encodeURIComponent(sessionID());
+    socket = new WebSocket(url, ["token." + info.token]);
+    chatStatus("Connecting…");
+    socket.onopen = function () { chatStatus("Connected"); };
+    socket.onclose = function () {
+      if (socket) {
+        chatStatus("Disconnected. Sign in again to reconnect.", true);
+        socket = null;
+      }
+    };
+    socket.onmessage = function (ev) {
+      var msg;
+      try {
+        msg = JSON.parse(ev.data);
+      } catch (e) {
+        return;
+      }
+      var p = msg.payload || {};
+      switch (msg.type) {
+      case "message.create":
+      case "message.update":
+        addBubble(p.kind === "thought" ? "thought" : "assistant", p.content || "", p.message_id);
+        break;
+      case "message.delete":
+        if (bubbles[p.message_id]) {
+          bubbles[p.message_id].remove();
+          delete bubbles[p.message_id];
+        }
+        break;
+      case "typing.start":
+        chatStatus("Thinking…");
+        break;
+      case "typing.stop":
+        chatStatus("Connected");
+        break;
+      case "error":
+        addBubble("error", p.message || p.error || "error");
+        break;
+      }
+    };
+  }
+
+  function sendChat() {
+    var text = $("chat-input").value.trim();
+    if (!text || !socket || socket.readyState !== WebSocket.OPEN) {
+      return;
+    }
+    socket.send(JSON.stringify({ type: "message.send", payload: { content: text } }));
+    addBubble("user", text);
+    $("chat-input").value = "";
+  }
+
+  $("chat-form").addEventListener("submit", function (ev) {
+    ev.preventDefault();
+    sendChat();
+  });
+  $("chat-input").addEventListener("keydown", function (ev) {
+    if (ev.key === "Enter" && !ev.shiftKey && !ev.isComposing) {
+      ev.preventDefault();
+      sendChat();
+    }
+  });
+
+  // --- config ---
+
+  function configStatus(text, isError) {
+    $("config-status").textContent = text;
+    $("config-status").classList.toggle("error", !!isError);
+  }
+
+  function loadConfig() {
+    configStatus("Loading…");
+    return api("GET", "/api/config").then(function (cfg) {
+      $("config-editor").value = JSON.stringify(cfg, null, 2);
+      configStatus("Loaded.");
+    }).catch(function (err) { configStatus(err.message, true); });
+  }
+
+  function saveConfig(apply) {
+    var text = $("config-editor").value;
+    try {
+      JSON.parse(text);
+    } catch (e) {
+      configStatus("Invalid JSON: " + e.message, true);
+      return;
+    }
+    configStatus("Saving…");
+    api("PUT", "/api/config", text).then(function (res) {
+      var notes = (res.warnings || []).map(function (w) { return "warning: " + w; });
+      if (!apply) {
+        configStatus(["Saved."].concat(notes).join("\n"));
+        return;
+      }
+      return api("POST", "/reload").then(function () {
+        configStatus(["Saved; reload started."].concat(notes).join("\n"));
+      });
+    }).catch(function (err) {
+      configStatus([err.message].concat(err.details || []).join("\n"), true);
+    });
+  }
+
+  $("config-load").addEventListener("click", loadConfig);
+  $("config-save").addEventListener("click", function () { saveConfig(false); });
+  $("config-apply").addEventListener("click", function () { saveConfig(true); });
+
+  if (token) {
+    signIn(token).catch(function (err) { signOut(err.message); });
+  } else {
+    showPanel("login");
+  }
+})();
@@ -0,0 +1,167 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestUIStaticHandler(t *testing.T) {
+	static, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		t.Fatalf("fs.Sub() error = %v", err)
+	}
+	handler := uiStaticHandler(http.FileServer(http.FS(static)))
+
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status = %d, want 200", path, rec.Code)
+		}
+		if rec.Header().Get("Content-Security-Policy") == "" {
+			t.Errorf("GET %s: missing Content-Security-Policy", path)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.Contains(rec.Body.String(), `<script src="app.js">`) {
+		t.Errorf("index.html does not load app.js:\n%s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /missing: status = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /: status = %d, want 405", rec.Code)
+	}
+}
+
+func TestConfigAPI(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = filepath.Join(t.TempDir(), "workspace")
+	cfg.Gateway.EventWebhooks = config.WebhookConfigs{
+		{URL: "https://hooks.example.com/picoclaw", Secret: *config.NewSecureString("webhook-signing-key")},
+	}
+	if err := config.SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+	api := &configAPI{configPath: configPath, token: "secret"}
+
+	serve := func(method, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, configAPIPath, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(http.MethodGet, "", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("no token: status = %d, want 401", rec.Code)
+	}
+	if rec := serve(http.MethodDelete, "secret", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("DELETE: status = %d, want 405", rec.Code)
+	}
+
+	rec := serve(http.MethodGet, "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "webhook-signing-key") {
+		t.Fatalf("GET: webhook secret is not masked: %s", rec.Body.String())
+	}
+	var current map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &current); err != nil {
+		t.Fatalf("GET: invalid JSON: %v", err)
+	}
+	gateway, _ := current["gateway"].(map[string]any)
+	if gateway == nil {
+		t.Fatalf("GET: missing gateway section in %s", rec.Body.String())
+	}
+
+	if rec = serve(http.MethodPut, "secret", "{"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("PUT invalid JSON: status = %d, want 400", rec.Code)
+	}
+
+	gateway["port"] = 70000
+	invalid, _ := json.Marshal(current)
+	rec = serve(http.MethodPut, "secret", string(invalid))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "gateway.port") {
+		t.Fatalf("PUT invalid config: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	gateway["port"] = 18888
+	valid, _ := json.Marshal(current)
+	rec = serve(http.MethodPut, "secret", string(valid))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	saved, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if saved.Gateway.Port != 18888 {
+		t.Errorf("saved gateway.port = %d, want 18888", saved.Gateway.Port)
+	}
+	if hooks := saved.Gateway.EventWebhooks; len(hooks) != 1 || hooks[0].Secret.String() != "webhook-signing-key" {
+		t.Errorf("webhook secret lost by PUT: %+v", hooks)
+	}
+}
+
+func TestPicoChatInfo(t *testing.T) {
+	newConfig := func(enabled bool, token, secret string, legacy bool) *config.Config {
+		cfg := config.DefaultConfig()
+		bc := cfg.Channels.GetByType(config.ChannelPico)
+		bc.Enabled = enabled
+		decoded, err := bc.GetDecoded()
+		if err != nil {
+			t.Fatalf("GetDecoded() error = %v", err)
+		}
+		pico := decoded.(*config.PicoSettings)
+		pico.SetToken(token)
+		pico.Secret = *config.NewSecureString(secret)
+		pico.LegacyTokenAuth = legacy
+		return cfg
+	}
+
+	tests := []struct {
+		name      string
+		cfg       *config.Config
+		available bool
+	}{
+		{"disabled", newConfig(false, "tok", "", false), false},
+		{"no token", newConfig(true, "", "", false), false},
+		{"hmac only", newConfig(true, "tok", "hmac", false), false},
+		{"hmac with legacy token", newConfig(true, "tok", "hmac", true), true},
+		{"token", newConfig(true, "tok", "", false), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := picoChatInfo(tt.cfg)
+			if info.Available != tt.available {
+				t.Fatalf("Available = %v, want %v (reason %q)", info.Available, tt.available, info.Reason)
+			}
+			if tt.available && (info.Token != "tok" || info.Path != picoChatPath) {
+				t.Errorf("info = %+v, want token and path", info)
+			}
+			if !tt.available && info.Reason == "" {
+				t.Error("Reason should explain why chat is unavailable")
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package health
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// AuthGuard checks bearer tokens on the gateway's protected endpoints and
+// locks out remote IPs that fail too often, fail2ban style: maxFailures
+// failures within window block the IP for lockout. A nil guard, or one with
+// maxFailures <= 0, checks tokens without tracking failures.
+type AuthGuard struct {
+	maxFailures int
+	window      time.Duration
+	lockout     time.Duration
+	now         func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*authClient
+	lastPrune time.Time
+
+	// After RotateToken, rotated replaces the token callers pass to
+	// Authorize and retired stays valid until retiredUntil.
+	tokenMu      sync.RWMutex
+	rotated      string
+	retired      string
+	retiredUntil time.Time
+}
+
+type authClient struct {
+	failures    []time.Time
+	lockedUntil time.Time
+}
+
+func NewAuthGuard(maxFailures int, window, lockout time.Duration) *AuthGuard {
+	return &AuthGuard{
+		maxFailures: maxFailures,
+		window:      window,
+		lockout:     lockout,
+		now:         time.Now,
+		clients:     make(map[string]*authClient),
+	}
+}
+
+// Authorize checks the request's bearer token against token, writing a JSON
+// 429 (with Retry-After) for locked-out clients or 401 for a missing or
+// wrong token. It reports whether the request may proceed. An empty token
+// never authorizes.
+func (g *AuthGuard) Authorize(w http.ResponseWriter, r *http.Request, token string) bool {
+	ip := ClientIP(r)
+	if wait, locked := g.lockedOut(ip); locked {
+		w.Header().Set("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+		writeAuthError(w, http.StatusTooManyRequests, "too many failed authentication attempts, try again later")
+		return false
+	}
+
+	given := extractBearerToken(r.Header.Get("Authorization"))
+	if token == "" || given == "" || !g.tokenAccepted(given, token) {
+		logger.WarnCF("gateway", "Authentication failed", map[string]any{
+			"remote_ip": ip,
+			"method":    r.Method,
+			"path":      r.URL.Path,
+		})
+		g.recordFailure(ip)
+		writeAuthError(w, http.StatusUnauthorized, "unauthorized")
+		return false
+	}
+	g.recordSuccess(ip)
+	return true
+}
+
+// RotateToken makes next the only token Authorize accepts, in place of
+// configured (or the previous rotation). The token it replaces stays valid
+// for grace, so clients that still hold it can switch over.
+func (g *AuthGuard) RotateToken(configured, next string, grace time.Duration) {
+	g.tokenMu.Lock()
+	defer g.tokenMu.Unlock()
+	previous := configured
+	if g.rotated != "" {
+		previous = g.rotated
+	}
+	g.rotated = next
+	g.retired = previous
+	g.retiredUntil = g.now().Add(grace)
+}
+
+// tokenAccepted reports whether given matches the current token: configured
+// until the first rotation, then the rotated one or, during its grace period,
+// the one it replaced.
+func (g *AuthGuard) tokenAccepted(given, configured string) bool {
+	current, retired := configured, ""
+	if g != nil {
+		g.tokenMu.RLock()
+		if g.rotated != "" {
+			current = g.rotated
+			if g.now().Before(g.retiredUntil) {
+				retired = g.retired
+			}
+		}
+		g.tokenMu.RUnlock()
+	}
+	if subtle.ConstantTimeCompare([]byte(given), []byte(current)) == 1 {
+		return true
+	}
+	return retired != "" && subtle.ConstantTimeCompare([]byte(given), []byte(retired)) == 1
+}
+
+func (g *AuthGuard) enabled() bool {
+	return g != nil && g.maxFailures > 0
+}
+
+func (g *AuthGuard) lockedOut(ip string) (time.Duration, bool) {
+	if !g.enabled() {
+		return 0, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	client, ok := g.clients[ip]
+	if !ok {
+		return 0, false
+	}
+	if wait := client.lockedUntil.Sub(g.now()); wait > 0 {
+		return wait, true
+	}
+	return 0, false
+}
+
+func (g *AuthGuard) recordFailure(ip string) {
+	if !g.enabled() {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	g.pruneLocked(now)
+	client, ok := g.clients[ip]
+	if !ok {
+		client = &authClient{}
+		g.clients[ip] = client
+	}
+	client.failures = append(recentFailures(client.failures, now.Add(-g.window)), now)
+	if len(client.failures) >= g.maxFailures {
+		client.lockedUntil = now.Add(g.lockout)
+		client.failures = nil
+		logger.WarnCF("gateway", "Locking out client after repeated authentication failures", map[string]any{
+			"remote_ip": ip,
+			"failures":  g.maxFailures,
+			"window":    g.window.String(),
+			"lockout":   g.lockout.String(),
+		})
+	}
+}
+
+func (g *AuthGuard) recordSuccess(ip string) {
+	if !g.enabled() {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.clients, ip)
+}
+
+// pruneLocked forgets clients with no recent failures and no active lockout,
+// at most once per window, so a scan from many addresses cannot grow the map
+// without bound.
+func (g *AuthGuard) pruneLocked(now time.Time) {
+	if now.Sub(g.lastPrune) < g.window {
+		return
+	}
+	g.lastPrune = now
+	cutoff := now.Add(-g.window)
+	for ip, client := range g.clients {
+		client.failures = recentFailures(client.failures, cutoff)
+		if len(client.failures) == 0 && !client.lockedUntil.After(now) {
+			delete(g.clients, ip)
+		}
+	}
+}
+
+func recentFailures(failures []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(failures) && !failures[i].After(cutoff) {
+		i++
+	}
+	return failures[i:]
+}
+
+// ClientIP returns the host part of the request's remote address.
+// Forwarding headers are ignored: they are set by the client and would let
+// an attacker pick a fresh address for every attempt.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func writeAuthError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
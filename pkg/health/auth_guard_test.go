@@ -0,0 +1,159 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestAuthGuard(maxFailures int) (*AuthGuard, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	g := NewAuthGuard(maxFailures, time.Minute, 10*time.Minute)
+	g.now = clock.now
+	return g, clock
+}
+
+func authorize(g *AuthGuard, remoteAddr, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/reload", nil)
+	req.RemoteAddr = remoteAddr
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	g.Authorize(w, req, "secret")
+	return w
+}
+
+func TestAuthGuard_LockoutEngagesAndExpires(t *testing.T) {
+	g, clock := newTestAuthGuard(3)
+	const attacker = "203.0.113.7:5555"
+
+	for i := 0; i < 3; i++ {
+		if w := authorize(g, attacker, "guess"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: status = %d, want 401", i+1, w.Code)
+		}
+	}
+
+	// Locked out: even the right token is refused.
+	w := authorize(g, attacker, "secret")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("locked status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "600" {
+		t.Fatalf("Retry-After = %q, want 600", got)
+	}
+
+	// Other clients are unaffected.
+	if w := authorize(g, "198.51.100.1:4000", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("other client status = %d, want 200", w.Code)
+	}
+
+	clock.advance(9 * time.Minute)
+	if w = authorize(g, attacker, "secret"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status before expiry = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "60" {
+		t.Fatalf("Retry-After = %q, want 60", got)
+	}
+
+	clock.advance(time.Minute)
+	if w := authorize(g, attacker, "secret"); w.Code != http.StatusOK {
+		t.Fatalf("status after expiry = %d, want 200", w.Code)
+	}
+}
+
+func TestAuthGuard_FailuresOutsideWindowDoNotCount(t *testing.T) {
+	g, clock := newTestAuthGuard(3)
+	const client = "203.0.113.8:1234"
+
+	authorize(g, client, "guess")
+	authorize(g, client, "guess")
+	clock.advance(2 * time.Minute)
+	authorize(g, client, "")
+	if w := authorize(g, client, "secret"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 when old failures have aged out", w.Code)
+	}
+}
+
+func TestAuthGuard_SuccessResetsFailures(t *testing.T) {
+	g, _ := newTestAuthGuard(3)
+	const client = "203.0.113.9:1234"
+
+	authorize(g, client, "guess")
+	authorize(g, client, "guess")
+	authorize(g, client, "secret")
+	authorize(g, client, "guess")
+	authorize(g, client, "guess")
+	if w := authorize(g, client, "secret"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 after a success reset the count", w.Code)
+	}
+}
+
+func TestAuthGuard_DisabledAndNil(t *testing.T) {
+	disabled, _ := newTestAuthGuard(0)
+	var nilGuard *AuthGuard
+	for name, g := range map[string]*AuthGuard{"disabled": disabled, "nil": nilGuard} {
+		for i := 0; i < 20; i++ {
+			authorize(g, "203.0.113.10:1", "guess")
+		}
+		if w := authorize(g, "203.0.113.10:1", "secret"); w.Code != http.StatusOK {
+			t.Fatalf("%s guard: status = %d, want 200", name, w.Code)
+		}
+		if w := authorize(g, "203.0.113.10:1", ""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("%s guard: missing token status = %d, want 401", name, w.Code)
+		}
+	}
+}
+
+func TestAuthGuard_RotateTokenKeepsOldTokenDuringGrace(t *testing.T) {
+	g, clock := newTestAuthGuard(0)
+	const client = "192.0.2.10:4000"
+
+	g.RotateToken("secret", "fresh", time.Minute)
+	for _, token := range []string{"secret", "fresh"} {
+		if w := authorize(g, client, token); w.Code != http.StatusOK {
+			t.Fatalf("token %q during grace: status = %d, want 200", token, w.Code)
+		}
+	}
+
+	clock.advance(time.Minute)
+	if w := authorize(g, client, "secret"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("old token after grace: status = %d, want 401", w.Code)
+	}
+	if w := authorize(g, client, "fresh"); w.Code != http.StatusOK {
+		t.Fatalf("new token after grace: status = %d, want 200", w.Code)
+	}
+
+	g.RotateToken("secret", "newer", 0)
+	if w := authorize(g, client, "fresh"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("rotated token without grace: status = %d, want 401", w.Code)
+	}
+	if w := authorize(g, client, "newer"); w.Code != http.StatusOK {
+		t.Fatalf("latest token: status = %d, want 200", w.Code)
+	}
+}
+
+func TestReloadHandler_LocksOutAfterRepeatedFailures(t *testing.T) {
+	s := newTestServer()
+	s.SetReloadFunc(func() error { return nil })
+	s.SetAuthGuard(NewAuthGuard(2, time.Minute, time.Minute))
+
+	send := func(token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/reload", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		s.reloadHandler(w, req)
+		return w.Code
+	}
+	send("wrong")
+	send("wrong")
+	if code := send("test"); code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", code)
+	}
+}
@@ -2,7 +2,6 @@ package health
 
 import (
 	"context"
-	"crypto/subtle"
 	"encoding/json"
 	"maps"
 	"net"
@@ -18,9 +17,11 @@ type Server struct {
 	mu         sync.RWMutex
 	ready      bool
 	checks     map[string]Check
+	liveChecks map[string]func() (bool, string)
 	startTime  time.Time
 	reloadFunc func() error
-	authToken  string // optional bearer token for protected endpoints
+	authToken  string     // optional bearer token for protected endpoints
+	authGuard  *AuthGuard // optional lockout for clients that keep failing auth
 }
 
 type Check struct {
@@ -40,15 +41,14 @@ type StatusResponse struct {
 func NewServer(host string, port int, token string) *Server {
 	mux := http.NewServeMux()
 	s := &Server{
-		ready:     false,
-		checks:    make(map[string]Check),
-		startTime: time.Now(),
-		authToken: token,
+		ready:      false,
+		checks:     make(map[string]Check),
+		liveChecks: make(map[string]func() (bool, string)),
+		startTime:  time.Now(),
+		authToken:  token,
 	}
 
-	mux.HandleFunc("/health", s.healthHandler)
-	mux.HandleFunc("/ready", s.readyHandler)
-	mux.HandleFunc("/reload", s.reloadHandler)
+	s.RegisterOnMux(mux)
 
 	addr := net.JoinHostPort(host, strconv.Itoa(port))
 	s.server = &http.Server{
@@ -112,6 +112,47 @@ func (s *Server) RegisterCheck(name string, checkFn func() (bool, string)) {
 	}
 }
 
+// RegisterLiveCheck registers a check that is evaluated on every /health and
+// /ready request, for state that changes while the gateway runs. A failing
+// live check fails readiness just like a check registered with RegisterCheck.
+func (s *Server) RegisterLiveCheck(name string, checkFn func() (bool, string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.liveChecks == nil {
+		s.liveChecks = make(map[string]func() (bool, string))
+	}
+	s.liveChecks[name] = checkFn
+}
+
+// evaluateLiveChecks runs all live checks outside the lock so a slow check
+// cannot block check registration.
+func (s *Server) evaluateLiveChecks() map[string]Check {
+	s.mu.RLock()
+	fns := make(map[string]func() (bool, string), len(s.liveChecks))
+	maps.Copy(fns, s.liveChecks)
+	s.mu.RUnlock()
+
+	checks := make(map[string]Check, len(fns))
+	for name, fn := range fns {
+		ok, msg := fn()
+		checks[name] = Check{
+			Name:      name,
+			Status:    statusString(ok),
+			Message:   msg,
+			Timestamp: time.Now(),
+		}
+	}
+	return checks
+}
+
+// SetAuthGuard sets the guard that locks out clients after repeated
+// authentication failures on protected endpoints.
+func (s *Server) SetAuthGuard(g *AuthGuard) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authGuard = g
+}
+
 // SetReloadFunc sets the callback function for config reload.
 func (s *Server) SetReloadFunc(fn func() error) {
 	s.mu.Lock()
@@ -130,16 +171,11 @@ func (s *Server) reloadHandler(w http.ResponseWriter, r *http.Request) {
 	// Token check
 	s.mu.RLock()
 	requiredToken := s.authToken
+	guard := s.authGuard
 	s.mu.RUnlock()
 
-	if requiredToken != "" {
-		given := extractBearerToken(r.Header.Get("Authorization"))
-		if given == "" || subtle.ConstantTimeCompare([]byte(given), []byte(requiredToken)) != 1 {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
-			return
-		}
+	if requiredToken != "" && !guard.Authorize(w, r, requiredToken) {
+		return
 	}
 
 	s.mu.Lock()
@@ -175,10 +211,27 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 		Uptime: uptime.String(),
 		PID:    os.Getpid(),
 	}
+	if checks := s.evaluateLiveChecks(); len(checks) > 0 {
+		resp.Checks = checks
+	}
 
 	json.NewEncoder(w).Encode(resp)
 }
 
+// livezHandler is the liveness probe: it answers 200 whenever the process is
+// serving requests and runs no checks, so a slow or failing dependency never
+// gets the process restarted.
+func (s *Server) livezHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(StatusResponse{
+		Status: "ok",
+		Uptime: time.Since(s.startTime).String(),
+	})
+}
+
+// readyHandler is the readiness probe (/ready and /readyz): it answers 200
+// only once startup has finished and every check passes.
 func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -187,6 +240,7 @@ func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
 	checks := make(map[string]Check)
 	maps.Copy(checks, s.checks)
 	s.mu.RUnlock()
+	maps.Copy(checks, s.evaluateLiveChecks())
 
 	if !ready {
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -225,11 +279,14 @@ type HandlerMux interface {
 	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
 }
 
-// RegisterOnMux registers /health, /ready and /reload handlers onto the given mux.
-// This allows the health endpoints to be served by a shared HTTP server.
+// RegisterOnMux registers /health, /ready, /livez, /readyz and /reload
+// handlers onto the given mux. This allows the health endpoints to be served
+// by a shared HTTP server.
 func (s *Server) RegisterOnMux(mux HandlerMux) {
 	mux.HandleFunc("/health", s.healthHandler)
 	mux.HandleFunc("/ready", s.readyHandler)
+	mux.HandleFunc("/livez", s.livezHandler)
+	mux.HandleFunc("/readyz", s.readyHandler)
 	mux.HandleFunc("/reload", s.reloadHandler)
 }
 
@@ -266,6 +266,47 @@ func TestRegisterCheck_MultipleChecks(t *testing.T) {
 	}
 }
 
+func TestRegisterLiveCheck_EvaluatedPerRequest(t *testing.T) {
+	s := newTestServer()
+	s.SetReady(true)
+	healthy := true
+	s.RegisterLiveCheck("mcp", func() (bool, string) {
+		if healthy {
+			return true, "all servers healthy"
+		}
+		return false, "github: failed"
+	})
+
+	get := func(handler http.HandlerFunc, path string) (int, StatusResponse) {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var resp StatusResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode %s response: %v", path, err)
+		}
+		return w.Code, resp
+	}
+
+	code, resp := get(s.healthHandler, "/health")
+	if code != http.StatusOK || resp.Checks["mcp"].Status != "ok" {
+		t.Fatalf("/health = %d %+v, want ok mcp check", code, resp.Checks)
+	}
+
+	healthy = false
+	code, resp = get(s.healthHandler, "/health")
+	if code != http.StatusOK {
+		t.Errorf("/health status = %d, want %d even with a failing check", code, http.StatusOK)
+	}
+	if check := resp.Checks["mcp"]; check.Status != "fail" || check.Message != "github: failed" {
+		t.Errorf("/health mcp check = %+v, want fail with message", check)
+	}
+
+	code, resp = get(s.readyHandler, "/ready")
+	if code != http.StatusServiceUnavailable || resp.Status != "not ready" {
+		t.Errorf("/ready = %d %q, want %d not ready", code, resp.Status, http.StatusServiceUnavailable)
+	}
+}
+
 func TestRegisterOnMux(t *testing.T) {
 	s := newTestServer()
 	s.SetReady(true)
@@ -292,6 +333,40 @@ func TestRegisterOnMux(t *testing.T) {
 	}
 }
 
+func TestLivezAndReadyz(t *testing.T) {
+	s := newTestServer()
+	healthy := false
+	s.RegisterLiveCheck("provider", func() (bool, string) { return healthy, "" })
+
+	mux := http.NewServeMux()
+	s.RegisterOnMux(mux)
+	get := func(path string) int {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	if code := get("/livez"); code != http.StatusOK {
+		t.Errorf("/livez before ready = %d, want %d", code, http.StatusOK)
+	}
+	if code := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz before ready = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+
+	s.SetReady(true)
+	if code := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz with failing check = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	if code := get("/livez"); code != http.StatusOK {
+		t.Errorf("/livez with failing check = %d, want %d", code, http.StatusOK)
+	}
+
+	healthy = true
+	if code := get("/readyz"); code != http.StatusOK {
+		t.Errorf("/readyz with passing checks = %d, want %d", code, http.StatusOK)
+	}
+}
+
 func TestNewServer(t *testing.T) {
 	s := NewServer("127.0.0.1", 0, "")
 	if s == nil {
@@ -34,16 +34,28 @@ const (
 // channel and chatID are derived from the last active user channel.
 type HeartbeatHandler func(prompt, channel, chatID string) *tools.ToolResult
 
+// TargetFunc reports the channel and chat ID that receive heartbeat output.
+// Empty values mean there is nowhere to send it.
+type TargetFunc func() (channel, chatID string)
+
+// Recipient is one chat that receives heartbeat output.
+type Recipient struct {
+	Channel string
+	ChatID  string
+}
+
 // HeartbeatService manages periodic heartbeat checks
 type HeartbeatService struct {
-	workspace string
-	bus       *bus.MessageBus
-	state     *state.Manager
-	handler   HeartbeatHandler
-	interval  time.Duration
-	enabled   bool
-	mu        sync.RWMutex
-	stopChan  chan struct{}
+	workspace  string
+	bus        *bus.MessageBus
+	state      *state.Manager
+	handler    HeartbeatHandler
+	target     TargetFunc
+	recipients []Recipient
+	interval   time.Duration
+	enabled    bool
+	mu         sync.RWMutex
+	stopChan   chan struct{}
 }
 
 // NewHeartbeatService creates a new heartbeat service
@@ -79,6 +91,23 @@ func (hs *HeartbeatService) SetHandler(handler HeartbeatHandler) {
 	hs.handler = handler
 }
 
+// SetTargetFunc overrides how the heartbeat picks its channel and chat. By
+// default it uses the last channel recorded in the workspace state.
+func (hs *HeartbeatService) SetTargetFunc(fn TargetFunc) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.target = fn
+}
+
+// SetRecipients sends heartbeat output to every recipient instead of the
+// single target. The heartbeat turn runs in the context of the first one. An
+// empty list goes back to the single target.
+func (hs *HeartbeatService) SetRecipients(recipients []Recipient) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.recipients = append([]Recipient(nil), recipients...)
+}
+
 // Start begins the heartbeat service
 func (hs *HeartbeatService) Start() error {
 	hs.mu.Lock()
@@ -173,12 +202,12 @@ func (hs *HeartbeatService) executeHeartbeat() {
 		return
 	}
 
-	// Get last channel info for context
-	lastChannel := hs.state.GetLastChannel()
-	channel, chatID := hs.parseLastChannel(lastChannel)
-
-	// Debug log for channel resolution
-	hs.logInfof("Resolved channel: %s, chatID: %s (from lastChannel: %s)", channel, chatID, lastChannel)
+	targets := hs.resolveTargets()
+	var channel, chatID string
+	if len(targets) > 0 {
+		channel, chatID = targets[0].Channel, targets[0].ChatID
+	}
+	hs.logInfof("Resolved channel: %s, chatID: %s", channel, chatID)
 
 	result := handler(prompt, channel, chatID)
 
@@ -210,9 +239,9 @@ func (hs *HeartbeatService) executeHeartbeat() {
 
 	// Send result to user
 	if result.ForUser != "" {
-		hs.sendResponse(result.ForUser)
+		hs.sendResponse(result.ForUser, targets)
 	} else if result.ForLLM != "" {
-		hs.sendResponse(result.ForLLM)
+		hs.sendResponse(result.ForLLM, targets)
 	}
 
 	hs.logInfof("Heartbeat completed: %s", result.ForLLM)
@@ -311,8 +340,36 @@ func heartbeatHasUserTasks(content string) bool {
 	return false
 }
 
-// sendResponse sends the heartbeat response to the last channel
-func (hs *HeartbeatService) sendResponse(response string) {
+// resolveTarget returns the channel and chat ID for heartbeat output.
+func (hs *HeartbeatService) resolveTarget() (channel, chatID string) {
+	hs.mu.RLock()
+	target := hs.target
+	hs.mu.RUnlock()
+	if target != nil {
+		return target()
+	}
+	return hs.parseLastChannel(hs.state.GetLastChannel())
+}
+
+// resolveTargets returns the chats that receive heartbeat output: the
+// configured recipients, or else the single target if one is known.
+func (hs *HeartbeatService) resolveTargets() []Recipient {
+	hs.mu.RLock()
+	recipients := append([]Recipient(nil), hs.recipients...)
+	hs.mu.RUnlock()
+	if len(recipients) > 0 {
+		return recipients
+	}
+	channel, chatID := hs.resolveTarget()
+	if channel == "" || chatID == "" {
+		return nil
+	}
+	return []Recipient{{Channel: channel, ChatID: chatID}}
+}
+
+// sendResponse sends the heartbeat response to each target. A failed
+// recipient is logged and does not keep the others from getting it.
+func (hs *HeartbeatService) sendResponse(response string, targets []Recipient) {
 	hs.mu.RLock()
 	msgBus := hs.bus
 	hs.mu.RUnlock()
@@ -322,28 +379,25 @@ func (hs *HeartbeatService) sendResponse(response string) {
 		return
 	}
 
-	// Get last channel from state
-	lastChannel := hs.state.GetLastChannel()
-	if lastChannel == "" {
-		hs.logInfof("No last channel recorded, heartbeat result not sent")
+	if len(targets) == 0 {
+		hs.logInfof("No target channel known, heartbeat result not sent")
 		return
 	}
 
-	platform, userID := hs.parseLastChannel(lastChannel)
-
-	// Skip internal channels that can't receive messages
-	if platform == "" || userID == "" {
-		return
+	for _, target := range targets {
+		pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
+			Context:   bus.NewOutboundContext(target.Channel, target.ChatID, ""),
+			Content:   response,
+			Proactive: true,
+		})
+		pubCancel()
+		if err != nil {
+			hs.logErrorf("Failed to send heartbeat result to %s:%s: %v", target.Channel, target.ChatID, err)
+			continue
+		}
+		hs.logInfof("Heartbeat result sent to %s", target.Channel)
 	}
-
-	pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer pubCancel()
-	msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
-		Context: bus.NewOutboundContext(platform, userID, ""),
-		Content: response,
-	})
-
-	hs.logInfof("Heartbeat result sent to %s", platform)
 }
 
 // parseLastChannel parses the last channel string into platform and userID.
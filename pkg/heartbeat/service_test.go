@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
@@ -108,6 +109,71 @@ func TestExecuteHeartbeat_ResultLogging(t *testing.T) {
 	}
 }
 
+func TestExecuteHeartbeat_UsesTargetFunc(t *testing.T) {
+	tmpDir := t.TempDir()
+	hs := NewHeartbeatService(tmpDir, 30, true)
+	hs.stopChan = make(chan struct{}) // Enable for testing
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	hs.SetBus(msgBus)
+	hs.SetTargetFunc(func() (string, string) { return "telegram", "42" })
+
+	var gotChannel, gotChatID string
+	hs.SetHandler(func(prompt, channel, chatID string) *tools.ToolResult {
+		gotChannel, gotChatID = channel, chatID
+		return &tools.ToolResult{ForLLM: "disk almost full", ForUser: "disk almost full"}
+	})
+	os.WriteFile(filepath.Join(tmpDir, "HEARTBEAT.md"), []byte("Check disk"), 0o644)
+
+	hs.executeHeartbeat()
+
+	if gotChannel != "telegram" || gotChatID != "42" {
+		t.Fatalf("handler target = %s/%s, want telegram/42", gotChannel, gotChatID)
+	}
+	select {
+	case msg := <-msgBus.OutboundChan():
+		if msg.Context.Channel != "telegram" || msg.Context.ChatID != "42" || msg.Content != "disk almost full" {
+			t.Fatalf("outbound = %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("heartbeat result was not sent")
+	}
+}
+
+func TestExecuteHeartbeat_SendsToAllRecipients(t *testing.T) {
+	tmpDir := t.TempDir()
+	hs := NewHeartbeatService(tmpDir, 30, true)
+	hs.stopChan = make(chan struct{}) // Enable for testing
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	hs.SetBus(msgBus)
+	hs.SetTargetFunc(func() (string, string) { return "telegram", "42" })
+	hs.SetRecipients([]Recipient{{Channel: "slack", ChatID: "C1"}, {Channel: "discord", ChatID: "99"}})
+
+	var gotChannel string
+	hs.SetHandler(func(prompt, channel, chatID string) *tools.ToolResult {
+		gotChannel = channel
+		return &tools.ToolResult{ForLLM: "disk almost full", ForUser: "disk almost full"}
+	})
+	os.WriteFile(filepath.Join(tmpDir, "HEARTBEAT.md"), []byte("Check disk"), 0o644)
+
+	hs.executeHeartbeat()
+
+	if gotChannel != "slack" {
+		t.Fatalf("handler channel = %s, want first recipient slack", gotChannel)
+	}
+	for _, want := range []string{"slack:C1", "discord:99"} {
+		select {
+		case msg := <-msgBus.OutboundChan():
+			if got := msg.Context.Channel + ":" + msg.Context.ChatID; got != want {
+				t.Fatalf("outbound to %s, want %s", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("heartbeat result was not sent to %s", want)
+		}
+	}
+}
+
 func TestHeartbeatService_StartStop(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "heartbeat-test-*")
 	if err != nil {
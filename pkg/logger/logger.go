@@ -26,6 +26,16 @@ const (
 	Component = "component"
 )
 
+// LogFormat selects how log lines are written to the console.
+type LogFormat string
+
+const (
+	// FormatText renders human-readable, optionally colored lines.
+	FormatText LogFormat = "text"
+	// FormatJSON writes one JSON object per line for log pipelines.
+	FormatJSON LogFormat = "json"
+)
+
 var (
 	logLevelNames = map[LogLevel]string{
 		DEBUG: "DEBUG",
@@ -42,6 +52,14 @@ var (
 	mu            sync.RWMutex
 	writers       []io.Writer
 	consoleWriter zerolog.ConsoleWriter
+
+	// componentLevels overrides currentLevel for individual components.
+	componentLevels = map[string]LogLevel{}
+
+	currentFormat  = FormatText
+	consoleEnabled = true
+	// jsonOutput is where JSON-formatted console logs are written.
+	jsonOutput io.Writer = os.Stdout
 )
 
 func init() {
@@ -114,7 +132,77 @@ func SetLevel(level LogLevel) {
 	mu.Lock()
 	defer mu.Unlock()
 	currentLevel = level
-	zerolog.SetGlobalLevel(level)
+	syncGlobalLevel()
+}
+
+// SetComponentLevel overrides the log level for a single component, so one
+// subsystem can log at DEBUG while everything else stays at the global level.
+func SetComponentLevel(component string, level LogLevel) {
+	mu.Lock()
+	defer mu.Unlock()
+	componentLevels[component] = level
+	syncGlobalLevel()
+}
+
+// SetComponentLevels replaces all per-component overrides. A nil or empty
+// map clears them.
+func SetComponentLevels(levels map[string]LogLevel) {
+	mu.Lock()
+	defer mu.Unlock()
+	componentLevels = make(map[string]LogLevel, len(levels))
+	for component, level := range levels {
+		componentLevels[component] = level
+	}
+	syncGlobalLevel()
+}
+
+// GetComponentLevel returns the effective level for component: its override
+// if one is set, otherwise the global level.
+func GetComponentLevel(component string) LogLevel {
+	mu.RLock()
+	defer mu.RUnlock()
+	return componentLevelLocked(component)
+}
+
+func componentLevelLocked(component string) LogLevel {
+	if level, ok := componentLevels[component]; ok {
+		return level
+	}
+	return currentLevel
+}
+
+// syncGlobalLevel lowers zerolog's global filter to the most verbose level in
+// use, since it would otherwise drop events a component override allows.
+// Callers must hold mu.
+func syncGlobalLevel() {
+	lowest := currentLevel
+	for _, level := range componentLevels {
+		lowest = min(lowest, level)
+	}
+	zerolog.SetGlobalLevel(lowest)
+}
+
+// ParseComponentLevels parses a comma-separated list of component=level
+// pairs, such as "agent=debug,mcp=warn".
+func ParseComponentLevels(s string) (map[string]LogLevel, error) {
+	levels := make(map[string]LogLevel)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		component, levelName, ok := strings.Cut(pair, "=")
+		component = strings.TrimSpace(component)
+		if !ok || component == "" {
+			return nil, fmt.Errorf("invalid component log level %q, want component=level", pair)
+		}
+		level, ok := ParseLevel(levelName)
+		if !ok {
+			return nil, fmt.Errorf("invalid log level %q for component %q", strings.TrimSpace(levelName), component)
+		}
+		levels[component] = level
+	}
+	return levels, nil
 }
 
 func SetConsoleLevel(level LogLevel) {
@@ -126,6 +214,7 @@ func SetConsoleLevel(level LogLevel) {
 func DisableConsole() {
 	mu.Lock()
 	defer mu.Unlock()
+	consoleEnabled = false
 	writers[0] = io.Discard
 	logger = logger.Output(io.MultiWriter(writers...))
 }
@@ -133,10 +222,62 @@ func DisableConsole() {
 func EnableConsole() {
 	mu.Lock()
 	defer mu.Unlock()
-	writers[0] = consoleWriter
+	consoleEnabled = true
+	writers[0] = consoleSink()
 	logger = logger.Output(io.MultiWriter(writers...))
 }
 
+// consoleSink returns the console writer for the current format. Callers
+// must hold mu.
+func consoleSink() io.Writer {
+	if currentFormat == FormatJSON {
+		return jsonOutput
+	}
+	return consoleWriter
+}
+
+// ParseFormat converts a case-insensitive format name to a LogFormat.
+// Returns the format and true if valid, or (FormatText, false) if unrecognized.
+func ParseFormat(s string) (LogFormat, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "text":
+		return FormatText, true
+	case "json":
+		return FormatJSON, true
+	default:
+		return FormatText, false
+	}
+}
+
+// SetFormat switches console output between text and JSON. File logging is
+// always JSON and is not affected.
+func SetFormat(format LogFormat) {
+	mu.Lock()
+	defer mu.Unlock()
+	currentFormat = format
+	if consoleEnabled {
+		writers[0] = consoleSink()
+		logger = logger.Output(io.MultiWriter(writers...))
+	}
+}
+
+// SetFormatFromString sets the console format from a string value.
+// If the string is empty or not a recognized format, the current format is kept.
+func SetFormatFromString(s string) {
+	if s == "" {
+		return
+	}
+	if format, ok := ParseFormat(s); ok {
+		SetFormat(format)
+	}
+}
+
+func GetFormat() LogFormat {
+	mu.RLock()
+	defer mu.RUnlock()
+	return currentFormat
+}
+
 func GetLevel() LogLevel {
 	mu.RLock()
 	defer mu.RUnlock()
@@ -218,6 +359,15 @@ func DisableFileLogging() {
 }
 
 func ConfigureFromEnv() {
+	SetFormatFromString(os.Getenv("PICOCLAW_LOG_FORMAT"))
+	if spec := os.Getenv("PICOCLAW_LOG_LEVELS"); spec != "" {
+		if levels, err := ParseComponentLevels(spec); err != nil {
+			fmt.Fprintf(os.Stderr, "ignoring PICOCLAW_LOG_LEVELS: %v\n", err)
+		} else {
+			SetComponentLevels(levels)
+		}
+	}
+
 	if logFile := os.Getenv("PICOCLAW_LOG_FILE"); logFile != "" {
 		if strings.HasPrefix(logFile, "~/") {
 			if home := os.Getenv("HOME"); home != "" {
@@ -303,33 +453,41 @@ func getEvent(logger zerolog.Logger, level LogLevel) *zerolog.Event {
 }
 
 func logMessage(level LogLevel, component string, message string, fields map[string]any) {
-	if level < currentLevel {
+	mu.RLock()
+	minLevel := currentLevel
+	hasOverrides := len(componentLevels) > 0
+	mu.RUnlock()
+	if level < minLevel && !hasOverrides {
 		return
 	}
 
 	skip, pkg := getCallerSkip()
 
-	event := getEvent(logger, level)
-
 	if component == "" {
 		component = pkg
 	}
+	if hasOverrides && level < GetComponentLevel(component) {
+		return
+	}
+
+	event := getEvent(logger, level)
 
 	event.Str(Component, component)
 
-	appendFields(event, fields)
+	redactor := activeRedactor.Load()
+	appendFields(event, fields, redactor)
 
-	event.CallerSkipFrame(skip).Msg(message)
+	event.CallerSkipFrame(skip).Msg(redactor.Redact(message))
 }
 
-func appendFields(event *zerolog.Event, fields map[string]any) {
+func appendFields(event *zerolog.Event, fields map[string]any, redactor *Redactor) {
 	for k, v := range fields {
 		// Type switch to avoid double JSON serialization of strings
 		switch val := v.(type) {
 		case error:
-			event.Str(k, val.Error())
+			event.Str(k, redactor.Redact(val.Error()))
 		case string:
-			event.Str(k, val)
+			event.Str(k, redactor.Redact(val))
 		case int:
 			event.Int(k, val)
 		case int64:
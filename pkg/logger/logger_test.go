@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -352,7 +353,7 @@ func TestAppendFields_ErrorUsesErrorString(t *testing.T) {
 	l := zerolog.New(&buf)
 
 	event := l.Info()
-	appendFields(event, map[string]any{"error": errors.New("transcription request failed")})
+	appendFields(event, map[string]any{"error": errors.New("transcription request failed")}, nil)
 	event.Msg("test")
 
 	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
@@ -370,6 +371,133 @@ func TestAppendFields_ErrorUsesErrorString(t *testing.T) {
 	}
 }
 
+func TestSetFormatJSONWritesStructuredLines(t *testing.T) {
+	var buf bytes.Buffer
+	origOutput := jsonOutput
+	origLevel := GetLevel()
+	jsonOutput = &buf
+	SetLevel(INFO)
+	EnableConsole()
+	t.Cleanup(func() {
+		SetFormat(FormatText)
+		jsonOutput = origOutput
+		SetLevel(origLevel)
+	})
+
+	SetFormat(FormatJSON)
+	if got := GetFormat(); got != FormatJSON {
+		t.Fatalf("GetFormat() = %q, want %q", got, FormatJSON)
+	}
+	InfoCF("gateway", "listener ready", map[string]any{"port": 18790, "host": "localhost"})
+
+	var got map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &got); err != nil {
+		t.Fatalf("console output is not JSON: %v\n%s", err, buf.String())
+	}
+	if got[Component] != "gateway" || got["message"] != "listener ready" {
+		t.Fatalf("unexpected component/message: %#v", got)
+	}
+	if got["host"] != "localhost" || got["port"] != float64(18790) {
+		t.Fatalf("context fields missing: %#v", got)
+	}
+	if got["level"] != "info" {
+		t.Fatalf("level = %#v, want info", got["level"])
+	}
+
+	buf.Reset()
+	SetFormat(FormatText)
+	Info("back to text")
+	if buf.Len() != 0 {
+		t.Fatalf("text format should not write to the JSON output, got %q", buf.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input string
+		want  LogFormat
+		ok    bool
+	}{
+		{"json", FormatJSON, true},
+		{" JSON ", FormatJSON, true},
+		{"text", FormatText, true},
+		{"", FormatText, false},
+		{"yaml", FormatText, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseFormat(tt.input)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseFormat(%q) = (%q, %v), want (%q, %v)", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestComponentLevelOverridesGlobalLevel(t *testing.T) {
+	var buf bytes.Buffer
+	origOutput := jsonOutput
+	origLevel := GetLevel()
+	jsonOutput = &buf
+	EnableConsole()
+	SetFormat(FormatJSON)
+	SetLevel(WARN)
+	t.Cleanup(func() {
+		SetComponentLevels(nil)
+		SetFormat(FormatText)
+		jsonOutput = origOutput
+		SetLevel(origLevel)
+	})
+
+	SetComponentLevel("mcp", DEBUG)
+	SetComponentLevel("agent", ERROR)
+	if got := GetComponentLevel("mcp"); got != DEBUG {
+		t.Fatalf("GetComponentLevel(mcp) = %v, want DEBUG", got)
+	}
+	if got := GetComponentLevel("gateway"); got != WARN {
+		t.Fatalf("GetComponentLevel(gateway) = %v, want global WARN", got)
+	}
+
+	DebugCF("mcp", "mcp debug", nil)
+	DebugCF("gateway", "gateway debug", nil)
+	WarnCF("agent", "agent warn", nil)
+	ErrorCF("agent", "agent error", nil)
+	WarnCF("gateway", "gateway warn", nil)
+
+	out := buf.String()
+	for _, want := range []string{"mcp debug", "agent error", "gateway warn"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"gateway debug", "agent warn"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("did not expect %q in output:\n%s", unwanted, out)
+		}
+	}
+
+	buf.Reset()
+	SetComponentLevels(nil)
+	DebugCF("mcp", "mcp debug after reset", nil)
+	if buf.Len() != 0 {
+		t.Fatalf("overrides should be cleared, got %q", buf.String())
+	}
+}
+
+func TestParseComponentLevels(t *testing.T) {
+	got, err := ParseComponentLevels(" agent=debug, mcp=WARN ,,")
+	if err != nil {
+		t.Fatalf("ParseComponentLevels() error = %v", err)
+	}
+	if len(got) != 2 || got["agent"] != DEBUG || got["mcp"] != WARN {
+		t.Fatalf("ParseComponentLevels() = %v", got)
+	}
+
+	for _, bad := range []string{"agent", "=debug", "agent=loud"} {
+		if _, err := ParseComponentLevels(bad); err == nil {
+			t.Errorf("ParseComponentLevels(%q) error = nil, want error", bad)
+		}
+	}
+}
+
 func TestDisableConsole(t *testing.T) {
 	DisableConsole()
 	Info("this should go to nowhere")
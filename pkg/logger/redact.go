@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Redaction modes for RedactionOptions.Mode.
+const (
+	RedactionOff      = "off"
+	RedactionModerate = "moderate"
+	RedactionStrict   = "strict"
+)
+
+const redactedText = "[REDACTED]"
+
+// redactionRule replaces matches of re with repl, which may refer to groups.
+type redactionRule struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// moderateRules catch strings that look like credentials.
+var moderateRules = []redactionRule{
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), redactedText},
+	{regexp.MustCompile(`\b(?:sk|pk|rk)-[A-Za-z0-9_-]{16,}`), redactedText},
+	{regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`), redactedText},
+	{regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`), redactedText},
+	{regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{30,}\b`), redactedText},
+	{regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), redactedText},
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`), redactedText},
+	{regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9._~+/=-]{16,}`), "${1}" + redactedText},
+	{
+		regexp.MustCompile(`(?i)\b((?:api[_-]?key|access[_-]?token|auth[_-]?token|token|secret|password|passwd)["']?\s*[:=]\s*["']?)[^\s"',}]{6,}`),
+		"${1}" + redactedText,
+	},
+}
+
+// strictRules additionally remove personal contact details.
+var strictRules = []redactionRule{
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[EMAIL]"},
+	{regexp.MustCompile(`\+\d{7,15}\b`), "[PHONE]"},
+	{regexp.MustCompile(`\(?\b\d{3}\)?[\s.-]\d{3}[\s.-]\d{4}\b`), "[PHONE]"},
+}
+
+// RedactionOptions configures NewRedactor.
+type RedactionOptions struct {
+	// Mode is "moderate" (the default) to scrub credential-like strings,
+	// "strict" to also scrub email addresses and phone numbers, or "off".
+	Mode string
+	// Patterns are extra regular expressions whose matches are scrubbed.
+	Patterns []string
+	// Secrets are exact values to scrub, such as configured API keys.
+	Secrets []string
+	// MaxLength caps each logged string, in bytes. Zero means no cap.
+	MaxLength int
+}
+
+// Redactor scrubs sensitive content from log messages and string fields.
+type Redactor struct {
+	rules     []redactionRule
+	secrets   *strings.Replacer
+	maxLength int
+}
+
+// NewRedactor builds a Redactor. It returns nil, which logs content as-is,
+// when the mode is "off" and there is nothing else to do.
+func NewRedactor(opts RedactionOptions) (*Redactor, error) {
+	r := &Redactor{maxLength: opts.MaxLength}
+	switch strings.ToLower(strings.TrimSpace(opts.Mode)) {
+	case "", RedactionModerate:
+		r.rules = append(r.rules, moderateRules...)
+	case RedactionStrict:
+		r.rules = append(r.rules, moderateRules...)
+		r.rules = append(r.rules, strictRules...)
+	case RedactionOff:
+		if len(opts.Patterns) == 0 && len(opts.Secrets) == 0 && opts.MaxLength <= 0 {
+			return nil, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown log redaction mode %q", opts.Mode)
+	}
+	for _, pattern := range opts.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log redaction pattern %q: %w", pattern, err)
+		}
+		r.rules = append(r.rules, redactionRule{re: re, repl: redactedText})
+	}
+	var pairs []string
+	for _, secret := range opts.Secrets {
+		if len(secret) >= 4 {
+			pairs = append(pairs, secret, redactedText)
+		}
+	}
+	if len(pairs) > 0 {
+		r.secrets = strings.NewReplacer(pairs...)
+	}
+	return r, nil
+}
+
+// Redact returns s with sensitive content replaced, cut to the maximum
+// length if one is set.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || len(s) < 8 {
+		return s
+	}
+	if r.secrets != nil {
+		s = r.secrets.Replace(s)
+	}
+	for _, rule := range r.rules {
+		s = rule.re.ReplaceAllString(s, rule.repl)
+	}
+	if r.maxLength > 0 && len(s) > r.maxLength {
+		s = s[:r.maxLength] + fmt.Sprintf("... (%d bytes truncated)", len(s)-r.maxLength)
+	}
+	return s
+}
+
+var defaultRedactor, _ = NewRedactor(RedactionOptions{})
+
+// activeRedactor is applied to every log line. Moderate redaction is on
+// until SetRedactor replaces it.
+var activeRedactor atomic.Pointer[Redactor]
+
+func init() {
+	activeRedactor.Store(defaultRedactor)
+}
+
+// SetRedactor replaces the redactor applied to log output. Nil turns
+// redaction off.
+func SetRedactor(r *Redactor) {
+	activeRedactor.Store(r)
+}
+
+// RedactString applies the active redactor to s, for content written
+// outside the logger, such as audit records.
+func RedactString(s string) string {
+	return activeRedactor.Load().Redact(s)
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -124,15 +125,27 @@ type ServerConnection struct {
 	Session     *mcp.ClientSession
 	Tools       []*mcp.Tool
 	reconnectMu sync.Mutex
+	calls       sync.WaitGroup // in-flight CallTool calls on this connection
 }
 
 // Manager manages multiple MCP server connections
 type Manager struct {
 	servers       map[string]*ServerConnection
+	health        map[string]*ServerHealth
 	runtimeEvents runtimeevents.Bus
 	mu            sync.RWMutex
 	closed        atomic.Bool    // changed from bool to atomic.Bool to avoid TOCTOU race
 	wg            sync.WaitGroup // tracks in-flight CallTool calls
+
+	// Supervision: restarts outlive the context servers were first connected
+	// with, so they run under the manager's own lifetime.
+	maxRestarts  int
+	lifetime     context.Context
+	stopLifetime context.CancelFunc
+	supervisors  sync.WaitGroup
+	// retiring tracks servers dropped by Reconcile that are still draining
+	// their in-flight calls.
+	retiring sync.WaitGroup
 }
 
 var connectServerFunc = connectServer
@@ -160,8 +173,13 @@ type ServerEventPayload struct {
 
 // NewManager creates a new MCP manager
 func NewManager(opts ...ManagerOption) *Manager {
+	lifetime, stop := context.WithCancel(context.Background())
 	m := &Manager{
-		servers: make(map[string]*ServerConnection),
+		servers:      make(map[string]*ServerConnection),
+		health:       make(map[string]*ServerHealth),
+		maxRestarts:  config.DefaultMCPMaxRestarts,
+		lifetime:     lifetime,
+		stopLifetime: stop,
 	}
 	for _, opt := range opts {
 		if opt != nil {
@@ -193,6 +211,8 @@ func (m *Manager) LoadFromMCPConfig(
 		return nil
 	}
 
+	m.maxRestarts = mcpCfg.GetMaxRestarts()
+
 	logger.InfoCF("mcp", "Initializing MCP servers",
 		map[string]any{
 			"count": len(mcpCfg.Servers),
@@ -216,24 +236,10 @@ func (m *Manager) LoadFromMCPConfig(
 		go func(name string, serverCfg config.MCPServerConfig, workspace string) {
 			defer wg.Done()
 
-			// Resolve relative envFile paths relative to workspace
-			if serverCfg.EnvFile != "" && !filepath.IsAbs(serverCfg.EnvFile) {
-				if workspace == "" {
-					err := fmt.Errorf(
-						"workspace path is empty while resolving relative envFile %q for server %s",
-						serverCfg.EnvFile,
-						name,
-					)
-					logger.ErrorCF("mcp", "Invalid MCP server configuration",
-						map[string]any{
-							"server":   name,
-							"env_file": serverCfg.EnvFile,
-							"error":    err.Error(),
-						})
-					errs <- err
-					return
-				}
-				serverCfg.EnvFile = filepath.Join(workspace, serverCfg.EnvFile)
+			serverCfg, err := resolveServerEnvFile(name, serverCfg, workspace)
+			if err != nil {
+				errs <- err
+				return
 			}
 
 			if err := m.ConnectServer(ctx, name, serverCfg); err != nil {
@@ -287,6 +293,33 @@ func (m *Manager) LoadFromMCPConfig(
 	return nil
 }
 
+// resolveServerEnvFile resolves a relative envFile path against the workspace.
+func resolveServerEnvFile(
+	name string,
+	serverCfg config.MCPServerConfig,
+	workspace string,
+) (config.MCPServerConfig, error) {
+	if serverCfg.EnvFile == "" || filepath.IsAbs(serverCfg.EnvFile) {
+		return serverCfg, nil
+	}
+	if workspace == "" {
+		err := fmt.Errorf(
+			"workspace path is empty while resolving relative envFile %q for server %s",
+			serverCfg.EnvFile,
+			name,
+		)
+		logger.ErrorCF("mcp", "Invalid MCP server configuration",
+			map[string]any{
+				"server":   name,
+				"env_file": serverCfg.EnvFile,
+				"error":    err.Error(),
+			})
+		return serverCfg, err
+	}
+	serverCfg.EnvFile = filepath.Join(workspace, serverCfg.EnvFile)
+	return serverCfg, nil
+}
+
 // ConnectServer connects to a single MCP server
 func (m *Manager) ConnectServer(
 	ctx context.Context,
@@ -310,6 +343,8 @@ func (m *Manager) ConnectServer(
 	}
 
 	m.servers[name] = conn
+	m.health[name] = &ServerHealth{Name: name, State: ServerStateHealthy, Since: time.Now()}
+	m.supervise(name, conn)
 	for _, tool := range conn.Tools {
 		toolName := ""
 		if tool != nil {
@@ -523,6 +558,7 @@ func (m *Manager) CallTool(
 	conn, ok := m.servers[serverName]
 	if ok {
 		m.wg.Add(1) // Add to WaitGroup while holding the lock
+		conn.calls.Add(1)
 	}
 	m.mu.RUnlock()
 
@@ -530,6 +566,11 @@ func (m *Manager) CallTool(
 		return nil, fmt.Errorf("server %s not found", serverName)
 	}
 	defer m.wg.Done()
+	defer conn.calls.Done()
+
+	if !m.ServerAvailable(serverName) {
+		return nil, fmt.Errorf("MCP server %s is unavailable while it is being restarted", serverName)
+	}
 
 	params := &mcp.CallToolParams{
 		Name:      toolName,
@@ -652,6 +693,7 @@ func (m *Manager) reconnectServer(
 
 	if currentConn == staleConn {
 		m.servers[serverName] = freshConn
+		m.supervise(serverName, freshConn)
 		staleToClose := staleConn
 		m.mu.Unlock()
 		_ = staleToClose.Session.Close()
@@ -674,6 +716,12 @@ func (m *Manager) Close() error {
 	// Wait for all in-flight CallTool calls to finish before closing sessions
 	// After closed=true is set, no new CallTool can start (they check closed first)
 	m.wg.Wait()
+	m.retiring.Wait()
+
+	// Stop pending restarts, then wait for the watchers once their sessions
+	// are closed below.
+	m.stopLifetime()
+	defer m.supervisors.Wait()
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -696,6 +744,7 @@ func (m *Manager) Close() error {
 	}
 
 	m.servers = make(map[string]*ServerConnection)
+	m.health = make(map[string]*ServerHealth)
 
 	if len(errs) > 0 {
 		return fmt.Errorf("failed to close %d server(s): %w", len(errs), errors.Join(errs...))
@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// ReconcileResult lists the servers a Reconcile call touched.
+type ReconcileResult struct {
+	Added     []string
+	Removed   []string
+	Restarted []string
+	Unchanged []string
+	Failed    []string
+}
+
+// Reconcile brings the connected servers in line with mcpCfg after a config
+// change. New servers are connected, servers that were removed or disabled
+// are dropped, and servers whose settings changed are reconnected; servers
+// whose config is identical keep their existing session.
+//
+// Dropped servers stop accepting new calls immediately, but their sessions
+// are only closed once in-flight calls have finished. An error is returned
+// only when servers were requested and none of them are connected.
+func (m *Manager) Reconcile(
+	ctx context.Context,
+	mcpCfg config.MCPConfig,
+	workspacePath string,
+) (ReconcileResult, error) {
+	var result ReconcileResult
+	if m.closed.Load() {
+		return result, fmt.Errorf("manager is closed")
+	}
+
+	desired := make(map[string]config.MCPServerConfig)
+	var errs []error
+	if mcpCfg.Enabled {
+		for name, serverCfg := range mcpCfg.Servers {
+			if !serverCfg.Enabled {
+				continue
+			}
+			resolved, err := resolveServerEnvFile(name, serverCfg, workspacePath)
+			if err != nil {
+				result.Failed = append(result.Failed, name)
+				errs = append(errs, err)
+				continue
+			}
+			desired[name] = resolved
+		}
+	}
+
+	m.mu.Lock()
+	m.maxRestarts = mcpCfg.GetMaxRestarts()
+	retired := make(map[string]*ServerConnection)
+	for name, conn := range m.servers {
+		serverCfg, keep := desired[name]
+		switch {
+		case !keep:
+			result.Removed = append(result.Removed, name)
+		case !reflect.DeepEqual(conn.Config, serverCfg) || m.serverFailedLocked(name):
+			result.Restarted = append(result.Restarted, name)
+		default:
+			result.Unchanged = append(result.Unchanged, name)
+			delete(desired, name)
+			continue
+		}
+		delete(m.servers, name)
+		delete(m.health, name)
+		retired[name] = conn
+	}
+	for name := range desired {
+		if !slices.Contains(result.Restarted, name) {
+			result.Added = append(result.Added, name)
+		}
+	}
+	m.mu.Unlock()
+
+	for name, conn := range retired {
+		m.retire(name, conn)
+	}
+	for _, name := range result.Removed {
+		logger.InfoCF("mcp", "Removed MCP server after config change",
+			map[string]any{"server": name})
+	}
+
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	for name, serverCfg := range desired {
+		wg.Add(1)
+		go func(name string, serverCfg config.MCPServerConfig) {
+			defer wg.Done()
+			if err := m.ConnectServer(ctx, name, serverCfg); err != nil {
+				logger.ErrorCF("mcp", "Failed to connect to MCP server",
+					map[string]any{
+						"server": name,
+						"error":  err.Error(),
+					})
+				errMu.Lock()
+				result.Failed = append(result.Failed, name)
+				errs = append(errs, fmt.Errorf("failed to connect to server %s: %w", name, err))
+				errMu.Unlock()
+			}
+		}(name, serverCfg)
+	}
+	wg.Wait()
+
+	for _, names := range [][]string{
+		result.Added, result.Removed, result.Restarted, result.Unchanged, result.Failed,
+	} {
+		sort.Strings(names)
+	}
+
+	logger.InfoCF("mcp", "MCP servers reconciled with config",
+		map[string]any{
+			"added":     len(result.Added),
+			"removed":   len(result.Removed),
+			"restarted": len(result.Restarted),
+			"unchanged": len(result.Unchanged),
+			"failed":    len(result.Failed),
+		})
+
+	if len(errs) > 0 && len(m.GetServers()) == 0 {
+		return result, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+func (m *Manager) serverFailedLocked(name string) bool {
+	h, ok := m.health[name]
+	return ok && h.State == ServerStateFailed
+}
+
+// retire closes a connection that is no longer registered once its
+// in-flight calls have returned. It does not block the caller.
+func (m *Manager) retire(name string, conn *ServerConnection) {
+	m.publishServerEvent(runtimeevents.KindMCPServerRemoved, name, conn.Config, len(conn.Tools), nil)
+	m.retiring.Add(1)
+	go func() {
+		defer m.retiring.Done()
+		conn.calls.Wait()
+		if err := conn.Session.Close(); err != nil {
+			logger.WarnCF("mcp", "Failed to close retired MCP server connection",
+				map[string]any{
+					"server": name,
+					"error":  err.Error(),
+				})
+		}
+	}()
+}
@@ -0,0 +1,196 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func connectScripted(t *testing.T, mgr *Manager, name string, cfg config.MCPServerConfig) *ServerConnection {
+	t.Helper()
+	conn, _, err := newScriptedServerConnection(name+"-session", nil, nil)
+	if err != nil {
+		t.Fatalf("newScriptedServerConnection() error = %v", err)
+	}
+	conn.Name = name
+	conn.Config = cfg
+	mgr.mu.Lock()
+	mgr.servers[name] = conn
+	mgr.health[name] = &ServerHealth{Name: name, State: ServerStateHealthy, Since: time.Now()}
+	mgr.mu.Unlock()
+	return conn
+}
+
+func sessionClosed(conn *ServerConnection) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		_ = conn.Session.Wait()
+		close(done)
+	}()
+	return done
+}
+
+func TestReconcile_AddsRemovesAndRestartsServers(t *testing.T) {
+	useFastRestarts(t)
+
+	var (
+		connectMu sync.Mutex
+		connected []string
+	)
+	connectServerFunc = func(_ context.Context, name string, cfg config.MCPServerConfig) (*ServerConnection, error) {
+		conn, _, err := newScriptedServerConnection(name+"-fresh", nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		conn.Name = name
+		conn.Config = cfg
+		connectMu.Lock()
+		connected = append(connected, name)
+		connectMu.Unlock()
+		return conn, nil
+	}
+
+	keepCfg := config.MCPServerConfig{Enabled: true, Type: "http", URL: "https://keep.invalid/mcp"}
+	mgr := NewManager()
+	defer mgr.Close()
+	keep := connectScripted(t, mgr, "keep", keepCfg)
+	gone := connectScripted(t, mgr, "gone", config.MCPServerConfig{Enabled: true, Command: "gone"})
+	changed := connectScripted(t, mgr, "changed", config.MCPServerConfig{Enabled: true, Command: "v1"})
+	goneClosed := sessionClosed(gone)
+
+	result, err := mgr.Reconcile(context.Background(), config.MCPConfig{
+		ToolConfig: config.ToolConfig{Enabled: true},
+		Servers: map[string]config.MCPServerConfig{
+			"keep":     keepCfg,
+			"changed":  {Enabled: true, Command: "v2"},
+			"new":      {Enabled: true, Command: "new"},
+			"disabled": {Enabled: false, Command: "off"},
+		},
+	}, t.TempDir())
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	assertNames(t, "Added", result.Added, "new")
+	assertNames(t, "Removed", result.Removed, "gone")
+	assertNames(t, "Restarted", result.Restarted, "changed")
+	assertNames(t, "Unchanged", result.Unchanged, "keep")
+	if len(result.Failed) != 0 {
+		t.Fatalf("Failed = %v, want none", result.Failed)
+	}
+
+	if conn, ok := mgr.GetServer("keep"); !ok || conn != keep {
+		t.Fatal("unchanged server should keep its existing connection")
+	}
+	if conn, ok := mgr.GetServer("changed"); !ok || conn == changed || conn.Config.Command != "v2" {
+		t.Fatalf("changed server was not reconnected with the new config: %+v", conn)
+	}
+	if _, ok := mgr.GetServer("gone"); ok {
+		t.Fatal("removed server is still registered")
+	}
+	if _, ok := mgr.GetServer("disabled"); ok {
+		t.Fatal("disabled server should not be connected")
+	}
+	if len(connected) != 2 {
+		t.Fatalf("connect calls = %v, want only the new and changed servers", connected)
+	}
+
+	select {
+	case <-goneClosed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("removed server session was never closed")
+	}
+}
+
+func TestReconcile_RemovedServerDrainsInFlightCalls(t *testing.T) {
+	useFastRestarts(t)
+
+	mgr := NewManager()
+	defer mgr.Close()
+	conn := connectScripted(t, mgr, "busy", config.MCPServerConfig{Enabled: true, Command: "busy"})
+	closed := sessionClosed(conn)
+
+	// Stand in for a CallTool that is still waiting on the server.
+	conn.calls.Add(1)
+
+	result, err := mgr.Reconcile(context.Background(), config.MCPConfig{
+		ToolConfig: config.ToolConfig{Enabled: true},
+	}, "")
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	assertNames(t, "Removed", result.Removed, "busy")
+
+	if _, err := mgr.CallTool(context.Background(), "busy", "echo", nil); err == nil {
+		t.Fatal("CallTool() on a removed server should fail")
+	}
+	select {
+	case <-closed:
+		t.Fatal("session was closed while a call was still in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	conn.calls.Done()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("session was not closed after the in-flight call finished")
+	}
+}
+
+func TestReconcile_ReconnectsFailedServer(t *testing.T) {
+	useFastRestarts(t)
+
+	cfg := config.MCPServerConfig{Enabled: true, Command: "flaky"}
+	connectServerFunc = func(_ context.Context, name string, cfg config.MCPServerConfig) (*ServerConnection, error) {
+		conn, _, err := newScriptedServerConnection(name+"-fresh", nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		conn.Name = name
+		conn.Config = cfg
+		return conn, nil
+	}
+
+	mgr := NewManager()
+	defer mgr.Close()
+	connectScripted(t, mgr, "flaky", cfg)
+	mgr.setServerState("flaky", ServerStateFailed, nil)
+
+	result, err := mgr.Reconcile(context.Background(), config.MCPConfig{
+		ToolConfig: config.ToolConfig{Enabled: true},
+		Servers:    map[string]config.MCPServerConfig{"flaky": cfg},
+	}, "")
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	assertNames(t, "Restarted", result.Restarted, "flaky")
+	if !mgr.ServerAvailable("flaky") {
+		t.Fatal("failed server should be available again after reconcile")
+	}
+}
+
+func TestReconcile_ClosedManager(t *testing.T) {
+	mgr := NewManager()
+	if err := mgr.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := mgr.Reconcile(context.Background(), config.MCPConfig{}, ""); err == nil {
+		t.Fatal("Reconcile() on a closed manager should return an error")
+	}
+}
+
+func assertNames(t *testing.T, field string, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s = %v, want %v", field, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s = %v, want %v", field, got, want)
+		}
+	}
+}
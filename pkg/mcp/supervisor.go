@@ -0,0 +1,225 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	runtimeevents "github.com/sipeed/picoclaw/pkg/events"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// ServerState describes the supervision state of an MCP server.
+type ServerState string
+
+const (
+	ServerStateHealthy    ServerState = "healthy"
+	ServerStateRestarting ServerState = "restarting"
+	ServerStateFailed     ServerState = "failed"
+)
+
+// ServerHealth is a point-in-time view of a supervised MCP server.
+type ServerHealth struct {
+	Name      string      `json:"name"`
+	State     ServerState `json:"state"`
+	Restarts  int         `json:"restarts"`
+	LastError string      `json:"last_error,omitempty"`
+	Since     time.Time   `json:"since"`
+}
+
+var (
+	restartBackoffBase = time.Second
+	restartBackoffMax  = 30 * time.Second
+	// stableUptime is how long a server must stay up after a restart before
+	// its restart budget is refilled, so a crash loop still hits the cap.
+	stableUptime = time.Minute
+)
+
+func restartBackoff(attempt int) time.Duration {
+	delay := restartBackoffBase
+	for i := 0; i < attempt && delay < restartBackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, restartBackoffMax)
+}
+
+// supervise watches conn until its session ends and restarts the server if
+// it went away on its own. Connections replaced or closed by the manager are
+// left alone; whoever replaced them starts a new watcher.
+func (m *Manager) supervise(name string, conn *ServerConnection) {
+	if conn == nil || conn.Session == nil || m.closed.Load() {
+		return
+	}
+	m.supervisors.Add(1)
+	go func() {
+		defer m.supervisors.Done()
+
+		attempts := 0
+		for {
+			connectedAt := time.Now()
+			waitErr := conn.Session.Wait()
+			if !m.isCurrent(name, conn) {
+				return
+			}
+			if time.Since(connectedAt) >= stableUptime {
+				attempts = 0
+			}
+			if waitErr == nil {
+				waitErr = fmt.Errorf("server closed the connection")
+			}
+			logger.WarnCF("mcp", "MCP server exited unexpectedly",
+				map[string]any{
+					"server": name,
+					"error":  waitErr.Error(),
+				})
+			m.setServerState(name, ServerStateRestarting, waitErr)
+			m.publishServerEvent(runtimeevents.KindMCPServerFailed, name, conn.Config, 0, waitErr)
+
+			fresh := m.restart(name, conn, &attempts, waitErr)
+			if fresh == nil {
+				return
+			}
+			conn = fresh
+		}
+	}()
+}
+
+// restart reconnects a crashed server with exponential backoff until it
+// succeeds, the restart budget is spent, or the manager shuts down.
+func (m *Manager) restart(
+	name string,
+	stale *ServerConnection,
+	attempts *int,
+	lastErr error,
+) *ServerConnection {
+	// Serialize with CallTool's session recovery for the same connection.
+	stale.reconnectMu.Lock()
+	defer stale.reconnectMu.Unlock()
+
+	for {
+		if *attempts >= m.maxRestarts {
+			logger.ErrorCF("mcp", "Giving up on MCP server after repeated crashes",
+				map[string]any{
+					"server":       name,
+					"max_restarts": max(m.maxRestarts, 0),
+					"error":        lastErr.Error(),
+				})
+			m.setServerState(name, ServerStateFailed, lastErr)
+			return nil
+		}
+
+		delay := restartBackoff(*attempts)
+		*attempts++
+		select {
+		case <-m.lifetime.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		if !m.isCurrent(name, stale) {
+			return nil
+		}
+
+		logger.InfoCF("mcp", "Restarting MCP server",
+			map[string]any{
+				"server":       name,
+				"attempt":      *attempts,
+				"max_restarts": m.maxRestarts,
+			})
+		m.publishServerEvent(runtimeevents.KindMCPServerConnecting, name, stale.Config, 0, nil)
+		fresh, err := connectServerFunc(m.lifetime, name, stale.Config)
+		if err != nil {
+			lastErr = err
+			logger.WarnCF("mcp", "MCP server restart failed",
+				map[string]any{
+					"server":  name,
+					"attempt": *attempts,
+					"error":   err.Error(),
+				})
+			m.setServerState(name, ServerStateRestarting, err)
+			m.publishServerEvent(runtimeevents.KindMCPServerFailed, name, stale.Config, 0, err)
+			continue
+		}
+
+		m.mu.Lock()
+		if m.closed.Load() || m.servers[name] != stale {
+			m.mu.Unlock()
+			_ = fresh.Session.Close()
+			return nil
+		}
+		m.servers[name] = fresh
+		h := m.healthLocked(name)
+		h.State = ServerStateHealthy
+		h.Restarts++
+		h.Since = time.Now()
+		restarts := h.Restarts
+		m.mu.Unlock()
+
+		_ = stale.Session.Close()
+		logger.InfoCF("mcp", "MCP server restarted",
+			map[string]any{
+				"server":     name,
+				"attempt":    *attempts,
+				"restarts":   restarts,
+				"tool_count": len(fresh.Tools),
+			})
+		m.publishServerEvent(runtimeevents.KindMCPServerRestarted, name, fresh.Config, len(fresh.Tools), nil)
+		return fresh
+	}
+}
+
+func (m *Manager) isCurrent(name string, conn *ServerConnection) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return !m.closed.Load() && m.servers[name] == conn
+}
+
+// healthLocked returns the health record for name, creating it if needed.
+// Callers must hold m.mu for writing.
+func (m *Manager) healthLocked(name string) *ServerHealth {
+	h, ok := m.health[name]
+	if !ok {
+		h = &ServerHealth{Name: name, State: ServerStateHealthy, Since: time.Now()}
+		m.health[name] = h
+	}
+	return h
+}
+
+func (m *Manager) setServerState(name string, state ServerState, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.healthLocked(name)
+	if h.State != state {
+		h.Since = time.Now()
+	}
+	h.State = state
+	h.LastError = ""
+	if err != nil {
+		h.LastError = err.Error()
+	}
+}
+
+// ServerAvailable reports whether the named server is connected and not
+// currently crashed or being restarted.
+func (m *Manager) ServerAvailable(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if _, ok := m.servers[name]; !ok {
+		return false
+	}
+	h, ok := m.health[name]
+	return !ok || h.State == ServerStateHealthy
+}
+
+// Health returns the supervision state of every connected server, sorted by
+// name.
+func (m *Manager) Health() []ServerHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]ServerHealth, 0, len(m.health))
+	for _, h := range m.health {
+		result = append(result, *h)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
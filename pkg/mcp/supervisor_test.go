@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func useFastRestarts(t *testing.T) {
+	t.Helper()
+	origBase, origMax, origStable := restartBackoffBase, restartBackoffMax, stableUptime
+	origConnect := connectServerFunc
+	restartBackoffBase, restartBackoffMax, stableUptime = time.Millisecond, 5*time.Millisecond, time.Hour
+	t.Cleanup(func() {
+		restartBackoffBase, restartBackoffMax, stableUptime = origBase, origMax, origStable
+		connectServerFunc = origConnect
+	})
+}
+
+func waitForHealth(t *testing.T, mgr *Manager, name string, cond func(ServerHealth) bool) ServerHealth {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, h := range mgr.Health() {
+			if h.Name == name && cond(h) {
+				return h
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("server %q never reached the expected state: %+v", name, mgr.Health())
+	return ServerHealth{}
+}
+
+func TestSupervisor_RestartsCrashedServer(t *testing.T) {
+	useFastRestarts(t)
+
+	first, firstTransport, err := newScriptedServerConnection("session-1", nil, nil)
+	if err != nil {
+		t.Fatalf("newScriptedServerConnection() error = %v", err)
+	}
+	second, _, err := newScriptedServerConnection("session-2", nil, nil)
+	if err != nil {
+		t.Fatalf("newScriptedServerConnection() error = %v", err)
+	}
+
+	var connects atomic.Int32
+	connectServerFunc = func(context.Context, string, config.MCPServerConfig) (*ServerConnection, error) {
+		if connects.Add(1) == 1 {
+			return first, nil
+		}
+		return second, nil
+	}
+
+	mgr := NewManager()
+	defer mgr.Close()
+	if err := mgr.ConnectServer(context.Background(), "flaky", first.Config); err != nil {
+		t.Fatalf("ConnectServer() error = %v", err)
+	}
+	if !mgr.ServerAvailable("flaky") {
+		t.Fatal("server should be available after connecting")
+	}
+
+	// Simulate the server process exiting.
+	_ = firstTransport.Close()
+
+	waitForHealth(t, mgr, "flaky", func(h ServerHealth) bool {
+		return h.State == ServerStateHealthy && h.Restarts == 1
+	})
+	conn, ok := mgr.GetServer("flaky")
+	if !ok || conn.Session.ID() != "session-2" {
+		t.Fatalf("server was not replaced with the restarted session: %+v", conn)
+	}
+	if !mgr.ServerAvailable("flaky") {
+		t.Fatal("server should be available after restart")
+	}
+}
+
+func TestSupervisor_GivesUpAfterMaxRestarts(t *testing.T) {
+	useFastRestarts(t)
+
+	conn, transport, err := newScriptedServerConnection("session-1", nil, nil)
+	if err != nil {
+		t.Fatalf("newScriptedServerConnection() error = %v", err)
+	}
+	var restarts atomic.Int32
+	connectServerFunc = func(context.Context, string, config.MCPServerConfig) (*ServerConnection, error) {
+		restarts.Add(1)
+		return nil, fmt.Errorf("command not found")
+	}
+
+	mgr := NewManager()
+	defer mgr.Close()
+	mgr.maxRestarts = 3
+	mgr.mu.Lock()
+	mgr.servers["flaky"] = conn
+	mgr.health["flaky"] = &ServerHealth{Name: "flaky", State: ServerStateHealthy}
+	mgr.supervise("flaky", conn)
+	mgr.mu.Unlock()
+
+	_ = transport.Close()
+
+	h := waitForHealth(t, mgr, "flaky", func(h ServerHealth) bool { return h.State == ServerStateFailed })
+	if got := restarts.Load(); got != 3 {
+		t.Fatalf("restart attempts = %d, want 3", got)
+	}
+	if h.LastError != "command not found" {
+		t.Fatalf("LastError = %q, want last restart error", h.LastError)
+	}
+	if mgr.ServerAvailable("flaky") {
+		t.Fatal("failed server must not be reported available")
+	}
+	if _, err := mgr.CallTool(context.Background(), "flaky", "echo", nil); err == nil {
+		t.Fatal("CallTool() on a failed server should return an error")
+	}
+}
+
+func TestSupervisor_CloseDoesNotRestart(t *testing.T) {
+	useFastRestarts(t)
+
+	conn, _, err := newScriptedServerConnection("session-1", nil, nil)
+	if err != nil {
+		t.Fatalf("newScriptedServerConnection() error = %v", err)
+	}
+	var restarts atomic.Int32
+	connectServerFunc = func(context.Context, string, config.MCPServerConfig) (*ServerConnection, error) {
+		if restarts.Add(1) == 1 {
+			return conn, nil
+		}
+		return nil, fmt.Errorf("unexpected restart")
+	}
+
+	mgr := NewManager()
+	if err := mgr.ConnectServer(context.Background(), "stable", conn.Config); err != nil {
+		t.Fatalf("ConnectServer() error = %v", err)
+	}
+	if err := mgr.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := restarts.Load(); got != 1 {
+		t.Fatalf("connect calls = %d, want 1 (no restart after Close)", got)
+	}
+}
+
+func TestRestartBackoff_DoublesUpToMax(t *testing.T) {
+	useFastRestarts(t)
+	restartBackoffBase, restartBackoffMax = time.Second, 10*time.Second
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}
+	for attempt, w := range want {
+		if got := restartBackoff(attempt); got != w {
+			t.Errorf("restartBackoff(%d) = %v, want %v", attempt, got, w)
+		}
+	}
+}
@@ -112,3 +112,60 @@ func MigrateFromJSON(
 
 	return migrated, nil
 }
+
+// sessionMetaStore is implemented by stores that keep structured session
+// metadata (scope and aliases), such as JSONLStore and SQLiteStore.
+type sessionMetaStore interface {
+	GetSessionMeta(ctx context.Context, sessionKey string) (SessionMeta, error)
+	UpsertSessionMeta(ctx context.Context, sessionKey string, scope json.RawMessage, aliases []string) error
+}
+
+// MigrateStore copies every session in src that dst does not have yet:
+// history, summary and, when both stores keep it, scope/alias metadata.
+// src is left untouched. Sessions already in dst are skipped, so running
+// the migration again only picks up new sessions. Returns the number of
+// sessions copied.
+func MigrateStore(ctx context.Context, src, dst Store) (int, error) {
+	existing := make(map[string]struct{})
+	for _, key := range dst.ListSessions() {
+		existing[key] = struct{}{}
+	}
+	srcMeta, srcHasMeta := src.(sessionMetaStore)
+	dstMeta, dstHasMeta := dst.(sessionMetaStore)
+
+	migrated := 0
+	for _, key := range src.ListSessions() {
+		if _, ok := existing[key]; ok {
+			continue
+		}
+		history, err := src.GetHistory(ctx, key)
+		if err != nil {
+			return migrated, fmt.Errorf("memory: migrate %s: get history: %w", key, err)
+		}
+		summary, err := src.GetSummary(ctx, key)
+		if err != nil {
+			return migrated, fmt.Errorf("memory: migrate %s: get summary: %w", key, err)
+		}
+		if err := dst.SetHistory(ctx, key, history); err != nil {
+			return migrated, fmt.Errorf("memory: migrate %s: set history: %w", key, err)
+		}
+		if summary != "" {
+			if err := dst.SetSummary(ctx, key, summary); err != nil {
+				return migrated, fmt.Errorf("memory: migrate %s: set summary: %w", key, err)
+			}
+		}
+		if srcHasMeta && dstHasMeta {
+			meta, err := srcMeta.GetSessionMeta(ctx, key)
+			if err != nil {
+				return migrated, fmt.Errorf("memory: migrate %s: get metadata: %w", key, err)
+			}
+			if len(meta.Scope) > 0 || len(meta.Aliases) > 0 {
+				if err := dstMeta.UpsertSessionMeta(ctx, key, meta.Scope, meta.Aliases); err != nil {
+					return migrated, fmt.Errorf("memory: migrate %s: set metadata: %w", key, err)
+				}
+			}
+		}
+		migrated++
+	}
+	return migrated, nil
+}
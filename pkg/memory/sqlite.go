@@ -0,0 +1,393 @@
+//go:build !mipsle && !netbsd && !(freebsd && arm)
+
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/providers/messageutil"
+)
+
+// SQLiteStore implements Store in a single SQLite database, so thousands of
+// sessions live in one file instead of two files each.
+//
+// Unlike JSONLStore, truncation deletes rows, so Compact has nothing to do.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS sessions (
+		session_key TEXT PRIMARY KEY,
+		summary     TEXT NOT NULL DEFAULT '',
+		scope       TEXT,
+		aliases     TEXT,
+		created_at  INTEGER NOT NULL,
+		updated_at  INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS session_messages (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		session_key TEXT NOT NULL,
+		data        TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_session_messages_key ON session_messages(session_key, id)`,
+	`CREATE TABLE IF NOT EXISTS session_aliases (
+		alias       TEXT NOT NULL,
+		session_key TEXT NOT NULL,
+		PRIMARY KEY (alias, session_key)
+	)`,
+}
+
+// NewSQLiteStore opens (or creates) the SQLite session database at path.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if dir := filepath.Dir(path); dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("memory: create directory: %w", err)
+		}
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("memory: open sqlite: %w", err)
+	}
+	pragmas := []string{
+		"PRAGMA journal_mode = WAL;",
+		"PRAGMA busy_timeout = 5000;",
+		"PRAGMA synchronous = NORMAL;",
+	}
+	for _, stmt := range append(pragmas, sqliteSchema...) {
+		if _, err := db.Exec(stmt); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("memory: init sqlite: %w", err)
+		}
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// touchSession creates the session row if needed and bumps updated_at.
+func touchSession(ctx context.Context, tx *sql.Tx, sessionKey string, now time.Time) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO sessions (session_key, created_at, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(session_key) DO UPDATE SET updated_at = excluded.updated_at`,
+		sessionKey, now.UnixMilli(), now.UnixMilli())
+	return err
+}
+
+func (s *SQLiteStore) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("memory: begin tx: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("memory: commit: %w", err)
+	}
+	return nil
+}
+
+func insertMessages(ctx context.Context, tx *sql.Tx, sessionKey string, msgs []providers.Message) error {
+	for i, msg := range msgs {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("memory: marshal message %d: %w", i, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO session_messages (session_key, data) VALUES (?, ?)`,
+			sessionKey, string(data)); err != nil {
+			return fmt.Errorf("memory: insert message: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) AddMessage(
+	ctx context.Context, sessionKey, role, content string,
+) error {
+	return s.AddFullMessage(ctx, sessionKey, providers.Message{
+		Role:    role,
+		Content: content,
+	})
+}
+
+func (s *SQLiteStore) AddFullMessage(
+	ctx context.Context, sessionKey string, msg providers.Message,
+) error {
+	if messageutil.IsTransientAssistantThoughtMessage(msg) {
+		return nil
+	}
+	now := time.Now()
+	if msg.CreatedAt == nil {
+		msg.CreatedAt = &now
+	}
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if err := touchSession(ctx, tx, sessionKey, now); err != nil {
+			return fmt.Errorf("memory: upsert session: %w", err)
+		}
+		return insertMessages(ctx, tx, sessionKey, []providers.Message{msg})
+	})
+}
+
+func (s *SQLiteStore) GetHistory(
+	ctx context.Context, sessionKey string,
+) ([]providers.Message, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT data FROM session_messages WHERE session_key = ? ORDER BY id`, sessionKey)
+	if err != nil {
+		return nil, fmt.Errorf("memory: query history: %w", err)
+	}
+	defer rows.Close()
+
+	msgs := []providers.Message{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("memory: scan message: %w", err)
+		}
+		var msg providers.Message
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			// Same policy as JSONLStore: skip a corrupt entry rather than
+			// failing the whole read.
+			log.Printf("memory: skipping corrupt message in %s: %v", sessionKey, err)
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("memory: read history: %w", err)
+	}
+	return msgs, nil
+}
+
+func (s *SQLiteStore) GetSummary(
+	ctx context.Context, sessionKey string,
+) (string, error) {
+	var summary string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT summary FROM sessions WHERE session_key = ?`, sessionKey).Scan(&summary)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("memory: query summary: %w", err)
+	}
+	return summary, nil
+}
+
+func (s *SQLiteStore) SetSummary(
+	ctx context.Context, sessionKey, summary string,
+) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if err := touchSession(ctx, tx, sessionKey, time.Now()); err != nil {
+			return fmt.Errorf("memory: upsert session: %w", err)
+		}
+		_, err := tx.ExecContext(ctx,
+			`UPDATE sessions SET summary = ? WHERE session_key = ?`, summary, sessionKey)
+		return err
+	})
+}
+
+func (s *SQLiteStore) TruncateHistory(
+	ctx context.Context, sessionKey string, keepLast int,
+) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if keepLast <= 0 {
+			_, err = tx.ExecContext(ctx,
+				`DELETE FROM session_messages WHERE session_key = ?`, sessionKey)
+		} else {
+			_, err = tx.ExecContext(ctx, `DELETE FROM session_messages
+				WHERE session_key = ? AND id NOT IN (
+					SELECT id FROM session_messages WHERE session_key = ?
+					ORDER BY id DESC LIMIT ?
+				)`, sessionKey, sessionKey, keepLast)
+		}
+		if err != nil {
+			return fmt.Errorf("memory: truncate history: %w", err)
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE sessions SET updated_at = ? WHERE session_key = ?`,
+			time.Now().UnixMilli(), sessionKey)
+		return err
+	})
+}
+
+func (s *SQLiteStore) SetHistory(
+	ctx context.Context,
+	sessionKey string,
+	history []providers.Message,
+) error {
+	history = messageutil.FilterInvalidHistoryMessages(history)
+	now := time.Now()
+	for i := range history {
+		if history[i].CreatedAt == nil {
+			history[i].CreatedAt = &now
+		}
+	}
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if err := touchSession(ctx, tx, sessionKey, now); err != nil {
+			return fmt.Errorf("memory: upsert session: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM session_messages WHERE session_key = ?`, sessionKey); err != nil {
+			return fmt.Errorf("memory: clear history: %w", err)
+		}
+		return insertMessages(ctx, tx, sessionKey, history)
+	})
+}
+
+// Compact is a no-op: truncated messages are deleted right away.
+func (s *SQLiteStore) Compact(context.Context, string) error {
+	return nil
+}
+
+// GetSessionMeta returns the metadata for sessionKey. Skip is always 0 and
+// Count is the number of stored messages.
+func (s *SQLiteStore) GetSessionMeta(ctx context.Context, sessionKey string) (SessionMeta, error) {
+	var (
+		summary          string
+		scope, aliases   sql.NullString
+		created, updated int64
+	)
+	err := s.db.QueryRowContext(ctx, `SELECT summary, scope, aliases, created_at, updated_at
+		FROM sessions WHERE session_key = ?`, sessionKey).
+		Scan(&summary, &scope, &aliases, &created, &updated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return SessionMeta{Key: sessionKey}, nil
+	}
+	if err != nil {
+		return SessionMeta{}, fmt.Errorf("memory: query session meta: %w", err)
+	}
+	meta := SessionMeta{
+		Key:       sessionKey,
+		Summary:   summary,
+		CreatedAt: time.UnixMilli(created),
+		UpdatedAt: time.UnixMilli(updated),
+	}
+	if scope.Valid && scope.String != "" {
+		meta.Scope = json.RawMessage(scope.String)
+	}
+	if aliases.Valid && aliases.String != "" {
+		_ = json.Unmarshal([]byte(aliases.String), &meta.Aliases)
+	}
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM session_messages WHERE session_key = ?`, sessionKey).
+		Scan(&meta.Count); err != nil {
+		return SessionMeta{}, fmt.Errorf("memory: count messages: %w", err)
+	}
+	return meta, nil
+}
+
+// UpsertSessionMeta stores the session's scope and aliases.
+func (s *SQLiteStore) UpsertSessionMeta(
+	ctx context.Context,
+	sessionKey string,
+	scope json.RawMessage,
+	aliases []string,
+) error {
+	aliases = normalizeAliases(sessionKey, aliases)
+	var scopeVal, aliasesVal any
+	if len(scope) > 0 {
+		scopeVal = string(scope)
+	}
+	if len(aliases) > 0 {
+		data, err := json.Marshal(aliases)
+		if err != nil {
+			return fmt.Errorf("memory: marshal aliases: %w", err)
+		}
+		aliasesVal = string(data)
+	}
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if err := touchSession(ctx, tx, sessionKey, time.Now()); err != nil {
+			return fmt.Errorf("memory: upsert session: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE sessions SET scope = ?, aliases = ? WHERE session_key = ?`,
+			scopeVal, aliasesVal, sessionKey); err != nil {
+			return fmt.Errorf("memory: update session meta: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM session_aliases WHERE session_key = ?`, sessionKey); err != nil {
+			return fmt.Errorf("memory: clear aliases: %w", err)
+		}
+		for _, alias := range aliases {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT OR IGNORE INTO session_aliases (alias, session_key) VALUES (?, ?)`,
+				alias, sessionKey); err != nil {
+				return fmt.Errorf("memory: insert alias: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ResolveSessionKey returns the canonical session key for a session key or
+// one of its aliases. A direct session key wins over an alias of the same
+// name unless the key looks like a structured (colon-separated) key.
+func (s *SQLiteStore) ResolveSessionKey(ctx context.Context, sessionKey string) (string, bool, error) {
+	sessionKey = strings.TrimSpace(sessionKey)
+	if sessionKey == "" {
+		return "", false, nil
+	}
+
+	var exists int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT 1 FROM sessions WHERE session_key = ?`, sessionKey).Scan(&exists)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", false, fmt.Errorf("memory: query session: %w", err)
+	}
+	hasDirectSession := err == nil
+	if hasDirectSession && shouldShortCircuitSessionResolve(sessionKey) {
+		return sessionKey, true, nil
+	}
+
+	var canonical string
+	err = s.db.QueryRowContext(ctx, `SELECT session_key FROM session_aliases
+		WHERE alias = ? AND session_key != ? ORDER BY session_key LIMIT 1`,
+		sessionKey, sessionKey).Scan(&canonical)
+	switch {
+	case err == nil:
+		return canonical, true, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return "", false, fmt.Errorf("memory: query alias: %w", err)
+	}
+
+	if hasDirectSession {
+		return sessionKey, true, nil
+	}
+	return "", false, nil
+}
+
+// ListSessions returns all session keys in the database.
+func (s *SQLiteStore) ListSessions() []string {
+	rows, err := s.db.Query(`SELECT session_key FROM sessions ORDER BY session_key`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var keys []string
+	for rows.Next() {
+		var key string
+		if rows.Scan(&key) == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
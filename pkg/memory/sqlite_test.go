@@ -0,0 +1,132 @@
+//go:build !mipsle && !netbsd && !(freebsd && arm)
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLiteStore_HistoryAndSummary(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	ctx := context.Background()
+
+	for _, content := range []string{"one", "two", "three", "four"} {
+		if err := store.AddMessage(ctx, "s1", "user", content); err != nil {
+			t.Fatalf("AddMessage: %v", err)
+		}
+	}
+	if err := store.SetSummary(ctx, "s1", "counting"); err != nil {
+		t.Fatalf("SetSummary: %v", err)
+	}
+	if err := store.TruncateHistory(ctx, "s1", 2); err != nil {
+		t.Fatalf("TruncateHistory: %v", err)
+	}
+
+	history, err := store.GetHistory(ctx, "s1")
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	if len(history) != 2 || history[0].Content != "three" || history[1].Content != "four" {
+		t.Fatalf("history after truncate = %+v, want [three four]", history)
+	}
+	if summary, _ := store.GetSummary(ctx, "s1"); summary != "counting" {
+		t.Fatalf("summary = %q, want counting", summary)
+	}
+
+	if err := store.SetHistory(ctx, "s1", history[1:]); err != nil {
+		t.Fatalf("SetHistory: %v", err)
+	}
+	history, _ = store.GetHistory(ctx, "s1")
+	if len(history) != 1 || history[0].Content != "four" {
+		t.Fatalf("history after SetHistory = %+v, want [four]", history)
+	}
+
+	empty, err := store.GetHistory(ctx, "missing")
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Fatalf("GetHistory(missing) = %v, %v; want empty slice", empty, err)
+	}
+	if keys := store.ListSessions(); len(keys) != 1 || keys[0] != "s1" {
+		t.Fatalf("ListSessions = %v, want [s1]", keys)
+	}
+}
+
+func TestSQLiteStore_ResolvesAliases(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	ctx := context.Background()
+
+	scope := json.RawMessage(`{"channel":"telegram"}`)
+	if err := store.UpsertSessionMeta(ctx, "sk_v1_abc", scope, []string{"agent:main:telegram:direct:42"}); err != nil {
+		t.Fatalf("UpsertSessionMeta: %v", err)
+	}
+
+	key, found, err := store.ResolveSessionKey(ctx, "agent:main:telegram:direct:42")
+	if err != nil || !found || key != "sk_v1_abc" {
+		t.Fatalf("ResolveSessionKey(alias) = %q, %v, %v; want sk_v1_abc", key, found, err)
+	}
+	if _, found, _ := store.ResolveSessionKey(ctx, "unknown"); found {
+		t.Fatal("expected unknown key to be unresolved")
+	}
+
+	meta, err := store.GetSessionMeta(ctx, "sk_v1_abc")
+	if err != nil {
+		t.Fatalf("GetSessionMeta: %v", err)
+	}
+	if string(meta.Scope) != string(scope) || len(meta.Aliases) != 1 {
+		t.Fatalf("meta = %+v, want scope and one alias", meta)
+	}
+}
+
+func TestMigrateStore_JSONLToSQLite(t *testing.T) {
+	ctx := context.Background()
+	src := newTestStore(t)
+	dst := newTestSQLiteStore(t)
+
+	if err := src.AddMessage(ctx, "agent:main:cli:direct:me", "user", "hello"); err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+	if err := src.SetSummary(ctx, "agent:main:cli:direct:me", "greeting"); err != nil {
+		t.Fatalf("SetSummary: %v", err)
+	}
+	if err := src.UpsertSessionMeta(ctx, "agent:main:cli:direct:me", nil, []string{"old-key"}); err != nil {
+		t.Fatalf("UpsertSessionMeta: %v", err)
+	}
+
+	n, err := MigrateStore(ctx, src, dst)
+	if err != nil || n != 1 {
+		t.Fatalf("MigrateStore = %d, %v; want 1 session", n, err)
+	}
+	history, _ := dst.GetHistory(ctx, "agent:main:cli:direct:me")
+	if len(history) != 1 || history[0].Content != "hello" {
+		t.Fatalf("migrated history = %+v", history)
+	}
+	if summary, _ := dst.GetSummary(ctx, "agent:main:cli:direct:me"); summary != "greeting" {
+		t.Fatalf("migrated summary = %q", summary)
+	}
+	if key, found, _ := dst.ResolveSessionKey(ctx, "old-key"); !found || key != "agent:main:cli:direct:me" {
+		t.Fatalf("migrated alias resolves to %q, %v", key, found)
+	}
+
+	// A second run must not duplicate or overwrite sessions.
+	if err := dst.AddMessage(ctx, "agent:main:cli:direct:me", "assistant", "hi"); err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+	if n, err := MigrateStore(ctx, src, dst); err != nil || n != 0 {
+		t.Fatalf("second MigrateStore = %d, %v; want 0", n, err)
+	}
+	if history, _ := dst.GetHistory(ctx, "agent:main:cli:direct:me"); len(history) != 2 {
+		t.Fatalf("history after second migration = %d messages, want 2", len(history))
+	}
+}
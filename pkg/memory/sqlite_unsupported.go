@@ -0,0 +1,11 @@
+//go:build mipsle || netbsd || (freebsd && arm)
+
+package memory
+
+import "fmt"
+
+// NewSQLiteStore is unavailable on platforms where modernc sqlite/libc
+// currently has no stable build path for this project.
+func NewSQLiteStore(string) (Store, error) {
+	return nil, fmt.Errorf("memory: sqlite session store is unavailable on this platform")
+}
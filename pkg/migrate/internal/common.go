@@ -39,13 +39,38 @@ func PlanWorkspaceMigration(
 	migrateableFiles []string,
 	migrateableDirs []string,
 	force bool,
+) ([]Action, error) {
+	onConflict := ActionBackup
+	if force {
+		onConflict = ActionCopy
+	}
+	return planWorkspace(srcWorkspace, dstWorkspace, migrateableFiles, migrateableDirs, onConflict)
+}
+
+// PlanWorkspaceMerge plans a migration that only adds files missing from the
+// PicoClaw workspace. Files present on both sides are kept as they are.
+func PlanWorkspaceMerge(
+	srcWorkspace, dstWorkspace string,
+	migrateableFiles []string,
+	migrateableDirs []string,
+) ([]Action, error) {
+	return planWorkspace(srcWorkspace, dstWorkspace, migrateableFiles, migrateableDirs, ActionKeep)
+}
+
+// planWorkspace plans the copies; onConflict is the action used for files
+// that already exist in the destination.
+func planWorkspace(
+	srcWorkspace, dstWorkspace string,
+	migrateableFiles []string,
+	migrateableDirs []string,
+	onConflict ActionType,
 ) ([]Action, error) {
 	var actions []Action
 
 	for _, filename := range migrateableFiles {
 		src := filepath.Join(srcWorkspace, filename)
 		dst := filepath.Join(dstWorkspace, filename)
-		action := planFileCopy(src, dst, force)
+		action := planFileCopy(src, dst, onConflict)
 		if action.Type != ActionSkip || action.Description != "" {
 			actions = append(actions, action)
 		}
@@ -56,7 +81,7 @@ func PlanWorkspaceMigration(
 		if _, err := os.Stat(srcDir); os.IsNotExist(err) {
 			continue
 		}
-		dirActions, err := planDirCopy(srcDir, filepath.Join(dstWorkspace, dirname), force)
+		dirActions, err := planDirCopy(srcDir, filepath.Join(dstWorkspace, dirname), onConflict)
 		if err != nil {
 			return nil, err
 		}
@@ -66,7 +91,7 @@ func PlanWorkspaceMigration(
 	return actions, nil
 }
 
-func planFileCopy(src, dst string, force bool) Action {
+func planFileCopy(src, dst string, onConflict ActionType) Action {
 	if _, err := os.Stat(src); os.IsNotExist(err) {
 		return Action{
 			Type:        ActionSkip,
@@ -76,13 +101,22 @@ func planFileCopy(src, dst string, force bool) Action {
 		}
 	}
 
-	_, dstExists := os.Stat(dst)
-	if dstExists == nil && !force {
-		return Action{
-			Type:        ActionBackup,
-			Source:      src,
-			Target:      dst,
-			Description: "destination exists, will backup and overwrite",
+	if _, err := os.Stat(dst); err == nil {
+		switch onConflict {
+		case ActionBackup:
+			return Action{
+				Type:        ActionBackup,
+				Source:      src,
+				Target:      dst,
+				Description: "destination exists, will backup and overwrite",
+			}
+		case ActionKeep:
+			return Action{
+				Type:        ActionKeep,
+				Source:      src,
+				Target:      dst,
+				Description: "exists in PicoClaw, kept",
+			}
 		}
 	}
 
@@ -94,7 +128,7 @@ func planFileCopy(src, dst string, force bool) Action {
 	}
 }
 
-func planDirCopy(srcDir, dstDir string, force bool) ([]Action, error) {
+func planDirCopy(srcDir, dstDir string, onConflict ActionType) ([]Action, error) {
 	var actions []Action
 
 	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
@@ -118,7 +152,7 @@ func planDirCopy(srcDir, dstDir string, force bool) ([]Action, error) {
 			return nil
 		}
 
-		action := planFileCopy(path, dst, force)
+		action := planFileCopy(path, dst, onConflict)
 		actions = append(actions, action)
 		return nil
 	})
@@ -184,3 +184,26 @@ func TestPlanWorkspaceMigrationNonExistentSource(t *testing.T) {
 	assert.Equal(t, ActionSkip, actions[0].Type)
 	assert.Contains(t, actions[0].Description, "source file not found")
 }
+
+func TestPlanWorkspaceMergeKeepsExistingFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcWorkspace := filepath.Join(tmpDir, "src", "workspace")
+	dstWorkspace := filepath.Join(tmpDir, "dst", "workspace")
+	require.NoError(t, os.MkdirAll(filepath.Join(srcWorkspace, "memory"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dstWorkspace, "memory"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcWorkspace, "SOUL.md"), []byte("source"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dstWorkspace, "SOUL.md"), []byte("existing"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcWorkspace, "memory", "new.md"), []byte("source"), 0o644))
+
+	actions, err := PlanWorkspaceMerge(srcWorkspace, dstWorkspace, []string{"SOUL.md"}, []string{"memory"})
+	require.NoError(t, err)
+
+	types := make(map[string]ActionType)
+	for _, action := range actions {
+		if action.Type != ActionCreateDir {
+			types[filepath.Base(action.Source)] = action.Type
+		}
+	}
+	assert.Equal(t, ActionKeep, types["SOUL.md"])
+	assert.Equal(t, ActionCopy, types["new.md"])
+}
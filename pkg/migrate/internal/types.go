@@ -6,6 +6,7 @@ type Options struct {
 	WorkspaceOnly bool
 	Force         bool
 	Refresh       bool
+	Merge         bool
 	Source        string
 	SourceHome    string
 	TargetHome    string
@@ -21,6 +22,19 @@ type Operation interface {
 	GetMigrateableDirs() []string
 }
 
+// ConfigMerger is implemented by sources that can merge their config into an
+// existing PicoClaw config instead of replacing it.
+type ConfigMerger interface {
+	ExecuteConfigMerge(srcConfigPath, dstConfigPath string) (*ConfigMergeReport, error)
+}
+
+// ConfigMergeReport lists the config settings a merge took from the source
+// and the ones it left alone because PicoClaw already customized them.
+type ConfigMergeReport struct {
+	Added []string
+	Kept  []string
+}
+
 type HandlerFactory func(opts Options) Operation
 
 type ActionType int
@@ -32,6 +46,7 @@ const (
 	ActionConvertConfig
 	ActionCreateDir
 	ActionMergeConfig
+	ActionKeep
 )
 
 type Action struct {
@@ -49,4 +64,11 @@ type Result struct {
 	DirsCreated    int
 	Warnings       []string
 	Errors         []error
+
+	// Merged is set for --merge runs. Added and Kept then list the files
+	// and config settings that were taken from the source or left as they
+	// were in PicoClaw.
+	Merged bool
+	Added  []string
+	Kept   []string
 }
@@ -11,12 +11,14 @@ import (
 )
 
 type (
-	Options        = internal.Options
-	Operation      = internal.Operation
-	ActionType     = internal.ActionType
-	Action         = internal.Action
-	Result         = internal.Result
-	HandlerFactory = internal.HandlerFactory
+	Options           = internal.Options
+	Operation         = internal.Operation
+	ConfigMerger      = internal.ConfigMerger
+	ConfigMergeReport = internal.ConfigMergeReport
+	ActionType        = internal.ActionType
+	Action            = internal.Action
+	Result            = internal.Result
+	HandlerFactory    = internal.HandlerFactory
 )
 
 const (
@@ -26,6 +28,7 @@ const (
 	ActionConvertConfig = internal.ActionConvertConfig
 	ActionCreateDir     = internal.ActionCreateDir
 	ActionMergeConfig   = internal.ActionMergeConfig
+	ActionKeep          = internal.ActionKeep
 )
 
 type MigrateInstance struct {
@@ -73,6 +76,10 @@ func (m *MigrateInstance) Run(opts Options) (*Result, error) {
 		return nil, fmt.Errorf("--config-only and --workspace-only are mutually exclusive")
 	}
 
+	if opts.Merge && opts.Refresh {
+		return nil, fmt.Errorf("--merge and --refresh are mutually exclusive")
+	}
+
 	if opts.Refresh {
 		opts.WorkspaceOnly = true
 	}
@@ -117,6 +124,7 @@ func (m *MigrateInstance) Run(opts Options) (*Result, error) {
 
 	result := m.Execute(actions, sourceHome, targetHome)
 	result.Warnings = warnings
+	result.Merged = opts.Merge
 	return result, nil
 }
 
@@ -138,12 +146,27 @@ func (m *MigrateInstance) Plan(opts Options, sourceHome, targetHome string) ([]A
 			}
 			warnings = append(warnings, fmt.Sprintf("Config migration skipped: %v", err))
 		} else {
-			actions = append(actions, Action{
-				Type:        ActionConvertConfig,
-				Source:      configPath,
-				Target:      filepath.Join(targetHome, "config.json"),
-				Description: "convert Source config to PicoClaw format",
-			})
+			target := filepath.Join(targetHome, "config.json")
+			_, mergeable := handler.(ConfigMerger)
+			if _, statErr := os.Stat(target); opts.Merge && statErr == nil && mergeable {
+				actions = append(actions, Action{
+					Type:        ActionMergeConfig,
+					Source:      configPath,
+					Target:      target,
+					Description: "merge Source config into the existing PicoClaw config",
+				})
+			} else {
+				if opts.Merge && statErr == nil {
+					warnings = append(warnings,
+						"Source does not support config merging; the PicoClaw config will be overwritten")
+				}
+				actions = append(actions, Action{
+					Type:        ActionConvertConfig,
+					Source:      configPath,
+					Target:      target,
+					Description: "convert Source config to PicoClaw format",
+				})
+			}
 		}
 	}
 
@@ -155,10 +178,17 @@ func (m *MigrateInstance) Plan(opts Options, sourceHome, targetHome string) ([]A
 		dstWorkspace := internal.ResolveWorkspace(targetHome)
 
 		if _, err := os.Stat(srcWorkspace); err == nil {
-			wsActions, err := internal.PlanWorkspaceMigration(srcWorkspace, dstWorkspace,
-				handler.GetMigrateableFiles(),
-				handler.GetMigrateableDirs(),
-				force)
+			var wsActions []Action
+			if opts.Merge {
+				wsActions, err = internal.PlanWorkspaceMerge(srcWorkspace, dstWorkspace,
+					handler.GetMigrateableFiles(),
+					handler.GetMigrateableDirs())
+			} else {
+				wsActions, err = internal.PlanWorkspaceMigration(srcWorkspace, dstWorkspace,
+					handler.GetMigrateableFiles(),
+					handler.GetMigrateableDirs(),
+					force)
+			}
 			if err != nil {
 				return nil, nil, fmt.Errorf("planning workspace migration: %w", err)
 			}
@@ -188,6 +218,32 @@ func (m *MigrateInstance) Execute(actions []Action, sourceHome, targetHome strin
 				result.ConfigMigrated = true
 				fmt.Printf("  ✓ Converted config: %s\n", action.Target)
 			}
+		case ActionMergeConfig:
+			merger, ok := handler.(ConfigMerger)
+			if !ok {
+				result.Errors = append(result.Errors, fmt.Errorf("config merge: source does not support merging"))
+				continue
+			}
+			if err := internal.CopyFile(action.Target, action.Target+".bak"); err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("backup %s: %w", action.Target, err))
+				fmt.Printf("  ✗ Backup failed: %s\n", action.Target)
+				continue
+			}
+			result.BackupsCreated++
+			report, err := merger.ExecuteConfigMerge(action.Source, action.Target)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("config merge: %w", err))
+				fmt.Printf("  ✗ Config merge failed: %v\n", err)
+				continue
+			}
+			result.ConfigMigrated = true
+			for _, field := range report.Added {
+				result.Added = append(result.Added, "config: "+field)
+			}
+			for _, field := range report.Kept {
+				result.Kept = append(result.Kept, "config: "+field)
+			}
+			fmt.Printf("  ✓ Merged config: %s\n", action.Target)
 		case ActionCreateDir:
 			if err := os.MkdirAll(action.Target, 0o755); err != nil {
 				result.Errors = append(result.Errors, err)
@@ -229,8 +285,12 @@ func (m *MigrateInstance) Execute(actions []Action, sourceHome, targetHome strin
 				fmt.Printf("  ✗ Copy failed: %s\n", action.Source)
 			} else {
 				result.FilesCopied++
+				result.Added = append(result.Added, internal.RelPath(action.Source, sourceHome))
 				fmt.Printf("  ✓ Copied %s\n", internal.RelPath(action.Source, sourceHome))
 			}
+		case ActionKeep:
+			result.FilesSkipped++
+			result.Kept = append(result.Kept, internal.RelPath(action.Source, sourceHome))
 		case ActionSkip:
 			result.FilesSkipped++
 		}
@@ -268,6 +328,11 @@ func (m *MigrateInstance) PrintSummary(result *Result) {
 		fmt.Println("Migration complete! No actions taken.")
 	}
 
+	if result.Merged {
+		printMergeList("Added from Source:", result.Added)
+		printMergeList("Kept (already customized in PicoClaw):", result.Kept)
+	}
+
 	if len(result.Errors) > 0 {
 		fmt.Println()
 		fmt.Printf("%d errors occurred:\n", len(result.Errors))
@@ -277,6 +342,17 @@ func (m *MigrateInstance) PrintSummary(result *Result) {
 	}
 }
 
+func printMergeList(title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println(title)
+	for _, item := range items {
+		fmt.Printf("  - %s\n", item)
+	}
+}
+
 func PrintPlan(actions []Action, warnings []string) {
 	fmt.Println("Planned actions:")
 	copies := 0
@@ -289,6 +365,9 @@ func PrintPlan(actions []Action, warnings []string) {
 		case ActionConvertConfig:
 			fmt.Printf("  [config]  %s -> %s\n", action.Source, action.Target)
 			configCount++
+		case ActionMergeConfig:
+			fmt.Printf("  [merge]   %s -> %s (settings PicoClaw left at defaults)\n", action.Source, action.Target)
+			configCount++
 		case ActionCopy:
 			fmt.Printf("  [copy]    %s\n", filepath.Base(action.Source))
 			copies++
@@ -301,6 +380,9 @@ func PrintPlan(actions []Action, warnings []string) {
 				fmt.Printf("  [skip]    %s (%s)\n", filepath.Base(action.Source), action.Description)
 			}
 			skips++
+		case ActionKeep:
+			fmt.Printf("  [keep]    %s (%s)\n", filepath.Base(action.Source), action.Description)
+			skips++
 		case ActionCreateDir:
 			fmt.Printf("  [mkdir]   %s\n", action.Target)
 		}
@@ -274,6 +274,46 @@ func TestMigrateInstanceExecuteSkip(t *testing.T) {
 	assert.Equal(t, 1, result.FilesSkipped)
 }
 
+func TestMigrateInstanceMergeKeepsExistingFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourceDir := filepath.Join(tmpDir, "source")
+	targetDir := filepath.Join(tmpDir, "target")
+	srcWorkspace := filepath.Join(sourceDir, "workspace")
+	dstWorkspace := filepath.Join(targetDir, "workspace")
+	require.NoError(t, os.MkdirAll(srcWorkspace, 0o755))
+	require.NoError(t, os.MkdirAll(dstWorkspace, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcWorkspace, "AGENTS.md"), []byte("source"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcWorkspace, "USER.md"), []byte("source"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dstWorkspace, "AGENTS.md"), []byte("picoclaw"), 0o644))
+
+	instance := &MigrateInstance{
+		options:  Options{Source: "mock"},
+		handlers: make(map[string]Operation),
+	}
+	instance.Register("mock", &mockOperation{
+		sourceHome:   sourceDir,
+		migrateFiles: []string{"AGENTS.md", "USER.md"},
+	})
+
+	opts := Options{Source: "mock", Merge: true, Force: true, WorkspaceOnly: true, TargetHome: targetDir}
+	result, err := instance.Run(opts)
+	require.NoError(t, err)
+
+	assert.True(t, result.Merged)
+	assert.Equal(t, []string{filepath.Join("workspace", "USER.md")}, result.Added)
+	assert.Equal(t, []string{filepath.Join("workspace", "AGENTS.md")}, result.Kept)
+
+	content, err := os.ReadFile(filepath.Join(dstWorkspace, "AGENTS.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "picoclaw", string(content))
+	_, err = os.Stat(filepath.Join(dstWorkspace, "AGENTS.md.bak"))
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = instance.Run(Options{Source: "mock", Merge: true, Refresh: true, TargetHome: targetDir})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mutually exclusive")
+}
+
 func TestMigrateInstancePrintSummary(t *testing.T) {
 	instance := NewMigrateInstance(Options{})
 
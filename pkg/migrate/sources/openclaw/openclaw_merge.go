@@ -0,0 +1,182 @@
+package openclaw
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/migrate/internal"
+)
+
+// ExecuteConfigMerge merges the OpenClaw config into the PicoClaw config at
+// dstConfigPath. A setting is taken from OpenClaw only where PicoClaw still
+// has the default value; models, agents and channels that PicoClaw lacks are
+// added. Everything PicoClaw customized is kept.
+func (o *OpenclawHandler) ExecuteConfigMerge(
+	srcConfigPath, dstConfigPath string,
+) (*internal.ConfigMergeReport, error) {
+	openclawCfg, err := LoadOpenClawConfig(srcConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	picoCfg, warnings, err := openclawCfg.ConvertToPicoClaw(o.opts.SourceHome)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range warnings {
+		fmt.Printf("  Warning: %s\n", w)
+	}
+
+	existing, err := config.LoadConfig(dstConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading PicoClaw config: %w", err)
+	}
+
+	report := mergeConfig(existing, picoCfg.ToStandardConfig(), config.DefaultConfig())
+	if len(report.Added) == 0 {
+		return report, nil
+	}
+	return report, config.SaveConfig(dstConfigPath, existing)
+}
+
+// configMerge applies incoming settings to dst and records each decision.
+type configMerge struct {
+	report internal.ConfigMergeReport
+}
+
+// mergeScalar takes incoming for field when dst still holds the default value.
+func mergeScalar[T comparable](m *configMerge, field string, dst *T, incoming, def T) {
+	var zero T
+	switch {
+	case incoming == zero || incoming == *dst:
+	case *dst == def || *dst == zero:
+		*dst = incoming
+		m.report.Added = append(m.report.Added, field)
+	default:
+		m.report.Kept = append(m.report.Kept, field)
+	}
+}
+
+func mergeConfig(dst, incoming, defaults *config.Config) *internal.ConfigMergeReport {
+	m := &configMerge{}
+
+	d, in, def := &dst.Agents.Defaults, &incoming.Agents.Defaults, &defaults.Agents.Defaults
+	mergeScalar(m, "agents.defaults.workspace", &d.Workspace, in.Workspace, def.Workspace)
+	mergeScalar(m, "agents.defaults.provider", &d.Provider, in.Provider, def.Provider)
+	mergeScalar(m, "agents.defaults.model_name", &d.ModelName, in.ModelName, def.ModelName)
+	if len(in.ModelFallbacks) > 0 {
+		if len(d.ModelFallbacks) == 0 {
+			d.ModelFallbacks = in.ModelFallbacks
+			m.report.Added = append(m.report.Added, "agents.defaults.model_fallbacks")
+		} else if !slices.Equal(d.ModelFallbacks, in.ModelFallbacks) {
+			m.report.Kept = append(m.report.Kept, "agents.defaults.model_fallbacks")
+		}
+	}
+
+	// The converted config starts from DefaultConfig, so its model list
+	// includes the built-in templates; only models from OpenClaw count.
+	isModel := func(name string) func(*config.ModelConfig) bool {
+		return func(c *config.ModelConfig) bool { return c.ModelName == name }
+	}
+	for _, model := range incoming.ModelList {
+		if slices.ContainsFunc(defaults.ModelList, isModel(model.ModelName)) {
+			continue
+		}
+		field := fmt.Sprintf("model_list[%s]", model.ModelName)
+		if slices.ContainsFunc(dst.ModelList, isModel(model.ModelName)) {
+			m.report.Kept = append(m.report.Kept, field)
+			continue
+		}
+		dst.ModelList = append(dst.ModelList, model)
+		m.report.Added = append(m.report.Added, field)
+	}
+
+	for _, agent := range incoming.Agents.List {
+		field := fmt.Sprintf("agents.list[%s]", agent.ID)
+		if slices.ContainsFunc(dst.Agents.List, func(a config.AgentConfig) bool { return a.ID == agent.ID }) {
+			m.report.Kept = append(m.report.Kept, field)
+			continue
+		}
+		dst.Agents.List = append(dst.Agents.List, agent)
+		m.report.Added = append(m.report.Added, field)
+	}
+
+	m.mergeChannels(dst, incoming, defaults)
+
+	mergeScalar(m, "gateway.host", &dst.Gateway.Host, incoming.Gateway.Host, defaults.Gateway.Host)
+	mergeScalar(m, "gateway.port", &dst.Gateway.Port, incoming.Gateway.Port, defaults.Gateway.Port)
+
+	web, inWeb := &dst.Tools.Web, &incoming.Tools.Web
+	if inWeb.Brave.Enabled {
+		m.webProvider("tools.web.brave", web.Brave.Enabled || web.Brave.APIKey() != "", func() {
+			web.Brave = inWeb.Brave
+		})
+	}
+	if inWeb.Tavily.Enabled {
+		m.webProvider("tools.web.tavily", web.Tavily.Enabled || web.Tavily.APIKey() != "", func() {
+			web.Tavily = inWeb.Tavily
+		})
+	}
+	if inWeb.Perplexity.Enabled {
+		m.webProvider("tools.web.perplexity", web.Perplexity.Enabled || web.Perplexity.APIKey() != "", func() {
+			web.Perplexity = inWeb.Perplexity
+		})
+	}
+
+	return &m.report
+}
+
+// mergeChannels adds enabled OpenClaw channels that PicoClaw has left
+// untouched: missing, or disabled with default settings.
+func (m *configMerge) mergeChannels(dst, incoming, defaults *config.Config) {
+	names := make([]string, 0, len(incoming.Channels))
+	for name, bc := range incoming.Channels {
+		if bc != nil && bc.Enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if dst.Channels == nil {
+		dst.Channels = make(config.ChannelsConfig)
+	}
+	for _, name := range names {
+		field := "channels." + name
+		current := dst.Channels[name]
+		if current != nil && (current.Enabled || !sameChannel(current, defaults.Channels[name])) {
+			m.report.Kept = append(m.report.Kept, field)
+			continue
+		}
+		dst.Channels[name] = incoming.Channels[name]
+		m.report.Added = append(m.report.Added, field)
+	}
+}
+
+func (m *configMerge) webProvider(field string, customized bool, take func()) {
+	if customized {
+		m.report.Kept = append(m.report.Kept, field)
+		return
+	}
+	take()
+	m.report.Added = append(m.report.Added, field)
+}
+
+// sameChannel compares two channel configs by their JSON form. Settings
+// are decoded first so both sides serialize the same way.
+func sameChannel(a, b *config.Channel) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if _, err := a.GetDecoded(); err != nil {
+		return false
+	}
+	if _, err := b.GetDecoded(); err != nil {
+		return false
+	}
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(da, db)
+}
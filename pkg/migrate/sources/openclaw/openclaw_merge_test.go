@@ -0,0 +1,90 @@
+package openclaw
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestMergeConfig_OnlyFillsDefaults(t *testing.T) {
+	existing := config.DefaultConfig()
+	existing.Agents.Defaults.ModelName = "local-llama"
+	existing.ModelList = []*config.ModelConfig{{ModelName: "local-llama", Model: "ollama/llama3"}}
+
+	incoming := config.DefaultConfig()
+	incoming.Agents.Defaults.ModelName = "claude"
+	incoming.ModelList = []*config.ModelConfig{
+		{ModelName: "claude", Model: "anthropic/claude-sonnet-4"},
+		{ModelName: "local-llama", Model: "ollama/llama2"},
+	}
+	incoming.Gateway.Port = 19000
+	incoming.Agents.List = []config.AgentConfig{{ID: "research"}}
+	setChannel(incoming.Channels, "telegram", map[string]any{"enabled": true, "proxy": "socks5://proxy"})
+
+	report := mergeConfig(existing, incoming, config.DefaultConfig())
+
+	assert.ElementsMatch(t, []string{
+		"model_list[claude]",
+		"agents.list[research]",
+		"channels.telegram",
+		"gateway.port",
+	}, report.Added)
+	assert.ElementsMatch(t, []string{
+		"agents.defaults.model_name",
+		"model_list[local-llama]",
+	}, report.Kept)
+
+	assert.Equal(t, "local-llama", existing.Agents.Defaults.ModelName)
+	require.Len(t, existing.ModelList, 2)
+	assert.Equal(t, "ollama/llama3", existing.ModelList[0].Model)
+	assert.Equal(t, 19000, existing.Gateway.Port)
+	assert.True(t, existing.Channels["telegram"].Enabled)
+}
+
+func TestMergeConfig_KeepsCustomizedChannel(t *testing.T) {
+	existing := config.DefaultConfig()
+	setChannel(existing.Channels, "telegram", map[string]any{"enabled": false, "proxy": "http://mine"})
+
+	incoming := config.DefaultConfig()
+	setChannel(incoming.Channels, "telegram", map[string]any{"enabled": true, "proxy": "socks5://theirs"})
+
+	report := mergeConfig(existing, incoming, config.DefaultConfig())
+
+	assert.Equal(t, []string{"channels.telegram"}, report.Kept)
+	assert.Empty(t, report.Added)
+	assert.False(t, existing.Channels["telegram"].Enabled)
+}
+
+func TestExecuteConfigMerge_PreservesExistingConfig(t *testing.T) {
+	t.Setenv("PICOCLAW_HOME", t.TempDir())
+	srcDir := t.TempDir()
+	srcConfig := filepath.Join(srcDir, "openclaw.json")
+	require.NoError(t, os.WriteFile(srcConfig, []byte(`{
+		"agents": {"list": [{"id": "research", "name": "Research"}]},
+		"channels": {"telegram": {"enabled": true, "botToken": "openclaw-token"}}
+	}`), 0o644))
+
+	dstConfig := filepath.Join(t.TempDir(), "config.json")
+	existing := config.DefaultConfig()
+	existing.Gateway.Port = 18888
+	require.NoError(t, config.SaveConfig(dstConfig, existing))
+
+	handler, err := NewOpenclawHandler(Options{SourceHome: srcDir})
+	require.NoError(t, err)
+	report, err := handler.(*OpenclawHandler).ExecuteConfigMerge(srcConfig, dstConfig)
+	require.NoError(t, err)
+	assert.Contains(t, report.Added, "agents.list[research]")
+	assert.Contains(t, report.Added, "channels.telegram")
+
+	merged, err := config.LoadConfig(dstConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 18888, merged.Gateway.Port)
+	require.Len(t, merged.Agents.List, 1)
+	assert.Equal(t, "research", merged.Agents.List[0].ID)
+	assert.True(t, merged.Channels["telegram"].Enabled)
+}
@@ -341,3 +341,27 @@ func TestReadPidFileUnlockedInvalidPID(t *testing.T) {
 		t.Error("expected error for invalid PID")
 	}
 }
+
+// TestRotateToken replaces the token of our own PID file and keeps the rest.
+func TestRotateToken(t *testing.T) {
+	dir := tmpDir(t)
+	if _, err := RotateToken(dir); err == nil {
+		t.Fatal("RotateToken without a pid file should fail")
+	}
+
+	written, err := WritePidFile(dir, "127.0.0.1", 18790)
+	if err != nil {
+		t.Fatalf("WritePidFile failed: %v", err)
+	}
+	token, err := RotateToken(dir)
+	if err != nil {
+		t.Fatalf("RotateToken failed: %v", err)
+	}
+	if len(token) != 32 || token == written.Token {
+		t.Fatalf("token = %q, want a new 32-character token", token)
+	}
+	read := ReadPidFileWithCheck(dir)
+	if read == nil || read.Token != token || read.PID != written.PID || read.Port != written.Port {
+		t.Fatalf("pid file after rotation = %+v", read)
+	}
+}
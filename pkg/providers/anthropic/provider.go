@@ -47,6 +47,7 @@ func NewProviderWithBaseURL(token, apiBase string) *Provider {
 	client := anthropic.NewClient(
 		option.WithAuthToken(token),
 		option.WithBaseURL(baseURL),
+		option.WithHTTPClient(common.NewHTTPClient("")),
 	)
 	return &Provider{
 		client:  &client,
@@ -232,6 +233,10 @@ func buildParams(
 		params.Temperature = anthropic.Float(temp)
 	}
 
+	if stop, ok := options["stop"].([]string); ok && len(stop) > 0 {
+		params.StopSequences = stop
+	}
+
 	if len(tools) > 0 {
 		params.Tools = translateTools(tools)
 	}
@@ -374,6 +379,8 @@ func parseResponse(resp *anthropic.Message) *LLMResponse {
 		finishReason = "length"
 	case anthropic.StopReasonEndTurn:
 		finishReason = "stop"
+	case anthropic.StopReasonRefusal:
+		finishReason = "content_filter"
 	}
 
 	return &LLMResponse{
@@ -381,10 +388,19 @@ func parseResponse(resp *anthropic.Message) *LLMResponse {
 		Reasoning:    reasoning.String(),
 		ToolCalls:    toolCalls,
 		FinishReason: finishReason,
-		Usage: &UsageInfo{
-			PromptTokens:     int(resp.Usage.InputTokens),
-			CompletionTokens: int(resp.Usage.OutputTokens),
-			TotalTokens:      int(resp.Usage.InputTokens + resp.Usage.OutputTokens),
-		},
+		Usage:        usageFromAnthropic(resp.Usage),
+	}
+}
+
+// usageFromAnthropic folds cache reads and writes into PromptTokens: the API
+// reports input_tokens net of them, but they still occupy the context window.
+func usageFromAnthropic(u anthropic.Usage) *UsageInfo {
+	prompt := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	return &UsageInfo{
+		PromptTokens:        int(prompt),
+		CompletionTokens:    int(u.OutputTokens),
+		TotalTokens:         int(prompt + u.OutputTokens),
+		CacheCreationTokens: int(u.CacheCreationInputTokens),
+		CacheReadTokens:     int(u.CacheReadInputTokens),
 	}
 }
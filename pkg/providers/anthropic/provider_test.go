@@ -123,6 +123,24 @@ func TestParseResponse_TextOnly(t *testing.T) {
 	}
 }
 
+func TestParseResponse_CacheUsage(t *testing.T) {
+	resp := &anthropic.Message{
+		Usage: anthropic.Usage{
+			InputTokens:              10,
+			OutputTokens:             20,
+			CacheCreationInputTokens: 500,
+			CacheReadInputTokens:     1500,
+		},
+	}
+	usage := parseResponse(resp).Usage
+	if usage.PromptTokens != 2010 || usage.TotalTokens != 2030 {
+		t.Errorf("PromptTokens/TotalTokens = %d/%d, want 2010/2030", usage.PromptTokens, usage.TotalTokens)
+	}
+	if usage.CacheCreationTokens != 500 || usage.CacheReadTokens != 1500 {
+		t.Errorf("cache tokens = %d written, %d read; want 500, 1500", usage.CacheCreationTokens, usage.CacheReadTokens)
+	}
+}
+
 func TestParseResponse_StopReasons(t *testing.T) {
 	tests := []struct {
 		stopReason anthropic.StopReason
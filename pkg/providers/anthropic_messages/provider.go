@@ -39,10 +39,12 @@ const (
 // Provider implements Anthropic Messages API via HTTP (without SDK).
 // It supports custom endpoints that use Anthropic's native message format.
 type Provider struct {
-	apiKey     string
-	apiBase    string
-	httpClient *http.Client
-	userAgent  string
+	apiKey        string
+	apiBase       string
+	httpClient    *http.Client
+	timeout       time.Duration
+	userAgent     string
+	promptCaching bool
 }
 
 // NewProvider creates a new Anthropic Messages API provider.
@@ -59,15 +61,19 @@ func NewProviderWithTimeout(apiKey, apiBase, userAgent string, timeoutSeconds in
 	}
 
 	return &Provider{
-		apiKey:    apiKey,
-		apiBase:   baseURL,
-		userAgent: userAgent,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
+		apiKey:     apiKey,
+		apiBase:    baseURL,
+		userAgent:  userAgent,
+		httpClient: common.NewHTTPClient(""),
+		timeout:    timeout,
 	}
 }
 
+// SetPromptCaching enables cache_control breakpoints on the system prompt.
+func (p *Provider) SetPromptCaching(enabled bool) {
+	p.promptCaching = enabled
+}
+
 // Chat sends messages to the Anthropic Messages API and returns the response.
 func (p *Provider) Chat(
 	ctx context.Context,
@@ -85,6 +91,9 @@ func (p *Provider) Chat(
 	if err != nil {
 		return nil, fmt.Errorf("building request body: %w", err)
 	}
+	if p.promptCaching {
+		applyPromptCaching(requestBody, messages)
+	}
 
 	// Serialize to JSON
 	jsonBody, err := json.Marshal(requestBody)
@@ -99,6 +108,8 @@ func (p *Provider) Chat(
 	}
 
 	// Create HTTP request
+	ctx, cancel := common.WithRequestTimeout(ctx, p.timeout)
+	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, "POST", endpointURL, bytes.NewReader(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("creating HTTP request: %w", err)
@@ -113,7 +124,7 @@ func (p *Provider) Chat(
 	}
 
 	// Execute request
-	resp, err := p.httpClient.Do(req)
+	resp, err := common.DoBuffered(p.httpClient, req)
 	if err != nil {
 		return nil, fmt.Errorf("executing HTTP request: %w", err)
 	}
@@ -178,6 +189,10 @@ func buildRequestBody(
 		result["temperature"] = temp
 	}
 
+	if stop, ok := options["stop"].([]string); ok && len(stop) > 0 {
+		result["stop_sequences"] = stop
+	}
+
 	// Process messages
 	var systemPrompt string
 	var apiMessages []any
@@ -294,6 +309,44 @@ func buildRequestBody(
 	return result, nil
 }
 
+// applyPromptCaching rewrites the system prompt as content blocks carrying
+// cache_control. When the agent supplied SystemParts, only the blocks it
+// marked cacheable (the static prompt) get a breakpoint; the per-request
+// runtime context and summary that follow would never produce a cache hit.
+// Without SystemParts the whole system prompt is cached as one block.
+func applyPromptCaching(body map[string]any, messages []Message) {
+	if _, ok := body["system"]; !ok {
+		return
+	}
+
+	var blocks []map[string]any
+	marked := false
+	for _, msg := range messages {
+		if msg.Role != "system" {
+			continue
+		}
+		if len(msg.SystemParts) == 0 {
+			blocks = append(blocks, map[string]any{"type": "text", "text": msg.Content})
+			continue
+		}
+		for _, part := range msg.SystemParts {
+			block := map[string]any{"type": "text", "text": part.Text}
+			if part.CacheControl != nil && part.CacheControl.Type == "ephemeral" {
+				block["cache_control"] = map[string]any{"type": "ephemeral"}
+				marked = true
+			}
+			blocks = append(blocks, block)
+		}
+	}
+	if len(blocks) == 0 {
+		return
+	}
+	if !marked {
+		blocks[len(blocks)-1]["cache_control"] = map[string]any{"type": "ephemeral"}
+	}
+	body["system"] = blocks
+}
+
 // buildTools converts tool definitions to Anthropic format.
 func buildTools(tools []ToolDefinition) []any {
 	result := make([]any, len(tools))
@@ -311,7 +364,7 @@ func buildTools(tools []ToolDefinition) []any {
 // parseResponseBody parses Anthropic Messages API response.
 func parseResponseBody(body []byte) (*LLMResponse, error) {
 	var resp anthropicMessageResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
+	if err := common.DecodeJSONResponse(body, &resp); err != nil {
 		return nil, fmt.Errorf("parsing JSON response: %w", err)
 	}
 
@@ -348,17 +401,15 @@ func parseResponseBody(body []byte) (*LLMResponse, error) {
 		finishReason = "stop"
 	case "stop_sequence":
 		finishReason = "stop"
+	case "refusal":
+		finishReason = "content_filter"
 	}
 
 	return &LLMResponse{
 		Content:      content.String(),
 		ToolCalls:    toolCalls,
 		FinishReason: finishReason,
-		Usage: &UsageInfo{
-			PromptTokens:     int(resp.Usage.InputTokens),
-			CompletionTokens: int(resp.Usage.OutputTokens),
-			TotalTokens:      int(resp.Usage.InputTokens + resp.Usage.OutputTokens),
-		},
+		Usage:        resp.Usage.toUsageInfo(),
 	}, nil
 }
 
@@ -383,6 +434,21 @@ type contentBlock struct {
 }
 
 type usageInfo struct {
-	InputTokens  int64 `json:"input_tokens"`
-	OutputTokens int64 `json:"output_tokens"`
+	InputTokens              int64 `json:"input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+}
+
+// toUsageInfo counts cached input in PromptTokens, since input_tokens
+// excludes it but it still fills the context window.
+func (u usageInfo) toUsageInfo() *UsageInfo {
+	prompt := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	return &UsageInfo{
+		PromptTokens:        int(prompt),
+		CompletionTokens:    int(u.OutputTokens),
+		TotalTokens:         int(prompt + u.OutputTokens),
+		CacheCreationTokens: int(u.CacheCreationInputTokens),
+		CacheReadTokens:     int(u.CacheReadInputTokens),
+	}
 }
@@ -8,9 +8,13 @@ package anthropicmessages
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
 func TestBuildRequestBody(t *testing.T) {
@@ -318,6 +322,34 @@ func TestParseResponseBody(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "refusal stop reason",
+			body: []byte(`{
+				"id": "msg-999",
+				"type": "message",
+				"role": "assistant",
+				"content": [],
+				"stop_reason": "refusal",
+				"model": "test-model",
+				"usage": {
+					"input_tokens": 12,
+					"output_tokens": 0
+				}
+			}`),
+			want: &LLMResponse{
+				Content:      "",
+				ToolCalls:    []ToolCall{},
+				FinishReason: "content_filter",
+				Usage: &UsageInfo{
+					PromptTokens:     12,
+					CompletionTokens: 0,
+					TotalTokens:      12,
+				},
+				Reasoning:        "",
+				ReasoningDetails: nil,
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -717,3 +749,74 @@ func TestProviderChatErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestProviderChat_PromptCaching(t *testing.T) {
+	var captured map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn",` +
+			`"usage":{"input_tokens":12,"output_tokens":5,` +
+			`"cache_creation_input_tokens":0,"cache_read_input_tokens":3000}}`))
+	}))
+	defer server.Close()
+
+	messages := []Message{
+		{
+			Role:    "system",
+			Content: "static\n\n---\n\nruntime\n\n---\n\nsummary",
+			SystemParts: []protocoltypes.ContentBlock{
+				{Type: "text", Text: "static", CacheControl: &protocoltypes.CacheControl{Type: "ephemeral"}},
+				{Type: "text", Text: "runtime"},
+				{Type: "text", Text: "summary"},
+			},
+		},
+		{Role: "user", Content: "hi"},
+	}
+	options := map[string]any{"max_tokens": 100}
+
+	provider := NewProvider("key", server.URL, "")
+	provider.SetPromptCaching(true)
+	resp, err := provider.Chat(context.Background(), messages, nil, "claude-test", options)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	system, ok := captured["system"].([]any)
+	if !ok || len(system) != 3 {
+		t.Fatalf("system = %#v, want three content blocks", captured["system"])
+	}
+	for i, want := range []bool{true, false, false} {
+		block := system[i].(map[string]any)
+		_, cached := block["cache_control"]
+		if cached != want {
+			t.Errorf("system[%d] (%v) cache_control present = %v, want %v", i, block["text"], cached, want)
+		}
+	}
+	if resp.Usage.CacheReadTokens != 3000 || resp.Usage.PromptTokens != 3012 || resp.Usage.TotalTokens != 3017 {
+		t.Errorf("Usage = %+v, want cache reads counted in prompt tokens", resp.Usage)
+	}
+
+	// Without SystemParts the whole system prompt becomes one cached block.
+	_, err = provider.Chat(context.Background(), []Message{
+		{Role: "system", Content: "plain system prompt"},
+		{Role: "user", Content: "hi"},
+	}, nil, "claude-test", options)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	system, _ = captured["system"].([]any)
+	if len(system) != 1 || system[0].(map[string]any)["cache_control"] == nil {
+		t.Fatalf("system = %#v, want one cached block", captured["system"])
+	}
+
+	// Caching stays off unless enabled, keeping the plain string form.
+	plain := NewProvider("key", server.URL, "")
+	if _, err := plain.Chat(context.Background(), messages, nil, "claude-test", options); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if _, ok := captured["system"].(string); !ok {
+		t.Fatalf("system = %#v, want plain string when caching is disabled", captured["system"])
+	}
+}
@@ -33,7 +33,7 @@ func TestNewProviderWithIdentityAndTimeout_Construction(t *testing.T) {
 	if p == nil {
 		t.Fatal("returned nil provider")
 	}
-	if p.httpClient.Timeout.Seconds() != 30 {
-		t.Errorf("timeout = %v, want 30s", p.httpClient.Timeout)
+	if p.timeout.Seconds() != 30 {
+		t.Errorf("timeout = %v, want 30s", p.timeout)
 	}
 }
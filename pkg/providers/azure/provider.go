@@ -36,6 +36,7 @@ type Provider struct {
 	apiKey      string
 	apiBase     string
 	httpClient  *http.Client
+	timeout     time.Duration // bounds each request
 	userAgent   string
 	tokenSource func(ctx context.Context) (string, error)
 }
@@ -47,7 +48,7 @@ type Option func(*Provider)
 func WithRequestTimeout(timeout time.Duration) Option {
 	return func(p *Provider) {
 		if timeout > 0 {
-			p.httpClient.Timeout = timeout
+			p.timeout = timeout
 		}
 	}
 }
@@ -74,6 +75,7 @@ func NewProvider(apiKey, apiBase, proxy, userAgent string, opts ...Option) *Prov
 		apiBase:    strings.TrimRight(apiBase, "/"),
 		userAgent:  userAgent,
 		httpClient: common.NewHTTPClient(proxy),
+		timeout:    defaultRequestTimeout,
 	}
 
 	for _, opt := range opts {
@@ -105,6 +107,7 @@ func NewProviderWithTokenSource(
 		apiBase:     strings.TrimRight(apiBase, "/"),
 		userAgent:   userAgent,
 		httpClient:  common.NewHTTPClient(proxy),
+		timeout:     defaultRequestTimeout,
 		tokenSource: tokenSource,
 	}
 
@@ -174,6 +177,8 @@ func (p *Provider) Chat(
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	ctx, cancel := common.WithRequestTimeout(ctx, p.timeout)
+	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, "POST", requestURL, bytes.NewReader(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -298,22 +298,22 @@ func TestProvider_AzureEmptyAPIBase(t *testing.T) {
 
 func TestProvider_AzureRequestTimeoutDefault(t *testing.T) {
 	p := NewProvider("test-key", "https://example.com", "", "")
-	if p.httpClient.Timeout != defaultRequestTimeout {
-		t.Errorf("timeout = %v, want %v", p.httpClient.Timeout, defaultRequestTimeout)
+	if p.timeout != defaultRequestTimeout {
+		t.Errorf("timeout = %v, want %v", p.timeout, defaultRequestTimeout)
 	}
 }
 
 func TestProvider_AzureRequestTimeoutOverride(t *testing.T) {
 	p := NewProvider("test-key", "https://example.com", "", "", WithRequestTimeout(300*time.Second))
-	if p.httpClient.Timeout != 300*time.Second {
-		t.Errorf("timeout = %v, want %v", p.httpClient.Timeout, 300*time.Second)
+	if p.timeout != 300*time.Second {
+		t.Errorf("timeout = %v, want %v", p.timeout, 300*time.Second)
 	}
 }
 
 func TestProvider_AzureNewProviderWithTimeout(t *testing.T) {
 	p := NewProviderWithTimeout("test-key", "https://example.com", "", "", 180)
-	if p.httpClient.Timeout != 180*time.Second {
-		t.Errorf("timeout = %v, want %v", p.httpClient.Timeout, 180*time.Second)
+	if p.timeout != 180*time.Second {
+		t.Errorf("timeout = %v, want %v", p.timeout, 180*time.Second)
 	}
 }
 
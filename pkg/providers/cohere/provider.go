@@ -0,0 +1,416 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package cohere
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/common"
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	ToolCall       = protocoltypes.ToolCall
+	FunctionCall   = protocoltypes.FunctionCall
+	LLMResponse    = protocoltypes.LLMResponse
+	UsageInfo      = protocoltypes.UsageInfo
+	Message        = protocoltypes.Message
+	ToolDefinition = protocoltypes.ToolDefinition
+)
+
+const (
+	defaultBaseURL = "https://api.cohere.com/v2"
+	defaultModel   = "command-a-03-2025"
+)
+
+// Provider implements Cohere's v2 Chat API.
+//
+// Cohere differs from OpenAI-compatible endpoints in how tool use is
+// represented: assistant turns carry a tool_plan alongside tool_calls, and
+// tool results are sent back as document content blocks rather than plain
+// strings.
+type Provider struct {
+	apiKey     string
+	apiBase    string
+	httpClient *http.Client
+	timeout    time.Duration
+	userAgent  string
+}
+
+// NewProvider creates a new Cohere provider.
+func NewProvider(apiKey, apiBase, proxy, userAgent string) *Provider {
+	return NewProviderWithTimeout(apiKey, apiBase, proxy, userAgent, 0)
+}
+
+// NewProviderWithTimeout creates a Cohere provider with a custom request timeout.
+func NewProviderWithTimeout(apiKey, apiBase, proxy, userAgent string, timeoutSeconds int) *Provider {
+	base := strings.TrimRight(strings.TrimSpace(apiBase), "/")
+	if base == "" {
+		base = defaultBaseURL
+	}
+	timeout := common.DefaultRequestTimeout
+	if timeoutSeconds > 0 {
+		timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+	return &Provider{
+		apiKey:     strings.TrimSpace(apiKey),
+		apiBase:    base,
+		httpClient: common.NewHTTPClient(proxy),
+		timeout:    timeout,
+		userAgent:  strings.TrimSpace(userAgent),
+	}
+}
+
+// GetDefaultModel returns the default model for this provider.
+func (p *Provider) GetDefaultModel() string {
+	return defaultModel
+}
+
+// Chat sends messages to the Cohere v2 Chat API and returns the response.
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("API key not configured")
+	}
+
+	jsonBody, err := json.Marshal(buildRequest(messages, tools, model, options))
+	if err != nil {
+		return nil, fmt.Errorf("serializing request body: %w", err)
+	}
+
+	endpointURL, err := url.JoinPath(p.apiBase, "chat")
+	if err != nil {
+		return nil, fmt.Errorf("building endpoint URL: %w", err)
+	}
+
+	ctx, cancel := common.WithRequestTimeout(ctx, p.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointURL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("creating HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	if p.userAgent != "" {
+		req.Header.Set("User-Agent", p.userAgent)
+	}
+
+	resp, err := common.DoBuffered(p.httpClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("executing HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, common.HandleErrorResponse(resp, p.apiBase)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	return parseResponse(body)
+}
+
+// buildRequest converts internal messages and tools to the Cohere v2 format.
+func buildRequest(
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) chatRequest {
+	req := chatRequest{
+		Model:    model,
+		Messages: make([]chatMessage, 0, len(messages)),
+	}
+	if maxTokens, ok := common.AsInt(options["max_tokens"]); ok && maxTokens > 0 {
+		req.MaxTokens = maxTokens
+	}
+	if temp, ok := common.AsFloat(options["temperature"]); ok {
+		req.Temperature = &temp
+	}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			req.Messages = append(req.Messages, chatMessage{Role: "system", Content: msg.Content})
+
+		case "user":
+			if msg.ToolCallID != "" {
+				req.Messages = append(req.Messages, toolResultMessage(msg))
+				continue
+			}
+			req.Messages = append(req.Messages, userMessage(msg))
+
+		case "assistant":
+			out := chatMessage{Role: "assistant"}
+			for _, tc := range msg.ToolCalls {
+				if wire, ok := serializeToolCall(tc); ok {
+					out.ToolCalls = append(out.ToolCalls, wire)
+				}
+			}
+			// Cohere expects the reasoning that precedes tool calls in
+			// tool_plan; plain assistant replies use content.
+			if len(out.ToolCalls) > 0 {
+				out.ToolPlan = msg.Content
+			} else {
+				out.Content = msg.Content
+			}
+			req.Messages = append(req.Messages, out)
+
+		case "tool":
+			req.Messages = append(req.Messages, toolResultMessage(msg))
+		}
+	}
+
+	for _, tool := range tools {
+		req.Tools = append(req.Tools, chatTool{
+			Type: "function",
+			Function: chatToolFunction{
+				Name:        tool.Function.Name,
+				Description: tool.Function.Description,
+				Parameters:  tool.Function.Parameters,
+			},
+		})
+	}
+
+	return req
+}
+
+func userMessage(msg Message) chatMessage {
+	if len(msg.Media) == 0 {
+		return chatMessage{Role: "user", Content: msg.Content}
+	}
+	parts := make([]contentPart, 0, len(msg.Media)+1)
+	if msg.Content != "" {
+		parts = append(parts, contentPart{Type: "text", Text: msg.Content})
+	}
+	for _, mediaURL := range msg.Media {
+		parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: mediaURL}})
+	}
+	return chatMessage{Role: "user", Content: parts}
+}
+
+// toolResultMessage wraps a tool result as a document block, which lets
+// Cohere cite it in the final answer.
+func toolResultMessage(msg Message) chatMessage {
+	return chatMessage{
+		Role:       "tool",
+		ToolCallID: msg.ToolCallID,
+		Content: []contentPart{{
+			Type:     "document",
+			Document: &document{Data: msg.Content},
+		}},
+	}
+}
+
+func serializeToolCall(tc ToolCall) (chatToolCall, bool) {
+	name := tc.Name
+	args := ""
+	if tc.Function != nil {
+		if name == "" {
+			name = tc.Function.Name
+		}
+		args = tc.Function.Arguments
+	}
+	if strings.TrimSpace(name) == "" {
+		return chatToolCall{}, false
+	}
+	if args == "" {
+		args = "{}"
+		if len(tc.Arguments) > 0 {
+			if encoded, err := json.Marshal(tc.Arguments); err == nil {
+				args = string(encoded)
+			}
+		}
+	}
+	return chatToolCall{
+		ID:   tc.ID,
+		Type: "function",
+		Function: chatFunctionCall{
+			Name:      name,
+			Arguments: args,
+		},
+	}, true
+}
+
+// parseResponse converts a Cohere v2 chat response into an LLMResponse.
+func parseResponse(body []byte) (*LLMResponse, error) {
+	var resp chatResponse
+	if err := common.DecodeJSONResponse(body, &resp); err != nil {
+		return nil, fmt.Errorf("parsing JSON response: %w", err)
+	}
+
+	var content, reasoning strings.Builder
+	for _, part := range resp.Message.Content {
+		switch part.Type {
+		case "text":
+			content.WriteString(part.Text)
+		case "thinking":
+			reasoning.WriteString(part.Thinking)
+		}
+	}
+
+	toolCalls := make([]ToolCall, 0, len(resp.Message.ToolCalls))
+	for _, tc := range resp.Message.ToolCalls {
+		args := common.DecodeToolCallArguments(json.RawMessage(tc.Function.Arguments), tc.Function.Name)
+		argsJSON := tc.Function.Arguments
+		if strings.TrimSpace(argsJSON) == "" {
+			argsJSON = "{}"
+		}
+		toolCalls = append(toolCalls, ToolCall{
+			ID:        tc.ID,
+			Type:      "function",
+			Name:      tc.Function.Name,
+			Arguments: args,
+			Function: &FunctionCall{
+				Name:      tc.Function.Name,
+				Arguments: argsJSON,
+			},
+		})
+	}
+
+	// When the model calls tools it explains its plan in tool_plan rather
+	// than content; surface it so the agent loop records the reasoning.
+	text := content.String()
+	if text == "" && len(toolCalls) > 0 {
+		text = resp.Message.ToolPlan
+	}
+
+	return &LLMResponse{
+		Content:          text,
+		ReasoningContent: reasoning.String(),
+		ToolCalls:        toolCalls,
+		FinishReason:     mapFinishReason(resp.FinishReason),
+		Usage:            resp.Usage.usageInfo(),
+	}, nil
+}
+
+func mapFinishReason(reason string) string {
+	switch reason {
+	case "TOOL_CALL":
+		return "tool_calls"
+	case "MAX_TOKENS":
+		return "length"
+	case "ERROR":
+		return "error"
+	default:
+		return "stop"
+	}
+}
+
+// Cohere v2 request structures
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Tools       []chatTool    `json:"tools,omitempty"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+}
+
+// chatMessage.Content is either a string or a slice of contentPart.
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    any            `json:"content,omitempty"`
+	ToolPlan   string         `json:"tool_plan,omitempty"`
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	Thinking string    `json:"thinking,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+	Document *document `json:"document,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+type document struct {
+	Data string `json:"data"`
+}
+
+type chatTool struct {
+	Type     string           `json:"type"`
+	Function chatToolFunction `json:"function"`
+}
+
+type chatToolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+type chatToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function chatFunctionCall `json:"function"`
+}
+
+type chatFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// Cohere v2 response structures
+
+type chatResponse struct {
+	ID           string `json:"id"`
+	FinishReason string `json:"finish_reason"`
+	Message      struct {
+		Role      string         `json:"role"`
+		Content   []contentPart  `json:"content"`
+		ToolPlan  string         `json:"tool_plan"`
+		ToolCalls []chatToolCall `json:"tool_calls"`
+	} `json:"message"`
+	Usage chatUsage `json:"usage"`
+}
+
+type chatUsage struct {
+	BilledUnits tokenCounts `json:"billed_units"`
+	Tokens      tokenCounts `json:"tokens"`
+}
+
+type tokenCounts struct {
+	InputTokens  float64 `json:"input_tokens"`
+	OutputTokens float64 `json:"output_tokens"`
+}
+
+// usageInfo prefers the raw token counts and falls back to billed units,
+// which exclude tokens Cohere adds for its own prompt templates.
+func (u chatUsage) usageInfo() *UsageInfo {
+	counts := u.Tokens
+	if counts.InputTokens == 0 && counts.OutputTokens == 0 {
+		counts = u.BilledUnits
+	}
+	if counts.InputTokens == 0 && counts.OutputTokens == 0 {
+		return nil
+	}
+	in, out := int(counts.InputTokens), int(counts.OutputTokens)
+	return &UsageInfo{
+		PromptTokens:     in,
+		CompletionTokens: out,
+		TotalTokens:      in + out,
+	}
+}
@@ -0,0 +1,191 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+func TestChat_ToolCallTurn(t *testing.T) {
+	var gotAuth, gotPath string
+	var gotBody map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotPath = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": "resp-1",
+			"finish_reason": "TOOL_CALL",
+			"message": {
+				"role": "assistant",
+				"tool_plan": "I will look up the weather in Paris.",
+				"tool_calls": [{
+					"id": "get_weather_abc",
+					"type": "function",
+					"function": {"name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}
+				}]
+			},
+			"usage": {
+				"billed_units": {"input_tokens": 20, "output_tokens": 8},
+				"tokens": {"input_tokens": 812, "output_tokens": 41}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-key", server.URL, "", "")
+	tools := []ToolDefinition{{
+		Type: "function",
+		Function: protocoltypes.ToolFunctionDefinition{
+			Name:        "get_weather",
+			Description: "Get weather",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"city": map[string]any{"type": "string"}},
+			},
+		},
+	}}
+	resp, err := p.Chat(context.Background(), []Message{
+		{Role: "system", Content: "You are helpful."},
+		{Role: "user", Content: "Weather in Paris?"},
+	}, tools, "command-a-03-2025", map[string]any{"max_tokens": 512})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if gotAuth != "Bearer test-key" {
+		t.Errorf("Authorization = %q, want Bearer token", gotAuth)
+	}
+	if gotPath != "/chat" {
+		t.Errorf("path = %q, want /chat", gotPath)
+	}
+	if gotBody["model"] != "command-a-03-2025" || gotBody["max_tokens"] != float64(512) {
+		t.Errorf("request model/max_tokens = %v/%v", gotBody["model"], gotBody["max_tokens"])
+	}
+	reqTools, _ := gotBody["tools"].([]any)
+	if len(reqTools) != 1 {
+		t.Fatalf("request tools = %v, want 1 tool", gotBody["tools"])
+	}
+
+	if resp.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", resp.FinishReason)
+	}
+	if resp.Content != "I will look up the weather in Paris." {
+		t.Errorf("Content = %q, want tool_plan text", resp.Content)
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("ToolCalls = %d, want 1", len(resp.ToolCalls))
+	}
+	tc := resp.ToolCalls[0]
+	if tc.ID != "get_weather_abc" || tc.Name != "get_weather" || tc.Arguments["city"] != "Paris" {
+		t.Errorf("tool call = %+v", tc)
+	}
+	if tc.Function == nil || tc.Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("tool call function = %+v", tc.Function)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 812 || resp.Usage.CompletionTokens != 41 ||
+		resp.Usage.TotalTokens != 853 {
+		t.Errorf("Usage = %+v, want 812/41/853", resp.Usage)
+	}
+}
+
+func TestBuildRequest_ToolResultRoundTrip(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: "Weather in Paris?"},
+		{
+			Role:    "assistant",
+			Content: "I will look up the weather.",
+			ToolCalls: []ToolCall{{
+				ID:        "call_1",
+				Name:      "get_weather",
+				Arguments: map[string]any{"city": "Paris"},
+			}},
+		},
+		{Role: "tool", ToolCallID: "call_1", Content: `{"temp":21}`},
+	}
+
+	raw, err := json.Marshal(buildRequest(messages, nil, "command-r", nil))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var req struct {
+		Messages []struct {
+			Role       string          `json:"role"`
+			Content    json.RawMessage `json:"content"`
+			ToolPlan   string          `json:"tool_plan"`
+			ToolCallID string          `json:"tool_call_id"`
+			ToolCalls  []chatToolCall  `json:"tool_calls"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(raw, &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(req.Messages) != 3 {
+		t.Fatalf("messages = %d, want 3", len(req.Messages))
+	}
+
+	assistant := req.Messages[1]
+	if assistant.ToolPlan != "I will look up the weather." || len(assistant.Content) != 0 {
+		t.Errorf("assistant tool_plan/content = %q/%s", assistant.ToolPlan, assistant.Content)
+	}
+	if len(assistant.ToolCalls) != 1 || assistant.ToolCalls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("assistant tool_calls = %+v", assistant.ToolCalls)
+	}
+
+	tool := req.Messages[2]
+	if tool.Role != "tool" || tool.ToolCallID != "call_1" {
+		t.Errorf("tool message role/id = %q/%q", tool.Role, tool.ToolCallID)
+	}
+	if !strings.Contains(string(tool.Content), `"type":"document"`) ||
+		!strings.Contains(string(tool.Content), `"data":"{\"temp\":21}"`) {
+		t.Errorf("tool content = %s, want document block", tool.Content)
+	}
+}
+
+func TestChat_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"message":"rate limited"}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-key", server.URL, "", "")
+	_, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "command-r", nil)
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("Chat() error = %v, want status 429", err)
+	}
+}
+
+func TestParseResponse_TextAndBilledUsageFallback(t *testing.T) {
+	resp, err := parseResponse([]byte(`{
+		"finish_reason": "COMPLETE",
+		"message": {"role": "assistant", "content": [
+			{"type": "thinking", "thinking": "hmm"},
+			{"type": "text", "text": "Hello"}
+		]},
+		"usage": {"billed_units": {"input_tokens": 5, "output_tokens": 2}}
+	}`))
+	if err != nil {
+		t.Fatalf("parseResponse() error = %v", err)
+	}
+	if resp.Content != "Hello" || resp.ReasoningContent != "hmm" || resp.FinishReason != "stop" {
+		t.Errorf("resp = %+v", resp)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 7 {
+		t.Errorf("Usage = %+v, want billed units fallback", resp.Usage)
+	}
+}
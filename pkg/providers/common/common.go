@@ -8,14 +8,12 @@
 package common
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
@@ -38,30 +36,11 @@ type (
 
 const DefaultRequestTimeout = 120 * time.Second
 
-// NewHTTPClient creates an *http.Client with an optional proxy and the default timeout.
+// NewHTTPClient creates an *http.Client on the shared transport for proxy.
+// The client has no overall timeout so it can carry streaming responses;
+// bound non-streaming calls with WithRequestTimeout.
 func NewHTTPClient(proxy string) *http.Client {
-	client := &http.Client{
-		Timeout: DefaultRequestTimeout,
-	}
-	if proxy != "" {
-		parsed, err := url.Parse(proxy)
-		if err == nil {
-			// Preserve http.DefaultTransport settings (TLS, HTTP/2, timeouts, etc.)
-			if base, ok := http.DefaultTransport.(*http.Transport); ok {
-				tr := base.Clone()
-				tr.Proxy = http.ProxyURL(parsed)
-				client.Transport = tr
-			} else {
-				// Fallback: minimal transport if DefaultTransport is not *http.Transport.
-				client.Transport = &http.Transport{
-					Proxy: http.ProxyURL(parsed),
-				}
-			}
-		} else {
-			log.Printf("common: invalid proxy URL %q: %v", proxy, err)
-		}
-	}
-	return client
+	return &http.Client{Transport: SharedTransport(proxy)}
 }
 
 // --- Message serialization ---
@@ -234,6 +213,7 @@ func ParseResponse(body io.Reader) (*LLMResponse, error) {
 		Choices []struct {
 			Message struct {
 				Content          string            `json:"content"`
+				Refusal          string            `json:"refusal"`
 				ReasoningContent string            `json:"reasoning_content"`
 				Reasoning        string            `json:"reasoning"`
 				ReasoningDetails []ReasoningDetail `json:"reasoning_details"`
@@ -255,11 +235,15 @@ func ParseResponse(body io.Reader) (*LLMResponse, error) {
 			} `json:"message"`
 			FinishReason string `json:"finish_reason"`
 		} `json:"choices"`
-		Usage *UsageInfo `json:"usage"`
+		Usage *APIUsage `json:"usage"`
 	}
 
-	if err := json.NewDecoder(body).Decode(&apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if err := DecodeJSONResponse(data, &apiResponse); err != nil {
+		return nil, err
 	}
 
 	if len(apiResponse.Choices) == 0 {
@@ -272,6 +256,9 @@ func ParseResponse(body io.Reader) (*LLMResponse, error) {
 	choice := apiResponse.Choices[0]
 	toolCalls := make([]ToolCall, 0, len(choice.Message.ToolCalls))
 	for _, tc := range choice.Message.ToolCalls {
+		if !IsFunctionToolCall(tc.Type) {
+			continue
+		}
 		arguments := make(map[string]any)
 		name := ""
 
@@ -315,17 +302,61 @@ func ParseResponse(body io.Reader) (*LLMResponse, error) {
 		toolCalls = append(toolCalls, toolCall)
 	}
 
+	// A model refusal arrives in "refusal" instead of "content"; report it the
+	// way filtered output is reported so callers handle both alike.
+	content, finishReason := choice.Message.Content, normalizeFinishReason(choice.FinishReason)
+	if refusal := strings.TrimSpace(choice.Message.Refusal); refusal != "" && strings.TrimSpace(content) == "" {
+		content, finishReason = refusal, "content_filter"
+	}
+
 	return &LLMResponse{
-		Content:          choice.Message.Content,
+		Content:          content,
 		ReasoningContent: choice.Message.ReasoningContent,
 		Reasoning:        choice.Message.Reasoning,
 		ReasoningDetails: choice.Message.ReasoningDetails,
 		ToolCalls:        toolCalls,
-		FinishReason:     normalizeFinishReason(choice.FinishReason),
-		Usage:            apiResponse.Usage,
+		FinishReason:     finishReason,
+		Usage:            apiResponse.Usage.Info(),
 	}, nil
 }
 
+// APIUsage is the usage object of an OpenAI-compatible response. Cache hits
+// and reasoning tokens arrive in nested detail objects, as reported by OpenAI,
+// Zhipu GLM and others.
+type APIUsage struct {
+	UsageInfo
+	PromptTokensDetails *struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+	CompletionTokensDetails *struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+}
+
+// Info flattens the usage details into a UsageInfo. It returns nil for a nil
+// receiver so a response without usage stays without usage.
+func (u *APIUsage) Info() *UsageInfo {
+	if u == nil {
+		return nil
+	}
+	info := u.UsageInfo
+	if u.PromptTokensDetails != nil && info.CacheReadTokens == 0 {
+		info.CacheReadTokens = u.PromptTokensDetails.CachedTokens
+	}
+	if u.CompletionTokensDetails != nil && info.ReasoningTokens == 0 {
+		info.ReasoningTokens = u.CompletionTokensDetails.ReasoningTokens
+	}
+	return &info
+}
+
+// IsFunctionToolCall reports whether a tool call of the given type is one the
+// agent should execute. Zhipu GLM also lists its built-in web_search and
+// retrieval runs in tool_calls; those already ran server-side and carry no
+// function.
+func IsFunctionToolCall(callType string) bool {
+	return callType == "" || callType == "function"
+}
+
 // normalizeFinishReason normalizes finish_reason values across providers.
 // Converts "length" to "truncated" for consistent handling.
 func normalizeFinishReason(reason string) string {
@@ -388,21 +419,20 @@ func HandleErrorResponse(resp *http.Response, apiBase string) error {
 	)
 }
 
-// ReadAndParseResponse peeks at the response body to detect HTML errors,
+// ReadAndParseResponse reads the response body, reports HTML error pages,
 // then parses the JSON response into an LLMResponse.
 func ReadAndParseResponse(resp *http.Response, apiBase string) (*LLMResponse, error) {
 	contentType := resp.Header.Get("Content-Type")
-	reader := bufio.NewReader(resp.Body)
-	prefix, err := reader.Peek(256)
-	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
-		return nil, fmt.Errorf("failed to inspect response: %w", err)
+	body, err := ReadResponseBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
-	if LooksLikeHTML(prefix, contentType) {
-		return nil, WrapHTMLResponseError(resp.StatusCode, prefix, contentType, apiBase)
+	if LooksLikeHTML(body, contentType) {
+		return nil, WrapHTMLResponseError(resp.StatusCode, body, contentType, apiBase)
 	}
-	out, err := ParseResponse(reader)
+	out, err := ParseResponse(bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 	return out, nil
 }
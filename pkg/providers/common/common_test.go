@@ -1,22 +1,26 @@
 package common
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
 // --- NewHTTPClient tests ---
 
-func TestNewHTTPClient_DefaultTimeout(t *testing.T) {
+func TestNewHTTPClient_NoClientTimeout(t *testing.T) {
+	// A client-wide timeout would also cut off streaming bodies; requests are
+	// bounded through their context instead.
 	client := NewHTTPClient("")
-	if client.Timeout != DefaultRequestTimeout {
-		t.Errorf("timeout = %v, want %v", client.Timeout, DefaultRequestTimeout)
+	if client.Timeout != 0 {
+		t.Errorf("timeout = %v, want 0", client.Timeout)
 	}
 }
 
@@ -36,10 +40,51 @@ func TestNewHTTPClient_WithProxy(t *testing.T) {
 	}
 }
 
-func TestNewHTTPClient_NoProxy(t *testing.T) {
-	client := NewHTTPClient("")
-	if client.Transport != nil {
-		t.Errorf("expected nil transport without proxy, got %T", client.Transport)
+func TestNewHTTPClient_SharesTransportPerProxy(t *testing.T) {
+	direct := NewHTTPClient("")
+	if direct.Transport == nil || direct.Transport != NewHTTPClient("").Transport {
+		t.Fatal("clients without a proxy should share one transport")
+	}
+	proxied := NewHTTPClient("http://127.0.0.1:8080")
+	if proxied.Transport != NewHTTPClient("http://127.0.0.1:8080").Transport {
+		t.Fatal("clients with the same proxy should share one transport")
+	}
+	if proxied.Transport == direct.Transport {
+		t.Fatal("proxied and direct clients must not share a transport")
+	}
+
+	tr := direct.Transport.(*http.Transport)
+	if !tr.ForceAttemptHTTP2 || tr.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost ||
+		tr.IdleConnTimeout != DefaultIdleConnTimeout {
+		t.Errorf("transport = HTTP2 %v, per host %d, idle %v; want tuned defaults",
+			tr.ForceAttemptHTTP2, tr.MaxIdleConnsPerHost, tr.IdleConnTimeout)
+	}
+}
+
+func TestConfigureTransport_AppliesToNewClients(t *testing.T) {
+	t.Cleanup(func() { ConfigureTransport(TransportOptions{}) })
+	before := NewHTTPClient("").Transport
+
+	ConfigureTransport(TransportOptions{MaxIdleConnsPerHost: 200, IdleConnTimeout: 5 * time.Second})
+	tr := NewHTTPClient("").Transport.(*http.Transport)
+	if tr == before {
+		t.Fatal("ConfigureTransport should build a new transport")
+	}
+	if tr.MaxIdleConnsPerHost != 200 || tr.MaxIdleConns != 200 || tr.IdleConnTimeout != 5*time.Second {
+		t.Errorf("transport = per host %d, total %d, idle %v", tr.MaxIdleConnsPerHost, tr.MaxIdleConns, tr.IdleConnTimeout)
+	}
+}
+
+func TestWithRequestTimeout(t *testing.T) {
+	ctx, cancel := WithRequestTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("expected a deadline")
+	}
+	ctx, cancel = WithRequestTimeout(context.Background(), 0)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("zero timeout should not set a deadline")
 	}
 }
 
@@ -290,6 +335,20 @@ func TestParseResponse_EmptyChoices(t *testing.T) {
 	}
 }
 
+func TestParseResponse_RefusalReportedAsContentFilter(t *testing.T) {
+	body := `{"choices":[{"message":{"content":null,"refusal":"I can't help with that."},"finish_reason":"stop"}]}`
+	out, err := ParseResponse(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("ParseResponse() error = %v", err)
+	}
+	if out.Content != "I can't help with that." {
+		t.Errorf("Content = %q, want the refusal text", out.Content)
+	}
+	if out.FinishReason != "content_filter" {
+		t.Errorf("FinishReason = %q, want %q", out.FinishReason, "content_filter")
+	}
+}
+
 func TestParseResponse_WithToolCalls(t *testing.T) {
 	body := `{"choices":[{"message":{"content":"","tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"SF\"}"}}]},"finish_reason":"tool_calls"}]}`
 	out, err := ParseResponse(strings.NewReader(body))
@@ -321,6 +380,36 @@ func TestParseResponse_WithUsage(t *testing.T) {
 	}
 }
 
+func TestParseResponse_UsageDetails(t *testing.T) {
+	body := `{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}],"usage":{"prompt_tokens":100,` +
+		`"completion_tokens":40,"total_tokens":140,"prompt_tokens_details":{"cached_tokens":64},` +
+		`"completion_tokens_details":{"reasoning_tokens":30}}}`
+	out, err := ParseResponse(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("ParseResponse() error = %v", err)
+	}
+	if out.Usage == nil || out.Usage.CacheReadTokens != 64 || out.Usage.ReasoningTokens != 30 {
+		t.Fatalf("Usage = %#v, want CacheReadTokens 64 and ReasoningTokens 30", out.Usage)
+	}
+}
+
+func TestParseResponse_SkipsBuiltinToolCalls(t *testing.T) {
+	body := `{"choices":[{"message":{"content":"","tool_calls":[` +
+		`{"id":"ws_1","type":"web_search","web_search":{"search_result":[{"title":"t","link":"https://example.com"}]}},` +
+		`{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":{"city":"Beijing"}}}]},` +
+		`"finish_reason":"tool_calls"}]}`
+	out, err := ParseResponse(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("ParseResponse() error = %v", err)
+	}
+	if len(out.ToolCalls) != 1 || out.ToolCalls[0].Name != "get_weather" {
+		t.Fatalf("ToolCalls = %#v, want only get_weather", out.ToolCalls)
+	}
+	if out.ToolCalls[0].Arguments["city"] != "Beijing" {
+		t.Errorf("Arguments[city] = %v, want Beijing", out.ToolCalls[0].Arguments["city"])
+	}
+}
+
 func TestParseResponse_WithReasoningContent(t *testing.T) {
 	body := `{"choices":[{"message":{"content":"2","reasoning_content":"Let me think... 1+1=2"},"finish_reason":"stop"}]}`
 	out, err := ParseResponse(strings.NewReader(body))
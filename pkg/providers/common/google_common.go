@@ -22,6 +22,15 @@ func NormalizeStoredToolCall(tc protocoltypes.ToolCall) (string, map[string]any,
 		thoughtSignature = tc.Function.ThoughtSignature
 	}
 
+	if thoughtSignature == "" {
+		// Signatures parsed in this process may only be on the top level or
+		// in the Google extra content.
+		thoughtSignature = tc.ThoughtSignature
+		if thoughtSignature == "" && tc.ExtraContent != nil && tc.ExtraContent.Google != nil {
+			thoughtSignature = tc.ExtraContent.Google.ThoughtSignature
+		}
+	}
+
 	if args == nil {
 		args = map[string]any{}
 	}
@@ -144,3 +144,16 @@ func TestInferToolNameFromCallID(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeStoredToolCall_SignatureOutsideFunction(t *testing.T) {
+	for _, tc := range []protocoltypes.ToolCall{
+		{Name: "search", ThoughtSignature: "sig-top"},
+		{Name: "search", ExtraContent: &protocoltypes.ExtraContent{
+			Google: &protocoltypes.GoogleExtra{ThoughtSignature: "sig-top"},
+		}},
+	} {
+		if _, _, sig := NormalizeStoredToolCall(tc); sig != "sig-top" {
+			t.Errorf("thoughtSignature = %q, want %q", sig, "sig-top")
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// MaxResponseBodyBytes caps how much of a provider response is buffered.
+const MaxResponseBodyBytes = 32 << 20
+
+var (
+	// ErrIncompleteResponse reports a response body that ended before it was
+	// complete, typically because the connection dropped mid-response.
+	ErrIncompleteResponse = errors.New("connection dropped mid-response")
+	// ErrInvalidResponseJSON reports a complete response body that is not
+	// valid JSON of the expected shape.
+	ErrInvalidResponseJSON = errors.New("invalid JSON in response")
+	// ErrProviderErrorResponse reports a response that carries an error
+	// object instead of a result.
+	ErrProviderErrorResponse = errors.New("provider returned an error")
+)
+
+// DoBuffered sends req with client and reads the whole response body with
+// ReadResponseBody. The returned response's body is the buffered copy, so
+// callers read and close it as usual. An error status is returned even when
+// its body cannot be read, since the status says what went wrong.
+//
+// When a successful response is cut off, because the connection dropped
+// while the body was read or the JSON in it ends early, the request is sent
+// once more: a chat request has no side effects, so repeating it is safe.
+// This needs req.GetBody, which http.NewRequest sets for in-memory bodies.
+func DoBuffered(client *http.Client, req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ReadResponseBody(resp)
+		resp.Body.Close()
+		ok := resp.StatusCode/100 == 2
+		if err == nil && ok && looksLikeJSON(body) && incompleteJSON(body) {
+			err = fmt.Errorf("%w: JSON ends after %d bytes", ErrIncompleteResponse, len(body))
+		}
+		if err == nil || !ok {
+			// The buffered body is already decoded, as net/http does for
+			// gzip it asked for.
+			if resp.Header.Get("Content-Encoding") != "" {
+				resp.Header.Del("Content-Encoding")
+				resp.Header.Del("Content-Length")
+				resp.ContentLength = -1
+				resp.Uncompressed = true
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			return resp, nil
+		}
+		if attempt > 0 || !errors.Is(err, ErrIncompleteResponse) || req.GetBody == nil || req.Context().Err() != nil {
+			return nil, err
+		}
+		log.Printf("common: %s %s: %v; retrying once", req.Method, req.URL.Redacted(), err)
+		retry := req.Clone(req.Context())
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+		req = retry
+	}
+}
+
+var errUnsupportedEncoding = errors.New("unsupported response content-encoding")
+
+// ReadResponseBody reads the whole body of resp, undoing gzip or deflate
+// content encoding, up to MaxResponseBodyBytes. A body that breaks off is
+// reported as ErrIncompleteResponse, unless what arrived is already a
+// complete JSON document.
+func ReadResponseBody(resp *http.Response) ([]byte, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	reader, err := decodeContent(resp.Body, encoding)
+	var body []byte
+	if err == nil {
+		body, err = io.ReadAll(io.LimitReader(reader, MaxResponseBodyBytes+1))
+	}
+	switch {
+	case err == nil:
+		if len(body) > MaxResponseBodyBytes {
+			return nil, fmt.Errorf("response body exceeds %d MB", MaxResponseBodyBytes>>20)
+		}
+		return body, nil
+	case errors.Is(err, errUnsupportedEncoding):
+		return nil, err
+	case isCorruptContent(err):
+		return nil, fmt.Errorf("invalid %s response body: %w", encoding, err)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, fmt.Errorf("reading response body: %w", err)
+	case looksLikeJSON(body) && json.Valid(body):
+		// The connection failed only after a complete JSON document.
+		return body, nil
+	}
+	return nil, fmt.Errorf("%w after %d bytes: %w", ErrIncompleteResponse, len(body), err)
+}
+
+// decodeContent wraps body to undo the given Content-Encoding. The HTTP
+// client already undoes gzip it asked for itself; this covers providers and
+// proxies that compress without being asked, or with deflate.
+func decodeContent(body io.Reader, encoding string) (io.Reader, error) {
+	switch encoding {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(body)
+	case "deflate":
+		// "deflate" is meant to be zlib-wrapped, but some servers send a raw
+		// deflate stream; the zlib header tells the two apart.
+		br := bufio.NewReader(body)
+		header, err := br.Peek(2)
+		if err != nil {
+			return nil, err
+		}
+		if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	default:
+		return nil, fmt.Errorf("%w %q", errUnsupportedEncoding, encoding)
+	}
+}
+
+func isCorruptContent(err error) bool {
+	var corrupt flate.CorruptInputError
+	return errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) ||
+		errors.Is(err, zlib.ErrHeader) || errors.Is(err, zlib.ErrChecksum) ||
+		errors.As(err, &corrupt)
+}
+
+// DecodeJSONResponse unmarshals a buffered response body into v. Failures
+// tell apart a body cut short (ErrIncompleteResponse), an error object sent
+// by the provider (ErrProviderErrorResponse) and anything else that does not
+// decode (ErrInvalidResponseJSON).
+func DecodeJSONResponse(body []byte, v any) error {
+	if message, ok := ProviderErrorMessage(body); ok {
+		return fmt.Errorf("%w: %s", ErrProviderErrorResponse, message)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		if incompleteJSON(body) {
+			return fmt.Errorf("%w: JSON ends after %d bytes", ErrIncompleteResponse, len(body))
+		}
+		return fmt.Errorf("%w: %w (body: %s)", ErrInvalidResponseJSON, err, ResponsePreview(body, 128))
+	}
+	return nil
+}
+
+// ProviderErrorMessage reports whether body is an error object, such as
+// OpenAI's or Gemini's {"error": {"message": ...}} or Anthropic's
+// {"type": "error", ...}, and returns its message.
+func ProviderErrorMessage(body []byte) (string, bool) {
+	var envelope struct {
+		Type  string          `json:"type"`
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) != nil {
+		return "", false
+	}
+	raw := bytes.TrimSpace(envelope.Error)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", false
+	}
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text, text != ""
+	}
+	var detail struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Status  string `json:"status"`
+		Code    any    `json:"code"`
+	}
+	if json.Unmarshal(raw, &detail) != nil {
+		return "", false
+	}
+	var parts []string
+	for _, part := range []string{detail.Type, detail.Status} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if detail.Code != nil && detail.Code != "" {
+		parts = append(parts, fmt.Sprint(detail.Code))
+	}
+	if detail.Message == "" && len(parts) == 0 {
+		if envelope.Type != "error" {
+			return "", false
+		}
+		return ResponsePreview(raw, 128), true
+	}
+	if len(parts) == 0 {
+		return detail.Message, true
+	}
+	return fmt.Sprintf("%s (%s)", detail.Message, strings.Join(parts, ", ")), true
+}
+
+func looksLikeJSON(body []byte) bool {
+	trimmed := leadingTrimmedPrefix(body, 1)
+	return len(trimmed) == 1 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// incompleteJSON reports whether body is empty or a JSON value that breaks
+// off before its end.
+func incompleteJSON(body []byte) bool {
+	var raw json.RawMessage
+	err := json.NewDecoder(bytes.NewReader(body)).Decode(&raw)
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
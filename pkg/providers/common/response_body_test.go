@@ -0,0 +1,214 @@
+package common
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const okBody = `{"choices":[{"message":{"content":"hello"},"finish_reason":"stop"}]}`
+
+func compressed(t *testing.T, encoding string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "flate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	if _, err := w.Write([]byte(okBody)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func bodyResponse(encoding string, body io.Reader) *http.Response {
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(body)}
+}
+
+// droppingReader returns data and then fails as a reset connection would.
+type droppingReader struct {
+	data []byte
+}
+
+func (r *droppingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, errors.New("read tcp 127.0.0.1:443: connection reset by peer")
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestReadResponseBody_ContentEncodings(t *testing.T) {
+	tests := []struct {
+		header string
+		body   []byte
+	}{
+		{"", []byte(okBody)},
+		{"gzip", compressed(t, "gzip")},
+		{"deflate", compressed(t, "zlib")},
+		{"deflate", compressed(t, "flate")},
+	}
+	for _, tt := range tests {
+		got, err := ReadResponseBody(bodyResponse(tt.header, bytes.NewReader(tt.body)))
+		if err != nil {
+			t.Fatalf("%q: ReadResponseBody() error = %v", tt.header, err)
+		}
+		if string(got) != okBody {
+			t.Fatalf("%q: body = %q", tt.header, got)
+		}
+	}
+
+	if _, err := ReadResponseBody(bodyResponse("br", strings.NewReader("x"))); err == nil ||
+		!strings.Contains(err.Error(), `unsupported response content-encoding "br"`) {
+		t.Fatalf("br error = %v", err)
+	}
+}
+
+func TestReadResponseBody_Truncated(t *testing.T) {
+	gz := compressed(t, "gzip")
+	_, err := ReadResponseBody(bodyResponse("gzip", bytes.NewReader(gz[:len(gz)/2])))
+	if !errors.Is(err, ErrIncompleteResponse) {
+		t.Fatalf("truncated gzip error = %v, want ErrIncompleteResponse", err)
+	}
+
+	_, err = ReadResponseBody(bodyResponse("", &droppingReader{data: []byte(okBody[:20])}))
+	if !errors.Is(err, ErrIncompleteResponse) || !strings.Contains(err.Error(), "after 20 bytes") {
+		t.Fatalf("dropped connection error = %v", err)
+	}
+
+	// A connection that fails after a complete document loses nothing.
+	got, err := ReadResponseBody(bodyResponse("", &droppingReader{data: []byte(okBody)}))
+	if err != nil || string(got) != okBody {
+		t.Fatalf("complete body = %q, %v", got, err)
+	}
+
+	_, err = ReadResponseBody(bodyResponse("gzip", strings.NewReader("not gzip at all")))
+	if err == nil || errors.Is(err, ErrIncompleteResponse) {
+		t.Fatalf("corrupt gzip error = %v, want a decoding error", err)
+	}
+}
+
+func TestDecodeJSONResponse_ClassifiesFailures(t *testing.T) {
+	var v map[string]any
+	tests := []struct {
+		body string
+		want error
+		text string
+	}{
+		{okBody[:30], ErrIncompleteResponse, "JSON ends after 30 bytes"},
+		{"", ErrIncompleteResponse, "JSON ends after 0 bytes"},
+		{`{"choices": nope}`, ErrInvalidResponseJSON, "invalid character"},
+		{
+			`{"error":{"message":"Rate limit reached","type":"rate_limit_error","code":429}}`,
+			ErrProviderErrorResponse, "Rate limit reached (rate_limit_error, 429)",
+		},
+		{
+			`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			ErrProviderErrorResponse, "Overloaded (overloaded_error)",
+		},
+		{`{"error":"model not found"}`, ErrProviderErrorResponse, "model not found"},
+	}
+	for _, tt := range tests {
+		err := DecodeJSONResponse([]byte(tt.body), &v)
+		if !errors.Is(err, tt.want) || !strings.Contains(err.Error(), tt.text) {
+			t.Errorf("DecodeJSONResponse(%q) = %v, want %v containing %q", tt.body, err, tt.want, tt.text)
+		}
+	}
+
+	if err := DecodeJSONResponse([]byte(`{"error":null,"choices":[]}`), &v); err != nil {
+		t.Fatalf("a null error field is not an error: %v", err)
+	}
+}
+
+func TestParseResponse_ErrorObjectIsNotAnEmptyReply(t *testing.T) {
+	_, err := ParseResponse(strings.NewReader(`{"error":{"message":"upstream timeout","code":504}}`))
+	if !errors.Is(err, ErrProviderErrorResponse) {
+		t.Fatalf("ParseResponse() error = %v, want ErrProviderErrorResponse", err)
+	}
+}
+
+func TestDoBuffered_RetriesOnceAfterDrop(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, _ := io.ReadAll(r.Body); string(got) != "request" {
+			t.Errorf("attempt %d body = %q", calls.Load()+1, got)
+		}
+		if calls.Add(1) == 1 {
+			w.Header().Set("Content-Length", "1000")
+			_, _ = w.Write([]byte(okBody[:40]))
+			return // the server closes the connection short of Content-Length
+		}
+		_, _ = w.Write([]byte(okBody))
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("request"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := DoBuffered(server.Client(), req)
+	if err != nil {
+		t.Fatalf("DoBuffered() error = %v", err)
+	}
+	defer resp.Body.Close()
+	got, _ := io.ReadAll(resp.Body)
+	if string(got) != okBody || calls.Load() != 2 {
+		t.Fatalf("body = %q after %d calls, want the full body after 2", got, calls.Load())
+	}
+}
+
+func TestDoBuffered_GivesUpAfterSecondDrop(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(okBody[:40])) // complete HTTP response, cut-off JSON
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("request"))
+	_, err := DoBuffered(server.Client(), req)
+	if !errors.Is(err, ErrIncompleteResponse) || calls.Load() != 2 {
+		t.Fatalf("DoBuffered() = %v after %d calls, want ErrIncompleteResponse after 2", err, calls.Load())
+	}
+}
+
+func TestDoBuffered_ErrorStatusIsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"slow down"}}`))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("request"))
+	resp, err := DoBuffered(server.Client(), req)
+	if err != nil {
+		t.Fatalf("DoBuffered() error = %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+		t.Fatalf("status %d after %d calls", resp.StatusCode, calls.Load())
+	}
+}
@@ -0,0 +1,113 @@
+package common
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Default connection pool settings for provider HTTP transports. Go's stock
+// transport keeps only 2 idle connections per host, so concurrent sessions
+// talking to one API would keep opening new TLS connections.
+const (
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 32
+	DefaultIdleConnTimeout     = 90 * time.Second
+)
+
+// TransportOptions tunes the shared provider transports. Zero values use the
+// defaults above.
+type TransportOptions struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+}
+
+var transports = struct {
+	sync.Mutex
+	opts   TransportOptions
+	byHost map[string]*http.Transport
+}{byHost: make(map[string]*http.Transport)}
+
+// ConfigureTransport sets the pool settings for transports created from now
+// on. Clients that already hold a transport keep it and its idle connections.
+func ConfigureTransport(opts TransportOptions) {
+	transports.Lock()
+	defer transports.Unlock()
+	if opts == transports.opts {
+		return
+	}
+	transports.opts = opts
+	transports.byHost = make(map[string]*http.Transport)
+}
+
+// SharedTransport returns the pooled transport for proxy ("" for a direct
+// connection). Every provider client using the same proxy shares one
+// transport, so keep-alive connections are reused across providers, models
+// and sessions. An invalid proxy URL is logged and ignored.
+func SharedTransport(proxy string) *http.Transport {
+	var proxyURL *url.URL
+	if proxy != "" {
+		parsed, err := url.Parse(proxy)
+		if err != nil {
+			log.Printf("common: invalid proxy URL %q: %v", proxy, err)
+			proxy = ""
+		} else {
+			proxyURL = parsed
+		}
+	}
+
+	transports.Lock()
+	defer transports.Unlock()
+	if tr, ok := transports.byHost[proxy]; ok {
+		return tr
+	}
+	tr := newTunedTransport(transports.opts)
+	if proxyURL != nil {
+		tr.Proxy = http.ProxyURL(proxyURL)
+	}
+	transports.byHost[proxy] = tr
+	return tr
+}
+
+func newTunedTransport(opts TransportOptions) *http.Transport {
+	var tr *http.Transport
+	// Preserve http.DefaultTransport settings (TLS, dial timeouts, proxy from
+	// environment) and only adjust pooling.
+	if base, ok := http.DefaultTransport.(*http.Transport); ok {
+		tr = base.Clone()
+	} else {
+		tr = &http.Transport{Proxy: http.ProxyFromEnvironment}
+	}
+	tr.ForceAttemptHTTP2 = true
+	tr.MaxIdleConns = DefaultMaxIdleConns
+	if opts.MaxIdleConns > 0 {
+		tr.MaxIdleConns = opts.MaxIdleConns
+	}
+	tr.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	if opts.MaxIdleConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	}
+	if tr.MaxIdleConns < tr.MaxIdleConnsPerHost {
+		tr.MaxIdleConns = tr.MaxIdleConnsPerHost
+	}
+	tr.IdleConnTimeout = DefaultIdleConnTimeout
+	if opts.IdleConnTimeout > 0 {
+		tr.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	return tr
+}
+
+// WithRequestTimeout bounds one non-streaming request. Timeouts belong on the
+// request context rather than http.Client.Timeout, which also covers body
+// reads and would cut off long streams sharing the same client. A
+// non-positive timeout leaves ctx unchanged.
+func WithRequestTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,57 @@
+package providers
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrContentPolicy reports that the model refused a request or the provider
+// blocked it for content policy or safety reasons. Match it with errors.Is;
+// the concrete error is a *ContentPolicyError.
+var ErrContentPolicy = errors.New("model declined the request (content policy)")
+
+// ContentPolicyError is a provider refusal. Reason holds the refusal text or
+// block reason the provider gave, if any.
+type ContentPolicyError struct {
+	Reason string
+}
+
+func (e *ContentPolicyError) Error() string {
+	if e.Reason == "" {
+		return ErrContentPolicy.Error()
+	}
+	return ErrContentPolicy.Error() + ": " + e.Reason
+}
+
+// Is makes errors.Is(err, ErrContentPolicy) match.
+func (e *ContentPolicyError) Is(target error) bool {
+	return target == ErrContentPolicy
+}
+
+// CheckContentPolicy returns a *ContentPolicyError when resp ended on a
+// content filter without requesting tools, and nil otherwise. Providers
+// report refusals and safety blocks as finish reason "content_filter"; any
+// text that came with it is kept as the reason.
+func CheckContentPolicy(resp *LLMResponse) error {
+	if resp == nil || resp.FinishReason != "content_filter" || len(resp.ToolCalls) > 0 {
+		return nil
+	}
+	return &ContentPolicyError{Reason: strings.TrimSpace(resp.Content)}
+}
+
+// AsContentPolicyError reports whether err is a content policy refusal,
+// either a *ContentPolicyError or a provider HTTP error the classifier
+// recognizes as a policy block.
+func AsContentPolicyError(err error) (*ContentPolicyError, bool) {
+	if err == nil {
+		return nil, false
+	}
+	var refusal *ContentPolicyError
+	if errors.As(err, &refusal) {
+		return refusal, true
+	}
+	if failErr := ClassifyError(err, "", ""); failErr != nil && failErr.Reason == FailoverContentPolicy {
+		return &ContentPolicyError{}, true
+	}
+	return nil, false
+}
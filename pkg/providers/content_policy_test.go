@@ -0,0 +1,37 @@
+package providers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckContentPolicy(t *testing.T) {
+	if err := CheckContentPolicy(&LLMResponse{Content: "hi", FinishReason: "stop"}); err != nil {
+		t.Fatalf("CheckContentPolicy(stop) = %v, want nil", err)
+	}
+	if err := CheckContentPolicy(&LLMResponse{
+		FinishReason: "content_filter",
+		ToolCalls:    []ToolCall{{ID: "call_1", Name: "read_file"}},
+	}); err != nil {
+		t.Fatalf("CheckContentPolicy(tool calls) = %v, want nil", err)
+	}
+
+	err := CheckContentPolicy(&LLMResponse{Content: " I can't help with that. ", FinishReason: "content_filter"})
+	if !errors.Is(err, ErrContentPolicy) {
+		t.Fatalf("CheckContentPolicy(content_filter) = %v, want ErrContentPolicy", err)
+	}
+	refusal, ok := AsContentPolicyError(err)
+	if !ok || refusal.Reason != "I can't help with that." {
+		t.Fatalf("AsContentPolicyError() = %+v, %v", refusal, ok)
+	}
+}
+
+func TestAsContentPolicyError_RecognizesHTTPBlocks(t *testing.T) {
+	err := errors.New(`status 400: {"error":{"code":"content_filter"}}`)
+	if _, ok := AsContentPolicyError(err); !ok {
+		t.Fatal("expected a content_filter HTTP error to be recognized as a refusal")
+	}
+	if _, ok := AsContentPolicyError(errors.New("status 400: bad request")); ok {
+		t.Fatal("plain 400 should not be treated as a refusal")
+	}
+}
@@ -162,6 +162,47 @@ func (ct *CooldownTracker) FailureCount(provider string, reason FailoverReason)
 	return entry.FailureCounts[reason]
 }
 
+// CooldownStatus is a snapshot of one provider's cooldown state.
+type CooldownStatus struct {
+	Available      bool
+	Remaining      time.Duration
+	ErrorCount     int
+	DisabledReason FailoverReason
+	LastFailure    time.Time
+}
+
+// Status returns the current cooldown state for a provider. Providers that
+// never failed are available with zero counts.
+func (ct *CooldownTracker) Status(provider string) CooldownStatus {
+	remaining := ct.CooldownRemaining(provider)
+
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	status := CooldownStatus{Available: remaining == 0, Remaining: remaining}
+	if entry := ct.entries[provider]; entry != nil {
+		status.ErrorCount = entry.ErrorCount
+		status.LastFailure = entry.LastFailure
+		if !entry.DisabledUntil.IsZero() && ct.nowFunc().Before(entry.DisabledUntil) {
+			status.DisabledReason = entry.DisabledReason
+		}
+	}
+	return status
+}
+
+// Reset forgets all failures of a provider, making it available again.
+// It reports whether the provider had any recorded state.
+func (ct *CooldownTracker) Reset(provider string) bool {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	if _, ok := ct.entries[provider]; !ok {
+		return false
+	}
+	delete(ct.entries, provider)
+	return true
+}
+
 func (ct *CooldownTracker) getOrCreate(provider string) *cooldownEntry {
 	entry := ct.entries[provider]
 	if entry == nil {
@@ -267,3 +267,29 @@ func TestCooldown_MultipleProviders(t *testing.T) {
 		t.Error("groq should be available")
 	}
 }
+
+func TestCooldown_StatusAndReset(t *testing.T) {
+	now := time.Now()
+	ct, _ := newTestTracker(now)
+
+	if s := ct.Status("openai"); !s.Available || s.ErrorCount != 0 {
+		t.Fatalf("fresh status = %+v", s)
+	}
+
+	ct.MarkFailure("openai", FailoverBilling)
+	s := ct.Status("openai")
+	if s.Available || s.Remaining != 5*time.Hour || s.ErrorCount != 1 ||
+		s.DisabledReason != FailoverBilling || !s.LastFailure.Equal(now) {
+		t.Fatalf("status after billing failure = %+v", s)
+	}
+
+	if !ct.Reset("openai") {
+		t.Fatal("Reset() = false for a provider with failures")
+	}
+	if !ct.IsAvailable("openai") || ct.ErrorCount("openai") != 0 {
+		t.Fatal("provider should be available with no errors after Reset")
+	}
+	if ct.Reset("openai") {
+		t.Fatal("Reset() = true for a provider with nothing recorded")
+	}
+}
@@ -56,6 +56,7 @@ var (
 
 	networkPatterns = []errorPattern{
 		substr("connection reset"),
+		substr("connection dropped mid-response"),
 		substr("reset by peer"),
 		substr("connection refused"),
 		substr("connection aborted"),
@@ -126,6 +127,15 @@ var (
 		substr("request too large"),
 	}
 
+	// Content policy blocks reported as HTTP errors, e.g. Azure OpenAI's
+	// content_filter 400 and OpenAI's content_policy_violation.
+	contentPolicyPatterns = []errorPattern{
+		substr("content_policy_violation"),
+		rxp(`"code"\s*:\s*"content_filter"`),
+		substr("content management policy"),
+		substr("responsibleaipolicyviolation"),
+	}
+
 	imageDimensionPatterns = []errorPattern{
 		rxp(`image dimensions exceed max`),
 	}
@@ -164,6 +174,17 @@ func ClassifyError(err error, provider, model string) *FailoverError {
 		}
 	}
 
+	// Refusals and safety blocks: the request itself was declined, so the
+	// same prompt fails the same way on retry.
+	if errors.Is(err, ErrContentPolicy) {
+		return &FailoverError{
+			Reason:   FailoverContentPolicy,
+			Provider: provider,
+			Model:    model,
+			Wrapped:  err,
+		}
+	}
+
 	msg := strings.ToLower(err.Error())
 
 	// Concrete transport errors should continue the fallback chain even when
@@ -177,6 +198,16 @@ func ClassifyError(err error, provider, model string) *FailoverError {
 		}
 	}
 
+	// Content policy blocks arrive as 400s; check them before the status code.
+	if matchesAny(msg, contentPolicyPatterns) {
+		return &FailoverError{
+			Reason:   FailoverContentPolicy,
+			Provider: provider,
+			Model:    model,
+			Wrapped:  err,
+		}
+	}
+
 	// Image dimension/size errors: non-retriable, non-fallback.
 	if IsImageDimensionError(msg) || IsImageSizeError(msg) {
 		return &FailoverError{
@@ -176,6 +176,7 @@ func TestClassifyError_NetworkPatterns(t *testing.T) {
 		"x509: certificate has expired or is not yet valid",
 		"read tcp 127.0.0.1:443: read: unexpected EOF",
 		"lookup api.example.com: no such host",
+		"failed to parse response: connection dropped mid-response: JSON ends after 512 bytes",
 	}
 
 	for _, msg := range patterns {
@@ -498,3 +499,22 @@ func TestIsImageSizeError(t *testing.T) {
 		t.Error("should not match normal error")
 	}
 }
+
+func TestClassifyError_ContentPolicy(t *testing.T) {
+	tests := []error{
+		&ContentPolicyError{Reason: "I can't help with that."},
+		fmt.Errorf("wrapped: %w", ErrContentPolicy),
+		errors.New(`API request failed: status 400: {"error":{"code":"content_filter","message":"filtered"}}`),
+		errors.New("Your request was rejected as a result of our safety system. code: content_policy_violation"),
+	}
+	for _, err := range tests {
+		result := ClassifyError(err, "openai", "gpt-4")
+		if result == nil || result.Reason != FailoverContentPolicy {
+			t.Errorf("ClassifyError(%q) = %+v, want content_policy", err, result)
+			continue
+		}
+		if result.IsRetriable() {
+			t.Errorf("content policy error %q should not be retriable", err)
+		}
+	}
+}
@@ -8,6 +8,8 @@ package providers
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,6 +17,7 @@ import (
 	anthropicmessages "github.com/sipeed/picoclaw/pkg/providers/anthropic_messages"
 	"github.com/sipeed/picoclaw/pkg/providers/azure"
 	"github.com/sipeed/picoclaw/pkg/providers/bedrock"
+	"github.com/sipeed/picoclaw/pkg/providers/cohere"
 	"github.com/sipeed/picoclaw/pkg/providers/common"
 )
 
@@ -42,6 +45,32 @@ func createCodexAuthProvider() (LLMProvider, error) {
 	return NewCodexProviderWithTokenSource(cred.AccessToken, cred.AccountID, createCodexTokenSource()), nil
 }
 
+// createGeminiAuthProvider creates a Gemini provider using OAuth credentials from auth store.
+func createGeminiAuthProvider(cfg *config.ModelConfig, userAgent string) (*GeminiProvider, error) {
+	cred, err := getCredential("google")
+	if err != nil {
+		return nil, fmt.Errorf("loading auth credentials: %w", err)
+	}
+	if cred == nil {
+		return nil, fmt.Errorf("no credentials for google. Run: picoclaw auth login --provider google")
+	}
+	apiBase := cfg.APIBase
+	if apiBase == "" {
+		apiBase = getDefaultAPIBase("gemini")
+	}
+	provider := NewGeminiProvider(
+		"",
+		apiBase,
+		cfg.Proxy,
+		userAgent,
+		cfg.RequestTimeout,
+		cfg.ExtraBody,
+		cfg.CustomHeaders,
+	)
+	provider.SetTokenSource(createGeminiTokenSource())
+	return provider, nil
+}
+
 // ExtractProtocol extracts the effective protocol and model identifier from a
 // model configuration.
 //
@@ -130,7 +159,7 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 			cfg.MaxTokensField,
 			userAgent,
 			cfg.RequestTimeout,
-			cfg.ExtraBody,
+			openAICompatExtraBody(cfg),
 			cfg.CustomHeaders,
 		)
 		provider.SetProviderName(protocol)
@@ -201,7 +230,7 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		return finalizeProviderFromConfig(provider, modelID, cfg)
 
 	case "litellm", "lmstudio", "gpt4free", "openrouter", "groq", "zhipu", "nvidia", "venice",
-		"ollama", "moonshot", "shengsuanyun", "siliconflow", "deepseek", "cerebras",
+		"ollama", "moonshot", "shengsuanyun", "siliconflow", "deepseek", "cerebras", "fireworks",
 		"vivgrid", "volcengine", "vllm", "qwen-portal", "qwen-intl", "qwen-us", "mistral",
 		"avian", "longcat", "modelscope", "novita", "alibaba-coding", "zai", "mimo":
 		// All other OpenAI-compatible HTTP providers
@@ -212,6 +241,10 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if apiBase == "" {
 			apiBase = getDefaultAPIBase(protocol)
 		}
+		extraBody := openAICompatExtraBody(cfg)
+		if protocol == "openrouter" {
+			extraBody = withOpenRouterPreferences(extraBody)
+		}
 		provider := NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(
 			cfg.APIKey(),
 			apiBase,
@@ -219,13 +252,23 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 			cfg.MaxTokensField,
 			userAgent,
 			cfg.RequestTimeout,
-			cfg.ExtraBody,
+			extraBody,
 			cfg.CustomHeaders,
 		)
 		provider.SetProviderName(protocol)
+		provider.SetWebSearch(cfg.EnableWebSearch)
 		return finalizeProviderFromConfig(provider, modelID, cfg)
 
 	case "gemini":
+		// Gemini with OAuth (picoclaw auth login --provider google)
+		if authMethod == "oauth" {
+			provider, err := createGeminiAuthProvider(cfg, userAgent)
+			if err != nil {
+				return nil, "", err
+			}
+			provider.SetThinkingBudget(cfg.ThinkingBudget)
+			return finalizeProviderFromConfig(provider, modelID, cfg)
+		}
 		if cfg.APIKey() == "" && cfg.APIBase == "" {
 			return nil, "", fmt.Errorf("api_key or api_base is required for gemini protocol (model: %s)", cfg.Model)
 		}
@@ -233,7 +276,7 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if apiBase == "" {
 			apiBase = getDefaultAPIBase(protocol)
 		}
-		return finalizeProviderFromConfig(NewGeminiProvider(
+		provider := NewGeminiProvider(
 			cfg.APIKey(),
 			apiBase,
 			cfg.Proxy,
@@ -241,7 +284,9 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 			cfg.RequestTimeout,
 			cfg.ExtraBody,
 			cfg.CustomHeaders,
-		), modelID, cfg)
+		)
+		provider.SetThinkingBudget(cfg.ThinkingBudget)
+		return finalizeProviderFromConfig(provider, modelID, cfg)
 
 	case "minimax":
 		// Minimax requires reasoning_split: true in the request body
@@ -252,7 +297,7 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if apiBase == "" {
 			apiBase = getDefaultAPIBase(protocol)
 		}
-		extraBody := cfg.ExtraBody
+		extraBody := openAICompatExtraBody(cfg)
 		if extraBody == nil {
 			extraBody = make(map[string]any)
 		}
@@ -293,7 +338,7 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 			cfg.MaxTokensField,
 			userAgent,
 			cfg.RequestTimeout,
-			cfg.ExtraBody,
+			openAICompatExtraBody(cfg),
 			cfg.CustomHeaders,
 		)
 		provider.SetProviderName(protocol)
@@ -308,11 +353,26 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if cfg.APIKey() == "" {
 			return nil, "", fmt.Errorf("api_key is required for anthropic-messages protocol (model: %s)", cfg.Model)
 		}
-		return finalizeProviderFromConfig(anthropicmessages.NewProviderWithTimeout(
+		provider := anthropicmessages.NewProviderWithTimeout(
 			cfg.APIKey(),
 			apiBase,
 			userAgent,
 			cfg.RequestTimeout,
+		)
+		provider.SetPromptCaching(cfg.PromptCaching)
+		return finalizeProviderFromConfig(provider, modelID, cfg)
+
+	case "cohere":
+		// Cohere v2 Chat API (native tool_plan / document tool results)
+		if cfg.APIKey() == "" {
+			return nil, "", fmt.Errorf("api_key is required for cohere protocol (model: %s)", cfg.Model)
+		}
+		return finalizeProviderFromConfig(cohere.NewProviderWithTimeout(
+			cfg.APIKey(),
+			ResolveAPIBase(cfg),
+			cfg.Proxy,
+			userAgent,
+			cfg.RequestTimeout,
 		), modelID, cfg)
 
 	case "alibaba-coding-anthropic":
@@ -324,12 +384,14 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if cfg.APIKey() == "" {
 			return nil, "", fmt.Errorf("api_key is required for %q protocol (model: %s)", protocol, cfg.Model)
 		}
-		return finalizeProviderFromConfig(anthropicmessages.NewProviderWithTimeout(
+		provider := anthropicmessages.NewProviderWithTimeout(
 			cfg.APIKey(),
 			apiBase,
 			userAgent,
 			cfg.RequestTimeout,
-		), modelID, cfg)
+		)
+		provider.SetPromptCaching(cfg.PromptCaching)
+		return finalizeProviderFromConfig(provider, modelID, cfg)
 
 	case "antigravity":
 		return finalizeProviderFromConfig(NewAntigravityProvider(), modelID, cfg)
@@ -380,6 +442,22 @@ func finalizeProviderFromConfig(
 	return wrapped, modelID, nil
 }
 
+// openAICompatExtraBody returns the extra request fields of an
+// OpenAI-compatible model entry, with stop_sequences sent as "stop" unless
+// extra_body already sets it.
+func openAICompatExtraBody(cfg *config.ModelConfig) map[string]any {
+	if len(cfg.StopSequences) == 0 {
+		return cfg.ExtraBody
+	}
+	if _, ok := cfg.ExtraBody["stop"]; ok {
+		return cfg.ExtraBody
+	}
+	body := make(map[string]any, len(cfg.ExtraBody)+1)
+	maps.Copy(body, cfg.ExtraBody)
+	body["stop"] = slices.Clone(cfg.StopSequences)
+	return body
+}
+
 func isEmptyAPIKeyAllowed(protocol string) bool {
 	option, ok := modelProviderOptionForName(protocol)
 	return ok && option.EmptyAPIKeyAllowed
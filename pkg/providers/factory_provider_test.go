@@ -15,6 +15,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/auth"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers/cohere"
 )
 
 func TestExtractProtocol(t *testing.T) {
@@ -227,6 +228,7 @@ func TestCreateProviderFromConfig_DefaultAPIBase(t *testing.T) {
 		{"novita", "novita"},
 		{"openrouter", "openrouter"},
 		{"cerebras", "cerebras"},
+		{"fireworks", "fireworks"},
 		{"vivgrid", "vivgrid"},
 		{"siliconflow", "siliconflow"},
 		{"qwen", "qwen"},
@@ -547,6 +549,81 @@ func TestCreateProviderFromConfig_SiliconFlow(t *testing.T) {
 	}
 }
 
+func TestCreateProviderFromConfig_FireworksCompletion(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			http.Error(w, "unexpected path "+r.URL.Path, http.StatusNotFound)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer fw-key" {
+			http.Error(w, "bad auth "+got, http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"choices": [{
+				"message": {
+					"content": "",
+					"tool_calls": [{
+						"id": "call_1",
+						"type": "function",
+						"function": {"name": "read_file", "arguments": "{\"path\":\"notes.md\"}"}
+					}]
+				},
+				"finish_reason": "tool_calls"
+			}],
+			"usage": {"prompt_tokens": 12, "completion_tokens": 7, "total_tokens": 19}
+		}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.ModelConfig{
+		ModelName: "fw-deepseek",
+		Model:     "fireworks/accounts/fireworks/models/deepseek-v3p1",
+		APIBase:   server.URL,
+	}
+	cfg.SetAPIKey("fw-key")
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if modelID != "accounts/fireworks/models/deepseek-v3p1" {
+		t.Fatalf("modelID = %q, want the full Fireworks model path", modelID)
+	}
+
+	resp, err := provider.Chat(
+		t.Context(),
+		[]Message{{Role: "user", Content: "read my notes"}},
+		nil,
+		modelID,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if got := requestBody["model"]; got != "accounts/fireworks/models/deepseek-v3p1" {
+		t.Fatalf("request model = %v, want the full Fireworks model path", got)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "read_file" || resp.ToolCalls[0].Arguments["path"] != "notes.md" {
+		t.Fatalf("tool calls = %+v", resp.ToolCalls)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 12 || resp.Usage.CompletionTokens != 7 || resp.Usage.TotalTokens != 19 {
+		t.Fatalf("usage = %+v", resp.Usage)
+	}
+}
+
+func TestGetDefaultAPIBase_Fireworks(t *testing.T) {
+	if got := getDefaultAPIBase("fireworks"); got != "https://api.fireworks.ai/inference/v1" {
+		t.Fatalf("getDefaultAPIBase(%q) = %q, want %q", "fireworks", got, "https://api.fireworks.ai/inference/v1")
+	}
+}
+
 func TestGetDefaultAPIBase_Mimo(t *testing.T) {
 	if got := getDefaultAPIBase("mimo"); got != "https://api.xiaomimimo.com/v1" {
 		t.Fatalf("getDefaultAPIBase(%q) = %q, want %q", "mimo", got, "https://api.xiaomimimo.com/v1")
@@ -646,6 +723,37 @@ func TestCreateProviderFromConfig_GeminiCustomAPIBaseWithoutKey(t *testing.T) {
 	}
 }
 
+func TestCreateProviderFromConfig_Cohere(t *testing.T) {
+	cfg := &config.ModelConfig{
+		ModelName: "test-cohere",
+		Model:     "cohere/command-a-03-2025",
+	}
+	cfg.SetAPIKey("test-key")
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if modelID != "command-a-03-2025" {
+		t.Errorf("modelID = %q, want %q", modelID, "command-a-03-2025")
+	}
+	if _, ok := provider.(*cohere.Provider); !ok {
+		t.Fatalf("expected *cohere.Provider, got %T", provider)
+	}
+}
+
+func TestCreateProviderFromConfig_CohereMissingAPIKey(t *testing.T) {
+	cfg := &config.ModelConfig{
+		ModelName: "test-cohere-no-key",
+		Model:     "cohere/command-a-03-2025",
+	}
+
+	_, _, err := CreateProviderFromConfig(cfg)
+	if err == nil {
+		t.Fatal("CreateProviderFromConfig() expected error for missing cohere API key")
+	}
+}
+
 func TestCreateProviderFromConfig_ClaudeCLI(t *testing.T) {
 	cfg := &config.ModelConfig{
 		ModelName: "test-claude-cli",
@@ -1551,3 +1659,110 @@ func TestCreateProviderFromConfig_InvalidToolSchemaTransform(t *testing.T) {
 		t.Fatalf("error = %v, want mention tool_schema_transform", err)
 	}
 }
+
+func TestCreateProviderFromConfig_VLLMSendsStopSequencesAndExtraBody(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.ModelConfig{
+		ModelName:     "local",
+		Model:         "vllm/Qwen/Qwen3-8B",
+		APIBase:       server.URL,
+		StopSequences: []string{"<|im_end|>", "</s>"},
+		ExtraBody:     map[string]any{"top_k": 20, "repetition_penalty": 1.05},
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, err = provider.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, modelID, nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	stop, _ := requestBody["stop"].([]any)
+	if len(stop) != 2 || stop[0] != "<|im_end|>" || stop[1] != "</s>" {
+		t.Errorf("stop = %#v, want the configured stop sequences", requestBody["stop"])
+	}
+	if requestBody["top_k"] != float64(20) || requestBody["repetition_penalty"] != 1.05 {
+		t.Errorf("request body = %#v, want extra_body sampling params", requestBody)
+	}
+	if _, ok := cfg.ExtraBody["stop"]; ok {
+		t.Error("stop sequences must not be written back into the config's extra_body")
+	}
+}
+
+func TestOpenAICompatExtraBody_ExplicitStopWins(t *testing.T) {
+	cfg := &config.ModelConfig{
+		StopSequences: []string{"</s>"},
+		ExtraBody:     map[string]any{"stop": "###"},
+	}
+	if got := openAICompatExtraBody(cfg)["stop"]; got != "###" {
+		t.Errorf("stop = %#v, want extra_body value to win", got)
+	}
+}
+
+func TestCreateProviderFromConfig_OpenRouterSendsRoutingPreferences(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	allowFallbacks := false
+	global := config.DefaultConfig()
+	global.Providers.OpenRouter = config.OpenRouterConfig{
+		ProviderOrder:  []string{"anthropic", " together "},
+		AllowFallbacks: &allowFallbacks,
+		DataCollection: "Deny",
+	}
+	Configure(global)
+	t.Cleanup(func() { Configure(config.DefaultConfig()) })
+
+	chat := func(cfg *config.ModelConfig) {
+		t.Helper()
+		requestBody = nil
+		provider, modelID, err := CreateProviderFromConfig(cfg)
+		if err != nil {
+			t.Fatalf("CreateProviderFromConfig() error = %v", err)
+		}
+		if _, err = provider.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, modelID, nil); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+
+	chat(&config.ModelConfig{ModelName: "claude", Model: "openrouter/anthropic/claude-sonnet-4", APIBase: server.URL})
+	got, _ := json.Marshal(requestBody["provider"])
+	want := `{"allow_fallbacks":false,"data_collection":"deny","order":["anthropic","together"]}`
+	if string(got) != want {
+		t.Errorf("provider = %s, want %s", got, want)
+	}
+
+	chat(&config.ModelConfig{
+		ModelName: "pinned",
+		Model:     "openrouter/meta-llama/llama-3.3-70b-instruct",
+		APIBase:   server.URL,
+		ExtraBody: map[string]any{"provider": map[string]any{"only": []string{"groq"}}},
+	})
+	if got, _ = json.Marshal(requestBody["provider"]); string(got) != `{"only":["groq"]}` {
+		t.Errorf("provider = %s, want the model's extra_body to win", got)
+	}
+
+	chat(&config.ModelConfig{ModelName: "local", Model: "vllm/Qwen/Qwen3-8B", APIBase: server.URL})
+	if _, ok := requestBody["provider"]; ok {
+		t.Errorf("non-OpenRouter request carries provider = %#v", requestBody["provider"])
+	}
+}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/sipeed/picoclaw/pkg/auth"
@@ -104,6 +105,64 @@ func TestCreateProviderReturnsClaudeProviderForAnthropicOAuth(t *testing.T) {
 	// TODO: Test custom APIBase when createClaudeAuthProvider supports it
 }
 
+func TestCreateProviderReturnsGeminiProviderForGoogleOAuth(t *testing.T) {
+	originalGetCredential := getCredential
+	t.Cleanup(func() { getCredential = originalGetCredential })
+
+	getCredential = func(provider string) (*auth.AuthCredential, error) {
+		if provider != "google" {
+			t.Fatalf("provider = %q, want google", provider)
+		}
+		return &auth.AuthCredential{
+			AccessToken: "google-token",
+			AuthMethod:  "oauth",
+		}, nil
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.ModelName = "test-gemini-oauth"
+	cfg.ModelList = []*config.ModelConfig{
+		{
+			ModelName:  "test-gemini-oauth",
+			Model:      "gemini/gemini-3-flash-preview",
+			AuthMethod: "oauth",
+		},
+	}
+
+	provider, modelID, err := CreateProvider(cfg)
+	if err != nil {
+		t.Fatalf("CreateProvider() error = %v", err)
+	}
+	if _, ok := provider.(*GeminiProvider); !ok {
+		t.Fatalf("provider type = %T, want *GeminiProvider", provider)
+	}
+	if modelID != "gemini-3-flash-preview" {
+		t.Fatalf("modelID = %q, want %q", modelID, "gemini-3-flash-preview")
+	}
+}
+
+func TestCreateProviderGoogleOAuthWithoutCredentials(t *testing.T) {
+	originalGetCredential := getCredential
+	t.Cleanup(func() { getCredential = originalGetCredential })
+
+	getCredential = func(string) (*auth.AuthCredential, error) { return nil, nil }
+
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.ModelName = "test-gemini-oauth"
+	cfg.ModelList = []*config.ModelConfig{
+		{
+			ModelName:  "test-gemini-oauth",
+			Model:      "gemini/gemini-3-flash-preview",
+			AuthMethod: "oauth",
+		},
+	}
+
+	_, _, err := CreateProvider(cfg)
+	if err == nil || !strings.Contains(err.Error(), "auth login --provider google") {
+		t.Fatalf("CreateProvider() error = %v, want login hint", err)
+	}
+}
+
 func TestCreateProviderReturnsCodexProviderForOpenAIOAuth(t *testing.T) {
 	// TODO: This test requires openai protocol to support auth_method: "oauth"
 	// which is not yet implemented in the new factory_provider.go
@@ -9,8 +9,9 @@ import (
 
 // FallbackChain orchestrates model fallback across multiple candidates.
 type FallbackChain struct {
-	cooldown *CooldownTracker
-	rl       *RateLimiterRegistry
+	cooldown        *CooldownTracker
+	rl              *RateLimiterRegistry
+	refusalFallback bool
 }
 
 // FallbackCandidate represents one model/provider to try.
@@ -56,6 +57,13 @@ func NewFallbackChain(cooldown *CooldownTracker, rl *RateLimiterRegistry) *Fallb
 	return &FallbackChain{cooldown: cooldown, rl: rl}
 }
 
+// SetRefusalFallback controls whether a content policy refusal moves on to
+// the next candidate instead of ending the chain. Off by default: a refusal
+// is usually the answer, but a chain can list a more permissive model last.
+func (fc *FallbackChain) SetRefusalFallback(enabled bool) {
+	fc.refusalFallback = enabled
+}
+
 // ResolveCandidates parses model config into a deduplicated candidate list.
 func ResolveCandidates(cfg ModelConfig, defaultProvider string) []FallbackCandidate {
 	return ResolveCandidatesWithLookup(cfg, defaultProvider, nil)
@@ -236,7 +244,9 @@ func (fc *FallbackChain) ExecuteCandidate(
 				candidate.Provider, candidate.Model, err)
 		}
 
-		// Non-retriable error: abort immediately.
+		// Non-retriable error: abort immediately. A refusal is not a provider
+		// fault, so when refusal fallback is on it moves to the next
+		// candidate without a cooldown.
 		if !failErr.IsRetriable() {
 			result.Attempts = append(result.Attempts, FallbackAttempt{
 				Provider: candidate.Provider,
@@ -245,6 +255,9 @@ func (fc *FallbackChain) ExecuteCandidate(
 				Reason:   failErr.Reason,
 				Duration: elapsed,
 			})
+			if fc.refusalFallback && failErr.Reason == FailoverContentPolicy && i < len(candidates)-1 {
+				continue
+			}
 			return nil, failErr
 		}
 
@@ -696,3 +696,34 @@ func TestFallbackExhaustedError_Message(t *testing.T) {
 		t.Error("expected non-empty error message")
 	}
 }
+
+func TestFallback_ContentPolicyRefusal(t *testing.T) {
+	candidates := []FallbackCandidate{
+		makeCandidate("openai", "gpt-4"),
+		makeCandidate("local", "permissive"),
+	}
+	run := func(ctx context.Context, provider, model string) (*LLMResponse, error) {
+		if provider == "openai" {
+			return nil, &ContentPolicyError{Reason: "declined"}
+		}
+		return &LLMResponse{Content: "answer", FinishReason: "stop"}, nil
+	}
+
+	ct := NewCooldownTracker()
+	fc := NewFallbackChain(ct, nil)
+	if _, err := fc.Execute(context.Background(), candidates, run); !errors.Is(err, ErrContentPolicy) {
+		t.Fatalf("err = %v, want the refusal without trying the next candidate", err)
+	}
+
+	fc.SetRefusalFallback(true)
+	result, err := fc.Execute(context.Background(), candidates, run)
+	if err != nil {
+		t.Fatalf("unexpected error with refusal fallback: %v", err)
+	}
+	if result.Provider != "local" || result.Response.Content != "answer" {
+		t.Fatalf("result = %s/%q, want local/answer", result.Provider, result.Response.Content)
+	}
+	if !ct.IsAvailable(candidates[0].StableKey()) {
+		t.Fatal("a refusal should not put the candidate in cooldown")
+	}
+}
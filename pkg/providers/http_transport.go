@@ -0,0 +1,54 @@
+package providers
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers/common"
+)
+
+var (
+	openRouterMu    sync.RWMutex
+	openRouterPrefs map[string]any
+)
+
+// Configure applies the providers section of cfg: providers.http to the
+// connection pool shared by provider HTTP clients and providers.openrouter to
+// OpenRouter requests. Call it before creating providers; providers that
+// already exist keep their current settings.
+func Configure(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	h := cfg.Providers.HTTP
+	common.ConfigureTransport(common.TransportOptions{
+		MaxIdleConns:        h.MaxIdleConns,
+		MaxIdleConnsPerHost: h.MaxIdleConnsPerHost,
+		IdleConnTimeout:     time.Duration(h.IdleTimeout) * time.Second,
+	})
+
+	openRouterMu.Lock()
+	openRouterPrefs = cfg.Providers.OpenRouter.RoutingPreferences()
+	openRouterMu.Unlock()
+}
+
+// withOpenRouterPreferences adds the configured routing preferences to an
+// OpenRouter request body as its "provider" object, unless extra_body already
+// sets one. body is not modified.
+func withOpenRouterPreferences(body map[string]any) map[string]any {
+	openRouterMu.RLock()
+	prefs := openRouterPrefs
+	openRouterMu.RUnlock()
+	if prefs == nil {
+		return body
+	}
+	if _, ok := body["provider"]; ok {
+		return body
+	}
+	out := make(map[string]any, len(body)+1)
+	maps.Copy(out, body)
+	out["provider"] = prefs
+	return out
+}
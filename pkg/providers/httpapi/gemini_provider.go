@@ -24,9 +24,18 @@ type GeminiProvider struct {
 	apiKey        string
 	apiBase       string
 	httpClient    *http.Client
+	timeout       time.Duration // bounds non-streaming calls
 	extraBody     map[string]any
 	customHeaders map[string]string
 	userAgent     string
+
+	// thinkingBudget, when set, replaces the budget or level derived from
+	// thinking_level. -1 lets the model decide; 0 turns thinking off.
+	thinkingBudget *int
+
+	// tokenSource, when set, supplies an OAuth access token and an optional
+	// quota project for each request instead of the API key.
+	tokenSource func() (token, projectID string, err error)
 }
 
 func NewGeminiProvider(
@@ -41,21 +50,40 @@ func NewGeminiProvider(
 	if strings.TrimSpace(apiBase) == "" {
 		apiBase = geminiDefaultAPIBase
 	}
-	client := common.NewHTTPClient(proxy)
+	timeout := common.DefaultRequestTimeout
 	if requestTimeoutSeconds > 0 {
-		client.Timeout = time.Duration(requestTimeoutSeconds) * time.Second
+		timeout = time.Duration(requestTimeoutSeconds) * time.Second
 	}
 
 	return &GeminiProvider{
 		apiKey:        strings.TrimSpace(apiKey),
 		apiBase:       strings.TrimRight(strings.TrimSpace(apiBase), "/"),
-		httpClient:    client,
+		httpClient:    common.NewHTTPClient(proxy),
+		timeout:       timeout,
 		extraBody:     cloneAnyMap(extraBody),
 		customHeaders: cloneStringMap(customHeaders),
 		userAgent:     strings.TrimSpace(userAgent),
 	}
 }
 
+// SetThinkingBudget sets a fixed thinking token budget for thinking-capable
+// models. nil restores the default of following thinking_level.
+func (p *GeminiProvider) SetThinkingBudget(budget *int) {
+	if budget == nil {
+		p.thinkingBudget = nil
+		return
+	}
+	b := *budget
+	p.thinkingBudget = &b
+}
+
+// SetTokenSource authenticates requests with OAuth access tokens from
+// source instead of the API key. A non-empty project ID is sent as the
+// quota project.
+func (p *GeminiProvider) SetTokenSource(source func() (token, projectID string, err error)) {
+	p.tokenSource = source
+}
+
 func (p *GeminiProvider) GetDefaultModel() string {
 	return geminiDefaultModel
 }
@@ -82,15 +110,19 @@ func (p *GeminiProvider) Chat(
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	ctx, cancel := common.WithRequestTimeout(ctx, p.timeout)
+	defer cancel()
 	url := fmt.Sprintf("%s/models/%s:generateContent", p.apiBase, model)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	p.applyHeaders(req)
+	if err = p.applyHeaders(req); err != nil {
+		return nil, err
+	}
 
-	resp, err := p.httpClient.Do(req)
+	resp, err := common.DoBuffered(p.httpClient, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -100,8 +132,12 @@ func (p *GeminiProvider) Chat(
 		return nil, common.HandleErrorResponse(resp, p.apiBase)
 	}
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
 	var apiResp geminiGenerateContentResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+	if err := common.DecodeJSONResponse(body, &apiResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -155,12 +191,13 @@ func (p *GeminiProvider) ChatStreamEvents(
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	p.applyHeaders(req)
+	if err = p.applyHeaders(req); err != nil {
+		return nil, err
+	}
 	req.Header.Set("Accept", "text/event-stream")
 
-	// Streaming should not use a whole-request timeout; context cancellation is the guard.
-	streamClient := &http.Client{Transport: p.httpClient.Transport}
-	resp, err := streamClient.Do(req)
+	// Streaming does not use the request timeout; context cancellation is the guard.
+	resp, err := p.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -209,9 +246,18 @@ func (b *geminiStreamingReadIdleTimeoutBody) Close() error {
 	return b.body.Close()
 }
 
-func (p *GeminiProvider) applyHeaders(req *http.Request) {
+func (p *GeminiProvider) applyHeaders(req *http.Request) error {
 	req.Header.Set("Content-Type", "application/json")
-	if p.apiKey != "" {
+	if p.tokenSource != nil {
+		token, projectID, err := p.tokenSource()
+		if err != nil {
+			return fmt.Errorf("failed to get auth token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		if projectID != "" {
+			req.Header.Set("X-Goog-User-Project", projectID)
+		}
+	} else if p.apiKey != "" {
 		req.Header.Set("X-Goog-Api-Key", p.apiKey)
 	}
 	if p.userAgent != "" {
@@ -223,6 +269,7 @@ func (p *GeminiProvider) applyHeaders(req *http.Request) {
 		}
 		req.Header.Set(k, v)
 	}
+	return nil
 }
 
 func (p *GeminiProvider) buildRequestBody(
@@ -343,7 +390,7 @@ func (p *GeminiProvider) buildRequestBody(
 		generationConfig["temperature"] = temp
 	}
 
-	if thinkingConfig := buildGeminiThinkingConfig(model, options); len(thinkingConfig) > 0 {
+	if thinkingConfig := buildGeminiThinkingConfig(model, options, p.thinkingBudget); len(thinkingConfig) > 0 {
 		generationConfig["thinkingConfig"] = thinkingConfig
 	}
 
@@ -388,12 +435,22 @@ func mapGeminiThinkingLevel(level string) string {
 	}
 }
 
-func buildGeminiThinkingConfig(model string, options map[string]any) map[string]any {
+func buildGeminiThinkingConfig(model string, options map[string]any, budget *int) map[string]any {
 	if !geminiModelSupportsThinkingConfig(model) {
 		return nil
 	}
 
 	config := map[string]any{}
+	if budget != nil {
+		config["includeThoughts"] = *budget != 0
+		if *budget == 0 && (isGemini25ProModel(model) || isGemini3ProModel(model)) {
+			// Pro models cannot turn thinking off; keep the model default.
+			return config
+		}
+		config["thinkingBudget"] = *budget
+		return config
+	}
+
 	rawLevel, _ := options["thinking_level"].(string)
 	rawLevel = strings.ToLower(strings.TrimSpace(rawLevel))
 	if rawLevel == "" {
@@ -495,14 +552,8 @@ func parseGeminiResponse(resp *geminiGenerateContentResponse) *LLMResponse {
 			finishReason = candidate.FinishReason
 		}
 	}
-
-	var usage *UsageInfo
-	if resp.UsageMetadata.TotalTokenCount > 0 {
-		usage = &UsageInfo{
-			PromptTokens:     resp.UsageMetadata.PromptTokenCount,
-			CompletionTokens: resp.UsageMetadata.CandidatesTokenCount,
-			TotalTokens:      resp.UsageMetadata.TotalTokenCount,
-		}
+	if resp.PromptFeedback.BlockReason != "" {
+		finishReason = geminiPromptBlocked
 	}
 
 	return &LLMResponse{
@@ -510,7 +561,7 @@ func parseGeminiResponse(resp *geminiGenerateContentResponse) *LLMResponse {
 		ReasoningContent: strings.Join(reasoningParts, ""),
 		ToolCalls:        toolCalls,
 		FinishReason:     normalizeGeminiFinishReason(finishReason, len(toolCalls)),
-		Usage:            usage,
+		Usage:            resp.UsageMetadata.usageInfo(),
 	}
 }
 
@@ -598,13 +649,12 @@ func parseGeminiStreamResponse(
 				finishReason = candidate.FinishReason
 			}
 		}
+		if chunk.PromptFeedback.BlockReason != "" {
+			finishReason = geminiPromptBlocked
+		}
 
-		if chunk.UsageMetadata.TotalTokenCount > 0 {
-			usage = &UsageInfo{
-				PromptTokens:     chunk.UsageMetadata.PromptTokenCount,
-				CompletionTokens: chunk.UsageMetadata.CandidatesTokenCount,
-				TotalTokens:      chunk.UsageMetadata.TotalTokenCount,
-			}
+		if chunkUsage := chunk.UsageMetadata.usageInfo(); chunkUsage != nil {
+			usage = chunkUsage
 		}
 	}
 
@@ -626,6 +676,10 @@ func parseGeminiStreamResponse(
 	}, nil
 }
 
+// geminiPromptBlocked stands in for a finish reason when promptFeedback
+// reports that the prompt itself was blocked and no candidate was returned.
+const geminiPromptBlocked = "PROMPT_BLOCKED"
+
 func normalizeGeminiFinishReason(reason string, toolCalls int) string {
 	if toolCalls > 0 {
 		return "tool_calls"
@@ -636,6 +690,8 @@ func normalizeGeminiFinishReason(reason string, toolCalls int) string {
 		return "length"
 	case "", "STOP":
 		return "stop"
+	case "SAFETY", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY", geminiPromptBlocked:
+		return "content_filter"
 	default:
 		return strings.ToLower(strings.TrimSpace(reason))
 	}
@@ -802,11 +858,31 @@ type geminiGenerateContentResponse struct {
 		} `json:"content"`
 		FinishReason string `json:"finishReason"`
 	} `json:"candidates"`
-	UsageMetadata struct {
-		PromptTokenCount     int `json:"promptTokenCount"`
-		CandidatesTokenCount int `json:"candidatesTokenCount"`
-		TotalTokenCount      int `json:"totalTokenCount"`
-	} `json:"usageMetadata"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata geminiUsageMetadata `json:"usageMetadata"`
+}
+
+type geminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+// usageInfo converts Gemini usage, where thinking tokens are counted apart
+// from the candidates, into UsageInfo, where they are part of the completion.
+func (m geminiUsageMetadata) usageInfo() *UsageInfo {
+	if m.TotalTokenCount <= 0 {
+		return nil
+	}
+	return &UsageInfo{
+		PromptTokens:     m.PromptTokenCount,
+		CompletionTokens: m.CandidatesTokenCount + m.ThoughtsTokenCount,
+		TotalTokens:      m.TotalTokenCount,
+		ReasoningTokens:  m.ThoughtsTokenCount,
+	}
 }
 
 type geminiContent struct {
@@ -2,6 +2,7 @@ package httpapi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -893,6 +894,68 @@ func TestGeminiProvider_ChatAllowsCustomAuthHeaderWithoutAPIKey(t *testing.T) {
 	}
 }
 
+func TestGeminiProvider_ChatUsesTokenSourceInsteadOfAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer oauth-token" {
+			t.Fatalf("Authorization = %q, want %q", got, "Bearer oauth-token")
+		}
+		if got := r.Header.Get("X-Goog-User-Project"); got != "quota-project" {
+			t.Fatalf("X-Goog-User-Project = %q, want %q", got, "quota-project")
+		}
+		if got := r.Header.Get("X-Goog-Api-Key"); got != "" {
+			t.Fatalf("X-Goog-Api-Key = %q, want empty", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []any{
+				map[string]any{
+					"content": map[string]any{
+						"parts": []any{map[string]any{"text": "ok"}},
+					},
+					"finishReason": "STOP",
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewGeminiProvider("test-key", server.URL, "", "", 0, nil, nil)
+	provider.SetTokenSource(func() (string, string, error) {
+		return "oauth-token", "quota-project", nil
+	})
+
+	resp, err := provider.Chat(
+		t.Context(),
+		[]Message{{Role: "user", Content: "hello"}},
+		nil,
+		"gemini-2.5-flash",
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "ok" {
+		t.Fatalf("Content = %q, want %q", resp.Content, "ok")
+	}
+}
+
+func TestGeminiProvider_ChatReturnsTokenSourceError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request must not be sent without a token")
+	}))
+	defer server.Close()
+
+	provider := NewGeminiProvider("", server.URL, "", "", 0, nil, nil)
+	provider.SetTokenSource(func() (string, string, error) {
+		return "", "", errors.New("credentials expired")
+	})
+
+	_, err := provider.Chat(t.Context(), []Message{{Role: "user", Content: "hello"}}, nil, "gemini-2.5-flash", nil)
+	if err == nil || !strings.Contains(err.Error(), "credentials expired") {
+		t.Fatalf("Chat() error = %v, want token source error", err)
+	}
+}
+
 func TestGeminiProvider_ChatAllowsMissingAPIKeyForCustomAPIBase(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if got := r.Header.Get("X-Goog-Api-Key"); got != "" {
@@ -925,3 +988,159 @@ func TestGeminiProvider_ChatAllowsMissingAPIKeyForCustomAPIBase(t *testing.T) {
 		t.Fatalf("Content = %q, want %q", resp.Content, "ok")
 	}
 }
+
+func TestGeminiProvider_BuildRequestBody_ThinkingBudgetOverridesLevel(t *testing.T) {
+	budget := func(v int) *int { return &v }
+	tests := []struct {
+		name         string
+		model        string
+		budget       *int
+		wantBudget   any
+		wantIncludes bool
+	}{
+		{"gemini 2.5 flash", "gemini-2.5-flash", budget(2048), float64(2048), true},
+		{"gemini 3 uses budget instead of level", "gemini-3-flash-preview", budget(512), float64(512), true},
+		{"dynamic", "gemini-2.5-flash", budget(-1), float64(-1), true},
+		{"off", "gemini-2.5-flash", budget(0), float64(0), false},
+		{"pro cannot turn thinking off", "gemini-2.5-pro", budget(0), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewGeminiProvider("test-key", "https://example.com/v1beta", "", "", 0, nil, nil)
+			provider.SetThinkingBudget(tt.budget)
+			body := provider.buildRequestBody(
+				[]Message{{Role: "user", Content: "hello"}},
+				nil,
+				tt.model,
+				map[string]any{"thinking_level": "high"},
+			)
+
+			// Round-trip through JSON so the assertions see the wire format.
+			raw, err := json.Marshal(body)
+			if err != nil {
+				t.Fatalf("marshal request body: %v", err)
+			}
+			var wire map[string]any
+			if err := json.Unmarshal(raw, &wire); err != nil {
+				t.Fatalf("unmarshal request body: %v", err)
+			}
+			thinkingConfig := wire["generationConfig"].(map[string]any)["thinkingConfig"].(map[string]any)
+			if got := thinkingConfig["thinkingBudget"]; got != tt.wantBudget {
+				t.Fatalf("thinkingBudget = %#v, want %#v", got, tt.wantBudget)
+			}
+			if _, hasLevel := thinkingConfig["thinkingLevel"]; hasLevel {
+				t.Fatalf("thinkingLevel should not be sent with a budget: %#v", thinkingConfig)
+			}
+			if got := thinkingConfig["includeThoughts"]; got != tt.wantIncludes {
+				t.Fatalf("includeThoughts = %#v, want %v", got, tt.wantIncludes)
+			}
+		})
+	}
+
+	provider := NewGeminiProvider("test-key", "https://example.com/v1beta", "", "", 0, nil, nil)
+	provider.SetThinkingBudget(budget(1024))
+	body := provider.buildRequestBody([]Message{{Role: "user", Content: "hello"}}, nil, "gemini-2.0-flash", nil)
+	if _, ok := body["generationConfig"]; ok {
+		t.Fatalf("generationConfig should be omitted for models without thinking: %#v", body)
+	}
+}
+
+func TestGeminiProvider_ThinkingRoundTripsThroughHistory(t *testing.T) {
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request body: %v", err)
+		}
+		requests = append(requests, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []any{map[string]any{
+				"content": map[string]any{
+					"role": "model",
+					"parts": []any{
+						map[string]any{"text": "I should look this up.", "thought": true},
+						map[string]any{
+							"functionCall":     map[string]any{"id": "call_1", "name": "search", "args": map[string]any{"q": "tides"}},
+							"thoughtSignature": "sig-abc",
+						},
+					},
+				},
+				"finishReason": "STOP",
+			}},
+			"usageMetadata": map[string]any{
+				"promptTokenCount":     10,
+				"candidatesTokenCount": 4,
+				"thoughtsTokenCount":   20,
+				"totalTokenCount":      34,
+			},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewGeminiProvider("test-key", server.URL, "", "", 0, nil, nil)
+	budget := 4096
+	provider.SetThinkingBudget(&budget)
+
+	history := []Message{{Role: "user", Content: "When is high tide?"}}
+	resp, err := provider.Chat(t.Context(), history, nil, "gemini-2.5-flash", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.ReasoningContent != "I should look this up." {
+		t.Fatalf("ReasoningContent = %q", resp.ReasoningContent)
+	}
+	if resp.Usage == nil || resp.Usage.ReasoningTokens != 20 || resp.Usage.CompletionTokens != 24 {
+		t.Fatalf("Usage = %#v, want 20 reasoning tokens counted in 24 completion tokens", resp.Usage)
+	}
+
+	// Store the turn the way the agent loop does and reload it from the
+	// session JSON, which drops the in-memory-only fields.
+	assistant := Message{Role: "assistant", ReasoningContent: resp.ReasoningContent, ToolCalls: resp.ToolCalls}
+	raw, err := json.Marshal([]Message{assistant, {Role: "tool", ToolCallID: "call_1", Content: "6:42 PM"}})
+	if err != nil {
+		t.Fatalf("marshal history: %v", err)
+	}
+	var stored []Message
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		t.Fatalf("unmarshal history: %v", err)
+	}
+	history = append(history, stored...)
+
+	if _, err := provider.Chat(t.Context(), history, nil, "gemini-2.5-flash", nil); err != nil {
+		t.Fatalf("second Chat() error = %v", err)
+	}
+	contents := requests[1]["contents"].([]any)
+	if len(contents) != 3 {
+		t.Fatalf("contents = %#v, want user, model and function response", contents)
+	}
+	modelParts := contents[1].(map[string]any)["parts"].([]any)
+	if len(modelParts) != 1 {
+		t.Fatalf("model parts = %#v, want only the function call (thoughts are not replayed)", modelParts)
+	}
+	part := modelParts[0].(map[string]any)
+	if part["thoughtSignature"] != "sig-abc" {
+		t.Fatalf("model part = %#v, want thoughtSignature sig-abc", part)
+	}
+	thinkingConfig := requests[1]["generationConfig"].(map[string]any)["thinkingConfig"].(map[string]any)
+	if thinkingConfig["thinkingBudget"] != float64(4096) {
+		t.Fatalf("thinkingConfig = %#v, want the budget on every request", thinkingConfig)
+	}
+}
+
+func TestParseGeminiResponse_SafetyBlocksAreContentFilter(t *testing.T) {
+	tests := map[string]string{
+		"safety finish":  `{"candidates":[{"content":{"role":"model","parts":[]},"finishReason":"SAFETY"}]}`,
+		"blocked prompt": `{"promptFeedback":{"blockReason":"PROHIBITED_CONTENT"}}`,
+	}
+	for name, body := range tests {
+		var resp geminiGenerateContentResponse
+		if err := json.Unmarshal([]byte(body), &resp); err != nil {
+			t.Fatalf("%s: unmarshal: %v", name, err)
+		}
+		if got := parseGeminiResponse(&resp).FinishReason; got != "content_filter" {
+			t.Errorf("%s: FinishReason = %q, want content_filter", name, got)
+		}
+	}
+}
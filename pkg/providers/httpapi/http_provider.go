@@ -89,6 +89,10 @@ func (p *HTTPProvider) SupportsNativeSearch() bool {
 	return p.delegate.SupportsNativeSearch()
 }
 
+func (p *HTTPProvider) SupportsDeveloperRole() bool {
+	return p.delegate.SupportsDeveloperRole()
+}
+
 func (p *HTTPProvider) SupportsThinking() bool {
 	if p == nil || p.delegate == nil {
 		return false
@@ -102,3 +106,12 @@ func (p *HTTPProvider) SetProviderName(providerName string) {
 	}
 	p.delegate.SetProviderName(providerName)
 }
+
+// SetWebSearch enables Zhipu's built-in web_search tool on zhipu and zai
+// providers.
+func (p *HTTPProvider) SetWebSearch(enabled bool) {
+	if p == nil || p.delegate == nil {
+		return
+	}
+	p.delegate.SetWebSearch(enabled)
+}
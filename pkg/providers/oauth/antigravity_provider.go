@@ -37,9 +37,7 @@ type AntigravityProvider struct {
 func NewAntigravityProvider() *AntigravityProvider {
 	return &AntigravityProvider{
 		tokenSource: createAntigravityTokenSource(),
-		httpClient: &http.Client{
-			Timeout: 120 * time.Second,
-		},
+		httpClient:  common.NewHTTPClient(""),
 	}
 }
 
@@ -92,6 +90,8 @@ func (p *AntigravityProvider) Chat(
 	// Build API URL — uses Cloud Code Assist v1internal streaming endpoint
 	apiURL := fmt.Sprintf("%s/v1internal:streamGenerateContent?alt=sse", antigravityBaseURL)
 
+	ctx, cancel := common.WithRequestTimeout(ctx, common.DefaultRequestTimeout)
+	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
@@ -110,7 +110,7 @@ func (p *AntigravityProvider) Chat(
 	req.Header.Set("X-Goog-Api-Client", antigravityXGoogClient)
 	req.Header.Set("Client-Metadata", string(clientMetadata))
 
-	resp, err := p.httpClient.Do(req)
+	resp, err := common.DoBuffered(p.httpClient, req)
 	if err != nil {
 		return nil, fmt.Errorf("antigravity API call: %w", err)
 	}
@@ -422,8 +422,13 @@ func (p *AntigravityProvider) parseSSEResponse(body string) (*LLMResponse, error
 	if len(toolCalls) > 0 {
 		mappedFinish = "tool_calls"
 	}
-	if finishReason == "MAX_TOKENS" {
+	switch finishReason {
+	case "MAX_TOKENS":
 		mappedFinish = "length"
+	case "SAFETY", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII":
+		if len(toolCalls) == 0 {
+			mappedFinish = "content_filter"
+		}
 	}
 
 	return &LLMResponse{
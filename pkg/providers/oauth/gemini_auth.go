@@ -0,0 +1,43 @@
+package oauthprovider
+
+import (
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/auth"
+)
+
+// refreshGoogleCredential is replaced in tests.
+var refreshGoogleCredential = auth.RefreshAccessToken
+
+// CreateGeminiTokenSource returns a token source for the Gemini provider
+// backed by the "google" credential from `picoclaw auth login --provider
+// google`. Tokens close to expiry are refreshed and saved back to the store.
+func CreateGeminiTokenSource(
+	getCredential func(string) (*auth.AuthCredential, error),
+) func() (string, string, error) {
+	return func() (string, string, error) {
+		cred, err := getCredential("google")
+		if err != nil {
+			return "", "", fmt.Errorf("loading auth credentials: %w", err)
+		}
+		if cred == nil {
+			return "", "", fmt.Errorf("no credentials for google. Run: picoclaw auth login --provider google")
+		}
+
+		if cred.NeedsRefresh() && cred.RefreshToken != "" {
+			refreshed, err := refreshGoogleCredential(cred, auth.GoogleOAuthConfig())
+			if err != nil {
+				return "", "", fmt.Errorf("refreshing token: %w", err)
+			}
+			if err := auth.SetCredential("google", refreshed); err != nil {
+				return "", "", fmt.Errorf("saving refreshed token: %w", err)
+			}
+			cred = refreshed
+		}
+
+		if cred.IsExpired() {
+			return "", "", fmt.Errorf("google credentials expired. Run: picoclaw auth login --provider google")
+		}
+		return cred.AccessToken, cred.ProjectID, nil
+	}
+}
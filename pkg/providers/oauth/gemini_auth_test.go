@@ -0,0 +1,82 @@
+package oauthprovider
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/auth"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestGeminiTokenSource_ReturnsStoredToken(t *testing.T) {
+	source := CreateGeminiTokenSource(func(provider string) (*auth.AuthCredential, error) {
+		if provider != "google" {
+			t.Fatalf("provider = %q, want google", provider)
+		}
+		return &auth.AuthCredential{
+			AccessToken: "google-token",
+			ProjectID:   "quota-project",
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}, nil
+	})
+
+	token, projectID, err := source()
+	if err != nil {
+		t.Fatalf("source() error = %v", err)
+	}
+	if token != "google-token" || projectID != "quota-project" {
+		t.Fatalf("source() = %q, %q; want google-token, quota-project", token, projectID)
+	}
+}
+
+func TestGeminiTokenSource_RefreshesAndSavesExpiringToken(t *testing.T) {
+	t.Setenv(config.EnvHome, filepath.Join(t.TempDir(), ".picoclaw"))
+
+	original := refreshGoogleCredential
+	t.Cleanup(func() { refreshGoogleCredential = original })
+	refreshGoogleCredential = func(cred *auth.AuthCredential, _ auth.OAuthProviderConfig) (*auth.AuthCredential, error) {
+		if cred.RefreshToken != "refresh" {
+			t.Fatalf("RefreshToken = %q, want refresh", cred.RefreshToken)
+		}
+		return &auth.AuthCredential{
+			AccessToken:  "fresh-token",
+			RefreshToken: "refresh",
+			ExpiresAt:    time.Now().Add(time.Hour),
+			Provider:     "google",
+			AuthMethod:   "oauth",
+			ProjectID:    cred.ProjectID,
+		}, nil
+	}
+
+	source := CreateGeminiTokenSource(func(string) (*auth.AuthCredential, error) {
+		return &auth.AuthCredential{
+			AccessToken:  "stale-token",
+			RefreshToken: "refresh",
+			ExpiresAt:    time.Now().Add(time.Minute),
+			ProjectID:    "quota-project",
+		}, nil
+	})
+
+	token, projectID, err := source()
+	if err != nil {
+		t.Fatalf("source() error = %v", err)
+	}
+	if token != "fresh-token" || projectID != "quota-project" {
+		t.Fatalf("source() = %q, %q; want fresh-token, quota-project", token, projectID)
+	}
+	saved, err := auth.GetCredential("google")
+	if err != nil || saved == nil || saved.AccessToken != "fresh-token" {
+		t.Fatalf("saved credential = %+v, %v; want fresh-token", saved, err)
+	}
+}
+
+func TestGeminiTokenSource_MissingCredential(t *testing.T) {
+	source := CreateGeminiTokenSource(func(string) (*auth.AuthCredential, error) { return nil, nil })
+
+	_, _, err := source()
+	if err == nil || !strings.Contains(err.Error(), "auth login --provider google") {
+		t.Fatalf("source() error = %v, want login hint", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"maps"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -40,9 +41,11 @@ type Provider struct {
 	providerName   string
 	maxTokensField string // Field name for max tokens (e.g., "max_completion_tokens" for o1/glm models)
 	httpClient     *http.Client
+	requestTimeout time.Duration  // bounds non-streaming calls
 	extraBody      map[string]any // Additional fields to inject into request body
 	customHeaders  map[string]string
 	userAgent      string
+	webSearch      bool // Zhipu built-in web_search tool, see SetWebSearch
 }
 
 type Option func(*Provider)
@@ -86,7 +89,7 @@ func WithUserAgent(userAgent string) Option {
 func WithRequestTimeout(timeout time.Duration) Option {
 	return func(p *Provider) {
 		if timeout > 0 {
-			p.httpClient.Timeout = timeout
+			p.requestTimeout = timeout
 		}
 	}
 }
@@ -111,9 +114,10 @@ func WithProviderName(providerName string) Option {
 
 func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
 	p := &Provider{
-		apiKey:     apiKey,
-		apiBase:    strings.TrimRight(apiBase, "/"),
-		httpClient: common.NewHTTPClient(proxy),
+		apiKey:         apiKey,
+		apiBase:        strings.TrimRight(apiBase, "/"),
+		httpClient:     common.NewHTTPClient(proxy),
+		requestTimeout: defaultRequestTimeout,
 	}
 
 	for _, opt := range opts {
@@ -155,9 +159,12 @@ func (p *Provider) buildRequestBody(
 
 	// When fallback uses a different provider (e.g. DeepSeek), that provider must not inject web_search_preview.
 	nativeSearch, _ := options["native_search"].(bool)
-	nativeSearch = nativeSearch && isNativeSearchHost(p.apiBase)
-	if len(tools) > 0 || nativeSearch {
-		requestBody["tools"] = buildToolsList(tools, nativeSearch)
+	var searchTool map[string]any
+	if nativeSearch && p.SupportsNativeSearch() {
+		searchTool = p.nativeSearchTool()
+	}
+	if len(tools) > 0 || searchTool != nil {
+		requestBody["tools"] = buildToolsList(tools, searchTool)
 		requestBody["tool_choice"] = "auto"
 	}
 
@@ -184,6 +191,12 @@ func (p *Provider) buildRequestBody(
 		}
 	}
 
+	// Stop sequences configured on the model_list entry arrive through
+	// extraBody below and take precedence over these.
+	if stop, ok := options["stop"].([]string); ok && len(stop) > 0 {
+		requestBody["stop"] = stop
+	}
+
 	// Prompt caching: pass a stable cache key so OpenAI can bucket requests
 	// with the same key and reuse prefix KV cache across calls.
 	// Prompt caching is only supported by OpenAI-native endpoints.
@@ -322,6 +335,17 @@ func (p *Provider) SetProviderName(providerName string) {
 	p.providerName = strings.ToLower(strings.TrimSpace(providerName))
 }
 
+// SetWebSearch enables Zhipu's built-in web_search tool. It only takes effect
+// for the zhipu and zai providers, which then report native search support so
+// the agent swaps the client-side web_search tool for the built-in one.
+func (p *Provider) SetWebSearch(enabled bool) {
+	p.webSearch = enabled
+}
+
+func (p *Provider) isZhipu() bool {
+	return p.providerName == "zhipu" || p.providerName == "zai"
+}
+
 func (p *Provider) SupportsThinking() bool {
 	return strings.EqualFold(strings.TrimSpace(p.providerName), "deepseek") || isDeepSeekHost(p.apiBase)
 }
@@ -334,7 +358,47 @@ func (p *Provider) prepareMessagesForRequest(messages []Message) []Message {
 	if p.requiresToolRoundReasoningReplay() {
 		return filterReasoningReplayMessages(messages)
 	}
-	return stripReasoningMessages(messages)
+	messages = stripReasoningMessages(messages)
+	if p.isZhipu() {
+		return mergeSystemMessages(messages)
+	}
+	return messages
+}
+
+// mergeSystemMessages folds every system message into the first one. GLM
+// rejects requests with more than one system message, which hooks and
+// mid-conversation notes can otherwise produce.
+func mergeSystemMessages(messages []Message) []Message {
+	first := -1
+	var parts []string
+	for i, msg := range messages {
+		if msg.Role != "system" {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		if strings.TrimSpace(msg.Content) != "" {
+			parts = append(parts, msg.Content)
+		}
+	}
+	if first < 0 {
+		return messages
+	}
+
+	out := make([]Message, 0, len(messages))
+	for i, msg := range messages {
+		if msg.Role != "system" {
+			out = append(out, msg)
+			continue
+		}
+		if i == first {
+			msg.Content = strings.Join(parts, "\n\n")
+			msg.SystemParts = nil
+			out = append(out, msg)
+		}
+	}
+	return out
 }
 
 func (p *Provider) requiresToolRoundReasoningReplay() bool {
@@ -461,6 +525,8 @@ func (p *Provider) Chat(
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	ctx, cancel := common.WithRequestTimeout(ctx, p.requestTimeout)
+	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/chat/completions", bytes.NewReader(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -475,7 +541,7 @@ func (p *Provider) Chat(
 	}
 	p.applyCustomHeaders(req)
 
-	resp, err := p.httpClient.Do(req)
+	resp, err := common.DoBuffered(p.httpClient, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -547,11 +613,9 @@ func (p *Provider) ChatStreamEvents(
 	}
 	p.applyCustomHeaders(req)
 
-	// Use a client without Timeout for streaming — the http.Client.Timeout covers
-	// the entire request lifecycle including body reads, which would kill long streams.
-	// Context cancellation still provides the safety net.
-	streamClient := &http.Client{Transport: p.httpClient.Transport}
-	resp, err := streamClient.Do(req)
+	// Streams are not bounded by requestTimeout, which would kill long
+	// responses; context cancellation and the read idle timeout apply instead.
+	resp, err := p.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -604,6 +668,7 @@ func parseStreamResponse(
 	onChunk func(StreamChunk),
 ) (*LLMResponse, error) {
 	var textContent strings.Builder
+	var refusal strings.Builder
 	var reasoningContent strings.Builder
 	var reasoning strings.Builder
 	var reasoningDetails []ReasoningDetail
@@ -630,12 +695,14 @@ func parseStreamResponse(
 			Choices []struct {
 				Delta struct {
 					Content          string            `json:"content"`
+					Refusal          string            `json:"refusal"`
 					ReasoningContent string            `json:"reasoning_content"`
 					Reasoning        string            `json:"reasoning"`
 					ReasoningDetails []ReasoningDetail `json:"reasoning_details"`
 					ToolCalls        []struct {
 						Index    int    `json:"index"`
 						ID       string `json:"id"`
+						Type     string `json:"type"`
 						Function *struct {
 							Name      string `json:"name"`
 							Arguments string `json:"arguments"`
@@ -644,7 +711,7 @@ func parseStreamResponse(
 				} `json:"delta"`
 				FinishReason *string `json:"finish_reason"`
 			} `json:"choices"`
-			Usage *UsageInfo `json:"usage"`
+			Usage *common.APIUsage `json:"usage"`
 		}
 
 		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
@@ -652,7 +719,7 @@ func parseStreamResponse(
 		}
 
 		if chunk.Usage != nil {
-			usage = chunk.Usage
+			usage = chunk.Usage.Info()
 		}
 
 		if len(chunk.Choices) == 0 {
@@ -685,8 +752,13 @@ func parseStreamResponse(
 			}
 		}
 
+		refusal.WriteString(choice.Delta.Refusal)
+
 		// Accumulate tool call deltas
 		for _, tc := range choice.Delta.ToolCalls {
+			if !common.IsFunctionToolCall(tc.Type) {
+				continue
+			}
 			acc, ok := activeTools[tc.Index]
 			if !ok {
 				acc = &toolAccum{}
@@ -759,12 +831,10 @@ func parseStreamResponse(
 	}
 
 	// Assemble tool calls from accumulated deltas
+	// Indices can have gaps when built-in tool calls were skipped above.
 	var toolCalls []ToolCall
-	for i := 0; i < len(activeTools); i++ {
-		acc, ok := activeTools[i]
-		if !ok {
-			continue
-		}
+	for _, i := range slices.Sorted(maps.Keys(activeTools)) {
+		acc := activeTools[i]
 		args := make(map[string]any)
 		raw := acc.argsJSON.String()
 		if raw != "" {
@@ -783,9 +853,13 @@ func parseStreamResponse(
 	if finishReason == "" {
 		finishReason = "stop"
 	}
+	content := textContent.String()
+	if text := strings.TrimSpace(refusal.String()); text != "" && strings.TrimSpace(content) == "" {
+		content, finishReason = text, "content_filter"
+	}
 
 	return &LLMResponse{
-		Content:          textContent.String(),
+		Content:          content,
 		ReasoningContent: reasoningContent.String(),
 		Reasoning:        reasoning.String(),
 		ReasoningDetails: reasoningDetails,
@@ -813,24 +887,51 @@ func normalizeModel(model, apiBase string) string {
 	return model
 }
 
-func buildToolsList(tools []ToolDefinition, nativeSearch bool) []any {
+// buildToolsList returns tools followed by searchTool, the provider's
+// built-in search entry. When searchTool is set, the client-side web_search
+// tool is dropped so the model sees a single search surface.
+func buildToolsList(tools []ToolDefinition, searchTool map[string]any) []any {
 	result := make([]any, 0, len(tools)+1)
 	for _, t := range tools {
-		if nativeSearch && strings.EqualFold(t.Function.Name, "web_search") {
+		if searchTool != nil && strings.EqualFold(t.Function.Name, "web_search") {
 			continue
 		}
 		result = append(result, t)
 	}
-	if nativeSearch {
-		result = append(result, map[string]any{"type": "web_search_preview"})
+	if searchTool != nil {
+		result = append(result, searchTool)
 	}
 	return result
 }
 
+// nativeSearchTool returns the built-in search tool entry for this endpoint.
+func (p *Provider) nativeSearchTool() map[string]any {
+	if p.isZhipu() {
+		return map[string]any{
+			"type": "web_search",
+			"web_search": map[string]any{
+				"enable":        true,
+				"search_result": true,
+			},
+		}
+	}
+	return map[string]any{"type": "web_search_preview"}
+}
+
 func (p *Provider) SupportsNativeSearch() bool {
+	if p.isZhipu() {
+		return p.webSearch
+	}
 	return isNativeSearchHost(p.apiBase)
 }
 
+// SupportsDeveloperRole reports whether the endpoint accepts "developer"
+// messages. Third-party OpenAI-compatible servers often reject unknown roles
+// or more than one leading system message, so only OpenAI and Azure qualify.
+func (p *Provider) SupportsDeveloperRole() bool {
+	return isNativeOpenAIOrAzureEndpoint(p.apiBase)
+}
+
 // isNativeOpenAIOrAzureEndpoint reports whether the given API base points to
 // OpenAI's own API or an Azure OpenAI deployment.
 func isNativeOpenAIOrAzureEndpoint(apiBase string) bool {
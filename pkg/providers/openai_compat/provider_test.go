@@ -2,7 +2,9 @@ package openai_compat
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -1010,6 +1012,45 @@ func TestProviderChat_SuccessResponseUsesStreamingDecoder(t *testing.T) {
 	}
 }
 
+func TestProviderChat_GzipBodyAndErrorObject(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write([]byte(`{"choices":[{"message":{"content":"hi"},"finish_reason":"stop"}]}`))
+	_ = zw.Close()
+
+	p := NewProvider("key", "https://example.com/v1", "")
+	p.httpClient = &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header: http.Header{
+					"Content-Type":     []string{"application/json"},
+					"Content-Encoding": []string{"gzip"},
+				},
+				Body: io.NopCloser(bytes.NewReader(gz.Bytes())),
+			}, nil
+		}),
+	}
+	out, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil)
+	if err != nil || out.Content != "hi" {
+		t.Fatalf("Chat() = %+v, %v; want the gzipped reply", out, err)
+	}
+
+	p.httpClient = &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"upstream overloaded","code":502}}`)),
+			}, nil
+		}),
+	}
+	_, err = p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil)
+	if !errors.Is(err, common.ErrProviderErrorResponse) || !strings.Contains(err.Error(), "upstream overloaded") {
+		t.Fatalf("Chat() error = %v, want the provider's error object", err)
+	}
+}
+
 func TestProviderChat_LargeHTMLResponsePreviewIsTruncated(t *testing.T) {
 	body := append([]byte("<!DOCTYPE html><html><body>"), bytes.Repeat([]byte("A"), 2048)...)
 	body = append(body, []byte("</body></html>")...)
@@ -1272,15 +1313,15 @@ func TestNormalizeModel_UsesAPIBase(t *testing.T) {
 
 func TestProvider_RequestTimeoutDefault(t *testing.T) {
 	p := NewProviderWithMaxTokensFieldAndTimeout("key", "https://example.com/v1", "", "", 0)
-	if p.httpClient.Timeout != defaultRequestTimeout {
-		t.Fatalf("http timeout = %v, want %v", p.httpClient.Timeout, defaultRequestTimeout)
+	if p.requestTimeout != defaultRequestTimeout {
+		t.Fatalf("http timeout = %v, want %v", p.requestTimeout, defaultRequestTimeout)
 	}
 }
 
 func TestProvider_RequestTimeoutOverride(t *testing.T) {
 	p := NewProviderWithMaxTokensFieldAndTimeout("key", "https://example.com/v1", "", "", 300)
-	if p.httpClient.Timeout != 300*time.Second {
-		t.Fatalf("http timeout = %v, want %v", p.httpClient.Timeout, 300*time.Second)
+	if p.requestTimeout != 300*time.Second {
+		t.Fatalf("http timeout = %v, want %v", p.requestTimeout, 300*time.Second)
 	}
 }
 
@@ -1759,15 +1800,15 @@ func TestProvider_FunctionalOptionMaxTokensField(t *testing.T) {
 
 func TestProvider_FunctionalOptionRequestTimeout(t *testing.T) {
 	p := NewProvider("key", "https://example.com/v1", "", WithRequestTimeout(45*time.Second))
-	if p.httpClient.Timeout != 45*time.Second {
-		t.Fatalf("http timeout = %v, want %v", p.httpClient.Timeout, 45*time.Second)
+	if p.requestTimeout != 45*time.Second {
+		t.Fatalf("http timeout = %v, want %v", p.requestTimeout, 45*time.Second)
 	}
 }
 
 func TestProvider_FunctionalOptionRequestTimeoutNonPositive(t *testing.T) {
 	p := NewProvider("key", "https://example.com/v1", "", WithRequestTimeout(-1*time.Second))
-	if p.httpClient.Timeout != defaultRequestTimeout {
-		t.Fatalf("http timeout = %v, want %v", p.httpClient.Timeout, defaultRequestTimeout)
+	if p.requestTimeout != defaultRequestTimeout {
+		t.Fatalf("http timeout = %v, want %v", p.requestTimeout, defaultRequestTimeout)
 	}
 }
 
@@ -1956,7 +1997,7 @@ func TestBuildToolsList_NativeSearchAddsWebSearchPreview(t *testing.T) {
 	tools := []ToolDefinition{
 		{Type: "function", Function: ToolFunctionDefinition{Name: "read_file", Description: "read"}},
 	}
-	result := buildToolsList(tools, true)
+	result := buildToolsList(tools, map[string]any{"type": "web_search_preview"})
 	if len(result) != 2 {
 		t.Fatalf("len(result) = %d, want 2", len(result))
 	}
@@ -1974,7 +2015,7 @@ func TestBuildToolsList_NativeSearchFiltersClientWebSearch(t *testing.T) {
 		{Type: "function", Function: ToolFunctionDefinition{Name: "web_search", Description: "search"}},
 		{Type: "function", Function: ToolFunctionDefinition{Name: "read_file", Description: "read"}},
 	}
-	result := buildToolsList(tools, true)
+	result := buildToolsList(tools, map[string]any{"type": "web_search_preview"})
 	for _, entry := range result {
 		if td, ok := entry.(ToolDefinition); ok && strings.EqualFold(td.Function.Name, "web_search") {
 			t.Fatal("client-side web_search should be filtered out when native search is enabled")
@@ -1990,7 +2031,7 @@ func TestBuildToolsList_NoNativeSearchPassesThrough(t *testing.T) {
 		{Type: "function", Function: ToolFunctionDefinition{Name: "web_search", Description: "search"}},
 		{Type: "function", Function: ToolFunctionDefinition{Name: "read_file", Description: "read"}},
 	}
-	result := buildToolsList(tools, false)
+	result := buildToolsList(tools, nil)
 	if len(result) != 2 {
 		t.Fatalf("len(result) = %d, want 2", len(result))
 	}
@@ -2030,6 +2071,120 @@ func TestSupportsNativeSearch_NonOpenAI(t *testing.T) {
 	}
 }
 
+func TestSupportsNativeSearch_ZhipuRequiresWebSearch(t *testing.T) {
+	p := NewProvider("key", "https://open.bigmodel.cn/api/paas/v4", "")
+	p.SetProviderName("zhipu")
+	if p.SupportsNativeSearch() {
+		t.Fatal("Zhipu provider should not support native search unless web search is enabled")
+	}
+	p.SetWebSearch(true)
+	if !p.SupportsNativeSearch() {
+		t.Fatal("Zhipu provider with web search enabled should support native search")
+	}
+}
+
+func TestBuildRequestBody_ZhipuWebSearchTool(t *testing.T) {
+	p := NewProvider("key", "https://open.bigmodel.cn/api/paas/v4", "")
+	p.SetProviderName("zhipu")
+	p.SetWebSearch(true)
+	tools := []ToolDefinition{
+		{Type: "function", Function: ToolFunctionDefinition{Name: "web_search", Description: "search"}},
+		{Type: "function", Function: ToolFunctionDefinition{Name: "read_file", Description: "read"}},
+	}
+
+	body := p.buildRequestBody(
+		[]Message{{Role: "user", Content: "news?"}}, tools, "glm-4.7", map[string]any{"native_search": true},
+	)
+	list, ok := body["tools"].([]any)
+	if !ok || len(list) != 2 {
+		t.Fatalf("tools = %#v, want read_file + web_search", body["tools"])
+	}
+	if td, ok := list[0].(ToolDefinition); !ok || td.Function.Name != "read_file" {
+		t.Fatalf("tools[0] = %#v, want read_file", list[0])
+	}
+	search, _ := list[1].(map[string]any)
+	if search["type"] != "web_search" {
+		t.Fatalf("tools[1].type = %v, want web_search", search["type"])
+	}
+	if opts, _ := search["web_search"].(map[string]any); opts["enable"] != true {
+		t.Fatalf("tools[1].web_search = %#v, want enable=true", search["web_search"])
+	}
+
+	body = p.buildRequestBody([]Message{{Role: "user", Content: "news?"}}, tools, "glm-4.7", nil)
+	if list := body["tools"].([]any); len(list) != 2 {
+		t.Fatalf("without native_search tools = %#v, want the client-side tools unchanged", list)
+	}
+}
+
+func TestBuildRequestBody_ZhipuMergesSystemMessages(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "You are helpful."},
+		{Role: "user", Content: "hi"},
+		{Role: "system", Content: "Reply in Chinese."},
+		{Role: "user", Content: "again"},
+	}
+
+	p := NewProvider("key", "https://open.bigmodel.cn/api/paas/v4", "")
+	p.SetProviderName("zhipu")
+	got := p.prepareMessagesForRequest(messages)
+	if len(got) != 3 {
+		t.Fatalf("len(messages) = %d, want 3: %#v", len(got), got)
+	}
+	if got[0].Role != "system" || got[0].Content != "You are helpful.\n\nReply in Chinese." {
+		t.Fatalf("messages[0] = %#v, want merged system message", got[0])
+	}
+	if got[1].Content != "hi" || got[2].Content != "again" {
+		t.Fatalf("conversation order changed: %#v", got)
+	}
+
+	other := NewProvider("key", "https://api.example.com/v1", "")
+	if got := other.prepareMessagesForRequest(messages); len(got) != 4 {
+		t.Fatalf("non-Zhipu provider merged system messages: %#v", got)
+	}
+}
+
+func TestParseStreamResponse_SkipsBuiltinToolCalls(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"ws_1","type":"web_search","web_search":{"search_result":[]}}]}}]}`,
+		``,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":1,"id":"call_1","type":"function","function":{"name":"read_file","arguments":"{\"path\":"}}]}}]}`,
+		``,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":1,"function":{"arguments":"\"a.txt\"}"}}]},"finish_reason":"tool_calls"}],` +
+			`"usage":{"prompt_tokens":20,"completion_tokens":4,"total_tokens":24,"prompt_tokens_details":{"cached_tokens":16}}}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	resp, err := parseStreamResponse(t.Context(), strings.NewReader(stream), nil)
+	if err != nil {
+		t.Fatalf("parseStreamResponse() error = %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "read_file" || resp.ToolCalls[0].Arguments["path"] != "a.txt" {
+		t.Fatalf("ToolCalls = %#v, want only read_file(a.txt)", resp.ToolCalls)
+	}
+	if resp.Usage == nil || resp.Usage.CacheReadTokens != 16 {
+		t.Fatalf("Usage = %#v, want CacheReadTokens 16", resp.Usage)
+	}
+}
+
+func TestSupportsDeveloperRole(t *testing.T) {
+	tests := []struct {
+		apiBase string
+		want    bool
+	}{
+		{"https://api.openai.com/v1", true},
+		{"https://myres.openai.azure.com/openai/deployments/gpt-4o", true},
+		{"https://open.bigmodel.cn/api/paas/v4", false},
+		{"http://localhost:11434/v1", false},
+	}
+	for _, tt := range tests {
+		if got := NewProvider("key", tt.apiBase, "").SupportsDeveloperRole(); got != tt.want {
+			t.Errorf("SupportsDeveloperRole(%q) = %v, want %v", tt.apiBase, got, tt.want)
+		}
+	}
+}
+
 func TestProviderChat_NativeSearchToolInjected(t *testing.T) {
 	var requestBody map[string]any
 
@@ -2196,3 +2351,20 @@ func TestSerializeMessages_StripsSystemParts(t *testing.T) {
 		t.Fatal("system_parts should not appear in serialized output")
 	}
 }
+
+func TestBuildRequestBody_StopOptionYieldsToExtraBody(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "hi"}}
+	options := map[string]any{"stop": []string{"</answer>"}}
+
+	body := NewProvider("key", "https://example.com/v1", "").buildRequestBody(messages, nil, "m", options)
+	if got, ok := body["stop"].([]string); !ok || len(got) != 1 || got[0] != "</answer>" {
+		t.Fatalf("stop = %#v, want option value", body["stop"])
+	}
+
+	extraBody := map[string]any{"stop": []string{"END"}}
+	p := NewProvider("key", "https://example.com/v1", "", WithExtraBody(extraBody))
+	body = p.buildRequestBody(messages, nil, "m", options)
+	if got, ok := body["stop"].([]string); !ok || len(got) != 1 || got[0] != "END" {
+		t.Fatalf("stop = %#v, want extra_body value", body["stop"])
+	}
+}
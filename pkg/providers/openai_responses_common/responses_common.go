@@ -25,6 +25,13 @@ func TranslateMessages(messages []protocoltypes.Message) (input responses.Respon
 		switch msg.Role {
 		case "system":
 			instructions = msg.Content
+		case "developer":
+			input = append(input, responses.ResponseInputItemUnionParam{
+				OfMessage: &responses.EasyInputMessageParam{
+					Role:    responses.EasyInputMessageRoleDeveloper,
+					Content: responses.EasyInputMessageContentUnionParam{OfString: openai.Opt(msg.Content)},
+				},
+			})
 		case "user":
 			if msg.ToolCallID != "" {
 				input = append(input, responses.ResponseInputItemUnionParam{
@@ -217,6 +224,7 @@ func parseResponse(apiResp *responses.Response) *protocoltypes.LLMResponse {
 	var content strings.Builder
 	var reasoningContent strings.Builder
 	var toolCalls []protocoltypes.ToolCall
+	var answered, refused bool
 
 	for _, item := range apiResp.Output {
 		switch item.Type {
@@ -225,8 +233,10 @@ func parseResponse(apiResp *responses.Response) *protocoltypes.LLMResponse {
 				switch c.Type {
 				case "output_text":
 					content.WriteString(c.Text)
+					answered = answered || strings.TrimSpace(c.Text) != ""
 				case "refusal":
 					content.WriteString(c.Refusal)
+					refused = true
 				}
 			}
 		case "function_call":
@@ -250,9 +260,15 @@ func parseResponse(apiResp *responses.Response) *protocoltypes.LLMResponse {
 	if len(toolCalls) > 0 {
 		finishReason = "tool_calls"
 	}
+	if refused && !answered && len(toolCalls) == 0 {
+		finishReason = "content_filter"
+	}
 	switch apiResp.Status {
 	case responses.ResponseStatusIncomplete:
 		finishReason = "length"
+		if apiResp.IncompleteDetails.Reason == "content_filter" {
+			finishReason = "content_filter"
+		}
 	case responses.ResponseStatusFailed:
 		finishReason = "error"
 	case responses.ResponseStatusCancelled:
@@ -30,6 +30,27 @@ func TestTranslateMessages_SystemExtractedAsInstructions(t *testing.T) {
 	}
 }
 
+func TestTranslateMessages_DeveloperKeptAsInputMessage(t *testing.T) {
+	msgs := []protocoltypes.Message{
+		{Role: "system", Content: "You are helpful"},
+		{Role: "developer", Content: "Current time: 10:00"},
+		{Role: "user", Content: "Hi"},
+	}
+	input, instructions := TranslateMessages(msgs)
+	if instructions != "You are helpful" {
+		t.Errorf("instructions = %q, want %q", instructions, "You are helpful")
+	}
+	if len(input) != 2 {
+		t.Fatalf("len(input) = %d, want 2", len(input))
+	}
+	if input[0].OfMessage == nil || input[0].OfMessage.Role != responses.EasyInputMessageRoleDeveloper {
+		t.Fatalf("input[0] = %+v, want developer message", input[0])
+	}
+	if got := input[0].OfMessage.Content.OfString.Value; got != "Current time: 10:00" {
+		t.Errorf("developer content = %q", got)
+	}
+}
+
 func TestTranslateMessages_UserTextMessage(t *testing.T) {
 	msgs := []protocoltypes.Message{
 		{Role: "user", Content: "Hello"},
@@ -439,6 +460,9 @@ func TestParseResponseBody_Refusal(t *testing.T) {
 	if result.Content != "I cannot help with that." {
 		t.Errorf("Content = %q, want %q", result.Content, "I cannot help with that.")
 	}
+	if result.FinishReason != "content_filter" {
+		t.Errorf("FinishReason = %q, want %q", result.FinishReason, "content_filter")
+	}
 }
 
 func TestParseResponseBody_IncompleteStatus(t *testing.T) {
@@ -53,6 +53,14 @@ type UsageInfo struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+	// Prompt-cache accounting for providers that report it. Both counts are
+	// already part of PromptTokens; they say how much of it was written to or
+	// served from the provider's cache.
+	CacheCreationTokens int `json:"cache_creation_tokens,omitempty"`
+	CacheReadTokens     int `json:"cache_read_tokens,omitempty"`
+	// ReasoningTokens is the part of CompletionTokens the model spent
+	// thinking, for providers that report it.
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
 }
 
 // CacheControl marks a content block for LLM-side prefix caching.
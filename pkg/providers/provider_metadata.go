@@ -77,6 +77,18 @@ var modelProviderOptionsByName = map[string]ModelProviderOption{
 		Aliases:             []string{"google"},
 		httpAPI:             true,
 	},
+	"cohere": {
+		ID:                  "cohere",
+		DisplayName:         "Cohere",
+		IconSlug:            "cohere",
+		Domain:              "cohere.com",
+		DefaultAPIBase:      "https://api.cohere.com/v2",
+		CreateAllowed:       true,
+		DefaultModelAllowed: true,
+		Priority:            86,
+		CommonModels:        []string{"command-a-03-2025", "command-r-plus-08-2024", "command-r7b-12-2024"},
+		httpAPI:             true,
+	},
 	"deepseek": {
 		ID:                  "deepseek",
 		DisplayName:         "DeepSeek",
@@ -251,6 +263,22 @@ var modelProviderOptionsByName = map[string]ModelProviderOption{
 		CommonModels:        []string{"gpt-oss-120b", "zai-glm-4.7"},
 		httpAPI:             true,
 	},
+	"fireworks": {
+		ID:                  "fireworks",
+		DisplayName:         "Fireworks AI",
+		Domain:              "fireworks.ai",
+		DefaultAPIBase:      "https://api.fireworks.ai/inference/v1",
+		CreateAllowed:       true,
+		DefaultModelAllowed: true,
+		SupportsFetch:       true,
+		Priority:            61.5,
+		CommonModels: []string{
+			"accounts/fireworks/models/deepseek-v3p1",
+			"accounts/fireworks/models/kimi-k2-instruct",
+			"accounts/fireworks/models/qwen3-coder-480b-a35b-instruct",
+		},
+		httpAPI: true,
+	},
 	"azure": {
 		ID:                  "azure",
 		DisplayName:         "Azure OpenAI",
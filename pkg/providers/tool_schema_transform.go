@@ -100,6 +100,11 @@ func (p *toolSchemaTransformProvider) SupportsNativeSearch() bool {
 	return ok && ns.SupportsNativeSearch()
 }
 
+func (p *toolSchemaTransformProvider) SupportsDeveloperRole() bool {
+	dc, ok := p.delegate.(DeveloperRoleCapable)
+	return ok && dc.SupportsDeveloperRole()
+}
+
 func (p *toolSchemaTransformProvider) Close() {
 	if stateful, ok := p.delegate.(StatefulProvider); ok {
 		stateful.Close()
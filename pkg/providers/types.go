@@ -80,6 +80,16 @@ type NativeSearchCapable interface {
 	SupportsNativeSearch() bool
 }
 
+// DeveloperRoleCapable is an optional interface for providers whose API
+// accepts a "developer" message alongside the system prompt (OpenAI chat
+// completions and the Responses API). When it returns true the agent loop
+// keeps the persistent persona in the system message and sends per-turn
+// instructions as a separate developer message; other providers receive
+// both combined in a single system message.
+type DeveloperRoleCapable interface {
+	SupportsDeveloperRole() bool
+}
+
 // FailoverReason classifies why an LLM request failed for fallback decisions.
 type FailoverReason string
 
@@ -92,6 +102,7 @@ const (
 	FailoverFormat          FailoverReason = "format"
 	FailoverContextOverflow FailoverReason = "context_overflow"
 	FailoverOverloaded      FailoverReason = "overloaded"
+	FailoverContentPolicy   FailoverReason = "content_policy"
 	FailoverUnknown         FailoverReason = "unknown"
 )
 
@@ -114,9 +125,11 @@ func (e *FailoverError) Unwrap() error {
 }
 
 // IsRetriable returns true if this error should trigger fallback to next candidate.
-// Non-retriable: Format errors (bad request structure, image dimension/size).
+// Non-retriable: Format errors (bad request structure, image dimension/size),
+// context overflow, and content policy refusals (see FallbackChain.SetRefusalFallback).
 func (e *FailoverError) IsRetriable() bool {
-	return e.Reason != FailoverFormat && e.Reason != FailoverContextOverflow
+	return e.Reason != FailoverFormat && e.Reason != FailoverContextOverflow &&
+		e.Reason != FailoverContentPolicy
 }
 
 // ModelConfig holds primary model and fallback list.
@@ -37,6 +37,13 @@ func NewRouteResolver(cfg *config.Config) *RouteResolver {
 // inbound context and returns the session policy that should be used to
 // allocate session state.
 func (r *RouteResolver) ResolveRoute(inbound bus.InboundContext) ResolvedRoute {
+	route, _ := r.ExplainRoute(inbound)
+	return route
+}
+
+// ExplainRoute resolves the route like ResolveRoute and also returns the
+// dispatch rule that selected it, or nil when the default agent was used.
+func (r *RouteResolver) ExplainRoute(inbound bus.InboundContext) (ResolvedRoute, *config.DispatchRule) {
 	channel := strings.ToLower(strings.TrimSpace(inbound.Channel))
 	accountID := NormalizeAccountID(inbound.Account)
 	identityLinks := cloneIdentityLinks(r.cfg.Session.IdentityLinks)
@@ -49,7 +56,7 @@ func (r *RouteResolver) ResolveRoute(inbound bus.InboundContext) ResolvedRoute {
 			AccountID:     accountID,
 			SessionPolicy: r.sessionPolicy(rule),
 			MatchedBy:     matchedByForRule(rule),
-		}
+		}, rule
 	}
 
 	return ResolvedRoute{
@@ -58,7 +65,44 @@ func (r *RouteResolver) ResolveRoute(inbound bus.InboundContext) ResolvedRoute {
 		AccountID:     accountID,
 		SessionPolicy: r.sessionPolicy(nil),
 		MatchedBy:     "default",
+	}, nil
+}
+
+// DispatchRules returns the configured dispatch rules in evaluation order.
+func (r *RouteResolver) DispatchRules() []config.DispatchRule {
+	if r.cfg == nil || r.cfg.Agents.Dispatch == nil {
+		return nil
+	}
+	rules := make([]config.DispatchRule, len(r.cfg.Agents.Dispatch.Rules))
+	copy(rules, r.cfg.Agents.Dispatch.Rules)
+	return rules
+}
+
+// DescribeSelector renders the constraints of a dispatch selector as
+// space-separated key=value pairs, e.g. "channel=telegram mentioned=true".
+func DescribeSelector(selector config.DispatchSelector) string {
+	account := ""
+	if strings.TrimSpace(selector.Account) != "" {
+		account = NormalizeAccountID(selector.Account)
+	}
+	selector = normalizeDispatchSelector(selector)
+	parts := make([]string, 0, 7)
+	for _, field := range []struct{ key, value string }{
+		{"channel", selector.Channel},
+		{"account", account},
+		{"space", selector.Space},
+		{"chat", selector.Chat},
+		{"topic", selector.Topic},
+		{"sender", selector.Sender},
+	} {
+		if field.value != "" {
+			parts = append(parts, field.key+"="+field.value)
+		}
+	}
+	if selector.Mentioned != nil {
+		parts = append(parts, fmt.Sprintf("mentioned=%t", *selector.Mentioned))
 	}
+	return strings.Join(parts, " ")
 }
 
 func (r *RouteResolver) pickAgentID(agentID string) string {
@@ -187,6 +187,51 @@ func TestResolveRoute_DispatchMentionedRule(t *testing.T) {
 	}
 }
 
+func TestExplainRoute_ReturnsMatchedRule(t *testing.T) {
+	cfg := testConfig([]config.AgentConfig{
+		{ID: "main", Default: true},
+		{ID: "support"},
+	})
+	cfg.Agents.Dispatch = &config.DispatchConfig{
+		Rules: []config.DispatchRule{
+			{Name: "Telegram-Group", Agent: "support", When: config.DispatchSelector{Chat: "group:-100"}},
+		},
+	}
+	r := NewRouteResolver(cfg)
+
+	route, rule := r.ExplainRoute(bus.InboundContext{Channel: "telegram", ChatID: "-100", ChatType: "group"})
+	if rule == nil || rule.Name != "Telegram-Group" {
+		t.Fatalf("rule = %+v, want Telegram-Group", rule)
+	}
+	if route.AgentID != "support" || route.MatchedBy != "dispatch.rule:telegram-group" {
+		t.Fatalf("route = %+v, want support via dispatch.rule:telegram-group", route)
+	}
+
+	route, rule = r.ExplainRoute(bus.InboundContext{Channel: "telegram", ChatID: "42", ChatType: "direct"})
+	if rule != nil {
+		t.Fatalf("rule = %+v, want nil for default route", rule)
+	}
+	if route.AgentID != "main" || route.MatchedBy != "default" {
+		t.Fatalf("route = %+v, want main via default", route)
+	}
+}
+
+func TestDescribeSelector(t *testing.T) {
+	mentioned := false
+	got := DescribeSelector(config.DispatchSelector{
+		Channel:   " Slack ",
+		Account:   "Bot2",
+		Chat:      "Channel:C123",
+		Mentioned: &mentioned,
+	})
+	if want := "channel=slack account=bot2 chat=channel:c123 mentioned=false"; got != want {
+		t.Fatalf("DescribeSelector() = %q, want %q", got, want)
+	}
+	if got := DescribeSelector(config.DispatchSelector{}); got != "" {
+		t.Fatalf("DescribeSelector(empty) = %q, want empty", got)
+	}
+}
+
 func TestResolveRoute_InvalidAgentFallsToDefault(t *testing.T) {
 	agents := []config.AgentConfig{
 		{ID: "main", Default: true},
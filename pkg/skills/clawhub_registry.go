@@ -314,12 +314,15 @@ func (c *ClawHubRegistry) DownloadAndInstall(
 
 	tmpPath, err := c.downloadToTempFileWithRetry(ctx, u.String())
 	if err != nil {
-		return nil, fmt.Errorf("download failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrSkillDownload, err)
 	}
 	defer os.Remove(tmpPath)
 
-	// Step 4: Extract from file on disk.
-	if err := utils.ExtractZipFile(tmpPath, targetDir); err != nil {
+	// Step 4: Extract from file on disk into a staging directory and move it
+	// into place once it holds a valid skill.
+	if err := installStaged(targetDir, func(stageDir string) error {
+		return utils.ExtractZipFile(tmpPath, stageDir)
+	}); err != nil {
 		return nil, err
 	}
 
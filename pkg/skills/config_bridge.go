@@ -1,6 +1,11 @@
 package skills
 
-import "github.com/sipeed/picoclaw/pkg/config"
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
 
 const defaultGitHubRegistryBaseURL = "https://github.com"
 
@@ -81,6 +86,17 @@ func NewRegistryManagerFromToolsConfig(cfg config.SkillsToolsConfig) *RegistryMa
 	})
 }
 
+// NewSearchCacheFromToolsConfig builds the shared, disk-backed search cache
+// stored under the picoclaw home directory.
+func NewSearchCacheFromToolsConfig(cfg config.SkillsToolsConfig) *SearchCache {
+	return NewPersistentSearchCache(
+		filepath.Join(config.GetHome(), "cache", "skills_search.json"),
+		cfg.SearchCache.MaxSize,
+		time.Duration(cfg.SearchCache.TTLSeconds)*time.Second,
+		time.Duration(cfg.SearchCache.StaleTTLSeconds)*time.Second,
+	)
+}
+
 func LookupRegistryFromToolsConfig(cfg config.SkillsToolsConfig, name string) SkillRegistry {
 	for _, provider := range registryProvidersFromToolsConfig(cfg) {
 		if provider == nil {
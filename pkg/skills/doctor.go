@@ -0,0 +1,235 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// DiagnosisStatus grades a skill check. A skill takes the worst status of
+// its problems.
+type DiagnosisStatus string
+
+const (
+	DiagnosisPass DiagnosisStatus = "pass"
+	DiagnosisWarn DiagnosisStatus = "warn"
+	DiagnosisFail DiagnosisStatus = "fail"
+)
+
+// SkillProblem is one finding, with a hint on how to fix it.
+type SkillProblem struct {
+	Status  DiagnosisStatus `json:"status"`
+	Message string          `json:"message"`
+	Hint    string          `json:"hint,omitempty"`
+}
+
+// SkillDiagnosis is the doctor's report for one installed skill directory.
+type SkillDiagnosis struct {
+	Name     string          `json:"name"`
+	Path     string          `json:"path"`
+	Source   string          `json:"source"`
+	Status   DiagnosisStatus `json:"status"`
+	Problems []SkillProblem  `json:"problems,omitempty"`
+}
+
+func (d *SkillDiagnosis) add(status DiagnosisStatus, hint, format string, args ...any) {
+	d.Problems = append(d.Problems, SkillProblem{Status: status, Message: fmt.Sprintf(format, args...), Hint: hint})
+	if status == DiagnosisFail || (status == DiagnosisWarn && d.Status == DiagnosisPass) {
+		d.Status = status
+	}
+}
+
+// skillRequirements is what a skill's frontmatter metadata asks of the host,
+// e.g. metadata: {"nanobot":{"requires":{"bins":["gh"]},"os":["linux"]}}.
+type skillRequirements struct {
+	Bins    []string
+	Tools   []string
+	MCP     []string
+	OS      []string
+	Install map[string]string // bin → install hint
+}
+
+type skillManifest struct {
+	Metadata map[string]any `yaml:"metadata"`
+}
+
+// lookPath is swapped in tests.
+var lookPath = exec.LookPath
+
+// Diagnose checks every skill directory under the loader's roots, including
+// the ones ListSkills skips as invalid. With a config, it also checks that the
+// tools and MCP servers a skill requires are enabled.
+func (sl *SkillsLoader) Diagnose(cfg *config.Config) []SkillDiagnosis {
+	var out []SkillDiagnosis
+	seen := make(map[string]string) // skill name → source that provides it
+
+	for _, root := range []struct{ dir, source string }{
+		{sl.workspaceSkills, "workspace"},
+		{sl.globalSkills, "global"},
+		{sl.builtinSkills, "builtin"},
+	} {
+		if root.dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(root.dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			d := sl.diagnoseSkill(cfg, filepath.Join(root.dir, entry.Name()), root.source)
+			if d.Status != DiagnosisFail {
+				if owner, ok := seen[d.Name]; ok {
+					d.add(DiagnosisWarn, "remove one of the copies if this is not intended",
+						"shadowed by the %s skill of the same name", owner)
+				} else {
+					seen[d.Name] = root.source
+				}
+			}
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func (sl *SkillsLoader) diagnoseSkill(cfg *config.Config, dir, source string) SkillDiagnosis {
+	skillFile := filepath.Join(dir, "SKILL.md")
+	d := SkillDiagnosis{Name: filepath.Base(dir), Path: skillFile, Source: source, Status: DiagnosisPass}
+
+	content, err := os.ReadFile(skillFile)
+	if err != nil {
+		d.Path = dir
+		d.add(DiagnosisFail, "add a SKILL.md with name and description frontmatter, or remove the directory",
+			"SKILL.md is missing or unreadable")
+		return d
+	}
+
+	frontmatter, _ := splitFrontmatter(string(content))
+	var manifest skillManifest
+	// JSON frontmatter is valid YAML too.
+	if err := yaml.Unmarshal([]byte(frontmatter), &manifest); err != nil {
+		d.add(DiagnosisFail, "fix the YAML between the --- lines at the top of SKILL.md",
+			"frontmatter does not parse: %v", err)
+		return d
+	}
+
+	// Validate what the loader would actually use.
+	info := SkillInfo{Name: d.Name, Path: skillFile, Source: source}
+	if metadata := sl.getSkillMetadata(skillFile); metadata != nil {
+		info.Name, info.Description = metadata.Name, metadata.Description
+	}
+	d.Name = info.Name
+	if err := info.validate(); err != nil {
+		d.add(DiagnosisFail, "set a valid name and a description in the frontmatter; the skill is not loaded",
+			"invalid manifest: %s", strings.ReplaceAll(err.Error(), "\n", "; "))
+		return d
+	}
+	if info.Name != filepath.Base(dir) {
+		d.add(DiagnosisWarn, fmt.Sprintf("rename the directory to %q", info.Name),
+			"name %q differs from its directory %q, so loading it by name fails", info.Name, filepath.Base(dir))
+	}
+
+	req := parseSkillRequirements(manifest.Metadata)
+	if len(req.OS) > 0 && !slices.Contains(req.OS, runtime.GOOS) {
+		d.add(DiagnosisWarn, "", "only supported on %s, not %s", strings.Join(req.OS, ", "), runtime.GOOS)
+	}
+	for _, bin := range req.Bins {
+		if _, err := lookPath(bin); err != nil {
+			hint := req.Install[bin]
+			if hint == "" {
+				hint = fmt.Sprintf("install %s and make sure it is on PATH", bin)
+			}
+			d.add(DiagnosisWarn, hint, "required program %q not found on PATH", bin)
+		}
+	}
+	if cfg == nil {
+		return d
+	}
+	for _, tool := range req.Tools {
+		if !cfg.Tools.IsToolEnabled(tool) {
+			d.add(DiagnosisFail, fmt.Sprintf("enable the %s tool in the tools section of config.json", tool),
+				"required tool %q is disabled", tool)
+		}
+	}
+	for _, name := range req.MCP {
+		server, ok := cfg.Tools.MCP.Servers[name]
+		switch {
+		case !ok:
+			d.add(DiagnosisFail, fmt.Sprintf("add tools.mcp.servers.%s to config.json", name),
+				"required MCP server %q is not configured", name)
+		case !cfg.Tools.MCP.Enabled || !server.Enabled:
+			d.add(DiagnosisFail, fmt.Sprintf("set tools.mcp.enabled and tools.mcp.servers.%s.enabled to true", name),
+				"required MCP server %q is disabled", name)
+		}
+	}
+	return d
+}
+
+// parseSkillRequirements merges the requires blocks of every namespace under
+// metadata (nanobot, picoclaw, ...).
+func parseSkillRequirements(metadata map[string]any) skillRequirements {
+	req := skillRequirements{Install: make(map[string]string)}
+	namespaces := make([]string, 0, len(metadata))
+	for ns := range metadata {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	for _, ns := range namespaces {
+		block, ok := metadata[ns].(map[string]any)
+		if !ok {
+			continue
+		}
+		req.OS = appendUnique(req.OS, stringList(block["os"])...)
+		if requires, ok := block["requires"].(map[string]any); ok {
+			req.Bins = appendUnique(req.Bins, stringList(requires["bins"])...)
+			req.Tools = appendUnique(req.Tools, stringList(requires["tools"])...)
+			req.MCP = appendUnique(req.MCP, stringList(requires["mcp"])...)
+		}
+		installs, _ := block["install"].([]any)
+		for _, raw := range installs {
+			install, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			label, _ := install["label"].(string)
+			for _, bin := range stringList(install["bins"]) {
+				if _, done := req.Install[bin]; !done && label != "" {
+					req.Install[bin] = label
+				}
+			}
+		}
+	}
+	return req
+}
+
+func stringList(v any) []string {
+	items, _ := v.([]any)
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+			out = append(out, strings.TrimSpace(s))
+		}
+	}
+	return out
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		if !slices.Contains(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
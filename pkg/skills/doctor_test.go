@@ -0,0 +1,82 @@
+package skills
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func writeDoctorSkill(t *testing.T, root, dir, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0o755))
+	if content != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(root, dir, "SKILL.md"), []byte(content), 0o644))
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	orig := lookPath
+	lookPath = func(bin string) (string, error) {
+		if bin == "gh" {
+			return "/usr/bin/gh", nil
+		}
+		return "", errors.New("not found")
+	}
+	t.Cleanup(func() { lookPath = orig })
+
+	workspace := t.TempDir()
+	global := t.TempDir()
+	wsSkills := filepath.Join(workspace, "skills")
+
+	writeDoctorSkill(t, wsSkills, "github", "---\nname: github\ndescription: GitHub via gh\n"+
+		"metadata: {\"nanobot\":{\"requires\":{\"bins\":[\"gh\"]}}}\n---\n\n# GitHub\n")
+	writeDoctorSkill(t, wsSkills, "browser", "---\nname: browser\ndescription: Browse\n"+
+		"metadata: {\"nanobot\":{\"requires\":{\"bins\":[\"agent-browser\"]},"+
+		"\"install\":[{\"kind\":\"npm\",\"bins\":[\"agent-browser\"],\"label\":\"Install agent-browser (npm)\"}]}}\n---\n")
+	writeDoctorSkill(t, wsSkills, "hardware", "---\nname: hardware\ndescription: I2C\n"+
+		"metadata:\n  picoclaw:\n    requires:\n      tools: [i2c]\n      mcp: [sensors]\n---\n")
+	writeDoctorSkill(t, wsSkills, "broken", "---\nname: [oops\n---\n")
+	writeDoctorSkill(t, wsSkills, "bad-name", "---\nname: bad name\ndescription: x\n---\n")
+	writeDoctorSkill(t, wsSkills, "empty", "")
+	writeDoctorSkill(t, global, "github", "---\nname: github\ndescription: older copy\n---\n")
+
+	cfg := config.DefaultConfig()
+	cfg.Tools.I2C.Enabled = false
+
+	results := NewSkillsLoader(workspace, global, "").Diagnose(cfg)
+	byKey := make(map[string]SkillDiagnosis)
+	for _, d := range results {
+		byKey[d.Source+"/"+filepath.Base(filepath.Dir(d.Path))] = d
+		if d.Path == filepath.Join(wsSkills, "empty") {
+			byKey["workspace/empty"] = d
+		}
+	}
+
+	assert.Equal(t, DiagnosisPass, byKey["workspace/github"].Status)
+
+	browser := byKey["workspace/browser"]
+	assert.Equal(t, DiagnosisWarn, browser.Status)
+	require.Len(t, browser.Problems, 1)
+	assert.Equal(t, "Install agent-browser (npm)", browser.Problems[0].Hint)
+
+	hardware := byKey["workspace/hardware"]
+	assert.Equal(t, DiagnosisFail, hardware.Status)
+	require.Len(t, hardware.Problems, 2)
+	assert.Contains(t, hardware.Problems[0].Message, `"i2c" is disabled`)
+	assert.Contains(t, hardware.Problems[1].Message, `"sensors" is not configured`)
+
+	assert.Equal(t, DiagnosisFail, byKey["workspace/broken"].Status)
+	assert.Equal(t, DiagnosisFail, byKey["workspace/bad-name"].Status)
+	assert.Equal(t, DiagnosisFail, byKey["workspace/empty"].Status)
+
+	shadowed := byKey["global/github"]
+	assert.Equal(t, DiagnosisWarn, shadowed.Status)
+	require.Len(t, shadowed.Problems, 1)
+	assert.Contains(t, shadowed.Problems[0].Message, "shadowed by the workspace skill")
+}
@@ -412,7 +412,9 @@ func (si *SkillInstaller) InstallFromGitHub(ctx context.Context, repo string) er
 	}
 	skillDirectory := filepath.Join(si.workspace, "skills", skillName)
 
-	if _, statErr := os.Stat(skillDirectory); statErr == nil {
+	// A directory left behind by an interrupted install is not a skill;
+	// install over it instead of refusing.
+	if _, statErr := os.Stat(skillDirectory); statErr == nil && validateSkillDir(skillDirectory) == nil {
 		return fmt.Errorf("skill '%s' already exists", skillName)
 	}
 	_, err = si.InstallFromGitHubToDir(ctx, repo, "", skillDirectory)
@@ -444,21 +446,27 @@ func (si *SkillInstaller) InstallFromGitHubToDir(
 	}
 	apiURL := fmt.Sprintf("%s/repos/%s?ref=%s", target.Endpoints.APIBaseURL, apiPath, url.QueryEscape(ref.Ref))
 
-	if err := si.getGithubDirAllFiles(ctx, apiURL, skillDirectory, true); err != nil {
-		// Fallback to raw download
-		if downloadErr := si.downloadRaw(
-			ctx,
-			target.Endpoints.RawBaseURL,
-			ref.Owner,
-			ref.RepoName,
-			ref.Ref,
-			ref.SubPath,
-			skillDirectory,
-		); downloadErr != nil {
-			return nil, downloadErr
+	err = installStaged(skillDirectory, func(stageDir string) error {
+		if err := si.getGithubDirAllFiles(ctx, apiURL, stageDir, true); err != nil {
+			// Fallback to raw download, starting from an empty directory so
+			// files from the failed attempt are not mixed in.
+			if err := os.RemoveAll(stageDir); err != nil {
+				return err
+			}
+			return si.downloadRaw(
+				ctx,
+				target.Endpoints.RawBaseURL,
+				ref.Owner,
+				ref.RepoName,
+				ref.Ref,
+				ref.SubPath,
+				stageDir,
+			)
 		}
-	} else if _, err := os.Stat(filepath.Join(skillDirectory, "SKILL.md")); err != nil {
-		return nil, fmt.Errorf("SKILL.md not found in repository")
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &InstallResult{Version: ref.Ref}, nil
@@ -0,0 +1,132 @@
+package skills
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// installStagePrefix names the staging directories installs create in
+	// the skills directory. They hold no SKILL.md at their top level, so the
+	// loader never lists them as skills.
+	installStagePrefix = ".picoclaw-install-"
+	// installBackupName is where a replaced skill is kept inside the staging
+	// directory until the new copy is in place. The leading dot keeps it
+	// apart from any valid skill name.
+	installBackupName = ".previous"
+)
+
+var (
+	// ErrSkillDownload marks install failures caused by fetching the skill:
+	// network errors, HTTP errors or a broken archive. Retrying may help.
+	ErrSkillDownload = errors.New("skill download failed")
+
+	// ErrInvalidSkill marks install failures where the download succeeded
+	// but did not contain a usable skill. Retrying will not help.
+	ErrInvalidSkill = errors.New("invalid skill")
+)
+
+// installStaged runs download into a fresh staging directory next to
+// targetDir, checks that the result is a loadable skill, and only then moves
+// it to targetDir, replacing any existing copy. On failure targetDir is left
+// as it was and the staging directory is removed, so an interrupted install
+// never leaves a half-written skill behind.
+//
+// download errors are reported as ErrSkillDownload and validation errors as
+// ErrInvalidSkill.
+func installStaged(targetDir string, download func(stageDir string) error) error {
+	parent := filepath.Dir(targetDir)
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return fmt.Errorf("failed to create skills directory: %w", err)
+	}
+	// Stage under the same parent so the final rename stays on one
+	// filesystem, and keep the target's base name so validation sees the
+	// directory name the skill will have.
+	stageRoot, err := os.MkdirTemp(parent, installStagePrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stageRoot)
+	stageDir := filepath.Join(stageRoot, filepath.Base(targetDir))
+
+	if err := download(stageDir); err != nil {
+		if errors.Is(err, ErrInvalidSkill) || errors.Is(err, ErrSkillDownload) {
+			return err
+		}
+		return fmt.Errorf("%w: %w", ErrSkillDownload, err)
+	}
+	if err := validateSkillDir(stageDir); err != nil {
+		return err
+	}
+	return replaceDir(stageDir, targetDir, filepath.Join(stageRoot, installBackupName))
+}
+
+// CleanupStaleInstalls removes staging directories under skillsDir that were
+// left behind by installs interrupted by a crash and are older than
+// olderThan, so a concurrent install is not disturbed. It returns how many
+// were removed.
+func CleanupStaleInstalls(skillsDir string, olderThan time.Duration) int {
+	entries, err := os.ReadDir(skillsDir)
+	if err != nil {
+		return 0
+	}
+	cutoff := time.Now().Add(-olderThan)
+	removed := 0
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), installStagePrefix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if os.RemoveAll(filepath.Join(skillsDir, e.Name())) == nil {
+			removed++
+		}
+	}
+	return removed
+}
+
+// validateSkillDir checks that dir holds a SKILL.md the loader would accept.
+func validateSkillDir(dir string) error {
+	skillFile := filepath.Join(dir, "SKILL.md")
+	if _, err := os.Stat(skillFile); err != nil {
+		return fmt.Errorf("%w: SKILL.md not found", ErrInvalidSkill)
+	}
+	meta := (&SkillsLoader{}).getSkillMetadata(skillFile)
+	if meta == nil {
+		return fmt.Errorf("%w: SKILL.md could not be read", ErrInvalidSkill)
+	}
+	if err := (SkillInfo{Name: meta.Name, Description: meta.Description}).validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSkill, err)
+	}
+	return nil
+}
+
+// replaceDir moves src to dst. An existing dst is first moved to backup and
+// put back if the move fails. backup must be inside the staging directory, so
+// a crash in between never leaves a second copy of the skill where the
+// loader would find it.
+func replaceDir(src, dst, backup string) error {
+	hadExisting := false
+	if _, err := os.Stat(dst); err == nil {
+		if err := os.Rename(dst, backup); err != nil {
+			return fmt.Errorf("failed to move existing skill aside: %w", err)
+		}
+		hadExisting = true
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to inspect existing skill: %w", err)
+	}
+
+	if err := os.Rename(src, dst); err != nil {
+		if hadExisting {
+			_ = os.Rename(backup, dst)
+		}
+		return fmt.Errorf("failed to move skill into place: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package skills
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const stageTestSkillMD = "---\nname: demo\ndescription: Demo skill\n---\n# Demo\n"
+
+func writeStageTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func assertOnlyEntries(t *testing.T, dir string, want ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir(%s) error = %v", dir, err)
+	}
+	if len(entries) != len(want) {
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.Name()
+		}
+		t.Fatalf("entries of %s = %v, want %v", dir, names, want)
+	}
+	for i, e := range entries {
+		if e.Name() != want[i] {
+			t.Fatalf("entry %d of %s = %q, want %q", i, dir, e.Name(), want[i])
+		}
+	}
+}
+
+func TestInstallStaged_DownloadFailureLeavesNothing(t *testing.T) {
+	skillsDir := t.TempDir()
+	target := filepath.Join(skillsDir, "demo")
+
+	err := installStaged(target, func(stageDir string) error {
+		writeStageTestFile(t, filepath.Join(stageDir, "SKILL.md"), stageTestSkillMD)
+		return errors.New("connection reset")
+	})
+	if !errors.Is(err, ErrSkillDownload) {
+		t.Fatalf("installStaged() error = %v, want ErrSkillDownload", err)
+	}
+	if errors.Is(err, ErrInvalidSkill) {
+		t.Fatalf("installStaged() error = %v, should not be ErrInvalidSkill", err)
+	}
+	assertOnlyEntries(t, skillsDir)
+}
+
+func TestInstallStaged_InvalidSkillKeepsExisting(t *testing.T) {
+	skillsDir := t.TempDir()
+	target := filepath.Join(skillsDir, "demo")
+	writeStageTestFile(t, filepath.Join(target, "SKILL.md"), stageTestSkillMD)
+
+	for name, content := range map[string]string{
+		"missing":        "",
+		"no description": "---\nname: demo\n---\n# Demo\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := installStaged(target, func(stageDir string) error {
+				if content == "" {
+					return os.MkdirAll(stageDir, 0o755)
+				}
+				writeStageTestFile(t, filepath.Join(stageDir, "SKILL.md"), content)
+				return nil
+			})
+			if !errors.Is(err, ErrInvalidSkill) {
+				t.Fatalf("installStaged() error = %v, want ErrInvalidSkill", err)
+			}
+			data, err := os.ReadFile(filepath.Join(target, "SKILL.md"))
+			if err != nil || string(data) != stageTestSkillMD {
+				t.Fatalf("existing SKILL.md = %q, %v; want it untouched", data, err)
+			}
+			assertOnlyEntries(t, skillsDir, "demo")
+		})
+	}
+}
+
+func TestInstallStaged_ReplacesExistingWithoutMerging(t *testing.T) {
+	skillsDir := t.TempDir()
+	target := filepath.Join(skillsDir, "demo")
+	writeStageTestFile(t, filepath.Join(target, "SKILL.md"), "partial")
+	writeStageTestFile(t, filepath.Join(target, "stale.sh"), "old")
+
+	err := installStaged(target, func(stageDir string) error {
+		writeStageTestFile(t, filepath.Join(stageDir, "SKILL.md"), stageTestSkillMD)
+		writeStageTestFile(t, filepath.Join(stageDir, "run.sh"), "new")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("installStaged() error = %v", err)
+	}
+	assertOnlyEntries(t, target, "SKILL.md", "run.sh")
+	assertOnlyEntries(t, skillsDir, "demo")
+}
+
+func TestSkillInstaller_InstallFromGitHub_ReplacesPartialInstall(t *testing.T) {
+	tmpDir := t.TempDir()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/org/repo/contents/skills/demo":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[
+				{"type":"file","name":"SKILL.md","download_url":"` + server.URL + `/raw/org/repo/main/skills/demo/SKILL.md"}
+			]`))
+		case "/raw/org/repo/main/skills/demo/SKILL.md":
+			_, _ = w.Write([]byte(stageTestSkillMD))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	installer, err := NewSkillInstallerWithBaseURL(tmpDir, server.URL, "", "")
+	if err != nil {
+		t.Fatalf("NewSkillInstallerWithBaseURL() error = %v", err)
+	}
+
+	// Leftovers of an interrupted install: no SKILL.md, one stray file.
+	skillDir := filepath.Join(tmpDir, "skills", "demo")
+	writeStageTestFile(t, filepath.Join(skillDir, "half.tmp"), "partial")
+
+	if err := installer.InstallFromGitHub(
+		context.Background(),
+		server.URL+"/org/repo/tree/main/skills/demo",
+	); err != nil {
+		t.Fatalf("InstallFromGitHub() error = %v", err)
+	}
+	assertOnlyEntries(t, skillDir, "SKILL.md")
+	assertOnlyEntries(t, filepath.Join(tmpDir, "skills"), "demo")
+}
+
+func TestReplaceDir_RestoresExistingOnFailure(t *testing.T) {
+	skillsDir := t.TempDir()
+	target := filepath.Join(skillsDir, "demo")
+	writeStageTestFile(t, filepath.Join(target, "SKILL.md"), stageTestSkillMD)
+	stageRoot := filepath.Join(skillsDir, installStagePrefix+"test")
+	if err := os.MkdirAll(stageRoot, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// src does not exist, so moving it into place fails after the backup.
+	err := replaceDir(filepath.Join(stageRoot, "demo"), target, filepath.Join(stageRoot, installBackupName))
+	if err == nil {
+		t.Fatal("replaceDir() error = nil, want failure")
+	}
+	data, err := os.ReadFile(filepath.Join(target, "SKILL.md"))
+	if err != nil || string(data) != stageTestSkillMD {
+		t.Fatalf("restored SKILL.md = %q, %v; want original", data, err)
+	}
+}
+
+func TestStagingDirsAreNotListedAsSkills(t *testing.T) {
+	workspace := t.TempDir()
+	stageRoot := filepath.Join(workspace, "skills", installStagePrefix+"crashed")
+	writeStageTestFile(t, filepath.Join(stageRoot, installBackupName, "SKILL.md"), stageTestSkillMD)
+	writeStageTestFile(t, filepath.Join(stageRoot, "demo", "SKILL.md"), stageTestSkillMD)
+
+	if got := NewSkillsLoader(workspace, "", "").ListSkills(); len(got) != 0 {
+		t.Fatalf("ListSkills() = %+v, want staging leftovers ignored", got)
+	}
+}
+
+func TestCleanupStaleInstalls(t *testing.T) {
+	skillsDir := t.TempDir()
+	stale := filepath.Join(skillsDir, installStagePrefix+"old")
+	fresh := filepath.Join(skillsDir, installStagePrefix+"new")
+	skill := filepath.Join(skillsDir, "demo")
+	writeStageTestFile(t, filepath.Join(stale, installBackupName, "SKILL.md"), stageTestSkillMD)
+	writeStageTestFile(t, filepath.Join(fresh, "demo", "SKILL.md"), stageTestSkillMD)
+	writeStageTestFile(t, filepath.Join(skill, "SKILL.md"), stageTestSkillMD)
+
+	old := time.Now().Add(-2 * time.Hour)
+	for _, dir := range []string{stale, skill} {
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := CleanupStaleInstalls(skillsDir, time.Hour); n != 1 {
+		t.Fatalf("CleanupStaleInstalls() = %d, want 1", n)
+	}
+	assertOnlyEntries(t, skillsDir, installStagePrefix+"new", "demo")
+}
@@ -729,7 +729,11 @@ func TestSkillInstaller_InstallFromGitHub_SkillAlreadyExists(t *testing.T) {
 	// Create an existing skill directory
 	existingSkill := filepath.Join(skillsDir, "picoclaw")
 	os.MkdirAll(existingSkill, 0o755)
-	os.WriteFile(filepath.Join(existingSkill, "SKILL.md"), []byte("existing"), 0o644)
+	os.WriteFile(
+		filepath.Join(existingSkill, "SKILL.md"),
+		[]byte("---\nname: picoclaw\ndescription: Existing skill\n---\n# Existing\n"),
+		0o644,
+	)
 
 	// Try to install the same skill - should fail
 	err = installer.InstallFromGitHub(context.Background(), "sipeed/picoclaw")
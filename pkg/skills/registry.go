@@ -12,6 +12,12 @@ import (
 
 const (
 	defaultMaxConcurrentSearches = 2
+
+	// defaultSearchAttemptTimeout bounds a single registry search so an
+	// unresponsive registry fails over to cached results quickly.
+	defaultSearchAttemptTimeout = 20 * time.Second
+	defaultSearchRetries        = 1
+	defaultSearchRetryBackoff   = 500 * time.Millisecond
 )
 
 // SearchResult represents a single result from a skill registry search.
@@ -119,6 +125,9 @@ type ClawHubConfig struct {
 type RegistryManager struct {
 	registries    []SkillRegistry
 	maxConcurrent int
+	searchTimeout time.Duration
+	searchRetries int
+	retryBackoff  time.Duration
 	mu            sync.RWMutex
 }
 
@@ -150,6 +159,9 @@ func NewRegistryManager() *RegistryManager {
 	return &RegistryManager{
 		registries:    make([]SkillRegistry, 0),
 		maxConcurrent: defaultMaxConcurrentSearches,
+		searchTimeout: defaultSearchAttemptTimeout,
+		searchRetries: defaultSearchRetries,
+		retryBackoff:  defaultSearchRetryBackoff,
 	}
 }
 
@@ -228,10 +240,7 @@ func (rm *RegistryManager) SearchAll(ctx context.Context, query string, limit in
 				return
 			}
 
-			searchCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
-			defer cancel()
-
-			results, err := r.Search(searchCtx, query, limit)
+			results, err := rm.searchWithRetry(ctx, r, query, limit)
 			if err != nil {
 				slog.Warn("registry search failed", "registry", r.Name(), "error", err)
 				resultsCh <- regResult{err: err}
@@ -276,6 +285,37 @@ func (rm *RegistryManager) SearchAll(ctx context.Context, query string, limit in
 	return merged, nil
 }
 
+// searchWithRetry runs one registry search with a per-attempt timeout,
+// retrying failed attempts with exponential backoff.
+func (rm *RegistryManager) searchWithRetry(
+	ctx context.Context,
+	r SkillRegistry,
+	query string,
+	limit int,
+) ([]SearchResult, error) {
+	backoff := rm.retryBackoff
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, rm.searchTimeout)
+		results, err := r.Search(attemptCtx, query, limit)
+		cancel()
+		if err == nil {
+			return results, nil
+		}
+		if attempt >= rm.searchRetries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		slog.Debug("registry search failed, retrying",
+			"registry", r.Name(), "attempt", attempt+1, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
 // sortByScoreDesc sorts SearchResults by Score in descending order (insertion sort — small slices).
 func sortByScoreDesc(results []SearchResult) {
 	for i := 1; i < len(results); i++ {
@@ -255,3 +255,44 @@ func TestNormalizeInstallTargetForRegistryCanonicalizesGitHubURLs(t *testing.T)
 	)
 	assert.Equal(t, "org/repo/skills/pr-review", got)
 }
+
+// flakyRegistry fails the first failures searches, then succeeds.
+type flakyRegistry struct {
+	mockRegistry
+	failures int
+	calls    int
+}
+
+func (f *flakyRegistry) Search(_ context.Context, _ string, _ int) ([]SearchResult, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, fmt.Errorf("registry unavailable")
+	}
+	return f.searchResults, nil
+}
+
+func TestRegistryManagerSearchAllRetriesTransientFailure(t *testing.T) {
+	mgr := NewRegistryManager()
+	mgr.retryBackoff = time.Millisecond
+	reg := &flakyRegistry{
+		mockRegistry: mockRegistry{name: "flaky", searchResults: []SearchResult{{Slug: "skill-a"}}},
+		failures:     1,
+	}
+	mgr.AddRegistry(reg)
+
+	results, err := mgr.SearchAll(context.Background(), "test", 5)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, 2, reg.calls)
+}
+
+func TestRegistryManagerSearchAllGivesUpAfterRetries(t *testing.T) {
+	mgr := NewRegistryManager()
+	mgr.retryBackoff = time.Millisecond
+	reg := &flakyRegistry{mockRegistry: mockRegistry{name: "down"}, failures: 100}
+	mgr.AddRegistry(reg)
+
+	_, err := mgr.SearchAll(context.Background(), "test", 5)
+	assert.Error(t, err)
+	assert.Equal(t, 1+defaultSearchRetries, reg.calls)
+}
@@ -1,21 +1,31 @@
 package skills
 
 import (
+	"encoding/json"
+	"log/slog"
+	"os"
 	"slices"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
 )
 
 // SearchCache provides lightweight caching for search results.
 // It uses trigram-based similarity to match similar queries to cached results,
 // avoiding redundant API calls. Thread-safe for concurrent access.
+//
+// Entries stop serving normal lookups after ttl but are kept until staleTTL
+// so GetStale can fall back to them while registries are unreachable.
 type SearchCache struct {
 	mu         sync.RWMutex
 	entries    map[string]*cacheEntry
 	order      []string // LRU order: oldest first.
 	maxEntries int
 	ttl        time.Duration
+	staleTTL   time.Duration
+	path       string // optional file the cache is persisted to
 }
 
 type cacheEntry struct {
@@ -28,6 +38,10 @@ type cacheEntry struct {
 // similarityThreshold is the minimum trigram Jaccard similarity for a cache hit.
 const similarityThreshold = 0.7
 
+// DefaultSearchCacheStaleTTL is how long results remain usable as a fallback
+// when every registry is unreachable.
+const DefaultSearchCacheStaleTTL = 7 * 24 * time.Hour
+
 // NewSearchCache creates a new search cache.
 // maxEntries is the maximum number of cached queries (excess evicts LRU).
 // ttl is how long each entry lives before expiration.
@@ -43,15 +57,42 @@ func NewSearchCache(maxEntries int, ttl time.Duration) *SearchCache {
 		order:      make([]string, 0),
 		maxEntries: maxEntries,
 		ttl:        ttl,
+		staleTTL:   max(ttl, DefaultSearchCacheStaleTTL),
+	}
+}
+
+// NewPersistentSearchCache creates a search cache backed by a JSON file so
+// fallback results survive restarts and are shared between the CLI and the
+// agent. Entries already on disk are loaded; a missing or corrupt file starts
+// an empty cache. staleTTL <= 0 uses DefaultSearchCacheStaleTTL.
+func NewPersistentSearchCache(path string, maxEntries int, ttl, staleTTL time.Duration) *SearchCache {
+	sc := NewSearchCache(maxEntries, ttl)
+	if staleTTL > 0 {
+		sc.staleTTL = max(sc.ttl, staleTTL)
 	}
+	sc.path = path
+	sc.load()
+	return sc
 }
 
 // Get looks up results for a query. Returns cached results and true if found
 // (either exact or similar match above threshold). Returns nil, false on miss.
 func (sc *SearchCache) Get(query string) ([]SearchResult, bool) {
+	results, _, ok := sc.lookup(query, sc.ttl)
+	return results, ok
+}
+
+// GetStale looks up results like Get but also accepts entries past their TTL,
+// up to the stale TTL. It returns when the results were fetched so callers can
+// tell users how old they are. Use it only when live registries are unreachable.
+func (sc *SearchCache) GetStale(query string) ([]SearchResult, time.Time, bool) {
+	return sc.lookup(query, sc.staleTTL)
+}
+
+func (sc *SearchCache) lookup(query string, maxAge time.Duration) ([]SearchResult, time.Time, bool) {
 	normalized := normalizeQuery(query)
 	if normalized == "" {
-		return nil, false
+		return nil, time.Time{}, false
 	}
 
 	sc.mu.Lock()
@@ -59,9 +100,9 @@ func (sc *SearchCache) Get(query string) ([]SearchResult, bool) {
 
 	// Exact match first.
 	if entry, ok := sc.entries[normalized]; ok {
-		if time.Since(entry.createdAt) < sc.ttl {
+		if time.Since(entry.createdAt) < maxAge {
 			sc.moveToEndLocked(normalized)
-			return copyResults(entry.results), true
+			return copyResults(entry.results), entry.createdAt, true
 		}
 	}
 
@@ -71,7 +112,7 @@ func (sc *SearchCache) Get(query string) ([]SearchResult, bool) {
 	var bestSim float64
 
 	for _, entry := range sc.entries {
-		if time.Since(entry.createdAt) >= sc.ttl {
+		if time.Since(entry.createdAt) >= maxAge {
 			continue // Skip expired.
 		}
 		sim := jaccardSimilarity(queryTrigrams, entry.trigrams)
@@ -83,10 +124,10 @@ func (sc *SearchCache) Get(query string) ([]SearchResult, bool) {
 
 	if bestSim >= similarityThreshold && bestEntry != nil {
 		sc.moveToEndLocked(bestEntry.query)
-		return copyResults(bestEntry.results), true
+		return copyResults(bestEntry.results), bestEntry.createdAt, true
 	}
 
-	return nil, false
+	return nil, time.Time{}, false
 }
 
 // Put stores results for a query. Evicts the oldest entry if at capacity.
@@ -98,6 +139,7 @@ func (sc *SearchCache) Put(query string, results []SearchResult) {
 
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
+	defer sc.saveLocked()
 
 	// Evict expired entries first.
 	sc.evictExpiredLocked()
@@ -146,7 +188,7 @@ func (sc *SearchCache) evictExpiredLocked() {
 	newOrder := make([]string, 0, len(sc.order))
 	for _, key := range sc.order {
 		entry, ok := sc.entries[key]
-		if !ok || now.Sub(entry.createdAt) >= sc.ttl {
+		if !ok || now.Sub(entry.createdAt) >= sc.staleTTL {
 			delete(sc.entries, key)
 			continue
 		}
@@ -227,3 +269,75 @@ func copyResults(results []SearchResult) []SearchResult {
 	copy(cp, results)
 	return cp
 }
+
+type persistedCacheEntry struct {
+	Query     string         `json:"query"`
+	Results   []SearchResult `json:"results"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// load restores persisted entries, dropping those past the stale TTL.
+func (sc *SearchCache) load() {
+	if sc.path == "" {
+		return
+	}
+	data, err := os.ReadFile(sc.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("failed to read skill search cache", "path", sc.path, "error", err)
+		}
+		return
+	}
+	var persisted []persistedCacheEntry
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		slog.Warn("ignoring corrupt skill search cache", "path", sc.path, "error", err)
+		return
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for _, p := range persisted {
+		normalized := normalizeQuery(p.Query)
+		if normalized == "" || time.Since(p.CreatedAt) >= sc.staleTTL {
+			continue
+		}
+		if _, exists := sc.entries[normalized]; !exists {
+			sc.order = append(sc.order, normalized)
+		}
+		sc.entries[normalized] = &cacheEntry{
+			query:     normalized,
+			trigrams:  buildTrigrams(normalized),
+			results:   p.Results,
+			createdAt: p.CreatedAt,
+		}
+	}
+	for len(sc.order) > sc.maxEntries {
+		delete(sc.entries, sc.order[0])
+		sc.order = sc.order[1:]
+	}
+}
+
+// saveLocked writes the cache to disk in LRU order. Failures are logged and
+// otherwise ignored: persistence only improves the offline fallback.
+func (sc *SearchCache) saveLocked() {
+	if sc.path == "" {
+		return
+	}
+	persisted := make([]persistedCacheEntry, 0, len(sc.order))
+	for _, key := range sc.order {
+		if entry, ok := sc.entries[key]; ok {
+			persisted = append(persisted, persistedCacheEntry{
+				Query:     entry.query,
+				Results:   entry.results,
+				CreatedAt: entry.createdAt,
+			})
+		}
+	}
+	data, err := json.Marshal(persisted)
+	if err == nil {
+		err = fileutil.WriteFileAtomic(sc.path, data, 0o600)
+	}
+	if err != nil {
+		slog.Warn("failed to persist skill search cache", "path", sc.path, "error", err)
+	}
+}
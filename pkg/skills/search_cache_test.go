@@ -1,6 +1,8 @@
 package skills
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -198,3 +200,37 @@ func TestSearchCacheLRUUpdateOnGet(t *testing.T) {
 		t.Fatal("query-B should have been evicted")
 	}
 }
+
+func TestSearchCacheGetStaleServesExpiredEntries(t *testing.T) {
+	cache := NewSearchCache(10, 50*time.Millisecond)
+	cache.Put("github integration", []SearchResult{{Slug: "github"}})
+
+	time.Sleep(60 * time.Millisecond)
+
+	_, hit := cache.Get("github integration")
+	assert.False(t, hit, "expired entry must not serve normal lookups")
+
+	results, fetchedAt, ok := cache.GetStale("github integration")
+	assert.True(t, ok)
+	assert.Len(t, results, 1)
+	assert.WithinDuration(t, time.Now(), fetchedAt, time.Second)
+}
+
+func TestPersistentSearchCacheSurvivesReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "skills_search.json")
+	first := NewPersistentSearchCache(path, 10, time.Minute, time.Hour)
+	first.Put("docker", []SearchResult{{Slug: "docker-compose", RegistryName: "clawhub"}})
+
+	second := NewPersistentSearchCache(path, 10, time.Minute, time.Hour)
+	results, hit := second.Get("docker")
+	assert.True(t, hit)
+	assert.Equal(t, "docker-compose", results[0].Slug)
+}
+
+func TestPersistentSearchCacheIgnoresCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skills_search.json")
+	assert.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+
+	cache := NewPersistentSearchCache(path, 10, time.Minute, time.Hour)
+	assert.Equal(t, 0, cache.Len())
+}
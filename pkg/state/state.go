@@ -3,8 +3,10 @@ package state
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
@@ -21,10 +23,36 @@ type State struct {
 	// LastChatID is the last chat ID used for communication
 	LastChatID string `json:"last_chat_id,omitempty"`
 
+	// PinnedFiles maps a session key to the workspace-relative paths pinned
+	// into that session's context.
+	PinnedFiles map[string][]string `json:"pinned_files,omitempty"`
+
+	// Plans maps a session key to the task plan the agent keeps for it with
+	// the plan tool.
+	Plans map[string][]PlanStep `json:"plans,omitempty"`
+
+	// SensitiveSessions holds the session keys a user marked sensitive with
+	// /sensitive; session exports leave them out.
+	SensitiveSessions map[string]bool `json:"sensitive_sessions,omitempty"`
+
+	// GreetedPeers records when each "channel:sender" peer was sent the
+	// channel greeting, so it is sent only once per peer.
+	GreetedPeers map[string]time.Time `json:"greeted_peers,omitempty"`
+
+	// PeerPreferences maps a "channel:sender" peer to the preferences that
+	// user set for themselves, such as reply language or model.
+	PeerPreferences map[string]map[string]string `json:"peer_preferences,omitempty"`
+
 	// Timestamp is the last time this state was updated
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// PlanStep is one item of a session's task plan.
+type PlanStep struct {
+	Text   string `json:"text"`
+	Status string `json:"status"`
+}
+
 // Manager manages persistent state with atomic saves.
 type Manager struct {
 	workspace string
@@ -132,6 +160,184 @@ func (sm *Manager) GetLastChatID() string {
 	return sm.state.LastChatID
 }
 
+// PinFile adds a workspace-relative path to a session's pinned files and
+// saves the state. It reports false if the path was already pinned.
+func (sm *Manager) PinFile(sessionKey, path string) (bool, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if slices.Contains(sm.state.PinnedFiles[sessionKey], path) {
+		return false, nil
+	}
+	if sm.state.PinnedFiles == nil {
+		sm.state.PinnedFiles = make(map[string][]string)
+	}
+	sm.state.PinnedFiles[sessionKey] = append(sm.state.PinnedFiles[sessionKey], path)
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return false, fmt.Errorf("failed to save state atomically: %w", err)
+	}
+	return true, nil
+}
+
+// UnpinFile removes a path from a session's pinned files and saves the state.
+// It reports false if the path was not pinned.
+func (sm *Manager) UnpinFile(sessionKey, path string) (bool, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	pins := sm.state.PinnedFiles[sessionKey]
+	idx := slices.Index(pins, path)
+	if idx < 0 {
+		return false, nil
+	}
+	pins = slices.Delete(pins, idx, idx+1)
+	if len(pins) == 0 {
+		delete(sm.state.PinnedFiles, sessionKey)
+	} else {
+		sm.state.PinnedFiles[sessionKey] = pins
+	}
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return false, fmt.Errorf("failed to save state atomically: %w", err)
+	}
+	return true, nil
+}
+
+// GetPinnedFiles returns the paths pinned to a session, in pin order.
+func (sm *Manager) GetPinnedFiles(sessionKey string) []string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return slices.Clone(sm.state.PinnedFiles[sessionKey])
+}
+
+// SetPlan replaces a session's task plan and saves the state. An empty plan
+// removes it.
+func (sm *Manager) SetPlan(sessionKey string, steps []PlanStep) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if len(steps) == 0 {
+		if _, ok := sm.state.Plans[sessionKey]; !ok {
+			return nil
+		}
+		delete(sm.state.Plans, sessionKey)
+	} else {
+		if sm.state.Plans == nil {
+			sm.state.Plans = make(map[string][]PlanStep)
+		}
+		sm.state.Plans[sessionKey] = slices.Clone(steps)
+	}
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+	return nil
+}
+
+// GetPlan returns a copy of a session's task plan.
+func (sm *Manager) GetPlan(sessionKey string) []PlanStep {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return slices.Clone(sm.state.Plans[sessionKey])
+}
+
+// SetSensitive marks a session sensitive, or clears the mark.
+func (sm *Manager) SetSensitive(sessionKey string, sensitive bool) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.state.SensitiveSessions[sessionKey] == sensitive {
+		return nil
+	}
+	if sensitive {
+		if sm.state.SensitiveSessions == nil {
+			sm.state.SensitiveSessions = make(map[string]bool)
+		}
+		sm.state.SensitiveSessions[sessionKey] = true
+	} else {
+		delete(sm.state.SensitiveSessions, sessionKey)
+	}
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+	return nil
+}
+
+// IsSensitive reports whether a session is marked sensitive.
+func (sm *Manager) IsSensitive(sessionKey string) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.state.SensitiveSessions[sessionKey]
+}
+
+// MarkPeerGreeted records that peer has been greeted and saves the state. It
+// reports false if the peer was already recorded.
+func (sm *Manager) MarkPeerGreeted(peer string) (bool, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if _, ok := sm.state.GreetedPeers[peer]; ok {
+		return false, nil
+	}
+	if sm.state.GreetedPeers == nil {
+		sm.state.GreetedPeers = make(map[string]time.Time)
+	}
+	now := time.Now()
+	sm.state.GreetedPeers[peer] = now
+	sm.state.Timestamp = now
+
+	if err := sm.saveAtomic(); err != nil {
+		return true, fmt.Errorf("failed to save state atomically: %w", err)
+	}
+	return true, nil
+}
+
+// SetPeerPreference stores a preference for peer and saves the state. An
+// empty value removes the preference.
+func (sm *Manager) SetPeerPreference(peer, key, value string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	prefs := sm.state.PeerPreferences[peer]
+	if value == "" {
+		if _, ok := prefs[key]; !ok {
+			return nil
+		}
+		delete(prefs, key)
+		if len(prefs) == 0 {
+			delete(sm.state.PeerPreferences, peer)
+		}
+	} else {
+		if sm.state.PeerPreferences == nil {
+			sm.state.PeerPreferences = make(map[string]map[string]string)
+		}
+		if prefs == nil {
+			prefs = make(map[string]string)
+			sm.state.PeerPreferences[peer] = prefs
+		}
+		prefs[key] = value
+	}
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+	return nil
+}
+
+// GetPeerPreferences returns a copy of the preferences stored for peer.
+func (sm *Manager) GetPeerPreferences(peer string) map[string]string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return maps.Clone(sm.state.PeerPreferences[peer])
+}
+
 // GetTimestamp returns the timestamp of the last state update.
 func (sm *Manager) GetTimestamp() time.Time {
 	sm.mu.RLock()
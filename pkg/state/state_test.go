@@ -244,3 +244,152 @@ func TestNewManager_MkdirFailureDoesNotCrash(t *testing.T) {
 		t.Fatalf("NewManager should not crash when state dir creation fails, got: %v", err)
 	}
 }
+
+func TestPinnedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := NewManager(tmpDir)
+
+	added, err := sm.PinFile("session-1", "README.md")
+	if err != nil || !added {
+		t.Fatalf("PinFile() = %v, %v; want true, nil", added, err)
+	}
+	if added, _ := sm.PinFile("session-1", "README.md"); added {
+		t.Error("Expected duplicate pin to report false")
+	}
+	if _, err := sm.PinFile("session-1", "docs/spec.md"); err != nil {
+		t.Fatalf("PinFile() error = %v", err)
+	}
+
+	if got := sm.GetPinnedFiles("session-1"); len(got) != 2 || got[0] != "README.md" || got[1] != "docs/spec.md" {
+		t.Errorf("GetPinnedFiles() = %v", got)
+	}
+	if got := sm.GetPinnedFiles("session-2"); len(got) != 0 {
+		t.Errorf("Expected no pins for other session, got %v", got)
+	}
+
+	// Pins survive a reload from disk.
+	sm2 := NewManager(tmpDir)
+	if got := sm2.GetPinnedFiles("session-1"); len(got) != 2 {
+		t.Errorf("Expected 2 pins after reload, got %v", got)
+	}
+
+	removed, err := sm2.UnpinFile("session-1", "README.md")
+	if err != nil || !removed {
+		t.Fatalf("UnpinFile() = %v, %v; want true, nil", removed, err)
+	}
+	if removed, _ := sm2.UnpinFile("session-1", "README.md"); removed {
+		t.Error("Expected unpinning a missing path to report false")
+	}
+	if got := sm2.GetPinnedFiles("session-1"); len(got) != 1 || got[0] != "docs/spec.md" {
+		t.Errorf("GetPinnedFiles() after unpin = %v", got)
+	}
+}
+
+func TestMarkPeerGreetedPersists(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "state-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sm := NewManager(tmpDir)
+	added, err := sm.MarkPeerGreeted("telegram:42")
+	if err != nil || !added {
+		t.Fatalf("MarkPeerGreeted() = %v, %v; want true, nil", added, err)
+	}
+	added, err = sm.MarkPeerGreeted("telegram:42")
+	if err != nil || added {
+		t.Fatalf("second MarkPeerGreeted() = %v, %v; want false, nil", added, err)
+	}
+
+	reloaded := NewManager(tmpDir)
+	if added, _ := reloaded.MarkPeerGreeted("telegram:42"); added {
+		t.Fatal("greeted peer should survive reload")
+	}
+	if added, _ := reloaded.MarkPeerGreeted("telegram:43"); !added {
+		t.Fatal("unrelated peer should still be new")
+	}
+}
+
+func TestPeerPreferencesPersist(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "state-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sm := NewManager(tmpDir)
+	if err := sm.SetPeerPreference("telegram:42", "lang", "es"); err != nil {
+		t.Fatalf("SetPeerPreference() error = %v", err)
+	}
+	if err := sm.SetPeerPreference("telegram:42", "verbosity", "brief"); err != nil {
+		t.Fatalf("SetPeerPreference() error = %v", err)
+	}
+
+	reloaded := NewManager(tmpDir)
+	prefs := reloaded.GetPeerPreferences("telegram:42")
+	if prefs["lang"] != "es" || prefs["verbosity"] != "brief" {
+		t.Fatalf("GetPeerPreferences() = %v after reload", prefs)
+	}
+	if other := reloaded.GetPeerPreferences("telegram:43"); len(other) != 0 {
+		t.Fatalf("unrelated peer has preferences %v", other)
+	}
+
+	prefs["lang"] = "fr"
+	if got := reloaded.GetPeerPreferences("telegram:42")["lang"]; got != "es" {
+		t.Fatalf("returned map is not a copy: lang = %q", got)
+	}
+	if err := reloaded.SetPeerPreference("telegram:42", "lang", ""); err != nil {
+		t.Fatalf("clearing preference: %v", err)
+	}
+	if _, ok := reloaded.GetPeerPreferences("telegram:42")["lang"]; ok {
+		t.Fatal("empty value should remove the preference")
+	}
+}
+
+func TestPlansPersist(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := NewManager(tmpDir)
+
+	steps := []PlanStep{{Text: "read spec", Status: "done"}, {Text: "write code", Status: "pending"}}
+	if err := sm.SetPlan("session-1", steps); err != nil {
+		t.Fatalf("SetPlan() error = %v", err)
+	}
+	steps[0].Status = "pending"
+	if got := sm.GetPlan("session-1"); len(got) != 2 || got[0].Status != "done" {
+		t.Fatalf("GetPlan() = %v; stored plan must not alias the caller's slice", got)
+	}
+
+	reloaded := NewManager(tmpDir)
+	if got := reloaded.GetPlan("session-1"); len(got) != 2 || got[1].Text != "write code" {
+		t.Fatalf("GetPlan() after reload = %v", got)
+	}
+	if got := reloaded.GetPlan("session-2"); len(got) != 0 {
+		t.Fatalf("unrelated session has plan %v", got)
+	}
+	if err := reloaded.SetPlan("session-1", nil); err != nil {
+		t.Fatalf("clearing plan: %v", err)
+	}
+	if got := reloaded.GetPlan("session-1"); len(got) != 0 {
+		t.Fatalf("GetPlan() after clear = %v", got)
+	}
+}
+
+func TestSensitiveSessionsPersist(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := NewManager(tmpDir)
+
+	if err := sm.SetSensitive("session-1", true); err != nil {
+		t.Fatalf("SetSensitive() error = %v", err)
+	}
+	reloaded := NewManager(tmpDir)
+	if !reloaded.IsSensitive("session-1") || reloaded.IsSensitive("session-2") {
+		t.Fatalf("sensitive marks after reload = %v", reloaded.state.SensitiveSessions)
+	}
+	if err := reloaded.SetSensitive("session-1", false); err != nil {
+		t.Fatalf("SetSensitive(false) error = %v", err)
+	}
+	if NewManager(tmpDir).IsSensitive("session-1") {
+		t.Fatal("session-1 is still sensitive after clearing the mark")
+	}
+}
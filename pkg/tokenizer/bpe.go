@@ -0,0 +1,68 @@
+package tokenizer
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkoukk/tiktoken-go"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// BPE returns a tokenizer that counts with the named tiktoken encoding
+// ("cl100k_base", "o200k_base", ...). The merge ranks are loaded in the
+// background on first use, from the tiktoken cache directory
+// (TIKTOKEN_CACHE_DIR) or by downloading them once; binaries built with the
+// tiktoken_embed tag carry them instead. Until the ranks are loaded, or if
+// loading fails, counts come from WordHeuristic.
+func BPE(encoding string) Tokenizer {
+	return &bpeTokenizer{encoding: encoding}
+}
+
+type bpeTokenizer struct {
+	encoding string
+	once     sync.Once
+	enc      atomic.Pointer[tiktoken.Tiktoken]
+}
+
+func (b *bpeTokenizer) CountTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	b.once.Do(func() { go b.load() })
+	if enc := b.enc.Load(); enc != nil {
+		return len(enc.EncodeOrdinary(text))
+	}
+	return WordHeuristic.CountTokens(text)
+}
+
+func (b *bpeTokenizer) load() {
+	enc, err := tiktoken.GetEncoding(b.encoding)
+	if err != nil {
+		logger.WarnCF("tokenizer", "BPE encoding unavailable; estimating token counts", map[string]any{
+			"encoding": b.encoding,
+			"error":    err.Error(),
+		})
+		return
+	}
+	b.enc.Store(enc)
+}
+
+var enableBPEOnce sync.Once
+
+// EnableBPE registers exact BPE tokenizers for the OpenAI model families
+// over the built-in estimator. The rank tables cost tens of megabytes of
+// memory once loaded, so this is opt-in (agents.defaults.tokenizer "bpe").
+func EnableBPE() {
+	enableBPEOnce.Do(func() {
+		cl100k, o200k := BPE("cl100k_base"), BPE("o200k_base")
+		for _, prefix := range []string{"gpt-4", "gpt-3.5", "text-embedding-"} {
+			Register(prefix, cl100k)
+		}
+		for _, prefix := range []string{
+			"gpt-", "gpt-4o", "gpt-4.1", "gpt-4.5", "chatgpt-", "o1", "o3", "o4",
+		} {
+			Register(prefix, o200k)
+		}
+	})
+}
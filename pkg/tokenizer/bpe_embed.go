@@ -0,0 +1,13 @@
+//go:build tiktoken_embed
+
+package tokenizer
+
+import (
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// Offline builds carry the BPE rank tables instead of downloading them.
+func init() {
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+}
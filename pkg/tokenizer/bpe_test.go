@@ -0,0 +1,85 @@
+package tokenizer
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+func loadedBPE(t *testing.T, encoding string) *bpeTokenizer {
+	t.Helper()
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+	b := BPE(encoding).(*bpeTokenizer)
+	b.load()
+	return b
+}
+
+func TestBPE_CountsExactTokens(t *testing.T) {
+	tests := []struct {
+		encoding string
+		text     string
+		want     int
+	}{
+		{encoding: "cl100k_base", text: "hello world", want: 2},
+		{encoding: "cl100k_base", text: "func main() {\n\tfmt.Println(\"hi\")\n}", want: 10},
+		{encoding: "o200k_base", text: "你好，世界", want: 3},
+		{encoding: "o200k_base", text: "<|endoftext|>", want: 7},
+	}
+	for _, tt := range tests {
+		b := loadedBPE(t, tt.encoding)
+		if b.enc.Load() == nil {
+			t.Fatalf("%s did not load", tt.encoding)
+		}
+		if got := b.CountTokens(tt.text); got != tt.want {
+			t.Errorf("%s CountTokens(%q) = %d, want %d", tt.encoding, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBPE_FallsBackToEstimator(t *testing.T) {
+	b := loadedBPE(t, "no_such_encoding")
+	text := "func main() { return 42 }"
+	if got, want := b.CountTokens(text), WordHeuristic.CountTokens(text); got != want {
+		t.Fatalf("CountTokens() = %d, want the WordHeuristic estimate %d", got, want)
+	}
+}
+
+func TestEnableBPE(t *testing.T) {
+	registryMu.Lock()
+	saved, savedPrefixes := registry, prefixes
+	registry = make(map[string]Tokenizer, len(saved))
+	for k, v := range saved {
+		registry[k] = v
+	}
+	prefixes = append([]string(nil), savedPrefixes...)
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry, prefixes = saved, savedPrefixes
+		registryMu.Unlock()
+		enableBPEOnce = sync.Once{}
+	})
+
+	EnableBPE()
+	tests := []struct {
+		model    string
+		encoding string
+	}{
+		{model: "openai/gpt-4o-mini", encoding: "o200k_base"},
+		{model: "gpt-5", encoding: "o200k_base"},
+		{model: "o3-mini", encoding: "o200k_base"},
+		{model: "gpt-4-turbo", encoding: "cl100k_base"},
+		{model: "gpt-3.5-turbo", encoding: "cl100k_base"},
+	}
+	for _, tt := range tests {
+		b, ok := ForModel(tt.model).(*bpeTokenizer)
+		if !ok || b.encoding != tt.encoding {
+			t.Errorf("ForModel(%q) = %#v, want BPE %s", tt.model, ForModel(tt.model), tt.encoding)
+		}
+	}
+	if got := ForModel("anthropic/claude-sonnet-4"); got != WordHeuristic {
+		t.Errorf("ForModel(claude) = %T, want the estimator", got)
+	}
+}
@@ -0,0 +1,229 @@
+package tokenizer
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// Tokenizer counts the tokens a model would see for a piece of text.
+// Implementations must be safe for concurrent use.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a plain function to the Tokenizer interface.
+type TokenizerFunc func(text string) int
+
+func (f TokenizerFunc) CountTokens(text string) int { return f(text) }
+
+// Heuristic is the fallback tokenizer: a flat 2.5 characters per token.
+// Counting through it gives the same results as EstimateMessageTokens.
+var Heuristic Tokenizer = heuristic{}
+
+type heuristic struct{}
+
+func (heuristic) CountTokens(text string) int {
+	return utf8.RuneCountInString(text) * 2 / 5
+}
+
+// WordHeuristic estimates the token counts of byte-pair-encoding vocabularies
+// (tiktoken cl100k/o200k and the similar ones used by Claude, Gemini, Qwen,
+// DeepSeek, ...) by counting word, number, CJK and punctuation runs instead of
+// raw characters. It is not a real BPE encoder and its counts are still
+// estimates, but it tracks real counts much more closely than Heuristic for
+// code and CJK text, where a flat ratio is off by 2x or more. EnableBPE counts
+// OpenAI models exactly; Register plugs in other exact tokenizers.
+var WordHeuristic Tokenizer = heuristicEstimator{}
+
+type heuristicEstimator struct{}
+
+func (heuristicEstimator) CountTokens(text string) int {
+	tokens := 0
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		j := i + 1
+		switch {
+		case r == '\n' || r == '\r':
+			// Consecutive line breaks usually merge into one token.
+			for j < len(runes) && (runes[j] == '\n' || runes[j] == '\r') {
+				j++
+			}
+			tokens++
+		case unicode.IsSpace(r):
+			// A single space is folded into the following word; longer runs
+			// (indentation) cost a token of their own.
+			for j < len(runes) && unicode.IsSpace(runes[j]) && runes[j] != '\n' && runes[j] != '\r' {
+				j++
+			}
+			if j-i > 1 {
+				tokens++
+			}
+		case isCJK(r):
+			// Common CJK characters are roughly one token each.
+			tokens++
+		case r < utf8.RuneSelf && unicode.IsDigit(r):
+			// Numbers are split into groups of up to three digits.
+			for j < len(runes) && runes[j] < utf8.RuneSelf && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			tokens += ceilDiv(j-i, 3)
+		case r < utf8.RuneSelf && unicode.IsLetter(r):
+			// English words average about four characters per token.
+			for j < len(runes) && runes[j] < utf8.RuneSelf && unicode.IsLetter(runes[j]) {
+				j++
+			}
+			tokens += ceilDiv(j-i, 4)
+		case unicode.IsLetter(r) || unicode.IsMark(r):
+			// Other scripts (Cyrillic, Arabic, accented Latin, ...) are
+			// covered less densely by the vocabularies.
+			for j < len(runes) && !isCJK(runes[j]) && runes[j] >= utf8.RuneSelf &&
+				(unicode.IsLetter(runes[j]) || unicode.IsMark(runes[j])) {
+				j++
+			}
+			tokens += ceilDiv((j-i)*2, 5)
+		default:
+			// Punctuation and symbols: short runs such as "{}" or "==" are
+			// often a single token.
+			for j < len(runes) && isSymbol(runes[j]) && j-i < 2 {
+				j++
+			}
+			tokens++
+		}
+		i = j
+	}
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func isSymbol(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+}
+
+func ceilDiv(n, d int) int {
+	return (n + d - 1) / d
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Tokenizer{}
+	prefixes   []string // registered prefixes, longest first
+)
+
+func init() {
+	for _, prefix := range []string{
+		"gpt-", "chatgpt-", "o1", "o3", "o4", "text-embedding-",
+		"claude", "gemini", "gemma", "deepseek", "qwen", "llama",
+		"mistral", "codestral", "glm", "kimi", "moonshot", "grok",
+	} {
+		Register(prefix, WordHeuristic)
+	}
+}
+
+// Register associates a tokenizer with model IDs starting with prefix
+// (case-insensitive, provider prefix such as "openai/" ignored). Later
+// registrations replace earlier ones for the same prefix, so an exact
+// tokenizer can be plugged in over the built-in estimator.
+func Register(prefix string, t Tokenizer) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" || t == nil {
+		return
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[prefix]; !exists {
+		prefixes = append(prefixes, prefix)
+		sort.SliceStable(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	}
+	registry[prefix] = t
+}
+
+// ForModel returns the tokenizer registered for model, matching the longest
+// prefix. Unknown models get Heuristic.
+func ForModel(model string) Tokenizer {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if idx := strings.LastIndex(model, "/"); idx >= 0 {
+		model = model[idx+1:]
+	}
+	if model == "" {
+		return Heuristic
+	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(model, prefix) {
+			return registry[prefix]
+		}
+	}
+	return Heuristic
+}
+
+// CountMessageTokens counts the tokens of a single message with t. A nil
+// tokenizer or Heuristic falls back to EstimateMessageTokens.
+func CountMessageTokens(t Tokenizer, msg providers.Message) int {
+	if t == nil || t == Heuristic {
+		return EstimateMessageTokens(msg)
+	}
+
+	tokens := t.CountTokens(msg.Content)
+	if len(msg.SystemParts) > 0 {
+		// SystemParts carry the same content as Content; count whichever
+		// representation is larger, plus per-block JSON overhead.
+		const perPartOverhead = 8
+		partTokens := 0
+		for _, part := range msg.SystemParts {
+			partTokens += t.CountTokens(part.Text) + perPartOverhead
+		}
+		tokens = max(tokens, partTokens)
+	}
+
+	tokens += t.CountTokens(msg.ReasoningContent)
+	for _, tc := range msg.ToolCalls {
+		tokens += t.CountTokens(tc.ID) + t.CountTokens(tc.Type)
+		if tc.Function != nil {
+			tokens += t.CountTokens(tc.Function.Name) + t.CountTokens(tc.Function.Arguments)
+		} else {
+			tokens += t.CountTokens(tc.Name)
+		}
+	}
+	tokens += t.CountTokens(msg.ToolCallID)
+
+	// Role label and message framing.
+	const messageOverhead = 4
+	tokens += messageOverhead
+
+	const mediaTokensPerItem = 256
+	tokens += len(msg.Media) * mediaTokensPerItem
+
+	return tokens
+}
+
+// CountToolDefsTokens counts the tokens of tool definitions with t. A nil
+// tokenizer or Heuristic falls back to EstimateToolDefsTokens.
+func CountToolDefsTokens(t Tokenizer, defs []providers.ToolDefinition) int {
+	if t == nil || t == Heuristic {
+		return EstimateToolDefsTokens(defs)
+	}
+
+	tokens := 0
+	for _, d := range defs {
+		tokens += t.CountTokens(d.Function.Name) + t.CountTokens(d.Function.Description)
+		if d.Function.Parameters != nil {
+			if paramJSON, err := json.Marshal(d.Function.Parameters); err == nil {
+				tokens += t.CountTokens(string(paramJSON))
+			}
+		}
+		// Per-tool overhead: type field, JSON structure, separators.
+		tokens += 8
+	}
+	return tokens
+}
@@ -0,0 +1,87 @@
+package tokenizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestForModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  Tokenizer
+	}{
+		{model: "openai/gpt-4o", want: WordHeuristic},
+		{model: "GPT-5-mini", want: WordHeuristic},
+		{model: "anthropic/claude-sonnet-4", want: WordHeuristic},
+		{model: "openrouter/qwen/qwen3-coder", want: WordHeuristic},
+		{model: "o3-mini", want: WordHeuristic},
+		{model: "test-model", want: Heuristic},
+		{model: "", want: Heuristic},
+	}
+	for _, tt := range tests {
+		if got := ForModel(tt.model); got != tt.want {
+			t.Errorf("ForModel(%q) = %T, want %T", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestRegister_LongestPrefixWins(t *testing.T) {
+	exact := TokenizerFunc(func(string) int { return 42 })
+	Register("gpt-4o-exact-test", exact)
+	if got := ForModel("openai/gpt-4o-exact-test-2024").CountTokens("x"); got != 42 {
+		t.Fatalf("registered tokenizer not selected, got %d tokens", got)
+	}
+	if got := ForModel("gpt-4o"); got != WordHeuristic {
+		t.Fatalf("ForModel(gpt-4o) = %T, want built-in estimator", got)
+	}
+}
+
+func TestWordHeuristic_CountTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{text: "", want: 0},
+		{text: "hello world", want: 4},      // "hello" (2) + " world" (2)
+		{text: "你好世界", want: 4},             // one per CJK character
+		{text: "1234567", want: 3},          // digit groups of three
+		{text: "a == b", want: 3},           // "a", "==", "b"
+		{text: "line\n\n    next", want: 4}, // word, line breaks, indent, word
+	}
+	for _, tt := range tests {
+		if got := WordHeuristic.CountTokens(tt.text); got != tt.want {
+			t.Errorf("WordHeuristic.CountTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWordHeuristic_CJKCountsHigherThanHeuristic(t *testing.T) {
+	text := strings.Repeat("这是一个测试句子。", 20)
+	if words, heur := WordHeuristic.CountTokens(text), Heuristic.CountTokens(text); words <= heur {
+		t.Fatalf("WordHeuristic = %d, Heuristic = %d; expected CJK text to count higher than the char heuristic", words, heur)
+	}
+}
+
+func TestCountMessageTokens_HeuristicMatchesEstimate(t *testing.T) {
+	msg := providers.Message{
+		Role:    "assistant",
+		Content: "Running the tool now.",
+		ToolCalls: []providers.ToolCall{{
+			ID:       "call_1",
+			Type:     "function",
+			Function: &providers.FunctionCall{Name: "exec", Arguments: `{"command":"ls"}`},
+		}},
+	}
+	want := EstimateMessageTokens(msg)
+	if got := CountMessageTokens(nil, msg); got != want {
+		t.Fatalf("CountMessageTokens(nil) = %d, want %d", got, want)
+	}
+	if got := CountMessageTokens(Heuristic, msg); got != want {
+		t.Fatalf("CountMessageTokens(Heuristic) = %d, want %d", got, want)
+	}
+	if got := CountMessageTokens(WordHeuristic, msg); got <= 0 {
+		t.Fatalf("CountMessageTokens(WordHeuristic) = %d, want > 0", got)
+	}
+}
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
@@ -32,8 +34,14 @@ type CronTool struct {
 	execTool     *ExecTool
 	allowCommand bool
 	execEnabled  bool
+
+	fallbackTarget CronTargetFunc
 }
 
+// CronTargetFunc reports where output of a job scheduled without a channel
+// or chat should go. Empty values mean no fallback is known.
+type CronTargetFunc func() (channel, chatID string)
+
 // NewCronTool creates a new CronTool
 // execTimeout: 0 means no timeout, >0 sets the timeout duration
 func NewCronTool(
@@ -69,6 +77,13 @@ func NewCronTool(
 	}, nil
 }
 
+// NewCronManageTool creates a CronTool that only manages jobs: it can list,
+// add, update, remove, enable, and disable them, but cannot schedule shell
+// commands or run jobs itself. Use it where another component executes jobs.
+func NewCronManageTool(cronService *cron.CronService) *CronTool {
+	return &CronTool{cronService: cronService}
+}
+
 // Name returns the tool name
 func (t *CronTool) Name() string {
 	return "cron"
@@ -81,7 +96,8 @@ IMPORTANT: When user asks to be reminded or scheduled, you MUST call this tool.
 Use 'at_seconds' for one-time reminders (e.g., 'remind me in 10 minutes' → at_seconds=600). 
 Use 'every_seconds' ONLY for recurring tasks (e.g., 'every 2 hours' → every_seconds=7200). 
 Use 'cron_expr' for complex recurring schedules. 
-Use 'command' to execute shell commands directly.`
+Use 'command' to execute shell commands directly.
+Jobs created by someone else can only be removed or disabled with confirm_foreign=true after the user asked for it.`
 }
 
 // Parameters returns the tool parameters schema
@@ -128,6 +144,10 @@ func (t *CronTool) Parameters() map[string]any {
 				"type":        "string",
 				"description": "Job ID (for get/update/remove/enable/disable)",
 			},
+			"confirm_foreign": map[string]any{
+				"type":        "boolean",
+				"description": "Required to remove or disable a job this agent did not create. Only set it when the user explicitly asked to change that job.",
+			},
 		},
 		"required": []string{"action"},
 	}
@@ -150,11 +170,11 @@ func (t *CronTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 	case "update":
 		return t.updateJob(ctx, args)
 	case "remove":
-		return t.removeJob(args)
+		return t.removeJob(ctx, args)
 	case "enable":
-		return t.enableJob(args, true)
+		return t.enableJob(ctx, args, true)
 	case "disable":
-		return t.enableJob(args, false)
+		return t.enableJob(ctx, args, false)
 	default:
 		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
 	}
@@ -239,21 +259,18 @@ func (t *CronTool) addJob(ctx context.Context, args map[string]any) *ToolResult
 		return ErrorResult(fmt.Sprintf("Error adding job: %v", err))
 	}
 
-	// Apply optional payload fields and persist in a single UpdateJob call
-	needsUpdate := false
-	if command != "" {
-		job.Payload.Command = command
-		needsUpdate = true
-	}
-	if needsUpdate {
-		t.cronService.UpdateJob(job)
+	// Apply optional payload fields and the creator tag in a single UpdateJob call
+	job.Payload.Command = command
+	job.CreatedBy = cronJobCreator(ctx)
+	if err := t.cronService.UpdateJob(job); err != nil {
+		return ErrorResult(fmt.Sprintf("Error saving job: %v", err))
 	}
 
 	return SilentResult(fmt.Sprintf("Cron job added: %s (id: %s)", job.Name, job.ID))
 }
 
 func (t *CronTool) listJobs(ctx context.Context) *ToolResult {
-	jobs := t.cronService.ListJobs(false)
+	jobs := t.cronService.ListJobs(true)
 
 	var accessibleJobs []cron.CronJob
 	for _, job := range jobs {
@@ -280,6 +297,14 @@ func (t *CronTool) listJobs(ctx context.Context) *ToolResult {
 		} else {
 			scheduleInfo = "unknown"
 		}
+		if !j.Enabled {
+			scheduleInfo += ", disabled"
+		}
+		if j.CreatedBy == cronJobCreator(ctx) {
+			scheduleInfo += ", created by you"
+		} else {
+			scheduleInfo += ", created by " + cronJobCreatorLabel(&j)
+		}
 		result.WriteString(fmt.Sprintf("- %s (id: %s, %s)\n", j.Name, j.ID, scheduleInfo))
 	}
 
@@ -371,10 +396,21 @@ func (t *CronTool) updateJob(ctx context.Context, args map[string]any) *ToolResu
 	return SilentResult(fmt.Sprintf("Cron job updated:\n%s", formatCronJobJSON(updated)))
 }
 
-func (t *CronTool) removeJob(args map[string]any) *ToolResult {
-	jobID, ok := args["job_id"].(string)
-	if !ok || jobID == "" {
-		return ErrorResult("job_id is required for remove")
+func (t *CronTool) removeJob(ctx context.Context, args map[string]any) *ToolResult {
+	jobID, errResult := requiredCronJobID(args, "remove")
+	if errResult != nil {
+		return errResult
+	}
+
+	job, ok := t.cronService.GetJob(jobID)
+	if !ok {
+		return ErrorResult(fmt.Sprintf("Job %s not found", jobID))
+	}
+	if !t.canAccessJob(ctx, job) {
+		return ErrorResult(fmt.Sprintf("Job %s is not accessible from this channel", jobID))
+	}
+	if errResult := confirmForeignJob(ctx, args, job, "remove"); errResult != nil {
+		return errResult
 	}
 
 	if t.cronService.RemoveJob(jobID) {
@@ -508,7 +544,44 @@ func (t *CronTool) canAccessJob(ctx context.Context, job *cron.CronJob) bool {
 	if job.Payload.Command != "" {
 		return false
 	}
-	return job.Payload.Channel == channel && job.Payload.To == chatID
+	for _, target := range job.Payload.Targets() {
+		if target.Channel == channel && target.To == chatID {
+			return true
+		}
+	}
+	return false
+}
+
+// cronJobCreator returns the CreatedBy tag for jobs added in ctx.
+func cronJobCreator(ctx context.Context) string {
+	agentID := ToolAgentID(ctx)
+	if agentID == "" {
+		agentID = routing.DefaultAgentID
+	}
+	return "agent:" + agentID
+}
+
+func cronJobCreatorLabel(job *cron.CronJob) string {
+	if job.CreatedBy == "" {
+		return "the user"
+	}
+	return job.CreatedBy
+}
+
+// confirmForeignJob requires confirm_foreign=true before removing or disabling
+// a job the current agent did not create. The flag is part of the tool
+// arguments, so tool approval hooks see it and can hold the call for a human.
+func confirmForeignJob(ctx context.Context, args map[string]any, job *cron.CronJob, action string) *ToolResult {
+	if job.CreatedBy == cronJobCreator(ctx) {
+		return nil
+	}
+	if confirm, _ := args["confirm_foreign"].(bool); confirm {
+		return nil
+	}
+	return ErrorResult(fmt.Sprintf(
+		"Job %s was created by %s; confirm_foreign=true is required to %s it",
+		job.ID, cronJobCreatorLabel(job), action,
+	))
 }
 
 func formatCronJobJSON(job *cron.CronJob) string {
@@ -519,48 +592,111 @@ func formatCronJobJSON(job *cron.CronJob) string {
 	return string(data)
 }
 
-func (t *CronTool) enableJob(args map[string]any, enable bool) *ToolResult {
-	jobID, ok := args["job_id"].(string)
-	if !ok || jobID == "" {
-		return ErrorResult("job_id is required for enable/disable")
+func (t *CronTool) enableJob(ctx context.Context, args map[string]any, enable bool) *ToolResult {
+	action := "enable"
+	if !enable {
+		action = "disable"
+	}
+	jobID, errResult := requiredCronJobID(args, action)
+	if errResult != nil {
+		return errResult
 	}
 
-	job := t.cronService.EnableJob(jobID, enable)
-	if job == nil {
+	job, ok := t.cronService.GetJob(jobID)
+	if !ok {
 		return ErrorResult(fmt.Sprintf("Job %s not found", jobID))
 	}
-
-	status := "enabled"
+	if !t.canAccessJob(ctx, job) {
+		return ErrorResult(fmt.Sprintf("Job %s is not accessible from this channel", jobID))
+	}
 	if !enable {
-		status = "disabled"
+		if errResult := confirmForeignJob(ctx, args, job, action); errResult != nil {
+			return errResult
+		}
+	}
+
+	job = t.cronService.EnableJob(jobID, enable)
+	if job == nil {
+		return ErrorResult(fmt.Sprintf("Job %s not found", jobID))
 	}
+
+	status := action + "d"
 	return SilentResult(fmt.Sprintf("Cron job '%s' %s", job.Name, status))
 }
 
-// ExecuteJob executes a cron job through the agent
-func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
-	// Get channel/chatID from job payload
-	channel := job.Payload.Channel
-	chatID := job.Payload.To
+// SetFallbackTarget sets where jobs without their own channel or chat send
+// their output. Without it, or when it has no answer, they go to cli:direct.
+func (t *CronTool) SetFallbackTarget(fn CronTargetFunc) {
+	t.fallbackTarget = fn
+}
 
-	// Default values if not set
+// cronJobTarget returns the channel and chat ID a job reports to.
+func cronJobTarget(job *cron.CronJob, fallback CronTargetFunc) (channel, chatID string) {
+	channel, chatID = job.Payload.Channel, job.Payload.To
+	if (channel == "" || chatID == "") && fallback != nil {
+		if fbChannel, fbChatID := fallback(); fbChannel != "" && fbChatID != "" {
+			return fbChannel, fbChatID
+		}
+	}
 	if channel == "" {
 		channel = "cli"
 	}
 	if chatID == "" {
 		chatID = "direct"
 	}
+	return channel, chatID
+}
+
+// cronJobTargets returns every chat a job reports to. A job with a single
+// target resolves it like cronJobTarget; the entries of a recipient list
+// always name their own channel and chat.
+func cronJobTargets(job *cron.CronJob, fallback CronTargetFunc) []cron.CronRecipient {
+	if len(job.Payload.Recipients) > 1 {
+		return job.Payload.Targets()
+	}
+	channel, chatID := cronJobTarget(job, fallback)
+	return []cron.CronRecipient{{Channel: channel, To: chatID}}
+}
+
+// publishCronOutput sends content to every target. A failed recipient does
+// not stop delivery to the others; all failures are returned together.
+func publishCronOutput(ctx context.Context, msgBus *bus.MessageBus, targets []cron.CronRecipient, content string) error {
+	var errs []error
+	for _, target := range targets {
+		pubCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
+			Context:   bus.NewOutboundContext(target.Channel, target.To, ""),
+			Content:   content,
+			Proactive: true,
+		})
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ExecuteJob executes a cron job through the agent
+func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
+	ctx = bus.WithProactive(ctx)
+	targets := cronJobTargets(job, t.fallbackTarget)
+	// Agent turns and commands run once, in the context of the first
+	// recipient; their output then goes to every recipient.
+	channel, chatID := targets[0].Channel, targets[0].To
+
+	if job.Payload.Deliver && job.Payload.Command == "" {
+		if err := DeliverCronMessage(ctx, t.msgBus, job, t.fallbackTarget); err != nil {
+			return fmt.Sprintf("Error delivering message: %v", err)
+		}
+		return "ok"
+	}
 
 	// Execute command if present
 	if job.Payload.Command != "" {
 		if !t.execEnabled || t.execTool == nil {
 			output := "Error executing scheduled command: command execution is disabled"
-			pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer pubCancel()
-			t.msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
-				Context: bus.NewOutboundContext(channel, chatID, ""),
-				Content: output,
-			})
+			publishCronOutput(context.Background(), t.msgBus, targets, output)
 			return "ok"
 		}
 
@@ -579,12 +715,7 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 			output = fmt.Sprintf("Scheduled command '%s' executed:\n%s", job.Payload.Command, result.ForLLM)
 		}
 
-		pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer pubCancel()
-		t.msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
-			Context: bus.NewOutboundContext(channel, chatID, ""),
-			Content: output,
-		})
+		publishCronOutput(context.Background(), t.msgBus, targets, output)
 		return "ok"
 	}
 
@@ -603,7 +734,9 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 	}
 
 	if response != "" {
-		t.executor.PublishResponseIfNeeded(ctx, channel, chatID, sessionKey, response)
+		for _, target := range targets {
+			t.executor.PublishResponseIfNeeded(ctx, target.Channel, target.To, sessionKey, response)
+		}
 	}
 	return "ok"
 }
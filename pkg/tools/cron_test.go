@@ -486,6 +486,92 @@ func TestCronTool_ListFiltersJobsForRemoteChannel(t *testing.T) {
 	}
 }
 
+func TestCronTool_AddTagsJobWithCreator(t *testing.T) {
+	tool := newTestCronTool(t)
+	ctx := WithToolSessionContext(WithToolContext(context.Background(), "telegram", "chat-1"), "helper", "", nil)
+
+	result := tool.Execute(ctx, map[string]any{
+		"action":        "add",
+		"message":       "summarize my feeds",
+		"every_seconds": float64(86400),
+	})
+	if result.IsError {
+		t.Fatalf("add failed: %s", result.ForLLM)
+	}
+
+	jobs := tool.cronService.ListJobs(true)
+	if len(jobs) != 1 || jobs[0].CreatedBy != "agent:helper" {
+		t.Fatalf("jobs = %+v, want one job created by agent:helper", jobs)
+	}
+	list := tool.Execute(ctx, map[string]any{"action": "list"})
+	if !strings.Contains(list.ForLLM, "created by you") {
+		t.Fatalf("list should mark the agent's own job, got: %s", list.ForLLM)
+	}
+}
+
+func TestCronTool_ForeignJobRequiresConfirmToRemoveOrDisable(t *testing.T) {
+	tool := newTestCronTool(t)
+	job, err := tool.cronService.AddJob(
+		"user job",
+		cron.CronSchedule{Kind: "cron", Expr: "0 8 * * *"},
+		"morning digest",
+		"telegram",
+		"chat-1",
+	)
+	if err != nil {
+		t.Fatalf("AddJob() error: %v", err)
+	}
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+
+	for _, action := range []string{"disable", "remove"} {
+		result := tool.Execute(ctx, map[string]any{"action": action, "job_id": job.ID})
+		if !result.IsError || !strings.Contains(result.ForLLM, "confirm_foreign=true") {
+			t.Fatalf("%s without confirmation: got %+v, want confirm_foreign error", action, result)
+		}
+	}
+	if _, ok := tool.cronService.GetJob(job.ID); !ok {
+		t.Fatal("job should survive unconfirmed remove")
+	}
+
+	list := tool.Execute(ctx, map[string]any{"action": "list"})
+	if !strings.Contains(list.ForLLM, "created by the user") {
+		t.Fatalf("list should show the job's creator, got: %s", list.ForLLM)
+	}
+
+	result := tool.Execute(ctx, map[string]any{"action": "disable", "job_id": job.ID, "confirm_foreign": true})
+	if result.IsError {
+		t.Fatalf("confirmed disable failed: %s", result.ForLLM)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "enable", "job_id": job.ID})
+	if result.IsError {
+		t.Fatalf("enable should not need confirmation: %s", result.ForLLM)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "remove", "job_id": job.ID, "confirm_foreign": true})
+	if result.IsError {
+		t.Fatalf("confirmed remove failed: %s", result.ForLLM)
+	}
+}
+
+func TestCronTool_RemoteCannotRemoveOtherChatJob(t *testing.T) {
+	tool := newTestCronTool(t)
+	job, err := tool.cronService.AddJob(
+		"private",
+		cron.CronSchedule{Kind: "cron", Expr: "0 8 * * *"},
+		"secret",
+		"telegram",
+		"chat-1",
+	)
+	if err != nil {
+		t.Fatalf("AddJob() error: %v", err)
+	}
+	ctx := WithToolContext(context.Background(), "telegram", "chat-2")
+
+	result := tool.Execute(ctx, map[string]any{"action": "remove", "job_id": job.ID, "confirm_foreign": true})
+	if !result.IsError || !strings.Contains(result.ForLLM, "not accessible") {
+		t.Fatalf("expected inaccessible remove, got: %+v", result)
+	}
+}
+
 func TestCronTool_RemoteCannotAccessOtherChatJob(t *testing.T) {
 	tool := newTestCronTool(t)
 	job, err := tool.cronService.AddJob(
@@ -747,6 +833,35 @@ func TestCronTool_ExecuteJobPublishesAgentResponse(t *testing.T) {
 	}
 }
 
+func TestCronTool_ExecuteJobUsesFallbackTarget(t *testing.T) {
+	executor := &stubJobExecutor{response: "daily summary"}
+	tool := newTestCronToolWithExecutorAndConfig(t, executor, config.DefaultConfig())
+
+	job := &cron.CronJob{ID: "job-headless"}
+	job.Payload.Message = "summarize the day"
+
+	if got := tool.ExecuteJob(context.Background(), job); got != "ok" {
+		t.Fatalf("ExecuteJob() = %q, want ok", got)
+	}
+	if executor.publishedChan != "cli" || executor.publishedChatID != "direct" {
+		t.Fatalf("target without fallback = %s/%s, want cli/direct", executor.publishedChan, executor.publishedChatID)
+	}
+
+	tool.SetFallbackTarget(func() (string, string) { return "telegram", "42" })
+	tool.ExecuteJob(context.Background(), job)
+	if executor.publishedChan != "telegram" || executor.publishedChatID != "42" {
+		t.Fatalf("target with fallback = %s/%s, want telegram/42", executor.publishedChan, executor.publishedChatID)
+	}
+
+	// A job's own target always wins over the fallback.
+	job.Payload.Channel = "discord"
+	job.Payload.To = "room"
+	tool.ExecuteJob(context.Background(), job)
+	if executor.publishedChan != "discord" || executor.publishedChatID != "room" {
+		t.Fatalf("target = %s/%s, want the job's discord/room", executor.publishedChan, executor.publishedChatID)
+	}
+}
+
 func TestCronTool_ExecuteJobSkipsEmptyAgentResponse(t *testing.T) {
 	executor := &stubJobExecutor{}
 	tool := newTestCronToolWithExecutorAndConfig(t, executor, config.DefaultConfig())
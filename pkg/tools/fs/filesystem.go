@@ -421,7 +421,7 @@ func (t *ReadFileTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 
 	file, err := t.fs.Open(path)
 	if err != nil {
-		return ErrorResult(err.Error())
+		return ErrorResult(err.Error()).WithError(err)
 	}
 	defer file.Close()
 
@@ -569,7 +569,7 @@ func (t *ReadFileLinesTool) Execute(ctx context.Context, args map[string]any) *T
 
 	file, err := t.fs.Open(path)
 	if err != nil {
-		return ErrorResult(err.Error())
+		return ErrorResult(err.Error()).WithError(err)
 	}
 	defer file.Close()
 
@@ -929,7 +929,7 @@ func (t *WriteFileTool) Execute(ctx context.Context, args map[string]any) *ToolR
 	}
 
 	if err := t.fs.WriteFile(path, []byte(content)); err != nil {
-		return ErrorResult(err.Error())
+		return ErrorResult(err.Error()).WithError(err)
 	}
 
 	return SilentResult(fmt.Sprintf("File written: %s", path))
@@ -976,7 +976,7 @@ func (t *ListDirTool) Execute(ctx context.Context, args map[string]any) *ToolRes
 
 	entries, err := t.fs.ReadDir(path)
 	if err != nil {
-		return ErrorResult(fmt.Sprintf("failed to read directory: %v", err))
+		return ErrorResult(fmt.Sprintf("failed to read directory: %v", err)).WithError(err)
 	}
 	return formatDirEntries(entries)
 }
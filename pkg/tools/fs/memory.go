@@ -0,0 +1,288 @@
+package fstools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+const (
+	// DefaultMemoryMaxBytes caps the combined size of all memory keys and values.
+	DefaultMemoryMaxBytes = 32 * 1024
+
+	maxMemoryKeyLen      = 128
+	memoryPreviewLen     = 80
+	memoryFileName       = "memory.json"
+	memoryDirName        = "memory"
+	memoryToolName       = "memory"
+	memoryActionRemember = "remember"
+	memoryActionRecall   = "recall"
+	memoryActionForget   = "forget"
+	memoryActionList     = "list"
+)
+
+// memoryFileMu serializes read-modify-write cycles on memory files, which
+// may be shared by several agents or subagents pointed at one workspace.
+var memoryFileMu sync.Mutex
+
+type memoryEntry struct {
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MemoryFilePath returns the JSON file that holds the named memories of a
+// workspace.
+func MemoryFilePath(workspace string) string {
+	return filepath.Join(workspace, memoryDirName, memoryFileName)
+}
+
+// MemoryKeys returns the sorted keys stored in the workspace memory file. A
+// missing file yields no keys and no error.
+func MemoryKeys(workspace string) ([]string, error) {
+	entries, err := loadMemory(MemoryFilePath(workspace))
+	if err != nil {
+		return nil, err
+	}
+	return sortedMemoryKeys(entries), nil
+}
+
+// MemoryTool is a small key-value store the agent controls, persisted under
+// the workspace so facts survive across sessions and restarts.
+type MemoryTool struct {
+	path     string
+	maxBytes int
+}
+
+func NewMemoryTool(workspace string, maxBytes int) *MemoryTool {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMemoryMaxBytes
+	}
+	return &MemoryTool{path: MemoryFilePath(workspace), maxBytes: maxBytes}
+}
+
+func (t *MemoryTool) Name() string { return memoryToolName }
+
+func (t *MemoryTool) Description() string {
+	return "Durable key-value memory that persists across conversations and restarts. " +
+		"Use remember to save a fact the user will expect you to know later (preferences, " +
+		"names, project details), recall to read one back, forget to delete one, and list " +
+		"to see what is stored. Keep values short and factual."
+}
+
+func (t *MemoryTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{memoryActionRemember, memoryActionRecall, memoryActionForget, memoryActionList},
+				"description": "Operation to perform.",
+			},
+			"key": map[string]any{
+				"type":        "string",
+				"description": "Short descriptive name, e.g. user_timezone. Required for remember, recall, and forget.",
+			},
+			"value": map[string]any{
+				"type":        "string",
+				"description": "Text to store under key. Required for remember; replaces any existing value.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *MemoryTool) Execute(_ context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	action = strings.ToLower(strings.TrimSpace(action))
+	key, _ := args["key"].(string)
+	key = strings.TrimSpace(key)
+
+	switch action {
+	case memoryActionList:
+		return t.list()
+	case memoryActionRemember, memoryActionRecall, memoryActionForget:
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action %q (expected remember, recall, forget, or list)", action))
+	}
+
+	if err := validateMemoryKey(key); err != nil {
+		return ErrorResult(err.Error())
+	}
+	switch action {
+	case memoryActionRemember:
+		value, _ := args["value"].(string)
+		return t.remember(key, value)
+	case memoryActionRecall:
+		return t.recall(key)
+	default:
+		return t.forget(key)
+	}
+}
+
+func (t *MemoryTool) remember(key, value string) *ToolResult {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ErrorResult("value is required for remember")
+	}
+
+	memoryFileMu.Lock()
+	defer memoryFileMu.Unlock()
+
+	entries, err := loadMemory(t.path)
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	_, existed := entries[key]
+	entries[key] = memoryEntry{Value: value, UpdatedAt: time.Now().UTC()}
+	if used := memorySize(entries); used > t.maxBytes {
+		return ErrorResult(fmt.Sprintf(
+			"memory is full: storing %q would use %d of %d bytes; forget entries you no longer need first",
+			key, used, t.maxBytes,
+		))
+	}
+	if err := saveMemory(t.path, entries); err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+
+	if existed {
+		return SilentResult(fmt.Sprintf("Updated memory %q.", key))
+	}
+	return SilentResult(fmt.Sprintf("Remembered %q.", key))
+}
+
+func (t *MemoryTool) recall(key string) *ToolResult {
+	memoryFileMu.Lock()
+	entries, err := loadMemory(t.path)
+	memoryFileMu.Unlock()
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	entry, ok := entries[key]
+	if !ok {
+		return ErrorResult(missingMemoryMessage(key, entries))
+	}
+	return NewToolResult(entry.Value)
+}
+
+func (t *MemoryTool) forget(key string) *ToolResult {
+	memoryFileMu.Lock()
+	defer memoryFileMu.Unlock()
+
+	entries, err := loadMemory(t.path)
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	if _, ok := entries[key]; !ok {
+		return ErrorResult(missingMemoryMessage(key, entries))
+	}
+	delete(entries, key)
+	if err := saveMemory(t.path, entries); err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	return SilentResult(fmt.Sprintf("Forgot %q.", key))
+}
+
+func (t *MemoryTool) list() *ToolResult {
+	memoryFileMu.Lock()
+	entries, err := loadMemory(t.path)
+	memoryFileMu.Unlock()
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	if len(entries) == 0 {
+		return NewToolResult("No memories stored.")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d memories (%d of %d bytes used):\n", len(entries), memorySize(entries), t.maxBytes)
+	for _, key := range sortedMemoryKeys(entries) {
+		preview := strings.Join(strings.Fields(entries[key].Value), " ")
+		if runes := []rune(preview); len(runes) > memoryPreviewLen {
+			preview = string(runes[:memoryPreviewLen]) + "..."
+		}
+		fmt.Fprintf(&sb, "- %s: %s\n", key, preview)
+	}
+	return NewToolResult(strings.TrimRight(sb.String(), "\n"))
+}
+
+func validateMemoryKey(key string) error {
+	if key == "" {
+		return errors.New("key is required")
+	}
+	if len([]rune(key)) > maxMemoryKeyLen {
+		return fmt.Errorf("key is too long (max %d characters)", maxMemoryKeyLen)
+	}
+	if strings.IndexFunc(key, unicode.IsControl) >= 0 {
+		return errors.New("key must not contain control characters")
+	}
+	return nil
+}
+
+func missingMemoryMessage(key string, entries map[string]memoryEntry) string {
+	if len(entries) == 0 {
+		return fmt.Sprintf("no memory stored under %q; memory is empty", key)
+	}
+	return fmt.Sprintf("no memory stored under %q; stored keys: %s",
+		key, strings.Join(sortedMemoryKeys(entries), ", "))
+}
+
+func loadMemory(path string) (map[string]memoryEntry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]memoryEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory: %w", err)
+	}
+	entries := map[string]memoryEntry{}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return entries, nil
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("memory file %s is corrupt: %w", path, err)
+	}
+	return entries, nil
+}
+
+func saveMemory(path string, entries map[string]memoryEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode memory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create memory directory: %w", err)
+	}
+	if err := fileutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save memory: %w", err)
+	}
+	return nil
+}
+
+// memorySize counts key and value bytes only, so the cap the user configures
+// does not depend on JSON formatting or timestamps.
+func memorySize(entries map[string]memoryEntry) int {
+	total := 0
+	for key, entry := range entries {
+		total += len(key) + len(entry.Value)
+	}
+	return total
+}
+
+func sortedMemoryKeys(entries map[string]memoryEntry) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,166 @@
+package fstools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func runMemory(t *testing.T, tool *MemoryTool, args map[string]any) *ToolResult {
+	t.Helper()
+	return tool.Execute(context.Background(), args)
+}
+
+func TestMemoryTool_RememberRecallForget(t *testing.T) {
+	workspace := t.TempDir()
+	tool := NewMemoryTool(workspace, 0)
+
+	result := runMemory(t, tool, map[string]any{"action": "remember", "key": "timezone", "value": "Europe/Berlin"})
+	if result.IsError || !strings.Contains(result.ForLLM, "Remembered") {
+		t.Fatalf("remember = %+v", result)
+	}
+	result = runMemory(t, tool, map[string]any{"action": "remember", "key": "timezone", "value": "Asia/Tokyo"})
+	if result.IsError || !strings.Contains(result.ForLLM, "Updated") {
+		t.Fatalf("second remember = %+v, want update", result)
+	}
+
+	// A fresh tool instance reads what the first one wrote, as after a restart.
+	reopened := NewMemoryTool(workspace, 0)
+	result = runMemory(t, reopened, map[string]any{"action": "recall", "key": "timezone"})
+	if result.IsError || result.ForLLM != "Asia/Tokyo" {
+		t.Fatalf("recall = %+v, want stored value", result)
+	}
+
+	result = runMemory(t, reopened, map[string]any{"action": "forget", "key": "timezone"})
+	if result.IsError {
+		t.Fatalf("forget error: %s", result.ForLLM)
+	}
+	result = runMemory(t, reopened, map[string]any{"action": "recall", "key": "timezone"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "memory is empty") {
+		t.Fatalf("recall after forget = %+v, want missing-key error", result)
+	}
+}
+
+func TestMemoryTool_ListAndKeys(t *testing.T) {
+	workspace := t.TempDir()
+	tool := NewMemoryTool(workspace, 0)
+	runMemory(t, tool, map[string]any{"action": "remember", "key": "repo", "value": "sipeed/picoclaw"})
+	runMemory(t, tool, map[string]any{"action": "remember", "key": "editor", "value": strings.Repeat("vim ", 40)})
+
+	result := runMemory(t, tool, map[string]any{"action": "list"})
+	if result.IsError {
+		t.Fatalf("list error: %s", result.ForLLM)
+	}
+	if !strings.HasPrefix(result.ForLLM, "2 memories") ||
+		strings.Index(result.ForLLM, "- editor:") > strings.Index(result.ForLLM, "- repo:") ||
+		!strings.Contains(result.ForLLM, "...") {
+		t.Fatalf("list = %q, want sorted entries with truncated preview", result.ForLLM)
+	}
+
+	keys, err := MemoryKeys(workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(keys, ",") != "editor,repo" {
+		t.Fatalf("MemoryKeys() = %v", keys)
+	}
+
+	result = runMemory(t, tool, map[string]any{"action": "recall", "key": "missing"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "stored keys: editor, repo") {
+		t.Fatalf("recall missing = %q, want key hint", result.ForLLM)
+	}
+}
+
+func TestMemoryTool_SizeCap(t *testing.T) {
+	workspace := t.TempDir()
+	tool := NewMemoryTool(workspace, 20)
+
+	result := runMemory(t, tool, map[string]any{"action": "remember", "key": "a", "value": "0123456789"})
+	if result.IsError {
+		t.Fatalf("remember within cap: %s", result.ForLLM)
+	}
+	result = runMemory(t, tool, map[string]any{"action": "remember", "key": "b", "value": "0123456789"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "memory is full") {
+		t.Fatalf("remember over cap = %+v, want full error", result)
+	}
+
+	keys, _ := MemoryKeys(workspace)
+	if len(keys) != 1 {
+		t.Fatalf("rejected write changed the store: %v", keys)
+	}
+}
+
+func TestMemoryTool_Validation(t *testing.T) {
+	tool := NewMemoryTool(t.TempDir(), 0)
+	tests := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"action": "shout"}, "unknown action"},
+		{map[string]any{"action": "recall"}, "key is required"},
+		{map[string]any{"action": "remember", "key": "k"}, "value is required"},
+		{map[string]any{"action": "remember", "key": "a\nb", "value": "v"}, "control characters"},
+		{map[string]any{"action": "remember", "key": strings.Repeat("k", 200), "value": "v"}, "too long"},
+	}
+	for _, tt := range tests {
+		result := runMemory(t, tool, tt.args)
+		if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+			t.Errorf("Execute(%v) = %q, want error containing %q", tt.args, result.ForLLM, tt.want)
+		}
+	}
+}
+
+func TestMemoryTool_CorruptFile(t *testing.T) {
+	workspace := t.TempDir()
+	path := MemoryFilePath(workspace)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewMemoryTool(workspace, 0)
+	result := runMemory(t, tool, map[string]any{"action": "remember", "key": "k", "value": "v"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "corrupt") {
+		t.Fatalf("remember on corrupt file = %q, want corrupt error", result.ForLLM)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "{not json" {
+		t.Fatal("corrupt memory file must not be overwritten")
+	}
+}
+
+func TestMemoryTool_ConcurrentWrites(t *testing.T) {
+	workspace := t.TempDir()
+	keys := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tool := NewMemoryTool(workspace, 0)
+			if result := runMemory(t, tool, map[string]any{"action": "remember", "key": key, "value": "v"}); result.IsError {
+				t.Errorf("remember %s: %s", key, result.ForLLM)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(MemoryFilePath(workspace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored map[string]memoryEntry
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != len(keys) {
+		t.Fatalf("stored %d entries, want %d (lost update)", len(stored), len(keys))
+	}
+}
@@ -16,9 +16,13 @@ type (
 	AppendFileTool    = fstools.AppendFileTool
 	LoadImageTool     = fstools.LoadImageTool
 	SendFileTool      = fstools.SendFileTool
+	MemoryTool        = fstools.MemoryTool
 )
 
-const MaxReadFileSize = fstools.MaxReadFileSize
+const (
+	MaxReadFileSize       = fstools.MaxReadFileSize
+	DefaultMemoryMaxBytes = fstools.DefaultMemoryMaxBytes
+)
 
 func NewReadFileTool(
 	workspace string,
@@ -98,3 +102,22 @@ func NewSendFileTool(
 ) *SendFileTool {
 	return fstools.NewSendFileTool(workspace, restrict, maxFileSize, store, allowPaths...)
 }
+
+// ValidatePathWithAllowPaths resolves path against workspace and, when
+// restrict is set, rejects paths (including symlink targets) that escape it.
+func ValidatePathWithAllowPaths(
+	path, workspace string,
+	restrict bool,
+	patterns []*regexp.Regexp,
+) (string, error) {
+	return fstools.ValidatePathWithAllowPaths(path, workspace, restrict, patterns)
+}
+
+func NewMemoryTool(workspace string, maxBytes int) *MemoryTool {
+	return fstools.NewMemoryTool(workspace, maxBytes)
+}
+
+// MemoryKeys returns the keys saved with the memory tool in workspace.
+func MemoryKeys(workspace string) ([]string, error) {
+	return fstools.MemoryKeys(workspace)
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	toolshared "github.com/sipeed/picoclaw/pkg/tools/shared"
+)
+
+const (
+	defaultFullResultLength = 16000
+	maxFullResultLength     = 50000
+)
+
+// GetFullResultTool reads back tool output that was truncated, by the handle
+// in its truncation marker. Long results are paged with offset and length,
+// like read_file.
+type GetFullResultTool struct {
+	results *toolshared.FullResults
+}
+
+// NewGetFullResultTool creates a GetFullResultTool reading from results.
+func NewGetFullResultTool(results *toolshared.FullResults) *GetFullResultTool {
+	return &GetFullResultTool{results: results}
+}
+
+func (t *GetFullResultTool) Name() string {
+	return "get_full_result"
+}
+
+func (t *GetFullResultTool) Description() string {
+	return "Read the complete output of an earlier tool call that was truncated. " +
+		"Truncated results end with a marker such as [full output: get_full_result handle=r1a2b3c4]; " +
+		"pass that handle. Supports pagination via `offset` and `length`. " +
+		"Results are kept for a limited time."
+}
+
+func (t *GetFullResultTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"handle": map[string]any{
+				"type":        "string",
+				"description": "Handle from the truncation marker.",
+			},
+			"offset": map[string]any{
+				"type":        "integer",
+				"description": "Byte offset to start reading from.",
+			},
+			"length": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Bytes to read (default %d, max %d).", defaultFullResultLength, maxFullResultLength),
+			},
+		},
+		"required": []string{"handle"},
+	}
+}
+
+func (t *GetFullResultTool) Execute(_ context.Context, args map[string]any) *ToolResult {
+	handle, _ := args["handle"].(string)
+	handle = strings.TrimSpace(handle)
+	if handle == "" {
+		return ErrorResult("handle is required")
+	}
+	offset := 0
+	if n, ok := args["offset"].(float64); ok {
+		offset = int(n)
+	}
+	if offset < 0 {
+		return ErrorResult("offset must be >= 0")
+	}
+	length := defaultFullResultLength
+	if n, ok := args["length"].(float64); ok && n > 0 {
+		length = min(int(n), maxFullResultLength)
+	}
+
+	text, ok := t.results.Get(handle)
+	if !ok {
+		return ErrorResult(fmt.Sprintf("no result with handle %q; it may have expired", handle))
+	}
+	page, start, end := FullResultPage(text, offset, length)
+	if end < len(text) {
+		page += fmt.Sprintf("\n[Showing bytes %d-%d of %d. Call get_full_result again with offset=%d to continue.]",
+			start, end, len(text), end)
+	}
+	return NewToolResult(page)
+}
+
+// FullResultPage returns about length bytes of text from offset, moved to
+// rune boundaries, with the byte range it covers.
+func FullResultPage(text string, offset, length int) (page string, start, end int) {
+	start = min(offset, len(text))
+	for start < len(text) && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	end = min(start+length, len(text))
+	for end > start && end < len(text) && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	if end == start && start < len(text) {
+		_, size := utf8.DecodeRuneInString(text[start:])
+		end = start + size
+	}
+	return text[start:end], start, end
+}
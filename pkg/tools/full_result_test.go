@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetFullResultTool_PagesThroughKeptOutput(t *testing.T) {
+	results := NewFullResults(time.Minute, 1<<20)
+	SetDefaultFullResults(results)
+	t.Cleanup(func() { SetDefaultFullResults(nil) })
+
+	full := strings.Repeat("line of exec output\n", 1000)
+	note := keepFullResult(full)
+	_, handle, found := strings.Cut(strings.TrimSuffix(note, "]"), "handle=")
+	if !found {
+		t.Fatalf("note = %q, want a handle", note)
+	}
+
+	tool := NewGetFullResultTool(results)
+	first := tool.Execute(context.Background(), map[string]any{"handle": handle, "length": float64(15000)})
+	if first.IsError || !strings.HasPrefix(first.ForLLM, full[:15000]) {
+		t.Fatalf("first page = %q...", first.ForLLM[:min(len(first.ForLLM), 80)])
+	}
+	if !strings.Contains(first.ForLLM, "offset=15000 to continue") {
+		t.Errorf("first page should say how to continue, got tail %q", first.ForLLM[15000:])
+	}
+
+	rest := tool.Execute(context.Background(), map[string]any{"handle": handle, "offset": float64(15000)})
+	if rest.IsError || rest.ForLLM != full[15000:] {
+		t.Errorf("last page = %d bytes, want the remaining %d", len(rest.ForLLM), len(full)-15000)
+	}
+
+	missing := tool.Execute(context.Background(), map[string]any{"handle": "rdeadbeef"})
+	if !missing.IsError || !strings.Contains(missing.ForLLM, "expired") {
+		t.Errorf("unknown handle result = %+v", missing)
+	}
+}
+
+func TestFullResultPage_KeepsRunesWhole(t *testing.T) {
+	text := "añb"
+	page, start, end := FullResultPage(text, 0, 2)
+	if page != "a" || start != 0 || end != 1 {
+		t.Errorf("FullResultPage(0, 2) = %q, %d, %d", page, start, end)
+	}
+	page, start, end = FullResultPage(text, 2, 1)
+	if page != "b" || start != 3 || end != 4 {
+		t.Errorf("FullResultPage(2, 1) = %q, %d, %d", page, start, end)
+	}
+	page, _, end = FullResultPage(text, 1, 1)
+	if page != "ñ" || end != 3 {
+		t.Errorf("FullResultPage(1, 1) = %q, end %d; want the whole rune", page, end)
+	}
+}
@@ -0,0 +1,578 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	gitTimeout        = 30 * time.Second
+	gitMaxOutputChars = 64 * 1024
+	gitDefaultLogSize = 10
+	gitMaxLogSize     = 100
+)
+
+var gitActions = []string{"status", "diff", "log", "add", "commit", "branch", "checkout", "reset", "push"}
+
+// GitTool runs common git operations against repositories inside the
+// workspace and returns structured results where git offers a stable format.
+type GitTool struct {
+	workspace  string
+	restrict   bool
+	allowPush  bool
+	allowReset bool
+	timeout    time.Duration
+}
+
+// NewGitTool creates a git tool confined to workspace when restrict is set.
+// allowPush enables the push action and allowReset enables hard resets; both
+// are off unless configured because they can discard or publish work.
+func NewGitTool(workspace string, restrict, allowPush, allowReset bool) *GitTool {
+	return &GitTool{
+		workspace:  workspace,
+		restrict:   restrict,
+		allowPush:  allowPush,
+		allowReset: allowReset,
+		timeout:    gitTimeout,
+	}
+}
+
+func (t *GitTool) Name() string {
+	return "git"
+}
+
+func (t *GitTool) Description() string {
+	desc := "Run git operations on a repository in the workspace: status, diff, log, add, commit, branch, " +
+		"checkout, reset. status, log, and branch return structured JSON. Prefer this over exec for git."
+	if t.allowPush {
+		desc += " push is enabled."
+	}
+	return desc
+}
+
+func (t *GitTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        gitActions,
+				"description": "Git operation to run",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Repository directory relative to the workspace (default: workspace root)",
+			},
+			"files": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Paths for add, diff, checkout, or reset, relative to the repository",
+			},
+			"message": map[string]any{
+				"type":        "string",
+				"description": "Commit message (required for commit)",
+			},
+			"branch": map[string]any{
+				"type":        "string",
+				"description": "Branch to create (branch), switch to (checkout), or push",
+			},
+			"create": map[string]any{
+				"type":        "boolean",
+				"description": "checkout: create the branch before switching",
+			},
+			"staged": map[string]any{
+				"type":        "boolean",
+				"description": "diff: show staged changes instead of unstaged ones",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "log: number of commits to return (default 10, max 100)",
+			},
+			"mode": map[string]any{
+				"type":        "string",
+				"enum":        []string{"soft", "mixed", "hard"},
+				"description": "reset: reset mode (default mixed); hard must be enabled in config",
+			},
+			"remote": map[string]any{
+				"type":        "string",
+				"description": "push: name of a configured remote (default origin)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *GitTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	if action == "" {
+		return ErrorResult("action is required")
+	}
+
+	repoPath, _ := args["path"].(string)
+	if repoPath == "" {
+		repoPath = "."
+	}
+	repoDir, err := validatePathWithAllowPaths(repoPath, t.workspace, t.restrict, nil)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	files, err := t.gitFiles(args, repoDir)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	switch action {
+	case "status":
+		return t.status(ctx, repoDir)
+	case "diff":
+		gitArgs := []string{"diff"}
+		if staged, _ := args["staged"].(bool); staged {
+			gitArgs = append(gitArgs, "--cached")
+		}
+		return t.textResult(ctx, repoDir, withPathspec(gitArgs, files), "No changes.")
+	case "log":
+		return t.log(ctx, repoDir, args)
+	case "add":
+		if len(files) == 0 {
+			return ErrorResult("files is required for add (use [\".\"] to stage everything)")
+		}
+		return t.textResult(ctx, repoDir, withPathspec([]string{"add"}, files), "Staged.")
+	case "commit":
+		message, _ := args["message"].(string)
+		if strings.TrimSpace(message) == "" {
+			return ErrorResult("message is required for commit")
+		}
+		return t.textResult(ctx, repoDir, []string{"commit", "-m", message}, "Committed.")
+	case "branch":
+		branch, _ := args["branch"].(string)
+		if branch == "" {
+			return t.branches(ctx, repoDir)
+		}
+		if err := validateGitRef(branch); err != nil {
+			return ErrorResult(err.Error())
+		}
+		return t.textResult(ctx, repoDir, []string{"branch", branch}, "Created branch "+branch+".")
+	case "checkout":
+		return t.checkout(ctx, repoDir, args, files)
+	case "reset":
+		return t.reset(ctx, repoDir, args, files)
+	case "push":
+		return t.push(ctx, repoDir, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+}
+
+// gitFiles validates the files argument: every path must resolve inside the
+// repository and the workspace.
+func (t *GitTool) gitFiles(args map[string]any, repoDir string) ([]string, error) {
+	raw, ok := args["files"].([]any)
+	if !ok {
+		return nil, nil
+	}
+	files := make([]string, 0, len(raw))
+	for _, item := range raw {
+		file, ok := item.(string)
+		if !ok || strings.TrimSpace(file) == "" {
+			return nil, fmt.Errorf("files must be non-empty strings")
+		}
+		if _, err := validatePathWithAllowPaths(filepath.Join(repoDir, file), t.workspace, t.restrict, nil); err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// gitSafetyArgs stop git from running code the model could plant in the
+// repository: hooks, an fsmonitor command, a pager, an external diff, signing
+// programs, an ssh or askpass command, or an ext:: transport set in
+// .git/config would otherwise bypass the exec tool's deny patterns. Commands
+// under per-driver or per-remote names are overridden by gitRepoConfigArgs.
+var gitSafetyArgs = []string{
+	"-c", "core.hooksPath=/dev/null",
+	"-c", "core.fsmonitor=false",
+	"-c", "core.pager=cat",
+	"-c", "core.editor=true",
+	"-c", "core.sshCommand=ssh",
+	"-c", "core.askPass=",
+	"-c", "protocol.ext.allow=never",
+	"-c", "diff.external=",
+	"-c", "commit.gpgSign=false",
+	"-c", "tag.gpgSign=false",
+	"-c", "log.showSignature=false",
+	"-c", "gpg.program=gpg",
+	"-c", "gpg.ssh.program=ssh-keygen",
+	"-c", "gpg.x509.program=gpgsm",
+}
+
+// gitCommandKeys matches config keys whose value is a command git runs and
+// whose name includes a driver, remote or URL, so they cannot be listed in
+// gitSafetyArgs.
+const gitCommandKeys = `^(filter\..+\.(clean|smudge|process)|diff\..+\.(textconv|command)|merge\..+\.driver|` +
+	`credential\.(.+\.)?helper|remote\..+\.(receivepack|uploadpack)|core\.gitproxy)$`
+
+// gitRepoConfigArgs returns -c overrides that neutralize the command keys
+// set in the repository's own config, including files it includes. The
+// remote's receive-pack program is set on the push command line instead.
+// Credential helpers are a list, so the list is reset and the user's global
+// and system helpers are added back, which keeps push working.
+func (t *GitTool) gitRepoConfigArgs(ctx context.Context, repoDir string, env []string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", repoDir, "config", "--show-scope", "-z", "--get-regexp", gitCommandKeys)
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			// No matching keys, or not inside a repository; the real
+			// command reports the latter.
+			return nil, nil
+		}
+		return nil, fmt.Errorf("git config failed: %v", err)
+	}
+
+	type entry struct{ scope, key, value string }
+	var entries []entry
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, value, _ := strings.Cut(fields[i+1], "\n")
+		entries = append(entries, entry{scope: fields[i], key: key, value: value})
+	}
+
+	var args []string
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if (e.scope != "local" && e.scope != "worktree") || seen[e.key] {
+			continue
+		}
+		seen[e.key] = true
+		switch {
+		case strings.HasPrefix(e.key, "credential."):
+			args = append(args, "-c", e.key+"=")
+			for _, other := range entries {
+				if other.key == e.key && other.scope != "local" && other.scope != "worktree" {
+					args = append(args, "-c", e.key+"="+other.value)
+				}
+			}
+		case strings.HasPrefix(e.key, "filter."):
+			// An empty command skips the filter; a required one would
+			// then fail every checkout and add.
+			driver := e.key[:strings.LastIndex(e.key, ".")]
+			args = append(args, "-c", e.key+"=", "-c", driver+".required=false")
+		default:
+			args = append(args, "-c", e.key+"=")
+		}
+	}
+	return args, nil
+}
+
+func (t *GitTool) run(ctx context.Context, repoDir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_PAGER=cat", "GIT_EDITOR=true", "LC_ALL=C")
+	if t.restrict && t.workspace != "" {
+		// Stop repository discovery at the workspace, so a path without its
+		// own repository cannot reach one that contains the workspace.
+		if ws, err := filepath.Abs(t.workspace); err == nil {
+			env = append(env, "GIT_CEILING_DIRECTORIES="+filepath.Dir(ws))
+		}
+	}
+	repoArgs, err := t.gitRepoConfigArgs(ctx, repoDir, env)
+	if err != nil {
+		return "", err
+	}
+
+	cmdArgs := append(append(append([]string{}, gitSafetyArgs...), repoArgs...), "-C", repoDir)
+	switch args[0] {
+	case "diff", "log":
+		args = append([]string{args[0], "--no-ext-diff", "--no-textconv"}, args[1:]...)
+	}
+	cmd := exec.CommandContext(ctx, "git", append(cmdArgs, args...)...)
+	cmd.Env = env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s failed: %s", args[0], msg)
+	}
+	out := stdout.String()
+	if stderr.Len() > 0 {
+		out += stderr.String()
+	}
+	return out, nil
+}
+
+func (t *GitTool) textResult(ctx context.Context, repoDir string, args []string, empty string) *ToolResult {
+	out, err := t.run(ctx, repoDir, args...)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	out = strings.TrimRight(out, "\n")
+	if out == "" {
+		out = empty
+	}
+	if len(out) > gitMaxOutputChars {
+		out = out[:gitMaxOutputChars] + "\n[Output truncated due to size limit]" + keepFullResult(out)
+	}
+	return NewToolResult(out)
+}
+
+func jsonResult(v any) *ToolResult {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to marshal result: %v", err))
+	}
+	return NewToolResult(string(data))
+}
+
+// gitStatus is the structured form of `git status --porcelain=v1 --branch`.
+type gitStatus struct {
+	Branch   string          `json:"branch"`
+	Upstream string          `json:"upstream,omitempty"`
+	Ahead    int             `json:"ahead,omitempty"`
+	Behind   int             `json:"behind,omitempty"`
+	Clean    bool            `json:"clean"`
+	Files    []gitStatusFile `json:"files,omitempty"`
+}
+
+type gitStatusFile struct {
+	Path     string `json:"path"`
+	OrigPath string `json:"orig_path,omitempty"`
+	Index    string `json:"index"`
+	Worktree string `json:"worktree"`
+}
+
+func (t *GitTool) status(ctx context.Context, repoDir string) *ToolResult {
+	out, err := t.run(ctx, repoDir, "status", "--porcelain=v1", "--branch")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	return jsonResult(parseGitStatus(out))
+}
+
+func parseGitStatus(out string) gitStatus {
+	var status gitStatus
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+		if header, ok := strings.CutPrefix(line, "## "); ok {
+			parseGitStatusHeader(header, &status)
+			continue
+		}
+		if len(line) < 4 {
+			continue
+		}
+		file := gitStatusFile{
+			Index:    gitStatusCode(line[0]),
+			Worktree: gitStatusCode(line[1]),
+			Path:     line[3:],
+		}
+		if orig, path, ok := strings.Cut(file.Path, " -> "); ok {
+			file.OrigPath, file.Path = orig, path
+		}
+		status.Files = append(status.Files, file)
+	}
+	status.Clean = len(status.Files) == 0
+	return status
+}
+
+// parseGitStatusHeader parses "main...origin/main [ahead 1, behind 2]".
+func parseGitStatusHeader(header string, status *gitStatus) {
+	header, tracking, _ := strings.Cut(header, " [")
+	if rest, ok := strings.CutPrefix(header, "No commits yet on "); ok {
+		header = rest
+	}
+	status.Branch, status.Upstream, _ = strings.Cut(header, "...")
+	for _, part := range strings.Split(strings.TrimSuffix(tracking, "]"), ", ") {
+		if n, ok := strings.CutPrefix(part, "ahead "); ok {
+			status.Ahead, _ = strconv.Atoi(n)
+		} else if n, ok := strings.CutPrefix(part, "behind "); ok {
+			status.Behind, _ = strconv.Atoi(n)
+		}
+	}
+}
+
+func gitStatusCode(c byte) string {
+	switch c {
+	case 'M':
+		return "modified"
+	case 'A':
+		return "added"
+	case 'D':
+		return "deleted"
+	case 'R':
+		return "renamed"
+	case 'C':
+		return "copied"
+	case 'U':
+		return "unmerged"
+	case 'T':
+		return "type_changed"
+	case '?':
+		return "untracked"
+	case '!':
+		return "ignored"
+	default:
+		return "unmodified"
+	}
+}
+
+type gitCommit struct {
+	Hash    string `json:"hash"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Subject string `json:"subject"`
+}
+
+func (t *GitTool) log(ctx context.Context, repoDir string, args map[string]any) *ToolResult {
+	limit := gitDefaultLogSize
+	if n, ok := args["limit"].(float64); ok && n > 0 {
+		limit = min(int(n), gitMaxLogSize)
+	}
+	out, err := t.run(ctx, repoDir, "log", "-n", strconv.Itoa(limit), "--pretty=format:%H%x1f%an%x1f%aI%x1f%s")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	commits := []gitCommit{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		commits = append(commits, gitCommit{Hash: fields[0], Author: fields[1], Date: fields[2], Subject: fields[3]})
+	}
+	return jsonResult(map[string]any{"commits": commits})
+}
+
+func (t *GitTool) branches(ctx context.Context, repoDir string) *ToolResult {
+	out, err := t.run(ctx, repoDir, "branch", "--format=%(HEAD)%(refname:short)")
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	current := ""
+	branches := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		name := strings.TrimSpace(line[1:])
+		if line[0] == '*' {
+			current = name
+		}
+		branches = append(branches, name)
+	}
+	return jsonResult(map[string]any{"current": current, "branches": branches})
+}
+
+func (t *GitTool) checkout(ctx context.Context, repoDir string, args map[string]any, files []string) *ToolResult {
+	branch, _ := args["branch"].(string)
+	if branch == "" {
+		if len(files) == 0 {
+			return ErrorResult("branch or files is required for checkout")
+		}
+		return t.textResult(ctx, repoDir, withPathspec([]string{"checkout"}, files), "Restored files.")
+	}
+	if err := validateGitRef(branch); err != nil {
+		return ErrorResult(err.Error())
+	}
+	gitArgs := []string{"checkout", branch}
+	if create, _ := args["create"].(bool); create {
+		gitArgs = []string{"checkout", "-b", branch}
+	}
+	return t.textResult(ctx, repoDir, gitArgs, "Switched to "+branch+".")
+}
+
+func (t *GitTool) reset(ctx context.Context, repoDir string, args map[string]any, files []string) *ToolResult {
+	mode, _ := args["mode"].(string)
+	switch mode {
+	case "", "mixed":
+		return t.textResult(ctx, repoDir, withPathspec([]string{"reset"}, files), "Unstaged.")
+	case "soft":
+		return t.textResult(ctx, repoDir, []string{"reset", "--soft"}, "Reset.")
+	case "hard":
+		if !t.allowReset {
+			return ErrorResult("hard reset is disabled; set tools.git.allow_reset to enable it")
+		}
+		return t.textResult(ctx, repoDir, []string{"reset", "--hard"}, "Reset.")
+	default:
+		return ErrorResult(fmt.Sprintf("unknown reset mode: %s", mode))
+	}
+}
+
+func (t *GitTool) push(ctx context.Context, repoDir string, args map[string]any) *ToolResult {
+	if !t.allowPush {
+		return ErrorResult("push is disabled; set tools.git.allow_push to enable it")
+	}
+	remote, _ := args["remote"].(string)
+	if remote == "" {
+		remote = "origin"
+	}
+	gitArgs := []string{remote}
+	if branch, _ := args["branch"].(string); branch != "" {
+		gitArgs = append(gitArgs, branch)
+	}
+	for _, ref := range gitArgs {
+		if err := validateGitRef(ref); err != nil {
+			return ErrorResult(err.Error())
+		}
+	}
+	if err := t.validateRemote(ctx, repoDir, remote); err != nil {
+		return ErrorResult(err.Error())
+	}
+	// remote.<name>.receivepack may list several programs and the first
+	// wins, so a -c override cannot replace the repository's own.
+	gitArgs = append([]string{"push", "--receive-pack=git-receive-pack"}, gitArgs...)
+	return t.textResult(ctx, repoDir, gitArgs, "Pushed.")
+}
+
+// validateRemote accepts only the name of a remote configured in the
+// repository, so push cannot be pointed at an arbitrary URL or path.
+func (t *GitTool) validateRemote(ctx context.Context, repoDir, remote string) error {
+	if strings.ContainsAny(remote, ":/") {
+		return fmt.Errorf("remote must be the name of a configured remote, not a URL or path: %q", remote)
+	}
+	out, err := t.run(ctx, repoDir, "remote")
+	if err != nil {
+		return fmt.Errorf("listing remotes: %w", err)
+	}
+	for _, name := range strings.Fields(out) {
+		if name == remote {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown remote %q; run git remote to list the configured remotes", remote)
+}
+
+// validateGitRef rejects names git would parse as options.
+func validateGitRef(name string) error {
+	if strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n") {
+		return fmt.Errorf("invalid git name %q", name)
+	}
+	return nil
+}
+
+func withPathspec(args, files []string) []string {
+	if len(files) == 0 {
+		return args
+	}
+	return append(append(args, "--"), files...)
+}
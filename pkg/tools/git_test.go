@@ -0,0 +1,298 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newGitTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	workspace := t.TempDir()
+	repo := filepath.Join(workspace, "repo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "Pico Test"},
+		{"config", "user.email", "pico@example.com"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return workspace
+}
+
+func execGit(t *testing.T, tool *GitTool, args map[string]any) *ToolResult {
+	t.Helper()
+	if _, ok := args["path"]; !ok {
+		args["path"] = "repo"
+	}
+	return tool.Execute(context.Background(), args)
+}
+
+func TestGitTool_StatusAddCommitLog(t *testing.T) {
+	workspace := newGitTestRepo(t)
+	tool := NewGitTool(workspace, true, false, false)
+	if err := os.WriteFile(filepath.Join(workspace, "repo", "a.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := execGit(t, tool, map[string]any{"action": "status"})
+	if result.IsError {
+		t.Fatalf("status failed: %s", result.ForLLM)
+	}
+	var status gitStatus
+	if err := json.Unmarshal([]byte(result.ForLLM), &status); err != nil {
+		t.Fatalf("status is not JSON: %v", err)
+	}
+	if status.Branch != "main" || status.Clean || len(status.Files) != 1 ||
+		status.Files[0].Path != "a.txt" || status.Files[0].Worktree != "untracked" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	if result = execGit(t, tool, map[string]any{"action": "add", "files": []any{"a.txt"}}); result.IsError {
+		t.Fatalf("add failed: %s", result.ForLLM)
+	}
+	if result = execGit(t, tool, map[string]any{"action": "commit", "message": "Add a.txt"}); result.IsError {
+		t.Fatalf("commit failed: %s", result.ForLLM)
+	}
+
+	result = execGit(t, tool, map[string]any{"action": "log", "limit": float64(5)})
+	if result.IsError {
+		t.Fatalf("log failed: %s", result.ForLLM)
+	}
+	var log struct {
+		Commits []gitCommit `json:"commits"`
+	}
+	if err := json.Unmarshal([]byte(result.ForLLM), &log); err != nil {
+		t.Fatalf("log is not JSON: %v", err)
+	}
+	if len(log.Commits) != 1 || log.Commits[0].Subject != "Add a.txt" || log.Commits[0].Author != "Pico Test" {
+		t.Fatalf("unexpected log: %+v", log.Commits)
+	}
+
+	result = execGit(t, tool, map[string]any{"action": "checkout", "branch": "feature", "create": true})
+	if result.IsError {
+		t.Fatalf("checkout failed: %s", result.ForLLM)
+	}
+	result = execGit(t, tool, map[string]any{"action": "branch"})
+	if !strings.Contains(result.ForLLM, `"current": "feature"`) {
+		t.Fatalf("branch = %s, want current feature", result.ForLLM)
+	}
+}
+
+func TestGitTool_GatesDestructiveActions(t *testing.T) {
+	workspace := newGitTestRepo(t)
+	tool := NewGitTool(workspace, true, false, false)
+
+	result := execGit(t, tool, map[string]any{"action": "push"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "allow_push") {
+		t.Fatalf("push = %q, want disabled error", result.ForLLM)
+	}
+	result = execGit(t, tool, map[string]any{"action": "reset", "mode": "hard"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "allow_reset") {
+		t.Fatalf("reset --hard = %q, want disabled error", result.ForLLM)
+	}
+	result = execGit(t, tool, map[string]any{"action": "checkout", "branch": "--orphan"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "invalid git name") {
+		t.Fatalf("checkout option injection = %q, want invalid name error", result.ForLLM)
+	}
+}
+
+func TestGitTool_PushOnlyToConfiguredRemotes(t *testing.T) {
+	workspace := newGitTestRepo(t)
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	tool := NewGitTool(workspace, true, true, false)
+
+	for _, name := range []string{remote, "file://" + remote, "host:repo.git", "origin"} {
+		result := execGit(t, tool, map[string]any{"action": "push", "remote": name, "branch": "main"})
+		if !result.IsError || !strings.Contains(result.ForLLM, "remote") {
+			t.Errorf("push to %q = %q, want it rejected", name, result.ForLLM)
+		}
+	}
+
+	repo := filepath.Join(workspace, "repo")
+	if out, err := exec.Command("git", "-C", repo, "remote", "add", "origin", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git remote add: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range []map[string]any{
+		{"action": "add", "files": []any{"a.txt"}},
+		{"action": "commit", "message": "Add a.txt"},
+		{"action": "push", "remote": "origin", "branch": "main"},
+	} {
+		if result := execGit(t, tool, args); result.IsError {
+			t.Fatalf("%s failed: %s", args["action"], result.ForLLM)
+		}
+	}
+}
+
+func TestGitTool_ConfinedToWorkspace(t *testing.T) {
+	workspace := newGitTestRepo(t)
+	tool := NewGitTool(workspace, true, false, false)
+
+	result := execGit(t, tool, map[string]any{"action": "status", "path": ".."})
+	if !result.IsError {
+		t.Fatalf("status outside workspace succeeded: %s", result.ForLLM)
+	}
+	result = execGit(t, tool, map[string]any{"action": "add", "files": []any{"../../outside.txt"}})
+	if !result.IsError {
+		t.Fatalf("add outside workspace succeeded: %s", result.ForLLM)
+	}
+}
+
+func TestGitTool_IgnoresRepoHooksAndCommandConfig(t *testing.T) {
+	workspace := newGitTestRepo(t)
+	repo := filepath.Join(workspace, "repo")
+	marker := filepath.Join(t.TempDir(), "ran")
+	script := "#!/bin/sh\necho ran >> " + marker + "\n"
+
+	hook := filepath.Join(repo, ".git", "hooks", "pre-commit")
+	if err := os.WriteFile(hook, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	monitor := filepath.Join(repo, "monitor.sh")
+	if err := os.WriteFile(monitor, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", repo, "config", "core.fsmonitor", monitor).CombinedOutput(); err != nil {
+		t.Fatalf("git config: %v\n%s", err, out)
+	}
+
+	tool := NewGitTool(workspace, true, false, false)
+	if result := execGit(t, tool, map[string]any{"action": "status"}); result.IsError {
+		t.Fatalf("status failed: %s", result.ForLLM)
+	}
+	if result := execGit(t, tool, map[string]any{"action": "add", "files": []any{"monitor.sh"}}); result.IsError {
+		t.Fatalf("add failed: %s", result.ForLLM)
+	}
+	if result := execGit(t, tool, map[string]any{"action": "commit", "message": "Add script"}); result.IsError {
+		t.Fatalf("commit failed: %s", result.ForLLM)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("repository hook or fsmonitor ran (stat err = %v)", err)
+	}
+}
+
+func TestGitTool_IgnoresCommandsInRepoConfig(t *testing.T) {
+	workspace := newGitTestRepo(t)
+	repo := filepath.Join(workspace, "repo")
+	markerDir := t.TempDir()
+	script := func(name string) string {
+		path := filepath.Join(markerDir, name+".sh")
+		body := "#!/bin/sh\necho ran > " + filepath.Join(markerDir, name) + "\ncat\n"
+		if err := os.WriteFile(path, []byte(body), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	include := filepath.Join(repo, "extra.config")
+	if err := os.WriteFile(include, []byte("[diff \"evil\"]\n\ttextconv = "+script("textconv")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, kv := range [][2]string{
+		{"filter.evil.clean", script("clean")},
+		{"filter.evil.smudge", script("smudge")},
+		{"filter.evil.required", "true"},
+		{"diff.external", script("external")},
+		{"include.path", include},
+		{"commit.gpgSign", "true"},
+		{"gpg.program", script("gpg")},
+		{"credential.helper", "!" + script("credential")},
+		{"remote.origin.url", remote},
+		{"remote.origin.receivepack", script("receivepack")},
+	} {
+		if out, err := exec.Command("git", "-C", repo, "config", kv[0], kv[1]).CombinedOutput(); err != nil {
+			t.Fatalf("git config %s: %v\n%s", kv[0], err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".gitattributes"), []byte("*.txt filter=evil diff=evil\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewGitTool(workspace, true, true, false)
+	for _, args := range []map[string]any{
+		{"action": "add", "files": []any{"."}},
+		{"action": "commit", "message": "First"},
+	} {
+		if result := execGit(t, tool, args); result.IsError {
+			t.Fatalf("%s failed: %s", args["action"], result.ForLLM)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range []map[string]any{
+		{"action": "diff"},
+		{"action": "log"},
+		{"action": "checkout", "files": []any{"a.txt"}},
+		{"action": "push", "branch": "main"},
+	} {
+		if result := execGit(t, tool, args); result.IsError {
+			t.Fatalf("%s failed: %s", args["action"], result.ForLLM)
+		}
+	}
+
+	entries, err := os.ReadDir(markerDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".sh") {
+			t.Errorf("command from repository config ran: %s", entry.Name())
+		}
+	}
+}
+
+func TestGitTool_DoesNotFindRepoAboveWorkspace(t *testing.T) {
+	outer := newGitTestRepo(t)
+	workspace := filepath.Join(outer, "repo", "workspace")
+	if err := os.MkdirAll(workspace, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewGitTool(workspace, true, false, false)
+	result := tool.Execute(context.Background(), map[string]any{"action": "status"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "not a git repository") {
+		t.Fatalf("status should not reach the repository above the workspace: %s", result.ForLLM)
+	}
+}
+
+func TestParseGitStatus(t *testing.T) {
+	status := parseGitStatus("## main...origin/main [ahead 2, behind 1]\n M changed.go\nR  old.go -> new.go\n")
+	if status.Branch != "main" || status.Upstream != "origin/main" || status.Ahead != 2 || status.Behind != 1 {
+		t.Fatalf("unexpected header: %+v", status)
+	}
+	if len(status.Files) != 2 {
+		t.Fatalf("files = %+v, want 2", status.Files)
+	}
+	if f := status.Files[0]; f.Path != "changed.go" || f.Index != "unmodified" || f.Worktree != "modified" {
+		t.Fatalf("unexpected first file: %+v", f)
+	}
+	if f := status.Files[1]; f.Path != "new.go" || f.OrigPath != "old.go" || f.Index != "renamed" {
+		t.Fatalf("unexpected renamed file: %+v", f)
+	}
+}
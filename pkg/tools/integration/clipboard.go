@@ -0,0 +1,148 @@
+package integrationtools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	clipboardTimeout  = 5 * time.Second
+	clipboardMaxChars = 64 * 1024
+)
+
+// clipboardBackend is the pair of commands that read from and write to the
+// system clipboard. write receives the new contents on stdin.
+type clipboardBackend struct {
+	read  []string
+	write []string
+}
+
+// errNoClipboard is returned by platform backends when no clipboard is
+// reachable, e.g. on a headless server.
+var errNoClipboard = errors.New("no system clipboard is available")
+
+// detectClipboard is replaced in tests.
+var detectClipboard = platformClipboard
+
+// ClipboardTool reads and writes the desktop clipboard through the
+// platform's clipboard utilities.
+type ClipboardTool struct{}
+
+func NewClipboardTool() *ClipboardTool {
+	return &ClipboardTool{}
+}
+
+func (t *ClipboardTool) Name() string {
+	return "clipboard"
+}
+
+func (t *ClipboardTool) Description() string {
+	return "Read or write the text clipboard of the desktop PicoClaw runs on. Actions: read (return what the user last copied), write (put text on the clipboard so the user can paste it). Fails on headless machines."
+}
+
+func (t *ClipboardTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"read", "write"},
+				"description": "read returns the clipboard text; write replaces it with text",
+			},
+			"text": map[string]any{
+				"type":        "string",
+				"description": "Text to copy to the clipboard. Required for write.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ClipboardTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	switch action {
+	case "read":
+		return t.read(ctx)
+	case "write":
+		text, ok := args["text"].(string)
+		if !ok {
+			return ErrorResult("text is required for write")
+		}
+		return t.write(ctx, text)
+	default:
+		return ErrorResult("action must be read or write")
+	}
+}
+
+func (t *ClipboardTool) read(ctx context.Context) *ToolResult {
+	backend, err := detectClipboard()
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("clipboard unavailable: %v", err))
+	}
+	out, err := runClipboardCommand(ctx, backend.read, "")
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to read clipboard: %v", err))
+	}
+	if !utf8.Valid(out) {
+		return ErrorResult("clipboard does not contain text")
+	}
+
+	text := string(out)
+	if text == "" {
+		return SilentResult("The clipboard is empty.")
+	}
+	if runes := []rune(text); len(runes) > clipboardMaxChars {
+		text = string(runes[:clipboardMaxChars]) +
+			fmt.Sprintf("\n[clipboard truncated: showing %d of %d characters]", clipboardMaxChars, len(runes))
+	}
+	return SilentResult(text)
+}
+
+func (t *ClipboardTool) write(ctx context.Context, text string) *ToolResult {
+	backend, err := detectClipboard()
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("clipboard unavailable: %v", err))
+	}
+	if _, err := runClipboardCommand(ctx, backend.write, text); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to write clipboard: %v", err))
+	}
+	return SilentResult(fmt.Sprintf("Copied %d characters to the clipboard.", utf8.RuneCountInString(text)))
+}
+
+func runClipboardCommand(ctx context.Context, argv []string, stdin string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, clipboardTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", argv[0], err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", argv[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// firstAvailable returns the first backend whose commands are installed.
+func firstAvailable(candidates []clipboardBackend, missing string) (clipboardBackend, error) {
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c.read[0]); err != nil {
+			continue
+		}
+		if _, err := exec.LookPath(c.write[0]); err != nil {
+			continue
+		}
+		return c, nil
+	}
+	return clipboardBackend{}, fmt.Errorf("%w: %s", errNoClipboard, missing)
+}
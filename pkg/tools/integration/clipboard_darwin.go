@@ -0,0 +1,9 @@
+//go:build darwin
+
+package integrationtools
+
+func platformClipboard() (clipboardBackend, error) {
+	return firstAvailable([]clipboardBackend{
+		{read: []string{"pbpaste"}, write: []string{"pbcopy"}},
+	}, "pbcopy and pbpaste not found")
+}
@@ -0,0 +1,23 @@
+//go:build linux
+
+package integrationtools
+
+import (
+	"fmt"
+	"os"
+)
+
+func platformClipboard() (clipboardBackend, error) {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return firstAvailable([]clipboardBackend{
+			{read: []string{"wl-paste", "--no-newline"}, write: []string{"wl-copy"}},
+		}, "install wl-clipboard")
+	}
+	if os.Getenv("DISPLAY") != "" {
+		return firstAvailable([]clipboardBackend{
+			{read: []string{"xclip", "-selection", "clipboard", "-o"}, write: []string{"xclip", "-selection", "clipboard", "-i"}},
+			{read: []string{"xsel", "--clipboard", "--output"}, write: []string{"xsel", "--clipboard", "--input"}},
+		}, "install xclip or xsel")
+	}
+	return clipboardBackend{}, fmt.Errorf("%w: no graphical session (DISPLAY and WAYLAND_DISPLAY are unset)", errNoClipboard)
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package integrationtools
+
+import "fmt"
+
+func platformClipboard() (clipboardBackend, error) {
+	return clipboardBackend{}, fmt.Errorf("%w on this platform", errNoClipboard)
+}
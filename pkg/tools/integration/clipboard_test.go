@@ -0,0 +1,94 @@
+//go:build !windows
+
+package integrationtools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func useFakeClipboard(t *testing.T, contents string) string {
+	t.Helper()
+	store := filepath.Join(t.TempDir(), "clipboard")
+	if err := os.WriteFile(store, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	orig := detectClipboard
+	detectClipboard = func() (clipboardBackend, error) {
+		return clipboardBackend{
+			read:  []string{"cat", store},
+			write: []string{"sh", "-c", `cat > "$0"`, store},
+		}, nil
+	}
+	t.Cleanup(func() { detectClipboard = orig })
+	return store
+}
+
+func TestClipboardTool_ReadAndWrite(t *testing.T) {
+	store := useFakeClipboard(t, "copied text")
+	tool := NewClipboardTool()
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "read"})
+	if result.IsError || result.ForLLM != "copied text" {
+		t.Fatalf("read result = %+v, want clipboard contents", result)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"action": "write", "text": "héllo"})
+	if result.IsError {
+		t.Fatalf("write error: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "5 characters") {
+		t.Fatalf("write result = %q, want character count", result.ForLLM)
+	}
+	got, err := os.ReadFile(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "héllo" {
+		t.Fatalf("clipboard = %q, want %q", got, "héllo")
+	}
+}
+
+func TestClipboardTool_ReadEmptyAndBinary(t *testing.T) {
+	store := useFakeClipboard(t, "")
+	tool := NewClipboardTool()
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "read"})
+	if result.IsError || !strings.Contains(result.ForLLM, "empty") {
+		t.Fatalf("read empty = %+v", result)
+	}
+
+	if err := os.WriteFile(store, []byte{0xff, 0xfe, 0x00}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result = tool.Execute(context.Background(), map[string]any{"action": "read"})
+	if !result.IsError {
+		t.Fatalf("read binary = %+v, want error", result)
+	}
+}
+
+func TestClipboardTool_Errors(t *testing.T) {
+	tool := NewClipboardTool()
+	useFakeClipboard(t, "")
+
+	for _, args := range []map[string]any{
+		{},
+		{"action": "paste"},
+		{"action": "write"},
+	} {
+		if result := tool.Execute(context.Background(), args); !result.IsError {
+			t.Errorf("Execute(%v) should fail", args)
+		}
+	}
+
+	detectClipboard = func() (clipboardBackend, error) {
+		return clipboardBackend{}, errNoClipboard
+	}
+	result := tool.Execute(context.Background(), map[string]any{"action": "read"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "clipboard unavailable") {
+		t.Fatalf("headless read = %+v, want unavailable error", result)
+	}
+}
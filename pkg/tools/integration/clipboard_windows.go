@@ -0,0 +1,15 @@
+//go:build windows
+
+package integrationtools
+
+func platformClipboard() (clipboardBackend, error) {
+	return firstAvailable([]clipboardBackend{
+		{
+			read: []string{"powershell", "-NoProfile", "-NonInteractive", "-Command", "Get-Clipboard -Raw"},
+			write: []string{
+				"powershell", "-NoProfile", "-NonInteractive", "-Command",
+				"[Console]::InputEncoding = [Text.Encoding]::UTF8; Set-Clipboard -Value ([Console]::In.ReadToEnd())",
+			},
+		},
+	}, "powershell not found")
+}
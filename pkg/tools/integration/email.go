@@ -0,0 +1,466 @@
+package integrationtools
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	fstools "github.com/sipeed/picoclaw/pkg/tools/fs"
+)
+
+const (
+	defaultSMTPPort      = 587
+	smtpImplicitTLSPort  = 465
+	emailSendTimeout     = 30 * time.Second
+	maxEmailRecipients   = 20
+	maxEmailAttachments  = 10
+	emailBase64LineWidth = 76
+)
+
+// EmailSendToolOptions holds the settings for NewEmailSendTool.
+type EmailSendToolOptions struct {
+	SMTPHost string
+	SMTPPort int
+	Username string
+	Password string
+	From     string
+	// AllowedDomains limits recipients to these domains and their
+	// subdomains. Empty allows any domain.
+	AllowedDomains []string
+	// RequireConfirm rejects calls without confirm=true.
+	RequireConfirm bool
+
+	Workspace   string
+	Restrict    bool
+	AllowPaths  []*regexp.Regexp
+	MaxFileSize int
+}
+
+// EmailSendTool sends an email over SMTP as an action, independent of any
+// email chat channel. Attachments are read from the workspace.
+type EmailSendTool struct {
+	host           string
+	port           int
+	username       string
+	password       string
+	from           *mail.Address
+	allowedDomains []string
+	requireConfirm bool
+
+	workspace   string
+	restrict    bool
+	allowPaths  []*regexp.Regexp
+	maxFileSize int
+
+	now func() time.Time
+}
+
+func NewEmailSendTool(opts EmailSendToolOptions) (*EmailSendTool, error) {
+	host := strings.TrimSpace(opts.SMTPHost)
+	if host == "" {
+		return nil, fmt.Errorf("send_email: smtp_host is required")
+	}
+	port := opts.SMTPPort
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	if port < 0 || port > 65535 {
+		return nil, fmt.Errorf("send_email: invalid smtp_port %d", opts.SMTPPort)
+	}
+	from, err := mail.ParseAddress(strings.TrimSpace(opts.From))
+	if err != nil {
+		return nil, fmt.Errorf("send_email: invalid from address %q: %w", opts.From, err)
+	}
+
+	var domains []string
+	for _, d := range opts.AllowedDomains {
+		d = strings.ToLower(strings.Trim(strings.TrimSpace(d), "@."))
+		if d != "" {
+			domains = append(domains, d)
+		}
+	}
+
+	maxFileSize := opts.MaxFileSize
+	if maxFileSize <= 0 {
+		maxFileSize = config.DefaultMaxMediaSize
+	}
+
+	return &EmailSendTool{
+		host:           host,
+		port:           port,
+		username:       opts.Username,
+		password:       opts.Password,
+		from:           from,
+		allowedDomains: domains,
+		requireConfirm: opts.RequireConfirm,
+		workspace:      opts.Workspace,
+		restrict:       opts.Restrict,
+		allowPaths:     opts.AllowPaths,
+		maxFileSize:    maxFileSize,
+		now:            time.Now,
+	}, nil
+}
+
+func (t *EmailSendTool) Name() string { return "send_email" }
+
+func (t *EmailSendTool) Description() string {
+	desc := "Send an email to one or more recipients, optionally with files from the workspace attached."
+	if t.requireConfirm {
+		desc += " Sending has real-world effect: show the user the recipients, subject and body, " +
+			"and call with confirm=true only after they agree."
+	}
+	if len(t.allowedDomains) > 0 {
+		desc += " Recipients must be in: " + strings.Join(t.allowedDomains, ", ") + "."
+	}
+	return desc
+}
+
+func (t *EmailSendTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"to": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Recipient email addresses.",
+			},
+			"subject": map[string]any{
+				"type":        "string",
+				"description": "Subject line.",
+			},
+			"body": map[string]any{
+				"type":        "string",
+				"description": "Plain-text message body.",
+			},
+			"attachments": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Optional file paths to attach. Relative paths are resolved from the workspace.",
+			},
+			"confirm": map[string]any{
+				"type":        "boolean",
+				"description": "Set to true once the user has approved sending this email.",
+			},
+		},
+		"required": []string{"to", "subject", "body"},
+	}
+}
+
+type emailAttachment struct {
+	name string
+	data []byte
+}
+
+func (t *EmailSendTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	to, err := t.recipients(args["to"])
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	subject, _ := args["subject"].(string)
+	subject = strings.TrimSpace(subject)
+	if subject == "" {
+		return ErrorResult("subject is required")
+	}
+	if strings.ContainsAny(subject, "\r\n") {
+		return ErrorResult("subject must be a single line")
+	}
+	body, _ := args["body"].(string)
+	if strings.TrimSpace(body) == "" {
+		return ErrorResult("body is required")
+	}
+	if confirm, _ := args["confirm"].(bool); t.requireConfirm && !confirm {
+		return ErrorResult(fmt.Sprintf(
+			"confirm=true is required to send email. Ask the user to approve sending %q to %s first.",
+			subject, strings.Join(to, ", "),
+		))
+	}
+	attachments, err := t.loadAttachments(args["attachments"])
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	msg, err := t.buildMessage(to, subject, body, attachments)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to build email: %v", err))
+	}
+	if err := t.send(ctx, to, msg); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to send email: %v", err))
+	}
+
+	result := fmt.Sprintf("Email %q sent to %s", subject, strings.Join(to, ", "))
+	if len(attachments) > 0 {
+		result += fmt.Sprintf(" with %d attachment(s)", len(attachments))
+	}
+	return NewToolResult(result)
+}
+
+// recipients parses and checks the "to" argument, which may be an array or
+// a comma-separated string.
+func (t *EmailSendTool) recipients(raw any) ([]string, error) {
+	var values []string
+	switch v := raw.(type) {
+	case string:
+		values = strings.Split(v, ",")
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("to must contain only strings")
+			}
+			values = append(values, s)
+		}
+	case []string:
+		values = v
+	}
+
+	var to []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		addr, err := mail.ParseAddress(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %v", value, err)
+		}
+		if !t.domainAllowed(addr.Address) {
+			return nil, fmt.Errorf("recipient %s is not in an allowed domain (%s)",
+				addr.Address, strings.Join(t.allowedDomains, ", "))
+		}
+		key := strings.ToLower(addr.Address)
+		if !seen[key] {
+			seen[key] = true
+			to = append(to, addr.Address)
+		}
+	}
+	if len(to) == 0 {
+		return nil, fmt.Errorf("to is required")
+	}
+	if len(to) > maxEmailRecipients {
+		return nil, fmt.Errorf("too many recipients: %d (max %d)", len(to), maxEmailRecipients)
+	}
+	return to, nil
+}
+
+func (t *EmailSendTool) domainAllowed(address string) bool {
+	if len(t.allowedDomains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(address[at+1:])
+	for _, allowed := range t.allowedDomains {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *EmailSendTool) loadAttachments(raw any) ([]emailAttachment, error) {
+	items, _ := raw.([]any)
+	if len(items) > maxEmailAttachments {
+		return nil, fmt.Errorf("too many attachments: %d (max %d)", len(items), maxEmailAttachments)
+	}
+	attachments := make([]emailAttachment, 0, len(items))
+	for _, item := range items {
+		path, _ := item.(string)
+		if strings.TrimSpace(path) == "" {
+			return nil, fmt.Errorf("attachment paths must be non-empty strings")
+		}
+		resolved, err := fstools.ValidatePathWithAllowPaths(path, t.workspace, t.restrict, t.allowPaths)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attachment path %q: %v", path, err)
+		}
+		info, err := os.Stat(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("attachment not found: %v", err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("attachment %q is a directory", path)
+		}
+		if info.Size() > int64(t.maxFileSize) {
+			return nil, fmt.Errorf("attachment %q too large: %d bytes (max %d bytes)",
+				path, info.Size(), t.maxFileSize)
+		}
+		data, err := os.ReadFile(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment %q: %v", path, err)
+		}
+		attachments = append(attachments, emailAttachment{name: filepath.Base(resolved), data: data})
+	}
+	return attachments, nil
+}
+
+func (t *EmailSendTool) buildMessage(
+	to []string,
+	subject, body string,
+	attachments []emailAttachment,
+) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+
+	recipients := make([]string, len(to))
+	for i, addr := range to {
+		recipients[i] = (&mail.Address{Address: addr}).String()
+	}
+	header("From", t.from.String())
+	header("To", strings.Join(recipients, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", t.now().Format(time.RFC1123Z))
+	header("Message-ID", t.messageID())
+	header("MIME-Version", "1.0")
+
+	if len(attachments) == 0 {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		return buf.Bytes(), writeQuotedPrintable(&buf, body)
+	}
+
+	mw := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+	buf.WriteString("\r\n")
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeQuotedPrintable(part, body); err != nil {
+		return nil, err
+	}
+
+	for _, att := range attachments {
+		contentType := mime.TypeByExtension(filepath.Ext(att.name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": att.name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(att.data)
+		for len(encoded) > emailBase64LineWidth {
+			fmt.Fprintf(part, "%s\r\n", encoded[:emailBase64LineWidth])
+			encoded = encoded[emailBase64LineWidth:]
+		}
+		fmt.Fprintf(part, "%s\r\n", encoded)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	body = strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n")
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func (t *EmailSendTool) messageID() string {
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	domain := t.from.Address[strings.LastIndex(t.from.Address, "@")+1:]
+	return "<" + hex.EncodeToString(b[:]) + "@" + domain + ">"
+}
+
+// send delivers msg over SMTP. PlainAuth refuses to send credentials over an
+// unencrypted connection to anything but localhost.
+func (t *EmailSendTool) send(ctx context.Context, to []string, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, emailSendTimeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(t.host, strconv.Itoa(t.port))
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if t.port == smtpImplicitTLSPort {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: t.host}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, t.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if t.port != smtpImplicitTLSPort {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: t.host}); err != nil {
+				return fmt.Errorf("starttls: %w", err)
+			}
+		}
+	}
+	if t.username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("server does not support authentication")
+		}
+		if err := client.Auth(smtp.PlainAuth("", t.username, t.password, t.host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	if err := client.Mail(t.from.Address); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("recipient %s: %w", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,247 @@
+package integrationtools
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSMTPServer accepts one plain-text SMTP session and records the
+// envelope and message.
+type fakeSMTPServer struct {
+	ln   net.Listener
+	done chan struct{}
+
+	mu   sync.Mutex
+	from string
+	rcpt []string
+	data string
+}
+
+func startFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeSMTPServer{ln: ln, done: make(chan struct{})}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		upper := strings.ToUpper(cmd)
+		switch {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = strings.Trim(cmd[len("MAIL FROM:"):], "<> ")
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			s.mu.Lock()
+			s.rcpt = append(s.rcpt, strings.Trim(cmd[len("RCPT TO:"):], "<> "))
+			s.mu.Unlock()
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 queued")
+		case upper == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func newTestEmailTool(t *testing.T, port int, opts EmailSendToolOptions) *EmailSendTool {
+	t.Helper()
+	opts.SMTPHost = "127.0.0.1"
+	opts.SMTPPort = port
+	if opts.From == "" {
+		opts.From = "PicoClaw <bot@example.com>"
+	}
+	tool, err := NewEmailSendTool(opts)
+	if err != nil {
+		t.Fatalf("NewEmailSendTool() error = %v", err)
+	}
+	return tool
+}
+
+func TestEmailSendTool_SendsMessageWithAttachment(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "report.txt"), []byte("quarterly numbers"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	tool := newTestEmailTool(t, server.port(), EmailSendToolOptions{Workspace: workspace, Restrict: true})
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"to":          []any{"alice@example.com", "Bob <bob@example.org>"},
+		"subject":     "Weekly summary",
+		"body":        "Hello,\nthe report is attached.",
+		"attachments": []any{"report.txt"},
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error = %s", result.ForLLM)
+	}
+	<-server.done
+
+	if server.from != "bot@example.com" {
+		t.Errorf("MAIL FROM = %q", server.from)
+	}
+	if strings.Join(server.rcpt, ",") != "alice@example.com,bob@example.org" {
+		t.Errorf("RCPT TO = %v", server.rcpt)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(server.data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if got := msg.Header.Get("Subject"); got != "Weekly summary" {
+		t.Errorf("Subject = %q", got)
+	}
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("ParseMediaType: %v", err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []string
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		var body io.Reader = part
+		if part.Header.Get("Content-Transfer-Encoding") == "base64" {
+			body = base64.NewDecoder(base64.StdEncoding, part)
+		}
+		data, _ := io.ReadAll(body)
+		parts = append(parts, part.FileName()+"="+string(data))
+	}
+	if len(parts) != 2 || parts[0] != "=Hello,\r\nthe report is attached." || parts[1] != "report.txt=quarterly numbers" {
+		t.Errorf("parts = %q", parts)
+	}
+}
+
+func TestEmailSendTool_RequiresConfirmation(t *testing.T) {
+	tool := newTestEmailTool(t, 1, EmailSendToolOptions{RequireConfirm: true})
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"to":      "alice@example.com",
+		"subject": "Hi",
+		"body":    "Hello",
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "confirm=true") {
+		t.Fatalf("Execute() = %+v, want confirmation error", result)
+	}
+	if !strings.Contains(tool.Description(), "confirm=true") {
+		t.Errorf("Description() = %q, want confirmation hint", tool.Description())
+	}
+}
+
+func TestEmailSendTool_RejectsInvalidRecipients(t *testing.T) {
+	tool := newTestEmailTool(t, 1, EmailSendToolOptions{AllowedDomains: []string{"@Example.com"}})
+
+	tests := []struct {
+		name string
+		to   any
+		want string
+	}{
+		{"malformed", "not-an-address", "invalid recipient"},
+		{"outside allowlist", []any{"eve@evil.test"}, "not in an allowed domain"},
+		{"lookalike domain", []any{"eve@notexample.com"}, "not in an allowed domain"},
+		{"missing", []any{}, "to is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tool.Execute(context.Background(), map[string]any{
+				"to": tt.to, "subject": "Hi", "body": "Hello",
+			})
+			if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+				t.Fatalf("Execute() = %q, want error containing %q", result.ForLLM, tt.want)
+			}
+		})
+	}
+
+	if !tool.domainAllowed("ops@mail.example.com") {
+		t.Error("subdomain of an allowed domain should be accepted")
+	}
+}
+
+func TestEmailSendTool_RejectsAttachmentOutsideWorkspace(t *testing.T) {
+	tool := newTestEmailTool(t, 1, EmailSendToolOptions{Workspace: t.TempDir(), Restrict: true})
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"to":          "alice@example.com",
+		"subject":     "Hi",
+		"body":        "Hello",
+		"attachments": []any{"../../etc/passwd"},
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "invalid attachment path") {
+		t.Fatalf("Execute() = %q, want path error", result.ForLLM)
+	}
+}
+
+func TestNewEmailSendTool_ValidatesConfig(t *testing.T) {
+	if _, err := NewEmailSendTool(EmailSendToolOptions{From: "bot@example.com"}); err == nil {
+		t.Error("missing smtp_host should fail")
+	}
+	if _, err := NewEmailSendTool(EmailSendToolOptions{SMTPHost: "smtp.example.com", From: "bot"}); err == nil {
+		t.Error("invalid from address should fail")
+	}
+	tool, err := NewEmailSendTool(EmailSendToolOptions{SMTPHost: "smtp.example.com", From: "bot@example.com"})
+	if err != nil || tool.port != defaultSMTPPort {
+		t.Fatalf("NewEmailSendTool() = %v, %v; want default port %d", tool, err, defaultSMTPPort)
+	}
+}
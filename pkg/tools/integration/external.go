@@ -0,0 +1,181 @@
+package integrationtools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	toolshared "github.com/sipeed/picoclaw/pkg/tools/shared"
+)
+
+const (
+	defaultExternalToolTimeout = 60 * time.Second
+	maxExternalToolOutputBytes = 1 << 20
+)
+
+var externalToolNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ExternalTool runs a configured command for each call. It is a lightweight
+// alternative to MCP for script-backed tools: the arguments go to the
+// command's stdin as JSON, and a ToolResult JSON object is read from stdout.
+type ExternalTool struct {
+	name        string
+	description string
+	command     string
+	args        []string
+	env         []string
+	schema      map[string]any
+	workspace   string
+	timeout     time.Duration
+}
+
+// NewExternalTool validates cfg and returns the tool. Commands run in
+// workspace.
+func NewExternalTool(cfg config.ExternalToolConfig, workspace string) (*ExternalTool, error) {
+	name := strings.TrimSpace(cfg.Name)
+	if !externalToolNameRe.MatchString(name) {
+		return nil, fmt.Errorf("external tool name %q must be 1-64 letters, digits, '_' or '-'", cfg.Name)
+	}
+	command := strings.TrimSpace(cfg.Command)
+	if command == "" {
+		return nil, fmt.Errorf("external tool %q has no command", name)
+	}
+	schema := cfg.Schema
+	if schema == nil {
+		schema = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	description := strings.TrimSpace(cfg.Description)
+	if description == "" {
+		description = fmt.Sprintf("Run the external %q tool.", name)
+	}
+	timeout := defaultExternalToolTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	env := make([]string, 0, len(cfg.Env))
+	for k, v := range cfg.Env {
+		env = append(env, k+"="+v)
+	}
+	return &ExternalTool{
+		name:        name,
+		description: description,
+		command:     command,
+		args:        append([]string(nil), cfg.Args...),
+		env:         env,
+		schema:      schema,
+		workspace:   workspace,
+		timeout:     timeout,
+	}, nil
+}
+
+func (t *ExternalTool) Name() string {
+	return t.name
+}
+
+func (t *ExternalTool) Description() string {
+	return t.description
+}
+
+func (t *ExternalTool) Parameters() map[string]any {
+	return t.schema
+}
+
+func (t *ExternalTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	if args == nil {
+		args = map[string]any{}
+	}
+	input, err := json.Marshal(args)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to encode arguments: %v", err)).
+			WithErrorCode(toolshared.ErrorCodeInvalid)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, t.command, t.args...)
+	cmd.Dir = t.workspace
+	cmd.Env = append(os.Environ(), t.env...)
+	cmd.Env = append(cmd.Env,
+		"PICOCLAW_TOOL_CHANNEL="+ToolChannel(ctx),
+		"PICOCLAW_TOOL_CHAT_ID="+ToolChatID(ctx),
+	)
+	cmd.Stdin = bytes.NewReader(input)
+	stdout := &limitedBuffer{limit: maxExternalToolOutputBytes}
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	// Do not wait forever on pipes held open by children the command left
+	// behind after it was killed.
+	cmd.WaitDelay = time.Second
+
+	runErr := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrorResult(fmt.Sprintf("external tool %s timed out after %s", t.name, t.timeout)).
+			WithError(ctx.Err())
+	}
+	if runErr != nil {
+		msg := fmt.Sprintf("external tool %s failed: %v", t.name, runErr)
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			msg += "\n" + detail
+		}
+		return ErrorResult(msg).WithError(runErr)
+	}
+	if stdout.truncated {
+		return ErrorResult(fmt.Sprintf("external tool %s printed more than %d bytes", t.name, maxExternalToolOutputBytes))
+	}
+	return parseExternalToolResult(t.name, stdout.Bytes())
+}
+
+// parseExternalToolResult decodes the command's stdout. Only the fields a
+// script can meaningfully set are honored.
+func parseExternalToolResult(name string, out []byte) *ToolResult {
+	var decoded struct {
+		ForLLM    string               `json:"for_llm"`
+		ForUser   string               `json:"for_user"`
+		Silent    bool                 `json:"silent"`
+		IsError   bool                 `json:"is_error"`
+		ErrorCode toolshared.ErrorCode `json:"error_code"`
+	}
+	if err := json.Unmarshal(bytes.TrimSpace(out), &decoded); err != nil {
+		return ErrorResult(fmt.Sprintf(
+			"external tool %s did not print a JSON tool result: %v", name, err)).WithError(err)
+	}
+	if decoded.ForLLM == "" {
+		decoded.ForLLM = decoded.ForUser
+	}
+	return &ToolResult{
+		ForLLM:    decoded.ForLLM,
+		ForUser:   decoded.ForUser,
+		Silent:    decoded.Silent,
+		IsError:   decoded.IsError,
+		ErrorCode: decoded.ErrorCode,
+	}
+}
+
+// limitedBuffer keeps the first limit bytes written to it and remembers
+// whether anything was dropped.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); len(p) > room {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,88 @@
+//go:build !windows
+
+package integrationtools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	toolshared "github.com/sipeed/picoclaw/pkg/tools/shared"
+)
+
+func newShellExternalTool(t *testing.T, script string, timeoutSeconds int) *ExternalTool {
+	t.Helper()
+	tool, err := NewExternalTool(config.ExternalToolConfig{
+		Name:           "ext_test",
+		Command:        "sh",
+		Args:           []string{"-c", script},
+		Env:            map[string]string{"EXT_GREETING": "hello"},
+		TimeoutSeconds: timeoutSeconds,
+	}, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewExternalTool() error = %v", err)
+	}
+	return tool
+}
+
+func TestExternalTool_PassesArgsAndReadsResult(t *testing.T) {
+	// The script echoes its stdin, greeting and chat back inside for_llm.
+	tool := newShellExternalTool(t,
+		`in=$(cat); printf '{"for_llm":"%s %s %s","for_user":"done"}' "$EXT_GREETING" "$PICOCLAW_TOOL_CHAT_ID" "$(printf %s "$in" | tr -d '"')"`,
+		0)
+
+	ctx := WithToolContext(context.Background(), "telegram", "42")
+	result := tool.Execute(ctx, map[string]any{"city": "Oslo"})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if result.ForLLM != "hello 42 {city:Oslo}" || result.ForUser != "done" {
+		t.Fatalf("result = %+v", result)
+	}
+	if got := tool.Parameters()["type"]; got != "object" {
+		t.Fatalf("default schema type = %v, want object", got)
+	}
+}
+
+func TestExternalTool_Failures(t *testing.T) {
+	tests := []struct {
+		name, script string
+		timeout      int
+		want         string
+		wantCode     toolshared.ErrorCode
+	}{
+		{name: "exit status", script: "echo broken >&2; exit 3", want: "broken"},
+		{name: "not json", script: "echo plain text", want: "did not print a JSON tool result"},
+		{name: "timeout", script: "sleep 5", timeout: 1, want: "timed out", wantCode: toolshared.ErrorCodeTimeout},
+		{
+			name:     "script error",
+			script:   `echo '{"for_llm":"quota exceeded","is_error":true,"error_code":"rate_limited"}'`,
+			want:     "quota exceeded",
+			wantCode: toolshared.ErrorCodeRateLimited,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newShellExternalTool(t, tt.script, tt.timeout).Execute(context.Background(), nil)
+			if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+				t.Fatalf("result = %+v, want error containing %q", result, tt.want)
+			}
+			if tt.wantCode != "" && result.ErrorCode != tt.wantCode {
+				t.Fatalf("ErrorCode = %q, want %q", result.ErrorCode, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestNewExternalTool_Validates(t *testing.T) {
+	for _, cfg := range []config.ExternalToolConfig{
+		{Name: "", Command: "true"},
+		{Name: "has space", Command: "true"},
+		{Name: "ok", Command: " "},
+	} {
+		if _, err := NewExternalTool(cfg, ""); err == nil {
+			t.Fatalf("NewExternalTool(%+v) succeeded, want error", cfg)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package integrationtools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const defaultHTTPRequestMaxBytes = 256 * 1024
+
+var httpRequestMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"}
+
+// HTTPRequestTool performs arbitrary HTTP calls (method, headers, body) so the
+// agent can talk to JSON APIs. It uses the same SSRF-safe client as web_fetch
+// and can additionally be restricted to an allowlist of hosts.
+type HTTPRequestTool struct {
+	client           *http.Client
+	whitelist        *privateHostWhitelist
+	allowedHosts     []string
+	maxResponseBytes int64
+}
+
+func NewHTTPRequestTool(
+	proxy string,
+	allowedHosts []string,
+	privateHostWhitelist []string,
+	maxResponseBytes int64,
+) (*HTTPRequestTool, error) {
+	whitelist, err := newPrivateHostWhitelist(privateHostWhitelist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse http private host whitelist: %w", err)
+	}
+	client, err := newSafeHTTPClient(proxy, whitelist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client for http_request: %w", err)
+	}
+	if maxResponseBytes <= 0 {
+		maxResponseBytes = defaultHTTPRequestMaxBytes
+	}
+
+	t := &HTTPRequestTool{
+		client:           client,
+		whitelist:        whitelist,
+		allowedHosts:     normalizeAllowedHosts(allowedHosts),
+		maxResponseBytes: maxResponseBytes,
+	}
+	checkRedirect := client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !t.hostAllowed(req.URL.Hostname()) {
+			return fmt.Errorf("redirect target %s is not in the allowed hosts", req.URL.Hostname())
+		}
+		return checkRedirect(req, via)
+	}
+	return t, nil
+}
+
+func (t *HTTPRequestTool) Name() string {
+	return "http_request"
+}
+
+func (t *HTTPRequestTool) Description() string {
+	desc := "Send an HTTP request with any method, headers, and body, and return the status, " +
+		"response headers, and body. Use this to call JSON APIs; use web_fetch to read web pages."
+	if len(t.allowedHosts) > 0 {
+		desc += " Allowed hosts: " + strings.Join(t.allowedHosts, ", ") + "."
+	}
+	return desc
+}
+
+func (t *HTTPRequestTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"method": map[string]any{
+				"type":        "string",
+				"enum":        httpRequestMethods,
+				"description": "HTTP method (default GET)",
+			},
+			"url": map[string]any{
+				"type":        "string",
+				"description": "Absolute http or https URL",
+			},
+			"headers": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+				"description":          "Request headers, e.g. {\"Content-Type\": \"application/json\"}",
+			},
+			"body": map[string]any{
+				"type":        "string",
+				"description": "Request body sent as-is",
+			},
+		},
+		"required": []string{"url"},
+	}
+}
+
+func (t *HTTPRequestTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	urlStr, ok := args["url"].(string)
+	if !ok || strings.TrimSpace(urlStr) == "" {
+		return ErrorResult("url is required")
+	}
+
+	method := http.MethodGet
+	if raw, exists := args["method"]; exists {
+		m, ok := raw.(string)
+		if !ok {
+			return ErrorResult("method must be a string")
+		}
+		method = strings.ToUpper(strings.TrimSpace(m))
+		if !slices.Contains(httpRequestMethods, method) {
+			return ErrorResult(fmt.Sprintf("unsupported method %q", m))
+		}
+	}
+
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("invalid URL: %v", err))
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return ErrorResult("only http/https URLs are allowed")
+	}
+	hostname := parsedURL.Hostname()
+	if hostname == "" {
+		return ErrorResult("missing domain in URL")
+	}
+	if !t.hostAllowed(hostname) {
+		return ErrorResult(fmt.Sprintf("host %s is not in the allowed hosts", hostname))
+	}
+	if isObviousPrivateHost(hostname, t.whitelist) {
+		return ErrorResult("requests to private or local network hosts are not allowed")
+	}
+
+	var body io.Reader
+	if raw, exists := args["body"]; exists && raw != nil {
+		s, ok := raw.(string)
+		if !ok {
+			return ErrorResult("body must be a string")
+		}
+		body = strings.NewReader(s)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, body)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to create request: %v", err))
+	}
+	allowConfiguredProxyFirstHop(req, t.client.Transport)
+	req.Header.Set("User-Agent", fmt.Sprintf(userAgentHonest, config.Version))
+	if raw, exists := args["headers"]; exists && raw != nil {
+		headers, ok := raw.(map[string]any)
+		if !ok {
+			return ErrorResult("headers must be an object of strings")
+		}
+		for name, value := range headers {
+			s, ok := value.(string)
+			if !ok {
+				return ErrorResult(fmt.Sprintf("header %q must be a string", name))
+			}
+			req.Header.Set(name, s)
+		}
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		result := ErrorResult(fmt.Sprintf("request failed: %v", err)).WithError(err)
+		// Only requests without side effects may be sent again.
+		if method == http.MethodGet || method == http.MethodHead {
+			result.WithIdempotent()
+		}
+		return result
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, t.maxResponseBytes+1))
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to read response: %v", err))
+	}
+	truncated := int64(len(respBody)) > t.maxResponseBytes
+	if truncated {
+		respBody = respBody[:t.maxResponseBytes]
+	}
+
+	result := map[string]any{
+		"url":       urlStr,
+		"method":    method,
+		"status":    resp.StatusCode,
+		"headers":   flattenHeaders(resp.Header),
+		"body":      string(respBody),
+		"truncated": truncated,
+	}
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to marshal result: %v", err))
+	}
+
+	return &ToolResult{
+		ForLLM: string(resultJSON),
+		ForUser: fmt.Sprintf(
+			"%s %s -> %d (%d bytes, truncated: %v)",
+			method,
+			urlStr,
+			resp.StatusCode,
+			len(respBody),
+			truncated,
+		),
+	}
+}
+
+// hostAllowed reports whether host passes the configured allowlist. An empty
+// allowlist permits every public host.
+func (t *HTTPRequestTool) hostAllowed(host string) bool {
+	return len(t.allowedHosts) == 0 || hostMatchesAny(host, t.allowedHosts)
+}
+
+// hostMatchesAny reports whether host equals one of the normalized patterns,
+// or falls under a "*.example.com" pattern, which also matches example.com.
+func hostMatchesAny(host string, patterns []string) bool {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	for _, pattern := range patterns {
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if host == suffix || strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeAllowedHosts(hosts []string) []string {
+	normalized := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+		if host != "" {
+			normalized = append(normalized, host)
+		}
+	}
+	return normalized
+}
+
+func flattenHeaders(header http.Header) map[string]string {
+	flat := make(map[string]string, len(header))
+	for name, values := range header {
+		flat[name] = strings.Join(values, ", ")
+	}
+	return flat
+}
@@ -0,0 +1,132 @@
+package integrationtools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPRequestTool_SendsMethodHeadersAndBody(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Echo-Method", r.Method)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{
+			"auth": r.Header.Get("Authorization"),
+			"body": string(body),
+		})
+	}))
+	defer server.Close()
+
+	tool, err := NewHTTPRequestTool("", nil, nil, 0)
+	if err != nil {
+		t.Fatalf("NewHTTPRequestTool: %v", err)
+	}
+	result := tool.Execute(context.Background(), map[string]any{
+		"method":  "post",
+		"url":     server.URL,
+		"headers": map[string]any{"Authorization": "Bearer abc"},
+		"body":    `{"name":"pico"}`,
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+
+	var got struct {
+		Method    string            `json:"method"`
+		Status    int               `json:"status"`
+		Headers   map[string]string `json:"headers"`
+		Body      string            `json:"body"`
+		Truncated bool              `json:"truncated"`
+	}
+	if err := json.Unmarshal([]byte(result.ForLLM), &got); err != nil {
+		t.Fatalf("result is not JSON: %v", err)
+	}
+	if got.Method != "POST" || got.Status != http.StatusCreated || got.Headers["X-Echo-Method"] != "POST" {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+	if !strings.Contains(got.Body, `"auth":"Bearer abc"`) || !strings.Contains(got.Body, `{\"name\":\"pico\"}`) {
+		t.Fatalf("body = %q, want echoed header and request body", got.Body)
+	}
+	if got.Truncated {
+		t.Fatal("truncated = true, want false")
+	}
+}
+
+func TestHTTPRequestTool_TruncatesLargeResponses(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	tool, err := NewHTTPRequestTool("", nil, nil, 10)
+	if err != nil {
+		t.Fatalf("NewHTTPRequestTool: %v", err)
+	}
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, `"body": "xxxxxxxxxx"`) || !strings.Contains(result.ForLLM, `"truncated": true`) {
+		t.Fatalf("ForLLM = %s, want 10-byte truncated body", result.ForLLM)
+	}
+}
+
+func TestHTTPRequestTool_RejectsDisallowedTargets(t *testing.T) {
+	tool, err := NewHTTPRequestTool("", []string{"api.example.com", "*.example.org"}, nil, 0)
+	if err != nil {
+		t.Fatalf("NewHTTPRequestTool: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"host not allowed", map[string]any{"url": "https://evil.example.net/"}, "not in the allowed hosts"},
+		{"private host", map[string]any{"url": "http://127.0.0.1/"}, "not in the allowed hosts"},
+		{"bad scheme", map[string]any{"url": "file:///etc/passwd"}, "only http/https"},
+		{"bad method", map[string]any{"url": "https://api.example.com/", "method": "TRACE"}, "unsupported method"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tool.Execute(context.Background(), tt.args)
+			if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+				t.Fatalf("Execute() = %q, want error containing %q", result.ForLLM, tt.want)
+			}
+		})
+	}
+
+	for host, want := range map[string]bool{
+		"api.example.com":     true,
+		"API.example.com.":    true,
+		"www.api.example.com": false,
+		"example.org":         true,
+		"a.b.example.org":     true,
+		"example.com":         false,
+	} {
+		if got := tool.hostAllowed(host); got != want {
+			t.Errorf("hostAllowed(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestHTTPRequestTool_BlocksPrivateHostsWithoutAllowlist(t *testing.T) {
+	tool, err := NewHTTPRequestTool("", nil, nil, 0)
+	if err != nil {
+		t.Fatalf("NewHTTPRequestTool: %v", err)
+	}
+	result := tool.Execute(context.Background(), map[string]any{"url": "http://localhost:8080/admin"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "private or local") {
+		t.Fatalf("Execute() = %q, want private host error", result.ForLLM)
+	}
+}
@@ -0,0 +1,415 @@
+package integrationtools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	imageGenProviderOpenAI          = "openai"
+	imageGenProviderStableDiffusion = "stable_diffusion"
+
+	defaultImageGenOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultImageGenOpenAIModel   = "gpt-image-1"
+	defaultImageGenSDBaseURL     = "http://127.0.0.1:7860"
+
+	imageGenTimeout  = 3 * time.Minute
+	maxImageGenBytes = 20 << 20
+)
+
+var imageGenSizePattern = regexp.MustCompile(`^(\d{2,4})x(\d{2,4})$`)
+
+// ImageGenToolOptions holds the settings for NewImageGenTool.
+type ImageGenToolOptions struct {
+	Provider string
+	APIKey   string
+	Model    string
+	BaseURL  string
+	// Proxy is only used for the OpenAI provider; a Stable Diffusion
+	// endpoint is usually on the local network and is reached directly.
+	Proxy string
+}
+
+func ImageGenToolOptionsFromConfig(cfg *config.Config) ImageGenToolOptions {
+	return ImageGenToolOptions{
+		Provider: cfg.Tools.ImageGen.Provider,
+		APIKey:   cfg.Tools.ImageGen.APIKey.String(),
+		Model:    cfg.Tools.ImageGen.Model,
+		BaseURL:  cfg.Tools.ImageGen.BaseURL,
+		Proxy:    cfg.Tools.Web.Proxy,
+	}
+}
+
+// ImageGenTool generates an image from a text prompt with the OpenAI images
+// API or an AUTOMATIC1111-compatible Stable Diffusion server, and sends the
+// result to the current chat as an attachment.
+type ImageGenTool struct {
+	provider   string
+	apiKey     string
+	model      string
+	baseURL    string
+	client     *http.Client
+	mediaStore media.MediaStore
+}
+
+func NewImageGenTool(opts ImageGenToolOptions) (*ImageGenTool, error) {
+	provider := strings.ToLower(strings.TrimSpace(opts.Provider))
+	if provider == "" {
+		provider = imageGenProviderOpenAI
+	}
+
+	t := &ImageGenTool{
+		provider: provider,
+		apiKey:   strings.TrimSpace(opts.APIKey),
+		model:    strings.TrimSpace(opts.Model),
+		baseURL:  strings.TrimRight(strings.TrimSpace(opts.BaseURL), "/"),
+	}
+
+	proxy := ""
+	switch provider {
+	case imageGenProviderOpenAI:
+		if t.apiKey == "" {
+			return nil, fmt.Errorf("image_gen: api_key is required for the openai provider")
+		}
+		if t.baseURL == "" {
+			t.baseURL = defaultImageGenOpenAIBaseURL
+		}
+		if t.model == "" {
+			t.model = defaultImageGenOpenAIModel
+		}
+		proxy = opts.Proxy
+	case imageGenProviderStableDiffusion:
+		if t.baseURL == "" {
+			t.baseURL = defaultImageGenSDBaseURL
+		}
+	default:
+		return nil, fmt.Errorf("image_gen: unknown provider %q (expected %q or %q)",
+			opts.Provider, imageGenProviderOpenAI, imageGenProviderStableDiffusion)
+	}
+
+	client, err := utils.CreateHTTPClient(proxy, imageGenTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client for image_gen: %w", err)
+	}
+	t.client = client
+	return t, nil
+}
+
+func (t *ImageGenTool) Name() string { return "image_gen" }
+
+func (t *ImageGenTool) Description() string {
+	return "Generate an image from a text description and send it to the user as an attachment. " +
+		"Write a detailed prompt describing the subject, composition, and mood."
+}
+
+func (t *ImageGenTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"prompt": map[string]any{
+				"type":        "string",
+				"description": "Description of the image to generate.",
+			},
+			"size": map[string]any{
+				"type":        "string",
+				"description": "Optional image size as WIDTHxHEIGHT, e.g. 1024x1024 or 1024x1536.",
+			},
+			"style": map[string]any{
+				"type":        "string",
+				"description": "Optional visual style, e.g. vivid, natural, watercolor, pixel art.",
+			},
+		},
+		"required": []string{"prompt"},
+	}
+}
+
+func (t *ImageGenTool) SetMediaStore(store media.MediaStore) {
+	t.mediaStore = store
+}
+
+func (t *ImageGenTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	prompt, _ := args["prompt"].(string)
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return ErrorResult("prompt is required")
+	}
+	size, _ := args["size"].(string)
+	size = strings.ToLower(strings.TrimSpace(size))
+	if size != "" && !imageGenSizePattern.MatchString(size) {
+		return ErrorResult(fmt.Sprintf("invalid size %q: expected WIDTHxHEIGHT, e.g. 1024x1024", size))
+	}
+	style, _ := args["style"].(string)
+	style = strings.TrimSpace(style)
+
+	channel := ToolChannel(ctx)
+	chatID := ToolChatID(ctx)
+	if channel == "" || chatID == "" {
+		return ErrorResult("no target channel/chat available")
+	}
+	if t.mediaStore == nil {
+		return ErrorResult("media store not configured")
+	}
+
+	var (
+		image []byte
+		err   error
+	)
+	if t.provider == imageGenProviderStableDiffusion {
+		image, err = t.generateStableDiffusion(ctx, prompt, size, style)
+	} else {
+		image, err = t.generateOpenAI(ctx, prompt, size, style)
+	}
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("image generation failed: %v", err)).WithError(err)
+	}
+
+	ref, err := t.storeImage(image, channel, chatID)
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	return MediaResult(
+		fmt.Sprintf("Generated an image for %q and sent it to the user", prompt),
+		[]string{ref},
+	).WithResponseHandled()
+}
+
+func (t *ImageGenTool) generateOpenAI(ctx context.Context, prompt, size, style string) ([]byte, error) {
+	body := map[string]any{
+		"model":  t.model,
+		"prompt": prompt,
+		"n":      1,
+	}
+	if size != "" {
+		body["size"] = size
+	}
+	// dall-e models return a URL unless asked otherwise and only dall-e-3
+	// understands "style"; newer models always return base64.
+	if strings.HasPrefix(t.model, "dall-e") {
+		body["response_format"] = "b64_json"
+	}
+	if style != "" {
+		if t.model == "dall-e-3" && (style == "vivid" || style == "natural") {
+			body["style"] = style
+		} else {
+			body["prompt"] = withImageStyle(prompt, style)
+		}
+	}
+
+	var resp struct {
+		Data []struct {
+			B64JSON string `json:"b64_json"`
+			URL     string `json:"url"`
+		} `json:"data"`
+	}
+	if err := t.postJSON(ctx, t.baseURL+"/images/generations", body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("response contained no images")
+	}
+	if resp.Data[0].B64JSON != "" {
+		return decodeImageBase64(resp.Data[0].B64JSON)
+	}
+	if resp.Data[0].URL != "" {
+		return t.download(ctx, resp.Data[0].URL)
+	}
+	return nil, fmt.Errorf("response contained no image data")
+}
+
+func (t *ImageGenTool) generateStableDiffusion(ctx context.Context, prompt, size, style string) ([]byte, error) {
+	body := map[string]any{
+		"prompt":     withImageStyle(prompt, style),
+		"batch_size": 1,
+	}
+	if size != "" {
+		m := imageGenSizePattern.FindStringSubmatch(size)
+		width, _ := strconv.Atoi(m[1])
+		height, _ := strconv.Atoi(m[2])
+		body["width"] = width
+		body["height"] = height
+	}
+	if t.model != "" {
+		body["override_settings"] = map[string]any{"sd_model_checkpoint": t.model}
+	}
+
+	var resp struct {
+		Images []string `json:"images"`
+	}
+	if err := t.postJSON(ctx, t.baseURL+"/sdapi/v1/txt2img", body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Images) == 0 {
+		return nil, fmt.Errorf("response contained no images")
+	}
+	return decodeImageBase64(resp.Images[0])
+}
+
+func (t *ImageGenTool) postJSON(ctx context.Context, endpoint string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Base64 inflates the payload by a third, so allow some headroom.
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageGenBytes*2))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, imageGenErrorMessage(data))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func (t *ImageGenTool) download(ctx context.Context, imageURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image download returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageGenBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	if len(data) > maxImageGenBytes {
+		return nil, fmt.Errorf("image exceeds %d bytes", maxImageGenBytes)
+	}
+	return data, nil
+}
+
+func (t *ImageGenTool) storeImage(image []byte, channel, chatID string) (string, error) {
+	contentType := http.DetectContentType(image)
+	ext := imageGenExtension(contentType)
+	if ext == "" {
+		return "", fmt.Errorf("provider returned unsupported image data (%s)", contentType)
+	}
+
+	if err := os.MkdirAll(media.TempDir(), 0o700); err != nil {
+		return "", fmt.Errorf("failed to create media temp dir: %w", err)
+	}
+	file, err := os.CreateTemp(media.TempDir(), "image-gen-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	removeTemp := true
+	defer func() {
+		if removeTemp {
+			_ = os.Remove(file.Name())
+		}
+	}()
+	if _, err := file.Write(image); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write image: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to close image file: %w", err)
+	}
+
+	scope := fmt.Sprintf("tool:image_gen:%s:%s:%d", channel, chatID, time.Now().UnixNano())
+	ref, err := t.mediaStore.Store(file.Name(), media.MediaMeta{
+		Filename:    fmt.Sprintf("image-%d%s", time.Now().Unix(), ext),
+		ContentType: contentType,
+		Source:      "tool:image_gen",
+	}, scope)
+	if err != nil {
+		return "", fmt.Errorf("failed to register media: %w", err)
+	}
+	removeTemp = false
+	return ref, nil
+}
+
+func withImageStyle(prompt, style string) string {
+	if style == "" {
+		return prompt
+	}
+	return prompt + ", in " + style + " style"
+}
+
+func decodeImageBase64(data string) ([]byte, error) {
+	// Stable Diffusion servers may prefix the payload with a data URL header.
+	if i := strings.Index(data, ";base64,"); i >= 0 {
+		data = data[i+len(";base64,"):]
+	}
+	if base64.StdEncoding.DecodedLen(len(data)) > maxImageGenBytes {
+		return nil, fmt.Errorf("image exceeds %d bytes", maxImageGenBytes)
+	}
+	image, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 image data: %w", err)
+	}
+	return image, nil
+}
+
+func imageGenExtension(contentType string) string {
+	switch contentType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	case "image/gif":
+		return ".gif"
+	}
+	return ""
+}
+
+func imageGenErrorMessage(body []byte) string {
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		Detail string `json:"detail"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		if payload.Error.Message != "" {
+			return payload.Error.Message
+		}
+		if payload.Detail != "" {
+			return payload.Detail
+		}
+	}
+	msg := strings.TrimSpace(string(body))
+	if len(msg) > 200 {
+		msg = msg[:200] + "..."
+	}
+	return msg
+}
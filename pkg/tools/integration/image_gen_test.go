@@ -0,0 +1,196 @@
+package integrationtools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/media"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func executeImageGen(t *testing.T, tool *ImageGenTool, args map[string]any) (*ToolResult, media.MediaStore) {
+	t.Helper()
+	store := media.NewFileMediaStore()
+	tool.SetMediaStore(store)
+	return tool.Execute(WithToolContext(context.Background(), "telegram", "chat-1"), args), store
+}
+
+func TestImageGenTool_OpenAI(t *testing.T) {
+	pngData := testPNG(t)
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/images/generations" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"b64_json": base64.StdEncoding.EncodeToString(pngData)}},
+		})
+	}))
+	defer server.Close()
+
+	tool, err := NewImageGenTool(ImageGenToolOptions{APIKey: "sk-test", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("NewImageGenTool() error = %v", err)
+	}
+	result, store := executeImageGen(t, tool, map[string]any{
+		"prompt": "a lighthouse at dusk",
+		"size":   "1024x1536",
+		"style":  "watercolor",
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if !result.ResponseHandled || len(result.Media) != 1 {
+		t.Fatalf("result = %+v, want one handled media ref", result)
+	}
+
+	if got["model"] != defaultImageGenOpenAIModel || got["size"] != "1024x1536" {
+		t.Fatalf("request = %v, want default model and requested size", got)
+	}
+	if got["prompt"] != "a lighthouse at dusk, in watercolor style" {
+		t.Fatalf("prompt = %q, want style folded into prompt", got["prompt"])
+	}
+	if _, ok := got["response_format"]; ok {
+		t.Fatal("response_format must not be sent to gpt-image models")
+	}
+
+	path, meta, err := store.ResolveWithMeta(result.Media[0])
+	if err != nil {
+		t.Fatalf("ResolveWithMeta() error = %v", err)
+	}
+	if meta.ContentType != "image/png" || !strings.HasSuffix(meta.Filename, ".png") {
+		t.Fatalf("meta = %+v, want png", meta)
+	}
+	stored, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stored, pngData) {
+		t.Fatal("stored image does not match the generated bytes")
+	}
+}
+
+func TestImageGenTool_OpenAIDallE3Style(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"b64_json": base64.StdEncoding.EncodeToString(testPNG(t))}},
+		})
+	}))
+	defer server.Close()
+
+	tool, err := NewImageGenTool(ImageGenToolOptions{APIKey: "k", Model: "dall-e-3", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, _ := executeImageGen(t, tool, map[string]any{"prompt": "a cat", "style": "natural"})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if got["style"] != "natural" || got["prompt"] != "a cat" || got["response_format"] != "b64_json" {
+		t.Fatalf("request = %v, want native dall-e-3 style and b64 response", got)
+	}
+}
+
+func TestImageGenTool_StableDiffusion(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sdapi/v1/txt2img" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("unexpected Authorization header %q", auth)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"images": []string{"data:image/png;base64," + base64.StdEncoding.EncodeToString(testPNG(t))},
+		})
+	}))
+	defer server.Close()
+
+	tool, err := NewImageGenTool(ImageGenToolOptions{
+		Provider: "stable_diffusion",
+		Model:    "sdxl_base",
+		BaseURL:  server.URL + "/",
+	})
+	if err != nil {
+		t.Fatalf("NewImageGenTool() error = %v", err)
+	}
+	result, _ := executeImageGen(t, tool, map[string]any{"prompt": "a fox", "size": "768x512"})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if got["width"] != float64(768) || got["height"] != float64(512) {
+		t.Fatalf("request = %v, want width 768 and height 512", got)
+	}
+	overrides, _ := got["override_settings"].(map[string]any)
+	if overrides["sd_model_checkpoint"] != "sdxl_base" {
+		t.Fatalf("override_settings = %v, want checkpoint", got["override_settings"])
+	}
+}
+
+func TestImageGenTool_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"prompt rejected by safety system"}}`))
+	}))
+	defer server.Close()
+
+	tool, err := NewImageGenTool(ImageGenToolOptions{APIKey: "k", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, _ := executeImageGen(t, tool, map[string]any{"prompt": "x"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "prompt rejected by safety system") {
+		t.Fatalf("result = %q, want API error message", result.ForLLM)
+	}
+
+	result, _ = executeImageGen(t, tool, map[string]any{"prompt": "x", "size": "huge"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "invalid size") {
+		t.Fatalf("result = %q, want size validation error", result.ForLLM)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"prompt": "x"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "no target channel") {
+		t.Fatalf("result = %q, want missing chat error", result.ForLLM)
+	}
+}
+
+func TestNewImageGenTool_Validation(t *testing.T) {
+	if _, err := NewImageGenTool(ImageGenToolOptions{}); err == nil {
+		t.Fatal("openai provider without api key should fail")
+	}
+	if _, err := NewImageGenTool(ImageGenToolOptions{Provider: "midjourney"}); err == nil {
+		t.Fatal("unknown provider should fail")
+	}
+	if _, err := NewImageGenTool(ImageGenToolOptions{Provider: "stable_diffusion"}); err != nil {
+		t.Fatalf("stable diffusion without api key should be allowed: %v", err)
+	}
+}
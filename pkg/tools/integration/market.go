@@ -0,0 +1,728 @@
+package integrationtools
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	toolshared "github.com/sipeed/picoclaw/pkg/tools/shared"
+)
+
+const (
+	marketStockProviderStooq        = "stooq"
+	marketStockProviderAlphaVantage = "alpha_vantage"
+
+	defaultStooqURL        = "https://stooq.com/q/d/l/"
+	defaultAlphaVantageURL = "https://www.alphavantage.co/query"
+	defaultCoinGeckoURL    = "https://api.coingecko.com/api/v3"
+
+	defaultMarketCurrency     = "usd"
+	defaultMarketCacheTTL     = time.Minute
+	defaultMarketHistoryDays  = 30
+	maxMarketHistoryDays      = 365
+	maxMarketResponseBytes    = 2 << 20
+	marketCoinIDCacheTTL      = 24 * time.Hour
+	stooqQuoteLookbackDays    = 10
+	alphaVantageCompactPoints = 100
+)
+
+// errUnknownSymbol marks lookups where the provider answered but has no data
+// for the symbol, as opposed to transport or rate-limit failures.
+var errUnknownSymbol = errors.New("unknown symbol")
+
+// MarketToolOptions holds the settings for NewMarketTool.
+type MarketToolOptions struct {
+	// StockProvider is "stooq" (default, no key) or "alpha_vantage".
+	StockProvider string
+	StockAPIKey   string
+	// CryptoAPIKey is an optional CoinGecko demo key.
+	CryptoAPIKey string
+	Currency     string
+	CacheTTL     time.Duration
+
+	// Endpoint overrides, mainly for tests.
+	StooqURL        string
+	AlphaVantageURL string
+	CoinGeckoURL    string
+
+	Proxy                string
+	PrivateHostWhitelist []string
+}
+
+func MarketToolOptionsFromConfig(cfg *config.Config) MarketToolOptions {
+	return MarketToolOptions{
+		StockProvider:        cfg.Tools.Market.StockProvider,
+		StockAPIKey:          cfg.Tools.Market.StockAPIKey.String(),
+		CryptoAPIKey:         cfg.Tools.Market.CryptoAPIKey.String(),
+		Currency:             cfg.Tools.Market.Currency,
+		CacheTTL:             time.Duration(cfg.Tools.Market.CacheSeconds) * time.Second,
+		Proxy:                cfg.Tools.Web.Proxy,
+		PrivateHostWhitelist: cfg.Tools.Web.PrivateHostWhitelist,
+	}
+}
+
+// MarketTool fetches stock and cryptocurrency prices. Stocks come from Stooq
+// or Alpha Vantage, crypto from CoinGecko. Successful lookups are cached for
+// a short time so repeated questions do not burn through free-tier limits.
+type MarketTool struct {
+	stockProvider   string
+	stockAPIKey     string
+	cryptoAPIKey    string
+	currency        string
+	cacheTTL        time.Duration
+	stooqURL        string
+	alphaVantageURL string
+	coinGeckoURL    string
+	client          *http.Client
+	now             func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]marketCacheEntry
+}
+
+type marketCacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+func NewMarketTool(opts MarketToolOptions) (*MarketTool, error) {
+	provider := strings.ToLower(strings.TrimSpace(opts.StockProvider))
+	if provider == "" {
+		provider = marketStockProviderStooq
+	}
+	switch provider {
+	case marketStockProviderStooq:
+	case marketStockProviderAlphaVantage:
+		if strings.TrimSpace(opts.StockAPIKey) == "" {
+			return nil, fmt.Errorf("market: stock_api_key is required for the alpha_vantage provider")
+		}
+	default:
+		return nil, fmt.Errorf("market: unknown stock provider %q (expected %q or %q)",
+			opts.StockProvider, marketStockProviderStooq, marketStockProviderAlphaVantage)
+	}
+
+	t := &MarketTool{
+		stockProvider:   provider,
+		stockAPIKey:     strings.TrimSpace(opts.StockAPIKey),
+		cryptoAPIKey:    strings.TrimSpace(opts.CryptoAPIKey),
+		currency:        strings.ToLower(strings.TrimSpace(opts.Currency)),
+		cacheTTL:        opts.CacheTTL,
+		stooqURL:        strings.TrimSpace(opts.StooqURL),
+		alphaVantageURL: strings.TrimSpace(opts.AlphaVantageURL),
+		coinGeckoURL:    strings.TrimRight(strings.TrimSpace(opts.CoinGeckoURL), "/"),
+		now:             time.Now,
+		cache:           make(map[string]marketCacheEntry),
+	}
+	if t.currency == "" {
+		t.currency = defaultMarketCurrency
+	}
+	if t.cacheTTL <= 0 {
+		t.cacheTTL = defaultMarketCacheTTL
+	}
+	if t.stooqURL == "" {
+		t.stooqURL = defaultStooqURL
+	}
+	if t.alphaVantageURL == "" {
+		t.alphaVantageURL = defaultAlphaVantageURL
+	}
+	if t.coinGeckoURL == "" {
+		t.coinGeckoURL = defaultCoinGeckoURL
+	}
+
+	whitelist, err := newPrivateHostWhitelist(opts.PrivateHostWhitelist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse market private host whitelist: %w", err)
+	}
+	client, err := newSafeHTTPClient(opts.Proxy, whitelist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client for market: %w", err)
+	}
+	t.client = client
+	return t, nil
+}
+
+func (t *MarketTool) Name() string { return "market" }
+
+func (t *MarketTool) Description() string {
+	return "Get real stock or cryptocurrency prices. quote returns the latest price and change; " +
+		"history returns daily closing prices. Results are JSON. Never guess prices: if the tool " +
+		"reports an unknown symbol, tell the user."
+}
+
+func (t *MarketTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"quote", "history"},
+				"description": "quote for the latest price, history for daily closes.",
+			},
+			"symbol": map[string]any{
+				"type": "string",
+				"description": "Ticker such as AAPL or BTC. Non-US stocks on Stooq take a market " +
+					"suffix (e.g. SAP.DE, 7203.JP). Crypto also accepts CoinGecko ids like bitcoin.",
+			},
+			"asset": map[string]any{
+				"type":        "string",
+				"enum":        []string{"stock", "crypto"},
+				"description": "Asset class of the symbol (default stock).",
+			},
+			"days": map[string]any{
+				"type": "integer",
+				"description": fmt.Sprintf(
+					"History length in days (1-%d, default %d).",
+					maxMarketHistoryDays, defaultMarketHistoryDays,
+				),
+			},
+			"currency": map[string]any{
+				"type":        "string",
+				"description": "Quote currency for crypto, e.g. usd or eur. Defaults to the configured currency.",
+			},
+		},
+		"required": []string{"action", "symbol"},
+	}
+}
+
+// marketQuote is the quote action's result.
+type marketQuote struct {
+	Symbol        string   `json:"symbol"`
+	Name          string   `json:"name,omitempty"`
+	Asset         string   `json:"asset"`
+	Price         float64  `json:"price"`
+	Change        *float64 `json:"change,omitempty"`
+	ChangePercent *float64 `json:"change_percent,omitempty"`
+	Currency      string   `json:"currency,omitempty"`
+	Timestamp     string   `json:"timestamp"`
+	Source        string   `json:"source"`
+}
+
+// marketHistory is the history action's result.
+type marketHistory struct {
+	Symbol   string        `json:"symbol"`
+	Name     string        `json:"name,omitempty"`
+	Asset    string        `json:"asset"`
+	Currency string        `json:"currency,omitempty"`
+	Points   []marketPoint `json:"points"`
+	Source   string        `json:"source"`
+}
+
+type marketPoint struct {
+	Date  string  `json:"date"`
+	Close float64 `json:"close"`
+}
+
+func (t *MarketTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	action = strings.ToLower(strings.TrimSpace(action))
+	if action != "quote" && action != "history" {
+		return ErrorResult(fmt.Sprintf("unknown action %q (expected quote or history)", action))
+	}
+	symbol, _ := args["symbol"].(string)
+	symbol = strings.TrimSpace(symbol)
+	if symbol == "" {
+		return ErrorResult("symbol is required")
+	}
+	asset, _ := args["asset"].(string)
+	asset = strings.ToLower(strings.TrimSpace(asset))
+	if asset == "" {
+		asset = "stock"
+	}
+	if asset != "stock" && asset != "crypto" {
+		return ErrorResult(fmt.Sprintf("unknown asset %q (expected stock or crypto)", asset))
+	}
+	currency, _ := args["currency"].(string)
+	currency = strings.ToLower(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = t.currency
+	}
+
+	days := defaultMarketHistoryDays
+	if raw, ok := args["days"]; ok && action == "history" {
+		n, ok := numberArg(raw)
+		if !ok || n < 1 || n > maxMarketHistoryDays || n != math.Trunc(n) {
+			return ErrorResult(fmt.Sprintf("days must be a whole number from 1 to %d", maxMarketHistoryDays))
+		}
+		days = int(n)
+	}
+
+	key := strings.Join([]string{action, asset, strings.ToUpper(symbol), currency, strconv.Itoa(days)}, "|")
+	result, err := t.cached(key, t.cacheTTL, func() (any, error) {
+		switch {
+		case asset == "crypto" && action == "quote":
+			return t.cryptoQuote(ctx, symbol, currency)
+		case asset == "crypto":
+			return t.cryptoHistory(ctx, symbol, currency, days)
+		case action == "quote":
+			return t.stockQuote(ctx, symbol)
+		default:
+			return t.stockHistory(ctx, symbol, days)
+		}
+	})
+	if err != nil {
+		if errors.Is(err, errUnknownSymbol) {
+			return ErrorResult(fmt.Sprintf(
+				"no %s data found for %q: %v. Check the ticker (and asset type) rather than estimating a price.",
+				asset, symbol, err,
+			)).WithError(err).WithErrorCode(toolshared.ErrorCodeNotFound)
+		}
+		return ErrorResult(fmt.Sprintf("market lookup failed: %v", err)).WithError(err).WithIdempotent()
+	}
+
+	encoded, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to encode market data: %v", err))
+	}
+	return NewToolResult(string(encoded))
+}
+
+// cached returns the value stored under key, calling fetch on a miss.
+// Errors are not cached so a transient failure can be retried at once.
+func (t *MarketTool) cached(key string, ttl time.Duration, fetch func() (any, error)) (any, error) {
+	now := t.now()
+	t.mu.Lock()
+	if entry, ok := t.cache[key]; ok && now.Before(entry.expires) {
+		t.mu.Unlock()
+		return entry.value, nil
+	}
+	t.mu.Unlock()
+
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	for k, entry := range t.cache {
+		if !now.Before(entry.expires) {
+			delete(t.cache, k)
+		}
+	}
+	t.cache[key] = marketCacheEntry{value: value, expires: now.Add(ttl)}
+	t.mu.Unlock()
+	return value, nil
+}
+
+func (t *MarketTool) stockQuote(ctx context.Context, symbol string) (*marketQuote, error) {
+	if t.stockProvider == marketStockProviderAlphaVantage {
+		return t.alphaVantageQuote(ctx, symbol)
+	}
+	points, stooqSymbol, err := t.stooqDaily(ctx, symbol, stooqQuoteLookbackDays)
+	if err != nil {
+		return nil, err
+	}
+	last := points[len(points)-1]
+	quote := &marketQuote{
+		Symbol:    strings.ToUpper(stooqSymbol),
+		Asset:     "stock",
+		Price:     last.Close,
+		Currency:  stooqCurrency(stooqSymbol),
+		Timestamp: last.Date,
+		Source:    "stooq",
+	}
+	if len(points) > 1 {
+		setMarketChange(quote, points[len(points)-2].Close)
+	}
+	return quote, nil
+}
+
+func (t *MarketTool) stockHistory(ctx context.Context, symbol string, days int) (*marketHistory, error) {
+	if t.stockProvider == marketStockProviderAlphaVantage {
+		return t.alphaVantageHistory(ctx, symbol, days)
+	}
+	points, stooqSymbol, err := t.stooqDaily(ctx, symbol, days)
+	if err != nil {
+		return nil, err
+	}
+	return &marketHistory{
+		Symbol:   strings.ToUpper(stooqSymbol),
+		Asset:    "stock",
+		Currency: stooqCurrency(stooqSymbol),
+		Points:   points,
+		Source:   "stooq",
+	}, nil
+}
+
+// stooqDaily returns daily closes for the last days calendar days, oldest
+// first. Symbols without a market suffix are treated as US listings.
+func (t *MarketTool) stooqDaily(ctx context.Context, symbol string, days int) ([]marketPoint, string, error) {
+	stooqSymbol := strings.ToLower(symbol)
+	if !strings.Contains(stooqSymbol, ".") {
+		stooqSymbol += ".us"
+	}
+	now := t.now().UTC()
+	params := url.Values{}
+	params.Set("s", stooqSymbol)
+	params.Set("i", "d")
+	params.Set("d1", now.AddDate(0, 0, -days).Format("20060102"))
+	params.Set("d2", now.Format("20060102"))
+
+	body, err := t.get(ctx, t.stooqURL, params, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	text := strings.TrimSpace(string(body))
+	if text == "" || strings.EqualFold(text, "No data") {
+		return nil, "", errUnknownSymbol
+	}
+
+	records, err := csv.NewReader(strings.NewReader(text)).ReadAll()
+	if err != nil || len(records) == 0 || len(records[0]) < 5 || !strings.EqualFold(records[0][0], "Date") {
+		return nil, "", fmt.Errorf("unexpected response from stooq")
+	}
+	closeCol := -1
+	for i, name := range records[0] {
+		if strings.EqualFold(name, "Close") {
+			closeCol = i
+		}
+	}
+	if closeCol < 0 {
+		return nil, "", fmt.Errorf("unexpected response from stooq")
+	}
+	var points []marketPoint
+	for _, rec := range records[1:] {
+		if len(rec) <= closeCol {
+			continue
+		}
+		price, err := strconv.ParseFloat(rec[closeCol], 64)
+		if err != nil {
+			continue
+		}
+		points = append(points, marketPoint{Date: rec[0], Close: price})
+	}
+	if len(points) == 0 {
+		return nil, "", errUnknownSymbol
+	}
+	return points, stooqSymbol, nil
+}
+
+// stooqCurrency returns the listing currency for the markets where it is
+// unambiguous, and "" otherwise.
+func stooqCurrency(stooqSymbol string) string {
+	switch {
+	case strings.HasSuffix(stooqSymbol, ".us"):
+		return "USD"
+	case strings.HasSuffix(stooqSymbol, ".uk"):
+		return "GBX"
+	case strings.HasSuffix(stooqSymbol, ".de"):
+		return "EUR"
+	case strings.HasSuffix(stooqSymbol, ".jp"):
+		return "JPY"
+	case strings.HasSuffix(stooqSymbol, ".hk"):
+		return "HKD"
+	default:
+		return ""
+	}
+}
+
+func (t *MarketTool) alphaVantageQuote(ctx context.Context, symbol string) (*marketQuote, error) {
+	params := url.Values{}
+	params.Set("function", "GLOBAL_QUOTE")
+	params.Set("symbol", strings.ToUpper(symbol))
+	params.Set("apikey", t.stockAPIKey)
+
+	var resp struct {
+		Quote map[string]string `json:"Global Quote"`
+	}
+	if err := t.alphaVantageGet(ctx, params, &resp); err != nil {
+		return nil, err
+	}
+	price, err := strconv.ParseFloat(resp.Quote["05. price"], 64)
+	if err != nil {
+		return nil, errUnknownSymbol
+	}
+	quote := &marketQuote{
+		Symbol:    resp.Quote["01. symbol"],
+		Asset:     "stock",
+		Price:     price,
+		Timestamp: resp.Quote["07. latest trading day"],
+		Source:    "alpha_vantage",
+	}
+	if prev, err := strconv.ParseFloat(resp.Quote["08. previous close"], 64); err == nil {
+		setMarketChange(quote, prev)
+	}
+	return quote, nil
+}
+
+func (t *MarketTool) alphaVantageHistory(ctx context.Context, symbol string, days int) (*marketHistory, error) {
+	params := url.Values{}
+	params.Set("function", "TIME_SERIES_DAILY")
+	params.Set("symbol", strings.ToUpper(symbol))
+	params.Set("apikey", t.stockAPIKey)
+	if days > alphaVantageCompactPoints {
+		params.Set("outputsize", "full")
+	}
+
+	var resp struct {
+		Series map[string]map[string]string `json:"Time Series (Daily)"`
+	}
+	if err := t.alphaVantageGet(ctx, params, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Series) == 0 {
+		return nil, errUnknownSymbol
+	}
+
+	cutoff := t.now().UTC().AddDate(0, 0, -days).Format("2006-01-02")
+	points := make([]marketPoint, 0, len(resp.Series))
+	for date, bar := range resp.Series {
+		if date < cutoff {
+			continue
+		}
+		if price, err := strconv.ParseFloat(bar["4. close"], 64); err == nil {
+			points = append(points, marketPoint{Date: date, Close: price})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Date < points[j].Date })
+	return &marketHistory{
+		Symbol: strings.ToUpper(symbol),
+		Asset:  "stock",
+		Points: points,
+		Source: "alpha_vantage",
+	}, nil
+}
+
+// alphaVantageGet decodes an Alpha Vantage response. The API reports errors
+// and rate limits with HTTP 200 and a message field instead of data.
+func (t *MarketTool) alphaVantageGet(ctx context.Context, params url.Values, dst any) error {
+	body, err := t.get(ctx, t.alphaVantageURL, params, nil)
+	if err != nil {
+		return err
+	}
+	var status struct {
+		ErrorMessage string `json:"Error Message"`
+		Note         string `json:"Note"`
+		Information  string `json:"Information"`
+	}
+	if json.Unmarshal(body, &status) == nil {
+		switch {
+		case status.ErrorMessage != "":
+			return fmt.Errorf("%w: %s", errUnknownSymbol, status.ErrorMessage)
+		case status.Note != "":
+			return fmt.Errorf("alpha vantage: %s", status.Note)
+		case status.Information != "":
+			return fmt.Errorf("alpha vantage: %s", status.Information)
+		}
+	}
+	if err := json.Unmarshal(body, dst); err != nil {
+		return fmt.Errorf("invalid response from alpha vantage: %w", err)
+	}
+	return nil
+}
+
+type coinGeckoCoin struct {
+	ID     string
+	Symbol string
+	Name   string
+}
+
+// resolveCoin maps a ticker or CoinGecko id to a coin. When several coins
+// share a ticker, the one with the best market-cap rank wins.
+func (t *MarketTool) resolveCoin(ctx context.Context, symbol string) (*coinGeckoCoin, error) {
+	value, err := t.cached("coin|"+strings.ToLower(symbol), marketCoinIDCacheTTL, func() (any, error) {
+		params := url.Values{}
+		params.Set("query", symbol)
+		var resp struct {
+			Coins []struct {
+				ID            string `json:"id"`
+				Symbol        string `json:"symbol"`
+				Name          string `json:"name"`
+				MarketCapRank int    `json:"market_cap_rank"`
+			} `json:"coins"`
+		}
+		body, err := t.get(ctx, t.coinGeckoURL+"/search", params, t.coinGeckoHeaders())
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("invalid response from coingecko: %w", err)
+		}
+
+		var best *coinGeckoCoin
+		bestRank := 0
+		for _, c := range resp.Coins {
+			if strings.EqualFold(c.ID, symbol) {
+				return &coinGeckoCoin{ID: c.ID, Symbol: c.Symbol, Name: c.Name}, nil
+			}
+			if !strings.EqualFold(c.Symbol, symbol) {
+				continue
+			}
+			rank := c.MarketCapRank
+			if rank <= 0 {
+				rank = math.MaxInt32
+			}
+			if best == nil || rank < bestRank {
+				best = &coinGeckoCoin{ID: c.ID, Symbol: c.Symbol, Name: c.Name}
+				bestRank = rank
+			}
+		}
+		if best == nil {
+			return nil, errUnknownSymbol
+		}
+		return best, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*coinGeckoCoin), nil
+}
+
+func (t *MarketTool) cryptoQuote(ctx context.Context, symbol, currency string) (*marketQuote, error) {
+	coin, err := t.resolveCoin(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("ids", coin.ID)
+	params.Set("vs_currencies", currency)
+	params.Set("include_24hr_change", "true")
+	params.Set("include_last_updated_at", "true")
+
+	body, err := t.get(ctx, t.coinGeckoURL+"/simple/price", params, t.coinGeckoHeaders())
+	if err != nil {
+		return nil, err
+	}
+	var resp map[string]map[string]float64
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response from coingecko: %w", err)
+	}
+	data := resp[coin.ID]
+	price, ok := data[currency]
+	if !ok {
+		return nil, fmt.Errorf("%w: no %s price for %s", errUnknownSymbol, strings.ToUpper(currency), coin.Name)
+	}
+
+	quote := &marketQuote{
+		Symbol:    strings.ToUpper(coin.Symbol),
+		Name:      coin.Name,
+		Asset:     "crypto",
+		Price:     price,
+		Currency:  strings.ToUpper(currency),
+		Timestamp: t.now().UTC().Format(time.RFC3339),
+		Source:    "coingecko",
+	}
+	if updated, ok := data["last_updated_at"]; ok && updated > 0 {
+		quote.Timestamp = time.Unix(int64(updated), 0).UTC().Format(time.RFC3339)
+	}
+	if pct, ok := data[currency+"_24h_change"]; ok && pct > -100 {
+		setMarketChange(quote, price/(1+pct/100))
+	}
+	return quote, nil
+}
+
+func (t *MarketTool) cryptoHistory(ctx context.Context, symbol, currency string, days int) (*marketHistory, error) {
+	coin, err := t.resolveCoin(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("vs_currency", currency)
+	params.Set("days", strconv.Itoa(days))
+	params.Set("interval", "daily")
+
+	endpoint := t.coinGeckoURL + "/coins/" + url.PathEscape(coin.ID) + "/market_chart"
+	body, err := t.get(ctx, endpoint, params, t.coinGeckoHeaders())
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Prices [][2]float64 `json:"prices"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response from coingecko: %w", err)
+	}
+
+	// The last sample is the current price, which can share a date with the
+	// previous daily close; keep the latest value per day.
+	points := make([]marketPoint, 0, len(resp.Prices))
+	for _, p := range resp.Prices {
+		date := time.UnixMilli(int64(p[0])).UTC().Format("2006-01-02")
+		if n := len(points); n > 0 && points[n-1].Date == date {
+			points[n-1].Close = p[1]
+			continue
+		}
+		points = append(points, marketPoint{Date: date, Close: p[1]})
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("%w: no %s history for %s", errUnknownSymbol, strings.ToUpper(currency), coin.Name)
+	}
+	return &marketHistory{
+		Symbol:   strings.ToUpper(coin.Symbol),
+		Name:     coin.Name,
+		Asset:    "crypto",
+		Currency: strings.ToUpper(currency),
+		Points:   points,
+		Source:   "coingecko",
+	}, nil
+}
+
+func (t *MarketTool) coinGeckoHeaders() map[string]string {
+	if t.cryptoAPIKey == "" {
+		return nil
+	}
+	return map[string]string{"x-cg-demo-api-key": t.cryptoAPIKey}
+}
+
+func (t *MarketTool) get(
+	ctx context.Context,
+	endpoint string,
+	params url.Values,
+	headers map[string]string,
+) ([]byte, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+	u.RawQuery = params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxMarketResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, fmt.Errorf("%s rate limit reached; try again in a minute: %w", u.Host, errRateLimited)
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errUnknownSymbol
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s returned HTTP %d", u.Host, resp.StatusCode)
+	}
+	return body, nil
+}
+
+func setMarketChange(q *marketQuote, previous float64) {
+	if previous == 0 {
+		return
+	}
+	change := roundMarket(q.Price - previous)
+	pct := roundMarket((q.Price - previous) / previous * 100)
+	q.Change = &change
+	q.ChangePercent = &pct
+}
+
+func roundMarket(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
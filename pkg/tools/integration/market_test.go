@@ -0,0 +1,222 @@
+package integrationtools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var marketTestNow = time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC)
+
+// newMarketTestServer serves canned Stooq, Alpha Vantage and CoinGecko
+// responses and counts the requests it receives.
+func newMarketTestServer(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/stooq":
+			if q.Get("s") != "aapl.us" {
+				_, _ = w.Write([]byte("No data"))
+				return
+			}
+			_, _ = w.Write([]byte("Date,Open,High,Low,Close,Volume\n" +
+				"2026-03-02,180,182,179,181.5,1000\n" +
+				"2026-03-03,181.5,184,181,183,1200\n" +
+				"2026-03-04,183,186,182,185.2,1500\n"))
+		case "/alpha":
+			switch {
+			case q.Get("apikey") != "av-key":
+				_, _ = w.Write([]byte(`{"Information":"invalid api key"}`))
+			case q.Get("symbol") != "MSFT":
+				_, _ = w.Write([]byte(`{"Error Message":"Invalid API call."}`))
+			case q.Get("function") == "GLOBAL_QUOTE":
+				_, _ = w.Write([]byte(`{"Global Quote":{"01. symbol":"MSFT","05. price":"410.0000",` +
+					`"07. latest trading day":"2026-03-04","08. previous close":"400.0000"}}`))
+			default:
+				_, _ = w.Write([]byte(`{"Time Series (Daily)":{` +
+					`"2026-03-04":{"4. close":"410.0"},"2026-03-03":{"4. close":"400.0"},` +
+					`"2025-01-02":{"4. close":"300.0"}}}`))
+			}
+		case "/cg/search":
+			if r.Header.Get("x-cg-demo-api-key") != "cg-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"coins":[
+				{"id":"bitcoin-wannabe","symbol":"BTC","name":"Bitcoin Wannabe","market_cap_rank":null},
+				{"id":"bitcoin","symbol":"BTC","name":"Bitcoin","market_cap_rank":1},
+				{"id":"wrapped-bitcoin","symbol":"WBTC","name":"Wrapped Bitcoin","market_cap_rank":15}
+			]}`))
+		case "/cg/simple/price":
+			_, _ = w.Write([]byte(`{"bitcoin":{"eur":60000,"eur_24h_change":20,"last_updated_at":1772640000}}`))
+		case "/cg/coins/bitcoin/market_chart":
+			_, _ = w.Write([]byte(`{"prices":[[1772496000000,58000],[1772582400000,59000],` +
+				`[1772625600000,59500],[1772640000000,60000]]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func newTestMarketTool(t *testing.T, serverURL string, opts MarketToolOptions) *MarketTool {
+	t.Helper()
+	opts.StooqURL = serverURL + "/stooq"
+	opts.AlphaVantageURL = serverURL + "/alpha"
+	opts.CoinGeckoURL = serverURL + "/cg"
+	opts.PrivateHostWhitelist = []string{"127.0.0.0/8"}
+	tool, err := NewMarketTool(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool.now = func() time.Time { return marketTestNow }
+	return tool
+}
+
+func decodeMarketResult(t *testing.T, result *ToolResult) map[string]any {
+	t.Helper()
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	var out map[string]any
+	if err := json.Unmarshal([]byte(result.ForLLM), &out); err != nil {
+		t.Fatalf("result is not JSON: %v\n%s", err, result.ForLLM)
+	}
+	return out
+}
+
+func TestMarketTool_StooqQuoteAndHistory(t *testing.T) {
+	var hits atomic.Int32
+	server := newMarketTestServer(t, &hits)
+	defer server.Close()
+	tool := newTestMarketTool(t, server.URL, MarketToolOptions{})
+
+	out := decodeMarketResult(t, tool.Execute(context.Background(), map[string]any{"action": "quote", "symbol": "aapl"}))
+	if out["symbol"] != "AAPL.US" || out["price"] != 185.2 || out["change"] != 2.2 ||
+		out["currency"] != "USD" || out["timestamp"] != "2026-03-04" || out["source"] != "stooq" {
+		t.Fatalf("quote = %v", out)
+	}
+
+	out = decodeMarketResult(t, tool.Execute(context.Background(), map[string]any{
+		"action": "history", "symbol": "AAPL", "days": float64(5),
+	}))
+	points := out["points"].([]any)
+	if len(points) != 3 || points[0].(map[string]any)["date"] != "2026-03-02" {
+		t.Fatalf("history points = %v", points)
+	}
+}
+
+func TestMarketTool_CachesResults(t *testing.T) {
+	var hits atomic.Int32
+	server := newMarketTestServer(t, &hits)
+	defer server.Close()
+	tool := newTestMarketTool(t, server.URL, MarketToolOptions{CacheTTL: time.Minute})
+
+	args := map[string]any{"action": "quote", "symbol": "AAPL"}
+	tool.Execute(context.Background(), args)
+	tool.Execute(context.Background(), args)
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("requests = %d, want 1 while cached", got)
+	}
+
+	tool.now = func() time.Time { return marketTestNow.Add(2 * time.Minute) }
+	tool.Execute(context.Background(), args)
+	if got := hits.Load(); got != 2 {
+		t.Fatalf("requests = %d, want a refetch after the TTL", got)
+	}
+
+	// Failures are not cached.
+	tool.Execute(context.Background(), map[string]any{"action": "quote", "symbol": "ZZZZ"})
+	tool.Execute(context.Background(), map[string]any{"action": "quote", "symbol": "ZZZZ"})
+	if got := hits.Load(); got != 4 {
+		t.Fatalf("requests = %d, want unknown symbols retried", got)
+	}
+}
+
+func TestMarketTool_AlphaVantage(t *testing.T) {
+	var hits atomic.Int32
+	server := newMarketTestServer(t, &hits)
+	defer server.Close()
+	tool := newTestMarketTool(t, server.URL, MarketToolOptions{StockProvider: "alpha_vantage", StockAPIKey: "av-key"})
+
+	out := decodeMarketResult(t, tool.Execute(context.Background(), map[string]any{"action": "quote", "symbol": "msft"}))
+	if out["price"] != 410.0 || out["change"] != 10.0 || out["change_percent"] != 2.5 || out["source"] != "alpha_vantage" {
+		t.Fatalf("quote = %v", out)
+	}
+
+	out = decodeMarketResult(t, tool.Execute(context.Background(), map[string]any{"action": "history", "symbol": "MSFT"}))
+	points := out["points"].([]any)
+	if len(points) != 2 || points[0].(map[string]any)["date"] != "2026-03-03" {
+		t.Fatalf("history = %v, want the two points inside 30 days, oldest first", points)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "quote", "symbol": "NOPE"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "no stock data found") {
+		t.Fatalf("unknown symbol = %q", result.ForLLM)
+	}
+}
+
+func TestMarketTool_Crypto(t *testing.T) {
+	var hits atomic.Int32
+	server := newMarketTestServer(t, &hits)
+	defer server.Close()
+	tool := newTestMarketTool(t, server.URL, MarketToolOptions{CryptoAPIKey: "cg-key", Currency: "EUR"})
+
+	out := decodeMarketResult(t, tool.Execute(context.Background(), map[string]any{
+		"action": "quote", "symbol": "btc", "asset": "crypto",
+	}))
+	if out["name"] != "Bitcoin" || out["price"] != 60000.0 || out["currency"] != "EUR" || out["change"] != 10000.0 {
+		t.Fatalf("quote = %v, want the top-ranked BTC in EUR", out)
+	}
+	if out["timestamp"] != time.Unix(1772640000, 0).UTC().Format(time.RFC3339) {
+		t.Fatalf("timestamp = %v", out["timestamp"])
+	}
+
+	out = decodeMarketResult(t, tool.Execute(context.Background(), map[string]any{
+		"action": "history", "symbol": "bitcoin", "asset": "crypto", "days": float64(2),
+	}))
+	points := out["points"].([]any)
+	if len(points) != 2 || points[1].(map[string]any)["close"] != 60000.0 {
+		t.Fatalf("history = %v, want one point per day with the latest price last", points)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "quote", "symbol": "DOGE", "asset": "crypto"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "no crypto data found") {
+		t.Fatalf("unknown coin = %q", result.ForLLM)
+	}
+}
+
+func TestMarketTool_Validation(t *testing.T) {
+	tool, err := NewMarketTool(MarketToolOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"action": "buy", "symbol": "AAPL"}, "unknown action"},
+		{map[string]any{"action": "quote"}, "symbol is required"},
+		{map[string]any{"action": "quote", "symbol": "AAPL", "asset": "bond"}, "unknown asset"},
+		{map[string]any{"action": "history", "symbol": "AAPL", "days": float64(1000)}, "days must be"},
+	}
+	for _, tt := range tests {
+		result := tool.Execute(context.Background(), tt.args)
+		if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+			t.Errorf("Execute(%v) = %q, want error containing %q", tt.args, result.ForLLM, tt.want)
+		}
+	}
+
+	if _, err := NewMarketTool(MarketToolOptions{StockProvider: "alpha_vantage"}); err == nil {
+		t.Error("expected error for alpha_vantage without a key")
+	}
+	if _, err := NewMarketTool(MarketToolOptions{StockProvider: "bloomberg"}); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
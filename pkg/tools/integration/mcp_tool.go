@@ -29,6 +29,12 @@ type MCPManager interface {
 	) (*mcp.CallToolResult, error)
 }
 
+// mcpAvailabilityChecker is implemented by managers that supervise their
+// servers and can report when one is down.
+type mcpAvailabilityChecker interface {
+	ServerAvailable(serverName string) bool
+}
+
 // MCPTool wraps an MCP tool to implement the Tool interface
 type MCPTool struct {
 	manager            MCPManager
@@ -73,6 +79,13 @@ func (t *MCPTool) SetMaxInlineTextRunes(limit int) {
 	}
 }
 
+// Available reports whether the backing MCP server is up. Tools of a crashed
+// server are hidden from the model until the server has been restarted.
+func (t *MCPTool) Available() bool {
+	checker, ok := t.manager.(mcpAvailabilityChecker)
+	return !ok || checker.ServerAvailable(t.serverName)
+}
+
 // SetEventPublisher injects the runtime event bus used for MCP tool observations.
 func (t *MCPTool) SetEventPublisher(eventBus runtimeevents.Bus) {
 	t.runtimeEvents = eventBus
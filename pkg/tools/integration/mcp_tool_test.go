@@ -123,6 +123,30 @@ func TestMCPTool_PromptMetadata(t *testing.T) {
 	}
 }
 
+type supervisedMockMCPManager struct {
+	MockMCPManager
+	down map[string]bool
+}
+
+func (m *supervisedMockMCPManager) ServerAvailable(serverName string) bool {
+	return !m.down[serverName]
+}
+
+// TestMCPTool_Available verifies availability follows the supervising manager
+func TestMCPTool_Available(t *testing.T) {
+	if !NewMCPTool(&MockMCPManager{}, "github", &mcp.Tool{Name: "create_issue"}).Available() {
+		t.Fatal("tools of an unsupervised manager should always be available")
+	}
+
+	manager := &supervisedMockMCPManager{down: map[string]bool{"github": true}}
+	if NewMCPTool(manager, "github", &mcp.Tool{Name: "create_issue"}).Available() {
+		t.Fatal("tool of a crashed server should be unavailable")
+	}
+	if !NewMCPTool(manager, "filesystem", &mcp.Tool{Name: "read"}).Available() {
+		t.Fatal("tool of a healthy server should be available")
+	}
+}
+
 // TestMCPTool_Description verifies tool description generation
 func TestMCPTool_Description(t *testing.T) {
 	tests := []struct {
@@ -24,6 +24,15 @@ type SendCallbackWithContext func(
 	mediaParts []bus.MediaPart,
 ) error
 
+// MessageOpCallback edits or deletes a message the agent sent earlier.
+// messageID may be empty or "last" to target the latest sent message.
+type MessageOpCallback func(
+	ctx context.Context,
+	channel, chatID string,
+	op bus.OutboundOperation,
+	messageID, content string,
+) error
+
 type messageMediaArg struct {
 	Path     string
 	Type     string
@@ -38,6 +47,7 @@ type sentTarget struct {
 
 type MessageTool struct {
 	sendCallback      SendCallbackWithContext
+	opCallback        MessageOpCallback
 	workspace         string
 	restrict          bool
 	maxFileSize       int
@@ -67,6 +77,15 @@ func (t *MessageTool) Description() string {
 
 func (t *MessageTool) Parameters() map[string]any {
 	properties := map[string]any{
+		"action": map[string]any{
+			"type":        "string",
+			"enum":        []string{"send", "edit", "delete"},
+			"description": "Optional: send (default) a new message, or edit/delete one you sent earlier. Content is the new text for edit and is ignored for delete",
+		},
+		"message_id": map[string]any{
+			"type":        "string",
+			"description": "Optional for edit/delete: ID of your earlier message; defaults to the last one you sent in the chat",
+		},
 		"content": map[string]any{
 			"type":        "string",
 			"description": "Optional message text. When media is present, this text is used as the caption/body for the media message.",
@@ -177,7 +196,21 @@ func (t *MessageTool) SetSendCallback(callback SendCallbackWithContext) {
 	t.sendCallback = callback
 }
 
+// SetMessageOpCallback enables the edit and delete actions.
+func (t *MessageTool) SetMessageOpCallback(callback MessageOpCallback) {
+	t.opCallback = callback
+}
+
 func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	switch op := bus.OutboundOperation(strings.ToLower(strings.TrimSpace(action))); op {
+	case "", bus.OutboundSend:
+	case bus.OutboundEdit, bus.OutboundDelete:
+		return t.executeOp(ctx, op, args)
+	default:
+		return &ToolResult{ForLLM: fmt.Sprintf("unknown action %q", action), IsError: true}
+	}
+
 	content, _ := args["content"].(string)
 	content = strings.TrimSpace(content)
 	mediaArgs, err := parseMessageMediaArgs(args["media"])
@@ -242,6 +275,47 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *ToolRes
 	}
 }
 
+func (t *MessageTool) executeOp(ctx context.Context, op bus.OutboundOperation, args map[string]any) *ToolResult {
+	content, _ := args["content"].(string)
+	content = strings.TrimSpace(content)
+	if op == bus.OutboundEdit && content == "" {
+		return &ToolResult{ForLLM: "content is required for edit", IsError: true}
+	}
+
+	channel, _ := args["channel"].(string)
+	chatID, _ := args["chat_id"].(string)
+	messageID, _ := args["message_id"].(string)
+	if channel == "" {
+		channel = ToolChannel(ctx)
+	}
+	if chatID == "" {
+		chatID = ToolChatID(ctx)
+	}
+	if channel == "" || chatID == "" {
+		return &ToolResult{ForLLM: "No target channel/chat specified", IsError: true}
+	}
+	if t.opCallback == nil {
+		return &ToolResult{ForLLM: fmt.Sprintf("Message %s not configured", op), IsError: true}
+	}
+
+	if err := t.opCallback(ctx, channel, chatID, op, strings.TrimSpace(messageID), content); err != nil {
+		return &ToolResult{
+			ForLLM:  fmt.Sprintf("%s message: %v", op, err),
+			IsError: true,
+			Err:     err,
+		}
+	}
+
+	if op == bus.OutboundEdit {
+		sessionKey := ToolSessionKey(ctx)
+		t.mu.Lock()
+		t.sentTargets[sessionKey] = append(t.sentTargets[sessionKey], sentTarget{Channel: channel, ChatID: chatID})
+		t.mu.Unlock()
+		return &ToolResult{ForLLM: fmt.Sprintf("Message edited in %s:%s", channel, chatID), Silent: true}
+	}
+	return &ToolResult{ForLLM: fmt.Sprintf("Message deleted in %s:%s", channel, chatID), Silent: true}
+}
+
 func parseMessageMediaArgs(raw any) ([]messageMediaArg, error) {
 	if raw == nil {
 		return nil, nil
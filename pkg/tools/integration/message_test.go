@@ -467,3 +467,73 @@ func TestMessageTool_Execute_WithMedia(t *testing.T) {
 		t.Fatal("expected media type to be inferred")
 	}
 }
+
+func TestMessageTool_Execute_EditAction(t *testing.T) {
+	tool := NewMessageTool()
+	tool.SetSendCallback(func(
+		context.Context,
+		string, string, string, string,
+		[]bus.MediaPart,
+	) error {
+		t.Fatal("edit must not send a new message")
+		return nil
+	})
+
+	var gotOp bus.OutboundOperation
+	var gotID, gotContent string
+	tool.SetMessageOpCallback(func(
+		_ context.Context,
+		channel, chatID string,
+		op bus.OutboundOperation,
+		messageID, content string,
+	) error {
+		if channel != "telegram" || chatID != "42" {
+			t.Fatalf("target = %s:%s, want telegram:42", channel, chatID)
+		}
+		gotOp, gotID, gotContent = op, messageID, content
+		return nil
+	})
+
+	ctx := WithToolContext(context.Background(), "telegram", "42")
+	result := tool.Execute(ctx, map[string]any{
+		"action":  "edit",
+		"content": "fixed answer",
+	})
+	if result.IsError {
+		t.Fatalf("edit failed: %s", result.ForLLM)
+	}
+	if gotOp != bus.OutboundEdit || gotID != "" || gotContent != "fixed answer" {
+		t.Fatalf("op=%q id=%q content=%q, want edit of last message", gotOp, gotID, gotContent)
+	}
+
+	result = tool.Execute(ctx, map[string]any{
+		"action":     "delete",
+		"message_id": "7",
+		"content":    "",
+	})
+	if result.IsError {
+		t.Fatalf("delete failed: %s", result.ForLLM)
+	}
+	if gotOp != bus.OutboundDelete || gotID != "7" {
+		t.Fatalf("op=%q id=%q, want delete of 7", gotOp, gotID)
+	}
+}
+
+func TestMessageTool_Execute_EditRequiresContent(t *testing.T) {
+	tool := NewMessageTool()
+	tool.SetMessageOpCallback(func(
+		context.Context,
+		string, string,
+		bus.OutboundOperation,
+		string, string,
+	) error {
+		t.Fatal("callback should not run without content")
+		return nil
+	})
+
+	ctx := WithToolContext(context.Background(), "telegram", "42")
+	result := tool.Execute(ctx, map[string]any{"action": "edit"})
+	if !result.IsError {
+		t.Fatal("expected error for edit without content")
+	}
+}
@@ -0,0 +1,396 @@
+package integrationtools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	fstools "github.com/sipeed/picoclaw/pkg/tools/fs"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	ocrEngineTesseract = "tesseract"
+	ocrEngineOCRSpace  = "ocr_space"
+
+	defaultOCRSpaceURL = "https://api.ocr.space/parse/image"
+	ocrTimeout         = time.Minute
+	maxOCRTextChars    = 50000
+)
+
+// ocrFormats maps the content types accepted by the ocr tool to display names.
+var ocrFormats = map[string]string{
+	"image/png":  "PNG",
+	"image/jpeg": "JPEG",
+	"image/gif":  "GIF",
+	"image/bmp":  "BMP",
+	"image/webp": "WebP",
+	"image/tiff": "TIFF",
+}
+
+// OCRToolOptions holds the settings for NewOCRTool.
+type OCRToolOptions struct {
+	Engine        string
+	Languages     string
+	TesseractPath string
+	APIKey        string
+	BaseURL       string
+
+	Workspace   string
+	Restrict    bool
+	AllowPaths  []*regexp.Regexp
+	MaxFileSize int
+
+	Proxy                string
+	PrivateHostWhitelist []string
+}
+
+// OCRTool extracts text from an image file or URL, so models without vision
+// support can still read screenshots and photos.
+type OCRTool struct {
+	engine        string
+	languages     string
+	tesseractPath string
+	apiKey        string
+	baseURL       string
+
+	workspace   string
+	restrict    bool
+	allowPaths  []*regexp.Regexp
+	maxFileSize int
+
+	// fetchClient downloads image URLs and refuses private targets;
+	// apiClient talks to the configured OCR service.
+	fetchClient *http.Client
+	apiClient   *http.Client
+	whitelist   *privateHostWhitelist
+}
+
+func NewOCRTool(opts OCRToolOptions) (*OCRTool, error) {
+	engine := strings.ToLower(strings.TrimSpace(opts.Engine))
+	if engine == "" {
+		engine = ocrEngineTesseract
+	}
+	t := &OCRTool{
+		engine:        engine,
+		languages:     strings.TrimSpace(opts.Languages),
+		tesseractPath: strings.TrimSpace(opts.TesseractPath),
+		apiKey:        strings.TrimSpace(opts.APIKey),
+		baseURL:       strings.TrimSpace(opts.BaseURL),
+		workspace:     opts.Workspace,
+		restrict:      opts.Restrict,
+		allowPaths:    opts.AllowPaths,
+		maxFileSize:   opts.MaxFileSize,
+	}
+	if t.maxFileSize <= 0 {
+		t.maxFileSize = config.DefaultMaxMediaSize
+	}
+
+	switch engine {
+	case ocrEngineTesseract:
+		if t.tesseractPath == "" {
+			t.tesseractPath = "tesseract"
+		}
+	case ocrEngineOCRSpace:
+		if t.apiKey == "" {
+			return nil, fmt.Errorf("ocr: api_key is required for the ocr_space engine")
+		}
+		if t.baseURL == "" {
+			t.baseURL = defaultOCRSpaceURL
+		}
+		apiClient, err := utils.CreateHTTPClient(opts.Proxy, ocrTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client for ocr: %w", err)
+		}
+		t.apiClient = apiClient
+	default:
+		return nil, fmt.Errorf("ocr: unknown engine %q (expected %q or %q)",
+			opts.Engine, ocrEngineTesseract, ocrEngineOCRSpace)
+	}
+
+	whitelist, err := newPrivateHostWhitelist(opts.PrivateHostWhitelist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ocr private host whitelist: %w", err)
+	}
+	fetchClient, err := newSafeHTTPClient(opts.Proxy, whitelist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client for ocr: %w", err)
+	}
+	t.fetchClient = fetchClient
+	t.whitelist = whitelist
+	return t, nil
+}
+
+func (t *OCRTool) Name() string { return "ocr" }
+
+func (t *OCRTool) Description() string {
+	return "Extract text from an image (screenshot, photo, scanned page) given a local path or an " +
+		"http(s) URL. Supported formats: PNG, JPEG, GIF, BMP, WebP, TIFF."
+}
+
+func (t *OCRTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Path to a local image file. Relative paths are resolved from workspace.",
+			},
+			"url": map[string]any{
+				"type":        "string",
+				"description": "http(s) URL of an image. Use instead of path.",
+			},
+			"language": map[string]any{
+				"type": "string",
+				"description": "Optional OCR language code overriding the configured default " +
+					"(e.g. eng, deu, chi_sim; tesseract accepts several joined with +).",
+			},
+		},
+	}
+}
+
+func (t *OCRTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	path, _ := args["path"].(string)
+	path = strings.TrimSpace(path)
+	rawURL, _ := args["url"].(string)
+	rawURL = strings.TrimSpace(rawURL)
+	if (path == "") == (rawURL == "") {
+		return ErrorResult("provide exactly one of path or url")
+	}
+	language, _ := args["language"].(string)
+	language = strings.TrimSpace(language)
+	if language == "" {
+		language = t.languages
+	}
+
+	var (
+		image  []byte
+		source string
+		err    error
+	)
+	if path != "" {
+		image, source, err = t.readLocal(path)
+	} else {
+		image, err = t.fetch(ctx, rawURL)
+		source = rawURL
+	}
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+
+	contentType := detectOCRContentType(image)
+	if _, ok := ocrFormats[contentType]; !ok {
+		return ErrorResult(fmt.Sprintf(
+			"unsupported image format (detected type: %s); supported formats: PNG, JPEG, GIF, BMP, WebP, TIFF",
+			contentType,
+		))
+	}
+
+	var text string
+	if t.engine == ocrEngineOCRSpace {
+		text, err = t.recognizeOCRSpace(ctx, image, contentType, language)
+	} else {
+		text, err = t.recognizeTesseract(ctx, image, language)
+	}
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("ocr failed: %v", err)).WithError(err)
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return NewToolResult(fmt.Sprintf("No text found in %s.", source))
+	}
+	if runes := []rune(text); len(runes) > maxOCRTextChars {
+		text = string(runes[:maxOCRTextChars]) + "\n[truncated]"
+	}
+	return NewToolResult(fmt.Sprintf("Text extracted from %s:\n\n%s", source, text))
+}
+
+func (t *OCRTool) readLocal(path string) ([]byte, string, error) {
+	resolved, err := fstools.ValidatePathWithAllowPaths(path, t.workspace, t.restrict, t.allowPaths)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid path: %w", err)
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, "", fmt.Errorf("file not found: %w", err)
+	}
+	if info.IsDir() {
+		return nil, "", fmt.Errorf("path is a directory, expected an image file")
+	}
+	if info.Size() > int64(t.maxFileSize) {
+		return nil, "", fmt.Errorf("file too large: %d bytes (max %d bytes)", info.Size(), t.maxFileSize)
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read image: %w", err)
+	}
+	return data, path, nil
+}
+
+func (t *OCRTool) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("only http/https URLs are allowed")
+	}
+	if parsed.Hostname() == "" {
+		return nil, fmt.Errorf("missing domain in URL")
+	}
+	if isObviousPrivateHost(parsed.Hostname(), t.whitelist) {
+		return nil, fmt.Errorf("fetching images from private or local network hosts is not allowed")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := t.fetchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch image: server returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(t.maxFileSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	if len(data) > t.maxFileSize {
+		return nil, fmt.Errorf("image too large (max %d bytes)", t.maxFileSize)
+	}
+	return data, nil
+}
+
+func (t *OCRTool) recognizeTesseract(ctx context.Context, image []byte, language string) (string, error) {
+	bin, err := exec.LookPath(t.tesseractPath)
+	if err != nil {
+		return "", fmt.Errorf(
+			"tesseract is not installed or not on PATH (%s); install tesseract-ocr "+
+				"or set tools.ocr.tesseract_path", t.tesseractPath)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ocrTimeout)
+	defer cancel()
+
+	// Feed the image on stdin so local files and downloads take the same path
+	// and nothing has to be written to disk.
+	cmdArgs := []string{"stdin", "stdout"}
+	if language != "" {
+		cmdArgs = append(cmdArgs, "-l", language)
+	}
+	cmd := exec.CommandContext(ctx, bin, cmdArgs...)
+	cmd.Stdin = bytes.NewReader(image)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("tesseract timed out after %s", ocrTimeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("tesseract: %s", msg)
+		}
+		return "", fmt.Errorf("tesseract: %w", err)
+	}
+	return stdout.String(), nil
+}
+
+func (t *OCRTool) recognizeOCRSpace(ctx context.Context, image []byte, contentType, language string) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	fields := map[string]string{
+		"base64Image": "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(image),
+		"scale":       "true",
+	}
+	if language != "" {
+		fields["language"] = language
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return "", fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("apikey", t.apiKey)
+
+	resp, err := t.apiClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var parsed struct {
+		ParsedResults []struct {
+			ParsedText string `json:"ParsedText"`
+		} `json:"ParsedResults"`
+		IsErroredOnProcessing bool            `json:"IsErroredOnProcessing"`
+		ErrorMessage          json.RawMessage `json:"ErrorMessage"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if parsed.IsErroredOnProcessing {
+		return "", fmt.Errorf("API error: %s", ocrSpaceErrorMessage(parsed.ErrorMessage))
+	}
+
+	parts := make([]string, 0, len(parsed.ParsedResults))
+	for _, r := range parsed.ParsedResults {
+		parts = append(parts, strings.TrimSpace(r.ParsedText))
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// ocrSpaceErrorMessage flattens OCR.space's ErrorMessage, which is either a
+// string or a list of strings.
+func ocrSpaceErrorMessage(raw json.RawMessage) string {
+	var list []string
+	if json.Unmarshal(raw, &list) == nil && len(list) > 0 {
+		return strings.Join(list, "; ")
+	}
+	var msg string
+	if json.Unmarshal(raw, &msg) == nil && msg != "" {
+		return msg
+	}
+	return "processing failed"
+}
+
+func detectOCRContentType(data []byte) string {
+	// http.DetectContentType does not sniff TIFF, a common scanner format.
+	if bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*")) {
+		return "image/tiff"
+	}
+	return http.DetectContentType(data)
+}
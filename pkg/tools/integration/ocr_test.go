@@ -0,0 +1,193 @@
+//go:build !windows
+
+package integrationtools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeTesseract writes a script that records its arguments and the size of
+// its stdin, then prints text as the recognized output.
+func fakeTesseract(t *testing.T, text string) (bin, argsFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	bin = filepath.Join(dir, "tesseract")
+	argsFile = filepath.Join(dir, "args")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\ncat > /dev/null\nprintf '%s' '" + text + "'\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return bin, argsFile
+}
+
+func writeTestImage(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "shot.png")
+	if err := os.WriteFile(path, testPNG(t), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOCRTool_TesseractLocalFile(t *testing.T) {
+	workspace := t.TempDir()
+	writeTestImage(t, workspace)
+	bin, argsFile := fakeTesseract(t, "Invoice #42\nTotal: 10 EUR")
+
+	tool, err := NewOCRTool(OCRToolOptions{
+		TesseractPath: bin,
+		Languages:     "eng",
+		Workspace:     workspace,
+		Restrict:      true,
+	})
+	if err != nil {
+		t.Fatalf("NewOCRTool() error = %v", err)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"path": "shot.png", "language": "deu+eng"})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "Total: 10 EUR") {
+		t.Fatalf("ForLLM = %q, want recognized text", result.ForLLM)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(args)); got != "stdin stdout -l deu+eng" {
+		t.Fatalf("tesseract args = %q, want language override", got)
+	}
+}
+
+func TestOCRTool_Errors(t *testing.T) {
+	workspace := t.TempDir()
+	writeTestImage(t, workspace)
+	if err := os.WriteFile(filepath.Join(workspace, "notes.txt"), []byte("plain text"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outside := writeTestImage(t, t.TempDir())
+
+	tool, err := NewOCRTool(OCRToolOptions{
+		TesseractPath: filepath.Join(t.TempDir(), "missing-tesseract"),
+		Workspace:     workspace,
+		Restrict:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"no input", map[string]any{}, "exactly one of path or url"},
+		{"both inputs", map[string]any{"path": "shot.png", "url": "https://example.com/a.png"}, "exactly one"},
+		{"engine missing", map[string]any{"path": "shot.png"}, "tesseract is not installed"},
+		{"unsupported format", map[string]any{"path": "notes.txt"}, "unsupported image format"},
+		{"outside workspace", map[string]any{"path": outside}, "invalid path"},
+		{"private url", map[string]any{"url": "http://127.0.0.1/a.png"}, "private or local network"},
+		{"bad scheme", map[string]any{"url": "file:///etc/passwd"}, "only http/https"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tool.Execute(context.Background(), tt.args)
+			if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+				t.Fatalf("result = %q, want error containing %q", result.ForLLM, tt.want)
+			}
+		})
+	}
+}
+
+func TestOCRTool_URLWithWhitelist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(testPNG(t))
+	}))
+	defer server.Close()
+	bin, _ := fakeTesseract(t, "hello from a url")
+
+	tool, err := NewOCRTool(OCRToolOptions{
+		TesseractPath:        bin,
+		PrivateHostWhitelist: []string{"127.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL + "/shot.png"})
+	if result.IsError || !strings.Contains(result.ForLLM, "hello from a url") {
+		t.Fatalf("result = %+v, want recognized text", result)
+	}
+}
+
+func TestOCRTool_OCRSpace(t *testing.T) {
+	workspace := t.TempDir()
+	writeTestImage(t, workspace)
+
+	var gotKey, gotLanguage, gotImage string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("apikey")
+		gotLanguage = r.FormValue("language")
+		gotImage = r.FormValue("base64Image")
+		_, _ = w.Write([]byte(`{"ParsedResults":[{"ParsedText":"line one\r\n"},{"ParsedText":"line two"}],` +
+			`"IsErroredOnProcessing":false}`))
+	}))
+	defer server.Close()
+
+	tool, err := NewOCRTool(OCRToolOptions{
+		Engine:    "ocr_space",
+		APIKey:    "key-123",
+		BaseURL:   server.URL,
+		Languages: "ger",
+		Workspace: workspace,
+	})
+	if err != nil {
+		t.Fatalf("NewOCRTool() error = %v", err)
+	}
+	result := tool.Execute(context.Background(), map[string]any{"path": "shot.png"})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "line one\nline two") {
+		t.Fatalf("ForLLM = %q, want both parsed pages", result.ForLLM)
+	}
+	if gotKey != "key-123" || gotLanguage != "ger" || !strings.HasPrefix(gotImage, "data:image/png;base64,") {
+		t.Fatalf("request key=%q language=%q image prefix=%.30q", gotKey, gotLanguage, gotImage)
+	}
+}
+
+func TestOCRTool_OCRSpaceError(t *testing.T) {
+	workspace := t.TempDir()
+	writeTestImage(t, workspace)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"IsErroredOnProcessing":true,"ErrorMessage":["E201: language is invalid"]}`))
+	}))
+	defer server.Close()
+
+	tool, err := NewOCRTool(OCRToolOptions{Engine: "ocr_space", APIKey: "k", BaseURL: server.URL, Workspace: workspace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := tool.Execute(context.Background(), map[string]any{"path": "shot.png"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "E201: language is invalid") {
+		t.Fatalf("result = %q, want API error", result.ForLLM)
+	}
+}
+
+func TestNewOCRTool_Validation(t *testing.T) {
+	if _, err := NewOCRTool(OCRToolOptions{Engine: "ocr_space"}); err == nil {
+		t.Fatal("ocr_space without api key should fail")
+	}
+	if _, err := NewOCRTool(OCRToolOptions{Engine: "abbyy"}); err == nil {
+		t.Fatal("unknown engine should fail")
+	}
+	if _, err := NewOCRTool(OCRToolOptions{PrivateHostWhitelist: []string{"not-an-ip"}}); err == nil {
+		t.Fatal("invalid whitelist should fail")
+	}
+}
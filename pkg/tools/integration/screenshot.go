@@ -0,0 +1,207 @@
+package integrationtools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/media"
+)
+
+const screenshotTimeout = 15 * time.Second
+
+// screenRegion is a rectangle in screen pixels.
+type screenRegion struct {
+	X, Y, Width, Height int
+}
+
+// screenshotBackend returns the command that writes a PNG of the screen, or
+// of region when it is non-nil, to path.
+type screenshotBackend func(path string, region *screenRegion) []string
+
+// errNoDisplay is returned by platform backends when there is no screen to
+// capture, e.g. on a headless server.
+var errNoDisplay = errors.New("no display available")
+
+// detectScreenshot is replaced in tests.
+var detectScreenshot = platformScreenshot
+
+// ScreenshotTool captures the desktop PicoClaw runs on through the platform's
+// screenshot utility and attaches the image to the tool result, so a
+// vision-capable model sees it on its next call.
+type ScreenshotTool struct {
+	mediaStore media.MediaStore
+}
+
+func NewScreenshotTool() *ScreenshotTool {
+	return &ScreenshotTool{}
+}
+
+func (t *ScreenshotTool) Name() string {
+	return "screenshot"
+}
+
+func (t *ScreenshotTool) Description() string {
+	return "Capture the screen of the desktop PicoClaw runs on, or a rectangular region of it, so you can look at what the user sees. The image is attached to the result for you to analyze. Fails on headless machines."
+}
+
+func (t *ScreenshotTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"x": map[string]any{
+				"type":        "integer",
+				"description": "Left edge of the region in pixels. Give x, y, width and height together to capture a region; omit all four for the whole screen.",
+			},
+			"y": map[string]any{
+				"type":        "integer",
+				"description": "Top edge of the region in pixels",
+			},
+			"width": map[string]any{
+				"type":        "integer",
+				"description": "Region width in pixels",
+			},
+			"height": map[string]any{
+				"type":        "integer",
+				"description": "Region height in pixels",
+			},
+		},
+	}
+}
+
+func (t *ScreenshotTool) SetMediaStore(store media.MediaStore) {
+	t.mediaStore = store
+}
+
+func (t *ScreenshotTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	region, err := parseScreenRegion(args)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if t.mediaStore == nil {
+		return ErrorResult("media store not configured")
+	}
+	backend, err := detectScreenshot()
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("screenshot unavailable: %v", err))
+	}
+
+	if err := os.MkdirAll(media.TempDir(), 0o700); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to create media temp dir: %v", err))
+	}
+	// Some capture tools refuse to overwrite a file, so the path must not
+	// exist yet.
+	path := filepath.Join(media.TempDir(), fmt.Sprintf("screenshot-%d.png", time.Now().UnixNano()))
+	if err := runScreenshotCommand(ctx, backend(path, region)); err != nil {
+		_ = os.Remove(path)
+		return ErrorResult(fmt.Sprintf("failed to capture screen: %v", err)).WithError(err)
+	}
+	if err := checkScreenshotFile(path); err != nil {
+		_ = os.Remove(path)
+		return ErrorResult(err.Error()).WithError(err)
+	}
+
+	scope := fmt.Sprintf("tool:screenshot:%s:%s:%d", ToolChannel(ctx), ToolChatID(ctx), time.Now().UnixNano())
+	ref, err := t.mediaStore.Store(path, media.MediaMeta{
+		Filename:    filepath.Base(path),
+		ContentType: "image/png",
+		Source:      "tool:screenshot",
+	}, scope)
+	if err != nil {
+		_ = os.Remove(path)
+		return ErrorResult(fmt.Sprintf("failed to register screenshot in media store: %v", err))
+	}
+
+	what := "the screen"
+	if region != nil {
+		what = fmt.Sprintf("region %dx%d at (%d,%d)", region.Width, region.Height, region.X, region.Y)
+	}
+	// As with load_image, the ref is resolved into an image part of the
+	// tool result message rather than sent to the user.
+	return &ToolResult{
+		ForLLM:  fmt.Sprintf("Captured %s\n[image: screenshot]", what),
+		ForUser: "Captured a screenshot",
+		Media:   []string{ref},
+	}
+}
+
+// parseScreenRegion returns nil when no region is given. A partial region is
+// an error rather than silently capturing the whole screen.
+func parseScreenRegion(args map[string]any) (*screenRegion, error) {
+	keys := []string{"x", "y", "width", "height"}
+	values := make([]int, len(keys))
+	given := 0
+	for i, key := range keys {
+		raw, ok := args[key]
+		if !ok || raw == nil {
+			continue
+		}
+		n, ok := raw.(float64)
+		if !ok || n != float64(int(n)) {
+			return nil, fmt.Errorf("%s must be an integer", key)
+		}
+		values[i] = int(n)
+		given++
+	}
+	switch {
+	case given == 0:
+		return nil, nil
+	case given < len(keys):
+		return nil, errors.New("x, y, width and height must be given together")
+	case values[0] < 0 || values[1] < 0:
+		return nil, errors.New("x and y must not be negative")
+	case values[2] <= 0 || values[3] <= 0:
+		return nil, errors.New("width and height must be positive")
+	}
+	return &screenRegion{X: values[0], Y: values[1], Width: values[2], Height: values[3]}, nil
+}
+
+func runScreenshotCommand(ctx context.Context, argv []string) error {
+	ctx, cancel := context.WithTimeout(ctx, screenshotTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", argv[0], err, msg)
+		}
+		return fmt.Errorf("%s: %w", argv[0], err)
+	}
+	return nil
+}
+
+func checkScreenshotFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("screenshot was not written: %w", err)
+	}
+	if contentType := http.DetectContentType(data); contentType != "image/png" {
+		return fmt.Errorf("screenshot is not a PNG image (%s)", contentType)
+	}
+	return nil
+}
+
+// screenshotCommand pairs a capture utility with the argv builder for it.
+type screenshotCommand struct {
+	name  string
+	build screenshotBackend
+}
+
+// firstScreenshotCommand returns the first candidate that is installed.
+func firstScreenshotCommand(candidates []screenshotCommand, missing string) (screenshotBackend, error) {
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c.name); err == nil {
+			return c.build, nil
+		}
+	}
+	return nil, fmt.Errorf("no screenshot utility found: %s", missing)
+}
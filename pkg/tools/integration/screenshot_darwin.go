@@ -0,0 +1,16 @@
+//go:build darwin
+
+package integrationtools
+
+import "fmt"
+
+func platformScreenshot() (screenshotBackend, error) {
+	return firstScreenshotCommand([]screenshotCommand{
+		{name: "screencapture", build: func(path string, r *screenRegion) []string {
+			if r == nil {
+				return []string{"screencapture", "-x", "-t", "png", path}
+			}
+			return []string{"screencapture", "-x", "-t", "png", "-R", fmt.Sprintf("%d,%d,%d,%d", r.X, r.Y, r.Width, r.Height), path}
+		}},
+	}, "screencapture not found")
+}
@@ -0,0 +1,44 @@
+//go:build linux
+
+package integrationtools
+
+import (
+	"fmt"
+	"os"
+)
+
+func platformScreenshot() (screenshotBackend, error) {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return firstScreenshotCommand([]screenshotCommand{
+			{name: "grim", build: func(path string, r *screenRegion) []string {
+				if r == nil {
+					return []string{"grim", path}
+				}
+				return []string{"grim", "-g", fmt.Sprintf("%d,%d %dx%d", r.X, r.Y, r.Width, r.Height), path}
+			}},
+		}, "install grim")
+	}
+	if os.Getenv("DISPLAY") != "" {
+		return firstScreenshotCommand([]screenshotCommand{
+			{name: "scrot", build: func(path string, r *screenRegion) []string {
+				if r == nil {
+					return []string{"scrot", path}
+				}
+				return []string{"scrot", "-a", fmt.Sprintf("%d,%d,%d,%d", r.X, r.Y, r.Width, r.Height), path}
+			}},
+			{name: "maim", build: func(path string, r *screenRegion) []string {
+				if r == nil {
+					return []string{"maim", path}
+				}
+				return []string{"maim", "-g", fmt.Sprintf("%dx%d+%d+%d", r.Width, r.Height, r.X, r.Y), path}
+			}},
+			{name: "import", build: func(path string, r *screenRegion) []string {
+				if r == nil {
+					return []string{"import", "-window", "root", path}
+				}
+				return []string{"import", "-window", "root", "-crop", fmt.Sprintf("%dx%d+%d+%d", r.Width, r.Height, r.X, r.Y), path}
+			}},
+		}, "install scrot, maim or ImageMagick")
+	}
+	return nil, fmt.Errorf("%w: no graphical session (DISPLAY and WAYLAND_DISPLAY are unset)", errNoDisplay)
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package integrationtools
+
+import "fmt"
+
+func platformScreenshot() (screenshotBackend, error) {
+	return nil, fmt.Errorf("%w on this platform", errNoDisplay)
+}
@@ -0,0 +1,104 @@
+//go:build !windows
+
+package integrationtools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/media"
+)
+
+// useFakeScreenshot makes captures copy src and records the requested region.
+func useFakeScreenshot(t *testing.T, src string) *string {
+	t.Helper()
+	var gotRegion string
+	orig := detectScreenshot
+	detectScreenshot = func() (screenshotBackend, error) {
+		return func(path string, r *screenRegion) []string {
+			if r != nil {
+				gotRegion = fmt.Sprintf("%d,%d,%d,%d", r.X, r.Y, r.Width, r.Height)
+			}
+			return []string{"cp", src, path}
+		}, nil
+	}
+	t.Cleanup(func() { detectScreenshot = orig })
+	return &gotRegion
+}
+
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestScreenshotTool_CapturesRegionIntoMediaStore(t *testing.T) {
+	gotRegion := useFakeScreenshot(t, writeTestFile(t, "screen.png", testPNG(t)))
+	store := media.NewFileMediaStore()
+	tool := NewScreenshotTool()
+	tool.SetMediaStore(store)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"x": float64(10), "y": float64(20), "width": float64(300), "height": float64(200),
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if *gotRegion != "10,20,300,200" {
+		t.Fatalf("region = %q, want 10,20,300,200", *gotRegion)
+	}
+	if result.ResponseHandled || len(result.Media) != 1 {
+		t.Fatalf("result = %+v, want one media ref for the model", result)
+	}
+	if !strings.Contains(result.ForLLM, "300x200") {
+		t.Fatalf("ForLLM = %q, want region description", result.ForLLM)
+	}
+	path, meta, err := store.ResolveWithMeta(result.Media[0])
+	if err != nil {
+		t.Fatalf("ResolveWithMeta() error = %v", err)
+	}
+	if meta.ContentType != "image/png" || meta.Source != "tool:screenshot" {
+		t.Fatalf("meta = %+v", meta)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("stored screenshot missing: %v", err)
+	}
+}
+
+func TestScreenshotTool_Errors(t *testing.T) {
+	tool := NewScreenshotTool()
+	tool.SetMediaStore(media.NewFileMediaStore())
+
+	orig := detectScreenshot
+	detectScreenshot = func() (screenshotBackend, error) {
+		return nil, fmt.Errorf("%w: no graphical session", errNoDisplay)
+	}
+	result := tool.Execute(context.Background(), map[string]any{})
+	detectScreenshot = orig
+	if !result.IsError || !strings.Contains(result.ForLLM, "no display available") {
+		t.Fatalf("headless result = %+v, want no display error", result)
+	}
+
+	useFakeScreenshot(t, writeTestFile(t, "screen.txt", []byte("not an image")))
+	if result := tool.Execute(context.Background(), map[string]any{}); !result.IsError {
+		t.Fatalf("non-PNG capture = %+v, want error", result)
+	}
+
+	for _, args := range []map[string]any{
+		{"x": float64(0), "y": float64(0)},
+		{"x": float64(0), "y": float64(0), "width": float64(0), "height": float64(10)},
+		{"x": float64(-1), "y": float64(0), "width": float64(10), "height": float64(10)},
+		{"x": "left", "y": float64(0), "width": float64(10), "height": float64(10)},
+	} {
+		if result := tool.Execute(context.Background(), args); !result.IsError {
+			t.Fatalf("Execute(%v) = %+v, want error", args, result)
+		}
+	}
+}
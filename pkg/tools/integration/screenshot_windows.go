@@ -0,0 +1,29 @@
+//go:build windows
+
+package integrationtools
+
+import (
+	"fmt"
+	"strings"
+)
+
+func platformScreenshot() (screenshotBackend, error) {
+	return firstScreenshotCommand([]screenshotCommand{
+		{name: "powershell", build: func(path string, r *screenRegion) []string {
+			bounds := "$b = [System.Windows.Forms.SystemInformation]::VirtualScreen; $x = $b.X; $y = $b.Y; $w = $b.Width; $h = $b.Height"
+			if r != nil {
+				bounds = fmt.Sprintf("$x = %d; $y = %d; $w = %d; $h = %d", r.X, r.Y, r.Width, r.Height)
+			}
+			script := strings.Join([]string{
+				"Add-Type -AssemblyName System.Windows.Forms, System.Drawing",
+				bounds,
+				"$bmp = New-Object System.Drawing.Bitmap $w, $h",
+				"$g = [System.Drawing.Graphics]::FromImage($bmp)",
+				"$g.CopyFromScreen($x, $y, 0, 0, $bmp.Size)",
+				fmt.Sprintf("$bmp.Save('%s', [System.Drawing.Imaging.ImageFormat]::Png)", strings.ReplaceAll(path, "'", "''")),
+				"$g.Dispose(); $bmp.Dispose()",
+			}, "; ")
+			return []string{"powershell", "-NoProfile", "-NonInteractive", "-Command", script}
+		}},
+	}, "powershell not found")
+}
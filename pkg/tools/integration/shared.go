@@ -13,6 +13,12 @@ type (
 	AsyncCallback = toolshared.AsyncCallback
 )
 
+// errRateLimited marks upstream throttling; see toolshared.ErrRateLimited.
+var errRateLimited = toolshared.ErrRateLimited
+
+// keepFullResult keeps truncated output readable; see toolshared.KeepFullResult.
+var keepFullResult = toolshared.KeepFullResult
+
 func WithToolContext(ctx context.Context, channel, chatID string) context.Context {
 	return toolshared.WithToolContext(ctx, channel, chatID)
 }
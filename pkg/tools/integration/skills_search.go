@@ -76,6 +76,16 @@ func (t *FindSkillsTool) Execute(ctx context.Context, args map[string]any) *Tool
 	// Search all registries.
 	results, err := t.registryMgr.SearchAll(ctx, query, limit)
 	if err != nil {
+		// Fall back to older cached results so a registry outage does not
+		// break skill discovery entirely.
+		if t.cache != nil {
+			if stale, fetchedAt, ok := t.cache.GetStale(query); ok {
+				return SilentResult(fmt.Sprintf(
+					"Skill registries are unreachable (%v); showing cached results from %s.\n\n%s",
+					err, fetchedAt.Local().Format("2006-01-02 15:04"), formatSearchResults(query, stale, true),
+				))
+			}
+		}
 		return ErrorResult(fmt.Sprintf("skill search failed: %v", err))
 	}
 
@@ -3,6 +3,7 @@ package integrationtools
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -45,6 +46,25 @@ func TestFindSkillsToolCacheHit(t *testing.T) {
 	assert.Contains(t, result.ForLLM, "cached")
 }
 
+func TestFindSkillsToolFallsBackToStaleCacheWhenRegistriesFail(t *testing.T) {
+	cache := skills.NewSearchCache(10, time.Millisecond)
+	cache.Put("github", []skills.SearchResult{
+		{Slug: "github", Score: 0.9, RegistryName: "clawhub"},
+	})
+	time.Sleep(5 * time.Millisecond)
+
+	// A manager without registries fails every search.
+	tool := NewFindSkillsTool(skills.NewRegistryManager(), cache)
+	result := tool.Execute(context.Background(), map[string]any{
+		"query": "github",
+	})
+
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "unreachable")
+	assert.Contains(t, result.ForLLM, "showing cached results from")
+	assert.Contains(t, result.ForLLM, "**github**")
+}
+
 func TestFindSkillsToolParameters(t *testing.T) {
 	tool := NewFindSkillsTool(skills.NewRegistryManager(), nil)
 	params := tool.Parameters()
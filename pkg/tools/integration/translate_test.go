@@ -0,0 +1,155 @@
+package integrationtools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type translateRecordingProvider struct {
+	messages []providers.Message
+	model    string
+	opts     map[string]any
+	reply    string
+	err      error
+}
+
+func (p *translateRecordingProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.messages, p.model, p.opts = messages, model, opts
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &providers.LLMResponse{Content: p.reply}, nil
+}
+
+func (p *translateRecordingProvider) GetDefaultModel() string { return "mock" }
+
+func TestTranslateTool_LLMEngineReturnsOnlyTranslation(t *testing.T) {
+	provider := &translateRecordingProvider{reply: "  Guten Morgen!\n"}
+	tool, err := NewTranslateTool(TranslateToolOptions{Provider: provider, Model: "cheap-model"})
+	if err != nil {
+		t.Fatalf("NewTranslateTool() error = %v", err)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"text":        "Good morning!",
+		"target_lang": "de",
+		"source_lang": "en",
+	})
+	if result.IsError {
+		t.Fatalf("Execute() error = %s", result.ForLLM)
+	}
+	if result.ForLLM != "Guten Morgen!" {
+		t.Errorf("ForLLM = %q, want trimmed translation", result.ForLLM)
+	}
+	if provider.model != "cheap-model" {
+		t.Errorf("model = %q, want cheap-model", provider.model)
+	}
+	if len(provider.messages) != 2 || provider.messages[1].Content != "Good morning!" {
+		t.Fatalf("messages = %+v, want system prompt and the text", provider.messages)
+	}
+	if system := provider.messages[0].Content; !strings.Contains(system, "from en into de") {
+		t.Errorf("system prompt = %q, want source and target languages", system)
+	}
+	if provider.opts["temperature"] != 0.0 {
+		t.Errorf("temperature = %#v, want 0", provider.opts["temperature"])
+	}
+}
+
+func TestTranslateTool_ValidatesArguments(t *testing.T) {
+	provider := &translateRecordingProvider{reply: "x"}
+	tool, _ := NewTranslateTool(TranslateToolOptions{Provider: provider})
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"missing text", map[string]any{"target_lang": "de"}, "text is required"},
+		{"missing target", map[string]any{"text": "hi"}, "target_lang is required"},
+		{"too long", map[string]any{"text": strings.Repeat("a", maxTranslateTextLength+1), "target_lang": "de"}, "too long"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tool.Execute(context.Background(), tt.args)
+			if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+				t.Fatalf("Execute() = %q, want error containing %q", result.ForLLM, tt.want)
+			}
+		})
+	}
+	if provider.messages != nil {
+		t.Error("provider should not be called for invalid arguments")
+	}
+
+	provider.err = errors.New("rate limited")
+	result := tool.Execute(context.Background(), map[string]any{"text": "hi", "target_lang": "de"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "rate limited") {
+		t.Fatalf("Execute() = %q, want provider error", result.ForLLM)
+	}
+}
+
+func TestTranslateTool_LibreTranslateEngine(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/translate" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		if got["target"] == "xx" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"xx is not supported"}`))
+			return
+		}
+		w.Write([]byte(`{"translatedText":"Bonjour"}`))
+	}))
+	defer server.Close()
+
+	tool, err := NewTranslateTool(TranslateToolOptions{
+		Engine:               "libretranslate",
+		BaseURL:              server.URL + "/",
+		APIKey:               "secret",
+		PrivateHostWhitelist: []string{"127.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatalf("NewTranslateTool() error = %v", err)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"text": "Hello", "target_lang": "fr"})
+	if result.IsError || result.ForLLM != "Bonjour" {
+		t.Fatalf("Execute() = %+v, want Bonjour", result)
+	}
+	if got["source"] != "auto" || got["api_key"] != "secret" || got["q"] != "Hello" {
+		t.Errorf("request = %v", got)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"text": "Hello", "target_lang": "xx"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "xx is not supported") {
+		t.Fatalf("Execute() = %q, want API error", result.ForLLM)
+	}
+}
+
+func TestNewTranslateTool_ValidatesOptions(t *testing.T) {
+	if _, err := NewTranslateTool(TranslateToolOptions{}); err == nil {
+		t.Error("llm engine without a provider should fail")
+	}
+	if _, err := NewTranslateTool(TranslateToolOptions{Engine: "babelfish"}); err == nil {
+		t.Error("unknown engine should fail")
+	}
+	tool, err := NewTranslateTool(TranslateToolOptions{Engine: "LibreTranslate"})
+	if err != nil || tool.baseURL != defaultLibreTranslateURL {
+		t.Fatalf("NewTranslateTool() = %v, %v; want default LibreTranslate URL", tool, err)
+	}
+}
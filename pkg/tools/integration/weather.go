@@ -0,0 +1,572 @@
+package integrationtools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const (
+	weatherProviderOpenMeteo = "open_meteo"
+
+	defaultWeatherBaseURL         = "https://api.open-meteo.com/v1/forecast"
+	defaultWeatherCustomerBaseURL = "https://customer-api.open-meteo.com/v1/forecast"
+	defaultWeatherGeocodingURL    = "https://geocoding-api.open-meteo.com/v1/search"
+
+	defaultWeatherForecastDays = 3
+	maxWeatherForecastDays     = 16
+	maxWeatherResponseBytes    = 1 << 20
+	weatherGeocodeCandidates   = 10
+)
+
+// weatherCodes maps WMO weather interpretation codes, as returned by
+// Open-Meteo, to short descriptions.
+var weatherCodes = map[int]string{
+	0:  "clear sky",
+	1:  "mainly clear",
+	2:  "partly cloudy",
+	3:  "overcast",
+	45: "fog",
+	48: "depositing rime fog",
+	51: "light drizzle",
+	53: "moderate drizzle",
+	55: "dense drizzle",
+	56: "light freezing drizzle",
+	57: "dense freezing drizzle",
+	61: "slight rain",
+	63: "moderate rain",
+	65: "heavy rain",
+	66: "light freezing rain",
+	67: "heavy freezing rain",
+	71: "slight snowfall",
+	73: "moderate snowfall",
+	75: "heavy snowfall",
+	77: "snow grains",
+	80: "slight rain showers",
+	81: "moderate rain showers",
+	82: "violent rain showers",
+	85: "slight snow showers",
+	86: "heavy snow showers",
+	95: "thunderstorm",
+	96: "thunderstorm with slight hail",
+	99: "thunderstorm with heavy hail",
+}
+
+// WeatherToolOptions holds the settings for NewWeatherTool.
+type WeatherToolOptions struct {
+	Provider string
+	// APIKey is only needed for Open-Meteo's commercial plan; it switches the
+	// default endpoint to the customer API.
+	APIKey       string
+	BaseURL      string
+	GeocodingURL string
+	// Units is "metric" (default) or "imperial".
+	Units string
+
+	Proxy                string
+	PrivateHostWhitelist []string
+}
+
+func WeatherToolOptionsFromConfig(cfg *config.Config) WeatherToolOptions {
+	return WeatherToolOptions{
+		Provider:             cfg.Tools.Weather.Provider,
+		APIKey:               cfg.Tools.Weather.APIKey.String(),
+		BaseURL:              cfg.Tools.Weather.BaseURL,
+		GeocodingURL:         cfg.Tools.Weather.GeocodingURL,
+		Units:                cfg.Tools.Weather.Units,
+		Proxy:                cfg.Tools.Web.Proxy,
+		PrivateHostWhitelist: cfg.Tools.Web.PrivateHostWhitelist,
+	}
+}
+
+// WeatherTool reports current conditions and daily forecasts from
+// Open-Meteo, geocoding place names when no coordinates are given.
+type WeatherTool struct {
+	apiKey       string
+	baseURL      string
+	geocodingURL string
+	units        string
+	client       *http.Client
+}
+
+func NewWeatherTool(opts WeatherToolOptions) (*WeatherTool, error) {
+	provider := strings.ToLower(strings.TrimSpace(opts.Provider))
+	if provider != "" && provider != weatherProviderOpenMeteo {
+		return nil, fmt.Errorf("weather: unknown provider %q (expected %q)", opts.Provider, weatherProviderOpenMeteo)
+	}
+	units, err := normalizeWeatherUnits(opts.Units)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &WeatherTool{
+		apiKey:       strings.TrimSpace(opts.APIKey),
+		baseURL:      strings.TrimSpace(opts.BaseURL),
+		geocodingURL: strings.TrimSpace(opts.GeocodingURL),
+		units:        units,
+	}
+	if t.baseURL == "" {
+		t.baseURL = defaultWeatherBaseURL
+		if t.apiKey != "" {
+			t.baseURL = defaultWeatherCustomerBaseURL
+		}
+	}
+	if t.geocodingURL == "" {
+		t.geocodingURL = defaultWeatherGeocodingURL
+	}
+
+	whitelist, err := newPrivateHostWhitelist(opts.PrivateHostWhitelist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse weather private host whitelist: %w", err)
+	}
+	client, err := newSafeHTTPClient(opts.Proxy, whitelist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client for weather: %w", err)
+	}
+	t.client = client
+	return t, nil
+}
+
+func normalizeWeatherUnits(units string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(units)) {
+	case "", "metric":
+		return "metric", nil
+	case "imperial":
+		return "imperial", nil
+	default:
+		return "", fmt.Errorf("weather: unknown units %q (expected metric or imperial)", units)
+	}
+}
+
+func (t *WeatherTool) Name() string { return "weather" }
+
+func (t *WeatherTool) Description() string {
+	return "Get current weather conditions or a daily forecast for a place name (e.g. 'Berlin', " +
+		"'Portland, Oregon') or latitude/longitude. Returns JSON with temperatures, precipitation, " +
+		"wind, and a plain-language description."
+}
+
+func (t *WeatherTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"current", "forecast"},
+				"description": "current for conditions right now, forecast for daily highs and lows.",
+			},
+			"location": map[string]any{
+				"type": "string",
+				"description": "Place name, optionally followed by a comma and region or country " +
+					"to disambiguate. Use instead of latitude/longitude.",
+			},
+			"latitude": map[string]any{
+				"type":        "number",
+				"description": "Latitude in decimal degrees.",
+			},
+			"longitude": map[string]any{
+				"type":        "number",
+				"description": "Longitude in decimal degrees.",
+			},
+			"days": map[string]any{
+				"type": "integer",
+				"description": fmt.Sprintf(
+					"Forecast days including today (1-%d, default %d).",
+					maxWeatherForecastDays, defaultWeatherForecastDays,
+				),
+			},
+			"units": map[string]any{
+				"type":        "string",
+				"enum":        []string{"metric", "imperial"},
+				"description": "Override the configured units.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+// weatherPlace is a resolved location.
+type weatherPlace struct {
+	Name      string  `json:"name,omitempty"`
+	Region    string  `json:"region,omitempty"`
+	Country   string  `json:"country,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Timezone  string  `json:"timezone,omitempty"`
+}
+
+func (t *WeatherTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	action = strings.ToLower(strings.TrimSpace(action))
+	if action != "current" && action != "forecast" {
+		return ErrorResult(fmt.Sprintf("unknown action %q (expected current or forecast)", action))
+	}
+
+	units := t.units
+	if raw, _ := args["units"].(string); strings.TrimSpace(raw) != "" {
+		var err error
+		if units, err = normalizeWeatherUnits(raw); err != nil {
+			return ErrorResult(err.Error())
+		}
+	}
+
+	days := defaultWeatherForecastDays
+	if raw, ok := args["days"]; ok && action == "forecast" {
+		n, ok := numberArg(raw)
+		if !ok || n < 1 || n > maxWeatherForecastDays || n != float64(int(n)) {
+			return ErrorResult(fmt.Sprintf("days must be a whole number from 1 to %d", maxWeatherForecastDays))
+		}
+		days = int(n)
+	}
+
+	place, errResult := t.resolvePlace(ctx, args)
+	if errResult != nil {
+		return errResult
+	}
+
+	data, err := t.fetchForecast(ctx, place, action, units, days)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("weather lookup failed: %v", err)).WithError(err)
+	}
+
+	if place.Timezone == "" {
+		place.Timezone = data.Timezone
+	}
+	out := map[string]any{
+		"location": place,
+		"units":    data.Units(action),
+	}
+	if action == "current" {
+		out["current"] = data.CurrentReport()
+	} else {
+		out["forecast"] = data.DailyReport()
+	}
+	encoded, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to encode weather data: %v", err))
+	}
+	return NewToolResult(string(encoded))
+}
+
+func (t *WeatherTool) resolvePlace(ctx context.Context, args map[string]any) (*weatherPlace, *ToolResult) {
+	lat, hasLat := numberArg(args["latitude"])
+	lon, hasLon := numberArg(args["longitude"])
+	location, _ := args["location"].(string)
+	location = strings.TrimSpace(location)
+
+	if hasLat || hasLon {
+		if !hasLat || !hasLon {
+			return nil, ErrorResult("latitude and longitude must be given together")
+		}
+		if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			return nil, ErrorResult("latitude must be within [-90, 90] and longitude within [-180, 180]")
+		}
+		return &weatherPlace{Name: location, Latitude: lat, Longitude: lon}, nil
+	}
+	if location == "" {
+		return nil, ErrorResult("provide a location or latitude and longitude")
+	}
+
+	place, err := t.geocode(ctx, location)
+	if err != nil {
+		return nil, ErrorResult(fmt.Sprintf(
+			"could not look up location %q: %v. Try a nearby city name, or pass latitude and longitude.",
+			location, err,
+		)).WithError(err)
+	}
+	if place == nil {
+		return nil, ErrorResult(fmt.Sprintf(
+			"no place called %q was found. Try a different spelling or a nearby city, "+
+				"or pass latitude and longitude.",
+			location,
+		))
+	}
+	return place, nil
+}
+
+// geocode resolves a place name. Open-Meteo only matches the name itself,
+// so anything after the first comma ("Portland, Oregon") is used to pick
+// among the candidates by region or country instead. Without a qualifier the
+// most populous candidate wins, since the first hit is often a small
+// namesake. It returns nil when nothing matches.
+func (t *WeatherTool) geocode(ctx context.Context, location string) (*weatherPlace, error) {
+	name, qualifier, _ := strings.Cut(location, ",")
+	name = strings.TrimSpace(name)
+	qualifier = strings.ToLower(strings.TrimSpace(qualifier))
+	if name == "" {
+		return nil, nil
+	}
+
+	params := url.Values{}
+	params.Set("name", name)
+	params.Set("count", strconv.Itoa(weatherGeocodeCandidates))
+	params.Set("language", "en")
+	params.Set("format", "json")
+	if t.apiKey != "" {
+		params.Set("apikey", t.apiKey)
+	}
+
+	var resp struct {
+		Results []struct {
+			Name        string  `json:"name"`
+			Latitude    float64 `json:"latitude"`
+			Longitude   float64 `json:"longitude"`
+			Country     string  `json:"country"`
+			CountryCode string  `json:"country_code"`
+			Admin1      string  `json:"admin1"`
+			Timezone    string  `json:"timezone"`
+			Population  int64   `json:"population"`
+		} `json:"results"`
+	}
+	if err := t.getJSON(ctx, t.geocodingURL, params, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Results) == 0 {
+		return nil, nil
+	}
+
+	best := -1
+	for i, r := range resp.Results {
+		if qualifier != "" &&
+			!strings.Contains(strings.ToLower(r.Admin1), qualifier) &&
+			!strings.Contains(strings.ToLower(r.Country), qualifier) &&
+			!strings.EqualFold(r.CountryCode, qualifier) {
+			continue
+		}
+		if best < 0 || r.Population > resp.Results[best].Population {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil, nil
+	}
+	match := resp.Results[best]
+	return &weatherPlace{
+		Name:      match.Name,
+		Region:    match.Admin1,
+		Country:   match.Country,
+		Latitude:  match.Latitude,
+		Longitude: match.Longitude,
+		Timezone:  match.Timezone,
+	}, nil
+}
+
+func (t *WeatherTool) fetchForecast(
+	ctx context.Context,
+	place *weatherPlace,
+	action, units string,
+	days int,
+) (*openMeteoForecast, error) {
+	params := url.Values{}
+	params.Set("latitude", strconv.FormatFloat(place.Latitude, 'f', 4, 64))
+	params.Set("longitude", strconv.FormatFloat(place.Longitude, 'f', 4, 64))
+	params.Set("timezone", "auto")
+	if action == "current" {
+		params.Set("current", "temperature_2m,apparent_temperature,relative_humidity_2m,precipitation,"+
+			"weather_code,cloud_cover,wind_speed_10m,wind_direction_10m,wind_gusts_10m,is_day")
+	} else {
+		params.Set("daily", "weather_code,temperature_2m_max,temperature_2m_min,precipitation_sum,"+
+			"precipitation_probability_max,wind_speed_10m_max,sunrise,sunset")
+		params.Set("forecast_days", strconv.Itoa(days))
+	}
+	if units == "imperial" {
+		params.Set("temperature_unit", "fahrenheit")
+		params.Set("wind_speed_unit", "mph")
+		params.Set("precipitation_unit", "inch")
+	}
+	if t.apiKey != "" {
+		params.Set("apikey", t.apiKey)
+	}
+
+	var data openMeteoForecast
+	if err := t.getJSON(ctx, t.baseURL, params, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (t *WeatherTool) getJSON(ctx context.Context, endpoint string, params url.Values, dst any) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint: %w", err)
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxWeatherResponseBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Reason string `json:"reason"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Reason != "" {
+			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, apiErr.Reason)
+		}
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, dst); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// openMeteoForecast is the subset of the Open-Meteo forecast response the
+// tool reports.
+type openMeteoForecast struct {
+	Timezone     string            `json:"timezone"`
+	CurrentUnits map[string]string `json:"current_units"`
+	Current      *struct {
+		Time                string   `json:"time"`
+		Temperature         float64  `json:"temperature_2m"`
+		ApparentTemperature float64  `json:"apparent_temperature"`
+		RelativeHumidity    float64  `json:"relative_humidity_2m"`
+		Precipitation       float64  `json:"precipitation"`
+		WeatherCode         int      `json:"weather_code"`
+		CloudCover          float64  `json:"cloud_cover"`
+		WindSpeed           float64  `json:"wind_speed_10m"`
+		WindDirection       float64  `json:"wind_direction_10m"`
+		WindGusts           *float64 `json:"wind_gusts_10m"`
+		IsDay               int      `json:"is_day"`
+	} `json:"current"`
+	DailyUnits map[string]string `json:"daily_units"`
+	Daily      *struct {
+		Time                     []string   `json:"time"`
+		WeatherCode              []int      `json:"weather_code"`
+		TemperatureMax           []float64  `json:"temperature_2m_max"`
+		TemperatureMin           []float64  `json:"temperature_2m_min"`
+		PrecipitationSum         []float64  `json:"precipitation_sum"`
+		PrecipitationProbability []*float64 `json:"precipitation_probability_max"`
+		WindSpeedMax             []float64  `json:"wind_speed_10m_max"`
+		Sunrise                  []string   `json:"sunrise"`
+		Sunset                   []string   `json:"sunset"`
+	} `json:"daily"`
+}
+
+func (f *openMeteoForecast) Units(action string) map[string]string {
+	src, temp, wind, precip := f.CurrentUnits, "temperature_2m", "wind_speed_10m", "precipitation"
+	if action != "current" {
+		src, temp, wind, precip = f.DailyUnits, "temperature_2m_max", "wind_speed_10m_max", "precipitation_sum"
+	}
+	return map[string]string{
+		"temperature":   src[temp],
+		"wind_speed":    src[wind],
+		"precipitation": src[precip],
+	}
+}
+
+func (f *openMeteoForecast) CurrentReport() map[string]any {
+	c := f.Current
+	if c == nil {
+		return nil
+	}
+	report := map[string]any{
+		"time":                   c.Time,
+		"conditions":             weatherDescription(c.WeatherCode),
+		"temperature":            c.Temperature,
+		"feels_like":             c.ApparentTemperature,
+		"humidity_percent":       c.RelativeHumidity,
+		"precipitation":          c.Precipitation,
+		"cloud_cover_percent":    c.CloudCover,
+		"wind_speed":             c.WindSpeed,
+		"wind_direction":         compassDirection(c.WindDirection),
+		"wind_direction_degrees": c.WindDirection,
+		"is_day":                 c.IsDay == 1,
+	}
+	if c.WindGusts != nil {
+		report["wind_gusts"] = *c.WindGusts
+	}
+	return report
+}
+
+func (f *openMeteoForecast) DailyReport() []map[string]any {
+	d := f.Daily
+	if d == nil {
+		return nil
+	}
+	days := make([]map[string]any, 0, len(d.Time))
+	for i, date := range d.Time {
+		day := map[string]any{"date": date}
+		if i < len(d.WeatherCode) {
+			day["conditions"] = weatherDescription(d.WeatherCode[i])
+		}
+		if i < len(d.TemperatureMax) {
+			day["high"] = d.TemperatureMax[i]
+		}
+		if i < len(d.TemperatureMin) {
+			day["low"] = d.TemperatureMin[i]
+		}
+		if i < len(d.PrecipitationSum) {
+			day["precipitation"] = d.PrecipitationSum[i]
+		}
+		if i < len(d.PrecipitationProbability) && d.PrecipitationProbability[i] != nil {
+			day["precipitation_chance_percent"] = *d.PrecipitationProbability[i]
+		}
+		if i < len(d.WindSpeedMax) {
+			day["max_wind_speed"] = d.WindSpeedMax[i]
+		}
+		if i < len(d.Sunrise) {
+			day["sunrise"] = d.Sunrise[i]
+		}
+		if i < len(d.Sunset) {
+			day["sunset"] = d.Sunset[i]
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+func weatherDescription(code int) string {
+	if desc, ok := weatherCodes[code]; ok {
+		return desc
+	}
+	return fmt.Sprintf("unknown (code %d)", code)
+}
+
+func compassDirection(degrees float64) string {
+	points := []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+	idx := int((degrees+22.5)/45) % len(points)
+	if idx < 0 {
+		idx += len(points)
+	}
+	return points[idx]
+}
+
+// numberArg accepts the numeric forms a tool call may carry: JSON
+// numbers and numeric strings.
+func numberArg(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,205 @@
+package integrationtools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newWeatherTestServer serves canned Open-Meteo geocoding and forecast
+// responses and records the forecast query.
+func newWeatherTestServer(t *testing.T, forecastQuery *string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/search":
+			if r.URL.Query().Get("name") != "Portland" {
+				_, _ = w.Write([]byte(`{"generationtime_ms":0.1}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"results":[
+				{"name":"Portland","latitude":45.52,"longitude":-122.68,"country":"United States",
+				 "country_code":"US","admin1":"Oregon","timezone":"America/Los_Angeles","population":632309},
+				{"name":"Portland","latitude":43.66,"longitude":-70.26,"country":"United States",
+				 "country_code":"US","admin1":"Maine","timezone":"America/New_York","population":66881}
+			]}`))
+		case "/forecast":
+			*forecastQuery = r.URL.RawQuery
+			if r.URL.Query().Get("latitude") == "0.0000" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":true,"reason":"Cannot initialize WeatherVariable"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{
+				"timezone":"America/New_York",
+				"current_units":{"temperature_2m":"°C","wind_speed_10m":"km/h","precipitation":"mm"},
+				"current":{"time":"2026-03-04T14:30","temperature_2m":7.5,"apparent_temperature":4.1,
+					"relative_humidity_2m":81,"precipitation":0.2,"weather_code":61,"cloud_cover":100,
+					"wind_speed_10m":18.4,"wind_direction_10m":225,"wind_gusts_10m":35.6,"is_day":1},
+				"daily_units":{"temperature_2m_max":"°C","wind_speed_10m_max":"km/h","precipitation_sum":"mm"},
+				"daily":{"time":["2026-03-04","2026-03-05"],"weather_code":[61,2],
+					"temperature_2m_max":[9.1,12.3],"temperature_2m_min":[3.2,4.0],
+					"precipitation_sum":[4.5,0],"precipitation_probability_max":[90,null],
+					"wind_speed_10m_max":[25,14],"sunrise":["2026-03-04T06:32","2026-03-05T06:30"],
+					"sunset":["2026-03-04T17:52","2026-03-05T17:53"]}
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func newTestWeatherTool(t *testing.T, serverURL string) *WeatherTool {
+	t.Helper()
+	tool, err := NewWeatherTool(WeatherToolOptions{
+		BaseURL:              serverURL + "/forecast",
+		GeocodingURL:         serverURL + "/search",
+		PrivateHostWhitelist: []string{"127.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tool
+}
+
+func decodeWeatherResult(t *testing.T, result *ToolResult) map[string]any {
+	t.Helper()
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	var out map[string]any
+	if err := json.Unmarshal([]byte(result.ForLLM), &out); err != nil {
+		t.Fatalf("result is not JSON: %v\n%s", err, result.ForLLM)
+	}
+	return out
+}
+
+func TestWeatherTool_CurrentByLocation(t *testing.T) {
+	var query string
+	server := newWeatherTestServer(t, &query)
+	defer server.Close()
+	tool := newTestWeatherTool(t, server.URL)
+
+	out := decodeWeatherResult(t, tool.Execute(context.Background(), map[string]any{
+		"action":   "current",
+		"location": "Portland, Maine",
+	}))
+
+	location := out["location"].(map[string]any)
+	if location["region"] != "Maine" || location["timezone"] != "America/New_York" {
+		t.Fatalf("location = %v, want Portland, Maine", location)
+	}
+	if !strings.Contains(query, "latitude=43.6600") || !strings.Contains(query, "current=") {
+		t.Fatalf("forecast query = %q", query)
+	}
+	current := out["current"].(map[string]any)
+	if current["conditions"] != "slight rain" || current["wind_direction"] != "SW" || current["temperature"] != 7.5 {
+		t.Fatalf("current = %v", current)
+	}
+	if units := out["units"].(map[string]any); units["temperature"] != "°C" {
+		t.Fatalf("units = %v", units)
+	}
+}
+
+func TestWeatherTool_ForecastPrefersPopulousMatch(t *testing.T) {
+	var query string
+	server := newWeatherTestServer(t, &query)
+	defer server.Close()
+	tool := newTestWeatherTool(t, server.URL)
+
+	out := decodeWeatherResult(t, tool.Execute(context.Background(), map[string]any{
+		"action":   "forecast",
+		"location": "Portland",
+		"days":     float64(2),
+		"units":    "imperial",
+	}))
+
+	if region := out["location"].(map[string]any)["region"]; region != "Oregon" {
+		t.Fatalf("region = %v, want the most populous Portland", region)
+	}
+	for _, want := range []string{"forecast_days=2", "temperature_unit=fahrenheit", "wind_speed_unit=mph", "daily="} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("forecast query = %q, want %q", query, want)
+		}
+	}
+	days := out["forecast"].([]any)
+	if len(days) != 2 {
+		t.Fatalf("forecast has %d days, want 2", len(days))
+	}
+	first, second := days[0].(map[string]any), days[1].(map[string]any)
+	if first["high"] != 9.1 || first["precipitation_chance_percent"] != 90.0 || first["conditions"] != "slight rain" {
+		t.Fatalf("first day = %v", first)
+	}
+	if _, ok := second["precipitation_chance_percent"]; ok {
+		t.Fatalf("second day = %v, want missing probability omitted", second)
+	}
+}
+
+func TestWeatherTool_Errors(t *testing.T) {
+	var query string
+	server := newWeatherTestServer(t, &query)
+	defer server.Close()
+	tool := newTestWeatherTool(t, server.URL)
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"unknown action", map[string]any{"action": "radar"}, "unknown action"},
+		{"no location", map[string]any{"action": "current"}, "provide a location"},
+		{"half coordinates", map[string]any{"action": "current", "latitude": 1.0}, "given together"},
+		{"bad coordinates", map[string]any{"action": "current", "latitude": 95.0, "longitude": 0.0}, "within"},
+		{"bad days", map[string]any{"action": "forecast", "location": "Portland", "days": 30.0}, "days must be"},
+		{"bad units", map[string]any{"action": "current", "location": "Portland", "units": "kelvin"}, "unknown units"},
+		{"unknown place", map[string]any{"action": "current", "location": "Atlantis"}, "no place called"},
+		{"qualifier mismatch", map[string]any{"action": "current", "location": "Portland, Texas"}, "no place called"},
+		{"api error", map[string]any{"action": "current", "latitude": 0.0, "longitude": 0.0}, "Cannot initialize"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tool.Execute(context.Background(), tt.args)
+			if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+				t.Fatalf("Execute() = %q, want error containing %q", result.ForLLM, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeatherTool_RefusesPrivateEndpointWithoutWhitelist(t *testing.T) {
+	var query string
+	server := newWeatherTestServer(t, &query)
+	defer server.Close()
+
+	tool, err := NewWeatherTool(WeatherToolOptions{
+		BaseURL:      server.URL + "/forecast",
+		GeocodingURL: server.URL + "/search",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := tool.Execute(context.Background(), map[string]any{"action": "current", "location": "Portland"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "could not look up location") {
+		t.Fatalf("Execute() = %q, want geocoding refused", result.ForLLM)
+	}
+}
+
+func TestNewWeatherTool_Options(t *testing.T) {
+	if _, err := NewWeatherTool(WeatherToolOptions{Provider: "accuweather"}); err == nil {
+		t.Fatal("expected error for unknown provider")
+	}
+	if _, err := NewWeatherTool(WeatherToolOptions{Units: "kelvin"}); err == nil {
+		t.Fatal("expected error for unknown units")
+	}
+	tool, err := NewWeatherTool(WeatherToolOptions{APIKey: "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tool.baseURL != defaultWeatherCustomerBaseURL {
+		t.Fatalf("baseURL = %q, want customer API when a key is set", tool.baseURL)
+	}
+}
@@ -39,6 +39,17 @@ const (
 	maxRedirects    = 5
 )
 
+// defaultWebFetchContentTypes are the media types web_fetch downloads when
+// tools.web.fetch_content_types is not set.
+var defaultWebFetchContentTypes = []string{
+	"text/*",
+	"application/json",
+	"application/*+json",
+	"application/xml",
+	"application/*+xml",
+	"application/pdf",
+}
+
 // Pre-compiled regexes for HTML text extraction
 var (
 	reScript     = regexp.MustCompile(`<script[\s\S]*?</script>`)
@@ -1448,6 +1459,11 @@ type WebSearchTool struct {
 	provider         SearchProvider
 	maxResults       int
 	providerResolver func(query string) (SearchProvider, int)
+	// backendResolver lists every ready backend for a query, highest priority
+	// first. When set, the backends are queried concurrently.
+	backendResolver func(query string) []searchBackend
+	searchTimeout   time.Duration
+	mergeResults    bool
 }
 
 type WebSearchToolOptions struct {
@@ -1487,6 +1503,8 @@ type WebSearchToolOptions struct {
 	BaiduSearchMaxResults int
 	BaiduSearchEnabled    bool
 	Proxy                 string
+	SearchTimeout         time.Duration
+	MergeResults          bool
 }
 
 func WebSearchToolOptionsFromConfig(cfg *config.Config) WebSearchToolOptions {
@@ -1527,6 +1545,8 @@ func WebSearchToolOptionsFromConfig(cfg *config.Config) WebSearchToolOptions {
 		BaiduSearchMaxResults: cfg.Tools.Web.BaiduSearch.MaxResults,
 		BaiduSearchEnabled:    cfg.Tools.Web.BaiduSearch.Enabled,
 		Proxy:                 cfg.Tools.Web.Proxy,
+		SearchTimeout:         time.Duration(cfg.Tools.Web.SearchTimeout) * time.Second,
+		MergeResults:          cfg.Tools.Web.MergeResults,
 	}
 }
 
@@ -1858,10 +1878,8 @@ func prefersDuckDuckGoQuery(text string) bool {
 	return false
 }
 
-func (opts WebSearchToolOptions) buildProviderResolver() (func(query string) (SearchProvider, int), error) {
-	providersByName := make(map[string]SearchProvider, len(knownWebSearchProviders))
-	maxResultsByName := make(map[string]int, len(knownWebSearchProviders))
-
+func (opts WebSearchToolOptions) buildSearchBackends() (map[string]searchBackend, error) {
+	backends := make(map[string]searchBackend, len(knownWebSearchProviders))
 	for _, name := range knownWebSearchProviders {
 		if !opts.providerReady(name) {
 			continue
@@ -1873,27 +1891,26 @@ func (opts WebSearchToolOptions) buildProviderResolver() (func(query string) (Se
 		if provider == nil {
 			continue
 		}
-		providersByName[name] = provider
-		maxResultsByName[name] = maxResults
+		backends[name] = searchBackend{name: name, provider: provider, maxResults: maxResults}
 	}
+	return backends, nil
+}
 
-	return func(query string) (SearchProvider, int) {
+func NewWebSearchTool(opts WebSearchToolOptions) (*WebSearchTool, error) {
+	backends, err := opts.buildSearchBackends()
+	if err != nil {
+		return nil, err
+	}
+	resolver := func(query string) (SearchProvider, int) {
 		name, err := opts.resolveProviderName(query)
 		if err != nil {
 			return nil, 0
 		}
-		provider, ok := providersByName[name]
+		backend, ok := backends[name]
 		if !ok {
 			return nil, 0
 		}
-		return provider, maxResultsByName[name]
-	}, nil
-}
-
-func NewWebSearchTool(opts WebSearchToolOptions) (*WebSearchTool, error) {
-	resolver, err := opts.buildProviderResolver()
-	if err != nil {
-		return nil, err
+		return backend.provider, backend.maxResults
 	}
 	provider, maxResults := resolver("")
 	if provider == nil {
@@ -1904,6 +1921,18 @@ func NewWebSearchTool(opts WebSearchToolOptions) (*WebSearchTool, error) {
 		provider:         provider,
 		maxResults:       maxResults,
 		providerResolver: resolver,
+		backendResolver: func(query string) []searchBackend {
+			names := opts.orderedProviderNames(query)
+			ordered := make([]searchBackend, 0, len(names))
+			for _, name := range names {
+				if backend, ok := backends[name]; ok {
+					ordered = append(ordered, backend)
+				}
+			}
+			return ordered
+		},
+		searchTimeout: opts.SearchTimeout,
+		mergeResults:  opts.MergeResults,
 	}, nil
 }
 
@@ -1979,9 +2008,23 @@ func (t *WebSearchTool) Execute(ctx context.Context, args map[string]any) *ToolR
 		}
 	}
 
-	result, err := provider.Search(ctx, query, count, rangeCode)
+	backends := []searchBackend{{provider: provider, maxResults: maxResults}}
+	if t.backendResolver != nil {
+		if resolved := t.backendResolver(query); len(resolved) > 0 {
+			backends = resolved
+		}
+	}
+	requested := 0
+	if count64 > 0 && count64 <= 10 {
+		requested = int(count64)
+	}
+	if len(backends) == 1 {
+		requested = count
+	}
+
+	result, err := t.searchBackends(ctx, backends, query, requested, rangeCode)
 	if err != nil {
-		return ErrorResult(fmt.Sprintf("search failed: %v", err))
+		return ErrorResult(fmt.Sprintf("search failed: %v", err)).WithError(err).WithIdempotent()
 	}
 
 	return &ToolResult{
@@ -1997,6 +2040,10 @@ type WebFetchTool struct {
 	format          string
 	fetchLimitBytes int64
 	whitelist       *privateHostWhitelist
+	domainAllowlist []string
+	domainDenylist  []string
+	maxRedirects    int
+	contentTypes    []string
 }
 
 type privateHostWhitelist struct {
@@ -2039,38 +2086,102 @@ func NewWebFetchToolWithConfig(
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse web fetch private host whitelist: %w", err)
 	}
-	client, err := utils.CreateHTTPClient(proxy, fetchTimeout)
+	client, err := newSafeHTTPClient(proxy, whitelist)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP client for web fetch: %w", err)
 	}
-	if transport, ok := client.Transport.(*http.Transport); ok {
-		dialer := &net.Dialer{
-			Timeout:   15 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}
-		transport.DialContext = newSafeDialContext(dialer, whitelist)
-	}
-	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-		if len(via) >= maxRedirects {
-			return fmt.Errorf("stopped after %d redirects", maxRedirects)
-		}
-		if isObviousPrivateHost(req.URL.Hostname(), whitelist) {
-			return fmt.Errorf("redirect target is private or local network host")
-		}
-		allowConfiguredProxyFirstHop(req, client.Transport)
-		return nil
-	}
 	if fetchLimitBytes <= 0 {
 		fetchLimitBytes = 10 * 1024 * 1024 // Security Fallback
 	}
-	return &WebFetchTool{
+	t := &WebFetchTool{
 		maxChars:        maxChars,
 		proxy:           proxy,
 		client:          client,
 		format:          format,
 		fetchLimitBytes: fetchLimitBytes,
 		whitelist:       whitelist,
-	}, nil
+		maxRedirects:    maxRedirects,
+		contentTypes:    defaultWebFetchContentTypes,
+	}
+	// Every hop is held to the same rules as the URL the model asked for.
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > t.maxRedirects {
+			return fmt.Errorf("redirect blocked: more than %d redirects", t.maxRedirects)
+		}
+		if reason := t.domainBlockReason(req.URL.Hostname()); reason != "" {
+			return fmt.Errorf("redirect blocked: %s", reason)
+		}
+		return checkSafeRedirect(req, client.Transport, whitelist)
+	}
+	return t, nil
+}
+
+// SetMaxRedirects sets how many redirects a fetch may follow. A value of 0
+// or less keeps the default.
+func (t *WebFetchTool) SetMaxRedirects(n int) {
+	if n > 0 {
+		t.maxRedirects = n
+	}
+}
+
+// SetContentTypes sets the media types web_fetch downloads. Entries are exact
+// types ("application/json"), a whole top-level type ("text/*") or a
+// structured-syntax suffix ("application/*+json"). An empty list keeps the
+// default.
+func (t *WebFetchTool) SetContentTypes(types []string) {
+	normalized := make([]string, 0, len(types))
+	for _, ct := range types {
+		if ct = strings.ToLower(strings.TrimSpace(ct)); ct != "" {
+			normalized = append(normalized, ct)
+		}
+	}
+	if len(normalized) > 0 {
+		t.contentTypes = normalized
+	}
+}
+
+// contentTypeAllowed reports whether mediaType matches one of the configured
+// patterns. Responses without a Content-Type are let through; the fetch size
+// limit still bounds them.
+func (t *WebFetchTool) contentTypeAllowed(mediaType string) bool {
+	if mediaType == "" {
+		return true
+	}
+	mainType, subType, _ := strings.Cut(mediaType, "/")
+	for _, pattern := range t.contentTypes {
+		patMain, patSub, _ := strings.Cut(pattern, "/")
+		if patMain != "*" && patMain != mainType {
+			continue
+		}
+		switch {
+		case patSub == "*" || patSub == subType:
+			return true
+		case strings.HasPrefix(patSub, "*+") && strings.HasSuffix(subType, patSub[1:]):
+			return true
+		}
+	}
+	return false
+}
+
+// SetDomainPolicy restricts which domains web_fetch may request, redirect
+// targets included. A pattern matches a host exactly, or with a "*." prefix
+// the domain and all of its subdomains. A non-empty allowlist permits only
+// matching hosts; the denylist is checked on top of it.
+func (t *WebFetchTool) SetDomainPolicy(allowlist, denylist []string) {
+	t.domainAllowlist = normalizeAllowedHosts(allowlist)
+	t.domainDenylist = normalizeAllowedHosts(denylist)
+}
+
+// domainBlockReason explains why host may not be fetched, or returns "" when
+// the domain policy permits it.
+func (t *WebFetchTool) domainBlockReason(host string) string {
+	if hostMatchesAny(host, t.domainDenylist) {
+		return fmt.Sprintf("domain not permitted: %s matches tools.web.fetch_denylist", host)
+	}
+	if len(t.domainAllowlist) > 0 && !hostMatchesAny(host, t.domainAllowlist) {
+		return fmt.Sprintf("domain not permitted: %s is not in tools.web.fetch_allowlist", host)
+	}
+	return ""
 }
 
 func (t *WebFetchTool) Name() string {
@@ -2078,7 +2189,11 @@ func (t *WebFetchTool) Name() string {
 }
 
 func (t *WebFetchTool) Description() string {
-	return "Fetch a URL and extract readable content (HTML to text). Use this to get weather info, news, articles, or any web content."
+	desc := "Fetch a URL and extract readable content (HTML to text). Use this to get weather info, news, articles, or any web content."
+	if len(t.domainAllowlist) > 0 {
+		desc += " Allowed domains: " + strings.Join(t.domainAllowlist, ", ") + "."
+	}
+	return desc
 }
 
 func (t *WebFetchTool) Parameters() map[string]any {
@@ -2118,6 +2233,10 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 		return ErrorResult("missing domain in URL")
 	}
 
+	if reason := t.domainBlockReason(parsedURL.Hostname()); reason != "" {
+		return ErrorResult(reason)
+	}
+
 	// Lightweight pre-flight: block obvious localhost/literal-IP without DNS resolution.
 	// The real SSRF guard is newSafeDialContext at connect time.
 	hostname := parsedURL.Hostname()
@@ -2143,6 +2262,14 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 		if doErr != nil {
 			return nil, nil, fmt.Errorf("request failed: %w", doErr)
 		}
+		// Check the type before reading so a large binary is never downloaded.
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if !t.contentTypeAllowed(mediaType) {
+			return resp, nil, fmt.Errorf(
+				"content type %s is not allowed (tools.web.fetch_content_types permits %s)",
+				mediaType, strings.Join(t.contentTypes, ", "),
+			)
+		}
 		resp.Body = http.MaxBytesReader(nil, resp.Body, t.fetchLimitBytes)
 
 		b, readErr := io.ReadAll(resp.Body)
@@ -2164,7 +2291,7 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 				),
 			)
 		}
-		return ErrorResult(err.Error())
+		return ErrorResult(err.Error()).WithError(err).WithIdempotent()
 	}
 
 	// Cloudflare (and similar WAFs) signal bot challenges with 403 + cf-mitigated: challenge.
@@ -2188,7 +2315,7 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 					fmt.Sprintf("failed to read response: size exceeded %d bytes limit", t.fetchLimitBytes),
 				)
 			}
-			return ErrorResult(err2.Error())
+			return ErrorResult(err2.Error()).WithError(err2).WithIdempotent()
 		}
 	}
 
@@ -2263,7 +2390,7 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 
 	truncated := len(text) > maxChars
 	if truncated {
-		text = text[:maxChars] + "\n[Content truncated due to size limit]"
+		text = text[:maxChars] + "\n[Content truncated due to size limit]" + keepFullResult(text)
 	}
 
 	result := map[string]any{
@@ -2325,6 +2452,39 @@ func (t *WebFetchTool) extractText(htmlContent string) string {
 	return strings.Join(cleanLines, "\n")
 }
 
+// newSafeHTTPClient creates the client shared by the outbound web tools. Every
+// connection goes through newSafeDialContext and redirects to private or local
+// hosts are refused, so only hosts in whitelist can reach the internal network.
+func newSafeHTTPClient(proxy string, whitelist *privateHostWhitelist) (*http.Client, error) {
+	client, err := utils.CreateHTTPClient(proxy, fetchTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		dialer := &net.Dialer{
+			Timeout:   15 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}
+		transport.DialContext = newSafeDialContext(dialer, whitelist)
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return checkSafeRedirect(req, client.Transport, whitelist)
+	}
+	return client, nil
+}
+
+// checkSafeRedirect refuses a redirect to a private or local host.
+func checkSafeRedirect(req *http.Request, transport http.RoundTripper, whitelist *privateHostWhitelist) error {
+	if isObviousPrivateHost(req.URL.Hostname(), whitelist) {
+		return fmt.Errorf("redirect target is private or local network host")
+	}
+	allowConfiguredProxyFirstHop(req, transport)
+	return nil
+}
+
 // newSafeDialContext re-resolves DNS at connect time to mitigate DNS rebinding (TOCTOU)
 // where a hostname resolves to a public IP during pre-flight but a private IP at connect time.
 func newSafeDialContext(
@@ -0,0 +1,333 @@
+package integrationtools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// searchBackend is a ready search provider with the name it is configured
+// under and its result cap.
+type searchBackend struct {
+	name       string
+	provider   SearchProvider
+	maxResults int
+}
+
+type searchBackendResult struct {
+	text string
+	err  error
+}
+
+var (
+	reSearchResultItem = regexp.MustCompile(`^\d+\.\s+(.*)$`)
+
+	webSearchProviderLabels = map[string]string{
+		"sogou":        "Sogou",
+		"duckduckgo":   "DuckDuckGo",
+		"gemini":       "Gemini",
+		"brave":        "Brave",
+		"tavily":       "Tavily",
+		"kagi":         "Kagi",
+		"perplexity":   "Perplexity",
+		"searxng":      "SearXNG",
+		"glm_search":   "GLM Search",
+		"baidu_search": "Baidu Search",
+	}
+)
+
+func searchProviderLabel(name string) string {
+	if label, ok := webSearchProviderLabels[name]; ok {
+		return label
+	}
+	return name
+}
+
+// orderedProviderNames lists the ready providers for query, the one
+// resolveProviderName picks first and the rest in auto-selection order.
+func (opts WebSearchToolOptions) orderedProviderNames(query string) []string {
+	var names []string
+	add := func(name string) {
+		if opts.providerReady(name) && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if primary, err := opts.resolveProviderName(query); err == nil && primary != "" {
+		add(primary)
+	}
+	for _, name := range autoPrimaryWebSearchProviders {
+		add(name)
+	}
+	if prefersDuckDuckGoQuery(query) {
+		add("duckduckgo")
+		add("sogou")
+	} else {
+		add("sogou")
+		add("duckduckgo")
+	}
+	for _, name := range autoFallbackWebSearchProviders {
+		add(name)
+	}
+	return names
+}
+
+// searchBackends queries every backend concurrently, each bounded by
+// t.searchTimeout. With t.mergeResults the answers are combined; otherwise
+// the highest-priority backend that answers wins and the others are
+// cancelled. A backend failing, for bad credentials or a timeout, only fails
+// the search when every backend fails.
+func (t *WebSearchTool) searchBackends(
+	ctx context.Context,
+	backends []searchBackend,
+	query string,
+	count int,
+	rangeCode string,
+) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]searchBackendResult, len(backends))
+	done := make([]chan struct{}, len(backends))
+	for i, backend := range backends {
+		done[i] = make(chan struct{})
+		go func() {
+			defer close(done[i])
+			searchCtx := ctx
+			if t.searchTimeout > 0 {
+				var stop context.CancelFunc
+				searchCtx, stop = context.WithTimeout(ctx, t.searchTimeout)
+				defer stop()
+			}
+			n := backend.maxResults
+			if count > 0 {
+				n = min(count, backend.maxResults)
+			}
+			text, err := backend.provider.Search(searchCtx, query, n, rangeCode)
+			if err == nil && searchCtx.Err() != nil {
+				err = searchCtx.Err()
+			}
+			if t.searchTimeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s", t.searchTimeout)
+			}
+			results[i] = searchBackendResult{text: text, err: err}
+		}()
+	}
+
+	if !t.mergeResults {
+		for i := range backends {
+			<-done[i]
+			if results[i].err == nil {
+				return results[i].text, nil
+			}
+			logSearchBackendFailure(backends[i].name, results[i].err)
+		}
+		return "", searchBackendsError(backends, results)
+	}
+
+	for i := range backends {
+		<-done[i]
+	}
+	var ok bool
+	for i, result := range results {
+		if result.err != nil {
+			logSearchBackendFailure(backends[i].name, result.err)
+		} else {
+			ok = true
+		}
+	}
+	if !ok {
+		return "", searchBackendsError(backends, results)
+	}
+	limit := count
+	if limit <= 0 {
+		for _, backend := range backends {
+			limit = max(limit, backend.maxResults)
+		}
+	}
+	return mergeSearchResults(query, backends, results, limit), nil
+}
+
+func logSearchBackendFailure(name string, err error) {
+	logger.WarnCF("tool", "Web search backend failed", map[string]any{
+		"provider": name,
+		"error":    err.Error(),
+	})
+}
+
+// searchBackendsError reports why every backend failed. A lone backend's
+// error is returned as is.
+func searchBackendsError(backends []searchBackend, results []searchBackendResult) error {
+	if len(results) == 1 {
+		return results[0].err
+	}
+	errs := make([]error, 0, len(results))
+	for i, result := range results {
+		errs = append(errs, fmt.Errorf("%s: %w", searchProviderLabel(backends[i].name), result.err))
+	}
+	return errors.Join(errs...)
+}
+
+// mergedSearchResult is a result found by one or more backends.
+type mergedSearchResult struct {
+	SearchResultItem
+	sources []string
+}
+
+// mergeSearchResults combines the answers of the backends that succeeded:
+// results are taken in turn from each backend in priority order, up to
+// limit, and a URL found by several backends is listed once with each of
+// them as a source. Prose answers, such as Perplexity's, are kept under the
+// list, followed by the backends that failed; their errors are only logged.
+func mergeSearchResults(
+	query string,
+	backends []searchBackend,
+	results []searchBackendResult,
+	limit int,
+) string {
+	itemsByBackend := make([][]SearchResultItem, len(backends))
+	var summaries, failures []string
+	for i, result := range results {
+		label := searchProviderLabel(backends[i].name)
+		if result.err != nil {
+			failures = append(failures, label)
+			continue
+		}
+		items, summary := parseSearchResults(result.text)
+		itemsByBackend[i] = items
+		if summary != "" {
+			summaries = append(summaries, fmt.Sprintf("Answer from %s:\n%s", label, summary))
+		}
+	}
+
+	var merged []*mergedSearchResult
+	byURL := make(map[string]*mergedSearchResult)
+	for rank := 0; ; rank++ {
+		more := false
+		for i, items := range itemsByBackend {
+			if rank >= len(items) {
+				continue
+			}
+			more = true
+			item := items[rank]
+			label := searchProviderLabel(backends[i].name)
+			key := normalizeResultURL(item.URL)
+			if existing, ok := byURL[key]; ok {
+				if !slices.Contains(existing.sources, label) {
+					existing.sources = append(existing.sources, label)
+				}
+				if existing.Snippet == "" {
+					existing.Snippet = item.Snippet
+				}
+				continue
+			}
+			if len(merged) >= limit {
+				continue
+			}
+			result := &mergedSearchResult{SearchResultItem: item, sources: []string{label}}
+			byURL[key] = result
+			merged = append(merged, result)
+		}
+		if !more {
+			break
+		}
+	}
+
+	var succeeded []string
+	for i, result := range results {
+		if result.err == nil {
+			succeeded = append(succeeded, searchProviderLabel(backends[i].name))
+		}
+	}
+	var lines []string
+	if len(merged) == 0 && len(summaries) == 0 {
+		lines = append(lines, fmt.Sprintf("No results for: %s", query))
+	} else {
+		lines = append(lines, fmt.Sprintf("Results for: %s (via %s)", query, strings.Join(succeeded, ", ")))
+	}
+	for i, item := range merged {
+		title := item.Title
+		if title == "" {
+			title = item.URL
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s\n   %s", i+1, title, item.URL))
+		if item.Published != "" {
+			lines = append(lines, fmt.Sprintf("   Published: %s", item.Published))
+		}
+		if item.Snippet != "" {
+			lines = append(lines, fmt.Sprintf("   %s", item.Snippet))
+		}
+		lines = append(lines, fmt.Sprintf("   Sources: %s", strings.Join(item.sources, ", ")))
+	}
+	lines = append(lines, summaries...)
+	if len(failures) > 0 {
+		lines = append(lines, "Unavailable: "+strings.Join(failures, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseSearchResults reads back the numbered result list the providers
+// format ("1. Title", then the URL and snippet indented). Any other text,
+// such as an answer written by the provider, is returned as the summary.
+func parseSearchResults(text string) (items []SearchResultItem, summary string) {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	var prose []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if i == 0 && (strings.HasPrefix(trimmed, "Results for:") || strings.HasPrefix(trimmed, "No results")) {
+			continue
+		}
+		match := reSearchResultItem.FindStringSubmatch(line)
+		if match != nil && i+1 < len(lines) && isResultURLLine(lines[i+1]) {
+			item := SearchResultItem{
+				Title: strings.TrimSpace(match[1]),
+				URL:   strings.TrimSpace(lines[i+1]),
+			}
+			i++
+			var snippet []string
+			for i+1 < len(lines) && strings.HasPrefix(lines[i+1], "   ") {
+				i++
+				detail := strings.TrimSpace(lines[i])
+				if published, ok := strings.CutPrefix(detail, "Published: "); ok && item.Published == "" {
+					item.Published = published
+					continue
+				}
+				snippet = append(snippet, detail)
+			}
+			item.Snippet = strings.Join(snippet, " ")
+			items = append(items, item)
+			continue
+		}
+		prose = append(prose, line)
+	}
+	return items, strings.TrimSpace(strings.Join(prose, "\n"))
+}
+
+func isResultURLLine(line string) bool {
+	if !strings.HasPrefix(line, "   ") {
+		return false
+	}
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://")
+}
+
+// normalizeResultURL is the key results are deduplicated by: the URL
+// without scheme, "www.", fragment or trailing slash.
+func normalizeResultURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return strings.ToLower(strings.TrimSpace(raw))
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	key := host + strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
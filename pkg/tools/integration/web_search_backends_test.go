@@ -0,0 +1,219 @@
+package integrationtools
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// delayedSearchProvider answers after delay, or fails with err.
+type delayedSearchProvider struct {
+	delay  time.Duration
+	result string
+	err    error
+}
+
+func (p *delayedSearchProvider) Search(ctx context.Context, _ string, _ int, _ string) (string, error) {
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	return p.result, p.err
+}
+
+func delayedBackend(name string, delay time.Duration, result string, err error) searchBackend {
+	return searchBackend{
+		name:       name,
+		provider:   &delayedSearchProvider{delay: delay, result: result, err: err},
+		maxResults: 5,
+	}
+}
+
+func newBackendsTool(merge bool, timeout time.Duration, backends ...searchBackend) *WebSearchTool {
+	return &WebSearchTool{
+		provider:        backends[0].provider,
+		maxResults:      backends[0].maxResults,
+		backendResolver: func(string) []searchBackend { return backends },
+		searchTimeout:   timeout,
+		mergeResults:    merge,
+	}
+}
+
+const (
+	braveResults = "Results for: golang\n" +
+		"1. The Go Programming Language\n   https://go.dev/\n   Build simple, secure, scalable systems.\n" +
+		"2. Go by Example\n   https://gobyexample.com\n   Hands-on introduction to Go."
+	duckResults = "Results for: golang (via DuckDuckGo)\n" +
+		"1. Go (programming language) - Wikipedia\n   https://en.wikipedia.org/wiki/Go_(programming_language)\n" +
+		"   Go is a statically typed language.\n" +
+		"2. The Go Programming Language\n   https://www.go.dev\n   The official site."
+)
+
+func TestWebSearch_FirstAvailableSkipsSlowBackend(t *testing.T) {
+	tool := newBackendsTool(false, 50*time.Millisecond,
+		delayedBackend("brave", 5*time.Second, braveResults, nil),
+		delayedBackend("duckduckgo", 10*time.Millisecond, duckResults, nil),
+	)
+
+	start := time.Now()
+	result := tool.Execute(context.Background(), map[string]any{"query": "golang"})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("search waited %s for the slow backend", elapsed)
+	}
+	if result.ForLLM != duckResults {
+		t.Fatalf("ForLLM = %q, want the DuckDuckGo results", result.ForLLM)
+	}
+}
+
+func TestWebSearch_FirstAvailableKeepsPriorityOrder(t *testing.T) {
+	tool := newBackendsTool(false, 0,
+		delayedBackend("brave", 100*time.Millisecond, braveResults, nil),
+		delayedBackend("duckduckgo", 0, duckResults, nil),
+	)
+
+	result := tool.Execute(context.Background(), map[string]any{"query": "golang"})
+	if result.IsError || result.ForLLM != braveResults {
+		t.Fatalf("Execute() = %q, want the higher-priority Brave results", result.ForLLM)
+	}
+}
+
+func TestWebSearch_MergeDedupesAndAttributesSources(t *testing.T) {
+	tool := newBackendsTool(true, time.Second,
+		searchBackend{
+			name:       "tavily",
+			provider:   &delayedSearchProvider{err: errors.New("API error (status 401): invalid api key tvly-secret")},
+			maxResults: 5,
+		},
+		delayedBackend("brave", 30*time.Millisecond, braveResults, nil),
+		delayedBackend("duckduckgo", 0, duckResults, nil),
+	)
+
+	result := tool.Execute(context.Background(), map[string]any{"query": "golang", "count": 10})
+	if result.IsError {
+		t.Fatalf("an auth failure on one backend should not fail the search: %s", result.ForLLM)
+	}
+	want := strings.Join([]string{
+		"Results for: golang (via Brave, DuckDuckGo)",
+		"1. The Go Programming Language\n   https://go.dev/\n   Build simple, secure, scalable systems.\n" +
+			"   Sources: Brave, DuckDuckGo",
+		"2. Go (programming language) - Wikipedia\n   https://en.wikipedia.org/wiki/Go_(programming_language)\n" +
+			"   Go is a statically typed language.\n   Sources: DuckDuckGo",
+		"3. Go by Example\n   https://gobyexample.com\n   Hands-on introduction to Go.\n   Sources: Brave",
+		"Unavailable: Tavily",
+	}, "\n")
+	if result.ForLLM != want {
+		t.Fatalf("merged result =\n%s\nwant\n%s", result.ForLLM, want)
+	}
+	if strings.Contains(result.ForLLM, "tvly-secret") {
+		t.Fatal("backend error details leaked into the result")
+	}
+}
+
+func TestWebSearch_MergeRespectsCountAndKeepsAnswers(t *testing.T) {
+	tool := newBackendsTool(true, 0,
+		searchBackend{
+			name:       "perplexity",
+			provider:   &delayedSearchProvider{result: "Results for: golang (via Perplexity)\nGo is a language made at Google."},
+			maxResults: 5,
+		},
+		delayedBackend("brave", 0, braveResults, nil),
+		delayedBackend("duckduckgo", 0, duckResults, nil),
+	)
+
+	result := tool.Execute(context.Background(), map[string]any{"query": "golang", "count": 2})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if strings.Contains(result.ForLLM, "3. ") {
+		t.Fatalf("merged result has more than 2 items:\n%s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "Answer from Perplexity:\nGo is a language made at Google.") {
+		t.Fatalf("Perplexity answer missing:\n%s", result.ForLLM)
+	}
+}
+
+func TestWebSearch_AllBackendsFailing(t *testing.T) {
+	tool := newBackendsTool(true, 20*time.Millisecond,
+		delayedBackend("brave", time.Second, braveResults, nil),
+		delayedBackend("duckduckgo", 0, "", errors.New("request failed")),
+	)
+
+	result := tool.Execute(context.Background(), map[string]any{"query": "golang"})
+	if !result.IsError {
+		t.Fatalf("Execute() should fail when every backend fails, got %q", result.ForLLM)
+	}
+	for _, want := range []string{"Brave: timed out after 20ms", "DuckDuckGo: request failed"} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("error %q missing %q", result.ForLLM, want)
+		}
+	}
+}
+
+func TestParseSearchResults(t *testing.T) {
+	text := "Results for: golang (via Gemini Google Search)\n" +
+		"Go is an open source language.\n" +
+		"1. go.dev\n   https://go.dev/\n" +
+		"2. Kagi result\n   https://example.com/a#top\n   Published: 2024-05-01\n   first line\n   second line"
+	items, summary := parseSearchResults(text)
+	if summary != "Go is an open source language." {
+		t.Fatalf("summary = %q", summary)
+	}
+	if len(items) != 2 {
+		t.Fatalf("items = %+v, want 2", items)
+	}
+	if items[1].Published != "2024-05-01" || items[1].Snippet != "first line second line" {
+		t.Fatalf("second item = %+v", items[1])
+	}
+
+	_, summary = parseSearchResults("Results for: q (via Perplexity)\n1. Use goroutines.\n2. Use channels.")
+	if summary != "1. Use goroutines.\n2. Use channels." {
+		t.Fatalf("numbered prose should stay in the summary, got %q", summary)
+	}
+}
+
+func TestNormalizeResultURL(t *testing.T) {
+	same := []string{"https://www.Go.dev/doc/", "http://go.dev/doc", "https://go.dev/doc#install"}
+	for _, raw := range same[1:] {
+		if normalizeResultURL(raw) != normalizeResultURL(same[0]) {
+			t.Errorf("%s and %s should dedupe", raw, same[0])
+		}
+	}
+	if normalizeResultURL("https://go.dev/doc?page=2") == normalizeResultURL("https://go.dev/doc") {
+		t.Error("URLs with different queries should not dedupe")
+	}
+}
+
+func TestOrderedProviderNames(t *testing.T) {
+	opts := WebSearchToolOptions{
+		Provider:          "duckduckgo",
+		DuckDuckGoEnabled: true,
+		SogouEnabled:      true,
+		TavilyEnabled:     true,
+		TavilyAPIKeys:     []string{"key"},
+		BraveEnabled:      true, // no API key, so not ready
+	}
+	got := opts.orderedProviderNames("golang")
+	if want := []string{"duckduckgo", "tavily", "sogou"}; !slices.Equal(got, want) {
+		t.Fatalf("orderedProviderNames() = %v, want %v", got, want)
+	}
+}
+
+func TestWebSearchToolOptionsFromConfig_SearchTimeoutAndMerge(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Tools.Web.SearchTimeout = 3
+	cfg.Tools.Web.MergeResults = true
+
+	opts := WebSearchToolOptionsFromConfig(cfg)
+	if opts.SearchTimeout != 3*time.Second || !opts.MergeResults {
+		t.Fatalf("SearchTimeout = %s, MergeResults = %v", opts.SearchTimeout, opts.MergeResults)
+	}
+}
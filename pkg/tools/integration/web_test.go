@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -2390,3 +2391,175 @@ type roundTripFunc func(*http.Request) (*http.Response, error)
 func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 	return fn(req)
 }
+
+func TestWebFetchTool_DomainPolicy(t *testing.T) {
+	tool, err := NewWebFetchTool(50000, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("Failed to create web fetch tool: %v", err)
+	}
+	tool.SetDomainPolicy([]string{"*.Wikipedia.org", "docs.python.org."}, []string{"secret.wikipedia.org"})
+
+	tests := []struct {
+		host    string
+		blocked string
+	}{
+		{"wikipedia.org", ""},
+		{"en.wikipedia.org", ""},
+		{"DOCS.python.org", ""},
+		{"python.org", "fetch_allowlist"},
+		{"notwikipedia.org", "fetch_allowlist"},
+		{"secret.wikipedia.org", "fetch_denylist"},
+	}
+	for _, tt := range tests {
+		reason := tool.domainBlockReason(tt.host)
+		if tt.blocked == "" && reason != "" {
+			t.Errorf("domainBlockReason(%q) = %q, want allowed", tt.host, reason)
+		}
+		if tt.blocked != "" && !strings.Contains(reason, tt.blocked) {
+			t.Errorf("domainBlockReason(%q) = %q, want blocked by %s", tt.host, reason, tt.blocked)
+		}
+	}
+
+	if !strings.Contains(tool.Description(), "*.wikipedia.org") {
+		t.Errorf("Description() = %q, want the allowed domains listed", tool.Description())
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"url": "https://python.org/downloads"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "domain not permitted") {
+		t.Fatalf("Execute() = %q, want domain not permitted", result.ForLLM)
+	}
+}
+
+func TestWebFetchTool_DenylistOnly(t *testing.T) {
+	tool, err := NewWebFetchTool(50000, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("Failed to create web fetch tool: %v", err)
+	}
+	tool.SetDomainPolicy(nil, []string{"*.ads.example"})
+
+	if reason := tool.domainBlockReason("news.example"); reason != "" {
+		t.Errorf("domainBlockReason(news.example) = %q, want allowed without an allowlist", reason)
+	}
+	if reason := tool.domainBlockReason("x.ads.example"); !strings.Contains(reason, "fetch_denylist") {
+		t.Errorf("domainBlockReason(x.ads.example) = %q, want denied", reason)
+	}
+}
+
+func TestWebFetchTool_DomainPolicyAppliesToRedirects(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	var port string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "http://localhost:"+port+"/elsewhere", http.StatusFound)
+			return
+		}
+		w.Write([]byte("should not be reached"))
+	}))
+	defer server.Close()
+
+	host, port := serverHostAndPort(t, server.URL)
+	tool, err := NewWebFetchTool(50000, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("Failed to create web fetch tool: %v", err)
+	}
+	tool.SetDomainPolicy([]string{host}, nil)
+
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL})
+	if !result.IsError || !strings.Contains(result.ForLLM, "domain not permitted") {
+		t.Fatalf("Execute() = %q, want the redirect to localhost refused", result.ForLLM)
+	}
+}
+
+func TestWebFetchTool_MaxRedirects(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	// /hop/N redirects to /hop/N-1 until /hop/0, which serves the page.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hop/"))
+		if n > 0 {
+			http.Redirect(w, r, fmt.Sprintf("/hop/%d", n-1), http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("arrived"))
+	}))
+	defer server.Close()
+
+	tool, err := NewWebFetchTool(50000, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("Failed to create web fetch tool: %v", err)
+	}
+	tool.SetMaxRedirects(2)
+
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL + "/hop/2"})
+	if result.IsError || !strings.Contains(result.ForLLM, "arrived") {
+		t.Fatalf("two redirects: Execute() = %q, want the final page", result.ForLLM)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"url": server.URL + "/hop/3"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "redirect blocked: more than 2 redirects") {
+		t.Fatalf("three redirects: Execute() = %q, want redirect limit error", result.ForLLM)
+	}
+}
+
+func TestWebFetchTool_RedirectToPrivateHostRevalidated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	// The first hop is whitelisted; the metadata address it redirects to is not.
+	host, _ := serverHostAndPort(t, server.URL)
+	tool, err := NewWebFetchToolWithConfig(50000, "", format, testFetchLimit, []string{host})
+	if err != nil {
+		t.Fatalf("Failed to create web fetch tool: %v", err)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL})
+	if !result.IsError || !strings.Contains(result.ForLLM, "private or local network host") {
+		t.Fatalf("Execute() = %q, want the redirect target refused", result.ForLLM)
+	}
+}
+
+func TestWebFetchTool_ContentTypeFilter(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/video":
+			http.Redirect(w, r, "/video.mp4", http.StatusFound)
+		case "/video.mp4":
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Write(make([]byte, 64*1024))
+		case "/feed":
+			w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+			w.Write([]byte("<rss><channel><title>feed</title></channel></rss>"))
+		case "/data":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+
+	tool, err := NewWebFetchTool(50000, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("Failed to create web fetch tool: %v", err)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL + "/video"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "content type video/mp4 is not allowed") {
+		t.Fatalf("video: Execute() = %q, want content type rejected", result.ForLLM)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"url": server.URL + "/feed"})
+	if result.IsError || !strings.Contains(result.ForLLM, "feed") {
+		t.Fatalf("rss: Execute() = %q, want +xml types allowed by default", result.ForLLM)
+	}
+
+	tool.SetContentTypes([]string{" Text/* "})
+	result = tool.Execute(context.Background(), map[string]any{"url": server.URL + "/data"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "permits text/*") {
+		t.Fatalf("json with text/* only: Execute() = %q, want content type rejected", result.ForLLM)
+	}
+}
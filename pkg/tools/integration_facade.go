@@ -12,6 +12,7 @@ import (
 
 type (
 	SendCallbackWithContext  = integrationtools.SendCallbackWithContext
+	MessageOpCallback        = integrationtools.MessageOpCallback
 	ReactionCallback         = integrationtools.ReactionCallback
 	MCPManager               = integrationtools.MCPManager
 	MCPTool                  = integrationtools.MCPTool
@@ -36,6 +37,22 @@ type (
 	WebSearchTool            = integrationtools.WebSearchTool
 	WebSearchToolOptions     = integrationtools.WebSearchToolOptions
 	WebFetchTool             = integrationtools.WebFetchTool
+	HTTPRequestTool          = integrationtools.HTTPRequestTool
+	ClipboardTool            = integrationtools.ClipboardTool
+	ScreenshotTool           = integrationtools.ScreenshotTool
+	ImageGenTool             = integrationtools.ImageGenTool
+	ImageGenToolOptions      = integrationtools.ImageGenToolOptions
+	OCRTool                  = integrationtools.OCRTool
+	OCRToolOptions           = integrationtools.OCRToolOptions
+	WeatherTool              = integrationtools.WeatherTool
+	WeatherToolOptions       = integrationtools.WeatherToolOptions
+	MarketTool               = integrationtools.MarketTool
+	MarketToolOptions        = integrationtools.MarketToolOptions
+	EmailSendTool            = integrationtools.EmailSendTool
+	EmailSendToolOptions     = integrationtools.EmailSendToolOptions
+	TranslateTool            = integrationtools.TranslateTool
+	TranslateToolOptions     = integrationtools.TranslateToolOptions
+	ExternalTool             = integrationtools.ExternalTool
 )
 
 func NewMCPTool(manager MCPManager, serverName string, tool *mcp.Tool) *MCPTool {
@@ -105,3 +122,64 @@ func NewWebFetchToolWithConfig(
 ) (*WebFetchTool, error) {
 	return integrationtools.NewWebFetchToolWithConfig(maxChars, proxy, format, fetchLimitBytes, privateHostWhitelist)
 }
+
+func NewHTTPRequestTool(
+	proxy string,
+	allowedHosts []string,
+	privateHostWhitelist []string,
+	maxResponseBytes int64,
+) (*HTTPRequestTool, error) {
+	return integrationtools.NewHTTPRequestTool(proxy, allowedHosts, privateHostWhitelist, maxResponseBytes)
+}
+
+func NewClipboardTool() *ClipboardTool {
+	return integrationtools.NewClipboardTool()
+}
+
+func NewScreenshotTool() *ScreenshotTool {
+	return integrationtools.NewScreenshotTool()
+}
+
+func NewImageGenTool(opts ImageGenToolOptions) (*ImageGenTool, error) {
+	return integrationtools.NewImageGenTool(opts)
+}
+
+func ImageGenToolOptionsFromConfig(cfg *config.Config) ImageGenToolOptions {
+	return integrationtools.ImageGenToolOptionsFromConfig(cfg)
+}
+
+func NewOCRTool(opts OCRToolOptions) (*OCRTool, error) {
+	return integrationtools.NewOCRTool(opts)
+}
+
+func NewWeatherTool(opts WeatherToolOptions) (*WeatherTool, error) {
+	return integrationtools.NewWeatherTool(opts)
+}
+
+func WeatherToolOptionsFromConfig(cfg *config.Config) WeatherToolOptions {
+	return integrationtools.WeatherToolOptionsFromConfig(cfg)
+}
+
+func NewMarketTool(opts MarketToolOptions) (*MarketTool, error) {
+	return integrationtools.NewMarketTool(opts)
+}
+
+func MarketToolOptionsFromConfig(cfg *config.Config) MarketToolOptions {
+	return integrationtools.MarketToolOptionsFromConfig(cfg)
+}
+
+func NewEmailSendTool(opts EmailSendToolOptions) (*EmailSendTool, error) {
+	return integrationtools.NewEmailSendTool(opts)
+}
+
+func NewTranslateTool(opts TranslateToolOptions) (*TranslateTool, error) {
+	return integrationtools.NewTranslateTool(opts)
+}
+
+func TranslateToolOptionsFromConfig(cfg *config.Config) TranslateToolOptions {
+	return integrationtools.TranslateToolOptionsFromConfig(cfg)
+}
+
+func NewExternalTool(cfg config.ExternalToolConfig, workspace string) (*ExternalTool, error) {
+	return integrationtools.NewExternalTool(cfg, workspace)
+}
@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// rawArgumentsKey is where providers keep tool call arguments they could not
+// decode as JSON.
+const rawArgumentsKey = "raw"
+
+// MalformedArguments reports whether args is the placeholder providers
+// produce for arguments that were not valid JSON, and returns the raw text.
+// Tools that declare their own "raw" parameter are never treated as malformed.
+func MalformedArguments(schema map[string]any, args map[string]any) (string, bool) {
+	if len(args) != 1 {
+		return "", false
+	}
+	raw, ok := args[rawArgumentsKey].(string)
+	if !ok {
+		return "", false
+	}
+	if props, ok := schema["properties"].(map[string]any); ok {
+		if _, declared := props[rawArgumentsKey]; declared {
+			return "", false
+		}
+	}
+	return raw, true
+}
+
+// RepairArguments tries to turn almost-JSON tool arguments into a JSON
+// object. It strips markdown code fences and text around the object, turns
+// single-quoted strings into double-quoted ones, escapes raw control
+// characters inside strings and drops trailing commas. It returns the decoded
+// arguments and the fixes it applied, or the decode error if nothing helped.
+func RepairArguments(raw string) (map[string]any, []string, error) {
+	text := strings.TrimSpace(raw)
+	var fixes []string
+
+	decode := func() (map[string]any, error) {
+		var args map[string]any
+		if err := json.Unmarshal([]byte(text), &args); err != nil {
+			return nil, err
+		}
+		if args == nil {
+			return nil, errors.New("arguments are not a JSON object")
+		}
+		return args, nil
+	}
+
+	args, err := decode()
+	if err == nil {
+		return args, nil, nil
+	}
+	if unfenced, ok := stripCodeFence(text); ok {
+		text = unfenced
+		fixes = append(fixes, "code fence")
+		if args, err = decode(); err == nil {
+			return args, fixes, nil
+		}
+	}
+	if start, end := strings.IndexByte(text, '{'), strings.LastIndexByte(text, '}'); start >= 0 && end > start &&
+		(start > 0 || end < len(text)-1) {
+		text = text[start : end+1]
+		fixes = append(fixes, "surrounding text")
+		if args, err = decode(); err == nil {
+			return args, fixes, nil
+		}
+	}
+	if rewritten, applied := rewriteLooseJSON(text); len(applied) > 0 {
+		text = rewritten
+		fixes = append(fixes, applied...)
+		if args, err = decode(); err == nil {
+			return args, fixes, nil
+		}
+	}
+	return nil, fixes, err
+}
+
+// stripCodeFence removes a ```json ... ``` wrapper.
+func stripCodeFence(text string) (string, bool) {
+	if !strings.HasPrefix(text, "```") {
+		return text, false
+	}
+	body := strings.TrimPrefix(text, "```")
+	if nl := strings.IndexByte(body, '\n'); nl >= 0 {
+		body = body[nl+1:]
+	} else {
+		body = strings.TrimPrefix(body, "json")
+	}
+	body = strings.TrimSpace(body)
+	body = strings.TrimSuffix(body, "```")
+	return strings.TrimSpace(body), true
+}
+
+// rewriteLooseJSON fixes string quoting, unescaped control characters and
+// trailing commas in one pass, keeping track of whether it is inside a
+// string. It returns the names of the fixes it had to make.
+func rewriteLooseJSON(text string) (string, []string) {
+	var out strings.Builder
+	out.Grow(len(text) + 16)
+	applied := make(map[string]bool)
+	var quote rune // 0 outside a string, otherwise the opening quote
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		if quote == 0 {
+			switch ch {
+			case '\'':
+				quote = ch
+				applied["single quotes"] = true
+				out.WriteRune('"')
+			case '"':
+				quote = ch
+				out.WriteRune(ch)
+			case ',':
+				j := i + 1
+				for j < len(runes) && strings.ContainsRune(" \t\r\n", runes[j]) {
+					j++
+				}
+				if j < len(runes) && (runes[j] == '}' || runes[j] == ']') {
+					applied["trailing comma"] = true
+					continue
+				}
+				out.WriteRune(ch)
+			default:
+				out.WriteRune(ch)
+			}
+			continue
+		}
+
+		switch {
+		case ch == '\\' && i+1 < len(runes):
+			i++
+			if quote == '\'' && runes[i] == '\'' {
+				out.WriteRune('\'')
+			} else {
+				out.WriteRune(ch)
+				out.WriteRune(runes[i])
+			}
+		case ch == quote:
+			quote = 0
+			out.WriteRune('"')
+		case ch == '"':
+			out.WriteString(`\"`)
+		case ch == '\n':
+			applied["unescaped newline"] = true
+			out.WriteString(`\n`)
+		case ch == '\r':
+			applied["unescaped newline"] = true
+			out.WriteString(`\r`)
+		case ch == '\t':
+			applied["unescaped newline"] = true
+			out.WriteString(`\t`)
+		default:
+			out.WriteRune(ch)
+		}
+	}
+
+	var fixes []string
+	for _, fix := range []string{"single quotes", "unescaped newline", "trailing comma"} {
+		if applied[fix] {
+			fixes = append(fixes, fix)
+		}
+	}
+	return out.String(), fixes
+}
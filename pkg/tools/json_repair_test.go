@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRepairArguments(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   string
+		want  map[string]any
+		fixes []string
+	}{
+		{
+			name: "valid",
+			raw:  `{"path": "a.txt"}`,
+			want: map[string]any{"path": "a.txt"},
+		},
+		{
+			name:  "trailing comma",
+			raw:   `{"path": "a.txt", "lines": [1, 2,],}`,
+			want:  map[string]any{"path": "a.txt", "lines": []any{1.0, 2.0}},
+			fixes: []string{"trailing comma"},
+		},
+		{
+			name:  "single quotes",
+			raw:   `{'path': 'it\'s "here".txt'}`,
+			want:  map[string]any{"path": `it's "here".txt`},
+			fixes: []string{"single quotes"},
+		},
+		{
+			name:  "unescaped newline",
+			raw:   "{\"content\": \"line one\nline two\"}",
+			want:  map[string]any{"content": "line one\nline two"},
+			fixes: []string{"unescaped newline"},
+		},
+		{
+			name:  "code fence",
+			raw:   "```json\n{\"path\": \"a.txt\"}\n```",
+			want:  map[string]any{"path": "a.txt"},
+			fixes: []string{"code fence"},
+		},
+		{
+			name:  "surrounding text",
+			raw:   `Sure! {"path": "a.txt"} Hope that helps.`,
+			want:  map[string]any{"path": "a.txt"},
+			fixes: []string{"surrounding text"},
+		},
+		{
+			name:  "several at once",
+			raw:   "```\n{'path': 'a.txt', 'body': 'x\ny',}\n```",
+			want:  map[string]any{"path": "a.txt", "body": "x\ny"},
+			fixes: []string{"code fence", "single quotes", "unescaped newline", "trailing comma"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fixes, err := RepairArguments(tt.raw)
+			if err != nil {
+				t.Fatalf("RepairArguments(%q) error = %v", tt.raw, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("args = %#v, want %#v", got, tt.want)
+			}
+			if !reflect.DeepEqual(fixes, tt.fixes) {
+				t.Errorf("fixes = %v, want %v", fixes, tt.fixes)
+			}
+		})
+	}
+}
+
+func TestRepairArguments_Unfixable(t *testing.T) {
+	for _, raw := range []string{"path=a.txt", `["a.txt"]`, "null", `{"path": }`} {
+		if args, _, err := RepairArguments(raw); err == nil {
+			t.Errorf("RepairArguments(%q) = %v, want error", raw, args)
+		}
+	}
+}
+
+func TestMalformedArguments(t *testing.T) {
+	schema := map[string]any{"properties": map[string]any{"path": map[string]any{"type": "string"}}}
+	if raw, ok := MalformedArguments(schema, map[string]any{"raw": "{bad"}); !ok || raw != "{bad" {
+		t.Errorf("MalformedArguments = %q, %v; want the raw text", raw, ok)
+	}
+	if _, ok := MalformedArguments(schema, map[string]any{"path": "a.txt"}); ok {
+		t.Error("well-formed arguments reported as malformed")
+	}
+	declared := map[string]any{"properties": map[string]any{"raw": map[string]any{"type": "string"}}}
+	if _, ok := MalformedArguments(declared, map[string]any{"raw": "text"}); ok {
+		t.Error("a declared raw parameter must not be treated as malformed")
+	}
+}
+
+func TestToolRegistry_RejectsMalformedArguments(t *testing.T) {
+	r := NewToolRegistry()
+	tool := newMockTool("read", "reads")
+	r.Register(tool)
+
+	result := r.ExecuteWithContext(context.Background(), "read", map[string]any{"raw": "{bad"}, "cli", "direct", nil)
+	if !result.IsError || result.ErrorCode != ErrorCodeInvalid {
+		t.Fatalf("result = %+v, want an invalid-arguments error", result)
+	}
+	if !strings.Contains(result.ForLLM, "reissue the call") {
+		t.Errorf("ForLLM = %q, want a reissue request", result.ForLLM)
+	}
+}
@@ -0,0 +1,192 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// Plan step statuses.
+const (
+	PlanStatusPending    = "pending"
+	PlanStatusInProgress = "in_progress"
+	PlanStatusDone       = "done"
+	PlanStatusSkipped    = "skipped"
+)
+
+const (
+	maxPlanSteps    = 30
+	maxPlanStepText = 200
+)
+
+var planStatuses = []string{PlanStatusPending, PlanStatusInProgress, PlanStatusDone, PlanStatusSkipped}
+
+// PlanStore keeps one task plan per session. state.Manager implements it.
+type PlanStore interface {
+	GetPlan(sessionKey string) []state.PlanStep
+	SetPlan(sessionKey string, steps []state.PlanStep) error
+}
+
+// PlanTool lets the agent keep an explicit checklist for a multi-step task.
+// Plans are stored per session in the workspace state, so they survive
+// context compression and restarts; the agent loop shows the current plan
+// in the prompt on every turn.
+type PlanTool struct {
+	store PlanStore
+}
+
+// NewPlanTool creates a PlanTool that keeps plans in store.
+func NewPlanTool(store PlanStore) *PlanTool {
+	return &PlanTool{store: store}
+}
+
+func (t *PlanTool) Name() string {
+	return "plan"
+}
+
+func (t *PlanTool) Description() string {
+	return "Keep a checklist for a task that takes several steps. create replaces the plan with new steps, " +
+		"update marks a step pending, in_progress, done or skipped (or rewords it), show prints it, and " +
+		"clear removes it when the task is finished or abandoned. The current plan is shown to you every " +
+		"turn; keep it up to date as you work."
+}
+
+func (t *PlanTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"create", "update", "show", "clear"},
+				"description": "create starts a new plan, update changes one step, show prints the plan, clear removes it.",
+			},
+			"steps": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": fmt.Sprintf("Step descriptions in order, at most %d. Required for create.", maxPlanSteps),
+			},
+			"step": map[string]any{
+				"type":        "integer",
+				"description": "1-based number of the step to update. Required for update.",
+			},
+			"status": map[string]any{
+				"type":        "string",
+				"enum":        planStatuses,
+				"description": "New status of the step.",
+			},
+			"text": map[string]any{
+				"type":        "string",
+				"description": "New description of the step, to reword it.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *PlanTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	sessionKey := ToolSessionKey(ctx)
+	if sessionKey == "" {
+		return ErrorResult("no session context. Use this tool in an active conversation.")
+	}
+	action, _ := args["action"].(string)
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case "create":
+		return t.create(sessionKey, args)
+	case "update":
+		return t.update(sessionKey, args)
+	case "show":
+		return SilentResult(FormatPlan(t.store.GetPlan(sessionKey)))
+	case "clear":
+		if err := t.store.SetPlan(sessionKey, nil); err != nil {
+			return ErrorResult(fmt.Sprintf("Error clearing plan: %v", err))
+		}
+		return SilentResult("Plan cleared")
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action %q (expected create, update, show, or clear)", action))
+	}
+}
+
+func (t *PlanTool) create(sessionKey string, args map[string]any) *ToolResult {
+	raw, _ := args["steps"].([]any)
+	var steps []state.PlanStep
+	for _, item := range raw {
+		text, _ := item.(string)
+		if text = strings.TrimSpace(text); text == "" {
+			continue
+		}
+		steps = append(steps, state.PlanStep{Text: utils.Truncate(text, maxPlanStepText), Status: PlanStatusPending})
+	}
+	if len(steps) == 0 {
+		return ErrorResult("steps is required for create and must contain at least one non-empty step")
+	}
+	if len(steps) > maxPlanSteps {
+		return ErrorResult(fmt.Sprintf("a plan can have at most %d steps; group related work", maxPlanSteps))
+	}
+	if err := t.store.SetPlan(sessionKey, steps); err != nil {
+		return ErrorResult(fmt.Sprintf("Error saving plan: %v", err))
+	}
+	return SilentResult("Plan created.\n" + FormatPlan(steps))
+}
+
+func (t *PlanTool) update(sessionKey string, args map[string]any) *ToolResult {
+	steps := t.store.GetPlan(sessionKey)
+	if len(steps) == 0 {
+		return ErrorResult("there is no plan in this session; create one first")
+	}
+	n, ok := args["step"].(float64)
+	if !ok || n != float64(int(n)) || int(n) < 1 || int(n) > len(steps) {
+		return ErrorResult(fmt.Sprintf("step must be a step number from 1 to %d", len(steps)))
+	}
+	idx := int(n) - 1
+
+	status, _ := args["status"].(string)
+	status = strings.ToLower(strings.TrimSpace(status))
+	text, _ := args["text"].(string)
+	text = strings.TrimSpace(text)
+	if status == "" && text == "" {
+		return ErrorResult("update needs a status or text")
+	}
+	if status != "" {
+		if !slices.Contains(planStatuses, status) {
+			return ErrorResult(fmt.Sprintf("unknown status %q (expected %s)", status, strings.Join(planStatuses, ", ")))
+		}
+		steps[idx].Status = status
+	}
+	if text != "" {
+		steps[idx].Text = utils.Truncate(text, maxPlanStepText)
+	}
+	if err := t.store.SetPlan(sessionKey, steps); err != nil {
+		return ErrorResult(fmt.Sprintf("Error saving plan: %v", err))
+	}
+	return SilentResult(fmt.Sprintf("Step %d updated.\n%s", idx+1, FormatPlan(steps)))
+}
+
+// FormatPlan renders a plan as a numbered checklist, or a note that there is
+// none.
+func FormatPlan(steps []state.PlanStep) string {
+	if len(steps) == 0 {
+		return "No plan"
+	}
+	done := 0
+	var sb strings.Builder
+	for i, step := range steps {
+		mark := "[ ]"
+		switch step.Status {
+		case PlanStatusInProgress:
+			mark = "[~]"
+		case PlanStatusDone:
+			mark = "[x]"
+			done++
+		case PlanStatusSkipped:
+			mark = "[-]"
+			done++
+		}
+		fmt.Fprintf(&sb, "%d. %s %s\n", i+1, mark, step.Text)
+	}
+	fmt.Fprintf(&sb, "(%d of %d steps finished)", done, len(steps))
+	return sb.String()
+}
@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func TestPlanTool(t *testing.T) {
+	store := state.NewManager(t.TempDir())
+	tool := NewPlanTool(store)
+	ctx := WithToolSessionContext(context.Background(), "main", "session-1", nil)
+
+	if res := tool.Execute(context.Background(), map[string]any{"action": "show"}); !res.IsError {
+		t.Fatal("expected an error without a session")
+	}
+	if res := tool.Execute(ctx, map[string]any{"action": "update", "step": float64(1), "status": "done"}); !res.IsError {
+		t.Fatal("expected an error updating a missing plan")
+	}
+
+	res := tool.Execute(ctx, map[string]any{
+		"action": "create",
+		"steps":  []any{"read the spec", " ", "write the code", "run the tests"},
+	})
+	if res.IsError {
+		t.Fatalf("create: %s", res.ForLLM)
+	}
+	if got := store.GetPlan("session-1"); len(got) != 3 || got[0].Status != PlanStatusPending {
+		t.Fatalf("stored plan = %v", got)
+	}
+
+	res = tool.Execute(ctx, map[string]any{"action": "update", "step": float64(1), "status": "done"})
+	if res.IsError {
+		t.Fatalf("update: %s", res.ForLLM)
+	}
+	res = tool.Execute(ctx, map[string]any{"action": "update", "step": float64(2), "status": "in_progress"})
+	if !strings.Contains(res.ForLLM, "1. [x] read the spec") || !strings.Contains(res.ForLLM, "2. [~] write the code") ||
+		!strings.Contains(res.ForLLM, "(1 of 3 steps finished)") {
+		t.Fatalf("update result = %q", res.ForLLM)
+	}
+
+	for _, args := range []map[string]any{
+		{"action": "update", "step": float64(4), "status": "done"},
+		{"action": "update", "step": float64(1), "status": "finished"},
+		{"action": "update", "step": float64(1)},
+		{"action": "create"},
+	} {
+		if res := tool.Execute(ctx, args); !res.IsError {
+			t.Errorf("Execute(%v) succeeded, want error", args)
+		}
+	}
+
+	if res := tool.Execute(ctx, map[string]any{"action": "clear"}); res.IsError {
+		t.Fatalf("clear: %s", res.ForLLM)
+	}
+	if res := tool.Execute(ctx, map[string]any{"action": "show"}); res.ForLLM != "No plan" {
+		t.Fatalf("show after clear = %q", res.ForLLM)
+	}
+}
@@ -0,0 +1,433 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/isolation"
+)
+
+const (
+	pythonDefaultTimeout       = 30 * time.Second
+	pythonDefaultMemoryLimitMB = 1024
+	pythonMaxOutputChars       = 16 * 1024
+	// pythonMaxCaptureBytes bounds how much of each stream is kept in memory;
+	// a script that prints more has the rest discarded.
+	pythonMaxCaptureBytes = 1 << 20
+	pythonMaxFiles        = 20
+)
+
+// PythonToolOptions holds the settings for NewPythonTool.
+type PythonToolOptions struct {
+	// InterpreterPath is the Python to run; empty looks up python3 (python
+	// on Windows) on PATH.
+	InterpreterPath string
+	AllowNetwork    bool
+	Timeout         time.Duration
+	MemoryLimitMB   int
+
+	Workspace   string
+	Restrict    bool
+	AllowPaths  []*regexp.Regexp
+	MaxFileSize int
+	// AttachmentsDir returns the directory the inbound attachments of a
+	// session are staged in. Its files are copied next to every script.
+	AttachmentsDir func(sessionKey string) string
+}
+
+// PythonTool runs a Python script in a scratch directory that is removed
+// afterwards. The run has a timeout and, where the platform supports it, a
+// memory limit, no network access and no view of files outside that
+// directory; see pythonSandbox.
+type PythonTool struct {
+	interpreter   string
+	sandbox       pythonSandbox
+	allowNetwork  bool
+	timeout       time.Duration
+	memoryLimitMB int
+
+	workspace      string
+	restrict       bool
+	allowPaths     []*regexp.Regexp
+	maxFileSize    int
+	attachmentsDir func(sessionKey string) string
+}
+
+// NewPythonTool creates the python tool. It fails when no interpreter is
+// found, or when opts.Restrict is set and scripts cannot be kept away from
+// files outside their directory, so the tool is not offered to the model.
+func NewPythonTool(opts PythonToolOptions) (*PythonTool, error) {
+	interpreter := strings.TrimSpace(opts.InterpreterPath)
+	if interpreter == "" {
+		interpreter = "python3"
+		if runtime.GOOS == "windows" {
+			interpreter = "python"
+		}
+	}
+	resolved, err := exec.LookPath(interpreter)
+	if err != nil {
+		return nil, fmt.Errorf("python: interpreter %q not found: %w", interpreter, err)
+	}
+	if abs, err := filepath.Abs(resolved); err == nil {
+		resolved = abs
+	}
+	executable, dirs, err := pythonInstallation(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("python: interpreter %q does not run: %w", resolved, err)
+	}
+	sandbox, err := newPythonSandbox(dirs, opts.Restrict)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = pythonDefaultTimeout
+	}
+	memoryLimitMB := opts.MemoryLimitMB
+	if memoryLimitMB <= 0 {
+		memoryLimitMB = pythonDefaultMemoryLimitMB
+	}
+	maxFileSize := opts.MaxFileSize
+	if maxFileSize <= 0 {
+		maxFileSize = config.DefaultMaxMediaSize
+	}
+
+	return &PythonTool{
+		interpreter:    executable,
+		sandbox:        sandbox,
+		allowNetwork:   opts.AllowNetwork,
+		timeout:        timeout,
+		memoryLimitMB:  memoryLimitMB,
+		workspace:      opts.Workspace,
+		restrict:       opts.Restrict,
+		allowPaths:     opts.AllowPaths,
+		maxFileSize:    maxFileSize,
+		attachmentsDir: opts.AttachmentsDir,
+	}, nil
+}
+
+// pythonInstallation asks the interpreter for its real executable, which
+// skips wrappers such as pyenv shims, and for the directories its standard
+// library and packages live in, which a confined script must be able to read.
+func pythonInstallation(interpreter string) (string, []string, error) {
+	cmd := exec.Command(interpreter, "-I", "-c",
+		"import sys; print(sys.executable); print(sys.prefix); print(sys.base_prefix)")
+	cmd.Env = pythonEnv(os.TempDir())
+	out, err := cmd.Output()
+	if err != nil {
+		return "", nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 3 || lines[0] == "" {
+		return "", nil, fmt.Errorf("unexpected answer %q", out)
+	}
+	executable := strings.TrimSpace(lines[0])
+	dirs := []string{filepath.Dir(executable)}
+	if real, err := filepath.EvalSymlinks(executable); err == nil {
+		dirs = append(dirs, filepath.Dir(real))
+	}
+	for _, prefix := range lines[1:] {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			dirs = append(dirs, prefix)
+		}
+	}
+	return executable, dirs, nil
+}
+
+func (t *PythonTool) Name() string { return "python" }
+
+func (t *PythonTool) Description() string {
+	desc := fmt.Sprintf("Run a Python script for calculations and data processing, and return its stdout "+
+		"and stderr. The script runs in an empty scratch directory that is deleted afterwards, with a %v "+
+		"timeout. Files the user attached, and any workspace files listed in files, are copied into that "+
+		"directory, so open them by file name. Print the results you need.", t.timeout)
+	if notes := t.sandbox.notes(t.allowNetwork); len(notes) > 0 {
+		desc += " On this host " + strings.Join(notes, " and ") + "."
+	} else {
+		desc += " The script cannot read files outside its directory."
+		if !t.allowNetwork {
+			desc += " It has no network access."
+		}
+	}
+	return desc
+}
+
+func (t *PythonTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"code": map[string]any{
+				"type":        "string",
+				"description": "The Python script to run.",
+			},
+			"files": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+				"description": "Optional workspace files to copy into the script's directory. " +
+					"Relative paths are resolved from the workspace.",
+			},
+		},
+		"required": []string{"code"},
+	}
+}
+
+func (t *PythonTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	code, _ := args["code"].(string)
+	if strings.TrimSpace(code) == "" {
+		return ErrorResult("code is required")
+	}
+	files, err := t.inputFiles(args["files"])
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	workDir, err := os.MkdirTemp("", "picoclaw-python-")
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to create the script directory: %v", err))
+	}
+	defer os.RemoveAll(workDir)
+
+	if t.attachmentsDir != nil {
+		if key := ToolSessionKey(ctx); key != "" {
+			if err := t.stageAttachments(t.attachmentsDir(key), workDir); err != nil {
+				return ErrorResult(err.Error())
+			}
+		}
+	}
+	for _, src := range files {
+		if err := copyPythonInput(src, filepath.Join(workDir, filepath.Base(src))); err != nil {
+			return ErrorResult(fmt.Sprintf("failed to copy %s: %v", filepath.Base(src), err))
+		}
+	}
+
+	return t.run(ctx, code, workDir)
+}
+
+// inputFiles resolves the files argument to workspace paths of regular
+// files within the size limit. Base names must be distinct, since they are
+// copied into one directory.
+func (t *PythonTool) inputFiles(raw any) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("files must be an array of paths")
+	}
+	if len(items) > pythonMaxFiles {
+		return nil, fmt.Errorf("at most %d files can be copied", pythonMaxFiles)
+	}
+	var paths []string
+	names := make(map[string]bool, len(items))
+	for _, item := range items {
+		p, _ := item.(string)
+		if strings.TrimSpace(p) == "" {
+			return nil, fmt.Errorf("files must be non-empty paths")
+		}
+		resolved, err := validatePathWithAllowPaths(p, t.workspace, t.restrict, t.allowPaths)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("file %s: %w", p, err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("file %s is not a regular file", p)
+		}
+		if info.Size() > int64(t.maxFileSize) {
+			return nil, fmt.Errorf("file %s is larger than the %d byte limit", p, t.maxFileSize)
+		}
+		name := filepath.Base(resolved)
+		if names[name] {
+			return nil, fmt.Errorf("more than one file is named %s", name)
+		}
+		names[name] = true
+		paths = append(paths, resolved)
+	}
+	return paths, nil
+}
+
+// stageAttachments copies the regular files in dir into workDir. A session
+// without attachments has no directory, which is not an error.
+func (t *PythonTool) stageAttachments(dir, workDir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if err := copyPythonInput(filepath.Join(dir, entry.Name()), filepath.Join(workDir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to copy attachment %s: %v", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+func copyPythonInput(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func (t *PythonTool) run(ctx context.Context, code, workDir string) *ToolResult {
+	cmdCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	// -I ignores PYTHON* variables and the user's site-packages, -B keeps
+	// .pyc files out of the directory, and "-" reads the script from stdin.
+	cmd := t.sandbox.command(t.interpreter, []string{"-I", "-B", "-"}, workDir, t.allowNetwork, t.memoryLimitMB)
+	cmd.Env = pythonEnv(workDir)
+	cmd.Stdin = strings.NewReader(code)
+	stdout := &cappedBuffer{limit: pythonMaxCaptureBytes}
+	stderr := &cappedBuffer{limit: pythonMaxCaptureBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := isolation.Start
+	if t.sandbox.confined() {
+		start = (*exec.Cmd).Start
+	}
+	if err := start(cmd); err != nil {
+		msg := fmt.Sprintf("failed to start python: %v", err)
+		if !t.allowNetwork && runtime.GOOS == "linux" && !t.sandbox.confined() {
+			msg += "; running without network access needs unprivileged user namespaces, " +
+				"or set tools.python.allow_network to run scripts with network access"
+		}
+		return ErrorResult(msg)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-cmdCtx.Done():
+		_ = terminateProcessTree(cmd)
+		err = <-done
+	}
+
+	output := stdout.String()
+	if stderr.Len() > 0 {
+		output += "\nSTDERR:\n" + stderr.String()
+	}
+	if dropped := stdout.dropped + stderr.dropped; dropped > 0 {
+		output += fmt.Sprintf("\n[%d more bytes of output were discarded]", dropped)
+	}
+
+	if err != nil && errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+		msg := fmt.Sprintf("Python script timed out after %v", t.timeout)
+		if strings.TrimSpace(output) != "" {
+			msg += "\n\nPartial output before timeout:\n" + truncatePythonOutput(output)
+		}
+		return &ToolResult{
+			ForLLM:    msg,
+			ForUser:   msg,
+			IsError:   true,
+			Err:       fmt.Errorf("python timeout: %w", err),
+			ErrorCode: ErrorCodeTimeout,
+		}
+	}
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case errors.As(err, &exitErr) && exitErr.ExitCode() == -1:
+			output += "\n\n[Script was killed by a signal]"
+		case errors.As(err, &exitErr):
+			output += fmt.Sprintf("\n\n[Script exited with code %d]", exitErr.ExitCode())
+		default:
+			output += fmt.Sprintf("\n\n[Script failed: %v]", err)
+		}
+	}
+	if strings.TrimSpace(output) == "" {
+		output = "(no output)"
+	}
+	output = truncatePythonOutput(output)
+	if notes := t.sandbox.notes(t.allowNetwork); len(notes) > 0 {
+		output += "\n[sandbox: " + strings.Join(notes, "; ") + "]"
+	}
+
+	return &ToolResult{
+		ForLLM:  output,
+		ForUser: output,
+		IsError: err != nil,
+	}
+}
+
+func truncatePythonOutput(output string) string {
+	if len(output) <= pythonMaxOutputChars {
+		return output
+	}
+	return output[:pythonMaxOutputChars] +
+		fmt.Sprintf("\n... (truncated, %d more chars)", len(output)-pythonMaxOutputChars) +
+		keepFullResult(output)
+}
+
+// pythonEnv is the environment of a script: enough to find the interpreter
+// and write temporary files in its directory, and nothing from the agent's
+// own environment, which may hold API keys.
+func pythonEnv(workDir string) []string {
+	env := []string{
+		"HOME=" + workDir,
+		"TMPDIR=" + workDir,
+		"TEMP=" + workDir,
+		"TMP=" + workDir,
+		"PYTHONIOENCODING=utf-8",
+		"PYTHONDONTWRITEBYTECODE=1",
+		"MPLBACKEND=Agg",
+	}
+	for _, key := range []string{"PATH", "LANG", "LC_ALL", "SYSTEMROOT", "WINDIR"} {
+		if v, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+v)
+		}
+	}
+	return env
+}
+
+// cappedBuffer keeps the first limit bytes written to it and counts the rest,
+// so a script printing in a loop cannot exhaust the agent's memory.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit   int
+	dropped int64
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Buffer.Len(); room < len(p) {
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		b.dropped += int64(len(p) - max(room, 0))
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,122 @@
+//go:build linux
+
+package tools
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// pythonMemoryLimitScript sets the address-space limit given as $1 before it
+// runs the rest of its arguments, and refuses to run them without it.
+const pythonMemoryLimitScript = `ulimit -v "$1" || { echo "picoclaw: could not set the memory limit" >&2; exit 125; }
+shift
+exec "$@"`
+
+// pythonSystemPaths are the host paths a confined script can read: the
+// system's programs and libraries and the files that TLS, time zones and,
+// with network access, name resolution need. Missing ones are skipped.
+var (
+	pythonSystemPaths = []string{
+		"/usr", "/bin", "/lib", "/lib32", "/lib64",
+		"/etc/alternatives", "/etc/ld.so.cache", "/etc/localtime",
+		"/etc/ssl", "/etc/pki", "/etc/ca-certificates",
+	}
+	pythonNetworkPaths = []string{"/etc/resolv.conf", "/etc/hosts", "/etc/nsswitch.conf"}
+)
+
+// pythonSandbox is how scripts are confined on this host. With bubblewrap a
+// script sees only the system paths, the interpreter's directories and its
+// own scratch directory; without it, only the network is cut off, and
+// newPythonSandbox refuses that under restrict_to_workspace.
+type pythonSandbox struct {
+	bwrap    string
+	readOnly []string
+}
+
+func newPythonSandbox(interpreterDirs []string, restrict bool) (pythonSandbox, error) {
+	bwrap, err := exec.LookPath("bwrap")
+	if err != nil {
+		if restrict {
+			return pythonSandbox{}, fmt.Errorf("python: restrict_to_workspace needs bubblewrap (bwrap) " +
+				"to keep scripts away from files outside their directory; install bubblewrap " +
+				"or turn restrict_to_workspace off")
+		}
+		return pythonSandbox{}, nil
+	}
+	return pythonSandbox{bwrap: bwrap, readOnly: interpreterDirs}, nil
+}
+
+// notes lists the limits the sandbox cannot enforce here.
+func (s pythonSandbox) notes(allowNetwork bool) []string {
+	if s.bwrap == "" {
+		return []string{"file access is not confined to the script's directory"}
+	}
+	return nil
+}
+
+// command builds the command that runs the interpreter in workDir. The
+// memory limit is applied with ulimit in a wrapper shell. Under bubblewrap
+// the script gets fresh namespaces with only the allowed paths mounted;
+// otherwise, without network access, the process gets its own user and
+// network namespaces, in which only an unconfigured loopback interface
+// exists.
+func (s pythonSandbox) command(
+	interpreter string,
+	args []string,
+	workDir string,
+	allowNetwork bool,
+	memoryLimitMB int,
+) *exec.Cmd {
+	inner := append([]string{
+		"/bin/sh", "-c", pythonMemoryLimitScript, "sh", strconv.Itoa(memoryLimitMB * 1024), interpreter,
+	}, args...)
+	if s.bwrap != "" {
+		cmd := exec.Command(s.bwrap, pythonBwrapArgs(s.readOnly, workDir, allowNetwork, inner)...)
+		cmd.Dir = workDir
+		prepareCommandForTermination(cmd)
+		return cmd
+	}
+
+	cmd := exec.Command(inner[0], inner[1:]...)
+	cmd.Dir = workDir
+	prepareCommandForTermination(cmd)
+	if !allowNetwork {
+		cmd.SysProcAttr.Cloneflags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET
+		cmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+		cmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+	}
+	return cmd
+}
+
+// confined reports whether command runs scripts in a sandbox of its own, so
+// the global subprocess isolation must not wrap it again.
+func (s pythonSandbox) confined() bool {
+	return s.bwrap != ""
+}
+
+func pythonBwrapArgs(readOnly []string, workDir string, allowNetwork bool, inner []string) []string {
+	args := []string{"--die-with-parent", "--new-session", "--unshare-all"}
+	if allowNetwork {
+		args = append(args, "--share-net")
+	}
+	paths := append([]string{}, pythonSystemPaths...)
+	if allowNetwork {
+		paths = append(paths, pythonNetworkPaths...)
+	}
+	for _, p := range append(paths, readOnly...) {
+		args = append(args, "--ro-bind-try", p, p)
+	}
+	args = append(args,
+		"--proc", "/proc",
+		"--dev", "/dev",
+		"--tmpfs", "/tmp",
+		"--bind", workDir, workDir,
+		"--chdir", workDir,
+		"--",
+	)
+	return append(args, inner...)
+}
@@ -0,0 +1,42 @@
+//go:build linux
+
+package tools
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPythonBwrapArgs(t *testing.T) {
+	inner := []string{"/bin/sh", "-c", "exec \"$@\"", "sh", "/opt/py/bin/python3"}
+
+	args := pythonBwrapArgs([]string{"/opt/py"}, "/tmp/picoclaw-python-1", false, inner)
+	joined := strings.Join(args, " ")
+	for _, want := range []string{
+		"--unshare-all",
+		"--ro-bind-try /usr /usr",
+		"--ro-bind-try /opt/py /opt/py",
+		"--bind /tmp/picoclaw-python-1 /tmp/picoclaw-python-1",
+		"--chdir /tmp/picoclaw-python-1",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("args missing %q: %s", want, joined)
+		}
+	}
+	for _, unwanted := range []string{"--share-net", "/etc/resolv.conf", "/etc/passwd", "/home", "/root"} {
+		if strings.Contains(joined, unwanted) {
+			t.Errorf("args should not contain %q: %s", unwanted, joined)
+		}
+	}
+	if sep := slices.Index(args, "--"); sep < 0 || !slices.Equal(args[sep+1:], inner) {
+		t.Errorf("command not passed after --: %s", joined)
+	}
+
+	joined = strings.Join(pythonBwrapArgs(nil, "/tmp/w", true, inner), " ")
+	for _, want := range []string{"--share-net", "--ro-bind-try /etc/resolv.conf /etc/resolv.conf"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("args with network missing %q: %s", want, joined)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+//go:build !linux
+
+package tools
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// pythonSandbox is how scripts are confined on this host. Outside Linux
+// there is no unprivileged way to limit memory, the network or file access
+// for one child process, so only the timeout applies, and newPythonSandbox
+// refuses to run scripts at all under restrict_to_workspace.
+type pythonSandbox struct{}
+
+func newPythonSandbox(interpreterDirs []string, restrict bool) (pythonSandbox, error) {
+	if restrict {
+		return pythonSandbox{}, fmt.Errorf("python: scripts cannot be kept away from files outside "+
+			"their directory on %s, so the tool is not available with restrict_to_workspace", runtime.GOOS)
+	}
+	return pythonSandbox{}, nil
+}
+
+// notes lists the limits the sandbox cannot enforce here.
+func (s pythonSandbox) notes(allowNetwork bool) []string {
+	notes := []string{
+		"the memory limit is not enforced on " + runtime.GOOS,
+		"file access is not confined to the script's directory",
+	}
+	if !allowNetwork {
+		notes = append(notes, "network access is not blocked on "+runtime.GOOS)
+	}
+	return notes
+}
+
+// command builds the command that runs the interpreter in workDir.
+func (s pythonSandbox) command(
+	interpreter string,
+	args []string,
+	workDir string,
+	allowNetwork bool,
+	memoryLimitMB int,
+) *exec.Cmd {
+	cmd := exec.Command(interpreter, args...)
+	cmd.Dir = workDir
+	prepareCommandForTermination(cmd)
+	return cmd
+}
+
+// confined reports whether command runs scripts in a sandbox of its own.
+func (s pythonSandbox) confined() bool {
+	return false
+}
@@ -0,0 +1,180 @@
+package tools
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestPythonTool(t *testing.T, opts PythonToolOptions) *PythonTool {
+	t.Helper()
+	if opts.Workspace == "" {
+		opts.Workspace = t.TempDir()
+	}
+	tool, err := NewPythonTool(opts)
+	if err != nil {
+		t.Skipf("python not available: %v", err)
+	}
+	return tool
+}
+
+func TestPythonTool_RunsScriptInScratchDir(t *testing.T) {
+	tool := newTestPythonTool(t, PythonToolOptions{AllowNetwork: true})
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"code": "import os, sys\nprint(6 * 7)\nprint(os.listdir('.'))\nprint('oops', file=sys.stderr)\nsys.exit(3)",
+	})
+	if !result.IsError {
+		t.Fatalf("exit code 3 should be an error: %s", result.ForLLM)
+	}
+	for _, want := range []string{"42\n[]", "STDERR:\noops", "[Script exited with code 3]"} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("output missing %q:\n%s", want, result.ForLLM)
+		}
+	}
+}
+
+func TestPythonTool_CopiesAttachmentsAndFiles(t *testing.T) {
+	workspace := t.TempDir()
+	staged := filepath.Join(workspace, "attachments", "session")
+	if err := os.MkdirAll(staged, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(staged, "sales.csv"), []byte("a,b\n1,2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "notes.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var gotKey string
+	tool := newTestPythonTool(t, PythonToolOptions{
+		AllowNetwork: true,
+		Workspace:    workspace,
+		AttachmentsDir: func(sessionKey string) string {
+			gotKey = sessionKey
+			return staged
+		},
+	})
+
+	ctx := WithToolSessionContext(context.Background(), "main", "sk_v1_test", nil)
+	result := tool.Execute(ctx, map[string]any{
+		"code":  "print(sorted(__import__('os').listdir('.')))\nprint(open('sales.csv').read().splitlines()[1])",
+		"files": []any{"notes.txt"},
+	})
+	if result.IsError {
+		t.Fatalf("run failed: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "['notes.txt', 'sales.csv']\n1,2") {
+		t.Errorf("unexpected output:\n%s", result.ForLLM)
+	}
+	if gotKey != "sk_v1_test" {
+		t.Errorf("attachments looked up for session %q", gotKey)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"code": "print(1)", "files": []any{"../outside.txt"}})
+	if !result.IsError {
+		t.Errorf("a file outside the workspace should be rejected: %s", result.ForLLM)
+	}
+}
+
+func TestPythonTool_Timeout(t *testing.T) {
+	tool := newTestPythonTool(t, PythonToolOptions{AllowNetwork: true, Timeout: 500 * time.Millisecond})
+
+	start := time.Now()
+	result := tool.Execute(context.Background(), map[string]any{
+		"code": "import time\nprint('started', flush=True)\ntime.sleep(30)",
+	})
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("timeout took %v", elapsed)
+	}
+	if !result.IsError || result.ErrorCode != ErrorCodeTimeout {
+		t.Fatalf("expected a timeout error, got %+v", result)
+	}
+	if !strings.Contains(result.ForLLM, "started") {
+		t.Errorf("partial output missing:\n%s", result.ForLLM)
+	}
+}
+
+func TestPythonTool_LinuxLimits(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("memory and network limits are enforced on Linux only")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	tool := newTestPythonTool(t, PythonToolOptions{MemoryLimitMB: 256})
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"code": "import socket\ntry:\n    socket.create_connection(('127.0.0.1', " +
+			strings.TrimPrefix(ln.Addr().String(), "127.0.0.1:") + "), timeout=2)\n    print('connected')\n" +
+			"except OSError:\n    print('blocked')\n" +
+			"try:\n    b = bytearray(1 << 30)\n    print('allocated')\nexcept MemoryError:\n    print('memory limited')",
+	})
+	if strings.Contains(result.ForLLM, "failed to start python") {
+		t.Skipf("user namespaces unavailable: %s", result.ForLLM)
+	}
+	if result.IsError || !strings.Contains(result.ForLLM, "blocked\nmemory limited") {
+		t.Fatalf("limits not applied:\n%s", result.ForLLM)
+	}
+}
+
+func TestPythonTool_RestrictNeedsConfinement(t *testing.T) {
+	if _, err := exec.LookPath("bwrap"); err == nil && runtime.GOOS == "linux" {
+		t.Skip("bubblewrap is installed")
+	}
+	if _, err := NewPythonTool(PythonToolOptions{Workspace: t.TempDir()}); err != nil {
+		t.Skipf("python not available: %v", err)
+	}
+	_, err := NewPythonTool(PythonToolOptions{Workspace: t.TempDir(), Restrict: true})
+	if err == nil {
+		t.Fatal("restrict_to_workspace without a way to confine file access should refuse the tool")
+	}
+}
+
+func TestPythonTool_ConfinesFileAccess(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("file access is confined on Linux only")
+	}
+	if _, err := exec.LookPath("bwrap"); err != nil {
+		t.Skip("bubblewrap is not installed")
+	}
+	workspace := t.TempDir()
+	secret := filepath.Join(workspace, "config.json")
+	if err := os.WriteFile(secret, []byte("sk-secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tool := newTestPythonTool(t, PythonToolOptions{Workspace: workspace, Restrict: true})
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"code": "import os\nfor p in ['/etc/passwd', " + strconv.Quote(secret) + "]:\n" +
+			"    print(p, os.path.exists(p))\nprint(open('/usr/lib/os-release').read() != '')",
+	})
+	if result.IsError {
+		t.Fatalf("run failed: %s", result.ForLLM)
+	}
+	want := "/etc/passwd False\n" + secret + " False\nTrue"
+	if !strings.Contains(result.ForLLM, want) {
+		t.Errorf("files outside the script's directory are visible:\n%s", result.ForLLM)
+	}
+}
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{limit: 5}
+	for _, s := range []string{"abc", "defg", "hi"} {
+		if n, err := b.Write([]byte(s)); n != len(s) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v", s, n, err)
+		}
+	}
+	if b.String() != "abcde" || b.dropped != 4 {
+		t.Errorf("got %q with %d dropped", b.String(), b.dropped)
+	}
+}
@@ -14,6 +14,14 @@ import (
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
+// A tool call that fails with a retryable ErrorCode (rate limited or network)
+// and that the tool marked Idempotent is attempted up to toolRetryAttempts
+// times, waiting toolRetryBackoff times the attempt number in between.
+const toolRetryAttempts = 3
+
+// toolRetryBackoff is shortened in tests.
+var toolRetryBackoff = time.Second
+
 type ToolEntry struct {
 	Tool   Tool
 	IsCore bool
@@ -26,6 +34,8 @@ type ToolRegistry struct {
 	version    atomic.Uint64 // incremented on Register/RegisterHidden for cache invalidation
 	mediaStore media.MediaStore
 	allowlist  map[string]struct{}
+	denylist   map[string]struct{}
+	stats      *toolStatsRecorder
 }
 
 type mediaStoreAware interface {
@@ -35,6 +45,7 @@ type mediaStoreAware interface {
 func NewToolRegistry() *ToolRegistry {
 	return &ToolRegistry{
 		tools: make(map[string]*ToolEntry),
+		stats: newToolStatsRecorder(),
 	}
 }
 
@@ -60,6 +71,25 @@ func (r *ToolRegistry) SetAllowlist(names []string) {
 	r.allowlist = allowlist
 }
 
+// SetDenylist blocks registrations of the provided runtime tool names. It
+// applies on top of the allowlist, and to discovery tools as well.
+func (r *ToolRegistry) SetDenylist(names []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.denylist = nil
+	for _, name := range names {
+		trimmed := strings.ToLower(strings.TrimSpace(name))
+		if trimmed == "" {
+			continue
+		}
+		if r.denylist == nil {
+			r.denylist = make(map[string]struct{})
+		}
+		r.denylist[trimmed] = struct{}{}
+	}
+}
+
 func (r *ToolRegistry) Register(tool Tool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -67,7 +97,7 @@ func (r *ToolRegistry) Register(tool Tool) {
 	if !r.toolAllowedLocked(name) {
 		logger.DebugCF(
 			"tools",
-			"Skipped core tool registration by agent allowlist",
+			"Skipped core tool registration by agent tool policy",
 			map[string]any{"name": name},
 		)
 		return
@@ -96,7 +126,7 @@ func (r *ToolRegistry) RegisterHidden(tool Tool) {
 	if !r.toolAllowedLocked(name) {
 		logger.DebugCF(
 			"tools",
-			"Skipped hidden tool registration by agent allowlist",
+			"Skipped hidden tool registration by agent tool policy",
 			map[string]any{"name": name},
 		)
 		return
@@ -169,6 +199,9 @@ func (r *ToolRegistry) Version() uint64 {
 }
 
 func (r *ToolRegistry) toolAllowedLocked(name string) bool {
+	if _, denied := r.denylist[strings.ToLower(strings.TrimSpace(name))]; denied {
+		return false
+	}
 	if r.allowlist == nil {
 		return true
 	}
@@ -269,71 +302,53 @@ func (r *ToolRegistry) ExecuteWithContext(
 			})
 		return ErrorResult(
 			fmt.Sprintf("tool %q not found", name),
-		).WithError(fmt.Errorf("tool not found"))
+		).WithError(fmt.Errorf("tool not found")).WithErrorCode(ErrorCodeNotFound)
+	}
+
+	// Arguments the provider could not decode never reach the tool.
+	if _, malformed := MalformedArguments(tool.Parameters(), args); malformed {
+		logger.WarnCF("tool", "Tool arguments are not valid JSON", map[string]any{"tool": name})
+		r.stats.record(name, true, ErrorCodeInvalid, 0)
+		return ErrorResult(fmt.Sprintf(
+			"tool %q was called with arguments that are not valid JSON; reissue the call with the "+
+				"arguments as a single JSON object matching the parameter schema", name,
+		)).WithError(fmt.Errorf("malformed tool arguments")).WithErrorCode(ErrorCodeInvalid)
 	}
 
 	// Validate arguments against the tool's declared schema.
 	if err := validateToolArgs(tool.Parameters(), args); err != nil {
 		logger.WarnCF("tool", "Tool argument validation failed",
 			map[string]any{"tool": name, "error": err.Error()})
+		r.stats.record(name, true, ErrorCodeInvalid, 0)
 		return ErrorResult(fmt.Sprintf("invalid arguments for tool %q: %s", name, err)).
-			WithError(fmt.Errorf("argument validation failed: %w", err))
+			WithError(fmt.Errorf("argument validation failed: %w", err)).
+			WithErrorCode(ErrorCodeInvalid)
 	}
 
 	// Inject channel/chatID into ctx so tools read them via ToolChannel(ctx)/ToolChatID(ctx).
 	// Always inject — tools validate what they require.
 	ctx = WithToolContext(ctx, channel, chatID)
 
-	// If tool implements AsyncExecutor and callback is provided, use ExecuteAsync.
-	// The callback is a call parameter, not mutable state on the tool instance.
-	var result *ToolResult
 	start := time.Now()
-
-	// Use recover to catch any panics during tool execution
-	// This prevents tool crashes from killing the entire agent
-	func() {
-		defer func() {
-			if re := recover(); re != nil {
-				logger.RecoverPanicNoExit(re)
-				errMsg := fmt.Sprintf("Tool '%s' crashed with panic: %v", name, re)
-				logger.ErrorCF("tool", "Tool execution panic recovered",
-					map[string]any{
-						"tool":  name,
-						"panic": fmt.Sprintf("%v", re),
-					})
-				result = &ToolResult{
-					ForLLM:  errMsg,
-					ForUser: errMsg,
-					IsError: true,
-					Err:     fmt.Errorf("panic: %v", re),
-				}
-			}
-		}()
-
-		if asyncExec, ok := tool.(AsyncExecutor); ok && asyncCallback != nil {
-			logger.DebugCF("tool", "Executing async tool via ExecuteAsync",
-				map[string]any{
-					"tool": name,
-				})
-			result = asyncExec.ExecuteAsync(ctx, args, asyncCallback)
-		} else {
-			result = tool.Execute(ctx, args)
-		}
-	}()
-
-	// Handle nil result (should not happen, but defensive)
-	if result == nil {
-		result = &ToolResult{
-			ForLLM:  fmt.Sprintf("Tool '%s' returned nil result unexpectedly", name),
-			ForUser: fmt.Sprintf("Tool '%s' returned nil result unexpectedly", name),
-			IsError: true,
-			Err:     fmt.Errorf("nil result from tool"),
+	result := executeToolOnce(ctx, tool, name, args, asyncCallback)
+	for attempt := 1; attempt < toolRetryAttempts && retryableToolResult(result); attempt++ {
+		logger.WarnCF("tool", "Retrying tool after retryable error",
+			map[string]any{
+				"tool":       name,
+				"attempt":    attempt + 1,
+				"error_code": string(result.ErrorCode),
+				"error":      result.ForLLM,
+			})
+		if !waitToolRetry(ctx, time.Duration(attempt)*toolRetryBackoff) {
+			break
 		}
+		result = executeToolOnce(ctx, tool, name, args, asyncCallback)
 	}
 
 	result = normalizeToolResult(result, name, r.mediaStore, channel, chatID)
 
 	duration := time.Since(start)
+	r.stats.record(name, result.IsError, result.ErrorCode, duration)
 
 	// Log based on result type
 	if result.IsError {
@@ -361,6 +376,81 @@ func (r *ToolRegistry) ExecuteWithContext(
 	return result
 }
 
+// retryableToolResult reports whether result failed in a way worth
+// repeating the call for. Calls with side effects are never repeated.
+func retryableToolResult(result *ToolResult) bool {
+	return result.IsError && !result.Async && result.Idempotent && result.ErrorCode.Retryable()
+}
+
+// waitToolRetry sleeps for d and reports false if ctx ends first.
+func waitToolRetry(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// executeToolOnce runs tool once, turning a panic or a nil result into an
+// error result so a crashing tool cannot take down the agent.
+func executeToolOnce(
+	ctx context.Context,
+	tool Tool,
+	name string,
+	args map[string]any,
+	asyncCallback AsyncCallback,
+) (result *ToolResult) {
+	defer func() {
+		if re := recover(); re != nil {
+			logger.RecoverPanicNoExit(re)
+			errMsg := fmt.Sprintf("Tool '%s' crashed with panic: %v", name, re)
+			logger.ErrorCF("tool", "Tool execution panic recovered",
+				map[string]any{
+					"tool":  name,
+					"panic": fmt.Sprintf("%v", re),
+				})
+			result = &ToolResult{
+				ForLLM:  errMsg,
+				ForUser: errMsg,
+				IsError: true,
+				Err:     fmt.Errorf("panic: %v", re),
+			}
+		}
+	}()
+
+	// If tool implements AsyncExecutor and callback is provided, use ExecuteAsync.
+	// The callback is a call parameter, not mutable state on the tool instance.
+	if asyncExec, ok := tool.(AsyncExecutor); ok && asyncCallback != nil {
+		logger.DebugCF("tool", "Executing async tool via ExecuteAsync",
+			map[string]any{
+				"tool": name,
+			})
+		result = asyncExec.ExecuteAsync(ctx, args, asyncCallback)
+	} else {
+		result = tool.Execute(ctx, args)
+	}
+
+	// Handle nil result (should not happen, but defensive)
+	if result == nil {
+		result = &ToolResult{
+			ForLLM:  fmt.Sprintf("Tool '%s' returned nil result unexpectedly", name),
+			ForUser: fmt.Sprintf("Tool '%s' returned nil result unexpectedly", name),
+			IsError: true,
+			Err:     fmt.Errorf("nil result from tool"),
+		}
+	}
+	return result
+}
+
+// Stats returns per-tool execution counts, keyed by tool name. Calls for
+// tools that are not registered are not counted.
+func (r *ToolRegistry) Stats() map[string]ToolStats {
+	return r.stats.snapshot()
+}
+
 // sortedToolNames returns tool names in sorted order for deterministic iteration.
 // This is critical for KV cache stability: non-deterministic map iteration would
 // produce different system prompts and tool definitions on each call, invalidating
@@ -386,6 +476,9 @@ func (r *ToolRegistry) GetDefinitions() []map[string]any {
 		if !entry.IsCore && entry.TTL <= 0 {
 			continue
 		}
+		if !toolAvailable(entry.Tool) {
+			continue
+		}
 
 		definitions = append(definitions, ToolToSchema(r.tools[name].Tool))
 	}
@@ -406,6 +499,9 @@ func (r *ToolRegistry) ToProviderDefs() []providers.ToolDefinition {
 		if !entry.IsCore && entry.TTL <= 0 {
 			continue
 		}
+		if !toolAvailable(entry.Tool) {
+			continue
+		}
 
 		schema := ToolToSchema(entry.Tool)
 
@@ -435,6 +531,11 @@ func (r *ToolRegistry) ToProviderDefs() []providers.ToolDefinition {
 	return definitions
 }
 
+func toolAvailable(tool Tool) bool {
+	reporter, ok := tool.(AvailabilityReporter)
+	return !ok || reporter.Available()
+}
+
 func promptMetadataForTool(tool Tool) PromptMetadata {
 	metadata := PromptMetadata{
 		Layer:  ToolPromptLayerCapability,
@@ -470,12 +571,14 @@ func (r *ToolRegistry) List() []string {
 // tools registered on the parent after cloning (e.g. spawn, spawn_status)
 // will NOT be visible to the clone, preventing recursive subagent spawning.
 // The version counter is reset to 0 in the clone as it's a new independent registry.
+// Execution stats are shared with the original.
 func (r *ToolRegistry) Clone() *ToolRegistry {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	clone := &ToolRegistry{
 		tools:      make(map[string]*ToolEntry, len(r.tools)),
 		mediaStore: r.mediaStore,
+		stats:      r.stats,
 	}
 	if r.allowlist != nil {
 		clone.allowlist = make(map[string]struct{}, len(r.allowlist))
@@ -483,6 +586,12 @@ func (r *ToolRegistry) Clone() *ToolRegistry {
 			clone.allowlist[name] = struct{}{}
 		}
 	}
+	if r.denylist != nil {
+		clone.denylist = make(map[string]struct{}, len(r.denylist))
+		for name := range r.denylist {
+			clone.denylist[name] = struct{}{}
+		}
+	}
 	for name, entry := range r.tools {
 		clone.tools[name] = &ToolEntry{
 			Tool:   entry.Tool,
@@ -3,11 +3,16 @@ package tools
 import (
 	"context"
 	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/providers"
@@ -48,6 +53,15 @@ func (m *mockPromptMetadataTool) PromptMetadata() PromptMetadata {
 	return m.metadata
 }
 
+type mockAvailabilityTool struct {
+	mockRegistryTool
+	available bool
+}
+
+func (m *mockAvailabilityTool) Available() bool {
+	return m.available
+}
+
 type mockAsyncRegistryTool struct {
 	mockRegistryTool
 	lastCB AsyncCallback
@@ -149,6 +163,36 @@ func TestToolRegistry_AllowlistStillAllowsDiscoveryTools(t *testing.T) {
 	}
 }
 
+func TestToolRegistry_DenylistOverridesAllowlistAndDiscovery(t *testing.T) {
+	r := NewToolRegistry()
+	r.SetAllowlist([]string{"exec", "read_file"})
+	r.SetDenylist([]string{" EXEC ", RegexSearchToolName})
+
+	r.Register(newMockTool("exec", "exec"))
+	r.Register(newMockTool("read_file", "read"))
+	r.Register(newMockTool(BM25SearchToolName, "discover"))
+	r.Register(newMockTool(RegexSearchToolName, "discover via regex"))
+
+	if _, ok := r.Get("exec"); ok {
+		t.Fatal("exec is denied and should not be registered")
+	}
+	if _, ok := r.Get(RegexSearchToolName); ok {
+		t.Fatal("denied discovery tool should not be registered")
+	}
+	if _, ok := r.Get("read_file"); !ok {
+		t.Fatal("expected read_file to be registered")
+	}
+	if _, ok := r.Get(BM25SearchToolName); !ok {
+		t.Fatal("expected BM25 discovery tool to be registered")
+	}
+
+	clone := r.Clone()
+	clone.Register(newMockTool("exec", "exec"))
+	if _, ok := clone.Get("exec"); ok {
+		t.Fatal("clone should keep the denylist")
+	}
+}
+
 func TestToolRegistry_HasRegisteredIncludesHiddenTools(t *testing.T) {
 	r := NewToolRegistry()
 	r.SetAllowlist([]string{"visible", "hidden"})
@@ -455,6 +499,27 @@ func TestToolToSchema(t *testing.T) {
 	}
 }
 
+func TestToolRegistry_ToProviderDefsSkipsUnavailableTools(t *testing.T) {
+	r := NewToolRegistry()
+	r.Register(newMockTool("native", "native tool"))
+	down := &mockAvailabilityTool{
+		mockRegistryTool: mockRegistryTool{name: "mcp_down", desc: "crashed server tool", params: map[string]any{}},
+	}
+	r.Register(down)
+
+	if defs := r.ToProviderDefs(); len(defs) != 1 || defs[0].Function.Name != "native" {
+		t.Fatalf("ToProviderDefs() = %#v, want only the available tool", defs)
+	}
+	if defs := r.GetDefinitions(); len(defs) != 1 {
+		t.Fatalf("GetDefinitions() len = %d, want 1", len(defs))
+	}
+
+	down.available = true
+	if defs := r.ToProviderDefs(); len(defs) != 2 {
+		t.Fatalf("ToProviderDefs() len = %d after recovery, want 2", len(defs))
+	}
+}
+
 func TestToolRegistry_ToProviderDefsAttachesPromptMetadata(t *testing.T) {
 	r := NewToolRegistry()
 	r.Register(newMockTool("native", "native tool"))
@@ -640,6 +705,134 @@ func (m *mockNilResultTool) Execute(_ context.Context, _ map[string]any) *ToolRe
 	return nil
 }
 
+func TestToolRegistry_StatsCountsExecutions(t *testing.T) {
+	r := NewToolRegistry()
+	r.Register(newMockTool("ok_tool", "succeeds"))
+	r.Register(&mockRegistryTool{
+		name:   "bad_tool",
+		params: map[string]any{"type": "object"},
+		result: ErrorResult("boom"),
+	})
+	r.Register(&mockPanicTool{name: "panic_tool", panicValue: "crash"})
+
+	ctx := context.Background()
+	r.Execute(ctx, "ok_tool", nil)
+	r.Execute(ctx, "ok_tool", nil)
+	r.Execute(ctx, "bad_tool", nil)
+	r.Execute(ctx, "panic_tool", nil)
+	r.Execute(ctx, "missing_tool", nil)
+
+	// Clones made for subagents report into the same stats.
+	r.Clone().Execute(ctx, "ok_tool", nil)
+
+	stats := r.Stats()
+	if got := stats["ok_tool"]; got.Invocations != 3 || got.Successes != 3 || got.Failures != 0 {
+		t.Fatalf("ok_tool stats = %+v", got)
+	}
+	if got := stats["bad_tool"]; got.Invocations != 1 || got.Failures != 1 {
+		t.Fatalf("bad_tool stats = %+v", got)
+	}
+	if got := stats["panic_tool"]; got.Invocations != 1 || got.Failures != 1 {
+		t.Fatalf("panic_tool stats = %+v", got)
+	}
+	if _, ok := stats["missing_tool"]; ok {
+		t.Fatal("unregistered tools should not be counted")
+	}
+}
+
+type flakyRegistryTool struct {
+	mockRegistryTool
+	failures int
+	code     ErrorCode
+	calls    int
+}
+
+func (m *flakyRegistryTool) Execute(_ context.Context, _ map[string]any) *ToolResult {
+	m.calls++
+	if m.calls <= m.failures {
+		return ErrorResult("upstream failed").WithErrorCode(m.code).WithIdempotent()
+	}
+	return NewToolResult("ok")
+}
+
+func TestToolRegistry_RetriesRetryableErrors(t *testing.T) {
+	orig := toolRetryBackoff
+	toolRetryBackoff = time.Millisecond
+	t.Cleanup(func() { toolRetryBackoff = orig })
+
+	r := NewToolRegistry()
+	limited := &flakyRegistryTool{
+		mockRegistryTool: mockRegistryTool{name: "limited", params: map[string]any{"type": "object"}},
+		failures:         2,
+		code:             ErrorCodeRateLimited,
+	}
+	denied := &flakyRegistryTool{
+		mockRegistryTool: mockRegistryTool{name: "denied", params: map[string]any{"type": "object"}},
+		failures:         1,
+		code:             ErrorCodePermission,
+	}
+	r.Register(limited)
+	r.Register(denied)
+
+	if result := r.Execute(context.Background(), "limited", nil); result.IsError || limited.calls != 3 {
+		t.Fatalf("limited: result = %+v after %d calls, want success on third call", result, limited.calls)
+	}
+	result := r.Execute(context.Background(), "denied", nil)
+	if !result.IsError || result.ErrorCode != ErrorCodePermission || denied.calls != 1 {
+		t.Fatalf("denied: result = %+v after %d calls, want one permission failure", result, denied.calls)
+	}
+	if got := r.Stats()["denied"].ErrorCodes[ErrorCodePermission]; got != 1 {
+		t.Fatalf("denied permission count = %d, want 1", got)
+	}
+	if result := r.Execute(context.Background(), "missing", nil); result.ErrorCode != ErrorCodeNotFound {
+		t.Fatalf("missing tool ErrorCode = %q, want not_found", result.ErrorCode)
+	}
+}
+
+func TestToolRegistry_RetriesOnlyIdempotentHTTPRequests(t *testing.T) {
+	orig := toolRetryBackoff
+	toolRetryBackoff = time.Millisecond
+	t.Cleanup(func() { toolRetryBackoff = orig })
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack: %v", err)
+			return
+		}
+		// Drop the connection without a response, as a reset would.
+		conn.(*net.TCPConn).SetLinger(0)
+		conn.Close()
+	}))
+	defer server.Close()
+
+	tool, err := NewHTTPRequestTool("", nil, []string{"127.0.0.0/8"}, 0)
+	if err != nil {
+		t.Fatalf("NewHTTPRequestTool: %v", err)
+	}
+	r := NewToolRegistry()
+	r.Register(tool)
+
+	for _, tt := range []struct {
+		method string
+		want   int32
+	}{
+		{method: "POST", want: 1},
+		{method: "GET", want: toolRetryAttempts},
+	} {
+		requests.Store(0)
+		result := r.Execute(context.Background(), tool.Name(), map[string]any{"method": tt.method, "url": server.URL})
+		if !result.IsError || result.ErrorCode != ErrorCodeNetwork {
+			t.Fatalf("%s: result = %+v, want a network failure", tt.method, result)
+		}
+		if got := requests.Load(); got != tt.want {
+			t.Errorf("%s was sent %d times, want %d", tt.method, got, tt.want)
+		}
+	}
+}
+
 func TestToolRegistry_Execute_PanicRecovery(t *testing.T) {
 	r := NewToolRegistry()
 	r.Register(&mockPanicTool{
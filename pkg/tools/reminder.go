@@ -0,0 +1,458 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	reminderPrefix      = "Reminder: "
+	defaultReminderHour = 9
+	tonightReminderHour = 20
+)
+
+// ReminderTool sets one-time reminders that the cron service delivers, as a
+// plain message, back to the chat they were set from.
+type ReminderTool struct {
+	cronService *cron.CronService
+	now         func() time.Time
+}
+
+// NewReminderTool creates a ReminderTool that stores its jobs in cronService.
+func NewReminderTool(cronService *cron.CronService) *ReminderTool {
+	return &ReminderTool{cronService: cronService, now: time.Now}
+}
+
+func (t *ReminderTool) Name() string {
+	return "reminder"
+}
+
+func (t *ReminderTool) Description() string {
+	return "Set, list, or cancel one-time reminders for the current chat. Use this when the user says " +
+		"things like 'remind me to call Bob in 2 hours' or 'cancel my reminder about the dentist'. " +
+		"The reminder text is sent back to this chat at the requested time."
+}
+
+func (t *ReminderTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"set", "list", "cancel"},
+				"description": "set creates a reminder, list shows pending reminders for this chat, cancel removes one.",
+			},
+			"message": map[string]any{
+				"type":        "string",
+				"description": "What to remind the user about, e.g. 'call Bob'. Required for set.",
+			},
+			"when": map[string]any{
+				"type": "string",
+				"description": "When to send it. Relative ('in 2 hours', 'in 1h30m', 'in half an hour'), " +
+					"day and time ('tomorrow 9am', 'friday at 17:30', 'tonight'), a time today " +
+					"('at 5pm'), or an absolute timestamp ('2026-03-01 14:00'). Required for set.",
+			},
+			"job_id": map[string]any{
+				"type":        "string",
+				"description": "Reminder ID to cancel, as shown by set or list.",
+			},
+			"about": map[string]any{
+				"type":        "string",
+				"description": "Text to find the reminder to cancel when the ID is not known, e.g. 'dentist'.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ReminderTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case "set":
+		return t.set(ctx, args)
+	case "list":
+		return t.list(ctx)
+	case "cancel":
+		return t.cancel(ctx, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action %q (expected set, list, or cancel)", action))
+	}
+}
+
+func (t *ReminderTool) set(ctx context.Context, args map[string]any) *ToolResult {
+	channel := ToolChannel(ctx)
+	chatID := ToolChatID(ctx)
+	if channel == "" || chatID == "" {
+		return ErrorResult("no session context (channel/chat_id not set). Use this tool in an active conversation.")
+	}
+
+	message, _ := args["message"].(string)
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return ErrorResult("message is required for set")
+	}
+	when, _ := args["when"].(string)
+
+	now := t.now()
+	at, err := parseReminderTime(when, now)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if !at.After(now) {
+		return ErrorResult(fmt.Sprintf("%s is in the past; pick a future time", at.Format(time.RFC1123)))
+	}
+
+	atMS := at.UnixMilli()
+	job, err := t.cronService.AddJob(
+		reminderPrefix+utils.Truncate(message, 30),
+		cron.CronSchedule{Kind: "at", AtMS: &atMS},
+		reminderPrefix+message,
+		channel,
+		chatID,
+	)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error setting reminder: %v", err))
+	}
+	job.Payload.Deliver = true
+	if err := t.cronService.UpdateJob(job); err != nil {
+		t.cronService.RemoveJob(job.ID)
+		return ErrorResult(fmt.Sprintf("Error setting reminder: %v", err))
+	}
+
+	return SilentResult(fmt.Sprintf("Reminder set for %s (%s): %s (id: %s)",
+		at.Format("Mon Jan 2 15:04 MST"), formatReminderWait(at.Sub(now)), message, job.ID))
+}
+
+func (t *ReminderTool) list(ctx context.Context) *ToolResult {
+	reminders := t.reminders(ctx)
+	if len(reminders) == 0 {
+		return SilentResult("No pending reminders")
+	}
+	now := t.now()
+	var sb strings.Builder
+	sb.WriteString("Pending reminders:\n")
+	for _, job := range reminders {
+		at := time.UnixMilli(*job.Schedule.AtMS).In(now.Location())
+		fmt.Fprintf(&sb, "- %s at %s (%s, id: %s)\n",
+			strings.TrimPrefix(job.Payload.Message, reminderPrefix),
+			at.Format("Mon Jan 2 15:04"), formatReminderWait(at.Sub(now)), job.ID)
+	}
+	return SilentResult(sb.String())
+}
+
+func (t *ReminderTool) cancel(ctx context.Context, args map[string]any) *ToolResult {
+	jobID, _ := args["job_id"].(string)
+	about, _ := args["about"].(string)
+	jobID = strings.TrimSpace(jobID)
+	about = strings.ToLower(strings.TrimSpace(about))
+	if jobID == "" && about == "" {
+		return ErrorResult("job_id or about is required for cancel")
+	}
+
+	var matches []cron.CronJob
+	for _, job := range t.reminders(ctx) {
+		if jobID != "" && job.ID == jobID {
+			matches = []cron.CronJob{job}
+			break
+		}
+		if jobID == "" && strings.Contains(strings.ToLower(job.Payload.Message), about) {
+			matches = append(matches, job)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if jobID != "" {
+			return ErrorResult(fmt.Sprintf("no pending reminder with id %s in this chat", jobID))
+		}
+		return ErrorResult(fmt.Sprintf("no pending reminder in this chat mentions %q", about))
+	case 1:
+	default:
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%d reminders mention %q; cancel one by job_id:\n", len(matches), about)
+		for _, job := range matches {
+			fmt.Fprintf(&sb, "- %s (id: %s)\n", strings.TrimPrefix(job.Payload.Message, reminderPrefix), job.ID)
+		}
+		return ErrorResult(sb.String())
+	}
+
+	job := matches[0]
+	if !t.cronService.RemoveJob(job.ID) {
+		return ErrorResult(fmt.Sprintf("reminder %s already fired or was removed", job.ID))
+	}
+	return SilentResult(fmt.Sprintf("Reminder cancelled: %s (id: %s)",
+		strings.TrimPrefix(job.Payload.Message, reminderPrefix), job.ID))
+}
+
+// reminders returns the pending one-time delivered jobs visible from the
+// current chat, soonest first.
+func (t *ReminderTool) reminders(ctx context.Context) []cron.CronJob {
+	channel := ToolChannel(ctx)
+	chatID := ToolChatID(ctx)
+	internal := constants.IsInternalChannel(channel)
+
+	var result []cron.CronJob
+	for _, job := range t.cronService.ListJobs(false) {
+		if !job.Payload.Deliver || job.Schedule.Kind != "at" || job.Schedule.AtMS == nil {
+			continue
+		}
+		if !internal && (job.Payload.Channel != channel || job.Payload.To != chatID) {
+			continue
+		}
+		result = append(result, job)
+	}
+	for i := 1; i < len(result); i++ {
+		for j := i; j > 0 && *result[j].Schedule.AtMS < *result[j-1].Schedule.AtMS; j-- {
+			result[j], result[j-1] = result[j-1], result[j]
+		}
+	}
+	return result
+}
+
+// DeliverCronMessage publishes the saved message of a deliver-mode cron job
+// straight to its target chats, without running an agent turn. fallback, which
+// may be nil, picks the chat for jobs that were scheduled without one.
+func DeliverCronMessage(
+	ctx context.Context, msgBus *bus.MessageBus, job *cron.CronJob, fallback CronTargetFunc,
+) error {
+	return publishCronOutput(ctx, msgBus, cronJobTargets(job, fallback), job.Payload.Message)
+}
+
+func formatReminderWait(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "in under a minute"
+	}
+	days := int(d / (24 * time.Hour))
+	d -= time.Duration(days) * 24 * time.Hour
+	hours := int(d / time.Hour)
+	minutes := int((d - time.Duration(hours)*time.Hour) / time.Minute)
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 && days == 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	if len(parts) == 0 {
+		return "in under a minute"
+	}
+	return "in " + strings.Join(parts, " ")
+}
+
+var reminderUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+var reminderWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// parseReminderTime turns a relative or absolute time phrase into an instant
+// in now's location. Phrases that name only a time of day resolve to the next
+// occurrence; phrases that name only a day resolve to 9am that day.
+func parseReminderTime(when string, now time.Time) (time.Time, error) {
+	raw := strings.TrimSpace(when)
+	if raw == "" {
+		return time.Time{}, errors.New("when is required for set")
+	}
+	loc := now.Location()
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", raw, loc); err == nil {
+		return t.Add(defaultReminderHour * time.Hour), nil
+	}
+
+	s := strings.TrimSuffix(strings.ToLower(strings.Join(strings.Fields(raw), " ")), ".")
+	if rest, ok := strings.CutPrefix(s, "in "); ok {
+		d, err := parseReminderDuration(rest)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(d), nil
+	}
+
+	words := strings.Fields(s)
+	var day time.Time
+	dayGiven := false
+	defaultHour := defaultReminderHour
+	if len(words) > 0 {
+		first := words[0]
+		if first == "on" || first == "next" || first == "this" {
+			if len(words) > 1 {
+				if _, ok := reminderWeekdays[words[1]]; ok {
+					words = words[1:]
+					first = words[0]
+				}
+			}
+		}
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+		switch first {
+		case "today":
+			day, dayGiven = today, true
+		case "tonight":
+			day, dayGiven, defaultHour = today, true, tonightReminderHour
+		case "tomorrow":
+			day, dayGiven = today.AddDate(0, 0, 1), true
+		default:
+			if wd, ok := reminderWeekdays[first]; ok {
+				ahead := (int(wd) - int(now.Weekday()) + 7) % 7
+				if ahead == 0 {
+					ahead = 7
+				}
+				day, dayGiven = today.AddDate(0, 0, ahead), true
+			}
+		}
+		if dayGiven {
+			words = words[1:]
+		}
+	}
+	if len(words) > 0 && words[0] == "at" {
+		words = words[1:]
+	}
+
+	if len(words) == 0 {
+		if !dayGiven {
+			return time.Time{}, fmt.Errorf("could not understand time %q", raw)
+		}
+		return day.Add(time.Duration(defaultHour) * time.Hour), nil
+	}
+
+	hour, minute, err := parseReminderClock(strings.Join(words, ""))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not understand time %q", raw)
+	}
+	if dayGiven {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc), nil
+	}
+	t := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, loc)
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// parseReminderDuration parses the part after "in": "2 hours", "an hour",
+// "half an hour", "1 hour and 30 minutes", or Go syntax such as "1h30m".
+func parseReminderDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "half an hour" || s == "half hour" {
+		return 30 * time.Minute, nil
+	}
+	if d, err := time.ParseDuration(strings.ReplaceAll(s, " ", "")); err == nil && d > 0 {
+		return d, nil
+	}
+
+	fields := strings.Fields(strings.NewReplacer(",", " ", " and ", " ").Replace(s))
+	var total time.Duration
+	for i := 0; i < len(fields); i++ {
+		word := fields[i]
+		var n float64
+		switch word {
+		case "a", "an", "one":
+			n = 1
+		default:
+			v, err := strconv.ParseFloat(word, 64)
+			if err != nil {
+				// Allow a number glued to its unit, e.g. "90min".
+				j := strings.IndexFunc(word, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+				if j <= 0 {
+					return 0, fmt.Errorf("could not understand duration %q", s)
+				}
+				v, err = strconv.ParseFloat(word[:j], 64)
+				if err != nil {
+					return 0, fmt.Errorf("could not understand duration %q", s)
+				}
+				fields = append(fields[:i+1], append([]string{word[j:]}, fields[i+1:]...)...)
+			}
+			n = v
+		}
+		if i+1 >= len(fields) {
+			return 0, fmt.Errorf("missing unit in duration %q", s)
+		}
+		unit, ok := reminderUnits[fields[i+1]]
+		if !ok {
+			return 0, fmt.Errorf("unknown time unit %q", fields[i+1])
+		}
+		total += time.Duration(n * float64(unit))
+		i++
+	}
+	if total <= 0 {
+		return 0, fmt.Errorf("could not understand duration %q", s)
+	}
+	return total, nil
+}
+
+// parseReminderClock parses a time of day with spaces removed: "9am",
+// "9:30pm", "21:00", "noon", or "midnight".
+func parseReminderClock(s string) (hour, minute int, err error) {
+	switch s {
+	case "noon", "midday":
+		return 12, 0, nil
+	case "midnight":
+		return 0, 0, nil
+	}
+	meridiem := ""
+	for _, suffix := range []string{"am", "pm", "a.m.", "p.m."} {
+		if rest, ok := strings.CutSuffix(s, suffix); ok {
+			meridiem = suffix[:1]
+			s = rest
+			break
+		}
+	}
+	hourStr, minuteStr, hasMinutes := strings.Cut(s, ":")
+	hour, err = strconv.Atoi(hourStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if hasMinutes {
+		if minute, err = strconv.Atoi(minuteStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	if minute < 0 || minute > 59 {
+		return 0, 0, errors.New("minute out of range")
+	}
+	switch meridiem {
+	case "a", "p":
+		if hour < 1 || hour > 12 {
+			return 0, 0, errors.New("hour out of range")
+		}
+		hour %= 12
+		if meridiem == "p" {
+			hour += 12
+		}
+	default:
+		if !hasMinutes || hour < 0 || hour > 23 {
+			return 0, 0, errors.New("ambiguous time of day")
+		}
+	}
+	return hour, minute, nil
+}
@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/cron"
+)
+
+// reminderTestNow is a Wednesday afternoon.
+var reminderTestNow = time.Date(2026, 3, 4, 14, 30, 0, 0, time.UTC)
+
+func newTestReminderTool(t *testing.T) *ReminderTool {
+	t.Helper()
+	tool := NewReminderTool(cron.NewCronService(filepath.Join(t.TempDir(), "cron.json"), nil))
+	tool.now = func() time.Time { return reminderTestNow }
+	return tool
+}
+
+func TestParseReminderTime(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		when string
+		want time.Time
+	}{
+		{"in 2 hours", reminderTestNow.Add(2 * time.Hour)},
+		{"in an hour", reminderTestNow.Add(time.Hour)},
+		{"in half an hour", reminderTestNow.Add(30 * time.Minute)},
+		{"in 1h30m", reminderTestNow.Add(90 * time.Minute)},
+		{"in 1 hour and 15 minutes", reminderTestNow.Add(75 * time.Minute)},
+		{"in 90min", reminderTestNow.Add(90 * time.Minute)},
+		{"in 3 days", reminderTestNow.Add(72 * time.Hour)},
+		{"tomorrow 9am", at(5, 9, 0)},
+		{"Tomorrow at 9:30 PM", at(5, 21, 30)},
+		{"tomorrow", at(5, 9, 0)},
+		{"tonight", at(4, 20, 0)},
+		{"today at 17:45", at(4, 17, 45)},
+		{"at 5pm", at(4, 17, 0)},
+		{"at 8am", at(5, 8, 0)},
+		{"noon", at(5, 12, 0)},
+		{"friday", at(6, 9, 0)},
+		{"next wednesday at 10am", at(11, 10, 0)},
+		{"on mon 8:15am", at(9, 8, 15)},
+		{"2026-03-10 14:00", at(10, 14, 0)},
+		{"2026-03-10", at(10, 9, 0)},
+		{"2026-03-10T14:00:00Z", at(10, 14, 0)},
+	}
+	for _, tt := range tests {
+		got, err := parseReminderTime(tt.when, reminderTestNow)
+		if err != nil {
+			t.Errorf("parseReminderTime(%q) error = %v", tt.when, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseReminderTime(%q) = %v, want %v", tt.when, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "someday", "in a while", "in 5 fortnights", "at 25:00", "tomorrow at 13pm", "at 7"} {
+		if got, err := parseReminderTime(bad, reminderTestNow); err == nil {
+			t.Errorf("parseReminderTime(%q) = %v, want error", bad, got)
+		}
+	}
+}
+
+func TestReminderTool_SetListCancel(t *testing.T) {
+	tool := newTestReminderTool(t)
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+
+	result := tool.Execute(ctx, map[string]any{"action": "set", "message": "call the dentist", "when": "in 2 hours"})
+	if result.IsError || !strings.Contains(result.ForLLM, "in 2h") {
+		t.Fatalf("set = %+v", result)
+	}
+	tool.Execute(ctx, map[string]any{"action": "set", "message": "water the plants", "when": "tomorrow 9am"})
+
+	jobs := tool.cronService.ListJobs(false)
+	if len(jobs) != 2 {
+		t.Fatalf("stored %d jobs, want 2", len(jobs))
+	}
+	for _, job := range jobs {
+		if !job.Payload.Deliver || job.Schedule.Kind != "at" || !job.DeleteAfterRun ||
+			job.Payload.Channel != "telegram" || job.Payload.To != "chat-1" {
+			t.Fatalf("job = %+v, want one-time delivered job for telegram/chat-1", job)
+		}
+	}
+
+	// Another chat sees neither reminder.
+	other := WithToolContext(context.Background(), "telegram", "chat-2")
+	if result := tool.Execute(other, map[string]any{"action": "list"}); result.ForLLM != "No pending reminders" {
+		t.Fatalf("list from other chat = %q", result.ForLLM)
+	}
+	if result := tool.Execute(other, map[string]any{"action": "cancel", "about": "dentist"}); !result.IsError {
+		t.Fatal("cancel from other chat should not find the reminder")
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "list"})
+	if strings.Index(result.ForLLM, "call the dentist") > strings.Index(result.ForLLM, "water the plants") {
+		t.Fatalf("list = %q, want soonest first", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "cancel", "about": "Dentist"})
+	if result.IsError || !strings.Contains(result.ForLLM, "call the dentist") {
+		t.Fatalf("cancel = %+v", result)
+	}
+	if jobs := tool.cronService.ListJobs(false); len(jobs) != 1 || !strings.Contains(jobs[0].Payload.Message, "plants") {
+		t.Fatalf("remaining jobs = %+v", jobs)
+	}
+}
+
+func TestReminderTool_CancelAmbiguous(t *testing.T) {
+	tool := newTestReminderTool(t)
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+	tool.Execute(ctx, map[string]any{"action": "set", "message": "pay rent", "when": "in 1 day"})
+	tool.Execute(ctx, map[string]any{"action": "set", "message": "pay phone bill", "when": "in 2 days"})
+
+	result := tool.Execute(ctx, map[string]any{"action": "cancel", "about": "pay"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "2 reminders mention") {
+		t.Fatalf("ambiguous cancel = %+v", result)
+	}
+	if len(tool.cronService.ListJobs(false)) != 2 {
+		t.Fatal("ambiguous cancel must not remove anything")
+	}
+
+	id := tool.cronService.ListJobs(false)[0].ID
+	if result := tool.Execute(ctx, map[string]any{"action": "cancel", "job_id": id}); result.IsError {
+		t.Fatalf("cancel by id: %s", result.ForLLM)
+	}
+	if len(tool.cronService.ListJobs(false)) != 1 {
+		t.Fatal("cancel by id did not remove the job")
+	}
+}
+
+func TestReminderTool_SetValidation(t *testing.T) {
+	tool := newTestReminderTool(t)
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+	tests := []struct {
+		ctx  context.Context
+		args map[string]any
+		want string
+	}{
+		{context.Background(), map[string]any{"action": "set", "message": "x", "when": "in 1 hour"}, "no session context"},
+		{ctx, map[string]any{"action": "set", "when": "in 1 hour"}, "message is required"},
+		{ctx, map[string]any{"action": "set", "message": "x", "when": "later-ish"}, "could not understand"},
+		{ctx, map[string]any{"action": "set", "message": "x", "when": "2020-01-01 10:00"}, "in the past"},
+		{ctx, map[string]any{"action": "cancel"}, "job_id or about"},
+		{ctx, map[string]any{"action": "snooze"}, "unknown action"},
+	}
+	for _, tt := range tests {
+		result := tool.Execute(tt.ctx, tt.args)
+		if !result.IsError || !strings.Contains(result.ForLLM, tt.want) {
+			t.Errorf("Execute(%v) = %q, want error containing %q", tt.args, result.ForLLM, tt.want)
+		}
+	}
+}
+
+func TestDeliverCronMessage(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	job := &cron.CronJob{Payload: cron.CronPayload{
+		Kind:    "agent_turn",
+		Message: "Reminder: stretch",
+		Channel: "telegram",
+		To:      "chat-1",
+		Deliver: true,
+	}}
+
+	if err := DeliverCronMessage(context.Background(), msgBus, job, nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-msgBus.OutboundChan():
+		if msg.Content != "Reminder: stretch" || msg.Context.Channel != "telegram" || msg.Context.ChatID != "chat-1" {
+			t.Fatalf("outbound = %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no outbound message")
+	}
+}
+
+func TestDeliverCronMessage_MultipleRecipients(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	job := &cron.CronJob{Payload: cron.CronPayload{Kind: "agent_turn", Message: "Standup", Deliver: true}}
+	job.Payload.SetRecipients([]cron.CronRecipient{{Channel: "telegram", To: "a"}, {Channel: "slack", To: "C1"}})
+
+	if err := DeliverCronMessage(context.Background(), msgBus, job, nil); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for range 2 {
+		select {
+		case msg := <-msgBus.OutboundChan():
+			got = append(got, msg.Context.Channel+":"+msg.Context.ChatID+"="+msg.Content)
+		case <-time.After(time.Second):
+			t.Fatalf("got %v, want two outbound messages", got)
+		}
+	}
+	if strings.Join(got, ",") != "telegram:a=Standup,slack:C1=Standup" {
+		t.Fatalf("outbound = %v", got)
+	}
+}
@@ -3,6 +3,8 @@ package tools
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io/fs"
 	"strings"
 	"testing"
 )
@@ -231,6 +233,34 @@ func TestToolResultWithErrors(t *testing.T) {
 	}
 }
 
+func TestToolResultErrorCode(t *testing.T) {
+	notFound := ErrorResult("missing").WithError(fmt.Errorf("open a.txt: %w", fs.ErrNotExist))
+	if notFound.ErrorCode != ErrorCodeNotFound {
+		t.Fatalf("ErrorCode = %q, want %q", notFound.ErrorCode, ErrorCodeNotFound)
+	}
+	if got := notFound.ContentForLLM(); got != "missing\n[error_code: not_found]" {
+		t.Fatalf("ContentForLLM() = %q", got)
+	}
+
+	denied := ErrorResult("denied").WithError(fs.ErrPermission)
+	if denied.ErrorCode != ErrorCodePermission || denied.ErrorCode.Retryable() || denied.ErrorCode.Correctable() {
+		t.Fatalf("ErrorCode = %q, want non-retryable, uncorrectable permission", denied.ErrorCode)
+	}
+	if !notFound.ErrorCode.Correctable() || !ErrorCodeInvalid.Correctable() {
+		t.Fatal("not_found and invalid should be correctable")
+	}
+
+	// An explicit code is kept when an error is attached afterwards.
+	limited := ErrorResult("slow down").WithErrorCode(ErrorCodeRateLimited).WithError(errors.New("429"))
+	if got := limited.ContentForLLM(); !strings.HasSuffix(got, "[error_code: rate_limited, retryable]") {
+		t.Fatalf("ContentForLLM() = %q", got)
+	}
+
+	if plain := ErrorResult("boom").WithError(errors.New("boom")); plain.ErrorCode != "" {
+		t.Fatalf("ErrorCode = %q, want none for unclassified error", plain.ErrorCode)
+	}
+}
+
 func TestToolResultJSONStructure(t *testing.T) {
 	result := UserResult("test content")
 
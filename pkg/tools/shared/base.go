@@ -32,6 +32,13 @@ type PromptMetadataProvider interface {
 	PromptMetadata() PromptMetadata
 }
 
+// AvailabilityReporter is implemented by tools whose backing service can go
+// away at runtime. Unavailable tools stay registered but are left out of the
+// definitions sent to the model until they report available again.
+type AvailabilityReporter interface {
+	Available() bool
+}
+
 // --- Request-scoped tool context (channel / chatID) ---
 //
 // Carried via context.Value so that concurrent tool calls each receive
@@ -50,6 +57,7 @@ var (
 	ctxKeyAgentID          = &toolCtxKey{"agentID"}
 	ctxKeySessionKey       = &toolCtxKey{"sessionKey"}
 	ctxKeySessionScope     = &toolCtxKey{"sessionScope"}
+	ctxKeyProgress         = &toolCtxKey{"progress"}
 )
 
 // WithToolContext returns a child context carrying channel and chatID.
@@ -151,6 +159,22 @@ func ToolSessionScope(ctx context.Context) *session.SessionScope {
 	return session.CloneScope(scope)
 }
 
+// ProgressCallback receives intermediate output from a tool that is still
+// running, such as the latest lines printed by a long shell command. Tools
+// call it from their own goroutine and should throttle calls themselves.
+type ProgressCallback func(output string)
+
+// WithToolProgress returns a child context carrying a progress callback.
+func WithToolProgress(ctx context.Context, cb ProgressCallback) context.Context {
+	return context.WithValue(ctx, ctxKeyProgress, cb)
+}
+
+// ToolProgress extracts the progress callback from ctx, or nil if unset.
+func ToolProgress(ctx context.Context) ProgressCallback {
+	cb, _ := ctx.Value(ctxKeyProgress).(ProgressCallback)
+	return cb
+}
+
 // AsyncCallback is a function type that async tools use to notify completion.
 // When an async tool finishes its work, it calls this callback with the result.
 //
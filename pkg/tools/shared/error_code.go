@@ -0,0 +1,75 @@
+package toolshared
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"strings"
+)
+
+// ErrorCode classifies a failed tool call so the model and the agent loop can
+// react to the kind of failure instead of parsing the message.
+type ErrorCode string
+
+const (
+	// ErrorCodeNotFound means the file, resource or tool does not exist.
+	ErrorCodeNotFound ErrorCode = "not_found"
+	// ErrorCodePermission means access was denied. Retrying will not help.
+	ErrorCodePermission ErrorCode = "permission"
+	// ErrorCodeRateLimited means an upstream service throttled the call.
+	ErrorCodeRateLimited ErrorCode = "rate_limited"
+	// ErrorCodeInvalid means the arguments were rejected.
+	ErrorCodeInvalid ErrorCode = "invalid"
+	// ErrorCodeTimeout means the call ran out of time.
+	ErrorCodeTimeout ErrorCode = "timeout"
+	// ErrorCodeNetwork means a connection or transport failure.
+	ErrorCodeNetwork ErrorCode = "network"
+)
+
+// ErrRateLimited can be wrapped by tool errors to mark upstream throttling,
+// so ClassifyError reports ErrorCodeRateLimited.
+var ErrRateLimited = errors.New("rate limited")
+
+// Retryable reports whether the same call may succeed if repeated shortly,
+// which is the case for throttling and transient network failures.
+func (c ErrorCode) Retryable() bool {
+	return c == ErrorCodeRateLimited || c == ErrorCodeNetwork
+}
+
+// Correctable reports whether the model can likely fix the call by changing
+// its arguments, as with rejected arguments or a wrong path.
+func (c ErrorCode) Correctable() bool {
+	return c == ErrorCodeInvalid || c == ErrorCodeNotFound
+}
+
+// ClassifyError infers an ErrorCode from err. It returns "" when the error
+// does not match a known category.
+func ClassifyError(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return ErrorCodeRateLimited
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorCodeTimeout
+	case errors.Is(err, fs.ErrNotExist):
+		return ErrorCodeNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return ErrorCodePermission
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorCodeTimeout
+		}
+		return ErrorCodeNetwork
+	}
+	// Sandboxed file systems wrap some denials without fs.ErrPermission.
+	if msg := strings.ToLower(err.Error()); strings.Contains(msg, "access denied") ||
+		strings.Contains(msg, "permission denied") {
+		return ErrorCodePermission
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package toolshared
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FullResults keeps the complete text of tool output that was truncated
+// before it reached the model, so it can be read back by handle. Entries
+// expire after a TTL and the oldest are evicted once the byte budget is used.
+type FullResults struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	maxBytes int
+	size     int
+	entries  map[string]fullResult
+	order    []string // handles, oldest first
+	now      func() time.Time
+}
+
+type fullResult struct {
+	text    string
+	expires time.Time
+}
+
+const (
+	defaultFullResultsTTL      = 10 * time.Minute
+	defaultFullResultsMaxBytes = 2 << 20
+)
+
+// NewFullResults returns a store holding results for ttl within maxBytes.
+// Zero values use 10 minutes and 2 MiB.
+func NewFullResults(ttl time.Duration, maxBytes int) *FullResults {
+	if ttl <= 0 {
+		ttl = defaultFullResultsTTL
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultFullResultsMaxBytes
+	}
+	return &FullResults{
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		entries:  make(map[string]fullResult),
+		now:      time.Now,
+	}
+}
+
+// Put stores text and returns its handle, or "" when text alone exceeds the
+// byte budget.
+func (c *FullResults) Put(text string) string {
+	if len(text) > c.maxBytes {
+		return ""
+	}
+	handle := newResultHandle()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneLocked()
+	for c.size+len(text) > c.maxBytes && len(c.order) > 0 {
+		c.removeLocked(c.order[0])
+	}
+	c.entries[handle] = fullResult{text: text, expires: c.now().Add(c.ttl)}
+	c.order = append(c.order, handle)
+	c.size += len(text)
+	return handle
+}
+
+// Get returns the text stored under handle if it has not expired.
+func (c *FullResults) Get(handle string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneLocked()
+	entry, ok := c.entries[handle]
+	return entry.text, ok
+}
+
+func (c *FullResults) pruneLocked() {
+	now := c.now()
+	for len(c.order) > 0 && !now.Before(c.entries[c.order[0]].expires) {
+		c.removeLocked(c.order[0])
+	}
+}
+
+func (c *FullResults) removeLocked(handle string) {
+	c.size -= len(c.entries[handle].text)
+	delete(c.entries, handle)
+	for i, h := range c.order {
+		if h == handle {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func newResultHandle() string {
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return "r" + hex.EncodeToString(b[:])
+}
+
+var defaultFullResults atomic.Pointer[FullResults]
+
+// SetDefaultFullResults sets the store truncating tools keep their output
+// in. nil turns keeping off.
+func SetDefaultFullResults(c *FullResults) {
+	defaultFullResults.Store(c)
+}
+
+// DefaultFullResults returns the store set by SetDefaultFullResults, or nil.
+func DefaultFullResults() *FullResults {
+	return defaultFullResults.Load()
+}
+
+// KeepFullResult stores the untruncated output of a tool in the default store
+// and returns a note for the tool's truncation marker that tells the model how
+// to read the rest. It returns "" when no store is set.
+func KeepFullResult(full string) string {
+	c := DefaultFullResults()
+	if c == nil {
+		return ""
+	}
+	handle := c.Put(full)
+	if handle == "" {
+		return ""
+	}
+	return fmt.Sprintf(" [full output: get_full_result handle=%s]", handle)
+}
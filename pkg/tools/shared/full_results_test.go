@@ -0,0 +1,53 @@
+package toolshared
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFullResults_ExpiresAndEvicts(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := NewFullResults(time.Minute, 10)
+	c.now = func() time.Time { return now }
+
+	first := c.Put("aaaaaa")
+	if got, ok := c.Get(first); !ok || got != "aaaaaa" {
+		t.Fatalf("Get(first) = %q, %v", got, ok)
+	}
+	second := c.Put("bbbbbb")
+	if _, ok := c.Get(first); ok {
+		t.Error("first result should be evicted once the byte budget is exceeded")
+	}
+	if got, _ := c.Get(second); got != "bbbbbb" {
+		t.Errorf("Get(second) = %q", got)
+	}
+	if h := c.Put("far too long for the budget"); h != "" {
+		t.Errorf("Put(oversized) = %q, want no handle", h)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get(second); ok {
+		t.Error("result should expire after the TTL")
+	}
+	if c.size != 0 || len(c.order) != 0 {
+		t.Errorf("size = %d, order = %v after expiry", c.size, c.order)
+	}
+}
+
+func TestKeepFullResult(t *testing.T) {
+	t.Cleanup(func() { SetDefaultFullResults(nil) })
+
+	SetDefaultFullResults(nil)
+	if note := KeepFullResult("output"); note != "" {
+		t.Fatalf("note without a store = %q", note)
+	}
+
+	c := NewFullResults(0, 0)
+	SetDefaultFullResults(c)
+	note := KeepFullResult("full output")
+	handle := strings.TrimSuffix(strings.TrimPrefix(note, " [full output: get_full_result handle="), "]")
+	if got, ok := c.Get(handle); !ok || got != "full output" {
+		t.Fatalf("note %q: Get(%q) = %q, %v", note, handle, got, ok)
+	}
+}
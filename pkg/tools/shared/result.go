@@ -41,6 +41,15 @@ type ToolResult struct {
 	// Used for internal error handling and logging.
 	Err error `json:"-"`
 
+	// ErrorCode classifies the failure when IsError is true. It is shown to
+	// the LLM next to the error text.
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+
+	// Idempotent marks the failed call as safe to repeat, so the registry
+	// retries it when ErrorCode is retryable. Tools set it only for calls
+	// without side effects, such as GET requests or searches.
+	Idempotent bool `json:"-"`
+
 	// Media contains media store refs produced by this tool.
 	// When non-empty, the agent will publish these as OutboundMediaMessage.
 	Media []string `json:"media,omitempty"`
@@ -71,6 +80,13 @@ func (tr *ToolResult) ContentForLLM() string {
 	if content == "" && tr.Err != nil {
 		content = tr.Err.Error()
 	}
+	if tr.IsError && tr.ErrorCode != "" {
+		if content == "" {
+			content = errorCodeLLMNote(tr.ErrorCode)
+		} else {
+			content += "\n" + errorCodeLLMNote(tr.ErrorCode)
+		}
+	}
 	if tr.ResponseHandled {
 		if content == "" {
 			return HandledToolLLMNote
@@ -207,15 +223,47 @@ func (tr *ToolResult) MarshalJSON() ([]byte, error) {
 
 // WithError sets the Err field and returns the result for chaining.
 // This preserves the error for logging while keeping it out of JSON.
+// When no ErrorCode is set yet, one is inferred from err.
 //
 // Example:
 //
 //	result := ErrorResult("Operation failed").WithError(err)
 func (tr *ToolResult) WithError(err error) *ToolResult {
 	tr.Err = err
+	if tr.ErrorCode == "" {
+		tr.ErrorCode = ClassifyError(err)
+	}
 	return tr
 }
 
+// WithErrorCode sets the ErrorCode field and returns the result for chaining.
+//
+// Example:
+//
+//	result := ErrorResult("Search API rate limit exceeded").WithErrorCode(ErrorCodeRateLimited)
+func (tr *ToolResult) WithErrorCode(code ErrorCode) *ToolResult {
+	tr.ErrorCode = code
+	return tr
+}
+
+// WithIdempotent marks the call as safe to repeat and returns the result for
+// chaining. Only idempotent calls are retried on a retryable ErrorCode.
+//
+// Example:
+//
+//	result := ErrorResult("search failed").WithError(err).WithIdempotent()
+func (tr *ToolResult) WithIdempotent() *ToolResult {
+	tr.Idempotent = true
+	return tr
+}
+
+func errorCodeLLMNote(code ErrorCode) string {
+	if code.Retryable() {
+		return "[error_code: " + string(code) + ", retryable]"
+	}
+	return "[error_code: " + string(code) + "]"
+}
+
 // WithResponseHandled marks the tool result as already delivered to the user.
 func (tr *ToolResult) WithResponseHandled() *ToolResult {
 	tr.ResponseHandled = true
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/session"
 	toolshared "github.com/sipeed/picoclaw/pkg/tools/shared"
@@ -21,10 +22,14 @@ type (
 	SessionInfo            = toolshared.SessionInfo
 	Tool                   = toolshared.Tool
 	AsyncCallback          = toolshared.AsyncCallback
+	ProgressCallback       = toolshared.ProgressCallback
 	AsyncExecutor          = toolshared.AsyncExecutor
 	PromptMetadata         = toolshared.PromptMetadata
 	PromptMetadataProvider = toolshared.PromptMetadataProvider
+	AvailabilityReporter   = toolshared.AvailabilityReporter
 	ToolResult             = toolshared.ToolResult
+	ErrorCode              = toolshared.ErrorCode
+	FullResults            = toolshared.FullResults
 )
 
 const (
@@ -36,8 +41,30 @@ const (
 	ToolPromptSlotMCP         = toolshared.ToolPromptSlotMCP
 	ToolPromptSourceRegistry  = toolshared.ToolPromptSourceRegistry
 	ToolPromptSourceDiscovery = toolshared.ToolPromptSourceDiscovery
+
+	ErrorCodeNotFound    = toolshared.ErrorCodeNotFound
+	ErrorCodePermission  = toolshared.ErrorCodePermission
+	ErrorCodeRateLimited = toolshared.ErrorCodeRateLimited
+	ErrorCodeInvalid     = toolshared.ErrorCodeInvalid
+	ErrorCodeTimeout     = toolshared.ErrorCodeTimeout
+	ErrorCodeNetwork     = toolshared.ErrorCodeNetwork
 )
 
+// keepFullResult keeps truncated output readable; see toolshared.KeepFullResult.
+var keepFullResult = toolshared.KeepFullResult
+
+func NewFullResults(ttl time.Duration, maxBytes int) *FullResults {
+	return toolshared.NewFullResults(ttl, maxBytes)
+}
+
+func SetDefaultFullResults(c *FullResults) {
+	toolshared.SetDefaultFullResults(c)
+}
+
+func DefaultFullResults() *FullResults {
+	return toolshared.DefaultFullResults()
+}
+
 func WithToolContext(ctx context.Context, channel, chatID string) context.Context {
 	return toolshared.WithToolContext(ctx, channel, chatID)
 }
@@ -89,6 +116,14 @@ func ToolSessionScope(ctx context.Context) *session.SessionScope {
 	return toolshared.ToolSessionScope(ctx)
 }
 
+func WithToolProgress(ctx context.Context, cb ProgressCallback) context.Context {
+	return toolshared.WithToolProgress(ctx, cb)
+}
+
+func ToolProgress(ctx context.Context) ProgressCallback {
+	return toolshared.ToolProgress(ctx)
+}
+
 func ToolToSchema(tool Tool) map[string]any {
 	return toolshared.ToolToSchema(tool)
 }
@@ -15,6 +15,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/creack/pty"
 
@@ -45,6 +46,7 @@ type ExecTool struct {
 	restrictToWorkspace bool
 	allowRemote         bool
 	sessionManager      *SessionManager
+	progressInterval    time.Duration
 }
 
 var (
@@ -121,6 +123,21 @@ var (
 	// absolutePathPattern matches absolute file paths in commands (Unix and Windows).
 	absolutePathPattern = regexp.MustCompile(`[A-Za-z]:\\[^\\\"']+|/[^\s\"']+`)
 
+	// parentDirPattern matches ".." used as a whole argument, as in "cd .." or
+	// "ls ..", which the slash-based traversal check does not see.
+	parentDirPattern = regexp.MustCompile(`(?:^|[\s;&|(=])\.\.(?:$|[\s;&|)])`)
+
+	// rootDirPattern matches "/" used as a whole argument; absolutePathPattern
+	// needs at least one character after the slash.
+	rootDirPattern = regexp.MustCompile(`(?:^|[\s;&|(=])/(?:$|[\s;&|)])`)
+
+	// bareCdPattern matches "cd" without a target, which changes to $HOME.
+	bareCdPattern = regexp.MustCompile(`(?:^|[;&|(]\s*)cd\s*(?:$|[;&|)])`)
+
+	// homeRefPattern matches shell references to the home directory: a "~"
+	// starting an argument and $HOME.
+	homeRefPattern = regexp.MustCompile(`(?:^|[\s=:"'(])~(?:/|$|[\s;&|)])|\$HOME\b`)
+
 	// safePaths are kernel pseudo-devices that are always safe to reference in
 	// commands, regardless of workspace restriction. They contain no user data
 	// and cannot cause destructive writes.
@@ -409,6 +426,18 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
+	if report := ToolProgress(ctx); report != nil {
+		progress := &execProgress{}
+		cmd.Stdout = io.MultiWriter(&stdout, progress)
+		cmd.Stderr = io.MultiWriter(&stderr, progress)
+		interval := t.progressInterval
+		if interval <= 0 {
+			interval = execProgressInterval
+		}
+		stopProgress := progress.start(interval, report)
+		defer stopProgress()
+	}
+
 	// Route shell execution through the shared isolation entry point so exec tool
 	// subprocesses receive the same isolation policy as other integrations.
 	if err := isolation.Start(cmd); err != nil {
@@ -447,10 +476,11 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 				msg += "\n\nPartial output before timeout:\n" + output
 			}
 			return &ToolResult{
-				ForLLM:  msg,
-				ForUser: msg,
-				IsError: true,
-				Err:     fmt.Errorf("command timeout: %w", err),
+				ForLLM:    msg,
+				ForUser:   msg,
+				IsError:   true,
+				Err:       fmt.Errorf("command timeout: %w", err),
+				ErrorCode: ErrorCodeTimeout,
 			}
 		}
 
@@ -475,7 +505,8 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 
 	maxLen := 10000
 	if len(output) > maxLen {
-		output = output[:maxLen] + fmt.Sprintf("\n... (truncated, %d more chars)", len(output)-maxLen)
+		output = output[:maxLen] + fmt.Sprintf("\n... (truncated, %d more chars)", len(output)-maxLen) +
+			keepFullResult(output)
 	}
 
 	if err != nil {
@@ -493,6 +524,92 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 	}
 }
 
+const (
+	// execProgressInterval is how often a running command's output is
+	// reported. Progress usually ends up as a chat message edit, so this
+	// stays well above the edit rate limits of the chat platforms.
+	execProgressInterval = 3 * time.Second
+	execProgressMaxLines = 15
+	execProgressMaxChars = 1500
+)
+
+// execProgress collects the combined stdout and stderr of a running command
+// and periodically reports its tail to a ProgressCallback.
+type execProgress struct {
+	mu      sync.Mutex
+	buf     []byte
+	changed bool
+}
+
+func (p *execProgress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.buf = append(p.buf, b...)
+	// Only the tail is ever reported, so keep the buffer bounded.
+	if len(p.buf) > 4*execProgressMaxChars {
+		p.buf = append(p.buf[:0], p.buf[len(p.buf)-2*execProgressMaxChars:]...)
+	}
+	p.changed = true
+	return len(b), nil
+}
+
+// tail returns the latest output, and false when nothing new was written
+// since the previous call.
+func (p *execProgress) tail() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.changed {
+		return "", false
+	}
+	p.changed = false
+	return execOutputTail(string(p.buf)), true
+}
+
+// start reports the output tail every interval until the returned stop
+// function is called. stop waits for the reporter to exit, so report is
+// never called after it returns.
+func (p *execProgress) start(interval time.Duration, report ProgressCallback) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if out, ok := p.tail(); ok && out != "" {
+					report(out)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+// execOutputTail keeps the last execProgressMaxLines lines of output, and at
+// most execProgressMaxChars bytes of those, without splitting a UTF-8 rune.
+func execOutputTail(output string) string {
+	output = strings.TrimRight(output, " \t\r\n")
+	lines := strings.Split(output, "\n")
+	if len(lines) > execProgressMaxLines {
+		output = strings.Join(lines[len(lines)-execProgressMaxLines:], "\n")
+	}
+	if len(output) > execProgressMaxChars {
+		start := len(output) - execProgressMaxChars
+		for start < len(output) && !utf8.RuneStart(output[start]) {
+			start++
+		}
+		output = output[start:]
+	}
+	return output
+}
+
 func (t *ExecTool) runBackground(ctx context.Context, command, cwd string, ptyEnabled bool) *ToolResult {
 	sessionID := generateSessionID()
 	session := &ProcessSession{
@@ -1094,6 +1211,17 @@ func expandPowerShellEnvVars(cmd string) string {
 	})
 }
 
+// expandHomeRefs replaces the home-directory references matched by
+// homeRefPattern with home.
+func expandHomeRefs(cmd, home string) string {
+	return homeRefPattern.ReplaceAllStringFunc(cmd, func(match string) string {
+		if match == "$HOME" {
+			return home
+		}
+		return strings.Replace(match, "~", home, 1)
+	})
+}
+
 func (t *ExecTool) guardCommand(command, cwd string) string {
 	cmd := strings.TrimSpace(command)
 	lower := strings.ToLower(cmd)
@@ -1130,9 +1258,15 @@ func (t *ExecTool) guardCommand(command, cwd string) string {
 
 	if t.restrictToWorkspace {
 		// Block path traversal patterns including .../.../ variants
-		if regexp.MustCompile(`\.\.(?:[\\/]\.\.)*[\\/]`).MatchString(cmd) {
+		if regexp.MustCompile(`\.\.(?:[\\/]\.\.)*[\\/]`).MatchString(cmd) || parentDirPattern.MatchString(cmd) {
 			return "Command blocked by safety guard (path traversal detected)"
 		}
+		if rootDirPattern.MatchString(cmd) {
+			return "Command blocked by safety guard (path outside working dir)"
+		}
+		if bareCdPattern.MatchString(cmd) {
+			return "Command blocked by safety guard (cd without a target leaves the workspace)"
+		}
 
 		cwdPath, err := filepath.Abs(cwd)
 		if err != nil {
@@ -1153,6 +1287,11 @@ func (t *ExecTool) guardCommand(command, cwd string) string {
 				cmd = strings.ReplaceAll(cmd, "~", filepath.FromSlash(home))
 			}
 		}
+		// Resolve home references so "cat ~/.ssh/id_rsa" is checked like the
+		// absolute path the shell would expand it to.
+		if home, err := os.UserHomeDir(); err == nil {
+			cmd = expandHomeRefs(cmd, home)
+		}
 
 		matchIndices := absolutePathPattern.FindAllStringIndex(cmd, -1)
 
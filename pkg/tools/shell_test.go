@@ -3,12 +3,15 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/require"
 
@@ -223,6 +226,59 @@ func TestShellTool_StderrCapture(t *testing.T) {
 	}
 }
 
+// TestShellTool_ReportsProgress verifies a running command's output tail is
+// passed to the progress callback and that reporting stops with the command.
+func TestShellTool_ReportsProgress(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh sleep")
+	}
+	tool, err := NewExecTool("", false)
+	require.NoError(t, err)
+	tool.progressInterval = 50 * time.Millisecond
+
+	var mu sync.Mutex
+	var updates []string
+	ctx := WithToolProgress(context.Background(), func(output string) {
+		mu.Lock()
+		defer mu.Unlock()
+		updates = append(updates, output)
+	})
+	result := tool.Execute(ctx, map[string]any{
+		"action":  "run",
+		"command": "echo step1; sleep 0.3; echo step2 >&2; sleep 0.3",
+	})
+	require.False(t, result.IsError, result.ForLLM)
+
+	mu.Lock()
+	got := append([]string(nil), updates...)
+	mu.Unlock()
+	require.NotEmpty(t, got)
+	require.Equal(t, "step1", got[0])
+	require.Equal(t, "step1\nstep2", got[len(got)-1])
+	for i := 1; i < len(got); i++ {
+		require.NotEqual(t, got[i-1], got[i], "unchanged output should not be reported again")
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, updates, len(got), "no progress after the command returned")
+}
+
+func TestExecOutputTail(t *testing.T) {
+	var lines []string
+	for i := 0; i < 40; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	tail := execOutputTail(strings.Join(lines, "\n") + "\n\n")
+	require.Equal(t, strings.Join(lines[40-execProgressMaxLines:], "\n"), tail)
+
+	long := strings.Repeat("é", execProgressMaxChars)
+	tail = execOutputTail(long)
+	require.LessOrEqual(t, len(tail), execProgressMaxChars)
+	require.True(t, utf8.ValidString(tail))
+}
+
 // TestShellTool_OutputTruncation verifies long output is truncated
 func TestShellTool_OutputTruncation(t *testing.T) {
 	tool, err := NewExecTool("", false)
@@ -426,6 +482,55 @@ func TestShellTool_RestrictToWorkspace(t *testing.T) {
 	}
 }
 
+// TestShellTool_RestrictToWorkspace_HomeAndParentEscapes verifies that
+// commands reaching outside the workspace through the home directory or a bare
+// ".." are blocked.
+func TestShellTool_RestrictToWorkspace_HomeAndParentEscapes(t *testing.T) {
+	tool, err := NewExecTool(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("unable to configure exec tool: %s", err)
+	}
+
+	for _, cmd := range []string{
+		"cat /etc/passwd",
+		"cd / && cat etc/passwd",
+		"ls /",
+		"cat ~/.bashrc",
+		"cat $HOME/.bashrc",
+		"cd ~ && ls",
+		"cd .. && ls",
+		"ls ..",
+		"cd; ls",
+		"cd",
+	} {
+		result := tool.Execute(context.Background(), map[string]any{"action": "run", "command": cmd})
+		if !result.IsError || !strings.Contains(result.ForLLM, "blocked") {
+			t.Errorf("command should be blocked: %q\n  got: %s", cmd, result.ForLLM)
+		}
+	}
+}
+
+// TestShellTool_RestrictToWorkspace_AllowsLookalikes verifies that the home
+// and parent-directory checks do not trip on similar-looking arguments.
+func TestShellTool_RestrictToWorkspace_AllowsLookalikes(t *testing.T) {
+	tool, err := NewExecTool(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("unable to configure exec tool: %s", err)
+	}
+
+	for _, cmd := range []string{
+		"echo a~b",
+		"echo v1..v2",
+		"echo cdrom",
+		"echo 6/3",
+	} {
+		result := tool.Execute(context.Background(), map[string]any{"action": "run", "command": cmd})
+		if result.IsError {
+			t.Errorf("command should be allowed: %q\n  got: %s", cmd, result.ForLLM)
+		}
+	}
+}
+
 // TestShellTool_RelativePathWithSlashAllowed verifies that local relative paths
 // under the workspace are not mistaken for absolute paths by the safety guard.
 func TestShellTool_RelativePathWithSlashAllowed(t *testing.T) {
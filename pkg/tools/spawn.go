@@ -7,6 +7,7 @@ import (
 )
 
 type SpawnTool struct {
+	manager        *SubagentManager
 	spawner        SubTurnSpawner
 	defaultModel   string
 	maxTokens      int
@@ -22,6 +23,7 @@ func NewSpawnTool(manager *SubagentManager) *SpawnTool {
 		return &SpawnTool{}
 	}
 	return &SpawnTool{
+		manager:      manager,
 		defaultModel: manager.defaultModel,
 		maxTokens:    manager.maxTokens,
 		temperature:  manager.temperature,
@@ -127,8 +129,24 @@ Task: %s`,
 
 	// Use spawner if available (direct SpawnSubTurn call)
 	if t.spawner != nil {
+		slot, err := t.manager.claimSlot()
+		if err != nil {
+			return ErrorResult(fmt.Sprintf(
+				"cannot spawn subagent: %v. Wait for a running subagent to report back, then try again.", err,
+			)).WithError(err)
+		}
+		queued := slot.Queued()
+
 		// Launch async sub-turn in goroutine
 		go func() {
+			if err := slot.Wait(ctx); err != nil {
+				if cb != nil {
+					cb(ctx, ErrorResult(fmt.Sprintf("Spawn canceled while queued: %v", err)).WithError(err))
+				}
+				return
+			}
+			defer slot.Release()
+
 			result, err := t.spawner.SpawnSubTurn(ctx, SubTurnConfig{
 				Model:         t.defaultModel,
 				Tools:         nil, // Will inherit from parent via context
@@ -150,10 +168,16 @@ Task: %s`,
 		}()
 
 		// Return immediate acknowledgment
+		what := "subagent"
 		if label != "" {
-			return AsyncResult(fmt.Sprintf("Spawned subagent '%s' for task: %s", label, task))
+			what = fmt.Sprintf("subagent '%s'", label)
+		}
+		if queued {
+			return AsyncResult(fmt.Sprintf(
+				"Queued %s for task (it starts when a running subagent finishes): %s", what, task,
+			))
 		}
-		return AsyncResult(fmt.Sprintf("Spawned subagent for task: %s", task))
+		return AsyncResult(fmt.Sprintf("Spawned %s for task: %s", what, task))
 	}
 
 	// Fallback: spawner not configured
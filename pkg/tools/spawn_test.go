@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -113,3 +114,71 @@ func TestSpawnTool_Execute_NilManager(t *testing.T) {
 		t.Errorf("Error message should mention manager not configured, got: %s", result.ForLLM)
 	}
 }
+
+// blockingSpawner holds every sub-turn until release is closed.
+type blockingSpawner struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingSpawner) SpawnSubTurn(ctx context.Context, cfg SubTurnConfig) (*ToolResult, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return &ToolResult{ForLLM: "done"}, nil
+}
+
+func TestSpawnTool_ConcurrencyLimitQueuesExcessSpawns(t *testing.T) {
+	manager := NewSubagentManager(&MockLLMProvider{}, "test-model", "/tmp/test")
+	manager.SetConcurrencyLimit(1, false)
+	tool := NewSpawnTool(manager)
+	spawner := &blockingSpawner{started: make(chan struct{}, 2), release: make(chan struct{})}
+	tool.SetSpawner(spawner)
+
+	done := make(chan *ToolResult, 2)
+	cb := func(_ context.Context, result *ToolResult) { done <- result }
+	ctx := context.Background()
+
+	first := tool.ExecuteAsync(ctx, map[string]any{"task": "first"}, cb)
+	<-spawner.started
+	second := tool.ExecuteAsync(ctx, map[string]any{"task": "second"}, cb)
+	if first.IsError || second.IsError {
+		t.Fatalf("spawns failed: %+v, %+v", first, second)
+	}
+	if !strings.Contains(second.ForLLM, "Queued subagent") {
+		t.Fatalf("second spawn = %q, want queued acknowledgment", second.ForLLM)
+	}
+	if running, queued := manager.Counts(); running != 1 || queued != 1 {
+		t.Fatalf("Counts() = %d running, %d queued, want 1 and 1", running, queued)
+	}
+
+	close(spawner.release)
+	<-done
+	<-spawner.started
+	<-done
+	if running, queued := manager.Counts(); running != 0 || queued != 0 {
+		t.Fatalf("Counts() after completion = %d running, %d queued, want 0 and 0", running, queued)
+	}
+}
+
+func TestSpawnTool_ConcurrencyLimitRejectsWhenConfigured(t *testing.T) {
+	manager := NewSubagentManager(&MockLLMProvider{}, "test-model", "/tmp/test")
+	manager.SetConcurrencyLimit(1, true)
+	tool := NewSpawnTool(manager)
+	spawner := &blockingSpawner{started: make(chan struct{}, 1), release: make(chan struct{})}
+	tool.SetSpawner(spawner)
+	defer close(spawner.release)
+
+	ctx := context.Background()
+	if result := tool.Execute(ctx, map[string]any{"task": "first"}); result.IsError {
+		t.Fatalf("first spawn failed: %s", result.ForLLM)
+	}
+	<-spawner.started
+
+	result := tool.Execute(ctx, map[string]any{"task": "second"})
+	if !result.IsError || !errors.Is(result.Err, ErrSubagentLimit) {
+		t.Fatalf("second spawn = %+v, want ErrSubagentLimit", result)
+	}
+	if !strings.Contains(result.ForLLM, "all 1 subagent slots are busy") {
+		t.Fatalf("ForLLM = %q, want slot explanation", result.ForLLM)
+	}
+}
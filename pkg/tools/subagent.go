@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -33,6 +34,10 @@ type SubTurnConfig struct {
 	TargetAgentID      string        // If set, run as this agent (its workspace, model, tools)
 }
 
+// ErrSubagentLimit is returned when every subagent slot is busy and the
+// manager rejects new spawns instead of queueing them.
+var ErrSubagentLimit = errors.New("subagent limit reached")
+
 type SubagentTask struct {
 	ID            string
 	Task          string
@@ -69,6 +74,12 @@ type SubagentManager struct {
 	nextID         int
 	spawner        SpawnSubTurnFunc
 
+	// slots caps how many subagents run at once; nil means no limit.
+	slots          chan struct{}
+	rejectWhenBusy bool
+	running        atomic.Int64
+	queued         atomic.Int64
+
 	// mediaResolver resolves media:// refs in tool-loop messages before
 	// each LLM call in the legacy RunToolLoop fallback path.
 	// This lets subagents reuse the same media handling behavior as the
@@ -133,17 +144,116 @@ func (sm *SubagentManager) RegisterTool(tool Tool) {
 	sm.tools.Register(tool)
 }
 
+// SetConcurrencyLimit caps how many spawned subagents run at once. Spawns
+// beyond the limit wait for a running subagent to finish, or fail with
+// ErrSubagentLimit when reject is true. max <= 0 removes the limit. It must
+// be called before the first spawn.
+func (sm *SubagentManager) SetConcurrencyLimit(max int, reject bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.slots = nil
+	if max > 0 {
+		sm.slots = make(chan struct{}, max)
+	}
+	sm.rejectWhenBusy = reject
+}
+
+// Counts reports how many subagents are running and how many wait for a
+// free slot.
+func (sm *SubagentManager) Counts() (running, queued int) {
+	return int(sm.running.Load()), int(sm.queued.Load())
+}
+
+// subagentSlot is a claim on one of the manager's run slots. A slot that
+// was not free when claimed is queued until Wait returns.
+type subagentSlot struct {
+	sm       *SubagentManager
+	acquired bool
+}
+
+// claimSlot takes a free run slot if there is one. Otherwise the returned
+// slot is queued, or ErrSubagentLimit is returned when the manager rejects
+// spawns at the limit. A nil manager hands out unlimited slots.
+func (sm *SubagentManager) claimSlot() (*subagentSlot, error) {
+	if sm == nil {
+		return &subagentSlot{acquired: true}, nil
+	}
+	sm.mu.RLock()
+	slots, reject := sm.slots, sm.rejectWhenBusy
+	sm.mu.RUnlock()
+
+	if slots == nil {
+		sm.running.Add(1)
+		return &subagentSlot{sm: sm, acquired: true}, nil
+	}
+	select {
+	case slots <- struct{}{}:
+		sm.running.Add(1)
+		return &subagentSlot{sm: sm, acquired: true}, nil
+	default:
+	}
+	if reject {
+		return nil, fmt.Errorf("%w: all %d subagent slots are busy", ErrSubagentLimit, cap(slots))
+	}
+	sm.queued.Add(1)
+	return &subagentSlot{sm: sm}, nil
+}
+
+// Queued reports whether the slot has to wait for a running subagent.
+func (s *subagentSlot) Queued() bool {
+	return !s.acquired
+}
+
+// Wait blocks until a queued slot is free. It returns ctx's error if ctx
+// ends first, after which the slot must not be released.
+func (s *subagentSlot) Wait(ctx context.Context) error {
+	if s.acquired {
+		return nil
+	}
+	defer s.sm.queued.Add(-1)
+	select {
+	case s.sm.slots <- struct{}{}:
+		s.acquired = true
+		s.sm.running.Add(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees the slot for the next queued subagent.
+func (s *subagentSlot) Release() {
+	if s.sm == nil || !s.acquired {
+		return
+	}
+	s.acquired = false
+	s.sm.running.Add(-1)
+	if s.sm.slots != nil {
+		<-s.sm.slots
+	}
+}
+
 func (sm *SubagentManager) Spawn(
 	ctx context.Context,
 	task, label, agentID, originChannel, originChatID string,
 	callback AsyncCallback,
 ) (string, error) {
+	slot, err := sm.claimSlot()
+	if err != nil {
+		return "", err
+	}
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
 	taskID := fmt.Sprintf("subagent-%d", sm.nextID)
 	sm.nextID++
 
+	queued := slot.Queued()
+	status := "running"
+	if queued {
+		status = "queued"
+	}
 	subagentTask := &SubagentTask{
 		ID:            taskID,
 		Task:          task,
@@ -151,31 +261,49 @@ func (sm *SubagentManager) Spawn(
 		AgentID:       agentID,
 		OriginChannel: originChannel,
 		OriginChatID:  originChatID,
-		Status:        "running",
+		Status:        status,
 		Created:       time.Now().UnixMilli(),
 	}
 	sm.tasks[taskID] = subagentTask
 
 	// Start task in background with context cancellation support
-	go sm.runTask(ctx, subagentTask, callback)
+	go sm.runTask(ctx, subagentTask, slot, callback)
 
+	what := "subagent"
 	if label != "" {
-		return fmt.Sprintf("Spawned subagent '%s' for task: %s", label, task), nil
+		what = fmt.Sprintf("subagent '%s'", label)
+	}
+	if queued {
+		return fmt.Sprintf("Queued %s for task (waiting for a free subagent slot): %s", what, task), nil
 	}
-	return fmt.Sprintf("Spawned subagent for task: %s", task), nil
+	return fmt.Sprintf("Spawned %s for task: %s", what, task), nil
 }
 
 func (sm *SubagentManager) runTask(
 	ctx context.Context,
 	task *SubagentTask,
+	slot *subagentSlot,
 	callback AsyncCallback,
 ) {
-	task.Status = "running"
-	task.Created = time.Now().UnixMilli()
 	// TODO(eventbus): once subagents are modeled as child turns inside
 	// pkg/agent, emit SubTurnEnd and SubTurnResultDelivered from the parent
 	// AgentLoop instead of this legacy manager.
 
+	// A queued task is canceled if its context ends before a slot frees up.
+	if err := slot.Wait(ctx); err != nil {
+		sm.mu.Lock()
+		task.Status = "canceled"
+		task.Result = "Task canceled before execution"
+		sm.mu.Unlock()
+		return
+	}
+	defer slot.Release()
+
+	sm.mu.Lock()
+	task.Status = "running"
+	task.Created = time.Now().UnixMilli()
+	sm.mu.Unlock()
+
 	// Check if context is already canceled before starting
 	select {
 	case <-ctx.Done():
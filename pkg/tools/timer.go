@@ -0,0 +1,310 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	defaultTimerLabel = "timer"
+	// maxTimerDuration caps countdowns; anything longer belongs in a reminder,
+	// which survives restarts.
+	maxTimerDuration    = 24 * time.Hour
+	maxTimersPerSession = 20
+)
+
+// TimerPublishFunc sends a timer's expiry message, normally the message
+// bus's PublishOutbound.
+type TimerPublishFunc func(ctx context.Context, msg bus.OutboundMessage) error
+
+type sessionTimer struct {
+	label    string
+	started  time.Time
+	duration time.Duration // zero for a stopwatch
+	timer    *time.Timer
+}
+
+// TimerTool runs short countdowns and stopwatches for the current session.
+// Unlike reminders they live in memory only: they are gone after a restart,
+// when the session is cleared, or when the tool is closed. A countdown that
+// runs out sends a message back to the chat it was started from.
+type TimerTool struct {
+	publish TimerPublishFunc
+	now     func() time.Time
+
+	mu     sync.Mutex
+	timers map[string]map[string]*sessionTimer // session key -> label -> timer
+}
+
+// NewTimerTool creates a TimerTool that delivers expiry messages via publish.
+func NewTimerTool(publish TimerPublishFunc) *TimerTool {
+	return &TimerTool{
+		publish: publish,
+		now:     time.Now,
+		timers:  make(map[string]map[string]*sessionTimer),
+	}
+}
+
+func (t *TimerTool) Name() string {
+	return "timer"
+}
+
+func (t *TimerTool) Description() string {
+	return "Start, check, or cancel short in-session timers. With a duration, start sets a countdown and a " +
+		"message is sent to this chat when it runs out ('set a 10 minute timer for the tea'); without one it " +
+		"starts a stopwatch. Timers are not kept across restarts; use the reminder tool for anything longer " +
+		"than a day or that must survive a restart."
+}
+
+func (t *TimerTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"start", "check", "cancel"},
+				"description": "start creates a timer, check reports time left or elapsed, cancel stops one.",
+			},
+			"label": map[string]any{
+				"type": "string",
+				"description": "Name of the timer, e.g. 'tea'. Defaults to 'timer'. " +
+					"Omit for check to list every timer in this session.",
+			},
+			"duration": map[string]any{
+				"type": "string",
+				"description": "Countdown length such as '10 minutes', '1h30m' or '90s'. " +
+					"Omit to start a stopwatch instead.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *TimerTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	label, _ := args["label"].(string)
+	label = strings.TrimSpace(label)
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case "start":
+		duration, _ := args["duration"].(string)
+		return t.start(ctx, label, strings.TrimSpace(duration))
+	case "check":
+		return t.check(ctx, label)
+	case "cancel":
+		return t.cancel(ctx, label)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action %q (expected start, check, or cancel)", action))
+	}
+}
+
+func (t *TimerTool) start(ctx context.Context, label, duration string) *ToolResult {
+	channel := ToolChannel(ctx)
+	chatID := ToolChatID(ctx)
+	if channel == "" || chatID == "" {
+		return ErrorResult("no session context (channel/chat_id not set). Use this tool in an active conversation.")
+	}
+	if label == "" {
+		label = defaultTimerLabel
+	}
+
+	var d time.Duration
+	if duration != "" {
+		var err error
+		if d, err = parseReminderDuration(strings.TrimPrefix(strings.ToLower(duration), "in ")); err != nil {
+			return ErrorResult(err.Error())
+		}
+		if d > maxTimerDuration {
+			return ErrorResult(fmt.Sprintf("%s is longer than a timer allows (%s); use the reminder tool instead",
+				formatTimerDuration(d), formatTimerDuration(maxTimerDuration)))
+		}
+	}
+
+	key := timerSessionKey(ctx)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	session := t.timers[key]
+	if _, exists := session[label]; exists {
+		return ErrorResult(fmt.Sprintf("a timer named %q is already running; cancel it first or pick another label",
+			label))
+	}
+	if len(session) >= maxTimersPerSession {
+		return ErrorResult(fmt.Sprintf("this session already has %d timers; cancel one first", len(session)))
+	}
+	if session == nil {
+		session = make(map[string]*sessionTimer)
+		t.timers[key] = session
+	}
+
+	st := &sessionTimer{label: label, started: t.now(), duration: d}
+	session[label] = st
+	if d == 0 {
+		return SilentResult(fmt.Sprintf("Stopwatch %q started", label))
+	}
+	st.timer = time.AfterFunc(d, func() { t.expire(key, st, channel, chatID) })
+	return SilentResult(fmt.Sprintf("Timer %q set for %s; this chat gets a message when it is up", label,
+		formatTimerDuration(d)))
+}
+
+// expire removes st and tells the chat its countdown is over. The message is
+// marked proactive, so it is held during quiet hours like other messages the
+// agent sends on its own.
+func (t *TimerTool) expire(key string, st *sessionTimer, channel, chatID string) {
+	t.mu.Lock()
+	current, ok := t.timers[key][st.label]
+	if !ok || current != st {
+		t.mu.Unlock()
+		return
+	}
+	t.removeLocked(key, st.label)
+	t.mu.Unlock()
+
+	content := fmt.Sprintf("⏰ Your %s timer is up.", formatTimerDuration(st.duration))
+	if st.label != defaultTimerLabel {
+		content = fmt.Sprintf("⏰ Your %s timer %q is up.", formatTimerDuration(st.duration), st.label)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := t.publish(ctx, bus.OutboundMessage{
+		Context:   bus.NewOutboundContext(channel, chatID, ""),
+		Content:   content,
+		Proactive: true,
+	})
+	if err != nil {
+		logger.WarnCF("tool", "Failed to deliver timer", map[string]any{
+			"label":   st.label,
+			"channel": channel,
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+func (t *TimerTool) check(ctx context.Context, label string) *ToolResult {
+	key := timerSessionKey(ctx)
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	session := t.timers[key]
+	if label != "" {
+		st, ok := session[label]
+		if !ok {
+			return ErrorResult(fmt.Sprintf("no timer named %q in this session", label))
+		}
+		return SilentResult(describeTimer(st, now))
+	}
+	if len(session) == 0 {
+		return SilentResult("No timers running")
+	}
+	labels := make([]string, 0, len(session))
+	for l := range session {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+	var sb strings.Builder
+	sb.WriteString("Timers:\n")
+	for _, l := range labels {
+		fmt.Fprintf(&sb, "- %s\n", describeTimer(session[l], now))
+	}
+	return SilentResult(sb.String())
+}
+
+func (t *TimerTool) cancel(ctx context.Context, label string) *ToolResult {
+	if label == "" {
+		label = defaultTimerLabel
+	}
+	key := timerSessionKey(ctx)
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st, ok := t.timers[key][label]
+	if !ok {
+		return ErrorResult(fmt.Sprintf("no timer named %q in this session", label))
+	}
+	t.removeLocked(key, label)
+	if st.duration == 0 {
+		return SilentResult(fmt.Sprintf("Stopwatch %q stopped at %s", label, formatTimerDuration(now.Sub(st.started))))
+	}
+	return SilentResult(fmt.Sprintf("Timer %q cancelled with %s left", label,
+		formatTimerDuration(st.started.Add(st.duration).Sub(now))))
+}
+
+// CancelSession stops every timer of sessionKey, for example when the
+// session is cleared.
+func (t *TimerTool) CancelSession(sessionKey string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for label := range t.timers[sessionKey] {
+		t.removeLocked(sessionKey, label)
+	}
+}
+
+// Close stops all timers. Countdowns that have not run out are dropped.
+func (t *TimerTool) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, session := range t.timers {
+		for label := range session {
+			t.removeLocked(key, label)
+		}
+	}
+}
+
+func (t *TimerTool) removeLocked(key, label string) {
+	session := t.timers[key]
+	if st, ok := session[label]; ok && st.timer != nil {
+		st.timer.Stop()
+	}
+	delete(session, label)
+	if len(session) == 0 {
+		delete(t.timers, key)
+	}
+}
+
+// timerSessionKey scopes timers to the conversation. Tools called outside a
+// session fall back to the chat they run in.
+func timerSessionKey(ctx context.Context) string {
+	if key := ToolSessionKey(ctx); key != "" {
+		return key
+	}
+	return ToolChannel(ctx) + ":" + ToolChatID(ctx)
+}
+
+func describeTimer(st *sessionTimer, now time.Time) string {
+	if st.duration == 0 {
+		return fmt.Sprintf("stopwatch %q: %s elapsed", st.label, formatTimerDuration(now.Sub(st.started)))
+	}
+	return fmt.Sprintf("timer %q: %s left of %s", st.label,
+		formatTimerDuration(st.started.Add(st.duration).Sub(now)), formatTimerDuration(st.duration))
+}
+
+// formatTimerDuration renders d to the second as "1h 5m", "10m" or "45s".
+func formatTimerDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Second {
+		return "0s"
+	}
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	seconds := int(d % time.Minute / time.Second)
+
+	var parts []string
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	if seconds > 0 {
+		parts = append(parts, fmt.Sprintf("%ds", seconds))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+type timerOutbox struct {
+	mu   sync.Mutex
+	sent []bus.OutboundMessage
+	done chan struct{}
+}
+
+func newTimerOutbox() *timerOutbox {
+	return &timerOutbox{done: make(chan struct{}, 10)}
+}
+
+func (o *timerOutbox) publish(_ context.Context, msg bus.OutboundMessage) error {
+	o.mu.Lock()
+	o.sent = append(o.sent, msg)
+	o.mu.Unlock()
+	o.done <- struct{}{}
+	return nil
+}
+
+func TestTimerTool_CountdownMessagesOriginatingChat(t *testing.T) {
+	outbox := newTimerOutbox()
+	tool := NewTimerTool(outbox.publish)
+	defer tool.Close()
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+
+	result := tool.Execute(ctx, map[string]any{"action": "start", "label": "tea", "duration": "50ms"})
+	if result.IsError {
+		t.Fatalf("start failed: %s", result.ForLLM)
+	}
+
+	select {
+	case <-outbox.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timer did not fire")
+	}
+	msg := bus.NormalizeOutboundMessage(outbox.sent[0])
+	if msg.Channel != "telegram" || msg.ChatID != "chat-1" {
+		t.Fatalf("timer sent to %s:%s, want telegram:chat-1", msg.Channel, msg.ChatID)
+	}
+	if !msg.Proactive {
+		t.Fatal("timer message must be proactive so quiet hours apply")
+	}
+	if !strings.Contains(msg.Content, `"tea" is up`) {
+		t.Fatalf("content = %q", msg.Content)
+	}
+	if got := tool.Execute(ctx, map[string]any{"action": "check"}); got.ForLLM != "No timers running" {
+		t.Fatalf("check after expiry = %q", got.ForLLM)
+	}
+}
+
+func TestTimerTool_CheckAndCancel(t *testing.T) {
+	outbox := newTimerOutbox()
+	tool := NewTimerTool(outbox.publish)
+	defer tool.Close()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tool.now = func() time.Time { return now }
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+
+	tool.Execute(ctx, map[string]any{"action": "start", "duration": "10 minutes"})
+	tool.Execute(ctx, map[string]any{"action": "start", "label": "run"})
+	if got := tool.Execute(ctx, map[string]any{"action": "start", "duration": "5m"}); !got.IsError {
+		t.Fatal("starting a second timer with the same label should fail")
+	}
+
+	now = now.Add(4 * time.Minute)
+	list := tool.Execute(ctx, map[string]any{"action": "check"}).ForLLM
+	if !strings.Contains(list, `timer "timer": 6m left of 10m`) ||
+		!strings.Contains(list, `stopwatch "run": 4m elapsed`) {
+		t.Fatalf("check = %q", list)
+	}
+
+	other := WithToolContext(context.Background(), "telegram", "chat-2")
+	if got := tool.Execute(other, map[string]any{"action": "check"}).ForLLM; got != "No timers running" {
+		t.Fatalf("timers leaked into another chat: %q", got)
+	}
+
+	if got := tool.Execute(ctx, map[string]any{"action": "cancel"}); !strings.Contains(got.ForLLM, "6m left") {
+		t.Fatalf("cancel = %q", got.ForLLM)
+	}
+	if got := tool.Execute(ctx, map[string]any{"action": "cancel", "label": "run"}); !strings.Contains(
+		got.ForLLM, "stopped at 4m") {
+		t.Fatalf("cancel stopwatch = %q", got.ForLLM)
+	}
+	if got := tool.Execute(ctx, map[string]any{"action": "cancel"}); !got.IsError {
+		t.Fatal("cancelling a missing timer should fail")
+	}
+}
+
+func TestTimerTool_RejectsBadInput(t *testing.T) {
+	tool := NewTimerTool(newTimerOutbox().publish)
+	defer tool.Close()
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+
+	for _, args := range []map[string]any{
+		{"action": "start", "duration": "two days"},
+		{"action": "start", "duration": "25 hours"},
+		{"action": "pause"},
+	} {
+		if got := tool.Execute(ctx, args); !got.IsError {
+			t.Errorf("Execute(%v) succeeded: %q", args, got.ForLLM)
+		}
+	}
+	if got := tool.Execute(context.Background(), map[string]any{"action": "start"}); !got.IsError {
+		t.Error("start without a chat should fail")
+	}
+}
+
+func TestTimerTool_CancelSessionStopsCountdowns(t *testing.T) {
+	outbox := newTimerOutbox()
+	tool := NewTimerTool(outbox.publish)
+	ctx := WithToolSessionContext(WithToolContext(context.Background(), "telegram", "chat-1"),
+		"main", "session-1", nil)
+
+	tool.Execute(ctx, map[string]any{"action": "start", "duration": "50ms"})
+	tool.CancelSession("session-1")
+	select {
+	case <-outbox.done:
+		t.Fatal("a cancelled session's timer fired")
+	case <-time.After(150 * time.Millisecond):
+	}
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// ToolStats counts the executions of one tool since the process started.
+// Async tools count as successful once they have started.
+type ToolStats struct {
+	Invocations     int64 `json:"invocations"`
+	Successes       int64 `json:"successes"`
+	Failures        int64 `json:"failures"`
+	TotalDurationMs int64 `json:"total_duration_ms"`
+	// ErrorCodes counts failures by ToolResult.ErrorCode. Failures without
+	// a code are only counted in Failures.
+	ErrorCodes map[ErrorCode]int64 `json:"error_codes,omitempty"`
+}
+
+// Add returns the sum of s and other.
+func (s ToolStats) Add(other ToolStats) ToolStats {
+	sum := ToolStats{
+		Invocations:     s.Invocations + other.Invocations,
+		Successes:       s.Successes + other.Successes,
+		Failures:        s.Failures + other.Failures,
+		TotalDurationMs: s.TotalDurationMs + other.TotalDurationMs,
+	}
+	for _, codes := range []map[ErrorCode]int64{s.ErrorCodes, other.ErrorCodes} {
+		for code, n := range codes {
+			if sum.ErrorCodes == nil {
+				sum.ErrorCodes = make(map[ErrorCode]int64)
+			}
+			sum.ErrorCodes[code] += n
+		}
+	}
+	return sum
+}
+
+// toolStatsRecorder accumulates ToolStats per tool name. A registry and its
+// clones share one recorder, so calls made by subagents and sub-turns count
+// toward the agent they were cloned from.
+type toolStatsRecorder struct {
+	mu    sync.Mutex
+	stats map[string]*toolStatsEntry
+}
+
+type toolStatsEntry struct {
+	ToolStats
+	total time.Duration
+}
+
+func newToolStatsRecorder() *toolStatsRecorder {
+	return &toolStatsRecorder{stats: make(map[string]*toolStatsEntry)}
+}
+
+func (s *toolStatsRecorder) record(name string, failed bool, code ErrorCode, duration time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.stats[name]
+	if !ok {
+		entry = &toolStatsEntry{}
+		s.stats[name] = entry
+	}
+	entry.Invocations++
+	if failed {
+		entry.Failures++
+		if code != "" {
+			if entry.ErrorCodes == nil {
+				entry.ErrorCodes = make(map[ErrorCode]int64)
+			}
+			entry.ErrorCodes[code]++
+		}
+	} else {
+		entry.Successes++
+	}
+	entry.total += duration
+	entry.TotalDurationMs = entry.total.Milliseconds()
+}
+
+func (s *toolStatsRecorder) snapshot() map[string]ToolStats {
+	if s == nil {
+		return map[string]ToolStats{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]ToolStats, len(s.stats))
+	for name, entry := range s.stats {
+		stats := entry.ToolStats
+		stats.ErrorCodes = maps.Clone(entry.ErrorCodes)
+		out[name] = stats
+	}
+	return out
+}
@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// scriptLanguages maps writing systems used by a single major language to
+// its tag. Han, Cyrillic and Arabic need a closer look and are handled in
+// detectScript.
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	tag   string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// latinWords holds short, frequent words for languages written in the Latin
+// script. A word listed for several languages counts for each of them.
+var latinWords = map[string][]string{
+	"en": {
+		"the", "and", "is", "are", "you", "to", "of", "what", "how", "this", "that", "with",
+		"for", "it", "have", "can", "do", "not", "my", "please", "hello", "hi", "thanks", "i",
+	},
+	"es": {
+		"el", "la", "los", "las", "que", "qué", "de", "y", "es", "por", "para", "con", "una",
+		"como", "cómo", "está", "estás", "pero", "mi", "lo", "del", "se", "hola", "gracias", "yo",
+	},
+	"fr": {
+		"le", "la", "les", "et", "est", "que", "de", "des", "un", "une", "pour", "avec", "pas",
+		"je", "vous", "ce", "qui", "dans", "sur", "mon", "c'est", "bonjour", "merci", "salut",
+	},
+	"de": {
+		"der", "die", "das", "und", "ist", "nicht", "ich", "du", "sie", "ein", "eine", "mit",
+		"für", "auf", "wie", "was", "zu", "den", "dem", "es", "hallo", "danke", "bitte",
+	},
+	"pt": {
+		"o", "a", "os", "as", "que", "de", "e", "é", "um", "uma", "para", "com", "não", "por",
+		"você", "do", "da", "em", "está", "como", "meu", "olá", "obrigado", "obrigada",
+	},
+	"it": {
+		"il", "lo", "la", "gli", "le", "che", "di", "e", "è", "un", "una", "per", "con", "non",
+		"sono", "come", "cosa", "del", "della", "mi", "ciao", "grazie",
+	},
+	"nl": {
+		"de", "het", "een", "en", "is", "van", "ik", "je", "niet", "dat", "met", "voor", "op",
+		"zijn", "wat", "hoe", "ook", "maar", "hallo", "bedankt",
+	},
+	"id": {
+		"yang", "dan", "di", "ini", "itu", "dengan", "untuk", "tidak", "saya", "apa", "ada",
+		"ke", "dari", "bisa", "akan", "terima", "kasih",
+	},
+	"tr": {
+		"ve", "bir", "bu", "da", "de", "ne", "için", "ile", "mi", "çok", "ben", "sen", "nasıl",
+		"var", "yok", "değil", "merhaba", "teşekkürler",
+	},
+	"pl": {
+		"i", "w", "nie", "się", "na", "że", "jest", "to", "z", "do", "jak", "co", "czy", "mam",
+		"ale", "dla", "cześć", "dziękuję",
+	},
+	"sv": {
+		"och", "att", "det", "är", "en", "som", "på", "jag", "inte", "med", "för", "har", "vad",
+		"hur", "kan", "du", "hej", "tack",
+	},
+}
+
+// latinMarks are letters or punctuation that appear in one Latin-script
+// language only and weigh as much as two matching words.
+var latinMarks = map[rune]string{
+	'ñ': "es", '¿': "es", '¡': "es",
+	'ã': "pt", 'õ': "pt",
+	'ß': "de",
+	'ğ': "tr", 'ı': "tr", 'ş': "tr",
+	'ą': "pl", 'ę': "pl", 'ł': "pl", 'ś': "pl", 'ź': "pl", 'ż': "pl",
+	'å': "sv",
+}
+
+var latinWordLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for tag, words := range latinWords {
+		for _, w := range words {
+			index[w] = append(index[w], tag)
+		}
+	}
+	return index
+}()
+
+var languageNames = map[string]string{
+	"ar": "Arabic", "de": "German", "el": "Greek", "en": "English", "es": "Spanish",
+	"fa": "Persian", "fr": "French", "he": "Hebrew", "hi": "Hindi", "id": "Indonesian",
+	"it": "Italian", "ja": "Japanese", "ko": "Korean", "nl": "Dutch", "pl": "Polish",
+	"pt": "Portuguese", "ru": "Russian", "sv": "Swedish", "th": "Thai", "tr": "Turkish",
+	"uk": "Ukrainian", "zh": "Chinese",
+}
+
+// DetectLanguage guesses the language of text and returns its tag with a
+// confidence between 0 and 1. It recognizes languages by script, and
+// Latin-script languages by their most frequent words, so it is fast and
+// needs no model, but it only knows the languages in LanguageName. An empty
+// tag means the text gave no evidence.
+func DetectLanguage(text string) (string, float64) {
+	if tag, confidence, ok := detectScript(text); ok {
+		return tag, confidence
+	}
+	return detectLatin(text)
+}
+
+// LanguageName returns the English name for a tag DetectLanguage returns,
+// or the tag itself when it is unknown.
+func LanguageName(tag string) string {
+	if name, ok := languageNames[tag]; ok {
+		return name
+	}
+	return tag
+}
+
+// detectScript reports a language when most letters of text belong to a
+// script other than Latin.
+func detectScript(text string) (string, float64, bool) {
+	counts := make(map[string]int)
+	letters, latin := 0, 0
+	kana, ukrainian, persian := false, false, false
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana = true
+			counts["cjk"]++
+		case unicode.Is(unicode.Han, r):
+			counts["cjk"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			ukrainian = ukrainian || strings.ContainsRune("іїєґІЇЄҐ", r)
+			counts["cyrillic"]++
+		case unicode.Is(unicode.Arabic, r):
+			persian = persian || strings.ContainsRune("پچژگ", r)
+			counts["arabic"]++
+		default:
+			for _, s := range scriptLanguages {
+				if unicode.Is(s.table, r) {
+					counts[s.tag]++
+					break
+				}
+			}
+		}
+	}
+	if letters == 0 || latin*2 >= letters {
+		return "", 0, false
+	}
+
+	best, bestCount := "", 0
+	for script, n := range counts {
+		if n > bestCount || (n == bestCount && script < best) {
+			best, bestCount = script, n
+		}
+	}
+	if best == "" {
+		return "", 0, false
+	}
+	confidence := float64(bestCount) / float64(letters)
+	switch best {
+	case "cjk":
+		if kana {
+			return "ja", confidence, true
+		}
+		return "zh", confidence, true
+	case "cyrillic":
+		if ukrainian {
+			return "uk", confidence, true
+		}
+		return "ru", confidence, true
+	case "arabic":
+		if persian {
+			return "fa", confidence, true
+		}
+		return "ar", confidence, true
+	}
+	return best, confidence, true
+}
+
+// detectLatin scores each Latin-script language by the frequent words and
+// distinctive letters found in text. Confidence is the best score's share of
+// the two highest, so text that fits two languages equally scores 0.5.
+func detectLatin(text string) (string, float64) {
+	scores := make(map[string]int)
+	for _, r := range strings.ToLower(text) {
+		if tag, ok := latinMarks[r]; ok {
+			scores[tag] += 2
+		}
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, w := range words {
+		for _, tag := range latinWordLanguages[strings.Trim(w, "'")] {
+			scores[tag]++
+		}
+	}
+
+	best, second := "", 0
+	bestScore := 0
+	for tag, score := range scores {
+		switch {
+		case score > bestScore || (score == bestScore && tag < best):
+			if best != "" {
+				second = max(second, bestScore)
+			}
+			best, bestScore = tag, score
+		case score > second:
+			second = score
+		}
+	}
+	// A single matching word is too little to go on.
+	if bestScore < 2 {
+		return "", 0
+	}
+	return best, float64(bestScore) / float64(bestScore+second)
+}
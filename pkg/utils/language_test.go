@@ -0,0 +1,59 @@
+package utils
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hello, can you tell me what the weather is like today?", "en"},
+		{"Hola, ¿cómo estás? Necesito ayuda con mi cuenta", "es"},
+		{"Bonjour, je voudrais savoir pourquoi le serveur est en panne", "fr"},
+		{"Hallo, ich habe eine Frage zu der Rechnung", "de"},
+		{"Olá, você pode me ajudar com a configuração? Não está funcionando", "pt"},
+		{"Ciao, non riesco a capire come funziona il sistema", "it"},
+		{"Hej, jag har en fråga om det här", "sv"},
+		{"今天天气怎么样？", "zh"},
+		{"今日の天気はどうですか？", "ja"},
+		{"오늘 날씨 어때요?", "ko"},
+		{"Привет, как дела?", "ru"},
+		{"Привіт, як справи? Що нового?", "uk"},
+		{"مرحبا، كيف حالك؟", "ar"},
+	}
+	for _, tt := range tests {
+		got, confidence := DetectLanguage(tt.text)
+		if got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q (%.2f), want %q", tt.text, got, confidence, tt.want)
+			continue
+		}
+		if confidence < 0.6 {
+			t.Errorf("DetectLanguage(%q) confidence = %.2f, want >= 0.6", tt.text, confidence)
+		}
+	}
+}
+
+func TestDetectLanguage_NoEvidence(t *testing.T) {
+	for _, text := range []string{"", "ok", "12345 !!!", "https://example.com/x"} {
+		if got, confidence := DetectLanguage(text); got != "" || confidence != 0 {
+			t.Errorf("DetectLanguage(%q) = %q (%.2f), want no language", text, got, confidence)
+		}
+	}
+}
+
+func TestDetectLanguage_AmbiguousTextHasLowConfidence(t *testing.T) {
+	// "de la" is Spanish, French and more.
+	_, confidence := DetectLanguage("de la")
+	if confidence > 0.5 {
+		t.Fatalf("confidence = %.2f for ambiguous text, want <= 0.5", confidence)
+	}
+}
+
+func TestLanguageName(t *testing.T) {
+	if got := LanguageName("es"); got != "Spanish" {
+		t.Fatalf("LanguageName(es) = %q", got)
+	}
+	if got := LanguageName("xx"); got != "xx" {
+		t.Fatalf("LanguageName(xx) = %q", got)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -165,7 +166,7 @@ func (h *Handler) handlePatchConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Recursively merge patch into base
-	mergeMap(base, patch)
+	config.MergePatch(base, patch)
 
 	// When the patch updates dm_scope, the old derived dimensions from the
 	// base must be cleared so that ApplyDmScope() can re-derive them from
@@ -321,133 +322,22 @@ func (h *Handler) handleTestCommandPatterns(w http.ResponseWriter, r *http.Reque
 }
 
 // validateConfig checks the config for common errors before saving.
-// Returns a list of human-readable error strings; empty means valid.
+// Returns a list of human-readable error strings; empty means valid. Besides
+// hard errors it rejects enabled channels with missing credentials, which
+// config.Validate only warns about, since saving one from the UI is always a
+// mistake.
 func validateConfig(cfg *config.Config) []string {
 	var errs []string
-
-	// Validate model_list entries
-	if err := cfg.ValidateModelList(); err != nil {
-		errs = append(errs, err.Error())
-	}
-
-	if err := cfg.ValidateTurnProfile(); err != nil {
-		errs = append(errs, err.Error())
-	}
-
-	// Gateway port range
-	if cfg.Gateway.Port != 0 && (cfg.Gateway.Port < 1 || cfg.Gateway.Port > 65535) {
-		errs = append(errs, fmt.Sprintf("gateway.port %d is out of valid range (1-65535)", cfg.Gateway.Port))
-	}
-
-	for name, bc := range cfg.Channels {
-		streaming, ok := channelStreamingConfig(bc)
-		if !ok {
+	for _, err := range cfg.Validate() {
+		var ve *config.ValidationError
+		if errors.As(err, &ve) && ve.Warning && !strings.HasPrefix(ve.Field, "channels.") {
 			continue
 		}
-		if streaming.ThrottleSeconds < 0 {
-			errs = append(errs, fmt.Sprintf("channel %q streaming.throttle_seconds must be >= 0", name))
-		}
-		if streaming.MinGrowthChars < 0 {
-			errs = append(errs, fmt.Sprintf("channel %q streaming.min_growth_chars must be >= 0", name))
-		}
-	}
-
-	// Pico channel: token required when enabled
-	{
-		bc := cfg.Channels.GetByType(config.ChannelPico)
-		if bc != nil && bc.Enabled {
-			if decoded, err := bc.GetDecoded(); err == nil && decoded != nil {
-				if c, ok := decoded.(*config.PicoSettings); ok && c.Token.String() == "" {
-					errs = append(errs, "channels.pico.token is required when pico channel is enabled")
-				}
-			}
-		}
-	}
-
-	// Telegram: token required when enabled
-	{
-		bc := cfg.Channels.GetByType(config.ChannelTelegram)
-		if bc != nil && bc.Enabled {
-			if decoded, err := bc.GetDecoded(); err == nil && decoded != nil {
-				if c, ok := decoded.(*config.TelegramSettings); ok && c.Token.String() == "" {
-					errs = append(errs, "channels.telegram.token is required when telegram channel is enabled")
-				}
-			}
-		}
-	}
-
-	// Discord: token required when enabled
-	{
-		bc := cfg.Channels.GetByType(config.ChannelDiscord)
-		if bc != nil && bc.Enabled {
-			if decoded, err := bc.GetDecoded(); err == nil && decoded != nil {
-				if c, ok := decoded.(*config.DiscordSettings); ok && c.Token.String() == "" {
-					errs = append(errs, "channels.discord.token is required when discord channel is enabled")
-				}
-			}
-		}
-	}
-
-	{
-		bc := cfg.Channels.GetByType(config.ChannelWeCom)
-		if bc != nil && bc.Enabled {
-			if decoded, err := bc.GetDecoded(); err == nil && decoded != nil {
-				if c, ok := decoded.(*config.WeComSettings); ok {
-					if c.BotID == "" {
-						errs = append(errs, "channels.wecom.bot_id is required when wecom channel is enabled")
-					}
-					if c.Secret.String() == "" {
-						errs = append(errs, "channels.wecom.secret is required when wecom channel is enabled")
-					}
-				}
-			}
-		}
-	}
-
-	if cfg.Tools.Exec.Enabled {
-		if cfg.Tools.Exec.EnableDenyPatterns {
-			errs = append(
-				errs,
-				validateRegexPatterns("tools.exec.custom_deny_patterns", cfg.Tools.Exec.CustomDenyPatterns)...)
-		}
-		errs = append(
-			errs,
-			validateRegexPatterns("tools.exec.custom_allow_patterns", cfg.Tools.Exec.CustomAllowPatterns)...)
-	}
-
-	return errs
-}
-
-func validateRegexPatterns(field string, patterns []string) []string {
-	var errs []string
-	for index, pattern := range patterns {
-		if _, err := regexp.Compile(pattern); err != nil {
-			errs = append(errs, fmt.Sprintf("%s[%d] is not a valid regular expression: %v", field, index, err))
-		}
+		errs = append(errs, err.Error())
 	}
 	return errs
 }
 
-// mergeMap recursively merges src into dst (JSON Merge Patch semantics).
-// - If a key in src has a null value, it is deleted from dst.
-// - If both dst and src have a nested object for the same key, merge recursively.
-// - Otherwise the value from src overwrites dst.
-func mergeMap(dst, src map[string]any) {
-	for key, srcVal := range src {
-		if srcVal == nil {
-			delete(dst, key)
-			continue
-		}
-		srcMap, srcIsMap := srcVal.(map[string]any)
-		dstMap, dstIsMap := dst[key].(map[string]any)
-		if srcIsMap && dstIsMap {
-			mergeMap(dstMap, srcMap)
-		} else {
-			dst[key] = srcVal
-		}
-	}
-}
-
 func asMapField(value map[string]any, key string) (map[string]any, bool) {
 	raw, exists := value[key]
 	if !exists {
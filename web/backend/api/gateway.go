@@ -437,6 +437,9 @@ func computeConfigSignature(cfg *config.Config) string {
 	if cfg.Tools.Exec.Enabled {
 		toolSignatures = append(toolSignatures, "exec")
 	}
+	if cfg.Tools.Git.Enabled {
+		toolSignatures = append(toolSignatures, fmt.Sprintf("git:%t:%t", cfg.Tools.Git.AllowPush, cfg.Tools.Git.AllowReset))
+	}
 	if cfg.Tools.Cron.Enabled {
 		toolSignatures = append(toolSignatures, "cron")
 	}
@@ -450,6 +453,13 @@ func computeConfigSignature(cfg *config.Config) string {
 	if cfg.Tools.WebFetch.Enabled {
 		toolSignatures = append(toolSignatures, "web_fetch")
 	}
+	if cfg.Tools.HTTP.Enabled {
+		toolSignatures = append(toolSignatures, "http")
+		httpConfig, err := json.Marshal(canonicalizeSignatureValue(reflect.ValueOf(cfg.Tools.HTTP)))
+		if err == nil {
+			parts = append(parts, "httpcfg:"+string(httpConfig))
+		}
+	}
 	if cfg.Tools.Message.Enabled {
 		toolSignatures = append(toolSignatures, "message")
 	}
@@ -482,6 +482,8 @@ func oauthProviderForModel(m *config.ModelConfig) (string, bool) {
 		return oauthProviderAnthropic, true
 	case "antigravity":
 		return oauthProviderGoogleAntigravity, true
+	case "gemini":
+		return oauthProviderGoogle, true
 	default:
 		return "", false
 	}
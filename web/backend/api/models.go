@@ -51,6 +51,10 @@ type modelResponse struct {
 	RequestTimeout      int                         `json:"request_timeout,omitempty"`
 	ThinkingLevel       string                      `json:"thinking_level,omitempty"`
 	ToolSchemaTransform string                      `json:"tool_schema_transform,omitempty"`
+	PromptCaching       bool                        `json:"prompt_caching,omitempty"`
+	ThinkingBudget      *int                        `json:"thinking_budget,omitempty"`
+	EnableWebSearch     bool                        `json:"enable_web_search,omitempty"`
+	StopSequences       []string                    `json:"stop_sequences,omitempty"`
 	Streaming           config.ModelStreamingConfig `json:"streaming,omitempty"`
 	ExtraBody           map[string]any              `json:"extra_body,omitempty"`
 	CustomHeaders       map[string]string           `json:"custom_headers,omitempty"`
@@ -280,7 +284,11 @@ func (h *Handler) handleListModels(w http.ResponseWriter, r *http.Request) {
 			MaxTokensField:      m.MaxTokensField,
 			RequestTimeout:      m.RequestTimeout,
 			ThinkingLevel:       m.ThinkingLevel,
+			PromptCaching:       m.PromptCaching,
+			ThinkingBudget:      m.ThinkingBudget,
+			EnableWebSearch:     m.EnableWebSearch,
 			ToolSchemaTransform: m.ToolSchemaTransform,
+			StopSequences:       m.StopSequences,
 			Streaming:           m.Streaming,
 			ExtraBody:           m.ExtraBody,
 			CustomHeaders:       m.CustomHeaders,
@@ -433,6 +441,18 @@ func (h *Handler) handleUpdateModel(w http.ResponseWriter, r *http.Request) {
 	if _, ok := rawFields["streaming"]; !ok {
 		mc.Streaming = cfg.ModelList[idx].Streaming
 	}
+	if _, ok := rawFields["prompt_caching"]; !ok {
+		mc.PromptCaching = cfg.ModelList[idx].PromptCaching
+	}
+	if _, ok := rawFields["thinking_budget"]; !ok {
+		mc.ThinkingBudget = cfg.ModelList[idx].ThinkingBudget
+	}
+	if _, ok := rawFields["enable_web_search"]; !ok {
+		mc.EnableWebSearch = cfg.ModelList[idx].EnableWebSearch
+	}
+	if _, ok := rawFields["stop_sequences"]; !ok {
+		mc.StopSequences = cfg.ModelList[idx].StopSequences
+	}
 	// Preserve the existing Provider when the caller omits it. This keeps the
 	// update API backward-compatible for clients that haven't started sending
 	// the new field yet, while still allowing explicit clearing via "".
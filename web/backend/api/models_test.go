@@ -258,8 +258,8 @@ func TestHandleListModels_AvailabilityForOAuthModelWithCredential(t *testing.T)
 
 func TestHasModelConfiguration_OAuthWithoutMappedCredentialFallsBackToAPIKey(t *testing.T) {
 	noKey := &config.ModelConfig{
-		Provider:   "gemini",
-		Model:      "gemini-2.5-flash",
+		Provider:   "deepseek",
+		Model:      "deepseek-chat",
 		AuthMethod: "oauth",
 	}
 	if hasModelConfiguration(noKey) {
@@ -267,13 +267,40 @@ func TestHasModelConfiguration_OAuthWithoutMappedCredentialFallsBackToAPIKey(t *
 	}
 
 	withKey := &config.ModelConfig{
+		Provider:   "deepseek",
+		Model:      "deepseek-chat",
+		AuthMethod: "oauth",
+		APIKeys:    config.SimpleSecureStrings("deepseek-key"),
+	}
+	if !hasModelConfiguration(withKey) {
+		t.Fatal("oauth model without credential mapping should fall back to api key configuration")
+	}
+}
+
+func TestHasModelConfiguration_GeminiOAuthUsesGoogleCredential(t *testing.T) {
+	resetOAuthHooks(t)
+
+	var stored *auth.AuthCredential
+	oauthGetCredential = func(provider string) (*auth.AuthCredential, error) {
+		if provider != oauthProviderGoogle {
+			t.Fatalf("provider = %q, want %q", provider, oauthProviderGoogle)
+		}
+		return stored, nil
+	}
+
+	model := &config.ModelConfig{
 		Provider:   "gemini",
 		Model:      "gemini-2.5-flash",
 		AuthMethod: "oauth",
 		APIKeys:    config.SimpleSecureStrings("gemini-key"),
 	}
-	if !hasModelConfiguration(withKey) {
-		t.Fatal("oauth model without credential mapping should fall back to api key configuration")
+	if hasModelConfiguration(model) {
+		t.Fatal("gemini oauth model without google credential should be unconfigured")
+	}
+
+	stored = &auth.AuthCredential{AccessToken: "google-token", Provider: "google", AuthMethod: "oauth"}
+	if !hasModelConfiguration(model) {
+		t.Fatal("gemini oauth model with google credential should be configured")
 	}
 }
 
@@ -21,6 +21,7 @@ const (
 	oauthProviderOpenAI            = "openai"
 	oauthProviderAnthropic         = "anthropic"
 	oauthProviderGoogleAntigravity = "google-antigravity"
+	oauthProviderGoogle            = "google"
 
 	oauthMethodBrowser    = "browser"
 	oauthMethodDeviceCode = "device_code"
@@ -100,18 +100,60 @@ var toolCatalog = []toolCatalogEntry{
 		Category:    "filesystem",
 		ConfigKey:   "append_file",
 	},
+	{
+		Name:        "ocr",
+		Description: "Extract text from images in the workspace or at a URL.",
+		Category:    "filesystem",
+		ConfigKey:   "ocr",
+	},
+	{
+		Name:        "memory",
+		Description: "Remember, recall, and forget named facts that persist across sessions.",
+		Category:    "filesystem",
+		ConfigKey:   "memory",
+	},
 	{
 		Name:        "exec",
 		Description: "Run shell commands inside the configured workspace sandbox.",
 		Category:    "filesystem",
 		ConfigKey:   "exec",
 	},
+	{
+		Name:        "git",
+		Description: "Run git status, diff, log, commit, and branch operations on workspace repositories.",
+		Category:    "filesystem",
+		ConfigKey:   "git",
+	},
+	{
+		Name:        "python",
+		Description: "Run Python scripts with a timeout, memory limit, and no network access.",
+		Category:    "filesystem",
+		ConfigKey:   "python",
+	},
 	{
 		Name:        "cron",
 		Description: "Schedule one-time or recurring reminders, jobs, and shell commands.",
 		Category:    "automation",
 		ConfigKey:   "cron",
 	},
+	{
+		Name:        "plan",
+		Description: "Keep a per-session checklist for multi-step tasks, shown to the agent every turn.",
+		Category:    "automation",
+		ConfigKey:   "plan",
+	},
+	{
+		Name:        "reminder",
+		Description: "Set, list, and cancel one-time reminders delivered back to the chat.",
+		Category:    "automation",
+		ConfigKey:   "reminder",
+	},
+	{
+		Name:        "timer",
+		Description: "Run short in-session countdowns and stopwatches; a finished countdown messages the chat.",
+		Category:    "automation",
+		ConfigKey:   "timer",
+	},
 	{
 		Name:        "web_search",
 		Description: "Search the web using the configured providers.",
@@ -124,18 +166,54 @@ var toolCatalog = []toolCatalogEntry{
 		Category:    "web",
 		ConfigKey:   "web_fetch",
 	},
+	{
+		Name:        "weather",
+		Description: "Get current conditions and daily forecasts for a place or coordinates.",
+		Category:    "web",
+		ConfigKey:   "weather",
+	},
+	{
+		Name:        "market",
+		Description: "Get stock and cryptocurrency quotes and daily price history.",
+		Category:    "web",
+		ConfigKey:   "market",
+	},
+	{
+		Name:        "http_request",
+		Description: "Call HTTP APIs with custom methods, headers, and request bodies.",
+		Category:    "web",
+		ConfigKey:   "http",
+	},
 	{
 		Name:        "message",
 		Description: "Send a follow-up message back to the active user or chat.",
 		Category:    "communication",
 		ConfigKey:   "message",
 	},
+	{
+		Name:        "send_email",
+		Description: "Send an email over SMTP, optionally with workspace files attached.",
+		Category:    "communication",
+		ConfigKey:   "email",
+	},
+	{
+		Name:        "translate",
+		Description: "Translate text into another language and return only the translation.",
+		Category:    "communication",
+		ConfigKey:   "translate",
+	},
 	{
 		Name:        "send_file",
 		Description: "Send an outbound file or media attachment to the active chat.",
 		Category:    "communication",
 		ConfigKey:   "send_file",
 	},
+	{
+		Name:        "image_gen",
+		Description: "Generate images from text prompts and send them to the chat.",
+		Category:    "communication",
+		ConfigKey:   "image_gen",
+	},
 	{
 		Name:        "find_skills",
 		Description: "Search external skill registries for installable skills.",
@@ -178,6 +256,18 @@ var toolCatalog = []toolCatalogEntry{
 		Category:    "hardware",
 		ConfigKey:   "serial",
 	},
+	{
+		Name:        "clipboard",
+		Description: "Read and write the clipboard of the desktop PicoClaw runs on.",
+		Category:    "hardware",
+		ConfigKey:   "clipboard",
+	},
+	{
+		Name:        "screenshot",
+		Description: "Capture the screen of the desktop PicoClaw runs on for the model to look at.",
+		Category:    "hardware",
+		ConfigKey:   "screenshot",
+	},
 	{
 		Name:        "tool_search_tool_regex",
 		Description: "Discover hidden MCP tools by regex search when tool discovery is enabled.",
@@ -348,16 +438,42 @@ func applyToolState(cfg *config.Config, toolName string, enabled bool) error {
 		cfg.Tools.AppendFile.Enabled = enabled
 	case "exec":
 		cfg.Tools.Exec.Enabled = enabled
+	case "git":
+		cfg.Tools.Git.Enabled = enabled
+	case "python":
+		cfg.Tools.Python.Enabled = enabled
 	case "cron":
 		cfg.Tools.Cron.Enabled = enabled
+	case "plan":
+		cfg.Tools.Plan.Enabled = enabled
+	case "reminder":
+		cfg.Tools.Reminder.Enabled = enabled
+	case "timer":
+		cfg.Tools.Timer.Enabled = enabled
 	case "web_search":
 		cfg.Tools.Web.Enabled = enabled
 	case "web_fetch":
 		cfg.Tools.WebFetch.Enabled = enabled
+	case "http_request":
+		cfg.Tools.HTTP.Enabled = enabled
 	case "message":
 		cfg.Tools.Message.Enabled = enabled
 	case "send_file":
 		cfg.Tools.SendFile.Enabled = enabled
+	case "image_gen":
+		cfg.Tools.ImageGen.Enabled = enabled
+	case "ocr":
+		cfg.Tools.OCR.Enabled = enabled
+	case "weather":
+		cfg.Tools.Weather.Enabled = enabled
+	case "market":
+		cfg.Tools.Market.Enabled = enabled
+	case "send_email":
+		cfg.Tools.Email.Enabled = enabled
+	case "translate":
+		cfg.Tools.Translate.Enabled = enabled
+	case "memory":
+		cfg.Tools.Memory.Enabled = enabled
 	case "find_skills":
 		cfg.Tools.FindSkills.Enabled = enabled
 		if enabled {
@@ -385,6 +501,10 @@ func applyToolState(cfg *config.Config, toolName string, enabled bool) error {
 		cfg.Tools.SPI.Enabled = enabled
 	case "serial":
 		cfg.Tools.Serial.Enabled = enabled
+	case "clipboard":
+		cfg.Tools.Clipboard.Enabled = enabled
+	case "screenshot":
+		cfg.Tools.Screenshot.Enabled = enabled
 	case "tool_search_tool_regex":
 		cfg.Tools.MCP.Discovery.UseRegex = enabled
 		if enabled {
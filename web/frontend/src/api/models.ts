@@ -23,6 +23,7 @@ export interface ModelInfo {
   streaming?: {
     enabled?: boolean
   }
+  stop_sequences?: string[]
   extra_body?: Record<string, unknown>
   custom_headers?: Record<string, string>
   // Meta